@@ -27,6 +27,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/policy"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/router"
@@ -35,6 +36,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	apiTypes "github.com/tsuru/tsuru/types/api"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	logTypes "github.com/tsuru/tsuru/types/log"
@@ -78,6 +80,15 @@ func getAppFromContext(name string, r *http.Request) (*appTypes.App, error) {
 	return a, nil
 }
 
+func changesEgressAllow(metadata appTypes.Metadata) bool {
+	for _, item := range metadata.Annotations {
+		if item.Name == appTypes.EgressAllowAnnotation {
+			return true
+		}
+	}
+	return false
+}
+
 func getApp(ctx stdContext.Context, name string) (*appTypes.App, error) {
 	a, err := app.GetByName(ctx, name)
 	if err != nil {
@@ -147,6 +158,7 @@ func appVersionDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 //	200: App removed
 //	401: Unauthorized
 //	404: Not found
+//	409: App protected against deletion
 func appDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
@@ -176,7 +188,158 @@ func appDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
 	w.Header().Set("Content-Type", "application/x-json-stream")
-	return app.Delete(ctx, a, evt, requestIDHeader(r))
+	err = app.Delete(ctx, a, evt, requestIDHeader(r))
+	if err == app.ErrDeletionProtected {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: enable app deletion protection
+// path: /apps/{app}/deletion-protection
+// method: POST
+// responses:
+//
+//	200: Deletion protection enabled
+//	401: Unauthorized
+//	404: App not found
+func appDeletionProtectionEnable(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppDeletionProtection(r, t, permission.PermAppUpdateDeletionProtection, true)
+}
+
+// title: disable app deletion protection
+// path: /apps/{app}/deletion-protection
+// method: DELETE
+// responses:
+//
+//	200: Deletion protection disabled
+//	401: Unauthorized
+//	404: App not found
+func appDeletionProtectionDisable(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppDeletionProtection(r, t, permission.PermAppAdminDeletionProtection, false)
+}
+
+func setAppDeletionProtection(r *http.Request, t auth.Token, perm *permTypes.PermissionScheme, enabled bool) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, perm, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       perm,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.SetDeletionProtection(ctx, a, enabled)
+}
+
+// title: enable app deploy approval
+// path: /apps/{app}/require-approval
+// method: POST
+// responses:
+//
+//	200: Deploy approval enabled
+//	401: Unauthorized
+//	404: App not found
+func appRequireApprovalEnable(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppRequireApproval(r, t, permission.PermAppUpdateRequireApproval, true)
+}
+
+// title: disable app deploy approval
+// path: /apps/{app}/require-approval
+// method: DELETE
+// responses:
+//
+//	200: Deploy approval disabled
+//	401: Unauthorized
+//	404: App not found
+func appRequireApprovalDisable(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppRequireApproval(r, t, permission.PermAppAdminRequireApproval, false)
+}
+
+func setAppRequireApproval(r *http.Request, t auth.Token, perm *permTypes.PermissionScheme, enabled bool) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, perm, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       perm,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.SetRequireApproval(ctx, a, enabled)
+}
+
+// title: set app service account annotations
+// path: /apps/{app}/service-account
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Service account annotations set
+//	400: Invalid annotations
+//	401: Unauthorized
+//	404: App not found
+func setAppServiceAccount(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var data struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	err = ParseInput(r, &data)
+	if err != nil {
+		return err
+	}
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateServiceAccount, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateServiceAccount,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.SetServiceAccountAnnotations(ctx, a, data.Annotations)
+	if err != nil {
+		if _, ok := err.(*errors.ValidationError); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	return nil
 }
 
 func minifyApp(app *appTypes.App, unitData app.AppUnitsResponse, extended bool) (appTypes.AppResume, error) {
@@ -226,6 +389,8 @@ contextsLoop:
 			filter.ExtraIn("name", c.Value)
 		case permTypes.CtxPool:
 			filter.ExtraIn("pool", c.Value)
+		case permTypes.CtxTag:
+			filter.ExtraIn("tags", c.Value)
 		}
 	}
 	return filter
@@ -353,7 +518,11 @@ type inputApp struct {
 	PlanOverride appTypes.PlanOverride
 	Metadata     appTypes.Metadata
 
-	Processes []appTypes.Process
+	Processes             []appTypes.Process
+	SchedulingConstraints map[string]string
+	Spread                *appTypes.Spread
+	SecurityContext       *appTypes.SecurityContext
+	InitContainers        []appTypes.InitContainer
 }
 
 func autoTeamOwner(ctx stdContext.Context, t auth.Token, perm *permTypes.PermissionScheme) (string, error) {
@@ -384,7 +553,7 @@ func autoTeamOwner(ctx stdContext.Context, t auth.Token, perm *permTypes.Permiss
 //	201: App created
 //	400: Invalid data
 //	401: Unauthorized
-//	403: Quota exceeded
+//	403: Quota exceeded or denied by admission policies
 //	409: App already exists
 func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
@@ -406,7 +575,11 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Metadata:    ia.Metadata,
 		Quota:       quota.UnlimitedQuota,
 
-		Processes: ia.Processes,
+		Processes:             ia.Processes,
+		SchedulingConstraints: ia.SchedulingConstraints,
+		Spread:                ia.Spread,
+		SecurityContext:       ia.SecurityContext,
+		InitContainers:        ia.InitContainers,
 	}
 	tags, _ := InputValues(r, "tag")
 	a.Tags = append(a.Tags, tags...) // for compatibility
@@ -422,6 +595,20 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !canCreate {
 		return permission.ErrUnauthorized
 	}
+	err = checkTagsContextPermission(ctx, t, nil, a.Tags)
+	if err != nil {
+		return err
+	}
+	// only global admins may override the security context of the pool.
+	if a.SecurityContext != nil && !permission.Check(ctx, t, permission.PermAppUpdateSecurityContext) {
+		return permission.ErrUnauthorized
+	}
+	if changesEgressAllow(a.Metadata) && !permission.Check(ctx, t, permission.PermAppAdminEgress,
+		permission.Context(permTypes.CtxTeam, a.TeamOwner),
+		permission.Context(permTypes.CtxPool, a.Pool),
+	) {
+		return permission.ErrUnauthorized
+	}
 	u, err := auth.ConvertNewUser(t.User(ctx))
 	if err != nil {
 		return err
@@ -487,6 +674,9 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		if err == appTypes.ErrInvalidPlatform {
 			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 		}
+		if _, ok := err.(*policy.DeniedError); ok {
+			return &errors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
+		}
 		return err
 	}
 	msg := map[string]interface{}{
@@ -519,6 +709,7 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 //	200: App updated
 //	400: Invalid new pool
 //	401: Unauthorized
+//	403: Denied by admission policies
 //	404: Not found
 func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
@@ -540,6 +731,11 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		RouterOpts:     ia.RouterOpts,
 		Metadata:       ia.Metadata,
 		Processes:      ia.Processes,
+
+		SchedulingConstraints: ia.SchedulingConstraints,
+		Spread:                ia.Spread,
+		SecurityContext:       ia.SecurityContext,
+		InitContainers:        ia.InitContainers,
 	}
 	tags, _ := InputValues(r, "tag")
 	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
@@ -596,9 +792,28 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if len(updateData.Metadata.Annotations) > 0 || len(updateData.Metadata.Labels) > 0 {
 		wantedPerms = append(wantedPerms, permission.PermAppUpdateMetadata)
 	}
+	if changesEgressAllow(updateData.Metadata) {
+		// the egress allow list opens the network of the app to outside
+		// destinations, so it's set by pool admins only.
+		wantedPerms = append(wantedPerms, permission.PermAppAdminEgress)
+	}
 	if len(updateData.Processes) > 0 {
 		wantedPerms = append(wantedPerms, permission.PermAppUpdateProcesses)
 	}
+	if updateData.Spread != nil {
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateSpread)
+	}
+	if updateData.SecurityContext != nil {
+		// overriding the security context may weaken the one of the pool, so
+		// it's only allowed to global admins.
+		if !permission.Check(ctx, t, permission.PermAppUpdateSecurityContext) {
+			return permission.ErrUnauthorized
+		}
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateSecurityContext)
+	}
+	if len(updateData.InitContainers) > 0 {
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateInitContainers)
+	}
 	if len(wantedPerms) == 0 {
 		msg := "Neither the description, tags, plan, pool, team owner or platform were set. You must define at least one."
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
@@ -611,6 +826,22 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			return permission.ErrUnauthorized
 		}
 	}
+	if len(updateData.Tags) > 0 {
+		err = checkTagsContextPermission(ctx, t, a.Tags, updateData.Tags)
+		if err != nil {
+			return err
+		}
+	}
+	if updateData.Plan.Name != "" {
+		// Unknown plans are reported by app.Update.
+		plan, errPlan := servicemanager.Plan.FindByName(ctx, updateData.Plan.Name)
+		if errPlan == nil {
+			err = checkAdmissionPolicies(ctx, t, policy.OperationAppPlanUpdate, a, map[string]interface{}{"plan": plan})
+			if err != nil {
+				return err
+			}
+		}
+	}
 
 	if len(updateData.Tags) > 0 {
 		var tagResponse *tagTypes.ValidationResponse
@@ -845,6 +1076,129 @@ func killUnit(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return err
 }
 
+type unitsHistoryResponse struct {
+	History    []provTypes.UnitStatusHistory `json:"history"`
+	CrashLoops []app.CrashLoopStatus         `json:"crashLoops"`
+}
+
+// title: units status history
+// path: /apps/{app}/units/history
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func unitsHistory(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadInfo,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	filter := app.UnitHistoryFilter{
+		Unit:    InputValue(r, "unit"),
+		Process: InputValue(r, "process"),
+	}
+	if since := InputValue(r, "since"); since != "" {
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid since value %q: %v", since, err)}
+		}
+	}
+	if limit := InputValue(r, "limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid limit value %q", limit)}
+		}
+	}
+	history, err := app.ListUnitStatusHistory(ctx, a.Name, filter)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	crashLoops, err := app.CrashLoopReport(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(unitsHistoryResponse{
+		History:    history,
+		CrashLoops: crashLoops,
+	})
+}
+
+// title: app health
+// path: /apps/{app}/health
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func appHealth(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadInfo,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	health := app.Health(ctx, a, requestIDHeader(r))
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(health)
+}
+
+// title: app units metrics
+// path: /apps/{app}/metrics
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+//	404: App not found
+func appUnitsMetrics(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadInfo,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	metrics, err := app.UnitsMetrics(ctx, a)
+	if err != nil {
+		return err
+	}
+	if len(metrics) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(metrics)
+}
+
 // title: grant access to app
 // path: /apps/{app}/teams/{team}
 // method: PUT
@@ -1000,6 +1354,75 @@ func runCommand(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	return app.Run(ctx, a, command, evt, args)
 }
 
+// title: run task
+// path: /apps/{app}/tasks
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// method: POST
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func runTask(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppRunTask,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	args := app.TaskArgs{Command: InputValue(r, "command")}
+	if raw := InputValue(r, "timeout"); raw != "" {
+		seconds, parseErr := strconv.Atoi(raw)
+		if parseErr != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid timeout: %s", raw)}
+		}
+		args.Timeout = time.Duration(seconds) * time.Second
+	}
+	if raw := InputValue(r, "cpumilli"); raw != "" {
+		args.CPUMilli, err = strconv.Atoi(raw)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid cpumilli: %s", raw)}
+		}
+	}
+	if raw := InputValue(r, "memory"); raw != "" {
+		args.Memory, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid memory: %s", raw)}
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppRunTask,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = app.RunTask(ctx, a, args, evt)
+	switch err {
+	case app.ErrTaskCommandRequired, app.ErrInvalidTaskTimeout, app.ErrInvalidTaskLimits:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
 // title: get envs
 // path: /apps/{app}/env
 // method: GET
@@ -1026,6 +1449,13 @@ func getAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if rawVersion := r.URL.Query().Get("version"); rawVersion != "" {
+		version, parseErr := strconv.Atoi(rawVersion)
+		if parseErr != nil || version <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "version must be a positive integer"}
+		}
+		return writeVersionEnvVars(w, a, version, variables...)
+	}
 
 	return writeEnvVars(w, a, variables...)
 }
@@ -1047,6 +1477,25 @@ func writeEnvVars(w http.ResponseWriter, a *appTypes.App, variables ...string) e
 	return json.NewEncoder(w).Encode(result)
 }
 
+// writeVersionEnvVars writes the envs seen by the units of a version,
+// including the ones restricted to it.
+func writeVersionEnvVars(w http.ResponseWriter, a *appTypes.App, version int, variables ...string) error {
+	var result []bindTypes.EnvVar
+	w.Header().Set("Content-Type", "application/json")
+	envs := provision.EnvsForAppVersion(a, version)
+	if len(variables) == 0 {
+		for _, v := range envs {
+			result = append(result, v)
+		}
+	}
+	for _, variable := range variables {
+		if v, ok := envs[variable]; ok {
+			result = append(result, v)
+		}
+	}
+	return json.NewEncoder(w).Encode(result)
+}
+
 // title: set envs
 // path: /apps/{app}/env
 // method: POST
@@ -1057,6 +1506,7 @@ func writeEnvVars(w http.ResponseWriter, a *appTypes.App, variables ...string) e
 //	200: Envs updated
 //	400: Invalid data
 //	401: Unauthorized
+//	403: Denied by admission policies
 //	404: App not found
 func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
@@ -1092,6 +1542,22 @@ func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		return permission.ErrUnauthorized
 	}
 
+	policyEnvs := make([]map[string]interface{}, 0, len(e.Envs))
+	for _, v := range e.Envs {
+		policyEnvs = append(policyEnvs, map[string]interface{}{
+			"name":    v.Name,
+			"private": e.Private || (v.Private != nil && *v.Private),
+		})
+	}
+	err = checkAdmissionPolicies(ctx, t, policy.OperationAppEnvSet, a, map[string]interface{}{
+		"envs":      policyEnvs,
+		"managedBy": e.ManagedBy,
+		"version":   e.Version,
+	})
+	if err != nil {
+		return err
+	}
+
 	var toExclude []string
 	for i := 0; i < len(e.Envs); i++ {
 		if (e.Envs[i].Private != nil && *e.Envs[i].Private) || e.Private {
@@ -1143,10 +1609,14 @@ func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		PruneUnused:   e.PruneUnused,
 		ShouldRestart: !e.NoRestart,
 		Writer:        evt,
+		Version:       e.Version,
 	})
 	if v, ok := err.(*errors.ValidationError); ok {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
 	}
+	if appTypes.IsInvalidVersionError(err) {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
 	return err
 }
 
@@ -1243,12 +1713,149 @@ func unsetAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
 	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
+	version, _ := strconv.Atoi(InputValue(r, "version"))
 
-	return app.UnsetEnvs(ctx, a, bindTypes.UnsetEnvArgs{
+	err = app.UnsetEnvs(ctx, a, bindTypes.UnsetEnvArgs{
 		VariableNames: variables,
 		ShouldRestart: !noRestart,
 		Writer:        evt,
+		Version:       version,
 	})
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	if appTypes.IsInvalidVersionError(err) {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: promote version envs
+// path: /apps/{app}/env/promote
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Envs promoted
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func promoteAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	version, err := strconv.Atoi(InputValue(r, "version"))
+	if err != nil || version <= 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "version must be a positive integer"}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateEnvPromote,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateEnvPromote,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
+	err = app.PromoteVersionEnvs(ctx, a, version, !noRestart, evt)
+	if err == app.ErrNoVersionEnvs {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: get env schema
+// path: /apps/{app}/env/schema
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func getAppEnvSchema(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadEnv,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	schema := a.EnvSchema
+	if schema == nil {
+		schema = []appTypes.EnvVarSchema{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(schema)
+}
+
+// title: set env schema
+// path: /apps/{app}/env/schema
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Schema updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func setAppEnvSchema(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var schema []appTypes.EnvVarSchema
+	err = ParseJSON(r, &schema)
+	if err != nil {
+		return err
+	}
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateEnvSchema,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateEnvSchema,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: schema,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.SetEnvSchema(ctx, a, schema)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
 }
 
 // title: set cname
@@ -1402,6 +2009,23 @@ func appLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		Source:       source,
 		InvertSource: invert,
 		Units:        units,
+		Query:        urlValues.Get("query"),
+		Severity:     urlValues.Get("severity"),
+	}
+	if since := urlValues.Get("since"); since != "" {
+		listArgs.Since, err = time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid since value %q: %v", since, err)}
+		}
+	}
+	if until := urlValues.Get("until"); until != "" {
+		listArgs.Until, err = time.Parse(time.RFC3339Nano, until)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid until value %q: %v", until, err)}
+		}
+	}
+	if err = listArgs.Validate(); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
 	logs, err := app.LastLogs(ctx, a, logService, listArgs)
 	if err != nil {
@@ -1496,6 +2120,7 @@ func bindServiceInstance(w http.ResponseWriter, r *http.Request, t auth.Token) (
 	serviceName := r.URL.Query().Get(":service")
 	req := struct {
 		NoRestart  bool
+		Version    int
 		Parameters service.BindAppParameters
 	}{}
 	err = ParseInput(r, &req)
@@ -1556,7 +2181,7 @@ func bindServiceInstance(w http.ResponseWriter, r *http.Request, t auth.Token) (
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
-	err = instance.BindApp(ctx, a, req.Parameters, !req.NoRestart, evt, evt, requestIDHeader(r))
+	err = instance.BindAppVersion(ctx, a, req.Version, req.Parameters, !req.NoRestart, evt, evt, requestIDHeader(r))
 	if err != nil {
 		status, errStatus := instance.Status(ctx, requestIDHeader(r))
 		if errStatus != nil {
@@ -2200,9 +2825,103 @@ func unsetCertIssuer(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	return err
 }
 
+// tagsUsedAsContext returns which of the given tags are the context value of a
+// role assigned to a user, group or team token. Such tags grant permissions
+// over the apps carrying them.
+func tagsUsedAsContext(ctx stdContext.Context, tags []string) (map[string]bool, error) {
+	used := map[string]bool{}
+	if len(tags) == 0 {
+		return used, nil
+	}
+	roles, err := permission.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagRoles := map[string]bool{}
+	for _, role := range roles {
+		if role.ContextType == permTypes.CtxTag {
+			tagRoles[role.Name] = true
+		}
+	}
+	if len(tagRoles) == 0 {
+		return used, nil
+	}
+	wanted := map[string]bool{}
+	for _, tag := range tags {
+		wanted[tag] = true
+	}
+	mark := func(instances []authTypes.RoleInstance) {
+		for _, ri := range instances {
+			if tagRoles[ri.Name] && wanted[ri.ContextValue] {
+				used[ri.ContextValue] = true
+			}
+		}
+	}
+	for roleName := range tagRoles {
+		users, err := auth.ListUsersWithRole(ctx, roleName)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			mark(u.Roles)
+		}
+		tokens, err := servicemanager.TeamToken.FindByRole(ctx, roleName)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			mark(token.Roles)
+		}
+	}
+	groups, err := servicemanager.AuthGroup.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		mark(g.Roles)
+	}
+	return used, nil
+}
+
+// checkTagsContextPermission requires the app.update.tags permission in the
+// context of each added or removed tag that is used as a permission context,
+// otherwise tagging an app would change who can access it.
+func checkTagsContextPermission(ctx stdContext.Context, t auth.Token, oldTags, newTags []string) error {
+	oldSet := map[string]bool{}
+	for _, tag := range oldTags {
+		oldSet[strings.TrimSpace(tag)] = true
+	}
+	newSet := map[string]bool{}
+	for _, tag := range newTags {
+		newSet[strings.TrimSpace(tag)] = true
+	}
+	var changed []string
+	for tag := range newSet {
+		if tag != "" && !oldSet[tag] {
+			changed = append(changed, tag)
+		}
+	}
+	for tag := range oldSet {
+		if tag != "" && !newSet[tag] {
+			changed = append(changed, tag)
+		}
+	}
+	used, err := tagsUsedAsContext(ctx, changed)
+	if err != nil {
+		return err
+	}
+	for tag := range used {
+		if !permission.Check(ctx, t, permission.PermAppUpdateTags, permission.Context(permTypes.CtxTag, tag)) {
+			return permission.ErrUnauthorized
+		}
+	}
+	return nil
+}
+
 func contextsForApp(a *appTypes.App) []permTypes.PermissionContext {
-	return append(permission.Contexts(permTypes.CtxTeam, a.Teams),
+	contexts := append(permission.Contexts(permTypes.CtxTeam, a.Teams),
 		permission.Context(permTypes.CtxApp, a.Name),
 		permission.Context(permTypes.CtxPool, a.Pool),
 	)
+	return append(contexts, permission.Contexts(permTypes.CtxTag, a.Tags)...)
 }
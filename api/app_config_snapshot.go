@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+// title: list app config snapshots
+// path: /apps/{app}/config/snapshots
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+//	404: App not found
+func appConfigSnapshotList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppReadConfig, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	snapshots, err := app.ListConfigSnapshots(ctx, a)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	for i := range snapshots {
+		env := map[string]bindTypes.EnvVar{}
+		for name, v := range snapshots[i].Env {
+			if !v.Public {
+				v.Value = app.SuppressedEnv
+			}
+			env[name] = v
+		}
+		snapshots[i].Env = env
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(snapshots)
+}
+
+// title: restore app config snapshot
+// path: /apps/{app}/config/restore
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Configuration restored
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App or snapshot not found
+func appConfigRestore(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppUpdateConfigRestore, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	snapshotID := InputValue(r, "snapshot")
+	if snapshotID == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the snapshot to restore"}
+	}
+	snapshot, err := app.GetConfigSnapshot(ctx, a, snapshotID)
+	if err == app.ErrConfigSnapshotNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateConfigRestore,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = app.RestoreConfigSnapshot(ctx, a, snapshot, evt)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppConfigSnapshotList(c *check.C) {
+	a := appTypes.App{Name: "swift", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "MODE", Value: "prod", Public: true},
+			{Name: "PASSWORD", Value: "secret"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/swift/config/snapshots", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var snapshots []app.ConfigSnapshot
+	err = json.NewDecoder(recorder.Body).Decode(&snapshots)
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 2)
+	c.Assert(snapshots[0].Env["MODE"].Value, check.Equals, "prod")
+	c.Assert(snapshots[0].Env["PASSWORD"].Value, check.Equals, app.SuppressedEnv)
+}
+
+func (s *S) TestAppConfigRestore(c *check.C) {
+	a := appTypes.App{Name: "swift", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "old"}},
+	})
+	c.Assert(err, check.IsNil)
+	snapshots, err := app.ListConfigSnapshots(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "new"}},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/swift/config/restore?snapshot="+snapshots[0].ID.Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, "(?s).*Restoring configuration from snapshot.*")
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["MODE"].Value, check.Equals, "old")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.config.restore",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppConfigRestoreSnapshotNotFound(c *check.C) {
+	a := appTypes.App{Name: "swift", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/swift/config/restore?snapshot="+primitive.NewObjectID().Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrConfigSnapshotNotFound.Error()+"\n")
+}
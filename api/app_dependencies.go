@@ -0,0 +1,69 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app dependencies
+// path: /apps/{app}/dependencies
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func appDependencies(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppRead,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	graph, err := app.Dependencies(ctx, a)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(graph)
+}
+
+// title: service instance dependents
+// path: /services/{service}/instances/{instance}/dependents
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Service instance not found
+func serviceInstanceDependents(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	serviceName := r.URL.Query().Get(":service")
+	si, err := getServiceInstanceOrError(ctx, serviceName, r.URL.Query().Get(":instance"))
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermServiceInstanceRead,
+		contextsForServiceInstance(si, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(app.ServiceInstanceDependents(si))
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppDependencies(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), service.ServiceInstance{
+		ServiceName: "mysql",
+		Name:        "shared-db",
+		Teams:       []string{s.team.Name},
+		Apps:        []string{"myapp", "otherapp"},
+	})
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.25/apps/myapp/dependencies", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var graph app.DependencyGraph
+	err = json.Unmarshal(recorder.Body.Bytes(), &graph)
+	c.Assert(err, check.IsNil)
+	c.Assert(graph.Nodes, check.HasLen, 3)
+	c.Assert(graph.Edges, check.DeepEquals, []app.DependencyEdge{
+		{From: "app:myapp", To: "service-instance:mysql/shared-db"},
+		{From: "app:otherapp", To: "service-instance:mysql/shared-db"},
+	})
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/services/mysql/instances/shared-db/dependents", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	err = json.Unmarshal(recorder.Body.Bytes(), &graph)
+	c.Assert(err, check.IsNil)
+	c.Assert(graph.Edges, check.HasLen, 2)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/services/mysql/instances/unknown/dependents", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
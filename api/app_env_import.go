@@ -0,0 +1,194 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/policy"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+// title: export envs
+// path: /apps/{app}/env/export
+// method: GET
+// produce: application/json, text/plain
+// responses:
+//
+//	200: OK
+//	400: Invalid format
+//	401: Unauthorized
+//	404: App not found
+func exportAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "dotenv" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: `format must be "json" or "dotenv"`}
+	}
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadEnv,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	// only the envs set in the app itself are exported, the ones coming from
+	// service binds and teams can't be imported back.
+	envs := make([]bindTypes.EnvVar, 0, len(a.Env))
+	for _, env := range a.Env {
+		envs = append(envs, env)
+	}
+	if format == "dotenv" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", a.Name+".env"))
+		return app.FormatDotenv(w, envs)
+	}
+	result := make([]apiTypes.Env, 0, len(envs))
+	for _, env := range envs {
+		private := !env.Public
+		result = append(result, apiTypes.Env{
+			Name:    env.Name,
+			Value:   env.Value,
+			Alias:   env.Alias,
+			Private: &private,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: import envs
+// path: /apps/{app}/env/import
+// method: POST
+// consume: application/json
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Envs imported
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Denied by admission policies
+//	404: App not found
+func importAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var e apiTypes.EnvsImport
+	err = ParseJSON(r, &e)
+	if err != nil {
+		return err
+	}
+	if e.Mode != "" && e.Mode != app.EnvImportMerge && e.Mode != app.EnvImportReplace {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid import mode %q, must be %q or %q", e.Mode, app.EnvImportMerge, app.EnvImportReplace)}
+	}
+	if len(e.Envs) > 0 && e.Dotenv != "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "You must provide either the list of environment variables or a dotenv file, not both"}
+	}
+	if e.Dotenv != "" {
+		parsed, parseErr := app.ParseDotenv(e.Dotenv)
+		if parseErr != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: parseErr.Error()}
+		}
+		for _, env := range parsed {
+			e.Envs = append(e.Envs, apiTypes.Env{Name: env.Name, Value: env.Value})
+		}
+	}
+	if len(e.Envs) == 0 && e.Mode != app.EnvImportReplace {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the list of environment variables"}
+	}
+	if err = validateApiEnvVars(e.Envs); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("There were errors validating environment variables: %s", err)}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateEnvSet,
+		contextsForApp(a)...,
+	)
+	if allowed && e.Mode == app.EnvImportReplace {
+		allowed = permission.Check(ctx, t, permission.PermAppUpdateEnvUnset,
+			contextsForApp(a)...,
+		)
+	}
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	variables := make([]bindTypes.EnvVar, 0, len(e.Envs))
+	policyEnvs := make([]map[string]interface{}, 0, len(e.Envs))
+	names := make([]string, 0, len(e.Envs))
+	for _, v := range e.Envs {
+		private := e.Private || (v.Private != nil && *v.Private)
+		variables = append(variables, bindTypes.EnvVar{
+			Name:   v.Name,
+			Value:  v.Value,
+			Alias:  v.Alias,
+			Public: !private,
+		})
+		policyEnvs = append(policyEnvs, map[string]interface{}{
+			"name":    v.Name,
+			"private": private,
+		})
+		names = append(names, v.Name)
+	}
+	err = checkAdmissionPolicies(ctx, t, policy.OperationAppEnvSet, a, map[string]interface{}{
+		"envs": policyEnvs,
+	})
+	if err != nil {
+		return err
+	}
+	// values are never recorded in the event, as imports usually carry
+	// private envs.
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateEnvSet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(url.Values{
+			"name":      names,
+			"mode":      {e.Mode},
+			"dryRun":    {strconv.FormatBool(e.DryRun)},
+			"noRestart": {strconv.FormatBool(e.NoRestart)},
+		}),
+		Allowed: event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	_, err = app.ImportEnvs(ctx, a, app.ImportEnvsArgs{
+		Envs:          variables,
+		Mode:          e.Mode,
+		DryRun:        e.DryRun,
+		ShouldRestart: !e.NoRestart,
+		Writer:        evt,
+	})
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
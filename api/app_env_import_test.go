@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestExportAppEnv(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "DATABASE_HOST", Value: "localhost", Public: true},
+			{Name: "DATABASE_PASSWORD", Value: "secret"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.25/apps/myapp/env/export?format=dotenv", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/plain")
+	c.Assert(recorder.Body.String(), check.Equals, "DATABASE_HOST=\"localhost\"\nDATABASE_PASSWORD=\"secret\"\n")
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/env/export", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var envs []apiTypes.Env
+	err = json.Unmarshal(recorder.Body.Bytes(), &envs)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.HasLen, 2)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/env/export?format=yaml", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestImportAppEnv(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "DATABASE_HOST", Value: "localhost", Public: true},
+			{Name: "LOG_LEVEL", Value: "info", Public: true},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	body := `{"dotenv": "DATABASE_HOST=db.example.com\nDATABASE_PASSWORD=secret\n", "mode": "replace", "private": true, "noRestart": true}`
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/env/import", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Importing 3 changes to environment variables.*`)
+	c.Assert(strings.Contains(recorder.Body.String(), "secret"), check.Equals, false)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.DeepEquals, map[string]bindTypes.EnvVar{
+		"DATABASE_HOST":     {Name: "DATABASE_HOST", Value: "db.example.com"},
+		"DATABASE_PASSWORD": {Name: "DATABASE_PASSWORD", Value: "secret"},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.env.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": []interface{}{"DATABASE_HOST", "DATABASE_PASSWORD"}},
+			{"name": "mode", "value": "replace"},
+			{"name": "dryRun", "value": "false"},
+			{"name": "noRestart", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestImportAppEnvDryRun(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := `{"envs": [{"Name": "DATABASE_HOST", "Value": "localhost"}], "dryRun": true}`
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/env/import", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*dry run, nothing applied.*\+ DATABASE_HOST.*`)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, 0)
+}
+
+func (s *S) TestImportAppEnvInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		body    string
+		message string
+	}{
+		{`{"dotenv": "DATABASE_HOST"}`, "invalid dotenv line 1: missing \"=\"\n"},
+		{`{"envs": [{"Name": "TSURU_APPNAME", "Value": "x"}]}`, ".*cannot change an internal environment variable.*"},
+		{`{"envs": [{"Name": "A", "Value": "1"}], "mode": "overwrite"}`, "invalid import mode \"overwrite\", must be \"merge\" or \"replace\"\n"},
+		{`{}`, "You must provide the list of environment variables\n"},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/1.25/apps/myapp/env/import", strings.NewReader(tt.body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %s", tt.body))
+		c.Assert(recorder.Body.String(), check.Matches, tt.message)
+	}
+}
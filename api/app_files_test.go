@@ -0,0 +1,96 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func newAppFileRequest(c *check.C, appName, path, content string, secret bool) *http.Request {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("path", path)
+	if secret {
+		writer.WriteField("secret", "true")
+	}
+	writer.WriteField("noRestart", "true")
+	fileWriter, err := writer.CreateFormFile("file", "file")
+	c.Assert(err, check.IsNil)
+	fileWriter.Write([]byte(content))
+	writer.Close()
+	request, err := http.NewRequest("POST", "/1.25/apps/"+appName+"/files", &buf)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func (s *S) TestAppFiles(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	for _, f := range []appTypes.FileMount{
+		{Path: "/etc/app/config.yaml", Content: []byte("debug: true")},
+		{Path: "/etc/app/token", Content: []byte("abc"), Secret: true},
+	} {
+		recorder := httptest.NewRecorder()
+		request := newAppFileRequest(c, "myapp", f.Path, string(f.Content), f.Secret)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	}
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Files, check.DeepEquals, []appTypes.FileMount{
+		{Path: "/etc/app/config.yaml", Content: []byte("debug: true")},
+		{Path: "/etc/app/token", Content: []byte("abc"), Secret: true},
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.25/apps/myapp/files", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var files []appTypes.FileMountInfo
+	err = json.Unmarshal(recorder.Body.Bytes(), &files)
+	c.Assert(err, check.IsNil)
+	c.Assert(files, check.DeepEquals, []appTypes.FileMountInfo{
+		dbApp.Files[0].Info(),
+		dbApp.Files[1].Info(),
+	})
+	c.Assert(files[1].Size, check.Equals, 3)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/files?path=/etc/app/token&noRestart=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/files?path=/etc/app/token", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSetAppFileInvalidPath(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request := newAppFileRequest(c, "myapp", "config.yaml", "debug: true", false)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid file path \"config.yaml\", it must be an absolute path to a file\n")
+}
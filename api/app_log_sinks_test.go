@@ -0,0 +1,106 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppLogSinks(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader("name=events&type=kafka&url=http://kafka-rest:8082&topic=app-logs")
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/log-sinks", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.log-sinks.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "myapp"},
+			{"name": "name", "value": "events"},
+			{"name": "type", "value": "kafka"},
+			{"name": "topic", "value": "app-logs"},
+		},
+	}, eventtest.HasEvent)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/log-sinks", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var sinks []appTypes.LogSink
+	err = json.NewDecoder(recorder.Body).Decode(&sinks)
+	c.Assert(err, check.IsNil)
+	c.Assert(sinks, check.DeepEquals, []appTypes.LogSink{
+		{Name: "events", Type: appTypes.LogSinkTypeKafka, URL: "http://kafka-rest:8082", Topic: "app-logs"},
+	})
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/log-sinks/events", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.LogSinks, check.HasLen, 0)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/log-sinks/events", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppSetLogSinkInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/log-sinks", strings.NewReader("name=syslog&type=syslog&url=http://logs.example.com"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "syslog log sink url must use the udp or tcp scheme\n")
+}
+
+func (s *S) TestAppSetLogSinkUnauthorized(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadLog,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/log-sinks", strings.NewReader("name=collector&type=http&url=http://collector"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppMaintenance(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/maintenance", strings.NewReader("redirectURL=https://status.example.com"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(routertest.FakeRouter.BackendOpts["myapp"].Maintenance, check.NotNil)
+	c.Assert(routertest.FakeRouter.BackendOpts["myapp"].Maintenance.RedirectURL, check.Equals, "https://status.example.com")
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Maintenance, check.NotNil)
+	c.Assert(dbApp.Maintenance.StartedBy, check.Equals, s.token.GetUserName())
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.maintenance.enable",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "myapp"},
+			{"name": "redirectURL", "value": "https://status.example.com"},
+		},
+	}, eventtest.HasEvent)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/maintenance", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(routertest.FakeRouter.BackendOpts["myapp"].Maintenance, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target:          appTarget("myapp"),
+		Owner:           s.token.GetUserName(),
+		Kind:            "app.update.maintenance.disable",
+		StartCustomData: []map[string]interface{}{{"name": ":app", "value": "myapp"}},
+	}, eventtest.HasEvent)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/maintenance", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrAppNotInMaintenance.Error()+"\n")
+}
+
+func (s *S) TestAppMaintenanceInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/maintenance", strings.NewReader("redirectURL=/status"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "maintenance redirect must be an absolute http or https URL\n")
+}
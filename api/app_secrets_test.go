@@ -0,0 +1,100 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppSecrets(c *check.C) {
+	config.Set("secrets:master-key", "a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2s=")
+	defer config.Unset("secrets")
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"secrets": {"DB_PASSWORD": "abc"}, "noRestart": true}`)
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/secrets", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.secrets.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "DB_PASSWORD"},
+			{"name": "noRestart", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/secrets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(strings.Contains(recorder.Body.String(), "abc"), check.Equals, false)
+	var secrets []appTypes.AppSecret
+	err = json.Unmarshal(recorder.Body.Bytes(), &secrets)
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.HasLen, 1)
+	c.Assert(secrets[0].Name, check.Equals, "DB_PASSWORD")
+	c.Assert(secrets[0].UpdatedBy, check.Equals, s.token.GetUserName())
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/secrets/DB_PASSWORD", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "{\"name\":\"DB_PASSWORD\",\"value\":\"abc\"}\n")
+	c.Assert(eventtest.EventDesc{
+		Target:          appTarget("myapp"),
+		Owner:           s.token.GetUserName(),
+		Kind:            "app.read.secret-values",
+		StartCustomData: []map[string]interface{}{{"name": "name", "value": "DB_PASSWORD"}},
+	}, eventtest.HasEvent)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/secrets?name=DB_PASSWORD&noRestart=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/secrets/DB_PASSWORD", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSetAppSecretsWithoutMasterKey(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"secrets": {"DB_PASSWORD": "abc"}}`)
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/secrets", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "secrets:master-key is not configured, app secrets are disabled\n")
+}
@@ -209,6 +209,51 @@ func (s *S) TestAppListFilteringByTags(c *check.C) {
 	c.Assert(apps[0].Tags, check.DeepEquals, app1.Tags)
 }
 
+func (s *S) TestAppListTagPermission(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTag, "env:prod"),
+	})
+	app1 := appTypes.App{Name: "app1", TeamOwner: s.team.Name, Tags: []string{"env:prod"}}
+	err := app.CreateApp(context.TODO(), &app1, s.user)
+	c.Assert(err, check.IsNil)
+	app2 := appTypes.App{Name: "app2", TeamOwner: s.team.Name, Tags: []string{"env:dev"}}
+	err = app.CreateApp(context.TODO(), &app2, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	apps := []appTypes.App{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &apps)
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 1)
+	c.Assert(apps[0].Name, check.Equals, app1.Name)
+	for name, code := range map[string]int{app1.Name: http.StatusOK, app2.Name: http.StatusForbidden} {
+		request, err = http.NewRequest("GET", "/apps/"+name, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "b "+token.GetValue())
+		recorder = httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, code, check.Commentf("app %s", name))
+	}
+}
+
+func (s *S) TestCreateAppInvalidTags(c *check.C) {
+	s.setupMockForCreateApp(c, "zend")
+	data := "name=someapp&platform=zend&teamOwner=" + s.team.Name + "&tag=env:prod&tag=env:dev"
+	request, err := http.NewRequest("POST", "/apps", strings.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `tags "env:prod" and "env:dev" have the same key.*\n`)
+}
+
 func (s *S) TestAppListFilteringByLockState(c *check.C) {
 	ctx := context.Background()
 	app1 := appTypes.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
@@ -809,6 +854,156 @@ func (s *S) TestDelete(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestDeleteWithDeletionProtection(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapptodelete", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetDeletionProtection(ctx, myApp, true)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/apps/"+myApp.Name, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrDeletionProtected.Error()+"\n")
+	_, err = app.GetByName(ctx, myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target:       appTarget(myApp.Name),
+		Owner:        s.token.GetUserName(),
+		Kind:         "app.delete",
+		ErrorMatches: ".*protected against deletion.*",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": myApp.Name},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppDeletionProtectionEnableAndDisable(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/myapp/deletion-protection", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(ctx, myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeletionProtection, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(myApp.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.deletion-protection",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("DELETE", "/apps/myapp/deletion-protection", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err = app.GetByName(ctx, myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeletionProtection, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(myApp.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.admin.deletion-protection",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppDeletionProtectionDisableRequiresDedicatedPermission(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetDeletionProtection(ctx, myApp, true)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, myApp.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppDelete,
+		Context: permission.Context(permTypes.CtxApp, myApp.Name),
+	})
+	request, err := http.NewRequest("DELETE", "/apps/myapp/deletion-protection", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	dbApp, err := app.GetByName(ctx, myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeletionProtection, check.Equals, true)
+}
+
+func (s *S) TestSetAppServiceAccount(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"annotations":{"eks.amazonaws.com/role-arn":"arn:aws:iam::123456789012:role/myapp"}}`)
+	request, err := http.NewRequest("PUT", "/apps/myapp/service-account", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(ctx, myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ServiceAccountAnnotations, check.DeepEquals, map[string]string{
+		"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/myapp",
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(myApp.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.service-account",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetAppServiceAccountInvalidAnnotation(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"annotations":{"example.com/token":"secret"}}`)
+	request, err := http.NewRequest("PUT", "/apps/myapp/service-account", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s)annotation "example.com/token" is not allowed in service accounts.*`)
+	dbApp, err := app.GetByName(ctx, myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ServiceAccountAnnotations, check.IsNil)
+}
+
+func (s *S) TestSetAppServiceAccountWithoutPermission(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateMetadata,
+		Context: permission.Context(permTypes.CtxApp, myApp.Name),
+	})
+	body := strings.NewReader(`{"annotations":{"iam.gke.io/gcp-service-account":"myapp@myproject.iam.gserviceaccount.com"}}`)
+	request, err := http.NewRequest("PUT", "/apps/myapp/service-account", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *S) TestDeleteVersion(c *check.C) {
 	ctx := context.TODO()
 	myApp := &appTypes.App{
@@ -1970,6 +2165,59 @@ func (s *S) TestUpdateAppWithTagsWithoutPermission(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
 }
 
+func (s *S) TestUpdateAppWithTagUsedAsPermissionContextWithoutPermission(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	permissiontest.CustomUserWithPermission(c, nativeScheme, "prodeployer", permTypes.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxTag, "env:prod"),
+	})
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	b := strings.NewReader("tag=env:prod")
+	request, err := http.NewRequest("PUT", "/apps/myapp", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	gotApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(gotApp.Tags, check.HasLen, 0)
+}
+
+func (s *S) TestUpdateAppWithTagUsedAsPermissionContext(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	permissiontest.CustomUserWithPermission(c, nativeScheme, "prodeployer", permTypes.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxTag, "env:prod"),
+	})
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateTags,
+		Context: permission.Context(permTypes.CtxTag, "env:prod"),
+	})
+	b := strings.NewReader("tag=env:prod")
+	request, err := http.NewRequest("PUT", "/apps/myapp", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	gotApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(gotApp.Tags, check.DeepEquals, []string{"env:prod"})
+}
+
 func (s *S) TestUpdateAppWithAnnotations(c *check.C) {
 	a := appTypes.App{
 		Name:      "myapp",
@@ -2015,6 +2263,85 @@ func (s *S) TestUpdateAppWithAnnotations(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestUpdateAppWithSecurityContext(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	b := strings.NewReader(`{"securityContext":{"runAsNonRoot":false,"seccompProfile":"Unconfined"}}`)
+	request, err := http.NewRequest("PUT", "/apps/myapp", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	runAsNonRoot := false
+	c.Assert(dbApp.SecurityContext, check.DeepEquals, &appTypes.SecurityContext{
+		RunAsNonRoot:   &runAsNonRoot,
+		SeccompProfile: "Unconfined",
+	})
+}
+
+func (s *S) TestUpdateAppWithSecurityContextRequiresGlobalPermission(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	b := strings.NewReader(`{"securityContext":{"runAsNonRoot":false}}`)
+	request, err := http.NewRequest("PUT", "/apps/myapp", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.SecurityContext, check.IsNil)
+}
+
+func (s *S) TestUpdateAppWithEgressAllowRequiresAdminPermission(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	body := `{"metadata":{"annotations":[{"name":"app.tsuru.io/egress-allow","value":"0.0.0.0/0"}]},"noRestart":true}`
+	request, err := http.NewRequest("PUT", "/apps/myapp", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	token = userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppAdminEgress,
+		Context: permission.Context(permTypes.CtxPool, a.Pool),
+	})
+	request, err = http.NewRequest("PUT", "/apps/myapp", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Metadata.Annotations, check.DeepEquals, []appTypes.MetadataItem{
+		{Name: appTypes.EgressAllowAnnotation, Value: "0.0.0.0/0"},
+	})
+}
+
 func (s *S) TestUpdateAppWithLabels(c *check.C) {
 	a := appTypes.App{
 		Name:      "myapp",
@@ -3161,55 +3488,106 @@ func (s *S) TestRunOnce(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
-func (s *S) TestRun(c *check.C) {
-	ctx := context.Background()
-	s.provisioner.PrepareOutput([]byte("lots of\nfiles"))
+func (s *S) TestRunTask(c *check.C) {
+	s.provisioner.PrepareOutput([]byte("migrations applied"))
 	a := appTypes.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &a, s.user)
 	c.Assert(err, check.IsNil)
-	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
-	url := fmt.Sprintf("/apps/%s/run", a.Name)
-	request, err := http.NewRequest("POST", url, strings.NewReader("command=ls"))
+	url := fmt.Sprintf("/apps/%s/tasks", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("command=./migrate&timeout=600&cpumilli=250&memory=268435456"))
 	c.Assert(err, check.IsNil)
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	request.Header.Set("Authorization", "b "+s.token.GetValue())
 	recorder := httptest.NewRecorder()
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
-	c.Assert(recorder.Body.String(), check.Matches, `{"Message":"lots of\\nfiles","Timestamp":".*"}`+"\n")
-	expected := "[ -f /home/application/apprc ] && source /home/application/apprc;"
-	expected += " [ -d /home/application/current ] && cd /home/application/current;"
-	expected += " ls"
-	units, err := app.AppUnits(ctx, &a)
-	c.Assert(err, check.IsNil)
-	c.Assert(units, check.HasLen, 1)
-	allExecs := s.provisioner.AllExecs()
-	c.Assert(allExecs, check.HasLen, 1)
-	c.Assert(allExecs[units[0].GetID()], check.HasLen, 1)
-	c.Assert(allExecs[units[0].GetID()][0].Cmds, check.DeepEquals, []string{"/bin/sh", "-c", expected})
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `{"Message":"migrations applied","Timestamp":".*"}`+"\n")
+	tasks := s.provisioner.Tasks(&a)
+	c.Assert(tasks, check.HasLen, 1)
+	c.Assert(tasks[0].Timeout, check.Equals, 10*time.Minute)
+	c.Assert(tasks[0].CPUMilli, check.Equals, 250)
+	c.Assert(tasks[0].Memory, check.Equals, int64(268435456))
 	c.Assert(eventtest.EventDesc{
 		Target: appTarget(a.Name),
 		Owner:  s.token.GetUserName(),
-		Kind:   "app.run",
+		Kind:   "app.run.task",
 		StartCustomData: []map[string]interface{}{
-			{"name": "command", "value": "ls"},
+			{"name": "command", "value": "./migrate"},
+			{"name": "timeout", "value": "600"},
+			{"name": "cpumilli", "value": "250"},
+			{"name": "memory", "value": "268435456"},
 			{"name": ":app", "value": a.Name},
 		},
 	}, eventtest.HasEvent)
 }
 
-func (s *S) TestRunIsolated(c *check.C) {
-	s.provisioner.PrepareOutput([]byte("lots of files"))
+func (s *S) TestRunTaskInvalidArgs(c *check.C) {
 	a := appTypes.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &a, s.user)
 	c.Assert(err, check.IsNil)
-	s.provisioner.AddUnits(context.TODO(), &a, 3, "web", nil, nil)
-	url := fmt.Sprintf("/apps/%s/run", a.Name)
-	request, err := http.NewRequest("POST", url, strings.NewReader("command=ls&isolated=true"))
-	c.Assert(err, check.IsNil)
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	request.Header.Set("Authorization", "b "+s.token.GetValue())
-	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/apps/%s/tasks", a.Name)
+	for _, body := range []string{"timeout=10", "command=ls&timeout=abc", "command=ls&timeout=-1", "command=ls&memory=-1"} {
+		request, err := http.NewRequest("POST", url, strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "b "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf(body))
+	}
+	c.Assert(s.provisioner.Tasks(&a), check.HasLen, 0)
+}
+
+func (s *S) TestRun(c *check.C) {
+	ctx := context.Background()
+	s.provisioner.PrepareOutput([]byte("lots of\nfiles"))
+	a := appTypes.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	url := fmt.Sprintf("/apps/%s/run", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("command=ls"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `{"Message":"lots of\\nfiles","Timestamp":".*"}`+"\n")
+	expected := "[ -f /home/application/apprc ] && source /home/application/apprc;"
+	expected += " [ -d /home/application/current ] && cd /home/application/current;"
+	expected += " ls"
+	units, err := app.AppUnits(ctx, &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 1)
+	allExecs := s.provisioner.AllExecs()
+	c.Assert(allExecs, check.HasLen, 1)
+	c.Assert(allExecs[units[0].GetID()], check.HasLen, 1)
+	c.Assert(allExecs[units[0].GetID()][0].Cmds, check.DeepEquals, []string{"/bin/sh", "-c", expected})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.run",
+		StartCustomData: []map[string]interface{}{
+			{"name": "command", "value": "ls"},
+			{"name": ":app", "value": a.Name},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestRunIsolated(c *check.C) {
+	s.provisioner.PrepareOutput([]byte("lots of files"))
+	a := appTypes.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 3, "web", nil, nil)
+	url := fmt.Sprintf("/apps/%s/run", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("command=ls&isolated=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
@@ -4064,6 +4442,68 @@ func (s *S) TestSetEnvHandlerNoRestart(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestSetEnvHandlerVersion(c *check.C) {
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	canary := newSuccessfulAppVersion(c, &a)
+	d := apiTypes.Envs{
+		Envs: []apiTypes.Env{
+			{Name: "DATABASE_HOST", Value: "canary"},
+		},
+		Version: canary.Version(),
+	}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/env", a.Name), strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches,
+		`{"Message":".*---- Setting 1 new environment variables to version 2 ----\\n","Timestamp":".*"}
+`)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	_, ok := dbApp.Env["DATABASE_HOST"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(dbApp.VersionEnvs, check.DeepEquals, []bindTypes.VersionEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "DATABASE_HOST", Value: "canary", Public: true}, Version: 2},
+	})
+	request, err = http.NewRequest("GET", fmt.Sprintf("/apps/%s/env?version=2&env=DATABASE_HOST", a.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var envs []bindTypes.EnvVar
+	err = json.Unmarshal(recorder.Body.Bytes(), &envs)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []bindTypes.EnvVar{{Name: "DATABASE_HOST", Value: "canary", Public: true}})
+}
+
+func (s *S) TestSetEnvHandlerInvalidVersion(c *check.C) {
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	d := apiTypes.Envs{
+		Envs:    []apiTypes.Env{{Name: "DATABASE_HOST", Value: "canary"}},
+		Version: 5,
+	}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/env", a.Name), strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
 func (s *S) TestSetEnvMissingFormBody(c *check.C) {
 	a := appTypes.App{Name: "rock", Platform: "zend"}
 	appsCollection, err := storagev2.AppsCollection()
@@ -4249,6 +4689,98 @@ func (s *S) TestUnsetEnv(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestUnsetEnvEnvSchemaRequired(c *check.C) {
+	a := appTypes.App{
+		Name:      "swift",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"DATABASE_URL": {Name: "DATABASE_URL", Value: "mysql://db/swift", Public: true},
+		},
+		EnvSchema: []appTypes.EnvVarSchema{{Name: "DATABASE_URL", Required: true}},
+	}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/apps/swift/env?env=DATABASE_URL", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "environment variables do not match the app schema: DATABASE_URL is required\n")
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, 1)
+}
+
+func (s *S) TestSetAppEnvSchema(c *check.C) {
+	a := appTypes.App{Name: "swift", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`[{"name":"DATABASE_URL","required":true,"type":"url"},{"name":"WORKERS","type":"int"}]`)
+	request, err := http.NewRequest("PUT", "/apps/swift/env/schema", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := []appTypes.EnvVarSchema{
+		{Name: "DATABASE_URL", Required: true, Type: appTypes.EnvTypeURL},
+		{Name: "WORKERS", Type: appTypes.EnvTypeInt},
+	}
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.EnvSchema, check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.env.schema",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/apps/swift/env/schema", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var schema []appTypes.EnvVarSchema
+	err = json.NewDecoder(recorder.Body).Decode(&schema)
+	c.Assert(err, check.IsNil)
+	c.Assert(schema, check.DeepEquals, expected)
+}
+
+func (s *S) TestSetAppEnvSchemaInvalid(c *check.C) {
+	a := appTypes.App{Name: "swift", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`[{"name":"WORKERS","type":"integer"}]`)
+	request, err := http.NewRequest("PUT", "/apps/swift/env/schema", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, `invalid type "integer" for environment variable "WORKERS"`+"\n")
+}
+
+func (s *S) TestSetAppEnvSchemaRequiresPermission(c *check.C) {
+	a := appTypes.App{Name: "swift", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateEnvSet,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("PUT", "/apps/swift/env/schema", strings.NewReader(`[]`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *S) TestUnsetEnvNoRestart(c *check.C) {
 	a := appTypes.App{
 		Name:     "swift",
@@ -4293,6 +4825,56 @@ func (s *S) TestUnsetEnvNoRestart(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestPromoteAppEnv(c *check.C) {
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	canary := newSuccessfulAppVersion(c, &a)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs:    []bindTypes.EnvVar{{Name: "DATABASE_HOST", Value: "canary"}},
+		Version: canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("version=2")
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/env/promote", a.Name), body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches,
+		`{"Message":".*---- Promoting 1 environment variables from version 2 to all versions ----\\n","Timestamp":".*"}
+`)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.VersionEnvs, check.HasLen, 0)
+	c.Assert(dbApp.Env["DATABASE_HOST"], check.DeepEquals, bindTypes.EnvVar{Name: "DATABASE_HOST", Value: "canary"})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.env.promote",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": a.Name},
+			{"name": "version", "value": "2"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPromoteAppEnvNothingToPromote(c *check.C) {
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/env/promote", a.Name), strings.NewReader("version=1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrNoVersionEnvs.Error()+"\n")
+}
 func (s *S) TestUnsetEnvHandlerRemovesAllGivenEnvironmentVariables(c *check.C) {
 	a := appTypes.App{
 		Name:     "let-it-be",
@@ -5007,6 +5589,62 @@ func (s *S) TestAppLogSelectByUnit(c *check.C) {
 	c.Assert(logs[1].Unit, check.Equals, "caliban")
 }
 
+func (s *S) TestAppLogSearch(c *check.C) {
+	a := appTypes.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	servicemanager.LogService.Add(a.Name, "ERROR: connection refused", "web", "")
+	servicemanager.LogService.Add(a.Name, "connection established", "web", "")
+	servicemanager.LogService.Add(a.Name, "error reading config", "worker", "")
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadLog,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	url := fmt.Sprintf("/apps/%s/log/?:app=%s&query=connection&severity=error&lines=10", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request.Header.Set("Content-Type", "application/json")
+	err = appLog(recorder, request, token)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	logs := []appTypes.Applog{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &logs)
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, "ERROR: connection refused")
+}
+
+func (s *S) TestAppLogSearchInvalidArgs(c *check.C) {
+	a := appTypes.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadLog,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"severity=loud", `invalid log severity "loud", must be one of: debug, info, warning, error`},
+		{"since=yesterday", `invalid since value "yesterday"`},
+		{"since=2026-02-01T00:00:00Z&until=2026-01-01T00:00:00Z", "the end of the log time range must be after its start"},
+	}
+	for _, tt := range tests {
+		url := fmt.Sprintf("/apps/%s/log/?:app=%s&lines=10&%s", a.Name, a.Name, tt.query)
+		request, err := http.NewRequest("GET", url, nil)
+		c.Assert(err, check.IsNil)
+		recorder := httptest.NewRecorder()
+		err = appLog(recorder, request, token)
+		c.Assert(err, check.NotNil)
+		e, ok := err.(*errors.HTTP)
+		c.Assert(ok, check.Equals, true)
+		c.Assert(e.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(strings.HasPrefix(e.Message, tt.expected), check.Equals, true, check.Commentf("message: %s", e.Message))
+	}
+}
+
 func (s *S) TestAppLogSelectByLinesShouldReturnTheLatestEntries(c *check.C) {
 	a := appTypes.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &a, s.user)
@@ -6745,3 +7383,168 @@ func (s *S) TestFollowLogs(c *check.C) {
 	c.Assert(msgSlice, check.HasLen, 1)
 	c.Assert(msgSlice[0].Message, check.Equals, "xyz")
 }
+
+func (s *S) TestUnitsHistory(c *check.C) {
+	ctx := context.TODO()
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.AddUnitStatusHistory(ctx, provTypes.UnitStatusHistory{
+		AppName:     a.Name,
+		Unit:        "myapp-web-1",
+		ProcessName: "web",
+		Status:      provTypes.UnitStatusError,
+		Restarts:    1,
+		OOMKilled:   true,
+	})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("GET", "/apps/myapp/units/history?process=web", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result unitsHistoryResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.History, check.HasLen, 1)
+	c.Assert(result.History[0].Unit, check.Equals, "myapp-web-1")
+	c.Assert(result.History[0].OOMKilled, check.Equals, true)
+	c.Assert(result.CrashLoops, check.HasLen, 1)
+	c.Assert(result.CrashLoops[0].Restarts, check.Equals, 1)
+	c.Assert(result.CrashLoops[0].CrashLoop, check.Equals, false)
+}
+
+func (s *S) TestUnitsHistoryInvalidSince(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/myapp/units/history?since=yesterday", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestUnitsHistoryForbidden(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, "-other-app-"),
+	})
+	request, err := http.NewRequest("GET", "/apps/myapp/units/history", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppHealth(c *check.C) {
+	ctx := context.TODO()
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.provisioner.AddUnits(ctx, &a, 2, "web", nil, nil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("GET", "/apps/myapp/health", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result app.AppHealth
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	var names []string
+	for _, hc := range result.Checks {
+		names = append(names, hc.Name)
+		if hc.Name == "units" {
+			c.Assert(hc.Status, check.Equals, app.HealthStatusHealthy)
+		}
+	}
+	c.Assert(names, check.DeepEquals, []string{"routers", "units", "autoscale", "certificates", "services"})
+}
+
+func (s *S) TestAppHealthNoUnits(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/myapp/health", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result app.AppHealth
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Status, check.Equals, app.HealthStatusUnhealthy)
+}
+
+func (s *S) TestAppHealthForbidden(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, "-other-app-"),
+	})
+	request, err := http.NewRequest("GET", "/apps/myapp/health", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppUnitsMetrics(c *check.C) {
+	ctx := context.TODO()
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.provisioner.AddUnits(ctx, &a, 1, "web", nil, nil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("GET", "/apps/myapp/metrics", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result []provTypes.UnitMetric
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, []provTypes.UnitMetric{
+		{ID: "myapp-0", ProcessName: "web", CPU: "10m", Memory: "100Mi"},
+	})
+}
+
+func (s *S) TestAppUnitsMetricsNoUnits(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/myapp/metrics", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
@@ -144,13 +144,52 @@ func login(w http.ResponseWriter, r *http.Request) (err error) {
 		if err != nil {
 			return handleAuthError(err)
 		}
-		return json.NewEncoder(w).Encode(map[string]string{"token": token.GetValue()})
+		return json.NewEncoder(w).Encode(tokenResponse(token))
 	}
 
 	w.WriteHeader(http.StatusNotImplemented)
 	return nil
 }
 
+// tokenResponse includes the refresh token issued along with the token, if
+// any.
+func tokenResponse(token auth.Token) map[string]string {
+	result := map[string]string{"token": token.GetValue()}
+	if refreshable, ok := token.(auth.RefreshableToken); ok && refreshable.GetRefreshToken() != "" {
+		result["refresh_token"] = refreshable.GetRefreshToken()
+	}
+	return result
+}
+
+// title: refresh token
+// path: /auth/refresh
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	501: Not implemented
+func refreshToken(w http.ResponseWriter, r *http.Request) (err error) {
+	refreshable, ok := app.AuthScheme.(auth.RefreshableScheme)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return nil
+	}
+	value := InputValue(r, "refresh_token")
+	if value == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "refresh_token is required"}
+	}
+	token, err := refreshable.Refresh(r.Context(), value)
+	if err != nil {
+		return handleAuthError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(tokenResponse(token))
+}
+
 // title: logout
 // path: /users/tokens
 // method: DELETE
@@ -277,6 +316,7 @@ var teamRenameFns = []func(ctx context.Context, oldName, newName string) error{
 //	400: Invalid data
 //	401: Unauthorized
 //	404: Team not found
+//	409: Team has apps or jobs in a dedicated namespace
 func updateTeam(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
 	name := r.URL.Query().Get(":name")
@@ -296,7 +336,7 @@ func updateTeam(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
-	_, err := servicemanager.Team.FindByName(ctx, name)
+	team, err := servicemanager.Team.FindByName(ctx, name)
 	if err != nil {
 		if err == authTypes.ErrTeamNotFound {
 			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
@@ -315,9 +355,22 @@ func updateTeam(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
+	ctx = event.WithParent(ctx, evt)
 	if changeRequest.NewName == "" {
 		return servicemanager.Team.Update(ctx, name, changeRequest.Tags)
 	}
+	var profile *authTypes.IsolationProfile
+	if team.IsolationProfile != "" {
+		// profiles removed from the config are handled as no profile.
+		profile, _ = auth.GetIsolationProfile(team.IsolationProfile)
+	}
+	if dedicatedNamespace(profile) {
+		// the dedicated namespace is named after the team.
+		err = ensureTeamHasNoWorkloads(ctx, name)
+		if err != nil {
+			return err
+		}
+	}
 	u, err := t.User(ctx)
 	if err != nil {
 		return err
@@ -343,6 +396,24 @@ func updateTeam(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 			}
 		}
 	}()
+	if len(team.Env) > 0 {
+		err = servicemanager.Team.SetEnvs(ctx, changeRequest.NewName, team.Env)
+		if err != nil {
+			return err
+		}
+	}
+	if profile != nil {
+		err = servicemanager.Team.SetIsolationProfile(ctx, changeRequest.NewName, profile.Name)
+		if err != nil {
+			return err
+		}
+	}
+	if team.Budget != nil {
+		err = servicemanager.Team.SetBudget(ctx, changeRequest.NewName, team.Budget)
+		if err != nil {
+			return err
+		}
+	}
 	for _, fn := range teamRenameFns {
 		err = fn(ctx, name, changeRequest.NewName)
 		if err != nil {
@@ -758,6 +829,9 @@ type apiUser struct {
 	Roles       []rolePermissionData
 	Permissions []rolePermissionData
 	Groups      []string
+	// DirectoryGroups are the groups synchronized from the external
+	// directory.
+	DirectoryGroups []string `json:",omitempty"`
 }
 
 func createAPIUser(ctx context.Context, perms []permTypes.Permission, user *auth.User, roleMap map[string]*permission.Role, includeAll bool) (*apiUser, error) {
@@ -767,9 +841,10 @@ func createAPIUser(ctx context.Context, perms []permTypes.Permission, user *auth
 	allGlobal := true
 
 	apiUsr := &apiUser{
-		Email:  user.Email,
-		Groups: user.Groups,
-		Roles:  make([]rolePermissionData, 0, len(user.Roles)),
+		Email:           user.Email,
+		Groups:          user.Groups,
+		DirectoryGroups: user.DirectoryGroups,
+		Roles:           make([]rolePermissionData, 0, len(user.Roles)),
 	}
 
 	for _, userRole := range user.Roles {
@@ -1073,6 +1148,114 @@ func teamGroupList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(result)
 }
 
+// teamGroupRole returns the role of a team group mapping, checking the team
+// exists and the role has the team context type.
+func teamGroupRole(ctx context.Context, teamName, roleName string) (permission.Role, error) {
+	_, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return permission.Role{}, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return permission.Role{}, err
+	}
+	if roleName == "" {
+		return permission.Role{}, &errors.HTTP{Code: http.StatusBadRequest, Message: "role is required"}
+	}
+	role, err := getRoleReturnNotFound(ctx, roleName)
+	if err != nil {
+		return permission.Role{}, err
+	}
+	if role.ContextType != permTypes.CtxTeam {
+		return permission.Role{}, &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("role %q has context type %s, only team roles can be mapped to team groups", role.Name, role.ContextType),
+		}
+	}
+	return role, nil
+}
+
+// title: add team group
+// path: /teams/{name}/groups
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team or role not found
+func addTeamGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleUpdateAssign) {
+		return permission.ErrUnauthorized
+	}
+	teamName := r.URL.Query().Get(":name")
+	groupName := InputValue(r, "group")
+	if groupName == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "group is required"}
+	}
+	role, err := teamGroupRole(ctx, teamName, InputValue(r, "role"))
+	if err != nil {
+		return err
+	}
+	err = canUseRole(ctx, t, role, teamName)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:       permission.PermRoleUpdateAssign,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return servicemanager.AuthGroup.AddRole(ctx, groupName, role.Name, teamName)
+}
+
+// title: remove team group
+// path: /teams/{name}/groups/{group}
+// method: DELETE
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team or role not found
+func removeTeamGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleUpdateDissociate) {
+		return permission.ErrUnauthorized
+	}
+	teamName := r.URL.Query().Get(":name")
+	groupName := r.URL.Query().Get(":group")
+	role, err := teamGroupRole(ctx, teamName, InputValue(r, "role"))
+	if err != nil {
+		return err
+	}
+	err = canUseRole(ctx, t, role, teamName)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:       permission.PermRoleUpdateDissociate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return servicemanager.AuthGroup.RemoveRole(ctx, groupName, role.Name, teamName)
+}
+
 type teamUserItem struct {
 	Email string   `json:"email"`
 	Roles []string `json:"roles"`
@@ -951,6 +951,89 @@ func (t TestScheme) Remove(ctx context.Context, u *auth.User) error {
 	return nil
 }
 
+type refreshableTestScheme struct {
+	TestScheme
+	refresh func(refreshToken string) (auth.Token, error)
+}
+
+func (t *refreshableTestScheme) Login(ctx context.Context, params map[string]string) (auth.Token, error) {
+	return &refreshableTestToken{value: "token01", refreshToken: "refresh01"}, nil
+}
+
+func (t *refreshableTestScheme) Refresh(ctx context.Context, refreshToken string) (auth.Token, error) {
+	return t.refresh(refreshToken)
+}
+
+type refreshableTestToken struct {
+	auth.Token
+	value        string
+	refreshToken string
+}
+
+func (t *refreshableTestToken) GetValue() string {
+	return t.value
+}
+
+func (t *refreshableTestToken) GetRefreshToken() string {
+	return t.refreshToken
+}
+
+func (s *AuthSuite) TestLoginWithRefreshToken(c *check.C) {
+	oldScheme := app.AuthScheme
+	defer func() { app.AuthScheme = oldScheme }()
+	app.AuthScheme = &refreshableTestScheme{}
+	request, err := http.NewRequest(http.MethodPost, "/auth/login", strings.NewReader("code=mycode&redirectUrl=http://localhost"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result map[string]string
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string]string{"token": "token01", "refresh_token": "refresh01"})
+}
+
+func (s *AuthSuite) TestRefreshToken(c *check.C) {
+	oldScheme := app.AuthScheme
+	defer func() { app.AuthScheme = oldScheme }()
+	app.AuthScheme = &refreshableTestScheme{
+		refresh: func(refreshToken string) (auth.Token, error) {
+			if refreshToken != "refresh01" {
+				return nil, auth.AuthenticationFailure{Message: "Invalid refresh token"}
+			}
+			return &refreshableTestToken{value: "token02", refreshToken: "refresh02"}, nil
+		},
+	}
+	request, err := http.NewRequest(http.MethodPost, "/1.25/auth/refresh", strings.NewReader("refresh_token=refresh01"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result map[string]string
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string]string{"token": "token02", "refresh_token": "refresh02"})
+	request, err = http.NewRequest(http.MethodPost, "/1.25/auth/refresh", strings.NewReader("refresh_token=refresh01-old"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+	c.Assert(recorder.Body.String(), check.Equals, "Invalid refresh token\n")
+}
+
+func (s *AuthSuite) TestRefreshTokenNotImplemented(c *check.C) {
+	request, err := http.NewRequest(http.MethodPost, "/1.25/auth/refresh", strings.NewReader("refresh_token=refresh01"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotImplemented)
+}
+
 func (s *AuthSuite) TestAuthScheme(c *check.C) {
 	oldScheme := app.AuthScheme
 	defer func() { app.AuthScheme = oldScheme }()
@@ -1586,6 +1669,50 @@ func (s *AuthSuite) TestUpdateTeam(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
 }
 
+func (s *AuthSuite) TestUpdateTeamKeepsIsolationProfile(c *check.C) {
+	config.Set("isolation-profiles:regulated:registry", "registry.example.com")
+	defer config.Unset("isolation-profiles")
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, IsolationProfile: "regulated"}, nil
+	}
+	var profiles [][]string
+	s.mockTeamService.OnSetIsolationProfile = func(name, profile string) error {
+		profiles = append(profiles, []string{name, profile})
+		return nil
+	}
+	body := strings.NewReader("newname=team9000")
+	request, err := http.NewRequest(http.MethodPost, "/teams/team1", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(profiles, check.DeepEquals, [][]string{{"team9000", "regulated"}})
+}
+
+func (s *AuthSuite) TestUpdateTeamKeepsBudget(c *check.C) {
+	budget := &quota.Budget{MonthlyLimit: 1000, Enforce: true}
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Budget: budget}, nil
+	}
+	var budgets []string
+	s.mockTeamService.OnSetBudget = func(name string, b *quota.Budget) error {
+		c.Assert(b, check.DeepEquals, budget)
+		budgets = append(budgets, name)
+		return nil
+	}
+	body := strings.NewReader("newname=team9000")
+	request, err := http.NewRequest(http.MethodPost, "/teams/team1", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(budgets, check.DeepEquals, []string{"team9000"})
+}
+
 func (s *AuthSuite) TestUpdateTeamNotFound(c *check.C) {
 	s.mockTeamService.OnFindByName = func(_ string) (*authTypes.Team, error) {
 		return nil, authTypes.ErrTeamNotFound
@@ -1833,3 +1960,96 @@ func (s *AuthSuite) TestTeamGroupsListNoTeam(c *check.C) {
 	err = teamGroupList(recorder, request, token)
 	c.Assert(err, check.DeepEquals, &errors.HTTP{Code: http.StatusNotFound, Message: "team not found"})
 }
+
+func (s *AuthSuite) TestAddTeamGroup(c *check.C) {
+	ctx := context.TODO()
+	teamName := "team-test"
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		c.Assert(name, check.Equals, teamName)
+		return &authTypes.Team{Name: name}, nil
+	}
+	role, err := permission.NewRole(ctx, "team-member", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app")
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("group=developers&role=team-member")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/teams/team-test/groups", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	groups, err := servicemanager.AuthGroup.List(ctx, []string{"developers"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, []authTypes.Group{
+		{Name: "developers", Roles: []authTypes.RoleInstance{{Name: "team-member", ContextValue: teamName}}},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(teamName),
+		Owner:  s.token.GetUserName(),
+		Kind:   "role.update.assign",
+		StartCustomData: []map[string]interface{}{
+			{"name": "group", "value": "developers"},
+			{"name": "role", "value": "team-member"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestAddTeamGroupNonTeamRole(c *check.C) {
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	_, err := permission.NewRole(context.TODO(), "app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("group=developers&role=app-admin")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/teams/team-test/groups", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "role \"app-admin\" has context type app, only team roles can be mapped to team groups\n")
+}
+
+func (s *AuthSuite) TestAddTeamGroupNoPerm(c *check.C) {
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	token := userWithPermission(c)
+	body := strings.NewReader("group=developers&role=team-member")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/teams/team-test/groups", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *AuthSuite) TestRemoveTeamGroup(c *check.C) {
+	ctx := context.TODO()
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	role, err := permission.NewRole(ctx, "team-member", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(ctx, "developers", "team-member", "team-test")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(ctx, "developers", "team-member", "other-team")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/1.25/teams/team-test/groups/developers?role=team-member", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	groups, err := servicemanager.AuthGroup.List(ctx, []string{"developers"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, []authTypes.Group{
+		{Name: "developers", Roles: []authTypes.RoleInstance{{Name: "team-member", ContextValue: "other-team"}}},
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+// title: enable app autoredeploy
+// path: /apps/{app}/autoredeploy
+// method: POST
+// responses:
+//
+//	200: Autoredeploy enabled
+//	401: Unauthorized
+//	404: App not found
+func appAutoRedeployEnable(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppAutoRedeploy(r, t, true)
+}
+
+// title: disable app autoredeploy
+// path: /apps/{app}/autoredeploy
+// method: DELETE
+// responses:
+//
+//	200: Autoredeploy disabled
+//	401: Unauthorized
+//	404: App not found
+func appAutoRedeployDisable(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppAutoRedeploy(r, t, false)
+}
+
+func setAppAutoRedeploy(r *http.Request, t auth.Token, enabled bool) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateAutoredeploy, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateAutoredeploy,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]bool{"enabled": enabled},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.SetAutoRedeploy(ctx, a, enabled)
+}
+
+// title: pending rebuild list
+// path: /autoredeploys
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+func listPendingRebuilds(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	pending, err := app.ListPendingRebuilds(ctx)
+	if err != nil {
+		return err
+	}
+	allowed := []app.PendingRebuild{}
+	for _, p := range pending {
+		a, err := app.GetByName(ctx, p.App)
+		if err == appTypes.ErrAppNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if permission.Check(ctx, t, permission.PermAppRead, contextsForApp(a)...) {
+			allowed = append(allowed, p)
+		}
+	}
+	if len(allowed) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(allowed)
+}
+
+// title: platform autoredeploy
+// path: /platforms/{name}/autoredeploy
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Not found
+func platformAutoRedeploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	name := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermPlatformUpdate) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePlatform, Value: name},
+		Kind:       permission.PermPlatformUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPlatformReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	pending, err := app.NotifyPlatformImageUpdate(ctx, name, InputValue(r, "version"), InputValue(r, "reason"))
+	if err == appTypes.ErrInvalidPlatform {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(pending)
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+// parseBudget reads the budget from the request body, a zero monthly limit
+// removes the current budget.
+func parseBudget(r *http.Request) (*quota.Budget, error) {
+	var budget quota.Budget
+	err := ParseJSON(r, &budget)
+	if err != nil {
+		return nil, err
+	}
+	if budget.MonthlyLimit == 0 {
+		return nil, nil
+	}
+	return &budget, nil
+}
+
+// title: get app budget
+// path: /apps/{app}/budget
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func getAppBudget(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppRead, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	status, err := app.AppBudgetStatus(ctx, a)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(status)
+}
+
+// title: set app budget
+// path: /apps/{app}/budget
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Budget updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func setAppBudget(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	budget, err := parseBudget(r)
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateBudget, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateBudget,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: budget,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.SetBudget(ctx, a, budget)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: get team budget
+// path: /teams/{name}/budget
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Team not found
+func getTeamBudget(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamReadBudget, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	status, err := app.TeamBudgetStatus(ctx, team)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(status)
+}
+
+// title: set team budget
+// path: /teams/{name}/budget
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Budget updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team not found
+func setTeamBudget(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	budget, err := parseBudget(r)
+	if err != nil {
+		return err
+	}
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateBudget, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     teamTarget(teamName),
+		Kind:       permission.PermTeamUpdateBudget,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: budget,
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Team.SetBudget(ctx, teamName, budget)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
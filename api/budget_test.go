@@ -0,0 +1,133 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetAppBudget(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"monthlyLimit":500,"enforce":true}`)
+	request, err := http.NewRequest(http.MethodPut, "/apps/myapp/budget", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Budget, check.DeepEquals, &quota.Budget{MonthlyLimit: 500, Enforce: true})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.budget",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest(http.MethodPut, "/apps/myapp/budget", strings.NewReader(`{"monthlyLimit":0}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err = app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Budget, check.IsNil)
+}
+
+func (s *S) TestSetAppBudgetInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPut, "/apps/myapp/budget", strings.NewReader(`{"monthlyLimit":-5}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "budget monthly limit must be greater than zero\n")
+}
+
+func (s *S) TestGetAppBudget(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetBudget(context.TODO(), &a, &quota.Budget{MonthlyLimit: 500})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/apps/myapp/budget", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var status quota.BudgetStatus
+	err = json.NewDecoder(recorder.Body).Decode(&status)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.DeepEquals, quota.BudgetStatus{Budget: &quota.Budget{MonthlyLimit: 500}})
+}
+
+func (s *S) TestSetTeamBudget(c *check.C) {
+	var budget *quota.Budget
+	s.mockService.Team.OnSetBudget = func(name string, b *quota.Budget) error {
+		c.Assert(name, check.Equals, s.team.Name)
+		budget = b
+		return nil
+	}
+	body := strings.NewReader(`{"monthlyLimit":1000}`)
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/budget", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(budget, check.DeepEquals, &quota.Budget{MonthlyLimit: 1000})
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(s.team.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.budget",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestGetTeamBudget(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name != s.team.Name {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return &authTypes.Team{Name: name, Budget: &quota.Budget{MonthlyLimit: 1000, Enforce: true}}, nil
+	}
+	request, err := http.NewRequest(http.MethodGet, "/teams/"+s.team.Name+"/budget", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var status quota.BudgetStatus
+	err = json.NewDecoder(recorder.Body).Decode(&status)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.DeepEquals, quota.BudgetStatus{Budget: &quota.Budget{MonthlyLimit: 1000, Enforce: true}})
+	request, err = http.NewRequest(http.MethodGet, "/teams/unknown/budget", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
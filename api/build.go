@@ -15,6 +15,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/builder"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
@@ -110,6 +111,7 @@ func build(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	}()
 	ctx, cancel := evt.CancelableContext(ctx)
 	defer cancel()
+	w.Header().Set(eventIDHeader, evt.UniqueID.Hex())
 	opts.Event = evt
 	writer := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "please wait...")
 	defer writer.Stop()
@@ -132,11 +134,37 @@ func prepareToBuild(r *http.Request) (opts app.DeployOptions, err error) {
 		}
 
 		opts.FileSize = fh.Size
+		if maxSize := app.DeployArchiveMaxSize(); opts.FileSize > maxSize {
+			opts.File.Close()
+			return opts, &tsuruErrors.HTTP{Code: http.StatusRequestEntityTooLarge, Message: (&app.ErrDeployArchiveTooLarge{Max: maxSize}).Error()}
+		}
 	}
 
 	opts.ArchiveURL = InputValue(r, "archive-url")
 	opts.Image = InputValue(r, "image")
 	opts.Dockerfile = InputValue(r, "dockerfile")
+	opts.GitURL = InputValue(r, "git-url")
+	opts.GitRef = InputValue(r, "git-ref")
+
+	if opts.GitURL != "" && (opts.FileSize > 0 || opts.ArchiveURL != "" || opts.Image != "" || opts.Dockerfile != "") {
+		return opts, &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: `Cannot set "git-url" mutually with "archive-url", "dockerfile", "file" or "image" fields`,
+		}
+	}
+
+	if opts.GitRef != "" && opts.GitURL == "" {
+		return opts, &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: `Cannot set "git-ref" without "git-url"`,
+		}
+	}
+
+	if opts.GitURL != "" {
+		if err = builder.ValidateGitSource(opts.GitURL, opts.GitRef); err != nil {
+			return opts, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
 
 	if opts.ArchiveURL != "" && (opts.FileSize > 0 || opts.Image != "" || opts.Dockerfile != "") {
 		return opts, &tsuruErrors.HTTP{
@@ -152,10 +180,10 @@ func prepareToBuild(r *http.Request) (opts app.DeployOptions, err error) {
 		}
 	}
 
-	if opts.Image == "" && opts.ArchiveURL == "" && opts.Dockerfile == "" && opts.FileSize == 0 {
+	if opts.Image == "" && opts.ArchiveURL == "" && opts.Dockerfile == "" && opts.GitURL == "" && opts.FileSize == 0 {
 		return opts, &tsuruErrors.HTTP{
 			Code:    http.StatusBadRequest,
-			Message: `You must provide at least one of: "archive-url", "dockerfile", "image" or "file"`,
+			Message: `You must provide at least one of: "archive-url", "dockerfile", "git-url", "image" or "file"`,
 		}
 	}
 
@@ -5,12 +5,16 @@
 package api
 
 import (
+	stdContext "context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -20,6 +24,7 @@ import (
 	"github.com/tsuru/tsuru/provision/cluster"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 )
@@ -128,6 +133,9 @@ func updateCluster(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	if err != nil {
 		return err
 	}
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run")); dryRun {
+		return clusterUpdateDryRun(w, r, provCluster)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     eventTypes.Target{Type: eventTypes.TargetTypeCluster, Value: provCluster.Name},
 		Kind:       permission.PermClusterUpdate,
@@ -177,6 +185,29 @@ func updateCluster(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	return nil
 }
 
+// clusterUpdateDryRun reports the apps and jobs that would be rescheduled by
+// the cluster update, without applying it.
+func clusterUpdateDryRun(w http.ResponseWriter, r *http.Request, provCluster provTypes.Cluster) error {
+	ctx := r.Context()
+	for _, poolName := range provCluster.Pools {
+		if _, err := pool.GetPoolByName(ctx, poolName); err != nil {
+			if err == pool.ErrPoolNotFound {
+				return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+			}
+			return err
+		}
+	}
+	impacts, err := app.ClusterUpdateImpact(ctx, provCluster)
+	if err != nil {
+		if err == provTypes.ErrClusterNotFound || err == provTypes.ErrNoCluster {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: provTypes.ErrClusterNotFound.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(impacts)
+}
+
 // title: list provisioner clusters
 // path: /provisioner/clusters
 // method: GET
@@ -290,6 +321,375 @@ func deleteCluster(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	return nil
 }
 
+func clusterFeatureProvisioner(ctx stdContext.Context, name string) (*provTypes.Cluster, cluster.FeatureFlagsProvisioner, error) {
+	c, err := servicemanager.Cluster.FindByName(ctx, name)
+	if err != nil {
+		if err == provTypes.ErrClusterNotFound {
+			return nil, nil, &tsuruErrors.HTTP{
+				Code:    http.StatusNotFound,
+				Message: err.Error(),
+			}
+		}
+		return nil, nil, err
+	}
+	prov, err := provision.Get(c.Provisioner)
+	if err != nil {
+		return nil, nil, err
+	}
+	featureProv, ok := prov.(cluster.FeatureFlagsProvisioner)
+	if !ok {
+		return nil, nil, &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("provisioner %q does not support feature flags", c.Provisioner),
+		}
+	}
+	return c, featureProv, nil
+}
+
+// title: list provisioner cluster features
+// path: /provisioner/clusters/{name}/features
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Provisioner does not support feature flags
+//	401: Unauthorized
+//	404: Cluster not found
+func clusterFeatureList(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterRead)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	c, prov, err := clusterFeatureProvisioner(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(prov.ClusterFeatures(c, r.URL.Query().Get("pool")))
+}
+
+// title: set provisioner cluster feature
+// path: /provisioner/clusters/{name}/features/{feature}
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Cluster or feature not found
+func clusterFeatureSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterUpdate)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	enabled, err := strconv.ParseBool(InputValue(r, "enabled"))
+	if err != nil {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "enabled must be a boolean",
+		}
+	}
+	poolName := InputValue(r, "pool")
+	if poolName != "" {
+		_, err = pool.GetPoolByName(ctx, poolName)
+		if err != nil {
+			if err == pool.ErrPoolNotFound {
+				return &tsuruErrors.HTTP{
+					Code:    http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+			return err
+		}
+	}
+	clusterName := r.URL.Query().Get(":name")
+	feature := r.URL.Query().Get(":feature")
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeCluster, Value: clusterName},
+		Kind:       permission.PermClusterUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermClusterReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	c, prov, err := clusterFeatureProvisioner(ctx, clusterName)
+	if err != nil {
+		return err
+	}
+	var found bool
+	for _, f := range prov.ClusterFeatures(c, poolName) {
+		if f.Name == feature {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: fmt.Sprintf("feature %q not found", feature),
+		}
+	}
+	key := provTypes.ClusterFeaturePrefix + feature
+	if poolName != "" {
+		key = poolName + ":" + key
+	}
+	if c.CustomData == nil {
+		c.CustomData = map[string]string{}
+	}
+	c.CustomData[key] = strconv.FormatBool(enabled)
+	return servicemanager.Cluster.Update(ctx, *c)
+}
+
+// title: list provisioner cluster resource quotas
+// path: /provisioner/clusters/{name}/resource-quotas
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Provisioner does not support resource quotas
+//	401: Unauthorized
+//	404: Cluster not found
+func clusterResourceQuotaList(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterRead)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	c, err := servicemanager.Cluster.FindByName(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		if err == provTypes.ErrClusterNotFound {
+			return &tsuruErrors.HTTP{
+				Code:    http.StatusNotFound,
+				Message: err.Error(),
+			}
+		}
+		return err
+	}
+	prov, err := provision.Get(c.Provisioner)
+	if err != nil {
+		return err
+	}
+	quotaProv, ok := prov.(cluster.ResourceQuotaProvisioner)
+	if !ok {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("provisioner %q does not support resource quotas", c.Provisioner),
+		}
+	}
+	quotas, err := quotaProv.ResourceQuotas(ctx, c)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(quotas)
+}
+
+// title: provisioner cluster capacity
+// path: /provisioner/clusters/{name}/capacity
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Cluster, app or plan not found
+func clusterCapacity(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterRead)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	c, err := servicemanager.Cluster.FindByName(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		if err == provTypes.ErrClusterNotFound {
+			return &tsuruErrors.HTTP{
+				Code:    http.StatusNotFound,
+				Message: err.Error(),
+			}
+		}
+		return err
+	}
+	prov, err := provision.Get(c.Provisioner)
+	if err != nil {
+		return err
+	}
+	capacityProv, ok := prov.(cluster.CapacityProvisioner)
+	if !ok {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("provisioner %q does not support capacity reports", c.Provisioner),
+		}
+	}
+	simulation, err := capacitySimulationOptions(r)
+	if err != nil {
+		return err
+	}
+	capacity, err := capacityProv.ClusterCapacity(ctx, c, simulation)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(capacity)
+}
+
+// capacitySimulationOptions returns the units to be simulated in a capacity
+// report, described by the app, plan, pool and units query parameters. The
+// pool and plan of the app are used unless explicitly set.
+func capacitySimulationOptions(r *http.Request) (*provision.CapacitySimulationOptions, error) {
+	ctx := r.Context()
+	appName := InputValue(r, "app")
+	planName := InputValue(r, "plan")
+	poolName := InputValue(r, "pool")
+	unitsRaw := InputValue(r, "units")
+	if appName == "" && planName == "" && poolName == "" && unitsRaw == "" {
+		return nil, nil
+	}
+	opts := &provision.CapacitySimulationOptions{Pool: poolName, Units: 1}
+	if unitsRaw != "" {
+		units, err := strconv.Atoi(unitsRaw)
+		if err != nil || units <= 0 {
+			return nil, &tsuruErrors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: "units must be a positive integer",
+			}
+		}
+		opts.Units = units
+	}
+	if appName != "" {
+		a, err := getApp(ctx, appName)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Pool == "" {
+			opts.Pool = a.Pool
+		}
+		opts.Plan = a.Plan
+	}
+	if opts.Pool == "" {
+		return nil, &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "pool or app must be provided to simulate units",
+		}
+	}
+	switch {
+	case planName != "":
+		plan, err := servicemanager.Plan.FindByName(ctx, planName)
+		if err != nil {
+			if err == appTypes.ErrPlanNotFound {
+				return nil, &tsuruErrors.HTTP{
+					Code:    http.StatusNotFound,
+					Message: err.Error(),
+				}
+			}
+			return nil, err
+		}
+		opts.Plan = *plan
+	case appName == "":
+		plan, err := servicemanager.Plan.DefaultPlan(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts.Plan = *plan
+	}
+	return opts, nil
+}
+
+// title: set provisioner cluster labels
+// path: /provisioner/clusters/{name}/labels
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Cluster not found
+func setClusterLabels(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterUpdateLabels)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var data struct {
+		Labels map[string]string
+	}
+	err = ParseInput(r, &data)
+	if err != nil {
+		return err
+	}
+	if len(data.Labels) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the labels."}
+	}
+	return updateClusterLabels(ctx, r, t, data.Labels, nil)
+}
+
+// title: unset provisioner cluster labels
+// path: /provisioner/clusters/{name}/labels
+// method: DELETE
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Cluster not found
+func unsetClusterLabels(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterUpdateLabels)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	labels, _ := InputValues(r, "label")
+	if len(labels) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the labels."}
+	}
+	return updateClusterLabels(ctx, r, t, nil, labels)
+}
+
+func updateClusterLabels(ctx stdContext.Context, r *http.Request, t auth.Token, labels map[string]string, unset []string) (err error) {
+	clusterName := r.URL.Query().Get(":name")
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeCluster, Value: clusterName},
+		Kind:       permission.PermClusterUpdateLabels,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermClusterReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	c, err := servicemanager.Cluster.FindByName(ctx, clusterName)
+	if err != nil {
+		if err == provTypes.ErrClusterNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	newLabels := map[string]string{}
+	for k, v := range c.Labels {
+		newLabels[k] = v
+	}
+	for k, v := range labels {
+		newLabels[k] = v
+	}
+	for _, k := range unset {
+		delete(newLabels, k)
+	}
+	c.Labels = newLabels
+	return servicemanager.Cluster.Update(ctx, *c)
+}
+
 type provisionerInfo struct {
 	Name        string                    `json:"name"`
 	ClusterHelp provTypes.ClusterHelpInfo `json:"cluster_help"`
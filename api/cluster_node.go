@@ -0,0 +1,221 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/cluster"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+func clusterNodeProvisioner(ctx stdContext.Context, name string) (*provTypes.Cluster, cluster.NodeProvisioner, error) {
+	c, err := servicemanager.Cluster.FindByName(ctx, name)
+	if err != nil {
+		if err == provTypes.ErrClusterNotFound {
+			return nil, nil, &tsuruErrors.HTTP{
+				Code:    http.StatusNotFound,
+				Message: err.Error(),
+			}
+		}
+		return nil, nil, err
+	}
+	prov, err := provision.Get(c.Provisioner)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodeProv, ok := prov.(cluster.NodeProvisioner)
+	if !ok {
+		return nil, nil, &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("provisioner %q does not support node management", c.Provisioner),
+		}
+	}
+	return c, nodeProv, nil
+}
+
+func clusterNodeError(err error) error {
+	if err == provision.ErrNodeNotFound {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	return err
+}
+
+func clusterNodeEvent(ctx stdContext.Context, r *http.Request, t auth.Token) (*event.Event, error) {
+	return event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeCluster, Value: r.URL.Query().Get(":name")},
+		Kind:       permission.PermClusterUpdateNodes,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermClusterReadEvents),
+	})
+}
+
+// title: list provisioner cluster nodes
+// path: /provisioner/clusters/{name}/nodes
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	204: No content
+//	400: Provisioner does not support node management
+//	401: Unauthorized
+//	404: Cluster not found
+func listClusterNodes(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterRead)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	c, prov, err := clusterNodeProvisioner(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	nodes, err := prov.ListNodes(ctx, c)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(nodes)
+}
+
+// title: cordon provisioner cluster node
+// path: /provisioner/clusters/{name}/nodes/{node}/cordon
+// method: POST
+// responses:
+//
+//	200: Ok
+//	400: Provisioner does not support node management
+//	401: Unauthorized
+//	404: Cluster or node not found
+func cordonClusterNode(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setClusterNodeUnschedulable(r, t, true)
+}
+
+// title: uncordon provisioner cluster node
+// path: /provisioner/clusters/{name}/nodes/{node}/uncordon
+// method: POST
+// responses:
+//
+//	200: Ok
+//	400: Provisioner does not support node management
+//	401: Unauthorized
+//	404: Cluster or node not found
+func uncordonClusterNode(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setClusterNodeUnschedulable(r, t, false)
+}
+
+func setClusterNodeUnschedulable(r *http.Request, t auth.Token, unschedulable bool) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterUpdateNodes)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	c, prov, err := clusterNodeProvisioner(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	evt, err := clusterNodeEvent(ctx, r, t)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = prov.CordonNode(ctx, c, r.URL.Query().Get(":node"), unschedulable)
+	return clusterNodeError(err)
+}
+
+// title: drain provisioner cluster node
+// path: /provisioner/clusters/{name}/nodes/{node}/drain
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Ok
+//	400: Provisioner does not support node management
+//	401: Unauthorized
+//	404: Cluster or node not found
+func drainClusterNode(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterUpdateNodes)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	c, prov, err := clusterNodeProvisioner(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	evt, err := clusterNodeEvent(ctx, r, t)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = prov.DrainNode(ctx, c, r.URL.Query().Get(":node"), evt)
+	return clusterNodeError(err)
+}
+
+// title: annotate provisioner cluster node
+// path: /provisioner/clusters/{name}/nodes/{node}/annotations
+// method: POST
+// consume: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Cluster or node not found
+func annotateClusterNode(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterUpdateNodes)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var data struct {
+		Annotations map[string]string
+	}
+	err = ParseInput(r, &data)
+	if err != nil {
+		return err
+	}
+	if len(data.Annotations) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the annotations."}
+	}
+	c, prov, err := clusterNodeProvisioner(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	evt, err := clusterNodeEvent(ctx, r, t)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = prov.AnnotateNode(ctx, c, r.URL.Query().Get(":node"), data.Annotations)
+	return clusterNodeError(err)
+}
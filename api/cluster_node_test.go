@@ -0,0 +1,156 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) mockFakeCluster(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		c.Assert(name, check.Equals, "c1")
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+}
+
+func (s *S) TestListClusterNodes(c *check.C) {
+	s.mockFakeCluster(c)
+	s.provisioner.AddNode(provision.ClusterNode{Name: "n2", Address: "10.0.0.2", Ready: true})
+	s.provisioner.AddNode(provision.ClusterNode{Name: "n1", Address: "10.0.0.1"})
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/nodes", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var nodes []provision.ClusterNode
+	err = json.Unmarshal(recorder.Body.Bytes(), &nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.DeepEquals, []provision.ClusterNode{
+		{Name: "n1", Address: "10.0.0.1"},
+		{Name: "n2", Address: "10.0.0.2", Ready: true},
+	})
+}
+
+func (s *S) TestListClusterNodesNoContent(c *check.C) {
+	s.mockFakeCluster(c)
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/nodes", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestCordonAndUncordonClusterNode(c *check.C) {
+	s.mockFakeCluster(c)
+	s.provisioner.AddNode(provision.ClusterNode{Name: "n1"})
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/cordon", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	node, _ := s.provisioner.GetNode("n1")
+	c.Assert(node.Unschedulable, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeCluster, Value: "c1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "cluster.update.nodes",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/uncordon", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	node, _ = s.provisioner.GetNode("n1")
+	c.Assert(node.Unschedulable, check.Equals, false)
+}
+
+func (s *S) TestCordonClusterNodeNotFound(c *check.C) {
+	s.mockFakeCluster(c)
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/cordon", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "node not found\n")
+}
+
+func (s *S) TestCordonClusterNodeWithoutPermission(c *check.C) {
+	s.mockFakeCluster(c)
+	s.provisioner.AddNode(provision.ClusterNode{Name: "n1"})
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermClusterRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/cordon", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	node, _ := s.provisioner.GetNode("n1")
+	c.Assert(node.Unschedulable, check.Equals, false)
+}
+
+func (s *S) TestDrainClusterNode(c *check.C) {
+	s.mockFakeCluster(c)
+	s.provisioner.AddNode(provision.ClusterNode{Name: "n1"})
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/drain", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*node \\"n1\\" drained.*`)
+	node, _ := s.provisioner.GetNode("n1")
+	c.Assert(node.Unschedulable, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeCluster, Value: "c1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "cluster.update.nodes",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAnnotateClusterNode(c *check.C) {
+	s.mockFakeCluster(c)
+	s.provisioner.AddNode(provision.ClusterNode{Name: "n1", Annotations: map[string]string{"a1": "v1", "a2": "v2"}})
+	body := strings.NewReader(`{"annotations":{"a1":"","a3":"v3"}}`)
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/annotations", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	node, _ := s.provisioner.GetNode("n1")
+	c.Assert(node.Annotations, check.DeepEquals, map[string]string{"a2": "v2", "a3": "v3"})
+}
+
+func (s *S) TestAnnotateClusterNodeWithoutAnnotations(c *check.C) {
+	s.mockFakeCluster(c)
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/nodes/n1/annotations", strings.NewReader(`{}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
@@ -6,11 +6,15 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/provision"
 	check "gopkg.in/check.v1"
@@ -131,6 +135,40 @@ func (s *S) TestUpdateClusterNonExistentPool(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %q", recorder.Body.String()))
 }
 
+func (s *S) TestUpdateClusterDryRun(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Pool: s.Pool}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.mockService.Cluster.OnList = func() ([]provision.Cluster, error) {
+		return []provision.Cluster{
+			{Name: "c1", Provisioner: "fake", Default: true},
+			{Name: "c2", Provisioner: "fake"},
+		}, nil
+	}
+	s.mockService.Cluster.OnUpdate = func(provision.Cluster) error {
+		c.Fatal("cluster must not be updated in dry-run")
+		return nil
+	}
+	kubeCluster := provision.Cluster{Name: "c2", Provisioner: "fake", Pools: []string{s.Pool}}
+	var buf bytes.Buffer
+	err = json.NewEncoder(&buf).Encode(kubeCluster)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/1.4/provisioner/clusters/c2?dry-run=true", &buf)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var impacts []app.ConfigImpact
+	err = json.NewDecoder(recorder.Body).Decode(&impacts)
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.DeepEquals, []app.ConfigImpact{
+		{Kind: app.ImpactKindApp, Name: "myapp", Pool: s.Pool, Reason: `would move from cluster "c1" to cluster "c2"`},
+	})
+}
+
 func (s *S) TestListClusters(c *check.C) {
 	kubeCluster := provision.Cluster{
 		Name:        "c1",
@@ -297,3 +335,224 @@ func (s *S) TestListProvisioners(c *check.C) {
 		{Name: "fake"},
 	})
 }
+
+func (s *S) TestClusterFeatureList(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		c.Assert(name, check.Equals, "c1")
+		return &provision.Cluster{
+			Name:        "c1",
+			Provisioner: "fake",
+			CustomData:  map[string]string{"mypool:feature-fake-feature": "true"},
+		}, nil
+	}
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/features?pool=mypool", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var features []provision.ClusterFeature
+	err = json.Unmarshal(recorder.Body.Bytes(), &features)
+	c.Assert(err, check.IsNil)
+	c.Assert(features, check.DeepEquals, []provision.ClusterFeature{
+		{Name: "fake-feature", Description: "Fake feature.", Enabled: true},
+	})
+}
+
+func (s *S) TestClusterResourceQuotaList(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		c.Assert(name, check.Equals, "c1")
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/resource-quotas", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var quotas []provision.NamespaceResourceQuota
+	err = json.Unmarshal(recorder.Body.Bytes(), &quotas)
+	c.Assert(err, check.IsNil)
+	c.Assert(quotas, check.DeepEquals, []provision.NamespaceResourceQuota{
+		{
+			Namespace: "default",
+			Hard:      map[string]string{"limits.cpu": "4", "limits.memory": "4Gi"},
+			Used:      map[string]string{"limits.cpu": "1", "limits.memory": "1Gi"},
+		},
+	})
+}
+
+func (s *S) TestClusterResourceQuotaListClusterNotFound(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return nil, provision.ErrClusterNotFound
+	}
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/resource-quotas", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestClusterCapacity(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		c.Assert(name, check.Equals, "c1")
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/capacity", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var capacity provision.ClusterCapacity
+	err = json.Unmarshal(recorder.Body.Bytes(), &capacity)
+	c.Assert(err, check.IsNil)
+	c.Assert(capacity.Pools, check.HasLen, 1)
+	c.Assert(capacity.Pools[0].Pool, check.Equals, "default")
+	c.Assert(capacity.Simulation, check.IsNil)
+}
+
+func (s *S) TestClusterCapacitySimulateApp(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/capacity?app=myapp&units=5", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var capacity provision.ClusterCapacity
+	err = json.Unmarshal(recorder.Body.Bytes(), &capacity)
+	c.Assert(err, check.IsNil)
+	c.Assert(capacity.Simulation, check.DeepEquals, &provision.CapacitySimulation{
+		Pool:             s.Pool,
+		Units:            5,
+		UnitMemory:       s.defaultPlan.Memory,
+		SchedulableUnits: 3,
+		Fits:             false,
+	})
+}
+
+func (s *S) TestClusterCapacitySimulatePlan(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+	s.plan = appTypes.Plan{Name: "large", Memory: 4096, CPUMilli: 2000}
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/capacity?plan=large&pool=pool1&units=2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var capacity provision.ClusterCapacity
+	err = json.Unmarshal(recorder.Body.Bytes(), &capacity)
+	c.Assert(err, check.IsNil)
+	c.Assert(capacity.Simulation, check.DeepEquals, &provision.CapacitySimulation{
+		Pool:             "pool1",
+		Units:            2,
+		UnitCPUMilli:     2000,
+		UnitMemory:       4096,
+		SchedulableUnits: 3,
+		Fits:             true,
+	})
+}
+
+func (s *S) TestClusterCapacitySimulateWithoutPool(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+	request, err := http.NewRequest(http.MethodGet, "/1.25/provisioner/clusters/c1/capacity?units=2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "pool or app must be provided to simulate units\n")
+}
+
+func (s *S) TestClusterFeatureSet(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{
+			Name:        "c1",
+			Provisioner: "fake",
+			CustomData:  map[string]string{"feature-fake-feature": "false"},
+		}, nil
+	}
+	var updated provision.Cluster
+	s.mockService.Cluster.OnUpdate = func(clust provision.Cluster) error {
+		updated = clust
+		return nil
+	}
+	body := strings.NewReader("enabled=true&pool=" + s.Pool)
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/features/fake-feature", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(updated.CustomData, check.DeepEquals, map[string]string{
+		"feature-fake-feature":           "false",
+		s.Pool + ":feature-fake-feature": "true",
+	})
+}
+
+func (s *S) TestClusterFeatureSetNotFound(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{Name: "c1", Provisioner: "fake"}, nil
+	}
+	body := strings.NewReader("enabled=true")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/features/sidecars", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "feature \"sidecars\" not found\n")
+}
+
+func (s *S) TestSetClusterLabels(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{Name: "c1", Provisioner: "fake", Labels: map[string]string{"zone": "a"}}, nil
+	}
+	var updated provision.Cluster
+	s.mockService.Cluster.OnUpdate = func(clust provision.Cluster) error {
+		updated = clust
+		return nil
+	}
+	body := strings.NewReader("Labels.ssd=true&Labels.zone=b")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/provisioner/clusters/c1/labels", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(updated.Labels, check.DeepEquals, map[string]string{"ssd": "true", "zone": "b"})
+}
+
+func (s *S) TestUnsetClusterLabels(c *check.C) {
+	s.mockService.Cluster.OnFindByName = func(name string) (*provision.Cluster, error) {
+		return &provision.Cluster{Name: "c1", Provisioner: "fake", Labels: map[string]string{"ssd": "true", "zone": "a"}}, nil
+	}
+	var updated provision.Cluster
+	s.mockService.Cluster.OnUpdate = func(clust provision.Cluster) error {
+		updated = clust
+		return nil
+	}
+	request, err := http.NewRequest(http.MethodDelete, "/1.25/provisioner/clusters/c1/labels?label=ssd", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(updated.Labels, check.DeepEquals, map[string]string{"zone": "a"})
+}
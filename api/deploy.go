@@ -5,8 +5,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -17,8 +19,13 @@ import (
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/policy"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provisionTypes "github.com/tsuru/tsuru/types/provision"
@@ -74,8 +81,6 @@ func init() {
 //	403: Forbidden
 //	404: Not found
 func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
-	startingDeployTime := time.Now()
-	ctx := r.Context()
 	opts, err := prepareToBuild(r)
 	if err != nil {
 		return err
@@ -83,8 +88,15 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if opts.File != nil {
 		defer opts.File.Close()
 	}
+	return runDeploy(w, r, t, r.URL.Query().Get(":appname"), opts)
+}
+
+// runDeploy deploys the app using the archive, image or source set in opts
+// and the remaining options from the request.
+func runDeploy(w http.ResponseWriter, r *http.Request, t auth.Token, appName string, opts app.DeployOptions) (err error) {
+	startingDeployTime := time.Now()
+	ctx := r.Context()
 	w.Header().Set("Content-Type", "text")
-	appName := r.URL.Query().Get(":appname")
 	origin := InputValue(r, "origin")
 	if opts.Image != "" {
 		origin = "image"
@@ -104,6 +116,9 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if err != nil {
 		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
 	}
+	if instance.Archive != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: app.ErrAppArchived.Error()}
+	}
 	message := InputValue(r, "message")
 	opts.App = instance
 	opts.User = userName
@@ -111,11 +126,26 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	opts.Message = message
 	opts.NewVersion, _ = strconv.ParseBool(InputValue(r, "new-version"))
 	opts.OverrideVersions, _ = strconv.ParseBool(InputValue(r, "override-versions"))
+	opts.Strategy = InputValue(r, "strategy")
+	opts.PlanOverride, err = deployPlanOverride(r)
+	if err != nil {
+		return err
+	}
 	opts.GetKind()
 	canDeploy := permission.Check(ctx, t, permSchemeForDeploy(opts), contextsForApp(instance)...)
 	if !canDeploy {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "User does not have permission to do this action in this app"}
 	}
+	err = checkAdmissionPolicies(ctx, t, policy.OperationAppDeploy, instance, map[string]interface{}{
+		"kind":       opts.GetKind(),
+		"image":      opts.Image,
+		"origin":     origin,
+		"newVersion": opts.NewVersion,
+		"strategy":   opts.Strategy,
+	})
+	if err != nil {
+		return err
+	}
 
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
@@ -131,16 +161,30 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if err != nil {
 		return err
 	}
-	defer func() {
+	finish := func(ctx context.Context, err error) {
 		evt.DoneCustomData(ctx, err, map[string]string{"image": imageID})
 		labels := prometheus.Labels{"app": appName, "status": deployStatus(evt), "kind": string(opts.GetKind()), "platform": opts.App.Platform}
 		appDeployDuration.With(labels).Observe(time.Since(startingDeployTime).Seconds())
 		appDeploysTotal.With(labels).Inc()
+	}
+	var pending bool
+	defer func() {
+		if !pending {
+			finish(ctx, err)
+		}
 	}()
-	ctx, cancel := evt.CancelableContext(ctx)
-	defer cancel()
 	w.Header().Set(eventIDHeader, evt.UniqueID.Hex())
 	opts.Event = evt
+	if instance.RequireApproval {
+		err = deployAfterApproval(ctx, w, w, opts, func(ctx context.Context, deployImageID string, deployErr error) {
+			imageID = deployImageID
+			finish(ctx, deployErr)
+		})
+		pending = err == nil
+		return err
+	}
+	ctx, cancel := evt.CancelableContext(ctx)
+	defer cancel()
 	writer := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "please wait...")
 	defer writer.Stop()
 	opts.OutputStream = writer
@@ -151,6 +195,70 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	return err
 }
 
+// deployAfterApproval runs, in background, the deploy of an app requiring
+// approval, answering the request with 202, and a message written to out, as
+// soon as the deploy is waiting to be approved. Uploaded archives are copied
+// to the spool dir, as they are gone with the request. done is called when
+// the deploy finishes. Deploys lost with a restart of the API are failed by
+// the approval janitor.
+func deployAfterApproval(ctx context.Context, w http.ResponseWriter, out io.Writer, opts app.DeployOptions, done func(context.Context, string, error)) error {
+	if opts.File != nil {
+		file, err := spoolDeployFile(opts.File, opts.Event.UniqueID.Hex())
+		if err != nil {
+			return err
+		}
+		opts.File = file
+	}
+	err := opts.Event.RequireApproval(ctx)
+	if err != nil {
+		if opts.File != nil {
+			opts.File.Close()
+		}
+		return err
+	}
+	ctx = tsuruNet.WithoutCancel(ctx)
+	go func() {
+		if opts.File != nil {
+			defer opts.File.Close()
+		}
+		deployCtx, cancel := opts.Event.CancelableContext(ctx)
+		defer cancel()
+		opts.OutputStream = io.Discard
+		imageID, err := app.Deploy(deployCtx, opts)
+		if err != nil {
+			log.Errorf("unable to deploy app %s after approval: %v", opts.App.Name, err)
+		}
+		done(ctx, imageID, err)
+	}()
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(out, "Deploy %s is waiting for approval.\n", opts.Event.UniqueID.Hex())
+	return nil
+}
+
+// deployPlanOverride parses the plan-override-cpumilli and
+// plan-override-memory values, applied only to the version being deployed.
+func deployPlanOverride(r *http.Request) (*appTypes.PlanOverride, error) {
+	var override appTypes.PlanOverride
+	if value := InputValue(r, "plan-override-cpumilli"); value != "" {
+		cpuMilli, err := strconv.Atoi(value)
+		if err != nil || cpuMilli <= 0 {
+			return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid plan-override-cpumilli %q", value)}
+		}
+		override.CPUMilli = &cpuMilli
+	}
+	if value := InputValue(r, "plan-override-memory"); value != "" {
+		memory, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || memory <= 0 {
+			return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid plan-override-memory %q", value)}
+		}
+		override.Memory = &memory
+	}
+	if override == (appTypes.PlanOverride{}) {
+		return nil, nil
+	}
+	return &override, nil
+}
+
 // path: /jobs/{name}/deploy
 // method: POST
 // consume: application/x-www-form-urlencoded
@@ -253,6 +361,8 @@ func permSchemeForDeploy(opts app.DeployOptions) *permTypes.PermissionScheme {
 	switch opts.GetKind() {
 	case provisionTypes.DeployGit:
 		return permission.PermAppDeployGit
+	case provisionTypes.DeployGitURL:
+		return permission.PermAppDeployGitUrl
 	case provisionTypes.DeployImage:
 		return permission.PermAppDeployImage
 	case provisionTypes.DeployUpload:
@@ -300,12 +410,19 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error
 		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
 	}
 	image := InputValue(r, "image")
-	if image == "" {
+	deployID := InputValue(r, "deploy-id")
+	if image == "" && deployID == "" {
 		return &tsuruErrors.HTTP{
 			Code:    http.StatusBadRequest,
 			Message: "you cannot rollback without an image name",
 		}
 	}
+	if image != "" && deployID != "" {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "image and deploy-id cannot be used together",
+		}
+	}
 	origin := InputValue(r, "origin")
 	if origin != "" {
 		if !app.ValidateOrigin(origin) {
@@ -320,20 +437,49 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	opts := app.DeployOptions{
-		App:          instance,
-		OutputStream: writer,
-		Image:        image,
-		User:         t.GetUserName(),
-		Origin:       origin,
-		Rollback:     true,
+		App:              instance,
+		OutputStream:     writer,
+		Image:            image,
+		User:             t.GetUserName(),
+		Origin:           origin,
+		Rollback:         true,
+		RollbackDeployID: deployID,
 	}
 	opts.NewVersion, _ = strconv.ParseBool(InputValue(r, "new-version"))
 	opts.OverrideVersions, _ = strconv.ParseBool(InputValue(r, "override-versions"))
+	opts.RollbackReason = InputValue(r, "reason")
+	opts.IncidentReference = InputValue(r, "incident")
 	opts.GetKind()
 	canRollback := permission.Check(ctx, t, permSchemeForDeploy(opts), contextsForApp(instance)...)
 	if !canRollback {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: permission.ErrUnauthorized.Error()}
 	}
+	if deployID != "" {
+		snapshot, snapshotErr := app.GetDeploySnapshot(ctx, instance, deployID)
+		if snapshotErr == app.ErrDeploySnapshotNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: snapshotErr.Error()}
+		}
+		if snapshotErr != nil {
+			return snapshotErr
+		}
+		opts.Image = snapshot.Image
+	}
+	err = app.ValidateRollback(ctx, opts)
+	if err != nil {
+		if _, ok := err.(*tsuruErrors.ValidationError); ok {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	err = checkAdmissionPolicies(ctx, t, policy.OperationAppDeploy, instance, map[string]interface{}{
+		"kind":       opts.GetKind(),
+		"image":      opts.Image,
+		"origin":     origin,
+		"newVersion": opts.NewVersion,
+	})
+	if err != nil {
+		return err
+	}
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
@@ -348,10 +494,22 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	if err != nil {
 		return err
 	}
-	defer func() { evt.DoneCustomData(ctx, err, map[string]string{"image": imageID}) }()
+	var pending bool
+	defer func() {
+		if !pending {
+			evt.DoneCustomData(ctx, err, map[string]string{"image": imageID})
+		}
+	}()
+	opts.Event = evt
+	if instance.RequireApproval {
+		err = deployAfterApproval(ctx, w, writer, opts, func(ctx context.Context, deployImageID string, deployErr error) {
+			evt.DoneCustomData(ctx, deployErr, map[string]string{"image": deployImageID})
+		})
+		pending = err == nil
+		return err
+	}
 	ctx, cancel := evt.CancelableContext(ctx)
 	defer cancel()
-	opts.Event = evt
 	imageID, err = app.Deploy(ctx, opts)
 	if err != nil {
 		return err
@@ -367,6 +525,7 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error
 //
 //	200: OK
 //	204: No content
+//	400: Invalid data
 func deploysList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
 	contexts := permission.ContextsForPermission(ctx, t, permission.PermAppReadDeploy)
@@ -380,7 +539,29 @@ func deploysList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	limit := r.URL.Query().Get("limit")
 	skipInt, _ := strconv.Atoi(skip)
 	limitInt, _ := strconv.Atoi(limit)
-	deploys, err := app.ListDeploys(ctx, filter, skipInt, limitInt)
+	opts := app.DeployListOptions{
+		Skip:   skipInt,
+		Limit:  limitInt,
+		Cursor: r.URL.Query().Get("cursor"),
+		Status: r.URL.Query().Get("status"),
+	}
+	var err error
+	if since := r.URL.Query().Get("since"); since != "" {
+		opts.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid since value %q: %v", since, err)}
+		}
+	}
+	if until := r.URL.Query().Get("until"); until != "" {
+		opts.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid until value %q: %v", until, err)}
+		}
+	}
+	deploys, err := app.ListDeploys(ctx, filter, opts)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
 	if err != nil {
 		return err
 	}
@@ -423,6 +604,40 @@ func deployInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(deploy)
 }
 
+// title: deploy manifest diff
+// path: /deploys/{deploy}/manifest-diff
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Not found
+func deployManifestDiff(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	depID := r.URL.Query().Get(":deploy")
+	diff, err := app.DeployManifestDiff(ctx, depID)
+	if err != nil {
+		if err == event.ErrEventNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Deploy not found."}
+		}
+		if err == app.ErrNoDeployManifests {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	dbApp, err := app.GetByName(ctx, diff.App)
+	if err != nil {
+		return err
+	}
+	canGet := permission.Check(ctx, t, permission.PermAppReadDeploy, contextsForApp(dbApp)...)
+	if !canGet {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Deploy not found."}
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(diff)
+}
+
 // title: rebuild
 // path: /apps/{app}/deploy/rebuild
 // method: POST
@@ -465,6 +680,15 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !canDeploy {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: permission.ErrUnauthorized.Error()}
 	}
+	err = checkAdmissionPolicies(ctx, t, policy.OperationAppDeploy, instance, map[string]interface{}{
+		"kind":       opts.GetKind(),
+		"image":      opts.Image,
+		"origin":     origin,
+		"newVersion": opts.NewVersion,
+	})
+	if err != nil {
+		return err
+	}
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
@@ -479,10 +703,22 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
-	defer func() { evt.DoneCustomData(ctx, err, map[string]string{"image": imageID}) }()
+	var pending bool
+	defer func() {
+		if !pending {
+			evt.DoneCustomData(ctx, err, map[string]string{"image": imageID})
+		}
+	}()
+	opts.Event = evt
+	if instance.RequireApproval {
+		err = deployAfterApproval(ctx, w, writer, opts, func(ctx context.Context, deployImageID string, deployErr error) {
+			evt.DoneCustomData(ctx, deployErr, map[string]string{"image": deployImageID})
+		})
+		pending = err == nil
+		return err
+	}
 	ctx, cancel := evt.CancelableContext(ctx)
 	defer cancel()
-	opts.Event = evt
 	imageID, err = app.Deploy(ctx, opts)
 	if err != nil {
 		return err
@@ -561,3 +797,312 @@ func deployRollbackUpdate(w http.ResponseWriter, r *http.Request, t auth.Token)
 	}
 	return err
 }
+
+type deployPauseStatus struct {
+	Deploy string               `json:"deploy"`
+	Pause  eventTypes.PauseInfo `json:"pause"`
+}
+
+func deployPauseError(err error) error {
+	switch err {
+	case app.ErrNoDeployInProgress, event.ErrEventNotFound:
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case event.ErrAlreadyPaused, event.ErrNotPaused, event.ErrNotPausable:
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: pause deploy
+// path: /apps/{app}/deploy/pause
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Deploy paused
+//	401: Unauthorized
+//	404: App or deploy in progress not found
+//	409: Deploy already paused
+func deployPause(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	if !permission.Check(ctx, t, permission.PermAppDeployPause, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      appTarget(appName),
+		Kind:        permission.PermAppDeployPause,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	deployEvt, err := app.PauseDeploy(ctx, instance, InputValue(r, "reason"), t.GetUserName())
+	if err != nil {
+		return deployPauseError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deployPauseStatus{
+		Deploy: deployEvt.UniqueID.Hex(),
+		Pause:  deployEvt.PauseInfo,
+	})
+}
+
+// title: resume deploy
+// path: /apps/{app}/deploy/resume
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Deploy resumed
+//	401: Unauthorized
+//	404: App or deploy in progress not found
+//	409: Deploy not paused
+func deployResume(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	if !permission.Check(ctx, t, permission.PermAppDeployResume, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      appTarget(appName),
+		Kind:        permission.PermAppDeployResume,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	deployEvt, err := app.ResumeDeploy(ctx, instance)
+	if err != nil {
+		return deployPauseError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deployPauseStatus{
+		Deploy: deployEvt.UniqueID.Hex(),
+		Pause:  deployEvt.PauseInfo,
+	})
+}
+
+// title: confirm blue-green deploy
+// path: /apps/{app}/deploy/confirm
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Traffic switched to the pending version
+//	401: Unauthorized
+//	404: App or pending deploy not found
+func deployConfirm(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return finishBlueGreenDeploy(w, r, t, permission.PermAppDeployConfirm, app.ConfirmDeploy)
+}
+
+// title: abort blue-green deploy
+// path: /apps/{app}/deploy/abort
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Pending version removed
+//	401: Unauthorized
+//	404: App or pending deploy not found
+func deployAbort(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return finishBlueGreenDeploy(w, r, t, permission.PermAppDeployAbort, app.AbortDeploy)
+}
+
+// finishBlueGreenDeploy confirms or aborts, according to finish, the version
+// left pending by a blue-green deploy of the app.
+func finishBlueGreenDeploy(w http.ResponseWriter, r *http.Request, t auth.Token, perm *permTypes.PermissionScheme, finish func(context.Context, *appTypes.App, *event.Event) (int, error)) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	if !permission.Check(ctx, t, perm, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       perm,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	var version int
+	defer func() { evt.DoneCustomData(ctx, err, map[string]int{"version": version}) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	version, err = finish(ctx, instance, evt)
+	if err == provision.ErrNoPendingDeploy {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: deploy rollout status
+// path: /apps/{app}/deploy/status
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App or deploy not found
+func deployRolloutStatus(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	if !permission.Check(ctx, t, permission.PermAppReadDeploy, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	status, err := app.DeployRolloutStatus(ctx, instance)
+	if err != nil {
+		if err == app.ErrNoDeploys {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(status)
+}
+
+// title: continue deploy
+// path: /deploys/{deploy}/continue
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Deploy continued
+//	401: Unauthorized
+//	404: Deploy not found or not in progress
+//	409: Deploy not paused
+func deployContinue(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	depID := r.URL.Query().Get(":deploy")
+	deploy, err := app.GetDeploy(ctx, depID)
+	if err != nil {
+		if err == event.ErrEventNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Deploy not found."}
+		}
+		return err
+	}
+	instance, err := app.GetByName(ctx, deploy.App)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", deploy.App)}
+	}
+	if !permission.Check(ctx, t, permission.PermAppDeployResume, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      appTarget(instance.Name),
+		Kind:        permission.PermAppDeployResume,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	deployEvt, err := app.ContinueDeploy(ctx, depID)
+	if err == app.ErrDeployNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return deployPauseError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deployPauseStatus{
+		Deploy: deployEvt.UniqueID.Hex(),
+		Pause:  deployEvt.PauseInfo,
+	})
+}
+
+type deployApprovalStatus struct {
+	Deploy   string                  `json:"deploy"`
+	Approval eventTypes.ApprovalInfo `json:"approval"`
+}
+
+// title: approve deploy
+// path: /apps/{app}/deploys/{id}/approve
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Deploy approved
+//	400: Deploy started by the approver
+//	401: Unauthorized
+//	404: App or deploy not found
+//	409: Deploy not waiting for approval
+func deployApprove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	if !permission.Check(ctx, t, permission.PermAppAdminApproveDeploy, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      appTarget(appName),
+		Kind:        permission.PermAppAdminApproveDeploy,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	deployEvt, err := app.ApproveDeploy(ctx, instance, r.URL.Query().Get(":id"), t.GetUserName())
+	switch err {
+	case nil:
+	case app.ErrDeployNotFound, event.ErrEventNotFound:
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case app.ErrDeploySelfApproval:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case event.ErrNotWaitingApproval:
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	default:
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deployApprovalStatus{
+		Deploy:   deployEvt.UniqueID.Hex(),
+		Approval: deployEvt.ApprovalInfo,
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const approvalJanitorInterval = time.Minute
+
+// deploySpoolDir holds the archives of deploys waiting for approval, named
+// after the id of their events.
+var deploySpoolDir = filepath.Join(os.TempDir(), "tsuru-deploy-approval")
+
+// tempFile is a temporary file removed when closed.
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+func spoolDeployFile(r io.Reader, eventID string) (io.ReadCloser, error) {
+	err := os.MkdirAll(deploySpoolDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(deploySpoolDir, eventID), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	file := &tempFile{File: f}
+	_, err = io.Copy(file, r)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+type approvalJanitor struct {
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// startApprovalJanitor starts a background loop failing the deploys waiting
+// for approval, or running after it, which were lost with a restart of the
+// API instance handling them, and removing their archives from the spool dir.
+func startApprovalJanitor() {
+	j := &approvalJanitor{
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go j.spin()
+	shutdown.Register(j)
+}
+
+func (j *approvalJanitor) spin() {
+	defer close(j.doneCh)
+	for {
+		err := cleanupAbandonedApprovals(context.Background())
+		if err != nil {
+			log.Errorf("[approval-janitor] %v", err)
+		}
+		select {
+		case <-j.stopCh:
+			return
+		case <-time.After(approvalJanitorInterval):
+		}
+	}
+}
+
+func (j *approvalJanitor) Shutdown(ctx context.Context) error {
+	close(j.stopCh)
+	select {
+	case <-j.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// cleanupAbandonedApprovals fails the abandoned approval events and removes
+// the spooled archives of events no longer running.
+func cleanupAbandonedApprovals(ctx context.Context) error {
+	err := event.FailAbandonedApprovals(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(deploySpoolDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if id, err := primitive.ObjectIDFromHex(entry.Name()); err == nil {
+			evt, err := event.GetByID(ctx, id)
+			if err != nil && err != event.ErrEventNotFound {
+				return err
+			}
+			if evt != nil && evt.Running {
+				continue
+			}
+		}
+		os.Remove(filepath.Join(deploySpoolDir, entry.Name()))
+	}
+	return nil
+}
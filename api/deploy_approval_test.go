@@ -0,0 +1,51 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCleanupAbandonedApprovalsSpoolDir(c *check.C) {
+	oldDir := deploySpoolDir
+	deploySpoolDir = c.MkDir()
+	defer func() { deploySpoolDir = oldDir }()
+	newEvent := func(appName string) *event.Event {
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: appName},
+			Kind:    permission.PermAppDeploy,
+			Owner:   s.token,
+			Allowed: event.Allowed(permission.PermAppReadEvents),
+		})
+		c.Assert(err, check.IsNil)
+		return evt
+	}
+	running := newEvent("app1")
+	defer running.Done(context.TODO(), nil)
+	finished := newEvent("app2")
+	err := finished.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	runningFile, err := spoolDeployFile(strings.NewReader("archive"), running.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	defer runningFile.Close()
+	_, err = spoolDeployFile(strings.NewReader("archive"), finished.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	err = os.WriteFile(filepath.Join(deploySpoolDir, "garbage"), []byte("x"), 0600)
+	c.Assert(err, check.IsNil)
+	err = cleanupAbandonedApprovals(context.TODO())
+	c.Assert(err, check.IsNil)
+	entries, err := os.ReadDir(deploySpoolDir)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Name(), check.Equals, running.UniqueID.Hex())
+}
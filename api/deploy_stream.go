@@ -0,0 +1,188 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const (
+	deployStreamFrameLog      = "log"
+	deployStreamFrameProgress = "progress"
+	deployStreamFrameStatus   = "status"
+	deployStreamFrameError    = "error"
+)
+
+var deployStreamPollInterval = time.Second
+
+// deployStreamFrame is a message sent to clients following a deploy. Log
+// frames carry the offset of the entry, which may be used to resume the
+// stream after reconnecting. Progress frames are sent whenever the deploy
+// reaches a new progress checkpoint. The status frame is the last one, sent
+// when the deploy finishes, and its offset is the number of log entries.
+type deployStreamFrame struct {
+	Type    string     `json:"type"`
+	Offset  int        `json:"offset"`
+	Date    *time.Time `json:"date,omitempty"`
+	Message string     `json:"message,omitempty"`
+	Step    string     `json:"step,omitempty"`
+	Percent int        `json:"percent,omitempty"`
+	Current int        `json:"current,omitempty"`
+	Total   int        `json:"total,omitempty"`
+	Status  string     `json:"status,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// title: deploy log stream
+// path: /apps/{appname}/deploys/{deploy}/stream
+// method: GET
+// produce: Websocket connection upgrade
+// responses:
+//
+//	101: Switch Protocol to websocket
+func deployStreamHandler(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		ws.Close()
+	}()
+	err = streamDeploy(r, ws)
+	if err != nil {
+		msg := err.Error()
+		if httpErr, ok := err.(*errors.HTTP); ok && httpErr.Code == http.StatusUnauthorized {
+			msg = "no token provided or session expired, please login again"
+		}
+		ws.WriteJSON(deployStreamFrame{Type: deployStreamFrameError, Error: msg})
+	}
+}
+
+func streamDeploy(r *http.Request, ws *websocket.Conn) error {
+	ctx, cancel := stdContext.WithCancel(r.Context())
+	defer cancel()
+	token := context.GetAuthToken(r)
+	if token == nil {
+		return &errors.HTTP{Code: http.StatusUnauthorized, Message: "no token provided"}
+	}
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, token, permission.PermAppReadDeploy, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	var offset int
+	if rawOffset := r.URL.Query().Get("offset"); rawOffset != "" {
+		offset, err = strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "offset must be a non negative integer"}
+		}
+	}
+	deployID := r.URL.Query().Get(":deploy")
+	evt, err := getDeployEvent(ctx, appName, deployID)
+	if err != nil {
+		return err
+	}
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(string) error {
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	go func() {
+		// Nothing is expected from the client, reading is only needed to
+		// handle control messages and to notice when it goes away.
+		defer cancel()
+		for {
+			if _, _, readErr := ws.NextReader(); readErr != nil {
+				return
+			}
+		}
+	}()
+	lastPing := time.Now()
+	var lastProgress time.Time
+	for {
+		if progress := evt.ProgressInfo; progress.UpdateTime.After(lastProgress) {
+			lastProgress = progress.UpdateTime
+			err = ws.WriteJSON(deployStreamFrame{
+				Type:    deployStreamFrameProgress,
+				Offset:  offset,
+				Date:    &progress.UpdateTime,
+				Step:    progress.Step,
+				Percent: progress.Percent,
+				Current: progress.Current,
+				Total:   progress.Total,
+			})
+			if err != nil {
+				return nil
+			}
+		}
+		for ; offset < len(evt.StructuredLog); offset++ {
+			entry := evt.StructuredLog[offset]
+			err = ws.WriteJSON(deployStreamFrame{
+				Type:    deployStreamFrameLog,
+				Offset:  offset,
+				Date:    &entry.Date,
+				Message: entry.Message,
+			})
+			if err != nil {
+				return nil
+			}
+		}
+		if !evt.Running {
+			return ws.WriteJSON(deployStreamStatus(evt, offset))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(deployStreamPollInterval):
+		}
+		if time.Since(lastPing) >= pingInterval {
+			ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(2*time.Second))
+			lastPing = time.Now()
+		}
+		evt, err = getDeployEvent(ctx, appName, deployID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func getDeployEvent(ctx stdContext.Context, appName, deployID string) (*event.Event, error) {
+	evt, err := app.DeployEvent(ctx, deployID)
+	if err == nil && (evt.Target.Type != eventTypes.TargetTypeApp || evt.Target.Value != appName) {
+		err = app.ErrDeployNotFound
+	}
+	if err == app.ErrDeployNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: "Deploy not found."}
+	}
+	return evt, err
+}
+
+func deployStreamStatus(evt *event.Event, offset int) deployStreamFrame {
+	frame := deployStreamFrame{
+		Type:   deployStreamFrameStatus,
+		Offset: offset,
+		Status: app.DeployStatusSuccess,
+	}
+	if evt.Error != "" {
+		frame.Status = app.DeployStatusFailed
+		frame.Error = evt.Error
+	}
+	return frame
+}
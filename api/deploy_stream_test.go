@@ -0,0 +1,186 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/websocket"
+	check "gopkg.in/check.v1"
+)
+
+func (s *DeploySuite) dialDeployStream(c *check.C, server *httptest.Server, appName, deployID, query string) *websocket.Conn {
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, check.IsNil)
+	streamURL := fmt.Sprintf("ws://%s/apps/%s/deploys/%s/stream?%s", serverURL.Host, appName, deployID, query)
+	config, err := websocket.NewConfig(streamURL, "ws://localhost/")
+	c.Assert(err, check.IsNil)
+	config.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	wsConn, err := websocket.DialConfig(config)
+	c.Assert(err, check.IsNil)
+	return wsConn
+}
+
+func receiveDeployStreamFrames(c *check.C, wsConn *websocket.Conn) []deployStreamFrame {
+	var frames []deployStreamFrame
+	wsConn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		var frame deployStreamFrame
+		if err := websocket.JSON.Receive(wsConn, &frame); err != nil {
+			break
+		}
+		frames = append(frames, frame)
+		if frame.Type == deployStreamFrameStatus || frame.Type == deployStreamFrameError {
+			break
+		}
+	}
+	return frames
+}
+
+func (s *DeploySuite) newFinishedDeployEvent(c *check.C, appName string, deployErr error) *event.Event {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(appName),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	evt.Logf("building image")
+	evt.Logf("deploying units")
+	err = evt.Done(context.TODO(), deployErr)
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *DeploySuite) TestDeployStream(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.newFinishedDeployEvent(c, a.Name, errors.New("units failed to start"))
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialDeployStream(c, server, a.Name, evt.UniqueID.Hex(), "")
+	defer wsConn.Close()
+	frames := receiveDeployStreamFrames(c, wsConn)
+	c.Assert(frames, check.HasLen, 3)
+	c.Assert(frames[0].Type, check.Equals, deployStreamFrameLog)
+	c.Assert(frames[0].Offset, check.Equals, 0)
+	c.Assert(frames[0].Message, check.Equals, "building image\n")
+	c.Assert(frames[1].Offset, check.Equals, 1)
+	c.Assert(frames[1].Message, check.Equals, "deploying units\n")
+	c.Assert(frames[2], check.DeepEquals, deployStreamFrame{
+		Type:   deployStreamFrameStatus,
+		Offset: 2,
+		Status: app.DeployStatusFailed,
+		Error:  "units failed to start",
+	})
+}
+
+func (s *DeploySuite) TestDeployStreamProgress(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.SetProgress(context.TODO(), eventTypes.ProgressInfo{Step: "units of process web rolling", Percent: 55, Current: 3, Total: 10})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialDeployStream(c, server, a.Name, evt.UniqueID.Hex(), "")
+	defer wsConn.Close()
+	frames := receiveDeployStreamFrames(c, wsConn)
+	c.Assert(frames, check.HasLen, 2)
+	c.Assert(frames[0].Type, check.Equals, deployStreamFrameProgress)
+	c.Assert(frames[0].Step, check.Equals, "units of process web rolling")
+	c.Assert(frames[0].Percent, check.Equals, 55)
+	c.Assert(frames[0].Current, check.Equals, 3)
+	c.Assert(frames[0].Total, check.Equals, 10)
+	c.Assert(frames[1].Type, check.Equals, deployStreamFrameStatus)
+}
+
+func (s *DeploySuite) TestDeployStreamFromOffset(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.newFinishedDeployEvent(c, a.Name, nil)
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialDeployStream(c, server, a.Name, evt.UniqueID.Hex(), "offset=1")
+	defer wsConn.Close()
+	frames := receiveDeployStreamFrames(c, wsConn)
+	c.Assert(frames, check.HasLen, 2)
+	c.Assert(frames[0].Offset, check.Equals, 1)
+	c.Assert(frames[0].Message, check.Equals, "deploying units\n")
+	c.Assert(frames[1].Type, check.Equals, deployStreamFrameStatus)
+	c.Assert(frames[1].Status, check.Equals, app.DeployStatusSuccess)
+}
+
+func (s *DeploySuite) TestDeployStreamRunningDeploy(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	evt.Logf("building image")
+	err = evt.FlushLog(context.TODO())
+	c.Assert(err, check.IsNil)
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialDeployStream(c, server, a.Name, evt.UniqueID.Hex(), "")
+	defer wsConn.Close()
+	var frame deployStreamFrame
+	err = websocket.JSON.Receive(wsConn, &frame)
+	c.Assert(err, check.IsNil)
+	c.Assert(frame.Message, check.Equals, "building image\n")
+	evt.Logf("deploying units")
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	frames := receiveDeployStreamFrames(c, wsConn)
+	c.Assert(frames, check.HasLen, 2)
+	c.Assert(frames[0].Offset, check.Equals, 1)
+	c.Assert(frames[0].Message, check.Equals, "deploying units\n")
+	c.Assert(frames[1].Type, check.Equals, deployStreamFrameStatus)
+	c.Assert(frames[1].Status, check.Equals, app.DeployStatusSuccess)
+}
+
+func (s *DeploySuite) TestDeployStreamDeployNotFound(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.newFinishedDeployEvent(c, "anotherapp", nil)
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	for _, id := range []string{primitive.NewObjectID().Hex(), evt.UniqueID.Hex()} {
+		wsConn := s.dialDeployStream(c, server, a.Name, id, "")
+		frames := receiveDeployStreamFrames(c, wsConn)
+		wsConn.Close()
+		c.Assert(frames, check.DeepEquals, []deployStreamFrame{
+			{Type: deployStreamFrameError, Error: "Deploy not found."},
+		})
+	}
+}
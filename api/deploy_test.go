@@ -27,6 +27,7 @@ import (
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/policy"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
@@ -41,6 +42,7 @@ import (
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 	check "gopkg.in/check.v1"
 )
@@ -313,6 +315,46 @@ func (s *DeploySuite) TestDeployOriginImage(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *DeploySuite) TestDeployWithPlanOverride(c *check.C) {
+	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		return newAppVersion(c, app), nil
+	}
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/deploy", a.Name)
+	body := strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp&new-version=true&plan-override-cpumilli=500&plan-override-memory=268435456")
+	request, err := http.NewRequest("POST", url, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	cpuMilli, memory := 500, int64(268435456)
+	c.Assert(s.provisioner.DeployPlanOverride(&a), check.DeepEquals, &appTypes.PlanOverride{CPUMilli: &cpuMilli, Memory: &memory})
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Plan.Override, check.IsNil)
+}
+
+func (s *DeploySuite) TestDeployWithInvalidPlanOverride(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/deploy", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp&plan-override-memory=-1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid plan-override-memory \"-1\"\n")
+}
+
 func (s *DeploySuite) TestDeployArchiveURL(c *check.C) {
 	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
 		return newAppVersion(c, app), nil
@@ -725,7 +767,23 @@ func (s *DeploySuite) TestDeployNoMandatoryFields(c *check.C) {
 	server.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
 	message := recorder.Body.String()
-	c.Assert(message, check.Equals, "You must provide at least one of: \"archive-url\", \"dockerfile\", \"image\" or \"file\"\n")
+	c.Assert(message, check.Equals, "You must provide at least one of: \"archive-url\", \"dockerfile\", \"git-url\", \"image\" or \"file\"\n")
+}
+
+func (s *DeploySuite) TestDeployInvalidGitURL(c *check.C) {
+	a := appTypes.App{Name: "abc", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("git-url=--upload-pack=id")
+	request, err := http.NewRequest("POST", "/apps/abc/deploy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "git url must be an https:// or ssh:// url\n")
 }
 
 func (s *DeploySuite) TestDeploySetBothFieldsArchiveURLAndImage(c *check.C) {
@@ -956,6 +1014,42 @@ func (s *DeploySuite) TestDeployListByAppWithImage(c *check.C) {
 	c.Assert(result[0].Timestamp.In(time.UTC), check.DeepEquals, timestamp.In(time.UTC))
 }
 
+func (s *DeploySuite) TestDeployListSinceAndStatus(c *check.C) {
+	a := appTypes.App{Name: "myblog", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	timestamp := time.Date(2013, time.November, 1, 0, 0, 0, 0, time.UTC)
+	deploys := []app.DeployData{
+		{App: "myblog", Timestamp: timestamp},
+		{App: "myblog", Timestamp: timestamp.Add(time.Hour)},
+	}
+	insertDeploysAsEvents(context.TODO(), deploys, c)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/deploys?app=myblog&status=success&since=2013-11-01T00:30:00Z", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []app.DeployData
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].Timestamp.In(time.UTC), check.DeepEquals, timestamp.Add(time.Hour))
+}
+
+func (s *DeploySuite) TestDeployListInvalidFilters(c *check.C) {
+	server := RunServer(true)
+	for _, query := range []string{"status=done", "since=yesterday", "until=today", "cursor=invalid"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/deploys?"+query, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		server.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("query: %s", query))
+	}
+}
+
 func (s *DeploySuite) TestDeployListAppWithNoDeploys(c *check.C) {
 	a := appTypes.App{Name: "myblog", Platform: "python", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &a, s.user)
@@ -1034,6 +1128,63 @@ func (s *DeploySuite) TestDeployInfoDiff(c *check.C) {
 	c.Assert(result, check.DeepEquals, lastDeploy)
 }
 
+func (s *DeploySuite) TestDeployManifestDiff(c *check.C) {
+	a := appTypes.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	timestamp := time.Now()
+	depData := []app.DeployData{
+		{App: "g1", Timestamp: timestamp.Add(-3600 * time.Second)},
+		{App: "g1", Timestamp: timestamp},
+	}
+	evts := insertDeploysAsEvents(context.TODO(), depData, c)
+	for i, evt := range evts {
+		image := fmt.Sprintf("tsuru/app-g1:v%d", i+1)
+		err = evt.SetOtherCustomData(context.TODO(), map[string]interface{}{
+			"manifests": []provision.RenderedManifest{{Process: "web", Image: image, Manifest: "image: " + image + "\n"}},
+		})
+		c.Assert(err, check.IsNil)
+	}
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/deploys/%s/manifest-diff", evts[1].UniqueID.Hex())
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result app.ManifestDiff
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, app.ManifestDiff{
+		App:              "g1",
+		DeployID:         evts[1].UniqueID.Hex(),
+		PreviousDeployID: evts[0].UniqueID.Hex(),
+		Processes: []app.ProcessManifestDiff{{
+			Process:  "web",
+			Changes:  []app.ManifestChange{{Field: "image", Previous: "tsuru/app-g1:v1", Current: "tsuru/app-g1:v2"}},
+			Manifest: "-image: tsuru/app-g1:v1\n+image: tsuru/app-g1:v2\n",
+		}},
+	})
+}
+
+func (s *DeploySuite) TestDeployManifestDiffWithoutManifests(c *check.C) {
+	a := appTypes.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evts := insertDeploysAsEvents(context.TODO(), []app.DeployData{{App: "g1", Timestamp: time.Now()}}, c)
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/deploys/%s/manifest-diff", evts[0].UniqueID.Hex())
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrNoDeployManifests.Error()+"\n")
+}
+
 func (s *DeploySuite) TestDeployInfoByNonAdminUser(c *check.C) {
 	a := appTypes.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &a, s.user)
@@ -1147,6 +1298,62 @@ func (s *DeploySuite) TestDeployRollbackHandler(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *DeploySuite) TestDeployRollbackHandlerWithReason(c *check.C) {
+	err := pool.PoolUpdate(context.TODO(), "pool1", pool.UpdatePoolOptions{Labels: map[string]string{"rollback-require-reason": "true"}})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	v := url.Values{}
+	v.Set("origin", "rollback")
+	v.Set("image", fmt.Sprintf("v%d", version.Version()))
+	v.Set("reason", "high error rate after deploy")
+	v.Set("incident", "INC-42")
+	u := fmt.Sprintf("/apps/%s/deploy/rollback", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy",
+		StartCustomData: map[string]interface{}{
+			"app.name":          a.Name,
+			"kind":              "rollback",
+			"rollback":          true,
+			"rollbackreason":    "high error rate after deploy",
+			"incidentreference": "INC-42",
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployRollbackHandlerReasonRequired(c *check.C) {
+	err := pool.PoolUpdate(context.TODO(), "pool1", pool.UpdatePoolOptions{Labels: map[string]string{"rollback-require-reason": "true"}})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	v := url.Values{}
+	v.Set("image", fmt.Sprintf("v%d", version.Version()))
+	u := fmt.Sprintf("/apps/%s/deploy/rollback", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, `pool "pool1" requires a reason to rollback apps`+"\n")
+}
+
 func (s *DeploySuite) TestDeployRollbackHandlerWithOnlyVersionImage(c *check.C) {
 	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &a, s.user)
@@ -1216,6 +1423,41 @@ func (s *DeploySuite) TestDeployRollbackHandlerWithInexistVersion(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Invalid version: v9.*`)
 }
 
+func (s *DeploySuite) TestDeployRollbackHandlerWithDeployIDNotFound(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("deploy-id", primitive.NewObjectID().Hex())
+	u := fmt.Sprintf("/apps/%s/deploy/rollback", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrDeploySnapshotNotFound.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployRollbackHandlerWithImageAndDeployID(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("image", "v1")
+	v.Set("deploy-id", primitive.NewObjectID().Hex())
+	u := fmt.Sprintf("/apps/%s/deploy/rollback", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "image and deploy-id cannot be used together\n")
+}
+
 func (s *DeploySuite) TestDiffDeploy(c *check.C) {
 	diff := `--- hello.go	2015-11-25 16:04:22.409241045 +0000
 +++ hello.go	2015-11-18 18:40:21.385697080 +0000
@@ -1334,6 +1576,62 @@ func (s *DeploySuite) TestDeployRebuildHandler(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *DeploySuite) TestDeployRollbackHandlerDeniedByAdmissionPolicy(c *check.C) {
+	srv := fakeOPAServer(map[string][]string{"no_rollback": {"rollbacks are frozen"}})
+	defer srv.Close()
+	config.Set("admission-policies:opa:url", srv.URL)
+	defer config.Unset("admission-policies")
+	err := policy.Save(context.TODO(), &policy.Policy{Name: "no_rollback", Operations: []string{policy.OperationAppDeploy}, Module: policyModule("no_rollback")})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	testBaseImage, err := version.BaseImageName()
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("origin", "rollback")
+	v.Set("image", testBaseImage)
+	u := fmt.Sprintf("/apps/%s/deploy/rollback", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, "app.deploy denied by admission policies: no_rollback: rollbacks are frozen\n")
+}
+
+func (s *DeploySuite) TestDeployRebuildHandlerDeniedByAdmissionPolicy(c *check.C) {
+	srv := fakeOPAServer(map[string][]string{"no_rebuild": {"rebuilds are frozen"}})
+	defer srv.Close()
+	config.Set("admission-policies:opa:url", srv.URL)
+	defer config.Unset("admission-policies")
+	err := policy.Save(context.TODO(), &policy.Policy{Name: "no_rebuild", Operations: []string{policy.OperationAppDeploy}, Module: policyModule("no_rebuild")})
+	c.Assert(err, check.IsNil)
+	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		c.Error("rebuild should not be called")
+		return newAppVersion(c, app), nil
+	}
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("origin", "rebuild")
+	u := fmt.Sprintf("/apps/%s/deploy/rebuild", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, "app.deploy denied by admission policies: no_rebuild: rebuilds are frozen\n")
+}
+
 func (s *DeploySuite) TestRollbackUpdate(c *check.C) {
 	fakeApp := appTypes.App{Name: "otherapp", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
@@ -1787,3 +2085,365 @@ func (s *DeploySuite) TestJobDeployFailed(c *check.C) {
 		ErrorMatches: "Some fake error during Build",
 	}, eventtest.HasEvent)
 }
+
+func (s *DeploySuite) TestDeployPauseAndResume(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	deployEvt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy/pause", strings.NewReader("reason=checking+metrics"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var status deployPauseStatus
+	err = json.Unmarshal(recorder.Body.Bytes(), &status)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Deploy, check.Equals, deployEvt.UniqueID.Hex())
+	c.Assert(status.Pause.Paused, check.Equals, true)
+	c.Assert(status.Pause.Reason, check.Equals, "checking metrics")
+	c.Assert(status.Pause.Owner, check.Equals, s.token.GetUserName())
+	paused, err := deployEvt.PauseRequested(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(paused, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy.pause",
+	}, eventtest.HasEvent)
+
+	request, err = http.NewRequest("POST", "/apps/otherapp/deploy/pause", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+
+	request, err = http.NewRequest("POST", "/apps/otherapp/deploy/resume", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	paused, err = deployEvt.PauseRequested(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(paused, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy.resume",
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployPauseNoDeployInProgress(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy/pause", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrNoDeployInProgress.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployResumeNotPaused(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy/resume", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *DeploySuite) TestDeployRolloutStatus(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 2, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	deployEvt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = deployEvt.SetProgress(context.TODO(), eventTypes.ProgressInfo{Step: "units of process web rolling", Percent: 60, Current: 1, Total: 2})
+	c.Assert(err, check.IsNil)
+	getStatus := func() app.RolloutStatus {
+		request, err := http.NewRequest("GET", "/apps/otherapp/deploy/status", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK)
+		c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+		var status app.RolloutStatus
+		err = json.Unmarshal(recorder.Body.Bytes(), &status)
+		c.Assert(err, check.IsNil)
+		return status
+	}
+	status := getStatus()
+	c.Assert(status.DeployID, check.Equals, deployEvt.UniqueID.Hex())
+	c.Assert(status.Status, check.Equals, app.RolloutStatusProgressing)
+	c.Assert(status.Progress.Percent, check.Equals, 60)
+	c.Assert(status.Progress.Step, check.Equals, "units of process web rolling")
+	c.Assert(status.Units, check.DeepEquals, []app.VersionUnitsStatus{{Version: version.Version(), Total: 2}})
+	c.Assert(status.FailedReason, check.Equals, "")
+	err = deployEvt.Done(context.TODO(), errors.New("units not ready"))
+	c.Assert(err, check.IsNil)
+	status = getStatus()
+	c.Assert(status.Status, check.Equals, app.RolloutStatusFailed)
+	c.Assert(status.FailedReason, check.Equals, "units not ready")
+	c.Assert(status.ElapsedSeconds >= 0, check.Equals, true)
+}
+
+func (s *DeploySuite) TestDeployRolloutStatusNoDeploys(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/otherapp/deploy/status", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrNoDeploys.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployRequiringApprovalIsAccepted(c *check.C) {
+	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		return newAppVersion(c, app), nil
+	}
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name, RequireApproval: true}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusAccepted)
+	evtID := recorder.Header().Get(eventIDHeader)
+	c.Assert(recorder.Body.String(), check.Equals, "Deploy "+evtID+" is waiting for approval.\n")
+	id, err := primitive.ObjectIDFromHex(evtID)
+	c.Assert(err, check.IsNil)
+	deployEvt, err := event.GetByID(context.TODO(), id)
+	c.Assert(err, check.IsNil)
+	c.Assert(deployEvt.Running, check.Equals, true)
+	c.Assert(deployEvt.ApprovalInfo.Required, check.Equals, true)
+	_, err = app.ApproveDeploy(context.TODO(), &a, evtID, "reviewer@example.com")
+	c.Assert(err, check.IsNil)
+	timeout := time.After(10 * time.Second)
+	for deployEvt.Running {
+		select {
+		case <-timeout:
+			c.Fatal("timeout waiting for approved deploy")
+		case <-time.After(100 * time.Millisecond):
+		}
+		deployEvt, err = event.GetByID(context.TODO(), id)
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(deployEvt.Error, check.Equals, "")
+	c.Assert(deployEvt.Log(), check.Matches, "(?s).*Builder deploy called.*")
+}
+
+func (s *DeploySuite) TestDeployApprove(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	deployEvt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go deployEvt.WaitApproval(ctx)
+	_, approver := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permTypes.Permission{
+		Scheme:  permission.PermAppAdminApproveDeploy,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	url := fmt.Sprintf("/apps/otherapp/deploys/%s/approve", deployEvt.UniqueID.Hex())
+	var recorder *httptest.ResponseRecorder
+	for i := 0; i < 50; i++ {
+		request, err := http.NewRequest("POST", url, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+approver.GetValue())
+		recorder = httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusConflict {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var status deployApprovalStatus
+	err = json.Unmarshal(recorder.Body.Bytes(), &status)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Deploy, check.Equals, deployEvt.UniqueID.Hex())
+	c.Assert(status.Approval.Approved, check.Equals, true)
+	c.Assert(status.Approval.Owner, check.Equals, approver.GetUserName())
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  approver.GetUserName(),
+		Kind:   "app.admin.approve-deploy",
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployApproveSelfApproval(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	deployEvt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploys/"+deployEvt.UniqueID.Hex()+"/approve", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrDeploySelfApproval.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployApproveDeployNotFound(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploys/"+primitive.NewObjectID().Hex()+"/approve", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployContinue(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	deployEvt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = deployEvt.TryPause(context.TODO(), "waiting to be continued within 10m0s", "tsuru.yaml")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/deploys/"+deployEvt.UniqueID.Hex()+"/continue", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var status deployPauseStatus
+	err = json.Unmarshal(recorder.Body.Bytes(), &status)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Deploy, check.Equals, deployEvt.UniqueID.Hex())
+	c.Assert(status.Pause.Paused, check.Equals, false)
+	paused, err := deployEvt.PauseRequested(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(paused, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy.resume",
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployContinueDeployNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/deploys/"+primitive.NewObjectID().Hex()+"/continue", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployBlueGreenConfirm(c *check.C) {
+	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		return newAppVersion(c, app), nil
+	}
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 2; i++ {
+		request, err := http.NewRequest("POST", "/apps/otherapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp&strategy=blue-green"))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	}
+	c.Assert(s.provisioner.PendingVersion(&a), check.Equals, 2)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy/confirm", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(s.provisioner.PendingVersion(&a), check.Equals, 0)
+	c.Assert(eventtest.EventDesc{
+		Target:        appTarget(a.Name),
+		Owner:         s.token.GetUserName(),
+		Kind:          "app.deploy.confirm",
+		EndCustomData: map[string]interface{}{"version": 2},
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployBlueGreenAbortNoPendingDeploy(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy/abort", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*`+provision.ErrNoPendingDeploy.Error()+`.*`)
+}
+
+func (s *DeploySuite) TestDeployBlueGreenWithNewVersion(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/otherapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp&strategy=blue-green&new-version=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*new-version and override-old-versions can't be used with the blue-green strategy.*`)
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const uploadOffsetHeader = "X-Tsuru-Upload-Offset"
+
+func deployUploadApp(r *http.Request, t auth.Token) (*appTypes.App, error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return nil, err
+	}
+	allowed := permission.Check(r.Context(), t, permission.PermAppDeployUpload, contextsForApp(a)...)
+	if !allowed {
+		return nil, permission.ErrUnauthorized
+	}
+	return a, nil
+}
+
+func getDeployUpload(r *http.Request, t auth.Token) (*appTypes.App, *app.DeployUpload, error) {
+	a, err := deployUploadApp(r, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	upload, err := app.GetDeployUpload(r.Context(), a, t.GetUserName(), r.URL.Query().Get(":id"))
+	if err == app.ErrDeployUploadNotFound {
+		return nil, nil, &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, upload, nil
+}
+
+func writeDeployUpload(w http.ResponseWriter, code int, upload *app.DeployUpload) error {
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(upload.Received, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(upload)
+}
+
+// title: start deploy upload
+// path: /apps/{app}/deploy/uploads
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	201: Upload started
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+//	413: Archive too large
+//	429: Too many uploads in progress
+func startDeployUpload(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	size, err := strconv.ParseInt(InputValue(r, "size"), 10, 64)
+	if err != nil || size <= 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "size must be a positive integer"}
+	}
+	a, err := deployUploadApp(r, t)
+	if err != nil {
+		return err
+	}
+	upload, err := app.NewDeployUpload(r.Context(), a, t.GetUserName(), size)
+	if _, ok := err.(*app.ErrDeployArchiveTooLarge); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusRequestEntityTooLarge, Message: err.Error()}
+	}
+	if err == app.ErrDeployUploadsLimit {
+		return &tsuruErrors.HTTP{Code: http.StatusTooManyRequests, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	return writeDeployUpload(w, http.StatusCreated, upload)
+}
+
+// title: deploy upload info
+// path: /apps/{app}/deploy/uploads/{id}
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Upload not found
+func deployUploadInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, upload, err := getDeployUpload(r, t)
+	if err != nil {
+		return err
+	}
+	return writeDeployUpload(w, http.StatusOK, upload)
+}
+
+// title: upload deploy chunk
+// path: /apps/{app}/deploy/uploads/{id}
+// method: PUT
+// consume: application/octet-stream
+// produce: application/json
+// responses:
+//
+//	200: Chunk stored
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Upload not found
+//	409: Offset does not match the upload
+func addDeployUploadChunk(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "offset must be a non-negative integer"}
+	}
+	_, upload, err := getDeployUpload(r, t)
+	if err != nil {
+		return err
+	}
+	err = upload.AddChunk(r.Context(), offset, r.Body)
+	if offsetErr, ok := err.(*app.ErrDeployUploadOffset); ok {
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(offsetErr.Expected, 10))
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	switch err {
+	case nil:
+		return writeDeployUpload(w, http.StatusOK, upload)
+	case app.ErrDeployUploadEmptyChunk, app.ErrDeployUploadTooLarge:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case app.ErrDeployUploadNotFound:
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: deploy upload
+// path: /apps/{app}/deploy/uploads/{id}
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Upload not found
+//	409: Upload incomplete
+func finishDeployUpload(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, upload, err := getDeployUpload(r, t)
+	if err != nil {
+		return err
+	}
+	archive, err := upload.Archive(r.Context())
+	if err == app.ErrDeployUploadIncomplete {
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(upload.Received, 10))
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	opts := app.DeployOptions{
+		File:     archive,
+		FileSize: upload.Size,
+	}
+	err = runDeploy(w, r, t, a.Name, opts)
+	if err != nil {
+		// The upload is kept when the deploy fails, so it can be retried
+		// without sending the archive again.
+		return err
+	}
+	if removeErr := upload.Remove(r.Context()); removeErr != nil {
+		log.Errorf("unable to remove deploy upload %s: %v", upload.ID.Hex(), removeErr)
+	}
+	return nil
+}
+
+// title: remove deploy upload
+// path: /apps/{app}/deploy/uploads/{id}
+// method: DELETE
+// responses:
+//
+//	200: Upload removed
+//	401: Unauthorized
+//	404: Upload not found
+func removeDeployUpload(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, upload, err := getDeployUpload(r, t)
+	if err != nil {
+		return err
+	}
+	return upload.Remove(r.Context())
+}
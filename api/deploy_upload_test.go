@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *DeploySuite) deployUploadRequest(c *check.C, method, url string, body io.Reader) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(method, url, body)
+	c.Assert(err, check.IsNil)
+	if method == http.MethodPost && body != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *DeploySuite) TestDeployUploadInChunks(c *check.C) {
+	var archive []byte
+	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		c.Assert(opts.ArchiveSize, check.Equals, int64(12))
+		var err error
+		archive, err = io.ReadAll(opts.ArchiveFile)
+		c.Assert(err, check.IsNil)
+		return newAppVersion(c, app), nil
+	}
+	a := appTypes.App{Name: "otherapp", Platform: "python", Router: "fake", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := s.deployUploadRequest(c, "POST", "/apps/otherapp/deploy/uploads", strings.NewReader("size=12"))
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var upload app.DeployUpload
+	err = json.NewDecoder(recorder.Body).Decode(&upload)
+	c.Assert(err, check.IsNil)
+	c.Assert(upload.Size, check.Equals, int64(12))
+	uploadURL := fmt.Sprintf("/apps/otherapp/deploy/uploads/%s", upload.ID.Hex())
+	recorder = s.deployUploadRequest(c, "PUT", uploadURL+"?offset=0", strings.NewReader("hello "))
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("X-Tsuru-Upload-Offset"), check.Equals, "6")
+	recorder = s.deployUploadRequest(c, "PUT", uploadURL+"?offset=0", strings.NewReader("hello "))
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Header().Get("X-Tsuru-Upload-Offset"), check.Equals, "6")
+	recorder = s.deployUploadRequest(c, "POST", uploadURL, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	recorder = s.deployUploadRequest(c, "PUT", uploadURL+"?offset=6", strings.NewReader("world!"))
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = s.deployUploadRequest(c, "POST", uploadURL, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, "(?s).*Builder deploy called\nOK\n")
+	c.Assert(string(archive), check.Equals, "hello world!")
+	recorder = s.deployUploadRequest(c, "GET", uploadURL, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployUploadChunkTooLarge(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	upload, err := app.NewDeployUpload(context.TODO(), &a, s.token.GetUserName(), 4)
+	c.Assert(err, check.IsNil)
+	uploadURL := fmt.Sprintf("/apps/otherapp/deploy/uploads/%s", upload.ID.Hex())
+	recorder := s.deployUploadRequest(c, "PUT", uploadURL+"?offset=0", strings.NewReader("hello"))
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrDeployUploadTooLarge.Error()+"\n")
+	recorder = s.deployUploadRequest(c, "GET", uploadURL, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("X-Tsuru-Upload-Offset"), check.Equals, "0")
+}
+
+func (s *DeploySuite) TestDeployUploadOtherUser(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	upload, err := app.NewDeployUpload(context.TODO(), &a, "someone@else.com", 4)
+	c.Assert(err, check.IsNil)
+	recorder := s.deployUploadRequest(c, "PUT", fmt.Sprintf("/apps/otherapp/deploy/uploads/%s?offset=0", upload.ID.Hex()), strings.NewReader("abcd"))
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestStartDeployUploadInvalidSize(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := s.deployUploadRequest(c, "POST", "/apps/otherapp/deploy/uploads", strings.NewReader("size=0"))
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// title: app discovery
+// path: /discovery/apps
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List in-cluster app endpoints
+//	204: No content
+//	401: Unauthorized
+func appDiscovery(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	filter := &app.Filter{}
+	if pool := r.URL.Query().Get("pool"); pool != "" {
+		filter.Pool = pool
+	}
+	if teamOwner := r.URL.Query().Get("teamOwner"); teamOwner != "" {
+		filter.TeamOwner = teamOwner
+	}
+	contexts := permission.ContextsForPermission(ctx, t, permission.PermAppRead)
+	contexts = append(contexts, permission.ContextsForPermission(ctx, t, permission.PermAppReadInfo)...)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	apps, err := app.List(ctx, appFilterByContext(contexts, filter))
+	if err != nil {
+		return err
+	}
+	if names := r.URL.Query()["app"]; len(names) > 0 {
+		apps = slices.DeleteFunc(apps, func(a *appTypes.App) bool {
+			return !slices.Contains(names, a.Name)
+		})
+	}
+	if len(apps) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	entries := app.Discover(ctx, apps, r.URL.Query().Get("version"))
+	w.Header().Set("Content-Type", "application/json")
+	if asEnvs, _ := strconv.ParseBool(r.URL.Query().Get("envs")); asEnvs {
+		return json.NewEncoder(w).Encode(app.DiscoveryEnvs(entries))
+	}
+	return json.NewEncoder(w).Encode(entries)
+}
+
+// title: set app discovery envs
+// path: /apps/{app}/discovery
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Envs updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func setAppDiscoveryEnvs(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	names, _ := InputValues(r, "app")
+	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateEnvSet,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	targets := make([]*appTypes.App, 0, len(names))
+	for _, name := range names {
+		if name == a.Name {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "An app cannot discover itself."}
+		}
+		target, err := getApp(ctx, name)
+		if err != nil {
+			return err
+		}
+		canRead := permission.Check(ctx, t, permission.PermAppRead, contextsForApp(target)...) ||
+			permission.Check(ctx, t, permission.PermAppReadInfo, contextsForApp(target)...)
+		if !canRead {
+			return permission.ErrUnauthorized
+		}
+		targets = append(targets, target)
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateEnvSet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = app.SetDiscoveryEnvs(ctx, a, targets, InputValue(r, "version"), evt, !noRestart)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppDiscovery(c *check.C) {
+	for _, name := range []string{"app1", "app2"} {
+		a := appTypes.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err := app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest(http.MethodGet, "/discovery/apps?app=app2&version=1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var entries []app.DiscoveryEntry
+	err = json.NewDecoder(recorder.Body).Decode(&entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].App, check.Equals, "app2")
+	c.Assert(entries[0].Endpoints, check.HasLen, 2)
+	c.Assert(entries[0].Endpoints[0].Address, check.Equals, "app2-web.fake-cluster.local:80")
+}
+
+func (s *S) TestAppDiscoveryAsEnvs(c *check.C) {
+	a := appTypes.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/discovery/apps?app=app1&version=1&envs=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var envs []bindTypes.EnvVar
+	err = json.NewDecoder(recorder.Body).Decode(&envs)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []bindTypes.EnvVar{
+		{Name: "TSURU_DISCOVERY_APP1_WEB", Value: "app1-web.fake-cluster.local:80", Public: true, ManagedBy: "tsuru/discovery"},
+		{Name: "TSURU_DISCOVERY_APP1_LOGS", Value: "app1-logs.fake-cluster.local:12201", Public: true, ManagedBy: "tsuru/discovery"},
+	})
+}
+
+func (s *S) TestAppDiscoveryNoApps(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/discovery/apps?app=unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestSetAppDiscoveryEnvs(c *check.C) {
+	for _, name := range []string{"caller", "callee"} {
+		a := appTypes.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err := app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	body := strings.NewReader(url.Values{"app": []string{"callee"}, "noRestart": []string{"true"}}.Encode())
+	request, err := http.NewRequest(http.MethodPost, "/apps/caller/discovery", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	a, err := app.GetByName(context.TODO(), "caller")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Env["TSURU_DISCOVERY_CALLEE_WEB"], check.DeepEquals, bindTypes.EnvVar{
+		Name: "TSURU_DISCOVERY_CALLEE_WEB", Value: "callee-web.fake-cluster.local:80", Public: true, ManagedBy: "tsuru/discovery",
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("caller"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.env.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "caller"},
+			{"name": "app", "value": "callee"},
+			{"name": "noRestart", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetAppDiscoveryEnvsItself(c *check.C) {
+	a := appTypes.App{Name: "caller", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(url.Values{"app": []string{"caller"}}.Encode())
+	request, err := http.NewRequest(http.MethodPost, "/apps/caller/discovery", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
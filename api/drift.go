@@ -0,0 +1,53 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: pool drift
+// path: /pools/{name}/drift
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No drift found
+//	401: Unauthorized
+//	404: Pool not found
+func poolDrift(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolReadDrift, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(ctx, poolName)
+	if err != nil {
+		if err == pool.ErrPoolNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	drifts, err := app.PoolDrift(ctx, poolName, nil, false)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(drifts)
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolDrift(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddDrift(&a, provision.Drift{
+		App:     "myapp",
+		Kind:    provision.DriftKindDeployment,
+		Name:    "myapp-web",
+		Process: "web",
+		Message: `deployment myapp-web runs image "nginx", expected "tsuru/app-myapp:v1"`,
+	})
+	request, err := http.NewRequest("GET", "/pools/test1/drift", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var drifts []provision.Drift
+	err = json.Unmarshal(recorder.Body.Bytes(), &drifts)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.DeepEquals, []provision.Drift{
+		{App: "myapp", Kind: "deployment", Name: "myapp-web", Process: "web", Message: `deployment myapp-web runs image "nginx", expected "tsuru/app-myapp:v1"`},
+	})
+}
+
+func (s *S) TestPoolDriftNoDrift(c *check.C) {
+	request, err := http.NewRequest("GET", "/pools/test1/drift", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestPoolDriftPoolNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/pools/unknown/drift", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
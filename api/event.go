@@ -10,16 +10,12 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/tsuru/config"
-	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
 )
 
 // title: event list
@@ -52,7 +48,7 @@ func eventList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		return nil
 	}
 	for _, event := range events {
-		err = suppressSensitiveEnvs(event)
+		err = event.Redact(filter.Permissions)
 		if err != nil {
 			return err
 		}
@@ -112,16 +108,33 @@ func eventInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
-	w.Header().Add("Content-Type", "application/json")
-	err = suppressSensitiveEnvs(e)
+	perms, err := t.Permissions(ctx)
+	if err != nil {
+		return err
+	}
+	err = e.Redact(perms)
 	if err != nil {
 		return err
 	}
+	w.Header().Add("Content-Type", "application/json")
 
 	eventInfo, err := event.EventInfo(e)
 	if err != nil {
 		return err
 	}
+	if chain, _ := strconv.ParseBool(r.URL.Query().Get("chain")); chain {
+		evts, err := event.Chain(ctx, e)
+		if err != nil {
+			return err
+		}
+		for _, evt := range evts {
+			scheme, err := permission.SafeGet(evt.Allowed.Scheme)
+			if err != nil || !permission.Check(ctx, t, scheme, evt.Allowed.Contexts...) {
+				continue
+			}
+			eventInfo.Chain = append(eventInfo.Chain, evt.ChainLink())
+		}
+	}
 
 	return json.NewEncoder(w).Encode(eventInfo)
 }
@@ -170,6 +183,56 @@ func eventCancel(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return nil
 }
 
+// title: event annotate
+// path: /events/{uuid}/annotations
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	201: Annotation added
+//	400: Invalid uuid or message
+//	401: Unauthorized
+//	404: Not found
+//	409: Too many annotations
+func eventAnnotate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	uuid := r.URL.Query().Get(":uuid")
+	if _, err := primitive.ObjectIDFromHex(uuid); err != nil {
+		msg := fmt.Sprintf("uuid parameter is not ObjectId: %s", uuid)
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	e, err := event.GetByHexID(ctx, uuid)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	scheme, err := permission.SafeGet(e.Allowed.Scheme)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, scheme, e.Allowed.Contexts...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	acknowledged, _ := strconv.ParseBool(InputValue(r, "acknowledged"))
+	annotation, err := e.Annotate(ctx, t.GetUserName(), InputValue(r, "message"), acknowledged)
+	switch err {
+	case nil:
+	case event.ErrEventNotFound:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case event.ErrTooManyAnnotations:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	default:
+		if _, ok := err.(event.ErrValidation); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(annotation)
+}
+
 // title: event block list
 // path: /events/blocks
 // method: GET
@@ -285,31 +348,3 @@ func eventBlockRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	}
 	return err
 }
-
-func suppressSensitiveEnvs(e *event.Event) error {
-	if supressEnabled, _ := config.GetBool("events:suppress-sensitive-envs"); !supressEnabled {
-		return nil
-	}
-	if e.Kind.Name != permission.PermAppDeploy.FullName() || len(e.StartCustomData.Value) == 0 {
-		return nil
-	}
-
-	deployOptions := &app.DeployOptions{}
-
-	err := e.StartCustomData.Unmarshal(deployOptions)
-	if err != nil {
-		return err
-	}
-
-	if deployOptions.App == nil {
-		return nil
-	}
-
-	app.SuppressSensitiveEnvs(deployOptions.App)
-
-	e.StartCustomData.Type, e.StartCustomData.Value, err = mongoBSON.MarshalValue(deployOptions)
-	if err != nil {
-		return err
-	}
-	return nil
-}
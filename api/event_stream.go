@@ -0,0 +1,219 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	eventStreamRunning = "running"
+	eventStreamDone    = "done"
+
+	// eventStreamOverlap is how far behind the newest timestamp already
+	// streamed each poll looks, so events written by API instances with
+	// skewed clocks aren't missed. Events seen twice are only sent once.
+	eventStreamOverlap = 5 * time.Second
+)
+
+var (
+	eventStreamInterval  = time.Second
+	eventStreamKeepAlive = 30 * time.Second
+
+	eventStreamsCurrent = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tsuru_events_stream_current",
+		Help: "The current number of clients streaming events",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(eventStreamsCurrent)
+}
+
+type eventStreamTracker struct {
+	sync.Mutex
+	streams map[*eventStreamer]struct{}
+}
+
+func (t *eventStreamTracker) add(s *eventStreamer) {
+	t.Lock()
+	defer t.Unlock()
+	if t.streams == nil {
+		t.streams = make(map[*eventStreamer]struct{})
+	}
+	t.streams[s] = struct{}{}
+}
+
+func (t *eventStreamTracker) remove(s *eventStreamer) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.streams, s)
+}
+
+func (t *eventStreamTracker) String() string {
+	return "event stream connections"
+}
+
+func (t *eventStreamTracker) Shutdown(ctx context.Context) error {
+	t.Lock()
+	defer t.Unlock()
+	for s := range t.streams {
+		s.cancel()
+	}
+	return nil
+}
+
+var eventStreams eventStreamTracker
+
+// eventStreamer sends events matching a filter as they start and finish. It
+// polls the events collection, so events created by every API instance are
+// streamed.
+type eventStreamer struct {
+	filter event.Filter
+	// from is when the stream starts, events started or finished earlier are
+	// never sent.
+	from   time.Time
+	cursor time.Time
+	// seen holds the events sent in the overlap window, by id and state,
+	// along with the time they were sent for.
+	seen   map[string]time.Time
+	w      io.Writer
+	cancel context.CancelFunc
+}
+
+func (s *eventStreamer) poll(ctx context.Context) (int, error) {
+	since := s.cursor.Add(-eventStreamOverlap)
+	filter := s.filter
+	filter.Sort = "starttime"
+	filter.Raw = mongoBSON.M{"$or": []mongoBSON.M{
+		{"starttime": mongoBSON.M{"$gt": since}},
+		{"endtime": mongoBSON.M{"$gt": since}},
+	}}
+	events, err := event.List(ctx, &filter)
+	if err != nil {
+		return 0, err
+	}
+	var sent int
+	for _, evt := range events {
+		state, at := eventStreamDone, evt.EndTime
+		if evt.Running {
+			state, at = eventStreamRunning, evt.StartTime
+		}
+		key := evt.UniqueID.Hex() + "/" + state
+		if _, ok := s.seen[key]; ok || !at.After(since) || !at.After(s.from) {
+			continue
+		}
+		err = evt.Redact(s.filter.Permissions)
+		if err != nil {
+			return sent, err
+		}
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return sent, err
+		}
+		_, err = fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", evt.UniqueID.Hex(), state, data)
+		if err != nil {
+			return sent, err
+		}
+		sent++
+		s.seen[key] = at
+		if at.After(s.cursor) {
+			s.cursor = at
+		}
+	}
+	for key, at := range s.seen {
+		if at.Before(s.cursor.Add(-eventStreamOverlap)) {
+			delete(s.seen, key)
+		}
+	}
+	return sent, nil
+}
+
+// title: event stream
+// path: /events/stream
+// method: GET
+// produce: text/event-stream
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+func eventStream(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	var filter *event.Filter
+	err := ParseInput(r, &filter)
+	if err != nil {
+		return err
+	}
+	filter.LoadKindNames(r.Form)
+	filter.PruneUserValues()
+	filter.Permissions, err = t.Permissions(ctx)
+	if err != nil {
+		return err
+	}
+	streamCtx, cancel := context.WithCancel(tsuruNet.CancelableParentContext(ctx))
+	defer cancel()
+	from := time.Now().UTC()
+	if !filter.Since.IsZero() {
+		from = filter.Since
+	}
+	streamer := &eventStreamer{
+		filter: *filter,
+		from:   from,
+		cursor: from,
+		seen:   map[string]time.Time{},
+		w:      w,
+		cancel: cancel,
+	}
+	streamer.filter.Since, streamer.filter.Until = time.Time{}, time.Time{}
+	eventStreams.add(streamer)
+	defer eventStreams.remove(streamer)
+	eventStreamsCurrent.Inc()
+	defer eventStreamsCurrent.Dec()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", eventStreamInterval.Milliseconds())
+	if err != nil {
+		return nil
+	}
+	pollTicker := time.NewTicker(eventStreamInterval)
+	defer pollTicker.Stop()
+	lastWrite := time.Now()
+	for {
+		sent, err := streamer.poll(streamCtx)
+		if streamCtx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if sent > 0 {
+			lastWrite = time.Now()
+		} else if time.Since(lastWrite) >= eventStreamKeepAlive {
+			_, err = io.WriteString(w, ": keepalive\n\n")
+			if err != nil {
+				return nil
+			}
+			lastWrite = time.Now()
+		}
+		select {
+		case <-streamCtx.Done():
+			return nil
+		case <-pollTicker.C:
+		}
+	}
+}
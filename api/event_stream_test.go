@@ -0,0 +1,133 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+type streamedEvent struct {
+	id, state string
+	evt       *event.Event
+}
+
+func parseEventStream(c *check.C, body string) []streamedEvent {
+	var result []streamedEvent
+	for _, msg := range strings.Split(body, "\n\n") {
+		sEvt := streamedEvent{evt: &event.Event{}}
+		for _, line := range strings.Split(msg, "\n") {
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				sEvt.id = value
+			case "event":
+				sEvt.state = value
+			case "data":
+				err := json.Unmarshal([]byte(value), sEvt.evt)
+				c.Assert(err, check.IsNil)
+			}
+		}
+		if sEvt.id != "" {
+			result = append(result, sEvt)
+		}
+	}
+	return result
+}
+
+func (s *EventSuite) streamEvents(c *check.C, query string, duration time.Duration) *httptest.ResponseRecorder {
+	oldInterval := eventStreamInterval
+	eventStreamInterval = 10 * time.Millisecond
+	defer func() { eventStreamInterval = oldInterval }()
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, "GET", "/1.25/events/stream?"+query, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *EventSuite) TestEventStreamSince(c *check.C) {
+	evts, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	_, err = s.insertEvents("node", nil, c)
+	c.Assert(err, check.IsNil)
+	since := url.QueryEscape(time.Now().Add(-time.Minute).Format(time.RFC3339))
+	recorder := s.streamEvents(c, "since="+since, 200*time.Millisecond)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/event-stream")
+	c.Assert(strings.HasPrefix(recorder.Body.String(), "retry: 10\n\n"), check.Equals, true)
+	streamed := parseEventStream(c, recorder.Body.String())
+	c.Assert(streamed, check.HasLen, 10)
+	states := map[string]string{}
+	for _, sEvt := range streamed {
+		c.Assert(sEvt.evt.Target.Type, check.Equals, eventTypes.TargetTypeApp)
+		c.Assert(sEvt.evt.UniqueID.Hex(), check.Equals, sEvt.id)
+		states[sEvt.id] = sEvt.state
+	}
+	c.Assert(states, check.HasLen, 10)
+	c.Assert(states[evts[0].UniqueID.Hex()], check.Equals, "running")
+	c.Assert(states[evts[1].UniqueID.Hex()], check.Equals, "done")
+}
+
+func (s *EventSuite) TestEventStreamFilter(c *check.C) {
+	_, err := s.insertEvents("app", []*permTypes.PermissionScheme{permission.PermAppDeploy, permission.PermAppUpdateEnvSet}, c)
+	c.Assert(err, check.IsNil)
+	since := url.QueryEscape(time.Now().Add(-time.Minute).Format(time.RFC3339))
+	recorder := s.streamEvents(c, "since="+since+"&kindName=app.update.env.set&target.value=app-1", 200*time.Millisecond)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	streamed := parseEventStream(c, recorder.Body.String())
+	c.Assert(streamed, check.HasLen, 1)
+	c.Assert(streamed[0].state, check.Equals, "done")
+	c.Assert(streamed[0].evt.Target.Value, check.Equals, "app-1")
+	c.Assert(streamed[0].evt.Kind.Name, check.Equals, "app.update.env.set")
+}
+
+func (s *EventSuite) TestEventStreamFollow(c *check.C) {
+	_, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	created := make(chan *event.Event, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+			Owner:   s.token,
+			Kind:    permission.PermAppDeploy,
+			Allowed: event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+		})
+		if err != nil {
+			created <- nil
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+		evt.Done(context.TODO(), fmt.Errorf("deploy failed"))
+		created <- evt
+	}()
+	recorder := s.streamEvents(c, "", 500*time.Millisecond)
+	evt := <-created
+	c.Assert(evt, check.NotNil)
+	streamed := parseEventStream(c, recorder.Body.String())
+	c.Assert(streamed, check.HasLen, 2)
+	c.Assert(streamed[0].id, check.Equals, evt.UniqueID.Hex())
+	c.Assert(streamed[0].state, check.Equals, "running")
+	c.Assert(streamed[1].id, check.Equals, evt.UniqueID.Hex())
+	c.Assert(streamed[1].state, check.Equals, "done")
+	c.Assert(streamed[1].evt.Error, check.Equals, "deploy failed")
+}
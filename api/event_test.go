@@ -426,8 +426,6 @@ func (s *EventSuite) TestEventInfoPermission(c *check.C) {
 }
 
 func (s *EventSuite) TestEventInfoPermissionWithSensitiveData(c *check.C) {
-	config.Set("events:suppress-sensitive-envs", true)
-	defer config.Unset("events:suppress-sensitive-envs")
 	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
 		Scheme:  permission.PermAppRead,
 		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
@@ -528,6 +526,53 @@ func (s *EventSuite) TestEventInfoWithoutPermission(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
 }
 
+func (s *EventSuite) TestEventInfoChain(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	parent, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha"},
+		Owner:   s.token,
+		Kind:    permission.PermAppDeploy,
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+	})
+	c.Assert(err, check.IsNil)
+	ctx := event.WithParent(context.TODO(), parent)
+	child, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha-child"},
+		InternalKind: "restart",
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "hidden"},
+		InternalKind: "restart",
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, "some-other-team")),
+	})
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("/events/%s?chain=true", child.UniqueID.Hex())
+	request, err := http.NewRequest("GET", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result struct {
+		ParentID string
+		Chain    []eventTypes.ChainLink
+	}
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ParentID, check.Equals, parent.UniqueID.Hex())
+	c.Assert(result.Chain, check.HasLen, 2)
+	c.Assert(result.Chain[0].UniqueID, check.Equals, parent.UniqueID)
+	c.Assert(result.Chain[0].Kind.Name, check.Equals, "app.deploy")
+	c.Assert(result.Chain[1].UniqueID, check.Equals, child.UniqueID)
+	c.Assert(result.Chain[1].ParentID, check.Equals, parent.UniqueID.Hex())
+}
+
 func (s *EventSuite) TestEventCancelPermission(c *check.C) {
 	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
 		Scheme:  permission.PermAppUpdate,
@@ -796,3 +841,88 @@ func addBlocks(c *check.C) []*event.Block {
 	}
 	return blocks
 }
+
+func (s *EventSuite) TestEventAnnotate(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("message=known flaky, retried&acknowledged=true")
+	u := fmt.Sprintf("/1.25/events/%s/annotations", events[1].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var annotation eventTypes.Annotation
+	err = json.Unmarshal(recorder.Body.Bytes(), &annotation)
+	c.Assert(err, check.IsNil)
+	c.Assert(annotation.Author, check.Equals, s.token.GetUserName())
+	c.Assert(annotation.Message, check.Equals, "known flaky, retried")
+	c.Assert(annotation.Acknowledged, check.Equals, true)
+	request, err = http.NewRequest("GET", "/events/"+events[1].UniqueID.Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var info eventTypes.EventInfo
+	err = json.Unmarshal(recorder.Body.Bytes(), &info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Annotations, check.HasLen, 1)
+	c.Assert(info.Annotations[0].Message, check.Equals, "known flaky, retried")
+}
+
+func (s *EventSuite) TestEventAnnotateNoMessage(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("message=")
+	u := fmt.Sprintf("/1.25/events/%s/annotations", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "annotation message is mandatory\n")
+}
+
+func (s *EventSuite) TestEventAnnotateNotFound(c *check.C) {
+	body := strings.NewReader("message=hi")
+	u := fmt.Sprintf("/1.25/events/%s/annotations", primitive.NewObjectID().Hex())
+	request, err := http.NewRequest("POST", u, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *EventSuite) TestEventAnnotateWithoutPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, "some-other-team"),
+	})
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha"},
+		Owner:   s.token,
+		Kind:    permission.PermAppDeploy,
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+	})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("message=hi")
+	u := fmt.Sprintf("/1.25/events/%s/annotations", evt.UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/directory"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/set"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+type groupSyncer struct {
+	dir      directory.Directory
+	interval time.Duration
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// startGroupSyncer starts the periodic synchronization of group members with
+// the external directory configured in auth:group-sync, if any.
+func startGroupSyncer() error {
+	dir, err := directory.FromConfig()
+	if err == directory.ErrNotConfigured {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s := &groupSyncer{
+		dir:      dir,
+		interval: directory.SyncInterval(),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go s.spin()
+	shutdown.Register(s)
+	return nil
+}
+
+func (s *groupSyncer) spin() {
+	defer close(s.doneCh)
+	for {
+		err := syncGroups(context.Background(), s.dir)
+		if err != nil {
+			log.Errorf("[group-sync] %v", err)
+		}
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+func (s *groupSyncer) Shutdown(ctx context.Context) error {
+	close(s.stopCh)
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// syncGroups reconciles the members of the groups with roles in tsuru with
+// the directory. Members of groups removed from the directory, or without
+// roles anymore, are removed as well.
+func syncGroups(ctx context.Context, dir directory.Directory) error {
+	dirGroups, err := dir.Groups(ctx)
+	if err != nil {
+		return err
+	}
+	groups, err := servicemanager.AuthGroup.List(ctx, nil)
+	if err != nil {
+		return err
+	}
+	synced, err := auth.ListDirectoryGroups(ctx)
+	if err != nil {
+		return err
+	}
+	members := map[string][]string{}
+	for _, name := range synced {
+		members[name] = nil
+	}
+	for _, group := range groups {
+		if emails, ok := dirGroups[group.Name]; ok {
+			members[group.Name] = emails
+		}
+	}
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	multi := errors.NewMultiError()
+	for _, name := range names {
+		err = syncGroupMembers(ctx, name, members[name])
+		if err != nil {
+			multi.Add(pkgErrors.Wrapf(err, "unable to sync members of group %q", name))
+		}
+	}
+	return multi.ToError()
+}
+
+func syncGroupMembers(ctx context.Context, group string, emails []string) (err error) {
+	users, err := auth.ListUsersInDirectoryGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+	current := set.Set{}
+	for _, u := range users {
+		current.Add(u.Email)
+	}
+	desired := set.FromSlice(emails)
+	removed := current.Difference(desired).Sorted()
+	var added []string
+	if missing := desired.Difference(current).Sorted(); len(missing) > 0 {
+		// members not registered in tsuru are ignored until they are.
+		users, err = auth.ListUsersByEmail(ctx, missing)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			added = append(added, u.Email)
+		}
+		sort.Strings(added)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGroup, Value: group},
+		InternalKind: "group membership sync",
+		CustomData: map[string]interface{}{
+			"added":   added,
+			"removed": removed,
+		},
+		Allowed:     event.Allowed(permission.PermRoleReadEvents),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = auth.AddUsersToDirectoryGroup(ctx, group, added)
+	if err != nil {
+		return err
+	}
+	return auth.RemoveUsersFromDirectoryGroup(ctx, group, removed)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+type fakeDirectory struct {
+	groups map[string][]string
+}
+
+func (d *fakeDirectory) Groups(ctx context.Context) (map[string][]string, error) {
+	return d.groups, nil
+}
+
+func (s *S) TestSyncGroups(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "team-member", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app.read")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(ctx, "developers", "team-member", s.team.Name)
+	c.Assert(err, check.IsNil)
+	for _, email := range []string{"alice@corp.com", "bob@corp.com"} {
+		u := &auth.User{Email: email}
+		err = u.Create(ctx)
+		c.Assert(err, check.IsNil)
+	}
+	dir := &fakeDirectory{groups: map[string][]string{
+		"developers": {"alice@corp.com", "bob@corp.com", "unknown@corp.com"},
+		"unmapped":   {"alice@corp.com"},
+	}}
+	err = syncGroups(ctx, dir)
+	c.Assert(err, check.IsNil)
+	alice, err := auth.GetUserByEmail(ctx, "alice@corp.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(alice.DirectoryGroups, check.DeepEquals, []string{"developers"})
+	perms, err := alice.Permissions(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppRead, permission.Context("team", s.team.Name)), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeGroup, Value: "developers"},
+		Kind:   "group membership sync",
+		StartCustomData: map[string]interface{}{
+			"added":   []interface{}{"alice@corp.com", "bob@corp.com"},
+			"removed": nil,
+		},
+	}, eventtest.HasEvent)
+	dir.groups["developers"] = []string{"bob@corp.com"}
+	err = syncGroups(ctx, dir)
+	c.Assert(err, check.IsNil)
+	alice, err = auth.GetUserByEmail(ctx, "alice@corp.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(alice.DirectoryGroups, check.HasLen, 0)
+	bob, err := auth.GetUserByEmail(ctx, "bob@corp.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(bob.DirectoryGroups, check.DeepEquals, []string{"developers"})
+	delete(dir.groups, "developers")
+	err = syncGroups(ctx, dir)
+	c.Assert(err, check.IsNil)
+	bob, err = auth.GetUserByEmail(ctx, "bob@corp.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(bob.DirectoryGroups, check.HasLen, 0)
+}
+
+func (s *S) TestSyncGroupsUnchanged(c *check.C) {
+	ctx := context.TODO()
+	_, err := permission.NewRole(ctx, "team-member", "team", "")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(ctx, "developers", "team-member", s.team.Name)
+	c.Assert(err, check.IsNil)
+	dir := &fakeDirectory{groups: map[string][]string{
+		"developers": {"unknown@corp.com"},
+	}}
+	err = syncGroups(ctx, dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeGroup, Value: "developers"},
+		Kind:   "group membership sync",
+	}, check.Not(eventtest.HasEvent))
+}
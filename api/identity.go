@@ -0,0 +1,111 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+type identityToken struct {
+	Token     string              `json:"token"`
+	ExpiresAt time.Time           `json:"expiresAt"`
+	Claims    *app.IdentityClaims `json:"claims"`
+}
+
+func identityError(err error) error {
+	switch pkgErrors.Cause(err) {
+	case app.ErrIdentityNotConfigured:
+		return &errors.HTTP{Code: http.StatusServiceUnavailable, Message: err.Error()}
+	case app.ErrIdentityNotSupported:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case app.ErrInvalidWorkloadToken, app.ErrInvalidIdentityToken:
+		return &errors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app identity token
+// path: /apps/{app}/identity/token
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Token created
+//	400: Invalid data
+//	401: Invalid workload token
+//	404: App not found
+func appIdentityToken(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	workloadToken := InputValue(r, "workloadToken")
+	if workloadToken == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "workloadToken is required"}
+	}
+	audience, _ := InputValues(r, "audience")
+	a, err := app.GetByName(ctx, r.URL.Query().Get(":app"))
+	if err != nil {
+		if err == appTypes.ErrAppNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	token, claims, err := app.MintIdentityToken(ctx, a, workloadToken, audience)
+	if err != nil {
+		return identityError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(identityToken{
+		Token:     token,
+		ExpiresAt: claims.RegisteredClaims.ExpiresAt.Time,
+		Claims:    claims,
+	})
+}
+
+// title: verify identity token
+// path: /identity/verify
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Token is valid
+//	400: Invalid data
+//	401: Invalid token
+func verifyIdentityToken(w http.ResponseWriter, r *http.Request) error {
+	token := InputValue(r, "token")
+	if token == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "token is required"}
+	}
+	claims, err := app.VerifyIdentityToken(token, InputValue(r, "audience"))
+	if err != nil {
+		return identityError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(claims)
+}
+
+// title: identity token keys
+// path: /identity/jwks
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	503: Workload identity not configured
+func identityJWKS(w http.ResponseWriter, r *http.Request) error {
+	keys, err := app.IdentityJWKS()
+	if err != nil {
+		return identityError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(keys)
+}
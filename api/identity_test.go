@@ -0,0 +1,110 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupIdentityKey(c *check.C) func() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	c.Assert(err, check.IsNil)
+	keyFile := filepath.Join(c.MkDir(), "identity.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	c.Assert(err, check.IsNil)
+	config.Set("workload-identity:private-key-file", keyFile)
+	return func() {
+		config.Unset("workload-identity")
+	}
+}
+
+func (s *S) TestAppIdentityTokenAndVerify(c *check.C) {
+	defer s.setupIdentityKey(c)()
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(url.Values{"workloadToken": {"fake-workload-token-myapp"}, "audience": {"billing"}}.Encode())
+	request, err := http.NewRequest(http.MethodPost, "/apps/myapp/identity/token", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var result struct {
+		Token  string
+		Claims map[string]interface{}
+	}
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Claims["app"], check.Equals, "myapp")
+	c.Assert(result.Claims["team"], check.Equals, s.team.Name)
+	body = strings.NewReader(url.Values{"token": {result.Token}, "audience": {"billing"}}.Encode())
+	request, err = http.NewRequest(http.MethodPost, "/identity/verify", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	body = strings.NewReader(url.Values{"token": {result.Token}, "audience": {"other"}}.Encode())
+	request, err = http.NewRequest(http.MethodPost, "/identity/verify", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
+
+func (s *S) TestAppIdentityTokenInvalidWorkloadToken(c *check.C) {
+	defer s.setupIdentityKey(c)()
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(url.Values{"workloadToken": {"stolen"}}.Encode())
+	request, err := http.NewRequest(http.MethodPost, "/apps/myapp/identity/token", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
+
+func (s *S) TestIdentityJWKS(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/identity/jwks", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusServiceUnavailable)
+	defer s.setupIdentityKey(c)()
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var jwks struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	err = json.NewDecoder(recorder.Body).Decode(&jwks)
+	c.Assert(err, check.IsNil)
+	c.Assert(jwks.Keys, check.HasLen, 1)
+	c.Assert(jwks.Keys[0]["alg"], check.Equals, "ES256")
+	c.Assert(jwks.Keys[0]["kty"], check.Equals, "EC")
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: orphan resources
+// path: /provisioner/orphans
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No orphan resources found
+//	401: Unauthorized
+func orphanResources(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterReadOrphans)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	orphans, err := app.OrphanResources(ctx, false)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(orphans)
+}
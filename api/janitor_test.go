@@ -0,0 +1,49 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestOrphanResources(c *check.C) {
+	s.provisioner.AddOrphanResources(provision.OrphanResource{
+		Cluster:   "c1",
+		Namespace: "tsuru",
+		Kind:      "service",
+		Name:      "ghost-web",
+		Reason:    `app "ghost" not found`,
+	})
+	request, err := http.NewRequest("GET", "/provisioner/orphans", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var orphans []provision.OrphanResource
+	err = json.Unmarshal(recorder.Body.Bytes(), &orphans)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.DeepEquals, []provision.OrphanResource{
+		{Cluster: "c1", Namespace: "tsuru", Kind: "service", Name: "ghost-web", Reason: `app "ghost" not found`},
+	})
+	remaining, err := s.provisioner.OrphanResources(request.Context())
+	c.Assert(err, check.IsNil)
+	c.Assert(remaining, check.HasLen, 1)
+}
+
+func (s *S) TestOrphanResourcesNoOrphans(c *check.C) {
+	request, err := http.NewRequest("GET", "/provisioner/orphans", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
@@ -46,6 +46,7 @@ type inputJob struct {
 	Metadata    appTypes.Metadata `json:"metadata"`
 
 	DeployOptions *jobTypes.DeployOptions `json:"deployOptions"`
+	Notifications jobTypes.Notifications  `json:"notifications"`
 
 	Container             jobTypes.ContainerInfo `json:"container"`
 	Schedule              string                 `json:"schedule"`
@@ -170,7 +171,7 @@ func jobTrigger(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	defer func() { evt.Done(ctx, err) }()
 	err = servicemanager.Job.Trigger(ctx, j)
 	if err != nil {
-		return err
+		return jobQuotaError(err)
 	}
 	msg := map[string]interface{}{
 		"status": "success",
@@ -368,12 +369,13 @@ func updateJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		return err
 	}
 	newJob := jobTypes.Job{
-		TeamOwner:   ij.TeamOwner,
-		Plan:        appTypes.Plan{Name: ij.Plan},
-		Name:        name,
-		Description: ij.Description,
-		Pool:        ij.Pool,
-		Metadata:    ij.Metadata,
+		TeamOwner:     ij.TeamOwner,
+		Plan:          appTypes.Plan{Name: ij.Plan},
+		Name:          name,
+		Description:   ij.Description,
+		Pool:          ij.Pool,
+		Metadata:      ij.Metadata,
+		Notifications: ij.Notifications,
 		Spec: jobTypes.JobSpec{
 			ConcurrencyPolicy:     ij.ConcurrencyPolicy,
 			Schedule:              ij.Schedule,
@@ -397,7 +399,7 @@ func updateJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Target:     jobTarget(newJob.Name),
 		Kind:       permission.PermJobUpdate,
 		Owner:      t,
-		CustomData: event.FormToCustomData(InputFields(r)),
+		CustomData: event.FormToCustomData(InputFields(r, "notifications.webhookURL")),
 		Allowed:    event.Allowed(permission.PermJobReadEvents, contextsForJob(&newJob)...),
 	})
 	if err != nil {
@@ -408,7 +410,7 @@ func updateJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	}()
 	err = servicemanager.Job.UpdateJob(ctx, &newJob, oldJob, user)
 	if err != nil {
-		return err
+		return jobQuotaError(err)
 	}
 	updatedJob, err := getJob(ctx, name)
 	if err != nil {
@@ -453,6 +455,7 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Pool:          ij.Pool,
 		Metadata:      ij.Metadata,
 		DeployOptions: ij.DeployOptions,
+		Notifications: ij.Notifications,
 		Spec: jobTypes.JobSpec{
 			ConcurrencyPolicy: ij.ConcurrencyPolicy,
 			Manual:            ij.Manual,
@@ -483,7 +486,7 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Target:        jobTarget(j.Name),
 		Kind:          permission.PermJobCreate,
 		Owner:         t,
-		CustomData:    event.FormToCustomData(InputFields(r)),
+		CustomData:    event.FormToCustomData(InputFields(r, "notifications.webhookURL")),
 		RemoteAddr:    r.RemoteAddr,
 		Allowed:       event.Allowed(permission.PermJobReadEvents, contextsForJob(j)...),
 		AllowedCancel: event.Allowed(permission.PermJobUpdateEvents, contextsForJob(j)...),
@@ -500,7 +503,7 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		if err == jobTypes.ErrJobAlreadyExists {
 			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
 		}
-		return err
+		return jobQuotaError(err)
 	}
 	if err != nil {
 		return err
@@ -1001,6 +1004,77 @@ func jobLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return followLogs(tsuruNet.CancelableParentContext(r.Context()), j.Name, watcher, encoder)
 }
 
+// title: job executions
+// path: /jobs/{name}/executions
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Not found
+func jobExecutions(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: `Parameter "limit" must be an integer.`}
+		}
+	}
+	executions, err := job.ListExecutions(ctx, j, limit)
+	if err != nil {
+		return err
+	}
+	if len(executions) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(executions)
+}
+
+// title: job execution logs
+// path: /jobs/{name}/executions/{id}/logs
+// method: GET
+// produce: application/x-json-stream
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Not found
+func jobExecutionLogs(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobReadLogs, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	logs, err := job.ExecutionLogs(ctx, j, r.URL.Query().Get(":id"))
+	if err != nil {
+		if err == job.ErrExecutionNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	if logs == nil {
+		logs = []appTypes.Applog{}
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	return json.NewEncoder(w).Encode(logs)
+}
+
 func jobTarget(jobName string) eventTypes.Target {
 	return eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: jobName}
 }
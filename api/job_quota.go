@@ -0,0 +1,191 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/job"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: team job quota
+// path: /teams/{name}/job-quota
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Team not found
+func getTeamJobQuota(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermTeamReadQuota, permission.Context(permTypes.CtxTeam, teamName)) {
+		return permission.ErrUnauthorized
+	}
+	if err := ensureTeamExists(r, teamName); err != nil {
+		return err
+	}
+	return writeJobQuotaUsage(w, r, jobTypes.QuotaScopeTeam, teamName)
+}
+
+// title: update team job quota
+// path: /teams/{name}/job-quota
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Quota updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team not found
+func changeTeamJobQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermTeamUpdateQuota, permission.Context(permTypes.CtxTeam, teamName)) {
+		return permission.ErrUnauthorized
+	}
+	if err = ensureTeamExists(r, teamName); err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     teamTarget(teamName),
+		Kind:       permission.PermTeamUpdateQuota,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return setJobQuota(r, jobTypes.QuotaScopeTeam, teamName)
+}
+
+// title: pool job quota
+// path: /pools/{name}/job-quota
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Pool not found
+func getPoolJobQuota(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermPoolReadQuota, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	if err := ensurePoolExists(r, poolName); err != nil {
+		return err
+	}
+	return writeJobQuotaUsage(w, r, jobTypes.QuotaScopePool, poolName)
+}
+
+// title: update pool job quota
+// path: /pools/{name}/job-quota
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Quota updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+func changePoolJobQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermPoolUpdateQuota, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	if err = ensurePoolExists(r, poolName); err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateQuota,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return setJobQuota(r, jobTypes.QuotaScopePool, poolName)
+}
+
+func ensureTeamExists(r *http.Request, teamName string) error {
+	_, err := servicemanager.Team.FindByName(r.Context(), teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+func ensurePoolExists(r *http.Request, poolName string) error {
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+func writeJobQuotaUsage(w http.ResponseWriter, r *http.Request, scope jobTypes.QuotaScope, name string) error {
+	usage, err := job.GetQuotaUsage(r.Context(), scope, name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(usage)
+}
+
+// setJobQuota updates the limits sent in the request, keeping the current
+// value of the ones omitted.
+func setJobQuota(r *http.Request, scope jobTypes.QuotaScope, name string) error {
+	ctx := r.Context()
+	quota, err := job.GetQuota(ctx, scope, name)
+	if err != nil {
+		return err
+	}
+	for field, limit := range map[string]*int{
+		"maxJobs":                 &quota.MaxJobs,
+		"maxConcurrentExecutions": &quota.MaxConcurrentExecutions,
+	} {
+		value := InputValue(r, field)
+		if value == "" {
+			continue
+		}
+		*limit, err = strconv.Atoi(value)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid " + field}
+		}
+	}
+	return job.SetQuota(ctx, scope, name, *quota)
+}
+
+// jobQuotaError converts job quota errors to forbidden responses, as done for
+// the quota of apps.
+func jobQuotaError(err error) error {
+	if e, ok := err.(*jobTypes.QuotaExceededError); ok {
+		return &errors.HTTP{Code: http.StatusForbidden, Message: e.Error()}
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/job"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestGetTeamJobQuota(c *check.C) {
+	err := job.SetQuota(context.TODO(), jobTypes.QuotaScopeTeam, s.team.Name, jobTypes.Quota{MaxJobs: 3, MaxConcurrentExecutions: 1})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/teams/"+s.team.Name+"/job-quota", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var usage jobTypes.QuotaUsage
+	err = json.NewDecoder(recorder.Body).Decode(&usage)
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, jobTypes.QuotaUsage{Quota: jobTypes.Quota{MaxJobs: 3, MaxConcurrentExecutions: 1}})
+}
+
+func (s *S) TestChangePoolJobQuota(c *check.C) {
+	err := job.SetQuota(context.TODO(), jobTypes.QuotaScopePool, s.Pool, jobTypes.Quota{MaxJobs: 3, MaxConcurrentExecutions: 1})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("maxConcurrentExecutions=5")
+	request, err := http.NewRequest(http.MethodPut, "/pools/"+s.Pool+"/job-quota", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	quota, err := job.GetQuota(context.TODO(), jobTypes.QuotaScopePool, s.Pool)
+	c.Assert(err, check.IsNil)
+	c.Assert(*quota, check.DeepEquals, jobTypes.Quota{MaxJobs: 3, MaxConcurrentExecutions: 5})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: s.Pool},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.quota",
+		StartCustomData: []map[string]interface{}{
+			{"name": "maxConcurrentExecutions", "value": "5"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestChangePoolJobQuotaInvalidLimit(c *check.C) {
+	body := strings.NewReader("maxJobs=many")
+	request, err := http.NewRequest(http.MethodPut, "/pools/"+s.Pool+"/job-quota", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "Invalid maxJobs\n")
+}
+
+func (s *S) TestChangeTeamJobQuotaUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermTeamReadQuota,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("maxJobs=10")
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/job-quota", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
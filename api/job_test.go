@@ -21,6 +21,7 @@ import (
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/job"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
 	"github.com/tsuru/tsuru/provision"
@@ -3359,6 +3360,70 @@ func (s *S) TestJobLogsList(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 }
 
+func (s *S) TestJobExecutions(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		prov := provisiontest.ProvisionerInstance
+		prov.LogsEnabled = true
+		return &provisiontest.JobProvisioner{FakeProvisioner: prov}, nil
+	})
+	defer provision.Unregister("jobProv")
+	j := jobTypes.Job{
+		Name:      "lost1",
+		Pool:      s.Pool,
+		TeamOwner: s.team.Name,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+		},
+		DeployOptions: &jobTypes.DeployOptions{
+			Kind:  provTypes.DeployImage,
+			Image: "busybox:1.18",
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", fmt.Sprintf("/jobs/%s/executions", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	err = job.RecordExecution(context.TODO(), jobTypes.Execution{
+		ID:        "lost1-29012345",
+		Job:       j.Name,
+		Status:    jobTypes.ExecutionSucceeded,
+		Image:     "busybox:1.18",
+		StartTime: time.Now().UTC().Add(-time.Minute),
+	})
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var executions []jobTypes.Execution
+	err = json.Unmarshal(recorder.Body.Bytes(), &executions)
+	c.Assert(err, check.IsNil)
+	c.Assert(executions, check.HasLen, 1)
+	c.Assert(executions[0].ID, check.Equals, "lost1-29012345")
+	c.Assert(executions[0].Status, check.Equals, jobTypes.ExecutionSucceeded)
+	request, err = http.NewRequest("GET", fmt.Sprintf("/jobs/%s/executions/lost1-29012345/logs", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "[]\n")
+	request, err = http.NewRequest("GET", fmt.Sprintf("/jobs/%s/executions/unknown/logs", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestJobLogsWatch(c *check.C) {
 	oldProvisioner := provision.DefaultProvisioner
 	defer func() { provision.DefaultProvisioner = oldProvisioner }()
@@ -34,6 +34,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 )
 
 const (
@@ -99,6 +100,14 @@ func tokenByAllAuthEngines(ctx stdContext.Context, token string) (auth.Token, er
 	}
 
 	t, err = servicemanager.TeamToken.Authenticate(ctx, token)
+	if err == nil {
+		if rotated, ok := t.(authTypes.RotatedToken); ok && rotated.UsingPreviousValue() && rotated.PreviousValueLastAccess().IsZero() {
+			recordPreviousTeamTokenUse(ctx, t)
+		}
+		return t, nil
+	}
+
+	t, err = auth.UserTokenAuth(ctx, token)
 	if err == nil {
 		return t, nil
 	}
@@ -149,11 +158,10 @@ func (m *flushingWriterMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Requ
 
 func setRequestIDHeaderMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	requestIDHeader, _ := config.GetString("request-id-header")
-	if requestIDHeader == "" {
-		next(w, r)
-		return
+	var requestID string
+	if requestIDHeader != "" {
+		requestID = r.Header.Get(requestIDHeader)
 	}
-	requestID := r.Header.Get(requestIDHeader)
 	if requestID == "" {
 		unparsedID, err := uuid.NewV4()
 		if err != nil {
@@ -163,7 +171,10 @@ func setRequestIDHeaderMiddleware(w http.ResponseWriter, r *http.Request, next h
 		}
 		requestID = unparsedID.String()
 	}
-	context.SetRequestID(r, requestIDHeader, requestID)
+	if requestIDHeader != "" {
+		context.SetRequestID(r, requestIDHeader, requestID)
+	}
+	*r = *r.WithContext(log.ContextWithRequestID(r.Context(), requestID))
 	next(w, r)
 }
 
@@ -205,7 +216,7 @@ func errorHandlingMiddleware(w http.ResponseWriter, r *http.Request, next http.H
 		} else {
 			http.Error(w, err.Error(), code)
 		}
-		log.Errorf("failure running HTTP request %s %s (%d): %s", r.Method, r.URL.Path, code, err)
+		log.ErrorfContext(r.Context(), "failure running HTTP request %s %s (%d): %s", r.Method, r.URL.Path, code, err)
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/io"
+	tsuruLog "github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -133,6 +134,7 @@ func (s *S) TestSetRequestIDHeaderAlreadySet(c *check.C) {
 	c.Assert(log.called, check.Equals, true)
 	reqID := context.GetRequestID(req, "Request-ID")
 	c.Assert(reqID, check.Equals, "test")
+	c.Assert(tsuruLog.RequestIDFromContext(req.Context()), check.Equals, "test")
 }
 
 func (s *S) TestSetRequestIDHeaderMiddlewareNoConfig(c *check.C) {
@@ -145,6 +147,7 @@ func (s *S) TestSetRequestIDHeaderMiddlewareNoConfig(c *check.C) {
 	c.Assert(log.called, check.Equals, true)
 	reqID := context.GetRequestID(req, "")
 	c.Assert(reqID, check.Equals, "")
+	c.Assert(tsuruLog.RequestIDFromContext(req.Context()), check.Not(check.Equals), "")
 }
 
 func (s *S) TestSetVersionHeadersMiddleware(c *check.C) {
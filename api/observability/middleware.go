@@ -21,6 +21,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/log"
 )
 
 const (
@@ -79,6 +80,9 @@ func (l *middleware) ServeHTTP(rw http.ResponseWriter, r *http.Request, next htt
 	if header := requestIDHeader(); header != "" {
 		requestID = context.GetRequestID(r, header)
 	}
+	if requestID == "" {
+		requestID = log.RequestIDFromContext(r.Context())
+	}
 	scheme := "http"
 	if r.TLS != nil {
 		scheme = "https"
@@ -20,6 +20,7 @@ import (
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/service"
 	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
@@ -187,6 +188,127 @@ func roleInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return err
 }
 
+// title: role clone
+// path: /roles/{name}/clone
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	201: Role created
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Role not found
+//	409: Role already exists
+func cloneRole(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleCreate) {
+		return permission.ErrUnauthorized
+	}
+	roleName := InputValue(r, "name")
+	if roleName == "" {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: permTypes.ErrInvalidRoleName.Error(),
+		}
+	}
+	source, err := getRoleReturnNotFound(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if len(source.SchemeNames) > 0 && !permission.Check(ctx, t, permission.PermRoleUpdatePermissionAdd) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: roleName},
+		Kind:       permission.PermRoleCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: append(event.FormToCustomData(InputFields(r)), map[string]interface{}{"name": "source", "value": source.Name}),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	_, err = source.Clone(ctx, roleName, InputValue(r, "description"))
+	if err == permTypes.ErrInvalidRoleName {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err == permTypes.ErrRoleAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err == nil {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return err
+}
+
+// title: role template list
+// path: /roles/templates
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+func listRoleTemplates(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	if !(permission.Check(ctx, t, permission.PermRoleUpdate) ||
+		permission.Check(ctx, t, permission.PermRoleCreate)) {
+		return permission.ErrUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(permission.ListRoleTemplates())
+}
+
+// title: role create from template
+// path: /roles/templates/{template}
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	201: Role created
+//	401: Unauthorized
+//	404: Role template not found
+//	409: Role already exists
+func addRoleFromTemplate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleCreate) {
+		return permission.ErrUnauthorized
+	}
+	tpl, err := permission.FindRoleTemplate(r.URL.Query().Get(":template"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if len(tpl.SchemeNames) > 0 && !permission.Check(ctx, t, permission.PermRoleUpdatePermissionAdd) {
+		return permission.ErrUnauthorized
+	}
+	roleName := InputValue(r, "name")
+	if roleName == "" {
+		roleName = tpl.Name
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: roleName},
+		Kind:       permission.PermRoleCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: append(event.FormToCustomData(InputFields(r)), map[string]interface{}{"name": "template", "value": tpl.Name}),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	_, err = permission.NewRoleFromTemplate(ctx, tpl.Name, roleName, InputValue(r, "description"))
+	if err == permTypes.ErrRoleAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err == nil {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return err
+}
+
 // title: add permissions
 // path: /roles/{name}/permissions
 // method: POST
@@ -346,6 +468,10 @@ func assignRole(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	defer func() { evt.Done(ctx, err) }()
 	email := InputValue(r, "email")
 	contextValue := InputValue(r, "context")
+	expiresAt, err := roleExpiration(InputValue(r, "expiresAt"))
+	if err != nil {
+		return err
+	}
 	user, err := auth.GetUserByEmail(ctx, email)
 	if err != nil {
 		return err
@@ -363,7 +489,9 @@ func assignRole(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if err != nil {
 		return err
 	}
-
+	if expiresAt != nil {
+		return user.AddRoleUntil(ctx, roleName, contextValue, *expiresAt)
+	}
 	return user.AddRole(ctx, roleName, contextValue)
 }
 
@@ -689,6 +817,12 @@ func validateContextValue(ctx context.Context, role permission.Role, contextValu
 		if _, err := servicemanager.Job.GetByName(ctx, contextValue); err != nil {
 			return &errors.ValidationError{Message: err.Error()}
 		}
+	case permTypes.CtxTag:
+		// tags don't need to be in use by any app yet, so that roles can be
+		// granted before the apps carrying them are created.
+		if err := appTypes.ValidateTags([]string{contextValue}); err != nil {
+			return err
+		}
 	}
 
 	return nil
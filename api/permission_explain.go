@@ -0,0 +1,414 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+// permissionGrant is a role assignment granting, or which would grant when
+// assigned, an action.
+type permissionGrant struct {
+	Role         string                `json:"role"`
+	ContextType  permTypes.ContextType `json:"contextType"`
+	ContextValue string                `json:"contextValue,omitempty"`
+	Permission   string                `json:"permission"`
+	Group        string                `json:"group,omitempty"`
+	ExpiresAt    *time.Time            `json:"expiresAt,omitempty"`
+}
+
+type permissionExplanation struct {
+	Allowed  bool                          `json:"allowed"`
+	Action   string                        `json:"action"`
+	Contexts []permTypes.PermissionContext `json:"contexts"`
+	Grants   []permissionGrant             `json:"grants"`
+	// Candidates are roles which would grant the action if assigned to the
+	// user in the listed context value.
+	Candidates []permissionGrant `json:"candidates"`
+}
+
+type roleAssignment struct {
+	Kind         string     `json:"kind"`
+	Name         string     `json:"name"`
+	ContextValue string     `json:"contextValue,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+type affectedResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type roleAffectedResources struct {
+	Role        string                `json:"role"`
+	ContextType permTypes.ContextType `json:"contextType"`
+	// Global is set when the role is assigned in the global context,
+	// touching every resource its permissions apply to.
+	Global      bool               `json:"global"`
+	Assignments []roleAssignment   `json:"assignments"`
+	Resources   []affectedResource `json:"resources"`
+}
+
+// contextsForTarget returns the permission contexts of a target in the
+// type:value format, like app:myapp or team:myteam. Apps, jobs and volumes
+// are loaded to include the contexts of their teams and pools, and are
+// reported as not found when the token isn't allowed to read them.
+func contextsForTarget(ctx context.Context, t auth.Token, target string) ([]permTypes.PermissionContext, error) {
+	if target == "" || target == string(permTypes.CtxGlobal) {
+		return []permTypes.PermissionContext{}, nil
+	}
+	targetType, value, ok := strings.Cut(target, ":")
+	if !ok || value == "" {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid target %q, must be in the type:value format", target)}
+	}
+	switch permTypes.ContextType(targetType) {
+	case permTypes.CtxApp:
+		a, err := app.GetByName(ctx, value)
+		if err == appTypes.ErrAppNotFound {
+			return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err != nil {
+			return nil, err
+		}
+		contexts := contextsForApp(a)
+		if !permission.Check(ctx, t, permission.PermAppRead, contexts...) {
+			return nil, &errors.HTTP{Code: http.StatusNotFound, Message: appTypes.ErrAppNotFound.Error()}
+		}
+		return contexts, nil
+	case permTypes.CtxJob:
+		job, err := servicemanager.Job.GetByName(ctx, value)
+		if err == jobTypes.ErrJobNotFound {
+			return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err != nil {
+			return nil, err
+		}
+		contexts := contextsForJob(job)
+		if !permission.Check(ctx, t, permission.PermJobRead, contexts...) {
+			return nil, &errors.HTTP{Code: http.StatusNotFound, Message: jobTypes.ErrJobNotFound.Error()}
+		}
+		return contexts, nil
+	case permTypes.CtxVolume:
+		v, err := servicemanager.Volume.Get(ctx, value)
+		if err == volumeTypes.ErrVolumeNotFound {
+			return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err != nil {
+			return nil, err
+		}
+		contexts := contextsForVolume(v)
+		if !permission.Check(ctx, t, permission.PermVolumeRead, contexts...) {
+			return nil, &errors.HTTP{Code: http.StatusNotFound, Message: volumeTypes.ErrVolumeNotFound.Error()}
+		}
+		return contexts, nil
+	}
+	ctxType, err := permission.ParseContext(targetType)
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return []permTypes.PermissionContext{permission.Context(ctxType, value)}, nil
+}
+
+// grantingScheme returns the scheme of the role granting the action in
+// the context value, if any.
+func grantingScheme(role *permission.Role, action *permTypes.PermissionScheme, contextValue string, contexts []permTypes.PermissionContext) (string, bool) {
+	for _, perm := range role.PermissionsFor(contextValue) {
+		if permission.CheckFromPermList([]permTypes.Permission{perm}, action, contexts...) {
+			return perm.Scheme.FullName(), true
+		}
+	}
+	return "", false
+}
+
+// title: explain user permission
+// path: /users/{email}/permissions/explain
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	400: Invalid action or target
+//	401: Unauthorized
+//	404: User or target not found
+func explainUserPermission(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	email := r.URL.Query().Get(":email")
+	if !permission.Check(ctx, t, permission.PermUserRead, permission.Context(permTypes.CtxUser, email)) {
+		return permission.ErrUnauthorized
+	}
+	action, err := permission.SafeGet(r.URL.Query().Get("action"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	user, err := auth.GetUserByEmail(ctx, email)
+	if err == authTypes.ErrUserNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	contexts, err := contextsForTarget(ctx, t, r.URL.Query().Get("target"))
+	if err != nil {
+		return err
+	}
+	perms, err := user.Permissions(ctx)
+	if err != nil {
+		return err
+	}
+	explanation := permissionExplanation{
+		Allowed:    permission.CheckFromPermList(perms, action, contexts...),
+		Action:     action.FullName(),
+		Contexts:   contexts,
+		Grants:     []permissionGrant{},
+		Candidates: []permissionGrant{},
+	}
+	roles, err := permission.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+	roleMap := make(map[string]*permission.Role, len(roles))
+	for i := range roles {
+		roleMap[roles[i].Name] = &roles[i]
+	}
+	groups, err := user.UserGroups()
+	if err != nil {
+		return err
+	}
+	type groupRole struct {
+		group string
+		authTypes.RoleInstance
+	}
+	var assigned []groupRole
+	for _, roleInstance := range user.Roles {
+		assigned = append(assigned, groupRole{RoleInstance: roleInstance})
+	}
+	for _, group := range groups {
+		for _, roleInstance := range group.Roles {
+			assigned = append(assigned, groupRole{group: group.Name, RoleInstance: roleInstance})
+		}
+	}
+	granted := map[string]bool{}
+	now := time.Now()
+	for _, roleInstance := range assigned {
+		role := roleMap[roleInstance.Name]
+		if role == nil || roleInstance.Expired(now) {
+			continue
+		}
+		scheme, ok := grantingScheme(role, action, roleInstance.ContextValue, contexts)
+		if !ok {
+			continue
+		}
+		granted[role.Name+"/"+roleInstance.ContextValue] = true
+		explanation.Grants = append(explanation.Grants, permissionGrant{
+			Role:         role.Name,
+			ContextType:  role.ContextType,
+			ContextValue: roleInstance.ContextValue,
+			Permission:   scheme,
+			Group:        roleInstance.group,
+			ExpiresAt:    roleInstance.ExpiresAt,
+		})
+	}
+	for _, role := range roles {
+		candidateValues := []string{""}
+		if role.ContextType != permTypes.CtxGlobal {
+			candidateValues = nil
+			for _, c := range contexts {
+				if c.CtxType == role.ContextType {
+					candidateValues = append(candidateValues, c.Value)
+				}
+			}
+		}
+		for _, value := range candidateValues {
+			if granted[role.Name+"/"+value] {
+				continue
+			}
+			scheme, ok := grantingScheme(&role, action, value, contexts)
+			if !ok {
+				continue
+			}
+			explanation.Candidates = append(explanation.Candidates, permissionGrant{
+				Role:         role.Name,
+				ContextType:  role.ContextType,
+				ContextValue: value,
+				Permission:   scheme,
+			})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(explanation)
+}
+
+func touchesResource(role *permission.Role, resource *permTypes.PermissionScheme) bool {
+	for _, perm := range role.PermissionsFor("") {
+		if perm.Scheme.IsParent(resource) || resource.IsParent(perm.Scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsContextType(types []permTypes.ContextType, ctxType permTypes.ContextType) bool {
+	for _, t := range types {
+		if t == ctxType {
+			return true
+		}
+	}
+	return false
+}
+
+// title: role affected resources
+// path: /roles/{name}/affected-resources
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Role not found
+func roleAffectedResourcesHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleRead) {
+		return permission.ErrUnauthorized
+	}
+	role, err := getRoleReturnNotFound(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	result := roleAffectedResources{
+		Role:        role.Name,
+		ContextType: role.ContextType,
+		Assignments: []roleAssignment{},
+		Resources:   []affectedResource{},
+	}
+	users, err := auth.ListUsersWithRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		for _, roleInstance := range u.Roles {
+			if roleInstance.Name == role.Name {
+				result.Assignments = append(result.Assignments, roleAssignment{Kind: "user", Name: u.Email, ContextValue: roleInstance.ContextValue, ExpiresAt: roleInstance.ExpiresAt})
+			}
+		}
+	}
+	groups, err := servicemanager.AuthGroup.List(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		for _, roleInstance := range group.Roles {
+			if roleInstance.Name == role.Name {
+				result.Assignments = append(result.Assignments, roleAssignment{Kind: "group", Name: group.Name, ContextValue: roleInstance.ContextValue})
+			}
+		}
+	}
+	tokens, err := servicemanager.TeamToken.FindByRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		for _, roleInstance := range token.Roles {
+			if roleInstance.Name == role.Name {
+				result.Assignments = append(result.Assignments, roleAssignment{Kind: "token", Name: token.TokenID, ContextValue: roleInstance.ContextValue})
+			}
+		}
+	}
+	if role.ContextType == permTypes.CtxGlobal {
+		result.Global = len(result.Assignments) > 0
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(result)
+	}
+	seen := map[string]struct{}{}
+	var contexts []permTypes.PermissionContext
+	for _, assignment := range result.Assignments {
+		if _, ok := seen[assignment.ContextValue]; ok {
+			continue
+		}
+		seen[assignment.ContextValue] = struct{}{}
+		contexts = append(contexts, permission.Context(role.ContextType, assignment.ContextValue))
+	}
+	if len(contexts) > 0 {
+		result.Resources, err = resourcesForContexts(ctx, &role, contexts)
+		if err != nil {
+			return err
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// resourcesForContexts lists the apps, jobs and volumes in the contexts the
+// role permissions apply to. Resources of other context types, like
+// services, are listed by their context values.
+func resourcesForContexts(ctx context.Context, role *permission.Role, contexts []permTypes.PermissionContext) ([]affectedResource, error) {
+	resources := []affectedResource{}
+	ctxType := role.ContextType
+	listed := false
+	if containsContextType(permission.PermApp.AllowedContexts(), ctxType) {
+		listed = true
+		if touchesResource(role, permission.PermApp) {
+			apps, err := app.List(ctx, appFilterByContext(contexts, nil))
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range apps {
+				resources = append(resources, affectedResource{Type: string(permTypes.CtxApp), Name: a.Name})
+			}
+		}
+	}
+	if containsContextType(permission.PermJob.AllowedContexts(), ctxType) {
+		listed = true
+		if touchesResource(role, permission.PermJob) {
+			jobs, err := servicemanager.Job.List(ctx, jobFilterByContext(contexts, nil))
+			if err != nil {
+				return nil, err
+			}
+			for _, job := range jobs {
+				resources = append(resources, affectedResource{Type: string(permTypes.CtxJob), Name: job.Name})
+			}
+		}
+	}
+	if containsContextType(permission.PermVolume.AllowedContexts(), ctxType) {
+		listed = true
+		if touchesResource(role, permission.PermVolume) {
+			volumes, err := servicemanager.Volume.ListByFilter(ctx, volumeFilterByContext(contexts))
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range volumes {
+				resources = append(resources, affectedResource{Type: string(permTypes.CtxVolume), Name: v.Name})
+			}
+		}
+	}
+	if ctxType == permTypes.CtxTeam || ctxType == permTypes.CtxPool || !listed {
+		for _, c := range contexts {
+			resources = append(resources, affectedResource{Type: string(c.CtxType), Name: c.Value})
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Type != resources[j].Type {
+			return resources[i].Type < resources[j].Type
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return resources, nil
+}
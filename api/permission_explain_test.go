@@ -0,0 +1,196 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestExplainUserPermission(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	deployer, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = deployer.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	admin, err := permission.NewRole(context.TODO(), "app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	err = admin.AddPermissions(context.TODO(), "app")
+	c.Assert(err, check.IsNil)
+	u := &auth.User{Email: "dev@tsuru.io"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "deployer", s.team.Name)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/1.25/users/dev@tsuru.io/permissions/explain?action=app.deploy&target=app:myapp", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var explanation permissionExplanation
+	err = json.Unmarshal(recorder.Body.Bytes(), &explanation)
+	c.Assert(err, check.IsNil)
+	c.Assert(explanation.Allowed, check.Equals, true)
+	c.Assert(explanation.Action, check.Equals, "app.deploy")
+	c.Assert(explanation.Grants, check.DeepEquals, []permissionGrant{
+		{Role: "deployer", ContextType: permTypes.CtxTeam, ContextValue: s.team.Name, Permission: "app.deploy"},
+	})
+	var candidates []permissionGrant
+	for _, candidate := range explanation.Candidates {
+		if candidate.Role == "app-admin" {
+			candidates = append(candidates, candidate)
+		}
+	}
+	c.Assert(candidates, check.DeepEquals, []permissionGrant{
+		{Role: "app-admin", ContextType: permTypes.CtxApp, ContextValue: "myapp", Permission: "app"},
+	})
+}
+
+func (s *S) TestExplainUserPermissionNotAllowed(c *check.C) {
+	u := &auth.User{Email: "dev@tsuru.io"}
+	err := u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/1.25/users/dev@tsuru.io/permissions/explain?action=app.deploy&target=team:otherteam", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var explanation permissionExplanation
+	err = json.Unmarshal(recorder.Body.Bytes(), &explanation)
+	c.Assert(err, check.IsNil)
+	c.Assert(explanation.Allowed, check.Equals, false)
+	c.Assert(explanation.Grants, check.HasLen, 0)
+}
+
+func (s *S) TestExplainUserPermissionInvalidAction(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/1.25/users/"+s.user.Email+"/permissions/explain?action=app.unknown", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestExplainUserPermissionUnauthorized(c *check.C) {
+	u := &auth.User{Email: "dev@tsuru.io"}
+	err := u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c)
+	req, err := http.NewRequest(http.MethodGet, "/1.25/users/dev@tsuru.io/permissions/explain?action=app.deploy", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestExplainUserPermissionTargetNotReadable(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	u := &auth.User{Email: "dev@tsuru.io"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermUserRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req, err := http.NewRequest(http.MethodGet, "/1.25/users/dev@tsuru.io/permissions/explain?action=app.deploy&target=app:myapp", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, appTypes.ErrAppNotFound.Error()+"\n")
+}
+
+func (s *S) TestRoleAffectedResources(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	u := &auth.User{Email: "dev@tsuru.io"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "deployer", s.team.Name)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/1.25/roles/deployer/affected-resources", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result roleAffectedResources
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, roleAffectedResources{
+		Role:        "deployer",
+		ContextType: permTypes.CtxTeam,
+		Assignments: []roleAssignment{
+			{Kind: "user", Name: "dev@tsuru.io", ContextValue: s.team.Name},
+		},
+		Resources: []affectedResource{
+			{Type: "app", Name: "myapp"},
+			{Type: "team", Name: s.team.Name},
+		},
+	})
+}
+
+func (s *S) TestRoleAffectedResourcesTokensOfOtherTeams(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	teamToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team: s.team.Name,
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.TeamToken.AddRole(context.TODO(), teamToken.TokenID, "deployer", s.team.Name)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req, err := http.NewRequest(http.MethodGet, "/1.25/roles/deployer/affected-resources", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result roleAffectedResources
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Assignments, check.DeepEquals, []roleAssignment{
+		{Kind: "token", Name: teamToken.TokenID, ContextValue: s.team.Name},
+	})
+}
+
+func (s *S) TestRoleAffectedResourcesNotFound(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/1.25/roles/unknown/affected-resources", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
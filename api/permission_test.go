@@ -253,6 +253,199 @@ func (s *S) TestRoleInfoNotFound(c *check.C) {
 	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
 }
 
+func (s *S) TestCloneRole(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "myrole", "team", "my role")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app.deploy", "app.update")
+	c.Assert(err, check.IsNil)
+	body := bytes.NewBufferString("name=myclone")
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/myrole/clone", body)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	}, permTypes.Permission{
+		Scheme:  permission.PermRoleUpdatePermissionAdd,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	clone, err := permission.FindRole(ctx, "myclone")
+	c.Assert(err, check.IsNil)
+	c.Assert(clone.ContextType, check.Equals, permTypes.CtxTeam)
+	c.Assert(clone.Description, check.Equals, "my role")
+	c.Assert(clone.SchemeNames, check.DeepEquals, []string{"app.deploy", "app.update"})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: "myclone"},
+		Owner:  token.GetUserName(),
+		Kind:   "role.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "myclone"},
+			{"name": "source", "value": "myrole"},
+		},
+	}, eventtest.HasEvent)
+	body = bytes.NewBufferString("name=myclone")
+	req, err = http.NewRequest(http.MethodPost, "/1.25/roles/myrole/clone", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestCloneRoleDoesNotCopyEvents(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "myrole", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddEvent(ctx, permTypes.RoleEventTeamCreate.String())
+	c.Assert(err, check.IsNil)
+	body := bytes.NewBufferString("name=myclone")
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/myrole/clone", body)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	clone, err := permission.FindRole(ctx, "myclone")
+	c.Assert(err, check.IsNil)
+	c.Assert(clone.Events, check.HasLen, 0)
+}
+
+func (s *S) TestCloneRoleNotFound(c *check.C) {
+	body := bytes.NewBufferString("name=myclone")
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/unknown/clone", body)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestCloneRoleUnauthorized(c *check.C) {
+	_, err := permission.NewRole(context.TODO(), "myrole", "team", "")
+	c.Assert(err, check.IsNil)
+	body := bytes.NewBufferString("name=myclone")
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/myrole/clone", body)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestCloneRoleWithoutPermissionAdd(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "myrole", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app.deploy")
+	c.Assert(err, check.IsNil)
+	body := bytes.NewBufferString("name=myclone")
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/myrole/clone", body)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	_, err = permission.FindRole(ctx, "myclone")
+	c.Assert(err, check.Equals, permTypes.ErrRoleNotFound)
+}
+
+func (s *S) TestListRoleTemplates(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/1.25/roles/templates", nil)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var templates []permission.RoleTemplate
+	err = json.Unmarshal(recorder.Body.Bytes(), &templates)
+	c.Assert(err, check.IsNil)
+	c.Assert(templates, check.DeepEquals, permission.ListRoleTemplates())
+}
+
+func (s *S) TestAddRoleFromTemplate(c *check.C) {
+	body := bytes.NewBufferString("name=myteam-dev")
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/templates/developer", body)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	}, permTypes.Permission{
+		Scheme:  permission.PermRoleUpdatePermissionAdd,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	tpl, err := permission.FindRoleTemplate("developer")
+	c.Assert(err, check.IsNil)
+	role, err := permission.FindRole(context.TODO(), "myteam-dev")
+	c.Assert(err, check.IsNil)
+	sort.Strings(tpl.SchemeNames)
+	c.Assert(role.ContextType, check.Equals, permTypes.CtxTeam)
+	c.Assert(role.SchemeNames, check.DeepEquals, tpl.SchemeNames)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: "myteam-dev"},
+		Owner:  token.GetUserName(),
+		Kind:   "role.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "myteam-dev"},
+			{"name": "template", "value": "developer"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAddRoleFromTemplateNotFound(c *check.C) {
+	req, err := http.NewRequest(http.MethodPost, "/1.25/roles/templates/unknown", nil)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, permTypes.ErrRoleTemplateNotFound.Error()+"\n")
+}
+
 func (s *S) TestRoleInfo(c *check.C) {
 	ctx := context.TODO()
 
@@ -679,6 +872,26 @@ func (s *S) TestRoleAssignValidateCtxPoolFound(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 }
 
+func (s *S) TestRoleAssignValidateCtxTag(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "user1", permTypes.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	_, err := permission.NewRole(context.TODO(), "test", "tag", "")
+	c.Assert(err, check.IsNil)
+	server := RunServer(true)
+	for tag, code := range map[string]int{"env:prod": http.StatusOK, "env:": http.StatusBadRequest} {
+		roleBody := bytes.NewBufferString(fmt.Sprintf("email=%s&context=%s", token.GetUserName(), tag))
+		req, err := http.NewRequest(http.MethodPost, "/roles/test/user", roleBody)
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "bearer "+token.GetValue())
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		c.Assert(recorder.Code, check.Equals, code, check.Commentf("tag %q: %s", tag, recorder.Body.String()))
+	}
+}
+
 func (s *S) TestRoleAssignValidateCtxServiceNotFound(c *check.C) {
 	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "user1", permTypes.Permission{
 		Scheme:  permission.PermAll,
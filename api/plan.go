@@ -5,17 +5,23 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -46,12 +52,30 @@ func addPlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 
 		isDefault, _ := strconv.ParseBool(InputValue(r, "default"))
 		memory := getSize(InputValue(r, "memory"))
+		hourlyCost, _ := strconv.ParseFloat(InputValue(r, "hourly-cost"), 64)
 
 		plan = appTypes.Plan{
-			Name:     InputValue(r, "name"),
-			Memory:   memory,
-			CPUMilli: cpuMilli,
-			Default:  isDefault,
+			Name:       InputValue(r, "name"),
+			Memory:     memory,
+			CPUMilli:   cpuMilli,
+			Default:    isDefault,
+			HourlyCost: hourlyCost,
+		}
+
+		if constraints, ok := InputValues(r, "constraint"); ok {
+			plan.SchedulingConstraints, err = parseSchedulingConstraints(constraints)
+			if err != nil {
+				return err
+			}
+		}
+
+		cpuMilliRequest, _ := strconv.Atoi(InputValue(r, "cpumilli-request"))
+		memoryRequest := getSize(InputValue(r, "memory-request"))
+		if cpuMilliRequest != 0 || memoryRequest != 0 {
+			plan.Requests = &appTypes.PlanRequests{
+				CPUMilli: cpuMilliRequest,
+				Memory:   memoryRequest,
+			}
 		}
 	}
 
@@ -84,6 +108,12 @@ func addPlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 			Message: err.Error(),
 		}
 	}
+	if _, ok := err.(appTypes.PlanValidationError); ok {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
 	if err == nil {
 		w.WriteHeader(http.StatusCreated)
 		return json.NewEncoder(w).Encode(plan)
@@ -118,8 +148,10 @@ func listPlans(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 // responses:
 //
 //	200: Plan removed
+//	400: Invalid replacement plan
 //	401: Unauthorized
 //	404: Plan not found
+//	409: Plan in use
 func removePlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	allowed := permission.Check(ctx, t, permission.PermPlanDelete)
@@ -139,6 +171,15 @@ func removePlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
+	if replacement := InputValue(r, "replacement"); replacement != "" {
+		err = app.MigratePlan(ctx, planName, replacement, evt)
+		if err != nil {
+			if _, ok := err.(*errors.ValidationError); ok || pkgErrors.Cause(err) == appTypes.ErrPlanNotFound {
+				return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+			}
+			return err
+		}
+	}
 	err = servicemanager.Plan.Remove(ctx, planName)
 	if err == appTypes.ErrPlanNotFound {
 		return &errors.HTTP{
@@ -146,6 +187,129 @@ func removePlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 			Message: err.Error(),
 		}
 	}
+	if _, ok := err.(*appTypes.PlanInUseError); ok {
+		return &errors.HTTP{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		}
+	}
+	return err
+}
+
+// title: plan usage
+// path: /plans/{name}/usage
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Plan not found
+func planUsage(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermPlanRead)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	planName := r.URL.Query().Get(":planname")
+	_, err := servicemanager.Plan.FindByName(ctx, planName)
+	if err == appTypes.ErrPlanNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	usage, err := servicemanager.Plan.Usage(ctx, planName)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(usage)
+}
+
+// title: plan defaults list
+// path: /plans/defaults
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+func listPlanDefaults(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	defaults, err := servicemanager.Plan.ListDefaults(r.Context())
+	if err != nil {
+		return err
+	}
+	if len(defaults) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(defaults)
+}
+
+// title: set plan default
+// path: /plans/{planname}/default
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Plan default set
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Plan, pool or team not found
+func setPlanDefault(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	return updatePlanDefault(r, t, servicemanager.Plan.SetDefault)
+}
+
+// title: unset plan default
+// path: /plans/{planname}/default
+// method: DELETE
+// responses:
+//
+//	200: Plan default unset
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Plan default not found
+func unsetPlanDefault(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	return updatePlanDefault(r, t, servicemanager.Plan.UnsetDefault)
+}
+
+func updatePlanDefault(r *http.Request, t auth.Token, fn func(context.Context, appTypes.PlanDefault) error) (err error) {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermPlanUpdateDefault)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	planDefault := appTypes.PlanDefault{
+		Plan: r.URL.Query().Get(":planname"),
+		Pool: InputValue(r, "pool"),
+		Team: InputValue(r, "team"),
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePlan, Value: planDefault.Plan},
+		Kind:       permission.PermPlanUpdateDefault,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPlanReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = fn(ctx, planDefault)
+	switch err {
+	case nil:
+		return nil
+	case appTypes.ErrPlanNotFound, appTypes.ErrPlanDefaultNotFound, pool.ErrPoolNotFound, authTypes.ErrTeamNotFound:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case appTypes.ErrPlanDefaultTarget:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if _, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
 	return err
 }
 
@@ -164,3 +328,16 @@ func getSize(formValue string) int64 {
 	v, _ := qtdy.AsInt64()
 	return v
 }
+
+// parseSchedulingConstraints parses constraints in the label=value form.
+func parseSchedulingConstraints(values []string) (map[string]string, error) {
+	constraints := make(map[string]string, len(values))
+	for _, v := range values {
+		label, value, ok := strings.Cut(v, "=")
+		if !ok || label == "" {
+			return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid constraint %q, must be in the label=value form", v)}
+		}
+		constraints[label] = value
+	}
+	return constraints, nil
+}
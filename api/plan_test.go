@@ -119,6 +119,64 @@ func (s *S) TestPlanAddWithGigabyteAsMemoryUnit(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
 }
 
+func (s *S) TestPlanAddWithRequests(c *check.C) {
+	s.mockService.Plan.OnCreate = func(plan appTypes.Plan) error {
+		c.Assert(plan, check.DeepEquals, appTypes.Plan{
+			Name:     "xyz",
+			Memory:   536870912,
+			CPUMilli: 1000,
+			Requests: &appTypes.PlanRequests{
+				CPUMilli: 250,
+				Memory:   268435456,
+			},
+		})
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader("name=xyz&memory=512M&cpumilli=1000&cpumilli-request=250&memory-request=256M")
+	request, err := http.NewRequest("POST", "/plans", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+}
+
+func (s *S) TestPlanAddWithHourlyCost(c *check.C) {
+	s.mockService.Plan.OnCreate = func(plan appTypes.Plan) error {
+		c.Assert(plan, check.DeepEquals, appTypes.Plan{
+			Name:       "xyz",
+			Memory:     536870912,
+			CPUMilli:   1000,
+			HourlyCost: 0.05,
+		})
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader("name=xyz&memory=512M&cpumilli=1000&hourly-cost=0.05")
+	request, err := http.NewRequest("POST", "/plans", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+}
+
+func (s *S) TestPlanAddInvalidRequests(c *check.C) {
+	s.mockService.Plan.OnCreate = func(plan appTypes.Plan) error {
+		return appTypes.PlanValidationError{Field: "requests.cpumilli"}
+	}
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader("name=xyz&cpumilli=1000&cpumilli-request=2000")
+	request, err := http.NewRequest("POST", "/plans", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid value for requests.cpumilli\n")
+}
+
 func (s *S) TestPlanAddWithNoPermission(c *check.C) {
 	token := userWithPermission(c)
 	recorder := httptest.NewRecorder()
@@ -262,3 +320,192 @@ func (s *S) TestPlanGetSize(c *check.C) {
 	c.Check(getSize("10Mi"), check.Equals, int64(10485760))
 	c.Check(getSize("10Gi"), check.Equals, int64(10737418240))
 }
+
+func (s *S) TestPlanListDefaults(c *check.C) {
+	expected := []appTypes.PlanDefault{
+		{Plan: "plan1", Pool: "pool1"},
+		{Plan: "plan2", Team: "team1"},
+	}
+	s.mockService.Plan.OnListDefaults = func() ([]appTypes.PlanDefault, error) {
+		return expected, nil
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/plans/defaults", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var defaults []appTypes.PlanDefault
+	err = json.Unmarshal(recorder.Body.Bytes(), &defaults)
+	c.Assert(err, check.IsNil)
+	c.Assert(defaults, check.DeepEquals, expected)
+}
+
+func (s *S) TestPlanListDefaultsEmpty(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/plans/defaults", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestPlanSetDefault(c *check.C) {
+	s.mockService.Plan.OnSetDefault = func(d appTypes.PlanDefault) error {
+		c.Assert(d, check.Equals, appTypes.PlanDefault{Plan: "plan1", Pool: "pool1"})
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/plans/plan1/default", strings.NewReader("pool=pool1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePlan, Value: "plan1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "plan.update.default",
+		StartCustomData: []map[string]interface{}{
+			{"name": "pool", "value": "pool1"},
+			{"name": ":planname", "value": "plan1"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPlanSetDefaultInvalidTarget(c *check.C) {
+	s.mockService.Plan.OnSetDefault = func(d appTypes.PlanDefault) error {
+		return appTypes.ErrPlanDefaultTarget
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/plans/plan1/default", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPlanSetDefaultNoPermission(c *check.C) {
+	s.mockService.Plan.OnSetDefault = func(d appTypes.PlanDefault) error {
+		c.Error("Plan service not expected to be called.")
+		return nil
+	}
+	token := userWithPermission(c)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/plans/plan1/default", strings.NewReader("team=team1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestPlanUnsetDefault(c *check.C) {
+	s.mockService.Plan.OnUnsetDefault = func(d appTypes.PlanDefault) error {
+		c.Assert(d, check.Equals, appTypes.PlanDefault{Plan: "plan1", Team: "team1"})
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("DELETE", "/plans/plan1/default?team=team1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestPlanUnsetDefaultNotFound(c *check.C) {
+	s.mockService.Plan.OnUnsetDefault = func(d appTypes.PlanDefault) error {
+		return appTypes.ErrPlanDefaultNotFound
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("DELETE", "/plans/plan1/default?team=team1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPlanRemoveInUse(c *check.C) {
+	s.mockService.Plan.OnRemove = func(name string) error {
+		return &appTypes.PlanInUseError{Usage: appTypes.PlanUsage{Plan: name, Apps: []string{"app1"}}}
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("DELETE", "/plans/plan1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, `plan "plan1" is used by 1 app(s) and 0 job(s), a replacement plan is required to remove it`+"\n")
+}
+
+func (s *S) TestPlanRemoveWithReplacement(c *check.C) {
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		return &appTypes.Plan{Name: name}, nil
+	}
+	s.mockService.Plan.OnUsage = func(name string) (*appTypes.PlanUsage, error) {
+		c.Assert(name, check.Equals, "plan1")
+		return &appTypes.PlanUsage{Plan: name}, nil
+	}
+	removed := false
+	s.mockService.Plan.OnRemove = func(name string) error {
+		c.Assert(name, check.Equals, "plan1")
+		removed = true
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("DELETE", "/plans/plan1?replacement=plan2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(removed, check.Equals, true)
+}
+
+func (s *S) TestPlanRemoveWithInvalidReplacement(c *check.C) {
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		return nil, appTypes.ErrPlanNotFound
+	}
+	s.mockService.Plan.OnRemove = func(name string) error {
+		c.Error("Plan service not expected to be called.")
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("DELETE", "/plans/plan1?replacement=plan2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPlanUsage(c *check.C) {
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		return &appTypes.Plan{Name: name}, nil
+	}
+	s.mockService.Plan.OnUsage = func(name string) (*appTypes.PlanUsage, error) {
+		return &appTypes.PlanUsage{Plan: name, Apps: []string{"app1"}, Jobs: []string{"job1"}}, nil
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/plans/plan1/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var usage appTypes.PlanUsage
+	err = json.Unmarshal(recorder.Body.Bytes(), &usage)
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, appTypes.PlanUsage{Plan: "plan1", Apps: []string{"app1"}, Jobs: []string{"job1"}})
+}
+
+func (s *S) TestPlanUsageNotFound(c *check.C) {
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		return nil, appTypes.ErrPlanNotFound
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/plans/plan1/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/policy"
+	"github.com/tsuru/tsuru/provision/pool"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// policyContexts returns the permission contexts of a policy, global
+// policies require global permissions.
+func policyContexts(poolName string) []permTypes.PermissionContext {
+	if poolName == "" {
+		return nil
+	}
+	return []permTypes.PermissionContext{permission.Context(permTypes.CtxPool, poolName)}
+}
+
+func policyTarget(poolName string) eventTypes.Target {
+	if poolName == "" {
+		return eventTypes.Target{Type: eventTypes.TargetTypeGlobal}
+	}
+	return eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName}
+}
+
+// checkAdmissionPolicies evaluates the admission policies for an operation
+// on an existing app, denied operations are reported as forbidden.
+func checkAdmissionPolicies(ctx context.Context, t auth.Token, operation string, a *appTypes.App, data map[string]interface{}) error {
+	units, err := app.AppUnits(ctx, a)
+	if err != nil {
+		return err
+	}
+	err = policy.Check(ctx, policy.Input{
+		Operation: operation,
+		Pool:      a.Pool,
+		User:      t.GetUserName(),
+		App:       policy.NewApp(a, units),
+		Data:      data,
+	})
+	if _, ok := err.(*policy.DeniedError); ok {
+		return &errors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
+	}
+	return err
+}
+
+// title: admission policy list
+// path: /admission-policies
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+func listAdmissionPolicies(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	poolName := InputValue(r, "pool")
+	if !permission.Check(ctx, t, permission.PermPoolReadPolicies, policyContexts(poolName)...) {
+		return permission.ErrUnauthorized
+	}
+	policies, err := policy.List(ctx, poolName)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(policies)
+}
+
+// title: admission policy set
+// path: /admission-policies/{name}
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+func setAdmissionPolicy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var p policy.Policy
+	if err = ParseInput(r, &p); err != nil {
+		return err
+	}
+	p.Name = r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermPoolUpdatePolicies, policyContexts(p.Pool)...) {
+		return permission.ErrUnauthorized
+	}
+	existing, err := policy.Get(ctx, p.Name)
+	if err == nil && existing.Pool != p.Pool {
+		if !permission.Check(ctx, t, permission.PermPoolUpdatePolicies, policyContexts(existing.Pool)...) {
+			return permission.ErrUnauthorized
+		}
+	} else if err != nil && err != policy.ErrPolicyNotFound {
+		return err
+	}
+	if p.Pool != "" {
+		if _, err = pool.GetPoolByName(ctx, p.Pool); err != nil {
+			if err == pool.ErrPoolNotFound {
+				return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+			}
+			return err
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     policyTarget(p.Pool),
+		Kind:       permission.PermPoolUpdatePolicies,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: p,
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, policyContexts(p.Pool)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = policy.Save(ctx, &p)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: admission policy remove
+// path: /admission-policies/{name}
+// method: DELETE
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Not found
+func removeAdmissionPolicy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	p, err := policy.Get(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		if err == policy.ErrPolicyNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermPoolUpdatePolicies, policyContexts(p.Pool)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     policyTarget(p.Pool),
+		Kind:       permission.PermPoolUpdatePolicies,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]string{"remove": p.Name},
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, policyContexts(p.Pool)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return policy.Remove(ctx, p.Name)
+}
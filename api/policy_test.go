@@ -0,0 +1,136 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/policy"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+// fakeOPAServer accepts every module and denies operations evaluated by the
+// policies in deny.
+func fakeOPAServer(deny map[string][]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1/data/tsuru/policies/") {
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/data/tsuru/policies/"), "/deny")
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": deny[name]})
+	}))
+}
+
+func policyModule(name string) string {
+	return fmt.Sprintf("package tsuru.policies.%s\n\ndeny contains msg if {\n\tinput.app.units.web < 2\n\tmsg := \"at least 2 units\"\n}\n", name)
+}
+
+func (s *S) TestSetAdmissionPolicy(c *check.C) {
+	srv := fakeOPAServer(nil)
+	defer srv.Close()
+	config.Set("admission-policies:opa:url", srv.URL)
+	defer config.Unset("admission-policies")
+	body := fmt.Sprintf(`{"pool": %q, "operations": ["app.deploy"], "module": %q}`, s.Pool, policyModule("min_units"))
+	req, err := http.NewRequest(http.MethodPut, "/1.25/admission-policies/min_units", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	p, err := policy.Get(context.TODO(), "min_units")
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &policy.Policy{
+		Name:       "min_units",
+		Pool:       s.Pool,
+		Operations: []string{policy.OperationAppDeploy},
+		Module:     policyModule("min_units"),
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: s.Pool},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.policies",
+	}, eventtest.HasEvent)
+	req, err = http.NewRequest(http.MethodGet, "/1.25/admission-policies?pool="+s.Pool, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var policies []policy.Policy
+	err = json.NewDecoder(rec.Body).Decode(&policies)
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.DeepEquals, []policy.Policy{*p})
+}
+
+func (s *S) TestSetAdmissionPolicyInvalid(c *check.C) {
+	srv := fakeOPAServer(nil)
+	defer srv.Close()
+	config.Set("admission-policies:opa:url", srv.URL)
+	defer config.Unset("admission-policies")
+	body := fmt.Sprintf(`{"operations": ["app.deploy"], "module": %q}`, policyModule("other"))
+	req, err := http.NewRequest(http.MethodPut, "/1.25/admission-policies/min_units", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, "module must declare package tsuru.policies.min_units\n")
+}
+
+func (s *S) TestRemoveAdmissionPolicy(c *check.C) {
+	srv := fakeOPAServer(nil)
+	defer srv.Close()
+	config.Set("admission-policies:opa:url", srv.URL)
+	defer config.Unset("admission-policies")
+	err := policy.Save(context.TODO(), &policy.Policy{Name: "min_units", Operations: []string{policy.OperationAppDeploy}, Module: policyModule("min_units")})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/1.25/admission-policies/min_units", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	_, err = policy.Get(context.TODO(), "min_units")
+	c.Assert(err, check.Equals, policy.ErrPolicyNotFound)
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSetEnvDeniedByAdmissionPolicy(c *check.C) {
+	srv := fakeOPAServer(map[string][]string{"no_envs": {"envs are managed by the platform team"}})
+	defer srv.Close()
+	config.Set("admission-policies:opa:url", srv.URL)
+	defer config.Unset("admission-policies")
+	err := policy.Save(context.TODO(), &policy.Policy{Name: "no_envs", Operations: []string{policy.OperationAppEnvSet}, Module: policyModule("no_envs")})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := `{"envs": [{"name": "DATABASE_HOST", "value": "localhost"}]}`
+	req, err := http.NewRequest(http.MethodPost, "/apps/black-dog/env", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+	c.Assert(rec.Body.String(), check.Equals, "app.env.set denied by admission policies: no_envs: envs are managed by the platform team\n")
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DATABASE_HOST"].Value, check.Equals, "")
+}
@@ -306,6 +306,113 @@ func removeTeamToPoolHandler(w http.ResponseWriter, r *http.Request, t auth.Toke
 	}
 }
 
+// title: pool scheduling labels
+// path: /pools/{name}/labels
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Pool not found
+func poolLabelsHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolRead, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	p, err := pool.GetPoolByName(ctx, poolName)
+	if err != nil {
+		if err == pool.ErrPoolNotFound {
+			return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	labels, err := p.SchedulingLabels(ctx)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(labels)
+}
+
+// title: set pool labels
+// path: /pools/{name}/labels
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Pool updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+func setPoolLabelsHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolUpdateLabels, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var data struct {
+		Labels map[string]string
+	}
+	err = ParseInput(r, &data)
+	if err != nil {
+		return err
+	}
+	if len(data.Labels) == 0 {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the labels."}
+	}
+	return updatePoolLabels(ctx, r, t, poolName, data.Labels, nil)
+}
+
+// title: unset pool labels
+// path: /pools/{name}/labels
+// method: DELETE
+// responses:
+//
+//	200: Pool updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+func unsetPoolLabelsHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolUpdateLabels, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	labels, _ := InputValues(r, "label")
+	if len(labels) == 0 {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the labels."}
+	}
+	return updatePoolLabels(ctx, r, t, poolName, nil, labels)
+}
+
+func updatePoolLabels(ctx context.Context, r *http.Request, t auth.Token, poolName string, labels map[string]string, unset []string) (err error) {
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateLabels,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = pool.SetPoolLabels(ctx, poolName, labels, unset)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if v, ok := err.(*terrors.ValidationError); ok {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
 // title: pool update
 // path: /pools/{name}
 // method: PUT
@@ -383,9 +490,11 @@ func poolConstraintList(w http.ResponseWriter, r *http.Request, t auth.Token) er
 // path: /constraints
 // method: PUT
 // consume: application/x-www-form-urlencoded
+// produce: application/json
 // responses:
 //
 //	200: OK
+//	400: Invalid data
 //	401: Unauthorized
 func poolConstraintSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
@@ -403,6 +512,21 @@ func poolConstraintSet(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 			Message: "You must provide a Pool Expression",
 		}
 	}
+	append := false
+	if appendStr := InputValue(r, "append"); appendStr != "" {
+		append, _ = strconv.ParseBool(appendStr)
+	}
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run")); dryRun {
+		impacts, errImpact := app.PoolConstraintImpact(ctx, &poolConstraint, append)
+		if errImpact == pool.ErrInvalidConstraintType {
+			return &terrors.HTTP{Code: http.StatusBadRequest, Message: errImpact.Error()}
+		}
+		if errImpact != nil {
+			return errImpact
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(impacts)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolConstraint.PoolExpr},
 		Kind:       permission.PermPoolUpdateConstraintsSet,
@@ -415,10 +539,6 @@ func poolConstraintSet(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
-	append := false
-	if appendStr := InputValue(r, "append"); appendStr != "" {
-		append, _ = strconv.ParseBool(appendStr)
-	}
 	if append {
 		return pool.AppendPoolConstraint(ctx, &poolConstraint)
 	}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -24,6 +25,7 @@ import (
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	check "gopkg.in/check.v1"
 )
@@ -764,6 +766,37 @@ func (s *S) TestPoolConstraintSet(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestPoolConstraintSetDryRun(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Pool: "test1"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	params := pool.PoolConstraint{
+		PoolExpr: "test1",
+		Field:    pool.ConstraintTypeTeam,
+		Values:   []string{"other-team"},
+	}
+	v, err := form.EncodeToValues(&params)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/1.3/constraints?dry-run=true", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	var impacts []app.ConfigImpact
+	err = json.NewDecoder(rec.Body).Decode(&impacts)
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.DeepEquals, []app.ConfigImpact{
+		{Kind: app.ImpactKindApp, Name: "myapp", Pool: "test1", Reason: fmt.Sprintf("team %q would not be allowed in the pool", s.team.Name)},
+	})
+	constraints, err := pool.ListPoolsConstraints(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(constraints, check.DeepEquals, []*pool.PoolConstraint{
+		{PoolExpr: "test1", Field: pool.ConstraintTypeTeam, Values: []string{"*"}},
+	})
+}
+
 func (s *S) TestPoolConstraintSetAppend(c *check.C) {
 	err := pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: "*", Field: pool.ConstraintTypeRouter, Values: []string{"routerA"}, Blacklist: true})
 	c.Assert(err, check.IsNil)
@@ -836,3 +869,72 @@ func (s *S) TestPoolGetHandler(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(pool, check.DeepEquals, expected)
 }
+
+func (s *S) TestPoolLabels(c *check.C) {
+	s.mockService.Cluster.OnFindByPool = func(prov, pool string) (*provTypes.Cluster, error) {
+		return &provTypes.Cluster{Name: "c1", Labels: map[string]string{"ssd": "true", "zone": "a"}}, nil
+	}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1", Provisioner: "fake", Labels: map[string]string{"zone": "b"}})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/pools/pool1/labels", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var labels map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &labels)
+	c.Assert(err, check.IsNil)
+	c.Assert(labels, check.DeepEquals, map[string]string{"ssd": "true", "zone": "b"})
+}
+
+func (s *S) TestSetPoolLabels(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1", Labels: map[string]string{"zone": "a"}})
+	c.Assert(err, check.IsNil)
+	b := strings.NewReader("Labels.ssd=true&Labels.pci-dss=true")
+	req, err := http.NewRequest(http.MethodPost, "/pools/pool1/labels", b)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Labels, check.DeepEquals, map[string]string{"zone": "a", "ssd": "true", "pci-dss": "true"})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.labels",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "pool1"},
+			{"name": "Labels.ssd", "value": "true"},
+			{"name": "Labels.pci-dss", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetPoolLabelsNotFound(c *check.C) {
+	b := strings.NewReader("Labels.ssd=true")
+	req, err := http.NewRequest(http.MethodPost, "/pools/notfound/labels", b)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestUnsetPoolLabels(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1", Labels: map[string]string{"zone": "a", "ssd": "true"}})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/pools/pool1/labels?label=ssd", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Labels, check.DeepEquals, map[string]string{"zone": "a"})
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app unit port forward
+// path: /apps/{appname}/units/{unit}/port-forward
+// method: GET
+// produce: Websocket connection upgrade
+// responses:
+//
+//	101: Switch Protocol to websocket
+func portForwardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		fmt.Fprintf(w, "unable to upgrade ws connection: %v", err)
+		return
+	}
+	var httpErr *errors.HTTP
+	defer func() {
+		if httpErr != nil {
+			// Data of the forwarded connection is only sent in binary
+			// messages, errors are told apart by being sent as text.
+			var msg string
+			switch httpErr.Code {
+			case http.StatusUnauthorized:
+				msg = "no token provided or session expired, please login again\n"
+			default:
+				msg = httpErr.Message + "\n"
+			}
+			ws.WriteMessage(websocket.TextMessage, []byte("Error: "+msg))
+		}
+		ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		ws.Close()
+	}()
+	token := context.GetAuthToken(r)
+	if token == nil {
+		httpErr = &errors.HTTP{
+			Code:    http.StatusUnauthorized,
+			Message: "no token provided",
+		}
+		return
+	}
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		if herr, ok := err.(*errors.HTTP); ok {
+			httpErr = herr
+		} else {
+			httpErr = &errors.HTTP{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		return
+	}
+	allowed := permission.Check(ctx, token, permission.PermAppRunPortforward, contextsForApp(a)...)
+	if !allowed {
+		httpErr = permission.ErrUnauthorized
+		return
+	}
+	unit := r.URL.Query().Get(":unit")
+	port, err := strconv.Atoi(r.URL.Query().Get("port"))
+	if err != nil {
+		httpErr = &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid port %q", r.URL.Query().Get("port")),
+		}
+		return
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      appTarget(appName),
+		Kind:        permission.PermAppRunPortforward,
+		Owner:       token,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		DisableLock: true,
+	})
+	if err != nil {
+		httpErr = &errors.HTTP{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+		return
+	}
+	defer func() {
+		var finalErr error
+		if httpErr != nil {
+			finalErr = httpErr
+		}
+		evt.Done(ctx, finalErr)
+	}()
+	fmt.Fprintf(evt, "forwarding port %d of unit %s\n", port, unit)
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(string) error {
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	quit := make(chan struct{})
+	defer close(quit)
+	go func() {
+		for {
+			select {
+			case <-quit:
+				return
+			case <-time.After(pingInterval):
+			}
+			ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(2*time.Second))
+		}
+	}()
+	err = app.PortForward(ctx, a, unit, port, &wsBinaryReadWriter{Conn: ws})
+	if err != nil {
+		code := http.StatusInternalServerError
+		if _, ok := err.(*errors.ValidationError); ok {
+			code = http.StatusBadRequest
+		}
+		httpErr = &errors.HTTP{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+}
+
+// wsBinaryReadWriter carries the raw data of a forwarded connection in the
+// messages of a websocket, which may be larger than the buffers used to read
+// them.
+type wsBinaryReadWriter struct {
+	*websocket.Conn
+	reader io.Reader
+}
+
+func (c *wsBinaryReadWriter) Read(p []byte) (int, error) {
+	for {
+		if c.reader == nil {
+			messageType, r, err := c.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			if messageType != websocket.BinaryMessage && messageType != websocket.TextMessage {
+				continue
+			}
+			c.reader = r
+		}
+		n, err := c.reader.Read(p)
+		if err == io.EOF {
+			c.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *wsBinaryReadWriter) Write(p []byte) (int, error) {
+	return len(p), c.Conn.WriteMessage(websocket.BinaryMessage, p)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/net/websocket"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) dialPortForward(c *check.C, server *httptest.Server, appName, unit, port string, token auth.Token) *websocket.Conn {
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("ws://%s/apps/%s/units/%s/port-forward?port=%s", serverURL.Host, appName, unit, port)
+	config, err := websocket.NewConfig(u, "ws://localhost/")
+	c.Assert(err, check.IsNil)
+	config.Header.Set("Authorization", "bearer "+token.GetValue())
+	wsConn, err := websocket.DialConfig(config)
+	c.Assert(err, check.IsNil)
+	return wsConn
+}
+
+func (s *S) TestPortForward(c *check.C) {
+	a := appTypes.App{Name: "someapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	c.Assert(err, check.IsNil)
+	units, err := s.provisioner.Units(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("pong"))
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialPortForward(c, server, a.Name, units[0].ID, "5005", s.token)
+	defer wsConn.Close()
+	var data []byte
+	err = websocket.Message.Receive(wsConn, &data)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "pong")
+	forwards := s.provisioner.PortForwards(units[0].ID)
+	c.Assert(forwards, check.HasLen, 1)
+	c.Assert(forwards[0].App.Name, check.Equals, a.Name)
+	c.Assert(forwards[0].Port, check.Equals, 5005)
+}
+
+func (s *S) TestPortForwardInvalidPort(c *check.C) {
+	a := appTypes.App{Name: "someapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialPortForward(c, server, a.Name, "someapp-web-1", "http", s.token)
+	defer wsConn.Close()
+	var msg string
+	err = websocket.Message.Receive(wsConn, &msg)
+	c.Assert(err, check.IsNil)
+	c.Assert(msg, check.Equals, "Error: invalid port \"http\"\n")
+}
+
+func (s *S) TestPortForwardWithoutPermission(c *check.C) {
+	a := appTypes.App{Name: "someapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRunShell,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	wsConn := s.dialPortForward(c, server, a.Name, "someapp-web-1", "5005", token)
+	defer wsConn.Close()
+	var msg string
+	err = websocket.Message.Receive(wsConn, &msg)
+	c.Assert(err, check.IsNil)
+	c.Assert(msg, check.Equals, "Error: You don't have permission to do this action\n")
+	c.Assert(s.provisioner.PortForwards("someapp-web-1"), check.HasLen, 0)
+}
@@ -0,0 +1,386 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	defaultQuotaGrantMaxDuration = 7 * 24 * time.Hour
+	quotaGrantExpirationInterval = time.Minute
+)
+
+// quotaIncreaseRequest is stored as the start custom data of the event
+// created when a team requests a temporary quota increase. The request is
+// pending while the event is running.
+type quotaIncreaseRequest struct {
+	ID          string    `json:"id" bson:"-"`
+	Increase    int       `json:"increase"`
+	Duration    string    `json:"duration"`
+	Reason      string    `json:"reason"`
+	RequestedBy string    `json:"requestedBy" bson:"-"`
+	CreatedAt   time.Time `json:"createdAt" bson:"-"`
+}
+
+type teamQuotaRequests struct {
+	Pending []quotaIncreaseRequest `json:"pending"`
+	Grants  []authTypes.QuotaGrant `json:"grants"`
+}
+
+func quotaGrantMaxDuration() time.Duration {
+	maxDuration, _ := config.GetDuration("quota:grants:max-duration")
+	if maxDuration <= 0 {
+		return defaultQuotaGrantMaxDuration
+	}
+	return maxDuration
+}
+
+func teamForQuotaRequest(ctx context.Context, teamName string) (*authTypes.Team, error) {
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return team, err
+}
+
+// pendingQuotaRequest returns the running request event with the given id
+// for the team.
+func pendingQuotaRequest(ctx context.Context, teamName, id string) (*event.Event, error) {
+	notFound := &errors.HTTP{Code: http.StatusNotFound, Message: "quota request not found"}
+	evt, err := event.GetByHexID(ctx, id)
+	if err != nil {
+		if err == event.ErrEventNotFound {
+			return nil, notFound
+		}
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if !evt.Running || evt.Kind.Name != permission.PermTeamUpdateQuotaRequest.FullName() ||
+		evt.Target != (eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName}) {
+		return nil, notFound
+	}
+	return evt, nil
+}
+
+func quotaRequestFromEvent(evt *event.Event) (quotaIncreaseRequest, error) {
+	var req quotaIncreaseRequest
+	err := evt.StartData(&req)
+	if err != nil {
+		return req, err
+	}
+	req.ID = evt.UniqueID.Hex()
+	req.RequestedBy = evt.Owner.Name
+	req.CreatedAt = evt.StartTime
+	return req, nil
+}
+
+// title: request team quota increase
+// path: /teams/{name}/quota/requests
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	201: Request created
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team not found
+func requestTeamQuotaIncrease(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateQuotaRequest, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	if _, err := teamForQuotaRequest(ctx, teamName); err != nil {
+		return err
+	}
+	increase, err := strconv.Atoi(InputValue(r, "increase"))
+	if err != nil || increase <= 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "increase must be a positive integer"}
+	}
+	duration, err := time.ParseDuration(InputValue(r, "duration"))
+	if err != nil || duration <= 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "duration must be a positive duration, e.g. 48h"}
+	}
+	if maxDuration := quotaGrantMaxDuration(); duration > maxDuration {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("duration must not be greater than %v", maxDuration)}
+	}
+	req := quotaIncreaseRequest{
+		Increase: increase,
+		Duration: duration.String(),
+		Reason:   InputValue(r, "reason"),
+	}
+	if req.Reason == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "reason is required"}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:        permission.PermTeamUpdateQuotaRequest,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  req,
+		Allowed:     event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	req, err = quotaRequestFromEvent(evt)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(req)
+}
+
+// title: list team quota requests
+// path: /teams/{name}/quota/requests
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Team not found
+func listTeamQuotaRequests(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamReadQuota, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	team, err := teamForQuotaRequest(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	running := true
+	evts, err := event.List(ctx, &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		KindNames: []string{permission.PermTeamUpdateQuotaRequest.FullName()},
+		Running:   &running,
+	})
+	if err != nil {
+		return err
+	}
+	result := teamQuotaRequests{
+		Pending: []quotaIncreaseRequest{},
+		Grants:  team.QuotaGrants,
+	}
+	if result.Grants == nil {
+		result.Grants = []authTypes.QuotaGrant{}
+	}
+	for _, evt := range evts {
+		req, err := quotaRequestFromEvent(evt)
+		if err != nil {
+			return err
+		}
+		result.Pending = append(result.Pending, req)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: approve team quota request
+// path: /teams/{name}/quota/requests/{id}/approve
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Request approved
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Request created by the approver
+//	404: Request not found
+func approveTeamQuotaRequest(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	reqEvt, evt, err := decideTeamQuotaRequest(r, t)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	req, err := quotaRequestFromEvent(reqEvt)
+	if err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		return err
+	}
+	grant := authTypes.QuotaGrant{
+		ID:          req.ID,
+		Increase:    req.Increase,
+		Reason:      req.Reason,
+		RequestedBy: req.RequestedBy,
+		ApprovedBy:  t.GetUserName(),
+		ExpiresAt:   time.Now().UTC().Add(duration),
+	}
+	err = servicemanager.Team.AddQuotaGrant(ctx, teamName, grant)
+	if err == authTypes.ErrQuotaGrantUnlimited {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	err = reqEvt.DoneCustomData(ctx, nil, grant)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(grant)
+}
+
+// title: reject team quota request
+// path: /teams/{name}/quota/requests/{id}/reject
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Request rejected
+//	401: Unauthorized
+//	403: Request created by the approver
+//	404: Request not found
+func rejectTeamQuotaRequest(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	reqEvt, evt, err := decideTeamQuotaRequest(r, t)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	msg := fmt.Sprintf("rejected by %s", t.GetUserName())
+	if reason := InputValue(r, "reason"); reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, reason)
+	}
+	return reqEvt.Done(ctx, pkgErrors.New(msg))
+}
+
+// decideTeamQuotaRequest checks whether the token is allowed to approve or
+// reject the request and creates the event auditing the decision.
+func decideTeamQuotaRequest(r *http.Request, t auth.Token) (*event.Event, *event.Event, error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateQuotaApprove, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return nil, nil, permission.ErrUnauthorized
+	}
+	reqEvt, err := pendingQuotaRequest(ctx, teamName, r.URL.Query().Get(":id"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if reqEvt.Owner.Name == t.GetUserName() {
+		return nil, nil, &errors.HTTP{Code: http.StatusForbidden, Message: "quota requests must be approved or rejected by someone other than the requester"}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:       permission.PermTeamUpdateQuotaApprove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return reqEvt, evt, nil
+}
+
+type quotaGrantExpirer struct {
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func startQuotaGrantExpirer() {
+	e := &quotaGrantExpirer{
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go e.spin()
+	shutdown.Register(e)
+}
+
+func (e *quotaGrantExpirer) spin() {
+	defer close(e.doneCh)
+	for {
+		err := expireQuotaGrants(context.Background(), time.Now())
+		if err != nil {
+			log.Errorf("[quota-grant-expirer] %v", err)
+		}
+		select {
+		case <-e.stopCh:
+			return
+		case <-time.After(quotaGrantExpirationInterval):
+		}
+	}
+}
+
+func (e *quotaGrantExpirer) Shutdown(ctx context.Context) error {
+	close(e.stopCh)
+	select {
+	case <-e.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// expireQuotaGrants reverts the quota increases which expired before now.
+func expireQuotaGrants(ctx context.Context, now time.Time) error {
+	teams, err := servicemanager.Team.List(ctx)
+	if err != nil {
+		return err
+	}
+	multi := errors.NewMultiError()
+	for _, team := range teams {
+		for _, grant := range team.QuotaGrants {
+			if grant.ExpiresAt.After(now) {
+				continue
+			}
+			err = expireQuotaGrant(ctx, team.Name, grant)
+			if err != nil {
+				multi.Add(pkgErrors.Wrapf(err, "unable to expire quota grant %q of team %q", grant.ID, team.Name))
+			}
+		}
+	}
+	return multi.ToError()
+}
+
+func expireQuotaGrant(ctx context.Context, teamName string, grant authTypes.QuotaGrant) (err error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		InternalKind: "quota grant expire",
+		CustomData:   grant,
+		Allowed:      event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+		DisableLock:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Team.RemoveQuotaGrant(ctx, teamName, grant.ID)
+	if err == authTypes.ErrQuotaGrantNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *QuotaSuite) quotaRequestTokens(c *check.C) (auth.Token, auth.Token) {
+	_, requester := permissiontest.CustomUserWithPermission(c, nativeScheme, "quotarequester", permTypes.Permission{
+		Scheme:  permission.PermTeamUpdateQuotaRequest,
+		Context: permission.Context(permTypes.CtxTeam, "avengers"),
+	}, permTypes.Permission{
+		Scheme:  permission.PermTeamReadQuota,
+		Context: permission.Context(permTypes.CtxTeam, "avengers"),
+	}, permTypes.Permission{
+		Scheme:  permission.PermTeamUpdateQuotaApprove,
+		Context: permission.Context(permTypes.CtxTeam, "avengers"),
+	})
+	_, approver := permissiontest.CustomUserWithPermission(c, nativeScheme, "quotaapprover", permTypes.Permission{
+		Scheme:  permission.PermTeamUpdateQuotaApprove,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	return requester, approver
+}
+
+func (s *QuotaSuite) requestQuotaIncrease(c *check.C, token auth.Token, body string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("POST", "/teams/avengers/quota/requests", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *QuotaSuite) TestRequestTeamQuotaIncrease(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Quota: quota.Quota{Limit: 4}}, nil
+	}
+	requester, _ := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=48h&reason=black+friday")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var req quotaIncreaseRequest
+	err := json.NewDecoder(recorder.Body).Decode(&req)
+	c.Assert(err, check.IsNil)
+	c.Assert(req.ID, check.Not(check.Equals), "")
+	c.Assert(req.Increase, check.Equals, 2)
+	c.Assert(req.Duration, check.Equals, "48h0m0s")
+	c.Assert(req.RequestedBy, check.Equals, requester.GetUserName())
+	evt, err := event.GetByHexID(context.TODO(), req.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, true)
+	c.Assert(evt.Kind.Name, check.Equals, "team.update.quota.request")
+	c.Assert(evt.Target, check.DeepEquals, eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: "avengers"})
+}
+
+func (s *QuotaSuite) TestRequestTeamQuotaIncreaseInvalidData(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	requester, _ := s.quotaRequestTokens(c)
+	tests := []struct {
+		body string
+		msg  string
+	}{
+		{"increase=0&duration=1h&reason=x", "increase must be a positive integer\n"},
+		{"increase=a&duration=1h&reason=x", "increase must be a positive integer\n"},
+		{"increase=1&duration=xyz&reason=x", "duration must be a positive duration, e.g. 48h\n"},
+		{"increase=1&duration=200h&reason=x", "duration must not be greater than 168h0m0s\n"},
+		{"increase=1&duration=1h", "reason is required\n"},
+	}
+	for _, tt := range tests {
+		recorder := s.requestQuotaIncrease(c, requester, tt.body)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf(tt.body))
+		c.Assert(recorder.Body.String(), check.Equals, tt.msg)
+	}
+}
+
+func (s *QuotaSuite) TestRequestTeamQuotaIncreaseTeamNotFound(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return nil, authTypes.ErrTeamNotFound
+	}
+	requester, _ := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *QuotaSuite) TestRequestTeamQuotaIncreaseRequiresPermission(c *check.C) {
+	token := userWithPermission(c)
+	recorder := s.requestQuotaIncrease(c, token, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *QuotaSuite) TestListTeamQuotaRequests(c *check.C) {
+	grant := authTypes.QuotaGrant{ID: "g1", Increase: 1, Reason: "old", ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second)}
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, QuotaGrants: []authTypes.QuotaGrant{grant}}, nil
+	}
+	requester, _ := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	request, _ := http.NewRequest("GET", "/teams/avengers/quota/requests", nil)
+	request.Header.Set("Authorization", "bearer "+requester.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result teamQuotaRequests
+	err := json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Pending, check.HasLen, 1)
+	c.Assert(result.Pending[0].Increase, check.Equals, 2)
+	c.Assert(result.Pending[0].Reason, check.Equals, "x")
+	c.Assert(result.Grants, check.HasLen, 1)
+	c.Assert(result.Grants[0].ID, check.Equals, "g1")
+}
+
+func (s *QuotaSuite) TestApproveTeamQuotaRequest(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Quota: quota.Quota{Limit: 4}}, nil
+	}
+	requester, approver := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var req quotaIncreaseRequest
+	err := json.NewDecoder(recorder.Body).Decode(&req)
+	c.Assert(err, check.IsNil)
+	var added authTypes.QuotaGrant
+	s.mockService.Team.OnAddQuotaGrant = func(teamName string, grant authTypes.QuotaGrant) error {
+		c.Assert(teamName, check.Equals, "avengers")
+		added = grant
+		return nil
+	}
+	request, _ := http.NewRequest("POST", fmt.Sprintf("/teams/avengers/quota/requests/%s/approve", req.ID), nil)
+	request.Header.Set("Authorization", "bearer "+approver.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(added.ID, check.Equals, req.ID)
+	c.Assert(added.Increase, check.Equals, 2)
+	c.Assert(added.RequestedBy, check.Equals, requester.GetUserName())
+	c.Assert(added.ApprovedBy, check.Equals, approver.GetUserName())
+	c.Assert(added.ExpiresAt.After(time.Now().Add(59*time.Minute)), check.Equals, true)
+	evt, err := event.GetByHexID(context.TODO(), req.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, false)
+	c.Assert(evt.Error, check.Equals, "")
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: "avengers"},
+		Owner:  approver.GetUserName(),
+		Kind:   "team.update.quota.approve",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "avengers"},
+			{"name": ":id", "value": req.ID},
+		},
+	}, eventtest.HasEvent)
+	request, _ = http.NewRequest("POST", fmt.Sprintf("/teams/avengers/quota/requests/%s/approve", req.ID), nil)
+	request.Header.Set("Authorization", "bearer "+approver.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *QuotaSuite) TestApproveTeamQuotaRequestByRequester(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Quota: quota.Quota{Limit: 4}}, nil
+	}
+	requester, _ := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var req quotaIncreaseRequest
+	err := json.NewDecoder(recorder.Body).Decode(&req)
+	c.Assert(err, check.IsNil)
+	s.mockService.Team.OnAddQuotaGrant = func(teamName string, grant authTypes.QuotaGrant) error {
+		c.Fatal("quota grant should not be added")
+		return nil
+	}
+	request, _ := http.NewRequest("POST", fmt.Sprintf("/teams/avengers/quota/requests/%s/approve", req.ID), nil)
+	request.Header.Set("Authorization", "bearer "+requester.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *QuotaSuite) TestApproveTeamQuotaRequestUnlimitedQuota(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Quota: quota.UnlimitedQuota}, nil
+	}
+	requester, approver := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var req quotaIncreaseRequest
+	err := json.NewDecoder(recorder.Body).Decode(&req)
+	c.Assert(err, check.IsNil)
+	s.mockService.Team.OnAddQuotaGrant = func(teamName string, grant authTypes.QuotaGrant) error {
+		return authTypes.ErrQuotaGrantUnlimited
+	}
+	request, _ := http.NewRequest("POST", fmt.Sprintf("/teams/avengers/quota/requests/%s/approve", req.ID), nil)
+	request.Header.Set("Authorization", "bearer "+approver.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, authTypes.ErrQuotaGrantUnlimited.Error()+"\n")
+	evt, err := event.GetByHexID(context.TODO(), req.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, true)
+}
+
+func (s *QuotaSuite) TestRejectTeamQuotaRequest(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Quota: quota.Quota{Limit: 4}}, nil
+	}
+	requester, approver := s.quotaRequestTokens(c)
+	recorder := s.requestQuotaIncrease(c, requester, "increase=2&duration=1h&reason=x")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var req quotaIncreaseRequest
+	err := json.NewDecoder(recorder.Body).Decode(&req)
+	c.Assert(err, check.IsNil)
+	s.mockService.Team.OnAddQuotaGrant = func(teamName string, grant authTypes.QuotaGrant) error {
+		c.Fatal("quota grant should not be added")
+		return nil
+	}
+	request, _ := http.NewRequest("POST", fmt.Sprintf("/teams/avengers/quota/requests/%s/reject", req.ID), strings.NewReader("reason=not+now"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+approver.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	evt, err := event.GetByHexID(context.TODO(), req.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, false)
+	c.Assert(evt.Error, check.Equals, "rejected by quotaapprover: not now")
+}
+
+func (s *QuotaSuite) TestExpireQuotaGrants(c *check.C) {
+	now := time.Now()
+	expired := authTypes.QuotaGrant{ID: "g1", Increase: 2, ExpiresAt: now.Add(-time.Minute)}
+	active := authTypes.QuotaGrant{ID: "g2", Increase: 1, ExpiresAt: now.Add(time.Hour)}
+	s.mockService.Team.OnList = func() ([]authTypes.Team, error) {
+		return []authTypes.Team{
+			{Name: "avengers", QuotaGrants: []authTypes.QuotaGrant{expired, active}},
+			{Name: "illuminati"},
+		}, nil
+	}
+	var removed []string
+	s.mockService.Team.OnRemoveQuotaGrant = func(teamName, id string) error {
+		removed = append(removed, teamName+"/"+id)
+		return nil
+	}
+	err := expireQuotaGrants(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.DeepEquals, []string{"avengers/g1"})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: "avengers"},
+		Kind:   "quota grant expire",
+	}, eventtest.HasEvent)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+func rebalanceUnitsOptions(r *http.Request) (provision.RebalanceUnitsOptions, error) {
+	var opts provision.RebalanceUnitsOptions
+	opts.DryRun, _ = strconv.ParseBool(InputValue(r, "dry"))
+	if maxEvictions := InputValue(r, "maxEvictions"); maxEvictions != "" {
+		n, err := strconv.Atoi(maxEvictions)
+		if err != nil || n < 0 {
+			return opts, &errors.HTTP{Code: http.StatusBadRequest, Message: "maxEvictions must be a non negative integer"}
+		}
+		opts.MaxEvictions = n
+	}
+	return opts, nil
+}
+
+// title: rebalance app units
+// path: /apps/{app}/units/rebalance
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Units rebalanced
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func appRebalanceUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	opts, err := rebalanceUnitsOptions(r)
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateUnitRebalance,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateUnitRebalance,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	var evictions []provision.UnitEviction
+	defer func() { evt.DoneCustomData(ctx, err, evictions) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	opts.Writer = evt
+	evictions, err = app.RebalanceUnits(ctx, a, opts)
+	if err == app.ErrRebalanceUnitsProvisioner {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: rebalance pool units
+// path: /pools/{name}/rebalance
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Units rebalanced
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+func poolRebalanceUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolUpdateRebalance, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	opts, err := rebalanceUnitsOptions(r)
+	if err != nil {
+		return err
+	}
+	_, err = pool.GetPoolByName(ctx, poolName)
+	if err != nil {
+		if err == pool.ErrPoolNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateRebalance,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	var evictions []provision.UnitEviction
+	defer func() { evt.DoneCustomData(ctx, err, evictions) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	opts.Writer = evt
+	evictions, err = app.RebalancePoolUnits(ctx, poolName, opts)
+	return err
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createSkewedApp(c *check.C, name string) *appTypes.App {
+	a := appTypes.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 3, "web", nil, "node1", nil)
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 1, "web", nil, "node2", nil)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestAppRebalanceUnits(c *check.C) {
+	a := s.createSkewedApp(c, "myapp")
+	body := strings.NewReader("maxEvictions=2")
+	request, err := http.NewRequest("POST", "/apps/myapp/units/rebalance", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(s.provisioner.GetUnits(a)[2].IP, check.Equals, "node2")
+	c.Assert(eventtest.EventDesc{
+		Target:          appTarget("myapp"),
+		Owner:           s.token.GetUserName(),
+		Kind:            "app.update.unit.rebalance",
+		StartCustomData: []map[string]interface{}{{"name": "maxEvictions", "value": "2"}, {"name": ":app", "value": "myapp"}},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppRebalanceUnitsDryRun(c *check.C) {
+	a := s.createSkewedApp(c, "myapp")
+	body := strings.NewReader("dry=true")
+	request, err := http.NewRequest("POST", "/apps/myapp/units/rebalance", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(s.provisioner.GetUnits(a)[2].IP, check.Equals, "node1")
+}
+
+func (s *S) TestAppRebalanceUnitsInvalidMaxEvictions(c *check.C) {
+	s.createSkewedApp(c, "myapp")
+	body := strings.NewReader("maxEvictions=-1")
+	request, err := http.NewRequest("POST", "/apps/myapp/units/rebalance", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPoolRebalanceUnits(c *check.C) {
+	a := s.createSkewedApp(c, "myapp")
+	request, err := http.NewRequest("POST", "/pools/test1/rebalance", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Rebalancing units of app \\"myapp\\".*`)
+	c.Assert(s.provisioner.GetUnits(a)[2].IP, check.Equals, "node2")
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "test1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.rebalance",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPoolRebalanceUnitsPoolNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/pools/unknown/rebalance", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
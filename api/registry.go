@@ -0,0 +1,41 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/registry"
+)
+
+// title: registry usage
+// path: /registry/usage
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No usage collected yet
+//	401: Unauthorized
+func registryUsage(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	allowed := permission.Check(ctx, t, permission.PermClusterReadRegistryUsage)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	usage, err := registry.ListUsage(ctx)
+	if err != nil {
+		return err
+	}
+	if len(usage) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(usage)
+}
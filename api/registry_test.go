@@ -0,0 +1,46 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/registry"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRegistryUsage(c *check.C) {
+	err := registry.SaveUsage(context.TODO(), []registry.Usage{
+		{Kind: registry.UsageKindApp, Name: "myapp", Tags: 3, UsedBytes: 100, ReclaimableBytes: 10},
+		{Kind: registry.UsageKindJob, Name: "myjob", Tags: 2, UsedBytes: 50, ReclaimableBytes: 40},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/registry/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var usage []registry.Usage
+	err = json.Unmarshal(recorder.Body.Bytes(), &usage)
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.HasLen, 2)
+	c.Assert(usage[0].Name, check.Equals, "myjob")
+	c.Assert(usage[0].ReclaimableBytes, check.Equals, int64(40))
+	c.Assert(usage[1].Name, check.Equals, "myapp")
+}
+
+func (s *S) TestRegistryUsageNoUsage(c *check.C) {
+	request, err := http.NewRequest("GET", "/registry/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const roleExpirationInterval = time.Minute
+
+// roleExpiration parses the expiration of a temporary role assignment, nil
+// for permanent assignments.
+func roleExpiration(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid expiresAt %q, must be in RFC 3339 format", value)}
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: "expiresAt must be in the future"}
+	}
+	return &expiresAt, nil
+}
+
+type roleExpirer struct {
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func startRoleExpirer() {
+	e := &roleExpirer{
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go e.spin()
+	shutdown.Register(e)
+}
+
+func (e *roleExpirer) spin() {
+	defer close(e.doneCh)
+	for {
+		err := expireRoles(context.Background(), time.Now())
+		if err != nil {
+			log.Errorf("[role-expirer] %v", err)
+		}
+		select {
+		case <-e.stopCh:
+			return
+		case <-time.After(roleExpirationInterval):
+		}
+	}
+}
+
+func (e *roleExpirer) Shutdown(ctx context.Context) error {
+	close(e.stopCh)
+	select {
+	case <-e.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// expireRoles revokes the temporary role assignments which expired before
+// now.
+func expireRoles(ctx context.Context, now time.Time) error {
+	users, err := auth.ListUsersWithExpiredRoles(ctx, now)
+	if err != nil {
+		return err
+	}
+	multi := errors.NewMultiError()
+	for i := range users {
+		for _, role := range users[i].Roles {
+			if !role.Expired(now) {
+				continue
+			}
+			err = expireRole(ctx, &users[i], role)
+			if err != nil {
+				multi.Add(pkgErrors.Wrapf(err, "unable to expire role %q of user %q", role.Name, users[i].Email))
+			}
+		}
+	}
+	return multi.ToError()
+}
+
+// expireRole revokes the role assignment, recording an event only when it
+// was still assigned, so API instances expiring it concurrently record it
+// once.
+func expireRole(ctx context.Context, u *auth.User, role authTypes.RoleInstance) error {
+	revoked, err := u.RemoveExpiredRole(ctx, role)
+	if !revoked {
+		return err
+	}
+	evt, evtErr := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: role.Name},
+		InternalKind: "role assignment expire",
+		CustomData: map[string]interface{}{
+			"email":     u.Email,
+			"context":   role.ContextValue,
+			"expiresAt": role.ExpiresAt,
+		},
+		Allowed:     event.Allowed(permission.PermRoleReadEvents),
+		DisableLock: true,
+	})
+	if evtErr != nil {
+		return evtErr
+	}
+	evt.Done(ctx, err)
+	return err
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) assignRoleRequest(c *check.C, email, expiresAt string) *httptest.ResponseRecorder {
+	body := bytes.NewBufferString(fmt.Sprintf("email=%s&context=myteam&expiresAt=%s", email, url.QueryEscape(expiresAt)))
+	req, err := http.NewRequest(http.MethodPost, "/roles/test/user", body)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "user1", permTypes.Permission{
+		Scheme:  permission.PermRoleUpdateAssign,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppCreate,
+		Context: permission.Context(permTypes.CtxTeam, "myteam"),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (s *S) TestAssignRoleWithExpiration(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "test", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.create")
+	c.Assert(err, check.IsNil)
+	_, emptyToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "user2")
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	recorder := s.assignRoleRequest(c, emptyToken.GetUserName(), expiresAt.Format(time.RFC3339))
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	emptyUser, err := emptyToken.User(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(emptyUser.Roles, check.HasLen, 1)
+	c.Assert(emptyUser.Roles[0].Name, check.Equals, "test")
+	c.Assert(emptyUser.Roles[0].ContextValue, check.Equals, "myteam")
+	c.Assert(emptyUser.Roles[0].ExpiresAt, check.NotNil)
+	c.Assert(emptyUser.Roles[0].ExpiresAt.Equal(expiresAt), check.Equals, true)
+}
+
+func (s *S) TestAssignRoleInvalidExpiration(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "test", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.create")
+	c.Assert(err, check.IsNil)
+	_, emptyToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "user2")
+	recorder := s.assignRoleRequest(c, emptyToken.GetUserName(), "tomorrow")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid expiresAt \"tomorrow\", must be in RFC 3339 format\n")
+	recorder = s.assignRoleRequest(c, emptyToken.GetUserName(), time.Now().Add(-time.Hour).Format(time.RFC3339))
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "expiresAt must be in the future\n")
+	emptyUser, err := emptyToken.User(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(emptyUser.Roles, check.HasLen, 0)
+}
+
+func (s *S) TestExpireRoles(c *check.C) {
+	_, err := permission.NewRole(context.TODO(), "breakglass", "team", "")
+	c.Assert(err, check.IsNil)
+	u := &auth.User{Email: "oncall@tsuru.io"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	err = u.AddRoleUntil(context.TODO(), "breakglass", "prod", now.Add(-time.Minute))
+	c.Assert(err, check.IsNil)
+	err = u.AddRoleUntil(context.TODO(), "breakglass", "staging", now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	err = expireRoles(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	u, err = auth.GetUserByEmail(context.TODO(), "oncall@tsuru.io")
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Roles, check.HasLen, 1)
+	c.Assert(u.Roles[0].ContextValue, check.Equals, "staging")
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: "breakglass"},
+		Kind:   "role assignment expire",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestExpireRoleAlreadyRevoked(c *check.C) {
+	_, err := permission.NewRole(context.TODO(), "breakglass", "team", "")
+	c.Assert(err, check.IsNil)
+	u := &auth.User{Email: "oncall@tsuru.io"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	expiresAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Millisecond)
+	err = u.AddRoleUntil(context.TODO(), "breakglass", "prod", expiresAt)
+	c.Assert(err, check.IsNil)
+	role := u.Roles[0]
+	stale := *u
+	err = expireRole(context.TODO(), u, role)
+	c.Assert(err, check.IsNil)
+	err = expireRole(context.TODO(), &stale, role)
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: "breakglass"},
+		KindNames: []string{"role assignment expire"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+}
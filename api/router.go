@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	stderrors "errors"
 	"net/http"
+	"strconv"
 
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
@@ -267,6 +268,10 @@ func addAppRouter(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 		}
 		return err
 	}
+	err = validateAppRouterOpts(appRouter.Opts)
+	if err != nil {
+		return err
+	}
 
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     appTarget(appName),
@@ -283,6 +288,16 @@ func addAppRouter(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 	return app.AddRouter(ctx, a, appRouter)
 }
 
+func validateAppRouterOpts(opts map[string]string) error {
+	if _, err := router.TLSPolicyFromOpts(opts); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if _, err := router.AccessLogPolicyFromOpts(opts); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return nil
+}
+
 // title: update app router
 // path: /app/{app}/routers/{name}
 // method: PUT
@@ -331,6 +346,10 @@ func updateAppRouter(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 		}
 		return err
 	}
+	err = validateAppRouterOpts(appRouter.Opts)
+	if err != nil {
+		return err
+	}
 
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     appTarget(appName),
@@ -388,6 +407,43 @@ func removeAppRouter(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	return err
 }
 
+// title: add app access logs
+// path: /apps/{app}/routers/{router}/access-logs
+// method: POST
+// consume: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App or router not found
+func addAppAccessLogs(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateLog,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var entries []app.AccessLogEntry
+	err = ParseJSON(r, &entries)
+	if err != nil {
+		return err
+	}
+	err = app.AddAccessLogs(ctx, a, r.URL.Query().Get(":router"), entries)
+	if _, isNotFound := err.(*router.ErrRouterNotFound); isNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err == app.ErrAccessLogDisabled {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
 // title: list app routers
 // path: /app/{app}/routers
 // method: GET
@@ -474,3 +530,143 @@ func appSetRoutable(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 	}
 	return app.SetRoutable(ctx, a, version, args.IsRoutable)
 }
+
+type routingRuleRequest struct {
+	Version string `json:"version"`
+	Header  string `json:"header"`
+	Cookie  string `json:"cookie"`
+	Value   string `json:"value"`
+}
+
+// title: list app routing rules
+// path: /apps/{app}/routable/targets
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Not authorized
+//	404: App not found
+func appRoutingRules(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadRouter,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	rules := a.RoutingRules
+	if rules == nil {
+		rules = []appTypes.VersionRoutingRule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(rules)
+}
+
+// title: target requests to an app version
+// path: /apps/{app}/routable/targets
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: OK
+//	400: Bad request
+//	401: Not authorized
+//	404: App not found
+func appSetRoutingRule(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var args routingRuleRequest
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateRoutable,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRoutable,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, a, args.Version)
+	if err != nil {
+		if appTypes.IsInvalidVersionError(err) {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	err = app.SetRoutingRule(ctx, a, appTypes.VersionRoutingRule{
+		Version: version.Version(),
+		Header:  args.Header,
+		Cookie:  args.Cookie,
+		Value:   args.Value,
+	}, evt)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: stop targeting requests to an app version
+// path: /apps/{app}/routable/targets/{version}
+// method: DELETE
+// responses:
+//
+//	200: OK
+//	400: Bad request
+//	401: Not authorized
+//	404: App or rule not found
+func appRemoveRoutingRule(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	version, err := strconv.Atoi(r.URL.Query().Get(":version"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid version"}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateRoutable,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRoutable,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.RemoveRoutingRule(ctx, a, version, evt)
+	if err == app.ErrRoutingRuleNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	routerTypes "github.com/tsuru/tsuru/types/router"
@@ -436,6 +437,31 @@ func (s *S) TestUpdateAppRouter(c *check.C) {
 	})
 }
 
+func (s *S) TestUpdateAppRouterInvalidTLSPolicy(c *check.C) {
+	ctx := context.Background()
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateRouterUpdate,
+		Context: permission.Context(permTypes.CtxTeam, "tsuruteam"),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "none"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.AddRouter(ctx, &myapp, appTypes.AppRouter{Name: "fake"})
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`opts.tls-min-version=1.4`)
+	request, err := http.NewRequest("PUT", "/1.5/apps/myapp/routers/fake", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid tls-min-version \"1.4\", valid versions are: 1.0, 1.1, 1.2, 1.3\n")
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(app.GetRouters(dbApp), check.DeepEquals, []appTypes.AppRouter{{Name: "fake"}})
+}
+
 func (s *S) TestUpdateAppRouterNotFound(c *check.C) {
 	token := userWithPermission(c, permTypes.Permission{
 		Scheme:  permission.PermAppUpdateRouterUpdate,
@@ -477,6 +503,53 @@ func (s *S) TestUpdateAppRouterBlockedByConstraint(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %q", recorder.Body.String()))
 }
 
+func (s *S) TestAddAppAccessLogs(c *check.C) {
+	ctx := context.Background()
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateLog,
+		Context: permission.Context(permTypes.CtxTeam, "tsuruteam"),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "none"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.AddRouter(ctx, &myapp, appTypes.AppRouter{Name: "fake", Opts: map[string]string{"access-log": "sampled"}})
+	c.Assert(err, check.IsNil)
+	body := `[{"time": "2026-01-02T03:04:05Z", "method": "GET", "host": "myapp.io", "path": "/", "status": 200, "latencyMs": 2, "bytes": 10}]`
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/routers/fake/access-logs", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	logs, err := servicemanager.LogService.List(ctx, appTypes.ListLogArgs{Name: "myapp", Source: router.AccessLogSource, Limit: 10})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, "GET myapp.io/ 200 2.000ms 10B")
+	c.Assert(logs[0].Unit, check.Equals, "fake")
+}
+
+func (s *S) TestAddAppAccessLogsDisabled(c *check.C) {
+	ctx := context.Background()
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateLog,
+		Context: permission.Context(permTypes.CtxTeam, "tsuruteam"),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "none"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.AddRouter(ctx, &myapp, appTypes.AppRouter{Name: "fake"})
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/routers/fake/access-logs", strings.NewReader(`[{"method": "GET"}]`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrAccessLogDisabled.Error()+"\n")
+}
+
 func (s *S) TestRemoveAppRouter(c *check.C) {
 	ctx := context.Background()
 	token := userWithPermission(c, permTypes.Permission{
@@ -514,3 +587,54 @@ func (s *S) TestRemoveAppRouterNotFound(c *check.C) {
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 }
+
+func (s *S) TestAppRoutingRules(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &myapp)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/routable/targets", strings.NewReader("version=1&header=X-Canary&value=yes"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.25/apps/myapp/routable/targets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var rules []appTypes.VersionRoutingRule
+	err = json.Unmarshal(recorder.Body.Bytes(), &rules)
+	c.Assert(err, check.IsNil)
+	c.Assert(rules, check.DeepEquals, []appTypes.VersionRoutingRule{{Version: 1, Header: "X-Canary", Value: "yes"}})
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/routable/targets/1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("DELETE", "/1.25/apps/myapp/routable/targets/1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppSetRoutingRuleInvalid(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &myapp)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.25/apps/myapp/routable/targets", strings.NewReader("version=1&header=X-Canary&cookie=beta"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "routing rule must match either a header or a cookie\n")
+}
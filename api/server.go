@@ -43,6 +43,7 @@ import (
 	_ "github.com/tsuru/tsuru/auth/oauth"
 	_ "github.com/tsuru/tsuru/auth/oidc"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/audit"
 	"github.com/tsuru/tsuru/event/webhook"
 	"github.com/tsuru/tsuru/hc"
 	"github.com/tsuru/tsuru/job"
@@ -242,6 +243,7 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPut, "/services/{service}/instances/{instance}", AuthorizationRequiredHandler(updateServiceInstance))
 	m.Add("1.0", http.MethodDelete, "/services/{service}/instances/{instance}", AuthorizationRequiredHandler(removeServiceInstance))
 	m.Add("1.0", http.MethodGet, "/services/{service}/instances/{instance}/status", AuthorizationRequiredHandler(serviceInstanceStatus))
+	m.Add("1.25", http.MethodGet, "/services/{service}/instances/{instance}/dependents", AuthorizationRequiredHandler(serviceInstanceDependents))
 	m.Add("1.0", http.MethodPut, "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(bindServiceInstance))
 	m.Add("1.0", http.MethodDelete, "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(unbindServiceInstance))
 	m.Add("1.13", http.MethodPut, "/services/{service}/instances/{instance}/apps/{app}", AuthorizationRequiredHandler(bindServiceInstance))
@@ -269,29 +271,72 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodDelete, "/services/{service}/team/{team}", AuthorizationRequiredHandler(revokeServiceAccess))
 
 	m.Add("1.0", http.MethodGet, "/apps", AuthorizationRequiredHandler(appList))
+	m.Add("1.25", http.MethodGet, "/discovery/apps", AuthorizationRequiredHandler(appDiscovery))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/identity/token", Handler(appIdentityToken))
+	m.Add("1.25", http.MethodPost, "/identity/verify", Handler(verifyIdentityToken))
+	m.Add("1.25", http.MethodGet, "/identity/jwks", Handler(identityJWKS))
 	m.Add("1.0", http.MethodPost, "/apps", AuthorizationRequiredHandler(createApp))
 	m.Add("1.0", http.MethodGet, "/apps/{app}", AuthorizationRequiredHandler(appInfo))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}", AuthorizationRequiredHandler(appDelete))
 	m.Add("1.0", http.MethodPut, "/apps/{app}", AuthorizationRequiredHandler(updateApp))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deletion-protection", AuthorizationRequiredHandler(appDeletionProtectionEnable))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/deletion-protection", AuthorizationRequiredHandler(appDeletionProtectionDisable))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/autoredeploy", AuthorizationRequiredHandler(appAutoRedeployEnable))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/autoredeploy", AuthorizationRequiredHandler(appAutoRedeployDisable))
+	m.Add("1.25", http.MethodGet, "/autoredeploys", AuthorizationRequiredHandler(listPendingRebuilds))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/require-approval", AuthorizationRequiredHandler(appRequireApprovalEnable))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/require-approval", AuthorizationRequiredHandler(appRequireApprovalDisable))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/archive", AuthorizationRequiredHandler(archiveApp))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/unarchive", AuthorizationRequiredHandler(unarchiveApp))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/maintenance", AuthorizationRequiredHandler(appMaintenanceEnable))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/maintenance", AuthorizationRequiredHandler(appMaintenanceDisable))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/log-sinks", AuthorizationRequiredHandler(appLogSinks))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/log-sinks", AuthorizationRequiredHandler(appSetLogSink))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/log-sinks/{name}", AuthorizationRequiredHandler(appUnsetLogSink))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/dependencies", AuthorizationRequiredHandler(appDependencies))
+	m.Add("1.25", http.MethodPut, "/apps/{app}/service-account", AuthorizationRequiredHandler(setAppServiceAccount))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/cname", AuthorizationRequiredHandler(setCName))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/cname", AuthorizationRequiredHandler(unsetCName))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/run", AuthorizationRequiredHandler(runCommand))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/tasks", AuthorizationRequiredHandler(runTask))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/restart", AuthorizationRequiredHandler(restart))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/start", AuthorizationRequiredHandler(start))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/stop", AuthorizationRequiredHandler(stop))
 	m.Add("1.10", http.MethodDelete, "/apps/{app}/versions/{version}", AuthorizationRequiredHandler(appVersionDelete))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/quota", AuthorizationRequiredHandler(getAppQuota))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/quota", AuthorizationRequiredHandler(changeAppQuota))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/budget", AuthorizationRequiredHandler(getAppBudget))
+	m.Add("1.25", http.MethodPut, "/apps/{app}/budget", AuthorizationRequiredHandler(setAppBudget))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/env", AuthorizationRequiredHandler(getAppEnv))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/env", AuthorizationRequiredHandler(setAppEnv))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/env", AuthorizationRequiredHandler(unsetAppEnv))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/discovery", AuthorizationRequiredHandler(setAppDiscoveryEnvs))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/env/promote", AuthorizationRequiredHandler(promoteAppEnv))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/env/export", AuthorizationRequiredHandler(exportAppEnv))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/env/import", AuthorizationRequiredHandler(importAppEnv))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/env/schema", AuthorizationRequiredHandler(getAppEnvSchema))
+	m.Add("1.25", http.MethodPut, "/apps/{app}/env/schema", AuthorizationRequiredHandler(setAppEnvSchema))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/files", AuthorizationRequiredHandler(listAppFiles))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/files", AuthorizationRequiredHandler(setAppFile))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/files", AuthorizationRequiredHandler(unsetAppFile))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/secrets", AuthorizationRequiredHandler(listAppSecrets))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/secrets/{name}", AuthorizationRequiredHandler(revealAppSecret))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/secrets", AuthorizationRequiredHandler(setAppSecrets))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/secrets", AuthorizationRequiredHandler(unsetAppSecrets))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/config/snapshots", AuthorizationRequiredHandler(appConfigSnapshotList))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/config/restore", AuthorizationRequiredHandler(appConfigRestore))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/lock", AuthorizationRequiredHandler(forceDeleteLock))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/units", AuthorizationRequiredHandler(addUnits))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/units", AuthorizationRequiredHandler(removeUnits))
 	m.Add("1.9", http.MethodGet, "/apps/{app}/units/autoscale", AuthorizationRequiredHandler(autoScaleUnitsInfo))
 	m.Add("1.9", http.MethodPost, "/apps/{app}/units/autoscale", AuthorizationRequiredHandler(addAutoScaleUnits))
 	m.Add("1.9", http.MethodDelete, "/apps/{app}/units/autoscale", AuthorizationRequiredHandler(removeAutoScaleUnits))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/units/history", AuthorizationRequiredHandler(unitsHistory))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/units/rebalance", AuthorizationRequiredHandler(appRebalanceUnits))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/health", AuthorizationRequiredHandler(appHealth))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/metrics", AuthorizationRequiredHandler(appUnitsMetrics))
 	m.Add("1.12", http.MethodDelete, "/apps/{app}/units/{unit}", AuthorizationRequiredHandler(killUnit))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/units/{unit}/capture", AuthorizationRequiredHandler(captureUnit))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/teams/{team}", AuthorizationRequiredHandler(grantAppAccess))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/teams/{team}", AuthorizationRequiredHandler(revokeAppAccess))
 	m.AddNamed("log-get", "1.0", http.MethodGet, "/apps/{app}/log", AuthorizationRequiredHandler(appLog))
@@ -300,6 +345,17 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/apps/{app}/deploy/rollback", AuthorizationRequiredHandler(deployRollback))
 	m.Add("1.4", http.MethodPut, "/apps/{app}/deploy/rollback/update", AuthorizationRequiredHandler(deployRollbackUpdate))
 	m.Add("1.3", http.MethodPost, "/apps/{app}/deploy/rebuild", AuthorizationRequiredHandler(deployRebuild))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploy/pause", AuthorizationRequiredHandler(deployPause))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploy/resume", AuthorizationRequiredHandler(deployResume))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploy/confirm", AuthorizationRequiredHandler(deployConfirm))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploy/abort", AuthorizationRequiredHandler(deployAbort))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/deploy/status", AuthorizationRequiredHandler(deployRolloutStatus))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploys/{id}/approve", AuthorizationRequiredHandler(deployApprove))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploy/uploads", AuthorizationRequiredHandler(startDeployUpload))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/deploy/uploads/{id}", AuthorizationRequiredHandler(deployUploadInfo))
+	m.Add("1.25", http.MethodPut, "/apps/{app}/deploy/uploads/{id}", AuthorizationRequiredHandler(addDeployUploadChunk))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/deploy/uploads/{id}", AuthorizationRequiredHandler(finishDeployUpload))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/deploy/uploads/{id}", AuthorizationRequiredHandler(removeDeployUpload))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/routes", AuthorizationRequiredHandler(appRebuildRoutes))
 
 	m.Add("1.2", http.MethodGet, "/apps/{app}/certificate", AuthorizationRequiredHandler(listCertificatesLegacy))
@@ -314,21 +370,31 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.5", http.MethodPut, "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(updateAppRouter))
 	m.Add("1.5", http.MethodDelete, "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(removeAppRouter))
 	m.Add("1.5", http.MethodGet, "/apps/{app}/routers", AuthorizationRequiredHandler(listAppRouters))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/routers/{router}/access-logs", AuthorizationRequiredHandler(addAppAccessLogs))
 	m.Add("1.8", http.MethodPost, "/apps/{app}/routable", AuthorizationRequiredHandler(appSetRoutable))
+	m.Add("1.25", http.MethodGet, "/apps/{app}/routable/targets", AuthorizationRequiredHandler(appRoutingRules))
+	m.Add("1.25", http.MethodPost, "/apps/{app}/routable/targets", AuthorizationRequiredHandler(appSetRoutingRule))
+	m.Add("1.25", http.MethodDelete, "/apps/{app}/routable/targets/{version}", AuthorizationRequiredHandler(appRemoveRoutingRule))
 	m.Add("1.0", http.MethodGet, "/deploys", AuthorizationRequiredHandler(deploysList))
 	m.Add("1.0", http.MethodGet, "/deploys/{deploy}", AuthorizationRequiredHandler(deployInfo))
+	m.Add("1.25", http.MethodGet, "/deploys/{deploy}/manifest-diff", AuthorizationRequiredHandler(deployManifestDiff))
+	m.Add("1.25", http.MethodPost, "/deploys/{deploy}/continue", AuthorizationRequiredHandler(deployContinue))
 
 	m.Add("1.1", http.MethodGet, "/events", AuthorizationRequiredHandler(eventList))
 	m.Add("1.3", http.MethodGet, "/events/blocks", AuthorizationRequiredHandler(eventBlockList))
 	m.Add("1.3", http.MethodPost, "/events/blocks", AuthorizationRequiredHandler(eventBlockAdd))
 	m.Add("1.3", http.MethodDelete, "/events/blocks/{uuid}", AuthorizationRequiredHandler(eventBlockRemove))
 	m.Add("1.1", http.MethodGet, "/events/kinds", AuthorizationRequiredHandler(kindList))
+	m.Add("1.25", http.MethodGet, "/events/stream", AuthorizationRequiredHandler(eventStream))
 	m.Add("1.1", http.MethodGet, "/events/{uuid}", AuthorizationRequiredHandler(eventInfo))
 	m.Add("1.1", http.MethodPost, "/events/{uuid}/cancel", AuthorizationRequiredHandler(eventCancel))
+	m.Add("1.25", http.MethodPost, "/events/{uuid}/annotations", AuthorizationRequiredHandler(eventAnnotate))
+	m.Add("1.25", http.MethodGet, "/events/{uuid}/shell-recording", AuthorizationRequiredHandler(shellRecordingHandler))
 
 	m.Add("1.6", http.MethodGet, "/events/webhooks", AuthorizationRequiredHandler(webhookList))
 	m.Add("1.6", http.MethodPost, "/events/webhooks", AuthorizationRequiredHandler(webhookCreate))
 	m.Add("1.6", http.MethodGet, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookInfo))
+	m.Add("1.25", http.MethodGet, "/events/webhooks/{name}/deliveries", AuthorizationRequiredHandler(webhookDeliveries))
 	m.Add("1.6", http.MethodPut, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookUpdate))
 	m.Add("1.6", http.MethodDelete, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookDelete))
 
@@ -338,6 +404,7 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodDelete, "/platforms/{name}", AuthorizationRequiredHandler(platformRemove))
 	m.Add("1.6", http.MethodGet, "/platforms/{name}", AuthorizationRequiredHandler(platformInfo))
 	m.Add("1.6", http.MethodPost, "/platforms/{name}/rollback", AuthorizationRequiredHandler(platformRollback))
+	m.Add("1.25", http.MethodPost, "/platforms/{name}/autoredeploy", AuthorizationRequiredHandler(platformAutoRedeploy))
 
 	// These handlers don't use {app} on purpose. Using :app means that only
 	// the token generate for the given app is valid, but these handlers
@@ -350,6 +417,8 @@ func RunServer(dry bool) http.Handler {
 	// Shell also doesn't use {app} on purpose. Middlewares don't play well
 	// with websocket.
 	m.Add("1.0", http.MethodGet, "/apps/{appname}/shell", http.HandlerFunc(remoteShellHandler))
+	m.Add("1.25", http.MethodGet, "/apps/{appname}/units/{unit}/port-forward", http.HandlerFunc(portForwardHandler))
+	m.Add("1.25", http.MethodGet, "/apps/{appname}/deploys/{deploy}/stream", http.HandlerFunc(deployStreamHandler))
 
 	m.Add("1.0", http.MethodGet, "/users", AuthorizationRequiredHandler(listUsers))
 	m.Add("1.0", http.MethodPost, "/users", Handler(createUser))
@@ -357,12 +426,17 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/auth/scheme", Handler(authScheme))
 	m.Add("1.18", http.MethodGet, "/auth/schemes", Handler(authSchemes))
 	m.Add("1.0", http.MethodPost, "/auth/login", Handler(login))
+	m.Add("1.25", http.MethodPost, "/auth/refresh", Handler(refreshToken))
 
 	m.Add("1.0", http.MethodPost, "/users/{email}/password", Handler(resetPassword))
 	m.Add("1.0", http.MethodPost, "/users/{email}/tokens", Handler(login))
 	m.Add("1.0", http.MethodGet, "/users/{email}/quota", AuthorizationRequiredHandler(getUserQuota))
+	m.Add("1.25", http.MethodGet, "/users/{email}/permissions/explain", AuthorizationRequiredHandler(explainUserPermission))
 	m.Add("1.0", http.MethodPut, "/users/{email}/quota", AuthorizationRequiredHandler(changeUserQuota))
 	m.Add("1.0", http.MethodDelete, "/users/tokens", AuthorizationRequiredHandler(logout))
+	m.Add("1.25", http.MethodGet, "/users/tokens", AuthorizationRequiredHandler(userTokenList))
+	m.Add("1.25", http.MethodPost, "/users/tokens", AuthorizationRequiredHandler(userTokenCreate))
+	m.Add("1.25", http.MethodDelete, "/users/tokens/{token_id}", AuthorizationRequiredHandler(userTokenDelete))
 	m.Add("1.0", http.MethodPut, "/users/password", AuthorizationRequiredHandler(changePassword))
 	m.Add("1.0", http.MethodDelete, "/users", AuthorizationRequiredHandler(removeUser))
 	m.Add("1.0", http.MethodGet, "/users/api-key", AuthorizationRequiredHandler(showAPIToken))
@@ -377,8 +451,27 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.4", http.MethodGet, "/teams/{name}", AuthorizationRequiredHandler(teamInfo))
 	m.Add("1.12", http.MethodGet, "/teams/{name}/quota", AuthorizationRequiredHandler(getTeamQuota))
 	m.Add("1.12", http.MethodPut, "/teams/{name}/quota", AuthorizationRequiredHandler(changeTeamQuota))
+	m.Add("1.25", http.MethodGet, "/teams/{name}/job-quota", AuthorizationRequiredHandler(getTeamJobQuota))
+	m.Add("1.25", http.MethodPut, "/teams/{name}/job-quota", AuthorizationRequiredHandler(changeTeamJobQuota))
+	m.Add("1.25", http.MethodGet, "/pools/{name}/job-quota", AuthorizationRequiredHandler(getPoolJobQuota))
+	m.Add("1.25", http.MethodPut, "/pools/{name}/job-quota", AuthorizationRequiredHandler(changePoolJobQuota))
+	m.Add("1.25", http.MethodGet, "/teams/{name}/quota/requests", AuthorizationRequiredHandler(listTeamQuotaRequests))
+	m.Add("1.25", http.MethodPost, "/teams/{name}/quota/requests", AuthorizationRequiredHandler(requestTeamQuotaIncrease))
+	m.Add("1.25", http.MethodPost, "/teams/{name}/quota/requests/{id}/approve", AuthorizationRequiredHandler(approveTeamQuotaRequest))
+	m.Add("1.25", http.MethodPost, "/teams/{name}/quota/requests/{id}/reject", AuthorizationRequiredHandler(rejectTeamQuotaRequest))
+	m.Add("1.25", http.MethodGet, "/teams/{name}/env", AuthorizationRequiredHandler(getTeamEnv))
+	m.Add("1.25", http.MethodPost, "/teams/{name}/env", AuthorizationRequiredHandler(setTeamEnv))
+	m.Add("1.25", http.MethodDelete, "/teams/{name}/env", AuthorizationRequiredHandler(unsetTeamEnv))
+	m.Add("1.25", http.MethodGet, "/teams/{name}/notifications", AuthorizationRequiredHandler(getTeamNotifications))
+	m.Add("1.25", http.MethodPut, "/teams/{name}/notifications", AuthorizationRequiredHandler(setTeamNotifications))
+	m.Add("1.25", http.MethodGet, "/teams/{name}/budget", AuthorizationRequiredHandler(getTeamBudget))
+	m.Add("1.25", http.MethodPut, "/teams/{name}/budget", AuthorizationRequiredHandler(setTeamBudget))
+	m.Add("1.25", http.MethodPut, "/teams/{name}/isolation-profile", AuthorizationRequiredHandler(setTeamIsolationProfile))
+	m.Add("1.25", http.MethodGet, "/isolation-profiles", AuthorizationRequiredHandler(isolationProfileList))
 	m.Add("1.17", http.MethodGet, "/teams/{name}/users", AuthorizationRequiredHandler(teamUserList))
 	m.Add("1.17", http.MethodGet, "/teams/{name}/groups", AuthorizationRequiredHandler(teamGroupList))
+	m.Add("1.25", http.MethodPost, "/teams/{name}/groups", AuthorizationRequiredHandler(addTeamGroup))
+	m.Add("1.25", http.MethodDelete, "/teams/{name}/groups/{group}", AuthorizationRequiredHandler(removeTeamGroup))
 
 	m.Add("1.0", http.MethodPost, "/swap", AuthorizationRequiredHandler(swap))
 
@@ -388,6 +481,10 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/plans", AuthorizationRequiredHandler(listPlans))
 	m.Add("1.0", http.MethodPost, "/plans", AuthorizationRequiredHandler(addPlan))
 	m.Add("1.0", http.MethodDelete, "/plans/{planname}", AuthorizationRequiredHandler(removePlan))
+	m.Add("1.25", http.MethodGet, "/plans/defaults", AuthorizationRequiredHandler(listPlanDefaults))
+	m.Add("1.25", http.MethodGet, "/plans/{planname}/usage", AuthorizationRequiredHandler(planUsage))
+	m.Add("1.25", http.MethodPut, "/plans/{planname}/default", AuthorizationRequiredHandler(setPlanDefault))
+	m.Add("1.25", http.MethodDelete, "/plans/{planname}/default", AuthorizationRequiredHandler(unsetPlanDefault))
 
 	m.Add("1.0", http.MethodGet, "/pools", AuthorizationRequiredHandler(poolList))
 	m.Add("1.0", http.MethodPost, "/pools", AuthorizationRequiredHandler(addPoolHandler))
@@ -396,15 +493,28 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/pools/{name}/team", AuthorizationRequiredHandler(addTeamToPoolHandler))
 	m.Add("1.0", http.MethodDelete, "/pools/{name}/team", AuthorizationRequiredHandler(removeTeamToPoolHandler))
 	m.Add("1.8", http.MethodGet, "/pools/{name}", AuthorizationRequiredHandler(getPoolHandler))
+	m.Add("1.25", http.MethodPost, "/pools/{name}/rebalance", AuthorizationRequiredHandler(poolRebalanceUnits))
+	m.Add("1.25", http.MethodGet, "/pools/{name}/drift", AuthorizationRequiredHandler(poolDrift))
+	m.Add("1.25", http.MethodGet, "/pools/{name}/labels", AuthorizationRequiredHandler(poolLabelsHandler))
+	m.Add("1.25", http.MethodPost, "/pools/{name}/labels", AuthorizationRequiredHandler(setPoolLabelsHandler))
+	m.Add("1.25", http.MethodDelete, "/pools/{name}/labels", AuthorizationRequiredHandler(unsetPoolLabelsHandler))
 
 	m.Add("1.3", http.MethodGet, "/constraints", AuthorizationRequiredHandler(poolConstraintList))
 	m.Add("1.3", http.MethodPut, "/constraints", AuthorizationRequiredHandler(poolConstraintSet))
 
+	m.Add("1.25", http.MethodGet, "/admission-policies", AuthorizationRequiredHandler(listAdmissionPolicies))
+	m.Add("1.25", http.MethodPut, "/admission-policies/{name}", AuthorizationRequiredHandler(setAdmissionPolicy))
+	m.Add("1.25", http.MethodDelete, "/admission-policies/{name}", AuthorizationRequiredHandler(removeAdmissionPolicy))
+
 	m.Add("1.0", http.MethodGet, "/roles", AuthorizationRequiredHandler(listRoles))
 	m.Add("1.4", http.MethodPut, "/roles", AuthorizationRequiredHandler(roleUpdate))
 	m.Add("1.0", http.MethodPost, "/roles", AuthorizationRequiredHandler(addRole))
+	m.Add("1.25", http.MethodGet, "/roles/templates", AuthorizationRequiredHandler(listRoleTemplates))
+	m.Add("1.25", http.MethodPost, "/roles/templates/{template}", AuthorizationRequiredHandler(addRoleFromTemplate))
 	m.Add("1.0", http.MethodGet, "/roles/{name}", AuthorizationRequiredHandler(roleInfo))
 	m.Add("1.0", http.MethodDelete, "/roles/{name}", AuthorizationRequiredHandler(removeRole))
+	m.Add("1.25", http.MethodPost, "/roles/{name}/clone", AuthorizationRequiredHandler(cloneRole))
+	m.Add("1.25", http.MethodGet, "/roles/{name}/affected-resources", AuthorizationRequiredHandler(roleAffectedResourcesHandler))
 	m.Add("1.0", http.MethodPost, "/roles/{name}/permissions", AuthorizationRequiredHandler(addPermissions))
 	m.Add("1.0", http.MethodDelete, "/roles/{name}/permissions/{permission}", AuthorizationRequiredHandler(removePermissions))
 	m.Add("1.0", http.MethodPost, "/roles/{name}/user", AuthorizationRequiredHandler(assignRole))
@@ -438,11 +548,25 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.2", http.MethodGet, "/metrics", promhttp.Handler())
 
 	m.Add("1.7", http.MethodGet, "/provisioner", AuthorizationRequiredHandler(provisionerList))
+	m.Add("1.25", http.MethodGet, "/provisioner/orphans", AuthorizationRequiredHandler(orphanResources))
+	m.Add("1.25", http.MethodGet, "/registry/usage", AuthorizationRequiredHandler(registryUsage))
+	m.Add("1.25", http.MethodGet, "/reports/usage", AuthorizationRequiredHandler(usageReport))
 	m.Add("1.3", http.MethodPost, "/provisioner/clusters", AuthorizationRequiredHandler(createCluster))
 	m.Add("1.4", http.MethodPost, "/provisioner/clusters/{name}", AuthorizationRequiredHandler(updateCluster))
 	m.Add("1.3", http.MethodGet, "/provisioner/clusters", AuthorizationRequiredHandler(listClusters))
 	m.Add("1.8", http.MethodGet, "/provisioner/clusters/{name}", AuthorizationRequiredHandler(clusterInfo))
 	m.Add("1.3", http.MethodDelete, "/provisioner/clusters/{name}", AuthorizationRequiredHandler(deleteCluster))
+	m.Add("1.25", http.MethodGet, "/provisioner/clusters/{name}/features", AuthorizationRequiredHandler(clusterFeatureList))
+	m.Add("1.25", http.MethodPost, "/provisioner/clusters/{name}/features/{feature}", AuthorizationRequiredHandler(clusterFeatureSet))
+	m.Add("1.25", http.MethodGet, "/provisioner/clusters/{name}/resource-quotas", AuthorizationRequiredHandler(clusterResourceQuotaList))
+	m.Add("1.25", http.MethodGet, "/provisioner/clusters/{name}/capacity", AuthorizationRequiredHandler(clusterCapacity))
+	m.Add("1.25", http.MethodGet, "/provisioner/clusters/{name}/nodes", AuthorizationRequiredHandler(listClusterNodes))
+	m.Add("1.25", http.MethodPost, "/provisioner/clusters/{name}/nodes/{node}/cordon", AuthorizationRequiredHandler(cordonClusterNode))
+	m.Add("1.25", http.MethodPost, "/provisioner/clusters/{name}/nodes/{node}/uncordon", AuthorizationRequiredHandler(uncordonClusterNode))
+	m.Add("1.25", http.MethodPost, "/provisioner/clusters/{name}/nodes/{node}/drain", AuthorizationRequiredHandler(drainClusterNode))
+	m.Add("1.25", http.MethodPost, "/provisioner/clusters/{name}/nodes/{node}/annotations", AuthorizationRequiredHandler(annotateClusterNode))
+	m.Add("1.25", http.MethodPost, "/provisioner/clusters/{name}/labels", AuthorizationRequiredHandler(setClusterLabels))
+	m.Add("1.25", http.MethodDelete, "/provisioner/clusters/{name}/labels", AuthorizationRequiredHandler(unsetClusterLabels))
 
 	m.Add("1.4", http.MethodGet, "/volumes", AuthorizationRequiredHandler(volumesList))
 	m.Add("1.4", http.MethodPost, "/volumes", AuthorizationRequiredHandler(volumeCreate))
@@ -458,6 +582,7 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.6", http.MethodPost, "/tokens", AuthorizationRequiredHandler(tokenCreate))
 	m.Add("1.6", http.MethodDelete, "/tokens/{token_id}", AuthorizationRequiredHandler(tokenDelete))
 	m.Add("1.6", http.MethodPut, "/tokens/{token_id}", AuthorizationRequiredHandler(tokenUpdate))
+	m.Add("1.25", http.MethodPost, "/tokens/{token_id}/rotate", AuthorizationRequiredHandler(tokenRotate))
 
 	m.Add("1.7", http.MethodGet, "/brokers", AuthorizationRequiredHandler(serviceBrokerList))
 	m.Add("1.7", http.MethodPost, "/brokers", AuthorizationRequiredHandler(serviceBrokerAdd))
@@ -479,6 +604,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.13", http.MethodPost, "/jobs/{name}/env", AuthorizationRequiredHandler(setJobEnv))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/env", AuthorizationRequiredHandler(unsetJobEnv))
 	m.Add("1.13", http.MethodGet, "/jobs/{name}/log", AuthorizationRequiredHandler(jobLog))
+	m.Add("1.25", http.MethodGet, "/jobs/{name}/executions", AuthorizationRequiredHandler(jobExecutions))
+	m.Add("1.25", http.MethodGet, "/jobs/{name}/executions/{id}/logs", AuthorizationRequiredHandler(jobExecutionLogs))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/units/{unit}", AuthorizationRequiredHandler(killJob))
 	m.Add("1.23", http.MethodPost, "/jobs/{name}/deploy", AuthorizationRequiredHandler(jobDeploy))
 
@@ -564,6 +691,7 @@ func startServer(handler http.Handler) error {
 	defer srvConf.shutdown(srvConf.shutdownTimeout)
 
 	shutdown.Register(&logTracker)
+	shutdown.Register(&eventStreams)
 	routers, err := router.List(ctx)
 	if err != nil {
 		return err
@@ -597,6 +725,33 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize old image gc")
 	}
+	err = app.StartReconciler()
+	if err != nil {
+		return errors.Wrap(err, "unable to start app reconciler")
+	}
+	err = app.StartJanitor()
+	if err != nil {
+		return errors.Wrap(err, "unable to start janitor")
+	}
+	err = app.StartAutoRedeployer()
+	if err != nil {
+		return errors.Wrap(err, "unable to start autoredeployer")
+	}
+	err = app.StartMetering()
+	if err != nil {
+		return errors.Wrap(err, "unable to start metering collector")
+	}
+	err = audit.StartExporter()
+	if err != nil {
+		return errors.Wrap(err, "unable to start audit exporter")
+	}
+	startQuotaGrantExpirer()
+	startRoleExpirer()
+	startApprovalJanitor()
+	err = startGroupSyncer()
+	if err != nil {
+		return errors.Wrap(err, "unable to start group syncer")
+	}
 	fmt.Println("Checking components status:")
 	results := hc.Check(ctx, "all")
 	for _, result := range results {
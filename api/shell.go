@@ -18,11 +18,15 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	terminal "golang.org/x/term"
 )
 
@@ -195,6 +199,23 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 		evt.Done(ctx, finalErr)
 	}()
 	term = terminal.NewTerminal(buf, "")
+	recording, err := shellRecordingForApp(ctx, evt, a, width, height)
+	if err != nil {
+		httpErr = &errors.HTTP{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+		return
+	}
+	var base io.ReadWriteCloser = &wsReadWriteCloser{ws}
+	if recording != nil {
+		defer func() {
+			if closeErr := recording.Close(); closeErr != nil {
+				log.Errorf("unable to store recording of shell session %s: %v", evt.UniqueID.Hex(), closeErr)
+			}
+		}()
+		base = &recordingReadWriteCloser{base: base, recording: recording}
+	}
 	ws.SetReadDeadline(time.Now().Add(pongWait))
 	ws.SetPongHandler(func(string) error {
 		ws.SetReadDeadline(time.Now().Add(pongWait))
@@ -212,7 +233,7 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 			ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(2*time.Second))
 		}
 	}()
-	conn := &cmdLogger{base: &wsReadWriteCloser{ws}, term: term}
+	conn := &cmdLogger{base: base, term: term}
 	opts := provision.ExecOptions{
 		Stdout: conn,
 		Stderr: conn,
@@ -224,6 +245,9 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 		Debug:  debug,
 	}
 	err = app.Shell(ctx, a, opts)
+	if recording != nil && recording.Err() != nil {
+		err = recording.Err()
+	}
 	if err != nil {
 		httpErr = &errors.HTTP{
 			Code:    http.StatusInternalServerError,
@@ -232,6 +256,65 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// shellRecordingForApp starts recording the shell session when the pool of
+// the app requires it, flagging the event of the session as recorded.
+func shellRecordingForApp(ctx stdContext.Context, evt *event.Event, a *appTypes.App, width, height int) (*app.ShellRecording, error) {
+	p, err := pool.GetPoolByName(ctx, a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if !p.ShellAuditEnabled() {
+		return nil, nil
+	}
+	err = evt.SetOtherCustomData(ctx, map[string]bool{"recorded": true})
+	if err != nil {
+		return nil, err
+	}
+	return app.NewShellRecording(ctx, evt.UniqueID, a.Name, width, height)
+}
+
+// title: app shell recording
+// path: /events/{uuid}/shell-recording
+// method: GET
+// produce: application/x-asciicast
+// responses:
+//
+//	200: OK
+//	400: Invalid uuid
+//	401: Unauthorized
+//	404: Not found
+func shellRecordingHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	uuid := r.URL.Query().Get(":uuid")
+	eventID, err := primitive.ObjectIDFromHex(uuid)
+	if err != nil {
+		msg := fmt.Sprintf("uuid parameter is not ObjectId: %s", uuid)
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	e, err := event.GetByID(ctx, eventID)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if e.Kind.Name != permission.PermAppRunShell.FullName() {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: app.ErrShellRecordingNotFound.Error()}
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppAdminShellRecording, e.Allowed.Contexts...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	recording, err := app.GetShellRecording(ctx, eventID)
+	if err == app.ErrShellRecordingNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	_, err = io.Copy(w, recording)
+	return err
+}
+
 func unitsForShell(ctx stdContext.Context, a *appTypes.App, unitID string, isolated bool) []string {
 	if isolated {
 		return nil
@@ -264,3 +347,29 @@ func (c *wsReadWriteCloser) Read(p []byte) (n int, err error) {
 func (c *wsReadWriteCloser) Write(p []byte) (n int, err error) {
 	return len(p), c.Conn.WriteMessage(websocket.TextMessage, p)
 }
+
+type recordingReadWriteCloser struct {
+	base      io.ReadWriteCloser
+	recording *app.ShellRecording
+}
+
+func (c *recordingReadWriteCloser) Read(p []byte) (n int, err error) {
+	n, err = c.base.Read(p)
+	if recErr := c.recording.Input(p[:n]); recErr != nil {
+		c.base.Close()
+		return 0, recErr
+	}
+	return n, err
+}
+
+func (c *recordingReadWriteCloser) Write(p []byte) (n int, err error) {
+	if recErr := c.recording.Output(p); recErr != nil {
+		c.base.Close()
+		return 0, recErr
+	}
+	return c.base.Write(p)
+}
+
+func (c *recordingReadWriteCloser) Close() error {
+	return c.base.Close()
+}
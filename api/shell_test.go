@@ -8,13 +8,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"time"
 
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/tsurutest"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	permTypes "github.com/tsuru/tsuru/types/permission"
@@ -64,6 +67,96 @@ func (s *S) TestAppShellWithAppName(c *check.C) {
 	c.Assert(shells[0].Units, check.DeepEquals, []string{units[0].ID})
 }
 
+func (s *S) TestAppShellRecordedWhenPoolRequiresAudit(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:   "audited",
+		Public: true,
+		Labels: map[string]string{"shell-audit": "true"},
+	})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{
+		Name:      "someapp",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		Pool:      "audited",
+	}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("test\n"))
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	testServerURL, err := url.Parse(server.URL)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("ws://%s/apps/%s/shell?width=140&height=38&term=xterm", testServerURL.Host, a.Name)
+	config, err := websocket.NewConfig(url, "ws://localhost/")
+	c.Assert(err, check.IsNil)
+	config.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	wsConn, err := websocket.DialConfig(config)
+	c.Assert(err, check.IsNil)
+	defer wsConn.Close()
+	var evts []*event.Event
+	err = tsurutest.WaitCondition(5*time.Second, func() bool {
+		evts, err = event.List(context.TODO(), &event.Filter{KindNames: []string{permission.PermAppRunShell.FullName()}})
+		c.Assert(err, check.IsNil)
+		return len(evts) == 1 && !evts[0].Running
+	})
+	c.Assert(err, check.IsNil)
+	var otherData map[string]bool
+	err = evts[0].OtherData(&otherData)
+	c.Assert(err, check.IsNil)
+	c.Assert(otherData, check.DeepEquals, map[string]bool{"recorded": true})
+	request, err := http.NewRequest(http.MethodGet, "/events/"+evts[0].UniqueID.Hex()+"/shell-recording", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-asciicast")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s)\{"version":2,"width":140,"height":38,.*"o","test\\n"\]\n`)
+}
+
+func (s *S) TestShellRecordingNotShellEvent(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget("someapp"),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/events/"+evt.UniqueID.Hex()+"/shell-recording", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestShellRecordingWithoutPermission(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget("someapp"),
+		Kind:    permission.PermAppRunShell,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "someapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRunShell,
+		Context: permission.Context(permTypes.CtxApp, "someapp"),
+	})
+	request, err := http.NewRequest(http.MethodGet, "/events/"+evt.UniqueID.Hex()+"/shell-recording", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *S) TestAppShellWithAppNameInvalidPermission(c *check.C) {
 	a := appTypes.App{
 		Name:      "someapp",
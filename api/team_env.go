@@ -0,0 +1,187 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: get team envs
+// path: /teams/{name}/env
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Team not found
+func getTeamEnv(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamReadEnv, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	envs := team.Env
+	if envs == nil {
+		envs = []bindTypes.EnvVar{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(envs)
+}
+
+// title: set team envs
+// path: /teams/{name}/env
+// method: POST
+// consume: application/json
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Envs updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team not found
+func setTeamEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var e apiTypes.Envs
+	err = ParseInput(r, &e)
+	if err != nil {
+		return err
+	}
+	if len(e.Envs) == 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the list of environment variables"}
+	}
+	if err = validateApiEnvVars(e.Envs); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("There were errors validating environment variables: %s", err)}
+	}
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateEnv, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	var toExclude []string
+	variables := []bindTypes.EnvVar{}
+	for i, v := range e.Envs {
+		private := e.Private || (v.Private != nil && *v.Private)
+		if private {
+			toExclude = append(toExclude, fmt.Sprintf("Envs.%d.Value", i))
+		}
+		variables = append(variables, bindTypes.EnvVar{
+			Name:   v.Name,
+			Value:  v.Value,
+			Alias:  v.Alias,
+			Public: !private,
+		})
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     teamTarget(teamName),
+		Kind:       permission.PermTeamUpdateEnv,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, toExclude...)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = app.SetTeamEnvs(ctx, teamName, bindTypes.SetEnvArgs{
+		Envs:          variables,
+		ShouldRestart: !e.NoRestart,
+		Writer:        evt,
+	})
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: unset team envs
+// path: /teams/{name}/env
+// method: DELETE
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Envs removed
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team not found
+func unsetTeamEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	variables, _ := InputValues(r, "env")
+	if len(variables) == 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the list of environment variables."}
+	}
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateEnv, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     teamTarget(teamName),
+		Kind:       permission.PermTeamUpdateEnv,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
+	return app.UnsetTeamEnvs(ctx, teamName, bindTypes.UnsetEnvArgs{
+		VariableNames: variables,
+		ShouldRestart: !noRestart,
+		Writer:        evt,
+	})
+}
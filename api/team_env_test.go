@@ -0,0 +1,170 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cezarsa/form"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) mockTeamEnvs(envs *[]bindTypes.EnvVar) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name != s.team.Name {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return &authTypes.Team{Name: name, Env: *envs}, nil
+	}
+	s.mockService.Team.OnSetEnvs = func(name string, newEnvs []bindTypes.EnvVar) error {
+		*envs = append(*envs, newEnvs...)
+		return nil
+	}
+	s.mockService.Team.OnUnsetEnvs = func(name string, names []string) error {
+		var remaining []bindTypes.EnvVar
+		for _, env := range *envs {
+			if env.Name != names[0] {
+				remaining = append(remaining, env)
+			}
+		}
+		*envs = remaining
+		return nil
+	}
+}
+
+func (s *S) TestGetTeamEnv(c *check.C) {
+	envs := []bindTypes.EnvVar{{Name: "LOG_LEVEL", Value: "info", Public: true}}
+	s.mockTeamEnvs(&envs)
+	request, err := http.NewRequest(http.MethodGet, "/teams/"+s.team.Name+"/env", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result []bindTypes.EnvVar
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, envs)
+}
+
+func (s *S) TestGetTeamEnvTeamNotFound(c *check.C) {
+	var envs []bindTypes.EnvVar
+	s.mockTeamEnvs(&envs)
+	request, err := http.NewRequest(http.MethodGet, "/teams/unknown/env", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSetTeamEnv(c *check.C) {
+	var envs []bindTypes.EnvVar
+	s.mockTeamEnvs(&envs)
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	private := true
+	d := apiTypes.Envs{
+		Envs: []apiTypes.Env{
+			{Name: "LOG_LEVEL", Value: "info"},
+			{Name: "SENTRY_DSN", Value: "secret", Private: &private},
+		},
+		NoRestart: true,
+	}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/teams/"+s.team.Name+"/env", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	expected := []bindTypes.EnvVar{
+		{Name: "LOG_LEVEL", Value: "info", Public: true},
+		{Name: "SENTRY_DSN", Value: "secret", Public: false},
+	}
+	c.Assert(envs, check.DeepEquals, expected)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamEnvs, check.DeepEquals, expected)
+	c.Assert(recorder.Body.String(), check.Matches,
+		`{"Message":".*---- Setting 2 team environment variables ----\\n","Timestamp":".*"}
+`)
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(s.team.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.env",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": s.team.Name},
+			{"name": "Envs.0.Name", "value": "LOG_LEVEL"},
+			{"name": "Envs.0.Value", "value": "info"},
+			{"name": "Envs.1.Name", "value": "SENTRY_DSN"},
+			{"name": "NoRestart", "value": "true"},
+			{"name": "Private", "value": ""},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetTeamEnvInvalidName(c *check.C) {
+	var envs []bindTypes.EnvVar
+	s.mockTeamEnvs(&envs)
+	d := apiTypes.Envs{Envs: []apiTypes.Env{{Name: "TSURU_APPNAME", Value: "x"}}}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/teams/"+s.team.Name+"/env", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(envs, check.HasLen, 0)
+}
+
+func (s *S) TestUnsetTeamEnv(c *check.C) {
+	envs := []bindTypes.EnvVar{
+		{Name: "LOG_LEVEL", Value: "info", Public: true},
+		{Name: "REGION", Value: "us", Public: true},
+	}
+	s.mockTeamEnvs(&envs)
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/teams/"+s.team.Name+"/env?noRestart=true&env=LOG_LEVEL", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	expected := []bindTypes.EnvVar{{Name: "REGION", Value: "us", Public: true}}
+	c.Assert(envs, check.DeepEquals, expected)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamEnvs, check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(s.team.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.env",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": s.team.Name},
+			{"name": "env", "value": "LOG_LEVEL"},
+			{"name": "noRestart", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: isolation profile list
+// path: /isolation-profiles
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+func isolationProfileList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(r.Context(), t, permission.PermTeamUpdateIsolationProfile) {
+		return permission.ErrUnauthorized
+	}
+	profiles, err := auth.ListIsolationProfiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(profiles)
+}
+
+// title: set team isolation profile
+// path: /teams/{name}/isolation-profile
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Isolation profile updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team not found
+//	409: Team has apps or jobs
+func setTeamIsolationProfile(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermTeamUpdateIsolationProfile) {
+		return permission.ErrUnauthorized
+	}
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	profileName := InputValue(r, "profile")
+	var wanted *authTypes.IsolationProfile
+	if profileName != "" {
+		wanted, err = auth.GetIsolationProfile(profileName)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
+	var current *authTypes.IsolationProfile
+	if team.IsolationProfile != "" {
+		// profiles removed from the config are handled as no profile.
+		current, _ = auth.GetIsolationProfile(team.IsolationProfile)
+	}
+	if dedicatedNamespace(current) != dedicatedNamespace(wanted) {
+		err = ensureTeamHasNoWorkloads(ctx, teamName)
+		if err != nil {
+			return err
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     teamTarget(teamName),
+		Kind:       permission.PermTeamUpdateIsolationProfile,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Team.SetIsolationProfile(ctx, teamName, profileName)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+func dedicatedNamespace(profile *authTypes.IsolationProfile) bool {
+	return profile != nil && profile.DedicatedNamespace
+}
+
+// ensureTeamHasNoWorkloads refuses changes to the namespace of a team while
+// it owns apps or jobs, as their resources would be left behind in the
+// previous namespace.
+func ensureTeamHasNoWorkloads(ctx context.Context, teamName string) error {
+	apps, err := app.List(ctx, &app.Filter{TeamOwner: teamName})
+	if err != nil {
+		return err
+	}
+	jobs, err := servicemanager.Job.List(ctx, &jobTypes.Filter{TeamOwner: teamName})
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 && len(jobs) == 0 {
+		return nil
+	}
+	return &errors.HTTP{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("team %q owns %d apps and %d jobs, the namespace isolation of a team can only be changed while it owns none", teamName, len(apps), len(jobs)),
+	}
+}
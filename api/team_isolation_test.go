@@ -0,0 +1,130 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) mockTeamIsolationProfile(profile *string) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name != s.team.Name {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return &authTypes.Team{Name: name, IsolationProfile: *profile}, nil
+	}
+	s.mockService.Team.OnSetIsolationProfile = func(name, p string) error {
+		*profile = p
+		return nil
+	}
+}
+
+func (s *S) TestSetTeamIsolationProfile(c *check.C) {
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	defer config.Unset("isolation-profiles")
+	var profile string
+	s.mockTeamIsolationProfile(&profile)
+	body := strings.NewReader("profile=regulated")
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/isolation-profile", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(profile, check.Equals, "regulated")
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(s.team.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.isolation-profile",
+		StartCustomData: []map[string]interface{}{
+			{"name": "profile", "value": "regulated"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetTeamIsolationProfileUnknownProfile(c *check.C) {
+	var profile string
+	s.mockTeamIsolationProfile(&profile)
+	body := strings.NewReader("profile=unknown")
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/isolation-profile", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, authTypes.ErrIsolationProfileNotFound.Error()+"\n")
+}
+
+func (s *S) TestSetTeamIsolationProfileTeamOwnsApps(c *check.C) {
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	defer config.Unset("isolation-profiles")
+	var profile string
+	s.mockTeamIsolationProfile(&profile)
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("profile=regulated")
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/isolation-profile", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(profile, check.Equals, "")
+}
+
+func (s *S) TestUpdateTeamNameDedicatedNamespaceTeamOwnsApps(c *check.C) {
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	defer config.Unset("isolation-profiles")
+	profile := "regulated"
+	s.mockTeamIsolationProfile(&profile)
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.mockService.Team.OnCreate = func(name string, _ []string, _ *authTypes.User) error {
+		c.Errorf("team %q should not be created", name)
+		return nil
+	}
+	body := strings.NewReader("newname=team9000")
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestSetTeamIsolationProfileUnauthorized(c *check.C) {
+	var profile string
+	s.mockTeamIsolationProfile(&profile)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermTeamUpdate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("profile=")
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/isolation-profile", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
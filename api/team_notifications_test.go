@@ -0,0 +1,104 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event/eventtest"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) mockTeamNotifications(notifications *authTypes.TeamNotifications) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name != s.team.Name {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return &authTypes.Team{Name: name, Notifications: *notifications}, nil
+	}
+	s.mockService.Team.OnSetNotifications = func(name string, n authTypes.TeamNotifications) error {
+		if n.WebhookURL == "invalid" {
+			return &errors.ValidationError{Message: "invalid webhook url"}
+		}
+		*notifications = n
+		return nil
+	}
+}
+
+func (s *S) TestGetTeamNotifications(c *check.C) {
+	notifications := authTypes.TeamNotifications{SlackURL: "https://hooks.slack.com/x"}
+	s.mockTeamNotifications(&notifications)
+	request, err := http.NewRequest(http.MethodGet, "/teams/"+s.team.Name+"/notifications", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result authTypes.TeamNotifications
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, authTypes.TeamNotifications{
+		Emails:   []string{},
+		SlackURL: "https://hooks.slack.com/x",
+	})
+}
+
+func (s *S) TestGetTeamNotificationsTeamNotFound(c *check.C) {
+	var notifications authTypes.TeamNotifications
+	s.mockTeamNotifications(&notifications)
+	request, err := http.NewRequest(http.MethodGet, "/teams/unknown/notifications", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSetTeamNotifications(c *check.C) {
+	var notifications authTypes.TeamNotifications
+	s.mockTeamNotifications(&notifications)
+	body := `{"emails":["ops@example.com"],"webhookURL":"https://example.com/hook?token=secret"}`
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/notifications", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(notifications, check.DeepEquals, authTypes.TeamNotifications{
+		Emails:     []string{"ops@example.com"},
+		WebhookURL: "https://example.com/hook?token=secret",
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(s.team.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.notifications",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": s.team.Name},
+			{"name": "emails.0", "value": "ops@example.com"},
+			{"name": "webhookURL", "value": "*****"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetTeamNotificationsInvalid(c *check.C) {
+	var notifications authTypes.TeamNotifications
+	s.mockTeamNotifications(&notifications)
+	request, err := http.NewRequest(http.MethodPut, "/teams/"+s.team.Name+"/notifications", strings.NewReader(`{"webhookURL":"invalid"}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid webhook url\n")
+	c.Assert(notifications.IsEmpty(), check.Equals, true)
+}
@@ -5,15 +5,18 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
@@ -192,6 +195,66 @@ func tokenUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	return json.NewEncoder(w).Encode(teamToken)
 }
 
+// title: token rotate
+// path: /tokens/{token_id}/rotate
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Token rotated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Token not found
+func tokenRotate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var args authTypes.TeamTokenRotateArgs
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	args.TokenID = r.URL.Query().Get(":token_id")
+	teamToken, err := servicemanager.TeamToken.FindByTokenID(ctx, args.TokenID)
+	if err != nil {
+		if err == authTypes.ErrTeamTokenNotFound {
+			return &errors.HTTP{
+				Code:    http.StatusNotFound,
+				Message: err.Error(),
+			}
+		}
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermTeamTokenUpdateRotate,
+		permission.Context(permTypes.CtxTeam, teamToken.Team),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     teamTarget(teamToken.Team),
+		Kind:       permission.PermTeamTokenUpdateRotate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamToken.Team)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	teamToken, err = servicemanager.TeamToken.Rotate(ctx, args, t)
+	if err == authTypes.ErrTeamTokenNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return handleAuthError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(teamToken)
+}
+
 // title: token delete
 // path: /tokens/{token_id}
 // method: DELETE
@@ -242,3 +305,35 @@ func tokenDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	}
 	return err
 }
+
+// recordPreviousTeamTokenUse adds an event for the first use of a team token
+// value replaced by a rotation, so the clients still using it may be tracked
+// down before the overlap window ends. Later uses only update the
+// previous_token_last_access of the token.
+func recordPreviousTeamTokenUse(ctx context.Context, t auth.Token) {
+	named, ok := t.(authTypes.NamedToken)
+	if !ok {
+		return
+	}
+	teamToken, err := servicemanager.TeamToken.FindByTokenID(ctx, named.GetTokenName())
+	if err != nil {
+		log.Errorf("unable to find team token %q: %v", named.GetTokenName(), err)
+		return
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       teamTarget(teamToken.Team),
+		InternalKind: "team token previous use",
+		RawOwner:     eventTypes.Owner{Type: eventTypes.OwnerTypeToken, Name: teamToken.TokenID},
+		CustomData: map[string]interface{}{
+			"token_id":                  teamToken.TokenID,
+			"previous_token_expires_at": teamToken.PreviousTokenExpiresAt,
+		},
+		Allowed:     event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamToken.Team)),
+		DisableLock: true,
+	})
+	if err != nil {
+		log.Errorf("unable to create event for previous value use of team token %q: %v", teamToken.TokenID, err)
+		return
+	}
+	evt.Done(ctx, nil)
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -240,6 +241,63 @@ func (s *S) TestTeamTokenUpdate(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestTeamTokenRotate(c *check.C) {
+	originalToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "id1",
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`overlap=300`)
+	request, err := http.NewRequest("POST", "/1.25/tokens/id1/rotate", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result authTypes.TeamToken
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Token, check.Not(check.Equals), originalToken.Token)
+	c.Assert(result.PreviousToken, check.Equals, "")
+	c.Assert(result.PreviousTokenExpiresAt.IsZero(), check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: s.team.Name},
+		Owner:  s.user.Email,
+		Kind:   "team.token.update.rotate",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":token_id", "value": "id1"},
+			{"name": "overlap", "value": "300"},
+		},
+	}, eventtest.HasEvent)
+
+	for i := 0; i < 2; i++ {
+		request, err = http.NewRequest("GET", "/apps", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+originalToken.Token)
+		recorder = httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Not(check.Equals), http.StatusUnauthorized)
+	}
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: s.team.Name},
+		Owner:  "id1",
+		Kind:   "team token previous use",
+	}, eventtest.HasEvent)
+	evts, err := event.List(context.TODO(), &event.Filter{KindNames: []string{"team token previous use"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+}
+
+func (s *S) TestTeamTokenRotateNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.25/tokens/unknown/rotate", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestTeamTokenInfo(c *check.C) {
 	newToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
 		Team:        s.team.Name,
@@ -0,0 +1,82 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCaptureUnit(c *check.C) {
+	var uploaded string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded = r.URL.Path
+	}))
+	defer srv.Close()
+	config.Set("capture:storage:url", srv.URL)
+	defer config.Unset("capture")
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	units, err := s.provisioner.AddUnitsToNode(&a, 1, "web", nil, "node1", nil)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("profile data"))
+	body := strings.NewReader("type=profile")
+	request, err := http.NewRequest("POST", "/apps/myapp/units/"+units[0].ID+"/capture", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, "(?s).*Capture stored at "+srv.URL+uploaded+".*")
+	c.Assert(eventtest.EventDesc{
+		Target:          appTarget("myapp"),
+		Owner:           s.token.GetUserName(),
+		Kind:            "app.update.unit.capture",
+		StartCustomData: []map[string]interface{}{{"name": "type", "value": "profile"}, {"name": ":app", "value": "myapp"}, {"name": ":unit", "value": units[0].ID}},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestCaptureUnitInvalidType(c *check.C) {
+	config.Set("capture:storage:url", "http://localhost")
+	defer config.Unset("capture")
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("type=trace")
+	request, err := http.NewRequest("POST", "/apps/myapp/units/myapp-0/capture", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrInvalidCaptureType.Error()+"\n")
+}
+
+func (s *S) TestCaptureUnitNotFound(c *check.C) {
+	config.Set("capture:storage:url", "http://localhost")
+	defer config.Unset("capture")
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("type=heap")
+	request, err := http.NewRequest("POST", "/apps/myapp/units/myapp-9/capture", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
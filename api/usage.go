@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultUsageReportWindow = 30 * 24 * time.Hour
+
+// title: usage report
+// path: /reports/usage
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+func usageReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	contexts := permission.ContextsForPermission(ctx, t, permission.PermTeamReadUsage)
+	if len(contexts) == 0 {
+		return permission.ErrUnauthorized
+	}
+	filter := app.UsageFilter{
+		To:      time.Now().UTC(),
+		GroupBy: r.URL.Query().Get("groupBy"),
+		Teams:   []string{},
+	}
+	var err error
+	if to := r.URL.Query().Get("to"); to != "" {
+		filter.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid to value %q: %v", to, err)}
+		}
+	}
+	filter.From = filter.To.Add(-defaultUsageReportWindow)
+	if from := r.URL.Query().Get("from"); from != "" {
+		filter.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid from value %q: %v", from, err)}
+		}
+	}
+	for _, c := range contexts {
+		if c.CtxType == permTypes.CtxGlobal {
+			filter.Teams = nil
+			break
+		}
+		if c.CtxType == permTypes.CtxTeam {
+			filter.Teams = append(filter.Teams, c.Value)
+		}
+	}
+	report, err := app.GetUsageReport(ctx, filter)
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(report)
+}
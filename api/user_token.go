@@ -0,0 +1,138 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: user token list
+// path: /users/tokens
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List tokens
+//	204: No content
+//	401: Unauthorized
+func userTokenList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	email := t.GetUserName()
+	allowed := permission.Check(ctx, t, permission.PermUserTokenRead,
+		permission.Context(permTypes.CtxUser, email),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	tokens, err := auth.ListUserTokens(ctx, email)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(tokens)
+}
+
+// title: user token create
+// path: /users/tokens
+// method: POST
+// produce: application/json
+// responses:
+//
+//	201: Token created
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Permission not held by the user
+//	409: Token already exists
+func userTokenCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var args auth.UserTokenCreateArgs
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	email := t.GetUserName()
+	allowed := permission.Check(ctx, t, permission.PermUserTokenCreate,
+		permission.Context(permTypes.CtxUser, email),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserTokenCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	token, err := auth.CreateUserToken(ctx, args, t)
+	if err == auth.ErrUserTokenAlreadyExists {
+		return &errors.HTTP{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return handleAuthError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(token)
+}
+
+// title: user token delete
+// path: /users/tokens/{token_id}
+// method: DELETE
+// responses:
+//
+//	200: Token deleted
+//	401: Unauthorized
+//	404: Token not found
+func userTokenDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	tokenID := r.URL.Query().Get(":token_id")
+	email := t.GetUserName()
+	allowed := permission.Check(ctx, t, permission.PermUserTokenDelete,
+		permission.Context(permTypes.CtxUser, email),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserTokenDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = auth.DeleteUserToken(ctx, email, tokenID)
+	if err == auth.ErrUserTokenNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	return err
+}
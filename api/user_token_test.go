@@ -0,0 +1,141 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestUserTokenCreate(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermUserToken,
+		Context: permission.Context(permTypes.CtxUser, "majortom@groundcontrol.com"),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("token_id=ci&description=deploys&expires_in=60&permissions.0=app.deploy")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/users/tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var result auth.UserToken
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.TokenID, check.Equals, "ci")
+	c.Assert(result.Token, check.Not(check.Equals), "")
+	c.Assert(result.Email, check.Equals, "majortom@groundcontrol.com")
+	c.Assert(result.Permissions, check.DeepEquals, []string{"app.deploy"})
+	c.Assert(result.ExpiresAt.IsZero(), check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(token.GetUserName()),
+		Owner:  token.GetUserName(),
+		Kind:   "user.token.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "token_id", "value": "ci"},
+			{"name": "description", "value": "deploys"},
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest(http.MethodGet, "/1.25/users/tokens", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+result.Token)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestUserTokenCreatePermissionNotHeld(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermUserToken,
+		Context: permission.Context(permTypes.CtxUser, "majortom@groundcontrol.com"),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("permissions.0=app")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/users/tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, "cannot create token with permission \"app\" not held by the user\n")
+}
+
+func (s *S) TestUserTokenCreateWithoutPermission(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("permissions.0=app.deploy")
+	request, err := http.NewRequest(http.MethodPost, "/1.25/users/tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestUserTokenList(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/1.25/users/tokens", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	_, err = auth.CreateUserToken(context.TODO(), auth.UserTokenCreateArgs{TokenID: "ci", Permissions: []string{"app.read"}}, s.token)
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result []auth.UserToken
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].TokenID, check.Equals, "ci")
+	c.Assert(result[0].Token, check.Equals, "")
+	c.Assert(result[0].Email, check.Equals, s.token.GetUserName())
+}
+
+func (s *S) TestUserTokenDelete(c *check.C) {
+	_, err := auth.CreateUserToken(context.TODO(), auth.UserTokenCreateArgs{TokenID: "ci", Permissions: []string{"app.read"}}, s.token)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/1.25/users/tokens/ci", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(s.token.GetUserName()),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.token.delete",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":token_id", "value": "ci"},
+		},
+	}, eventtest.HasEvent)
+	tokens, err := auth.ListUserTokens(context.TODO(), s.token.GetUserName())
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -6,6 +6,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -132,6 +133,11 @@ func volumeCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 	if !canCreate {
 		return permission.ErrUnauthorized
 	}
+	for _, sharedPool := range inputVolume.SharedPools {
+		if !permission.Check(ctx, t, permission.PermVolumeCreate, permission.Context(permTypes.CtxPool, sharedPool)) {
+			return permission.ErrUnauthorized
+		}
+	}
 
 	err = servicemanager.Volume.CheckPoolVolumeConstraints(ctx, inputVolume)
 	if err == volumeTypes.ErrVolumePlanNotFound || err == pool.ErrPoolHasNoVolumePlan {
@@ -281,6 +287,7 @@ func volumeDelete(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 // responses:
 //
 //	200: Volume binded
+//	400: Invalid data
 //	401: Unauthorized
 //	404: Volume not found
 //	409: Volume bind already exists
@@ -315,6 +322,12 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !canBindApp {
 		return permission.ErrUnauthorized
 	}
+	if !dbVolume.AllowsPool(a.Pool) && dbVolume.IsShared() {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("volume %q is not shared with pool %q of app %q", dbVolume.Name, a.Pool, a.Name),
+		}
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: dbVolume.Name},
 		Kind:       permission.PermVolumeUpdateBind,
@@ -337,6 +350,9 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		if err == volumeTypes.ErrVolumeAlreadyBound {
 			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
 		}
+		if err == volumeTypes.ErrSharedVolumeReadOnly {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
 		return err
 	}
 	w.Header().Set("Content-Type", "application/x-json-stream")
@@ -7,6 +7,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -705,6 +706,26 @@ func (s *S) TestVolumeBindConflict(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
 }
 
+func (s *S) TestVolumeBindSharedVolumeFromOtherPool(c *check.C) {
+	v1 := volumeTypes.Volume{Name: "v1", Pool: "pool2", SharedPools: []string{"pool3"}, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, appName string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`app=myapp&mountpoint=/mnt1&readonly=true`)
+	request, err := http.NewRequest("POST", "/1.4/volumes/v1/bind", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	m := RunServer(true)
+	m.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, fmt.Sprintf("volume \"v1\" is not shared with pool %q of app \"myapp\"\n", a.Pool))
+}
+
 func (s *S) TestVolumeBindNoRestart(c *check.C) {
 	s.mockService.Team.OnList = func() ([]authTypes.Team, error) {
 		return []authTypes.Team{{Name: s.team.Name}}, nil
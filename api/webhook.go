@@ -6,14 +6,18 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
 )
 
 // title: webhook list
@@ -74,6 +78,50 @@ func webhookInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(webhook)
 }
 
+// title: webhook deliveries
+// path: /events/webhooks/{name}/deliveries
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List webhook deliveries
+//	204: No content
+//	400: Invalid limit
+//	404: Not found
+//	401: Unauthorized
+func webhookDeliveries(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookRead, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	limit := 100
+	if rawLimit := InputValue(r, "limit"); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid limit value %q", rawLimit)}
+		}
+	}
+	deliveries, err := servicemanager.Webhook.Deliveries(ctx, webhookName, limit)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deliveries)
+}
+
 // title: webhook create
 // path: /events/webhooks
 // method: POST
@@ -82,6 +130,7 @@ func webhookInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 //	200: Webhook created
 //	401: Unauthorized
 //	400: Invalid webhook
+//	403: Quota exceeded
 //	409: Webhook already exists
 func webhookCreate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
@@ -100,6 +149,9 @@ func webhookCreate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !permission.Check(ctx, t, permission.PermWebhookCreate, permCtx) {
 		return permission.ErrUnauthorized
 	}
+	if !permission.Check(ctx, t, permission.PermWebhookCreate) {
+		webhook.TeamScoped = true
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: webhook.Name},
 		Kind:       permission.PermWebhookCreate,
@@ -118,6 +170,9 @@ func webhookCreate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err == eventTypes.ErrWebhookAlreadyExists {
 		w.WriteHeader(http.StatusConflict)
 	}
+	if quotaErr, ok := err.(*quotaTypes.QuotaExceededError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: quotaErr.Error()}
+	}
 	return err
 }
 
@@ -142,6 +197,9 @@ func webhookUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !permission.Check(ctx, t, permission.PermWebhookUpdate, permissionCtx) {
 		return permission.ErrUnauthorized
 	}
+	if !permission.Check(ctx, t, permission.PermWebhookUpdate) {
+		webhook.TeamScoped = true
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: webhook.Name},
 		Kind:       permission.PermWebhookUpdate,
@@ -12,10 +12,13 @@ import (
 	"strings"
 
 	"github.com/cezarsa/form"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	check "gopkg.in/check.v1"
 )
 
@@ -57,6 +60,7 @@ func (s *S) TestWebhookList(c *check.C) {
 				TargetValues: []string{},
 				KindTypes:    []string{},
 				KindNames:    []string{"app.deploy"},
+				Teams:        []string{},
 			},
 		},
 		{
@@ -69,6 +73,7 @@ func (s *S) TestWebhookList(c *check.C) {
 				TargetValues: []string{},
 				KindTypes:    []string{},
 				KindNames:    []string{},
+				Teams:        []string{},
 			},
 		},
 	})
@@ -125,6 +130,7 @@ func (s *S) TestWebhookListByTeam(c *check.C) {
 				TargetValues: []string{},
 				KindTypes:    []string{},
 				KindNames:    []string{},
+				Teams:        []string{},
 			},
 		},
 	})
@@ -160,6 +166,7 @@ func (s *S) TestWebhookCreate(c *check.C) {
 			TargetValues: []string{},
 			KindTypes:    []string{},
 			KindNames:    []string{"app.deploy"},
+			Teams:        []string{},
 		},
 	})
 }
@@ -193,10 +200,60 @@ func (s *S) TestWebhookCreateAutoTeam(c *check.C) {
 			TargetValues: []string{},
 			KindTypes:    []string{},
 			KindNames:    []string{"app.deploy"},
+			Teams:        []string{},
 		},
 	})
 }
 
+func (s *S) TestWebhookCreateTeamScoped(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermWebhookCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	webhook1 := eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	}
+	bodyData, err := form.EncodeToString(webhook1)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/events/webhooks", strings.NewReader(bodyData))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	wh, err := servicemanager.Webhook.Find(context.TODO(), "wh1")
+	c.Assert(err, check.IsNil)
+	c.Assert(wh.TeamScoped, check.Equals, true)
+}
+
+func (s *S) TestWebhookCreateQuotaExceeded(c *check.C) {
+	config.Set("quota:webhooks-per-team", 1)
+	defer config.Unset("quota:webhooks-per-team")
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	bodyData, err := form.EncodeToString(eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh2",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/events/webhooks", strings.NewReader(bodyData))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, "Quota exceeded. Available: 0, Requested: 1.\n")
+}
+
 func (s *S) TestWebhookCreateConflict(c *check.C) {
 	webhook1 := eventTypes.Webhook{
 		TeamOwner: s.team.Name,
@@ -272,6 +329,7 @@ func (s *S) TestWebhookUpdate(c *check.C) {
 			TargetValues: []string{},
 			KindTypes:    []string{},
 			KindNames:    []string{"app.deploy"},
+			Teams:        []string{},
 		},
 	})
 }
@@ -382,6 +440,7 @@ func (s *S) TestWebhookInfo(c *check.C) {
 			TargetValues: []string{},
 			KindTypes:    []string{},
 			KindNames:    []string{"app.deploy"},
+			Teams:        []string{},
 		},
 	})
 }
@@ -395,3 +454,76 @@ func (s *S) TestWebhookInfoNotFound(c *check.C) {
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 }
+
+func (s *S) TestWebhookDeliveries(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	c.Assert(err, check.IsNil)
+	for _, status := range []string{eventTypes.WebhookDeliveryFailed, eventTypes.WebhookDeliverySuccess} {
+		_, err = collection.InsertOne(context.TODO(), eventTypes.WebhookDelivery{
+			ID:       primitive.NewObjectID(),
+			Webhook:  "wh1",
+			EventID:  "evt1",
+			Status:   status,
+			Attempts: 1,
+		})
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("GET", "/1.25/events/webhooks/wh1/deliveries?limit=1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var deliveries []eventTypes.WebhookDelivery
+	err = json.Unmarshal(recorder.Body.Bytes(), &deliveries)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].Webhook, check.Equals, "wh1")
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliverySuccess)
+}
+
+func (s *S) TestWebhookDeliveriesEmpty(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.25/events/webhooks/wh1/deliveries", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestWebhookDeliveriesInvalidLimit(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.25/events/webhooks/wh1/deliveries?limit=abc", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid limit value \"abc\"\n")
+}
+
+func (s *S) TestWebhookDeliveriesNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.25/events/webhooks/wh1/deliveries", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
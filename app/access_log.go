@@ -0,0 +1,86 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	logTypes "github.com/tsuru/tsuru/types/log"
+)
+
+var ErrAccessLogDisabled = errors.New("access logs are not enabled for this app in the router")
+
+// AccessLogEntry is a single request served by a router to an app.
+type AccessLogEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Host       string    `json:"host"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	LatencyMs  float64   `json:"latencyMs"`
+	Bytes      int64     `json:"bytes"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Unit       string    `json:"unit"`
+}
+
+func (e AccessLogEntry) String() string {
+	msg := fmt.Sprintf("%s %s%s %d %.3fms %dB", e.Method, e.Host, e.Path, e.Status, e.LatencyMs, e.Bytes)
+	if e.RemoteAddr != "" {
+		msg += " from " + e.RemoteAddr
+	}
+	return msg
+}
+
+// AddAccessLogs stores the access logs sent by a router linked to the app
+// with the access-log opt enabled. They're readable through the log API
+// using the router-access source.
+func AddAccessLogs(ctx context.Context, app *appTypes.App, routerName string, entries []AccessLogEntry) error {
+	var appRouter *appTypes.AppRouter
+	routers := GetRouters(app)
+	for i := range routers {
+		if routers[i].Name == routerName {
+			appRouter = &routers[i]
+			break
+		}
+	}
+	if appRouter == nil {
+		return &router.ErrRouterNotFound{Name: routerName}
+	}
+	policy, err := router.AccessLogPolicyFromOpts(appRouter.Opts)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return ErrAccessLogDisabled
+	}
+	for _, entry := range entries {
+		date := entry.Time
+		if date.IsZero() {
+			date = time.Now()
+		}
+		unit := entry.Unit
+		if unit == "" {
+			unit = routerName
+		}
+		err = servicemanager.LogService.Enqueue(&appTypes.Applog{
+			Date:    date.UTC(),
+			Message: entry.String(),
+			Source:  router.AccessLogSource,
+			Name:    app.Name,
+			Type:    logTypes.LogTypeApp,
+			Unit:    unit,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
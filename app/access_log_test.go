@@ -0,0 +1,61 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddAccessLogs(c *check.C) {
+	a := appTypes.App{
+		Name:      "myapp",
+		Platform:  "go",
+		TeamOwner: s.team.Name,
+		Routers: []appTypes.AppRouter{
+			{Name: "fake", Opts: map[string]string{"access-log": "full"}},
+		},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	servicemanager.LogService.Add(a.Name, "app log", "app", "unit1")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = AddAccessLogs(context.TODO(), &a, "fake", []AccessLogEntry{
+		{Time: now, Method: "GET", Host: "myapp.io", Path: "/health", Status: 200, LatencyMs: 1.5, Bytes: 12, RemoteAddr: "10.0.0.1", Unit: "myapp-web-1"},
+		{Time: now, Method: "POST", Host: "myapp.io", Path: "/", Status: 502, LatencyMs: 30000, Bytes: 0},
+	})
+	c.Assert(err, check.IsNil)
+	logs, err := LastLogs(context.TODO(), &a, servicemanager.LogService, appTypes.ListLogArgs{
+		Limit:  10,
+		Source: router.AccessLogSource,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(logs[0].Message, check.Equals, "GET myapp.io/health 200 1.500ms 12B from 10.0.0.1")
+	c.Assert(logs[0].Unit, check.Equals, "myapp-web-1")
+	c.Assert(logs[0].Source, check.Equals, "router-access")
+	c.Assert(logs[1].Message, check.Equals, "POST myapp.io/ 502 30000.000ms 0B")
+	c.Assert(logs[1].Unit, check.Equals, "fake")
+}
+
+func (s *S) TestAddAccessLogsDisabled(c *check.C) {
+	a := appTypes.App{
+		Name:      "myapp",
+		Platform:  "go",
+		TeamOwner: s.team.Name,
+		Routers:   []appTypes.AppRouter{{Name: "fake"}},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = AddAccessLogs(context.TODO(), &a, "fake", []AccessLogEntry{{Method: "GET", Status: 200}})
+	c.Assert(err, check.Equals, ErrAccessLogDisabled)
+	err = AddAccessLogs(context.TODO(), &a, "other", []AccessLogEntry{{Method: "GET", Status: 200}})
+	c.Assert(err, check.DeepEquals, &router.ErrRouterNotFound{Name: "other"})
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/policy"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/registry"
@@ -65,6 +66,7 @@ var (
 	ErrNoAccess          = errors.New("team does not have access to this app")
 	ErrCannotOrphanApp   = errors.New("cannot revoke access from this team, as it's the unique team with access to the app")
 	ErrDisabledPlatform  = errors.New("Disabled Platform, only admin users can create applications with the platform")
+	ErrDeletionProtected = errors.New("app is protected against deletion, disable its deletion protection before removing it")
 
 	ErrRouterAlreadyLinked = errors.New("router already linked to this app")
 	ErrNoRouterWithTLS     = errors.New("no router with tls support")
@@ -152,6 +154,18 @@ func AppInfo(ctx context.Context, app *appTypes.App) (*appTypes.AppInfo, error)
 		Lock:        app.Lock,
 		Tags:        app.Tags,
 		Metadata:    app.Metadata,
+
+		DeletionProtection: app.DeletionProtection,
+		AutoRedeploy:       app.AutoRedeploy,
+		RequireApproval:    app.RequireApproval,
+		Archive:            app.Archive,
+		Maintenance:        app.Maintenance,
+		Budget:             app.Budget,
+
+		Spread:                    app.Spread,
+		SecurityContext:           app.SecurityContext,
+		ServiceAccountAnnotations: app.ServiceAccountAnnotations,
+		InitContainers:            app.InitContainers,
 	}
 
 	if version := image.GetPlatformVersion(app); version != "latest" {
@@ -222,6 +236,11 @@ func AppInfo(ctx context.Context, app *appTypes.App) (*appTypes.AppInfo, error)
 	if autoscaleRec != nil {
 		result.AutoscaleRecommendation = autoscaleRec
 	}
+	metricsScrape, err := MetricsScrapeInfo(ctx, app)
+	if err != nil {
+		errMsgs = append(errMsgs, fmt.Sprintf("unable to get metrics scrape info: %+v", err))
+	}
+	result.MetricsScrape = metricsScrape
 	unitMetrics, err := UnitsMetrics(ctx, app)
 	if err != nil {
 		errMsgs = append(errMsgs, fmt.Sprintf("unable to get units metrics: %+v", err))
@@ -315,7 +334,7 @@ func CreateApp(ctx context.Context, app *appTypes.App, user *auth.User) error {
 
 	var plan *appTypes.Plan
 	if app.Plan.Name == "" {
-		plan, err = appPool.GetDefaultPlan(ctx)
+		plan, err = appPool.GetDefaultPlanForTeam(ctx, app.TeamOwner)
 	} else {
 		plan, err = servicemanager.Plan.FindByName(ctx, app.Plan.Name)
 	}
@@ -323,6 +342,12 @@ func CreateApp(ctx context.Context, app *appTypes.App, user *auth.User) error {
 		return err
 	}
 	app.Plan = *plan
+	if app.Spread.Empty() {
+		app.Spread = nil
+	}
+	if app.SecurityContext.Empty() {
+		app.SecurityContext = nil
+	}
 	err = configureCreateRouters(ctx, app)
 	if err != nil {
 		return err
@@ -341,6 +366,21 @@ func CreateApp(ctx context.Context, app *appTypes.App, user *auth.User) error {
 	if err != nil {
 		return err
 	}
+	// Admission policies are evaluated here, after the pool and the plan
+	// of the app are resolved.
+	err = policy.Check(ctx, policy.Input{
+		Operation: policy.OperationAppCreate,
+		Pool:      app.Pool,
+		User:      user.Email,
+		App:       policy.NewApp(app, nil),
+	})
+	if err != nil {
+		return err
+	}
+	app.TeamEnvs, err = teamEnvsFor(ctx, app.TeamOwner)
+	if err != nil {
+		return err
+	}
 	actions := []*action.Action{
 		&reserveTeamApp,
 		&reserveUserApp,
@@ -353,6 +393,7 @@ func CreateApp(ctx context.Context, app *appTypes.App, user *auth.User) error {
 	if err != nil {
 		return &appTypes.AppCreationError{App: app.Name, Err: err}
 	}
+	recordConfigSnapshot(ctx, app)
 	return nil
 }
 
@@ -455,6 +496,7 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 			return errTeam
 		}
 		app.TeamOwner = team.Name
+		app.TeamEnvs = team.Env
 		defer func() {
 			if err == nil {
 				Grant(ctx, app, team)
@@ -464,11 +506,45 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 	if tags != nil {
 		app.Tags = tags
 	}
+	if len(args.UpdateData.SchedulingConstraints) > 0 {
+		constraints := map[string]string{}
+		for k, v := range app.SchedulingConstraints {
+			constraints[k] = v
+		}
+		for k, v := range args.UpdateData.SchedulingConstraints {
+			if v == "" {
+				delete(constraints, k)
+				continue
+			}
+			constraints[k] = v
+		}
+		app.SchedulingConstraints = constraints
+	}
+	if spread := args.UpdateData.Spread; spread != nil {
+		// an empty spread removes the one of the app, falling back to the
+		// spread of the pool.
+		if spread.Empty() {
+			app.Spread = nil
+		} else {
+			app.Spread = spread
+		}
+	}
+	if securityContext := args.UpdateData.SecurityContext; securityContext != nil {
+		// an empty security context removes the one of the app, falling
+		// back to the security context of the pool.
+		if securityContext.Empty() {
+			app.SecurityContext = nil
+		} else {
+			app.SecurityContext = securityContext
+		}
+	}
 	err = args.UpdateData.Metadata.Validate()
 	if err != nil {
 		return err
 	}
 
+	initContainersHasChanged := updateInitContainers(app, args.UpdateData.InitContainers)
+
 	processesHasChanged, err := updateProcesses(ctx, app, args.UpdateData.Processes)
 	if err != nil {
 		return err
@@ -531,8 +607,21 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 		actions = append(actions, &restartApp)
 	} else if string(newMetadata) != string(oldMetadata) && args.ShouldRestart {
 		actions = append(actions, &restartApp)
+	} else if !reflect.DeepEqual(app.Spread, oldApp.Spread) && args.ShouldRestart {
+		actions = append(actions, &restartApp)
+	} else if !reflect.DeepEqual(app.SecurityContext, oldApp.SecurityContext) && args.ShouldRestart {
+		actions = append(actions, &restartApp)
+	} else if initContainersHasChanged && args.ShouldRestart {
+		actions = append(actions, &restartApp)
+	} else if !reflect.DeepEqual(provision.EnvsForApp(app), provision.EnvsForApp(&oldApp)) && args.ShouldRestart {
+		actions = append(actions, &restartApp)
+	}
+	err = action.NewPipeline(actions...).Execute(ctx, app, &oldApp, args.Writer)
+	if err != nil {
+		return err
 	}
-	return action.NewPipeline(actions...).Execute(ctx, app, &oldApp, args.Writer)
+	recordConfigSnapshot(ctx, app)
+	return nil
 }
 
 func updateProcesses(ctx context.Context, app *appTypes.App, new []appTypes.Process) (changed bool, err error) {
@@ -544,6 +633,9 @@ func updateProcesses(ctx context.Context, app *appTypes.App, new []appTypes.Proc
 	if err != nil {
 		return false, errors.WithMessage(err, "could not serialize app process")
 	}
+	// the processes are copied so the ones of the app before the update,
+	// sent to the provisioner, are kept intact.
+	app.Processes = append([]appTypes.Process(nil), app.Processes...)
 
 	positionByName := map[string]*int{}
 	for i, p := range app.Processes {
@@ -560,6 +652,21 @@ func updateProcesses(ctx context.Context, app *appTypes.App, new []appTypes.Proc
 
 		pos := positionByName[p.Name]
 		if pos == nil {
+			if p.DisruptionBudget.Empty() {
+				p.DisruptionBudget = nil
+			}
+			if p.Shutdown.Empty() {
+				p.Shutdown = nil
+			}
+			if p.Rollout.Empty() {
+				p.Rollout = nil
+			}
+			if len(p.NodeSelector) == 0 {
+				p.NodeSelector = nil
+			}
+			if len(p.Tolerations) == 0 {
+				p.Tolerations = nil
+			}
 			app.Processes = append(app.Processes, p)
 			continue
 		}
@@ -567,6 +674,41 @@ func updateProcesses(ctx context.Context, app *appTypes.App, new []appTypes.Proc
 		if p.Plan != "" {
 			app.Processes[*pos].Plan = p.Plan
 		}
+		// An empty disruption budget removes the one set for the process.
+		if p.DisruptionBudget != nil {
+			app.Processes[*pos].DisruptionBudget = p.DisruptionBudget
+			if p.DisruptionBudget.Empty() {
+				app.Processes[*pos].DisruptionBudget = nil
+			}
+		}
+		// Shutdown and rollout settings are replaced as a whole, empty ones
+		// remove the ones set for the process.
+		if p.Shutdown != nil {
+			app.Processes[*pos].Shutdown = p.Shutdown
+			if p.Shutdown.Empty() {
+				app.Processes[*pos].Shutdown = nil
+			}
+		}
+		if p.Rollout != nil {
+			app.Processes[*pos].Rollout = p.Rollout
+			if p.Rollout.Empty() {
+				app.Processes[*pos].Rollout = nil
+			}
+		}
+		// Node selectors and tolerations are replaced as a whole, empty ones
+		// remove the ones set for the process.
+		if p.NodeSelector != nil {
+			app.Processes[*pos].NodeSelector = p.NodeSelector
+			if len(p.NodeSelector) == 0 {
+				app.Processes[*pos].NodeSelector = nil
+			}
+		}
+		if p.Tolerations != nil {
+			app.Processes[*pos].Tolerations = p.Tolerations
+			if len(p.Tolerations) == 0 {
+				app.Processes[*pos].Tolerations = nil
+			}
+		}
 		app.Processes[*pos].Metadata.Update(p.Metadata)
 
 	}
@@ -581,6 +723,43 @@ func updateProcesses(ctx context.Context, app *appTypes.App, new []appTypes.Proc
 	return string(oldProcesses) != string(newProcesses), nil
 }
 
+// updateInitContainers replaces the init containers of the app with the ones
+// of the same name, keeping their order, appends the new ones and removes the
+// ones marked for deletion.
+func updateInitContainers(app *appTypes.App, new []appTypes.InitContainer) bool {
+	if len(new) == 0 {
+		return false
+	}
+	old := app.InitContainers
+	// the init containers are copied so the ones of the app before the
+	// update, sent to the provisioner, are kept intact.
+	updated := append([]appTypes.InitContainer(nil), old...)
+	for _, c := range new {
+		pos := -1
+		for i := range updated {
+			if updated[i].Name == c.Name {
+				pos = i
+				break
+			}
+		}
+		switch {
+		case c.Delete:
+			if pos >= 0 {
+				updated = append(updated[:pos], updated[pos+1:]...)
+			}
+		case pos >= 0:
+			updated[pos] = c
+		default:
+			updated = append(updated, c)
+		}
+	}
+	if len(updated) == 0 {
+		updated = nil
+	}
+	app.InitContainers = updated
+	return !reflect.DeepEqual(old, updated)
+}
+
 func pruneProcesses(app *appTypes.App) {
 	updated := []appTypes.Process{}
 	for _, process := range app.Processes {
@@ -708,6 +887,9 @@ func unbindVolumes(ctx context.Context, app *appTypes.App) error {
 
 // Delete deletes an app.
 func Delete(ctx context.Context, app *appTypes.App, evt *event.Event, requestID string) error {
+	if app.DeletionProtection {
+		return ErrDeletionProtected
+	}
 	w := evt
 	appName := app.Name
 	fmt.Fprintf(w, "---- Removing application %q...\n", appName)
@@ -747,6 +929,16 @@ func Delete(ctx context.Context, app *appTypes.App, evt *event.Event, requestID
 	if err != nil {
 		log.Errorf("failed to remove image names from storage for app %s: %s", appName, err)
 	}
+
+	err = removeDeploySnapshots(ctx, appName)
+	if err != nil {
+		log.Errorf("failed to remove deploy snapshots for app %s: %s", appName, err)
+	}
+
+	err = removeConfigSnapshots(ctx, appName)
+	if err != nil {
+		log.Errorf("failed to remove configuration snapshots for app %s: %s", appName, err)
+	}
 	routers := GetRouters(app)
 	for _, appRouter := range routers {
 		var r router.Router
@@ -858,6 +1050,10 @@ func AddUnits(ctx context.Context, app *appTypes.App, n uint, process, versionSt
 		return err
 	}
 	w = withLogWriter(app, w)
+	err = checkBudget(ctx, app, n, w)
+	if err != nil {
+		return err
+	}
 	err = action.NewPipeline(
 		&reserveUnitsToAdd,
 		&provisionAddUnits,
@@ -1037,6 +1233,15 @@ func getPoolForApp(ctx context.Context, app *appTypes.App, poolName string) (str
 		if err != nil {
 			return "", err
 		}
+		if len(pools) > 1 && len(app.SchedulingConstraints) > 0 {
+			matching, err := pool.FilterBySchedulingConstraints(ctx, pools, app.SchedulingConstraints)
+			if err != nil {
+				return "", err
+			}
+			if len(matching) == 1 {
+				return matching[0].Name, nil
+			}
+		}
 		if len(pools) > 1 {
 			publicPools, err := pool.ListPublicPools(ctx)
 			if err != nil {
@@ -1089,12 +1294,134 @@ func validate(ctx context.Context, app *appTypes.App) error {
 		return err
 	}
 
+	err = appTypes.ValidateTags(app.Tags)
+	if err != nil {
+		return err
+	}
+
 	err = validateProcesses(app)
 	if err != nil {
 		return err
 	}
 
-	return validatePlan(ctx, app)
+	err = app.Spread.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = app.SecurityContext.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = validateInitContainers(app)
+	if err != nil {
+		return err
+	}
+
+	err = validatePlan(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	err = validateProcessesScheduling(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	err = validateMetadataPolicy(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	return validateSchedulingConstraints(ctx, app)
+}
+
+// validateMetadataPolicy checks the annotations and labels of the app and of
+// its processes against the constraints of the pool of the app.
+func validateMetadataPolicy(ctx context.Context, app *appTypes.App) error {
+	var p *pool.Pool
+	checkMetadata := func(metadata appTypes.Metadata, process string) error {
+		if metadata.Empty() {
+			return nil
+		}
+		if p == nil {
+			var err error
+			p, err = pool.GetPoolByName(ctx, app.Pool)
+			if err != nil {
+				return err
+			}
+		}
+		return p.ValidateMetadata(ctx, metadata, process)
+	}
+	err := checkMetadata(app.Metadata, "")
+	if err != nil {
+		return err
+	}
+	for _, process := range app.Processes {
+		err = checkMetadata(process.Metadata, process.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateProcessesScheduling checks the node selectors and tolerations of
+// the processes against the constraints of the pool of the app.
+func validateProcessesScheduling(ctx context.Context, app *appTypes.App) error {
+	var p *pool.Pool
+	for _, process := range app.Processes {
+		if len(process.NodeSelector) == 0 && len(process.Tolerations) == 0 {
+			continue
+		}
+		if p == nil {
+			var err error
+			p, err = pool.GetPoolByName(ctx, app.Pool)
+			if err != nil {
+				return err
+			}
+		}
+		err := p.ValidateProcessScheduling(ctx, process)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchedulingConstraints returns the labels required by the app merged with
+// the ones required by its plan, the app ones take precedence.
+func SchedulingConstraints(app *appTypes.App) map[string]string {
+	constraints := map[string]string{}
+	for k, v := range app.Plan.SchedulingConstraints {
+		constraints[k] = v
+	}
+	for k, v := range app.SchedulingConstraints {
+		constraints[k] = v
+	}
+	return constraints
+}
+
+func validateSchedulingConstraints(ctx context.Context, app *appTypes.App) error {
+	constraints := SchedulingConstraints(app)
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, app.TeamOwner)
+	if err != nil {
+		return err
+	}
+	if profile != nil {
+		for k, v := range profile.SchedulingConstraints {
+			constraints[k] = v
+		}
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+	p, err := pool.GetPoolByName(ctx, app.Pool)
+	if err != nil {
+		return err
+	}
+	return p.ValidateSchedulingConstraints(ctx, constraints)
 }
 
 func validatePlan(ctx context.Context, app *appTypes.App) error {
@@ -1171,6 +1498,27 @@ func validateProcesses(app *appTypes.App) error {
 			msg := fmt.Sprintf("process %q is duplicated", p.Name)
 			return &tsuruErrors.ValidationError{Message: msg}
 		}
+		if !p.DisruptionBudget.Empty() {
+			if err := p.DisruptionBudget.Validate(); err != nil {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid disruption budget for process %q: %v", p.Name, err)}
+			}
+		}
+		if err := p.Shutdown.Validate(); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid shutdown for process %q: %v", p.Name, err)}
+		}
+		if err := p.Rollout.Validate(); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid rollout for process %q: %v", p.Name, err)}
+		}
+		for key := range p.NodeSelector {
+			if key == "" {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("empty node selector key for process %q", p.Name)}
+			}
+		}
+		for _, t := range p.Tolerations {
+			if err := t.Validate(); err != nil {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid toleration for process %q: %v", p.Name, err)}
+			}
+		}
 
 		namesUsed[p.Name] = true
 	}
@@ -1178,6 +1526,29 @@ func validateProcesses(app *appTypes.App) error {
 	return nil
 }
 
+func validateInitContainers(app *appTypes.App) error {
+	namesUsed := map[string]bool{}
+	for _, c := range app.InitContainers {
+		if !validation.ValidateName(c.Name) {
+			msg := fmt.Sprintf("invalid init container name %q, it should have at most 40 characters, containing only lower case letters, numbers or dashes, starting with a letter", c.Name)
+			return &tsuruErrors.ValidationError{Message: msg}
+		}
+		if namesUsed[c.Name] {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("init container %q is duplicated", c.Name)}
+		}
+		namesUsed[c.Name] = true
+		if c.Image == "" && len(c.Command) == 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("init container %q must have a command when running the image of the app", c.Name)}
+		}
+		for name := range c.Env {
+			if name == "" {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("empty env name in init container %q", c.Name)}
+			}
+		}
+	}
+	return nil
+}
+
 func validateTeamOwner(ctx context.Context, app *appTypes.App, p *pool.Pool) error {
 	_, err := servicemanager.Team.FindByName(ctx, app.TeamOwner)
 	if err != nil {
@@ -1484,6 +1855,14 @@ func SetEnvs(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArg
 	if setEnvs.ManagedBy == "" && len(setEnvs.Envs) == 0 {
 		return nil
 	}
+	for _, env := range setEnvs.Envs {
+		if hasSecret(app, env.Name) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("environment variable %q conflicts with a secret of the app", env.Name)}
+		}
+	}
+	if setEnvs.Version != 0 {
+		return setVersionEnvs(ctx, app, setEnvs)
+	}
 
 	envNames := []string{}
 	for _, env := range setEnvs.Envs {
@@ -1494,27 +1873,38 @@ func SetEnvs(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArg
 		envNames = append(envNames, env.Name)
 	}
 
+	var pruned []string
+	if setEnvs.PruneUnused {
+		for name, value := range app.Env {
+			ok := envInSet(name, setEnvs.Envs)
+			// only prune variables managed by requested
+			if !ok && value.ManagedBy == setEnvs.ManagedBy {
+				pruned = append(pruned, name)
+			}
+		}
+		sort.Strings(pruned)
+	}
+
+	err := checkEnvSchemaChange(app, setEnvs.Envs, pruned)
+	if err != nil {
+		return err
+	}
+
 	if setEnvs.Writer != nil && len(setEnvs.Envs) > 0 {
 		fmt.Fprintf(setEnvs.Writer, "---- Setting %d new environment variables ----\n", len(setEnvs.Envs))
 	}
 
-	err := validateEnvConflicts(app, envNames)
+	err = validateEnvConflicts(app, envNames)
 	if err != nil {
 		fmt.Fprintf(setEnvs.Writer, "---- environment variables have conflicts with service binds: %s ----\n", err.Error())
 		return err
 	}
 
-	if setEnvs.PruneUnused {
-		for name, value := range app.Env {
-			ok := envInSet(name, setEnvs.Envs)
-			// only prune variables managed by requested
-			if !ok && value.ManagedBy == setEnvs.ManagedBy {
-				if setEnvs.Writer != nil {
-					fmt.Fprintf(setEnvs.Writer, "---- Pruning %s from environment variables ----\n", name)
-				}
-				delete(app.Env, name)
-			}
+	for _, name := range pruned {
+		if setEnvs.Writer != nil {
+			fmt.Fprintf(setEnvs.Writer, "---- Pruning %s from environment variables ----\n", name)
 		}
+		delete(app.Env, name)
 	}
 
 	for _, env := range setEnvs.Envs {
@@ -1530,6 +1920,7 @@ func SetEnvs(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArg
 	if err != nil {
 		return err
 	}
+	recordConfigSnapshot(ctx, app)
 
 	if setEnvs.ShouldRestart {
 		return restartIfUnits(ctx, app, setEnvs.Writer)
@@ -1561,6 +1952,13 @@ func UnsetEnvs(ctx context.Context, app *appTypes.App, unsetEnvs bindTypes.Unset
 	if len(unsetEnvs.VariableNames) == 0 {
 		return nil
 	}
+	if unsetEnvs.Version != 0 {
+		return unsetVersionEnvs(ctx, app, unsetEnvs)
+	}
+	err := checkEnvSchemaChange(app, nil, unsetEnvs.VariableNames)
+	if err != nil {
+		return err
+	}
 	if unsetEnvs.Writer != nil {
 		fmt.Fprintf(unsetEnvs.Writer, "---- Unsetting %d environment variables ----\n", len(unsetEnvs.VariableNames))
 	}
@@ -1575,6 +1973,7 @@ func UnsetEnvs(ctx context.Context, app *appTypes.App, unsetEnvs bindTypes.Unset
 	if err != nil {
 		return err
 	}
+	recordConfigSnapshot(ctx, app)
 	if unsetEnvs.ShouldRestart {
 		return restartIfUnits(ctx, app, unsetEnvs.Writer)
 	}
@@ -1651,6 +2050,17 @@ func AddInstance(ctx context.Context, app *appTypes.App, addArgs bindTypes.AddIn
 	if len(addArgs.Envs) == 0 {
 		return nil
 	}
+	var version appTypes.AppVersion
+	if addArgs.Version != 0 {
+		var err error
+		version, err = appVersionForEnvs(ctx, app, addArgs.Version)
+		if err != nil {
+			return err
+		}
+		for i := range addArgs.Envs {
+			addArgs.Envs[i].Version = addArgs.Version
+		}
+	}
 	if addArgs.Writer != nil {
 		fmt.Fprintf(addArgs.Writer, "---- Setting %d new environment variables ----\n", len(addArgs.Envs)+1)
 	}
@@ -1663,6 +2073,10 @@ func AddInstance(ctx context.Context, app *appTypes.App, addArgs bindTypes.AddIn
 	if err != nil {
 		return err
 	}
+	recordConfigSnapshot(ctx, app)
+	if addArgs.ShouldRestart && version != nil {
+		return restartVersionIfUnits(ctx, app, version, addArgs.Writer)
+	}
 	if addArgs.ShouldRestart {
 		return restartIfUnits(ctx, app, addArgs.Writer)
 	}
@@ -1693,6 +2107,7 @@ func RemoveInstance(ctx context.Context, app *appTypes.App, removeArgs bindTypes
 	if err != nil {
 		return err
 	}
+	recordConfigSnapshot(ctx, app)
 	if removeArgs.ShouldRestart {
 		return restartIfUnits(ctx, app, removeArgs.Writer)
 	}
@@ -2152,8 +2567,70 @@ func updateRoutersDB(ctx context.Context, app *appTypes.App, routers []appTypes.
 			"routeropts": app.RouterOpts,
 		},
 	})
+	if err != nil {
+		return err
+	}
+	recordConfigSnapshot(ctx, app)
+	return nil
+}
 
-	return err
+// SetDeletionProtection enables or disables the protection of the app
+// against deletion.
+func SetDeletionProtection(ctx context.Context, app *appTypes.App, enabled bool) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"deletionprotection": enabled},
+	})
+	if err != nil {
+		return err
+	}
+	app.DeletionProtection = enabled
+	return nil
+}
+
+// SetRequireApproval enables or disables the approval of deploys to the app
+// by a second user.
+func SetRequireApproval(ctx context.Context, app *appTypes.App, enabled bool) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"requireapproval": enabled},
+	})
+	if err != nil {
+		return err
+	}
+	app.RequireApproval = enabled
+	return nil
+}
+
+// SetServiceAccountAnnotations replaces the cloud IAM annotations of the
+// service account of the app. They're applied to the service account in the
+// next deploy or restart of the app.
+func SetServiceAccountAnnotations(ctx context.Context, app *appTypes.App, annotations map[string]string) error {
+	err := appTypes.ValidateServiceAccountAnnotations(annotations)
+	if err != nil {
+		return err
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"serviceaccountannotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	app.ServiceAccountAnnotations = annotations
+	return nil
 }
 
 func GetRouters(app *appTypes.App) []appTypes.AppRouter {
@@ -2230,6 +2707,31 @@ func Shell(ctx context.Context, app *appTypes.App, opts provision.ExecOptions) e
 	return execProv.ExecuteCommand(ctx, opts)
 }
 
+// PortForward tunnels conn to the port of the app unit, until either side
+// closes the connection.
+func PortForward(ctx context.Context, app *appTypes.App, unit string, port int, conn io.ReadWriter) error {
+	if unit == "" {
+		return &tsuruErrors.ValidationError{Message: "unit is required to forward a port"}
+	}
+	if port < 1 || port > 65535 {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid port %d, it must be between 1 and 65535", port)}
+	}
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return err
+	}
+	pfProv, ok := prov.(provision.PortForwardProvisioner)
+	if !ok {
+		return provision.ProvisionerNotSupported{Prov: prov, Action: "forwarding ports"}
+	}
+	return pfProv.PortForward(ctx, provision.PortForwardOptions{
+		App:  app,
+		Unit: unit,
+		Port: port,
+		Conn: conn,
+	})
+}
+
 func SetCertificate(ctx context.Context, app *appTypes.App, name, certificate, key string) error {
 	err := validateNameForCert(ctx, app, name)
 	if err != nil {
@@ -2568,6 +3070,18 @@ func AutoScaleInfo(ctx context.Context, app *appTypes.App) ([]provTypes.AutoScal
 	return autoscaleProv.GetAutoScale(ctx, app)
 }
 
+func MetricsScrapeInfo(ctx context.Context, app *appTypes.App) (*provTypes.MetricsScrape, error) {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	metricsProv, ok := prov.(provision.MetricsScrapeProvisioner)
+	if !ok {
+		return nil, nil
+	}
+	return metricsProv.MetricsScrape(ctx, app)
+}
+
 func VerticalAutoScaleRecommendations(ctx context.Context, app *appTypes.App) ([]provTypes.RecommendedResources, error) {
 	prov, err := getProvisioner(ctx, app)
 	if err != nil {
@@ -2601,7 +3115,12 @@ func AutoScale(ctx context.Context, app *appTypes.App, spec provTypes.AutoScaleS
 	if !ok {
 		return errors.Errorf("provisioner %q does not support native autoscaling", prov.GetName())
 	}
-	return autoscaleProv.SetAutoScale(ctx, app, spec)
+	err = autoscaleProv.SetAutoScale(ctx, app, spec)
+	if err != nil {
+		return err
+	}
+	recordConfigSnapshot(ctx, app)
+	return nil
 }
 
 func RemoveAutoScale(ctx context.Context, app *appTypes.App, process string) error {
@@ -2613,7 +3132,12 @@ func RemoveAutoScale(ctx context.Context, app *appTypes.App, process string) err
 	if !ok {
 		return errors.Errorf("provisioner %q does not support native autoscaling", prov.GetName())
 	}
-	return autoscaleProv.RemoveAutoScale(ctx, app, process)
+	err = autoscaleProv.RemoveAutoScale(ctx, app, process)
+	if err != nil {
+		return err
+	}
+	recordConfigSnapshot(ctx, app)
+	return nil
 }
 
 func envInSet(envName string, envs []bindTypes.EnvVar) bool {
@@ -2630,6 +3154,13 @@ func GetRegistry(ctx context.Context, app *appTypes.App) (imgTypes.ImageRegistry
 	if err != nil {
 		return "", err
 	}
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, app.TeamOwner)
+	if err != nil {
+		return "", err
+	}
+	if profile != nil && profile.Registry != "" {
+		return imgTypes.ImageRegistry(profile.Registry), nil
+	}
 	registryProv, ok := prov.(provision.MultiRegistryProvisioner)
 	if !ok {
 		return "", nil
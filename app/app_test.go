@@ -51,6 +51,7 @@ import (
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	check "gopkg.in/check.v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func (s *S) TestGetAppByName(c *check.C) {
@@ -114,6 +115,36 @@ func (s *S) TestDelete(c *check.C) {
 	c.Assert(appVersion.Versions, check.DeepEquals, map[int]appTypes.AppVersionInfo{})
 }
 
+func (s *S) TestDeleteWithDeletionProtection(c *check.C) {
+	a := appTypes.App{Name: "ritual", Platform: "ruby", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetDeletionProtection(context.TODO(), &a, true)
+	c.Assert(err, check.IsNil)
+	app, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(app.DeletionProtection, check.Equals, true)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDelete,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Delete(context.TODO(), app, evt, "")
+	c.Assert(err, check.Equals, ErrDeletionProtected)
+	c.Assert(s.provisioner.Provisioned(&a), check.Equals, true)
+	err = SetDeletionProtection(context.TODO(), app, false)
+	c.Assert(err, check.IsNil)
+	app, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(app.DeletionProtection, check.Equals, false)
+	err = Delete(context.TODO(), app, evt, "")
+	c.Assert(err, check.IsNil)
+	_, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
+
 func (s *S) TestDeleteVersion(c *check.C) {
 	a := appTypes.App{
 		Name:      "ritual",
@@ -523,6 +554,36 @@ func (s *S) TestCreateAppWithExplicitPlanConstraint(c *check.C) {
 	c.Assert(err, check.ErrorMatches, `App plan "myplan" is not allowed on pool "pool1"`)
 }
 
+func (s *S) TestCreateAppSchedulingConstraints(c *check.C) {
+	a := appTypes.App{
+		Name:                  "appname",
+		Platform:              "python",
+		TeamOwner:             s.team.Name,
+		SchedulingConstraints: map[string]string{"ssd": "true"},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `pool "pool1" does not satisfy the scheduling constraints: ssd=true`)
+	err = pool.SetPoolLabels(context.TODO(), s.Pool, map[string]string{"ssd": "true"}, nil)
+	c.Assert(err, check.IsNil)
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	retrievedApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(retrievedApp.SchedulingConstraints, check.DeepEquals, map[string]string{"ssd": "true"})
+}
+
+func (s *S) TestCreateAppIsolationProfileSchedulingConstraints(c *check.C) {
+	s.mockService.Team.OnFindIsolationProfile = func(name string) (*authTypes.IsolationProfile, error) {
+		c.Assert(name, check.Equals, s.team.Name)
+		return &authTypes.IsolationProfile{Name: "dedicated", SchedulingConstraints: map[string]string{"dedicated": "true"}}, nil
+	}
+	a := appTypes.App{Name: "appname", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `pool "pool1" does not satisfy the scheduling constraints: dedicated=true`)
+}
+
 func (s *S) TestCreateAppUserQuotaExceeded(c *check.C) {
 	app := appTypes.App{Name: "america", Platform: "python", TeamOwner: s.team.Name}
 
@@ -2609,9 +2670,10 @@ func (s *S) TestAppMarshalJSON(c *check.C) {
 		},
 		"unitsMetrics": []interface{}{
 			map[string]interface{}{
-				"ID":     "name-0",
-				"CPU":    "10m",
-				"Memory": "100Mi",
+				"ID":          "name-0",
+				"ProcessName": "web",
+				"CPU":         "10m",
+				"Memory":      "100Mi",
 			},
 		},
 		"ip": "name.fakerouter.com",
@@ -4442,6 +4504,146 @@ func (s *S) TestAppValidateProcesses(c *check.C) {
 	}
 	err = validateProcesses(&a)
 	c.Assert(err.Error(), check.Equals, "empty process name is not allowed")
+
+	two, half := intstr.FromInt(2), intstr.FromString("50%")
+	a = appTypes.App{
+		Name: "test",
+		Processes: []appTypes.Process{
+			{Name: "web", DisruptionBudget: &provTypes.DisruptionBudget{MinAvailable: &two, MaxUnavailable: &half}},
+		},
+	}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid disruption budget for process \"web\": minAvailable and maxUnavailable can't be set together")
+
+	invalid := intstr.FromString("half")
+	a.Processes[0].DisruptionBudget = &provTypes.DisruptionBudget{MaxUnavailable: &invalid}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid disruption budget for process \"web\": maxUnavailable must be a number of units or a percentage, got \"half\"")
+
+	a.Processes[0].DisruptionBudget = nil
+	a.Processes[0].Tolerations = []appTypes.Toleration{{Key: "spot", Operator: "Exists", Value: "true"}}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid toleration for process \"web\": value must be empty when the operator is Exists")
+
+	a.Processes[0].Tolerations = []appTypes.Toleration{{Key: "spot", Effect: "NoRun"}}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid toleration for process \"web\": invalid effect \"NoRun\", must be one of NoSchedule, PreferNoSchedule, NoExecute")
+
+	a.Processes[0].Tolerations = nil
+	sleep := int64(-1)
+	a.Processes[0].Shutdown = &provTypes.ProcessShutdown{PreStopSleepSeconds: &sleep}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid shutdown for process \"web\": preStopSleepSeconds must not be negative")
+
+	sleep = 5
+	a.Processes[0].Shutdown = &provTypes.ProcessShutdown{PreStopSleepSeconds: &sleep, PreStopCommand: []string{"/bin/drain"}}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid shutdown for process \"web\": preStopCommand and preStopSleepSeconds can't be set together")
+
+	a.Processes[0].Shutdown = nil
+	zero, none := intstr.FromInt(0), intstr.FromString("0%")
+	a.Processes[0].Rollout = &provTypes.ProcessRollout{MaxSurge: &zero, MaxUnavailable: &none}
+	err = validateProcesses(&a)
+	c.Assert(err.Error(), check.Equals, "invalid rollout for process \"web\": maxSurge and maxUnavailable can't both be zero")
+}
+
+func (s *S) TestUpdateProcessesNodeSelectorAndTolerations(c *check.C) {
+	err := pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{
+		PoolExpr: "pool1",
+		Field:    pool.ConstraintTypeToleration,
+		Values:   []string{"node.tsuru.io/*"},
+	})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	spot := []appTypes.Toleration{{Key: "node.tsuru.io/spot", Operator: "Exists", Effect: "NoSchedule"}}
+	updateData := appTypes.App{Name: "example", Processes: []appTypes.Process{
+		{Name: "worker", NodeSelector: map[string]string{"lifecycle": "spot"}, Tolerations: spot},
+	}}
+	err = Update(context.TODO(), &a, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Processes, check.DeepEquals, []appTypes.Process{
+		{Name: "worker", NodeSelector: map[string]string{"lifecycle": "spot"}, Tolerations: spot},
+	})
+
+	updateData = appTypes.App{Name: "example", Processes: []appTypes.Process{
+		{Name: "worker", Tolerations: []appTypes.Toleration{{Key: "dedicated", Value: "db"}}},
+	}}
+	err = Update(context.TODO(), dbApp, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.ErrorMatches, `toleration key "dedicated" of process "worker" is not allowed in pool "pool1"`)
+
+	updateData = appTypes.App{Name: "example", Processes: []appTypes.Process{
+		{Name: "worker", NodeSelector: map[string]string{}, Tolerations: []appTypes.Toleration{}},
+	}}
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	err = Update(context.TODO(), dbApp, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Processes, check.HasLen, 0)
+}
+
+func (s *S) TestUpdateMetadataNotAllowedInPool(c *check.C) {
+	err := pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{
+		PoolExpr:  "pool1",
+		Field:     pool.ConstraintTypeAnnotation,
+		Values:    []string{"sidecar.istio.io/*"},
+		Blacklist: true,
+	})
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	updateData := appTypes.App{Name: "example", Metadata: appTypes.Metadata{
+		Annotations: []appTypes.MetadataItem{{Name: "example.com/owner", Value: "me"}},
+	}}
+	err = Update(context.TODO(), &a, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	updateData = appTypes.App{Name: "example", Processes: []appTypes.Process{
+		{Name: "worker", Metadata: appTypes.Metadata{
+			Annotations: []appTypes.MetadataItem{{Name: "sidecar.istio.io/inject", Value: "false"}},
+		}},
+	}}
+	err = Update(context.TODO(), dbApp, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.ErrorMatches, `annotation "sidecar.istio.io/inject" of process "worker" is not allowed in pool "pool1"`)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Processes, check.HasLen, 0)
+}
+
+func (s *S) TestAppUpdateProcessesDisruptionBudget(c *check.C) {
+	two := intstr.FromInt(2)
+	a := appTypes.App{
+		Name: "test",
+		Processes: []appTypes.Process{
+			{Name: "web", Plan: "c1m1"},
+		},
+	}
+	oldApp := a
+	changed, err := updateProcesses(context.TODO(), &a, []appTypes.Process{
+		{Name: "web", DisruptionBudget: &provTypes.DisruptionBudget{MinAvailable: &two}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(changed, check.Equals, true)
+	c.Assert(a.Processes, check.DeepEquals, []appTypes.Process{
+		{Name: "web", Plan: "c1m1", DisruptionBudget: &provTypes.DisruptionBudget{MinAvailable: &two}},
+	})
+	c.Assert(oldApp.Processes[0].DisruptionBudget, check.IsNil)
+
+	changed, err = updateProcesses(context.TODO(), &a, []appTypes.Process{
+		{Name: "web", DisruptionBudget: &provTypes.DisruptionBudget{}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(changed, check.Equals, true)
+	c.Assert(a.Processes, check.DeepEquals, []appTypes.Process{
+		{Name: "web", Plan: "c1m1"},
+	})
 }
 
 func (s *S) TestAppUpdateProcessesWhenAppend(c *check.C) {
@@ -4910,6 +5112,37 @@ func (s *S) TestShellToUnit(c *check.C) {
 	c.Assert(allExecs[unit.GetID()][0].Cmds, check.DeepEquals, []string{"/bin/sh", "-c", "[ -f /home/application/apprc ] && source /home/application/apprc; [ -d /home/application/current ] && cd /home/application/current; [ $(command -v bash) ] && exec bash -l || exec sh -l"})
 }
 
+func (s *S) TestPortForward(c *check.C) {
+	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("pong"))
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	units, err := s.provisioner.Units(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	buf := safe.NewBuffer([]byte("ping"))
+	conn := &provisiontest.FakeConn{Buf: buf}
+	err = PortForward(context.TODO(), &a, units[0].ID, 5005, conn)
+	c.Assert(err, check.IsNil)
+	forwards := s.provisioner.PortForwards(units[0].ID)
+	c.Assert(forwards, check.HasLen, 1)
+	c.Assert(forwards[0].App.Name, check.Equals, a.Name)
+	c.Assert(forwards[0].Port, check.Equals, 5005)
+	c.Assert(buf.String(), check.Equals, "pingpong")
+}
+
+func (s *S) TestPortForwardInvalid(c *check.C) {
+	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	conn := &provisiontest.FakeConn{Buf: safe.NewBuffer(nil)}
+	err = PortForward(context.TODO(), &a, "", 5005, conn)
+	c.Assert(err, check.ErrorMatches, "unit is required to forward a port")
+	err = PortForward(context.TODO(), &a, "my-test-app-web-1", 70000, conn)
+	c.Assert(err, check.ErrorMatches, "invalid port 70000, it must be between 1 and 65535")
+	c.Assert(s.provisioner.PortForwards("my-test-app-web-1"), check.HasLen, 0)
+}
+
 func (s *S) TestShellNoUnits(c *check.C) {
 	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
 	err := CreateApp(context.TODO(), &a, s.user)
@@ -5186,6 +5419,107 @@ func (s *S) TestUpdateDescription(c *check.C) {
 	c.Assert(dbApp.Description, check.Equals, "bleble")
 }
 
+func (s *S) TestUpdateSecurityContext(c *check.C) {
+	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	readOnly := true
+	securityContext := &appTypes.SecurityContext{ReadOnlyRootFilesystem: &readOnly, SeccompProfile: "Localhost/profiles/app.json"}
+	updateData := appTypes.App{Name: "example", SecurityContext: securityContext}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.SecurityContext, check.DeepEquals, securityContext)
+	fsGroup := int64(-1)
+	updateData = appTypes.App{Name: "example", SecurityContext: &appTypes.SecurityContext{FSGroup: &fsGroup}}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.ErrorMatches, `invalid fsGroup -1, must be positive`)
+	updateData = appTypes.App{Name: "example", SecurityContext: &appTypes.SecurityContext{}}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.SecurityContext, check.IsNil)
+}
+
+func (s *S) TestUpdateSpread(c *check.C) {
+	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	spread := &appTypes.Spread{
+		AntiAffinity: []appTypes.AntiAffinityRule{{TopologyKey: "kubernetes.io/hostname", Required: true}},
+	}
+	updateData := appTypes.App{Name: "example", Spread: spread}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Spread, check.DeepEquals, spread)
+	updateData = appTypes.App{Name: "example", Spread: &appTypes.Spread{
+		TopologySpreadConstraints: []appTypes.TopologySpreadConstraint{{TopologyKey: ""}},
+	}}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.ErrorMatches, `topologyKey is required in each topology spread constraint`)
+	updateData = appTypes.App{Name: "example", Spread: &appTypes.Spread{}}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Spread, check.IsNil)
+}
+
+func (s *S) TestUpdateInitContainers(c *check.C) {
+	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name, InitContainers: []appTypes.InitContainer{
+		{Name: "migrate", Command: []string{"./migrate.sh"}},
+		{Name: "wait-db", Image: "busybox", Command: []string{"./wait.sh"}},
+	}}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	updateData := appTypes.App{Name: "example", InitContainers: []appTypes.InitContainer{
+		{Name: "migrate", Command: []string{"./migrate.sh", "--check"}},
+		{Name: "wait-db", Delete: true},
+		{Name: "fetch-assets", Image: "curlimages/curl"},
+	}}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.InitContainers, check.DeepEquals, []appTypes.InitContainer{
+		{Name: "migrate", Command: []string{"./migrate.sh", "--check"}},
+		{Name: "fetch-assets", Image: "curlimages/curl"},
+	})
+}
+
+func (s *S) TestUpdateInitContainersInvalid(c *check.C) {
+	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		containers []appTypes.InitContainer
+		expected   string
+	}{
+		{
+			containers: []appTypes.InitContainer{{Name: "Migrate", Command: []string{"./migrate.sh"}}},
+			expected:   `invalid init container name "Migrate", .*`,
+		},
+		{
+			containers: []appTypes.InitContainer{{Name: "migrate"}},
+			expected:   `init container "migrate" must have a command when running the image of the app`,
+		},
+		{
+			containers: []appTypes.InitContainer{{Name: "migrate", Image: "busybox", Env: map[string]string{"": "x"}}},
+			expected:   `empty env name in init container "migrate"`,
+		},
+	}
+	for _, tt := range tests {
+		updateData := appTypes.App{Name: "example", InitContainers: tt.containers}
+		err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+		c.Assert(err, check.ErrorMatches, tt.expected)
+		app.InitContainers = nil
+	}
+}
+
 func (s *S) TestUpdateAppPlatform(c *check.C) {
 	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
 	err := CreateApp(context.TODO(), &app, s.user)
@@ -5561,6 +5895,18 @@ func (s *S) TestUpdateIgnoresEmptyAndDuplicatedTags(c *check.C) {
 	c.Assert(dbApp.Tags, check.DeepEquals, []string{"tag2", "tag3"})
 }
 
+func (s *S) TestUpdateTagsWithSameKey(c *check.C) {
+	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name, Tags: []string{"env:dev"}}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	updateData := appTypes.App{Tags: []string{"env:dev", "env:prod"}}
+	err = Update(context.TODO(), &app, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.ErrorMatches, `tags "env:dev" and "env:prod" have the same key, each key must be used at most once`)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Tags, check.DeepEquals, []string{"env:dev"})
+}
+
 func (s *S) TestUpdatePlatform(c *check.C) {
 	app := appTypes.App{Name: "example", Platform: "python", TeamOwner: s.team.Name, Description: "blabla"}
 	err := CreateApp(context.TODO(), &app, s.user)
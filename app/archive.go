@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/router"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	ErrAppArchived        = errors.New("app is archived, unarchive it before deploying")
+	ErrAppAlreadyArchived = errors.New("app is already archived")
+	ErrAppNotArchived     = errors.New("app is not archived")
+)
+
+// ArchiveArgs holds who is archiving an app and why.
+type ArchiveArgs struct {
+	Owner  string
+	Reason string
+	Writer io.Writer
+}
+
+// Archive takes a configuration snapshot of the app, stops all its units and
+// removes its backends from the routers, keeping everything else so that it
+// can be unarchived later. Deploys to the app are refused while it's
+// archived.
+func Archive(ctx context.Context, app *appTypes.App, args ArchiveArgs) error {
+	if app.Archive != nil {
+		return ErrAppAlreadyArchived
+	}
+	w := args.Writer
+	if w == nil {
+		w = io.Discard
+	}
+	fmt.Fprintf(w, "---- Archiving app %q ----\n", app.Name)
+	snapshotID, err := saveConfigSnapshot(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = Stop(ctx, app, w, "", "")
+	if err != nil {
+		return err
+	}
+	for _, appRouter := range GetRouters(app) {
+		fmt.Fprintf(w, " ---> Removing backend from router %s\n", appRouter.Name)
+		var r router.Router
+		r, err = router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return err
+		}
+		err = r.RemoveBackend(ctx, app)
+		if err != nil && err != router.ErrBackendNotFound {
+			return err
+		}
+	}
+	archive := &appTypes.AppArchive{
+		ArchivedAt:     time.Now().UTC(),
+		ArchivedBy:     args.Owner,
+		Reason:         args.Reason,
+		ConfigSnapshot: snapshotID,
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"archive": archive},
+	})
+	if err != nil {
+		return err
+	}
+	app.Archive = archive
+	return nil
+}
+
+// Unarchive starts the units of an archived app again, recreating its
+// router backends, and allows deploys to it.
+func Unarchive(ctx context.Context, app *appTypes.App, w io.Writer) error {
+	if app.Archive == nil {
+		return ErrAppNotArchived
+	}
+	if w == nil {
+		w = io.Discard
+	}
+	fmt.Fprintf(w, "---- Unarchiving app %q ----\n", app.Name)
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$unset": mongoBSON.M{"archive": ""},
+	})
+	if err != nil {
+		return err
+	}
+	app.Archive = nil
+	return Start(ctx, app, w, "", "")
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestArchiveAndUnarchive(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = AddUnits(context.TODO(), &a, 2, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.HasBackend(a.Name), check.Equals, true)
+	var buf bytes.Buffer
+	err = Archive(context.TODO(), &a, ArchiveArgs{Owner: s.user.Email, Reason: "dormant", Writer: &buf})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)---- Archiving app "myapp" ----.*Removing backend from router fake.*`)
+	c.Assert(routertest.FakeRouter.HasBackend(a.Name), check.Equals, false)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	for _, u := range units {
+		c.Assert(u.Status, check.Equals, provTypes.UnitStatusStopped)
+	}
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Archive, check.NotNil)
+	c.Assert(dbApp.Archive.ArchivedBy, check.Equals, s.user.Email)
+	c.Assert(dbApp.Archive.Reason, check.Equals, "dormant")
+	_, err = GetConfigSnapshot(context.TODO(), dbApp, dbApp.Archive.ConfigSnapshot)
+	c.Assert(err, check.IsNil)
+	err = Archive(context.TODO(), dbApp, ArchiveArgs{})
+	c.Assert(err, check.Equals, ErrAppAlreadyArchived)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(context.TODO(), DeployOptions{App: dbApp, Event: evt})
+	c.Assert(err, check.Equals, ErrAppArchived)
+
+	err = evt.Done(context.TODO(), err)
+	c.Assert(err, check.IsNil)
+	err = Unarchive(context.TODO(), dbApp, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.HasBackend(a.Name), check.Equals, true)
+	units, err = AppUnits(context.TODO(), dbApp)
+	c.Assert(err, check.IsNil)
+	for _, u := range units {
+		c.Assert(u.Status, check.Equals, provTypes.UnitStatusStarted)
+	}
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Archive, check.IsNil)
+	err = Unarchive(context.TODO(), dbApp, nil)
+	c.Assert(err, check.Equals, ErrAppNotArchived)
+}
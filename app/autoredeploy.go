@@ -0,0 +1,426 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	autoRedeployCollectionName = "autoredeploys"
+
+	AutoRedeployEventKind = "autoredeploy"
+
+	defaultAutoRedeployInterval    = 10 * time.Minute
+	defaultAutoRedeployMaxPerRun   = 5
+	defaultAutoRedeployMaxAttempts = 3
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// PendingRebuild is an app opted in to autoredeploy waiting to be rebuilt
+// with the updated image of its platform.
+type PendingRebuild struct {
+	App             string    `json:"app"`
+	Platform        string    `json:"platform"`
+	PlatformVersion string    `json:"platformVersion"`
+	Reason          string    `json:"reason"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Attempts        int       `json:"attempts"`
+	LastError       string    `json:"lastError,omitempty"`
+}
+
+// freezeWindow is a daily time range, in UTC, in which apps are not
+// redeployed automatically. A window ending before its start crosses
+// midnight, and without days it applies to every day of the week.
+type freezeWindow struct {
+	days       map[time.Weekday]bool
+	start, end time.Duration
+}
+
+func (w freezeWindow) contains(t time.Time) bool {
+	t = t.UTC()
+	day := t.Weekday()
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.start <= w.end {
+		return w.appliesTo(day) && sinceMidnight >= w.start && sinceMidnight < w.end
+	}
+	if sinceMidnight >= w.start {
+		return w.appliesTo(day)
+	}
+	return sinceMidnight < w.end && w.appliesTo((day+6)%7)
+}
+
+func (w freezeWindow) appliesTo(day time.Weekday) bool {
+	return len(w.days) == 0 || w.days[day]
+}
+
+func parseClock(value interface{}) (time.Duration, error) {
+	str, _ := value.(string)
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func autoRedeployFreezeWindows() ([]freezeWindow, error) {
+	data, err := config.Get("autoredeploy:freeze-windows")
+	if err != nil {
+		return nil, nil
+	}
+	rawWindows, ok := data.([]interface{})
+	if !ok {
+		return nil, errors.New("autoredeploy:freeze-windows must be a list")
+	}
+	var windows []freezeWindow
+	for _, rawWindow := range rawWindows {
+		entry, ok := rawWindow.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.New("invalid autoredeploy freeze window, expected start and end")
+		}
+		var w freezeWindow
+		if w.start, err = parseClock(entry["start"]); err != nil {
+			return nil, err
+		}
+		if w.end, err = parseClock(entry["end"]); err != nil {
+			return nil, err
+		}
+		days, _ := entry["days"].([]interface{})
+		for _, d := range days {
+			name, _ := d.(string)
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, errors.Errorf("invalid day %q in autoredeploy freeze window", d)
+			}
+			if w.days == nil {
+				w.days = map[time.Weekday]bool{}
+			}
+			w.days[day] = true
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// SetAutoRedeploy enables or disables the automatic rebuild of the app when
+// the image of its platform is updated.
+func SetAutoRedeploy(ctx context.Context, app *appTypes.App, enabled bool) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"autoredeploy": enabled},
+	})
+	if err != nil {
+		return err
+	}
+	app.AutoRedeploy = enabled
+	if enabled {
+		return nil
+	}
+	return removePendingRebuild(ctx, app.Name)
+}
+
+// NotifyPlatformImageUpdate records a rebuild for every app opted in to
+// autoredeploy using the given version of the platform, "latest" standing
+// for the apps not pinned to a version. It's used when an image is updated
+// outside tsuru, like a security fix pushed to the image of a pinned version.
+func NotifyPlatformImageUpdate(ctx context.Context, platform, version, reason string) ([]PendingRebuild, error) {
+	if _, err := servicemanager.Platform.FindByName(ctx, platform); err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = "latest"
+	}
+	if reason == "" {
+		reason = fmt.Sprintf("image of platform %s version %s updated", platform, version)
+	}
+	return enqueueAutoRedeploys(ctx, platform, version, reason)
+}
+
+func enqueueAutoRedeploys(ctx context.Context, platform, version, reason string) ([]PendingRebuild, error) {
+	appsCollection, err := storagev2.AppsCollection()
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{"", "latest"}
+	if version != "latest" {
+		versions = []string{"v" + strings.TrimPrefix(version, "v"), strings.TrimPrefix(version, "v")}
+	}
+	query := mongoBSON.M{"framework": platform, "autoredeploy": true, "platformversion": mongoBSON.M{"$in": versions}}
+	var apps []appTypes.App
+	cursor, err := appsCollection.Find(ctx, query, options.Find().SetProjection(mongoBSON.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &apps); err != nil {
+		return nil, err
+	}
+	// Pinned versions aren't rebuilt by platform updates, the flag makes the
+	// rebuild use the updated platform image instead of the last build.
+	_, err = appsCollection.UpdateMany(ctx, query, mongoBSON.M{"$set": mongoBSON.M{"updateplatform": true}})
+	if err != nil {
+		return nil, err
+	}
+	collection, err := storagev2.Collection(autoRedeployCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	pending := make([]PendingRebuild, 0, len(apps))
+	for _, a := range apps {
+		p := PendingRebuild{App: a.Name, Platform: platform, PlatformVersion: version, Reason: reason, CreatedAt: now}
+		_, err = collection.UpdateOne(ctx, mongoBSON.M{"app": a.Name}, mongoBSON.M{
+			"$set": mongoBSON.M{
+				"platform":        p.Platform,
+				"platformversion": p.PlatformVersion,
+				"reason":          p.Reason,
+				"attempts":        0,
+				"lasterror":       "",
+			},
+			"$setOnInsert": mongoBSON.M{"createdat": p.CreatedAt},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, nil
+}
+
+// enqueuePlatformUpdate is called after a new image of the platform becomes
+// the current one. Failures are only logged as the platform is already
+// updated.
+func enqueuePlatformUpdate(ctx context.Context, platform string, version int) {
+	reason := fmt.Sprintf("platform %s updated to version %d", platform, version)
+	if _, err := enqueueAutoRedeploys(ctx, platform, "latest", reason); err != nil {
+		log.Errorf("[autoredeploy] unable to enqueue rebuilds for platform %s: %v", platform, err)
+	}
+}
+
+// ListPendingRebuilds returns the rebuilds waiting to run, oldest first.
+func ListPendingRebuilds(ctx context.Context) ([]PendingRebuild, error) {
+	collection, err := storagev2.Collection(autoRedeployCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{}, options.Find().SetSort(mongoBSON.M{"createdat": 1}))
+	if err != nil {
+		return nil, err
+	}
+	pending := []PendingRebuild{}
+	err = cursor.All(ctx, &pending)
+	return pending, err
+}
+
+func removePendingRebuild(ctx context.Context, appName string) error {
+	collection, err := storagev2.Collection(autoRedeployCollectionName)
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"app": appName})
+	return err
+}
+
+func failPendingRebuild(ctx context.Context, appName string, rebuildErr error) error {
+	collection, err := storagev2.Collection(autoRedeployCollectionName)
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"app": appName}, mongoBSON.M{
+		"$set": mongoBSON.M{"lasterror": rebuildErr.Error()},
+		"$inc": mongoBSON.M{"attempts": 1},
+	})
+	return err
+}
+
+type autoRedeployer struct {
+	interval    time.Duration
+	maxRuns     int
+	maxAttempts int
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+}
+
+// StartAutoRedeployer starts a background loop rebuilding the apps with
+// pending rebuilds, at most autoredeploy:max-per-run apps each run and none
+// inside the configured freeze windows. It's only started when
+// autoredeploy:enabled is set.
+func StartAutoRedeployer() error {
+	enabled, _ := config.GetBool("autoredeploy:enabled")
+	if !enabled {
+		return nil
+	}
+	if _, err := autoRedeployFreezeWindows(); err != nil {
+		return err
+	}
+	interval, _ := config.GetDuration("autoredeploy:interval")
+	if interval <= 0 {
+		interval = defaultAutoRedeployInterval
+	}
+	maxRuns, _ := config.GetInt("autoredeploy:max-per-run")
+	if maxRuns <= 0 {
+		maxRuns = defaultAutoRedeployMaxPerRun
+	}
+	maxAttempts, _ := config.GetInt("autoredeploy:max-attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = defaultAutoRedeployMaxAttempts
+	}
+	r := &autoRedeployer{
+		interval:    interval,
+		maxRuns:     maxRuns,
+		maxAttempts: maxAttempts,
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+	go r.spin()
+	shutdown.Register(r)
+	return nil
+}
+
+func (r *autoRedeployer) spin() {
+	defer close(r.doneCh)
+	for {
+		err := runAutoRedeploy(context.Background(), time.Now(), r.maxRuns, r.maxAttempts)
+		if err != nil {
+			log.Errorf("[autoredeploy] %v", err)
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+func (r *autoRedeployer) Shutdown(ctx context.Context) error {
+	close(r.stopCh)
+	select {
+	case <-r.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// runAutoRedeploy rebuilds up to maxRuns apps with pending rebuilds. Apps
+// removed, opted out or already rebuilt by a deploy since the update leave
+// the queue without a rebuild. Apps requiring deploy approval, and rebuilds
+// that failed maxAttempts times, are kept in the queue waiting for a manual
+// deploy.
+func runAutoRedeploy(ctx context.Context, now time.Time, maxRuns, maxAttempts int) error {
+	windows, err := autoRedeployFreezeWindows()
+	if err != nil {
+		return err
+	}
+	for _, w := range windows {
+		if w.contains(now) {
+			return nil
+		}
+	}
+	pending, err := ListPendingRebuilds(ctx)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	runs := 0
+	for _, p := range pending {
+		if runs >= maxRuns {
+			break
+		}
+		if p.Attempts >= maxAttempts {
+			continue
+		}
+		a, err := GetByName(ctx, p.App)
+		if err == appTypes.ErrAppNotFound {
+			err = removePendingRebuild(ctx, p.App)
+		} else if err == nil {
+			var ran bool
+			ran, err = autoRedeployApp(ctx, a)
+			if ran {
+				runs++
+			}
+		}
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to redeploy app %q", p.App))
+		}
+	}
+	return multi.ToError()
+}
+
+// autoRedeployApp rebuilds the app in an autoredeploy event, reporting
+// whether a rebuild was started. Apps locked by other operations are
+// retried in the next run.
+func autoRedeployApp(ctx context.Context, a *appTypes.App) (bool, error) {
+	if !a.AutoRedeploy || !a.UpdatePlatform || a.Archive != nil {
+		return false, removePendingRebuild(ctx, a.Name)
+	}
+	if a.RequireApproval {
+		return false, failPendingRebuild(ctx, a.Name, errors.New("app requires deploy approval, waiting for a manual deploy"))
+	}
+	opts := DeployOptions{
+		App:          a,
+		OutputStream: io.Discard,
+		Origin:       "rebuild",
+		Kind:         provisionTypes.DeployRebuild,
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:        eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind:  AutoRedeployEventKind,
+		CustomData:    opts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, a.Name)),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, permission.Context(permTypes.CtxApp, a.Name)),
+		Cancelable:    true,
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	var imageID string
+	defer func() { evt.DoneCustomData(ctx, err, map[string]string{"image": imageID}) }()
+	deployCtx, cancel := evt.CancelableContext(ctx)
+	defer cancel()
+	opts.Event = evt
+	imageID, err = Deploy(deployCtx, opts)
+	if err != nil {
+		if failErr := failPendingRebuild(ctx, a.Name, err); failErr != nil {
+			log.Errorf("[autoredeploy] unable to record failed rebuild of app %s: %v", a.Name, failErr)
+		}
+		return true, err
+	}
+	return true, removePendingRebuild(ctx, a.Name)
+}
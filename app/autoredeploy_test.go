@@ -0,0 +1,121 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestFreezeWindowContains(c *check.C) {
+	nightly := freezeWindow{start: 22 * time.Hour, end: 6 * time.Hour}
+	weekend := freezeWindow{start: 0, end: 24*time.Hour - time.Minute, days: map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}}
+	fridayNight := freezeWindow{start: 18 * time.Hour, end: 8 * time.Hour, days: map[time.Weekday]bool{time.Friday: true}}
+	// 2026-10-16 is a Friday.
+	friday := func(hour int) time.Time { return time.Date(2026, 10, 16, hour, 0, 0, 0, time.UTC) }
+	c.Assert(nightly.contains(friday(23)), check.Equals, true)
+	c.Assert(nightly.contains(friday(5)), check.Equals, true)
+	c.Assert(nightly.contains(friday(12)), check.Equals, false)
+	c.Assert(weekend.contains(friday(12)), check.Equals, false)
+	c.Assert(weekend.contains(friday(12).AddDate(0, 0, 1)), check.Equals, true)
+	c.Assert(fridayNight.contains(friday(19)), check.Equals, true)
+	c.Assert(fridayNight.contains(friday(7)), check.Equals, false)
+	c.Assert(fridayNight.contains(friday(7).AddDate(0, 0, 1)), check.Equals, true)
+	c.Assert(fridayNight.contains(friday(9).AddDate(0, 0, 1)), check.Equals, false)
+}
+
+func (s *S) TestAutoRedeployFreezeWindowsConfig(c *check.C) {
+	config.Set("autoredeploy:freeze-windows", []interface{}{
+		map[interface{}]interface{}{"start": "22:00", "end": "06:00", "days": []interface{}{"Fri"}},
+	})
+	defer config.Unset("autoredeploy:freeze-windows")
+	windows, err := autoRedeployFreezeWindows()
+	c.Assert(err, check.IsNil)
+	c.Assert(windows, check.DeepEquals, []freezeWindow{
+		{start: 22 * time.Hour, end: 6 * time.Hour, days: map[time.Weekday]bool{time.Friday: true}},
+	})
+	config.Set("autoredeploy:freeze-windows", []interface{}{
+		map[interface{}]interface{}{"start": "22h", "end": "06:00"},
+	})
+	_, err = autoRedeployFreezeWindows()
+	c.Assert(err, check.ErrorMatches, `invalid time "22h", expected HH:MM`)
+}
+
+func (s *S) TestNotifyPlatformImageUpdate(c *check.C) {
+	latest := appTypes.App{Name: "latest-app", Platform: "zend", TeamOwner: s.team.Name}
+	pinned := appTypes.App{Name: "pinned-app", Platform: "zend", TeamOwner: s.team.Name}
+	optedOut := appTypes.App{Name: "opted-out-app", Platform: "zend", TeamOwner: s.team.Name}
+	for _, a := range []*appTypes.App{&latest, &pinned, &optedOut} {
+		err := CreateApp(context.TODO(), a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(SetAutoRedeploy(context.TODO(), &latest, true), check.IsNil)
+	c.Assert(SetAutoRedeploy(context.TODO(), &pinned, true), check.IsNil)
+	collection, err := storagev2.AppsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"name": pinned.Name}, mongoBSON.M{"$set": mongoBSON.M{"platformversion": "v2"}})
+	c.Assert(err, check.IsNil)
+	pending, err := NotifyPlatformImageUpdate(context.TODO(), "zend", "", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].App, check.Equals, "latest-app")
+	c.Assert(pending[0].Reason, check.Equals, "image of platform zend version latest updated")
+	pending, err = NotifyPlatformImageUpdate(context.TODO(), "zend", "2", "CVE-2026-1234")
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].App, check.Equals, "pinned-app")
+	dbApp, err := GetByName(context.TODO(), pinned.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.UpdatePlatform, check.Equals, true)
+	pending, err = ListPendingRebuilds(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 2)
+	c.Assert(SetAutoRedeploy(context.TODO(), &latest, false), check.IsNil)
+	pending, err = ListPendingRebuilds(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].App, check.Equals, "pinned-app")
+	c.Assert(pending[0].Reason, check.Equals, "CVE-2026-1234")
+}
+
+func (s *S) TestRunAutoRedeploy(c *check.C) {
+	a := appTypes.App{Name: "my-app", Platform: "zend", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	c.Assert(SetAutoRedeploy(context.TODO(), &a, true), check.IsNil)
+	_, err = NotifyPlatformImageUpdate(context.TODO(), "zend", "latest", "")
+	c.Assert(err, check.IsNil)
+	config.Set("autoredeploy:freeze-windows", []interface{}{
+		map[interface{}]interface{}{"start": "00:00", "end": "12:00"},
+	})
+	defer config.Unset("autoredeploy:freeze-windows")
+	frozen := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	err = runAutoRedeploy(context.TODO(), frozen, 1, 3)
+	c.Assert(err, check.IsNil)
+	pending, err := ListPendingRebuilds(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	err = runAutoRedeploy(context.TODO(), frozen.Add(12*time.Hour), 1, 3)
+	c.Assert(err, check.IsNil)
+	pending, err = ListPendingRebuilds(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 0)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{AutoRedeployEventKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, "")
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/types/quota"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const BudgetExceededEventKind = "budget exceeded"
+
+// SetBudget replaces the budget of the app. A nil budget removes it.
+func SetBudget(ctx context.Context, app *appTypes.App, budget *quota.Budget) error {
+	err := budget.Validate()
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	update := mongoBSON.M{"$set": mongoBSON.M{"budget": budget}}
+	if budget == nil {
+		update = mongoBSON.M{"$unset": mongoBSON.M{"budget": ""}}
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Budget = budget
+	return nil
+}
+
+// AppBudgetStatus projects the monthly cost of the current units of the app.
+func AppBudgetStatus(ctx context.Context, app *appTypes.App) (*quota.BudgetStatus, error) {
+	units, cost, err := projectedCost(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	return &quota.BudgetStatus{Budget: app.Budget, Units: units, ProjectedCost: cost}, nil
+}
+
+// TeamBudgetStatus projects the monthly cost of the current units of all apps
+// owned by the team.
+func TeamBudgetStatus(ctx context.Context, team *authTypes.Team) (*quota.BudgetStatus, error) {
+	apps, err := List(ctx, &Filter{TeamOwner: team.Name})
+	if err != nil {
+		return nil, err
+	}
+	status := &quota.BudgetStatus{Budget: team.Budget}
+	for _, a := range apps {
+		units, cost, err := projectedCost(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		status.Units += units
+		status.ProjectedCost += cost
+	}
+	return status, nil
+}
+
+func projectedCost(ctx context.Context, app *appTypes.App) (int, float64, error) {
+	hourlyCost, err := planHourlyCost(ctx, app)
+	if err != nil {
+		return 0, 0, err
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(units), float64(len(units)) * hourlyCost * quota.HoursPerMonth, nil
+}
+
+// planHourlyCost prefers the current cost of the plan over the one copied to
+// the app when the plan was assigned, as prices may change afterwards.
+func planHourlyCost(ctx context.Context, app *appTypes.App) (float64, error) {
+	plan, err := servicemanager.Plan.FindByName(ctx, app.Plan.Name)
+	if err == appTypes.ErrPlanNotFound {
+		return app.Plan.HourlyCost, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return plan.HourlyCost, nil
+}
+
+// checkBudget projects the monthly cost of the app and of its team owner with
+// n more units. Exceeding an enforced budget refuses the scale-up, exceeding
+// any other only writes a warning. Both emit a budget exceeded event.
+func checkBudget(ctx context.Context, app *appTypes.App, n uint, w io.Writer) error {
+	hourlyCost, err := planHourlyCost(ctx, app)
+	if err != nil || hourlyCost == 0 {
+		return err
+	}
+	added := float64(n) * hourlyCost * quota.HoursPerMonth
+	if app.Budget != nil {
+		status, err := AppBudgetStatus(ctx, app)
+		if err != nil {
+			return err
+		}
+		status.ProjectedCost += added
+		err = budgetExceeded(ctx, app, "app "+app.Name, status, w)
+		if err != nil {
+			return err
+		}
+	}
+	team, err := servicemanager.Team.FindByName(ctx, app.TeamOwner)
+	if err == authTypes.ErrTeamNotFound {
+		return nil
+	}
+	if err != nil || team.Budget == nil {
+		return err
+	}
+	status, err := TeamBudgetStatus(ctx, team)
+	if err != nil {
+		return err
+	}
+	status.ProjectedCost += added
+	return budgetExceeded(ctx, app, "team "+team.Name, status, w)
+}
+
+func budgetExceeded(ctx context.Context, app *appTypes.App, target string, status *quota.BudgetStatus, w io.Writer) error {
+	if !status.Exceeded() {
+		return nil
+	}
+	exceededErr := &quota.BudgetExceededError{
+		Target:    target,
+		Limit:     status.Budget.MonthlyLimit,
+		Projected: status.ProjectedCost,
+	}
+	if err := notifyBudgetExceeded(ctx, app.Name, exceededErr); err != nil {
+		log.Errorf("[budget] unable to notify budget exceeded for app %q: %v", app.Name, err)
+	}
+	if status.Budget.Enforce {
+		return exceededErr
+	}
+	fmt.Fprintf(w, "WARNING: %s\n", exceededErr)
+	return nil
+}
+
+func notifyBudgetExceeded(ctx context.Context, appName string, exceededErr *quota.BudgetExceededError) error {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: appName},
+		InternalKind: BudgetExceededEventKind,
+		DisableLock:  true,
+		CustomData:   exceededErr,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, appName)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to create budget exceeded event")
+	}
+	return evt.Done(ctx, exceededErr)
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	"github.com/tsuru/tsuru/types/quota"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) setPlanHourlyCost(cost float64) {
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		plan := s.defaultPlan
+		plan.HourlyCost = cost
+		return &plan, nil
+	}
+}
+
+func (s *S) TestSetBudget(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetBudget(context.TODO(), &a, &quota.Budget{MonthlyLimit: 100, Enforce: true})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Budget, check.DeepEquals, &quota.Budget{MonthlyLimit: 100, Enforce: true})
+	err = SetBudget(context.TODO(), &a, &quota.Budget{MonthlyLimit: -1})
+	c.Assert(err, check.ErrorMatches, "budget monthly limit must be greater than zero")
+	err = SetBudget(context.TODO(), &a, nil)
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Budget, check.IsNil)
+}
+
+func (s *S) TestAddUnitsBudgetEnforced(c *check.C) {
+	s.setPlanHourlyCost(0.5)
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = SetBudget(context.TODO(), &a, &quota.Budget{MonthlyLimit: 500, Enforce: true})
+	c.Assert(err, check.IsNil)
+	err = AddUnits(context.TODO(), &a, 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = AddUnits(context.TODO(), &a, 1, "web", "", nil)
+	c.Assert(err, check.FitsTypeOf, &quota.BudgetExceededError{})
+	c.Assert(err, check.ErrorMatches, `projected monthly cost of app myapp \(730.00\) exceeds its budget of 500.00`)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 1)
+	status, err := AppBudgetStatus(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Units, check.Equals, 1)
+	c.Assert(status.ProjectedCost, check.Equals, 365.0)
+	c.Assert(status.Exceeded(), check.Equals, false)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{BudgetExceededEventKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, "projected monthly cost of app myapp (730.00) exceeds its budget of 500.00")
+}
+
+func (s *S) TestAddUnitsBudgetWarning(c *check.C) {
+	s.setPlanHourlyCost(0.5)
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = SetBudget(context.TODO(), &a, &quota.Budget{MonthlyLimit: 500})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = AddUnits(context.TODO(), &a, 2, "web", "", &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)WARNING: projected monthly cost of app myapp \(730.00\) exceeds its budget of 500.00\n.*`)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+}
+
+func (s *S) TestAddUnitsTeamBudget(c *check.C) {
+	s.setPlanHourlyCost(0.5)
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Budget: &quota.Budget{MonthlyLimit: 1000, Enforce: true}}, nil
+	}
+	a1 := appTypes.App{Name: "myapp1", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a1, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a1)
+	a2 := appTypes.App{Name: "myapp2", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err = CreateApp(context.TODO(), &a2, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a2)
+	err = AddUnits(context.TODO(), &a1, 2, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = AddUnits(context.TODO(), &a2, 1, "web", "", nil)
+	c.Assert(err, check.ErrorMatches, `projected monthly cost of team tsuruteam \(1095.00\) exceeds its budget of 1000.00`)
+	team, err := servicemanager.Team.FindByName(context.TODO(), s.team.Name)
+	c.Assert(err, check.IsNil)
+	status, err := TeamBudgetStatus(context.TODO(), team)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Units, check.Equals, 2)
+	c.Assert(status.ProjectedCost, check.Equals, 730.0)
+}
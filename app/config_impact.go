@@ -0,0 +1,249 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+const (
+	ImpactKindApp = "app"
+	ImpactKindJob = "job"
+
+	// clusterNamespaceKey is the custom data key holding the namespace, or
+	// the prefix of pool namespaces, used by kubernetes clusters.
+	clusterNamespaceKey = "namespace"
+)
+
+// ConfigImpact is an app or job that would become non-compliant, or would
+// be rescheduled, by a pool or cluster configuration change.
+type ConfigImpact struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Pool   string `json:"pool"`
+	Reason string `json:"reason"`
+}
+
+// PoolConstraintImpact reports the apps and jobs using values that would no
+// longer be allowed in their pools after setting the constraint, or
+// appending its values when appendValues is true.
+func PoolConstraintImpact(ctx context.Context, c *pool.PoolConstraint, appendValues bool) ([]ConfigImpact, error) {
+	constraints, err := pool.SimulateConstraint(ctx, c, appendValues)
+	if err != nil {
+		return nil, err
+	}
+	pools := make([]string, 0, len(constraints))
+	for poolName := range constraints {
+		pools = append(pools, poolName)
+	}
+	sort.Strings(pools)
+	impacts := []ConfigImpact{}
+	for _, poolName := range pools {
+		constraint := constraints[poolName]
+		apps, err := List(ctx, &Filter{Pool: poolName})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+		for _, a := range apps {
+			reasons, err := appConstraintViolations(ctx, a, constraint)
+			if err != nil {
+				return nil, err
+			}
+			for _, reason := range reasons {
+				impacts = append(impacts, ConfigImpact{Kind: ImpactKindApp, Name: a.Name, Pool: poolName, Reason: reason})
+			}
+		}
+		jobs, err := servicemanager.Job.List(ctx, &jobTypes.Filter{Pool: poolName})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+		for i := range jobs {
+			reasons, err := jobConstraintViolations(ctx, &jobs[i], constraint)
+			if err != nil {
+				return nil, err
+			}
+			for _, reason := range reasons {
+				impacts = append(impacts, ConfigImpact{Kind: ImpactKindJob, Name: jobs[i].Name, Pool: poolName, Reason: reason})
+			}
+		}
+	}
+	return impacts, nil
+}
+
+func constraintViolation(field pool.PoolConstraintType, value string) string {
+	return fmt.Sprintf("%s %q would not be allowed in the pool", field, value)
+}
+
+func appConstraintViolations(ctx context.Context, a *appTypes.App, c *pool.PoolConstraint) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var values []string
+	switch c.Field {
+	case pool.ConstraintTypeTeam:
+		values = []string{a.TeamOwner}
+	case pool.ConstraintTypePlan:
+		values = []string{a.Plan.Name}
+	case pool.ConstraintTypeRouter:
+		for _, r := range a.Routers {
+			values = append(values, r.Name)
+		}
+	case pool.ConstraintTypeService:
+		instances, err := service.GetServiceInstancesBoundToApp(ctx, a.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, si := range instances {
+			values = append(values, si.ServiceName)
+		}
+	}
+	return violations(c, values), nil
+}
+
+func jobConstraintViolations(ctx context.Context, j *jobTypes.Job, c *pool.PoolConstraint) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var values []string
+	switch c.Field {
+	case pool.ConstraintTypeTeam:
+		values = []string{j.TeamOwner}
+	case pool.ConstraintTypePlan:
+		values = []string{j.Plan.Name}
+	case pool.ConstraintTypeService:
+		instances, err := service.GetServiceInstancesBoundToJob(ctx, j.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, si := range instances {
+			values = append(values, si.ServiceName)
+		}
+	}
+	return violations(c, values), nil
+}
+
+func violations(c *pool.PoolConstraint, values []string) []string {
+	var reasons []string
+	seen := map[string]struct{}{}
+	for _, v := range values {
+		if _, ok := seen[v]; ok || c.Allows(v) {
+			continue
+		}
+		seen[v] = struct{}{}
+		reasons = append(reasons, constraintViolation(c.Field, v))
+	}
+	return reasons
+}
+
+// ClusterUpdateImpact reports the apps and jobs that would be rescheduled
+// by replacing the cluster with the updated one: the ones in pools moving
+// to, or out of, the cluster and the ones whose namespace would change.
+func ClusterUpdateImpact(ctx context.Context, updated provTypes.Cluster) ([]ConfigImpact, error) {
+	clusters, err := servicemanager.Cluster.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var current *provTypes.Cluster
+	var after []provTypes.Cluster
+	for i := range clusters {
+		if clusters[i].Name == updated.Name {
+			current = &clusters[i]
+			updated.Provisioner = current.Provisioner
+			after = append(after, updated)
+			continue
+		}
+		after = append(after, clusters[i])
+	}
+	if current == nil {
+		return nil, provTypes.ErrClusterNotFound
+	}
+	pools, err := pool.ListAllPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	impacts := []ConfigImpact{}
+	for _, p := range pools {
+		before := clusterForPool(clusters, current.Provisioner, p.Name)
+		next := clusterForPool(after, current.Provisioner, p.Name)
+		var reason string
+		switch {
+		case before == nil && next == nil:
+			continue
+		case next == nil:
+			reason = fmt.Sprintf("no cluster would serve the pool, currently served by cluster %q", before.Name)
+		case before == nil:
+			reason = fmt.Sprintf("would be scheduled to cluster %q", next.Name)
+		case before.Name != next.Name:
+			reason = fmt.Sprintf("would move from cluster %q to cluster %q", before.Name, next.Name)
+		case next.Name == updated.Name && before.CustomData[clusterNamespaceKey] != next.CustomData[clusterNamespaceKey]:
+			reason = fmt.Sprintf("would move from namespace %q to namespace %q", before.CustomData[clusterNamespaceKey], next.CustomData[clusterNamespaceKey])
+		default:
+			continue
+		}
+		poolImpacts, err := poolWorkloadsImpact(ctx, p.Name, reason)
+		if err != nil {
+			return nil, err
+		}
+		impacts = append(impacts, poolImpacts...)
+	}
+	return impacts, nil
+}
+
+// clusterForPool returns the cluster serving the pool: the one listing it in
+// its pools or, when there is none, the default cluster.
+func clusterForPool(clusters []provTypes.Cluster, provisioner, poolName string) *provTypes.Cluster {
+	var defaultCluster *provTypes.Cluster
+	for i := range clusters {
+		if clusters[i].Provisioner != provisioner {
+			continue
+		}
+		for _, p := range clusters[i].Pools {
+			if p == poolName {
+				return &clusters[i]
+			}
+		}
+		if clusters[i].Default && defaultCluster == nil {
+			defaultCluster = &clusters[i]
+		}
+	}
+	return defaultCluster
+}
+
+func poolWorkloadsImpact(ctx context.Context, poolName, reason string) ([]ConfigImpact, error) {
+	apps, err := List(ctx, &Filter{Pool: poolName})
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := servicemanager.Job.List(ctx, &jobTypes.Filter{Pool: poolName})
+	if err != nil {
+		return nil, err
+	}
+	impacts := []ConfigImpact{}
+	for _, a := range apps {
+		impacts = append(impacts, ConfigImpact{Kind: ImpactKindApp, Name: a.Name, Pool: poolName, Reason: reason})
+	}
+	for _, j := range jobs {
+		impacts = append(impacts, ConfigImpact{Kind: ImpactKindJob, Name: j.Name, Pool: poolName, Reason: reason})
+	}
+	sort.SliceStable(impacts, func(i, j int) bool {
+		if impacts[i].Kind != impacts[j].Kind {
+			return impacts[i].Kind < impacts[j].Kind
+		}
+		return impacts[i].Name < impacts[j].Name
+	})
+	return impacts, nil
+}
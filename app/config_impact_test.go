@@ -0,0 +1,69 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision/pool"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolConstraintImpact(c *check.C) {
+	a := appTypes.App{Name: "my-app", TeamOwner: s.team.Name, Pool: s.Pool}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	impacts, err := PoolConstraintImpact(context.TODO(), &pool.PoolConstraint{
+		PoolExpr: s.Pool,
+		Field:    pool.ConstraintTypePlan,
+		Values:   []string{"other-plan"},
+	}, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.DeepEquals, []ConfigImpact{
+		{Kind: ImpactKindApp, Name: "my-app", Pool: s.Pool, Reason: `plan "default-plan" would not be allowed in the pool`},
+	})
+	impacts, err = PoolConstraintImpact(context.TODO(), &pool.PoolConstraint{
+		PoolExpr: s.Pool,
+		Field:    pool.ConstraintTypeRouter,
+		Values:   []string{"fake"},
+	}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.HasLen, 0)
+	constraints, err := pool.ListPoolsConstraints(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	for _, constraint := range constraints {
+		c.Assert(constraint.Field, check.Not(check.Equals), pool.ConstraintTypePlan)
+	}
+}
+
+func (s *S) TestClusterUpdateImpact(c *check.C) {
+	a := appTypes.App{Name: "my-app", TeamOwner: s.team.Name, Pool: s.Pool}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.mockService.Cluster.OnList = func() ([]provTypes.Cluster, error) {
+		return []provTypes.Cluster{
+			{Name: "c1", Provisioner: "kubernetes", Default: true, CustomData: map[string]string{"namespace": "tsuru"}},
+			{Name: "c2", Provisioner: "kubernetes"},
+		}, nil
+	}
+	defer func() { s.mockService.Cluster.OnList = nil }()
+	impacts, err := ClusterUpdateImpact(context.TODO(), provTypes.Cluster{Name: "c2", Pools: []string{s.Pool}})
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.DeepEquals, []ConfigImpact{
+		{Kind: ImpactKindApp, Name: "my-app", Pool: s.Pool, Reason: `would move from cluster "c1" to cluster "c2"`},
+	})
+	impacts, err = ClusterUpdateImpact(context.TODO(), provTypes.Cluster{Name: "c1", Default: true, CustomData: map[string]string{"namespace": "apps"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.DeepEquals, []ConfigImpact{
+		{Kind: ImpactKindApp, Name: "my-app", Pool: s.Pool, Reason: `would move from namespace "tsuru" to namespace "apps"`},
+	})
+	impacts, err = ClusterUpdateImpact(context.TODO(), provTypes.Cluster{Name: "c1", Default: true, CustomData: map[string]string{"namespace": "tsuru"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(impacts, check.HasLen, 0)
+	_, err = ClusterUpdateImpact(context.TODO(), provTypes.Cluster{Name: "c3"})
+	c.Assert(err, check.Equals, provTypes.ErrClusterNotFound)
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/log"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxConfigSnapshots is the number of configuration snapshots kept for each
+// app, older ones are discarded.
+const maxConfigSnapshots = 50
+
+var ErrConfigSnapshotNotFound = errors.New("configuration snapshot not found")
+
+// ConfigSnapshot is the configuration of an app at some point in time, taken
+// whenever it changes, so that it can be restored later.
+type ConfigSnapshot struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	App       string
+	Plan      appTypes.Plan
+	Env       map[string]bindTypes.EnvVar
+	Routers   []appTypes.AppRouter
+	AutoScale []provTypes.AutoScaleSpec
+	// Binds only describe the service instances bound to the app, they're
+	// not restored as binding requires the service API.
+	Binds     []ConfigSnapshotBind
+	CreatedAt time.Time
+}
+
+type ConfigSnapshotBind struct {
+	Service  string
+	Instance string
+}
+
+type skipConfigSnapshotKey struct{}
+
+func newConfigSnapshot(ctx context.Context, app *appTypes.App) (*ConfigSnapshot, error) {
+	autoScale, err := AutoScaleInfo(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigSnapshot{
+		ID:        primitive.NewObjectID(),
+		App:       app.Name,
+		Plan:      app.Plan,
+		Env:       app.Env,
+		Routers:   GetRouters(app),
+		AutoScale: autoScale,
+		Binds:     configSnapshotBinds(app),
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+func configSnapshotBinds(app *appTypes.App) []ConfigSnapshotBind {
+	seen := map[ConfigSnapshotBind]struct{}{}
+	var binds []ConfigSnapshotBind
+	for _, env := range app.ServiceEnvs {
+		bind := ConfigSnapshotBind{Service: env.ServiceName, Instance: env.InstanceName}
+		if _, ok := seen[bind]; ok {
+			continue
+		}
+		seen[bind] = struct{}{}
+		binds = append(binds, bind)
+	}
+	sort.Slice(binds, func(i, j int) bool {
+		if binds[i].Service == binds[j].Service {
+			return binds[i].Instance < binds[j].Instance
+		}
+		return binds[i].Service < binds[j].Service
+	})
+	return binds
+}
+
+// sameConfig compares the configuration held by both snapshots, ignoring
+// when they were taken.
+func (s *ConfigSnapshot) sameConfig(other *ConfigSnapshot) bool {
+	a, b := *s, *other
+	a.ID, b.ID = primitive.NilObjectID, primitive.NilObjectID
+	a.CreatedAt, b.CreatedAt = time.Time{}, time.Time{}
+	aData, errA := json.Marshal(a)
+	bData, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aData) == string(bData)
+}
+
+// recordConfigSnapshot takes a snapshot of the app configuration after it's
+// changed. Failures are only logged, the change itself is already done.
+func recordConfigSnapshot(ctx context.Context, app *appTypes.App) {
+	if skip, _ := ctx.Value(skipConfigSnapshotKey{}).(bool); skip {
+		return
+	}
+	_, err := saveConfigSnapshot(ctx, app)
+	if err != nil {
+		log.Errorf("unable to save configuration snapshot for app %s: %v", app.Name, err)
+	}
+}
+
+// saveConfigSnapshot takes a snapshot of the app configuration, returning
+// its ID. No snapshot is taken when the latest one has the same configuration,
+// the ID of the latest one is returned instead.
+func saveConfigSnapshot(ctx context.Context, app *appTypes.App) (string, error) {
+	snapshot, err := newConfigSnapshot(ctx, app)
+	if err != nil {
+		return "", err
+	}
+	collection, err := storagev2.ConfigSnapshotsCollection()
+	if err != nil {
+		return "", err
+	}
+	var latest ConfigSnapshot
+	err = collection.FindOne(ctx, mongoBSON.M{"app": app.Name}, options.FindOne().SetSort(mongoBSON.M{"_id": -1})).Decode(&latest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+	if err == nil && latest.sameConfig(snapshot) {
+		return latest.ID.Hex(), nil
+	}
+	_, err = collection.InsertOne(ctx, snapshot)
+	if err != nil {
+		return "", err
+	}
+	return snapshot.ID.Hex(), pruneConfigSnapshots(ctx, collection, app.Name)
+}
+
+func pruneConfigSnapshots(ctx context.Context, collection *mongo.Collection, appName string) error {
+	opts := options.Find().
+		SetSort(mongoBSON.M{"_id": -1}).
+		SetSkip(maxConfigSnapshots).
+		SetProjection(mongoBSON.M{"_id": 1})
+	cursor, err := collection.Find(ctx, mongoBSON.M{"app": appName}, opts)
+	if err != nil {
+		return err
+	}
+	var old []ConfigSnapshot
+	err = cursor.All(ctx, &old)
+	if err != nil || len(old) == 0 {
+		return err
+	}
+	ids := make([]primitive.ObjectID, len(old))
+	for i := range old {
+		ids[i] = old[i].ID
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": ids}})
+	return err
+}
+
+// ListConfigSnapshots returns the configuration snapshots of the app, newest
+// first.
+func ListConfigSnapshots(ctx context.Context, app *appTypes.App) ([]ConfigSnapshot, error) {
+	collection, err := storagev2.ConfigSnapshotsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"app": app.Name}, options.Find().SetSort(mongoBSON.M{"_id": -1}))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []ConfigSnapshot{}
+	err = cursor.All(ctx, &snapshots)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetConfigSnapshot returns a configuration snapshot of the app.
+func GetConfigSnapshot(ctx context.Context, app *appTypes.App, snapshotID string) (*ConfigSnapshot, error) {
+	id, err := primitive.ObjectIDFromHex(snapshotID)
+	if err != nil {
+		return nil, ErrConfigSnapshotNotFound
+	}
+	collection, err := storagev2.ConfigSnapshotsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var snapshot ConfigSnapshot
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "app": app.Name}).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrConfigSnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// RestoreConfigSnapshot brings the plan, environment variables, routers and
+// autoscale of the app back to the ones in the snapshot and restarts it.
+// Service binds are only reported when they differ.
+func RestoreConfigSnapshot(ctx context.Context, app *appTypes.App, snapshot *ConfigSnapshot, w io.Writer) error {
+	if w == nil {
+		w = io.Discard
+	}
+	fmt.Fprintf(w, "---- Restoring configuration from snapshot %s taken at %s ----\n", snapshot.ID.Hex(), snapshot.CreatedAt.Format(time.RFC3339))
+	restoreCtx := context.WithValue(ctx, skipConfigSnapshotKey{}, true)
+	err := saveEnvAndPlan(restoreCtx, app, snapshot.Env, snapshot.Plan)
+	if err != nil {
+		return err
+	}
+	err = restoreConfigRouters(restoreCtx, app, snapshot.Routers, w)
+	if err != nil {
+		return err
+	}
+	err = restoreConfigAutoScale(restoreCtx, app, snapshot.AutoScale, w)
+	if err != nil {
+		return err
+	}
+	if current := configSnapshotBinds(app); !reflect.DeepEqual(current, snapshot.Binds) {
+		fmt.Fprintf(w, "---- Service binds are not restored, the snapshot had: %s ----\n", formatConfigSnapshotBinds(snapshot.Binds))
+	}
+	err = restartIfUnits(ctx, app, w)
+	if err != nil {
+		return err
+	}
+	recordConfigSnapshot(ctx, app)
+	return nil
+}
+
+func restoreConfigRouters(ctx context.Context, app *appTypes.App, routers []appTypes.AppRouter, w io.Writer) error {
+	wanted := map[string]appTypes.AppRouter{}
+	for _, r := range routers {
+		wanted[r.Name] = r
+	}
+	current := map[string]appTypes.AppRouter{}
+	for _, r := range GetRouters(app) {
+		current[r.Name] = r
+		if _, ok := wanted[r.Name]; ok {
+			continue
+		}
+		fmt.Fprintf(w, " ---> Removing router %s\n", r.Name)
+		err := RemoveRouter(ctx, app, r.Name)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range routers {
+		existing, ok := current[r.Name]
+		if !ok {
+			fmt.Fprintf(w, " ---> Adding router %s\n", r.Name)
+			err := AddRouter(ctx, app, r)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !reflect.DeepEqual(existing.Opts, r.Opts) {
+			fmt.Fprintf(w, " ---> Updating router %s\n", r.Name)
+			err := UpdateRouter(ctx, app, r)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func restoreConfigAutoScale(ctx context.Context, app *appTypes.App, specs []provTypes.AutoScaleSpec, w io.Writer) error {
+	current, err := AutoScaleInfo(ctx, app)
+	if err != nil {
+		return err
+	}
+	wanted := map[string]provTypes.AutoScaleSpec{}
+	for _, spec := range specs {
+		wanted[spec.Process] = spec
+	}
+	existing := map[string]provTypes.AutoScaleSpec{}
+	for _, spec := range current {
+		existing[spec.Process] = spec
+		if _, ok := wanted[spec.Process]; ok {
+			continue
+		}
+		fmt.Fprintf(w, " ---> Removing autoscale of process %s\n", spec.Process)
+		err = RemoveAutoScale(ctx, app, spec.Process)
+		if err != nil {
+			return err
+		}
+	}
+	for _, spec := range specs {
+		if old, ok := existing[spec.Process]; ok && reflect.DeepEqual(old, spec) {
+			continue
+		}
+		fmt.Fprintf(w, " ---> Setting autoscale of process %s\n", spec.Process)
+		err = AutoScale(ctx, app, spec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatConfigSnapshotBinds(binds []ConfigSnapshotBind) string {
+	if len(binds) == 0 {
+		return "none"
+	}
+	names := make([]string, len(binds))
+	for i, b := range binds {
+		names[i] = b.Service + "/" + b.Instance
+	}
+	return strings.Join(names, ", ")
+}
+
+func removeConfigSnapshots(ctx context.Context, appName string) error {
+	collection, err := storagev2.ConfigSnapshotsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"app": appName})
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"strings"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestConfigSnapshotRecordedOnChange(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	snapshots, err := ListConfigSnapshots(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].Plan.Name, check.Equals, a.Plan.Name)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "old"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "old"}},
+	})
+	c.Assert(err, check.IsNil)
+	snapshots, err = ListConfigSnapshots(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 2)
+	c.Assert(snapshots[0].Env["MODE"].Value, check.Equals, "old")
+}
+
+func (s *S) TestRestoreConfigSnapshot(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "old"}},
+	})
+	c.Assert(err, check.IsNil)
+	snapshots, err := ListConfigSnapshots(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	snapshot, err := GetConfigSnapshot(context.TODO(), &a, snapshots[0].ID.Hex())
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "new"}, {Name: "EXTRA", Value: "1"}},
+	})
+	c.Assert(err, check.IsNil)
+	var buf strings.Builder
+	err = RestoreConfigSnapshot(context.TODO(), &a, snapshot, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, "(?s).*Restoring configuration from snapshot "+snapshot.ID.Hex()+".*")
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["MODE"].Value, check.Equals, "old")
+	_, ok := dbApp.Env["EXTRA"]
+	c.Assert(ok, check.Equals, false)
+	snapshots, err = ListConfigSnapshots(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 4)
+	c.Assert(snapshots[0].sameConfig(snapshot), check.Equals, true)
+}
+
+func (s *S) TestGetConfigSnapshotNotFound(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = GetConfigSnapshot(context.TODO(), &a, "invalid")
+	c.Assert(err, check.Equals, ErrConfigSnapshotNotFound)
+	other := appTypes.App{Name: "other-app", Platform: "django", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &other, s.user)
+	c.Assert(err, check.IsNil)
+	snapshots, err := ListConfigSnapshots(context.TODO(), &other)
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 1)
+	_, err = GetConfigSnapshot(context.TODO(), &a, snapshots[0].ID.Hex())
+	c.Assert(err, check.Equals, ErrConfigSnapshotNotFound)
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	DependencyKindApp             = "app"
+	DependencyKindJob             = "job"
+	DependencyKindServiceInstance = "service-instance"
+	DependencyKindVolume          = "volume"
+)
+
+// DependencyNode is an app, job, service instance or volume in a dependency
+// graph. Service is only set for service instances.
+type DependencyNode struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Service string `json:"service,omitempty"`
+}
+
+// DependencyEdge means the From node depends on the To node.
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DependencyGraph holds nodes sorted by ID and edges sorted by their ends.
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+type dependencyGraphBuilder struct {
+	nodes map[string]DependencyNode
+	edges map[DependencyEdge]struct{}
+}
+
+func newDependencyGraphBuilder() *dependencyGraphBuilder {
+	return &dependencyGraphBuilder{
+		nodes: map[string]DependencyNode{},
+		edges: map[DependencyEdge]struct{}{},
+	}
+}
+
+func (b *dependencyGraphBuilder) node(kind, name, serviceName string) string {
+	id := kind + ":" + name
+	if serviceName != "" {
+		id = kind + ":" + serviceName + "/" + name
+	}
+	b.nodes[id] = DependencyNode{ID: id, Kind: kind, Name: name, Service: serviceName}
+	return id
+}
+
+func (b *dependencyGraphBuilder) edge(from, to string) {
+	b.edges[DependencyEdge{From: from, To: to}] = struct{}{}
+}
+
+func (b *dependencyGraphBuilder) graph() *DependencyGraph {
+	g := &DependencyGraph{
+		Nodes: make([]DependencyNode, 0, len(b.nodes)),
+		Edges: make([]DependencyEdge, 0, len(b.edges)),
+	}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	for e := range b.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From == g.Edges[j].From {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].From < g.Edges[j].From
+	})
+	return g
+}
+
+// Dependencies returns the service instances and volumes bound to the app,
+// along with the other apps and jobs sharing them, which are affected by the
+// same maintenances.
+func Dependencies(ctx context.Context, app *appTypes.App) (*DependencyGraph, error) {
+	b := newDependencyGraphBuilder()
+	appID := b.node(DependencyKindApp, app.Name, "")
+	instances, err := service.GetServiceInstancesBoundToApp(ctx, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range instances {
+		addServiceInstanceDependents(b, &instances[i])
+	}
+	volumes, err := servicemanager.Volume.ListByApp(ctx, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range volumes {
+		volumeID := b.node(DependencyKindVolume, volumes[i].Name, "")
+		b.edge(appID, volumeID)
+		binds, err := servicemanager.Volume.Binds(ctx, &volumes[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, bind := range binds {
+			b.edge(b.node(DependencyKindApp, bind.ID.App, ""), volumeID)
+		}
+	}
+	return b.graph(), nil
+}
+
+// ServiceInstanceDependents returns the apps and jobs bound to the service
+// instance.
+func ServiceInstanceDependents(si *service.ServiceInstance) *DependencyGraph {
+	b := newDependencyGraphBuilder()
+	addServiceInstanceDependents(b, si)
+	return b.graph()
+}
+
+func addServiceInstanceDependents(b *dependencyGraphBuilder, si *service.ServiceInstance) {
+	instanceID := b.node(DependencyKindServiceInstance, si.Name, si.ServiceName)
+	for _, appName := range si.Apps {
+		b.edge(b.node(DependencyKindApp, appName, ""), instanceID)
+	}
+	for _, jobName := range si.Jobs {
+		b.edge(b.node(DependencyKindJob, jobName, ""), instanceID)
+	}
+}
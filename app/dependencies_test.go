@@ -0,0 +1,87 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDependencies(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), service.ServiceInstance{
+		ServiceName: "mysql",
+		Name:        "shared-db",
+		Apps:        []string{"myapp", "otherapp"},
+		Jobs:        []string{"nightly"},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), service.ServiceInstance{
+		ServiceName: "redis",
+		Name:        "unrelated",
+		Apps:        []string{"otherapp"},
+	})
+	c.Assert(err, check.IsNil)
+	config.Set("volume-plans:nfs:fake:plugin", "nfs")
+	defer config.Unset("volume-plans")
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	err = servicemanager.Volume.Create(context.TODO(), &v1)
+	c.Assert(err, check.IsNil)
+	for _, appName := range []string{"myapp", "thirdapp"} {
+		err = servicemanager.Volume.BindApp(context.TODO(), &volumeTypes.BindOpts{
+			Volume:     &v1,
+			AppName:    appName,
+			MountPoint: "/mnt",
+		})
+		c.Assert(err, check.IsNil)
+	}
+	graph, err := Dependencies(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(graph, check.DeepEquals, &DependencyGraph{
+		Nodes: []DependencyNode{
+			{ID: "app:myapp", Kind: DependencyKindApp, Name: "myapp"},
+			{ID: "app:otherapp", Kind: DependencyKindApp, Name: "otherapp"},
+			{ID: "app:thirdapp", Kind: DependencyKindApp, Name: "thirdapp"},
+			{ID: "job:nightly", Kind: DependencyKindJob, Name: "nightly"},
+			{ID: "service-instance:mysql/shared-db", Kind: DependencyKindServiceInstance, Name: "shared-db", Service: "mysql"},
+			{ID: "volume:v1", Kind: DependencyKindVolume, Name: "v1"},
+		},
+		Edges: []DependencyEdge{
+			{From: "app:myapp", To: "service-instance:mysql/shared-db"},
+			{From: "app:myapp", To: "volume:v1"},
+			{From: "app:otherapp", To: "service-instance:mysql/shared-db"},
+			{From: "app:thirdapp", To: "volume:v1"},
+			{From: "job:nightly", To: "service-instance:mysql/shared-db"},
+		},
+	})
+}
+
+func (s *S) TestServiceInstanceDependents(c *check.C) {
+	graph := ServiceInstanceDependents(&service.ServiceInstance{
+		ServiceName: "mysql",
+		Name:        "db",
+		Apps:        []string{"myapp"},
+	})
+	c.Assert(graph, check.DeepEquals, &DependencyGraph{
+		Nodes: []DependencyNode{
+			{ID: "app:myapp", Kind: DependencyKindApp, Name: "myapp"},
+			{ID: "service-instance:mysql/db", Kind: DependencyKindServiceInstance, Name: "db", Service: "mysql"},
+		},
+		Edges: []DependencyEdge{
+			{From: "app:myapp", To: "service-instance:mysql/db"},
+		},
+	})
+}
@@ -14,13 +14,17 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
 	"github.com/tsuru/tsuru/router/rebuild"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
@@ -34,6 +38,15 @@ import (
 
 var reImageVersion = regexp.MustCompile(":v([0-9]+)$")
 
+// deployLogFlushInterval is how often the log of a running deploy is
+// persisted, so it can be streamed while the deploy happens.
+var deployLogFlushInterval = 2 * time.Second
+
+const (
+	RollbackReasonAnnotation            = "tsuru.io/rollback-reason"
+	RollbackIncidentReferenceAnnotation = "tsuru.io/rollback-incident-reference"
+)
+
 type DeployData struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
 	App         string
@@ -48,7 +61,15 @@ type DeployData struct {
 	Origin      string
 	CanRollback bool
 	Diff        string
+	DeployDiff  *DeployDiff
 	Message     string
+	// ImageSignature is the verified signature of the image of image
+	// deploys in clusters requiring signed images.
+	ImageSignature *registry.ImageSignature
+	// PendingApproval is set while the deploy waits for the approval
+	// required by the app.
+	PendingApproval bool
+	ApprovedBy      string
 }
 
 func findValidImages(ctx context.Context, appNames []string) (set.Set, error) {
@@ -69,9 +90,28 @@ func findValidImages(ctx context.Context, appNames []string) (set.Set, error) {
 	return validImages, nil
 }
 
-// ListDeploys returns the list of deploy that match a given filter.
-func ListDeploys(ctx context.Context, filter *Filter, skip, limit int) ([]DeployData, error) {
-	var rawFilter mongoBSON.M
+const (
+	DeployStatusSuccess = "success"
+	DeployStatusFailed  = "failed"
+	DeployStatusRunning = "running"
+)
+
+// DeployListOptions paginates and narrows the deploys returned by
+// ListDeploys. Cursor is the id of the last deploy of the previous page, the
+// deploys started before it are returned.
+type DeployListOptions struct {
+	Skip   int
+	Limit  int
+	Cursor string
+	Since  time.Time
+	Until  time.Time
+	Status string
+}
+
+// ListDeploys returns the list of deploy that match a given filter, newest
+// first.
+func ListDeploys(ctx context.Context, filter *Filter, opts DeployListOptions) ([]DeployData, error) {
+	rawFilter := mongoBSON.M{}
 	if !filter.IsEmpty() {
 		appsList, err := List(ctx, filter)
 		if err != nil {
@@ -81,16 +121,42 @@ func ListDeploys(ctx context.Context, filter *Filter, skip, limit int) ([]Deploy
 		for i, a := range appsList {
 			apps[i] = a.Name
 		}
-		rawFilter = mongoBSON.M{"target.value": mongoBSON.M{"$in": apps}}
+		rawFilter["target.value"] = mongoBSON.M{"$in": apps}
 	}
-	evts, err := event.List(ctx, &event.Filter{
+	evtFilter := &event.Filter{
 		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp},
-		Raw:       rawFilter,
 		KindNames: []string{permission.PermAppDeploy.FullName()},
 		KindType:  eventTypes.KindTypePermission,
-		Limit:     limit,
-		Skip:      skip,
-	})
+		Since:     opts.Since,
+		Until:     opts.Until,
+		Limit:     opts.Limit,
+		Skip:      opts.Skip,
+	}
+	running, notRunning := true, false
+	switch opts.Status {
+	case "":
+	case DeployStatusRunning:
+		evtFilter.Running = &running
+	case DeployStatusFailed:
+		evtFilter.Running = &notRunning
+		evtFilter.ErrorOnly = true
+	case DeployStatusSuccess:
+		evtFilter.Running = &notRunning
+		rawFilter["error"] = ""
+	default:
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid deploy status %q, expected one of: %s, %s, %s", opts.Status, DeployStatusSuccess, DeployStatusFailed, DeployStatusRunning)}
+	}
+	if opts.Cursor != "" {
+		cursorFilter, err := deployCursorFilter(ctx, opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		rawFilter["$or"] = cursorFilter
+	}
+	if len(rawFilter) > 0 {
+		evtFilter.Raw = rawFilter
+	}
+	evts, err := event.List(ctx, evtFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +178,32 @@ func ListDeploys(ctx context.Context, filter *Filter, skip, limit int) ([]Deploy
 	return list, nil
 }
 
+// deployCursorFilter matches the deploys listed after the cursor deploy,
+// following the order of ListDeploys.
+func deployCursorFilter(ctx context.Context, cursor string) ([]mongoBSON.M, error) {
+	invalidErr := &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid deploy cursor %q", cursor)}
+	id, err := primitive.ObjectIDFromHex(cursor)
+	if err != nil {
+		return nil, invalidErr
+	}
+	evts, err := event.List(ctx, &event.Filter{
+		KindNames: []string{permission.PermAppDeploy.FullName()},
+		Raw:       mongoBSON.M{"uniqueid": id},
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(evts) == 0 {
+		return nil, invalidErr
+	}
+	last := evts[0]
+	return []mongoBSON.M{
+		{"starttime": mongoBSON.M{"$lt": last.StartTime}},
+		{"starttime": last.StartTime, "uniqueid": mongoBSON.M{"$lt": last.UniqueID}},
+	}, nil
+}
+
 func GetDeploy(ctx context.Context, id string) (*DeployData, error) {
 	if _, err := primitive.ObjectIDFromHex(id); err != nil {
 		return nil, errors.Errorf("id parameter is not ObjectId: %s", id)
@@ -131,6 +223,9 @@ func eventToDeployData(evt *event.Event, validImages set.Set, full bool) *Deploy
 		Duration:  evt.EndTime.Sub(evt.StartTime),
 		Error:     evt.Error,
 		User:      evt.Owner.Name,
+
+		PendingApproval: evt.Running && evt.ApprovalInfo.Required && !evt.ApprovalInfo.Approved,
+		ApprovedBy:      evt.ApprovalInfo.Owner,
 	}
 	var err error
 	var deployOptions DeployOptions
@@ -141,14 +236,21 @@ func eventToDeployData(evt *event.Event, validImages set.Set, full bool) *Deploy
 	} else {
 		log.Errorf("cannot decode the event's start custom data value: event %s - %v", evt.UniqueID, err)
 	}
+	var otherData deployOtherData
+	if err = evt.OtherData(&otherData); err == nil {
+		if otherData.Commit != "" {
+			data.Commit = otherData.Commit
+		}
+		if full {
+			data.Diff = otherData.Diff
+			data.DeployDiff = otherData.DeployDiff
+			data.ImageSignature = otherData.ImageSignature
+		}
+	} else if full {
+		log.Errorf("cannot decode the event's other custom data value: event %s - %v", evt.UniqueID, err)
+	}
 	if full {
 		data.Log = evt.Log()
-		var otherData map[string]string
-		if err = evt.OtherData(&otherData); err == nil {
-			data.Diff = otherData["diff"]
-		} else {
-			log.Errorf("cannot decode the event's other custom data value: event %s - %v", evt.UniqueID, err)
-		}
 	}
 	var endData map[string]string
 	if err = evt.EndData(&endData); err == nil {
@@ -171,6 +273,8 @@ type DeployOptions struct {
 	Commit           string
 	BuildTag         string
 	ArchiveURL       string
+	GitURL           string
+	GitRef           string
 	Dockerfile       string
 	FileSize         int64
 	File             io.ReadCloser `bson:"-"`
@@ -185,6 +289,20 @@ type DeployOptions struct {
 	Build            bool
 	NewVersion       bool
 	OverrideVersions bool
+	// Strategy is how the units of the new version replace the current ones,
+	// see provision.DeployStrategyBlueGreen.
+	Strategy string
+
+	RollbackReason    string
+	IncidentReference string
+	// RollbackDeployID is the deploy whose environment variables and plan
+	// are restored by a rollback, besides its image.
+	RollbackDeployID string
+	// PlanOverride changes the resources of the app plan only for the
+	// version being deployed.
+	PlanOverride *appTypes.PlanOverride `bson:",omitempty"`
+
+	imageSignature *registry.ImageSignature
 }
 
 func (o *DeployOptions) GetOrigin() string {
@@ -223,6 +341,10 @@ func (o *DeployOptions) GetKind() (kind provisionTypes.DeployKind) {
 		return provisionTypes.DeployUpload
 	}
 
+	if o.GitURL != "" {
+		return provisionTypes.DeployGitURL
+	}
+
 	if o.Commit != "" {
 		return provisionTypes.DeployGit
 	}
@@ -245,6 +367,10 @@ func Build(ctx context.Context, opts DeployOptions) (string, error) {
 	if opts.App.Platform == "" {
 		return "", errors.Errorf("can't build app without platform")
 	}
+	err := cloneGitSource(ctx, &opts)
+	if err != nil {
+		return "", err
+	}
 	version, err := builderDeploy(ctx, &opts, opts.Event)
 	if err != nil {
 		return "", err
@@ -305,6 +431,16 @@ func (e *errorWithLog) Error() string {
 }
 
 func validateVersions(ctx context.Context, opts DeployOptions) error {
+	switch opts.Strategy {
+	case "", provision.DeployStrategyRolling:
+	case provision.DeployStrategyBlueGreen:
+		if opts.NewVersion || opts.OverrideVersions {
+			return &tsuruErrors.ValidationError{Message: "new-version and override-old-versions can't be used with the blue-green strategy"}
+		}
+		return nil
+	default:
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid deploy strategy %q", opts.Strategy)}
+	}
 	if opts.NewVersion && opts.OverrideVersions {
 		return errors.New("conflicting deploy flags, new-version and override-old-versions")
 	}
@@ -321,6 +457,36 @@ func validateVersions(ctx context.Context, opts DeployOptions) error {
 	return nil
 }
 
+// ValidateRollback checks the rollback requirements of the app pool, which
+// may demand a reason for every rollback.
+func ValidateRollback(ctx context.Context, opts DeployOptions) error {
+	p, err := pool.GetPoolByName(ctx, opts.App.Pool)
+	if err != nil {
+		return err
+	}
+	if p.RollbackRequiresReason() && strings.TrimSpace(opts.RollbackReason) == "" {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("pool %q requires a reason to rollback apps", p.Name)}
+	}
+	return nil
+}
+
+func (o *DeployOptions) annotations() map[string]string {
+	if o.GetKind() != provisionTypes.DeployRollback {
+		return nil
+	}
+	annotations := map[string]string{}
+	if o.RollbackReason != "" {
+		annotations[RollbackReasonAnnotation] = o.RollbackReason
+	}
+	if o.IncidentReference != "" {
+		annotations[RollbackIncidentReferenceAnnotation] = o.IncidentReference
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
 // Deploy runs a deployment of an application. It will first try to run an
 // archive based deploy (if opts.ArchiveURL is not empty), and then fallback to
 // the Git based deployment.
@@ -328,25 +494,77 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	if opts.Event == nil {
 		return "", errors.Errorf("missing event in deploy opts")
 	}
+	if opts.App.Archive != nil {
+		return "", ErrAppArchived
+	}
 	err := validateVersions(ctx, opts)
 	if err != nil {
 		return "", err
 	}
+	err = checkEnvSchema(opts.App, nil)
+	if err != nil {
+		return "", err
+	}
+	err = validateSchedulingConstraints(ctx, opts.App)
+	if err != nil {
+		return "", err
+	}
+	err = validateDeployPlanOverride(opts.PlanOverride)
+	if err != nil {
+		return "", err
+	}
+	previous, err := previousVersion(ctx, opts.App)
+	if err != nil {
+		return "", err
+	}
 	logWriter := LogWriter{AppName: opts.App.Name}
 	logWriter.Async()
 	defer logWriter.Close()
 	opts.Event.SetLogWriter(io.MultiWriter(&tsuruIo.NoErrorWriter{Writer: opts.OutputStream}, &logWriter))
+	stopFlush := flushDeployLog(ctx, opts.Event)
+	defer stopFlush()
+	if opts.App.RequireApproval {
+		err = waitDeployApproval(ctx, opts.Event)
+		if err != nil {
+			return "", err
+		}
+	}
+	err = cloneGitSource(ctx, &opts)
+	if err != nil {
+		return "", err
+	}
+	var archive *digestReader
+	if opts.File != nil {
+		archive = newDigestReader(opts.File)
+		opts.File = archive
+	}
+	if opts.RollbackDeployID != "" {
+		snapshot, snapshotErr := GetDeploySnapshot(ctx, opts.App, opts.RollbackDeployID)
+		if snapshotErr != nil {
+			return "", snapshotErr
+		}
+		err = restoreDeploySnapshot(ctx, opts.App, snapshot, opts.Event)
+		if err != nil {
+			return "", err
+		}
+	}
 	imageID, err := deployToProvisioner(ctx, &opts, opts.Event)
 	if err != nil {
 		return "", newErrorWithLog(ctx, err, opts.App, "deploy")
 	}
+	recordDeployDiff(ctx, &opts, previous, imageID, archive)
+	err = saveDeploySnapshot(ctx, &opts, imageID)
+	if err != nil {
+		log.ErrorfContext(ctx, "unable to save deploy snapshot for app %s: %v", opts.App.Name, err)
+	}
 	err = rebuild.RebuildRoutesWithAppName(opts.App.Name, opts.Event)
 	if err != nil {
 		return "", err
 	}
+	setDeployProgress(ctx, opts.Event, "routes swapped", provision.DeployProgressRoutesSwapped)
 	err = incrementDeploy(ctx, opts.App)
 	if err != nil {
-		log.Errorf("WARNING: couldn't increment deploy count, deploy opts: %#v", opts)
+		log.ErrorfContext(ctx, "WARNING: couldn't increment deploy count, deploy opts: %#v", opts)
 	}
 	if opts.Kind == provisionTypes.DeployImage || opts.Kind == provisionTypes.DeployRollback {
 		if !opts.App.UpdatePlatform {
@@ -355,9 +573,19 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	} else if opts.App.UpdatePlatform {
 		SetUpdatePlatform(ctx, opts.App, false)
 	}
+	setDeployProgress(ctx, opts.Event, "done", provision.DeployProgressDone)
 	return imageID, nil
 }
 
+// setDeployProgress records the progress of the deploy in its event, failures
+// are only logged as progress is informative.
+func setDeployProgress(ctx context.Context, evt *event.Event, step string, percent int) {
+	err := evt.SetProgress(ctx, eventTypes.ProgressInfo{Step: step, Percent: percent})
+	if err != nil {
+		log.Errorf("unable to set progress of event %s: %v", evt.UniqueID.Hex(), err)
+	}
+}
+
 func RollbackUpdate(ctx context.Context, app *appTypes.App, imageID, reason string, disableRollback bool) error {
 	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, imageID)
 	if err != nil {
@@ -366,6 +594,97 @@ func RollbackUpdate(ctx context.Context, app *appTypes.App, imageID, reason stri
 	return version.ToggleEnabled(!disableRollback, reason)
 }
 
+// flushDeployLog periodically persists the log of the deploy event until the
+// returned function is called.
+func flushDeployLog(ctx context.Context, evt *event.Event) func() {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(deployLogFlushInterval):
+			}
+			err := evt.FlushLog(context.WithoutCancel(ctx))
+			if err != nil {
+				log.Errorf("unable to flush deploy log for event %s: %v", evt.UniqueID.Hex(), err)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+var ErrNoDeployInProgress = errors.New("there is no deploy in progress for the app")
+
+func blueGreenProvisioner(ctx context.Context, app *appTypes.App) (provision.BlueGreenProvisioner, error) {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	bgProv, ok := prov.(provision.BlueGreenProvisioner)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "blue-green deploy"}
+	}
+	return bgProv, nil
+}
+
+// ConfirmDeploy switches the traffic of the app to the version left pending
+// by a blue-green deploy, removing the versions previously receiving it.
+func ConfirmDeploy(ctx context.Context, app *appTypes.App, evt *event.Event) (int, error) {
+	bgProv, err := blueGreenProvisioner(ctx, app)
+	if err != nil {
+		return 0, err
+	}
+	version, err := bgProv.ConfirmDeploy(ctx, app, evt)
+	if err != nil {
+		return 0, err
+	}
+	return version, rebuild.RebuildRoutesWithAppName(app.Name, evt)
+}
+
+// AbortDeploy removes the version left pending by a blue-green deploy,
+// keeping the traffic on the versions currently receiving it.
+func AbortDeploy(ctx context.Context, app *appTypes.App, evt *event.Event) (int, error) {
+	bgProv, err := blueGreenProvisioner(ctx, app)
+	if err != nil {
+		return 0, err
+	}
+	return bgProv.AbortDeploy(ctx, app, evt)
+}
+
+func runningDeploy(ctx context.Context, app *appTypes.App) (*event.Event, error) {
+	evt, err := event.GetRunning(ctx, eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: app.Name}, permission.PermAppDeploy.FullName())
+	if err == event.ErrEventNotFound {
+		return nil, ErrNoDeployInProgress
+	}
+	return evt, err
+}
+
+// PauseDeploy asks the deploy in progress for the app to stop at its next
+// checkpoint: after the build or between units being rolled out. Units
+// already updated are kept running until the deploy is resumed or canceled.
+func PauseDeploy(ctx context.Context, app *appTypes.App, reason, owner string) (*event.Event, error) {
+	evt, err := runningDeploy(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	return evt, evt.TryPause(ctx, reason, owner)
+}
+
+// ResumeDeploy continues a deploy paused by PauseDeploy from its checkpoint.
+func ResumeDeploy(ctx context.Context, app *appTypes.App) (*event.Event, error) {
+	evt, err := runningDeploy(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	return evt, evt.Resume(ctx)
+}
+
 func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Event) (string, error) {
 	prov, err := getProvisioner(ctx, opts.App)
 	if err != nil {
@@ -383,6 +702,9 @@ func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Ev
 	if !ok {
 		return "", provision.ProvisionerNotSupported{Prov: prov, Action: fmt.Sprintf("%s deploy", opts.Kind)}
 	}
+	if _, ok = prov.(provision.BlueGreenProvisioner); !ok && opts.Strategy == provision.DeployStrategyBlueGreen {
+		return "", provision.ProvisionerNotSupported{Prov: prov, Action: "blue-green deploy"}
+	}
 
 	var version appTypes.AppVersion
 	if opts.Kind == provisionTypes.DeployRollback {
@@ -397,21 +719,100 @@ func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Ev
 			return "", errors.Errorf("the selected version is disabled for rollback: %s", version.VersionInfo().DisabledReason)
 		}
 	} else {
-		version, err = builderDeploy(ctx, opts, evt)
+		if opts.Kind == provisionTypes.DeployImage {
+			version, err = builtVersion(ctx, opts.App, opts.Image)
+			if err != nil {
+				return "", err
+			}
+		}
+		if version != nil {
+			fmt.Fprintf(evt, "---- Deploying image %s from a previous build ----\n", version.VersionInfo().DeployImage)
+		} else {
+			if opts.Kind == provisionTypes.DeployImage {
+				err = checkImageSignature(ctx, opts, prov)
+				if err != nil {
+					return "", err
+				}
+			}
+			setDeployProgress(ctx, evt, "build started", provision.DeployProgressBuildStarted)
+			version, err = builderDeploy(ctx, opts, evt)
+			if err != nil {
+				return "", err
+			}
+		}
+		err = evt.Checkpoint(ctx, "build done")
 		if err != nil {
 			return "", err
 		}
 	}
 
+	setDeployProgress(ctx, evt, "image pushed", provision.DeployProgressImageReady)
 	return deployer.Deploy(ctx, provision.DeployArgs{
 		App:              opts.App,
 		Version:          version,
 		Event:            evt,
 		PreserveVersions: opts.NewVersion,
 		OverrideVersions: opts.OverrideVersions,
+		Annotations:      opts.annotations(),
+		PlanOverride:     opts.PlanOverride,
+		Strategy:         opts.Strategy,
 	})
 }
 
+func validateDeployPlanOverride(override *appTypes.PlanOverride) error {
+	if override == nil {
+		return nil
+	}
+	if override.CPUBurst != nil {
+		return &tsuruErrors.ValidationError{Message: "cpu burst can't be overridden in a deploy"}
+	}
+	if (override.Memory != nil && *override.Memory <= 0) || (override.CPUMilli != nil && *override.CPUMilli <= 0) {
+		return &tsuruErrors.ValidationError{Message: "plan override of a deploy must have positive cpu and memory values"}
+	}
+	return nil
+}
+
+// builtVersion returns the version created by a previous build of the app,
+// see Build, whose image is the given one, either the versioned image or the
+// one with the tag set in the build. It returns nil if there's no such
+// version.
+func builtVersion(ctx context.Context, app *appTypes.App, imageName string) (appTypes.AppVersion, error) {
+	versions, err := servicemanager.AppVersion.AppVersions(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions.Versions {
+		if v.CustomBuildTag == "" || v.DeployImage == "" || v.MarkedToRemoval || v.Disabled {
+			continue
+		}
+		repository, _ := image.SplitImageName(v.DeployImage)
+		if imageName == v.DeployImage || imageName == repository+":"+v.CustomBuildTag {
+			return servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(v.Version))
+		}
+	}
+	return nil, nil
+}
+
+// cloneGitSource clones the repository of git-url deploys into the archive
+// sent to the builder, recording the commit the ref resolved to in the
+// event.
+func cloneGitSource(ctx context.Context, opts *DeployOptions) error {
+	if opts.GetKind() != provisionTypes.DeployGitURL {
+		return nil
+	}
+	archive, err := builder.ArchiveFromGit(ctx, opts.GitURL, opts.GitRef, builder.GitScope{
+		Team: opts.App.TeamOwner,
+		Pool: opts.App.Pool,
+	}, opts.Event)
+	if err != nil {
+		return err
+	}
+	opts.File = archive.File
+	opts.FileSize = archive.Size
+	opts.Commit = archive.Commit
+	return opts.Event.SetOtherCustomData(ctx, deployOtherData{Commit: archive.Commit})
+}
+
 func builderDeploy(ctx context.Context, opts *DeployOptions, evt *event.Event) (appTypes.AppVersion, error) {
 	buildOpts := builder.BuildOpts{
 		Rebuild:     opts.GetKind() == provisionTypes.DeployRebuild,
@@ -0,0 +1,90 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultDeployApprovalTimeout = time.Hour
+
+var (
+	ErrDeployNotFound        = errors.New("deploy not found")
+	ErrDeploySelfApproval    = errors.New("deploys must be approved by a user other than the one who started them")
+	ErrDeployApprovalTimeout = errors.New("deploy was not approved in time")
+)
+
+// waitDeployApproval holds the deploy until it's approved with ApproveDeploy,
+// giving up after deploy:approval-timeout.
+func waitDeployApproval(ctx context.Context, evt *event.Event) error {
+	timeout, _ := config.GetDuration("deploy:approval-timeout")
+	if timeout <= 0 {
+		timeout = defaultDeployApprovalTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := evt.WaitApproval(waitCtx)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return ErrDeployApprovalTimeout
+	}
+	return err
+}
+
+// ApproveDeploy allows a deploy waiting for approval to continue. The
+// approver must not be the user who started the deploy.
+func ApproveDeploy(ctx context.Context, app *appTypes.App, deployID, approver string) (*event.Event, error) {
+	evt, err := DeployEvent(ctx, deployID)
+	if err != nil {
+		return nil, err
+	}
+	target := eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: app.Name}
+	if evt.Target != target {
+		return nil, ErrDeployNotFound
+	}
+	if evt.Owner.Name == approver {
+		return nil, ErrDeploySelfApproval
+	}
+	return evt, evt.Approve(ctx, approver)
+}
+
+// ContinueDeploy resumes a deploy waiting at a pause point of its tsuru.yaml.
+func ContinueDeploy(ctx context.Context, deployID string) (*event.Event, error) {
+	evt, err := DeployEvent(ctx, deployID)
+	if err != nil {
+		return nil, err
+	}
+	if !evt.Running {
+		return nil, ErrNoDeployInProgress
+	}
+	return evt, evt.Resume(ctx)
+}
+
+// DeployEvent returns the event of the deploy with the given id.
+func DeployEvent(ctx context.Context, deployID string) (*event.Event, error) {
+	id, err := primitive.ObjectIDFromHex(deployID)
+	if err != nil {
+		return nil, ErrDeployNotFound
+	}
+	evt, err := event.GetByID(ctx, id)
+	if err == event.ErrEventNotFound {
+		return nil, ErrDeployNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if evt.Kind.Name != permission.PermAppDeploy.FullName() {
+		return nil, ErrDeployNotFound
+	}
+	return evt, nil
+}
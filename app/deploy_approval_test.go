@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDeployRequiresApproval(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetRequireApproval(context.TODO(), &a, true)
+	c.Assert(err, check.IsNil)
+	evt := s.newDeployEvent(c, &a)
+	done := make(chan error)
+	go func() {
+		_, deployErr := Deploy(context.TODO(), DeployOptions{
+			App:          &a,
+			File:         io.NopCloser(strings.NewReader("my file")),
+			OutputStream: io.Discard,
+			Event:        evt,
+		})
+		done <- deployErr
+	}()
+	timeout := time.After(5 * time.Second)
+	for {
+		pending, getErr := event.GetByID(context.TODO(), evt.UniqueID)
+		c.Assert(getErr, check.IsNil)
+		if pending.ApprovalInfo.Required {
+			break
+		}
+		select {
+		case err = <-done:
+			c.Fatalf("deploy should wait for approval, got: %v", err)
+		case <-timeout:
+			c.Fatal("timeout waiting for deploy to require approval")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	_, err = ApproveDeploy(context.TODO(), &a, evt.UniqueID.Hex(), s.user.Email)
+	c.Assert(err, check.Equals, ErrDeploySelfApproval)
+	_, err = ApproveDeploy(context.TODO(), &a, evt.UniqueID.Hex(), "reviewer@example.com")
+	c.Assert(err, check.IsNil)
+	select {
+	case err = <-done:
+		c.Assert(err, check.IsNil)
+	case <-time.After(10 * time.Second):
+		c.Fatal("timeout waiting for approved deploy")
+	}
+	c.Assert(evt.Done(context.TODO(), nil), check.IsNil)
+	deploy, err := GetDeploy(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(deploy.PendingApproval, check.Equals, false)
+	c.Assert(deploy.ApprovedBy, check.Equals, "reviewer@example.com")
+}
+
+func (s *S) TestDeployApprovalTimeout(c *check.C) {
+	config.Set("deploy:approval-timeout", "100ms")
+	defer config.Unset("deploy:approval-timeout")
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name, RequireApproval: true}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(context.TODO(), DeployOptions{
+		App:          &a,
+		File:         io.NopCloser(strings.NewReader("my file")),
+		OutputStream: io.Discard,
+		Event:        s.newDeployEvent(c, &a),
+	})
+	c.Assert(err, check.Equals, ErrDeployApprovalTimeout)
+}
+
+func (s *S) TestApproveDeployNotFound(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = ApproveDeploy(context.TODO(), &a, "invalid", "reviewer@example.com")
+	c.Assert(err, check.Equals, ErrDeployNotFound)
+	other := appTypes.App{Name: "other-app", Platform: "django", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &other, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.newDeployEvent(c, &other)
+	_, err = ApproveDeploy(context.TODO(), &a, evt.UniqueID.Hex(), "reviewer@example.com")
+	c.Assert(err, check.Equals, ErrDeployNotFound)
+}
+
+func (s *S) TestContinueDeploy(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.newDeployEvent(c, &a)
+	err = evt.TryPause(context.TODO(), "waiting to be continued within 10m0s", "tsuru.yaml")
+	c.Assert(err, check.IsNil)
+	continued, err := ContinueDeploy(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(continued.UniqueID, check.Equals, evt.UniqueID)
+	paused, err := evt.PauseRequested(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(paused, check.Equals, false)
+	_, err = ContinueDeploy(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.Equals, event.ErrNotPaused)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	_, err = ContinueDeploy(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.Equals, ErrNoDeployInProgress)
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/registry"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+	"sigs.k8s.io/yaml"
+)
+
+var imageDigest = registry.ImageDigest
+
+// DeployDiff describes what a deploy changed compared to the version that
+// was deployed before it.
+type DeployDiff struct {
+	ArchiveDigest       string
+	Image               string
+	ImageDigest         string
+	PreviousVersion     int
+	PreviousImage       string
+	PreviousImageDigest string
+	// Manifest is a line diff of the processes and tsuru.yaml data of both
+	// versions, empty when they are the same.
+	Manifest string
+}
+
+// deployOtherData is stored as the other custom data of deploy events. Diff
+// holds diffs sent by old clients, Commit the commit cloned by git-url
+// deploys, ImageSignature the verified signature of image deploys and
+// Manifests the objects rendered by the provisioner for the deployed version.
+type deployOtherData struct {
+	Diff           string                       `bson:"diff,omitempty"`
+	DeployDiff     *DeployDiff                  `bson:"deploydiff,omitempty"`
+	Commit         string                       `bson:"commit,omitempty"`
+	ImageSignature *registry.ImageSignature     `bson:"imagesignature,omitempty"`
+	Manifests      []provision.RenderedManifest `bson:"manifests,omitempty"`
+}
+
+// digestReader computes the digest of an archive while it is read by the
+// builder.
+type digestReader struct {
+	io.ReadCloser
+	hash hash.Hash
+	n    int64
+}
+
+func newDigestReader(r io.ReadCloser) *digestReader {
+	return &digestReader{ReadCloser: r, hash: sha256.New()}
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *digestReader) digest() string {
+	if r == nil || r.n == 0 {
+		return ""
+	}
+	return "sha256:" + hex.EncodeToString(r.hash.Sum(nil))
+}
+
+func versionImage(version appTypes.AppVersion) string {
+	vi := version.VersionInfo()
+	if vi.DeployImage != "" {
+		return vi.DeployImage
+	}
+	return vi.BuildImage
+}
+
+func versionManifest(version appTypes.AppVersion) (string, error) {
+	vi := version.VersionInfo()
+	manifest := map[string]interface{}{}
+	for k, v := range vi.CustomData {
+		manifest[k] = v
+	}
+	if len(vi.Processes) > 0 {
+		manifest["processes"] = vi.Processes
+	}
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func newDeployDiff(ctx context.Context, previous, current appTypes.AppVersion, archive *digestReader) (*DeployDiff, error) {
+	diff := &DeployDiff{
+		ArchiveDigest: archive.digest(),
+		Image:         versionImage(current),
+	}
+	var err error
+	if diff.Image != "" {
+		diff.ImageDigest, err = imageDigest(ctx, diff.Image)
+		if err != nil {
+			log.Errorf("unable to get digest of image %s: %v", diff.Image, err)
+		}
+	}
+	if previous == nil || previous.Version() == current.Version() {
+		return diff, nil
+	}
+	diff.PreviousVersion = previous.Version()
+	diff.PreviousImage = versionImage(previous)
+	if diff.PreviousImage != "" {
+		diff.PreviousImageDigest, err = imageDigest(ctx, diff.PreviousImage)
+		if err != nil {
+			log.Errorf("unable to get digest of image %s: %v", diff.PreviousImage, err)
+		}
+	}
+	previousManifest, err := versionManifest(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentManifest, err := versionManifest(current)
+	if err != nil {
+		return nil, err
+	}
+	diff.Manifest = lineDiff(previousManifest, currentManifest)
+	return diff, nil
+}
+
+// recordDeployDiff stores in the deploy event what changed since the
+// previous version and the manifests rendered for the deployed one. Failures
+// are only logged, the deploy itself is already done.
+func recordDeployDiff(ctx context.Context, opts *DeployOptions, previous appTypes.AppVersion, imageID string, archive *digestReader) {
+	current, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, opts.App, imageID)
+	if err != nil {
+		log.Errorf("unable to find deployed version %s of app %s: %v", imageID, opts.App.Name, err)
+		return
+	}
+	otherData := deployOtherData{
+		ImageSignature: opts.imageSignature,
+		Manifests:      deployManifests(ctx, opts.App, current),
+	}
+	if opts.GetKind() == provisionTypes.DeployGitURL {
+		otherData.Commit = opts.Commit
+	}
+	otherData.DeployDiff, err = newDeployDiff(ctx, previous, current, archive)
+	if err != nil {
+		log.Errorf("unable to compute deploy diff for app %s: %v", opts.App.Name, err)
+	}
+	err = opts.Event.SetOtherCustomData(ctx, otherData)
+	if err != nil {
+		log.Errorf("unable to store deploy diff for app %s: %v", opts.App.Name, err)
+	}
+}
+
+func previousVersion(ctx context.Context, app *appTypes.App) (appTypes.AppVersion, error) {
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
+	if errors.Cause(err) == appTypes.ErrNoVersionsAvailable {
+		return nil, nil
+	}
+	return version, err
+}
+
+// lineDiff returns the lines removed from a, prefixed by "-", and added to b,
+// prefixed by "+", keeping the lines in common prefixed by a space. An empty
+// string is returned when a and b are equal.
+func lineDiff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	aLines, bLines := diffLines(a), diffLines(b)
+	// lcs[i][j] is the length of the longest common subsequence of
+	// aLines[i:] and bLines[j:].
+	lcs := make([][]int, len(aLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bLines)+1)
+	}
+	for i := len(aLines) - 1; i >= 0; i-- {
+		for j := len(bLines) - 1; j >= 0; j-- {
+			if aLines[i] == bLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(aLines) || j < len(bLines) {
+		switch {
+		case i < len(aLines) && j < len(bLines) && aLines[i] == bLines[j]:
+			out.WriteString(" " + aLines[i] + "\n")
+			i++
+			j++
+		case i < len(aLines) && (j == len(bLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("-" + aLines[i] + "\n")
+			i++
+		default:
+			out.WriteString("+" + bLines[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
+
+func diffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
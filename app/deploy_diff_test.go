@@ -0,0 +1,96 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestLineDiff(c *check.C) {
+	c.Assert(lineDiff("a\nb\n", "a\nb\n"), check.Equals, "")
+	c.Assert(lineDiff("a\nb\nc\n", "a\nc\nd\n"), check.Equals, " a\n-b\n c\n+d\n")
+	c.Assert(lineDiff("a\n", "b\n"), check.Equals, "-a\n+b\n")
+	c.Assert(lineDiff("", "a\n"), check.Equals, "+a\n")
+}
+
+func (s *S) TestDeployStoresDiff(c *check.C) {
+	oldDigest := imageDigest
+	defer func() { imageDigest = oldDigest }()
+	imageDigest = func(ctx context.Context, image string) (string, error) {
+		return "sha256:digest-of-" + image, nil
+	}
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	processes := []map[string][]string{
+		{"web": {"python app.py"}},
+		{"web": {"python app.py"}, "worker": {"python worker.py"}},
+	}
+	var evts []*event.Event
+	for i, procs := range processes {
+		s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+			_, err := io.ReadAll(opts.ArchiveFile)
+			c.Assert(err, check.IsNil)
+			version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+				App: app,
+			})
+			c.Assert(err, check.IsNil)
+			err = version.AddData(appTypes.AddVersionDataArgs{Processes: procs})
+			c.Assert(err, check.IsNil)
+			return version, version.CommitBuildImage()
+		}
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:   eventTypes.Target{Type: "app", Value: a.Name},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		archive := strings.NewReader([]string{"first", "second"}[i])
+		_, err = Deploy(context.TODO(), DeployOptions{
+			App:          &a,
+			File:         io.NopCloser(archive),
+			FileSize:     int64(archive.Len()),
+			OutputStream: io.Discard,
+			Event:        evt,
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(context.TODO(), nil)
+		c.Assert(err, check.IsNil)
+		evts = append(evts, evt)
+	}
+	first, err := GetDeploy(context.TODO(), evts[0].UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(first.DeployDiff, check.NotNil)
+	c.Assert(first.DeployDiff.ArchiveDigest, check.Equals, "sha256:a7937b64b8caa58f03721bb6bacf5c78cb235febe0e70b1b84cd99541461a08e")
+	c.Assert(first.DeployDiff.Image, check.Not(check.Equals), "")
+	c.Assert(first.DeployDiff.ImageDigest, check.Equals, "sha256:digest-of-"+first.DeployDiff.Image)
+	c.Assert(first.DeployDiff.PreviousVersion, check.Equals, 0)
+	c.Assert(first.DeployDiff.Manifest, check.Equals, "")
+	second, err := GetDeploy(context.TODO(), evts[1].UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(second.DeployDiff, check.NotNil)
+	c.Assert(second.DeployDiff.ArchiveDigest, check.Equals, "sha256:16367aacb67a4a017c8da8ab95682ccb390863780f7114dda0a0e0c55644c7c4")
+	c.Assert(second.DeployDiff.ImageDigest, check.Equals, "sha256:digest-of-"+second.DeployDiff.Image)
+	c.Assert(second.DeployDiff.PreviousVersion, check.Equals, 1)
+	c.Assert(second.DeployDiff.PreviousImage, check.Equals, first.DeployDiff.Image)
+	c.Assert(second.DeployDiff.PreviousImageDigest, check.Equals, "sha256:digest-of-"+first.DeployDiff.Image)
+	c.Assert(second.DeployDiff.Manifest, check.Equals, ` processes:
+   web:
+   - python app.py
++  worker:
++  - python worker.py
+`)
+}
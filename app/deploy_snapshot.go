@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrDeploySnapshotNotFound = errors.New("deploy not found or without a snapshot of the app configuration")
+
+// DeploySnapshot is the configuration of an app captured by a successful
+// deploy, identified by the deploy event. Rollbacks to the deploy restore it
+// along with the image. Snapshots are kept apart from the event because
+// private environment variables must not be exposed with the event data.
+type DeploySnapshot struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	App       string
+	Image     string
+	Env       map[string]bindTypes.EnvVar
+	Plan      appTypes.Plan
+	CreatedAt time.Time
+}
+
+func saveDeploySnapshot(ctx context.Context, opts *DeployOptions, imageID string) error {
+	collection, err := storagev2.DeploySnapshotsCollection()
+	if err != nil {
+		return err
+	}
+	snapshot := DeploySnapshot{
+		ID:        opts.Event.UniqueID,
+		App:       opts.App.Name,
+		Image:     imageID,
+		Env:       opts.App.Env,
+		Plan:      opts.App.Plan,
+		CreatedAt: time.Now().UTC(),
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": snapshot.ID}, snapshot, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetDeploySnapshot returns the snapshot taken by a deploy of the app.
+func GetDeploySnapshot(ctx context.Context, app *appTypes.App, deployID string) (*DeploySnapshot, error) {
+	id, err := primitive.ObjectIDFromHex(deployID)
+	if err != nil {
+		return nil, ErrDeploySnapshotNotFound
+	}
+	collection, err := storagev2.DeploySnapshotsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var snapshot DeploySnapshot
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "app": app.Name}).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrDeploySnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// restoreDeploySnapshot brings back the environment variables and plan of
+// the snapshot, without restarting the app, as the deploy rolling back to it
+// will replace its units anyway.
+func restoreDeploySnapshot(ctx context.Context, app *appTypes.App, snapshot *DeploySnapshot, w io.Writer) error {
+	fmt.Fprintf(w, "---- Restoring environment variables and plan from deploy %s ----\n", snapshot.ID.Hex())
+	return saveEnvAndPlan(ctx, app, snapshot.Env, snapshot.Plan)
+}
+
+func saveEnvAndPlan(ctx context.Context, app *appTypes.App, env map[string]bindTypes.EnvVar, plan appTypes.Plan) error {
+	restored := *app
+	restored.Env = env
+	restored.Plan = plan
+	err := validatePlan(ctx, &restored)
+	if err != nil {
+		return err
+	}
+	err = checkEnvSchema(&restored, nil)
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"env": env, "plan": plan},
+	})
+	if err != nil {
+		return err
+	}
+	app.Env = env
+	app.Plan = plan
+	recordConfigSnapshot(ctx, app)
+	return nil
+}
+
+func removeDeploySnapshots(ctx context.Context, appName string) error {
+	collection, err := storagev2.DeploySnapshotsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"app": appName})
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) newDeployEvent(c *check.C, a *appTypes.App) *event.Event {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestRollbackToDeployRestoresSnapshot(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "old"}},
+	})
+	c.Assert(err, check.IsNil)
+	firstEvt := s.newDeployEvent(c, &a)
+	imageID, err := Deploy(context.TODO(), DeployOptions{
+		App:          &a,
+		File:         io.NopCloser(strings.NewReader("my file")),
+		OutputStream: io.Discard,
+		Event:        firstEvt,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(firstEvt.Done(context.TODO(), nil), check.IsNil)
+	snapshot, err := GetDeploySnapshot(context.TODO(), &a, firstEvt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshot.Image, check.Equals, imageID)
+	c.Assert(snapshot.Env["MODE"].Value, check.Equals, "old")
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "new"}, {Name: "EXTRA", Value: "1"}},
+	})
+	c.Assert(err, check.IsNil)
+	var buf strings.Builder
+	_, err = Deploy(context.TODO(), DeployOptions{
+		App:              &a,
+		Image:            snapshot.Image,
+		Rollback:         true,
+		RollbackDeployID: firstEvt.UniqueID.Hex(),
+		OutputStream:     &buf,
+		Event:            s.newDeployEvent(c, &a),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, "(?s).*Restoring environment variables and plan from deploy.*")
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["MODE"].Value, check.Equals, "old")
+	_, ok := dbApp.Env["EXTRA"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestGetDeploySnapshotNotFound(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = GetDeploySnapshot(context.TODO(), &a, "invalid")
+	c.Assert(err, check.Equals, ErrDeploySnapshotNotFound)
+	evt := s.newDeployEvent(c, &a)
+	_, err = GetDeploySnapshot(context.TODO(), &a, evt.UniqueID.Hex())
+	c.Assert(err, check.Equals, ErrDeploySnapshotNotFound)
+}
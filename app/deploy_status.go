@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const (
+	RolloutStatusProgressing = "progressing"
+	RolloutStatusReady       = "ready"
+	RolloutStatusFailed      = "failed"
+)
+
+var ErrNoDeploys = errors.New("the app has no deploys")
+
+// VersionUnitsStatus counts the units of a version of the app and how many
+// of them are ready.
+type VersionUnitsStatus struct {
+	Version int `json:"version"`
+	Ready   int `json:"ready"`
+	Total   int `json:"total"`
+}
+
+// RolloutStatus is the state of the latest deploy of an app, meant to be
+// polled by automation waiting for the rollout to complete.
+type RolloutStatus struct {
+	App            string                  `json:"app"`
+	DeployID       string                  `json:"deployID"`
+	Status         string                  `json:"status"`
+	Paused         bool                    `json:"paused,omitempty"`
+	Progress       eventTypes.ProgressInfo `json:"progress"`
+	Units          []VersionUnitsStatus    `json:"units"`
+	FailedReason   string                  `json:"failedReason,omitempty"`
+	StartTime      time.Time               `json:"startTime"`
+	EndTime        time.Time               `json:"endTime"`
+	ElapsedSeconds float64                 `json:"elapsedSeconds"`
+}
+
+// DeployRolloutStatus returns the state of the latest deploy of the app,
+// along with the readiness of its units grouped by version.
+func DeployRolloutStatus(ctx context.Context, app *appTypes.App) (*RolloutStatus, error) {
+	evts, err := event.List(ctx, &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: app.Name},
+		KindType:  eventTypes.KindTypePermission,
+		KindNames: []string{permission.PermAppDeploy.FullName()},
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(evts) == 0 {
+		return nil, ErrNoDeploys
+	}
+	evt := evts[0]
+	status := &RolloutStatus{
+		App:       app.Name,
+		DeployID:  evt.UniqueID.Hex(),
+		Progress:  evt.ProgressInfo,
+		StartTime: evt.StartTime,
+		EndTime:   evt.EndTime,
+	}
+	switch {
+	case evt.Running:
+		status.Status = RolloutStatusProgressing
+		status.Paused = evt.PauseInfo.Paused
+		status.ElapsedSeconds = time.Since(evt.StartTime).Seconds()
+	case evt.Error != "":
+		status.Status = RolloutStatusFailed
+		status.FailedReason = evt.Error
+		status.ElapsedSeconds = evt.EndTime.Sub(evt.StartTime).Seconds()
+	default:
+		status.Status = RolloutStatusReady
+		status.ElapsedSeconds = evt.EndTime.Sub(evt.StartTime).Seconds()
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*VersionUnitsStatus{}
+	for _, u := range units {
+		versionStatus, ok := byVersion[u.Version]
+		if !ok {
+			versionStatus = &VersionUnitsStatus{Version: u.Version}
+			byVersion[u.Version] = versionStatus
+		}
+		versionStatus.Total++
+		if u.Ready != nil && *u.Ready {
+			versionStatus.Ready++
+		}
+	}
+	status.Units = make([]VersionUnitsStatus, 0, len(byVersion))
+	for _, versionStatus := range byVersion {
+		status.Units = append(status.Units, *versionStatus)
+	}
+	sort.Slice(status.Units, func(i, j int) bool { return status.Units[i].Version < status.Units[j].Version })
+	return status, nil
+}
@@ -10,15 +10,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/builder/buildertest"
 	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -65,7 +70,7 @@ func (s *S) TestListAppDeploysMarshalJSON(c *check.C) {
 		{App: "g1", Timestamp: time.Now(), Log: "logs", Diff: "diff", Commit: "abcdef1234567890", Message: "my awesome commit..."},
 	}
 	insertDeploysAsEvents(insert, c)
-	deploys, err := ListDeploys(context.TODO(), nil, 0, 0)
+	deploys, err := ListDeploys(context.TODO(), nil, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	c.Assert(deploys, check.HasLen, 3)
 	data, err := json.Marshal(&deploys)
@@ -97,7 +102,7 @@ func (s *S) TestListAppDeploys(c *check.C) {
 		{App: "g1", Timestamp: time.Now(), Log: "logs", Diff: "diff"},
 	}
 	insertDeploysAsEvents(insert, c)
-	deploys, err := ListDeploys(context.TODO(), nil, 0, 0)
+	deploys, err := ListDeploys(context.TODO(), nil, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	c.Assert(deploys, check.HasLen, 2)
 	expected := []DeployData{insert[1], insert[0]}
@@ -128,7 +133,7 @@ func (s *S) TestListAppDeploysWithImage(c *check.C) {
 	}
 	insertDeploysAsEvents(insert, c)
 	expected := []DeployData{expectedDeploy[1], expectedDeploy[0]}
-	deploys, err := ListDeploys(context.TODO(), nil, 0, 0)
+	deploys, err := ListDeploys(context.TODO(), nil, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	c.Assert(deploys, check.HasLen, 2)
 	normalizeTS(deploys)
@@ -204,18 +209,18 @@ func (s *S) TestListFilteredDeploys(c *check.C) {
 	normalizeTS(expected)
 	f := &Filter{}
 	f.ExtraIn("teams", team.Name)
-	deploys, err := ListDeploys(context.TODO(), f, 0, 0)
+	deploys, err := ListDeploys(context.TODO(), f, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	normalizeTS(deploys)
 	c.Assert(deploys, check.DeepEquals, []DeployData{expected[0]})
 	f = &Filter{}
 	f.ExtraIn("name", "g1")
-	deploys, err = ListDeploys(context.TODO(), f, 0, 0)
+	deploys, err = ListDeploys(context.TODO(), f, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	normalizeTS(deploys)
 	c.Assert(deploys, check.DeepEquals, []DeployData{expected[1]})
 	f = &Filter{}
-	deploys, err = ListDeploys(context.TODO(), f, 0, 0)
+	deploys, err = ListDeploys(context.TODO(), f, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	normalizeTS(deploys)
 	c.Assert(deploys, check.DeepEquals, []DeployData{expected[0], expected[1]})
@@ -259,19 +264,19 @@ func (s *S) TestListFilteredDeploysWithDisabledRollback(c *check.C) {
 	normalizeTS(expected)
 	f := &Filter{}
 	f.ExtraIn("teams", team.Name)
-	deploys, err := ListDeploys(context.TODO(), f, 0, 0)
+	deploys, err := ListDeploys(context.TODO(), f, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	normalizeTS(deploys)
 	c.Assert(deploys, check.HasLen, 1)
 	c.Assert(deploys, check.DeepEquals, []DeployData{expected[0]})
 	f = &Filter{}
 	f.ExtraIn("name", "g1")
-	deploys, err = ListDeploys(context.TODO(), f, 0, 0)
+	deploys, err = ListDeploys(context.TODO(), f, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	normalizeTS(deploys)
 	c.Assert(deploys, check.DeepEquals, []DeployData{expected[1]})
 	f = &Filter{}
-	deploys, err = ListDeploys(context.TODO(), f, 0, 0)
+	deploys, err = ListDeploys(context.TODO(), f, DeployListOptions{})
 	c.Assert(err, check.IsNil)
 	normalizeTS(deploys)
 	c.Assert(deploys, check.DeepEquals, []DeployData{expected[0], expected[1]})
@@ -310,7 +315,7 @@ func (s *S) TestListAllDeploysSkipAndLimit(c *check.C) {
 	expected := []DeployData{insert[2], insert[1]}
 	expected[0].Origin = "git"
 	expected[1].Origin = "git"
-	deploys, err := ListDeploys(context.TODO(), nil, 1, 2)
+	deploys, err := ListDeploys(context.TODO(), nil, DeployListOptions{Skip: 1, Limit: 2})
 	c.Assert(err, check.IsNil)
 	c.Assert(deploys, check.HasLen, 2)
 	normalizeTS(deploys)
@@ -318,6 +323,80 @@ func (s *S) TestListAllDeploysSkipAndLimit(c *check.C) {
 	c.Assert(deploys, check.DeepEquals, expected)
 }
 
+func (s *S) TestListDeploysCursor(c *check.C) {
+	a := appTypes.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	insert := []DeployData{
+		{App: "app1", Commit: "v1", Timestamp: now.Add(-30 * time.Second)},
+		{App: "app1", Commit: "v2", Timestamp: now.Add(-20 * time.Second)},
+		{App: "app1", Commit: "v3", Timestamp: now.Add(-20 * time.Second)},
+		{App: "app1", Commit: "v4", Timestamp: now},
+	}
+	insertDeploysAsEvents(insert, c)
+	var commits []string
+	cursor := ""
+	for {
+		deploys, err := ListDeploys(context.TODO(), nil, DeployListOptions{Limit: 2, Cursor: cursor})
+		c.Assert(err, check.IsNil)
+		if len(deploys) == 0 {
+			break
+		}
+		for _, d := range deploys {
+			commits = append(commits, d.Commit)
+		}
+		cursor = deploys[len(deploys)-1].ID.Hex()
+	}
+	c.Assert(commits, check.HasLen, 4)
+	c.Assert(commits[0], check.Equals, "v4")
+	c.Assert(commits[3], check.Equals, "v1")
+	_, err = ListDeploys(context.TODO(), nil, DeployListOptions{Cursor: "invalid"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *S) TestListDeploysByStatusAndDate(c *check.C) {
+	a := appTypes.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	insert := []DeployData{
+		{App: "app1", Commit: "old", Timestamp: now.Add(-2 * time.Hour)},
+		{App: "app1", Commit: "new", Timestamp: now.Add(-time.Minute)},
+	}
+	insertDeploysAsEvents(insert, c)
+	opts := &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "app1"},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	}
+	failed, err := event.New(context.TODO(), opts)
+	c.Assert(err, check.IsNil)
+	err = failed.Done(context.TODO(), errors.New("deploy failed"))
+	c.Assert(err, check.IsNil)
+	running, err := event.New(context.TODO(), opts)
+	c.Assert(err, check.IsNil)
+	deploys, err := ListDeploys(context.TODO(), nil, DeployListOptions{Status: DeployStatusFailed})
+	c.Assert(err, check.IsNil)
+	c.Assert(deploys, check.HasLen, 1)
+	c.Assert(deploys[0].Error, check.Equals, "deploy failed")
+	deploys, err = ListDeploys(context.TODO(), nil, DeployListOptions{Status: DeployStatusRunning})
+	c.Assert(err, check.IsNil)
+	c.Assert(deploys, check.HasLen, 1)
+	c.Assert(deploys[0].ID, check.Equals, running.UniqueID)
+	deploys, err = ListDeploys(context.TODO(), nil, DeployListOptions{Status: DeployStatusSuccess, Since: now.Add(-time.Hour)})
+	c.Assert(err, check.IsNil)
+	c.Assert(deploys, check.HasLen, 1)
+	c.Assert(deploys[0].Commit, check.Equals, "new")
+	deploys, err = ListDeploys(context.TODO(), nil, DeployListOptions{Until: now.Add(-time.Hour)})
+	c.Assert(err, check.IsNil)
+	c.Assert(deploys, check.HasLen, 1)
+	c.Assert(deploys[0].Commit, check.Equals, "old")
+	_, err = ListDeploys(context.TODO(), nil, DeployListOptions{Status: "done"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
 func (s *S) TestGetDeploy(c *check.C) {
 	a := appTypes.App{
 		Name:      "g1",
@@ -416,6 +495,52 @@ func (s *S) TestDeployAppUpload(c *check.C) {
 	err = appsCollection.FindOne(context.TODO(), mongoBSON.M{"name": "some-app"}).Decode(&updatedApp)
 	c.Assert(err, check.IsNil)
 	c.Assert(updatedApp.UpdatePlatform, check.Equals, false)
+	dbEvt, err := event.GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.ProgressInfo.Step, check.Equals, "done")
+	c.Assert(dbEvt.ProgressInfo.Percent, check.Equals, provision.DeployProgressDone)
+}
+
+func (s *S) TestDeployAppGitURL(c *check.C) {
+	server, err := buildertest.NewGitServer()
+	c.Assert(err, check.IsNil)
+	defer server.Close()
+	repoURL, commit, err := server.NewRepository("app.git", map[string]string{"Procfile": "web: ./run"})
+	c.Assert(err, check.IsNil)
+	os.Setenv("GIT_SSL_NO_VERIFY", "1")
+	defer os.Unsetenv("GIT_SSL_NO_VERIFY")
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	writer := &bytes.Buffer{}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	opts := DeployOptions{
+		App:          &a,
+		GitURL:       repoURL,
+		OutputStream: writer,
+		Event:        evt,
+	}
+	c.Assert(opts.GetKind(), check.Equals, provisionTypes.DeployGitURL)
+	_, err = Deploy(context.TODO(), opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(writer.String(), check.Matches, "(?s).*Resolved HEAD to commit "+commit+".*Builder deploy called.*")
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	deploy, err := GetDeploy(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(deploy.Commit, check.Equals, commit)
 }
 
 func (s *S) TestDeployAppImage(c *check.C) {
@@ -455,6 +580,50 @@ func (s *S) TestDeployAppImage(c *check.C) {
 	c.Assert(updatedApp.UpdatePlatform, check.Equals, true)
 }
 
+func (s *S) TestDeployAppImageFromBuild(c *check.C) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+		App:            &a,
+		CustomBuildTag: "mytag",
+	})
+	c.Assert(err, check.IsNil)
+	err = version.CommitBaseImage()
+	c.Assert(err, check.IsNil)
+	repository, _ := image.SplitImageName(version.VersionInfo().DeployImage)
+	for _, imageName := range []string{version.VersionInfo().DeployImage, repository + ":mytag"} {
+		writer := &bytes.Buffer{}
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:   eventTypes.Target{Type: "app", Value: a.Name},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		imageID, err := Deploy(context.TODO(), DeployOptions{
+			App:          &a,
+			Image:        imageName,
+			OutputStream: writer,
+			Event:        evt,
+		})
+		c.Assert(err, check.IsNil)
+		c.Assert(imageID, check.Equals, version.VersionInfo().DeployImage)
+		c.Assert(writer.String(), check.Matches, "(?s).*Deploying image .* from a previous build.*")
+		err = evt.Done(context.TODO(), nil)
+		c.Assert(err, check.IsNil)
+	}
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions.Versions, check.HasLen, 1)
+}
+
 func (s *S) TestDeployAppWithUpdatedPlatform(c *check.C) {
 	appsCollection, err := storagev2.AppsCollection()
 	c.Assert(err, check.IsNil)
@@ -846,6 +1015,30 @@ func (s *S) TestValidateOrigin(c *check.C) {
 	c.Assert(ValidateOrigin("invalid"), check.Equals, false)
 }
 
+func (s *S) TestValidateRollback(c *check.C) {
+	a := &appTypes.App{Name: "myapp", Pool: s.Pool}
+	err := ValidateRollback(context.TODO(), DeployOptions{App: a, Rollback: true})
+	c.Assert(err, check.IsNil)
+	err = pool.PoolUpdate(context.TODO(), s.Pool, pool.UpdatePoolOptions{Labels: map[string]string{"rollback-require-reason": "true"}})
+	c.Assert(err, check.IsNil)
+	err = ValidateRollback(context.TODO(), DeployOptions{App: a, Rollback: true, RollbackReason: " "})
+	c.Assert(err, check.ErrorMatches, `pool "pool1" requires a reason to rollback apps`)
+	err = ValidateRollback(context.TODO(), DeployOptions{App: a, Rollback: true, RollbackReason: "bad release"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDeployOptionsAnnotations(c *check.C) {
+	opts := DeployOptions{Image: "myimg", RollbackReason: "bad release", IncidentReference: "INC-1"}
+	c.Assert(opts.annotations(), check.IsNil)
+	opts = DeployOptions{Rollback: true, RollbackReason: "bad release", IncidentReference: "INC-1"}
+	c.Assert(opts.annotations(), check.DeepEquals, map[string]string{
+		"tsuru.io/rollback-reason":             "bad release",
+		"tsuru.io/rollback-incident-reference": "INC-1",
+	})
+	opts = DeployOptions{Rollback: true}
+	c.Assert(opts.annotations(), check.IsNil)
+}
+
 func (s *S) TestIncrementDeploy(c *check.C) {
 	appsCollection, err := storagev2.AppsCollection()
 	c.Assert(err, check.IsNil)
@@ -0,0 +1,337 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/log"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// deployUploadExpiration is how long an upload is kept after its last
+	// chunk was received.
+	deployUploadExpiration = 24 * time.Hour
+
+	defaultDeployArchiveMaxSize = 1024 * 1024 * 1024
+	defaultDeployUploadsPerApp  = 5
+)
+
+var (
+	ErrDeployUploadNotFound   = errors.New("deploy upload not found")
+	ErrDeployUploadIncomplete = errors.New("deploy upload is incomplete")
+	ErrDeployUploadEmptyChunk = errors.New("deploy upload chunk is empty")
+	ErrDeployUploadTooLarge   = errors.New("deploy upload chunk exceeds the archive size")
+	ErrDeployUploadsLimit     = errors.New("too many deploy uploads in progress for the app")
+)
+
+// ErrDeployUploadOffset is returned when a chunk does not start where the
+// upload stopped. Clients resume the upload from Expected.
+type ErrDeployUploadOffset struct {
+	Expected int64
+}
+
+func (e *ErrDeployUploadOffset) Error() string {
+	return fmt.Sprintf("unexpected chunk offset, upload continues from offset %d", e.Expected)
+}
+
+// DeployUpload is an archive being uploaded in chunks, to be deployed once
+// complete. Chunks are stored in GridFS, so they may be sent to any API
+// instance.
+type DeployUpload struct {
+	ID        primitive.ObjectID   `json:"id" bson:"_id"`
+	App       string               `json:"app"`
+	Owner     string               `json:"owner"`
+	Size      int64                `json:"size"`
+	Received  int64                `json:"received"`
+	ChunkIDs  []primitive.ObjectID `json:"-"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+// ErrDeployArchiveTooLarge is returned when an archive is larger than
+// deploy:max-archive-size.
+type ErrDeployArchiveTooLarge struct {
+	Max int64
+}
+
+func (e *ErrDeployArchiveTooLarge) Error() string {
+	return fmt.Sprintf("deploy archive exceeds the maximum size of %d bytes", e.Max)
+}
+
+// DeployArchiveMaxSize returns the maximum size of archives sent to deploys,
+// either directly or in chunked uploads.
+func DeployArchiveMaxSize() int64 {
+	size, err := config.GetInt("deploy:max-archive-size")
+	if err != nil || size <= 0 {
+		return defaultDeployArchiveMaxSize
+	}
+	return int64(size)
+}
+
+func deployUploadsPerApp() int64 {
+	limit, err := config.GetInt("deploy:uploads-per-app")
+	if err != nil || limit <= 0 {
+		return defaultDeployUploadsPerApp
+	}
+	return int64(limit)
+}
+
+// NewDeployUpload starts the upload of an archive with the given size. At
+// most deploy:uploads-per-app uploads may be in progress for each app,
+// abandoned ones expire after a day.
+func NewDeployUpload(ctx context.Context, app *appTypes.App, owner string, size int64) (*DeployUpload, error) {
+	if size <= 0 {
+		return nil, errors.New("deploy upload size must be greater than zero")
+	}
+	if maxSize := DeployArchiveMaxSize(); size > maxSize {
+		return nil, &ErrDeployArchiveTooLarge{Max: maxSize}
+	}
+	err := removeExpiredDeployUploads(ctx, time.Now())
+	if err != nil {
+		log.Errorf("unable to remove expired deploy uploads: %v", err)
+	}
+	collection, err := storagev2.DeployUploadsCollection()
+	if err != nil {
+		return nil, err
+	}
+	inProgress, err := collection.CountDocuments(ctx, mongoBSON.M{"app": app.Name})
+	if err != nil {
+		return nil, err
+	}
+	if inProgress >= deployUploadsPerApp() {
+		return nil, ErrDeployUploadsLimit
+	}
+	now := time.Now().UTC()
+	upload := &DeployUpload{
+		ID:        primitive.NewObjectID(),
+		App:       app.Name,
+		Owner:     owner,
+		Size:      size,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err = collection.InsertOne(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// GetDeployUpload returns an upload of the app started by owner.
+func GetDeployUpload(ctx context.Context, app *appTypes.App, owner, id string) (*DeployUpload, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrDeployUploadNotFound
+	}
+	collection, err := storagev2.DeployUploadsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var upload DeployUpload
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": objID, "app": app.Name, "owner": owner}).Decode(&upload)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrDeployUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Complete reports whether every byte of the archive was received.
+func (u *DeployUpload) Complete() bool {
+	return u.Received == u.Size
+}
+
+// AddChunk stores the chunk starting at offset, which must be the number of
+// bytes received so far. Concurrent chunks for the same offset are accepted
+// only once.
+func (u *DeployUpload) AddChunk(ctx context.Context, offset int64, r io.Reader) error {
+	if offset != u.Received {
+		return &ErrDeployUploadOffset{Expected: u.Received}
+	}
+	bucket, err := storagev2.DeployUploadChunksBucket()
+	if err != nil {
+		return err
+	}
+	counter := &countingReader{r: io.LimitReader(r, u.Size-offset+1)}
+	fileID, err := bucket.UploadFromStream(fmt.Sprintf("%s/%d", u.ID.Hex(), offset), counter,
+		options.GridFSUpload().SetMetadata(mongoBSON.M{"upload": u.ID, "offset": offset}))
+	if err != nil {
+		return err
+	}
+	discard := func(err error) error {
+		if delErr := bucket.DeleteContext(ctx, fileID); delErr != nil {
+			log.Errorf("unable to remove deploy upload chunk %s: %v", fileID.Hex(), delErr)
+		}
+		return err
+	}
+	if counter.n == 0 {
+		return discard(ErrDeployUploadEmptyChunk)
+	}
+	if offset+counter.n > u.Size {
+		return discard(ErrDeployUploadTooLarge)
+	}
+	collection, err := storagev2.DeployUploadsCollection()
+	if err != nil {
+		return discard(err)
+	}
+	now := time.Now().UTC()
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": u.ID, "received": offset}, mongoBSON.M{
+		"$inc":  mongoBSON.M{"received": counter.n},
+		"$push": mongoBSON.M{"chunkids": fileID},
+		"$set":  mongoBSON.M{"updatedat": now},
+	})
+	if err != nil {
+		return discard(err)
+	}
+	if result.MatchedCount == 0 {
+		var current DeployUpload
+		err = collection.FindOne(ctx, mongoBSON.M{"_id": u.ID}).Decode(&current)
+		if err == mongo.ErrNoDocuments {
+			return discard(ErrDeployUploadNotFound)
+		}
+		if err != nil {
+			return discard(err)
+		}
+		*u = current
+		return discard(&ErrDeployUploadOffset{Expected: u.Received})
+	}
+	u.Received += counter.n
+	u.ChunkIDs = append(u.ChunkIDs, fileID)
+	u.UpdatedAt = now
+	return nil
+}
+
+// Archive returns a reader for the assembled archive.
+func (u *DeployUpload) Archive(ctx context.Context) (io.ReadCloser, error) {
+	if !u.Complete() {
+		return nil, ErrDeployUploadIncomplete
+	}
+	bucket, err := storagev2.DeployUploadChunksBucket()
+	if err != nil {
+		return nil, err
+	}
+	return &deployUploadReader{bucket: bucket, ids: u.ChunkIDs}, nil
+}
+
+// Remove deletes the upload and its chunks.
+func (u *DeployUpload) Remove(ctx context.Context) error {
+	bucket, err := storagev2.DeployUploadChunksBucket()
+	if err != nil {
+		return err
+	}
+	cursor, err := bucket.FindContext(ctx, mongoBSON.M{"metadata.upload": u.ID})
+	if err != nil {
+		return err
+	}
+	var files []gridfs.File
+	err = cursor.All(ctx, &files)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		err = bucket.DeleteContext(ctx, f.ID)
+		if err != nil && err != gridfs.ErrFileNotFound {
+			return err
+		}
+	}
+	collection, err := storagev2.DeployUploadsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"_id": u.ID})
+	return err
+}
+
+func removeExpiredDeployUploads(ctx context.Context, now time.Time) error {
+	collection, err := storagev2.DeployUploadsCollection()
+	if err != nil {
+		return err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"updatedat": mongoBSON.M{"$lt": now.Add(-deployUploadExpiration)}})
+	if err != nil {
+		return err
+	}
+	var uploads []DeployUpload
+	err = cursor.All(ctx, &uploads)
+	if err != nil {
+		return err
+	}
+	for i := range uploads {
+		err = uploads[i].Remove(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// deployUploadReader reads the chunks of an upload in order, opening each
+// one only when the previous is exhausted.
+type deployUploadReader struct {
+	bucket  *gridfs.Bucket
+	ids     []primitive.ObjectID
+	current *gridfs.DownloadStream
+}
+
+func (r *deployUploadReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.ids) == 0 {
+				return 0, io.EOF
+			}
+			stream, err := r.bucket.OpenDownloadStream(r.ids[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current = stream
+			r.ids = r.ids[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *deployUploadReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
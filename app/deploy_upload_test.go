@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDeployUploadChunks(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	upload, err := NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 10)
+	c.Assert(err, check.IsNil)
+	err = upload.AddChunk(context.TODO(), 0, strings.NewReader("abcd"))
+	c.Assert(err, check.IsNil)
+	err = upload.AddChunk(context.TODO(), 2, strings.NewReader("cd"))
+	c.Assert(err, check.DeepEquals, &ErrDeployUploadOffset{Expected: 4})
+	err = upload.AddChunk(context.TODO(), 4, strings.NewReader(""))
+	c.Assert(err, check.Equals, ErrDeployUploadEmptyChunk)
+	_, err = upload.Archive(context.TODO())
+	c.Assert(err, check.Equals, ErrDeployUploadIncomplete)
+	stale, err := GetDeployUpload(context.TODO(), &a, "me@tsuru.io", upload.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(stale.Received, check.Equals, int64(4))
+	err = upload.AddChunk(context.TODO(), 4, strings.NewReader("efg"))
+	c.Assert(err, check.IsNil)
+	err = stale.AddChunk(context.TODO(), 4, strings.NewReader("xyz"))
+	c.Assert(err, check.DeepEquals, &ErrDeployUploadOffset{Expected: 7})
+	err = upload.AddChunk(context.TODO(), 7, strings.NewReader("hij"))
+	c.Assert(err, check.IsNil)
+	c.Assert(upload.Complete(), check.Equals, true)
+	archive, err := upload.Archive(context.TODO())
+	c.Assert(err, check.IsNil)
+	data, err := io.ReadAll(archive)
+	c.Assert(err, check.IsNil)
+	c.Assert(archive.Close(), check.IsNil)
+	c.Assert(string(data), check.Equals, "abcdefghij")
+	err = upload.Remove(context.TODO())
+	c.Assert(err, check.IsNil)
+	_, err = GetDeployUpload(context.TODO(), &a, "me@tsuru.io", upload.ID.Hex())
+	c.Assert(err, check.Equals, ErrDeployUploadNotFound)
+	bucket, err := storagev2.DeployUploadChunksBucket()
+	c.Assert(err, check.IsNil)
+	count, err := bucket.GetFilesCollection().CountDocuments(context.TODO(), mongoBSON.M{})
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, int64(0))
+}
+
+func (s *S) TestGetDeployUploadOtherOwner(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	upload, err := NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 10)
+	c.Assert(err, check.IsNil)
+	_, err = GetDeployUpload(context.TODO(), &a, "other@tsuru.io", upload.ID.Hex())
+	c.Assert(err, check.Equals, ErrDeployUploadNotFound)
+	_, err = GetDeployUpload(context.TODO(), &a, "me@tsuru.io", "invalid")
+	c.Assert(err, check.Equals, ErrDeployUploadNotFound)
+}
+
+func (s *S) TestNewDeployUploadLimits(c *check.C) {
+	config.Set("deploy:max-archive-size", 100)
+	config.Set("deploy:uploads-per-app", 2)
+	defer config.Unset("deploy")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 101)
+	c.Assert(err, check.DeepEquals, &ErrDeployArchiveTooLarge{Max: 100})
+	_, err = NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 100)
+	c.Assert(err, check.IsNil)
+	_, err = NewDeployUpload(context.TODO(), &a, "other@tsuru.io", 10)
+	c.Assert(err, check.IsNil)
+	_, err = NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 10)
+	c.Assert(err, check.Equals, ErrDeployUploadsLimit)
+}
+
+func (s *S) TestRemoveExpiredDeployUploads(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	old, err := NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 10)
+	c.Assert(err, check.IsNil)
+	err = old.AddChunk(context.TODO(), 0, strings.NewReader("abc"))
+	c.Assert(err, check.IsNil)
+	recent, err := NewDeployUpload(context.TODO(), &a, "me@tsuru.io", 10)
+	c.Assert(err, check.IsNil)
+	err = removeExpiredDeployUploads(context.TODO(), time.Now().Add(deployUploadExpiration-time.Minute))
+	c.Assert(err, check.IsNil)
+	_, err = GetDeployUpload(context.TODO(), &a, "me@tsuru.io", recent.ID.Hex())
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.DeployUploadsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"_id": old.ID}, mongoBSON.M{
+		"$set": mongoBSON.M{"updatedat": time.Now().Add(-2 * deployUploadExpiration)},
+	})
+	c.Assert(err, check.IsNil)
+	err = removeExpiredDeployUploads(context.TODO(), time.Now())
+	c.Assert(err, check.IsNil)
+	_, err = GetDeployUpload(context.TODO(), &a, "me@tsuru.io", old.ID.Hex())
+	c.Assert(err, check.Equals, ErrDeployUploadNotFound)
+	_, err = GetDeployUpload(context.TODO(), &a, "me@tsuru.io", recent.ID.Hex())
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+const discoveryManagedBy = "tsuru/discovery"
+
+var discoveryEnvInvalidChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// DiscoveryEntry describes how an app can be reached from inside the cluster
+// running it, without hairpinning through its public routers.
+type DiscoveryEntry struct {
+	App       string              `json:"app"`
+	Pool      string              `json:"pool"`
+	Endpoints []DiscoveryEndpoint `json:"endpoints"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// DiscoveryEndpoint is an in-cluster address of a process of an app. Version
+// is only set for addresses pointing to a single app version, those change on
+// every deploy and should only be used when pinning a version is intended.
+type DiscoveryEndpoint struct {
+	Process  string `json:"process"`
+	Version  string `json:"version,omitempty"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Port     int32  `json:"port"`
+	Address  string `json:"address"`
+	Env      string `json:"env"`
+}
+
+// DiscoveryEnvName returns the conventional environment variable name used to
+// inject the address of an app process in other apps, e.g.
+// TSURU_DISCOVERY_MYAPP_WEB or TSURU_DISCOVERY_MYAPP_WEB_V2 for a version.
+func DiscoveryEnvName(appName, process, version string) string {
+	parts := []string{"TSURU_DISCOVERY", appName, process}
+	if version != "" {
+		parts = append(parts, "V"+version)
+	}
+	name := strings.ToUpper(strings.Join(parts, "_"))
+	return discoveryEnvInvalidChars.ReplaceAllString(name, "_")
+}
+
+// Discover returns the in-cluster endpoints of the given apps. When version
+// is not empty, only endpoints of that version are returned in addition to
+// the unversioned ones. Failing to reach the provisioner of an app is
+// reported in the entry instead of aborting the whole discovery.
+func Discover(ctx context.Context, apps []*appTypes.App, version string) []DiscoveryEntry {
+	entries := make([]DiscoveryEntry, 0, len(apps))
+	for _, a := range apps {
+		entry := DiscoveryEntry{App: a.Name, Pool: a.Pool, Endpoints: []DiscoveryEndpoint{}}
+		addrs, err := internalAddresses(ctx, a)
+		if err != nil {
+			entry.Error = err.Error()
+			entries = append(entries, entry)
+			continue
+		}
+		for _, addr := range addrs {
+			if addr.Version != "" && version != "" && addr.Version != version {
+				continue
+			}
+			process := addr.Process
+			if process == "" {
+				process = provision.WebProcessName
+			}
+			entry.Endpoints = append(entry.Endpoints, DiscoveryEndpoint{
+				Process:  process,
+				Version:  addr.Version,
+				Protocol: strings.ToLower(addr.Protocol),
+				Host:     addr.Domain,
+				Port:     addr.Port,
+				Address:  fmt.Sprintf("%s:%d", addr.Domain, addr.Port),
+				Env:      DiscoveryEnvName(a.Name, process, addr.Version),
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// DiscoveryEnvs converts discovery entries into environment variables named
+// after DiscoveryEnvName, ready to be set in the apps calling them.
+func DiscoveryEnvs(entries []DiscoveryEntry) []bindTypes.EnvVar {
+	var envs []bindTypes.EnvVar
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		for _, endpoint := range entry.Endpoints {
+			// processes listening on more than one port are exported using
+			// their first port only.
+			if seen[endpoint.Env] {
+				continue
+			}
+			seen[endpoint.Env] = true
+			envs = append(envs, bindTypes.EnvVar{
+				Name:      endpoint.Env,
+				Value:     endpoint.Address,
+				Public:    true,
+				ManagedBy: discoveryManagedBy,
+			})
+		}
+	}
+	return envs
+}
+
+// SetDiscoveryEnvs injects the in-cluster addresses of the target apps in the
+// environment of app, replacing the ones previously injected. An empty list
+// of targets removes every discovery variable from the app.
+func SetDiscoveryEnvs(ctx context.Context, app *appTypes.App, targets []*appTypes.App, version string, w io.Writer, shouldRestart bool) error {
+	entries := Discover(ctx, targets, version)
+	for _, entry := range entries {
+		if entry.Error != "" {
+			return fmt.Errorf("unable to discover app %q: %s", entry.App, entry.Error)
+		}
+	}
+	return SetEnvs(ctx, app, bindTypes.SetEnvArgs{
+		Envs:          DiscoveryEnvs(entries),
+		ManagedBy:     discoveryManagedBy,
+		PruneUnused:   true,
+		ShouldRestart: shouldRestart,
+		Writer:        w,
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"strings"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDiscoveryEnvName(c *check.C) {
+	c.Assert(DiscoveryEnvName("my-app", "web", ""), check.Equals, "TSURU_DISCOVERY_MY_APP_WEB")
+	c.Assert(DiscoveryEnvName("myapp", "worker.1", "2"), check.Equals, "TSURU_DISCOVERY_MYAPP_WORKER_1_V2")
+}
+
+func (s *S) TestDiscover(c *check.C) {
+	a := appTypes.App{Name: "my-app", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	entries := Discover(context.TODO(), []*appTypes.App{&a}, "")
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].App, check.Equals, "my-app")
+	c.Assert(entries[0].Pool, check.Equals, s.Pool)
+	c.Assert(entries[0].Endpoints, check.HasLen, 4)
+	c.Assert(entries[0].Endpoints[0], check.DeepEquals, DiscoveryEndpoint{
+		Process:  "web",
+		Protocol: "tcp",
+		Host:     "my-app-web.fake-cluster.local",
+		Port:     80,
+		Address:  "my-app-web.fake-cluster.local:80",
+		Env:      "TSURU_DISCOVERY_MY_APP_WEB",
+	})
+	c.Assert(entries[0].Endpoints[3], check.DeepEquals, DiscoveryEndpoint{
+		Process:  "web",
+		Version:  "2",
+		Protocol: "tcp",
+		Host:     "my-app-web-v2.fake-cluster.local",
+		Port:     80,
+		Address:  "my-app-web-v2.fake-cluster.local:80",
+		Env:      "TSURU_DISCOVERY_MY_APP_WEB_V2",
+	})
+	entries = Discover(context.TODO(), []*appTypes.App{&a}, "3")
+	c.Assert(entries[0].Endpoints, check.HasLen, 2)
+	c.Assert(entries[0].Endpoints[0].Version, check.Equals, "")
+	c.Assert(entries[0].Endpoints[1].Version, check.Equals, "")
+}
+
+func (s *S) TestDiscoveryEnvs(c *check.C) {
+	entries := []DiscoveryEntry{
+		{App: "myapp", Endpoints: []DiscoveryEndpoint{
+			{Process: "web", Address: "myapp-web:80", Env: "TSURU_DISCOVERY_MYAPP_WEB"},
+			{Process: "web", Address: "myapp-web:8080", Env: "TSURU_DISCOVERY_MYAPP_WEB"},
+		}},
+		{App: "other", Error: "cluster unavailable", Endpoints: []DiscoveryEndpoint{}},
+	}
+	c.Assert(DiscoveryEnvs(entries), check.DeepEquals, []bindTypes.EnvVar{
+		{Name: "TSURU_DISCOVERY_MYAPP_WEB", Value: "myapp-web:80", Public: true, ManagedBy: "tsuru/discovery"},
+	})
+}
+
+func (s *S) TestSetDiscoveryEnvs(c *check.C) {
+	caller := appTypes.App{Name: "caller", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &caller, s.user)
+	c.Assert(err, check.IsNil)
+	callee := appTypes.App{Name: "callee", Platform: "python", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &callee, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &caller, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "TSURU_DISCOVERY_OLD_WEB", Value: "old-web:80", Public: true, ManagedBy: "tsuru/discovery"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = SetDiscoveryEnvs(context.TODO(), &caller, []*appTypes.App{&callee}, "", nil, false)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), caller.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["TSURU_DISCOVERY_CALLEE_WEB"], check.DeepEquals, bindTypes.EnvVar{
+		Name: "TSURU_DISCOVERY_CALLEE_WEB", Value: "callee-web.fake-cluster.local:80", Public: true, ManagedBy: "tsuru/discovery",
+	})
+	_, ok := dbApp.Env["TSURU_DISCOVERY_OLD_WEB"]
+	c.Assert(ok, check.Equals, false)
+	err = SetDiscoveryEnvs(context.TODO(), dbApp, nil, "", nil, false)
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), caller.Name)
+	c.Assert(err, check.IsNil)
+	for name := range dbApp.Env {
+		c.Assert(strings.HasPrefix(name, "TSURU_DISCOVERY_"), check.Equals, false)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+var ErrDriftProvisioner = errors.New("The current app provisioner does not support drift detection")
+
+// Drift compares the state of the app stored in tsuru with the objects
+// running in its provisioner and routers.
+func Drift(ctx context.Context, app *appTypes.App) ([]provision.Drift, error) {
+	return reconcile(ctx, app, nil, false)
+}
+
+// RepairDrift brings the objects of the app in its provisioner and routers
+// back in line with the state stored in tsuru, returning the drifts found.
+func RepairDrift(ctx context.Context, app *appTypes.App, w io.Writer) ([]provision.Drift, error) {
+	if w == nil {
+		w = io.Discard
+	}
+	return reconcile(ctx, app, w, true)
+}
+
+func reconcile(ctx context.Context, app *appTypes.App, w io.Writer, repair bool) ([]provision.Drift, error) {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	driftProv, ok := prov.(provision.DriftProvisioner)
+	if !ok {
+		return nil, ErrDriftProvisioner
+	}
+	var drifts []provision.Drift
+	if repair {
+		drifts, err = driftProv.RepairDrift(ctx, app, w)
+	} else {
+		drifts, err = driftProv.Drift(ctx, app)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !available(ctx, app) {
+		return drifts, nil
+	}
+	for _, appRouter := range GetRouters(app) {
+		drift, err := routerDrift(ctx, app, appRouter, w, repair)
+		if err != nil {
+			return nil, err
+		}
+		if drift != nil {
+			drifts = append(drifts, *drift)
+		}
+	}
+	return drifts, nil
+}
+
+func routerDrift(ctx context.Context, app *appTypes.App, appRouter appTypes.AppRouter, w io.Writer, repair bool) (*provision.Drift, error) {
+	r, err := router.Get(ctx, appRouter.Name)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.Addresses(ctx, app)
+	if err == nil {
+		return nil, nil
+	}
+	if err != router.ErrBackendNotFound {
+		return nil, err
+	}
+	drift := &provision.Drift{
+		App:     app.Name,
+		Kind:    provision.DriftKindRouter,
+		Name:    appRouter.Name,
+		Message: fmt.Sprintf("backend not found in router %s", appRouter.Name),
+	}
+	if !repair {
+		return drift, nil
+	}
+	err = rebuild.RebuildRoutesInRouter(ctx, appRouter, rebuild.RebuildRoutesOpts{
+		App:    app,
+		Writer: w,
+	})
+	if err != nil {
+		drift.Error = err.Error()
+	} else {
+		drift.Repaired = true
+	}
+	return drift, nil
+}
+
+// PoolDrift compares the state of every app in the pool with the objects
+// running in their provisioners and routers. When repair is true the drifts
+// found are also repaired. Apps whose provisioner does not support drift
+// detection are skipped.
+func PoolDrift(ctx context.Context, pool string, w io.Writer, repair bool) ([]provision.Drift, error) {
+	if w == nil {
+		w = io.Discard
+	}
+	apps, err := List(ctx, &Filter{Pool: pool})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	drifts := []provision.Drift{}
+	multiErr := tsuruErrors.NewMultiError()
+	for _, a := range apps {
+		appDrifts, err := reconcile(ctx, a, w, repair)
+		if err == ErrDriftProvisioner {
+			continue
+		}
+		if err != nil {
+			multiErr.Add(errors.Wrapf(err, "unable to check drift of app %q", a.Name))
+			continue
+		}
+		drifts = append(drifts, appDrifts...)
+	}
+	return drifts, multiErr.ToError()
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createDriftedApp(c *check.C, name string) *appTypes.App {
+	a := appTypes.App{Name: name, Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddDrift(&a, provision.Drift{
+		App:     name,
+		Kind:    provision.DriftKindService,
+		Name:    name + "-web",
+		Process: "web",
+		Message: "service " + name + "-web of process web not found",
+	})
+	return &a
+}
+
+func (s *S) TestDriftAndRepairDrift(c *check.C) {
+	a := s.createDriftedApp(c, "my-app")
+	drifts, err := Drift(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.DeepEquals, []provision.Drift{
+		{App: "my-app", Kind: provision.DriftKindService, Name: "my-app-web", Process: "web", Message: "service my-app-web of process web not found"},
+	})
+	drifts, err = RepairDrift(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 1)
+	c.Assert(drifts[0].Repaired, check.Equals, true)
+	drifts, err = Drift(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 0)
+}
+
+func (s *S) TestPoolDrift(c *check.C) {
+	s.createDriftedApp(c, "my-app2")
+	s.createDriftedApp(c, "my-app1")
+	drifts, err := PoolDrift(context.TODO(), s.Pool, nil, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 2)
+	c.Assert(drifts[0].App, check.Equals, "my-app1")
+	c.Assert(drifts[1].App, check.Equals, "my-app2")
+	c.Assert(drifts[0].Repaired, check.Equals, false)
+}
+
+func (s *S) TestRunReconcile(c *check.C) {
+	a := s.createDriftedApp(c, "my-app")
+	err := runReconcile(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	drifts, err := Drift(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 0)
+	err = runReconcile(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "my-app"},
+		KindNames: []string{"reconcile"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+}
+
+func (s *S) TestRunReconcileDryRun(c *check.C) {
+	a := s.createDriftedApp(c, "my-app")
+	err := runReconcile(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	drifts, err := Drift(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 1)
+}
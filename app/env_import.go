@@ -0,0 +1,204 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// EnvImportMerge sets the imported envs, keeping the other envs of the
+	// app.
+	EnvImportMerge = "merge"
+	// EnvImportReplace sets the imported envs, removing the envs of the app
+	// not in the import. Envs managed by other tools are kept.
+	EnvImportReplace = "replace"
+)
+
+// ImportEnvsArgs holds a full set of environment variables to be imported in
+// an app.
+type ImportEnvsArgs struct {
+	Envs          []bindTypes.EnvVar
+	Mode          string
+	DryRun        bool
+	ShouldRestart bool
+	Writer        io.Writer
+}
+
+// ImportEnvs applies a full set of environment variables to the app in a
+// single update, restarting it at most once. With DryRun the changes are
+// only computed and reported.
+func ImportEnvs(ctx context.Context, app *appTypes.App, args ImportEnvsArgs) (bindTypes.EnvsDiff, error) {
+	var diff bindTypes.EnvsDiff
+	if args.Mode == "" {
+		args.Mode = EnvImportMerge
+	}
+	if args.Mode != EnvImportMerge && args.Mode != EnvImportReplace {
+		return diff, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid import mode %q, must be %q or %q", args.Mode, EnvImportMerge, EnvImportReplace)}
+	}
+	imported := make(map[string]bindTypes.EnvVar, len(args.Envs))
+	names := make([]string, 0, len(args.Envs))
+	for _, env := range args.Envs {
+		if err := validateEnv(env.Name); err != nil {
+			return diff, err
+		}
+		if _, ok := imported[env.Name]; ok {
+			return diff, &tsuruErrors.ValidationError{Message: fmt.Sprintf("environment variable %q is imported more than once", env.Name)}
+		}
+		imported[env.Name] = env
+		names = append(names, env.Name)
+	}
+	sort.Strings(names)
+	err := validateEnvConflicts(app, names)
+	if err != nil {
+		return diff, err
+	}
+	var toSet []bindTypes.EnvVar
+	for _, name := range names {
+		env := imported[name]
+		current, ok := app.Env[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case current.Value != env.Value || current.Public != env.Public || current.Alias != env.Alias || current.ManagedBy != "":
+			diff.Changed = append(diff.Changed, name)
+		default:
+			continue
+		}
+		toSet = append(toSet, env)
+	}
+	if args.Mode == EnvImportReplace {
+		for name, env := range app.Env {
+			if _, ok := imported[name]; !ok && env.ManagedBy == "" {
+				diff.Removed = append(diff.Removed, name)
+			}
+		}
+		sort.Strings(diff.Removed)
+	}
+	err = checkEnvSchemaChange(app, toSet, diff.Removed)
+	if err != nil {
+		return diff, err
+	}
+	if args.Writer != nil {
+		writeEnvsDiff(args.Writer, diff, args.DryRun)
+	}
+	if args.DryRun || diff.Empty() {
+		return diff, nil
+	}
+	for _, name := range diff.Removed {
+		delete(app.Env, name)
+	}
+	for _, env := range toSet {
+		setEnv(app, env)
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return diff, err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$set": mongoBSON.M{"env": app.Env}})
+	if err != nil {
+		return diff, err
+	}
+	recordConfigSnapshot(ctx, app)
+	if args.ShouldRestart {
+		return diff, restartIfUnits(ctx, app, args.Writer)
+	}
+	return diff, nil
+}
+
+func writeEnvsDiff(w io.Writer, diff bindTypes.EnvsDiff, dryRun bool) {
+	if diff.Empty() {
+		fmt.Fprintln(w, "---- No changes to environment variables ----")
+		return
+	}
+	if dryRun {
+		fmt.Fprintln(w, "---- Changes to environment variables (dry run, nothing applied) ----")
+	} else {
+		fmt.Fprintf(w, "---- Importing %d changes to environment variables ----\n", len(diff.Added)+len(diff.Changed)+len(diff.Removed))
+	}
+	for _, name := range diff.Added {
+		fmt.Fprintf(w, "  + %s\n", name)
+	}
+	for _, name := range diff.Changed {
+		fmt.Fprintf(w, "  ~ %s\n", name)
+	}
+	for _, name := range diff.Removed {
+		fmt.Fprintf(w, "  - %s\n", name)
+	}
+}
+
+// FormatDotenv writes the envs as a dotenv file, sorted by name, with the
+// values double-quoted.
+func FormatDotenv(w io.Writer, envs []bindTypes.EnvVar) error {
+	sorted := append([]bindTypes.EnvVar(nil), envs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, env := range sorted {
+		_, err := fmt.Fprintf(w, "%s=%s\n", env.Name, strconv.Quote(env.Value))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseDotenv parses the contents of a dotenv file. Blank lines, comments
+// and the "export" prefix are ignored. Double-quoted values are unescaped,
+// single-quoted values are taken literally and unquoted values are trimmed.
+func ParseDotenv(data string) ([]bindTypes.EnvVar, error) {
+	var envs []bindTypes.EnvVar
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid dotenv line %d: missing \"=\"", lineNumber)}
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid dotenv line %d: malformed double-quoted value", lineNumber)}
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid dotenv line %d: malformed single-quoted value", lineNumber)}
+			}
+			value = value[1 : len(value)-1]
+		default:
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+		envs = append(envs, bindTypes.EnvVar{Name: name, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
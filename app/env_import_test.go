@@ -0,0 +1,128 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestImportEnvs(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "DATABASE_HOST", Value: "localhost", Public: true},
+			{Name: "DATABASE_USER", Value: "root", Public: true},
+			{Name: "LOG_LEVEL", Value: "info", Public: true},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs:      []bindTypes.EnvVar{{Name: "MANAGED", Value: "1", Public: true, ManagedBy: "terraform"}},
+		ManagedBy: "terraform",
+	})
+	c.Assert(err, check.IsNil)
+	envs := []bindTypes.EnvVar{
+		{Name: "DATABASE_HOST", Value: "db.example.com", Public: true},
+		{Name: "DATABASE_USER", Value: "root", Public: true},
+		{Name: "DATABASE_PASSWORD", Value: "secret"},
+	}
+	var buf bytes.Buffer
+	diff, err := ImportEnvs(context.TODO(), &a, ImportEnvsArgs{Envs: envs, Mode: EnvImportReplace, DryRun: true, Writer: &buf})
+	c.Assert(err, check.IsNil)
+	expectedDiff := bindTypes.EnvsDiff{
+		Added:   []string{"DATABASE_PASSWORD"},
+		Changed: []string{"DATABASE_HOST"},
+		Removed: []string{"LOG_LEVEL"},
+	}
+	c.Assert(diff, check.DeepEquals, expectedDiff)
+	c.Assert(buf.String(), check.Equals, `---- Changes to environment variables (dry run, nothing applied) ----
+  + DATABASE_PASSWORD
+  ~ DATABASE_HOST
+  - LOG_LEVEL
+`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DATABASE_HOST"].Value, check.Equals, "localhost")
+	c.Assert(dbApp.Env, check.HasLen, 4)
+	diff, err = ImportEnvs(context.TODO(), &a, ImportEnvsArgs{Envs: envs, Mode: EnvImportReplace})
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.DeepEquals, expectedDiff)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.DeepEquals, map[string]bindTypes.EnvVar{
+		"DATABASE_HOST":     {Name: "DATABASE_HOST", Value: "db.example.com", Public: true},
+		"DATABASE_USER":     {Name: "DATABASE_USER", Value: "root", Public: true},
+		"DATABASE_PASSWORD": {Name: "DATABASE_PASSWORD", Value: "secret"},
+		"MANAGED":           {Name: "MANAGED", Value: "1", Public: true, ManagedBy: "terraform"},
+	})
+	diff, err = ImportEnvs(context.TODO(), &a, ImportEnvsArgs{
+		Envs: []bindTypes.EnvVar{{Name: "LOG_LEVEL", Value: "debug", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.DeepEquals, bindTypes.EnvsDiff{Added: []string{"LOG_LEVEL"}})
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, 5)
+}
+
+func (s *S) TestImportEnvsInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = ImportEnvs(context.TODO(), &a, ImportEnvsArgs{Mode: "overwrite"})
+	c.Assert(err, check.ErrorMatches, `invalid import mode "overwrite", must be "merge" or "replace"`)
+	_, err = ImportEnvs(context.TODO(), &a, ImportEnvsArgs{
+		Envs: []bindTypes.EnvVar{{Name: "A", Value: "1"}, {Name: "A", Value: "2"}},
+	})
+	c.Assert(err, check.ErrorMatches, `environment variable "A" is imported more than once`)
+	_, err = ImportEnvs(context.TODO(), &a, ImportEnvsArgs{
+		Envs: []bindTypes.EnvVar{{Name: "1A", Value: "1"}},
+	})
+	c.Assert(err, check.ErrorMatches, `Invalid environment variable name: '1A'`)
+}
+
+func (s *S) TestParseDotenv(c *check.C) {
+	envs, err := ParseDotenv(`# database
+DATABASE_HOST=localhost
+export DATABASE_USER = root # the user
+
+DATABASE_PASSWORD="p4ss \"word\"\nline"
+GREETING='hello $USER'
+EMPTY=
+`)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []bindTypes.EnvVar{
+		{Name: "DATABASE_HOST", Value: "localhost"},
+		{Name: "DATABASE_USER", Value: "root"},
+		{Name: "DATABASE_PASSWORD", Value: "p4ss \"word\"\nline"},
+		{Name: "GREETING", Value: "hello $USER"},
+		{Name: "EMPTY", Value: ""},
+	})
+	_, err = ParseDotenv("DATABASE_HOST")
+	c.Assert(err, check.ErrorMatches, `invalid dotenv line 1: missing "="`)
+	_, err = ParseDotenv("A=1\nB=\"unterminated")
+	c.Assert(err, check.ErrorMatches, `invalid dotenv line 2: malformed double-quoted value`)
+}
+
+func (s *S) TestFormatDotenv(c *check.C) {
+	envs := []bindTypes.EnvVar{
+		{Name: "B", Value: "multi\nline \"quoted\""},
+		{Name: "A", Value: "plain"},
+	}
+	var buf bytes.Buffer
+	err := FormatDotenv(&buf, envs)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "A=\"plain\"\nB=\"multi\\nline \\\"quoted\\\"\"\n")
+	parsed, err := ParseDotenv(buf.String())
+	c.Assert(err, check.IsNil)
+	c.Assert(parsed, check.DeepEquals, []bindTypes.EnvVar{envs[1], envs[0]})
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// SetEnvSchema replaces the env schema of the app. Current envs are not
+// checked, so a schema may be set before the variables it requires, but
+// deploys fail until the app envs match it.
+func SetEnvSchema(ctx context.Context, app *appTypes.App, schema []appTypes.EnvVarSchema) error {
+	err := validateEnvSchema(schema)
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"envschema": schema},
+	})
+	if err != nil {
+		return err
+	}
+	app.EnvSchema = schema
+	return nil
+}
+
+func validateEnvSchema(schema []appTypes.EnvVarSchema) error {
+	names := map[string]struct{}{}
+	for _, s := range schema {
+		err := validateEnv(s.Name)
+		if err != nil {
+			return err
+		}
+		if _, ok := names[s.Name]; ok {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("environment variable %q declared more than once in schema", s.Name)}
+		}
+		names[s.Name] = struct{}{}
+		switch s.Type {
+		case "", appTypes.EnvTypeString, appTypes.EnvTypeInt, appTypes.EnvTypeFloat, appTypes.EnvTypeBool, appTypes.EnvTypeURL:
+		default:
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid type %q for environment variable %q", s.Type, s.Name)}
+		}
+		if s.Pattern != "" {
+			if _, err = envSchemaPattern(s.Pattern); err != nil {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid pattern for environment variable %q: %v", s.Name, err)}
+			}
+		}
+	}
+	return nil
+}
+
+func envSchemaPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// checkEnvSchemaChange checks the variables being set or unset against the
+// app schema, as they would be after the change.
+func checkEnvSchemaChange(app *appTypes.App, set []bindTypes.EnvVar, unset []string) error {
+	if len(app.EnvSchema) == 0 {
+		return nil
+	}
+	changed := *app
+	changed.Env = make(map[string]bindTypes.EnvVar, len(app.Env))
+	maps.Copy(changed.Env, app.Env)
+	names := make([]string, 0, len(set)+len(unset))
+	for _, name := range unset {
+		delete(changed.Env, name)
+		names = append(names, name)
+	}
+	for _, env := range set {
+		changed.Env[env.Name] = env
+		names = append(names, env.Name)
+	}
+	return checkEnvSchema(&changed, names)
+}
+
+// checkEnvSchema checks the envs of the app against its schema. When names
+// is nil every variable in the schema is checked.
+func checkEnvSchema(app *appTypes.App, names []string) error {
+	if len(app.EnvSchema) == 0 {
+		return nil
+	}
+	var filter map[string]struct{}
+	if names != nil {
+		filter = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			filter[name] = struct{}{}
+		}
+	}
+	envs := provision.EnvsForApp(app)
+	var violations []string
+	for _, s := range app.EnvSchema {
+		if filter != nil {
+			if _, ok := filter[s.Name]; !ok {
+				continue
+			}
+		}
+		env, ok := envs[s.Name]
+		if !ok || env.Value == "" {
+			if s.Required {
+				violations = append(violations, fmt.Sprintf("%s is required", s.Name))
+			}
+			continue
+		}
+		if err := checkEnvValue(s, env.Value); err != nil {
+			violations = append(violations, fmt.Sprintf("%s %v", s.Name, err))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &tsuruErrors.ValidationError{
+		Message: fmt.Sprintf("environment variables do not match the app schema: %s", strings.Join(violations, "; ")),
+	}
+}
+
+// checkEnvValue never includes the value in the error, as it may be private.
+func checkEnvValue(s appTypes.EnvVarSchema, value string) error {
+	switch s.Type {
+	case appTypes.EnvTypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return errors.New("must be an integer")
+		}
+	case appTypes.EnvTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("must be a number")
+		}
+	case appTypes.EnvTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("must be a boolean")
+		}
+	case appTypes.EnvTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+	}
+	if s.Pattern != "" {
+		re, err := envSchemaPattern(s.Pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(value) {
+			return errors.Errorf("must match %q", s.Pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetEnvSchema(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	schema := []appTypes.EnvVarSchema{
+		{Name: "DATABASE_URL", Required: true, Type: appTypes.EnvTypeURL},
+		{Name: "WORKERS", Type: appTypes.EnvTypeInt, Pattern: "[1-8]"},
+	}
+	err = SetEnvSchema(context.TODO(), &a, schema)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.EnvSchema, check.DeepEquals, schema)
+}
+
+func (s *S) TestSetEnvSchemaInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		schema []appTypes.EnvVarSchema
+		msg    string
+	}{
+		{[]appTypes.EnvVarSchema{{Name: "0INVALID"}}, `Invalid environment variable name: '0INVALID'`},
+		{[]appTypes.EnvVarSchema{{Name: "A"}, {Name: "A"}}, `environment variable "A" declared more than once in schema`},
+		{[]appTypes.EnvVarSchema{{Name: "A", Type: "date"}}, `invalid type "date" for environment variable "A"`},
+		{[]appTypes.EnvVarSchema{{Name: "A", Pattern: "("}}, `invalid pattern for environment variable "A": .*`},
+	}
+	for _, tt := range tests {
+		err = SetEnvSchema(context.TODO(), &a, tt.schema)
+		c.Check(err, check.ErrorMatches, tt.msg)
+	}
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.EnvSchema, check.HasLen, 0)
+}
+
+func (s *S) TestSetEnvsEnvSchemaViolation(c *check.C) {
+	a := appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		EnvSchema: []appTypes.EnvVarSchema{
+			{Name: "DATABASE_URL", Required: true, Type: appTypes.EnvTypeURL},
+			{Name: "WORKERS", Type: appTypes.EnvTypeInt},
+			{Name: "MODE", Pattern: "dev|prod"},
+		},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "DATABASE_URL", Value: "localhost"},
+			{Name: "WORKERS", Value: "four"},
+			{Name: "MODE", Value: "production"},
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `environment variables do not match the app schema: DATABASE_URL must be an absolute URL; WORKERS must be an integer; MODE must match "dev\|prod"`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, 0)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "WORKERS", Value: "4"},
+			{Name: "MODE", Value: "prod"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSetEnvsEnvSchemaPruneRequired(c *check.C) {
+	a := appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"DATABASE_URL": {Name: "DATABASE_URL", Value: "mysql://db/app", ManagedBy: "terraform"},
+		},
+		EnvSchema: []appTypes.EnvVarSchema{{Name: "DATABASE_URL", Required: true}},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs:        []bindTypes.EnvVar{{Name: "OTHER", Value: "x", ManagedBy: "terraform"}},
+		ManagedBy:   "terraform",
+		PruneUnused: true,
+	})
+	c.Assert(err, check.ErrorMatches, `environment variables do not match the app schema: DATABASE_URL is required`)
+	c.Assert(a.Env, check.HasLen, 1)
+}
+
+func (s *S) TestUnsetEnvsEnvSchemaRequired(c *check.C) {
+	a := appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"DATABASE_URL": {Name: "DATABASE_URL", Value: "mysql://db/app"},
+			"DEBUG":        {Name: "DEBUG", Value: "1"},
+		},
+		EnvSchema: []appTypes.EnvVarSchema{
+			{Name: "DATABASE_URL", Required: true},
+			{Name: "DEBUG", Type: appTypes.EnvTypeBool},
+		},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = UnsetEnvs(context.TODO(), &a, bindTypes.UnsetEnvArgs{VariableNames: []string{"DATABASE_URL"}})
+	c.Assert(err, check.ErrorMatches, `environment variables do not match the app schema: DATABASE_URL is required`)
+	err = UnsetEnvs(context.TODO(), &a, bindTypes.UnsetEnvArgs{VariableNames: []string{"DEBUG"}})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, 1)
+}
+
+func (s *S) TestUnsetEnvsEnvSchemaRequiredProvidedByService(c *check.C) {
+	a := appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"DATABASE_URL": {Name: "DATABASE_URL", Value: "mysql://db/app"},
+		},
+		ServiceEnvs: []bindTypes.ServiceEnvVar{
+			{EnvVar: bindTypes.EnvVar{Name: "DATABASE_URL", Value: "mysql://other/app"}, ServiceName: "mysql", InstanceName: "db"},
+		},
+		EnvSchema: []appTypes.EnvVarSchema{{Name: "DATABASE_URL", Required: true}},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = UnsetEnvs(context.TODO(), &a, bindTypes.UnsetEnvArgs{VariableNames: []string{"DATABASE_URL"}})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDeployAppEnvSchemaViolation(c *check.C) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		TeamOwner: s.team.Name,
+		EnvSchema: []appTypes.EnvVarSchema{{Name: "DATABASE_URL", Required: true}},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(context.TODO(), DeployOptions{App: &a, Image: "myimage", Event: evt})
+	c.Assert(err, check.ErrorMatches, `environment variables do not match the app schema: DATABASE_URL is required`)
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+}
+
+func (s *S) TestCheckEnvValue(c *check.C) {
+	tests := []struct {
+		schema appTypes.EnvVarSchema
+		value  string
+		err    string
+	}{
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeString}, "anything", ""},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeInt}, "-12", ""},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeInt}, "1.5", "must be an integer"},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeFloat}, "1.5", ""},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeFloat}, "one", "must be a number"},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeBool}, "true", ""},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeBool}, "yes", "must be a boolean"},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeURL}, "postgres://db:5432/app", ""},
+		{appTypes.EnvVarSchema{Type: appTypes.EnvTypeURL}, "db:5432", "must be an absolute URL"},
+		{appTypes.EnvVarSchema{Pattern: "[a-z]+"}, "abc", ""},
+		{appTypes.EnvVarSchema{Pattern: "[a-z]+"}, "abc1", `must match "\[a-z\]\+"`},
+	}
+	for _, tt := range tests {
+		err := checkEnvValue(tt.schema, tt.value)
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("%v %q", tt.schema, tt.value))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("%v %q", tt.schema, tt.value))
+		}
+	}
+}
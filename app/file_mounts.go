@@ -0,0 +1,101 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrFileMountNotFound = errors.New("file not found")
+
+// SetFileArgs holds the file to be mounted in the units of an app.
+type SetFileArgs struct {
+	File          appTypes.FileMount
+	Writer        io.Writer
+	ShouldRestart bool
+}
+
+// SetFile mounts the file in the units of the app, replacing any file in
+// the same path. Running units get the file on the next deploy or restart,
+// which happens right away when ShouldRestart is set.
+func SetFile(ctx context.Context, app *appTypes.App, args SetFileArgs) error {
+	files := slices.DeleteFunc(slices.Clone(app.Files), func(f appTypes.FileMount) bool {
+		return f.Path == args.File.Path
+	})
+	files = append(files, args.File)
+	err := validateFileMounts(files)
+	if err != nil {
+		return err
+	}
+	if args.Writer != nil {
+		fmt.Fprintf(args.Writer, "---- Setting file %s ----\n", args.File.Path)
+	}
+	return updateFileMounts(ctx, app, files, args.Writer, args.ShouldRestart)
+}
+
+// UnsetFile removes the file mounted in path from the units of the app.
+func UnsetFile(ctx context.Context, app *appTypes.App, filePath string, w io.Writer, shouldRestart bool) error {
+	files := slices.DeleteFunc(slices.Clone(app.Files), func(f appTypes.FileMount) bool {
+		return f.Path == filePath
+	})
+	if len(files) == len(app.Files) {
+		return ErrFileMountNotFound
+	}
+	if w != nil {
+		fmt.Fprintf(w, "---- Unsetting file %s ----\n", filePath)
+	}
+	return updateFileMounts(ctx, app, files, w, shouldRestart)
+}
+
+func updateFileMounts(ctx context.Context, app *appTypes.App, files []appTypes.FileMount, w io.Writer, shouldRestart bool) error {
+	if len(files) == 0 {
+		files = nil
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"files": files},
+	})
+	if err != nil {
+		return err
+	}
+	app.Files = files
+	if shouldRestart {
+		return restartIfUnits(ctx, app, w)
+	}
+	return nil
+}
+
+func validateFileMounts(files []appTypes.FileMount) error {
+	size := 0
+	for _, f := range files {
+		if !path.IsAbs(f.Path) || path.Clean(f.Path) != f.Path || f.Path == "/" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid file path %q, it must be an absolute path to a file", f.Path)}
+		}
+		for _, other := range files {
+			if strings.HasPrefix(other.Path, f.Path+"/") {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("file %q can't be mounted inside file %q", other.Path, f.Path)}
+			}
+		}
+		size += len(f.Content)
+	}
+	if size > appTypes.MaxFileMountsSize {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("files of the app can't exceed %d bytes, got %d bytes", appTypes.MaxFileMountsSize, size)}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetFile(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetFile(context.TODO(), &a, SetFileArgs{
+		File:   appTypes.FileMount{Path: "/etc/app/config.yaml", Content: []byte("debug: true")},
+		Writer: io.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	err = SetFile(context.TODO(), &a, SetFileArgs{
+		File:   appTypes.FileMount{Path: "/etc/app/token", Content: []byte("abc"), Secret: true},
+		Writer: io.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	err = SetFile(context.TODO(), &a, SetFileArgs{
+		File:   appTypes.FileMount{Path: "/etc/app/config.yaml", Content: []byte("debug: false")},
+		Writer: io.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Files, check.DeepEquals, []appTypes.FileMount{
+		{Path: "/etc/app/token", Content: []byte("abc"), Secret: true},
+		{Path: "/etc/app/config.yaml", Content: []byte("debug: false")},
+	})
+	err = UnsetFile(context.TODO(), &a, "/etc/app/token", io.Discard, false)
+	c.Assert(err, check.IsNil)
+	err = UnsetFile(context.TODO(), &a, "/etc/app/token", io.Discard, false)
+	c.Assert(err, check.Equals, ErrFileMountNotFound)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Files, check.DeepEquals, []appTypes.FileMount{
+		{Path: "/etc/app/config.yaml", Content: []byte("debug: false")},
+	})
+}
+
+func (s *S) TestSetFileInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetFile(context.TODO(), &a, SetFileArgs{File: appTypes.FileMount{Path: "/etc/app"}})
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		file     appTypes.FileMount
+		expected string
+	}{
+		{appTypes.FileMount{Path: "config.yaml"}, `invalid file path "config.yaml", it must be an absolute path to a file`},
+		{appTypes.FileMount{Path: "/etc/../config.yaml"}, `invalid file path "/etc/../config.yaml", it must be an absolute path to a file`},
+		{appTypes.FileMount{Path: "/"}, `invalid file path "/", it must be an absolute path to a file`},
+		{appTypes.FileMount{Path: "/etc/app/config.yaml"}, `file "/etc/app/config.yaml" can't be mounted inside file "/etc/app"`},
+		{appTypes.FileMount{Path: "/big", Content: bytes.Repeat([]byte("a"), appTypes.MaxFileMountsSize+1)}, `files of the app can't exceed 524288 bytes, got 524289 bytes`},
+	}
+	for _, tt := range tests {
+		err = SetFile(context.TODO(), &a, SetFileArgs{File: tt.file})
+		c.Check(err, check.ErrorMatches, tt.expected)
+	}
+	c.Assert(a.Files, check.DeepEquals, []appTypes.FileMount{{Path: "/etc/app"}})
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusHealthy   = HealthStatus("healthy")
+	HealthStatusDegraded  = HealthStatus("degraded")
+	HealthStatusUnhealthy = HealthStatus("unhealthy")
+
+	certificateExpirationWarning = 7 * 24 * time.Hour
+)
+
+var healthSeverity = map[HealthStatus]int{
+	HealthStatusHealthy:   0,
+	HealthStatusDegraded:  1,
+	HealthStatusUnhealthy: 2,
+}
+
+// HealthCheck is the verdict of a single aspect of the app health.
+type HealthCheck struct {
+	Name    string       `json:"name"`
+	Status  HealthStatus `json:"status"`
+	Details []string     `json:"details,omitempty"`
+}
+
+// AppHealth aggregates every health check of an app. Status is the worst
+// status among the checks.
+type AppHealth struct {
+	Status HealthStatus  `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+func (c *HealthCheck) report(status HealthStatus, format string, args ...interface{}) {
+	if healthSeverity[status] > healthSeverity[c.Status] {
+		c.Status = status
+	}
+	c.Details = append(c.Details, fmt.Sprintf(format, args...))
+}
+
+// Health combines router reachability, units readiness, autoscale state,
+// certificate validity and bound services status into a single verdict.
+func Health(ctx context.Context, app *appTypes.App, requestID string) *AppHealth {
+	health := &AppHealth{Status: HealthStatusHealthy}
+	checks := []func(context.Context, *appTypes.App, string) HealthCheck{
+		routersHealth,
+		unitsHealth,
+		autoScaleHealth,
+		certificatesHealth,
+		servicesHealth,
+	}
+	for _, fn := range checks {
+		hc := fn(ctx, app, requestID)
+		if healthSeverity[hc.Status] > healthSeverity[health.Status] {
+			health.Status = hc.Status
+		}
+		health.Checks = append(health.Checks, hc)
+	}
+	return health
+}
+
+func routersHealth(ctx context.Context, app *appTypes.App, _ string) HealthCheck {
+	hc := HealthCheck{Name: "routers", Status: HealthStatusHealthy}
+	routers, err := GetRoutersWithAddr(ctx, app)
+	for _, r := range routers {
+		if r.Status == "" || r.Status == string(router.BackendStatusReady) {
+			continue
+		}
+		detail := r.StatusDetail
+		if detail == "" {
+			detail = r.Status
+		}
+		hc.report(HealthStatusUnhealthy, "router %q is not ready: %s", r.Name, detail)
+	}
+	if err != nil && hc.Status == HealthStatusHealthy {
+		hc.report(HealthStatusDegraded, "unable to check routers: %v", err)
+	}
+	return hc
+}
+
+func unitsHealth(ctx context.Context, app *appTypes.App, _ string) HealthCheck {
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return HealthCheck{Name: "units", Status: HealthStatusUnhealthy, Details: []string{fmt.Sprintf("unable to list units: %v", err)}}
+	}
+	crashLoops, err := CrashLoopReport(ctx, app.Name)
+	hc := unitsHealthCheck(units, crashLoops)
+	if err != nil {
+		hc.report(HealthStatusDegraded, "unable to check crash loops: %v", err)
+	}
+	return hc
+}
+
+func unitsHealthCheck(units []provTypes.Unit, crashLoops []CrashLoopStatus) HealthCheck {
+	hc := HealthCheck{Name: "units", Status: HealthStatusHealthy}
+	if len(units) == 0 {
+		hc.report(HealthStatusUnhealthy, "no units running")
+		return hc
+	}
+	type processVersion struct {
+		process string
+		version int
+	}
+	total := map[processVersion]int{}
+	ready := map[processVersion]int{}
+	for _, u := range units {
+		key := processVersion{process: u.ProcessName, version: u.Version}
+		total[key]++
+		if unitReady(u) {
+			ready[key]++
+		}
+	}
+	keys := make([]processVersion, 0, len(total))
+	for k := range total {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].process == keys[j].process {
+			return keys[i].version < keys[j].version
+		}
+		return keys[i].process < keys[j].process
+	})
+	for _, k := range keys {
+		switch {
+		case ready[k] == 0:
+			hc.report(HealthStatusUnhealthy, "process %q version %d has no ready units out of %d", k.process, k.version, total[k])
+		case ready[k] < total[k]:
+			hc.report(HealthStatusDegraded, "process %q version %d has %d ready units out of %d", k.process, k.version, ready[k], total[k])
+		}
+	}
+	for _, cl := range crashLoops {
+		if cl.CrashLoop {
+			hc.report(HealthStatusDegraded, "unit %q is in crash loop, restarted %d times in %s", cl.Unit, cl.Restarts, cl.Window)
+		}
+	}
+	return hc
+}
+
+func unitReady(u provTypes.Unit) bool {
+	if u.Ready != nil {
+		return *u.Ready
+	}
+	return u.Status == provTypes.UnitStatusStarted
+}
+
+func autoScaleHealth(ctx context.Context, app *appTypes.App, _ string) HealthCheck {
+	hc := HealthCheck{Name: "autoscale", Status: HealthStatusHealthy}
+	specs, err := AutoScaleInfo(ctx, app)
+	if err != nil {
+		hc.report(HealthStatusDegraded, "unable to get autoscale info: %v", err)
+		return hc
+	}
+	if len(specs) == 0 {
+		return hc
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		hc.report(HealthStatusDegraded, "unable to list units: %v", err)
+		return hc
+	}
+	return autoScaleHealthCheck(specs, units)
+}
+
+func autoScaleHealthCheck(specs []provTypes.AutoScaleSpec, units []provTypes.Unit) HealthCheck {
+	hc := HealthCheck{Name: "autoscale", Status: HealthStatusHealthy}
+	unitsByProcess := map[string]uint{}
+	for _, u := range units {
+		unitsByProcess[u.ProcessName]++
+	}
+	for _, spec := range specs {
+		count := unitsByProcess[spec.Process]
+		switch {
+		case count < spec.MinUnits:
+			hc.report(HealthStatusDegraded, "process %q pending scale up: %d units running, minimum is %d", spec.Process, count, spec.MinUnits)
+		case spec.MaxUnits > 0 && count > spec.MaxUnits:
+			hc.report(HealthStatusDegraded, "process %q pending scale down: %d units running, maximum is %d", spec.Process, count, spec.MaxUnits)
+		}
+	}
+	return hc
+}
+
+func certificatesHealth(ctx context.Context, app *appTypes.App, _ string) HealthCheck {
+	hc := HealthCheck{Name: "certificates", Status: HealthStatusHealthy}
+	certs, err := GetCertificates(ctx, app)
+	if err != nil {
+		if err != ErrNoRouterWithTLS {
+			hc.report(HealthStatusDegraded, "unable to get certificates: %v", err)
+		}
+		return hc
+	}
+	return certificatesHealthCheck(certs, time.Now())
+}
+
+func certificatesHealthCheck(certs *appTypes.CertificateSetInfo, now time.Time) HealthCheck {
+	hc := HealthCheck{Name: "certificates", Status: HealthStatusHealthy}
+	routerNames := make([]string, 0, len(certs.Routers))
+	for name := range certs.Routers {
+		routerNames = append(routerNames, name)
+	}
+	sort.Strings(routerNames)
+	for _, routerName := range routerNames {
+		cnames := certs.Routers[routerName].CNames
+		names := make([]string, 0, len(cnames))
+		for name := range cnames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			info := cnames[name]
+			if info.Certificate == "" {
+				hc.report(HealthStatusDegraded, "certificate for %q on router %q is pending issuance", name, routerName)
+				continue
+			}
+			cert, err := parseCertificate(info.Certificate)
+			if err != nil {
+				hc.report(HealthStatusDegraded, "unable to parse certificate for %q on router %q: %v", name, routerName, err)
+				continue
+			}
+			switch {
+			case now.After(cert.NotAfter):
+				hc.report(HealthStatusUnhealthy, "certificate for %q on router %q expired at %s", name, routerName, cert.NotAfter.Format(time.RFC3339))
+			case now.Before(cert.NotBefore):
+				hc.report(HealthStatusUnhealthy, "certificate for %q on router %q is not valid before %s", name, routerName, cert.NotBefore.Format(time.RFC3339))
+			case cert.NotAfter.Sub(now) < certificateExpirationWarning:
+				hc.report(HealthStatusDegraded, "certificate for %q on router %q expires at %s", name, routerName, cert.NotAfter.Format(time.RFC3339))
+			}
+		}
+	}
+	return hc
+}
+
+func parseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func servicesHealth(ctx context.Context, app *appTypes.App, requestID string) HealthCheck {
+	hc := HealthCheck{Name: "services", Status: HealthStatusHealthy}
+	instances, err := service.GetServiceInstancesBoundToApp(ctx, app.Name)
+	if err != nil {
+		hc.report(HealthStatusDegraded, "unable to list bound service instances: %v", err)
+		return hc
+	}
+	for _, si := range instances {
+		status, err := si.Status(ctx, requestID)
+		if err != nil {
+			hc.report(HealthStatusDegraded, "unable to get status of service instance %q of service %q: %v", si.Name, si.ServiceName, err)
+			continue
+		}
+		if strings.TrimSpace(status) == "down" {
+			hc.report(HealthStatusUnhealthy, "service instance %q of service %q is down", si.Name, si.ServiceName)
+		}
+	}
+	return hc
+}
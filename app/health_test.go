@@ -0,0 +1,107 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"os"
+	"time"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestUnitsHealthCheck(c *check.C) {
+	ready, notReady := true, false
+	units := []provTypes.Unit{
+		{Name: "u1", ProcessName: "web", Version: 1, Ready: &ready},
+		{Name: "u2", ProcessName: "web", Version: 1, Ready: &notReady},
+		{Name: "u3", ProcessName: "worker", Version: 1, Status: provTypes.UnitStatusStarted},
+	}
+	hc := unitsHealthCheck(units, nil)
+	c.Assert(hc.Status, check.Equals, HealthStatusDegraded)
+	c.Assert(hc.Details, check.DeepEquals, []string{`process "web" version 1 has 1 ready units out of 2`})
+	units = append(units, provTypes.Unit{Name: "u4", ProcessName: "worker", Version: 2, Status: provTypes.UnitStatusError})
+	hc = unitsHealthCheck(units, nil)
+	c.Assert(hc.Status, check.Equals, HealthStatusUnhealthy)
+	c.Assert(hc.Details, check.HasLen, 2)
+}
+
+func (s *S) TestUnitsHealthCheckNoUnits(c *check.C) {
+	hc := unitsHealthCheck(nil, nil)
+	c.Assert(hc.Status, check.Equals, HealthStatusUnhealthy)
+	c.Assert(hc.Details, check.DeepEquals, []string{"no units running"})
+}
+
+func (s *S) TestUnitsHealthCheckCrashLoop(c *check.C) {
+	ready := true
+	units := []provTypes.Unit{{Name: "u1", ProcessName: "web", Ready: &ready}}
+	hc := unitsHealthCheck(units, []CrashLoopStatus{
+		{Unit: "u1", Restarts: 6, Window: 10 * time.Minute, CrashLoop: true},
+		{Unit: "u2", Restarts: 1, Window: 10 * time.Minute},
+	})
+	c.Assert(hc.Status, check.Equals, HealthStatusDegraded)
+	c.Assert(hc.Details, check.DeepEquals, []string{`unit "u1" is in crash loop, restarted 6 times in 10m0s`})
+}
+
+func (s *S) TestAutoScaleHealthCheck(c *check.C) {
+	units := []provTypes.Unit{
+		{Name: "u1", ProcessName: "web"},
+		{Name: "u2", ProcessName: "worker"},
+		{Name: "u3", ProcessName: "worker"},
+		{Name: "u4", ProcessName: "worker"},
+	}
+	hc := autoScaleHealthCheck([]provTypes.AutoScaleSpec{
+		{Process: "web", MinUnits: 1, MaxUnits: 5},
+	}, units)
+	c.Assert(hc.Status, check.Equals, HealthStatusHealthy)
+	c.Assert(hc.Details, check.HasLen, 0)
+	hc = autoScaleHealthCheck([]provTypes.AutoScaleSpec{
+		{Process: "web", MinUnits: 2, MaxUnits: 5},
+		{Process: "worker", MinUnits: 1, MaxUnits: 2},
+	}, units)
+	c.Assert(hc.Status, check.Equals, HealthStatusDegraded)
+	c.Assert(hc.Details, check.DeepEquals, []string{
+		`process "web" pending scale up: 1 units running, minimum is 2`,
+		`process "worker" pending scale down: 3 units running, maximum is 2`,
+	})
+}
+
+func (s *S) TestCertificatesHealthCheck(c *check.C) {
+	cert, err := os.ReadFile("testdata/certificate.crt")
+	c.Assert(err, check.IsNil)
+	certs := &appTypes.CertificateSetInfo{
+		Routers: map[string]appTypes.RouterCertificateInfo{
+			"ingress": {
+				CNames: map[string]appTypes.CertificateInfo{
+					"app.io": {Certificate: string(cert)},
+				},
+			},
+		},
+	}
+	hc := certificatesHealthCheck(certs, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(hc.Status, check.Equals, HealthStatusHealthy)
+	hc = certificatesHealthCheck(certs, time.Date(2031, 3, 14, 0, 0, 0, 0, time.UTC))
+	c.Assert(hc.Status, check.Equals, HealthStatusDegraded)
+	c.Assert(hc.Details, check.DeepEquals, []string{`certificate for "app.io" on router "ingress" expires at 2031-03-15T19:19:39Z`})
+	hc = certificatesHealthCheck(certs, time.Date(2032, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(hc.Status, check.Equals, HealthStatusUnhealthy)
+	c.Assert(hc.Details, check.DeepEquals, []string{`certificate for "app.io" on router "ingress" expired at 2031-03-15T19:19:39Z`})
+}
+
+func (s *S) TestCertificatesHealthCheckPendingIssuance(c *check.C) {
+	certs := &appTypes.CertificateSetInfo{
+		Routers: map[string]appTypes.RouterCertificateInfo{
+			"ingress": {
+				CNames: map[string]appTypes.CertificateInfo{
+					"app.io": {Issuer: "letsencrypt"},
+				},
+			},
+		},
+	}
+	hc := certificatesHealthCheck(certs, time.Now())
+	c.Assert(hc.Status, check.Equals, HealthStatusDegraded)
+	c.Assert(hc.Details, check.DeepEquals, []string{`certificate for "app.io" on router "ingress" is pending issuance`})
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	defaultIdentityTokenTTL = 15 * time.Minute
+	maxIdentityTokenTTL     = time.Hour
+	defaultIdentityIssuer   = "tsuru"
+)
+
+var (
+	ErrIdentityNotConfigured = errors.New("workload identity is not configured, workload-identity:private-key-file must be set")
+	ErrIdentityNotSupported  = errors.New("the app provisioner does not support workload identity")
+	ErrInvalidWorkloadToken  = errors.New("invalid workload token")
+	ErrInvalidIdentityToken  = errors.New("invalid identity token")
+
+	identityKeyMu sync.Mutex
+	identityKey   *identitySigningKey
+)
+
+// IdentityClaims are the claims of the short-lived tokens identifying apps
+// when calling other services.
+type IdentityClaims struct {
+	jwt.RegisteredClaims
+	App  string `json:"app"`
+	Team string `json:"team"`
+	Pool string `json:"pool"`
+}
+
+type identitySigningKey struct {
+	file   string
+	key    crypto.Signer
+	method jwt.SigningMethod
+	kid    string
+	jwks   jwk.Set
+}
+
+// getIdentitySigningKey returns the key used to sign identity tokens, it's
+// loaded again only when the configured key file changes.
+func getIdentitySigningKey() (*identitySigningKey, error) {
+	keyFile, _ := config.GetString("workload-identity:private-key-file")
+	if keyFile == "" {
+		return nil, ErrIdentityNotConfigured
+	}
+	identityKeyMu.Lock()
+	defer identityKeyMu.Unlock()
+	if identityKey != nil && identityKey.file == keyFile {
+		return identityKey, nil
+	}
+	signingKey, err := loadIdentitySigningKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	identityKey = signingKey
+	return identityKey, nil
+}
+
+func loadIdentitySigningKey(keyFile string) (*identitySigningKey, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read workload identity private key")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid workload identity private key, expected PEM data")
+	}
+	var rawKey interface{}
+	rawKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		rawKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		rawKey, err = x509.ParseECPrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.New("invalid workload identity private key, expected a RSA or ECDSA key")
+	}
+	signingKey := &identitySigningKey{file: keyFile}
+	switch k := rawKey.(type) {
+	case *rsa.PrivateKey:
+		signingKey.key, signingKey.method = k, jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		signingKey.key, signingKey.method = k, jwt.SigningMethodES256
+		switch k.Curve.Params().BitSize {
+		case 384:
+			signingKey.method = jwt.SigningMethodES384
+		case 521:
+			signingKey.method = jwt.SigningMethodES512
+		}
+	default:
+		return nil, errors.New("invalid workload identity private key, expected a RSA or ECDSA key")
+	}
+	pubKey, err := jwk.FromRaw(signingKey.key.Public())
+	if err != nil {
+		return nil, err
+	}
+	if err = jwk.AssignKeyID(pubKey); err != nil {
+		return nil, err
+	}
+	pubKey.Set(jwk.AlgorithmKey, signingKey.method.Alg())
+	pubKey.Set(jwk.KeyUsageKey, "sig")
+	signingKey.kid = pubKey.KeyID()
+	signingKey.jwks = jwk.NewSet()
+	if err = signingKey.jwks.AddKey(pubKey); err != nil {
+		return nil, err
+	}
+	return signingKey, nil
+}
+
+func identityIssuer() string {
+	issuer, _ := config.GetString("workload-identity:issuer")
+	if issuer == "" {
+		return defaultIdentityIssuer
+	}
+	return issuer
+}
+
+func identityTokenTTL() time.Duration {
+	ttl, err := config.GetDuration("workload-identity:token-ttl")
+	if err != nil || ttl <= 0 {
+		return defaultIdentityTokenTTL
+	}
+	if ttl > maxIdentityTokenTTL {
+		return maxIdentityTokenTTL
+	}
+	return ttl
+}
+
+// MintIdentityToken exchanges a token issued by the provisioner to a unit of
+// the app, such as a kubernetes service account token, for a short-lived
+// identity token signed by tsuru. Services receiving the token may check it
+// using VerifyIdentityToken or the public keys returned by IdentityJWKS.
+func MintIdentityToken(ctx context.Context, app *appTypes.App, workloadToken string, audience []string) (string, *IdentityClaims, error) {
+	signingKey, err := getIdentitySigningKey()
+	if err != nil {
+		return "", nil, err
+	}
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return "", nil, err
+	}
+	identityProv, ok := prov.(provision.WorkloadIdentityProvisioner)
+	if !ok {
+		return "", nil, ErrIdentityNotSupported
+	}
+	err = identityProv.ValidateWorkloadToken(ctx, app, workloadToken)
+	if err != nil {
+		return "", nil, errors.Wrap(ErrInvalidWorkloadToken, err.Error())
+	}
+	now := time.Now()
+	claims := &IdentityClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    identityIssuer(),
+			Subject:   "app:" + app.Name,
+			Audience:  audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(identityTokenTTL())),
+		},
+		App:  app.Name,
+		Team: app.TeamOwner,
+		Pool: app.Pool,
+	}
+	token := jwt.NewWithClaims(signingKey.method, claims)
+	token.Header["kid"] = signingKey.kid
+	signed, err := token.SignedString(signingKey.key)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// VerifyIdentityToken checks the signature and expiration of an identity
+// token, returning its claims. When audience is set, the token must have been
+// issued to it.
+func VerifyIdentityToken(rawToken, audience string) (*IdentityClaims, error) {
+	signingKey, err := getIdentitySigningKey()
+	if err != nil {
+		return nil, err
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{signingKey.method.Alg()}),
+		jwt.WithIssuer(identityIssuer()),
+		jwt.WithExpirationRequired(),
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	claims := &IdentityClaims{}
+	_, err = jwt.ParseWithClaims(rawToken, claims, func(*jwt.Token) (interface{}, error) {
+		return signingKey.key.Public(), nil
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidIdentityToken, err.Error())
+	}
+	return claims, nil
+}
+
+// IdentityJWKS returns the public keys used to sign identity tokens.
+func IdentityJWKS() (jwk.Set, error) {
+	signingKey, err := getIdentitySigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return signingKey.jwks, nil
+}
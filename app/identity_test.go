@@ -0,0 +1,77 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func setupIdentityKey(c *check.C) func() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	c.Assert(err, check.IsNil)
+	keyFile := filepath.Join(c.MkDir(), "identity.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	c.Assert(err, check.IsNil)
+	config.Set("workload-identity:private-key-file", keyFile)
+	return func() {
+		config.Unset("workload-identity")
+	}
+}
+
+func (s *S) TestMintIdentityToken(c *check.C) {
+	defer setupIdentityKey(c)()
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token, claims, err := MintIdentityToken(context.TODO(), &a, "fake-workload-token-myapp", []string{"billing"})
+	c.Assert(err, check.IsNil)
+	c.Assert(claims.App, check.Equals, "myapp")
+	c.Assert(claims.Team, check.Equals, s.team.Name)
+	c.Assert(claims.Pool, check.Equals, s.Pool)
+	c.Assert(claims.RegisteredClaims.Subject, check.Equals, "app:myapp")
+	verified, err := VerifyIdentityToken(token, "billing")
+	c.Assert(err, check.IsNil)
+	c.Assert(verified.App, check.Equals, "myapp")
+	c.Assert(verified.Team, check.Equals, s.team.Name)
+	c.Assert(verified.Pool, check.Equals, s.Pool)
+	_, err = VerifyIdentityToken(token, "other")
+	c.Assert(errors.Cause(err), check.Equals, ErrInvalidIdentityToken)
+	_, err = VerifyIdentityToken(token+"x", "")
+	c.Assert(errors.Cause(err), check.Equals, ErrInvalidIdentityToken)
+	keys, err := IdentityJWKS()
+	c.Assert(err, check.IsNil)
+	c.Assert(keys.Len(), check.Equals, 1)
+}
+
+func (s *S) TestMintIdentityTokenInvalidWorkloadToken(c *check.C) {
+	defer setupIdentityKey(c)()
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, _, err = MintIdentityToken(context.TODO(), &a, "fake-workload-token-otherapp", nil)
+	c.Assert(errors.Cause(err), check.Equals, ErrInvalidWorkloadToken)
+}
+
+func (s *S) TestMintIdentityTokenNotConfigured(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	_, _, err := MintIdentityToken(context.TODO(), &a, "fake-workload-token-myapp", nil)
+	c.Assert(err, check.Equals, ErrIdentityNotConfigured)
+	_, err = IdentityJWKS()
+	c.Assert(err, check.Equals, ErrIdentityNotConfigured)
+}
@@ -124,6 +124,7 @@ func Initialize() error {
 	gc := &imgGC{once: &sync.Once{}}
 	gc.start()
 	shutdown.Register(gc)
+	initializeRegistryGC()
 	return nil
 }
 
@@ -0,0 +1,277 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app/image"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/registry"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultRegistryGCInterval = 6 * time.Hour
+
+type registryGC struct {
+	interval time.Duration
+	remove   bool
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// initializeRegistryGC starts the loop removing the tags not referenced by
+// any app version or job from the registry. It's only started when
+// docker:gc:registry:enabled is set, docker:gc:dry-run makes it only report
+// the usage.
+func initializeRegistryGC() {
+	enabled, _ := config.GetBool("docker:gc:registry:enabled")
+	if !enabled {
+		return
+	}
+	interval, _ := config.GetDuration("docker:gc:registry:interval")
+	if interval <= 0 {
+		interval = defaultRegistryGCInterval
+	}
+	dryRun, _ := config.GetBool("docker:gc:dry-run")
+	g := &registryGC{
+		interval: interval,
+		remove:   !dryRun,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go g.spin()
+	shutdown.Register(g)
+}
+
+func (g *registryGC) spin() {
+	defer close(g.doneCh)
+	for {
+		err := runRegistryGC(context.Background(), g.remove)
+		if err != nil {
+			log.Errorf("[registry gc] %v", err)
+		}
+		select {
+		case <-g.stopCh:
+			return
+		case <-time.After(g.interval):
+		}
+	}
+}
+
+func (g *registryGC) Shutdown(ctx context.Context) error {
+	close(g.stopCh)
+	select {
+	case <-g.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// runRegistryGC records the removed tags in a registry gc event. Runs without
+// unreferenced tags are aborted and leave no event behind.
+func runRegistryGC(ctx context.Context, remove bool) (err error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGC, Value: "registry"},
+		InternalKind: "registry gc",
+		Allowed:      event.Allowed(permission.PermClusterReadEvents, permission.Context(permTypes.CtxGlobal, "")),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	var unreferenced []string
+	defer func() {
+		if err == nil && len(unreferenced) == 0 {
+			evt.Abort(ctx)
+			return
+		}
+		evt.DoneCustomData(ctx, err, map[string]interface{}{"removed": remove, "tags": unreferenced})
+	}()
+	usage, err := collectRegistryUsage(ctx, remove)
+	for _, u := range usage {
+		for _, tag := range u.UnreferencedTags {
+			unreferenced = append(unreferenced, fmt.Sprintf("%s %s: %s", u.Kind, u.Name, tag))
+		}
+	}
+	saveErr := registry.SaveUsage(ctx, usage)
+	if err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// registryOwner holds the images referenced by an app or job, grouped by
+// repository.
+type registryOwner struct {
+	kind  string
+	name  string
+	repos map[string]map[string]struct{}
+}
+
+func (o *registryOwner) reference(imageName string) {
+	if imageName == "" {
+		return
+	}
+	repo, tag := image.SplitImageName(imageName)
+	if o.repos[repo] == nil {
+		o.repos[repo] = map[string]struct{}{}
+	}
+	o.repos[repo][tag] = struct{}{}
+}
+
+func registryOwners(ctx context.Context) ([]*registryOwner, error) {
+	allAppVersions, err := servicemanager.AppVersion.AllAppVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var owners []*registryOwner
+	for _, appVersions := range allAppVersions {
+		owner := &registryOwner{kind: registry.UsageKindApp, name: appVersions.AppName, repos: map[string]map[string]struct{}{}}
+		for _, version := range appVersions.Versions {
+			owner.reference(version.BuildImage)
+			owner.reference(version.DeployImage)
+		}
+		for repo, tags := range owner.repos {
+			// Images are only committed to the version once pushed, the
+			// tags of versions being deployed must be kept as well.
+			for _, version := range appVersions.Versions {
+				tags[fmt.Sprintf("v%d", version.Version)] = struct{}{}
+				tags[fmt.Sprintf("v%d-builder", version.Version)] = struct{}{}
+				if version.CustomBuildTag != "" {
+					tags[version.CustomBuildTag] = struct{}{}
+				}
+			}
+			owner.repos[repo] = tags
+		}
+		owners = append(owners, owner)
+	}
+	jobs, err := servicemanager.Job.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		owner := &registryOwner{kind: registry.UsageKindJob, name: job.Name, repos: map[string]map[string]struct{}{}}
+		owner.reference(job.Spec.Container.InternalRegistryImage)
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+// collectRegistryUsage cross-references the tags in the repositories of apps
+// and jobs with the images they use. When remove is true the unreferenced
+// tags are deleted from the registry.
+func collectRegistryUsage(ctx context.Context, remove bool) ([]registry.Usage, error) {
+	owners, err := registryOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	var usage []registry.Usage
+	for _, owner := range owners {
+		if len(owner.repos) == 0 {
+			continue
+		}
+		u, ownerErr := ownerRegistryUsage(ctx, owner, remove)
+		if ownerErr != nil {
+			multi.Add(errors.Wrapf(ownerErr, "unable to collect registry usage of %s %q", owner.kind, owner.name))
+		}
+		usage = append(usage, u)
+	}
+	return usage, multi.ToError()
+}
+
+type registryTag struct {
+	name   string
+	digest string
+	blobs  map[string]int64
+}
+
+func ownerRegistryUsage(ctx context.Context, owner *registryOwner, remove bool) (registry.Usage, error) {
+	u := registry.Usage{Kind: owner.kind, Name: owner.name}
+	usedBlobs := map[string]int64{}
+	unreferencedBlobs := map[string]int64{}
+	multi := tsuruErrors.NewMultiError()
+	for repo, referenced := range owner.repos {
+		u.Repositories = append(u.Repositories, repo)
+		tags, err := repositoryTags(ctx, repo)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		u.Tags += len(tags)
+		// Manifests are removed by digest, an unreferenced tag pointing to
+		// the same image as a referenced one must be kept.
+		referencedDigests := map[string]struct{}{}
+		for _, tag := range tags {
+			if _, ok := referenced[tag.name]; ok {
+				referencedDigests[tag.digest] = struct{}{}
+			}
+		}
+		for _, tag := range tags {
+			_, isReferenced := referencedDigests[tag.digest]
+			target := usedBlobs
+			if !isReferenced {
+				target = unreferencedBlobs
+				u.UnreferencedTags = append(u.UnreferencedTags, repo+":"+tag.name)
+			}
+			for digest, size := range tag.blobs {
+				target[digest] = size
+			}
+			if isReferenced || !remove {
+				continue
+			}
+			err = pruneImageFromRegistry(ctx, repo+":"+tag.name)
+			if err != nil {
+				multi.Add(err)
+			}
+		}
+	}
+	for digest, size := range usedBlobs {
+		u.UsedBytes += size
+		delete(unreferencedBlobs, digest)
+	}
+	for _, size := range unreferencedBlobs {
+		u.ReclaimableBytes += size
+	}
+	sort.Strings(u.Repositories)
+	sort.Strings(u.UnreferencedTags)
+	return u, multi.ToError()
+}
+
+func repositoryTags(ctx context.Context, repo string) ([]registryTag, error) {
+	names, err := registry.ListTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]registryTag, 0, len(names))
+	for _, name := range names {
+		imageName := repo + ":" + name
+		digest, err := registry.ImageDigest(ctx, imageName)
+		if err != nil {
+			return nil, err
+		}
+		blobs, err := registry.ImageBlobs(ctx, imageName)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, registryTag{name: name, digest: digest, blobs: blobs})
+	}
+	return tags, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/registry"
+	registrytest "github.com/tsuru/tsuru/registry/testing"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupRegistryGC(c *check.C) (*registrytest.RegistryServer, string) {
+	server, err := registrytest.NewServer("127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	s.cluster.CustomData = map[string]string{"registry": server.Addr() + "/tsuru"}
+	fakeApp := provisiontest.NewFakeApp("myapp", "go", 0)
+	insertTestVersions(c, fakeApp, 2)
+	server.AddRepo(registrytest.Repository{
+		Name: "tsuru/app-myapp",
+		Tags: map[string]string{
+			"v2":         "sha256:v2",
+			"v3":         "sha256:v3",
+			"v3-builder": "sha256:v3-builder",
+			"alias":      "sha256:v3",
+			"old":        "sha256:old",
+			"v0":         "sha256:v0",
+		},
+		Layers: map[string][]registrytest.Layer{
+			"v3":    {{Digest: "sha256:base", Size: 100}, {Digest: "sha256:l3", Size: 10}},
+			"alias": {{Digest: "sha256:base", Size: 100}, {Digest: "sha256:l3", Size: 10}},
+			"old":   {{Digest: "sha256:base", Size: 100}, {Digest: "sha256:lold", Size: 50}},
+			"v0":    {{Digest: "sha256:l0", Size: 20}},
+		},
+	})
+	return server, server.Addr() + "/tsuru/app-myapp"
+}
+
+func (s *S) TestRunRegistryGCDryRun(c *check.C) {
+	server, repo := s.setupRegistryGC(c)
+	defer server.Stop()
+	err := runRegistryGC(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	c.Assert(server.Repos[0].Tags, check.HasLen, 6)
+	usage, err := registry.ListUsage(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.HasLen, 1)
+	c.Assert(usage[0].Kind, check.Equals, registry.UsageKindApp)
+	c.Assert(usage[0].Name, check.Equals, "myapp")
+	c.Assert(usage[0].Repositories, check.DeepEquals, []string{repo})
+	c.Assert(usage[0].Tags, check.Equals, 6)
+	c.Assert(usage[0].UnreferencedTags, check.DeepEquals, []string{repo + ":old", repo + ":v0"})
+	c.Assert(usage[0].UsedBytes, check.Equals, int64(110))
+	c.Assert(usage[0].ReclaimableBytes, check.Equals, int64(70))
+}
+
+func (s *S) TestRunRegistryGCRemovesUnreferencedTags(c *check.C) {
+	server, _ := s.setupRegistryGC(c)
+	defer server.Stop()
+	err := runRegistryGC(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	c.Assert(server.Repos[0].Tags, check.DeepEquals, map[string]string{
+		"v2":         "sha256:v2",
+		"v3":         "sha256:v3",
+		"v3-builder": "sha256:v3-builder",
+		"alias":      "sha256:v3",
+	})
+	evts, err := event.All(context.TODO())
+	c.Assert(err, check.IsNil)
+	evts = filterGCEvents(evts)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Kind.Name, check.Equals, "registry gc")
+}
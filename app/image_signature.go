@@ -0,0 +1,46 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/registry"
+	"github.com/tsuru/tsuru/servicemanager"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+var verifyImageSignature = registry.VerifyImageSignature
+
+// checkImageSignature verifies the signature of the image of image deploys
+// when required by the cluster of the app, recording the verification in the
+// deploy event. The deploy goes on with the verified digest of the image.
+func checkImageSignature(ctx context.Context, opts *DeployOptions, prov provision.Provisioner) error {
+	cluster, err := servicemanager.Cluster.FindByPool(ctx, prov.GetName(), opts.App.Pool)
+	if err != nil {
+		if err == provisionTypes.ErrNoCluster {
+			return nil
+		}
+		return err
+	}
+	if cluster == nil {
+		return nil
+	}
+	format, keys := cluster.ImageSignaturePolicy()
+	if format == "" {
+		return nil
+	}
+	fmt.Fprintf(opts.Event, "---- Verifying %s signature of image %s ----\n", format, opts.Image)
+	signature, err := verifyImageSignature(ctx, opts.Image, format, keys)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Event, "---- Image %s signed by key %s ----\n", signature.Digest, signature.KeyFingerprint)
+	opts.imageSignature = signature
+	opts.Image = signature.ImageByDigest()
+	return opts.Event.SetOtherCustomData(ctx, deployOtherData{ImageSignature: signature})
+}
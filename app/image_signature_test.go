@@ -0,0 +1,80 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/registry"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+const signatureTestKey = "-----BEGIN PUBLIC KEY-----\nMFkw\n-----END PUBLIC KEY-----\n"
+
+func (s *S) deployImageRequiringSignature(c *check.C, verify func(ctx context.Context, imageName, format string, keys []string) (*registry.ImageSignature, error)) (*event.Event, *bytes.Buffer, error) {
+	s.mockService.Cluster.OnFindByPool = func(prov, pool string) (*provisionTypes.Cluster, error) {
+		return &provisionTypes.Cluster{
+			Name: "c1",
+			CustomData: map[string]string{
+				provisionTypes.ClusterImageSignatureKey:           provisionTypes.ImageSignatureCosign,
+				provisionTypes.ClusterImageSignaturePublicKeysKey: signatureTestKey,
+			},
+		}, nil
+	}
+	oldVerify := verifyImageSignature
+	defer func() { verifyImageSignature = oldVerify }()
+	verifyImageSignature = verify
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name, Router: "fake"}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	writer := &bytes.Buffer{}
+	_, err = Deploy(context.TODO(), DeployOptions{
+		App:          &a,
+		Image:        "registry.io/tsuru/myimage:v1",
+		OutputStream: writer,
+		Event:        evt,
+	})
+	return evt, writer, err
+}
+
+func (s *S) TestDeployImageSignatureVerified(c *check.C) {
+	evt, writer, err := s.deployImageRequiringSignature(c, func(ctx context.Context, imageName, format string, keys []string) (*registry.ImageSignature, error) {
+		c.Assert(imageName, check.Equals, "registry.io/tsuru/myimage:v1")
+		c.Assert(format, check.Equals, provisionTypes.ImageSignatureCosign)
+		c.Assert(keys, check.DeepEquals, []string{signatureTestKey})
+		return &registry.ImageSignature{Format: format, Image: imageName, Digest: "sha256:abc", KeyFingerprint: "sha256:def"}, nil
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(writer.String(), check.Matches, "(?s).*Verifying cosign signature of image registry.io/tsuru/myimage:v1.*Builder deploy called.*")
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	deploy, err := GetDeploy(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(deploy.ImageSignature, check.NotNil)
+	c.Assert(deploy.ImageSignature.Digest, check.Equals, "sha256:abc")
+	c.Assert(deploy.ImageSignature.KeyFingerprint, check.Equals, "sha256:def")
+}
+
+func (s *S) TestDeployImageSignatureNotSigned(c *check.C) {
+	_, writer, err := s.deployImageRequiringSignature(c, func(ctx context.Context, imageName, format string, keys []string) (*registry.ImageSignature, error) {
+		return nil, errors.Wrap(registry.ErrImageNotSigned, "cosign signature verification failed")
+	})
+	c.Assert(errors.Cause(err), check.Equals, registry.ErrImageNotSigned)
+	c.Assert(writer.String(), check.Not(check.Matches), "(?s).*Builder deploy called.*")
+}
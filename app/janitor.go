@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultJanitorInterval = time.Hour
+
+type janitor struct {
+	interval time.Duration
+	remove   bool
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// StartJanitor starts a background loop looking for objects left behind in
+// the provisioners by failed removals of apps, versions and volumes, and
+// deleting them. It's only started when janitor:enabled is set.
+func StartJanitor() error {
+	enabled, _ := config.GetBool("janitor:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("janitor:interval")
+	if interval <= 0 {
+		interval = defaultJanitorInterval
+	}
+	dryRun, _ := config.GetBool("janitor:dry-run")
+	j := &janitor{
+		interval: interval,
+		remove:   !dryRun,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go j.spin()
+	shutdown.Register(j)
+	return nil
+}
+
+func (j *janitor) spin() {
+	defer close(j.doneCh)
+	for {
+		err := runJanitor(context.Background(), j.remove)
+		if err != nil {
+			log.Errorf("[janitor] %v", err)
+		}
+		select {
+		case <-j.stopCh:
+			return
+		case <-time.After(j.interval):
+		}
+	}
+}
+
+func (j *janitor) Shutdown(ctx context.Context) error {
+	close(j.stopCh)
+	select {
+	case <-j.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// runJanitor records the orphans found in a janitor event. Runs without
+// orphans are aborted and leave no event behind.
+func runJanitor(ctx context.Context, remove bool) (err error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGC, Value: "janitor"},
+		InternalKind: "janitor",
+		Allowed:      event.Allowed(permission.PermClusterReadEvents, permission.Context(permTypes.CtxGlobal, "")),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	var orphans []provision.OrphanResource
+	defer func() {
+		if err == nil && len(orphans) == 0 {
+			evt.Abort(ctx)
+			return
+		}
+		evt.DoneCustomData(ctx, err, orphans)
+	}()
+	orphans, err = OrphanResources(ctx, remove)
+	return err
+}
+
+// OrphanResources returns the objects left behind in every provisioner able
+// to find them. When remove is true the objects found are also deleted.
+func OrphanResources(ctx context.Context, remove bool) ([]provision.OrphanResource, error) {
+	provisioners, err := provision.Registry()
+	if err != nil {
+		return nil, err
+	}
+	orphans := []provision.OrphanResource{}
+	multiErr := tsuruErrors.NewMultiError()
+	for _, p := range provisioners {
+		janitorProv, ok := p.(provision.JanitorProvisioner)
+		if !ok {
+			continue
+		}
+		var provOrphans []provision.OrphanResource
+		if remove {
+			provOrphans, err = janitorProv.RemoveOrphanResources(ctx)
+		} else {
+			provOrphans, err = janitorProv.OrphanResources(ctx)
+		}
+		if err != nil {
+			multiErr.Add(errors.Wrapf(err, "unable to find orphan resources in provisioner %q", p.GetName()))
+			continue
+		}
+		orphans = append(orphans, provOrphans...)
+	}
+	return orphans, multiErr.ToError()
+}
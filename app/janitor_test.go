@@ -0,0 +1,53 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRunJanitor(c *check.C) {
+	orphan := provision.OrphanResource{
+		Cluster:   "c1",
+		Namespace: "tsuru",
+		Kind:      "secret",
+		Name:      "ghost-envs",
+		Reason:    `app "ghost" not found`,
+	}
+	s.provisioner.AddOrphanResources(orphan)
+	err := runJanitor(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	orphans, err := OrphanResources(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 0)
+	err = runJanitor(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeGC, Value: "janitor"},
+		KindNames: []string{"janitor"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	var removed []provision.OrphanResource
+	err = evts[0].EndData(&removed)
+	c.Assert(err, check.IsNil)
+	orphan.Removed = true
+	c.Assert(removed, check.DeepEquals, []provision.OrphanResource{orphan})
+}
+
+func (s *S) TestRunJanitorDryRun(c *check.C) {
+	s.provisioner.AddOrphanResources(provision.OrphanResource{Kind: "service", Name: "ghost-web"})
+	err := runJanitor(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	orphans, err := OrphanResources(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 1)
+	c.Assert(orphans[0].Removed, check.Equals, false)
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// maxLogSinks limits the sinks of an app, as every log line is sent to each
+// one of them.
+const maxLogSinks = 5
+
+var ErrLogSinkNotFound = errors.New("log sink not found")
+
+// SetLogSink starts forwarding the logs of the app to the sink, replacing any
+// sink with the same name.
+func SetLogSink(ctx context.Context, app *appTypes.App, sink appTypes.LogSink) error {
+	if err := sink.Validate(); err != nil {
+		return err
+	}
+	sinks := slices.DeleteFunc(slices.Clone(app.LogSinks), func(s appTypes.LogSink) bool {
+		return s.Name == sink.Name
+	})
+	if len(sinks) >= maxLogSinks {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("apps may have at most %d log sinks", maxLogSinks)}
+	}
+	return saveLogSinks(ctx, app, append(sinks, sink))
+}
+
+// RemoveLogSink stops forwarding the logs of the app to the sink.
+func RemoveLogSink(ctx context.Context, app *appTypes.App, name string) error {
+	sinks := slices.DeleteFunc(slices.Clone(app.LogSinks), func(s appTypes.LogSink) bool {
+		return s.Name == name
+	})
+	if len(sinks) == len(app.LogSinks) {
+		return ErrLogSinkNotFound
+	}
+	return saveLogSinks(ctx, app, sinks)
+}
+
+func saveLogSinks(ctx context.Context, app *appTypes.App, sinks []appTypes.LogSink) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"logsinks": sinks},
+	})
+	if err != nil {
+		return err
+	}
+	app.LogSinks = sinks
+	return nil
+}
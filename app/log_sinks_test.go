@@ -0,0 +1,56 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetLogSink(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: "collector", Type: appTypes.LogSinkTypeHTTP, URL: "http://collector-1"})
+	c.Assert(err, check.IsNil)
+	err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: "syslog", Type: appTypes.LogSinkTypeSyslog, URL: "udp://syslog:514"})
+	c.Assert(err, check.IsNil)
+	err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: "collector", Type: appTypes.LogSinkTypeHTTP, URL: "http://collector-2"})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.LogSinks, check.DeepEquals, []appTypes.LogSink{
+		{Name: "syslog", Type: appTypes.LogSinkTypeSyslog, URL: "udp://syslog:514"},
+		{Name: "collector", Type: appTypes.LogSinkTypeHTTP, URL: "http://collector-2"},
+	})
+	err = RemoveLogSink(context.TODO(), &a, "syslog")
+	c.Assert(err, check.IsNil)
+	err = RemoveLogSink(context.TODO(), &a, "syslog")
+	c.Assert(err, check.Equals, ErrLogSinkNotFound)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.LogSinks, check.DeepEquals, []appTypes.LogSink{
+		{Name: "collector", Type: appTypes.LogSinkTypeHTTP, URL: "http://collector-2"},
+	})
+}
+
+func (s *S) TestSetLogSinkInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: "collector", Type: "fluentd", URL: "http://collector"})
+	c.Assert(err, check.ErrorMatches, `invalid log sink type "fluentd".*`)
+	for i := 0; i < maxLogSinks; i++ {
+		err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: fmt.Sprintf("collector-%d", i), Type: appTypes.LogSinkTypeHTTP, URL: "http://collector"})
+		c.Assert(err, check.IsNil)
+	}
+	err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: "one-more", Type: appTypes.LogSinkTypeHTTP, URL: "http://collector"})
+	c.Assert(err, check.ErrorMatches, "apps may have at most 5 log sinks")
+	err = SetLogSink(context.TODO(), &a, appTypes.LogSink{Name: "collector-0", Type: appTypes.LogSinkTypeHTTP, URL: "http://other-collector"})
+	c.Assert(err, check.IsNil)
+}
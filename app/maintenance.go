@@ -0,0 +1,100 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router/rebuild"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// maxMaintenancePageSize is the maximum size of the maintenance page, which
+// is sent to the routers along with the backend of the app.
+const maxMaintenancePageSize = 64 * 1024
+
+var ErrAppNotInMaintenance = errors.New("app is not in maintenance mode")
+
+// MaintenanceArgs holds the response served by the routers while the app is
+// in maintenance mode and who is enabling it.
+type MaintenanceArgs struct {
+	Page        string
+	RedirectURL string
+	Owner       string
+	Writer      io.Writer
+}
+
+// SetMaintenance puts the app in maintenance mode, replacing the page or
+// redirect of a previous one. Routes are rebuilt right away and the app is
+// left as it was if any router of the app refuses it.
+func SetMaintenance(ctx context.Context, app *appTypes.App, args MaintenanceArgs) error {
+	if args.Page != "" && args.RedirectURL != "" {
+		return &tsuruErrors.ValidationError{Message: "maintenance mode must serve either a page or a redirect"}
+	}
+	if len(args.Page) > maxMaintenancePageSize {
+		return &tsuruErrors.ValidationError{Message: "maintenance page must have at most 64KiB"}
+	}
+	if args.RedirectURL != "" {
+		u, err := url.Parse(args.RedirectURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &tsuruErrors.ValidationError{Message: "maintenance redirect must be an absolute http or https URL"}
+		}
+	}
+	return updateMaintenance(ctx, app, &appTypes.Maintenance{
+		Page:        args.Page,
+		RedirectURL: args.RedirectURL,
+		StartedAt:   time.Now().UTC(),
+		StartedBy:   args.Owner,
+	}, args.Writer)
+}
+
+// UnsetMaintenance makes the routers send requests to the units of the app
+// again.
+func UnsetMaintenance(ctx context.Context, app *appTypes.App, w io.Writer) error {
+	if app.Maintenance == nil {
+		return ErrAppNotInMaintenance
+	}
+	return updateMaintenance(ctx, app, nil, w)
+}
+
+func updateMaintenance(ctx context.Context, app *appTypes.App, maintenance *appTypes.Maintenance, w io.Writer) error {
+	previous := app.Maintenance
+	err := saveMaintenance(ctx, app, maintenance)
+	if err != nil {
+		return err
+	}
+	err = rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{App: app, Writer: w})
+	if err == nil {
+		return nil
+	}
+	if rollbackErr := saveMaintenance(ctx, app, previous); rollbackErr != nil {
+		return tsuruErrors.NewMultiError(err, rollbackErr)
+	}
+	return err
+}
+
+func saveMaintenance(ctx context.Context, app *appTypes.App, maintenance *appTypes.Maintenance) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	update := mongoBSON.M{"$set": mongoBSON.M{"maintenance": maintenance}}
+	if maintenance == nil {
+		update = mongoBSON.M{"$unset": mongoBSON.M{"maintenance": ""}}
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Maintenance = maintenance
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetMaintenance(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetMaintenance(context.TODO(), &a, MaintenanceArgs{Page: "<h1>Be right back</h1>", Owner: s.user.Email, Writer: io.Discard})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.BackendOpts[a.Name].Maintenance, check.DeepEquals, &router.BackendMaintenance{Page: "<h1>Be right back</h1>"})
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Maintenance, check.NotNil)
+	c.Assert(dbApp.Maintenance.Page, check.Equals, "<h1>Be right back</h1>")
+	c.Assert(dbApp.Maintenance.StartedBy, check.Equals, s.user.Email)
+	err = UnsetMaintenance(context.TODO(), &a, io.Discard)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.BackendOpts[a.Name].Maintenance, check.IsNil)
+	err = UnsetMaintenance(context.TODO(), &a, io.Discard)
+	c.Assert(err, check.Equals, ErrAppNotInMaintenance)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Maintenance, check.IsNil)
+}
+
+func (s *S) TestSetMaintenanceInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		args     MaintenanceArgs
+		expected string
+	}{
+		{MaintenanceArgs{Page: "down", RedirectURL: "https://status.example.com"}, "maintenance mode must serve either a page or a redirect"},
+		{MaintenanceArgs{Page: strings.Repeat("a", maxMaintenancePageSize+1)}, "maintenance page must have at most 64KiB"},
+		{MaintenanceArgs{RedirectURL: "/status"}, "maintenance redirect must be an absolute http or https URL"},
+		{MaintenanceArgs{RedirectURL: "ftp://status.example.com"}, "maintenance redirect must be an absolute http or https URL"},
+	}
+	for _, tt := range tests {
+		err = SetMaintenance(context.TODO(), &a, tt.args)
+		c.Assert(err, check.ErrorMatches, tt.expected)
+	}
+	c.Assert(a.Maintenance, check.IsNil)
+}
+
+func (s *S) TestSetMaintenanceRouterFailureRestoresState(c *check.C) {
+	a := appTypes.App{Name: "myapp-with-error", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetMaintenance(context.TODO(), &a, MaintenanceArgs{Writer: io.Discard})
+	c.Assert(err, check.NotNil)
+	c.Assert(a.Maintenance, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Maintenance, check.IsNil)
+}
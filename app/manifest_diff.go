@@ -0,0 +1,158 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrNoDeployManifests = errors.New("the deploy has no rendered manifests")
+
+// ManifestChange is a summarized field of the manifest of a process that
+// differs between two deploys.
+type ManifestChange struct {
+	Field    string `json:"field"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// ProcessManifestDiff describes the changes to the manifest of a process,
+// Manifest is a line diff of the whole manifest.
+type ProcessManifestDiff struct {
+	Process  string           `json:"process"`
+	Changes  []ManifestChange `json:"changes,omitempty"`
+	Manifest string           `json:"manifest,omitempty"`
+}
+
+// ManifestDiff compares the manifests rendered by a deploy with the ones of
+// the previous successful deploy of the app. Only processes with changes are
+// listed.
+type ManifestDiff struct {
+	App              string                `json:"app"`
+	DeployID         string                `json:"deployID"`
+	PreviousDeployID string                `json:"previousDeployID,omitempty"`
+	Processes        []ProcessManifestDiff `json:"processes"`
+}
+
+// deployManifests returns the manifests rendered by the provisioner for the
+// deployed version, failures are only logged.
+func deployManifests(ctx context.Context, app *appTypes.App, version appTypes.AppVersion) []provision.RenderedManifest {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		log.Errorf("unable to get provisioner for app %s: %v", app.Name, err)
+		return nil
+	}
+	manifestsProv, ok := prov.(provision.ManifestsProvisioner)
+	if !ok {
+		return nil
+	}
+	manifests, err := manifestsProv.RenderedManifests(ctx, app, version)
+	if err != nil {
+		log.Errorf("unable to get rendered manifests of app %s: %v", app.Name, err)
+		return nil
+	}
+	return manifests
+}
+
+// DeployManifestDiff returns the changes in the manifests rendered by the
+// deploy compared to the previous successful deploy of the same app.
+func DeployManifestDiff(ctx context.Context, id string) (*ManifestDiff, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, errors.Errorf("id parameter is not ObjectId: %s", id)
+	}
+	evt, err := event.GetByHexID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if evt.Target.Type != eventTypes.TargetTypeApp || evt.Kind.Name != permission.PermAppDeploy.FullName() {
+		return nil, event.ErrEventNotFound
+	}
+	var current deployOtherData
+	if err = evt.OtherData(&current); err != nil || len(current.Manifests) == 0 {
+		return nil, ErrNoDeployManifests
+	}
+	diff := &ManifestDiff{App: evt.Target.Value, DeployID: id}
+	running := false
+	previousEvts, err := event.List(ctx, &event.Filter{
+		Target:    evt.Target,
+		KindType:  eventTypes.KindTypePermission,
+		KindNames: []string{permission.PermAppDeploy.FullName()},
+		Until:     evt.StartTime,
+		Running:   &running,
+		Raw: mongoBSON.M{
+			"uniqueid":                  mongoBSON.M{"$ne": evt.UniqueID},
+			"error":                     "",
+			"othercustomdata.manifests": mongoBSON.M{"$exists": true},
+		},
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var previous deployOtherData
+	if len(previousEvts) > 0 {
+		if err = previousEvts[0].OtherData(&previous); err != nil {
+			return nil, err
+		}
+		diff.PreviousDeployID = previousEvts[0].UniqueID.Hex()
+	}
+	diff.Processes = diffManifests(previous.Manifests, current.Manifests)
+	return diff, nil
+}
+
+func diffManifests(previous, current []provision.RenderedManifest) []ProcessManifestDiff {
+	byProcess := map[string][2]provision.RenderedManifest{}
+	for _, m := range previous {
+		pair := byProcess[m.Process]
+		pair[0] = m
+		byProcess[m.Process] = pair
+	}
+	for _, m := range current {
+		pair := byProcess[m.Process]
+		pair[1] = m
+		byProcess[m.Process] = pair
+	}
+	processes := make([]string, 0, len(byProcess))
+	for process := range byProcess {
+		processes = append(processes, process)
+	}
+	sort.Strings(processes)
+	result := []ProcessManifestDiff{}
+	for _, process := range processes {
+		prev, cur := byProcess[process][0], byProcess[process][1]
+		processDiff := ProcessManifestDiff{
+			Process:  process,
+			Manifest: lineDiff(prev.Manifest, cur.Manifest),
+		}
+		for _, field := range []struct {
+			name      string
+			prev, cur string
+		}{
+			{"image", prev.Image, cur.Image},
+			{"envHash", prev.EnvHash, cur.EnvHash},
+			{"resources", prev.Resources, cur.Resources},
+			{"probes", prev.Probes, cur.Probes},
+		} {
+			if field.prev != field.cur {
+				processDiff.Changes = append(processDiff.Changes, ManifestChange{Field: field.name, Previous: field.prev, Current: field.cur})
+			}
+		}
+		if len(processDiff.Changes) > 0 || processDiff.Manifest != "" {
+			result = append(result, processDiff)
+		}
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDiffManifests(c *check.C) {
+	previous := []provision.RenderedManifest{
+		{Process: "web", Image: "app:v1", EnvHash: "a", Resources: "{}", Probes: "{}", Manifest: "image: app:v1\n"},
+		{Process: "old", Image: "app:v1", Manifest: "image: app:v1\n"},
+		{Process: "same", Image: "app:v1", Manifest: "image: app:v1\n"},
+	}
+	current := []provision.RenderedManifest{
+		{Process: "web", Image: "app:v2", EnvHash: "b", Resources: "{}", Probes: "{}", Manifest: "image: app:v2\n"},
+		{Process: "same", Image: "app:v1", Manifest: "image: app:v1\n"},
+	}
+	c.Assert(diffManifests(previous, current), check.DeepEquals, []ProcessManifestDiff{
+		{
+			Process:  "old",
+			Changes:  []ManifestChange{{Field: "image", Previous: "app:v1", Current: ""}},
+			Manifest: "-image: app:v1\n",
+		},
+		{
+			Process: "web",
+			Changes: []ManifestChange{
+				{Field: "image", Previous: "app:v1", Current: "app:v2"},
+				{Field: "envHash", Previous: "a", Current: "b"},
+			},
+			Manifest: "-image: app:v1\n+image: app:v2\n",
+		},
+	})
+}
+
+func (s *S) TestDeployManifestDiff(c *check.C) {
+	a := appTypes.App{Name: "some-app", Platform: "django", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	processes := []map[string][]string{
+		{"web": {"python app.py"}},
+		{"web": {"python app.py"}, "worker": {"python worker.py"}},
+	}
+	var evts []*event.Event
+	for _, procs := range processes {
+		s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+			version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+				App: app,
+			})
+			c.Assert(err, check.IsNil)
+			err = version.AddData(appTypes.AddVersionDataArgs{Processes: procs})
+			c.Assert(err, check.IsNil)
+			return version, version.CommitBuildImage()
+		}
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:   eventTypes.Target{Type: "app", Value: a.Name},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		archive := strings.NewReader("my file")
+		_, err = Deploy(context.TODO(), DeployOptions{
+			App:          &a,
+			File:         io.NopCloser(archive),
+			FileSize:     int64(archive.Len()),
+			OutputStream: io.Discard,
+			Event:        evt,
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(context.TODO(), nil)
+		c.Assert(err, check.IsNil)
+		evts = append(evts, evt)
+	}
+	diff, err := DeployManifestDiff(context.TODO(), evts[1].UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(diff.App, check.Equals, a.Name)
+	c.Assert(diff.PreviousDeployID, check.Equals, evts[0].UniqueID.Hex())
+	c.Assert(diff.Processes, check.HasLen, 2)
+	c.Assert(diff.Processes[0].Process, check.Equals, "web")
+	c.Assert(diff.Processes[0].Changes, check.HasLen, 1)
+	c.Assert(diff.Processes[0].Changes[0].Field, check.Equals, "image")
+	c.Assert(diff.Processes[1].Process, check.Equals, "worker")
+	c.Assert(diff.Processes[1].Changes[0].Previous, check.Equals, "")
+	first, err := DeployManifestDiff(context.TODO(), evts[0].UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(first.PreviousDeployID, check.Equals, "")
+	c.Assert(first.Processes, check.HasLen, 1)
+}
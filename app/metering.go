@@ -0,0 +1,328 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	usageSamplesCollectionName = "usage_samples"
+
+	UsageGroupByApp  = "app"
+	UsageGroupByTeam = "team"
+	UsageGroupByPool = "pool"
+
+	defaultMeteringInterval  = 15 * time.Minute
+	defaultMeteringRetention = 400 * 24 * time.Hour
+
+	gigabyte = 1024 * 1024 * 1024
+)
+
+// usageSample is the usage of an app or volume during the Hours before Time.
+// Volumes are attributed to the apps bound to them in equal parts.
+type usageSample struct {
+	Time     time.Time
+	Hours    float64
+	App      string `bson:",omitempty"`
+	Volume   string `bson:",omitempty"`
+	Apps     []string
+	Team     string
+	Pool     string
+	Units    int
+	CPUMilli int
+	Memory   int64
+	Storage  int64
+	ExpireAt time.Time
+}
+
+// UsageFilter selects the samples aggregated in a usage report. Samples of
+// teams not in Teams are ignored unless it's nil.
+type UsageFilter struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+	Teams   []string
+}
+
+// UsageEntry is the usage of a single app, team or pool.
+type UsageEntry struct {
+	Name          string  `json:"name"`
+	UnitHours     float64 `json:"unitHours"`
+	CPUCoreHours  float64 `json:"cpuCoreHours"`
+	MemoryGBHours float64 `json:"memoryGBHours"`
+	VolumeGBHours float64 `json:"volumeGBHours"`
+}
+
+type UsageReport struct {
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+	GroupBy string       `json:"groupBy"`
+	Entries []UsageEntry `json:"entries"`
+}
+
+type meteringCollector struct {
+	interval time.Duration
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// StartMetering starts a background loop sampling the units of every app and
+// the volumes, which are aggregated later by GetUsageReport. It's only started
+// when metering:enabled is set.
+func StartMetering() error {
+	enabled, _ := config.GetBool("metering:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("metering:interval")
+	if interval <= 0 {
+		interval = defaultMeteringInterval
+	}
+	m := &meteringCollector{
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go m.spin()
+	shutdown.Register(m)
+	return nil
+}
+
+func (m *meteringCollector) spin() {
+	defer close(m.doneCh)
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-time.After(m.interval):
+		}
+		err := CollectUsage(context.Background(), time.Now().UTC(), m.interval)
+		if err != nil {
+			log.Errorf("[metering] %v", err)
+		}
+	}
+}
+
+func (m *meteringCollector) Shutdown(ctx context.Context) error {
+	close(m.stopCh)
+	select {
+	case <-m.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func meteringRetention() time.Duration {
+	retention, _ := config.GetDuration("metering:retention")
+	if retention <= 0 {
+		return defaultMeteringRetention
+	}
+	return retention
+}
+
+// CollectUsage stores the usage of all apps and volumes during the period
+// ending now. Failures in a single app or volume don't prevent the others
+// from being sampled.
+func CollectUsage(ctx context.Context, now time.Time, period time.Duration) error {
+	apps, err := List(ctx, nil)
+	if err != nil {
+		return err
+	}
+	hours := period.Hours()
+	expireAt := now.Add(meteringRetention())
+	multi := tsuruErrors.NewMultiError()
+	var samples []interface{}
+	for _, a := range apps {
+		units, err := AppUnits(ctx, a)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to list units of app %q", a.Name))
+			continue
+		}
+		running := 0
+		for _, u := range units {
+			if u.Status != provTypes.UnitStatusStopped {
+				running++
+			}
+		}
+		cpuMilli, memory := planRequests(a.Plan, a.Pool)
+		samples = append(samples, usageSample{
+			Time:     now,
+			Hours:    hours,
+			App:      a.Name,
+			Team:     a.TeamOwner,
+			Pool:     a.Pool,
+			Units:    running,
+			CPUMilli: cpuMilli * running,
+			Memory:   memory * int64(running),
+			ExpireAt: expireAt,
+		})
+	}
+	volumes, err := servicemanager.Volume.ListByFilter(ctx, nil)
+	if err != nil {
+		multi.Add(errors.Wrap(err, "unable to list volumes"))
+	}
+	for i := range volumes {
+		v := &volumes[i]
+		sample := usageSample{
+			Time:     now,
+			Hours:    hours,
+			Volume:   v.Name,
+			Team:     v.TeamOwner,
+			Pool:     v.Pool,
+			Storage:  volumeCapacity(v),
+			ExpireAt: expireAt,
+		}
+		seen := map[string]bool{}
+		for _, b := range v.Binds {
+			if !seen[b.ID.App] {
+				seen[b.ID.App] = true
+				sample.Apps = append(sample.Apps, b.ID.App)
+			}
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) > 0 {
+		collection, err := storagev2.Collection(usageSamplesCollectionName)
+		if err != nil {
+			return err
+		}
+		_, err = collection.InsertMany(ctx, samples)
+		if err != nil {
+			multi.Add(err)
+		}
+	}
+	return multi.ToError()
+}
+
+// planRequests returns the resources requested by each unit of the plan,
+// which are its limits unless explicit requests or a memory overcommit are
+// set.
+func planRequests(plan appTypes.Plan, pool string) (int, int64) {
+	cpuMilli := plan.GetMilliCPURequest()
+	if cpuMilli == 0 {
+		cpuMilli = plan.GetMilliCPU()
+	}
+	memory := plan.GetMemoryRequest()
+	if memory == 0 {
+		memory = plan.GetMemory()
+		if overcommit := plan.GetMemoryOvercommit(pool); overcommit > 1 {
+			memory = int64(float64(memory) / overcommit)
+		}
+	}
+	return cpuMilli, memory
+}
+
+// volumeCapacity returns the capacity of the volume in bytes, set either in
+// its options or in its plan.
+func volumeCapacity(v *volumeTypes.Volume) int64 {
+	raw := v.Opts["capacity"]
+	if raw == "" {
+		if planCapacity, ok := v.Plan.Opts["capacity"]; ok {
+			raw = fmt.Sprint(planCapacity)
+		}
+	}
+	if raw == "" {
+		return 0
+	}
+	capacity, err := resource.ParseQuantity(raw)
+	if err != nil {
+		return 0
+	}
+	return capacity.Value()
+}
+
+// GetUsageReport aggregates the usage sampled between From and To by app,
+// team or pool.
+func GetUsageReport(ctx context.Context, filter UsageFilter) (*UsageReport, error) {
+	if filter.GroupBy == "" {
+		filter.GroupBy = UsageGroupByApp
+	}
+	if filter.GroupBy != UsageGroupByApp && filter.GroupBy != UsageGroupByTeam && filter.GroupBy != UsageGroupByPool {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid groupBy %q, must be %q, %q or %q", filter.GroupBy, UsageGroupByApp, UsageGroupByTeam, UsageGroupByPool)}
+	}
+	if !filter.To.After(filter.From) {
+		return nil, &tsuruErrors.ValidationError{Message: "the end of the report window must be after its start"}
+	}
+	report := &UsageReport{From: filter.From, To: filter.To, GroupBy: filter.GroupBy, Entries: []UsageEntry{}}
+	if filter.Teams != nil && len(filter.Teams) == 0 {
+		return report, nil
+	}
+	collection, err := storagev2.Collection(usageSamplesCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{"time": mongoBSON.M{"$gt": filter.From, "$lte": filter.To}}
+	if filter.Teams != nil {
+		query["team"] = mongoBSON.M{"$in": filter.Teams}
+	}
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var samples []usageSample
+	err = cursor.All(ctx, &samples)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]*UsageEntry{}
+	entry := func(name string) *UsageEntry {
+		if entries[name] == nil {
+			entries[name] = &UsageEntry{Name: name}
+		}
+		return entries[name]
+	}
+	for _, s := range samples {
+		if s.Volume != "" {
+			gbHours := float64(s.Storage) / gigabyte * s.Hours
+			if filter.GroupBy != UsageGroupByApp {
+				entry(usageGroupKey(filter.GroupBy, s)).VolumeGBHours += gbHours
+				continue
+			}
+			for _, appName := range s.Apps {
+				entry(appName).VolumeGBHours += gbHours / float64(len(s.Apps))
+			}
+			continue
+		}
+		e := entry(usageGroupKey(filter.GroupBy, s))
+		e.UnitHours += float64(s.Units) * s.Hours
+		e.CPUCoreHours += float64(s.CPUMilli) / 1000 * s.Hours
+		e.MemoryGBHours += float64(s.Memory) / gigabyte * s.Hours
+	}
+	for _, e := range entries {
+		report.Entries = append(report.Entries, *e)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		return report.Entries[i].Name < report.Entries[j].Name
+	})
+	return report, nil
+}
+
+func usageGroupKey(groupBy string, s usageSample) string {
+	switch groupBy {
+	case UsageGroupByTeam:
+		return s.Team
+	case UsageGroupByPool:
+		return s.Pool
+	}
+	return s.App
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"github.com/tsuru/tsuru/types/quota"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestPlanRequests(c *check.C) {
+	plan := appTypes.Plan{CPUMilli: 1000, Memory: 1024}
+	cpu, memory := planRequests(plan, "pool1")
+	c.Assert(cpu, check.Equals, 1000)
+	c.Assert(memory, check.Equals, int64(1024))
+	plan.Requests = &appTypes.PlanRequests{CPUMilli: 250, MemoryOvercommit: map[string]float64{"pool1": 2}}
+	cpu, memory = planRequests(plan, "pool1")
+	c.Assert(cpu, check.Equals, 250)
+	c.Assert(memory, check.Equals, int64(512))
+	plan.Requests.Memory = 256
+	_, memory = planRequests(plan, "pool1")
+	c.Assert(memory, check.Equals, int64(256))
+}
+
+func (s *S) TestVolumeCapacity(c *check.C) {
+	v := volumeTypes.Volume{Opts: map[string]string{"capacity": "2Gi"}}
+	c.Assert(volumeCapacity(&v), check.Equals, int64(2*gigabyte))
+	v = volumeTypes.Volume{Plan: volumeTypes.VolumePlan{Opts: map[string]interface{}{"capacity": "1Gi"}}}
+	c.Assert(volumeCapacity(&v), check.Equals, int64(gigabyte))
+	v = volumeTypes.Volume{Opts: map[string]string{"capacity": "invalid"}}
+	c.Assert(volumeCapacity(&v), check.Equals, int64(0))
+}
+
+func (s *S) TestCollectUsage(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = AddUnits(context.TODO(), &a, 2, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC().Truncate(time.Second)
+	err = CollectUsage(context.TODO(), now, 30*time.Minute)
+	c.Assert(err, check.IsNil)
+	report, err := GetUsageReport(context.TODO(), UsageFilter{From: now.Add(-time.Hour), To: now})
+	c.Assert(err, check.IsNil)
+	c.Assert(report.GroupBy, check.Equals, UsageGroupByApp)
+	c.Assert(report.Entries, check.HasLen, 1)
+	c.Assert(report.Entries[0].Name, check.Equals, a.Name)
+	c.Assert(report.Entries[0].UnitHours, check.Equals, 1.0)
+}
+
+func (s *S) TestGetUsageReport(c *check.C) {
+	collection, err := storagev2.Collection(usageSamplesCollectionName)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = collection.InsertMany(context.TODO(), []interface{}{
+		usageSample{Time: now, Hours: 1, App: "app1", Team: "team1", Pool: "pool1", Units: 2, CPUMilli: 1000, Memory: 2 * gigabyte},
+		usageSample{Time: now, Hours: 1, App: "app2", Team: "team2", Pool: "pool1", Units: 1, CPUMilli: 500, Memory: gigabyte},
+		usageSample{Time: now, Hours: 1, Volume: "vol1", Apps: []string{"app1", "app2"}, Team: "team1", Pool: "pool1", Storage: 10 * gigabyte},
+		usageSample{Time: now.Add(-48 * time.Hour), Hours: 1, App: "app1", Team: "team1", Pool: "pool1", Units: 10},
+	})
+	c.Assert(err, check.IsNil)
+	filter := UsageFilter{From: now.Add(-time.Hour), To: now}
+	report, err := GetUsageReport(context.TODO(), filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Entries, check.DeepEquals, []UsageEntry{
+		{Name: "app1", UnitHours: 2, CPUCoreHours: 1, MemoryGBHours: 2, VolumeGBHours: 5},
+		{Name: "app2", UnitHours: 1, CPUCoreHours: 0.5, MemoryGBHours: 1, VolumeGBHours: 5},
+	})
+	filter.GroupBy = UsageGroupByTeam
+	report, err = GetUsageReport(context.TODO(), filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Entries, check.DeepEquals, []UsageEntry{
+		{Name: "team1", UnitHours: 2, CPUCoreHours: 1, MemoryGBHours: 2, VolumeGBHours: 10},
+		{Name: "team2", UnitHours: 1, CPUCoreHours: 0.5, MemoryGBHours: 1},
+	})
+	filter.GroupBy = UsageGroupByPool
+	filter.Teams = []string{"team2"}
+	report, err = GetUsageReport(context.TODO(), filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Entries, check.DeepEquals, []UsageEntry{
+		{Name: "pool1", UnitHours: 1, CPUCoreHours: 0.5, MemoryGBHours: 1},
+	})
+	filter.GroupBy = "cluster"
+	_, err = GetUsageReport(context.TODO(), filter)
+	c.Assert(err, check.ErrorMatches, `invalid groupBy "cluster", must be "app", "team" or "pool"`)
+}
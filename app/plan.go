@@ -6,13 +6,21 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"slices"
 
 	"github.com/pkg/errors"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 )
 
+const minPlanMemory = 4 * 1024 * 1024
+
 var defaultPlans = []appTypes.Plan{
 	// general plans
 	{
@@ -161,12 +169,40 @@ func (s *planService) Create(ctx context.Context, plan appTypes.Plan) error {
 	if plan.Name == "" {
 		return appTypes.PlanValidationError{Field: "name"}
 	}
-	if plan.Memory > 0 && plan.Memory < 4194304 {
+	if plan.Memory > 0 && plan.Memory < minPlanMemory {
 		return appTypes.ErrLimitOfMemory
 	}
+	if err := validatePlanRequests(plan); err != nil {
+		return err
+	}
+	if plan.HourlyCost < 0 {
+		return appTypes.PlanValidationError{Field: "hourlyCost"}
+	}
 	return s.storage.Insert(ctx, plan)
 }
 
+func validatePlanRequests(plan appTypes.Plan) error {
+	requests := plan.Requests
+	if requests == nil {
+		return nil
+	}
+	if requests.CPUMilli < 0 || (requests.CPUMilli > 0 && (plan.CPUMilli == 0 || requests.CPUMilli > plan.CPUMilli)) {
+		return appTypes.PlanValidationError{Field: "requests.cpumilli"}
+	}
+	if requests.Memory < 0 || (requests.Memory > 0 && (plan.Memory == 0 || requests.Memory > plan.Memory)) {
+		return appTypes.PlanValidationError{Field: "requests.memory"}
+	}
+	if requests.Memory > 0 && requests.Memory < minPlanMemory {
+		return appTypes.ErrLimitOfMemory
+	}
+	for pool, factor := range requests.MemoryOvercommit {
+		if factor < 1 {
+			return appTypes.PlanValidationError{Field: "requests.memoryOvercommit." + pool}
+		}
+	}
+	return nil
+}
+
 // List implements List method of PlanService interface
 func (s *planService) List(ctx context.Context) ([]appTypes.Plan, error) {
 	return s.storage.FindAll(ctx)
@@ -181,11 +217,183 @@ func (s *planService) DefaultPlan(ctx context.Context) (*appTypes.Plan, error) {
 	return s.storage.FindDefault(ctx)
 }
 
-// Remove implements Remove method of PlanService interface
+// Remove implements Remove method of PlanService interface, plans still in
+// use by apps or jobs are not removed.
 func (s *planService) Remove(ctx context.Context, planName string) error {
+	usage, err := s.Usage(ctx, planName)
+	if err != nil {
+		return err
+	}
+	if usage.InUse() {
+		return &appTypes.PlanInUseError{Usage: *usage}
+	}
 	return s.storage.Delete(ctx, appTypes.Plan{Name: planName})
 }
 
+// Usage implements Usage method of PlanService interface, listing the apps
+// using the plan, for the whole app or for a process, and the jobs using it.
+func (s *planService) Usage(ctx context.Context, planName string) (*appTypes.PlanUsage, error) {
+	apps, err := List(ctx, &Filter{Extra: map[string][]string{
+		"plan.name":      {planName},
+		"processes.plan": {planName},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := servicemanager.Job.List(ctx, &jobTypes.Filter{Extra: map[string][]string{
+		"plan.name": {planName},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	usage := &appTypes.PlanUsage{
+		Plan: planName,
+		Apps: make([]string, 0, len(apps)),
+		Jobs: make([]string, 0, len(jobs)),
+	}
+	for _, a := range apps {
+		usage.Apps = append(usage.Apps, a.Name)
+	}
+	for _, j := range jobs {
+		usage.Jobs = append(usage.Jobs, j.Name)
+	}
+	slices.Sort(usage.Apps)
+	slices.Sort(usage.Jobs)
+	return usage, nil
+}
+
+// MigratePlan moves every app and job using the plan from to the plan to,
+// restarting apps so their units are recreated with the new resources. The
+// replacement plan must be allowed in the pools of all of them, otherwise
+// none is migrated.
+func MigratePlan(ctx context.Context, from, to string, w io.Writer) error {
+	if from == to {
+		return &tsuruErrors.ValidationError{Message: "replacement plan must be different from the removed plan"}
+	}
+	if _, err := servicemanager.Plan.FindByName(ctx, to); err != nil {
+		return errors.WithMessagef(err, "could not find replacement plan %q", to)
+	}
+	usage, err := servicemanager.Plan.Usage(ctx, from)
+	if err != nil {
+		return err
+	}
+	apps := make([]*appTypes.App, 0, len(usage.Apps))
+	jobs := make([]*jobTypes.Job, 0, len(usage.Jobs))
+	var pools []string
+	for _, appName := range usage.Apps {
+		a, err := GetByName(ctx, appName)
+		if err != nil {
+			return err
+		}
+		apps = append(apps, a)
+		pools = append(pools, a.Pool)
+	}
+	for _, jobName := range usage.Jobs {
+		j, err := servicemanager.Job.GetByName(ctx, jobName)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, j)
+		pools = append(pools, j.Pool)
+	}
+	slices.Sort(pools)
+	for _, poolName := range slices.Compact(pools) {
+		p, err := pool.GetPoolByName(ctx, poolName)
+		if err != nil {
+			return err
+		}
+		plans, err := p.GetPlans(ctx)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(plans, to) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("replacement plan %q is not allowed in pool %q", to, poolName)}
+		}
+	}
+	for _, a := range apps {
+		updateData := &appTypes.App{}
+		if a.Plan.Name == from {
+			updateData.Plan = appTypes.Plan{Name: to}
+		}
+		for _, p := range a.Processes {
+			if p.Plan == from {
+				updateData.Processes = append(updateData.Processes, appTypes.Process{Name: p.Name, Plan: to})
+			}
+		}
+		fmt.Fprintf(w, "---- Migrating app %q from plan %q to %q ----\n", a.Name, from, to)
+		err = Update(ctx, a, UpdateAppArgs{UpdateData: updateData, Writer: w, ShouldRestart: true})
+		if err != nil {
+			return errors.WithMessagef(err, "could not migrate app %q", a.Name)
+		}
+	}
+	for _, j := range jobs {
+		fmt.Fprintf(w, "---- Migrating job %q from plan %q to %q ----\n", j.Name, from, to)
+		err = servicemanager.Job.UpdateJob(ctx, &jobTypes.Job{Name: j.Name, Plan: appTypes.Plan{Name: to}}, j, nil)
+		if err != nil {
+			return errors.WithMessagef(err, "could not migrate job %q", j.Name)
+		}
+	}
+	return nil
+}
+
+// SetDefault implements SetDefault method of PlanService interface
+func (s *planService) SetDefault(ctx context.Context, d appTypes.PlanDefault) error {
+	if (d.Pool == "") == (d.Team == "") {
+		return appTypes.ErrPlanDefaultTarget
+	}
+	if _, err := s.storage.FindByName(ctx, d.Plan); err != nil {
+		return err
+	}
+	if d.Team != "" {
+		if _, err := servicemanager.Team.FindByName(ctx, d.Team); err != nil {
+			return err
+		}
+		return s.storage.SetDefault(ctx, d)
+	}
+	p, err := pool.GetPoolByName(ctx, d.Pool)
+	if err != nil {
+		return err
+	}
+	plans, err := p.GetPlans(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(plans, d.Plan) {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("plan %q is not allowed in pool %q", d.Plan, d.Pool)}
+	}
+	return s.storage.SetDefault(ctx, d)
+}
+
+// UnsetDefault implements UnsetDefault method of PlanService interface
+func (s *planService) UnsetDefault(ctx context.Context, d appTypes.PlanDefault) error {
+	if (d.Pool == "") == (d.Team == "") {
+		return appTypes.ErrPlanDefaultTarget
+	}
+	return s.storage.UnsetDefault(ctx, d)
+}
+
+// ListDefaults implements ListDefaults method of PlanService interface
+func (s *planService) ListDefaults(ctx context.Context) ([]appTypes.PlanDefault, error) {
+	return s.storage.FindDefaults(ctx)
+}
+
+// DefaultPlanFor implements DefaultPlanFor method of PlanService interface,
+// defaults pointing to removed plans are ignored.
+func (s *planService) DefaultPlanFor(ctx context.Context, d appTypes.PlanDefault) (*appTypes.Plan, error) {
+	if (d.Pool == "") == (d.Team == "") {
+		return nil, appTypes.ErrPlanDefaultTarget
+	}
+	planDefault, err := s.storage.FindDefaultFor(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.storage.FindByName(ctx, planDefault.Plan)
+	if err == appTypes.ErrPlanNotFound {
+		return nil, appTypes.ErrPlanDefaultNotFound
+	}
+	return plan, err
+}
+
 // ensureDefault creates and stores an autogenerated plan in case of no plans
 // exists.
 func (s *planService) ensureDefault(ctx context.Context) error {
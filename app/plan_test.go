@@ -6,9 +6,17 @@ package app
 
 import (
 	"context"
+	"io"
 	"sync"
 
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	check "gopkg.in/check.v1"
 )
 
@@ -38,8 +46,12 @@ func (s *S) TestPlanAddInvalid(c *check.C) {
 			Name:   "plan1",
 			Memory: 4,
 		},
+		{
+			Name:       "plan2",
+			HourlyCost: -1,
+		},
 	}
-	expectedError := []error{appTypes.PlanValidationError{Field: "name"}, appTypes.ErrLimitOfMemory}
+	expectedError := []error{appTypes.PlanValidationError{Field: "name"}, appTypes.ErrLimitOfMemory, appTypes.PlanValidationError{Field: "hourlyCost"}}
 	ps := &planService{
 		storage: &appTypes.MockPlanStorage{
 			OnInsert: func(appTypes.Plan) error {
@@ -54,6 +66,253 @@ func (s *S) TestPlanAddInvalid(c *check.C) {
 	}
 }
 
+func (s *S) TestPlanAddWithRequests(c *check.C) {
+	p := appTypes.Plan{
+		Name:     "plan1",
+		Memory:   512 * 1024 * 1024,
+		CPUMilli: 1000,
+		Requests: &appTypes.PlanRequests{
+			CPUMilli:         250,
+			Memory:           256 * 1024 * 1024,
+			MemoryOvercommit: map[string]float64{"pool1": 2},
+		},
+	}
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnInsert: func(plan appTypes.Plan) error {
+				c.Assert(plan, check.DeepEquals, p)
+				return nil
+			},
+		},
+	}
+	err := ps.Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPlanAddInvalidRequests(c *check.C) {
+	invalidPlans := []appTypes.Plan{
+		{
+			Name:     "plan1",
+			CPUMilli: 1000,
+			Requests: &appTypes.PlanRequests{CPUMilli: 2000},
+		},
+		{
+			Name:     "plan1",
+			Requests: &appTypes.PlanRequests{CPUMilli: 100},
+		},
+		{
+			Name:     "plan1",
+			Memory:   512 * 1024 * 1024,
+			Requests: &appTypes.PlanRequests{Memory: 1024 * 1024 * 1024},
+		},
+		{
+			Name:     "plan1",
+			Memory:   512 * 1024 * 1024,
+			Requests: &appTypes.PlanRequests{Memory: 4},
+		},
+		{
+			Name:     "plan1",
+			Memory:   512 * 1024 * 1024,
+			Requests: &appTypes.PlanRequests{MemoryOvercommit: map[string]float64{"pool1": 0.5}},
+		},
+	}
+	expectedError := []error{
+		appTypes.PlanValidationError{Field: "requests.cpumilli"},
+		appTypes.PlanValidationError{Field: "requests.cpumilli"},
+		appTypes.PlanValidationError{Field: "requests.memory"},
+		appTypes.ErrLimitOfMemory,
+		appTypes.PlanValidationError{Field: "requests.memoryOvercommit.pool1"},
+	}
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnInsert: func(appTypes.Plan) error {
+				c.Error("storage.Insert should not be called")
+				return nil
+			},
+		},
+	}
+	for i, p := range invalidPlans {
+		err := ps.Create(context.TODO(), p)
+		c.Assert(err, check.DeepEquals, expectedError[i])
+	}
+}
+
+func (s *S) TestPlanSetDefault(c *check.C) {
+	var stored []appTypes.PlanDefault
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnFindByName: func(name string) (*appTypes.Plan, error) {
+				if name == s.defaultPlan.Name {
+					return &s.defaultPlan, nil
+				}
+				return nil, appTypes.ErrPlanNotFound
+			},
+			OnSetDefault: func(d appTypes.PlanDefault) error {
+				stored = append(stored, d)
+				return nil
+			},
+		},
+	}
+	err := ps.SetDefault(context.TODO(), appTypes.PlanDefault{Plan: s.defaultPlan.Name, Team: s.team.Name})
+	c.Assert(err, check.IsNil)
+	err = ps.SetDefault(context.TODO(), appTypes.PlanDefault{Plan: s.defaultPlan.Name, Pool: s.Pool})
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.DeepEquals, []appTypes.PlanDefault{
+		{Plan: s.defaultPlan.Name, Team: s.team.Name},
+		{Plan: s.defaultPlan.Name, Pool: s.Pool},
+	})
+}
+
+func (s *S) TestPlanSetDefaultInvalid(c *check.C) {
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnFindByName: func(name string) (*appTypes.Plan, error) {
+				if name == s.defaultPlan.Name {
+					return &s.defaultPlan, nil
+				}
+				return nil, appTypes.ErrPlanNotFound
+			},
+			OnSetDefault: func(appTypes.PlanDefault) error {
+				c.Error("storage.SetDefault should not be called")
+				return nil
+			},
+		},
+	}
+	err := ps.SetDefault(context.TODO(), appTypes.PlanDefault{Plan: s.defaultPlan.Name})
+	c.Assert(err, check.Equals, appTypes.ErrPlanDefaultTarget)
+	err = ps.SetDefault(context.TODO(), appTypes.PlanDefault{Plan: s.defaultPlan.Name, Pool: s.Pool, Team: s.team.Name})
+	c.Assert(err, check.Equals, appTypes.ErrPlanDefaultTarget)
+	err = ps.SetDefault(context.TODO(), appTypes.PlanDefault{Plan: "unknown", Pool: s.Pool})
+	c.Assert(err, check.Equals, appTypes.ErrPlanNotFound)
+	err = ps.SetDefault(context.TODO(), appTypes.PlanDefault{Plan: s.defaultPlan.Name, Team: "unknown"})
+	c.Assert(err, check.Equals, authTypes.ErrTeamNotFound)
+}
+
+func (s *S) TestPlanDefaultPlanForIgnoresRemovedPlans(c *check.C) {
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnFindDefaultFor: func(d appTypes.PlanDefault) (*appTypes.PlanDefault, error) {
+				return &appTypes.PlanDefault{Plan: "removed", Pool: d.Pool}, nil
+			},
+			OnFindByName: func(name string) (*appTypes.Plan, error) {
+				return nil, appTypes.ErrPlanNotFound
+			},
+		},
+	}
+	plan, err := ps.DefaultPlanFor(context.TODO(), appTypes.PlanDefault{Pool: s.Pool})
+	c.Assert(err, check.Equals, appTypes.ErrPlanDefaultNotFound)
+	c.Assert(plan, check.IsNil)
+}
+
+func (s *S) TestPlanUsage(c *check.C) {
+	appsCollection, err := storagev2.AppsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = appsCollection.InsertMany(context.TODO(), []interface{}{
+		mongoBSON.M{"name": "app2", "plan": mongoBSON.M{"name": "other"}, "processes": []mongoBSON.M{{"name": "web", "plan": "plan1"}}},
+		mongoBSON.M{"name": "app1", "plan": mongoBSON.M{"name": "plan1"}},
+		mongoBSON.M{"name": "app3", "plan": mongoBSON.M{"name": "other"}},
+	})
+	c.Assert(err, check.IsNil)
+	jobsCollection, err := storagev2.JobsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = jobsCollection.InsertOne(context.TODO(), mongoBSON.M{"name": "job1", "plan": mongoBSON.M{"name": "plan1"}})
+	c.Assert(err, check.IsNil)
+	ps := &planService{}
+	usage, err := ps.Usage(context.TODO(), "plan1")
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, &appTypes.PlanUsage{Plan: "plan1", Apps: []string{"app1", "app2"}, Jobs: []string{"job1"}})
+	c.Assert(usage.InUse(), check.Equals, true)
+	usage, err = ps.Usage(context.TODO(), "unused")
+	c.Assert(err, check.IsNil)
+	c.Assert(usage.InUse(), check.Equals, false)
+}
+
+func (s *S) TestPlanRemoveInUse(c *check.C) {
+	appsCollection, err := storagev2.AppsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = appsCollection.InsertOne(context.TODO(), mongoBSON.M{"name": "app1", "plan": mongoBSON.M{"name": s.defaultPlan.Name}})
+	c.Assert(err, check.IsNil)
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnDelete: func(plan appTypes.Plan) error {
+				c.Error("storage.Delete should not be called")
+				return nil
+			},
+		},
+	}
+	err = ps.Remove(context.TODO(), s.defaultPlan.Name)
+	c.Assert(err, check.FitsTypeOf, &appTypes.PlanInUseError{})
+	c.Assert(err.(*appTypes.PlanInUseError).Usage.Apps, check.DeepEquals, []string{"app1"})
+}
+
+func (s *S) TestMigratePlan(c *check.C) {
+	s.plan = appTypes.Plan{Name: "something", Memory: 268435456}
+	driver, err := storage.GetDefaultDbDriver()
+	c.Assert(err, check.IsNil)
+	ps := &planService{storage: driver.PlanStorage}
+	s.mockService.Plan.OnUsage = func(name string) (*appTypes.PlanUsage, error) {
+		return ps.Usage(context.TODO(), name)
+	}
+	a := appTypes.App{
+		Name:      "app1",
+		TeamOwner: s.team.Name,
+		Routers:   []appTypes.AppRouter{{Name: "fake"}},
+		Processes: []appTypes.Process{{Name: "worker", Plan: s.defaultPlan.Name}},
+	}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	err = MigratePlan(context.TODO(), s.defaultPlan.Name, s.plan.Name, io.Discard)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Plan.Name, check.Equals, s.plan.Name)
+	c.Assert(dbApp.Processes, check.DeepEquals, []appTypes.Process{{Name: "worker", Plan: s.plan.Name}})
+	c.Assert(s.provisioner.Restarts(dbApp, ""), check.Equals, 1)
+	usage, err := ps.Usage(context.TODO(), s.defaultPlan.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(usage.InUse(), check.Equals, false)
+}
+
+func (s *S) TestMigratePlanNotAllowedInPool(c *check.C) {
+	s.plan = appTypes.Plan{Name: "something", Memory: 268435456}
+	ps := &planService{}
+	s.mockService.Plan.OnUsage = func(name string) (*appTypes.PlanUsage, error) {
+		return ps.Usage(context.TODO(), name)
+	}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool2"})
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{
+		PoolExpr: "pool2",
+		Field:    pool.ConstraintTypePlan,
+		Values:   []string{s.defaultPlan.Name},
+	})
+	c.Assert(err, check.IsNil)
+	a1 := appTypes.App{Name: "app1", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := appTypes.App{Name: "app2", TeamOwner: s.team.Name, Pool: "pool2", Plan: s.defaultPlan}
+	appsCollection, err := storagev2.AppsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = appsCollection.InsertOne(context.TODO(), a2)
+	c.Assert(err, check.IsNil)
+	err = MigratePlan(context.TODO(), s.defaultPlan.Name, s.plan.Name, io.Discard)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `replacement plan "something" is not allowed in pool "pool2"`)
+	for _, name := range []string{a1.Name, a2.Name} {
+		dbApp, err := GetByName(context.TODO(), name)
+		c.Assert(err, check.IsNil)
+		c.Assert(dbApp.Plan.Name, check.Equals, s.defaultPlan.Name)
+	}
+}
+
+func (s *S) TestMigratePlanInvalidReplacement(c *check.C) {
+	err := MigratePlan(context.TODO(), s.defaultPlan.Name, s.defaultPlan.Name, io.Discard)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = MigratePlan(context.TODO(), s.defaultPlan.Name, "unknown", io.Discard)
+	c.Assert(errors.Cause(err), check.Equals, appTypes.ErrPlanNotFound)
+}
+
 func (s *S) TestPlansList(c *check.C) {
 	ps := &planService{
 		storage: &appTypes.MockPlanStorage{
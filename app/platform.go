@@ -164,6 +164,7 @@ func (s *platformService) Update(ctx context.Context, opts appTypes.PlatformOpti
 		for _, app := range apps {
 			SetUpdatePlatform(ctx, app, true)
 		}
+		enqueuePlatformUpdate(ctx, opts.Name, opts.Version)
 	}
 
 	if disabledStr := opts.Args["disabled"]; disabledStr != "" {
@@ -254,6 +255,7 @@ func (s *platformService) Rollback(ctx context.Context, opts appTypes.PlatformOp
 	for _, app := range apps {
 		SetUpdatePlatform(ctx, app, true)
 	}
+	enqueuePlatformUpdate(ctx, opts.Name, opts.Version)
 	return nil
 }
 
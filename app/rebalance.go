@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+var ErrRebalanceUnitsProvisioner = errors.New("The current app provisioner does not support rebalancing units")
+
+// RebalanceUnits evicts units of the app from overloaded nodes and zones, so
+// they are rescheduled evenly. Evictions respect the disruption budgets of
+// the app.
+func RebalanceUnits(ctx context.Context, app *appTypes.App, opts provision.RebalanceUnitsOptions) ([]provision.UnitEviction, error) {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	rebalanceProv, ok := prov.(provision.UnitRebalanceProvisioner)
+	if !ok {
+		return nil, ErrRebalanceUnitsProvisioner
+	}
+	return rebalanceProv.RebalanceUnits(ctx, app, opts)
+}
+
+// RebalancePoolUnits rebalances the units of every app in the pool, apps
+// whose provisioner does not support rebalancing are skipped. MaxEvictions
+// applies to the whole pool.
+func RebalancePoolUnits(ctx context.Context, pool string, opts provision.RebalanceUnitsOptions) ([]provision.UnitEviction, error) {
+	w := opts.Writer
+	if w == nil {
+		w = io.Discard
+	}
+	apps, err := List(ctx, &Filter{Pool: pool})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	evictions := []provision.UnitEviction{}
+	multiErr := tsuruErrors.NewMultiError()
+	for _, a := range apps {
+		appOpts := opts
+		if opts.MaxEvictions > 0 {
+			appOpts.MaxEvictions = opts.MaxEvictions - len(evictions)
+			if appOpts.MaxEvictions <= 0 {
+				fmt.Fprintf(w, "---- Maximum number of evictions reached ----\n")
+				break
+			}
+		}
+		fmt.Fprintf(w, "---- Rebalancing units of app %q ----\n", a.Name)
+		appEvictions, err := RebalanceUnits(ctx, a, appOpts)
+		if err == ErrRebalanceUnitsProvisioner {
+			fmt.Fprintf(w, " ---> Skipped: %v\n", err)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(w, " ---> Error: %v\n", err)
+			multiErr.Add(errors.Wrapf(err, "unable to rebalance units of app %q", a.Name))
+			continue
+		}
+		evictions = append(evictions, appEvictions...)
+	}
+	return evictions, multiErr.ToError()
+}
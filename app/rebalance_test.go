@@ -0,0 +1,59 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createSkewedApp(c *check.C, name string) *appTypes.App {
+	a := appTypes.App{Name: name, Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 3, "web", nil, "node1", nil)
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 1, "web", nil, "node2", nil)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestRebalanceUnits(c *check.C) {
+	a := s.createSkewedApp(c, "my-app")
+	evictions, err := RebalanceUnits(context.TODO(), a, provision.RebalanceUnitsOptions{DryRun: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(evictions, check.DeepEquals, []provision.UnitEviction{
+		{App: "my-app", Unit: "my-app-2", Process: "web", Node: "node1", Reason: "node node1 has 3 units of process web while node node2 has 1"},
+	})
+	c.Assert(s.provisioner.GetUnits(a)[2].IP, check.Equals, "node1")
+	evictions, err = RebalanceUnits(context.TODO(), a, provision.RebalanceUnitsOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(evictions, check.HasLen, 1)
+	c.Assert(s.provisioner.GetUnits(a)[2].IP, check.Equals, "node2")
+	evictions, err = RebalanceUnits(context.TODO(), a, provision.RebalanceUnitsOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(evictions, check.HasLen, 0)
+}
+
+func (s *S) TestRebalancePoolUnits(c *check.C) {
+	a1 := s.createSkewedApp(c, "my-app1")
+	a2 := s.createSkewedApp(c, "my-app2")
+	var buf bytes.Buffer
+	evictions, err := RebalancePoolUnits(context.TODO(), s.Pool, provision.RebalanceUnitsOptions{MaxEvictions: 1, Writer: &buf})
+	c.Assert(err, check.IsNil)
+	c.Assert(evictions, check.HasLen, 1)
+	c.Assert(evictions[0].App, check.Equals, "my-app1")
+	c.Assert(buf.String(), check.Matches, `(?s).*Maximum number of evictions reached.*`)
+	c.Assert(s.provisioner.GetUnits(a1)[2].IP, check.Equals, "node2")
+	c.Assert(s.provisioner.GetUnits(a2)[2].IP, check.Equals, "node1")
+	evictions, err = RebalancePoolUnits(context.TODO(), s.Pool, provision.RebalanceUnitsOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(evictions, check.HasLen, 1)
+	c.Assert(evictions[0].App, check.Equals, "my-app2")
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultReconcileInterval = 10 * time.Minute
+
+type appReconciler struct {
+	interval time.Duration
+	repair   bool
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// StartReconciler starts a background loop comparing the state of every
+// app with the objects running in its provisioner and routers, repairing
+// the drifts found. It's only started when reconciler:enabled is set.
+func StartReconciler() error {
+	enabled, _ := config.GetBool("reconciler:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("reconciler:interval")
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+	dryRun, _ := config.GetBool("reconciler:dry-run")
+	r := &appReconciler{
+		interval: interval,
+		repair:   !dryRun,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go r.spin()
+	shutdown.Register(r)
+	return nil
+}
+
+func (r *appReconciler) spin() {
+	defer close(r.doneCh)
+	for {
+		err := runReconcile(context.Background(), r.repair)
+		if err != nil {
+			log.Errorf("[reconciler] %v", err)
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+func (r *appReconciler) Shutdown(ctx context.Context) error {
+	close(r.stopCh)
+	select {
+	case <-r.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func runReconcile(ctx context.Context, repair bool) error {
+	apps, err := List(ctx, nil)
+	if err != nil {
+		return err
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	multi := tsuruErrors.NewMultiError()
+	for _, a := range apps {
+		err = reconcileAppDrift(ctx, a, repair)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to reconcile app %q", a.Name))
+		}
+	}
+	return multi.ToError()
+}
+
+// reconcileAppDrift holds the app lock while looking for drifts, so apps
+// being deployed or updated are skipped instead of reported. Runs without
+// drifts are aborted and leave no event behind.
+func reconcileAppDrift(ctx context.Context, a *appTypes.App, repair bool) (err error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: "reconcile",
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, a.Name)),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	var drifts []provision.Drift
+	defer func() {
+		if err == nil && len(drifts) == 0 {
+			evt.Abort(ctx)
+			return
+		}
+		evt.DoneCustomData(ctx, err, drifts)
+	}()
+	drifts, err = Drift(ctx, a)
+	if err == ErrDriftProvisioner {
+		return nil
+	}
+	if err != nil || len(drifts) == 0 || !repair {
+		return err
+	}
+	drifts, err = RepairDrift(ctx, a, evt)
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router/rebuild"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrRoutingRuleNotFound = errors.New("routing rule not found")
+
+// SetRoutingRule targets requests matching the rule to its version,
+// replacing any rule for the same version. Routes are rebuilt right away and
+// the rule is discarded if any router of the app refuses it.
+func SetRoutingRule(ctx context.Context, app *appTypes.App, rule appTypes.VersionRoutingRule, w io.Writer) error {
+	if (rule.Header == "") == (rule.Cookie == "") {
+		return &tsuruErrors.ValidationError{Message: "routing rule must match either a header or a cookie"}
+	}
+	if rule.Version <= 0 {
+		return &tsuruErrors.ValidationError{Message: "routing rule must target a version"}
+	}
+	rules := slices.DeleteFunc(slices.Clone(app.RoutingRules), func(r appTypes.VersionRoutingRule) bool {
+		return r.Version == rule.Version
+	})
+	return updateRoutingRules(ctx, app, append(rules, rule), w)
+}
+
+// RemoveRoutingRule stops targeting requests to the version.
+func RemoveRoutingRule(ctx context.Context, app *appTypes.App, version int, w io.Writer) error {
+	rules := slices.DeleteFunc(slices.Clone(app.RoutingRules), func(r appTypes.VersionRoutingRule) bool {
+		return r.Version == version
+	})
+	if len(rules) == len(app.RoutingRules) {
+		return ErrRoutingRuleNotFound
+	}
+	return updateRoutingRules(ctx, app, rules, w)
+}
+
+func updateRoutingRules(ctx context.Context, app *appTypes.App, rules []appTypes.VersionRoutingRule, w io.Writer) error {
+	previous := app.RoutingRules
+	err := saveRoutingRules(ctx, app, rules)
+	if err != nil {
+		return err
+	}
+	err = rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{App: app, Writer: w})
+	if err == nil {
+		return nil
+	}
+	if rollbackErr := saveRoutingRules(ctx, app, previous); rollbackErr != nil {
+		return tsuruErrors.NewMultiError(err, rollbackErr)
+	}
+	return err
+}
+
+func saveRoutingRules(ctx context.Context, app *appTypes.App, rules []appTypes.VersionRoutingRule) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"routingrules": rules},
+	})
+	if err != nil {
+		return err
+	}
+	app.RoutingRules = rules
+	return nil
+}
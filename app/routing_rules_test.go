@@ -0,0 +1,64 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetRoutingRule(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetRoutingRule(context.TODO(), &a, appTypes.VersionRoutingRule{Version: 2, Header: "X-Canary", Value: "1"}, io.Discard)
+	c.Assert(err, check.IsNil)
+	err = SetRoutingRule(context.TODO(), &a, appTypes.VersionRoutingRule{Version: 2, Cookie: "beta"}, io.Discard)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RoutingRules, check.DeepEquals, []appTypes.VersionRoutingRule{{Version: 2, Cookie: "beta"}})
+	err = RemoveRoutingRule(context.TODO(), &a, 2, io.Discard)
+	c.Assert(err, check.IsNil)
+	err = RemoveRoutingRule(context.TODO(), &a, 2, io.Discard)
+	c.Assert(err, check.Equals, ErrRoutingRuleNotFound)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RoutingRules, check.HasLen, 0)
+}
+
+func (s *S) TestSetRoutingRuleInvalid(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		rule     appTypes.VersionRoutingRule
+		expected string
+	}{
+		{appTypes.VersionRoutingRule{Version: 1}, "routing rule must match either a header or a cookie"},
+		{appTypes.VersionRoutingRule{Version: 1, Header: "X-Canary", Cookie: "beta"}, "routing rule must match either a header or a cookie"},
+		{appTypes.VersionRoutingRule{Header: "X-Canary"}, "routing rule must target a version"},
+	}
+	for _, tt := range tests {
+		err = SetRoutingRule(context.TODO(), &a, tt.rule, io.Discard)
+		c.Assert(err, check.ErrorMatches, tt.expected)
+	}
+	c.Assert(a.RoutingRules, check.HasLen, 0)
+}
+
+func (s *S) TestSetRoutingRuleRouterFailureRestoresRules(c *check.C) {
+	a := appTypes.App{Name: "myapp-with-error", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetRoutingRule(context.TODO(), &a, appTypes.VersionRoutingRule{Version: 1, Header: "X-Canary"}, io.Discard)
+	c.Assert(err, check.NotNil)
+	c.Assert(a.RoutingRules, check.HasLen, 0)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RoutingRules, check.HasLen, 0)
+}
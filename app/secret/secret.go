@@ -0,0 +1,219 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package secret seals the secrets of apps with envelope encryption: each
+// value is encrypted with a random data key, which is encrypted by a key
+// manager. The local key manager uses a master key from the config, other
+// ones, like cloud KMS services, may be registered with Register.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const localKeyManager = "local"
+
+var ErrMasterKeyNotConfigured = errors.New("secrets:master-key is not configured, app secrets are disabled")
+
+// KeyManager encrypts and decrypts the data keys of sealed values.
+type KeyManager interface {
+	// Encrypt returns the data key encrypted and the id of the key used.
+	Encrypt(dataKey []byte) ([]byte, string, error)
+	Decrypt(encrypted []byte, keyID string) ([]byte, error)
+}
+
+type keyManagerFactory func() (KeyManager, error)
+
+var keyManagers = map[string]keyManagerFactory{
+	localKeyManager: newLocalKeyManager,
+}
+
+// Register registers a new key manager, used when set in
+// secrets:key-manager.
+func Register(name string, factory func() (KeyManager, error)) {
+	keyManagers[name] = factory
+}
+
+func getKeyManager(name string) (KeyManager, error) {
+	factory, ok := keyManagers[name]
+	if !ok {
+		return nil, errors.Errorf("unknown secrets key manager %q", name)
+	}
+	return factory()
+}
+
+func configuredKeyManager() string {
+	name, _ := config.GetString("secrets:key-manager")
+	if name == "" {
+		return localKeyManager
+	}
+	return name
+}
+
+// Seal encrypts the value with a new data key.
+func Seal(value []byte) (appTypes.SealedValue, error) {
+	managerName := configuredKeyManager()
+	manager, err := getKeyManager(managerName)
+	if err != nil {
+		return appTypes.SealedValue{}, err
+	}
+	dataKey := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, dataKey); err != nil {
+		return appTypes.SealedValue{}, errors.WithStack(err)
+	}
+	nonce, ciphertext, err := encrypt(dataKey, value)
+	if err != nil {
+		return appTypes.SealedValue{}, err
+	}
+	encryptedKey, keyID, err := manager.Encrypt(dataKey)
+	if err != nil {
+		return appTypes.SealedValue{}, err
+	}
+	return appTypes.SealedValue{
+		KeyManager: managerName,
+		KeyID:      keyID,
+		DataKey:    encryptedKey,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// Open decrypts the sealed value, using the key manager that sealed it.
+func Open(sealed appTypes.SealedValue) ([]byte, error) {
+	manager, err := getKeyManager(sealed.KeyManager)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := manager.Decrypt(sealed.DataKey, sealed.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(dataKey, sealed.Nonce, sealed.Ciphertext)
+}
+
+// OpenAll decrypts the values of the secrets, keyed by their names.
+func OpenAll(secrets []appTypes.AppSecret) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(secrets))
+	for _, s := range secrets {
+		value, err := Open(s.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open secret %q", s.Name)
+		}
+		values[s.Name] = value
+	}
+	return values, nil
+}
+
+func encrypt(key, plaintext []byte) ([]byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func decrypt(key, nonce, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decrypt secret")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return gcm, nil
+}
+
+// localManager encrypts data keys with the master key set in
+// secrets:master-key, a base64 encoded 32 bytes key. Keys previously used
+// may be kept in secrets:previous-master-keys, so values sealed with them
+// can still be opened after the master key is rotated.
+type localManager struct {
+	keys    map[string][]byte
+	current string
+}
+
+func newLocalKeyManager() (KeyManager, error) {
+	rawKey, err := config.GetString("secrets:master-key")
+	if err != nil || rawKey == "" {
+		return nil, ErrMasterKeyNotConfigured
+	}
+	m := &localManager{keys: map[string][]byte{}}
+	m.current, err = m.addKey(rawKey)
+	if err != nil {
+		return nil, err
+	}
+	previous, _ := config.GetList("secrets:previous-master-keys")
+	for _, rawKey = range previous {
+		if _, err = m.addKey(rawKey); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *localManager) addKey(rawKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(rawKey)
+	if err != nil || len(key) != 32 {
+		return "", errors.New("secrets master keys must be base64 encoded 32 bytes keys")
+	}
+	keyID := keyFingerprint(key)
+	m.keys[keyID] = key
+	return keyID, nil
+}
+
+func (m *localManager) Encrypt(dataKey []byte) ([]byte, string, error) {
+	nonce, ciphertext, err := encrypt(m.keys[m.current], dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return append(nonce, ciphertext...), m.current, nil
+}
+
+func (m *localManager) Decrypt(encrypted []byte, keyID string) ([]byte, error) {
+	key, ok := m.keys[keyID]
+	if !ok {
+		return nil, errors.Errorf("master key %q not found, it must be set in secrets:master-key or secrets:previous-master-keys", keyID)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted data key")
+	}
+	return decrypt(key, encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():])
+}
+
+// keyFingerprint identifies a master key without revealing it.
+func keyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
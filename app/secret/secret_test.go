@@ -0,0 +1,104 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+var (
+	masterKey      = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+	otherMasterKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("o"), 32))
+)
+
+func (s *S) SetUpTest(c *check.C) {
+	config.Set("secrets:master-key", masterKey)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("secrets")
+}
+
+func (s *S) TestSealAndOpen(c *check.C) {
+	sealed, err := Seal([]byte("s3cr3t"))
+	c.Assert(err, check.IsNil)
+	c.Assert(sealed.KeyManager, check.Equals, "local")
+	c.Assert(sealed.KeyID, check.Not(check.Equals), "")
+	c.Assert(bytes.Contains(sealed.Ciphertext, []byte("s3cr3t")), check.Equals, false)
+	value, err := Open(sealed)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(value), check.Equals, "s3cr3t")
+	other, err := Seal([]byte("s3cr3t"))
+	c.Assert(err, check.IsNil)
+	c.Assert(other.Ciphertext, check.Not(check.DeepEquals), sealed.Ciphertext)
+}
+
+func (s *S) TestOpenAfterMasterKeyRotation(c *check.C) {
+	sealed, err := Seal([]byte("s3cr3t"))
+	c.Assert(err, check.IsNil)
+	config.Set("secrets:master-key", otherMasterKey)
+	_, err = Open(sealed)
+	c.Assert(err, check.ErrorMatches, `master key ".*" not found, it must be set in secrets:master-key or secrets:previous-master-keys`)
+	config.Set("secrets:previous-master-keys", []interface{}{masterKey})
+	value, err := Open(sealed)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(value), check.Equals, "s3cr3t")
+	resealed, err := Seal(value)
+	c.Assert(err, check.IsNil)
+	c.Assert(resealed.KeyID, check.Not(check.Equals), sealed.KeyID)
+}
+
+func (s *S) TestOpenTampered(c *check.C) {
+	sealed, err := Seal([]byte("s3cr3t"))
+	c.Assert(err, check.IsNil)
+	sealed.Ciphertext[0] ^= 1
+	_, err = Open(sealed)
+	c.Assert(err, check.ErrorMatches, "unable to decrypt secret: .*")
+}
+
+func (s *S) TestSealWithoutMasterKey(c *check.C) {
+	config.Unset("secrets")
+	_, err := Seal([]byte("s3cr3t"))
+	c.Assert(err, check.Equals, ErrMasterKeyNotConfigured)
+	config.Set("secrets:master-key", "c2hvcnQ=")
+	_, err = Seal([]byte("s3cr3t"))
+	c.Assert(err, check.ErrorMatches, "secrets master keys must be base64 encoded 32 bytes keys")
+}
+
+type fakeKeyManager struct{}
+
+func (fakeKeyManager) Encrypt(dataKey []byte) ([]byte, string, error) {
+	return append([]byte(nil), dataKey...), "fake-key", nil
+}
+
+func (fakeKeyManager) Decrypt(encrypted []byte, keyID string) ([]byte, error) {
+	return encrypted, nil
+}
+
+func (s *S) TestSealWithRegisteredKeyManager(c *check.C) {
+	Register("fake", func() (KeyManager, error) { return fakeKeyManager{}, nil })
+	defer delete(keyManagers, "fake")
+	config.Set("secrets:key-manager", "fake")
+	sealed, err := Seal([]byte("s3cr3t"))
+	c.Assert(err, check.IsNil)
+	c.Assert(sealed.KeyManager, check.Equals, "fake")
+	c.Assert(sealed.KeyID, check.Equals, "fake-key")
+	config.Unset("secrets:key-manager")
+	values, err := OpenAll([]appTypes.AppSecret{{Name: "TOKEN", Value: sealed}})
+	c.Assert(err, check.IsNil)
+	c.Assert(values, check.DeepEquals, map[string][]byte{"TOKEN": []byte("s3cr3t")})
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/secret"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrAppSecretNotFound = errors.New("secret not found")
+
+// SetSecretsArgs holds the secrets to be set in an app, keyed by name, and
+// who is setting them.
+type SetSecretsArgs struct {
+	Secrets       map[string]string
+	Owner         string
+	Writer        io.Writer
+	ShouldRestart bool
+}
+
+// SetSecrets seals the secrets and stores them in the app, replacing the
+// ones with the same names. Units get them as environment variables on the
+// next deploy or restart, which happens right away when ShouldRestart is set.
+func SetSecrets(ctx context.Context, app *appTypes.App, args SetSecretsArgs) error {
+	if len(args.Secrets) == 0 {
+		return &tsuruErrors.ValidationError{Message: "no secrets to set"}
+	}
+	names := make([]string, 0, len(args.Secrets))
+	for name := range args.Secrets {
+		if err := validateEnv(name); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid secret name: '%s'", name)}
+		}
+		if _, isEnv := app.Env[name]; isEnv {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("secret %q conflicts with an environment variable of the app", name)}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	secrets := slices.DeleteFunc(slices.Clone(app.Secrets), func(s appTypes.AppSecret) bool {
+		_, ok := args.Secrets[s.Name]
+		return ok
+	})
+	now := time.Now().UTC()
+	for _, name := range names {
+		sealed, err := secret.Seal([]byte(args.Secrets[name]))
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, appTypes.AppSecret{
+			Name:      name,
+			Value:     sealed,
+			UpdatedAt: now,
+			UpdatedBy: args.Owner,
+		})
+	}
+	if args.Writer != nil {
+		fmt.Fprintf(args.Writer, "---- Setting %d secrets ----\n", len(names))
+	}
+	return updateSecrets(ctx, app, secrets, args.Writer, args.ShouldRestart)
+}
+
+// UnsetSecrets removes the secrets from the app.
+func UnsetSecrets(ctx context.Context, app *appTypes.App, names []string, w io.Writer, shouldRestart bool) error {
+	for _, name := range names {
+		if !slices.ContainsFunc(app.Secrets, func(s appTypes.AppSecret) bool { return s.Name == name }) {
+			return errors.Wrapf(ErrAppSecretNotFound, "%s", name)
+		}
+	}
+	secrets := slices.DeleteFunc(slices.Clone(app.Secrets), func(s appTypes.AppSecret) bool {
+		return slices.Contains(names, s.Name)
+	})
+	if w != nil {
+		fmt.Fprintf(w, "---- Unsetting %d secrets ----\n", len(names))
+	}
+	return updateSecrets(ctx, app, secrets, w, shouldRestart)
+}
+
+func hasSecret(app *appTypes.App, name string) bool {
+	return slices.ContainsFunc(app.Secrets, func(s appTypes.AppSecret) bool {
+		return s.Name == name
+	})
+}
+
+// SecretValue returns the value of the secret of the app.
+func SecretValue(app *appTypes.App, name string) (string, error) {
+	for _, s := range app.Secrets {
+		if s.Name != name {
+			continue
+		}
+		value, err := secret.Open(s.Value)
+		if err != nil {
+			return "", err
+		}
+		return string(value), nil
+	}
+	return "", ErrAppSecretNotFound
+}
+
+func updateSecrets(ctx context.Context, app *appTypes.App, secrets []appTypes.AppSecret, w io.Writer, shouldRestart bool) error {
+	if len(secrets) == 0 {
+		secrets = nil
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"secrets": secrets},
+	})
+	if err != nil {
+		return err
+	}
+	app.Secrets = secrets
+	if shouldRestart {
+		return restartIfUnits(ctx, app, w)
+	}
+	return nil
+}
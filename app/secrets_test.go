@@ -0,0 +1,95 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetSecrets(c *check.C) {
+	config.Set("secrets:master-key", "a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2s=")
+	defer config.Unset("secrets")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetSecrets(context.TODO(), &a, SetSecretsArgs{
+		Secrets: map[string]string{"DB_PASSWORD": "abc", "API_TOKEN": "xyz"},
+		Owner:   s.user.Email,
+		Writer:  io.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	err = SetSecrets(context.TODO(), &a, SetSecretsArgs{
+		Secrets: map[string]string{"DB_PASSWORD": "def"},
+		Owner:   s.user.Email,
+		Writer:  io.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Secrets, check.HasLen, 2)
+	c.Assert(dbApp.Secrets[0].Name, check.Equals, "API_TOKEN")
+	c.Assert(dbApp.Secrets[1].Name, check.Equals, "DB_PASSWORD")
+	c.Assert(dbApp.Secrets[1].UpdatedBy, check.Equals, s.user.Email)
+	c.Assert(string(dbApp.Secrets[1].Value.Ciphertext), check.Not(check.Equals), "def")
+	value, err := SecretValue(dbApp, "DB_PASSWORD")
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "def")
+	err = UnsetSecrets(context.TODO(), &a, []string{"DB_PASSWORD"}, io.Discard, false)
+	c.Assert(err, check.IsNil)
+	err = UnsetSecrets(context.TODO(), &a, []string{"DB_PASSWORD"}, io.Discard, false)
+	c.Assert(errors.Cause(err), check.Equals, ErrAppSecretNotFound)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Secrets, check.HasLen, 1)
+	_, err = SecretValue(dbApp, "DB_PASSWORD")
+	c.Assert(err, check.Equals, ErrAppSecretNotFound)
+}
+
+func (s *S) TestSetSecretsInvalid(c *check.C) {
+	config.Set("secrets:master-key", "a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2s=")
+	defer config.Unset("secrets")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "DATABASE_HOST", Value: "localhost"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = SetSecrets(context.TODO(), &a, SetSecretsArgs{Secrets: map[string]string{"1TOKEN": "abc"}})
+	c.Assert(err, check.ErrorMatches, `Invalid secret name: '1TOKEN'`)
+	err = SetSecrets(context.TODO(), &a, SetSecretsArgs{Secrets: map[string]string{"DATABASE_HOST": "abc"}})
+	c.Assert(err, check.ErrorMatches, `secret "DATABASE_HOST" conflicts with an environment variable of the app`)
+	config.Unset("secrets")
+	err = SetSecrets(context.TODO(), &a, SetSecretsArgs{Secrets: map[string]string{"TOKEN": "abc"}})
+	c.Assert(err, check.ErrorMatches, `secrets:master-key is not configured, app secrets are disabled`)
+	c.Assert(a.Secrets, check.HasLen, 0)
+}
+
+func (s *S) TestSetEnvsConflictingWithSecret(c *check.C) {
+	config.Set("secrets:master-key", "a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2s=")
+	defer config.Unset("secrets")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetSecrets(context.TODO(), &a, SetSecretsArgs{Secrets: map[string]string{"DB_PASSWORD": "abc"}})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "DB_PASSWORD", Value: "plain"}},
+	})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `environment variable "DB_PASSWORD" conflicts with a secret of the app`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	_, isEnv := dbApp.Env["DB_PASSWORD"]
+	c.Assert(isEnv, check.Equals, false)
+}
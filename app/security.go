@@ -5,11 +5,12 @@
 package app
 
 import (
+	"github.com/tsuru/tsuru/event"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 )
 
-var SuppressedEnv = "*** (private variable)"
+var SuppressedEnv = event.SuppressedEnv
 
 func SuppressSensitiveEnvs(a *appTypes.App) {
 	newEnv := map[string]bindTypes.EnvVar{}
@@ -26,4 +27,16 @@ func SuppressSensitiveEnvs(a *appTypes.App) {
 			a.ServiceEnvs[i].Value = SuppressedEnv
 		}
 	}
+
+	for i, versionEnv := range a.VersionEnvs {
+		if !versionEnv.Public {
+			a.VersionEnvs[i].Value = SuppressedEnv
+		}
+	}
+
+	for i, teamEnv := range a.TeamEnvs {
+		if !teamEnv.Public {
+			a.TeamEnvs[i].Value = SuppressedEnv
+		}
+	}
 }
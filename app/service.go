@@ -10,6 +10,7 @@ import (
 	appTypes "github.com/tsuru/tsuru/types/app"
 	imgTypes "github.com/tsuru/tsuru/types/app/image"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
+	provTypes "github.com/tsuru/tsuru/types/provision"
 	routerTypes "github.com/tsuru/tsuru/types/router"
 )
 
@@ -57,6 +58,10 @@ func (a *appService) GetHealthcheckData(ctx context.Context, app *appTypes.App)
 	return GetHealthcheckData(ctx, app)
 }
 
+func (a *appService) AddUnitStatusHistory(ctx context.Context, h provTypes.UnitStatusHistory) error {
+	return AddUnitStatusHistory(ctx, h)
+}
+
 func AppService() (appTypes.AppService, error) {
 	return &appService{}, nil
 }
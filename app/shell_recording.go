@@ -0,0 +1,135 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/log"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrShellRecordingNotFound = errors.New("shell recording not found")
+	ErrShellRecordingFailed   = errors.New("unable to record shell session")
+)
+
+// ShellRecording records the input and output of a shell session in the
+// asciicast v2 format, so it can be replayed with asciinema. It's stored
+// under the id of the event of the session.
+type ShellRecording struct {
+	mu     sync.Mutex
+	stream io.WriteCloser
+	start  time.Time
+	err    error
+}
+
+type shellRecordingHeader struct {
+	Version   int   `json:"version"`
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+// NewShellRecording starts the recording of the shell session of the event.
+func NewShellRecording(ctx context.Context, eventID primitive.ObjectID, appName string, width, height int) (*ShellRecording, error) {
+	bucket, err := storagev2.ShellRecordingsBucket()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := bucket.OpenUploadStream(eventID.Hex(),
+		options.GridFSUpload().SetMetadata(mongoBSON.M{"event": eventID, "app": appName}))
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now().UTC()
+	header, err := json.Marshal(shellRecordingHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+	})
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	_, err = stream.Write(append(header, '\n'))
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	return &ShellRecording{stream: stream, start: start}, nil
+}
+
+// Input records data sent by the user to the session. Once recording
+// fails, every call returns an error and the session must be closed, as it
+// can't go on unrecorded.
+func (r *ShellRecording) Input(data []byte) error {
+	return r.record("i", data)
+}
+
+// Output records data sent by the session to the user.
+func (r *ShellRecording) Output(data []byte) error {
+	return r.record("o", data)
+}
+
+// Err returns the error that interrupted the recording, if any.
+func (r *ShellRecording) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *ShellRecording) record(kind string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	line, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), kind, string(data)})
+	if err == nil {
+		_, err = r.stream.Write(append(line, '\n'))
+	}
+	if err != nil {
+		log.Errorf("unable to record shell session: %v", err)
+		r.err = errors.Wrap(ErrShellRecordingFailed, err.Error())
+	}
+	return r.err
+}
+
+// Close finishes the recording, storing what was recorded so far.
+func (r *ShellRecording) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stream.Close()
+}
+
+// GetShellRecording returns the recording of the shell session of the event.
+func GetShellRecording(ctx context.Context, eventID primitive.ObjectID) (io.ReadCloser, error) {
+	bucket, err := storagev2.ShellRecordingsBucket()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := bucket.OpenDownloadStreamByName(eventID.Hex())
+	if err == gridfs.ErrFileNotFound {
+		return nil, ErrShellRecordingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
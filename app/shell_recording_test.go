@@ -0,0 +1,70 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestShellRecording(c *check.C) {
+	eventID := primitive.NewObjectID()
+	recording, err := NewShellRecording(context.TODO(), eventID, "myapp", 140, 38)
+	c.Assert(err, check.IsNil)
+	c.Assert(recording.Input([]byte("ls\n")), check.IsNil)
+	c.Assert(recording.Output([]byte("Procfile\r\n")), check.IsNil)
+	c.Assert(recording.Output(nil), check.IsNil)
+	err = recording.Close()
+	c.Assert(err, check.IsNil)
+	stream, err := GetShellRecording(context.TODO(), eventID)
+	c.Assert(err, check.IsNil)
+	data, err := io.ReadAll(stream)
+	c.Assert(err, check.IsNil)
+	c.Assert(stream.Close(), check.IsNil)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	c.Assert(lines, check.HasLen, 3)
+	var header map[string]interface{}
+	err = json.Unmarshal([]byte(lines[0]), &header)
+	c.Assert(err, check.IsNil)
+	c.Assert(header["version"], check.Equals, float64(2))
+	c.Assert(header["width"], check.Equals, float64(140))
+	c.Assert(header["height"], check.Equals, float64(38))
+	var entry []interface{}
+	err = json.Unmarshal([]byte(lines[1]), &entry)
+	c.Assert(err, check.IsNil)
+	c.Assert(entry[1:], check.DeepEquals, []interface{}{"i", "ls\n"})
+	err = json.Unmarshal([]byte(lines[2]), &entry)
+	c.Assert(err, check.IsNil)
+	c.Assert(entry[1:], check.DeepEquals, []interface{}{"o", "Procfile\r\n"})
+}
+
+type failingWriteCloser struct{}
+
+func (failingWriteCloser) Write(p []byte) (int, error) {
+	return 0, errors.New("connection lost")
+}
+
+func (failingWriteCloser) Close() error {
+	return nil
+}
+
+func (s *S) TestShellRecordingWriteError(c *check.C) {
+	recording := &ShellRecording{stream: failingWriteCloser{}}
+	err := recording.Input([]byte("ls\n"))
+	c.Assert(errors.Cause(err), check.Equals, ErrShellRecordingFailed)
+	c.Assert(errors.Cause(recording.Output(nil)), check.Equals, ErrShellRecordingFailed)
+	c.Assert(errors.Cause(recording.Err()), check.Equals, ErrShellRecordingFailed)
+}
+
+func (s *S) TestGetShellRecordingNotFound(c *check.C) {
+	_, err := GetShellRecording(context.TODO(), primitive.NewObjectID())
+	c.Assert(err, check.Equals, ErrShellRecordingNotFound)
+}
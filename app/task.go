@@ -0,0 +1,81 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	DefaultTaskTimeout = time.Hour
+	MaxTaskTimeout     = 24 * time.Hour
+)
+
+var (
+	ErrTaskCommandRequired = errors.New("you must provide the command to run")
+	ErrInvalidTaskTimeout  = errors.Errorf("timeout must be positive and at most %s", MaxTaskTimeout)
+	ErrInvalidTaskLimits   = errors.New("cpu and memory limits of the task must not be negative")
+)
+
+// TaskArgs is a one-off command run in a unit of its own, created from the
+// image, envs and volumes of the latest deployed version of the app.
+type TaskArgs struct {
+	Command  string
+	Timeout  time.Duration
+	CPUMilli int
+	Memory   int64
+}
+
+func (args *TaskArgs) validate() error {
+	if args.Command == "" {
+		return ErrTaskCommandRequired
+	}
+	if args.Timeout == 0 {
+		args.Timeout = DefaultTaskTimeout
+	}
+	if args.Timeout < 0 || args.Timeout > MaxTaskTimeout {
+		return ErrInvalidTaskTimeout
+	}
+	if args.CPUMilli < 0 || args.Memory < 0 {
+		return ErrInvalidTaskLimits
+	}
+	return nil
+}
+
+// RunTask runs the task to completion, writing its output to w and to the
+// logs of the app. Unlike Run, the task doesn't depend on the units of the
+// app and is killed once the timeout is reached.
+func RunTask(ctx context.Context, app *appTypes.App, args TaskArgs, w io.Writer) error {
+	if err := args.validate(); err != nil {
+		return err
+	}
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return err
+	}
+	taskProv, ok := prov.(provision.TaskProvisioner)
+	if !ok {
+		return provision.ProvisionerNotSupported{Prov: prov, Action: "running tasks"}
+	}
+	logWriter := LogWriter{AppName: app.Name, Source: "app-task"}
+	logWriter.Async()
+	defer logWriter.Close()
+	logWriter.Write([]byte(fmt.Sprintf("running task '%s'", args.Command)))
+	return taskProv.RunTask(ctx, provision.TaskOptions{
+		App:      app,
+		Cmds:     cmdsForExec(args.Command),
+		Output:   io.MultiWriter(w, &logWriter),
+		Timeout:  args.Timeout,
+		CPUMilli: args.CPUMilli,
+		Memory:   args.Memory,
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRunTask(c *check.C) {
+	s.provisioner.PrepareOutput([]byte("migrations applied"))
+	app := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = RunTask(context.TODO(), &app, TaskArgs{Command: "./manage.py migrate", CPUMilli: 500}, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "migrations applied")
+	tasks := s.provisioner.Tasks(&app)
+	c.Assert(tasks, check.HasLen, 1)
+	c.Assert(tasks[0].Cmds, check.DeepEquals, cmdsForExec("./manage.py migrate"))
+	c.Assert(tasks[0].Timeout, check.Equals, DefaultTaskTimeout)
+	c.Assert(tasks[0].CPUMilli, check.Equals, 500)
+	c.Assert(tasks[0].Memory, check.Equals, int64(0))
+}
+
+func (s *S) TestRunTaskInvalidArgs(c *check.C) {
+	app := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = RunTask(context.TODO(), &app, TaskArgs{}, &buf)
+	c.Assert(err, check.Equals, ErrTaskCommandRequired)
+	err = RunTask(context.TODO(), &app, TaskArgs{Command: "ls", Timeout: 48 * time.Hour}, &buf)
+	c.Assert(err, check.Equals, ErrInvalidTaskTimeout)
+	err = RunTask(context.TODO(), &app, TaskArgs{Command: "ls", Memory: -1}, &buf)
+	c.Assert(err, check.Equals, ErrInvalidTaskLimits)
+	c.Assert(s.provisioner.Tasks(&app), check.HasLen, 0)
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// SetTeamEnvs sets environment variables injected in every app owned by the
+// team. Variables set directly in an app take precedence over the team ones.
+func SetTeamEnvs(ctx context.Context, teamName string, setEnvs bindTypes.SetEnvArgs) error {
+	if len(setEnvs.Envs) == 0 {
+		return nil
+	}
+	for _, env := range setEnvs.Envs {
+		err := validateEnv(env.Name)
+		if err != nil {
+			return err
+		}
+	}
+	if setEnvs.Writer != nil {
+		fmt.Fprintf(setEnvs.Writer, "---- Setting %d team environment variables ----\n", len(setEnvs.Envs))
+	}
+	err := servicemanager.Team.SetEnvs(ctx, teamName, setEnvs.Envs)
+	if err != nil {
+		return err
+	}
+	return propagateTeamEnvs(ctx, teamName, setEnvs.ShouldRestart, setEnvs.Writer)
+}
+
+// UnsetTeamEnvs removes environment variables from the team, updating every
+// app owned by it.
+func UnsetTeamEnvs(ctx context.Context, teamName string, unsetEnvs bindTypes.UnsetEnvArgs) error {
+	if len(unsetEnvs.VariableNames) == 0 {
+		return nil
+	}
+	if unsetEnvs.Writer != nil {
+		fmt.Fprintf(unsetEnvs.Writer, "---- Unsetting %d team environment variables ----\n", len(unsetEnvs.VariableNames))
+	}
+	err := servicemanager.Team.UnsetEnvs(ctx, teamName, unsetEnvs.VariableNames)
+	if err != nil {
+		return err
+	}
+	return propagateTeamEnvs(ctx, teamName, unsetEnvs.ShouldRestart, unsetEnvs.Writer)
+}
+
+// propagateTeamEnvs copies the team env set to the apps owned by the team,
+// restarting the ones whose resulting environment has changed.
+func propagateTeamEnvs(ctx context.Context, teamName string, shouldRestart bool, w io.Writer) error {
+	teamEnvs, err := teamEnvsFor(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	apps, err := List(ctx, &Filter{TeamOwner: teamName})
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	for _, a := range apps {
+		oldEnvs := provision.EnvsForApp(a)
+		a.TeamEnvs = teamEnvs
+		_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": a.Name}, mongoBSON.M{"$set": mongoBSON.M{"teamenvs": a.TeamEnvs}})
+		if err != nil {
+			return err
+		}
+		if !shouldRestart || reflect.DeepEqual(oldEnvs, provision.EnvsForApp(a)) {
+			continue
+		}
+		if w != nil {
+			fmt.Fprintf(w, "---- Restarting app %q ----\n", a.Name)
+		}
+		err = restartIfUnits(ctx, a, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// teamEnvsFor returns the env set of the given team.
+func teamEnvsFor(ctx context.Context, teamName string) ([]bindTypes.EnvVar, error) {
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if team == nil {
+		return nil, nil
+	}
+	return team.Env, nil
+}
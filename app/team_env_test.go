@@ -0,0 +1,143 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) mockTeamEnvs(envs *[]bindTypes.EnvVar) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name, Env: *envs}, nil
+	}
+	s.mockService.Team.OnSetEnvs = func(name string, newEnvs []bindTypes.EnvVar) error {
+		*envs = append(*envs, newEnvs...)
+		return nil
+	}
+	s.mockService.Team.OnUnsetEnvs = func(name string, names []string) error {
+		var remaining []bindTypes.EnvVar
+		for _, env := range *envs {
+			if env.Name != names[0] {
+				remaining = append(remaining, env)
+			}
+		}
+		*envs = remaining
+		return nil
+	}
+}
+
+func (s *S) TestCreateAppWithTeamEnvs(c *check.C) {
+	envs := []bindTypes.EnvVar{{Name: "LOG_LEVEL", Value: "info", Public: true}}
+	s.mockTeamEnvs(&envs)
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamEnvs, check.DeepEquals, envs)
+	c.Assert(provision.EnvsForApp(dbApp)["LOG_LEVEL"], check.DeepEquals, bindTypes.EnvVar{
+		Name:      "LOG_LEVEL",
+		Value:     "info",
+		Public:    true,
+		ManagedBy: "team/" + s.team.Name,
+	})
+}
+
+func (s *S) TestSetTeamEnvs(c *check.C) {
+	var envs []bindTypes.EnvVar
+	s.mockTeamEnvs(&envs)
+	ctx := context.TODO()
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(ctx, &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = AddUnits(ctx, &a, 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	other := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name, Env: map[string]bindTypes.EnvVar{
+		"LOG_LEVEL": {Name: "LOG_LEVEL", Value: "debug", Public: true},
+	}}
+	err = CreateApp(ctx, &other, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &other)
+	err = AddUnits(ctx, &other, 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = SetTeamEnvs(ctx, s.team.Name, bindTypes.SetEnvArgs{
+		Envs:          []bindTypes.EnvVar{{Name: "LOG_LEVEL", Value: "info", Public: true}},
+		ShouldRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(ctx, a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamEnvs, check.DeepEquals, envs)
+	c.Assert(provision.EnvsForApp(dbApp)["LOG_LEVEL"].Value, check.Equals, "info")
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 1)
+	dbOther, err := GetByName(ctx, other.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbOther.TeamEnvs, check.DeepEquals, envs)
+	c.Assert(provision.EnvsForApp(dbOther)["LOG_LEVEL"].Value, check.Equals, "debug")
+	c.Assert(s.provisioner.Restarts(&other, ""), check.Equals, 0)
+}
+
+func (s *S) TestSetTeamEnvsNoRestart(c *check.C) {
+	var envs []bindTypes.EnvVar
+	s.mockTeamEnvs(&envs)
+	ctx := context.TODO()
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(ctx, &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = AddUnits(ctx, &a, 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = SetTeamEnvs(ctx, s.team.Name, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "LOG_LEVEL", Value: "info", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(ctx, a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamEnvs, check.DeepEquals, envs)
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+}
+
+func (s *S) TestSetTeamEnvsValidation(c *check.C) {
+	var envs []bindTypes.EnvVar
+	s.mockTeamEnvs(&envs)
+	err := SetTeamEnvs(context.TODO(), s.team.Name, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "INVALID-NAME", Value: "x"}},
+	})
+	c.Assert(err, check.ErrorMatches, "Invalid environment variable name: 'INVALID-NAME'")
+	c.Assert(envs, check.HasLen, 0)
+}
+
+func (s *S) TestUnsetTeamEnvs(c *check.C) {
+	envs := []bindTypes.EnvVar{
+		{Name: "LOG_LEVEL", Value: "info", Public: true},
+		{Name: "REGION", Value: "us", Public: true},
+	}
+	s.mockTeamEnvs(&envs)
+	ctx := context.TODO()
+	a := appTypes.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(ctx, &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = AddUnits(ctx, &a, 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = UnsetTeamEnvs(ctx, s.team.Name, bindTypes.UnsetEnvArgs{
+		VariableNames: []string{"LOG_LEVEL"},
+		ShouldRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(ctx, a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamEnvs, check.DeepEquals, []bindTypes.EnvVar{{Name: "REGION", Value: "us", Public: true}})
+	_, ok := provision.EnvsForApp(dbApp)["LOG_LEVEL"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 1)
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	UnitCaptureProfile = "profile"
+	UnitCaptureHeap    = "heap"
+
+	defaultCapturePprofURL = "http://127.0.0.1:6060/debug/pprof"
+	defaultProfileSeconds  = 30
+	defaultCaptureMaxSize  = 100 * 1024 * 1024
+)
+
+var (
+	ErrInvalidCaptureType       = errors.New("invalid capture type, must be one of: profile, heap")
+	ErrCaptureStorageNotEnabled = errors.New("unit capture storage is not configured")
+	ErrEmptyCapture             = errors.New("capture command produced no output")
+	ErrCaptureTooLarge          = errors.New("capture exceeds the maximum size")
+
+	errCaptureUploadStopped = errors.New("capture upload stopped")
+)
+
+// UnitCapture describes an artifact captured from an app unit and uploaded
+// to the object storage.
+type UnitCapture struct {
+	Type string `json:"type"`
+	Unit string `json:"unit"`
+	URL  string `json:"url"`
+	Size int    `json:"size"`
+}
+
+// captureCommand returns the command executed in the unit to capture the
+// artifact, capture:<type>:command in the config overrides the default, which
+// fetches it from the pprof endpoint at capture:pprof-url.
+func captureCommand(captureType string) string {
+	if cmd, _ := config.GetString("capture:" + captureType + ":command"); cmd != "" {
+		return cmd
+	}
+	pprofURL, _ := config.GetString("capture:pprof-url")
+	if pprofURL == "" {
+		pprofURL = defaultCapturePprofURL
+	}
+	target := strings.TrimSuffix(pprofURL, "/") + "/heap"
+	if captureType == UnitCaptureProfile {
+		target = fmt.Sprintf("%s/profile?seconds=%d", strings.TrimSuffix(pprofURL, "/"), defaultProfileSeconds)
+	}
+	return fmt.Sprintf("curl -sSf '%[1]s' 2>/dev/null || wget -qO- '%[1]s'", target)
+}
+
+func captureMaxSize() int64 {
+	size, err := config.GetInt("capture:max-size")
+	if err != nil || size <= 0 {
+		return defaultCaptureMaxSize
+	}
+	return int64(size)
+}
+
+// ValidateUnitCapture checks the capture type and whether captures can be
+// stored, before anything is run in the unit.
+func ValidateUnitCapture(captureType string) error {
+	if captureType != UnitCaptureProfile && captureType != UnitCaptureHeap {
+		return ErrInvalidCaptureType
+	}
+	if storageURL, _ := config.GetString("capture:storage:url"); storageURL == "" {
+		return ErrCaptureStorageNotEnabled
+	}
+	return nil
+}
+
+// captureReader counts the bytes of the artifact read from the unit, failing
+// once it exceeds the maximum size.
+type captureReader struct {
+	r        io.Reader
+	max      int64
+	n        int64
+	exceeded bool
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.n > r.max {
+		r.exceeded = true
+		return n, ErrCaptureTooLarge
+	}
+	return n, err
+}
+
+// CaptureUnit runs the capture for the given type inside the unit and streams
+// its output to the storage at capture:storage:url, up to capture:max-size
+// bytes. Messages from the command are written to w.
+func CaptureUnit(ctx context.Context, app *appTypes.App, unitID, captureType string, w io.Writer) (*UnitCapture, error) {
+	err := ValidateUnitCapture(captureType)
+	if err != nil {
+		return nil, err
+	}
+	storageURL, _ := config.GetString("capture:storage:url")
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	for _, u := range units {
+		if u.ID == unitID || u.Name == unitID {
+			unitID, found = u.ID, true
+			break
+		}
+	}
+	if !found {
+		return nil, &provision.UnitNotFoundError{ID: unitID}
+	}
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	execProv, ok := prov.(provision.ExecutableProvisioner)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "capturing units"}
+	}
+	fmt.Fprintf(w, "---- Capturing %s from unit %s ----\n", captureType, unitID)
+	maxSize := captureMaxSize()
+	pr, pw := io.Pipe()
+	execErr := make(chan error, 1)
+	go func() {
+		err := execProv.ExecuteCommand(ctx, provision.ExecOptions{
+			App:    app,
+			Stdout: pw,
+			Stderr: w,
+			Cmds:   cmdsForExec(captureCommand(captureType)),
+			Units:  []string{unitID},
+		})
+		pw.CloseWithError(err)
+		execErr <- err
+	}()
+	artifact := &captureReader{r: io.LimitReader(pr, maxSize+1), max: maxSize}
+	body := bufio.NewReader(artifact)
+	capture := &UnitCapture{
+		Type: captureType,
+		Unit: unitID,
+		URL:  fmt.Sprintf("%s/%s/%s/%s-%s.pprof", strings.TrimSuffix(storageURL, "/"), app.Name, unitID, captureType, time.Now().UTC().Format("20060102T150405Z")),
+	}
+	_, peekErr := body.Peek(1)
+	var uploadErr error
+	if peekErr == nil {
+		fmt.Fprintf(w, "---- Uploading to %s ----\n", capture.URL)
+		uploadErr = uploadCapture(ctx, capture.URL, body)
+	}
+	// Unblocks the command when the upload stops before reading all of it.
+	pr.CloseWithError(errCaptureUploadStopped)
+	err = <-execErr
+	if artifact.exceeded {
+		return nil, errors.Wrapf(ErrCaptureTooLarge, "%d bytes", maxSize)
+	}
+	if err != nil && errors.Cause(err) != errCaptureUploadStopped {
+		return nil, errors.Wrapf(err, "unable to capture %s from unit %s", captureType, unitID)
+	}
+	if peekErr == io.EOF {
+		return nil, ErrEmptyCapture
+	}
+	if peekErr != nil {
+		return nil, peekErr
+	}
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+	capture.Size = int(artifact.n)
+	fmt.Fprintf(w, "---- Uploaded %d bytes ----\n", capture.Size)
+	return capture, nil
+}
+
+// uploadCapture stores the artifact with a PUT request, which is supported by
+// most object storages. capture:storage:token is sent as a bearer token when
+// set.
+func uploadCapture(ctx context.Context, url string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if token, _ := config.GetString("capture:storage:token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rsp, err := tsuruNet.Dial15Full300Client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "unable to upload capture")
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		data, _ := io.ReadAll(rsp.Body)
+		return errors.Errorf("unable to upload capture: invalid status code %d: %s", rsp.StatusCode, string(data))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCaptureUnit(c *check.C) {
+	var uploaded []byte
+	var uploadPath, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, http.MethodPut)
+		uploadPath = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		uploaded, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	config.Set("capture:storage:url", srv.URL+"/captures")
+	config.Set("capture:storage:token", "secret")
+	defer config.Unset("capture")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("heap data"))
+	var buf bytes.Buffer
+	capture, err := CaptureUnit(context.TODO(), &a, units[0].ID, UnitCaptureHeap, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(capture.Type, check.Equals, UnitCaptureHeap)
+	c.Assert(capture.Size, check.Equals, len("heap data"))
+	c.Assert(capture.URL, check.Equals, srv.URL+uploadPath)
+	c.Assert(strings.HasPrefix(uploadPath, "/captures/myapp/"+units[0].ID+"/heap-"), check.Equals, true)
+	c.Assert(string(uploaded), check.Equals, "heap data")
+	c.Assert(auth, check.Equals, "Bearer secret")
+	execs := s.provisioner.Execs(units[0].ID)
+	c.Assert(execs, check.HasLen, 1)
+	c.Assert(execs[0].Cmds[2], check.Matches, ".*curl -sSf 'http://127.0.0.1:6060/debug/pprof/heap'.*")
+}
+
+func (s *S) TestCaptureUnitCustomCommand(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	config.Set("capture:storage:url", srv.URL)
+	config.Set("capture:profile:command", "my-profiler --seconds 10")
+	defer config.Unset("capture")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("profile data"))
+	_, err = CaptureUnit(context.TODO(), &a, units[0].ID, UnitCaptureProfile, io.Discard)
+	c.Assert(err, check.IsNil)
+	execs := s.provisioner.Execs(units[0].ID)
+	c.Assert(execs, check.HasLen, 1)
+	c.Assert(execs[0].Cmds[2], check.Matches, ".*; my-profiler --seconds 10$")
+}
+
+func (s *S) TestCaptureUnitErrors(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = CaptureUnit(context.TODO(), &a, "myapp-0", "trace", io.Discard)
+	c.Assert(err, check.Equals, ErrInvalidCaptureType)
+	_, err = CaptureUnit(context.TODO(), &a, "myapp-0", UnitCaptureHeap, io.Discard)
+	c.Assert(err, check.Equals, ErrCaptureStorageNotEnabled)
+	config.Set("capture:storage:url", "http://localhost")
+	defer config.Unset("capture")
+	_, err = CaptureUnit(context.TODO(), &a, "myapp-0", UnitCaptureHeap, io.Discard)
+	c.Assert(err, check.DeepEquals, &provision.UnitNotFoundError{ID: "myapp-0"})
+}
+
+func (s *S) TestCaptureUnitTooLarge(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer srv.Close()
+	config.Set("capture:storage:url", srv.URL)
+	config.Set("capture:max-size", 4)
+	defer config.Unset("capture")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("heap data"))
+	_, err = CaptureUnit(context.TODO(), &a, units[0].ID, UnitCaptureHeap, io.Discard)
+	c.Assert(errors.Cause(err), check.Equals, ErrCaptureTooLarge)
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	unitHistoryCollectionName = "unit_history"
+
+	CrashLoopEventKind = "crash-loop"
+
+	defaultUnitHistoryRetention = 7 * 24 * time.Hour
+	defaultCrashLoopWindow      = 10 * time.Minute
+	defaultCrashLoopThreshold   = 5
+	defaultUnitHistoryLimit     = 100
+)
+
+type unitHistoryEntry struct {
+	provTypes.UnitStatusHistory `bson:",inline"`
+	ExpireAt                    time.Time
+}
+
+// UnitHistoryFilter restricts the entries returned by ListUnitStatusHistory.
+type UnitHistoryFilter struct {
+	Unit    string
+	Process string
+	Since   time.Time
+	Limit   int
+}
+
+// CrashLoopStatus summarizes the restarts of a single unit inside the
+// crash-loop detection window.
+type CrashLoopStatus struct {
+	Unit        string        `json:"unit"`
+	ProcessName string        `json:"processName"`
+	Restarts    int           `json:"restarts"`
+	OOMKills    int           `json:"oomKills"`
+	Window      time.Duration `json:"window"`
+	CrashLoop   bool          `json:"crashLoop"`
+}
+
+func unitHistoryRetention() time.Duration {
+	retention, _ := config.GetDuration("apps:unit-history:retention")
+	if retention <= 0 {
+		return defaultUnitHistoryRetention
+	}
+	return retention
+}
+
+func crashLoopParams() (time.Duration, int) {
+	window, _ := config.GetDuration("apps:crash-loop:window")
+	if window <= 0 {
+		window = defaultCrashLoopWindow
+	}
+	threshold, _ := config.GetInt("apps:crash-loop:threshold")
+	if threshold <= 0 {
+		threshold = defaultCrashLoopThreshold
+	}
+	return window, threshold
+}
+
+// AddUnitStatusHistory stores a unit state transition and checks whether the
+// unit entered a crash loop, emitting an event the first time it happens
+// inside the detection window.
+func AddUnitStatusHistory(ctx context.Context, h provTypes.UnitStatusHistory) error {
+	collection, err := storagev2.Collection(unitHistoryCollectionName)
+	if err != nil {
+		return err
+	}
+	if h.Time.IsZero() {
+		h.Time = time.Now().UTC()
+	}
+	_, err = collection.InsertOne(ctx, unitHistoryEntry{
+		UnitStatusHistory: h,
+		ExpireAt:          h.Time.Add(unitHistoryRetention()),
+	})
+	if err != nil {
+		return err
+	}
+	if h.Restarts == 0 {
+		return nil
+	}
+	status, err := unitCrashLoopStatus(ctx, h.AppName, h.Unit, h.ProcessName)
+	if err != nil {
+		return err
+	}
+	if !status.CrashLoop {
+		return nil
+	}
+	return notifyCrashLoop(ctx, h.AppName, status)
+}
+
+// ListUnitStatusHistory returns the recorded state transitions of the units
+// of an app, most recent first.
+func ListUnitStatusHistory(ctx context.Context, appName string, filter UnitHistoryFilter) ([]provTypes.UnitStatusHistory, error) {
+	collection, err := storagev2.Collection(unitHistoryCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{"appname": appName}
+	if filter.Unit != "" {
+		query["unit"] = filter.Unit
+	}
+	if filter.Process != "" {
+		query["processname"] = filter.Process
+	}
+	if !filter.Since.IsZero() {
+		query["time"] = mongoBSON.M{"$gte": filter.Since}
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > defaultUnitHistoryLimit {
+		limit = defaultUnitHistoryLimit
+	}
+	opts := options.Find().SetSort(mongoBSON.M{"time": -1}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var entries []unitHistoryEntry
+	err = cursor.All(ctx, &entries)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]provTypes.UnitStatusHistory, len(entries))
+	for i := range entries {
+		history[i] = entries[i].UnitStatusHistory
+	}
+	return history, nil
+}
+
+// CrashLoopReport returns the crash-loop status of every unit of the app that
+// restarted inside the detection window.
+func CrashLoopReport(ctx context.Context, appName string) ([]CrashLoopStatus, error) {
+	window, _ := crashLoopParams()
+	history, err := ListUnitStatusHistory(ctx, appName, UnitHistoryFilter{
+		Since: time.Now().UTC().Add(-window),
+		Limit: defaultUnitHistoryLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summarizeCrashLoops(history), nil
+}
+
+func unitCrashLoopStatus(ctx context.Context, appName, unit, process string) (CrashLoopStatus, error) {
+	window, _ := crashLoopParams()
+	history, err := ListUnitStatusHistory(ctx, appName, UnitHistoryFilter{
+		Unit:  unit,
+		Since: time.Now().UTC().Add(-window),
+		Limit: defaultUnitHistoryLimit,
+	})
+	if err != nil {
+		return CrashLoopStatus{}, err
+	}
+	for _, s := range summarizeCrashLoops(history) {
+		if s.Unit == unit {
+			return s, nil
+		}
+	}
+	return CrashLoopStatus{Unit: unit, ProcessName: process, Window: window}, nil
+}
+
+func summarizeCrashLoops(history []provTypes.UnitStatusHistory) []CrashLoopStatus {
+	window, threshold := crashLoopParams()
+	var result []CrashLoopStatus
+	indexes := map[string]int{}
+	for _, h := range history {
+		if h.Restarts == 0 {
+			continue
+		}
+		idx, ok := indexes[h.Unit]
+		if !ok {
+			idx = len(result)
+			indexes[h.Unit] = idx
+			result = append(result, CrashLoopStatus{
+				Unit:        h.Unit,
+				ProcessName: h.ProcessName,
+				Window:      window,
+			})
+		}
+		result[idx].Restarts += int(h.Restarts)
+		if h.OOMKilled {
+			result[idx].OOMKills++
+		}
+	}
+	for i := range result {
+		result[i].CrashLoop = result[i].Restarts >= threshold
+	}
+	return result
+}
+
+func notifyCrashLoop(ctx context.Context, appName string, status CrashLoopStatus) error {
+	target := eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: appName}
+	evts, err := event.List(ctx, &event.Filter{
+		Target:    target,
+		KindType:  eventTypes.KindTypeInternal,
+		KindNames: []string{CrashLoopEventKind},
+		Since:     time.Now().UTC().Add(-status.Window),
+		Limit:     1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(evts) > 0 {
+		return nil
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       target,
+		InternalKind: CrashLoopEventKind,
+		DisableLock:  true,
+		CustomData:   status,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, appName)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to create crash loop event")
+	}
+	return evt.Done(ctx, errors.Errorf("unit %s restarted %d times in %s", status.Unit, status.Restarts, status.Window))
+}
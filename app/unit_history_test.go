@@ -0,0 +1,105 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddAndListUnitStatusHistory(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	exitCode := int32(137)
+	err := AddUnitStatusHistory(context.TODO(), provTypes.UnitStatusHistory{
+		AppName:     "myapp",
+		Unit:        "myapp-web-1",
+		ProcessName: "web",
+		Status:      provTypes.UnitStatusError,
+		Reason:      "OOMKilled",
+		ExitCode:    &exitCode,
+		Restarts:    1,
+		OOMKilled:   true,
+		Time:        now.Add(-time.Minute),
+	})
+	c.Assert(err, check.IsNil)
+	err = AddUnitStatusHistory(context.TODO(), provTypes.UnitStatusHistory{
+		AppName:     "myapp",
+		Unit:        "myapp-worker-1",
+		ProcessName: "worker",
+		Status:      provTypes.UnitStatusStopped,
+		Time:        now,
+	})
+	c.Assert(err, check.IsNil)
+	err = AddUnitStatusHistory(context.TODO(), provTypes.UnitStatusHistory{
+		AppName: "otherapp",
+		Unit:    "otherapp-web-1",
+		Time:    now,
+	})
+	c.Assert(err, check.IsNil)
+	history, err := ListUnitStatusHistory(context.TODO(), "myapp", UnitHistoryFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 2)
+	c.Assert(history[0].Unit, check.Equals, "myapp-worker-1")
+	c.Assert(history[1].Unit, check.Equals, "myapp-web-1")
+	c.Assert(history[1].OOMKilled, check.Equals, true)
+	c.Assert(*history[1].ExitCode, check.Equals, int32(137))
+	history, err = ListUnitStatusHistory(context.TODO(), "myapp", UnitHistoryFilter{Process: "web"})
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 1)
+	c.Assert(history[0].Unit, check.Equals, "myapp-web-1")
+	history, err = ListUnitStatusHistory(context.TODO(), "myapp", UnitHistoryFilter{Since: now.Add(-time.Second)})
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 1)
+	c.Assert(history[0].Unit, check.Equals, "myapp-worker-1")
+}
+
+func (s *S) TestAddUnitStatusHistoryCrashLoop(c *check.C) {
+	config.Set("apps:crash-loop:threshold", 3)
+	defer config.Unset("apps:crash-loop:threshold")
+	for i := 0; i < 4; i++ {
+		err := AddUnitStatusHistory(context.TODO(), provTypes.UnitStatusHistory{
+			AppName:     "myapp",
+			Unit:        "myapp-web-1",
+			ProcessName: "web",
+			Status:      provTypes.UnitStatusError,
+			Restarts:    1,
+		})
+		c.Assert(err, check.IsNil)
+	}
+	report, err := CrashLoopReport(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.HasLen, 1)
+	c.Assert(report[0].Unit, check.Equals, "myapp-web-1")
+	c.Assert(report[0].Restarts, check.Equals, 4)
+	c.Assert(report[0].CrashLoop, check.Equals, true)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		KindNames: []string{CrashLoopEventKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Matches, "unit myapp-web-1 restarted 3 times in .*")
+}
+
+func (s *S) TestSummarizeCrashLoops(c *check.C) {
+	config.Set("apps:crash-loop:threshold", 2)
+	defer config.Unset("apps:crash-loop:threshold")
+	report := summarizeCrashLoops([]provTypes.UnitStatusHistory{
+		{Unit: "u1", ProcessName: "web", Restarts: 2, OOMKilled: true},
+		{Unit: "u2", ProcessName: "worker", Restarts: 1},
+		{Unit: "u1", ProcessName: "web", Restarts: 1},
+		{Unit: "u3", ProcessName: "web"},
+	})
+	c.Assert(report, check.DeepEquals, []CrashLoopStatus{
+		{Unit: "u1", ProcessName: "web", Restarts: 3, OOMKills: 1, Window: defaultCrashLoopWindow, CrashLoop: true},
+		{Unit: "u2", ProcessName: "worker", Restarts: 1, Window: defaultCrashLoopWindow},
+	})
+}
@@ -21,6 +21,9 @@ type customData struct {
 	Healthcheck *provTypes.TsuruYamlHealthcheck
 	Kubernetes  *tsuruYamlKubernetesConfig
 	Processes   []provTypes.TsuruYamlProcess
+	Deploy      *provTypes.TsuruYamlDeploy
+	Metrics     *provTypes.TsuruYamlMetrics
+	Sidecars    []provTypes.TsuruYamlSidecar
 }
 
 type tsuruYamlKubernetesConfig struct {
@@ -62,6 +65,9 @@ func unmarshalYamlData(data map[string]interface{}) (provTypes.TsuruYamlData, er
 		Hooks:       custom.Hooks,
 		Processes:   custom.Processes,
 		Healthcheck: custom.Healthcheck,
+		Deploy:      custom.Deploy,
+		Metrics:     custom.Metrics,
+		Sidecars:    custom.Sidecars,
 	}
 	if custom.Kubernetes == nil {
 		return result, nil
@@ -0,0 +1,192 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrNoVersionEnvs = errors.New("no environment variables or binds restricted to the version")
+
+func appVersionForEnvs(ctx context.Context, app *appTypes.App, version int) (appTypes.AppVersion, error) {
+	if version < 0 {
+		return nil, appTypes.ErrInvalidVersion{Version: strconv.Itoa(version)}
+	}
+	return servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(version))
+}
+
+// setVersionEnvs saves envs only for the units of a single version, which is
+// the only one restarted.
+func setVersionEnvs(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArgs) error {
+	if setEnvs.PruneUnused {
+		return &tsuruErrors.ValidationError{Message: "prune unused is not supported for environment variables of a single version"}
+	}
+	envNames := []string{}
+	for _, env := range setEnvs.Envs {
+		err := validateEnv(env.Name)
+		if err != nil {
+			return err
+		}
+		envNames = append(envNames, env.Name)
+	}
+	err := checkEnvSchemaChange(app, setEnvs.Envs, nil)
+	if err != nil {
+		return err
+	}
+	version, err := appVersionForEnvs(ctx, app, setEnvs.Version)
+	if err != nil {
+		return err
+	}
+	err = validateEnvConflicts(app, envNames)
+	if err != nil {
+		return err
+	}
+	if setEnvs.Writer != nil {
+		fmt.Fprintf(setEnvs.Writer, "---- Setting %d new environment variables to version %d ----\n", len(setEnvs.Envs), setEnvs.Version)
+	}
+	for _, env := range setEnvs.Envs {
+		app.VersionEnvs = removeVersionEnv(app.VersionEnvs, setEnvs.Version, env.Name)
+		app.VersionEnvs = append(app.VersionEnvs, bindTypes.VersionEnvVar{EnvVar: env, Version: setEnvs.Version})
+	}
+	sort.SliceStable(app.VersionEnvs, func(i, j int) bool {
+		if app.VersionEnvs[i].Version == app.VersionEnvs[j].Version {
+			return app.VersionEnvs[i].Name < app.VersionEnvs[j].Name
+		}
+		return app.VersionEnvs[i].Version < app.VersionEnvs[j].Version
+	})
+	err = saveVersionEnvs(ctx, app, "versionenvs")
+	if err != nil {
+		return err
+	}
+	if setEnvs.ShouldRestart {
+		return restartVersionIfUnits(ctx, app, version, setEnvs.Writer)
+	}
+	return nil
+}
+
+func unsetVersionEnvs(ctx context.Context, app *appTypes.App, unsetEnvs bindTypes.UnsetEnvArgs) error {
+	version, err := appVersionForEnvs(ctx, app, unsetEnvs.Version)
+	if err != nil {
+		return err
+	}
+	if unsetEnvs.Writer != nil {
+		fmt.Fprintf(unsetEnvs.Writer, "---- Unsetting %d environment variables from version %d ----\n", len(unsetEnvs.VariableNames), unsetEnvs.Version)
+	}
+	for _, name := range unsetEnvs.VariableNames {
+		app.VersionEnvs = removeVersionEnv(app.VersionEnvs, unsetEnvs.Version, name)
+	}
+	err = saveVersionEnvs(ctx, app, "versionenvs")
+	if err != nil {
+		return err
+	}
+	if unsetEnvs.ShouldRestart {
+		return restartVersionIfUnits(ctx, app, version, unsetEnvs.Writer)
+	}
+	return nil
+}
+
+func removeVersionEnv(envs []bindTypes.VersionEnvVar, version int, name string) []bindTypes.VersionEnvVar {
+	result := envs[:0]
+	for _, env := range envs {
+		if env.Version != version || env.Name != name {
+			result = append(result, env)
+		}
+	}
+	return result
+}
+
+// PromoteVersionEnvs applies the envs and binds restricted to a version to
+// all versions of the app, restarting all of them when shouldRestart is set.
+func PromoteVersionEnvs(ctx context.Context, app *appTypes.App, version int, shouldRestart bool, w io.Writer) error {
+	var promoted int
+	remaining := []bindTypes.VersionEnvVar{}
+	for _, env := range app.VersionEnvs {
+		if env.Version != version {
+			remaining = append(remaining, env)
+			continue
+		}
+		setEnv(app, env.EnvVar)
+		promoted++
+	}
+	app.VersionEnvs = remaining
+	for i := range app.ServiceEnvs {
+		if app.ServiceEnvs[i].Version == version {
+			app.ServiceEnvs[i].Version = 0
+			promoted++
+		}
+	}
+	if promoted == 0 {
+		return ErrNoVersionEnvs
+	}
+	if w != nil {
+		fmt.Fprintf(w, "---- Promoting %d environment variables from version %d to all versions ----\n", promoted, version)
+	}
+	err := saveVersionEnvs(ctx, app, "env", "serviceenvs", "versionenvs")
+	if err != nil {
+		return err
+	}
+	recordConfigSnapshot(ctx, app)
+	if shouldRestart {
+		return restartIfUnits(ctx, app, w)
+	}
+	return nil
+}
+
+func saveVersionEnvs(ctx context.Context, app *appTypes.App, fields ...string) error {
+	values := map[string]interface{}{
+		"env":         app.Env,
+		"serviceenvs": app.ServiceEnvs,
+		"versionenvs": app.VersionEnvs,
+	}
+	update := mongoBSON.M{}
+	for _, field := range fields {
+		update[field] = values[field]
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$set": update})
+	return err
+}
+
+// restartVersionIfUnits restarts only the units of the given version.
+func restartVersionIfUnits(ctx context.Context, app *appTypes.App, version appTypes.AppVersion, w io.Writer) error {
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return err
+	}
+	var hasUnits bool
+	for _, u := range units {
+		if u.Version == version.Version() {
+			hasUnits = true
+			break
+		}
+	}
+	if !hasUnits {
+		return nil
+	}
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = prov.Restart(ctx, app, "", version, w)
+	if err != nil {
+		return newErrorWithLog(ctx, err, app, "restart")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createAppWithTwoVersions(c *check.C) (*appTypes.App, appTypes.AppVersion) {
+	a := &appTypes.App{Name: "canary", Quota: quota.Quota{Limit: 10}, TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, a)
+	canary := newSuccessfulAppVersion(c, a)
+	err = AddUnits(context.TODO(), a, 1, "web", "1", nil)
+	c.Assert(err, check.IsNil)
+	err = AddUnits(context.TODO(), a, 1, "web", strconv.Itoa(canary.Version()), nil)
+	c.Assert(err, check.IsNil)
+	return a, canary
+}
+
+func (s *S) TestSetEnvsVersion(c *check.C) {
+	a, canary := s.createAppWithTwoVersions(c)
+	a.Env = map[string]bindTypes.EnvVar{"DB_PASSWORD": {Name: "DB_PASSWORD", Value: "old"}}
+	var buf bytes.Buffer
+	err := SetEnvs(context.TODO(), a, bindTypes.SetEnvArgs{
+		Envs:          []bindTypes.EnvVar{{Name: "DB_PASSWORD", Value: "new"}},
+		ShouldRestart: true,
+		Writer:        &buf,
+		Version:       canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)---- Setting 1 new environment variables to version 2 ----.*`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.VersionEnvs, check.DeepEquals, []bindTypes.VersionEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "DB_PASSWORD", Value: "new"}, Version: 2},
+	})
+	c.Assert(provision.EnvsForApp(dbApp)["DB_PASSWORD"].Value, check.Equals, "old")
+	c.Assert(provision.EnvsForAppVersion(dbApp, 2)["DB_PASSWORD"].Value, check.Equals, "new")
+	c.Assert(s.provisioner.RestartsByVersion(a, ""), check.Equals, 0)
+	c.Assert(s.provisioner.RestartsByVersion(a, "2"), check.Equals, 1)
+}
+
+func (s *S) TestSetEnvsVersionInvalid(c *check.C) {
+	a, _ := s.createAppWithTwoVersions(c)
+	err := SetEnvs(context.TODO(), a, bindTypes.SetEnvArgs{
+		Envs:    []bindTypes.EnvVar{{Name: "DB_PASSWORD", Value: "new"}},
+		Version: 9,
+	})
+	c.Assert(appTypes.IsInvalidVersionError(err), check.Equals, true)
+	err = SetEnvs(context.TODO(), a, bindTypes.SetEnvArgs{
+		Envs:        []bindTypes.EnvVar{{Name: "DB_PASSWORD", Value: "new"}},
+		ManagedBy:   "terraform",
+		PruneUnused: true,
+		Version:     2,
+	})
+	c.Assert(err, check.ErrorMatches, "prune unused is not supported .*")
+}
+
+func (s *S) TestUnsetEnvsVersion(c *check.C) {
+	a, canary := s.createAppWithTwoVersions(c)
+	err := SetEnvs(context.TODO(), a, bindTypes.SetEnvArgs{
+		Envs:    []bindTypes.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}},
+		Version: canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	err = UnsetEnvs(context.TODO(), a, bindTypes.UnsetEnvArgs{
+		VariableNames: []string{"A"},
+		ShouldRestart: true,
+		Version:       canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.VersionEnvs, check.DeepEquals, []bindTypes.VersionEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "B", Value: "2"}, Version: 2},
+	})
+	c.Assert(s.provisioner.RestartsByVersion(a, "2"), check.Equals, 1)
+}
+
+func (s *S) TestAddInstanceVersion(c *check.C) {
+	a, canary := s.createAppWithTwoVersions(c)
+	err := AddInstance(context.TODO(), a, bindTypes.AddInstanceArgs{
+		Envs: []bindTypes.ServiceEnvVar{
+			{EnvVar: bindTypes.EnvVar{Name: "DATABASE_HOST", Value: "localhost"}, InstanceName: "myinstance", ServiceName: "myservice"},
+		},
+		ShouldRestart: true,
+		Version:       canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ServiceEnvs, check.DeepEquals, []bindTypes.ServiceEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "DATABASE_HOST", Value: "localhost"}, InstanceName: "myinstance", ServiceName: "myservice", Version: 2},
+	})
+	_, ok := provision.EnvsForApp(dbApp)["DATABASE_HOST"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(provision.EnvsForAppVersion(dbApp, 2)["DATABASE_HOST"].Value, check.Equals, "localhost")
+	c.Assert(s.provisioner.RestartsByVersion(a, ""), check.Equals, 0)
+	c.Assert(s.provisioner.RestartsByVersion(a, "2"), check.Equals, 1)
+}
+
+func (s *S) TestPromoteVersionEnvs(c *check.C) {
+	a, canary := s.createAppWithTwoVersions(c)
+	err := SetEnvs(context.TODO(), a, bindTypes.SetEnvArgs{
+		Envs:    []bindTypes.EnvVar{{Name: "DB_PASSWORD", Value: "new"}},
+		Version: canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	err = AddInstance(context.TODO(), a, bindTypes.AddInstanceArgs{
+		Envs: []bindTypes.ServiceEnvVar{
+			{EnvVar: bindTypes.EnvVar{Name: "DATABASE_HOST", Value: "localhost"}, InstanceName: "myinstance", ServiceName: "myservice"},
+		},
+		Version: canary.Version(),
+	})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = PromoteVersionEnvs(context.TODO(), a, canary.Version(), true, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)---- Promoting 2 environment variables from version 2 to all versions ----.*`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.VersionEnvs, check.HasLen, 0)
+	c.Assert(dbApp.Env["DB_PASSWORD"], check.DeepEquals, bindTypes.EnvVar{Name: "DB_PASSWORD", Value: "new"})
+	c.Assert(dbApp.ServiceEnvs, check.DeepEquals, []bindTypes.ServiceEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "DATABASE_HOST", Value: "localhost"}, InstanceName: "myinstance", ServiceName: "myservice"},
+	})
+	c.Assert(provision.EnvsForApp(dbApp)["DATABASE_HOST"].Value, check.Equals, "localhost")
+	c.Assert(s.provisioner.RestartsByVersion(a, ""), check.Equals, 1)
+	err = PromoteVersionEnvs(context.TODO(), a, canary.Version(), true, nil)
+	c.Assert(err, check.Equals, ErrNoVersionEnvs)
+}
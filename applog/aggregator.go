@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/auth/peer"
@@ -223,6 +224,18 @@ func buildInstanceRequests(ctx context.Context, args appTypes.ListLogArgs, follo
 			urlValues.Add("unit", u)
 		}
 		urlValues.Add("invert-source", strconv.FormatBool(args.InvertSource))
+		if args.Query != "" {
+			urlValues.Add("query", args.Query)
+		}
+		if args.Severity != "" {
+			urlValues.Add("severity", args.Severity)
+		}
+		if !args.Since.IsZero() {
+			urlValues.Add("since", args.Since.Format(time.RFC3339Nano))
+		}
+		if !args.Until.IsZero() {
+			urlValues.Add("until", args.Until.Format(time.RFC3339Nano))
+		}
 		if follow {
 			urlValues.Add("follow", "1")
 		}
@@ -0,0 +1,320 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	defaultLokiBatchSize     = 500
+	defaultLokiFlushInterval = time.Second
+	defaultLokiListLimit     = 100
+	lokiQueueSize            = 10000
+)
+
+var logsLokiDropped = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: promNamespace,
+	Subsystem: "logs_loki",
+	Name:      "dropped_total",
+	Help:      "The number of log entries not pushed to Loki due to a full queue or push failures.",
+})
+
+// lokiLogService stores the logs of apps in Loki, which is queried when
+// listing them. Watching logs still relies on the in memory buffers of every
+// tsuru API instance, as in the memory service.
+type lokiLogService struct {
+	*aggregatorLogService
+	url           string
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan *appTypes.Applog
+	quit          chan struct{}
+	wg            sync.WaitGroup
+}
+
+func lokiAppLogService() (appTypes.AppLogService, error) {
+	lokiURL, _ := config.GetString("log:loki:url")
+	if lokiURL == "" {
+		return nil, errors.New("log:loki:url is required when using the loki app log service")
+	}
+	aggregator, err := aggregatorAppLogService()
+	if err != nil {
+		return nil, err
+	}
+	batchSize, _ := config.GetInt("log:loki:batch-size")
+	if batchSize <= 0 {
+		batchSize = defaultLokiBatchSize
+	}
+	flushInterval, _ := config.GetDuration("log:loki:flush-interval")
+	if flushInterval <= 0 {
+		flushInterval = defaultLokiFlushInterval
+	}
+	s := &lokiLogService{
+		aggregatorLogService: aggregator.(*aggregatorLogService),
+		url:                  strings.TrimRight(lokiURL, "/"),
+		batchSize:            batchSize,
+		flushInterval:        flushInterval,
+		queue:                make(chan *appTypes.Applog, lokiQueueSize),
+		quit:                 make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	shutdown.Register(s)
+	return s, nil
+}
+
+func (s *lokiLogService) Enqueue(entry *appTypes.Applog) error {
+	err := s.aggregatorLogService.Enqueue(entry)
+	if err != nil {
+		return err
+	}
+	select {
+	case s.queue <- entry:
+	default:
+		logsLokiDropped.Inc()
+	}
+	return nil
+}
+
+func (s *lokiLogService) Add(appName, message, source, unit string) error {
+	for _, entry := range newLogEntries(appName, message, source, unit) {
+		err := s.Enqueue(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *lokiLogService) Shutdown(ctx context.Context) error {
+	close(s.quit)
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *lokiLogService) run() {
+	defer s.wg.Done()
+	var batch []*appTypes.Applog
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		err := s.push(context.Background(), batch)
+		if err != nil {
+			logsLokiDropped.Add(float64(len(batch)))
+			log.Errorf("[loki] unable to push %d log entries: %v", len(batch), err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.quit:
+			for {
+				select {
+				case entry := <-s.queue:
+					batch = append(batch, entry)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func lokiLabels(entry *appTypes.Applog) map[string]string {
+	labels := map[string]string{"app": entry.Name}
+	if entry.Source != "" {
+		labels["source"] = entry.Source
+	}
+	if entry.Unit != "" {
+		labels["unit"] = entry.Unit
+	}
+	return labels
+}
+
+func (s *lokiLogService) push(ctx context.Context, entries []*appTypes.Applog) error {
+	streams := map[string]*lokiStream{}
+	var keys []string
+	for _, entry := range entries {
+		key := entry.Name + "\x00" + entry.Source + "\x00" + entry.Unit
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: lokiLabels(entry)}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.Date.UnixNano(), 10), entry.Message})
+	}
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range keys {
+		payload.Streams = append(payload.Streams, streams[key])
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/loki/api/v1/push", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code %v: %q", rsp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// lokiQuery translates the arguments to a LogQL query. The severity is
+// filtered after the query, as it's inferred from the messages.
+func lokiQuery(args appTypes.ListLogArgs) string {
+	selectors := []string{fmt.Sprintf("app=%q", args.Name)}
+	if args.Source != "" {
+		op := "="
+		if args.InvertSource {
+			op = "!="
+		}
+		selectors = append(selectors, fmt.Sprintf("source%s%q", op, args.Source))
+	}
+	if len(args.Units) > 0 {
+		units := make([]string, len(args.Units))
+		for i, u := range args.Units {
+			units[i] = regexp.QuoteMeta(u)
+		}
+		selectors = append(selectors, fmt.Sprintf("unit=~%q", strings.Join(units, "|")))
+	}
+	query := "{" + strings.Join(selectors, ",") + "}"
+	if args.Query != "" {
+		query += fmt.Sprintf(" |~ %q", "(?i)"+regexp.QuoteMeta(args.Query))
+	}
+	return query
+}
+
+func (s *lokiLogService) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	if args.Name == "" {
+		return nil, errors.New("app name required to list logs")
+	}
+	if args.Limit < 0 {
+		return []appTypes.Applog{}, nil
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultLokiListLimit
+	}
+	values := url.Values{}
+	values.Set("query", lokiQuery(args))
+	values.Set("limit", strconv.Itoa(limit))
+	values.Set("direction", "backward")
+	if !args.Since.IsZero() {
+		values.Set("start", strconv.FormatInt(args.Since.UnixNano(), 10))
+	}
+	if !args.Until.IsZero() {
+		values.Set("end", strconv.FormatInt(args.Until.UnixNano(), 10))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/loki/api/v1/query_range?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rsp.Body.Close()
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("[loki] invalid status code %v: %q", rsp.StatusCode, string(body))
+	}
+	var result struct {
+		Data struct {
+			Result []lokiStream `json:"result"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[loki] unable to parse response %q", string(body))
+	}
+	logs := []appTypes.Applog{}
+	for _, stream := range result.Data.Result {
+		for _, value := range stream.Values {
+			ts, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[loki] invalid timestamp %q", value[0])
+			}
+			entry := appTypes.Applog{
+				Date:    time.Unix(0, ts).UTC(),
+				Message: value[1],
+				Source:  stream.Stream["source"],
+				Name:    args.Name,
+				Unit:    stream.Stream["unit"],
+			}
+			if args.MatchesSearch(&entry) {
+				logs = append(logs, entry)
+			}
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Date.Before(logs[j].Date)
+	})
+	if len(logs) > limit {
+		logs = logs[len(logs)-limit:]
+	}
+	return logs, nil
+}
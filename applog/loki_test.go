@@ -0,0 +1,101 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestLokiQuery(c *check.C) {
+	c.Assert(lokiQuery(appTypes.ListLogArgs{Name: "myapp"}), check.Equals, `{app="myapp"}`)
+	c.Assert(lokiQuery(appTypes.ListLogArgs{
+		Name:         "myapp",
+		Source:       "web",
+		InvertSource: true,
+		Units:        []string{"myapp-web-1", "myapp-web-2"},
+		Query:        "user.id",
+	}), check.Equals, `{app="myapp",source!="web",unit=~"myapp-web-1|myapp-web-2"} |~ "(?i)user\\.id"`)
+}
+
+func (s *S) TestLokiLogServiceList(c *check.C) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/loki/api/v1/query_range")
+		query = r.URL.Query()
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"myapp","source":"web","unit":"u1"},"values":[["1700000002000000000","ERROR: timeout"],["1700000000000000000","GET / 200"]]},
+			{"stream":{"app":"myapp","source":"worker","unit":"u2"},"values":[["1700000001000000000","job error"]]}
+		]}}`))
+	}))
+	defer server.Close()
+	config.Set("log:loki:url", server.URL)
+	defer config.Unset("log:loki:url")
+	svc, err := lokiAppLogService()
+	c.Assert(err, check.IsNil)
+	defer svc.(*lokiLogService).Shutdown(context.TODO())
+	since := time.Unix(1699990000, 0)
+	logs, err := svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Limit: 10, Since: since, Severity: appTypes.LogSeverityError})
+	c.Assert(err, check.IsNil)
+	c.Assert(query["query"], check.DeepEquals, []string{`{app="myapp"}`})
+	c.Assert(query["limit"], check.DeepEquals, []string{"10"})
+	c.Assert(query["start"], check.DeepEquals, []string{"1699990000000000000"})
+	c.Assert(logs, check.DeepEquals, []appTypes.Applog{
+		{Date: time.Unix(1700000001, 0).UTC(), Message: "job error", Source: "worker", Name: "myapp", Unit: "u2"},
+		{Date: time.Unix(1700000002, 0).UTC(), Message: "ERROR: timeout", Source: "web", Name: "myapp", Unit: "u1"},
+	})
+}
+
+func (s *S) TestLokiLogServicePush(c *check.C) {
+	var mu sync.Mutex
+	var streams []lokiStream
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/loki/api/v1/push")
+		var payload struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		c.Check(err, check.IsNil)
+		mu.Lock()
+		streams = append(streams, payload.Streams...)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	config.Set("log:loki:url", server.URL)
+	defer config.Unset("log:loki:url")
+	svc, err := lokiAppLogService()
+	c.Assert(err, check.IsNil)
+	err = svc.Add("myapp", "line 1\nline 2", "web", "u1")
+	c.Assert(err, check.IsNil)
+	err = svc.Add("myapp", "started", "tsuru", "")
+	c.Assert(err, check.IsNil)
+	err = svc.(*lokiLogService).Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(streams, check.HasLen, 2)
+	c.Assert(streams[0].Stream, check.DeepEquals, map[string]string{"app": "myapp", "source": "web", "unit": "u1"})
+	c.Assert(streams[0].Values, check.HasLen, 2)
+	c.Assert(streams[0].Values[0][1], check.Equals, "line 1")
+	c.Assert(streams[0].Values[1][1], check.Equals, "line 2")
+	c.Assert(streams[1].Stream, check.DeepEquals, map[string]string{"app": "myapp", "source": "tsuru"})
+	logs, err := svc.(*lokiLogService).Instance().List(context.TODO(), appTypes.ListLogArgs{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 3)
+}
+
+func (s *S) TestLokiLogServiceMissingURL(c *check.C) {
+	_, err := lokiAppLogService()
+	c.Assert(err, check.ErrorMatches, "log:loki:url is required when using the loki app log service")
+}
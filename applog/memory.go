@@ -79,6 +79,17 @@ func (s *memoryLogService) Enqueue(entry *appTypes.Applog) error {
 }
 
 func (s *memoryLogService) Add(appName, message, source, unit string) error {
+	for _, log := range newLogEntries(appName, message, source, unit) {
+		err := s.Enqueue(log)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newLogEntries returns one entry for each non-blank line of the message.
+func newLogEntries(appName, message, source, unit string) []*appTypes.Applog {
 	messages := strings.Split(message, "\n")
 	logs := make([]*appTypes.Applog, 0, len(messages))
 	for _, msg := range messages {
@@ -93,16 +104,7 @@ func (s *memoryLogService) Add(appName, message, source, unit string) error {
 			logs = append(logs, l)
 		}
 	}
-	if len(logs) == 0 {
-		return nil
-	}
-	for _, log := range logs {
-		err := s.Enqueue(log)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return logs
 }
 
 func (s *memoryLogService) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
@@ -193,7 +195,8 @@ func (b *appLogBuffer) list(args appTypes.ListLogArgs) []appTypes.Applog {
 	unitsSet := set.FromSlice(args.Units)
 	for current := b.end; count < args.Limit; {
 		if (args.Source == "" || (args.Source == current.log.Source) != args.InvertSource) &&
-			(len(args.Units) == 0 || unitsSet.Includes(current.log.Unit)) {
+			(len(args.Units) == 0 || unitsSet.Includes(current.log.Unit)) &&
+			args.MatchesSearch(current.log) {
 
 			logs[len(logs)-count-1] = *current.log
 			count++
@@ -289,6 +292,9 @@ func (w *memoryWatcher) notify(entry *appTypes.Applog, dropCounter prometheus.Co
 	if len(w.filter.Units) > 0 && !w.unitsSet.Includes(entry.Unit) {
 		return
 	}
+	if !w.filter.MatchesSearch(entry) {
+		return
+	}
 	select {
 	case w.ch <- *entry:
 	default:
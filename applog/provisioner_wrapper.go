@@ -52,13 +52,13 @@ func defineLogabbleObject(ctx context.Context, lType logTypes.LogType, name stri
 		if err != nil {
 			return nil, err
 		}
-		return &logTypes.LogabbleObject{Name: job.Name, Pool: job.Pool}, nil
+		return &logTypes.LogabbleObject{Name: job.Name, Pool: job.Pool, Team: job.TeamOwner}, nil
 	}
 	app, err := servicemanager.App.GetByName(ctx, name)
 	if err != nil {
 		return nil, err
 	}
-	return &logTypes.LogabbleObject{Name: app.Name, Pool: app.Pool}, nil
+	return &logTypes.LogabbleObject{Name: app.Name, Pool: app.Pool, Team: app.TeamOwner}, nil
 }
 
 func (k *provisionerWrapper) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
@@ -87,7 +87,11 @@ func (k *provisionerWrapper) List(ctx context.Context, args appTypes.ListLogArgs
 	if err != nil {
 		return nil, err
 	}
-	logs = append(logs, provLogs...)
+	for i := range provLogs {
+		if args.MatchesSearch(&provLogs[i]) {
+			logs = append(logs, provLogs[i])
+		}
+	}
 	sort.SliceStable(logs, func(i, j int) bool {
 		return logs[i].Date.Before(logs[j].Date)
 	})
@@ -121,9 +125,9 @@ func (k *provisionerWrapper) Watch(ctx context.Context, args appTypes.ListLogArg
 		return nil, err
 	}
 	if tsuruWatcher != nil {
-		return newMultiWatcher(provisionerWatcher, tsuruWatcher), nil
+		return newFilteredMultiWatcher(args, provisionerWatcher, tsuruWatcher), nil
 	}
-	return newMultiWatcher(provisionerWatcher), nil
+	return newFilteredMultiWatcher(args, provisionerWatcher), nil
 }
 
 func (k *provisionerWrapper) Instance() appTypes.AppLogService {
@@ -153,6 +157,7 @@ var _ appTypes.LogWatcher = &multiWatcher{}
 
 type multiWatcher struct {
 	subWatchers []appTypes.LogWatcher
+	filter      appTypes.ListLogArgs
 	ch          chan appTypes.Applog
 	close       chan struct{}
 	closeCalled int32
@@ -160,8 +165,15 @@ type multiWatcher struct {
 }
 
 func newMultiWatcher(subWatchers ...appTypes.LogWatcher) *multiWatcher {
+	return newFilteredMultiWatcher(appTypes.ListLogArgs{}, subWatchers...)
+}
+
+// newFilteredMultiWatcher merges the sub watchers, dropping the entries not
+// matching the search filters of the arguments.
+func newFilteredMultiWatcher(filter appTypes.ListLogArgs, subWatchers ...appTypes.LogWatcher) *multiWatcher {
 	watcher := &multiWatcher{
 		subWatchers: subWatchers,
+		filter:      filter,
 		ch:          make(chan appTypes.Applog, 1000),
 		close:       make(chan struct{}),
 	}
@@ -184,6 +196,9 @@ func (m *multiWatcher) startConsume(subWatcher appTypes.LogWatcher) {
 			if !open {
 				return
 			}
+			if !m.filter.MatchesSearch(&log) {
+				continue
+			}
 
 			select {
 			case m.ch <- log:
@@ -7,6 +7,7 @@ package applog
 import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
 	appTypes "github.com/tsuru/tsuru/types/app"
 )
 
@@ -26,11 +27,15 @@ func AppLogService() (appTypes.AppLogService, error) {
 		svc, err = memoryAppLogService()
 	case "memory":
 		svc, err = aggregatorAppLogService()
+	case "loki":
+		svc, err = lokiAppLogService()
 	default:
-		return nil, errors.New(`invalid app log service, valid values are: "memory" or "memory-standalone"`)
+		return nil, errors.New(`invalid app log service, valid values are: "memory", "memory-standalone" or "loki"`)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return newProvisionerWrapper(svc), nil
+	dispatcher := newSinkDispatcher(svc)
+	shutdown.Register(dispatcher)
+	return newProvisionerWrapper(dispatcher), nil
 }
@@ -182,6 +182,24 @@ func (s *ServiceSuite) Test_LogService_ListFilterInvert(c *check.C) {
 	c.Check(logs[0].Source, check.Equals, "circus")
 }
 
+func (s *ServiceSuite) Test_LogService_ListSearch(c *check.C) {
+	s.svc.Add("app3", "GET /healthcheck 200", "web", "rdaneel")
+	s.svc.Add("app3", "WARNING: slow query on users", "web", "rdaneel")
+	s.svc.Add("app3", "ERROR: query timeout on users", "web", "rdaneel")
+	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "app3", Query: "USERS"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Check(logs[0].Message, check.Equals, "WARNING: slow query on users")
+	c.Check(logs[1].Message, check.Equals, "ERROR: query timeout on users")
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "app3", Severity: appTypes.LogSeverityError})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Check(logs[0].Message, check.Equals, "ERROR: query timeout on users")
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "app3", Since: time.Now().Add(time.Minute)})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 0)
+}
+
 func (s *ServiceSuite) Test_LogService_ListEmpty(c *check.C) {
 	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{Limit: 10, Name: "myapp", Source: "tsuru"})
 	c.Assert(err, check.IsNil)
@@ -0,0 +1,395 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// LogSinkErrorEventKind is the kind of the events created when the logs of
+// an app can't be forwarded to one of its sinks.
+const LogSinkErrorEventKind = "log-sink-error"
+
+const (
+	logSinkQueueSize          = 10000
+	logSinkBatchSize          = 500
+	logSinkFlushInterval      = time.Second
+	logSinkWriteTimeout       = 30 * time.Second
+	logSinkCacheTTL           = 30 * time.Second
+	logSinkErrorEventInterval = 5 * time.Minute
+)
+
+var (
+	logsSinkSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: "logs_sink",
+		Name:      "sent_total",
+		Help:      "The number of log entries forwarded to the sinks of apps.",
+	}, []string{"type"})
+
+	logsSinkFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: "logs_sink",
+		Name:      "failures_total",
+		Help:      "The number of log entries not forwarded to the sinks of apps due to delivery errors.",
+	}, []string{"type"})
+
+	logsSinkDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: "logs_sink",
+		Name:      "dropped_total",
+		Help:      "The number of log entries not forwarded to the sinks of apps due to a full queue.",
+	})
+)
+
+type logSinkWriteFunc func(ctx context.Context, sink appTypes.LogSink, entries []*appTypes.Applog) error
+
+var logSinkWriters = map[string]logSinkWriteFunc{
+	appTypes.LogSinkTypeSyslog: writeSyslogLogSink,
+	appTypes.LogSinkTypeKafka:  writeKafkaLogSink,
+	appTypes.LogSinkTypeHTTP:   writeHTTPLogSink,
+}
+
+var (
+	_ appTypes.AppLogService         = &sinkDispatcher{}
+	_ appTypes.AppLogServiceInstance = &sinkDispatcher{}
+)
+
+type cachedLogSinks struct {
+	sinks   []appTypes.LogSink
+	expires time.Time
+}
+
+// sinkDispatcher fans out the logs of apps to their sinks, in batches, after
+// storing them in the wrapped log service. Delivery errors are reported as
+// events of the app, at most once every few minutes for each sink.
+type sinkDispatcher struct {
+	logService    appTypes.AppLogService
+	sinksGetter   func(ctx context.Context, appName string) ([]appTypes.LogSink, error)
+	errorNotifier func(ctx context.Context, appName string, sink appTypes.LogSink, err error)
+	queue         chan *appTypes.Applog
+	quit          chan struct{}
+	wg            sync.WaitGroup
+	sinks         map[string]cachedLogSinks
+
+	mu          sync.Mutex
+	lastErrorAt map[string]time.Time
+}
+
+func newSinkDispatcher(logService appTypes.AppLogService) *sinkDispatcher {
+	d := &sinkDispatcher{
+		logService:    logService,
+		sinksGetter:   appLogSinks,
+		errorNotifier: notifyLogSinkError,
+		queue:         make(chan *appTypes.Applog, logSinkQueueSize),
+		quit:          make(chan struct{}),
+		sinks:         map[string]cachedLogSinks{},
+		lastErrorAt:   map[string]time.Time{},
+	}
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+func appLogSinks(ctx context.Context, appName string) ([]appTypes.LogSink, error) {
+	if servicemanager.App == nil {
+		return nil, nil
+	}
+	a, err := servicemanager.App.GetByName(ctx, appName)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	return a.LogSinks, nil
+}
+
+func notifyLogSinkError(ctx context.Context, appName string, sink appTypes.LogSink, sinkErr error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: appName},
+		InternalKind: LogSinkErrorEventKind,
+		DisableLock:  true,
+		CustomData:   map[string]string{"name": sink.Name, "type": sink.Type},
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, appName)),
+	})
+	if err != nil {
+		log.Errorf("[log sink] unable to create event for app %q: %v", appName, err)
+		return
+	}
+	evt.Done(ctx, errors.Wrapf(sinkErr, "unable to forward logs to sink %q", sink.Name))
+}
+
+func (d *sinkDispatcher) Enqueue(entry *appTypes.Applog) error {
+	err := d.logService.Enqueue(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Type != "" && entry.Type != logTypes.LogTypeApp {
+		return nil
+	}
+	select {
+	case d.queue <- entry:
+	default:
+		logsSinkDropped.Inc()
+	}
+	return nil
+}
+
+func (d *sinkDispatcher) Add(appName, message, source, unit string) error {
+	for _, entry := range newLogEntries(appName, message, source, unit) {
+		err := d.Enqueue(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *sinkDispatcher) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	return d.logService.List(ctx, args)
+}
+
+func (d *sinkDispatcher) Watch(ctx context.Context, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
+	return d.logService.Watch(ctx, args)
+}
+
+func (d *sinkDispatcher) Instance() appTypes.AppLogService {
+	if svcInstance, ok := d.logService.(appTypes.AppLogServiceInstance); ok {
+		return svcInstance.Instance()
+	}
+	return d.logService
+}
+
+func (d *sinkDispatcher) Shutdown(ctx context.Context) error {
+	close(d.quit)
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (d *sinkDispatcher) run() {
+	defer d.wg.Done()
+	pending := map[string][]*appTypes.Applog{}
+	var size int
+	ticker := time.NewTicker(logSinkFlushInterval)
+	defer ticker.Stop()
+	add := func(entry *appTypes.Applog) {
+		pending[entry.Name] = append(pending[entry.Name], entry)
+		size++
+	}
+	flush := func() {
+		if size == 0 {
+			return
+		}
+		d.flush(pending)
+		pending = map[string][]*appTypes.Applog{}
+		size = 0
+	}
+	for {
+		select {
+		case entry := <-d.queue:
+			add(entry)
+			if size >= logSinkBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-d.quit:
+			for {
+				select {
+				case entry := <-d.queue:
+					add(entry)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *sinkDispatcher) appSinks(ctx context.Context, appName string) []appTypes.LogSink {
+	now := time.Now()
+	if cached, ok := d.sinks[appName]; ok && now.Before(cached.expires) {
+		return cached.sinks
+	}
+	sinks, err := d.sinksGetter(ctx, appName)
+	if err != nil {
+		log.Debugf("[log sink] unable to get sinks for app %q: %v", appName, err)
+	}
+	d.sinks[appName] = cachedLogSinks{sinks: sinks, expires: now.Add(logSinkCacheTTL)}
+	return sinks
+}
+
+func (d *sinkDispatcher) flush(pending map[string][]*appTypes.Applog) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for appName, entries := range pending {
+		for _, sink := range d.appSinks(ctx, appName) {
+			writeFunc, ok := logSinkWriters[sink.Type]
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func(appName string, sink appTypes.LogSink, entries []*appTypes.Applog) {
+				defer wg.Done()
+				writeCtx, cancel := context.WithTimeout(ctx, logSinkWriteTimeout)
+				defer cancel()
+				err := writeFunc(writeCtx, sink, entries)
+				if err == nil {
+					logsSinkSent.WithLabelValues(sink.Type).Add(float64(len(entries)))
+					return
+				}
+				logsSinkFailures.WithLabelValues(sink.Type).Add(float64(len(entries)))
+				if d.shouldNotifyError(appName, sink.Name) {
+					d.errorNotifier(ctx, appName, sink, err)
+				}
+			}(appName, sink, entries)
+		}
+	}
+	wg.Wait()
+}
+
+func (d *sinkDispatcher) shouldNotifyError(appName, sinkName string) bool {
+	key := appName + "/" + sinkName
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, ok := d.lastErrorAt[key]; ok && now.Sub(last) < logSinkErrorEventInterval {
+		return false
+	}
+	d.lastErrorAt[key] = now
+	return true
+}
+
+func syslogNilValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// syslogMessage formats the entry as a RFC 5424 message from the user
+// facility, with the unit as hostname and the source as process ID.
+func syslogMessage(entry *appTypes.Applog) string {
+	severity := 6
+	switch appTypes.LogSeverity(entry.Message) {
+	case appTypes.LogSeverityError:
+		severity = 3
+	case appTypes.LogSeverityWarning:
+		severity = 4
+	case appTypes.LogSeverityDebug:
+		severity = 7
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %s - - %s\n",
+		8+severity,
+		entry.Date.UTC().Format(time.RFC3339Nano),
+		syslogNilValue(entry.Unit),
+		syslogNilValue(entry.Name),
+		syslogNilValue(entry.Source),
+		entry.Message,
+	)
+}
+
+func writeSyslogLogSink(ctx context.Context, sink appTypes.LogSink, entries []*appTypes.Applog) error {
+	u, err := url.Parse(sink.URL)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, u.Scheme, u.Host)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	for _, entry := range entries {
+		_, err = io.WriteString(conn, syslogMessage(entry))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// writeKafkaLogSink produces the entries to the topic of the sink through a
+// Kafka REST proxy, keyed by the app name.
+func writeKafkaLogSink(ctx context.Context, sink appTypes.LogSink, entries []*appTypes.Applog) error {
+	type kafkaRecord struct {
+		Key   string           `json:"key"`
+		Value *appTypes.Applog `json:"value"`
+	}
+	payload := struct {
+		Records []kafkaRecord `json:"records"`
+	}{}
+	for _, entry := range entries {
+		payload.Records = append(payload.Records, kafkaRecord{Key: entry.Name, Value: entry})
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	u, err := url.JoinPath(sink.URL, "topics", sink.Topic)
+	if err != nil {
+		return err
+	}
+	return postLogSink(ctx, u, "application/vnd.kafka.json.v2+json", data)
+}
+
+func writeHTTPLogSink(ctx context.Context, sink appTypes.LogSink, entries []*appTypes.Applog) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return postLogSink(ctx, sink.URL, "application/json", data)
+}
+
+func postLogSink(ctx context.Context, address, contentType string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code %v: %q", rsp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSyslogMessage(c *check.C) {
+	date := time.Date(2026, 3, 1, 10, 20, 30, 0, time.UTC)
+	c.Assert(syslogMessage(&appTypes.Applog{Date: date, Message: "GET / 200", Source: "web", Name: "myapp", Unit: "myapp-web-1"}),
+		check.Equals, "<14>1 2026-03-01T10:20:30Z myapp-web-1 myapp web - - GET / 200\n")
+	c.Assert(syslogMessage(&appTypes.Applog{Date: date, Message: "ERROR: timeout", Source: "tsuru", Name: "myapp"}),
+		check.Equals, "<11>1 2026-03-01T10:20:30Z - myapp tsuru - - ERROR: timeout\n")
+}
+
+func (s *S) TestWriteSyslogLogSink(c *check.C) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	sink := appTypes.LogSink{Name: "syslog", Type: appTypes.LogSinkTypeSyslog, URL: "udp://" + conn.LocalAddr().String()}
+	err = writeSyslogLogSink(context.TODO(), sink, []*appTypes.Applog{
+		{Date: time.Now(), Message: "line 1", Source: "web", Name: "myapp"},
+		{Date: time.Now(), Message: "line 2", Source: "web", Name: "myapp"},
+	})
+	c.Assert(err, check.IsNil)
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var messages []string
+	for i := 0; i < 2; i++ {
+		n, _, err := conn.ReadFrom(buf)
+		c.Assert(err, check.IsNil)
+		messages = append(messages, string(buf[:n]))
+	}
+	c.Assert(strings.HasSuffix(messages[0], " myapp web - - line 1\n"), check.Equals, true, check.Commentf("%q", messages[0]))
+	c.Assert(strings.HasSuffix(messages[1], " myapp web - - line 2\n"), check.Equals, true, check.Commentf("%q", messages[1]))
+}
+
+func (s *S) TestWriteKafkaLogSink(c *check.C) {
+	var path, contentType string
+	var payload struct {
+		Records []struct {
+			Key   string          `json:"key"`
+			Value appTypes.Applog `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+	sink := appTypes.LogSink{Name: "kafka", Type: appTypes.LogSinkTypeKafka, URL: server.URL, Topic: "app-logs"}
+	err := writeKafkaLogSink(context.TODO(), sink, []*appTypes.Applog{{Message: "hello", Source: "web", Name: "myapp"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.Equals, "/topics/app-logs")
+	c.Assert(contentType, check.Equals, "application/vnd.kafka.json.v2+json")
+	c.Assert(payload.Records, check.HasLen, 1)
+	c.Assert(payload.Records[0].Key, check.Equals, "myapp")
+	c.Assert(payload.Records[0].Value.Message, check.Equals, "hello")
+}
+
+func (s *S) TestWriteHTTPLogSinkError(c *check.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "overloaded")
+	}))
+	defer server.Close()
+	sink := appTypes.LogSink{Name: "collector", Type: appTypes.LogSinkTypeHTTP, URL: server.URL}
+	err := writeHTTPLogSink(context.TODO(), sink, []*appTypes.Applog{{Message: "hello", Name: "myapp"}})
+	c.Assert(err, check.ErrorMatches, `invalid status code 503: "overloaded"`)
+}
+
+func (s *S) TestSinkDispatcher(c *check.C) {
+	var mu sync.Mutex
+	var received []appTypes.Applog
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entries []appTypes.Applog
+		err := json.NewDecoder(r.Body).Decode(&entries)
+		c.Check(err, check.IsNil)
+		mu.Lock()
+		received = append(received, entries...)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	memoryService, err := memoryAppLogService()
+	c.Assert(err, check.IsNil)
+	var lookups []string
+	var notified []string
+	d := newSinkDispatcher(memoryService)
+	d.sinksGetter = func(ctx context.Context, appName string) ([]appTypes.LogSink, error) {
+		lookups = append(lookups, appName)
+		if appName != "myapp" {
+			return nil, nil
+		}
+		return []appTypes.LogSink{
+			{Name: "collector", Type: appTypes.LogSinkTypeHTTP, URL: server.URL},
+			{Name: "broken", Type: appTypes.LogSinkTypeHTTP, URL: "http://127.0.0.1:1"},
+		}, nil
+	}
+	d.errorNotifier = func(ctx context.Context, appName string, sink appTypes.LogSink, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, appName+"/"+sink.Name)
+	}
+	err = d.Add("myapp", "line 1\nline 2", "web", "u1")
+	c.Assert(err, check.IsNil)
+	err = d.Add("otherapp", "other line", "web", "u2")
+	c.Assert(err, check.IsNil)
+	err = d.Enqueue(&appTypes.Applog{Name: "myapp", Message: "job line", Type: "job"})
+	c.Assert(err, check.IsNil)
+	err = d.Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	logs, err := d.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 3)
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(received, check.HasLen, 2)
+	c.Assert(received[0].Message, check.Equals, "line 1")
+	c.Assert(received[1].Message, check.Equals, "line 2")
+	c.Assert(notified, check.DeepEquals, []string{"myapp/broken"})
+	c.Assert(lookups, check.HasLen, 2)
+}
+
+func (s *S) TestSinkDispatcherThrottlesErrorEvents(c *check.C) {
+	d := &sinkDispatcher{lastErrorAt: map[string]time.Time{}}
+	c.Assert(d.shouldNotifyError("myapp", "broken"), check.Equals, true)
+	c.Assert(d.shouldNotifyError("myapp", "broken"), check.Equals, false)
+	c.Assert(d.shouldNotifyError("myapp", "other"), check.Equals, true)
+	d.lastErrorAt["myapp/broken"] = time.Now().Add(-logSinkErrorEventInterval)
+	c.Assert(d.shouldNotifyError("myapp", "broken"), check.Equals, true)
+}
+
+func (s *S) TestSinkDispatcherCachesSinks(c *check.C) {
+	calls := 0
+	d := &sinkDispatcher{
+		sinks: map[string]cachedLogSinks{},
+		sinksGetter: func(ctx context.Context, appName string) ([]appTypes.LogSink, error) {
+			calls++
+			return nil, errors.New("app not found")
+		},
+	}
+	c.Assert(d.appSinks(context.TODO(), "myapp"), check.HasLen, 0)
+	c.Assert(d.appSinks(context.TODO(), "myapp"), check.HasLen, 0)
+	c.Assert(calls, check.Equals, 1)
+	d.sinks["myapp"] = cachedLogSinks{expires: time.Now().Add(-time.Second)}
+	c.Assert(d.appSinks(context.TODO(), "myapp"), check.HasLen, 0)
+	c.Assert(calls, check.Equals, 2)
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package directory provides the groups of external user directories, whose
+// memberships are synchronized to tsuru users so roles assigned to groups
+// follow the directory.
+package directory
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const defaultSyncInterval = 10 * time.Minute
+
+var ErrNotConfigured = errors.New("group sync is not configured")
+
+// Directory is a source of groups and their members.
+type Directory interface {
+	// Groups returns the groups in the directory mapped to the emails of
+	// their members.
+	Groups(ctx context.Context) (map[string][]string, error)
+}
+
+// FromConfig returns the directory configured in auth:group-sync,
+// ErrNotConfigured when group sync is disabled.
+func FromConfig() (Directory, error) {
+	provider, _ := config.GetString("auth:group-sync:provider")
+	switch provider {
+	case "":
+		return nil, ErrNotConfigured
+	case "scim":
+		return scimFromConfig()
+	}
+	return nil, errors.Errorf("unknown group sync provider %q", provider)
+}
+
+// SyncInterval returns the interval between group synchronizations.
+func SyncInterval() time.Duration {
+	interval, _ := config.GetDuration("auth:group-sync:interval")
+	if interval <= 0 {
+		return defaultSyncInterval
+	}
+	return interval
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("auth:group-sync")
+}
+
+func (s *S) TestFromConfig(c *check.C) {
+	_, err := FromConfig()
+	c.Assert(err, check.Equals, ErrNotConfigured)
+	config.Set("auth:group-sync:provider", "ldap")
+	_, err = FromConfig()
+	c.Assert(err, check.ErrorMatches, `unknown group sync provider "ldap"`)
+	config.Set("auth:group-sync:provider", "scim")
+	_, err = FromConfig()
+	c.Assert(err, check.ErrorMatches, `auth:group-sync:scim:url is required .*`)
+	config.Set("auth:group-sync:scim:url", "https://directory.example.com/scim/v2/")
+	config.Set("auth:group-sync:scim:token", "secret")
+	dir, err := FromConfig()
+	c.Assert(err, check.IsNil)
+	scim := dir.(*scimDirectory)
+	c.Assert(scim.url, check.Equals, "https://directory.example.com/scim/v2")
+	c.Assert(scim.token, check.Equals, "secret")
+}
+
+func (s *S) TestSyncInterval(c *check.C) {
+	c.Assert(SyncInterval(), check.Equals, defaultSyncInterval)
+	config.Set("auth:group-sync:interval", "1m")
+	c.Assert(SyncInterval(), check.Equals, time.Minute)
+}
+
+func (s *S) TestSCIMGroups(c *check.C) {
+	users := []map[string]interface{}{
+		{"id": "1", "userName": "alice", "emails": []map[string]interface{}{
+			{"value": "alice@personal.com"},
+			{"value": "alice@corp.com", "primary": true},
+		}},
+		{"id": "2", "userName": "bob@corp.com"},
+		{"id": "3", "userName": "carol@corp.com", "active": false},
+		{"id": "4", "userName": "dave"},
+	}
+	groups := []map[string]interface{}{
+		{"displayName": "developers", "members": []map[string]string{{"value": "1"}, {"value": "2"}, {"value": "3"}}},
+		{"displayName": "empty"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get("Authorization"), check.Equals, "Bearer secret")
+		resources := users
+		if r.URL.Path == "/scim/Groups" {
+			resources = groups
+		}
+		// pages of a single resource to exercise pagination.
+		startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		page := resources[startIndex-1 : startIndex]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"totalResults": len(resources),
+			"Resources":    page,
+		})
+	}))
+	defer srv.Close()
+	dir := &scimDirectory{url: srv.URL + "/scim", token: "secret", client: http.DefaultClient}
+	result, err := dir.Groups(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string][]string{
+		"developers": {"alice@corp.com", "bob@corp.com"},
+		"empty":      {},
+	})
+}
+
+func (s *S) TestSCIMGroupsError(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("invalid token"))
+	}))
+	defer srv.Close()
+	dir := &scimDirectory{url: srv.URL, client: http.DefaultClient}
+	_, err := dir.Groups(context.TODO())
+	c.Assert(err, check.ErrorMatches, "unable to list scim Users, status code 401: invalid token")
+}
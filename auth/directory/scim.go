@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const scimPageSize = 100
+
+// scimDirectory reads groups from a SCIM 2.0 service provider, which most
+// identity providers and LDAP gateways expose.
+type scimDirectory struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type scimListResponse struct {
+	TotalResults int               `json:"totalResults"`
+	Resources    []json.RawMessage `json:"Resources"`
+}
+
+type scimUser struct {
+	ID       string `json:"id"`
+	UserName string `json:"userName"`
+	Active   *bool  `json:"active"`
+	Emails   []struct {
+		Value   string `json:"value"`
+		Primary bool   `json:"primary"`
+	} `json:"emails"`
+}
+
+type scimGroup struct {
+	DisplayName string `json:"displayName"`
+	Members     []struct {
+		Value string `json:"value"`
+	} `json:"members"`
+}
+
+func scimFromConfig() (Directory, error) {
+	url, _ := config.GetString("auth:group-sync:scim:url")
+	if url == "" {
+		return nil, errors.New("auth:group-sync:scim:url is required for the scim provider")
+	}
+	token, _ := config.GetString("auth:group-sync:scim:token")
+	return &scimDirectory{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: tsuruNet.Dial15Full60ClientNoKeepAlive,
+	}, nil
+}
+
+func (u *scimUser) email() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	if strings.Contains(u.UserName, "@") {
+		return u.UserName
+	}
+	return ""
+}
+
+func (d *scimDirectory) Groups(ctx context.Context) (map[string][]string, error) {
+	emails := map[string]string{}
+	err := d.list(ctx, "Users", func(data json.RawMessage) error {
+		var user scimUser
+		if err := json.Unmarshal(data, &user); err != nil {
+			return err
+		}
+		// inactive users are left out of every group.
+		if user.Active != nil && !*user.Active {
+			return nil
+		}
+		if email := user.email(); email != "" {
+			emails[user.ID] = email
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]string{}
+	err = d.list(ctx, "Groups", func(data json.RawMessage) error {
+		var group scimGroup
+		if err := json.Unmarshal(data, &group); err != nil {
+			return err
+		}
+		if group.DisplayName == "" {
+			return nil
+		}
+		members := []string{}
+		for _, member := range group.Members {
+			if email, ok := emails[member.Value]; ok {
+				members = append(members, email)
+			}
+		}
+		groups[group.DisplayName] = members
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (d *scimDirectory) list(ctx context.Context, resource string, fn func(json.RawMessage) error) error {
+	startIndex := 1
+	for {
+		page, err := d.listPage(ctx, resource, startIndex)
+		if err != nil {
+			return err
+		}
+		for _, data := range page.Resources {
+			if err = fn(data); err != nil {
+				return errors.Wrapf(err, "unable to decode scim %s", resource)
+			}
+		}
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			return nil
+		}
+	}
+}
+
+func (d *scimDirectory) listPage(ctx context.Context, resource string, startIndex int) (*scimListResponse, error) {
+	url := fmt.Sprintf("%s/%s?startIndex=%d&count=%d", d.url, resource, startIndex, scimPageSize)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/scim+json")
+	if d.token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.token)
+	}
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(rsp.Body)
+		return nil, errors.Errorf("unable to list scim %s, status code %d: %s", resource, rsp.StatusCode, string(body))
+	}
+	var page scimListResponse
+	err = json.NewDecoder(rsp.Body).Decode(&page)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decode scim %s", resource)
+	}
+	return &page, nil
+}
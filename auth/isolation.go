@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+const isolationProfilesKey = "isolation-profiles"
+
+// ListIsolationProfiles returns the isolation profiles defined in the config,
+// sorted by name.
+func ListIsolationProfiles() ([]authTypes.IsolationProfile, error) {
+	data, err := config.Get(isolationProfilesKey)
+	if err != nil {
+		return nil, nil
+	}
+	raw, ok := data.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s must be a map of profile names to profiles", isolationProfilesKey)
+	}
+	var profiles []authTypes.IsolationProfile
+	for name := range raw {
+		profile, err := GetIsolationProfile(fmt.Sprint(name))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// GetIsolationProfile returns the isolation profile defined in the config
+// under isolation-profiles:<name>.
+func GetIsolationProfile(name string) (*authTypes.IsolationProfile, error) {
+	key := fmt.Sprintf("%s:%s", isolationProfilesKey, name)
+	if name == "" {
+		return nil, authTypes.ErrIsolationProfileNotFound
+	}
+	if _, err := config.Get(key); err != nil {
+		return nil, authTypes.ErrIsolationProfileNotFound
+	}
+	profile := authTypes.IsolationProfile{Name: name}
+	profile.DedicatedNamespace, _ = config.GetBool(key + ":dedicated-namespace")
+	profile.Registry, _ = config.GetString(key + ":registry")
+	profile.StrictNetworkPolicy, _ = config.GetBool(key + ":strict-network-policy")
+	var err error
+	profile.SchedulingConstraints, err = configStringMap(key + ":scheduling-constraints")
+	if err != nil {
+		return nil, err
+	}
+	profile.IngressNamespaceLabels, err = configStringMap(key + ":ingress-namespace-labels")
+	if err != nil {
+		return nil, err
+	}
+	profile.EgressNamespaceLabels, err = configStringMap(key + ":egress-namespace-labels")
+	if err != nil {
+		return nil, err
+	}
+	if profile.StrictNetworkPolicy && !profile.DedicatedNamespace {
+		return nil, errors.Errorf("isolation profile %q: strict-network-policy requires dedicated-namespace", name)
+	}
+	return &profile, nil
+}
+
+// TeamIsolationProfile returns the isolation profile assigned to the team, or
+// nil when the team has none.
+func TeamIsolationProfile(ctx context.Context, teamName string) (*authTypes.IsolationProfile, error) {
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if team.IsolationProfile == "" {
+		return nil, nil
+	}
+	return GetIsolationProfile(team.IsolationProfile)
+}
+
+func configStringMap(key string) (map[string]string, error) {
+	data, err := config.Get(key)
+	if err != nil {
+		return nil, nil
+	}
+	raw, ok := data.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s must be a map", key)
+	}
+	result := make(map[string]string, len(raw))
+	for k, v := range raw {
+		result[fmt.Sprint(k)] = fmt.Sprint(v)
+	}
+	return result, nil
+}
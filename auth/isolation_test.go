@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestListIsolationProfiles(c *check.C) {
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	config.Set("isolation-profiles:regulated:strict-network-policy", true)
+	config.Set("isolation-profiles:regulated:registry", "registry.example.com/regulated")
+	config.Set("isolation-profiles:regulated:scheduling-constraints", map[interface{}]interface{}{"dedicated": "regulated"})
+	config.Set("isolation-profiles:regulated:ingress-namespace-labels", map[interface{}]interface{}{"name": "ingress"})
+	config.Set("isolation-profiles:regulated:egress-namespace-labels", map[interface{}]interface{}{"name": "shared"})
+	config.Set("isolation-profiles:basic:registry", "registry.example.com/basic")
+	defer config.Unset("isolation-profiles")
+	profiles, err := ListIsolationProfiles()
+	c.Assert(err, check.IsNil)
+	c.Assert(profiles, check.DeepEquals, []authTypes.IsolationProfile{
+		{Name: "basic", Registry: "registry.example.com/basic"},
+		{
+			Name:                   "regulated",
+			DedicatedNamespace:     true,
+			StrictNetworkPolicy:    true,
+			Registry:               "registry.example.com/regulated",
+			SchedulingConstraints:  map[string]string{"dedicated": "regulated"},
+			IngressNamespaceLabels: map[string]string{"name": "ingress"},
+			EgressNamespaceLabels:  map[string]string{"name": "shared"},
+		},
+	})
+}
+
+func (s *S) TestGetIsolationProfileStrictNetworkPolicyRequiresNamespace(c *check.C) {
+	config.Set("isolation-profiles:regulated:strict-network-policy", true)
+	defer config.Unset("isolation-profiles")
+	_, err := GetIsolationProfile("regulated")
+	c.Assert(err, check.ErrorMatches, `isolation profile "regulated": strict-network-policy requires dedicated-namespace`)
+	_, err = GetIsolationProfile("unknown")
+	c.Assert(err, check.Equals, authTypes.ErrIsolationProfileNotFound)
+}
+
+func (s *S) TestTeamServiceSetIsolationProfile(c *check.C) {
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	defer config.Unset("isolation-profiles")
+	var updated authTypes.Team
+	ts := &teamService{
+		storage: &authTypes.MockTeamStorage{
+			OnFindByName: func(name string) (*authTypes.Team, error) {
+				return &authTypes.Team{Name: name}, nil
+			},
+			OnUpdate: func(t authTypes.Team) error {
+				updated = t
+				return nil
+			},
+		},
+	}
+	err := ts.SetIsolationProfile(context.TODO(), "team1", "regulated")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated, check.DeepEquals, authTypes.Team{Name: "team1", IsolationProfile: "regulated"})
+	err = ts.SetIsolationProfile(context.TODO(), "team1", "unknown")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = ts.SetIsolationProfile(context.TODO(), "team1", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.IsolationProfile, check.Equals, "")
+}
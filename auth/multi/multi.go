@@ -16,8 +16,9 @@ import (
 )
 
 var (
-	_ auth.Scheme      = &multiScheme{}
-	_ auth.MultiScheme = &multiScheme{}
+	_ auth.Scheme            = &multiScheme{}
+	_ auth.MultiScheme       = &multiScheme{}
+	_ auth.RefreshableScheme = &multiScheme{}
 )
 
 func init() {
@@ -265,6 +266,37 @@ func (s *multiScheme) Remove(ctx context.Context, user *auth.User) error {
 	return newErrNotImplemented("remove")
 }
 
+func (s *multiScheme) Refresh(ctx context.Context, refreshToken string) (auth.Token, error) {
+	schemes, err := s.schemes()
+	if err != nil {
+		return nil, err
+	}
+
+	errors := tsuruErrors.NewMultiError()
+	for _, scheme := range schemes {
+		refreshableScheme, ok := scheme.(auth.RefreshableScheme)
+		if !ok {
+			continue
+		}
+		authToken, err := refreshableScheme.Refresh(ctx, refreshToken)
+
+		if err != nil {
+			errors.Add(err)
+			continue
+		}
+
+		if authToken != nil {
+			return authToken, nil
+		}
+	}
+
+	if errors.Len() > 0 {
+		return nil, errors.ToError()
+	}
+
+	return nil, newErrNotImplemented("refresh")
+}
+
 func (s *multiScheme) schemes() ([]auth.Scheme, error) {
 	schemes := s.cachedSchemes.Load()
 	if schemes == nil {
@@ -213,6 +213,68 @@ func (s *MultiSuite) TestInfos(c *check.C) {
 
 }
 
+func (s *MultiSuite) TestRefresh(c *check.C) {
+	type testCase struct {
+		desc          string
+		schemes       []auth.Scheme
+		expectedError string
+		expectedToken string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "skip schemes without refresh",
+			schemes: []auth.Scheme{
+				&nonUserScheme{},
+				&fakeScheme{
+					refresh: func(refreshToken string) (auth.Token, error) {
+						return fakeToken("token-" + refreshToken), nil
+					},
+				},
+			},
+			expectedToken: "token-refresh01",
+		},
+		{
+			desc: "fail on all schemes",
+			schemes: []auth.Scheme{
+				&fakeScheme{
+					refresh: func(refreshToken string) (auth.Token, error) {
+						return nil, errors.New("failed to refresh on scheme01")
+					},
+				},
+			},
+			expectedError: "failed to refresh on scheme01",
+		},
+		{
+			desc: "no schemes implements refresh",
+			schemes: []auth.Scheme{
+				&nonUserScheme{},
+			},
+			expectedError: "refresh is not implemented by any schemes",
+		},
+	}
+
+	for _, testCase := range testCases {
+		scheme := &multiScheme{
+			cachedSchemes: atomic.Pointer[[]auth.Scheme]{},
+		}
+		scheme.cachedSchemes.Store(&testCase.schemes)
+		token, err := scheme.Refresh(context.TODO(), "refresh01")
+
+		if testCase.expectedError != "" {
+			c.Check(err, check.ErrorMatches, testCase.expectedError, check.Commentf(testCase.desc))
+			c.Check(token, check.IsNil)
+		}
+
+		if testCase.expectedToken != "" {
+			c.Check(err, check.IsNil)
+			if c.Check(token, check.Not(check.IsNil)) {
+				c.Assert(token.GetValue(), check.Equals, testCase.expectedToken)
+			}
+		}
+	}
+}
+
 func (s *MultiSuite) TestLogout(c *check.C) {
 	type testCase struct {
 		desc          string
@@ -579,6 +641,8 @@ type fakeScheme struct {
 	info   func() (*authTypes.SchemeInfo, error)
 	create func(u *auth.User) (*auth.User, error)
 	remove func(u *auth.User) error
+
+	refresh func(refreshToken string) (auth.Token, error)
 }
 
 func (t *fakeScheme) Login(ctx context.Context, params map[string]string) (auth.Token, error) {
@@ -621,6 +685,24 @@ func (t *fakeScheme) Remove(ctx context.Context, u *auth.User) error {
 	return nil
 }
 
+func (t *fakeScheme) Refresh(ctx context.Context, refreshToken string) (auth.Token, error) {
+	if t.refresh != nil {
+		return t.refresh(refreshToken)
+	}
+	return nil, nil
+}
+
+// nonUserScheme only authenticates tokens.
+type nonUserScheme struct{}
+
+func (t *nonUserScheme) Auth(ctx context.Context, token string) (auth.Token, error) {
+	return nil, nil
+}
+
+func (t *nonUserScheme) Info(ctx context.Context) (*authTypes.SchemeInfo, error) {
+	return nil, nil
+}
+
 type fakeToken string
 
 func (t fakeToken) GetValue() string {
@@ -0,0 +1,249 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/secret"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"golang.org/x/oauth2"
+)
+
+var (
+	errMissingCode         = &tsuruErrors.ValidationError{Message: "You must provide code to login"}
+	errMissingRedirectURL  = &tsuruErrors.ValidationError{Message: "You must provide the used redirect url to login"}
+	errMissingIDToken      = &tsuruErrors.NotAuthorizedError{Message: "The identity provider did not return an ID token"}
+	errInvalidRefreshToken = auth.AuthenticationFailure{Message: "Invalid refresh token"}
+	errRefreshTokenReused  = auth.AuthenticationFailure{Message: "Refresh token already used, the session was revoked"}
+
+	_ auth.UserScheme        = &oidcScheme{}
+	_ auth.RefreshableScheme = &oidcScheme{}
+)
+
+func (s *oidcScheme) oauth2Config() (oauth2.Config, error) {
+	var emptyConfig oauth2.Config
+	clientID, err := config.GetString("auth:oidc:client-id")
+	if err != nil {
+		return emptyConfig, err
+	}
+	clientSecret, _ := config.GetString("auth:oidc:client-secret")
+	scopes, err := config.GetList("auth:oidc:scopes")
+	if err != nil {
+		return emptyConfig, err
+	}
+	authURL, err := config.GetString("auth:oidc:auth-url")
+	if err != nil {
+		return emptyConfig, err
+	}
+	tokenURL, err := config.GetString("auth:oidc:token-url")
+	if err != nil {
+		return emptyConfig, err
+	}
+	return oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
+		},
+	}, nil
+}
+
+func tracedClientContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, tsuruNet.Dial15Full60ClientWithPool)
+}
+
+// Login exchanges the authorization code, issued to the redirect url, for the
+// tokens of the identity provider. The ID token is the tsuru token, while
+// the refresh token of the provider is kept in a session renewed with
+// rotating refresh tokens.
+func (s *oidcScheme) Login(ctx context.Context, params map[string]string) (auth.Token, error) {
+	err := s.lazyInitialize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	code := params["code"]
+	if code == "" {
+		return nil, errMissingCode
+	}
+	redirectURL := params["redirectUrl"]
+	if redirectURL == "" {
+		return nil, errMissingRedirectURL
+	}
+	conf, err := s.oauth2Config()
+	if err != nil {
+		return nil, err
+	}
+	conf.RedirectURL = redirectURL
+	var opts []oauth2.AuthCodeOption
+	if verifier := params["code_verifier"]; verifier != "" {
+		opts = append(opts, oauth2.VerifierOption(verifier))
+	}
+	providerToken, err := conf.Exchange(tracedClientContext(ctx), code, opts...)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.tokenFromProvider(ctx, &conf, providerToken)
+	if err != nil {
+		return nil, err
+	}
+	if providerToken.RefreshToken != "" {
+		token.RefreshToken, err = createSession(ctx, token.GetUserName(), providerToken.RefreshToken, token.Raw, s.sessionMaxAge)
+		if err == secret.ErrMasterKeyNotConfigured {
+			log.Errorf("[oidc] unable to keep the session of %q, its refresh token must be sealed with secrets:master-key", token.GetUserName())
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// Refresh renews the session of the refresh token at the identity provider,
+// returning a new ID token and replacing the refresh token. Using a refresh
+// token already replaced revokes its session, as it may have leaked.
+func (s *oidcScheme) Refresh(ctx context.Context, refreshToken string) (auth.Token, error) {
+	err := s.lazyInitialize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if refreshToken == "" {
+		return nil, errInvalidRefreshToken
+	}
+	refreshTokenHash := hashToken(refreshToken)
+	sess, err := findSession(ctx, refreshTokenHash)
+	if err == errSessionNotFound {
+		return nil, s.revokeReused(ctx, refreshTokenHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(sess.ExpiresAt) {
+		return nil, removeSessionOrFail(ctx, sess.ID)
+	}
+	conf, err := s.oauth2Config()
+	if err != nil {
+		return nil, err
+	}
+	providerRefreshToken, err := sess.providerRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	providerToken, err := conf.TokenSource(tracedClientContext(ctx), &oauth2.Token{RefreshToken: providerRefreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.tokenFromProvider(ctx, &conf, providerToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.GetUserName() != sess.Email {
+		return nil, removeSessionOrFail(ctx, sess.ID)
+	}
+	token.RefreshToken, err = rotateSession(ctx, sess, providerToken.RefreshToken, token.Raw)
+	if err == errSessionNotFound {
+		return nil, s.revokeReused(ctx, refreshTokenHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *oidcScheme) revokeReused(ctx context.Context, refreshTokenHash string) error {
+	revoked, err := revokeReusedSession(ctx, refreshTokenHash)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errRefreshTokenReused
+	}
+	return errInvalidRefreshToken
+}
+
+func removeSessionOrFail(ctx context.Context, id string) error {
+	err := removeSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	return errInvalidRefreshToken
+}
+
+// tokenFromProvider validates the ID token returned by the identity
+// provider, which must have been issued to tsuru, and applies the role
+// mappings to its user.
+func (s *oidcScheme) tokenFromProvider(ctx context.Context, conf *oauth2.Config, providerToken *oauth2.Token) (*jwtToken, error) {
+	rawIDToken, _ := providerToken.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errMissingIDToken
+	}
+	identity, err := s.parseIdentity(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	audience, err := identity.MapClaims.GetAudience()
+	if err != nil {
+		return nil, err
+	}
+	var issuedToClient bool
+	for _, aud := range audience {
+		issuedToClient = issuedToClient || aud == conf.ClientID
+	}
+	if !issuedToClient {
+		return nil, &errInvalidClaim{"aud"}
+	}
+	user, err := s.userFromIdentity(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	s.applyRoleMappings(ctx, user, identity)
+	authUser, _ := auth.ConvertOldUser(user, nil)
+	return &jwtToken{
+		AuthUser: authUser,
+		Identity: identity,
+		Raw:      rawIDToken,
+	}, nil
+}
+
+// Logout revokes the session of the ID token. Since the token is
+// stateless, it remains valid until expired.
+func (s *oidcScheme) Logout(ctx context.Context, token string) error {
+	err := s.lazyInitialize(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(token, "Bearer ") || strings.HasPrefix(token, "bearer ") {
+		token = token[len("Bearer "):]
+	}
+	_, err = s.parseIdentity(ctx, token)
+	if err != nil {
+		return auth.ErrInvalidToken
+	}
+	return removeSessionsByIDToken(ctx, token)
+}
+
+func (s *oidcScheme) Create(ctx context.Context, user *auth.User) (*auth.User, error) {
+	user.Password = ""
+	err := user.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *oidcScheme) Remove(ctx context.Context, user *auth.User) error {
+	err := removeUserSessions(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	return user.Delete(ctx)
+}
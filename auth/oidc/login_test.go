@@ -0,0 +1,277 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+type fakeProvider struct {
+	server          *httptest.Server
+	key             *rsa.PrivateKey
+	kid             string
+	audience        string
+	email           string
+	groups          []string
+	emailUnverified bool
+	refreshToken    string
+	refreshRequests int
+}
+
+func (s *AuthSuite) newFakeProvider(c *check.C, email string) *fakeProvider {
+	p := &fakeProvider{kid: "rsa-provider-" + email, audience: "tsuru", email: email, refreshToken: "provider-refresh-0"}
+	var err error
+	p.key, err = s.generateNewPrivateRSAKey(p.kid)
+	c.Assert(err, check.IsNil)
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "valid-code" || r.Form.Get("redirect_uri") != "http://localhost:8080/callback" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != p.refreshToken {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			p.refreshRequests++
+			p.refreshToken = fmt.Sprintf("provider-refresh-%d", p.refreshRequests)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"email":          p.email,
+			"email_verified": !p.emailUnverified,
+			"groups":         p.groups,
+			"aud":            p.audience,
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = p.kid
+		idToken, err := token.SignedString(p.key)
+		c.Check(err, check.IsNil)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-token",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": p.refreshToken,
+			"id_token":      idToken,
+		})
+	}))
+	config.Set("auth:oidc:client-id", "tsuru")
+	config.Set("auth:oidc:client-secret", "secret")
+	config.Set("auth:oidc:scopes", []string{"openid", "email", "offline_access"})
+	config.Set("auth:oidc:auth-url", p.server.URL+"/authorize")
+	config.Set("auth:oidc:token-url", p.server.URL+"/token")
+	return p
+}
+
+func (p *fakeProvider) Close() {
+	p.server.Close()
+	config.Unset("auth:oidc:client-id")
+	config.Unset("auth:oidc:client-secret")
+	config.Unset("auth:oidc:scopes")
+	config.Unset("auth:oidc:auth-url")
+	config.Unset("auth:oidc:token-url")
+}
+
+func (s *AuthSuite) login(c *check.C) auth.RefreshableToken {
+	token, err := s.scheme.Login(context.TODO(), map[string]string{
+		"code":        "valid-code",
+		"redirectUrl": "http://localhost:8080/callback",
+	})
+	c.Assert(err, check.IsNil)
+	return token.(auth.RefreshableToken)
+}
+
+func (s *AuthSuite) TestLoginAuthorizationCode(c *check.C) {
+	provider := s.newFakeProvider(c, "code-flow@company.com")
+	defer provider.Close()
+	token := s.login(c)
+	c.Assert(token.GetUserName(), check.Equals, "code-flow@company.com")
+	c.Assert(token.GetRefreshToken(), check.Not(check.Equals), "")
+	authToken, err := s.scheme.Auth(context.TODO(), "Bearer "+token.GetValue())
+	c.Assert(err, check.IsNil)
+	c.Assert(authToken.GetUserName(), check.Equals, "code-flow@company.com")
+	sess, err := findSession(context.TODO(), hashToken(token.GetRefreshToken()))
+	c.Assert(err, check.IsNil)
+	c.Assert(sess.Email, check.Equals, "code-flow@company.com")
+	c.Assert(sess.ProviderRefreshToken.Ciphertext, check.Not(check.DeepEquals), []byte("provider-refresh-0"))
+	providerRefreshToken, err := sess.providerRefreshToken()
+	c.Assert(err, check.IsNil)
+	c.Assert(providerRefreshToken, check.Equals, "provider-refresh-0")
+}
+
+func (s *AuthSuite) TestLoginWithoutMasterKey(c *check.C) {
+	provider := s.newFakeProvider(c, "no-session@company.com")
+	defer provider.Close()
+	masterKey, _ := config.GetString("secrets:master-key")
+	config.Unset("secrets:master-key")
+	defer config.Set("secrets:master-key", masterKey)
+	token := s.login(c)
+	c.Assert(token.GetUserName(), check.Equals, "no-session@company.com")
+	c.Assert(token.GetRefreshToken(), check.Equals, "")
+}
+
+func (s *AuthSuite) TestLoginInvalidParams(c *check.C) {
+	provider := s.newFakeProvider(c, "code-flow@company.com")
+	defer provider.Close()
+	_, err := s.scheme.Login(context.TODO(), map[string]string{"redirectUrl": "http://localhost:8080/callback"})
+	c.Assert(err, check.Equals, errMissingCode)
+	_, err = s.scheme.Login(context.TODO(), map[string]string{"code": "valid-code"})
+	c.Assert(err, check.Equals, errMissingRedirectURL)
+	_, err = s.scheme.Login(context.TODO(), map[string]string{"code": "invalid-code", "redirectUrl": "http://localhost:8080/callback"})
+	c.Assert(err, check.ErrorMatches, "(?s).*invalid_grant.*")
+}
+
+func (s *AuthSuite) TestLoginInvalidAudience(c *check.C) {
+	provider := s.newFakeProvider(c, "code-flow@company.com")
+	defer provider.Close()
+	provider.audience = "other-client"
+	_, err := s.scheme.Login(context.TODO(), map[string]string{
+		"code":        "valid-code",
+		"redirectUrl": "http://localhost:8080/callback",
+	})
+	c.Assert(err, check.ErrorMatches, `invalid claim "aud"`)
+}
+
+func (s *AuthSuite) TestRefreshRotatesRefreshToken(c *check.C) {
+	provider := s.newFakeProvider(c, "refresh@company.com")
+	defer provider.Close()
+	first := s.login(c)
+	token, err := s.scheme.Refresh(context.TODO(), first.GetRefreshToken())
+	c.Assert(err, check.IsNil)
+	second := token.(auth.RefreshableToken)
+	c.Assert(second.GetUserName(), check.Equals, "refresh@company.com")
+	c.Assert(second.GetRefreshToken(), check.Not(check.Equals), first.GetRefreshToken())
+	token, err = s.scheme.Refresh(context.TODO(), second.GetRefreshToken())
+	c.Assert(err, check.IsNil)
+	third := token.(auth.RefreshableToken)
+	c.Assert(provider.refreshRequests, check.Equals, 2)
+	sess, err := findSession(context.TODO(), hashToken(third.GetRefreshToken()))
+	c.Assert(err, check.IsNil)
+	c.Assert(sess.ProviderRefreshToken.Ciphertext, check.Not(check.DeepEquals), []byte("provider-refresh-2"))
+	providerRefreshToken, err := sess.providerRefreshToken()
+	c.Assert(err, check.IsNil)
+	c.Assert(providerRefreshToken, check.Equals, "provider-refresh-2")
+}
+
+func (s *AuthSuite) TestRefreshTokenReuseRevokesSession(c *check.C) {
+	provider := s.newFakeProvider(c, "reuse@company.com")
+	defer provider.Close()
+	first := s.login(c)
+	token, err := s.scheme.Refresh(context.TODO(), first.GetRefreshToken())
+	c.Assert(err, check.IsNil)
+	second := token.(auth.RefreshableToken)
+	_, err = s.scheme.Refresh(context.TODO(), first.GetRefreshToken())
+	c.Assert(err, check.Equals, errRefreshTokenReused)
+	_, err = s.scheme.Refresh(context.TODO(), second.GetRefreshToken())
+	c.Assert(err, check.Equals, errInvalidRefreshToken)
+	_, err = s.scheme.Refresh(context.TODO(), "unknown")
+	c.Assert(err, check.Equals, errInvalidRefreshToken)
+}
+
+func (s *AuthSuite) TestLogoutRemovesSession(c *check.C) {
+	provider := s.newFakeProvider(c, "logout@company.com")
+	defer provider.Close()
+	token := s.login(c)
+	err := s.scheme.Logout(context.TODO(), token.GetValue())
+	c.Assert(err, check.IsNil)
+	_, err = s.scheme.Refresh(context.TODO(), token.GetRefreshToken())
+	c.Assert(err, check.Equals, errInvalidRefreshToken)
+	err = s.scheme.Logout(context.TODO(), "not-a-jwt")
+	c.Assert(err, check.Equals, auth.ErrInvalidToken)
+}
+
+func (s *AuthSuite) TestLoginRoleMappings(c *check.C) {
+	ctx := context.TODO()
+	_, err := permission.NewRole(ctx, "oidc-admin", "global", "")
+	c.Assert(err, check.IsNil)
+	defer permission.DestroyRole(ctx, "oidc-admin")
+	_, err = permission.NewRole(ctx, "oidc-reader", "team", "")
+	c.Assert(err, check.IsNil)
+	defer permission.DestroyRole(ctx, "oidc-reader")
+	s.scheme.roleMappings = []roleMapping{
+		{Group: "admins", Role: "oidc-admin"},
+		{Email: "*@mapped.com", Role: "oidc-reader", ContextValue: "myteam"},
+	}
+	defer func() { s.scheme.roleMappings = nil }()
+	provider := s.newFakeProvider(c, "someone@mapped.com")
+	defer provider.Close()
+	provider.groups = []string{"admins"}
+	s.login(c)
+	user, err := auth.GetUserByEmail(ctx, "someone@mapped.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(hasRole(user.Roles, "oidc-admin", ""), check.Equals, true)
+	c.Assert(hasRole(user.Roles, "oidc-reader", "myteam"), check.Equals, true)
+	provider.groups = nil
+	s.login(c)
+	user, err = auth.GetUserByEmail(ctx, "someone@mapped.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(hasRole(user.Roles, "oidc-admin", ""), check.Equals, false)
+	c.Assert(hasRole(user.Roles, "oidc-reader", "myteam"), check.Equals, true)
+}
+
+func (s *AuthSuite) TestLoginRoleMappingsUnverifiedEmail(c *check.C) {
+	ctx := context.TODO()
+	_, err := permission.NewRole(ctx, "oidc-reader", "team", "")
+	c.Assert(err, check.IsNil)
+	defer permission.DestroyRole(ctx, "oidc-reader")
+	s.scheme.roleMappings = []roleMapping{
+		{Email: "*@mapped.com", Role: "oidc-reader", ContextValue: "myteam"},
+	}
+	defer func() { s.scheme.roleMappings = nil }()
+	provider := s.newFakeProvider(c, "unverified@mapped.com")
+	defer provider.Close()
+	provider.emailUnverified = true
+	s.login(c)
+	user, err := auth.GetUserByEmail(ctx, "unverified@mapped.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(hasRole(user.Roles, "oidc-reader", "myteam"), check.Equals, false)
+	provider.emailUnverified = false
+	s.login(c)
+	user, err = auth.GetUserByEmail(ctx, "unverified@mapped.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(hasRole(user.Roles, "oidc-reader", "myteam"), check.Equals, true)
+}
+
+func (s *AuthSuite) TestLoginRoleMappingsKeepRolesAssignedByHand(c *check.C) {
+	ctx := context.TODO()
+	_, err := permission.NewRole(ctx, "oidc-admin", "global", "")
+	c.Assert(err, check.IsNil)
+	defer permission.DestroyRole(ctx, "oidc-admin")
+	s.scheme.roleMappings = []roleMapping{{Group: "admins", Role: "oidc-admin"}}
+	defer func() { s.scheme.roleMappings = nil }()
+	provider := s.newFakeProvider(c, "manual@mapped.com")
+	defer provider.Close()
+	s.login(c)
+	user, err := auth.GetUserByEmail(ctx, "manual@mapped.com")
+	c.Assert(err, check.IsNil)
+	err = user.AddRole(ctx, "oidc-admin", "")
+	c.Assert(err, check.IsNil)
+	provider.groups = []string{"admins"}
+	s.login(c)
+	provider.groups = nil
+	s.login(c)
+	user, err = auth.GetUserByEmail(ctx, "manual@mapped.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(hasRole(user.Roles, "oidc-admin", ""), check.Equals, true)
+	c.Assert(user.MappedRoles, check.HasLen, 0)
+}
@@ -58,6 +58,9 @@ type oidcScheme struct {
 	initialized         sync.Once
 	registrationEnabled bool
 	groupsInClaims      bool
+	issuer              string
+	roleMappings        []roleMapping
+	sessionMaxAge       time.Duration
 }
 
 func (s *oidcScheme) Auth(ctx context.Context, token string) (auth.Token, error) {
@@ -66,16 +69,37 @@ func (s *oidcScheme) Auth(ctx context.Context, token string) (auth.Token, error)
 		return nil, err
 	}
 
+	if strings.HasPrefix(token, "Bearer ") || strings.HasPrefix(token, "bearer ") {
+		token = token[len("Bearer "):]
+	}
+
+	identity, err := s.parseIdentity(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userFromIdentity(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	authUser, _ := auth.ConvertOldUser(user, nil)
+
+	return &jwtToken{
+		AuthUser: authUser,
+		Identity: identity,
+		Raw:      token,
+	}, nil
+}
+
+// parseIdentity validates the signature and claims of the ID token.
+func (s *oidcScheme) parseIdentity(ctx context.Context, token string) (*extendedClaims, error) {
 	if s.jwksURL == "" {
 		return nil, errNoJWKSURLS
 	}
 
 	identity := &extendedClaims{}
 
-	if strings.HasPrefix(token, "Bearer ") || strings.HasPrefix(token, "bearer ") {
-		token = token[len("Bearer "):]
-	}
-
 	parsedJWTToken, err := jwt.ParseWithClaims(token, identity, s.jwtGetKey(ctx))
 	if err != nil {
 		return nil, err
@@ -93,10 +117,20 @@ func (s *oidcScheme) Auth(ctx context.Context, token string) (auth.Token, error)
 		}
 	}
 
+	if s.issuer != "" && identity.MapClaims["iss"] != s.issuer {
+		return nil, &errInvalidClaim{"iss"}
+	}
+
 	if identity.Email == "" {
 		return nil, errMissingEmailClaim
 	}
 
+	return identity, nil
+}
+
+// userFromIdentity returns the user of the identity, registering it when
+// allowed, and updates the groups of the user from the claims.
+func (s *oidcScheme) userFromIdentity(ctx context.Context, identity *extendedClaims) (*auth.User, error) {
 	user, err := auth.GetUserByEmail(ctx, identity.Email)
 	if err == authTypes.ErrUserNotFound {
 		if s.registrationEnabled {
@@ -128,13 +162,7 @@ func (s *oidcScheme) Auth(ctx context.Context, token string) (auth.Token, error)
 		}
 	}
 
-	authUser, _ := auth.ConvertOldUser(user, nil)
-
-	return &jwtToken{
-		AuthUser: authUser,
-		Identity: identity,
-		Raw:      token,
-	}, nil
+	return user, nil
 }
 
 func (s *oidcScheme) Info(ctx context.Context) (*authTypes.SchemeInfo, error) {
@@ -181,6 +209,21 @@ func (s *oidcScheme) lazyInitialize(ctx context.Context) error {
 
 		s.registrationEnabled, _ = config.GetBool("auth:user-registration")
 		s.groupsInClaims, _ = config.GetBool("auth:oidc:groups-in-claims")
+		s.issuer, _ = config.GetString("auth:oidc:issuer")
+
+		s.sessionMaxAge, _ = config.GetDuration("auth:oidc:session-max-age")
+		if s.sessionMaxAge <= 0 {
+			s.sessionMaxAge = defaultSessionMaxAge
+		}
+
+		err = internalConfig.UnmarshalConfig("auth:oidc:role-mappings", &s.roleMappings)
+		if err != nil {
+			var notFound config.ErrKeyNotFound
+			if !errors.As(err, &notFound) {
+				return
+			}
+			err = nil
+		}
 
 		s.validClaims = map[string]interface{}{}
 		internalConfig.UnmarshalConfig("auth:oidc:valid-claims", &s.validClaims)
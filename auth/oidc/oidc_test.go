@@ -5,9 +5,11 @@
 package oidc
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -48,6 +50,7 @@ func (s *AuthSuite) SetUpSuite(c *check.C) {
 
 	config.Set("auth:oidc:jwks-url", s.fakeJWKSServer.URL)
 	config.Set("auth:user-registration", true)
+	config.Set("secrets:master-key", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32)))
 
 	err := s.scheme.lazyInitialize(context.Background())
 	c.Check(err, check.IsNil)
@@ -56,6 +59,7 @@ func (s *AuthSuite) SetUpSuite(c *check.C) {
 func (s *AuthSuite) TearDownSuite(c *check.C) {
 	s.fakeJWKSServer.Close()
 	config.Unset("auth:oidc:jwks-url")
+	config.Unset("secrets:master-key")
 }
 
 func (s *AuthSuite) TestLoginNoJWKSURLDefined(c *check.C) {
@@ -71,11 +75,11 @@ func (s *AuthSuite) TestLoginNoJWKSURLDefined(c *check.C) {
 	c.Assert(token, check.IsNil)
 }
 
-func (s *AuthSuite) TestNotImplementUserScheme(c *check.C) {
+func (s *AuthSuite) TestImplementRefreshableScheme(c *check.C) {
 	var scheme auth.Scheme = &oidcScheme{}
 
-	_, implements := scheme.(auth.UserScheme)
-	c.Assert(implements, check.Equals, false)
+	_, implements := scheme.(auth.RefreshableScheme)
+	c.Assert(implements, check.Equals, true)
 }
 
 func (s *AuthSuite) TestLoginWithRSAKey(c *check.C) {
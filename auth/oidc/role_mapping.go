@@ -0,0 +1,92 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"path"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/log"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+// roleMapping assigns a role to the users whose claims match it on login,
+// by group and/or by email pattern, like *@example.com. Email patterns only
+// match emails verified by the provider.
+type roleMapping struct {
+	Group        string `json:"group"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	ContextValue string `json:"context-value"`
+}
+
+func (m *roleMapping) key() string {
+	return m.Role + "/" + m.ContextValue
+}
+
+func (m *roleMapping) matches(identity *extendedClaims) bool {
+	if m.Group == "" && m.Email == "" {
+		return false
+	}
+	if m.Group != "" {
+		var member bool
+		for _, group := range identity.Groups {
+			member = member || group == m.Group
+		}
+		if !member {
+			return false
+		}
+	}
+	if m.Email != "" {
+		if !identity.EmailVerified {
+			return false
+		}
+		if ok, _ := path.Match(m.Email, identity.Email); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func hasRole(roles []authTypes.RoleInstance, roleName, contextValue string) bool {
+	for _, role := range roles {
+		if role.Name == roleName && role.ContextValue == contextValue && role.ExpiresAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRoleMappings reconciles the mapped roles of the user with the claims,
+// assigning the roles of the matching mappings and removing the roles of the
+// others which were granted by a mapping, leaving the ones assigned by hand.
+// Invalid mappings are logged without failing the login.
+func (s *oidcScheme) applyRoleMappings(ctx context.Context, user *auth.User, identity *extendedClaims) {
+	granted := map[string]bool{}
+	for i := range s.roleMappings {
+		if s.roleMappings[i].matches(identity) {
+			granted[s.roleMappings[i].key()] = true
+		}
+	}
+	done := map[string]bool{}
+	for i := range s.roleMappings {
+		mapping := &s.roleMappings[i]
+		if done[mapping.key()] {
+			continue
+		}
+		done[mapping.key()] = true
+		var err error
+		switch {
+		case granted[mapping.key()] && !hasRole(user.Roles, mapping.Role, mapping.ContextValue):
+			err = user.AddMappedRole(ctx, mapping.Role, mapping.ContextValue)
+		case !granted[mapping.key()] && hasRole(user.MappedRoles, mapping.Role, mapping.ContextValue):
+			err = user.RemoveMappedRole(ctx, mapping.Role, mapping.ContextValue)
+		}
+		if err != nil {
+			log.Errorf("[oidc] unable to apply mapping of role %q to user %q: %v", mapping.Role, user.Email, err)
+		}
+	}
+}
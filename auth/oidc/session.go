@@ -0,0 +1,199 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/tsuru/tsuru/app/secret"
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultSessionMaxAge = 30 * 24 * time.Hour
+
+	// maxUsedRefreshTokens is how many rotated refresh tokens are kept per
+	// session to detect their reuse.
+	maxUsedRefreshTokens = 100
+)
+
+var errSessionNotFound = errors.New("oidc session not found")
+
+// session keeps the refresh token of the identity provider, which never
+// leaves the API and is sealed like the secrets of apps, and the hash of the
+// refresh token handed to the user, replaced on every refresh.
+type session struct {
+	ID                     string `bson:"_id"`
+	Email                  string
+	ProviderRefreshToken   appTypes.SealedValue
+	RefreshTokenHash       string
+	UsedRefreshTokenHashes []string `bson:",omitempty"`
+	IDTokenHash            string
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	ExpiresAt              time.Time
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *session) providerRefreshToken() (string, error) {
+	value, err := secret.Open(s.ProviderRefreshToken)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+func randomToken() (string, error) {
+	data := make([]byte, 32)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// createSession stores a new session, returning the refresh token to be
+// handed to the user.
+func createSession(ctx context.Context, email, providerRefreshToken, idToken string, maxAge time.Duration) (string, error) {
+	sealed, err := secret.Seal([]byte(providerRefreshToken))
+	if err != nil {
+		return "", err
+	}
+	id, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	refreshToken, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	_, err = collection.InsertOne(ctx, session{
+		ID:                   id,
+		Email:                email,
+		ProviderRefreshToken: sealed,
+		RefreshTokenHash:     hashToken(refreshToken),
+		IDTokenHash:          hashToken(idToken),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		ExpiresAt:            now.Add(maxAge),
+	})
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+func findSession(ctx context.Context, refreshTokenHash string) (*session, error) {
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var sess session
+	err = collection.FindOne(ctx, mongoBSON.M{"refreshtokenhash": refreshTokenHash}).Decode(&sess)
+	if err == mongo.ErrNoDocuments {
+		return nil, errSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// rotateSession replaces the refresh token of the session, returning the new
+// one. It fails with errSessionNotFound when the refresh token was rotated
+// meanwhile by a concurrent refresh.
+func rotateSession(ctx context.Context, sess *session, providerRefreshToken, idToken string) (string, error) {
+	sealed, err := secret.Seal([]byte(providerRefreshToken))
+	if err != nil {
+		return "", err
+	}
+	refreshToken, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return "", err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": sess.ID, "refreshtokenhash": sess.RefreshTokenHash}, mongoBSON.M{
+		"$set": mongoBSON.M{
+			"refreshtokenhash":     hashToken(refreshToken),
+			"providerrefreshtoken": sealed,
+			"idtokenhash":          hashToken(idToken),
+			"updatedat":            time.Now().UTC(),
+		},
+		"$push": mongoBSON.M{
+			"usedrefreshtokenhashes": mongoBSON.M{
+				"$each":  []string{sess.RefreshTokenHash},
+				"$slice": -maxUsedRefreshTokens,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.MatchedCount == 0 {
+		return "", errSessionNotFound
+	}
+	return refreshToken, nil
+}
+
+// revokeReusedSession removes the session which the already rotated refresh
+// token belonged to, reporting whether there was one.
+func revokeReusedSession(ctx context.Context, refreshTokenHash string) (bool, error) {
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return false, err
+	}
+	result, err := collection.DeleteMany(ctx, mongoBSON.M{"usedrefreshtokenhashes": refreshTokenHash})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func removeSession(ctx context.Context, id string) error {
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"_id": id})
+	return err
+}
+
+func removeSessionsByIDToken(ctx context.Context, idToken string) error {
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"idtokenhash": hashToken(idToken)})
+	return err
+}
+
+func removeUserSessions(ctx context.Context, email string) error {
+	collection, err := storagev2.OIDCSessionsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"email": email})
+	return err
+}
@@ -14,17 +14,24 @@ import (
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
-var _ authTypes.Token = &jwtToken{}
+var (
+	_ authTypes.Token       = &jwtToken{}
+	_ auth.RefreshableToken = &jwtToken{}
+)
 
 type jwtToken struct {
 	AuthUser *authTypes.User
 	Raw      string
 	Identity *extendedClaims
+	// RefreshToken is set on tokens issued by the API, renewing them by
+	// the refresh of their session.
+	RefreshToken string
 }
 
 type extendedClaims struct {
 	jwt.MapClaims `json:"-"`
 	Email         string   `json:"email,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
 	Groups        []string `json:"groups,omitempty"`
 }
 
@@ -40,6 +47,14 @@ func (f *extendedClaims) UnmarshalJSON(b []byte) error {
 		f.Email = email
 	}
 
+	// some providers send the flag as a string.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		f.EmailVerified = verified
+	case string:
+		f.EmailVerified = verified == "true"
+	}
+
 	if groups, ok := claims["groups"].([]interface{}); ok {
 
 		f.Groups = make([]string, len(groups))
@@ -49,6 +64,7 @@ func (f *extendedClaims) UnmarshalJSON(b []byte) error {
 		}
 	}
 	delete(claims, "email")
+	delete(claims, "email_verified")
 	delete(claims, "groups")
 
 	f.MapClaims = claims
@@ -60,6 +76,10 @@ func (t *jwtToken) GetValue() string {
 	return t.Raw
 }
 
+func (t *jwtToken) GetRefreshToken() string {
+	return t.RefreshToken
+}
+
 func (t *jwtToken) User(ctx context.Context) (*authTypes.User, error) {
 	return t.AuthUser, nil
 }
@@ -29,6 +29,19 @@ type UserScheme interface {
 	Remove(ctx context.Context, user *User) error
 }
 
+// RefreshableScheme is implemented by schemes issuing refresh tokens on
+// login, which are exchanged for a new token and refresh token by Refresh.
+type RefreshableScheme interface {
+	UserScheme
+	Refresh(ctx context.Context, refreshToken string) (Token, error)
+}
+
+// RefreshableToken is a token issued along with a refresh token.
+type RefreshableToken interface {
+	Token
+	GetRefreshToken() string
+}
+
 type ManagedScheme interface {
 	UserScheme
 	StartPasswordReset(ctx context.Context, user *User) error
@@ -6,17 +6,23 @@ package auth
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/storage"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/bind"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/quota"
+	"github.com/tsuru/tsuru/validation"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -83,6 +89,148 @@ func (t *teamService) Update(ctx context.Context, name string, tags []string) er
 	return t.storage.Update(ctx, *team)
 }
 
+// SetEnvs adds or replaces environment variables in the team env set.
+func (t *teamService) SetEnvs(ctx context.Context, name string, envs []bind.EnvVar) error {
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		replaced := false
+		for i := range team.Env {
+			if team.Env[i].Name == env.Name {
+				team.Env[i] = env
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			team.Env = append(team.Env, env)
+		}
+	}
+	return t.storage.Update(ctx, *team)
+}
+
+// UnsetEnvs removes environment variables from the team env set.
+func (t *teamService) UnsetEnvs(ctx context.Context, name string, names []string) error {
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	envs := make([]bind.EnvVar, 0, len(team.Env))
+	for _, env := range team.Env {
+		if !slices.Contains(names, env.Name) {
+			envs = append(envs, env)
+		}
+	}
+	team.Env = envs
+	return t.storage.Update(ctx, *team)
+}
+
+// AddQuotaGrant records an approved temporary quota increase and raises the
+// team quota limit accordingly.
+func (t *teamService) AddQuotaGrant(ctx context.Context, name string, grant authTypes.QuotaGrant) error {
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if team.Quota.IsUnlimited() {
+		return authTypes.ErrQuotaGrantUnlimited
+	}
+	team.Quota.Limit += grant.Increase
+	team.QuotaGrants = append(team.QuotaGrants, grant)
+	return t.storage.Update(ctx, *team)
+}
+
+// RemoveQuotaGrant removes a temporary quota increase, lowering the team
+// quota limit back. Units already allocated are kept.
+func (t *teamService) RemoveQuotaGrant(ctx context.Context, name, id string) error {
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(team.QuotaGrants, func(g authTypes.QuotaGrant) bool {
+		return g.ID == id
+	})
+	if idx < 0 {
+		return authTypes.ErrQuotaGrantNotFound
+	}
+	if !team.Quota.IsUnlimited() {
+		team.Quota.Limit = max(team.Quota.Limit-team.QuotaGrants[idx].Increase, 0)
+	}
+	team.QuotaGrants = slices.Delete(team.QuotaGrants, idx, idx+1)
+	return t.storage.Update(ctx, *team)
+}
+
+// SetNotifications replaces the notification channels of the team. Empty
+// notifications disable them.
+func (t *teamService) SetNotifications(ctx context.Context, name string, notifications authTypes.TeamNotifications) error {
+	err := validateTeamNotifications(notifications)
+	if err != nil {
+		return err
+	}
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	team.Notifications = notifications
+	return t.storage.Update(ctx, *team)
+}
+
+// SetIsolationProfile assigns the isolation profile to the team. An empty
+// profile removes the current one.
+func (t *teamService) SetIsolationProfile(ctx context.Context, name, profile string) error {
+	if profile != "" {
+		if _, err := GetIsolationProfile(profile); err != nil {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+	}
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	team.IsolationProfile = profile
+	return t.storage.Update(ctx, *team)
+}
+
+// FindIsolationProfile returns the isolation profile assigned to the team, or
+// nil when the team has none.
+func (t *teamService) FindIsolationProfile(ctx context.Context, name string) (*authTypes.IsolationProfile, error) {
+	return TeamIsolationProfile(ctx, name)
+}
+
+// SetBudget replaces the budget of the team. A nil budget removes it.
+func (t *teamService) SetBudget(ctx context.Context, name string, budget *quota.Budget) error {
+	err := budget.Validate()
+	if err != nil {
+		return err
+	}
+	team, err := t.storage.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	team.Budget = budget
+	return t.storage.Update(ctx, *team)
+}
+
+func validateTeamNotifications(notifications authTypes.TeamNotifications) error {
+	for _, email := range notifications.Emails {
+		if !validation.ValidateEmail(email) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid notification email %q", email)}
+		}
+	}
+	for _, u := range []string{notifications.WebhookURL, notifications.SlackURL} {
+		if u == "" {
+			continue
+		}
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid notification url %q", u)}
+		}
+	}
+	return nil
+}
+
 func (t *teamService) List(ctx context.Context) ([]authTypes.Team, error) {
 	return t.storage.FindAll(ctx)
 }
@@ -9,6 +9,8 @@ import (
 
 	"github.com/tsuru/tsuru/db/storagev2"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/quota"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	check "gopkg.in/check.v1"
 )
@@ -53,6 +55,118 @@ func (s *S) TestTeamServiceUpdate(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (s *S) TestTeamServiceSetEnvs(c *check.C) {
+	var updated authTypes.Team
+	ts := &teamService{
+		storage: &authTypes.MockTeamStorage{
+			OnFindByName: func(name string) (*authTypes.Team, error) {
+				return &authTypes.Team{Name: name, Env: []bind.EnvVar{
+					{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+					{Name: "OTEL_ENDPOINT", Value: "otel:4317"},
+				}}, nil
+			},
+			OnUpdate: func(t authTypes.Team) error {
+				updated = t
+				return nil
+			},
+		},
+	}
+	err := ts.SetEnvs(context.TODO(), "pos", []bind.EnvVar{
+		{Name: "HTTP_PROXY", Value: "http://newproxy:3128"},
+		{Name: "TELEMETRY_KEY", Value: "secret"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Name, check.Equals, "pos")
+	c.Assert(updated.Env, check.DeepEquals, []bind.EnvVar{
+		{Name: "HTTP_PROXY", Value: "http://newproxy:3128"},
+		{Name: "OTEL_ENDPOINT", Value: "otel:4317"},
+		{Name: "TELEMETRY_KEY", Value: "secret"},
+	})
+}
+
+func (s *S) TestTeamServiceUnsetEnvs(c *check.C) {
+	var updated authTypes.Team
+	ts := &teamService{
+		storage: &authTypes.MockTeamStorage{
+			OnFindByName: func(name string) (*authTypes.Team, error) {
+				return &authTypes.Team{Name: name, Env: []bind.EnvVar{
+					{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+					{Name: "OTEL_ENDPOINT", Value: "otel:4317"},
+				}}, nil
+			},
+			OnUpdate: func(t authTypes.Team) error {
+				updated = t
+				return nil
+			},
+		},
+	}
+	err := ts.UnsetEnvs(context.TODO(), "pos", []string{"HTTP_PROXY", "OTHER"})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Env, check.DeepEquals, []bind.EnvVar{
+		{Name: "OTEL_ENDPOINT", Value: "otel:4317"},
+	})
+}
+
+func (s *S) TestTeamServiceAddQuotaGrant(c *check.C) {
+	var updated authTypes.Team
+	ts := &teamService{
+		storage: &authTypes.MockTeamStorage{
+			OnFindByName: func(name string) (*authTypes.Team, error) {
+				return &authTypes.Team{Name: name, Quota: quota.Quota{Limit: 10, InUse: 8}}, nil
+			},
+			OnUpdate: func(t authTypes.Team) error {
+				updated = t
+				return nil
+			},
+		},
+	}
+	grant := authTypes.QuotaGrant{ID: "abc", Increase: 5, ApprovedBy: "admin@example.com"}
+	err := ts.AddQuotaGrant(context.TODO(), "pos", grant)
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Quota, check.DeepEquals, quota.Quota{Limit: 15, InUse: 8})
+	c.Assert(updated.QuotaGrants, check.DeepEquals, []authTypes.QuotaGrant{grant})
+}
+
+func (s *S) TestTeamServiceAddQuotaGrantUnlimited(c *check.C) {
+	ts := &teamService{
+		storage: &authTypes.MockTeamStorage{
+			OnFindByName: func(name string) (*authTypes.Team, error) {
+				return &authTypes.Team{Name: name, Quota: quota.UnlimitedQuota}, nil
+			},
+		},
+	}
+	err := ts.AddQuotaGrant(context.TODO(), "pos", authTypes.QuotaGrant{ID: "abc", Increase: 5})
+	c.Assert(err, check.Equals, authTypes.ErrQuotaGrantUnlimited)
+}
+
+func (s *S) TestTeamServiceRemoveQuotaGrant(c *check.C) {
+	var updated authTypes.Team
+	ts := &teamService{
+		storage: &authTypes.MockTeamStorage{
+			OnFindByName: func(name string) (*authTypes.Team, error) {
+				return &authTypes.Team{
+					Name:  name,
+					Quota: quota.Quota{Limit: 15, InUse: 12},
+					QuotaGrants: []authTypes.QuotaGrant{
+						{ID: "abc", Increase: 5},
+						{ID: "def", Increase: 2},
+					},
+				}, nil
+			},
+			OnUpdate: func(t authTypes.Team) error {
+				updated = t
+				return nil
+			},
+		},
+	}
+	err := ts.RemoveQuotaGrant(context.TODO(), "pos", "abc")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Quota, check.DeepEquals, quota.Quota{Limit: 10, InUse: 12})
+	c.Assert(updated.QuotaGrants, check.DeepEquals, []authTypes.QuotaGrant{{ID: "def", Increase: 2}})
+	err = ts.RemoveQuotaGrant(context.TODO(), "pos", "xyz")
+	c.Assert(err, check.Equals, authTypes.ErrQuotaGrantNotFound)
+}
+
 func (s *S) TestTeamServiceCreateDuplicate(c *check.C) {
 	teamName := "pos"
 	u := authTypes.User{Email: "king@pos.com"}
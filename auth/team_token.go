@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/storage"
@@ -27,6 +29,8 @@ import (
 // should not be able to register using it.
 const TsuruTokenEmailDomain = "tsuru-team-token"
 
+const defaultTeamTokenRotationOverlap = time.Hour
+
 func IsEmailFromTeamToken(email string) bool {
 	return strings.HasSuffix(email, fmt.Sprintf("@%s", TsuruTokenEmailDomain))
 }
@@ -34,8 +38,9 @@ func IsEmailFromTeamToken(email string) bool {
 type teamToken authTypes.TeamToken
 
 var (
-	_ authTypes.Token      = &teamToken{}
-	_ authTypes.NamedToken = &teamToken{}
+	_ authTypes.Token        = &teamToken{}
+	_ authTypes.NamedToken   = &teamToken{}
+	_ authTypes.RotatedToken = &teamToken{}
 )
 
 func (t *teamToken) GetValue() string {
@@ -63,6 +68,14 @@ func (t *teamToken) Engine() string {
 	return "team"
 }
 
+func (t *teamToken) UsingPreviousValue() bool {
+	return t.PreviousToken != "" && t.Token == t.PreviousToken
+}
+
+func (t *teamToken) PreviousValueLastAccess() time.Time {
+	return t.PreviousTokenLastAccess
+}
+
 func (t *teamToken) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
 	return expandRolePermissions(ctx, t.Roles)
 }
@@ -90,6 +103,9 @@ func (s *teamTokenService) Authenticate(ctx context.Context, header string) (aut
 		return nil, err
 	}
 	storedToken, err := s.storage.FindByToken(ctx, tokenStr)
+	if err == authTypes.ErrTeamTokenNotFound {
+		storedToken, err = s.findByPreviousToken(ctx, tokenStr)
+	}
 	if err != nil {
 		if err == authTypes.ErrTeamTokenNotFound {
 			err = ErrInvalidToken
@@ -108,6 +124,21 @@ func (s *teamTokenService) Authenticate(ctx context.Context, header string) (aut
 	return &token, nil
 }
 
+// findByPreviousToken finds the team token whose value before the last
+// rotation is token, while in the overlap window. The token value is set to
+// the previous one, which was used to authenticate.
+func (s *teamTokenService) findByPreviousToken(ctx context.Context, token string) (*authTypes.TeamToken, error) {
+	storedToken, err := s.storage.FindByPreviousToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !storedToken.PreviousTokenExpiresAt.After(time.Now()) {
+		return nil, authTypes.ErrTeamTokenNotFound
+	}
+	storedToken.Token = storedToken.PreviousToken
+	return storedToken, nil
+}
+
 func (s *teamTokenService) Delete(ctx context.Context, tokenID string) error {
 	token, err := s.storage.FindByTokenID(ctx, tokenID)
 	if err != nil {
@@ -133,6 +164,9 @@ func (s *teamTokenService) Create(ctx context.Context, args authTypes.TeamTokenC
 	if err != nil {
 		return authTypes.TeamToken{}, err
 	}
+	if args.ExpiresIn > maxTokenExpiresIn {
+		return authTypes.TeamToken{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("expires_in must not be greater than %d", maxTokenExpiresIn)}
+	}
 	_, err = servicemanager.Team.FindByName(ctx, args.Team)
 	if err != nil {
 		return authTypes.TeamToken{}, err
@@ -223,6 +257,9 @@ func getTokenTeams(ctx context.Context, t Token) []string {
 }
 
 func (s *teamTokenService) Update(ctx context.Context, args authTypes.TeamTokenUpdateArgs, t authTypes.Token) (authTypes.TeamToken, error) {
+	if args.ExpiresIn > maxTokenExpiresIn {
+		return authTypes.TeamToken{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("expires_in must not be greater than %d", maxTokenExpiresIn)}
+	}
 	token, err := s.storage.FindByTokenID(ctx, args.TokenID)
 	if err != nil {
 		return authTypes.TeamToken{}, err
@@ -237,8 +274,43 @@ func (s *teamTokenService) Update(ctx context.Context, args authTypes.TeamTokenU
 	}
 	if args.Regenerate {
 		token.Token = generateToken(token.Team, crypto.SHA256)
+		token.PreviousToken = ""
+		token.PreviousTokenExpiresAt = time.Time{}
 	}
-	err = s.storage.Update(ctx, *token)
+	return s.updateAndHideValue(ctx, token, t)
+}
+
+// Rotate replaces the value of the team token, keeping the current one valid
+// for the overlap window, so clients may be updated without downtime.
+func (s *teamTokenService) Rotate(ctx context.Context, args authTypes.TeamTokenRotateArgs, t authTypes.Token) (authTypes.TeamToken, error) {
+	if args.Overlap < 0 {
+		return authTypes.TeamToken{}, &tsuruErrors.ValidationError{Message: "overlap must not be negative"}
+	}
+	token, err := s.storage.FindByTokenID(ctx, args.TokenID)
+	if err != nil {
+		return authTypes.TeamToken{}, err
+	}
+	overlap := time.Duration(args.Overlap) * time.Second
+	if args.Overlap == 0 {
+		overlap = teamTokenRotationOverlap()
+	}
+	token.PreviousToken = token.Token
+	token.PreviousTokenExpiresAt = time.Now().UTC().Add(overlap)
+	token.PreviousTokenLastAccess = time.Time{}
+	token.Token = generateToken(token.Team, crypto.SHA256)
+	return s.updateAndHideValue(ctx, token, t)
+}
+
+func teamTokenRotationOverlap() time.Duration {
+	overlap, err := config.GetDuration("auth:team-token-rotation-overlap")
+	if err != nil {
+		return defaultTeamTokenRotationOverlap
+	}
+	return overlap
+}
+
+func (s *teamTokenService) updateAndHideValue(ctx context.Context, token *authTypes.TeamToken, t authTypes.Token) (authTypes.TeamToken, error) {
+	err := s.storage.Update(ctx, *token)
 	if err != nil {
 		return authTypes.TeamToken{}, err
 	}
@@ -296,6 +368,20 @@ func (s *teamTokenService) FindByUserToken(ctx context.Context, t authTypes.Toke
 	return teamTokens, nil
 }
 
+// FindByRole returns every team token holding the role, in any context,
+// without their token values.
+func (s *teamTokenService) FindByRole(ctx context.Context, roleName string) ([]authTypes.TeamToken, error) {
+	teamTokens, err := s.storage.FindByRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range teamTokens {
+		teamTokens[i].Token = ""
+		teamTokens[i].PreviousToken = ""
+	}
+	return teamTokens, nil
+}
+
 func canUseRole(ctx context.Context, userPerms []permTypes.Permission, roleName, contextValue string) (bool, error) {
 	role, err := permission.FindRole(ctx, roleName)
 	if err != nil {
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -366,6 +368,84 @@ func (s *S) Test_TeamTokenService_Update_Regenerate(c *check.C) {
 	c.Assert(t, check.DeepEquals, expected)
 }
 
+func (s *S) Test_TeamTokenService_Rotate(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	rotatedToken, err := servicemanager.TeamToken.Rotate(context.TODO(), authTypes.TeamTokenRotateArgs{
+		TokenID: "t1",
+		Overlap: 60,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(rotatedToken.Token, check.Not(check.Equals), token.Token)
+	c.Assert(rotatedToken.PreviousToken, check.Equals, token.Token)
+	c.Assert(rotatedToken.PreviousTokenExpiresAt.Sub(time.Now()) <= time.Minute, check.Equals, true)
+	c.Assert(rotatedToken.PreviousTokenExpiresAt.Sub(time.Now()) > 50*time.Second, check.Equals, true)
+	t, err := servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+rotatedToken.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.(authTypes.RotatedToken).UsingPreviousValue(), check.Equals, false)
+	t, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.GetValue(), check.Equals, token.Token)
+	c.Assert(t.(authTypes.NamedToken).GetTokenName(), check.Equals, "t1")
+	c.Assert(t.(authTypes.RotatedToken).UsingPreviousValue(), check.Equals, true)
+	c.Assert(t.(authTypes.RotatedToken).PreviousValueLastAccess().IsZero(), check.Equals, true)
+	t, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.(authTypes.RotatedToken).PreviousValueLastAccess().IsZero(), check.Equals, false)
+	updatedToken, err := servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:    "t1",
+		Regenerate: true,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(updatedToken.PreviousToken, check.Equals, "")
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) Test_TeamTokenService_Rotate_DefaultOverlap(c *check.C) {
+	config.Set("auth:team-token-rotation-overlap", "10m")
+	defer config.Unset("auth:team-token-rotation-overlap")
+	_, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	rotatedToken, err := servicemanager.TeamToken.Rotate(context.TODO(), authTypes.TeamTokenRotateArgs{TokenID: "t1"}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(rotatedToken.PreviousTokenExpiresAt.Sub(time.Now()) <= 10*time.Minute, check.Equals, true)
+	c.Assert(rotatedToken.PreviousTokenExpiresAt.Sub(time.Now()) > 9*time.Minute, check.Equals, true)
+	_, err = servicemanager.TeamToken.Rotate(context.TODO(), authTypes.TeamTokenRotateArgs{TokenID: "t1", Overlap: -1}, &userToken{user: s.user})
+	c.Assert(err, check.ErrorMatches, "overlap must not be negative")
+	_, err = servicemanager.TeamToken.Rotate(context.TODO(), authTypes.TeamTokenRotateArgs{TokenID: "unknown"}, &userToken{user: s.user})
+	c.Assert(err, check.Equals, authTypes.ErrTeamTokenNotFound)
+}
+
+func (s *S) Test_TeamTokenService_Authenticate_PreviousTokenExpired(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	rotatedToken, err := servicemanager.TeamToken.Rotate(context.TODO(), authTypes.TeamTokenRotateArgs{
+		TokenID: "t1",
+		Overlap: 60,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	dbToken, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "t1")
+	c.Assert(err, check.IsNil)
+	dbToken.PreviousTokenExpiresAt = time.Now().Add(-time.Second)
+	svc := servicemanager.TeamToken.(*teamTokenService)
+	err = svc.storage.Update(context.TODO(), dbToken)
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+rotatedToken.Token)
+	c.Assert(err, check.IsNil)
+}
+
 func (s *S) Test_TeamTokenService_Update_Expires(c *check.C) {
 	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
 		Team:        s.team.Name,
@@ -398,6 +478,17 @@ func (s *S) Test_TeamTokenService_Update_Expires(c *check.C) {
 	}, &userToken{user: s.user})
 	c.Assert(err, check.IsNil)
 	c.Assert(updatedToken.ExpiresAt.IsZero(), check.Equals, true)
+
+	_, err = servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:   "t1",
+		ExpiresIn: maxTokenExpiresIn + 1,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:      s.team.Name,
+		ExpiresIn: maxTokenExpiresIn + 1,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
 }
 
 func (s *S) Test_TeamToken_Permissions(c *check.C) {
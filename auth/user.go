@@ -36,6 +36,15 @@ type User struct {
 	FromToken bool                     `bson:",omitempty"`
 	Disabled  bool                     `bson:",omitempty"`
 
+	// DirectoryGroups are the groups the user is a member of in the
+	// external directory, kept apart from Groups as those are replaced by
+	// the auth scheme on login.
+	DirectoryGroups []string `bson:",omitempty"`
+
+	// MappedRoles are the roles granted by the role mappings of the auth
+	// scheme, kept apart so it never revokes the ones assigned by hand.
+	MappedRoles []authTypes.RoleInstance `bson:",omitempty"`
+
 	APIKeyLastAccess   time.Time `bson:"apikey_last_access"`
 	APIKeyUsageCounter int64     `bson:"apikey_usage_counter"`
 }
@@ -69,6 +78,67 @@ func ListUsersWithRole(ctx context.Context, role string) ([]User, error) {
 	return listUsers(ctx, mongoBSON.M{"roles.name": role})
 }
 
+// ListUsersWithExpiredRoles lists users holding temporary role assignments
+// expired at now.
+func ListUsersWithExpiredRoles(ctx context.Context, now time.Time) ([]User, error) {
+	return listUsers(ctx, mongoBSON.M{"roles.expiresat": mongoBSON.M{"$lte": now}})
+}
+
+func ListUsersInDirectoryGroup(ctx context.Context, group string) ([]User, error) {
+	return listUsers(ctx, mongoBSON.M{"directorygroups": group})
+}
+
+// ListDirectoryGroups returns the directory groups with at least one member.
+func ListDirectoryGroups(ctx context.Context) ([]string, error) {
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return nil, err
+	}
+	result, err := usersCollection.Distinct(ctx, "directorygroups", mongoBSON.M{})
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(result))
+	for _, group := range result {
+		if name, ok := group.(string); ok {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+func ListUsersByEmail(ctx context.Context, emails []string) ([]User, error) {
+	return listUsers(ctx, mongoBSON.M{"email": mongoBSON.M{"$in": emails}})
+}
+
+func AddUsersToDirectoryGroup(ctx context.Context, group string, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return err
+	}
+	_, err = usersCollection.UpdateMany(ctx, mongoBSON.M{"email": mongoBSON.M{"$in": emails}}, mongoBSON.M{
+		"$addToSet": mongoBSON.M{"directorygroups": group},
+	})
+	return err
+}
+
+func RemoveUsersFromDirectoryGroup(ctx context.Context, group string, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return err
+	}
+	_, err = usersCollection.UpdateMany(ctx, mongoBSON.M{"email": mongoBSON.M{"$in": emails}}, mongoBSON.M{
+		"$pull": mongoBSON.M{"directorygroups": group},
+	})
+	return err
+}
+
 func ListUsersWithRolesAndContext(ctx context.Context, roles []string, context string) ([]User, error) {
 	return listUsers(ctx, mongoBSON.M{"roles": mongoBSON.M{"$elemMatch": mongoBSON.M{"contextvalue": context, "name": mongoBSON.M{"$in": roles}}}})
 }
@@ -121,6 +191,10 @@ func (u *User) Delete(ctx context.Context) error {
 	if err != nil {
 		log.Errorf("failed to remove user %q from the database: %s", u.Email, err)
 	}
+	err = deleteAllUserTokens(ctx, u.Email)
+	if err != nil {
+		log.Errorf("failed to remove tokens of user %q from the database: %s", u.Email, err)
+	}
 
 	return nil
 }
@@ -188,10 +262,8 @@ func expandRolePermissions(ctx context.Context, roleInstances []authTypes.RoleIn
 }
 
 func (u *User) UserGroups() ([]authTypes.Group, error) {
-	groupsFilter := []string{}
-	if u.Groups != nil {
-		groupsFilter = u.Groups
-	}
+	groupsFilter := append([]string{}, u.Groups...)
+	groupsFilter = append(groupsFilter, u.DirectoryGroups...)
 	groups, err := servicemanager.AuthGroup.List(context.TODO(), groupsFilter)
 	if err != nil {
 		return nil, err
@@ -204,7 +276,15 @@ func (u *User) Permissions(ctx context.Context) ([]permTypes.Permission, error)
 	if err != nil {
 		return nil, err
 	}
-	allRoles := u.Roles
+	now := time.Now()
+	var allRoles []authTypes.RoleInstance
+	for _, role := range u.Roles {
+		// expired roles are revoked in background, they must not be
+		// honored meanwhile.
+		if !role.Expired(now) {
+			allRoles = append(allRoles, role)
+		}
+	}
 	for _, group := range groups {
 		allRoles = append(allRoles, group.Roles...)
 	}
@@ -236,6 +316,89 @@ func (u *User) AddRole(ctx context.Context, roleName string, contextValue string
 				{Key: "contextvalue", Value: contextValue},
 			}),
 		},
+		// A role assigned by hand is no longer up to the role mappings.
+		"$pull": mongoBSON.M{
+			"mappedroles": mongoBSON.M{"name": roleName, "contextvalue": contextValue},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return u.reload(ctx)
+}
+
+// AddMappedRole grants the role to the user on behalf of a role mapping of
+// the auth scheme, which may later revoke it with RemoveMappedRole.
+func (u *User) AddMappedRole(ctx context.Context, roleName string, contextValue string) error {
+	_, err := permission.FindRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return err
+	}
+	role := mongoBSON.D([]mongoBSON.E{
+		{Key: "name", Value: roleName},
+		{Key: "contextvalue", Value: contextValue},
+	})
+	_, err = usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+		"$addToSet": mongoBSON.M{"roles": role, "mappedroles": role},
+	})
+	if err != nil {
+		return err
+	}
+	return u.reload(ctx)
+}
+
+// RemoveMappedRole revokes the role granted by AddMappedRole. Roles assigned
+// by hand are kept.
+func (u *User) RemoveMappedRole(ctx context.Context, roleName string, contextValue string) error {
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return err
+	}
+	role := mongoBSON.M{"name": roleName, "contextvalue": contextValue}
+	_, err = usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email, "mappedroles": mongoBSON.M{"$elemMatch": role}}, mongoBSON.M{
+		"$pull": mongoBSON.M{
+			"roles":       mongoBSON.M{"name": roleName, "contextvalue": contextValue, "expiresat": mongoBSON.M{"$exists": false}},
+			"mappedroles": role,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return u.reload(ctx)
+}
+
+// AddRoleUntil grants the role to the user until expiresAt, replacing a
+// previous temporary grant of the same role and context value. Expired
+// grants are revoked by RemoveExpiredRole.
+func (u *User) AddRoleUntil(ctx context.Context, roleName string, contextValue string, expiresAt time.Time) error {
+	_, err := permission.FindRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return err
+	}
+	_, err = usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+		"$pull": mongoBSON.M{
+			"roles": mongoBSON.M{"name": roleName, "contextvalue": contextValue, "expiresat": mongoBSON.M{"$exists": true}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+		"$push": mongoBSON.M{
+			"roles": mongoBSON.D([]mongoBSON.E{
+				{Key: "name", Value: roleName},
+				{Key: "contextvalue", Value: contextValue},
+				{Key: "expiresat", Value: expiresAt.UTC()},
+			}),
+		},
 	})
 	if err != nil {
 		return err
@@ -243,6 +406,27 @@ func (u *User) AddRole(ctx context.Context, roleName string, contextValue string
 	return u.reload(ctx)
 }
 
+// RemoveExpiredRole revokes the temporary role assignment if it's still held
+// by the user, reporting whether it was revoked.
+func (u *User) RemoveExpiredRole(ctx context.Context, role authTypes.RoleInstance) (bool, error) {
+	if role.ExpiresAt == nil {
+		return false, nil
+	}
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return false, err
+	}
+	result, err := usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+		"$pull": mongoBSON.M{
+			"roles": mongoBSON.M{"name": role.Name, "contextvalue": role.ContextValue, "expiresat": *role.ExpiresAt},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, u.reload(ctx)
+}
+
 func UpdateRoleFromAllUsers(ctx context.Context, roleName, newRoleName, permissionCtx, desc string) error {
 	role, err := permission.FindRole(ctx, roleName)
 	if err != nil {
@@ -7,6 +7,7 @@ package auth
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/errors"
@@ -195,6 +196,71 @@ func (s *S) TestUserRemoveRole(c *check.C) {
 	c.Assert(uDB.Roles, check.DeepEquals, expected)
 }
 
+func (s *S) TestUserAddRoleUntil(c *check.C) {
+	ctx := context.TODO()
+	_, err := permission.NewRole(ctx, "r1", "team", "")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "me@tsuru.com", Password: "123"}
+	err = u.Create(ctx)
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(ctx, "r1", "c1")
+	c.Assert(err, check.IsNil)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	err = u.AddRoleUntil(ctx, "r1", "c1", expiresAt.Add(-time.Minute))
+	c.Assert(err, check.IsNil)
+	err = u.AddRoleUntil(ctx, "r1", "c1", expiresAt)
+	c.Assert(err, check.IsNil)
+	err = u.AddRoleUntil(ctx, "r2", "c1", expiresAt)
+	c.Assert(err, check.Equals, permTypes.ErrRoleNotFound)
+	uDB, err := GetUserByEmail(ctx, "me@tsuru.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(uDB.Roles, check.HasLen, 2)
+	c.Assert(uDB.Roles[0], check.DeepEquals, authTypes.RoleInstance{Name: "r1", ContextValue: "c1"})
+	c.Assert(uDB.Roles[1].ExpiresAt, check.NotNil)
+	c.Assert(uDB.Roles[1].ExpiresAt.Equal(expiresAt), check.Equals, true)
+	c.Assert(u.Roles, check.DeepEquals, uDB.Roles)
+}
+
+func (s *S) TestUserRemoveExpiredRole(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "r1", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app.read")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "me@tsuru.com", Password: "123"}
+	err = u.Create(ctx)
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	err = u.AddRoleUntil(ctx, "r1", "c1", now.Add(-time.Minute))
+	c.Assert(err, check.IsNil)
+	err = u.AddRoleUntil(ctx, "r1", "c2", now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	users, err := ListUsersWithExpiredRoles(ctx, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(users, check.HasLen, 1)
+	c.Assert(users[0].Roles, check.HasLen, 2)
+	expired := users[0].Roles[0]
+	c.Assert(expired.Expired(now), check.Equals, true)
+	c.Assert(users[0].Roles[1].Expired(now), check.Equals, false)
+	perms, err := users[0].Permissions(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppRead, permission.Context(permTypes.CtxTeam, "c1")), check.Equals, false)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppRead, permission.Context(permTypes.CtxTeam, "c2")), check.Equals, true)
+	removed, err := users[0].RemoveExpiredRole(ctx, expired)
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.Equals, true)
+	removed, err = users[0].RemoveExpiredRole(ctx, expired)
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.Equals, false)
+	uDB, err := GetUserByEmail(ctx, "me@tsuru.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(uDB.Roles, check.HasLen, 1)
+	c.Assert(uDB.Roles[0].ContextValue, check.Equals, "c2")
+	users, err = ListUsersWithExpiredRoles(ctx, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(users, check.HasLen, 0)
+}
+
 func (s *S) TestRemoveRoleFromAllUsers(c *check.C) {
 	u := User{
 		Email:    "me@tsuru.com",
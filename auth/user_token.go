@@ -0,0 +1,272 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/validation"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrUserTokenAlreadyExists = errors.New("user token already exists")
+	ErrUserTokenNotFound      = errors.New("user token not found")
+	ErrUserTokenExpired       = errors.New("user token expired")
+)
+
+// maxTokenExpiresIn is the longest expiration, in seconds, accepted for user
+// and team tokens, keeping the resulting duration far from overflowing.
+const maxTokenExpiresIn = 10 * 365 * 24 * 60 * 60
+
+type UserTokenCreateArgs struct {
+	TokenID     string   `json:"token_id" form:"token_id"`
+	Description string   `json:"description" form:"description"`
+	ExpiresIn   int      `json:"expires_in" form:"expires_in"`
+	Permissions []string `json:"permissions" form:"permissions"`
+}
+
+// UserToken is an API token of a user restricted to a subset of the
+// permissions of the user. Only the hash of the token is stored, so its
+// value is only known when created.
+type UserToken struct {
+	Token       string    `json:"token,omitempty" bson:"-"`
+	TokenHash   string    `json:"-"`
+	TokenID     string    `json:"token_id" bson:"token_id"`
+	Description string    `json:"description"`
+	Email       string    `json:"email"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at,omitempty"`
+	LastAccess  time.Time `json:"last_access" bson:"last_access,omitempty"`
+}
+
+type scopedToken struct {
+	UserToken
+}
+
+var _ authTypes.Token = &scopedToken{}
+
+func (t *scopedToken) GetValue() string {
+	return t.Token
+}
+
+func (t *scopedToken) User(ctx context.Context) (*authTypes.User, error) {
+	return ConvertOldUser(GetUserByEmail(ctx, t.Email))
+}
+
+func (t *scopedToken) GetUserName() string {
+	return t.Email
+}
+
+func (t *scopedToken) Engine() string {
+	return "usertoken"
+}
+
+// Permissions returns the permissions of the user within the schemes of the
+// token, so the token never grants more than the user currently has.
+func (t *scopedToken) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
+	userPerms, err := BaseTokenPermission(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	var schemes []*permTypes.PermissionScheme
+	for _, name := range t.UserToken.Permissions {
+		scheme, err := permission.SafeGet(name)
+		if err != nil {
+			continue
+		}
+		schemes = append(schemes, scheme)
+	}
+	return restrictPermissions(userPerms, schemes), nil
+}
+
+func restrictPermissions(perms []permTypes.Permission, schemes []*permTypes.PermissionScheme) []permTypes.Permission {
+	var result []permTypes.Permission
+	for _, perm := range perms {
+		for _, scheme := range schemes {
+			if scheme.IsParent(perm.Scheme) {
+				result = append(result, perm)
+				break
+			}
+			if perm.Scheme.IsParent(scheme) {
+				result = append(result, permTypes.Permission{Scheme: scheme, Context: perm.Context})
+			}
+		}
+	}
+	return result
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func UserTokenAuth(ctx context.Context, header string) (authTypes.Token, error) {
+	tokenStr, err := ParseToken(header)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := storagev2.UserTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	tokenHash := hashUserToken(tokenStr)
+	var storedToken UserToken
+	err = collection.FindOne(ctx, mongoBSON.M{"tokenhash": tokenHash}).Decode(&storedToken)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !storedToken.ExpiresAt.IsZero() && storedToken.ExpiresAt.Before(time.Now()) {
+		return nil, ErrUserTokenExpired
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"tokenhash": tokenHash}, mongoBSON.M{
+		"$set": mongoBSON.M{"last_access": time.Now().UTC()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	storedToken.Token = tokenStr
+	return &scopedToken{UserToken: storedToken}, nil
+}
+
+// CreateUserToken creates a token for the user of t restricted to the given
+// permissions, which t must have in at least one context. It's not possible
+// to delegate a permission only held for some of its children. When t is a
+// scoped token, the new token expires no later than t.
+func CreateUserToken(ctx context.Context, args UserTokenCreateArgs, t authTypes.Token) (UserToken, error) {
+	u, err := t.User(ctx)
+	if err != nil {
+		return UserToken{}, err
+	}
+	if u.FromToken {
+		return UserToken{}, &tsuruErrors.NotAuthorizedError{Message: "team tokens cannot create user tokens"}
+	}
+	if len(args.Permissions) == 0 {
+		return UserToken{}, &tsuruErrors.ValidationError{Message: "at least one permission is required"}
+	}
+	if args.ExpiresIn < 0 {
+		return UserToken{}, &tsuruErrors.ValidationError{Message: "expires_in must not be negative"}
+	}
+	if args.ExpiresIn > maxTokenExpiresIn {
+		return UserToken{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("expires_in must not be greater than %d", maxTokenExpiresIn)}
+	}
+	perms, err := t.Permissions(ctx)
+	if err != nil {
+		return UserToken{}, err
+	}
+	for _, name := range args.Permissions {
+		scheme, err := permission.SafeGet(name)
+		if err != nil || name == "" {
+			return UserToken{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid permission %q", name)}
+		}
+		if !holdsScheme(perms, scheme) {
+			return UserToken{}, &tsuruErrors.NotAuthorizedError{Message: fmt.Sprintf("cannot create token with permission %q not held by the user", name)}
+		}
+	}
+	now := time.Now().UTC()
+	resultToken := UserToken{
+		Token:       generateToken(u.Email, crypto.SHA256),
+		TokenID:     args.TokenID,
+		Description: args.Description,
+		Email:       u.Email,
+		Permissions: args.Permissions,
+		CreatedAt:   now,
+	}
+	if args.ExpiresIn > 0 {
+		resultToken.ExpiresAt = now.Add(time.Duration(args.ExpiresIn) * time.Second)
+	}
+	// Tokens created with a scoped token never outlive it.
+	if parent, ok := t.(*scopedToken); ok && !parent.ExpiresAt.IsZero() {
+		if resultToken.ExpiresAt.IsZero() || resultToken.ExpiresAt.After(parent.ExpiresAt) {
+			resultToken.ExpiresAt = parent.ExpiresAt
+		}
+	}
+	if resultToken.TokenID == "" {
+		resultToken.TokenID = fmt.Sprintf("token-%s", resultToken.Token[:5])
+	}
+	if !validation.ValidateName(resultToken.TokenID) {
+		return UserToken{}, &tsuruErrors.ValidationError{Message: "invalid token_id"}
+	}
+	resultToken.TokenHash = hashUserToken(resultToken.Token)
+	collection, err := storagev2.UserTokensCollection()
+	if err != nil {
+		return UserToken{}, err
+	}
+	_, err = collection.InsertOne(ctx, resultToken)
+	if mongo.IsDuplicateKeyError(err) {
+		return UserToken{}, ErrUserTokenAlreadyExists
+	}
+	if err != nil {
+		return UserToken{}, err
+	}
+	return resultToken, nil
+}
+
+func holdsScheme(perms []permTypes.Permission, scheme *permTypes.PermissionScheme) bool {
+	for _, perm := range perms {
+		if perm.Scheme.IsParent(scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func ListUserTokens(ctx context.Context, email string) ([]UserToken, error) {
+	collection, err := storagev2.UserTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"email": email}, options.Find().SetSort(mongoBSON.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var tokens []UserToken
+	err = cursor.All(ctx, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func DeleteUserToken(ctx context.Context, email, tokenID string) error {
+	collection, err := storagev2.UserTokensCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"email": email, "token_id": tokenID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrUserTokenNotFound
+	}
+	return nil
+}
+
+func deleteAllUserTokens(ctx context.Context, email string) error {
+	collection, err := storagev2.UserTokensCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"email": email})
+	return err
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRestrictPermissions(c *check.C) {
+	perms := []permTypes.Permission{
+		{Scheme: permission.PermApp, Context: permission.Context(permTypes.CtxTeam, "cobrateam")},
+		{Scheme: permission.PermTeamRead, Context: permission.Context(permTypes.CtxGlobal, "")},
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxApp, "myapp")},
+	}
+	result := restrictPermissions(perms, []*permTypes.PermissionScheme{permission.PermAppDeploy, permission.PermTeam})
+	c.Assert(result, check.DeepEquals, []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxTeam, "cobrateam")},
+		{Scheme: permission.PermTeamRead, Context: permission.Context(permTypes.CtxGlobal, "")},
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxApp, "myapp")},
+	})
+	c.Assert(restrictPermissions(perms, nil), check.IsNil)
+}
+
+func (s *S) TestCreateUserToken(c *check.C) {
+	t := &userToken{user: s.user, permissions: []permTypes.Permission{
+		{Scheme: permission.PermApp, Context: permission.Context(permTypes.CtxTeam, "cobrateam")},
+	}}
+	token, err := CreateUserToken(context.TODO(), UserTokenCreateArgs{
+		TokenID:     "ci",
+		Description: "deploys from ci",
+		ExpiresIn:   60 * 60,
+		Permissions: []string{"app.deploy", "app.read"},
+	}, t)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Not(check.Equals), "")
+	c.Assert(token.TokenHash, check.Equals, hashUserToken(token.Token))
+	c.Assert(token.Email, check.Equals, s.user.Email)
+	c.Assert(token.ExpiresAt.Sub(token.CreatedAt), check.Equals, time.Hour)
+	tokens, err := ListUserTokens(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].TokenID, check.Equals, "ci")
+	c.Assert(tokens[0].Token, check.Equals, "")
+	c.Assert(tokens[0].Permissions, check.DeepEquals, []string{"app.deploy", "app.read"})
+	_, err = CreateUserToken(context.TODO(), UserTokenCreateArgs{
+		TokenID:     "ci",
+		Permissions: []string{"app.deploy"},
+	}, t)
+	c.Assert(err, check.Equals, ErrUserTokenAlreadyExists)
+}
+
+func (s *S) TestCreateUserTokenInvalidPermissions(c *check.C) {
+	t := &userToken{user: s.user, permissions: []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxGlobal, "")},
+	}}
+	_, err := CreateUserToken(context.TODO(), UserTokenCreateArgs{}, t)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = CreateUserToken(context.TODO(), UserTokenCreateArgs{Permissions: []string{"app.unknown"}}, t)
+	c.Assert(err, check.ErrorMatches, `invalid permission "app.unknown"`)
+	_, err = CreateUserToken(context.TODO(), UserTokenCreateArgs{Permissions: []string{"app"}}, t)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.NotAuthorizedError{})
+	_, err = CreateUserToken(context.TODO(), UserTokenCreateArgs{TokenID: "invalid id", Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.ErrorMatches, "invalid token_id")
+	_, err = CreateUserToken(context.TODO(), UserTokenCreateArgs{ExpiresIn: -1, Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = CreateUserToken(context.TODO(), UserTokenCreateArgs{ExpiresIn: maxTokenExpiresIn + 1, Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *S) TestUserTokenAuth(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "team-member", string(permTypes.CtxTeam), "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app", "team")
+	c.Assert(err, check.IsNil)
+	err = s.user.AddRole(ctx, "team-member", "cobrateam")
+	c.Assert(err, check.IsNil)
+	userPerms, err := s.user.Permissions(ctx)
+	c.Assert(err, check.IsNil)
+	created, err := CreateUserToken(ctx, UserTokenCreateArgs{Permissions: []string{"app.deploy"}}, &userToken{user: s.user, permissions: userPerms})
+	c.Assert(err, check.IsNil)
+	t, err := UserTokenAuth(ctx, "bearer "+created.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.GetUserName(), check.Equals, s.user.Email)
+	c.Assert(t.GetValue(), check.Equals, created.Token)
+	c.Assert(t.Engine(), check.Equals, "usertoken")
+	perms, err := t.Permissions(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(perms, check.DeepEquals, []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxTeam, "cobrateam")},
+	})
+	tokens, err := ListUserTokens(ctx, s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].LastAccess.IsZero(), check.Equals, false)
+	err = s.user.RemoveRole(ctx, "team-member", "cobrateam")
+	c.Assert(err, check.IsNil)
+	perms, err = t.Permissions(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(perms, check.HasLen, 0)
+}
+
+func (s *S) TestUserTokenAuthExpired(c *check.C) {
+	t := &userToken{user: s.user, permissions: []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxGlobal, "")},
+	}}
+	created, err := CreateUserToken(context.TODO(), UserTokenCreateArgs{ExpiresIn: 60, Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.UserTokensCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"token_id": created.TokenID}, mongoBSON.M{
+		"$set": mongoBSON.M{"expires_at": time.Now().Add(-time.Minute)},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = UserTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.Equals, ErrUserTokenExpired)
+	_, err = UserTokenAuth(context.TODO(), "bearer invalid-token")
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestCreateUserTokenFromScopedTokenKeepsExpiration(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "token-creator", string(permTypes.CtxGlobal), "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app.deploy", "user.token.create")
+	c.Assert(err, check.IsNil)
+	err = s.user.AddRole(ctx, "token-creator", "")
+	c.Assert(err, check.IsNil)
+	userPerms, err := s.user.Permissions(ctx)
+	c.Assert(err, check.IsNil)
+	parent, err := CreateUserToken(ctx, UserTokenCreateArgs{
+		TokenID:     "parent",
+		ExpiresIn:   60,
+		Permissions: []string{"app.deploy", "user.token.create"},
+	}, &userToken{user: s.user, permissions: userPerms})
+	c.Assert(err, check.IsNil)
+	t, err := UserTokenAuth(ctx, "bearer "+parent.Token)
+	c.Assert(err, check.IsNil)
+	expiresAt := t.(*scopedToken).ExpiresAt
+	c.Assert(expiresAt.IsZero(), check.Equals, false)
+	unbounded, err := CreateUserToken(ctx, UserTokenCreateArgs{TokenID: "unbounded", Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.IsNil)
+	c.Assert(unbounded.ExpiresAt.Equal(expiresAt), check.Equals, true)
+	longer, err := CreateUserToken(ctx, UserTokenCreateArgs{TokenID: "longer", ExpiresIn: 3600, Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.IsNil)
+	c.Assert(longer.ExpiresAt.Equal(expiresAt), check.Equals, true)
+	shorter, err := CreateUserToken(ctx, UserTokenCreateArgs{TokenID: "shorter", ExpiresIn: 10, Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.IsNil)
+	c.Assert(shorter.ExpiresAt.Before(expiresAt), check.Equals, true)
+}
+
+func (s *S) TestDeleteUserToken(c *check.C) {
+	t := &userToken{user: s.user, permissions: []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxGlobal, "")},
+	}}
+	created, err := CreateUserToken(context.TODO(), UserTokenCreateArgs{TokenID: "ci", Permissions: []string{"app.deploy"}}, t)
+	c.Assert(err, check.IsNil)
+	err = DeleteUserToken(context.TODO(), "other@globo.com", "ci")
+	c.Assert(err, check.Equals, ErrUserTokenNotFound)
+	err = DeleteUserToken(context.TODO(), s.user.Email, "ci")
+	c.Assert(err, check.IsNil)
+	_, err = UserTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	err = DeleteUserToken(context.TODO(), s.user.Email, "ci")
+	c.Assert(err, check.Equals, ErrUserTokenNotFound)
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildertest provides helpers for testing code that builds app
+// sources.
+package buildertest
+
+import (
+	"fmt"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitServer serves git repositories over https using git http-backend.
+// Clients must trust its certificate, e.g. with GIT_SSL_NO_VERIFY=1.
+type GitServer struct {
+	*httptest.Server
+	root string
+}
+
+// NewGitServer starts a GitServer serving the repositories created with
+// NewRepository.
+func NewGitServer() (*GitServer, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.MkdirTemp("", "tsuru-git-server-")
+	if err != nil {
+		return nil, err
+	}
+	handler := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	return &GitServer{Server: httptest.NewTLSServer(handler), root: root}, nil
+}
+
+// NewRepository creates a repository with a single commit adding the given
+// files, returning its url and the commit hash.
+func (s *GitServer) NewRepository(name string, files map[string]string) (string, string, error) {
+	dir := filepath.Join(s.root, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	commands := [][]string{{"init", "-q"}}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			return "", "", err
+		}
+		commands = append(commands, []string{"add", file})
+	}
+	commands = append(commands, []string{"-c", "user.name=tsuru", "-c", "user.email=tsuru@tsuru.io", "commit", "-q", "-m", "first"})
+	for _, args := range commands {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			return "", "", fmt.Errorf("%w: %s", err, out)
+		}
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", "", err
+	}
+	return s.URL + "/" + name, strings.TrimSpace(string(out)), nil
+}
+
+// Close stops the server and removes its repositories.
+func (s *GitServer) Close() {
+	s.Server.Close()
+	os.RemoveAll(s.root)
+}
@@ -0,0 +1,414 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const (
+	defaultGitMaxArchiveSize = 512 * 1024 * 1024
+	defaultGitMaxCloneSize   = 1024 * 1024 * 1024
+
+	gitCloneSizeCheckInterval = time.Second
+)
+
+var (
+	ErrInvalidGitURL      = errors.New("git url must be an https:// or ssh:// url")
+	ErrInvalidGitRef      = errors.New("invalid git ref")
+	ErrGitArchiveTooLarge = errors.New("git repository exceeds the maximum archive size")
+	ErrGitCloneTooLarge   = errors.New("git repository exceeds the maximum clone size")
+)
+
+// GitArchive is the source of a repository ref cloned by tsuru, packed as an
+// archive to be sent to the builder.
+type GitArchive struct {
+	File   io.ReadCloser
+	Size   int64
+	Commit string
+}
+
+// GitScope identifies who a repository is cloned for, restricting the
+// credentials from the config that may be used in the clone.
+type GitScope struct {
+	Team string
+	Pool string
+}
+
+type gitCredential struct {
+	prefix   string
+	username string
+	password string
+	teams    []string
+	pools    []string
+}
+
+func (cred *gitCredential) matches(repoURL string, scope GitScope) bool {
+	if !strings.HasPrefix(repoURL, cred.prefix) {
+		return false
+	}
+	// a prefix must end at a path boundary, so https://github.com/org does
+	// not match https://github.com/org-other/repo.git.
+	if !strings.HasSuffix(cred.prefix, "/") && len(repoURL) > len(cred.prefix) && repoURL[len(cred.prefix)] != '/' {
+		return false
+	}
+	if len(cred.teams) > 0 && !slices.Contains(cred.teams, scope.Team) {
+		return false
+	}
+	if len(cred.pools) > 0 && !slices.Contains(cred.pools, scope.Pool) {
+		return false
+	}
+	return true
+}
+
+func stringList(value interface{}) []string {
+	rawList, _ := value.([]interface{})
+	var result []string
+	for _, raw := range rawList {
+		if str, ok := raw.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
+func gitCredentialsConfig() ([]gitCredential, error) {
+	data, err := config.Get("git:credentials")
+	if err != nil {
+		return nil, nil
+	}
+	rawCreds, ok := data.([]interface{})
+	if !ok {
+		return nil, errors.New("git:credentials must be a list")
+	}
+	var creds []gitCredential
+	for _, rawCred := range rawCreds {
+		entry, ok := rawCred.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.New("invalid git credential, expected prefix, username and password")
+		}
+		var cred gitCredential
+		cred.prefix, _ = entry["prefix"].(string)
+		cred.username, _ = entry["username"].(string)
+		cred.password, _ = entry["password"].(string)
+		cred.teams = stringList(entry["teams"])
+		cred.pools = stringList(entry["pools"])
+		u, err := url.Parse(cred.prefix)
+		if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+			return nil, errors.Errorf("invalid git credential prefix %q, expected an https:// url", cred.prefix)
+		}
+		if cred.password == "" {
+			return nil, errors.Errorf("git credential for %q has no password", cred.prefix)
+		}
+		if cred.username == "" {
+			cred.username = "git"
+		}
+		creds = append(creds, cred)
+	}
+	return creds, nil
+}
+
+// gitCredentials returns the environment used to authenticate the clone of
+// the repository, using the first entry of git:credentials whose prefix
+// matches the url and whose teams and pools, when set, include the scope.
+// Repositories not matching any entry are cloned anonymously. The credentials
+// are sent as a header, keeping them out of the command line and of the clone
+// output.
+func gitCredentials(repoURL string, scope GitScope) ([]string, error) {
+	creds, err := gitCredentialsConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, cred := range creds {
+		if !cred.matches(repoURL, scope) {
+			continue
+		}
+		auth := base64.StdEncoding.EncodeToString([]byte(cred.username + ":" + cred.password))
+		return []string{
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+		}, nil
+	}
+	return nil, nil
+}
+
+// ValidateGitSource checks the repository url and ref of git-url deploys,
+// accepting only https and ssh urls and refusing values git could take as
+// options.
+func ValidateGitSource(repoURL, ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return ErrInvalidGitRef
+	}
+	if strings.HasPrefix(repoURL, "-") {
+		return ErrInvalidGitURL
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ErrInvalidGitURL
+	}
+	if u.Scheme != "https" && u.Scheme != "ssh" {
+		return ErrInvalidGitURL
+	}
+	if u.Hostname() == "" || strings.HasPrefix(u.Hostname(), "-") {
+		return ErrInvalidGitURL
+	}
+	return nil
+}
+
+func gitMaxArchiveSize() int64 {
+	size, err := config.GetInt("git:max-archive-size")
+	if err != nil || size <= 0 {
+		return defaultGitMaxArchiveSize
+	}
+	return int64(size)
+}
+
+func gitMaxCloneSize() int64 {
+	size, err := config.GetInt("git:max-clone-size")
+	if err != nil || size <= 0 {
+		return defaultGitMaxCloneSize
+	}
+	return int64(size)
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// files may be renamed by git while walking
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// watchCloneSize cancels the clone once dir grows past maxSize, as neither
+// fetch nor checkout can be limited by git itself.
+func watchCloneSize(ctx context.Context, cancel context.CancelCauseFunc, dir string, maxSize int64) {
+	ticker := time.NewTicker(gitCloneSizeCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if size, err := dirSize(dir); err == nil && size > maxSize {
+			cancel(ErrGitCloneTooLarge)
+			return
+		}
+	}
+}
+
+// gitPassthroughEnv are the variables of the API environment kept in git
+// commands, only used to reach the repository and verify its certificate.
+var gitPassthroughEnv = []string{
+	"PATH",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY",
+	"http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+	"GIT_SSL_CAINFO", "GIT_SSL_CAPATH", "GIT_SSL_NO_VERIFY",
+}
+
+// gitEnv returns a minimal environment for git commands, so that the
+// configs, credential helpers, ssh keys and ssh agent of the user running the
+// API are never used to clone repositories of apps.
+func gitEnv(home string) []string {
+	env := []string{
+		"HOME=" + home,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL=" + os.DevNull,
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_SSH_COMMAND=ssh -o BatchMode=yes -o IdentitiesOnly=yes -o IdentityFile=" + os.DevNull + " -F " + os.DevNull,
+	}
+	for _, name := range gitPassthroughEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// ArchiveFromGit clones the ref (a branch, tag or commit, defaulting to HEAD)
+// of the repository and packs its files, without the .git directory, in a
+// tar.gz archive. The clone is aborted once it takes more than
+// git:max-clone-size bytes on disk.
+func ArchiveFromGit(ctx context.Context, repoURL, ref string, scope GitScope, w io.Writer) (*GitArchive, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if w == nil {
+		w = io.Discard
+	}
+	if err := ValidateGitSource(repoURL, ref); err != nil {
+		return nil, err
+	}
+	env, err := gitCredentials(repoURL, scope)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "tsuru-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	cloneCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	maxCloneSize := gitMaxCloneSize()
+	go watchCloneSize(cloneCtx, cancel, dir, maxCloneSize)
+	home, err := os.MkdirTemp("", "tsuru-git-home-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(home)
+	env = append(gitEnv(home), env...)
+	runGit := func(stdout io.Writer, args ...string) error {
+		cmd := exec.CommandContext(cloneCtx, "git", append([]string{"-C", dir, "-c", "protocol.file.allow=never"}, args...)...)
+		cmd.Env = env
+		cmd.Stdout = stdout
+		cmd.Stderr = w
+		if err := cmd.Run(); err != nil {
+			if cause := context.Cause(cloneCtx); cause == ErrGitCloneTooLarge {
+				return cause
+			}
+			return errors.Wrapf(err, "unable to run git %s", args[0])
+		}
+		return nil
+	}
+	fmt.Fprintf(w, "---- Cloning %s (%s) ----\n", repoURL, ref)
+	if err = runGit(w, "init", "-q"); err != nil {
+		return nil, err
+	}
+	if err = runGit(w, "fetch", "-q", "--depth", "1", "--", repoURL, ref); err != nil {
+		return nil, err
+	}
+	if err = runGit(w, "checkout", "-q", "FETCH_HEAD"); err != nil {
+		return nil, err
+	}
+	size, err := dirSize(dir)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxCloneSize {
+		return nil, ErrGitCloneTooLarge
+	}
+	var commit bytes.Buffer
+	if err = runGit(&commit, "rev-parse", "HEAD"); err != nil {
+		return nil, err
+	}
+	archive, err := archiveDir(dir, gitMaxArchiveSize())
+	if err != nil {
+		return nil, err
+	}
+	result := &GitArchive{
+		File:   io.NopCloser(archive),
+		Size:   int64(archive.Len()),
+		Commit: strings.TrimSpace(commit.String()),
+	}
+	fmt.Fprintf(w, "---- Resolved %s to commit %s ----\n", ref, result.Commit)
+	return result, nil
+}
+
+// limitedBuffer is a bytes.Buffer refusing writes past a maximum size.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int64
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if int64(b.Len()+len(p)) > b.max {
+		return 0, ErrGitArchiveTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+func archiveDir(dir string, maxSize int64) (*bytes.Buffer, error) {
+	buf := limitedBuffer{max: maxSize}
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil || name == "." {
+			return err
+		}
+		if d.IsDir() && name == ".git" {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tarWriter, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return &buf.Buffer, nil
+}
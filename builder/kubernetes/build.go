@@ -13,8 +13,10 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
 	buildpb "github.com/tsuru/deploy-agent/pkg/build/grpc_build_v1"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"sigs.k8s.io/yaml"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/tsuru/tsuru/app/version"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
 	provisionk8s "github.com/tsuru/tsuru/provision/kubernetes"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -36,8 +39,17 @@ var (
 	_ builder.PlatformBuilder = &kubernetesBuilder{}
 
 	allowedHealthcheckValues = getJSONFieldNames(&provisiontypes.TsuruYamlHealthcheck{})
+
+	buildsInProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tsuru_builder_builds_in_progress",
+		Help: "The number of builds currently running in the deploy agent",
+	}, []string{"kind"})
 )
 
+// requestIDMetadataKey is the gRPC metadata carrying the ID of the API request
+// that triggered the build, so deploy agent logs can be correlated with it.
+const requestIDMetadataKey = "x-request-id"
+
 type processCommands struct {
 	commands  []string
 	definedOn string
@@ -47,6 +59,7 @@ type kubernetesBuilder struct{}
 
 func init() {
 	builder.Register("kubernetes", &kubernetesBuilder{})
+	prometheus.MustRegister(buildsInProgress)
 }
 
 func (b *kubernetesBuilder) Build(ctx context.Context, app *apptypes.App, evt *event.Event, opts builder.BuildOpts) (apptypes.AppVersion, error) {
@@ -284,10 +297,13 @@ func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptyp
 	}
 	defer conn.Close()
 
+	// The tag is only set by builds not followed by a deploy, it keeps the
+	// version from being garbage collected so it can be deployed later.
 	appVersion, err := servicemanager.AppVersion.NewAppVersion(ctx, apptypes.NewVersionArgs{
-		App:         app,
-		EventID:     evt.UniqueID.Hex(),
-		Description: opts.Message,
+		App:            app,
+		EventID:        evt.UniqueID.Hex(),
+		Description:    opts.Message,
+		CustomBuildTag: opts.Tag,
 	})
 	if err != nil {
 		return nil, err
@@ -499,6 +515,14 @@ func findDeprecatedHealthcheckData(w io.Writer, tsuruYaml string) {
 }
 
 func callBuildService(ctx context.Context, bc buildpb.BuildClient, req *buildpb.BuildRequest, w io.Writer) (*buildpb.TsuruConfig, error) {
+	kind := strings.ToLower(strings.TrimPrefix(req.GetKind().String(), "BUILD_KIND_"))
+	buildsInProgress.WithLabelValues(kind).Inc()
+	defer buildsInProgress.WithLabelValues(kind).Dec()
+
+	if requestID := log.RequestIDFromContext(ctx); requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDMetadataKey, requestID)
+	}
+
 	stream, err := bc.Build(ctx, req)
 	if err != nil {
 		return nil, err
@@ -575,6 +599,7 @@ func parseTsuruYaml(str string) (provisiontypes.TsuruYamlData, error) {
 
 func tsuruYamlDataToCustomData(tsuruYaml provisiontypes.TsuruYamlData) map[string]any {
 	return map[string]any{
+		"deploy":      tsuruYaml.Deploy,
 		"healthcheck": tsuruYaml.Healthcheck,
 		"hooks":       tsuruYaml.Hooks,
 		"kubernetes":  tsuruYaml.Kubernetes,
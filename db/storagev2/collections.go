@@ -6,6 +6,7 @@ package storagev2
 
 import (
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 )
 
 func AppsCollection() (*mongo.Collection, error) {
@@ -64,6 +65,10 @@ func TeamTokensCollection() (*mongo.Collection, error) {
 	return Collection("team_tokens")
 }
 
+func UserTokensCollection() (*mongo.Collection, error) {
+	return Collection("user_tokens")
+}
+
 func TeamsCollection() (*mongo.Collection, error) {
 	return Collection("teams")
 }
@@ -72,10 +77,18 @@ func PlansCollection() (*mongo.Collection, error) {
 	return Collection("plans")
 }
 
+func PlanDefaultsCollection() (*mongo.Collection, error) {
+	return Collection("plan_defaults")
+}
+
 func WebhookCollection() (*mongo.Collection, error) {
 	return Collection("webhook")
 }
 
+func WebhookDeliveriesCollection() (*mongo.Collection, error) {
+	return Collection("webhook_deliveries")
+}
+
 func VolumesCollection() (*mongo.Collection, error) {
 	return Collection("volumes")
 }
@@ -104,10 +117,42 @@ func AuthGroupsCollection() (*mongo.Collection, error) {
 	return Collection("auth_groups")
 }
 
+func DeployUploadsCollection() (*mongo.Collection, error) {
+	return Collection("deploy_uploads")
+}
+
+func DeployUploadChunksBucket() (*gridfs.Bucket, error) {
+	return Bucket("deploy_upload_chunks")
+}
+
+func ShellRecordingsBucket() (*gridfs.Bucket, error) {
+	return Bucket("shell_recordings")
+}
+
+func DeploySnapshotsCollection() (*mongo.Collection, error) {
+	return Collection("deploy_snapshots")
+}
+
+func ConfigSnapshotsCollection() (*mongo.Collection, error) {
+	return Collection("config_snapshots")
+}
+
+func RegistryUsageCollection() (*mongo.Collection, error) {
+	return Collection("registry_usage")
+}
+
 func MigrationsCollection() (*mongo.Collection, error) {
 	return Collection("migrations")
 }
 
+func AdmissionPoliciesCollection() (*mongo.Collection, error) {
+	return Collection("admission_policies")
+}
+
+func OIDCSessionsCollection() (*mongo.Collection, error) {
+	return Collection("oidc_sessions")
+}
+
 func OAuth2TokensCollection() (*mongo.Collection, error) {
 	collectionName := getOAuthTokensCollectionName()
 	return Collection(collectionName)
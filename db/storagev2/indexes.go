@@ -45,10 +45,21 @@ var EnsureIndexes = []EnsureIndex{
 			{
 				Keys: mongoBSON.D{{Key: "uniqueid", Value: 1}},
 			},
+			{
+				Keys:    mongoBSON.D{{Key: "requestid", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "parentid", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
 			{
 				Keys:    mongoBSON.D{{Key: "running", Value: 1}},
 				Options: &options.IndexOptions{},
 			},
+			{
+				Keys: mongoBSON.D{{Key: "endtime", Value: 1}, {Key: "uniqueid", Value: 1}},
+			},
 			{
 				Keys: mongoBSON.D{{Key: "allowed.scheme", Value: 1}},
 			},
@@ -125,6 +136,29 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "oidc_sessions",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "refreshtokenhash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: mongoBSON.D{{Key: "usedrefreshtokenhashes", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "idtokenhash", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "email", Value: 1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expiresat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	},
+
 	{
 		Collection: "users",
 		Indexes: []mongo.IndexModel{
@@ -135,6 +169,14 @@ var EnsureIndexes = []EnsureIndex{
 			{
 				Keys: mongoBSON.D{{Key: "apikey", Value: 1}},
 			},
+			{
+				Keys:    mongoBSON.D{{Key: "roles.expiresat", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "directorygroups", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
 		},
 	},
 
@@ -150,6 +192,25 @@ var EnsureIndexes = []EnsureIndex{
 				Keys:    mongoBSON.D{{Key: "token_id", Value: 1}},
 				Options: options.Index().SetUnique(true),
 			},
+
+			{
+				Keys:    mongoBSON.D{{Key: "previous_token", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
+		},
+	},
+
+	{
+		Collection: "user_tokens",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "tokenhash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "email", Value: 1}, {Key: "token_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
 		},
 	},
 
@@ -173,6 +234,26 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "autoredeploys",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "app", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
+	{
+		Collection: "admission_policies",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
 	{
 		Collection: "webhook",
 		Indexes: []mongo.IndexModel{
@@ -183,6 +264,22 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "webhook_deliveries",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "webhook", Value: 1}, {Key: "_id", Value: -1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "status", Value: 1}, {Key: "nextattempt", Value: 1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
 	{
 		Collection: "service_broker",
 		Indexes: []mongo.IndexModel{
@@ -210,6 +307,9 @@ var EnsureIndexes = []EnsureIndex{
 				Keys:    mongoBSON.D{{Key: "name", Value: 1}},
 				Options: options.Index().SetUnique(true),
 			},
+			{
+				Keys: mongoBSON.D{{Key: "tags", Value: 1}},
+			},
 		},
 	},
 
@@ -223,6 +323,69 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "plan_defaults",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "pool", Value: 1}, {Key: "team", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
+	{
+		Collection: "unit_history",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "appname", Value: 1}, {Key: "unit", Value: 1}, {Key: "time", Value: -1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
+	{
+		Collection: "usage_samples",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "time", Value: 1}, {Key: "team", Value: 1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
+	{
+		Collection: "job_executions",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "job", Value: 1}, {Key: "id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: mongoBSON.D{{Key: "job", Value: 1}, {Key: "starttime", Value: -1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
+	{
+		Collection: "job_quotas",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "scope", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
 	{
 		GetCollectionName: getOAuthTokensCollectionName,
 		Indexes: []mongo.IndexModel{
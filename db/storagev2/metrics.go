@@ -0,0 +1,48 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagev2
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var (
+	poolConnectionsOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tsuru_mongodb_pool_connections_open",
+		Help: "The number of connections open in the MongoDB connection pool",
+	})
+
+	poolConnectionsInUse = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tsuru_mongodb_pool_connections_in_use",
+		Help: "The number of connections checked out from the MongoDB connection pool",
+	})
+
+	poolCheckoutFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_mongodb_pool_checkout_failures_total",
+		Help: "The total number of failures checking out connections from the MongoDB connection pool",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(poolConnectionsOpen, poolConnectionsInUse, poolCheckoutFailures)
+}
+
+var poolMonitor = &event.PoolMonitor{
+	Event: func(evt *event.PoolEvent) {
+		switch evt.Type {
+		case event.ConnectionCreated:
+			poolConnectionsOpen.Inc()
+		case event.ConnectionClosed:
+			poolConnectionsOpen.Dec()
+		case event.GetSucceeded:
+			poolConnectionsInUse.Inc()
+		case event.ConnectionReturned:
+			poolConnectionsInUse.Dec()
+		case event.GetFailed:
+			poolCheckoutFailures.WithLabelValues(evt.Reason).Inc()
+		}
+	},
+}
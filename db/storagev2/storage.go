@@ -18,6 +18,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/bson/bsonrw"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -147,7 +148,8 @@ func connect() (*mongo.Client, *string, error) {
 				NilSliceAsEmpty: true,
 				NilMapAsEmpty:   true,
 			}).
-			SetMonitor(monitor),
+			SetMonitor(monitor).
+			SetPoolMonitor(poolMonitor),
 	)
 	if err != nil {
 		return nil, nil, err
@@ -200,6 +202,15 @@ func Collection(name string) (*mongo.Collection, error) {
 	return db.Collection(name, options.Collection()), nil
 }
 
+func Bucket(name string) (*gridfs.Bucket, error) {
+	db, err := database()
+	if err != nil {
+		return nil, err
+	}
+
+	return gridfs.NewBucket(db, options.GridFSBucket().SetName(name))
+}
+
 func database() (*mongo.Database, error) {
 	connectedClient := client.Load()
 	databaseName := databaseNamePtr.Load()
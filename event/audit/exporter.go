@@ -0,0 +1,310 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audit exports finished events to an external audit sink, in signed
+// and hash chained batches.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	exportStateCollectionName = "audit_export"
+	exportStateID             = "events"
+
+	defaultBatchSize    = 100
+	defaultInterval     = 10 * time.Second
+	defaultRetries      = 5
+	defaultRetryBackoff = time.Second
+
+	// exportDelay keeps events finished in the last seconds out of the
+	// batches, as they may still be written after events finished later.
+	exportDelay = 30 * time.Second
+)
+
+var (
+	exportedEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_audit_exported_events_total",
+		Help: "The number of events exported to the audit sink.",
+	})
+	exportFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_audit_export_failures_total",
+		Help: "The number of batches of events not delivered to the audit sink after all retries.",
+	})
+)
+
+// exportState is the cursor of the exporter, along with the lease held by the
+// API instance currently exporting events.
+type exportState struct {
+	ID          string `bson:"_id"`
+	Seq         int64
+	LastHash    string
+	EndTime     time.Time
+	UniqueID    primitive.ObjectID
+	Owner       string
+	LockedUntil time.Time
+}
+
+type exporter struct {
+	sink         sink
+	key          []byte
+	kinds        []string
+	batchSize    int
+	interval     time.Duration
+	retries      int
+	retryBackoff time.Duration
+	owner        string
+	stopCh       chan struct{}
+	doneCh       chan struct{}
+}
+
+// StartExporter starts a background loop exporting finished events to the
+// configured audit sink. It's only started when audit:enabled is set. Every
+// API instance runs the loop, but only the one holding the lease exports.
+func StartExporter() error {
+	enabled, _ := config.GetBool("audit:enabled")
+	if !enabled {
+		return nil
+	}
+	e, err := exporterFromConfig()
+	if err != nil {
+		return err
+	}
+	go e.spin()
+	shutdown.Register(e)
+	return nil
+}
+
+func exporterFromConfig() (*exporter, error) {
+	key, _ := config.GetString("audit:signing-key")
+	if key == "" {
+		return nil, errors.New("audit:signing-key is required when audit:enabled is set")
+	}
+	s, err := sinkFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	kinds, _ := config.GetList("audit:kinds")
+	batchSize, _ := config.GetInt("audit:batch-size")
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	interval, _ := config.GetDuration("audit:interval")
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	retries, err := config.GetInt("audit:retries")
+	if err != nil || retries < 0 {
+		retries = defaultRetries
+	}
+	retryBackoff, _ := config.GetDuration("audit:retry-backoff")
+	if retryBackoff <= 0 {
+		retryBackoff = defaultRetryBackoff
+	}
+	hostname, _ := os.Hostname()
+	return &exporter{
+		sink:         s,
+		key:          []byte(key),
+		kinds:        kinds,
+		batchSize:    batchSize,
+		interval:     interval,
+		retries:      retries,
+		retryBackoff: retryBackoff,
+		owner:        fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}, nil
+}
+
+func (e *exporter) spin() {
+	defer close(e.doneCh)
+	for {
+		for {
+			n, err := e.export(context.Background(), time.Now().UTC())
+			if err != nil {
+				log.Errorf("[audit] %v", err)
+			}
+			if err != nil || n < e.batchSize {
+				break
+			}
+		}
+		select {
+		case <-e.stopCh:
+			return
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+func (e *exporter) Shutdown(ctx context.Context) error {
+	close(e.stopCh)
+	select {
+	case <-e.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (e *exporter) leaseDuration() time.Duration {
+	return 2*e.interval + time.Duration(e.retries+1)*time.Minute
+}
+
+// acquireLease returns the state of the export when the lease is free,
+// expired or already held by this exporter, and nil otherwise.
+func (e *exporter) acquireLease(ctx context.Context, now time.Time) (*exportState, error) {
+	collection, err := storagev2.Collection(exportStateCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	var state exportState
+	err = collection.FindOneAndUpdate(ctx, mongoBSON.M{
+		"_id": exportStateID,
+		"$or": []mongoBSON.M{
+			{"lockeduntil": mongoBSON.M{"$lt": now}},
+			{"owner": e.owner},
+		},
+	}, mongoBSON.M{
+		"$set": mongoBSON.M{"owner": e.owner, "lockeduntil": now.Add(e.leaseDuration())},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&state)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (e *exporter) saveState(ctx context.Context, state *exportState) error {
+	collection, err := storagev2.Collection(exportStateCollectionName)
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": exportStateID, "owner": e.owner}, mongoBSON.M{
+		"$set": mongoBSON.M{
+			"seq":      state.Seq,
+			"lasthash": state.LastHash,
+			"endtime":  state.EndTime,
+			"uniqueid": state.UniqueID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("lease lost while exporting events, they may be exported again")
+	}
+	return nil
+}
+
+// kindsQuery matches the names of the kinds in audit:kinds, where a trailing
+// "*" matches any suffix, e.g. "app.*". All kinds match when it's empty.
+func kindsQuery(kinds []string) mongoBSON.M {
+	var patterns []string
+	for _, k := range kinds {
+		if k == "*" || k == "" {
+			return nil
+		}
+		if strings.HasSuffix(k, "*") {
+			patterns = append(patterns, regexp.QuoteMeta(strings.TrimSuffix(k, "*")))
+			continue
+		}
+		patterns = append(patterns, regexp.QuoteMeta(k)+"$")
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return mongoBSON.M{"$regex": "^(" + strings.Join(patterns, "|") + ")"}
+}
+
+func (e *exporter) pendingEvents(ctx context.Context, state *exportState, now time.Time) ([]*event.Event, error) {
+	query := mongoBSON.M{
+		"$or": []mongoBSON.M{
+			{"endtime": mongoBSON.M{"$gt": state.EndTime, "$lte": now.Add(-exportDelay)}},
+			{"endtime": state.EndTime, "uniqueid": mongoBSON.M{"$gt": state.UniqueID}},
+		},
+	}
+	if kinds := kindsQuery(e.kinds); kinds != nil {
+		query["kind.name"] = kinds
+	}
+	running := false
+	return event.List(ctx, &event.Filter{
+		Running: &running,
+		Raw:     query,
+		Sort:    "endtime",
+		Limit:   e.batchSize,
+	})
+}
+
+// export sends the next batch of finished events to the sink, advancing the
+// cursor only when it's delivered. It returns the number of events exported.
+func (e *exporter) export(ctx context.Context, now time.Time) (int, error) {
+	state, err := e.acquireLease(ctx, now)
+	if err != nil || state == nil {
+		return 0, err
+	}
+	evts, err := e.pendingEvents(ctx, state, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(evts) == 0 {
+		return 0, nil
+	}
+	batch := newBatch(state.Seq, state.LastHash, evts, e.key)
+	err = e.send(ctx, batch)
+	if err != nil {
+		exportFailures.Inc()
+		return 0, errors.Wrapf(err, "unable to export events %d to %d", batch.FirstSeq, batch.LastSeq)
+	}
+	last := evts[len(evts)-1]
+	state.Seq = batch.LastSeq
+	state.LastHash = batch.Records[len(batch.Records)-1].Hash
+	state.EndTime = last.EndTime
+	state.UniqueID = last.UniqueID
+	err = e.saveState(ctx, state)
+	if err != nil {
+		return 0, err
+	}
+	exportedEvents.Add(float64(len(evts)))
+	return len(evts), nil
+}
+
+// send delivers the batch, retrying with an exponential backoff.
+func (e *exporter) send(ctx context.Context, batch *Batch) error {
+	backoff := e.retryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = e.sink.send(ctx, batch)
+		if err == nil || attempt >= e.retries {
+			return err
+		}
+		log.Errorf("[audit] unable to send events %d to %d, retrying in %v: %v", batch.FirstSeq, batch.LastSeq, backoff, err)
+		select {
+		case <-e.stopCh:
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+type fakeSink struct {
+	batches  []*Batch
+	failures int
+}
+
+func (s *fakeSink) send(ctx context.Context, batch *Batch) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func newTestExporter(sk sink, owner string) *exporter {
+	return &exporter{
+		sink:         sk,
+		key:          []byte("secret"),
+		kinds:        []string{"app.*", "job.*"},
+		batchSize:    10,
+		interval:     time.Second,
+		retryBackoff: time.Millisecond,
+		owner:        owner,
+	}
+}
+
+func createEvent(c *check.C, kind *permTypes.PermissionScheme, target string) *event.Event {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: target},
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "me@example.com"},
+		Kind:     kind,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, target)),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestKindsQuery(c *check.C) {
+	c.Assert(kindsQuery(nil), check.IsNil)
+	c.Assert(kindsQuery([]string{"app.*", "*"}), check.IsNil)
+	c.Assert(kindsQuery([]string{"app.*", "user.create"}), check.DeepEquals, mongoBSON.M{"$regex": `^(app\.|user\.create$)`})
+}
+
+func (s *S) TestExport(c *check.C) {
+	evt1 := createEvent(c, permission.PermAppUpdateEnvSet, "myapp")
+	createEvent(c, permission.PermPoolCreate, "mypool")
+	evt2 := createEvent(c, permission.PermAppUpdateEnvUnset, "myapp")
+	sk := &fakeSink{}
+	e := newTestExporter(sk, "instance-1")
+	now := time.Now().UTC().Add(time.Minute)
+	n, err := e.export(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 2)
+	c.Assert(sk.batches, check.HasLen, 1)
+	batch := sk.batches[0]
+	c.Assert(batch.FirstSeq, check.Equals, int64(1))
+	c.Assert(batch.LastSeq, check.Equals, int64(2))
+	c.Assert(batch.Records[0].EventID, check.Equals, evt1.UniqueID.Hex())
+	c.Assert(batch.Records[1].EventID, check.Equals, evt2.UniqueID.Hex())
+	c.Assert(batch.Verify("", e.key), check.Equals, true)
+	n, err = e.export(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+	evt3 := createEvent(c, permission.PermAppUpdateEnvSet, "myapp")
+	n, err = e.export(context.TODO(), time.Now().UTC().Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	c.Assert(sk.batches, check.HasLen, 2)
+	c.Assert(sk.batches[1].FirstSeq, check.Equals, int64(3))
+	c.Assert(sk.batches[1].Records[0].EventID, check.Equals, evt3.UniqueID.Hex())
+	c.Assert(sk.batches[1].Verify(batch.Records[1].Hash, e.key), check.Equals, true)
+}
+
+func (s *S) TestExportSkipsRecentEvents(c *check.C) {
+	createEvent(c, permission.PermAppUpdateEnvSet, "myapp")
+	sk := &fakeSink{}
+	e := newTestExporter(sk, "instance-1")
+	n, err := e.export(context.TODO(), time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+	c.Assert(sk.batches, check.HasLen, 0)
+}
+
+func (s *S) TestExportLease(c *check.C) {
+	createEvent(c, permission.PermAppUpdateEnvSet, "myapp")
+	now := time.Now().UTC().Add(time.Minute)
+	sk1, sk2 := &fakeSink{}, &fakeSink{}
+	e1 := newTestExporter(sk1, "instance-1")
+	e2 := newTestExporter(sk2, "instance-2")
+	n, err := e1.export(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	createEvent(c, permission.PermAppUpdateEnvSet, "myapp")
+	now = time.Now().UTC().Add(time.Minute)
+	n, err = e2.export(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+	c.Assert(sk2.batches, check.HasLen, 0)
+	n, err = e2.export(context.TODO(), now.Add(e1.leaseDuration()))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	c.Assert(sk2.batches, check.HasLen, 1)
+	c.Assert(sk2.batches[0].FirstSeq, check.Equals, int64(2))
+}
+
+func (s *S) TestExportRetries(c *check.C) {
+	createEvent(c, permission.PermAppUpdateEnvSet, "myapp")
+	now := time.Now().UTC().Add(time.Minute)
+	sk := &fakeSink{failures: 2}
+	e := newTestExporter(sk, "instance-1")
+	e.retries = 1
+	n, err := e.export(context.TODO(), now)
+	c.Assert(err, check.ErrorMatches, "unable to export events 1 to 1: sink unavailable")
+	c.Assert(n, check.Equals, 0)
+	n, err = e.export(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	c.Assert(sk.batches, check.HasLen, 1)
+	c.Assert(sk.batches[0].FirstSeq, check.Equals, int64(1))
+}
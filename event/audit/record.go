@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+// Record is a finished event in the audit log. Records are numbered in the
+// order they're exported and chained by their hashes: Hash is the sha256 of
+// the JSON encoding of the record without it, which includes the hash of the
+// previous record, so changing, removing or reordering any record breaks the
+// chain from it onwards.
+type Record struct {
+	Seq          int64               `json:"seq"`
+	EventID      string              `json:"eventID"`
+	Kind         eventTypes.Kind     `json:"kind"`
+	Target       eventTypes.Target   `json:"target"`
+	ExtraTargets []eventTypes.Target `json:"extraTargets,omitempty"`
+	Owner        eventTypes.Owner    `json:"owner"`
+	SourceIP     string              `json:"sourceIP,omitempty"`
+	RequestID    string              `json:"requestID,omitempty"`
+	StartTime    time.Time           `json:"startTime"`
+	EndTime      time.Time           `json:"endTime"`
+	Error        string              `json:"error,omitempty"`
+	Canceled     bool                `json:"canceled,omitempty"`
+	PrevHash     string              `json:"prevHash"`
+	Hash         string              `json:"hash,omitempty"`
+}
+
+// Batch is the unit shipped to the audit sinks. Signature is the HMAC-SHA256,
+// with the signing key of the exporter, of the hash of the last record,
+// authenticating the whole chain up to it.
+type Batch struct {
+	FirstSeq  int64    `json:"firstSeq"`
+	LastSeq   int64    `json:"lastSeq"`
+	Records   []Record `json:"records"`
+	Signature string   `json:"signature"`
+}
+
+func newRecord(seq int64, prevHash string, evt *event.Event) Record {
+	r := Record{
+		Seq:       seq,
+		EventID:   evt.UniqueID.Hex(),
+		Kind:      evt.Kind,
+		Target:    evt.Target,
+		Owner:     evt.Owner,
+		SourceIP:  evt.SourceIP,
+		RequestID: evt.RequestID,
+		StartTime: evt.StartTime.UTC(),
+		EndTime:   evt.EndTime.UTC(),
+		Error:     evt.Error,
+		Canceled:  evt.CancelInfo.Canceled,
+		PrevHash:  prevHash,
+	}
+	for _, t := range evt.ExtraTargets {
+		r.ExtraTargets = append(r.ExtraTargets, t.Target)
+	}
+	r.Hash = r.computeHash()
+	return r
+}
+
+func (r Record) computeHash() string {
+	r.Hash = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newBatch chains the events after the record with the given sequence number
+// and hash, signing the result with key.
+func newBatch(lastSeq int64, lastHash string, evts []*event.Event, key []byte) *Batch {
+	batch := &Batch{FirstSeq: lastSeq + 1}
+	for _, evt := range evts {
+		lastSeq++
+		record := newRecord(lastSeq, lastHash, evt)
+		lastHash = record.Hash
+		batch.Records = append(batch.Records, record)
+	}
+	batch.LastSeq = lastSeq
+	batch.Signature = sign(key, lastHash)
+	return batch
+}
+
+func sign(key []byte, hash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the hash chain of the batch, starting from prevHash, and its
+// signature. Sinks may use it to validate what they receive.
+func (b *Batch) Verify(prevHash string, key []byte) bool {
+	if len(b.Records) == 0 {
+		return false
+	}
+	for i, r := range b.Records {
+		if r.Seq != b.FirstSeq+int64(i) || r.PrevHash != prevHash || r.Hash != r.computeHash() {
+			return false
+		}
+		prevHash = r.Hash
+	}
+	expected := sign(key, prevHash)
+	return b.LastSeq == b.Records[len(b.Records)-1].Seq && hmac.Equal([]byte(expected), []byte(b.Signature))
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	check "gopkg.in/check.v1"
+)
+
+func fakeEvents(n int) []*event.Event {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var evts []*event.Event
+	for i := 0; i < n; i++ {
+		evts = append(evts, &event.Event{EventData: eventTypes.EventData{
+			UniqueID:  primitive.NewObjectID(),
+			Kind:      eventTypes.Kind{Type: eventTypes.KindTypePermission, Name: "app.update.env.set"},
+			Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+			Owner:     eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "me@example.com"},
+			StartTime: start.Add(time.Duration(i) * time.Minute),
+			EndTime:   start.Add(time.Duration(i)*time.Minute + time.Second),
+		}})
+	}
+	return evts
+}
+
+func (s *S) TestNewBatch(c *check.C) {
+	key := []byte("secret")
+	evts := fakeEvents(3)
+	batch := newBatch(10, "abc", evts, key)
+	c.Assert(batch.FirstSeq, check.Equals, int64(11))
+	c.Assert(batch.LastSeq, check.Equals, int64(13))
+	c.Assert(batch.Records, check.HasLen, 3)
+	c.Assert(batch.Records[0].PrevHash, check.Equals, "abc")
+	c.Assert(batch.Records[1].PrevHash, check.Equals, batch.Records[0].Hash)
+	c.Assert(batch.Records[2].PrevHash, check.Equals, batch.Records[1].Hash)
+	c.Assert(batch.Records[0].EventID, check.Equals, evts[0].UniqueID.Hex())
+	c.Assert(batch.Records[0].Kind.Name, check.Equals, "app.update.env.set")
+	c.Assert(batch.Verify("abc", key), check.Equals, true)
+	c.Assert(batch.Verify("other", key), check.Equals, false)
+	c.Assert(batch.Verify("abc", []byte("other key")), check.Equals, false)
+	next := newBatch(batch.LastSeq, batch.Records[2].Hash, fakeEvents(1), key)
+	c.Assert(next.Verify(batch.Records[2].Hash, key), check.Equals, true)
+}
+
+func (s *S) TestBatchVerifyTampered(c *check.C) {
+	key := []byte("secret")
+	batch := newBatch(0, "", fakeEvents(3), key)
+	batch.Records[1].Owner.Name = "someone@example.com"
+	c.Assert(batch.Verify("", key), check.Equals, false)
+	batch = newBatch(0, "", fakeEvents(3), key)
+	batch.Records = append(batch.Records[:1], batch.Records[2:]...)
+	c.Assert(batch.Verify("", key), check.Equals, false)
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const (
+	SinkS3      = "s3"
+	SinkWebhook = "webhook"
+	SinkKafka   = "kafka"
+
+	signatureHeader = "X-Tsuru-Audit-Signature"
+)
+
+type sink interface {
+	send(ctx context.Context, batch *Batch) error
+}
+
+func sinkFromConfig() (sink, error) {
+	sinkType, _ := config.GetString("audit:sink")
+	switch sinkType {
+	case SinkWebhook:
+		u, _ := config.GetString("audit:webhook:url")
+		if u == "" {
+			return nil, errors.New("audit:webhook:url is required when using the webhook audit sink")
+		}
+		return &webhookSink{url: u}, nil
+	case SinkKafka:
+		u, _ := config.GetString("audit:kafka:url")
+		topic, _ := config.GetString("audit:kafka:topic")
+		if u == "" || topic == "" {
+			return nil, errors.New("audit:kafka:url and audit:kafka:topic are required when using the kafka audit sink")
+		}
+		return &kafkaSink{url: u, topic: topic}, nil
+	case SinkS3:
+		s := &s3Sink{}
+		s.bucket, _ = config.GetString("audit:s3:bucket")
+		s.region, _ = config.GetString("audit:s3:region")
+		s.prefix, _ = config.GetString("audit:s3:prefix")
+		s.endpoint, _ = config.GetString("audit:s3:endpoint")
+		s.accessKeyID, _ = config.GetString("audit:s3:access-key-id")
+		s.secretAccessKey, _ = config.GetString("audit:s3:secret-access-key")
+		if s.bucket == "" || s.region == "" || s.accessKeyID == "" || s.secretAccessKey == "" {
+			return nil, errors.New("audit:s3:bucket, audit:s3:region, audit:s3:access-key-id and audit:s3:secret-access-key are required when using the s3 audit sink")
+		}
+		if s.endpoint == "" {
+			s.endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s.region)
+		}
+		return s, nil
+	}
+	return nil, errors.Errorf(`invalid audit sink %q, valid values are: "s3", "webhook" or "kafka"`, sinkType)
+}
+
+func doRequest(req *http.Request) error {
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code %v: %q", rsp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// webhookSink posts each batch as JSON, with its signature also sent in the
+// X-Tsuru-Audit-Signature header.
+type webhookSink struct {
+	url string
+}
+
+func (s *webhookSink) send(ctx context.Context, batch *Batch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signatureHeader, "sha256="+batch.Signature)
+	return doRequest(req)
+}
+
+// kafkaSink produces each batch as a single message to the topic through a
+// Kafka REST proxy.
+type kafkaSink struct {
+	url   string
+	topic string
+}
+
+func (s *kafkaSink) send(ctx context.Context, batch *Batch) error {
+	payload := map[string]interface{}{
+		"records": []map[string]interface{}{
+			{"key": fmt.Sprintf("%020d", batch.FirstSeq), "value": batch},
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	u, err := url.JoinPath(s.url, "topics", s.topic)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	return doRequest(req)
+}
+
+// s3Sink stores each batch as an object named after its sequence numbers.
+// Objects are only created, never replaced: a batch sent again after a
+// partial failure finds its object already there.
+type s3Sink struct {
+	endpoint        string
+	bucket          string
+	region          string
+	prefix          string
+	accessKeyID     string
+	secretAccessKey string
+	now             func() time.Time
+}
+
+func (s *s3Sink) objectKey(batch *Batch) string {
+	return path.Join(s.prefix, fmt.Sprintf("%020d-%020d.json", batch.FirstSeq, batch.LastSeq))
+}
+
+func (s *s3Sink) send(ctx context.Context, batch *Batch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	u, err := url.JoinPath(s.endpoint, s.bucket, s.objectKey(batch))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-None-Match", "*")
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, data, now().UTC())
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusPreconditionFailed {
+		return nil
+	}
+	if rsp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code %v: %q", rsp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// sign adds the AWS signature version 4 of the request to its headers.
+func (s *s3Sink) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := hexSHA256(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	headers := []string{"host", "if-none-match", "x-amz-content-sha256", "x-amz-date"}
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", h, strings.TrimSpace(value))
+	}
+	signedHeaders := strings.Join(headers, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+	key := sigV4Key(s.secretAccessKey, date, s.region, "s3")
+	signature := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKeyID, scope, signedHeaders, signature))
+}
+
+func sigV4Key(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(service))
+	return hmacSHA256(key, []byte("aws4_request"))
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSinkFromConfig(c *check.C) {
+	defer config.Unset("audit")
+	_, err := sinkFromConfig()
+	c.Assert(err, check.ErrorMatches, `invalid audit sink "", valid values are: "s3", "webhook" or "kafka"`)
+	config.Set("audit:sink", "s3")
+	_, err = sinkFromConfig()
+	c.Assert(err, check.ErrorMatches, "audit:s3:bucket, .* are required when using the s3 audit sink")
+	config.Set("audit:s3:bucket", "audit-logs")
+	config.Set("audit:s3:region", "us-east-1")
+	config.Set("audit:s3:access-key-id", "AKID")
+	config.Set("audit:s3:secret-access-key", "secret")
+	sk, err := sinkFromConfig()
+	c.Assert(err, check.IsNil)
+	c.Assert(sk.(*s3Sink).endpoint, check.Equals, "https://s3.us-east-1.amazonaws.com")
+	config.Set("audit:sink", "kafka")
+	config.Set("audit:kafka:url", "http://kafka-rest:8082")
+	_, err = sinkFromConfig()
+	c.Assert(err, check.ErrorMatches, "audit:kafka:url and audit:kafka:topic are required when using the kafka audit sink")
+}
+
+func (s *S) TestWebhookSinkSend(c *check.C) {
+	var received Batch
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Tsuru-Audit-Signature")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+	batch := newBatch(0, "", fakeEvents(2), []byte("secret"))
+	err := (&webhookSink{url: server.URL}).send(context.TODO(), batch)
+	c.Assert(err, check.IsNil)
+	c.Assert(signature, check.Equals, "sha256="+batch.Signature)
+	c.Assert(received.Verify("", []byte("secret")), check.Equals, true)
+}
+
+func (s *S) TestKafkaSinkSend(c *check.C) {
+	var path string
+	var payload struct {
+		Records []struct {
+			Key   string `json:"key"`
+			Value Batch  `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+	batch := newBatch(41, "", fakeEvents(1), []byte("secret"))
+	err := (&kafkaSink{url: server.URL, topic: "audit"}).send(context.TODO(), batch)
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.Equals, "/topics/audit")
+	c.Assert(payload.Records, check.HasLen, 1)
+	c.Assert(payload.Records[0].Key, check.Equals, "00000000000000000042")
+	c.Assert(payload.Records[0].Value.Signature, check.Equals, batch.Signature)
+}
+
+func (s *S) TestSigV4Key(c *check.C) {
+	key := sigV4Key("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	c.Assert(hex.EncodeToString(key), check.Equals, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d")
+}
+
+func (s *S) TestS3SinkSend(c *check.C) {
+	var method, path, auth, ifNoneMatch string
+	var body []byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		auth = r.Header.Get("Authorization")
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	sk := &s3Sink{
+		endpoint:        server.URL,
+		bucket:          "audit-logs",
+		region:          "us-east-1",
+		prefix:          "tsuru/events",
+		accessKeyID:     "AKID",
+		secretAccessKey: "secret",
+		now: func() time.Time {
+			return time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+		},
+	}
+	batch := newBatch(0, "", fakeEvents(2), []byte("secret"))
+	err := sk.send(context.TODO(), batch)
+	c.Assert(err, check.IsNil)
+	c.Assert(method, check.Equals, http.MethodPut)
+	c.Assert(path, check.Equals, "/audit-logs/tsuru/events/00000000000000000001-00000000000000000002.json")
+	c.Assert(ifNoneMatch, check.Equals, "*")
+	c.Assert(strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20260301/us-east-1/s3/aws4_request, SignedHeaders=host;if-none-match;x-amz-content-sha256;x-amz-date, Signature="), check.Equals, true, check.Commentf("%s", auth))
+	var received Batch
+	err = json.Unmarshal(body, &received)
+	c.Assert(err, check.IsNil)
+	c.Assert(received.Verify("", []byte("secret")), check.Equals, true)
+	status = http.StatusPreconditionFailed
+	err = sk.send(context.TODO(), batch)
+	c.Assert(err, check.IsNil)
+	status = http.StatusForbidden
+	err = sk.send(context.TODO(), batch)
+	c.Assert(err, check.ErrorMatches, "invalid status code 403.*")
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// maxChainDepth bounds how many levels of parents and children are followed
+// when loading a chain of events.
+const maxChainDepth = 20
+
+type parentContextKey struct{}
+
+// WithParent returns a context in which new events are recorded as caused by
+// evt, so operations triggered by it, like restarts and rebinds during a
+// deploy, can be traced back to it.
+func WithParent(ctx context.Context, evt *Event) context.Context {
+	return context.WithValue(ctx, parentContextKey{}, evt)
+}
+
+func parentID(ctx context.Context, opts *Opts) string {
+	parent := opts.Parent
+	if parent == nil {
+		parent, _ = ctx.Value(parentContextKey{}).(*Event)
+	}
+	if parent == nil {
+		return ""
+	}
+	return parent.UniqueID.Hex()
+}
+
+// Chain returns the events causally related to evt, from the root of its
+// chain down to every descendant, ordered by start time. Parents already
+// expired end the chain early.
+func Chain(ctx context.Context, evt *Event) ([]*Event, error) {
+	root := evt
+	for i := 0; root.ParentID != "" && i < maxChainDepth; i++ {
+		parent, err := GetByHexID(ctx, root.ParentID)
+		if err == ErrEventNotFound {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		root = parent
+	}
+	chain := []*Event{root}
+	parents := []string{root.UniqueID.Hex()}
+	for i := 0; len(parents) > 0 && i < maxChainDepth && len(chain) < filterMaxLimit; i++ {
+		children, err := List(ctx, &Filter{
+			Raw:   mongoBSON.M{"parentid": mongoBSON.M{"$in": parents}},
+			Sort:  "starttime",
+			Limit: filterMaxLimit - len(chain),
+		})
+		if err != nil {
+			return nil, err
+		}
+		parents = nil
+		for _, child := range children {
+			chain = append(chain, child)
+			parents = append(parents, child.UniqueID.Hex())
+		}
+	}
+	sort.Slice(chain, func(i, j int) bool {
+		// start times are stored with millisecond precision, events started
+		// in the same millisecond are ordered by their ids.
+		ti, tj := chain[i].StartTime.Truncate(time.Millisecond), chain[j].StartTime.Truncate(time.Millisecond)
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return chain[i].UniqueID.Hex() < chain[j].UniqueID.Hex()
+	})
+	return chain, nil
+}
+
+// ChainLink returns the summary of the event shown in the chains of events.
+func (e *Event) ChainLink() eventTypes.ChainLink {
+	return eventTypes.ChainLink{
+		UniqueID:  e.UniqueID,
+		ParentID:  e.ParentID,
+		Target:    e.Target,
+		Kind:      e.Kind,
+		Owner:     e.Owner,
+		StartTime: e.StartTime,
+		EndTime:   e.EndTime,
+		Running:   e.Running,
+		Error:     e.Error,
+	}
+}
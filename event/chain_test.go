@@ -0,0 +1,75 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) newChainEvent(c *check.C, ctx context.Context, name string, parent *Event) *Event {
+	evt, err := NewInternal(ctx, &Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: name},
+		InternalKind: "chain test",
+		Parent:       parent,
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestNewParentFromContext(c *check.C) {
+	parent := s.newChainEvent(c, context.TODO(), "parent", nil)
+	c.Assert(parent.ParentID, check.Equals, "")
+	ctx, cancel := parent.CancelableContext(context.TODO())
+	defer cancel()
+	child := s.newChainEvent(c, ctx, "child", nil)
+	c.Assert(child.ParentID, check.Equals, parent.UniqueID.Hex())
+	grandchild := s.newChainEvent(c, WithParent(context.TODO(), child), "grandchild", nil)
+	c.Assert(grandchild.ParentID, check.Equals, child.UniqueID.Hex())
+	dbEvt, err := GetByID(context.TODO(), grandchild.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.ParentID, check.Equals, child.UniqueID.Hex())
+}
+
+func (s *S) TestNewParentFromOpts(c *check.C) {
+	parent := s.newChainEvent(c, context.TODO(), "parent", nil)
+	other := s.newChainEvent(c, context.TODO(), "other", nil)
+	child := s.newChainEvent(c, WithParent(context.TODO(), other), "child", parent)
+	c.Assert(child.ParentID, check.Equals, parent.UniqueID.Hex())
+}
+
+func (s *S) TestChain(c *check.C) {
+	root := s.newChainEvent(c, context.TODO(), "root", nil)
+	child1 := s.newChainEvent(c, context.TODO(), "child1", root)
+	child2 := s.newChainEvent(c, context.TODO(), "child2", root)
+	grandchild := s.newChainEvent(c, context.TODO(), "grandchild", child2)
+	s.newChainEvent(c, context.TODO(), "unrelated", nil)
+	for _, evt := range []*Event{root, child1, grandchild, child2} {
+		chain, err := Chain(context.TODO(), evt)
+		c.Assert(err, check.IsNil)
+		var ids []string
+		for _, e := range chain {
+			ids = append(ids, e.UniqueID.Hex())
+		}
+		c.Assert(ids, check.DeepEquals, []string{
+			root.UniqueID.Hex(),
+			child1.UniqueID.Hex(),
+			child2.UniqueID.Hex(),
+			grandchild.UniqueID.Hex(),
+		})
+	}
+}
+
+func (s *S) TestChainWithoutParent(c *check.C) {
+	evt := s.newChainEvent(c, context.TODO(), "alone", nil)
+	chain, err := Chain(context.TODO(), evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(chain, check.HasLen, 1)
+	c.Assert(chain[0].ChainLink(), check.DeepEquals, evt.ChainLink())
+}
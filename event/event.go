@@ -14,6 +14,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -56,7 +57,13 @@ var (
 		Help: "The total number of events expired",
 	}, []string{"kind"})
 
+	eventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_events_total",
+		Help: "The total number of finished events",
+	}, []string{"kind", "status"})
+
 	defaultAppRetryTimeout = 10 * time.Second
+	checkpointPollInterval = time.Second
 )
 
 const (
@@ -73,6 +80,11 @@ var (
 
 	ErrNotCancelable          = errors.New("event is not cancelable")
 	ErrCancelAlreadyRequested = errors.New("event cancel already requested")
+	ErrNotPausable            = errors.New("event is not pausable")
+	ErrAlreadyPaused          = errors.New("event is already paused")
+	ErrNotPaused              = errors.New("event is not paused")
+	ErrNotWaitingApproval     = errors.New("event is not waiting for approval")
+	ErrApprovalAbandoned      = errors.New("event abandoned by a stopped API instance while waiting for or running after approval")
 	ErrEventNotFound          = errors.New("event not found")
 	ErrNoTarget               = ErrValidation("event target is mandatory")
 	ErrNoKind                 = ErrValidation("event kind is mandatory")
@@ -83,14 +95,20 @@ var (
 	ErrNoAllowedCancel        = errors.New("event allowed cancel is mandatory for cancelable events")
 	ErrInvalidOwner           = ErrValidation("event owner must not be set on internal events")
 	ErrInvalidKind            = ErrValidation("event kind must not be set on internal events")
+	ErrNoAnnotationMessage    = ErrValidation("annotation message is mandatory")
+	ErrAnnotationTooLong      = ErrValidation(fmt.Sprintf("annotation message must have at most %d characters", maxAnnotationLength))
+	ErrTooManyAnnotations     = errors.Errorf("events may have at most %d annotations", maxAnnotations)
 )
 
 const (
 	filterMaxLimit = 100
+
+	maxAnnotations      = 100
+	maxAnnotationLength = 1000
 )
 
 func init() {
-	prometheus.MustRegister(eventDuration, eventCurrent, eventsRejected)
+	prometheus.MustRegister(eventDuration, eventCurrent, eventsRejected, eventsExpired, eventsTotal)
 }
 
 type ErrThrottled struct {
@@ -204,6 +222,9 @@ type Event struct {
 	eventTypes.EventData
 	logMu     sync.Mutex
 	logWriter io.Writer
+	// flushedLog is the number of StructuredLog entries already persisted
+	// by FlushLog.
+	flushedLog int
 }
 
 type Opts struct {
@@ -221,6 +242,9 @@ type Opts struct {
 	AllowedCancel eventTypes.AllowedPermission
 	RetryTimeout  time.Duration
 	ExpireAt      *time.Time
+	// Parent is the event causing the new one, taken from the context when
+	// not set, see WithParent.
+	Parent *Event
 }
 
 func Allowed(scheme *permTypes.PermissionScheme, contexts ...permTypes.PermissionContext) eventTypes.AllowedPermission {
@@ -251,6 +275,8 @@ type Filter struct {
 	KindNames      []string `form:"-"`
 	OwnerType      eventTypes.OwnerType
 	OwnerName      string
+	RequestID      string
+	ParentID       string
 	Since          time.Time
 	Until          time.Time
 	Running        *bool
@@ -359,6 +385,12 @@ func (f *Filter) toQuery() (mongoBSON.M, error) {
 	if f.OwnerName != "" {
 		query["owner.name"] = f.OwnerName
 	}
+	if f.RequestID != "" {
+		query["requestid"] = f.RequestID
+	}
+	if f.ParentID != "" {
+		query["parentid"] = f.ParentID
+	}
 	var timeParts []mongoBSON.M
 	if !f.Since.IsZero() {
 		timeParts = append(timeParts, mongoBSON.M{"starttime": mongoBSON.M{"$gte": f.Since}})
@@ -560,16 +592,16 @@ func List(ctx context.Context, filter *Filter) ([]*Event, error) {
 	skip := 0
 	var query mongoBSON.M
 	var err error
-	sort := mongoBSON.M{"starttime": -1}
+	sortField, sortOrder := "starttime", -1
 	if filter != nil {
 		limit = filterMaxLimit
 		if filter.Limit != 0 {
 			limit = filter.Limit
 		}
 		if strings.HasPrefix(filter.Sort, "-") {
-			sort = mongoBSON.M{filter.Sort[1:]: -1}
+			sortField, sortOrder = filter.Sort[1:], -1
 		} else if filter.Sort != "" {
-			sort = mongoBSON.M{filter.Sort: 1}
+			sortField, sortOrder = filter.Sort, 1
 		}
 		if filter.Skip > 0 {
 			skip = filter.Skip
@@ -587,6 +619,12 @@ func List(ctx context.Context, filter *Filter) ([]*Event, error) {
 		return nil, err
 	}
 
+	// uniqueid breaks ties between events with the same value in the sort
+	// field, keeping pages stable.
+	sort := mongoBSON.D{{Key: sortField, Value: sortOrder}}
+	if sortField != "uniqueid" {
+		sort = append(sort, mongoBSON.E{Key: "uniqueid", Value: sortOrder})
+	}
 	options := options.Find().SetSort(sort)
 	if limit > 0 {
 		options = options.SetLimit(int64(limit))
@@ -832,6 +870,8 @@ func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 			Kind:            k,
 			Owner:           o,
 			SourceIP:        sourceIP,
+			RequestID:       log.RequestIDFromContext(ctx),
+			ParentID:        parentID(ctx, opts),
 			StartCustomData: raw,
 			LockUpdateTime:  now,
 			Running:         true,
@@ -1010,7 +1050,36 @@ func (e *Event) Write(data []byte) (int, error) {
 	return len(data), nil
 }
 
+// FlushLog persists the log entries written since the last flush while the
+// event is still running, allowing them to be followed before it's done.
+func (e *Event) FlushLog(ctx context.Context) error {
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+	if e.flushedLog >= len(e.StructuredLog) {
+		return nil
+	}
+	entries := make([]eventTypes.LogEntry, len(e.StructuredLog)-e.flushedLog)
+	copy(entries, e.StructuredLog[e.flushedLog:])
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": e.ID, "running": true}, mongoBSON.M{
+		"$push": mongoBSON.M{"structuredlog": mongoBSON.M{"$each": entries}},
+	})
+	if err != nil {
+		return err
+	}
+	e.flushedLog += len(entries)
+	return nil
+}
+
+// CancelableContext returns a context canceled when a cancel of the event is
+// requested, events created with it are recorded as children of e.
 func (e *Event) CancelableContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e != nil {
+		ctx = WithParent(ctx, e)
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	if e == nil || !e.Cancelable {
 		return ctx, cancel
@@ -1103,6 +1172,315 @@ func (e *Event) AckCancel(ctx context.Context) (bool, error) {
 	return err == nil, err
 }
 
+// TryPause asks a running event to stop at its next checkpoint, see
+// Checkpoint.
+func (e *Event) TryPause(ctx context.Context, reason, owner string) error {
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+	if !e.Running {
+		return ErrNotPausable
+	}
+
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+
+	update := mongoBSON.M{"$set": mongoBSON.M{
+		"pauseinfo.owner":     owner,
+		"pauseinfo.reason":    reason,
+		"pauseinfo.starttime": time.Now().UTC(),
+		"pauseinfo.paused":    true,
+	}}
+	query := mongoBSON.M{"_id": e.ID, "running": true, "pauseinfo.paused": mongoBSON.M{"$ne": true}}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = collection.FindOneAndUpdate(ctx, query, update, options).Decode(&e.EventData)
+	if err == mongo.ErrNoDocuments {
+		if _, errID := GetByID(ctx, e.ID); errID == ErrEventNotFound {
+			return ErrEventNotFound
+		}
+		err = ErrAlreadyPaused
+	}
+	return err
+}
+
+// Resume allows a paused event to continue from the checkpoint where it
+// stopped.
+func (e *Event) Resume(ctx context.Context) error {
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+
+	update := mongoBSON.M{"$set": mongoBSON.M{"pauseinfo.paused": false}}
+	query := mongoBSON.M{"_id": e.ID, "running": true, "pauseinfo.paused": true}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = collection.FindOneAndUpdate(ctx, query, update, options).Decode(&e.EventData)
+	if err == mongo.ErrNoDocuments {
+		if _, errID := GetByID(ctx, e.ID); errID == ErrEventNotFound {
+			return ErrEventNotFound
+		}
+		err = ErrNotPaused
+	}
+	return err
+}
+
+// PauseRequested returns whether a pause was asked for the event and not
+// resumed yet.
+func (e *Event) PauseRequested(ctx context.Context) (bool, error) {
+	if e == nil {
+		return false, nil
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return false, err
+	}
+	var evtData struct {
+		PauseInfo eventTypes.PauseInfo
+	}
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": e.ID}).Decode(&evtData)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	e.logMu.Lock()
+	e.PauseInfo = evtData.PauseInfo
+	e.logMu.Unlock()
+	return evtData.PauseInfo.Paused, nil
+}
+
+// Checkpoint records the name of the step reached by the event and blocks
+// while the event is paused. Operations able to be paused, like deploys,
+// call it between steps which are safe to be inspected by operators.
+func (e *Event) Checkpoint(ctx context.Context, name string) error {
+	if e == nil {
+		return nil
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": e.ID}, mongoBSON.M{
+		"$set": mongoBSON.M{"pauseinfo.checkpoint": name},
+	})
+	if err != nil {
+		return err
+	}
+	var waiting bool
+	for {
+		paused, err := e.PauseRequested(ctx)
+		if err != nil {
+			return err
+		}
+		if !paused {
+			if waiting {
+				fmt.Fprintf(e, "\n---- Resumed at checkpoint %q ----\n", name)
+			}
+			return nil
+		}
+		if !waiting {
+			waiting = true
+			fmt.Fprintf(e, "\n---- Paused at checkpoint %q by %s: %s ----\n", name, e.PauseInfo.Owner, e.PauseInfo.Reason)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(checkpointPollInterval):
+		}
+	}
+}
+
+// RequireApproval marks the event as requiring approval, so it can be
+// approved with Approve before WaitApproval is called.
+func (e *Event) RequireApproval(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": e.ID}, mongoBSON.M{
+		"$set": mongoBSON.M{"approvalinfo.required": true},
+	})
+	if err != nil {
+		return err
+	}
+	e.logMu.Lock()
+	e.ApprovalInfo.Required = true
+	e.logMu.Unlock()
+	return nil
+}
+
+// WaitApproval marks the event as requiring approval and blocks until it's
+// approved with Approve or the context is done.
+func (e *Event) WaitApproval(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	err := e.RequireApproval(ctx)
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e, "\n---- Waiting for approval of event %s ----\n", e.UniqueID.Hex())
+	for {
+		var evtData struct {
+			ApprovalInfo eventTypes.ApprovalInfo
+		}
+		err = collection.FindOne(ctx, mongoBSON.M{"_id": e.ID}).Decode(&evtData)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if evtData.ApprovalInfo.Approved {
+			e.logMu.Lock()
+			e.ApprovalInfo = evtData.ApprovalInfo
+			e.logMu.Unlock()
+			fmt.Fprintf(e, "\n---- Approved by %s ----\n", evtData.ApprovalInfo.Owner)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(checkpointPollInterval):
+		}
+	}
+}
+
+// SetProgress records the progress checkpoint reached by the event.
+func (e *Event) SetProgress(ctx context.Context, progress eventTypes.ProgressInfo) error {
+	if e == nil {
+		return nil
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	progress.UpdateTime = time.Now().UTC()
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": e.ID}, mongoBSON.M{
+		"$set": mongoBSON.M{"progressinfo": progress},
+	})
+	if err != nil {
+		return err
+	}
+	e.logMu.Lock()
+	e.ProgressInfo = progress
+	e.logMu.Unlock()
+	return nil
+}
+
+// Approve allows an event blocked in WaitApproval to continue.
+func (e *Event) Approve(ctx context.Context, owner string) error {
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+
+	update := mongoBSON.M{"$set": mongoBSON.M{
+		"approvalinfo.approved": true,
+		"approvalinfo.owner":    owner,
+		"approvalinfo.time":     time.Now().UTC(),
+	}}
+	query := mongoBSON.M{"_id": e.ID, "running": true, "approvalinfo.required": true, "approvalinfo.approved": mongoBSON.M{"$ne": true}}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = collection.FindOneAndUpdate(ctx, query, update, options).Decode(&e.EventData)
+	if err == mongo.ErrNoDocuments {
+		if _, errID := GetByID(ctx, e.UniqueID); errID == ErrEventNotFound {
+			return ErrEventNotFound
+		}
+		err = ErrNotWaitingApproval
+	}
+	return err
+}
+
+// FailAbandonedApprovals finishes with ErrApprovalAbandoned the running
+// events requiring approval whose lock is no longer updated, left behind by
+// API instances stopped while the events waited for approval or ran after
+// it. Events of running instances keep their locks updated and are not
+// touched.
+func FailAbandonedApprovals(ctx context.Context) error {
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{
+		"running":               true,
+		"approvalinfo.required": true,
+		"lockupdatetime":        mongoBSON.M{"$lt": time.Now().UTC().Add(-lockExpireTimeout)},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var evt Event
+		if err = cursor.Decode(&evt.EventData); err != nil {
+			return err
+		}
+		if err = evt.Done(ctx, ErrApprovalAbandoned); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Annotate attaches a comment by owner to the event, running or not.
+func (e *Event) Annotate(ctx context.Context, owner, message string, acknowledged bool) (*eventTypes.Annotation, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, ErrNoAnnotationMessage
+	}
+	if utf8.RuneCountInString(message) > maxAnnotationLength {
+		return nil, ErrAnnotationTooLong
+	}
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	annotation := eventTypes.Annotation{
+		Author:       owner,
+		Message:      message,
+		Acknowledged: acknowledged,
+		Time:         time.Now().UTC(),
+	}
+	update := mongoBSON.M{"$push": mongoBSON.M{"annotations": annotation}}
+	query := mongoBSON.M{"_id": e.ID, fmt.Sprintf("annotations.%d", maxAnnotations-1): mongoBSON.M{"$exists": false}}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = collection.FindOneAndUpdate(ctx, query, update, options).Decode(&e.EventData)
+	if err == mongo.ErrNoDocuments {
+		if _, errID := GetByID(ctx, e.UniqueID); errID == ErrEventNotFound {
+			return nil, ErrEventNotFound
+		}
+		return nil, ErrTooManyAnnotations
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &annotation, nil
+}
+
 func (e *Event) StartData(value interface{}) error {
 	if e.StartCustomData.Type == 0 {
 		return nil
@@ -1124,6 +1502,18 @@ func (e *Event) OtherData(value interface{}) error {
 	return e.OtherCustomData.Unmarshal(value)
 }
 
+func (e *Event) metricStatus(abort bool) string {
+	switch {
+	case abort:
+		return "aborted"
+	case e.CancelInfo.Canceled:
+		return "canceled"
+	case e.Error != "":
+		return "error"
+	}
+	return "success"
+}
+
 func (e *Event) done(ctx context.Context, evtErr error, customData interface{}, abort bool) (err error) {
 	ctx = context.WithoutCancel(ctx)
 	// Done will be usually called in a defer block ignoring errors. This is
@@ -1132,6 +1522,7 @@ func (e *Event) done(ctx context.Context, evtErr error, customData interface{},
 		e.fillLegacyLog()
 		eventDuration.WithLabelValues(e.Kind.Name).Observe(time.Since(e.StartTime).Seconds())
 		eventCurrent.WithLabelValues(e.Kind.Name).Dec()
+		eventsTotal.WithLabelValues(e.Kind.Name, e.metricStatus(abort)).Inc()
 		if err != nil {
 			log.Errorf("[events] error marking event as done - %#v: %s", e, err)
 		} else {
@@ -1176,6 +1567,7 @@ func (e *Event) done(ctx context.Context, evtErr error, customData interface{},
 	err = collection.FindOne(ctx, mongoBSON.M{"_id": e.ID}).Decode(&dbEvt.EventData)
 	if err == nil {
 		e.OtherCustomData = dbEvt.OtherCustomData
+		e.Annotations = dbEvt.Annotations
 	}
 	e.logMu.Lock()
 	defer e.logMu.Unlock()
@@ -13,6 +13,7 @@ import (
 	"io"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -118,6 +119,41 @@ func (s *S) TestNewDone(c *check.C) {
 	c.Assert(evts[0], check.DeepEquals, expected)
 }
 
+func (s *S) TestNewWithRequestID(c *check.C) {
+	ctx := log.ContextWithRequestID(context.TODO(), "my-request")
+	evt, err := New(ctx, &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.RequestID, check.Equals, "my-request")
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	_, err = New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "otherapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	evts, err := List(context.TODO(), &Filter{RequestID: "my-request"})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].UniqueID, check.Equals, evt.UniqueID)
+}
+
+func (s *S) TestEventMetricStatus(c *check.C) {
+	evt := Event{}
+	c.Assert(evt.metricStatus(false), check.Equals, "success")
+	c.Assert(evt.metricStatus(true), check.Equals, "aborted")
+	evt.Error = "my error"
+	c.Assert(evt.metricStatus(false), check.Equals, "error")
+	evt.CancelInfo.Canceled = true
+	c.Assert(evt.metricStatus(false), check.Equals, "canceled")
+}
+
 func (s *S) TestNewExpirable(c *check.C) {
 	expireAt := time.Now().UTC().Add(10 * time.Minute)
 	evt, err := New(context.TODO(), &Opts{
@@ -585,6 +621,38 @@ func (s *S) TestEventLogfWithWriter(c *check.C) {
 	c.Assert(evts[0].Log(), check.Matches, `(?s)\d{4}-\d{2}-\d{2}.*: hey 42`+"\n")
 }
 
+func (s *S) TestEventFlushLog(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	evt.Logf("first")
+	err = evt.FlushLog(context.TODO())
+	c.Assert(err, check.IsNil)
+	evt.Logf("second")
+	err = evt.FlushLog(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = evt.FlushLog(context.TODO())
+	c.Assert(err, check.IsNil)
+	dbEvt, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, true)
+	c.Assert(dbEvt.StructuredLog, check.HasLen, 2)
+	c.Assert(dbEvt.StructuredLog[0].Message, check.Equals, "first\n")
+	c.Assert(dbEvt.StructuredLog[1].Message, check.Equals, "second\n")
+	evt.Logf("third")
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	err = evt.FlushLog(context.TODO())
+	c.Assert(err, check.IsNil)
+	dbEvt, err = GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.StructuredLog, check.HasLen, 3)
+}
+
 func (s *S) TestEventCancel(c *check.C) {
 	evt, err := New(context.TODO(), &Opts{
 		Target:        eventTypes.Target{Type: "app", Value: "myapp"},
@@ -778,6 +846,191 @@ func (s *S) TestEventCancelDoneCustomError(c *check.C) {
 	c.Assert(evts[0].Error, check.Equals, "my err")
 }
 
+func (s *S) TestEventPauseAndResume(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	other, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	err = other.TryPause(context.TODO(), "checking metrics", "admin@admin.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(other.PauseInfo.StartTime.IsZero(), check.Equals, false)
+	other.PauseInfo.StartTime = time.Time{}
+	c.Assert(other.PauseInfo, check.DeepEquals, eventTypes.PauseInfo{
+		Owner:  "admin@admin.com",
+		Reason: "checking metrics",
+		Paused: true,
+	})
+	err = other.TryPause(context.TODO(), "again", "admin@admin.com")
+	c.Assert(err, check.Equals, ErrAlreadyPaused)
+	paused, err := evt.PauseRequested(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(paused, check.Equals, true)
+	err = other.Resume(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(other.PauseInfo.Paused, check.Equals, false)
+	err = other.Resume(context.TODO())
+	c.Assert(err, check.Equals, ErrNotPaused)
+	paused, err = evt.PauseRequested(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(paused, check.Equals, false)
+}
+
+func (s *S) TestEventPauseNotRunning(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	err = evt.TryPause(context.TODO(), "", "admin@admin.com")
+	c.Assert(err, check.Equals, ErrNotPausable)
+}
+
+func (s *S) TestEventCheckpoint(c *check.C) {
+	oldInterval := checkpointPollInterval
+	checkpointPollInterval = 10 * time.Millisecond
+	defer func() { checkpointPollInterval = oldInterval }()
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	evt.SetLogWriter(&buf)
+	err = evt.Checkpoint(context.TODO(), "step 1")
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "")
+	other, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(other.PauseInfo.Checkpoint, check.Equals, "step 1")
+	err = other.TryPause(context.TODO(), "inspect", "admin@admin.com")
+	c.Assert(err, check.IsNil)
+	done := make(chan error)
+	go func() {
+		done <- evt.Checkpoint(context.TODO(), "step 2")
+	}()
+	select {
+	case err = <-done:
+		c.Fatalf("checkpoint should block while paused, got: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	err = other.Resume(context.TODO())
+	c.Assert(err, check.IsNil)
+	select {
+	case err = <-done:
+		c.Assert(err, check.IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for checkpoint to resume")
+	}
+	c.Assert(evt.PauseInfo.Checkpoint, check.Equals, "step 2")
+	c.Assert(buf.String(), check.Matches, `(?s).*Paused at checkpoint "step 2" by admin@admin.com: inspect.*Resumed at checkpoint "step 2".*`)
+}
+
+func (s *S) TestEventCheckpointNilEvent(c *check.C) {
+	var evt *Event
+	c.Assert(evt.Checkpoint(context.TODO(), "step"), check.IsNil)
+}
+
+func (s *S) TestEventWaitApproval(c *check.C) {
+	oldInterval := checkpointPollInterval
+	checkpointPollInterval = 10 * time.Millisecond
+	defer func() { checkpointPollInterval = oldInterval }()
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	evt.SetLogWriter(&buf)
+	other, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	err = other.Approve(context.TODO(), "admin@admin.com")
+	c.Assert(err, check.Equals, ErrNotWaitingApproval)
+	done := make(chan error)
+	go func() {
+		done <- evt.WaitApproval(context.TODO())
+	}()
+	select {
+	case err = <-done:
+		c.Fatalf("wait approval should block until approved, got: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	err = other.Approve(context.TODO(), "admin@admin.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(other.ApprovalInfo.Approved, check.Equals, true)
+	select {
+	case err = <-done:
+		c.Assert(err, check.IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for approval")
+	}
+	c.Assert(evt.ApprovalInfo.Owner, check.Equals, "admin@admin.com")
+	err = other.Approve(context.TODO(), "admin@admin.com")
+	c.Assert(err, check.Equals, ErrNotWaitingApproval)
+	c.Assert(buf.String(), check.Matches, `(?s).*Waiting for approval.*Approved by admin@admin.com.*`)
+}
+
+func (s *S) TestEventWaitApprovalContextDone(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	err = evt.WaitApproval(ctx)
+	c.Assert(err, check.Equals, context.DeadlineExceeded)
+}
+
+func (s *S) TestFailAbandonedApprovals(c *check.C) {
+	oldLockExpire := lockExpireTimeout
+	lockExpireTimeout = 200 * time.Millisecond
+	defer func() {
+		lockExpireTimeout = oldLockExpire
+	}()
+	abandoned, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = abandoned.RequireApproval(context.TODO())
+	c.Assert(err, check.IsNil)
+	notApproval, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "otherapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	updater.stop()
+	time.Sleep(300 * time.Millisecond)
+	err = FailAbandonedApprovals(context.TODO())
+	c.Assert(err, check.IsNil)
+	evt, err := GetByID(context.TODO(), abandoned.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, false)
+	c.Assert(evt.Error, check.Equals, ErrApprovalAbandoned.Error())
+	evt, err = GetByID(context.TODO(), notApproval.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, true)
+}
+
 func (s *S) TestEventNewValidation(c *check.C) {
 	_, err := New(context.TODO(), nil)
 	c.Assert(err, check.Equals, ErrNoOpts)
@@ -1262,6 +1515,7 @@ func (s *S) TestGetTargetType(c *check.C) {
 		{"service-instance", eventTypes.TargetTypeServiceInstance, nil},
 		{"team", eventTypes.TargetTypeTeam, nil},
 		{"user", eventTypes.TargetTypeUser, nil},
+		{"group", eventTypes.TargetTypeGroup, nil},
 		{"invalid", "", eventTypes.ErrInvalidTargetType},
 	}
 	for _, t := range tests {
@@ -1691,3 +1945,77 @@ func (s *S) TestEventInfoWithGenericCustomData(c *check.C) {
 		End:   "end",
 	})
 }
+
+func (s *S) TestEventSetProgress(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.SetProgress(context.TODO(), eventTypes.ProgressInfo{Step: "units rolling", Percent: 65, Current: 3, Total: 10})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.ProgressInfo.UpdateTime.IsZero(), check.Equals, false)
+	other, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(other.ProgressInfo.Step, check.Equals, "units rolling")
+	c.Assert(other.ProgressInfo.Percent, check.Equals, 65)
+	c.Assert(other.ProgressInfo.Current, check.Equals, 3)
+	c.Assert(other.ProgressInfo.Total, check.Equals, 10)
+	var nilEvt *Event
+	c.Assert(nilEvt.SetProgress(context.TODO(), eventTypes.ProgressInfo{Step: "x"}), check.IsNil)
+}
+
+func (s *S) TestEventAnnotate(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	other, err := GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	annotation, err := other.Annotate(context.TODO(), "admin@admin.com", "  looking into it ", false)
+	c.Assert(err, check.IsNil)
+	c.Assert(annotation.Author, check.Equals, "admin@admin.com")
+	c.Assert(annotation.Message, check.Equals, "looking into it")
+	c.Assert(other.Annotations, check.HasLen, 1)
+	err = evt.Done(context.TODO(), errors.New("deploy failed"))
+	c.Assert(err, check.IsNil)
+	other, err = GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	_, err = other.Annotate(context.TODO(), "user@example.com", "known flaky, retried", true)
+	c.Assert(err, check.IsNil)
+	dbEvt, err := GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Error, check.Equals, "deploy failed")
+	c.Assert(dbEvt.Annotations, check.HasLen, 2)
+	c.Assert(dbEvt.Annotations[0].Message, check.Equals, "looking into it")
+	c.Assert(dbEvt.Annotations[0].Acknowledged, check.Equals, false)
+	c.Assert(dbEvt.Annotations[1].Author, check.Equals, "user@example.com")
+	c.Assert(dbEvt.Annotations[1].Message, check.Equals, "known flaky, retried")
+	c.Assert(dbEvt.Annotations[1].Acknowledged, check.Equals, true)
+	c.Assert(dbEvt.Annotations[1].Time.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestEventAnnotateInvalid(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = evt.Annotate(context.TODO(), "admin@admin.com", " ", false)
+	c.Assert(err, check.Equals, ErrNoAnnotationMessage)
+	_, err = evt.Annotate(context.TODO(), "admin@admin.com", strings.Repeat("x", maxAnnotationLength+1), false)
+	c.Assert(err, check.Equals, ErrAnnotationTooLong)
+	for i := 0; i < maxAnnotations; i++ {
+		_, err = evt.Annotate(context.TODO(), "admin@admin.com", "note", false)
+		c.Assert(err, check.IsNil)
+	}
+	_, err = evt.Annotate(context.TODO(), "admin@admin.com", "note", false)
+	c.Assert(err, check.Equals, ErrTooManyAnnotations)
+}
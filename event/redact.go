@@ -0,0 +1,157 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const redactedValue = "*** (redacted)"
+
+// SuppressedEnv replaces the values of private envs hidden from users.
+var SuppressedEnv = "*** (private variable)"
+
+var (
+	// sensitiveFieldRegexp matches the names of fields, form inputs and URL
+	// query parameters holding credentials.
+	sensitiveFieldRegexp = regexp.MustCompile(`(?i)(passw|secret|token|credential|signature|^sig$|api.?key|access.?key|private.?key)`)
+
+	// envValueInputRegexp matches the form inputs holding env values, like
+	// the ones recorded by app.update.env.set events.
+	envValueInputRegexp = regexp.MustCompile(`^Envs\.\d+\.Value$`)
+)
+
+// Redact masks sensitive values in the custom data of the event, like values
+// of private envs and credentials in archive URLs, unless the permissions
+// include event.read.sensitive in one of the contexts allowed to see the
+// event. When events:suppress-sensitive-envs is enabled, values of private
+// envs are masked even for those holding event.read.sensitive.
+func (e *Event) Redact(perms []permTypes.Permission) error {
+	envsOnly := permission.CheckFromPermList(perms, permission.PermEventReadSensitive, e.Allowed.Contexts...)
+	if envsOnly {
+		if suppressEnabled, _ := config.GetBool("events:suppress-sensitive-envs"); !suppressEnabled {
+			return nil
+		}
+	}
+	for _, data := range []*mongoBSON.RawValue{&e.StartCustomData, &e.EndCustomData, &e.OtherCustomData} {
+		if len(data.Value) == 0 {
+			continue
+		}
+		var value any
+		err := data.Unmarshal(&value)
+		if err != nil {
+			return err
+		}
+		data.Type, data.Value, err = mongoBSON.MarshalValue(redactValue(value, envsOnly))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactValue masks sensitive values in place. With envsOnly, only the
+// values of private envs are masked.
+func redactValue(value any, envsOnly bool) any {
+	switch v := value.(type) {
+	case primitive.D:
+		redactDocument(v, envsOnly)
+	case primitive.A:
+		for i := range v {
+			v[i] = redactValue(v[i], envsOnly)
+		}
+	case string:
+		if !envsOnly {
+			return redactURL(v)
+		}
+	}
+	return value
+}
+
+// redactDocument masks, in place, sensitive fields of a document, the values
+// of private envs and of form inputs, as recorded by event.FormToCustomData,
+// holding credentials or env values.
+func redactDocument(doc primitive.D, envsOnly bool) {
+	fields := make(map[string]any, len(doc))
+	for _, elem := range doc {
+		fields[elem.Key] = elem.Value
+	}
+	var valueMask string
+	if public, ok := fields["public"].(bool); ok && !public {
+		valueMask = SuppressedEnv
+	}
+	if name, ok := fields["name"].(string); ok && len(doc) == 2 && !envsOnly {
+		if sensitiveFieldRegexp.MatchString(name) || envValueInputRegexp.MatchString(name) {
+			valueMask = redactedValue
+		}
+	}
+	for i, elem := range doc {
+		switch {
+		case elem.Key == "value" && valueMask != "":
+			doc[i].Value = maskValue(elem.Value, valueMask, envsOnly)
+		case sensitiveFieldRegexp.MatchString(elem.Key) && !envsOnly:
+			doc[i].Value = maskValue(elem.Value, redactedValue, envsOnly)
+		default:
+			doc[i].Value = redactValue(elem.Value, envsOnly)
+		}
+	}
+}
+
+func maskValue(value any, mask string, envsOnly bool) any {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		return mask
+	case primitive.A:
+		for i := range v {
+			v[i] = maskValue(v[i], mask, envsOnly)
+		}
+		return v
+	case primitive.D:
+		return redactValue(v, envsOnly)
+	}
+	// flags and numbers are kept, so custom data still decodes to the
+	// types recording it.
+	return value
+}
+
+// redactURL masks the password and the credentials in query parameters of
+// URLs, like presigned archive URLs. Other strings are returned unchanged.
+func redactURL(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return s
+	}
+	changed := false
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+		changed = true
+	}
+	query := u.Query()
+	for key := range query {
+		if sensitiveFieldRegexp.MatchString(key) {
+			query.Set(key, "xxxxx")
+			changed = true
+		}
+	}
+	if !changed {
+		return s
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
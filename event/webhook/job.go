@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+const jobExecutionFailedKind = "job execution failed"
+
+// notifyJob delivers failed executions to the notification channels of the
+// job. The webhook receives the event, holding the execution and the last
+// lines of its logs, and emails carry the same log lines.
+func (s *webhookService) notifyJob(ctx context.Context, evt *event.Event) error {
+	if evt.Kind.Name != jobExecutionFailedKind || evt.Target.Type != eventTypes.TargetTypeJob {
+		return nil
+	}
+	j, err := servicemanager.Job.GetByName(ctx, evt.Target.Value)
+	if err == jobTypes.ErrJobNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	notifications := j.Notifications
+	if notifications.IsEmpty() {
+		return nil
+	}
+	multi := tsuruErrors.NewMultiError()
+	if notifications.WebhookURL != "" {
+		err = s.doHook(eventTypes.Webhook{
+			Name: "job-" + j.Name,
+			URL:  notifications.WebhookURL,
+		}, evt)
+		if err != nil {
+			multi.Add(errors.Wrap(err, "unable to call job webhook"))
+		}
+	}
+	if len(notifications.Emails) > 0 {
+		err = sendEmail(notifications.Emails, jobNotificationMessage(evt))
+		if err != nil {
+			multi.Add(errors.Wrap(err, "unable to send job email"))
+		}
+	}
+	return multi.ToError()
+}
+
+func jobNotificationMessage(evt *event.Event) string {
+	msg := fmt.Sprintf("[tsuru] job %q failed: %s", evt.Target.Value, evt.Error)
+	var failure struct {
+		Logs []appTypes.Applog
+	}
+	if err := evt.StartData(&failure); err != nil || len(failure.Logs) == 0 {
+		return msg
+	}
+	lines := make([]string, len(failure.Logs))
+	for i, l := range failure.Logs {
+		lines[i] = fmt.Sprintf("%s [%s]: %s", l.Date.Format("2006-01-02 15:04:05"), l.Unit, l.Message)
+	}
+	return msg + "\r\n\r\n" + strings.Join(lines, "\r\n")
+}
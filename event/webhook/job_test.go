@@ -0,0 +1,80 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestWebhookServiceNotifyJobExecutionFailure(c *check.C) {
+	hookCalled := make(chan []byte, 1)
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hookCalled <- body
+	}))
+	defer hookSrv.Close()
+	var mock servicemock.MockService
+	servicemock.SetMockService(&mock)
+	mock.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		c.Assert(name, check.Equals, "myjob")
+		return &jobTypes.Job{Name: name, TeamOwner: "myteam", Notifications: jobTypes.Notifications{WebhookURL: hookSrv.URL}}, nil
+	}
+	evt, err := event.NewInternal(context.TODO(), &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "myjob"},
+		InternalKind: jobExecutionFailedKind,
+		CustomData: map[string]interface{}{
+			"execution": jobTypes.Execution{ID: "myjob-1", Job: "myjob", Status: jobTypes.ExecutionFailed},
+			"logs":      []appTypes.Applog{{Date: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), Unit: "myjob-1-abc", Message: "panic: boom"}},
+		},
+		Allowed: event.Allowed(permission.PermJobReadEvents, permission.Context(permTypes.CtxTeam, "myteam")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), errors.New("execution myjob-1 failed: BackoffLimitExceeded"))
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	var received map[string]interface{}
+	err = json.Unmarshal(<-hookCalled, &received)
+	c.Assert(err, check.IsNil)
+	c.Assert(received["Error"], check.Equals, "execution myjob-1 failed: BackoffLimitExceeded")
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(jobNotificationMessage(dbEvt), check.Equals, "[tsuru] job \"myjob\" failed: execution myjob-1 failed: BackoffLimitExceeded\r\n\r\n2026-10-16 12:00:00 [myjob-1-abc]: panic: boom")
+}
+
+func (s *S) TestWebhookServiceNotifyJobIgnoresOtherEvents(c *check.C) {
+	var mock servicemock.MockService
+	servicemock.SetMockService(&mock)
+	mock.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		c.Fatal("job should not be notified")
+		return nil, nil
+	}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "myjob"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermJobUpdate,
+		Allowed:  event.Allowed(permission.PermJobReadEvents, permission.Context(permTypes.CtxTeam, "myteam")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), errors.New("update failed"))
+	c.Assert(err, check.IsNil)
+	err = s.service.notifyJob(context.TODO(), evt)
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const deployKind = "app.deploy"
+
+// teamNotificationKinds are the kinds of events notified to the team owning
+// their target, with the description used in messages. Deploys are only
+// notified when they fail.
+var teamNotificationKinds = map[string]string{
+	deployKind:                  "deploy",
+	"reconcile":                 "drift repair",
+	"crash-loop":                "crash loop",
+	"team.update.quota.request": "quota increase request",
+	"quota grant expire":        "quota grant expiration",
+	"budget exceeded":           "budget check",
+}
+
+func (s *webhookService) notifyTeam(ctx context.Context, evt *event.Event) error {
+	desc, ok := teamNotificationKinds[evt.Kind.Name]
+	if !ok || (evt.Kind.Name == deployKind && evt.Error == "") {
+		return nil
+	}
+	teamName := eventTeam(ctx, evt)
+	if teamName == "" {
+		return nil
+	}
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	notifications := team.Notifications
+	if notifications.IsEmpty() {
+		return nil
+	}
+	message := teamNotificationMessage(evt, desc)
+	multi := tsuruErrors.NewMultiError()
+	if notifications.WebhookURL != "" {
+		err = s.doHook(eventTypes.Webhook{
+			Name: "team-" + team.Name,
+			URL:  notifications.WebhookURL,
+		}, evt)
+		if err != nil {
+			multi.Add(errors.Wrap(err, "unable to call team webhook"))
+		}
+	}
+	if notifications.SlackURL != "" {
+		err = postSlackMessage(notifications.SlackURL, message)
+		if err != nil {
+			multi.Add(errors.Wrap(err, "unable to post team slack message"))
+		}
+	}
+	if len(notifications.Emails) > 0 {
+		err = sendEmail(notifications.Emails, message)
+		if err != nil {
+			multi.Add(errors.Wrap(err, "unable to send team email"))
+		}
+	}
+	return multi.ToError()
+}
+
+func eventTeam(ctx context.Context, evt *event.Event) string {
+	switch evt.Target.Type {
+	case eventTypes.TargetTypeTeam:
+		return evt.Target.Value
+	case eventTypes.TargetTypeApp:
+		a, err := servicemanager.App.GetByName(ctx, evt.Target.Value)
+		if err != nil {
+			// The app may be gone already, there's no one left to notify.
+			return ""
+		}
+		return a.TeamOwner
+	}
+	return ""
+}
+
+func teamNotificationMessage(evt *event.Event, desc string) string {
+	status := "succeeded"
+	if evt.Error != "" {
+		status = "failed"
+	}
+	msg := fmt.Sprintf("[tsuru] %s %s for %s %q", desc, status, evt.Target.Type, evt.Target.Value)
+	if evt.Error != "" {
+		msg += ": " + evt.Error
+	}
+	return msg
+}
+
+func postSlackMessage(url, message string) error {
+	data, err := json.Marshal(map[string]string{"text": message})
+	if err != nil {
+		return err
+	}
+	client, err := tsuruNet.WithProxyFromConfig(*tsuruNet.Dial15Full60ClientNoKeepAlive, url)
+	if err != nil {
+		return err
+	}
+	rsp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 400 {
+		body, _ := io.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code: %d: %s", rsp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func sendEmail(to []string, message string) error {
+	server, _ := config.GetString("smtp:server")
+	if server == "" {
+		return errors.New(`Setting "smtp:server" is not defined`)
+	}
+	if !strings.Contains(server, ":") {
+		server += ":25"
+	}
+	user, err := config.GetString("smtp:user")
+	if err != nil {
+		return errors.New(`Setting "smtp:user" is not defined`)
+	}
+	var auth smtp.Auth
+	if password, _ := config.GetString("smtp:password"); password != "" {
+		host, _, _ := net.SplitHostPort(server)
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+	subject, _, _ := strings.Cut(message, ":")
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", user, strings.Join(to, ", "), subject, message)
+	return smtp.SendMail(server, auth, user, to, body.Bytes())
+}
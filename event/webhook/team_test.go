@@ -0,0 +1,88 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestWebhookServiceNotifyTeamDeployFailure(c *check.C) {
+	hookCalled := make(chan []byte, 1)
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hookCalled <- body
+	}))
+	defer hookSrv.Close()
+	slackCalled := make(chan map[string]string, 1)
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		json.NewDecoder(r.Body).Decode(&msg)
+		slackCalled <- msg
+	}))
+	defer slackSrv.Close()
+	var mock servicemock.MockService
+	servicemock.SetMockService(&mock)
+	mock.App.Apps = []*appTypes.App{{Name: "myapp", TeamOwner: "myteam"}}
+	mock.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		c.Assert(name, check.Equals, "myteam")
+		return &authTypes.Team{Name: name, Notifications: authTypes.TeamNotifications{
+			WebhookURL: hookSrv.URL,
+			SlackURL:   slackSrv.URL,
+		}}, nil
+	}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppDeploy,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), errors.New("build failed"))
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	var received map[string]interface{}
+	err = json.Unmarshal(<-hookCalled, &received)
+	c.Assert(err, check.IsNil)
+	c.Assert(received["Error"], check.Equals, "build failed")
+	c.Assert(<-slackCalled, check.DeepEquals, map[string]string{
+		"text": `[tsuru] deploy failed for app "myapp": build failed`,
+	})
+}
+
+func (s *S) TestWebhookServiceNotifyTeamIgnoresSuccessfulDeploy(c *check.C) {
+	var mock servicemock.MockService
+	servicemock.SetMockService(&mock)
+	mock.App.Apps = []*appTypes.App{{Name: "myapp", TeamOwner: "myteam"}}
+	mock.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		c.Fatal("team should not be notified")
+		return nil, nil
+	}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppDeploy,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	err = s.service.notifyTeam(context.TODO(), evt)
+	c.Assert(err, check.IsNil)
+}
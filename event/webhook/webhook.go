@@ -12,12 +12,14 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/shutdown"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -25,7 +27,10 @@ import (
 	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/storage"
 	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
 	"github.com/tsuru/tsuru/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -33,6 +38,16 @@ var (
 
 	chanBufferSize   = 1000
 	defaultUserAgent = "tsuru-webhook-client/1.0"
+
+	defaultMaxAttempts       = 5
+	maxAttemptsLimit         = 30
+	defaultRetryBackoff      = 30 * time.Second
+	maxRetryBackoff          = 24 * time.Hour
+	defaultDeliveryRetention = 7 * 24 * time.Hour
+	retryCheckInterval       = 5 * time.Second
+	// retryClaimTimeout postpones a claimed delivery long enough for the
+	// attempt to finish, it's only retried again if the claimer dies.
+	retryClaimTimeout = 5 * time.Minute
 )
 
 func WebhookService() (eventTypes.WebhookService, error) {
@@ -44,10 +59,26 @@ func WebhookService() (eventTypes.WebhookService, error) {
 		}
 	}
 	s := &webhookService{
-		storage: dbDriver.WebhookStorage,
-		evtCh:   make(chan string, chanBufferSize),
-		quitCh:  make(chan struct{}),
-		doneCh:  make(chan struct{}),
+		storage:           dbDriver.WebhookStorage,
+		evtCh:             make(chan string, chanBufferSize),
+		quitCh:            make(chan struct{}),
+		doneCh:            make(chan struct{}),
+		maxAttempts:       defaultMaxAttempts,
+		retryBackoff:      defaultRetryBackoff,
+		deliveryRetention: defaultDeliveryRetention,
+		retryInterval:     retryCheckInterval,
+	}
+	if _, err = config.Get("webhooks:max-attempts"); err == nil {
+		s.maxAttempts, err = config.GetInt("webhooks:max-attempts")
+		if err != nil || s.maxAttempts < 1 || s.maxAttempts > maxAttemptsLimit {
+			return nil, errors.Errorf("webhooks:max-attempts must be a number between 1 and %d", maxAttemptsLimit)
+		}
+	}
+	if d, _ := config.GetDuration("webhooks:retry-backoff"); d > 0 {
+		s.retryBackoff = d
+	}
+	if d, _ := config.GetDuration("webhooks:delivery-retention"); d > 0 {
+		s.deliveryRetention = d
 	}
 	err = s.initMetrics()
 	if err != nil {
@@ -64,6 +95,11 @@ type webhookService struct {
 	quitCh  chan struct{}
 	doneCh  chan struct{}
 
+	maxAttempts       int
+	retryBackoff      time.Duration
+	deliveryRetention time.Duration
+	retryInterval     time.Duration
+
 	webhooksLatency prometheus.Histogram
 	webhooksTotal   prometheus.Counter
 	webhooksError   prometheus.Counter
@@ -126,6 +162,8 @@ func (s *webhookService) Notify(ctx context.Context, evtID string) {
 
 func (s *webhookService) run() {
 	defer close(s.doneCh)
+	retryTicker := time.NewTicker(s.retryInterval)
+	defer retryTicker.Stop()
 	for {
 		select {
 		case evtID := <-s.evtCh:
@@ -133,6 +171,11 @@ func (s *webhookService) run() {
 			if err != nil {
 				log.Errorf("[webhooks] error handling webhooks for event %q: %v", evtID, err)
 			}
+		case <-retryTicker.C:
+			err := s.retryDeliveries(context.Background(), time.Now().UTC())
+			if err != nil {
+				log.Errorf("[webhooks] error retrying webhook deliveries: %v", err)
+			}
 		case <-s.quitCh:
 			return
 		}
@@ -154,12 +197,29 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 		filter.TargetTypes = append(filter.TargetTypes, string(t.Target.Type))
 		filter.TargetValues = append(filter.TargetValues, t.Target.Value)
 	}
+	err = s.notifyTeam(ctx, evt)
+	if err != nil {
+		log.Errorf("[webhooks] error notifying team for event %q: %v", evtID, err)
+	}
+	err = s.notifyJob(ctx, evt)
+	if err != nil {
+		log.Errorf("[webhooks] error notifying job for event %q: %v", evtID, err)
+	}
 	hooks, err := s.storage.FindByEvent(ctx, filter, evt.Error == "")
 	if err != nil {
 		return err
 	}
 	for _, h := range hooks {
-		err = s.doHook(h, evt)
+		if !hookMatchesEvent(h, evt) {
+			continue
+		}
+		delivery := eventTypes.WebhookDelivery{
+			ID:        primitive.NewObjectID(),
+			Webhook:   h.Name,
+			EventID:   evtID,
+			CreatedAt: time.Now().UTC(),
+		}
+		err = s.deliver(ctx, &delivery, h, evt)
 		if err != nil {
 			log.Errorf("[webhooks] error calling webhook %q for event %q: %v", h.Name, evtID, err)
 		}
@@ -167,6 +227,132 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 	return nil
 }
 
+// hookMatchesEvent checks the parts of the hook filter which are not handled
+// by the storage: target globs, teams and team scoping.
+func hookMatchesEvent(h eventTypes.Webhook, evt *event.Event) bool {
+	types := []string{string(evt.Target.Type)}
+	values := []string{evt.Target.Value}
+	for _, t := range evt.ExtraTargets {
+		types = append(types, string(t.Target.Type))
+		values = append(values, t.Target.Value)
+	}
+	if !matchAny(h.EventFilter.TargetTypes, types) || !matchAny(h.EventFilter.TargetValues, values) {
+		return false
+	}
+	if len(h.EventFilter.Teams) > 0 {
+		var allowed bool
+		for _, team := range h.EventFilter.Teams {
+			if eventAllowedForTeam(evt, team) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return !h.TeamScoped || eventAllowedForTeam(evt, h.TeamOwner)
+}
+
+func matchAny(patterns, values []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		for _, v := range values {
+			if ok, _ := path.Match(p, v); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deliver calls the hook and records the attempt in the delivery, scheduling
+// a retry with exponential backoff when it fails and attempts remain.
+func (s *webhookService) deliver(ctx context.Context, d *eventTypes.WebhookDelivery, hook eventTypes.Webhook, evt *event.Event) error {
+	now := time.Now().UTC()
+	statusCode, err := s.callHook(hook, evt)
+	d.Attempts++
+	d.LastAttempt = now
+	d.StatusCode = statusCode
+	d.Error = ""
+	d.NextAttempt = time.Time{}
+	d.ExpireAt = now.Add(s.deliveryRetention)
+	switch {
+	case err == nil:
+		d.Status = eventTypes.WebhookDeliverySuccess
+	case d.Attempts >= s.maxAttempts:
+		d.Status = eventTypes.WebhookDeliveryFailed
+		d.Error = err.Error()
+	default:
+		d.Status = eventTypes.WebhookDeliveryRetrying
+		d.Error = err.Error()
+		d.NextAttempt = now.Add(s.retryDelay(d.Attempts))
+	}
+	saveErr := s.storage.SaveDelivery(ctx, *d)
+	if saveErr != nil {
+		log.Errorf("[webhooks] unable to save delivery of webhook %q for event %q: %v", d.Webhook, d.EventID, saveErr)
+	}
+	return err
+}
+
+// retryDelay returns the wait before the next attempt of a delivery, doubling
+// the backoff at each attempt up to maxRetryBackoff.
+func (s *webhookService) retryDelay(attempts int) time.Duration {
+	delay := s.retryBackoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+func (s *webhookService) retryDeliveries(ctx context.Context, now time.Time) error {
+	for {
+		d, err := s.storage.ClaimDeliveryRetry(ctx, now, now.Add(retryClaimTimeout))
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return nil
+		}
+		err = s.retryDelivery(ctx, d)
+		if err != nil {
+			log.Errorf("[webhooks] error retrying webhook %q for event %q: %v", d.Webhook, d.EventID, err)
+		}
+	}
+}
+
+func (s *webhookService) retryDelivery(ctx context.Context, d *eventTypes.WebhookDelivery) error {
+	hook, err := s.storage.FindByName(ctx, d.Webhook)
+	if err == nil {
+		var evt *event.Event
+		evt, err = event.GetByHexID(ctx, d.EventID)
+		if err == nil {
+			return s.deliver(ctx, d, *hook, evt)
+		}
+	}
+	d.Status = eventTypes.WebhookDeliveryFailed
+	d.Error = err.Error()
+	d.NextAttempt = time.Time{}
+	saveErr := s.storage.SaveDelivery(ctx, *d)
+	if saveErr != nil {
+		log.Errorf("[webhooks] unable to save delivery of webhook %q for event %q: %v", d.Webhook, d.EventID, saveErr)
+	}
+	return err
+}
+
+// eventAllowedForTeam reports whether members of the team are allowed to see
+// the event, as in events of apps and jobs of the team.
+func eventAllowedForTeam(evt *event.Event, team string) bool {
+	for _, ctx := range evt.Allowed.Contexts {
+		if ctx.CtxType == permTypes.CtxTeam && ctx.Value == team {
+			return true
+		}
+	}
+	return false
+}
+
 func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error) {
 	if hook.Body != "" {
 		tpl, err := template.New(hook.Name).Parse(hook.Body)
@@ -197,7 +383,14 @@ func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error)
 	return bytes.NewReader(data), nil
 }
 
-func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err error) {
+func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) error {
+	_, err := s.callHook(hook, evt)
+	return err
+}
+
+// callHook calls the hook for the event, returning the response status code
+// when there's one.
+func (s *webhookService) callHook(hook eventTypes.Webhook, evt *event.Event) (statusCode int, err error) {
 	defer func() {
 		s.webhooksTotal.Inc()
 		if err != nil {
@@ -208,13 +401,22 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	if hook.Method == "" {
 		hook.Method = http.MethodPost
 	}
+	if hook.TeamScoped {
+		// Team scoped hooks may be created by any team member, who may not
+		// be allowed to see sensitive data of the events.
+		evt = &event.Event{EventData: evt.EventData}
+		err = evt.Redact(nil)
+		if err != nil {
+			return 0, err
+		}
+	}
 	body, err := webhookBody(&hook, evt)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	req, err := http.NewRequest(hook.Method, hook.URL, body)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	req.Header = hook.Headers
 
@@ -232,26 +434,26 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	if hook.ProxyURL != "" {
 		client, err = tsuruNet.WithProxy(*client, hook.ProxyURL)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	} else {
 		client, err = tsuruNet.WithProxyFromConfig(*client, hook.URL)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 	reqStart := time.Now()
 	rsp, err := client.Do(req)
 	s.webhooksLatency.Observe(time.Since(reqStart).Seconds())
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer rsp.Body.Close()
 	if rsp.StatusCode < 200 || rsp.StatusCode >= 400 {
 		data, _ := io.ReadAll(rsp.Body)
-		return errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
+		return rsp.StatusCode, errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
 	}
-	return nil
+	return rsp.StatusCode, nil
 }
 
 func validateURLs(w eventTypes.Webhook) error {
@@ -275,6 +477,17 @@ func validateURLs(w eventTypes.Webhook) error {
 	return nil
 }
 
+func validateEventFilter(f eventTypes.WebhookEventFilter) error {
+	for _, p := range append(f.TargetTypes, f.TargetValues...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("webhook target filter %q is not a valid glob: %v", p, err),
+			}
+		}
+	}
+	return nil
+}
+
 func (s *webhookService) Create(ctx context.Context, w eventTypes.Webhook) error {
 	if w.Name == "" {
 		return &tsuruErrors.ValidationError{Message: "webhook name must not be empty"}
@@ -288,14 +501,53 @@ func (s *webhookService) Create(ctx context.Context, w eventTypes.Webhook) error
 	if err != nil {
 		return err
 	}
+	err = validateEventFilter(w.EventFilter)
+	if err != nil {
+		return err
+	}
+	err = s.checkTeamQuota(ctx, w.TeamOwner)
+	if err != nil {
+		return err
+	}
 	return s.storage.Insert(ctx, w)
 }
 
+// checkTeamQuota enforces the maximum number of webhooks owned by a team, set
+// by quota:webhooks-per-team.
+func (s *webhookService) checkTeamQuota(ctx context.Context, team string) error {
+	limit, _ := config.GetInt("quota:webhooks-per-team")
+	if limit <= 0 {
+		return nil
+	}
+	webhooks, err := s.storage.FindAllByTeams(ctx, []string{team})
+	if err != nil {
+		return err
+	}
+	if len(webhooks) >= limit {
+		return &quotaTypes.QuotaExceededError{Requested: 1, Available: 0}
+	}
+	return nil
+}
+
 func (s *webhookService) Update(ctx context.Context, w eventTypes.Webhook) error {
 	err := validateURLs(w)
 	if err != nil {
 		return err
 	}
+	err = validateEventFilter(w.EventFilter)
+	if err != nil {
+		return err
+	}
+	current, err := s.storage.FindByName(ctx, w.Name)
+	if err != nil {
+		return err
+	}
+	if current.TeamOwner != w.TeamOwner {
+		err = s.checkTeamQuota(ctx, w.TeamOwner)
+		if err != nil {
+			return err
+		}
+	}
 	return s.storage.Update(ctx, w)
 }
 
@@ -314,3 +566,11 @@ func (s *webhookService) Find(ctx context.Context, name string) (eventTypes.Webh
 func (s *webhookService) List(ctx context.Context, teams []string) ([]eventTypes.Webhook, error) {
 	return s.storage.FindAllByTeams(ctx, teams)
 }
+
+func (s *webhookService) Deliveries(ctx context.Context, name string, limit int) ([]eventTypes.WebhookDelivery, error) {
+	_, err := s.storage.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.FindDeliveries(ctx, name, limit)
+}
@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/db/storagev2"
@@ -21,6 +22,7 @@ import (
 	_ "github.com/tsuru/tsuru/storage/mongodb"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
 	check "gopkg.in/check.v1"
 )
 
@@ -105,6 +107,70 @@ func (s *S) TestWebhookServiceNotify(c *check.C) {
 	})
 }
 
+func (s *S) TestWebhookServiceNotifyTeamScoped(c *check.C) {
+	var targets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evt event.Event
+		json.NewDecoder(r.Body).Decode(&evt)
+		targets = append(targets, evt.Target.Value)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name:       "xyz",
+		TeamOwner:  "team1",
+		URL:        srv.URL,
+		TeamScoped: true,
+	})
+	c.Assert(err, check.IsNil)
+	for _, team := range []string{"team1", "team2"} {
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:   eventTypes.Target{Type: "app", Value: "app-" + team},
+			RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+			Kind:     permission.PermAppUpdateEnvSet,
+			Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, team)),
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(context.TODO(), nil)
+		c.Assert(err, check.IsNil)
+		err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(targets, check.DeepEquals, []string{"app-team1"})
+}
+
+func (s *S) TestWebhookServiceNotifyTeamScopedRedactsSensitiveData(c *check.C) {
+	var received event.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name:       "xyz",
+		TeamOwner:  "team1",
+		URL:        srv.URL,
+		TeamScoped: true,
+	})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:     eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner:   eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:       permission.PermAppUpdateEnvSet,
+		CustomData: event.FormToCustomData(url.Values{"password": {"mypass"}}),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, "team1")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	var form []map[string]string
+	err = received.StartCustomData.Unmarshal(&form)
+	c.Assert(err, check.IsNil)
+	c.Assert(form, check.DeepEquals, []map[string]string{{"name": "password", "value": "*** (redacted)"}})
+}
+
 func (s *S) TestWebhookServiceNotifyDefaultBody(c *check.C) {
 	evt, err := event.New(context.TODO(), &event.Opts{
 		Target: eventTypes.Target{Type: "app", Value: "myapp"},
@@ -275,10 +341,38 @@ func (s *S) TestWebhookServiceCreate(c *check.C) {
 			TargetValues: []string{},
 			KindTypes:    []string{},
 			KindNames:    []string{},
+			Teams:        []string{},
 		},
 	})
 }
 
+func (s *S) TestWebhookServiceCreateTeamQuota(c *check.C) {
+	config.Set("quota:webhooks-per-team", 1)
+	defer config.Unset("quota:webhooks-per-team")
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{Name: "wh1", TeamOwner: "team1", URL: "http://me"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Create(context.TODO(), eventTypes.Webhook{Name: "wh2", TeamOwner: "team2", URL: "http://me"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Create(context.TODO(), eventTypes.Webhook{Name: "wh3", TeamOwner: "team1", URL: "http://me"})
+	c.Assert(err, check.DeepEquals, &quotaTypes.QuotaExceededError{Requested: 1, Available: 0})
+}
+
+func (s *S) TestWebhookServiceUpdateTeamQuota(c *check.C) {
+	config.Set("quota:webhooks-per-team", 1)
+	defer config.Unset("quota:webhooks-per-team")
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{Name: "wh1", TeamOwner: "team1", URL: "http://me"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Create(context.TODO(), eventTypes.Webhook{Name: "wh2", TeamOwner: "team2", URL: "http://me"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{Name: "wh1", TeamOwner: "team1", URL: "http://me/other"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{Name: "wh2", TeamOwner: "team1", URL: "http://me"})
+	c.Assert(err, check.DeepEquals, &quotaTypes.QuotaExceededError{Requested: 1, Available: 0})
+	w, err := s.service.Find(context.TODO(), "wh2")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.TeamOwner, check.Equals, "team2")
+}
+
 func (s *S) TestWebhookServiceCreateInvalid(c *check.C) {
 	var tests = []struct {
 		name, url, proxyURL string
@@ -358,6 +452,7 @@ func (s *S) TestWebhookServiceUpdate(c *check.C) {
 			TargetValues: []string{},
 			KindTypes:    []string{},
 			KindNames:    []string{},
+			Teams:        []string{},
 		},
 	})
 }
@@ -386,3 +481,142 @@ func (s *S) TestWebhookServiceDeleteNotFound(c *check.C) {
 	err := s.service.Delete(context.TODO(), "xyz")
 	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
 }
+
+func (s *S) TestWebhookServiceCreateInvalidGlob(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  "http://a",
+		EventFilter: eventTypes.WebhookEventFilter{
+			TargetValues: []string{"myapp-["},
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `webhook target filter "myapp-\[" is not a valid glob: syntax error in pattern`)
+}
+
+func (s *S) TestHookMatchesEvent(c *check.C) {
+	evt := &event.Event{}
+	evt.Target = eventTypes.Target{Type: "app", Value: "myapp-prod"}
+	evt.ExtraTargets = []eventTypes.ExtraTarget{{Target: eventTypes.Target{Type: "pool", Value: "pool1"}}}
+	evt.Allowed = event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, "team1"))
+	tests := []struct {
+		hook     eventTypes.Webhook
+		expected bool
+	}{
+		{hook: eventTypes.Webhook{}, expected: true},
+		{hook: eventTypes.Webhook{EventFilter: eventTypes.WebhookEventFilter{TargetValues: []string{"myapp-*"}}}, expected: true},
+		{hook: eventTypes.Webhook{EventFilter: eventTypes.WebhookEventFilter{TargetValues: []string{"otherapp-*"}}}, expected: false},
+		{hook: eventTypes.Webhook{EventFilter: eventTypes.WebhookEventFilter{TargetValues: []string{"pool?"}}}, expected: true},
+		{hook: eventTypes.Webhook{EventFilter: eventTypes.WebhookEventFilter{TargetTypes: []string{"job*"}}}, expected: false},
+		{hook: eventTypes.Webhook{EventFilter: eventTypes.WebhookEventFilter{Teams: []string{"team2", "team1"}}}, expected: true},
+		{hook: eventTypes.Webhook{EventFilter: eventTypes.WebhookEventFilter{Teams: []string{"team2"}}}, expected: false},
+		{hook: eventTypes.Webhook{TeamOwner: "team2", TeamScoped: true}, expected: false},
+		{hook: eventTypes.Webhook{TeamOwner: "team1", TeamScoped: true}, expected: true},
+	}
+	for i, tt := range tests {
+		c.Check(hookMatchesEvent(tt.hook, evt), check.Equals, tt.expected, check.Commentf("failed test %d", i))
+	}
+}
+
+func (s *S) TestWebhookServiceDeliveryRetries(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	statusCode := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer srv.Close()
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	s.service.maxAttempts = 3
+	s.service.retryBackoff = time.Minute
+	start := time.Now().UTC()
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.Deliveries(context.TODO(), "xyz", 10)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	d := deliveries[0]
+	c.Assert(d.EventID, check.Equals, evt.UniqueID.Hex())
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliveryRetrying)
+	c.Assert(d.Attempts, check.Equals, 1)
+	c.Assert(d.StatusCode, check.Equals, http.StatusServiceUnavailable)
+	c.Assert(d.NextAttempt.After(start.Add(time.Minute-time.Second)), check.Equals, true)
+	err = s.service.retryDeliveries(context.TODO(), start.Add(2*time.Minute))
+	c.Assert(err, check.IsNil)
+	deliveries, err = s.service.Deliveries(context.TODO(), "xyz", 10)
+	c.Assert(err, check.IsNil)
+	d = deliveries[0]
+	c.Assert(d.Attempts, check.Equals, 2)
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliveryRetrying)
+	c.Assert(d.NextAttempt.Sub(d.LastAttempt), check.Equals, 2*time.Minute)
+	statusCode = http.StatusOK
+	err = s.service.retryDeliveries(context.TODO(), start.Add(5*time.Minute))
+	c.Assert(err, check.IsNil)
+	deliveries, err = s.service.Deliveries(context.TODO(), "xyz", 10)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	d = deliveries[0]
+	c.Assert(d.Attempts, check.Equals, 3)
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliverySuccess)
+	c.Assert(d.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(d.Error, check.Equals, "")
+	c.Assert(d.NextAttempt.IsZero(), check.Equals, true)
+}
+
+func (s *S) TestWebhookServiceRetryDelay(c *check.C) {
+	s.service.retryBackoff = time.Minute
+	c.Assert(s.service.retryDelay(1), check.Equals, time.Minute)
+	c.Assert(s.service.retryDelay(3), check.Equals, 4*time.Minute)
+	c.Assert(s.service.retryDelay(12), check.Equals, 24*time.Hour)
+	c.Assert(s.service.retryDelay(100), check.Equals, 24*time.Hour)
+	s.service.retryBackoff = 48 * time.Hour
+	c.Assert(s.service.retryDelay(1), check.Equals, 24*time.Hour)
+}
+
+func (s *S) TestWebhookServiceInvalidMaxAttempts(c *check.C) {
+	defer config.Unset("webhooks:max-attempts")
+	for _, value := range []interface{}{0, -1, 31, "many"} {
+		config.Set("webhooks:max-attempts", value)
+		_, err := WebhookService()
+		c.Assert(err, check.ErrorMatches, "webhooks:max-attempts must be a number between 1 and 30")
+	}
+}
+
+func (s *S) TestWebhookServiceDeliveryFails(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	s.service.maxAttempts = 1
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.Deliveries(context.TODO(), "xyz", 10)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryFailed)
+	c.Assert(deliveries[0].Error, check.Equals, "invalid status code calling hook: 500: ")
+	c.Assert(deliveries[0].NextAttempt.IsZero(), check.Equals, true)
+}
+
+func (s *S) TestWebhookServiceDeliveriesNotFound(c *check.C) {
+	_, err := s.service.Deliveries(context.TODO(), "xyz", 10)
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
+}
@@ -31,7 +31,7 @@ func buildPlan(ctx context.Context, job *jobTypes.Job) error {
 	if err != nil {
 		return err
 	}
-	plan, err := jobPool.GetDefaultPlan(ctx)
+	plan, err := jobPool.GetDefaultPlanForTeam(ctx, job.TeamOwner)
 	if err != nil {
 		return err
 	}
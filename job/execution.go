@@ -0,0 +1,242 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	jobExecutionsCollectionName = "job_executions"
+
+	defaultExecutionRetention = 30 * 24 * time.Hour
+	defaultExecutionLogLines  = 1000
+	defaultExecutionsLimit    = 100
+	defaultNotificationLines  = 20
+
+	// ExecutionFailedEventKind is the kind of the events created for failed
+	// executions of jobs with notification channels.
+	ExecutionFailedEventKind = "job execution failed"
+)
+
+var ErrExecutionNotFound = errors.New("job execution not found")
+
+type executionFailure struct {
+	Execution jobTypes.Execution `json:"execution"`
+	Logs      []appTypes.Applog  `json:"logs"`
+}
+
+type executionEntry struct {
+	jobTypes.Execution `bson:",inline"`
+	Logs               []appTypes.Applog
+	ExpireAt           time.Time
+}
+
+func executionRetention() time.Duration {
+	retention, _ := config.GetDuration("jobs:executions:retention")
+	if retention <= 0 {
+		return defaultExecutionRetention
+	}
+	return retention
+}
+
+func notificationLogLines() int {
+	lines, _ := config.GetInt("jobs:notifications:log-lines")
+	if lines <= 0 {
+		return defaultNotificationLines
+	}
+	return lines
+}
+
+func executionLogLines() int {
+	lines, _ := config.GetInt("jobs:executions:log-lines")
+	if lines <= 0 {
+		return defaultExecutionLogLines
+	}
+	return lines
+}
+
+// executionUnit reports whether the unit, a pod, was created by the
+// execution. Pods of a kubernetes job are named after it.
+func executionUnit(executionID, unit string) bool {
+	return strings.HasPrefix(unit, executionID+"-")
+}
+
+func executionLogsFromService(ctx context.Context, jobName, executionID string, limit int) ([]appTypes.Applog, error) {
+	logs, err := servicemanager.LogService.List(ctx, appTypes.ListLogArgs{
+		Name:  jobName,
+		Type:  logTypes.LogTypeJob,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result []appTypes.Applog
+	for _, l := range logs {
+		if executionUnit(executionID, l.Unit) {
+			result = append(result, l)
+		}
+	}
+	if len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
+
+// RecordExecution stores a finished execution of a job along with the last
+// lines of its logs, read from the log service while the units of the
+// execution still exist.
+func RecordExecution(ctx context.Context, execution jobTypes.Execution) error {
+	collection, err := storagev2.Collection(jobExecutionsCollectionName)
+	if err != nil {
+		return err
+	}
+	logs, err := executionLogsFromService(ctx, execution.Job, execution.ID, executionLogLines())
+	if err != nil {
+		return errors.Wrapf(err, "unable to capture logs of execution %s", execution.ID)
+	}
+	if execution.EndTime.IsZero() {
+		execution.EndTime = time.Now().UTC()
+	}
+	entry := executionEntry{
+		Execution: execution,
+		Logs:      logs,
+		ExpireAt:  execution.EndTime.Add(executionRetention()),
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"job": execution.Job, "id": execution.ID}, entry, options.Replace().SetUpsert(true))
+	if err != nil || execution.Status != jobTypes.ExecutionFailed {
+		return err
+	}
+	return notifyExecutionFailure(ctx, execution, logs)
+}
+
+// notifyExecutionFailure creates an event for the failed execution, with the
+// last lines of its logs, delivered to the notification channels of the job.
+func notifyExecutionFailure(ctx context.Context, execution jobTypes.Execution, logs []appTypes.Applog) error {
+	j, err := servicemanager.Job.GetByName(ctx, execution.Job)
+	if err == jobTypes.ErrJobNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if j.Notifications.IsEmpty() {
+		return nil
+	}
+	excerpt := notificationLogLines()
+	if len(logs) > excerpt {
+		logs = logs[len(logs)-excerpt:]
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: j.Name},
+		InternalKind: ExecutionFailedEventKind,
+		DisableLock:  true,
+		CustomData:   executionFailure{Execution: execution, Logs: logs},
+		Allowed:      event.Allowed(permission.PermJobReadEvents, permission.Context(permTypes.CtxTeam, j.TeamOwner)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to create job execution failure event")
+	}
+	msg := fmt.Sprintf("execution %s failed", execution.ID)
+	if execution.Reason != "" {
+		msg += ": " + execution.Reason
+	}
+	return evt.Done(ctx, errors.New(msg))
+}
+
+// ListExecutions returns the executions of the job, most recent first. Runs
+// still in progress, as reported by the provisioner, are listed before the
+// finished ones.
+func ListExecutions(ctx context.Context, job *jobTypes.Job, limit int) ([]jobTypes.Execution, error) {
+	collection, err := storagev2.Collection(jobExecutionsCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > defaultExecutionsLimit {
+		limit = defaultExecutionsLimit
+	}
+	opts := options.Find().
+		SetSort(mongoBSON.M{"starttime": -1}).
+		SetLimit(int64(limit)).
+		SetProjection(mongoBSON.M{"logs": 0})
+	cursor, err := collection.Find(ctx, mongoBSON.M{"job": job.Name}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var entries []executionEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	recorded := map[string]struct{}{}
+	for _, e := range entries {
+		recorded[e.ID] = struct{}{}
+	}
+	units, err := Units(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	executions := []jobTypes.Execution{}
+	for _, u := range units {
+		if _, ok := recorded[u.ID]; ok || u.Status != provTypes.UnitStatusStarted {
+			continue
+		}
+		execution := jobTypes.Execution{ID: u.ID, Job: job.Name, Status: jobTypes.ExecutionRunning}
+		if u.CreatedAt != nil {
+			execution.StartTime = *u.CreatedAt
+		}
+		executions = append(executions, execution)
+	}
+	for _, e := range entries {
+		executions = append(executions, e.Execution)
+	}
+	return executions, nil
+}
+
+// ExecutionLogs returns the logs of an execution of the job. Logs of
+// finished executions are the ones captured when they finished, logs of
+// running executions are read from the log service.
+func ExecutionLogs(ctx context.Context, job *jobTypes.Job, executionID string) ([]appTypes.Applog, error) {
+	collection, err := storagev2.Collection(jobExecutionsCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	var entry executionEntry
+	err = collection.FindOne(ctx, mongoBSON.M{"job": job.Name, "id": executionID}).Decode(&entry)
+	if err == nil {
+		return entry.Logs, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	units, err := Units(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range units {
+		if u.ID == executionID {
+			return executionLogsFromService(ctx, job.Name, executionID, executionLogLines())
+		}
+	}
+	return nil, ErrExecutionNotFound
+}
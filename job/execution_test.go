@@ -0,0 +1,122 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	"gopkg.in/check.v1"
+)
+
+type executionLogService struct {
+	appTypes.MockAppLogService
+	logs []appTypes.Applog
+}
+
+func (s *executionLogService) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	return s.logs, nil
+}
+
+func (s *S) TestRecordAndListExecutions(c *check.C) {
+	oldLogService := servicemanager.LogService
+	defer func() { servicemanager.LogService = oldLogService }()
+	servicemanager.LogService = &executionLogService{logs: []appTypes.Applog{
+		{Message: "migrating", Unit: "myjob-1-abcde", Name: "myjob"},
+		{Message: "boom", Unit: "myjob-2-fghij", Name: "myjob"},
+	}}
+	j := jobTypes.Job{Name: "myjob", Pool: s.Pool, TeamOwner: s.team.Name}
+	err := s.provisioner.EnsureJob(context.TODO(), &j)
+	c.Assert(err, check.IsNil)
+	start := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	exitCode := int32(1)
+	err = RecordExecution(context.TODO(), jobTypes.Execution{
+		ID:        "myjob-1",
+		Job:       "myjob",
+		Status:    jobTypes.ExecutionSucceeded,
+		Image:     "registry/myjob:v1",
+		Node:      "node1",
+		StartTime: start,
+		EndTime:   start.Add(time.Minute),
+	})
+	c.Assert(err, check.IsNil)
+	err = RecordExecution(context.TODO(), jobTypes.Execution{
+		ID:        "myjob-2",
+		Job:       "myjob",
+		Status:    jobTypes.ExecutionFailed,
+		Reason:    "BackoffLimitExceeded",
+		ExitCode:  &exitCode,
+		StartTime: start.Add(time.Hour),
+	})
+	c.Assert(err, check.IsNil)
+	executions, err := ListExecutions(context.TODO(), &j, 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(executions, check.HasLen, 2)
+	c.Assert(executions[0].ID, check.Equals, "myjob-2")
+	c.Assert(executions[0].Status, check.Equals, jobTypes.ExecutionFailed)
+	c.Assert(*executions[0].ExitCode, check.Equals, int32(1))
+	c.Assert(executions[0].EndTime.IsZero(), check.Equals, false)
+	c.Assert(executions[1].ID, check.Equals, "myjob-1")
+	c.Assert(executions[1].Node, check.Equals, "node1")
+	c.Assert(executions[1].Image, check.Equals, "registry/myjob:v1")
+	servicemanager.LogService = oldLogService
+	logs, err := ExecutionLogs(context.TODO(), &j, "myjob-1")
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, "migrating")
+	_, err = ExecutionLogs(context.TODO(), &j, "myjob-3")
+	c.Assert(err, check.Equals, ErrExecutionNotFound)
+}
+
+func (s *S) TestRecordFailedExecutionNotifiesJob(c *check.C) {
+	oldLogService := servicemanager.LogService
+	defer func() { servicemanager.LogService = oldLogService }()
+	var logs []appTypes.Applog
+	for i := 0; i < 30; i++ {
+		logs = append(logs, appTypes.Applog{Message: fmt.Sprintf("line %d", i), Unit: "myjob-1-abcde", Name: "myjob"})
+	}
+	servicemanager.LogService = &executionLogService{logs: logs}
+	j := jobTypes.Job{
+		Name:          "myjob",
+		TeamOwner:     s.team.Name,
+		Pool:          s.Pool,
+		Notifications: jobTypes.Notifications{Emails: []string{"ops@example.com"}},
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, s.user)
+	c.Assert(err, check.IsNil)
+	err = RecordExecution(context.TODO(), jobTypes.Execution{
+		ID:     "myjob-1",
+		Job:    "myjob",
+		Status: jobTypes.ExecutionFailed,
+		Reason: "BackoffLimitExceeded",
+	})
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "myjob"},
+		KindNames: []string{ExecutionFailedEventKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, "execution myjob-1 failed: BackoffLimitExceeded")
+	var failure executionFailure
+	err = evts[0].StartData(&failure)
+	c.Assert(err, check.IsNil)
+	c.Assert(failure.Execution.ID, check.Equals, "myjob-1")
+	c.Assert(failure.Logs, check.HasLen, defaultNotificationLines)
+	c.Assert(failure.Logs[0].Message, check.Equals, "line 10")
+}
@@ -7,6 +7,7 @@ package job
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"reflect"
 	"sort"
 
@@ -29,6 +30,7 @@ import (
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	"github.com/tsuru/tsuru/validation"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -145,6 +147,13 @@ func getRegistry(ctx context.Context, job *jobTypes.Job) (imgTypes.ImageRegistry
 	if err != nil {
 		return "", err
 	}
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, job.TeamOwner)
+	if err != nil {
+		return "", err
+	}
+	if profile != nil && profile.Registry != "" {
+		return imgTypes.ImageRegistry(profile.Registry), nil
+	}
 	registryProv, ok := prov.(provision.MultiRegistryProvisioner)
 	if !ok {
 		return "", nil
@@ -242,6 +251,9 @@ func (*jobService) CreateJob(ctx context.Context, job *jobTypes.Job, user *authT
 	if err := validateJob(ctx, job); err != nil {
 		return err
 	}
+	if err := checkJobsQuota(ctx, job, nil); err != nil {
+		return err
+	}
 
 	if err := ensureDeployOptions(job); err != nil {
 		return err
@@ -337,6 +349,9 @@ func (*jobService) UpdateJob(ctx context.Context, newJob, oldJob *jobTypes.Job,
 	if err := validateJob(ctx, newJob); err != nil {
 		return err
 	}
+	if err := checkJobsQuota(ctx, newJob, oldJob); err != nil {
+		return err
+	}
 
 	actions := []*action.Action{
 		&jobUpdateDB,
@@ -410,6 +425,9 @@ func (*jobService) UpdateJobProv(ctx context.Context, job *jobTypes.Job) error {
 
 // Trigger triggers an execution of either job or cronjob object
 func (*jobService) Trigger(ctx context.Context, job *jobTypes.Job) error {
+	if err := checkExecutionsQuota(ctx, job); err != nil {
+		return err
+	}
 	return action.NewPipeline([]*action.Action{&triggerCron}...).Execute(ctx, job)
 }
 
@@ -606,7 +624,15 @@ func validatePool(ctx context.Context, job *jobTypes.Job) error {
 	if err != nil {
 		return err
 	}
-	return validateTeamOwner(ctx, job, p)
+	err = validateTeamOwner(ctx, job, p)
+	if err != nil {
+		return err
+	}
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, job.TeamOwner)
+	if err != nil || profile == nil {
+		return err
+	}
+	return p.ValidateSchedulingConstraints(ctx, profile.SchedulingConstraints)
 }
 
 func validatePlan(ctx context.Context, poolName, planName string) error {
@@ -664,5 +690,21 @@ func validateJob(ctx context.Context, j *jobTypes.Job) error {
 			return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidConcurrencyPolicy.Error()}
 		}
 	}
+	return validateNotifications(j.Notifications)
+}
+
+func validateNotifications(notifications jobTypes.Notifications) error {
+	for _, email := range notifications.Emails {
+		if !validation.ValidateEmail(email) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid notification email %q", email)}
+		}
+	}
+	if notifications.WebhookURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(notifications.WebhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid notification url %q", notifications.WebhookURL)}
+	}
 	return nil
 }
@@ -699,6 +699,26 @@ func (s *S) TestUpdateJob(c *check.C) {
 			},
 			expectedErr: &tsuruErrors.ValidationError{Message: "invalid schedule"},
 		},
+		{
+			name: "update job with invalid notification url",
+			oldJob: jobTypes.Job{
+				Name:      "some-job",
+				TeamOwner: s.team.Name,
+				Pool:      s.Pool,
+				Spec: jobTypes.JobSpec{
+					Schedule: "* * * * *",
+					Container: jobTypes.ContainerInfo{
+						OriginalImageSrc: "alpine:latest",
+						Command:          []string{"echo", "hello!"},
+					},
+				},
+			},
+			newJob: jobTypes.Job{
+				Name:          "some-job",
+				Notifications: jobTypes.Notifications{WebhookURL: "ftp://hooks.example.com"},
+			},
+			expectedErr: &tsuruErrors.ValidationError{Message: "invalid notification url \"ftp://hooks.example.com\""},
+		},
 		{
 			name: "update job should use deploy agent with container info on both newJob and oldJob",
 			oldJob: jobTypes.Job{
@@ -0,0 +1,178 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const jobQuotasCollectionName = "job_quotas"
+
+type quotaEntry struct {
+	Scope          jobTypes.QuotaScope
+	Name           string
+	jobTypes.Quota `bson:",inline"`
+}
+
+func validQuotaScope(scope jobTypes.QuotaScope) bool {
+	return scope == jobTypes.QuotaScopeTeam || scope == jobTypes.QuotaScopePool
+}
+
+// GetQuota returns the job quota of the team or pool, unlimited when none was
+// set.
+func GetQuota(ctx context.Context, scope jobTypes.QuotaScope, name string) (*jobTypes.Quota, error) {
+	collection, err := storagev2.Collection(jobQuotasCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	var entry quotaEntry
+	err = collection.FindOne(ctx, mongoBSON.M{"scope": scope, "name": name}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		q := jobTypes.UnlimitedQuota
+		return &q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry.Quota, nil
+}
+
+// SetQuota replaces the job quota of the team or pool. Limits lower than the
+// current usage are accepted, blocking new jobs and executions until the
+// usage goes down.
+func SetQuota(ctx context.Context, scope jobTypes.QuotaScope, name string, quota jobTypes.Quota) error {
+	if !validQuotaScope(scope) {
+		return &tsuruErrors.ValidationError{Message: "invalid job quota scope " + string(scope)}
+	}
+	if quota.MaxJobs < 0 {
+		quota.MaxJobs = -1
+	}
+	if quota.MaxConcurrentExecutions < 0 {
+		quota.MaxConcurrentExecutions = -1
+	}
+	collection, err := storagev2.Collection(jobQuotasCollectionName)
+	if err != nil {
+		return err
+	}
+	entry := quotaEntry{Scope: scope, Name: name, Quota: quota}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"scope": scope, "name": name}, entry, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetQuotaUsage returns the job quota of the team or pool along with the
+// number of jobs and running executions counted against it.
+func GetQuotaUsage(ctx context.Context, scope jobTypes.QuotaScope, name string) (*jobTypes.QuotaUsage, error) {
+	quota, err := GetQuota(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := jobsInQuotaScope(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	running, err := runningExecutions(ctx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	return &jobTypes.QuotaUsage{Quota: *quota, Jobs: len(jobs), RunningExecutions: running}, nil
+}
+
+func jobsInQuotaScope(ctx context.Context, scope jobTypes.QuotaScope, name string) ([]jobTypes.Job, error) {
+	filter := &jobTypes.Filter{TeamOwner: name}
+	if scope == jobTypes.QuotaScopePool {
+		filter = &jobTypes.Filter{Pool: name}
+	}
+	return (&jobService{}).List(ctx, filter)
+}
+
+func runningExecutions(ctx context.Context, jobs []jobTypes.Job) (int, error) {
+	var running int
+	for i := range jobs {
+		units, err := Units(ctx, &jobs[i])
+		if err != nil {
+			return 0, err
+		}
+		for _, u := range units {
+			if u.Status == provTypes.UnitStatusStarted {
+				running++
+			}
+		}
+	}
+	return running, nil
+}
+
+type quotaOwner struct {
+	scope jobTypes.QuotaScope
+	name  string
+}
+
+// quotaOwners returns the team and pool whose quotas the job is counted
+// against. When oldJob is given, only the ones the job is moving to are
+// returned.
+func quotaOwners(job, oldJob *jobTypes.Job) []quotaOwner {
+	var owners []quotaOwner
+	if oldJob == nil || oldJob.TeamOwner != job.TeamOwner {
+		owners = append(owners, quotaOwner{scope: jobTypes.QuotaScopeTeam, name: job.TeamOwner})
+	}
+	if oldJob == nil || oldJob.Pool != job.Pool {
+		owners = append(owners, quotaOwner{scope: jobTypes.QuotaScopePool, name: job.Pool})
+	}
+	return owners
+}
+
+// checkJobsQuota ensures the team and pool of the job may hold one more job.
+func checkJobsQuota(ctx context.Context, job, oldJob *jobTypes.Job) error {
+	for _, owner := range quotaOwners(job, oldJob) {
+		quota, err := GetQuota(ctx, owner.scope, owner.name)
+		if err != nil {
+			return err
+		}
+		if quota.MaxJobs < 0 {
+			continue
+		}
+		jobs, err := jobsInQuotaScope(ctx, owner.scope, owner.name)
+		if err != nil {
+			return err
+		}
+		if len(jobs) >= quota.MaxJobs {
+			return &jobTypes.QuotaExceededError{Scope: owner.scope, Name: owner.name, Resource: "jobs", Limit: quota.MaxJobs}
+		}
+	}
+	return nil
+}
+
+// checkExecutionsQuota ensures the team and pool of the job may run one more
+// execution concurrently.
+func checkExecutionsQuota(ctx context.Context, job *jobTypes.Job) error {
+	for _, owner := range quotaOwners(job, nil) {
+		quota, err := GetQuota(ctx, owner.scope, owner.name)
+		if err != nil {
+			return err
+		}
+		if quota.MaxConcurrentExecutions < 0 {
+			continue
+		}
+		jobs, err := jobsInQuotaScope(ctx, owner.scope, owner.name)
+		if err != nil {
+			return err
+		}
+		running, err := runningExecutions(ctx, jobs)
+		if err != nil {
+			return err
+		}
+		if running >= quota.MaxConcurrentExecutions {
+			return &jobTypes.QuotaExceededError{Scope: owner.scope, Name: owner.name, Resource: "concurrent executions", Limit: quota.MaxConcurrentExecutions}
+		}
+	}
+	return nil
+}
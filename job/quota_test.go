@@ -0,0 +1,69 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) newQuotaTestJob(name string) jobTypes.Job {
+	return jobTypes.Job{
+		Name:      name,
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+}
+
+func (s *S) TestGetQuotaDefaultsToUnlimited(c *check.C) {
+	quota, err := GetQuota(context.TODO(), jobTypes.QuotaScopeTeam, s.team.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(*quota, check.DeepEquals, jobTypes.UnlimitedQuota)
+	err = SetQuota(context.TODO(), jobTypes.QuotaScopeTeam, s.team.Name, jobTypes.Quota{MaxJobs: 2, MaxConcurrentExecutions: -5})
+	c.Assert(err, check.IsNil)
+	quota, err = GetQuota(context.TODO(), jobTypes.QuotaScopeTeam, s.team.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(*quota, check.DeepEquals, jobTypes.Quota{MaxJobs: 2, MaxConcurrentExecutions: -1})
+}
+
+func (s *S) TestCreateJobTeamQuotaExceeded(c *check.C) {
+	err := SetQuota(context.TODO(), jobTypes.QuotaScopeTeam, s.team.Name, jobTypes.Quota{MaxJobs: 1, MaxConcurrentExecutions: -1})
+	c.Assert(err, check.IsNil)
+	j1 := s.newQuotaTestJob("job1")
+	err = servicemanager.Job.CreateJob(context.TODO(), &j1, s.user)
+	c.Assert(err, check.IsNil)
+	j2 := s.newQuotaTestJob("job2")
+	err = servicemanager.Job.CreateJob(context.TODO(), &j2, s.user)
+	c.Assert(err, check.DeepEquals, &jobTypes.QuotaExceededError{
+		Scope:    jobTypes.QuotaScopeTeam,
+		Name:     s.team.Name,
+		Resource: "jobs",
+		Limit:    1,
+	})
+	usage, err := GetQuotaUsage(context.TODO(), jobTypes.QuotaScopePool, s.Pool)
+	c.Assert(err, check.IsNil)
+	c.Assert(*usage, check.DeepEquals, jobTypes.QuotaUsage{Quota: jobTypes.UnlimitedQuota, Jobs: 1})
+}
+
+func (s *S) TestTriggerPoolExecutionsQuotaExceeded(c *check.C) {
+	j1 := s.newQuotaTestJob("job1")
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetQuota(context.TODO(), jobTypes.QuotaScopePool, s.Pool, jobTypes.Quota{MaxJobs: -1, MaxConcurrentExecutions: 0})
+	c.Assert(err, check.IsNil)
+	err = servicemanager.Job.Trigger(context.TODO(), &j1)
+	c.Assert(err, check.ErrorMatches, `job quota of pool ".*" exceeded: at most 0 concurrent executions allowed`)
+	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"fmt"
+)
+
+type requestIDCtxKey struct{}
+
+// contextLogger is implemented by loggers able to record the request ID of
+// the context as a field of its own. Other loggers have it prepended to the
+// message.
+type contextLogger interface {
+	ErrorContext(ctx context.Context, message string)
+	DebugContext(ctx context.Context, message string)
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the API
+// request being handled, which is added to the messages logged with it.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID of the API request set in the context,
+// or an empty string if there's none.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return requestID
+}
+
+func withRequestIDPrefix(ctx context.Context, message string) string {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return fmt.Sprintf("[Request-ID: %s] %s", requestID, message)
+	}
+	return message
+}
+
+func errorContext(l Logger, ctx context.Context, message string) {
+	if cl, ok := l.(contextLogger); ok {
+		cl.ErrorContext(ctx, message)
+		return
+	}
+	l.Error(withRequestIDPrefix(ctx, message))
+}
+
+func debugContext(l Logger, ctx context.Context, message string) {
+	if cl, ok := l.(contextLogger); ok {
+		cl.DebugContext(ctx, message)
+		return
+	}
+	l.Debug(withRequestIDPrefix(ctx, message))
+}
@@ -18,11 +18,15 @@ var (
 )
 
 func NewFileLogger(fileName string, debug bool) Logger {
+	return NewWriterLogger(openLogFile(fileName), debug)
+}
+
+func openLogFile(fileName string) io.Writer {
 	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
 	if err != nil {
 		panic(err)
 	}
-	return NewWriterLogger(file, debug)
+	return file
 }
 
 func NewWriterLogger(writer io.Writer, debug bool) Logger {
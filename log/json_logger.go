@@ -0,0 +1,91 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+)
+
+const levelFatal = slog.Level(12)
+
+var (
+	_ Logger        = &jsonLogger{}
+	_ contextLogger = &jsonLogger{}
+)
+
+// NewJSONLogger returns a logger writing each message as a JSON object, with
+// the time, the level and the request ID, when logged with a context
+// carrying one.
+func NewJSONLogger(writer io.Writer, debug bool) Logger {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	handler := slog.NewJSONHandler(writer, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == levelFatal {
+				return slog.String(slog.LevelKey, "FATAL")
+			}
+			return a
+		},
+	})
+	return &jsonLogger{handler: handler, logger: slog.New(handler)}
+}
+
+type jsonLogger struct {
+	handler slog.Handler
+	logger  *slog.Logger
+}
+
+func (l *jsonLogger) log(ctx context.Context, level slog.Level, message string) {
+	var attrs []slog.Attr
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		attrs = append(attrs, slog.String("requestID", requestID))
+	}
+	l.logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+func (l *jsonLogger) Error(o string) {
+	l.log(context.Background(), slog.LevelError, o)
+}
+
+func (l *jsonLogger) Errorf(format string, o ...interface{}) {
+	l.Error(fmt.Sprintf(format, o...))
+}
+
+func (l *jsonLogger) ErrorContext(ctx context.Context, o string) {
+	l.log(ctx, slog.LevelError, o)
+}
+
+func (l *jsonLogger) Fatal(o string) {
+	l.log(context.Background(), levelFatal, o)
+	os.Exit(1)
+}
+
+func (l *jsonLogger) Fatalf(format string, o ...interface{}) {
+	l.Fatal(fmt.Sprintf(format, o...))
+}
+
+func (l *jsonLogger) Debug(o string) {
+	l.log(context.Background(), slog.LevelDebug, o)
+}
+
+func (l *jsonLogger) Debugf(format string, o ...interface{}) {
+	l.Debug(fmt.Sprintf(format, o...))
+}
+
+func (l *jsonLogger) DebugContext(ctx context.Context, o string) {
+	l.log(ctx, slog.LevelDebug, o)
+}
+
+func (l *jsonLogger) GetStdLogger() *log.Logger {
+	return slog.NewLogLogger(l.handler, slog.LevelInfo)
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestJSONLogger(c *check.C) {
+	var buf bytes.Buffer
+	l := NewJSONLogger(&buf, false)
+	l.Errorf("something %s", "failed")
+	l.Debug("ignored without debug")
+	var line map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &line)
+	c.Assert(err, check.IsNil)
+	c.Assert(line["level"], check.Equals, "ERROR")
+	c.Assert(line["msg"], check.Equals, "something failed")
+	c.Assert(line["time"], check.NotNil)
+	c.Assert(line["requestID"], check.IsNil)
+}
+
+func (s *S) TestJSONLoggerWithRequestID(c *check.C) {
+	var buf bytes.Buffer
+	SetLogger(NewMultiLogger(NewJSONLogger(&buf, true)))
+	defer SetLogger(nil)
+	ctx := ContextWithRequestID(context.Background(), "my-request")
+	DebugfContext(ctx, "log %d", 1)
+	var line map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &line)
+	c.Assert(err, check.IsNil)
+	c.Assert(line["level"], check.Equals, "DEBUG")
+	c.Assert(line["msg"], check.Equals, "log 1")
+	c.Assert(line["requestID"], check.Equals, "my-request")
+}
+
+func (s *S) TestLogErrorfContext(c *check.C) {
+	buf := newFakeLogger()
+	defer buf.Reset()
+	ctx := ContextWithRequestID(context.Background(), "my-request")
+	ErrorfContext(ctx, "log anything %d", 1)
+	c.Assert(buf.String(), check.Equals, "ERROR: [Request-ID: my-request] log anything 1\n")
+	buf.Reset()
+	ErrorfContext(context.Background(), "log anything %d", 2)
+	c.Assert(buf.String(), check.Equals, "ERROR: log anything 2\n")
+}
+
+func (s *S) TestInitWithInvalidFormat(c *check.C) {
+	config.Set("log:format", "xml")
+	defer config.Unset("log:format")
+	err := Init()
+	c.Assert(err, check.ErrorMatches, `invalid log format "xml", must be "text" or "json"`)
+}
@@ -10,6 +10,7 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -33,8 +34,16 @@ type Logger interface {
 func Init() error {
 	var loggers []Logger
 	debug, _ := config.GetBool("debug")
+	newWriterLogger := NewWriterLogger
+	switch format, _ := config.GetString("log:format"); format {
+	case "", "text":
+	case "json":
+		newWriterLogger = NewJSONLogger
+	default:
+		return errors.Errorf("invalid log format %q, must be \"text\" or \"json\"", format)
+	}
 	if logFileName, err := config.GetString("log:file"); err == nil {
-		loggers = append(loggers, NewFileLogger(logFileName, debug))
+		loggers = append(loggers, newWriterLogger(openLogFile(logFileName), debug))
 	} else if err == config.ErrMismatchConf {
 		panic(fmt.Sprintf("%s please see http://docs.tsuru.io/en/latest/reference/config.html#log-file", err))
 	}
@@ -50,7 +59,7 @@ func Init() error {
 		loggers = append(loggers, syslogLogger)
 	}
 	if useStderr, _ := config.GetBool("log:use-stderr"); useStderr {
-		loggers = append(loggers, NewWriterLogger(os.Stderr, debug))
+		loggers = append(loggers, newWriterLogger(os.Stderr, debug))
 	}
 	SetLogger(NewMultiLogger(loggers...))
 	return nil
@@ -100,6 +109,16 @@ func (t *Target) Errorf(format string, v ...interface{}) {
 	}
 }
 
+// ErrorfContext writes the formatted string to the Target logger, along
+// with the request ID set in the context.
+func (t *Target) ErrorfContext(ctx context.Context, format string, v ...interface{}) {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	if t.logger != nil {
+		errorContext(t.logger, ctx, fmt.Sprintf(format, v...))
+	}
+}
+
 // Fatal writes the given values to the Target
 // logger.
 func (t *Target) Fatal(v string) {
@@ -140,6 +159,16 @@ func (t *Target) Debugf(format string, v ...interface{}) {
 	}
 }
 
+// DebugfContext writes the formatted string to the Target logger, along
+// with the request ID set in the context.
+func (t *Target) DebugfContext(ctx context.Context, format string, v ...interface{}) {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	if t.logger != nil {
+		debugContext(t.logger, ctx, fmt.Sprintf(format, v...))
+	}
+}
+
 // GetStdLogger returns a standard Logger instance
 // useful for configuring log in external packages.
 func (t *Target) GetStdLogger() *log.Logger {
@@ -163,6 +192,11 @@ func Errorf(format string, v ...interface{}) {
 	DefaultTarget.Errorf(format, v...)
 }
 
+// ErrorfContext is a wrapper for DefaultTarget.ErrorfContext.
+func ErrorfContext(ctx context.Context, format string, v ...interface{}) {
+	DefaultTarget.ErrorfContext(ctx, format, v...)
+}
+
 // Fatal is a wrapper for DefaultTarget.Fatal.
 func Fatal(v string) {
 	DefaultTarget.Fatal(v)
@@ -183,6 +217,11 @@ func Debugf(format string, v ...interface{}) {
 	DefaultTarget.Debugf(format, v...)
 }
 
+// DebugfContext is a wrapper for DefaultTarget.DebugfContext.
+func DebugfContext(ctx context.Context, format string, v ...interface{}) {
+	DefaultTarget.DebugfContext(ctx, format, v...)
+}
+
 // GetStdLogger is a wrapper for DefaultTarget.GetStdLogger.
 func GetStdLogger() *log.Logger {
 	return DefaultTarget.GetStdLogger()
@@ -5,10 +5,13 @@
 package log
 
 import (
+	"context"
 	"log"
 	"os"
 )
 
+var _ contextLogger = &multiLogger{}
+
 func NewMultiLogger(loggers ...Logger) Logger {
 	return &multiLogger{loggers: loggers}
 }
@@ -29,6 +32,18 @@ func (m *multiLogger) Error(message string) {
 	}
 }
 
+func (m *multiLogger) DebugContext(ctx context.Context, message string) {
+	for _, logger := range m.loggers {
+		debugContext(logger, ctx, message)
+	}
+}
+
+func (m *multiLogger) ErrorContext(ctx context.Context, message string) {
+	for _, logger := range m.loggers {
+		errorContext(logger, ctx, message)
+	}
+}
+
 func (m *multiLogger) Fatal(message string) {
 	for _, logger := range m.loggers {
 		logger.Error(message)
@@ -12,76 +12,120 @@ var (
 	PermApikey                           = PermissionRegistry.get("apikey")                              // [global user]
 	PermApikeyRead                       = PermissionRegistry.get("apikey.read")                         // [global user]
 	PermApikeyUpdate                     = PermissionRegistry.get("apikey.update")                       // [global user]
-	PermApp                              = PermissionRegistry.get("app")                                 // [global app team pool]
-	PermAppAdmin                         = PermissionRegistry.get("app.admin")                           // [global app team pool]
-	PermAppAdminQuota                    = PermissionRegistry.get("app.admin.quota")                     // [global app team pool]
-	PermAppAdminRoutes                   = PermissionRegistry.get("app.admin.routes")                    // [global app team pool]
-	PermAppBuild                         = PermissionRegistry.get("app.build")                           // [global app team pool]
+	PermApp                              = PermissionRegistry.get("app")                                 // [global app team pool tag]
+	PermAppAdmin                         = PermissionRegistry.get("app.admin")                           // [global app team pool tag]
+	PermAppAdminApproveDeploy            = PermissionRegistry.get("app.admin.approve-deploy")            // [global app team pool tag]
+	PermAppAdminDeletionProtection       = PermissionRegistry.get("app.admin.deletion-protection")       // [global app team pool tag]
+	PermAppAdminEgress                   = PermissionRegistry.get("app.admin.egress")                    // [global app team pool tag]
+	PermAppAdminQuota                    = PermissionRegistry.get("app.admin.quota")                     // [global app team pool tag]
+	PermAppAdminRequireApproval          = PermissionRegistry.get("app.admin.require-approval")          // [global app team pool tag]
+	PermAppAdminRoutes                   = PermissionRegistry.get("app.admin.routes")                    // [global app team pool tag]
+	PermAppAdminShellRecording           = PermissionRegistry.get("app.admin.shell-recording")           // [global app team pool tag]
+	PermAppBuild                         = PermissionRegistry.get("app.build")                           // [global app team pool tag]
 	PermAppCreate                        = PermissionRegistry.get("app.create")                          // [global team]
-	PermAppDelete                        = PermissionRegistry.get("app.delete")                          // [global app team pool]
-	PermAppDeploy                        = PermissionRegistry.get("app.deploy")                          // [global app team pool]
-	PermAppDeployArchiveUrl              = PermissionRegistry.get("app.deploy.archive-url")              // [global app team pool]
-	PermAppDeployBuild                   = PermissionRegistry.get("app.deploy.build")                    // [global app team pool]
-	PermAppDeployDockerfile              = PermissionRegistry.get("app.deploy.dockerfile")               // [global app team pool]
-	PermAppDeployGit                     = PermissionRegistry.get("app.deploy.git")                      // [global app team pool]
-	PermAppDeployImage                   = PermissionRegistry.get("app.deploy.image")                    // [global app team pool]
-	PermAppDeployRollback                = PermissionRegistry.get("app.deploy.rollback")                 // [global app team pool]
-	PermAppDeployUpload                  = PermissionRegistry.get("app.deploy.upload")                   // [global app team pool]
-	PermAppRead                          = PermissionRegistry.get("app.read")                            // [global app team pool]
-	PermAppReadCertificate               = PermissionRegistry.get("app.read.certificate")                // [global app team pool]
-	PermAppReadDeploy                    = PermissionRegistry.get("app.read.deploy")                     // [global app team pool]
-	PermAppReadEnv                       = PermissionRegistry.get("app.read.env")                        // [global app team pool]
-	PermAppReadEvents                    = PermissionRegistry.get("app.read.events")                     // [global app team pool]
-	PermAppReadInfo                      = PermissionRegistry.get("app.read.info")                       // [global app team pool]
-	PermAppReadLog                       = PermissionRegistry.get("app.read.log")                        // [global app team pool]
-	PermAppReadRouter                    = PermissionRegistry.get("app.read.router")                     // [global app team pool]
-	PermAppRun                           = PermissionRegistry.get("app.run")                             // [global app team pool]
-	PermAppRunShell                      = PermissionRegistry.get("app.run.shell")                       // [global app team pool]
-	PermAppUpdate                        = PermissionRegistry.get("app.update")                          // [global app team pool]
-	PermAppUpdateBind                    = PermissionRegistry.get("app.update.bind")                     // [global app team pool]
-	PermAppUpdateBindVolume              = PermissionRegistry.get("app.update.bind-volume")              // [global app team pool]
-	PermAppUpdateCertificate             = PermissionRegistry.get("app.update.certificate")              // [global app team pool]
-	PermAppUpdateCertificateSet          = PermissionRegistry.get("app.update.certificate.set")          // [global app team pool]
-	PermAppUpdateCertificateUnset        = PermissionRegistry.get("app.update.certificate.unset")        // [global app team pool]
-	PermAppUpdateCname                   = PermissionRegistry.get("app.update.cname")                    // [global app team pool]
-	PermAppUpdateCnameAdd                = PermissionRegistry.get("app.update.cname.add")                // [global app team pool]
-	PermAppUpdateCnameRemove             = PermissionRegistry.get("app.update.cname.remove")             // [global app team pool]
-	PermAppUpdateDeploy                  = PermissionRegistry.get("app.update.deploy")                   // [global app team pool]
-	PermAppUpdateDeployRollback          = PermissionRegistry.get("app.update.deploy.rollback")          // [global app team pool]
-	PermAppUpdateDescription             = PermissionRegistry.get("app.update.description")              // [global app team pool]
-	PermAppUpdateEnv                     = PermissionRegistry.get("app.update.env")                      // [global app team pool]
-	PermAppUpdateEnvSet                  = PermissionRegistry.get("app.update.env.set")                  // [global app team pool]
-	PermAppUpdateEnvUnset                = PermissionRegistry.get("app.update.env.unset")                // [global app team pool]
-	PermAppUpdateEvents                  = PermissionRegistry.get("app.update.events")                   // [global app team pool]
-	PermAppUpdateGrant                   = PermissionRegistry.get("app.update.grant")                    // [global app team pool]
-	PermAppUpdateImageReset              = PermissionRegistry.get("app.update.image-reset")              // [global app team pool]
-	PermAppUpdateLog                     = PermissionRegistry.get("app.update.log")                      // [global app team pool]
-	PermAppUpdateMetadata                = PermissionRegistry.get("app.update.metadata")                 // [global app team pool]
-	PermAppUpdatePlan                    = PermissionRegistry.get("app.update.plan")                     // [global app team pool]
-	PermAppUpdatePlanoverride            = PermissionRegistry.get("app.update.planoverride")             // [global app team pool]
-	PermAppUpdatePlatform                = PermissionRegistry.get("app.update.platform")                 // [global app team pool]
-	PermAppUpdatePool                    = PermissionRegistry.get("app.update.pool")                     // [global app team pool]
-	PermAppUpdateProcesses               = PermissionRegistry.get("app.update.processes")                // [global app team pool]
-	PermAppUpdateRestart                 = PermissionRegistry.get("app.update.restart")                  // [global app team pool]
-	PermAppUpdateRevoke                  = PermissionRegistry.get("app.update.revoke")                   // [global app team pool]
-	PermAppUpdateRoutable                = PermissionRegistry.get("app.update.routable")                 // [global app team pool]
-	PermAppUpdateRouter                  = PermissionRegistry.get("app.update.router")                   // [global app team pool]
-	PermAppUpdateRouterAdd               = PermissionRegistry.get("app.update.router.add")               // [global app team pool]
-	PermAppUpdateRouterRemove            = PermissionRegistry.get("app.update.router.remove")            // [global app team pool]
-	PermAppUpdateRouterUpdate            = PermissionRegistry.get("app.update.router.update")            // [global app team pool]
-	PermAppUpdateStart                   = PermissionRegistry.get("app.update.start")                    // [global app team pool]
-	PermAppUpdateStop                    = PermissionRegistry.get("app.update.stop")                     // [global app team pool]
-	PermAppUpdateTags                    = PermissionRegistry.get("app.update.tags")                     // [global app team pool]
-	PermAppUpdateTeamowner               = PermissionRegistry.get("app.update.teamowner")                // [global app team pool]
-	PermAppUpdateUnbind                  = PermissionRegistry.get("app.update.unbind")                   // [global app team pool]
-	PermAppUpdateUnbindVolume            = PermissionRegistry.get("app.update.unbind-volume")            // [global app team pool]
-	PermAppUpdateUnit                    = PermissionRegistry.get("app.update.unit")                     // [global app team pool]
-	PermAppUpdateUnitAdd                 = PermissionRegistry.get("app.update.unit.add")                 // [global app team pool]
-	PermAppUpdateUnitAutoscale           = PermissionRegistry.get("app.update.unit.autoscale")           // [global app team pool]
-	PermAppUpdateUnitAutoscaleAdd        = PermissionRegistry.get("app.update.unit.autoscale.add")       // [global app team pool]
-	PermAppUpdateUnitAutoscaleRemove     = PermissionRegistry.get("app.update.unit.autoscale.remove")    // [global app team pool]
-	PermAppUpdateUnitKill                = PermissionRegistry.get("app.update.unit.kill")                // [global app team pool]
-	PermAppUpdateUnitRemove              = PermissionRegistry.get("app.update.unit.remove")              // [global app team pool]
+	PermAppDelete                        = PermissionRegistry.get("app.delete")                          // [global app team pool tag]
+	PermAppDeploy                        = PermissionRegistry.get("app.deploy")                          // [global app team pool tag]
+	PermAppDeployAbort                   = PermissionRegistry.get("app.deploy.abort")                    // [global app team pool tag]
+	PermAppDeployArchiveUrl              = PermissionRegistry.get("app.deploy.archive-url")              // [global app team pool tag]
+	PermAppDeployBuild                   = PermissionRegistry.get("app.deploy.build")                    // [global app team pool tag]
+	PermAppDeployConfirm                 = PermissionRegistry.get("app.deploy.confirm")                  // [global app team pool tag]
+	PermAppDeployDockerfile              = PermissionRegistry.get("app.deploy.dockerfile")               // [global app team pool tag]
+	PermAppDeployGit                     = PermissionRegistry.get("app.deploy.git")                      // [global app team pool tag]
+	PermAppDeployGitUrl                  = PermissionRegistry.get("app.deploy.git-url")                  // [global app team pool tag]
+	PermAppDeployImage                   = PermissionRegistry.get("app.deploy.image")                    // [global app team pool tag]
+	PermAppDeployPause                   = PermissionRegistry.get("app.deploy.pause")                    // [global app team pool tag]
+	PermAppDeployResume                  = PermissionRegistry.get("app.deploy.resume")                   // [global app team pool tag]
+	PermAppDeployRollback                = PermissionRegistry.get("app.deploy.rollback")                 // [global app team pool tag]
+	PermAppDeployUpload                  = PermissionRegistry.get("app.deploy.upload")                   // [global app team pool tag]
+	PermAppRead                          = PermissionRegistry.get("app.read")                            // [global app team pool tag]
+	PermAppReadCertificate               = PermissionRegistry.get("app.read.certificate")                // [global app team pool tag]
+	PermAppReadConfig                    = PermissionRegistry.get("app.read.config")                     // [global app team pool tag]
+	PermAppReadDeploy                    = PermissionRegistry.get("app.read.deploy")                     // [global app team pool tag]
+	PermAppReadEnv                       = PermissionRegistry.get("app.read.env")                        // [global app team pool tag]
+	PermAppReadEvents                    = PermissionRegistry.get("app.read.events")                     // [global app team pool tag]
+	PermAppReadFiles                     = PermissionRegistry.get("app.read.files")                      // [global app team pool tag]
+	PermAppReadInfo                      = PermissionRegistry.get("app.read.info")                       // [global app team pool tag]
+	PermAppReadLog                       = PermissionRegistry.get("app.read.log")                        // [global app team pool tag]
+	PermAppReadRouter                    = PermissionRegistry.get("app.read.router")                     // [global app team pool tag]
+	PermAppReadSecretValues              = PermissionRegistry.get("app.read.secret-values")              // [global app team pool tag]
+	PermAppReadSecrets                   = PermissionRegistry.get("app.read.secrets")                    // [global app team pool tag]
+	PermAppRun                           = PermissionRegistry.get("app.run")                             // [global app team pool tag]
+	PermAppRunPortforward                = PermissionRegistry.get("app.run.portforward")                 // [global app team pool tag]
+	PermAppRunShell                      = PermissionRegistry.get("app.run.shell")                       // [global app team pool tag]
+	PermAppRunTask                       = PermissionRegistry.get("app.run.task")                        // [global app team pool tag]
+	PermAppUpdate                        = PermissionRegistry.get("app.update")                          // [global app team pool tag]
+	PermAppUpdateArchive                 = PermissionRegistry.get("app.update.archive")                  // [global app team pool tag]
+	PermAppUpdateAutoredeploy            = PermissionRegistry.get("app.update.autoredeploy")             // [global app team pool tag]
+	PermAppUpdateBind                    = PermissionRegistry.get("app.update.bind")                     // [global app team pool tag]
+	PermAppUpdateBindVolume              = PermissionRegistry.get("app.update.bind-volume")              // [global app team pool tag]
+	PermAppUpdateBudget                  = PermissionRegistry.get("app.update.budget")                   // [global app team pool tag]
+	PermAppUpdateCertificate             = PermissionRegistry.get("app.update.certificate")              // [global app team pool tag]
+	PermAppUpdateCertificateSet          = PermissionRegistry.get("app.update.certificate.set")          // [global app team pool tag]
+	PermAppUpdateCertificateUnset        = PermissionRegistry.get("app.update.certificate.unset")        // [global app team pool tag]
+	PermAppUpdateCname                   = PermissionRegistry.get("app.update.cname")                    // [global app team pool tag]
+	PermAppUpdateCnameAdd                = PermissionRegistry.get("app.update.cname.add")                // [global app team pool tag]
+	PermAppUpdateCnameRemove             = PermissionRegistry.get("app.update.cname.remove")             // [global app team pool tag]
+	PermAppUpdateConfig                  = PermissionRegistry.get("app.update.config")                   // [global app team pool tag]
+	PermAppUpdateConfigRestore           = PermissionRegistry.get("app.update.config.restore")           // [global app team pool tag]
+	PermAppUpdateDeletionProtection      = PermissionRegistry.get("app.update.deletion-protection")      // [global app team pool tag]
+	PermAppUpdateDeploy                  = PermissionRegistry.get("app.update.deploy")                   // [global app team pool tag]
+	PermAppUpdateDeployRollback          = PermissionRegistry.get("app.update.deploy.rollback")          // [global app team pool tag]
+	PermAppUpdateDescription             = PermissionRegistry.get("app.update.description")              // [global app team pool tag]
+	PermAppUpdateEnv                     = PermissionRegistry.get("app.update.env")                      // [global app team pool tag]
+	PermAppUpdateEnvPromote              = PermissionRegistry.get("app.update.env.promote")              // [global app team pool tag]
+	PermAppUpdateEnvSchema               = PermissionRegistry.get("app.update.env.schema")               // [global app team pool tag]
+	PermAppUpdateEnvSet                  = PermissionRegistry.get("app.update.env.set")                  // [global app team pool tag]
+	PermAppUpdateEnvUnset                = PermissionRegistry.get("app.update.env.unset")                // [global app team pool tag]
+	PermAppUpdateEvents                  = PermissionRegistry.get("app.update.events")                   // [global app team pool tag]
+	PermAppUpdateFiles                   = PermissionRegistry.get("app.update.files")                    // [global app team pool tag]
+	PermAppUpdateFilesSet                = PermissionRegistry.get("app.update.files.set")                // [global app team pool tag]
+	PermAppUpdateFilesUnset              = PermissionRegistry.get("app.update.files.unset")              // [global app team pool tag]
+	PermAppUpdateGrant                   = PermissionRegistry.get("app.update.grant")                    // [global app team pool tag]
+	PermAppUpdateImageReset              = PermissionRegistry.get("app.update.image-reset")              // [global app team pool tag]
+	PermAppUpdateInitContainers          = PermissionRegistry.get("app.update.init-containers")          // [global app team pool tag]
+	PermAppUpdateLog                     = PermissionRegistry.get("app.update.log")                      // [global app team pool tag]
+	PermAppUpdateLogSinks                = PermissionRegistry.get("app.update.log-sinks")                // [global app team pool tag]
+	PermAppUpdateLogSinksSet             = PermissionRegistry.get("app.update.log-sinks.set")            // [global app team pool tag]
+	PermAppUpdateLogSinksUnset           = PermissionRegistry.get("app.update.log-sinks.unset")          // [global app team pool tag]
+	PermAppUpdateMaintenance             = PermissionRegistry.get("app.update.maintenance")              // [global app team pool tag]
+	PermAppUpdateMaintenanceDisable      = PermissionRegistry.get("app.update.maintenance.disable")      // [global app team pool tag]
+	PermAppUpdateMaintenanceEnable       = PermissionRegistry.get("app.update.maintenance.enable")       // [global app team pool tag]
+	PermAppUpdateMetadata                = PermissionRegistry.get("app.update.metadata")                 // [global app team pool tag]
+	PermAppUpdatePlan                    = PermissionRegistry.get("app.update.plan")                     // [global app team pool tag]
+	PermAppUpdatePlanoverride            = PermissionRegistry.get("app.update.planoverride")             // [global app team pool tag]
+	PermAppUpdatePlatform                = PermissionRegistry.get("app.update.platform")                 // [global app team pool tag]
+	PermAppUpdatePool                    = PermissionRegistry.get("app.update.pool")                     // [global app team pool tag]
+	PermAppUpdateProcesses               = PermissionRegistry.get("app.update.processes")                // [global app team pool tag]
+	PermAppUpdateRequireApproval         = PermissionRegistry.get("app.update.require-approval")         // [global app team pool tag]
+	PermAppUpdateRestart                 = PermissionRegistry.get("app.update.restart")                  // [global app team pool tag]
+	PermAppUpdateRevoke                  = PermissionRegistry.get("app.update.revoke")                   // [global app team pool tag]
+	PermAppUpdateRoutable                = PermissionRegistry.get("app.update.routable")                 // [global app team pool tag]
+	PermAppUpdateRouter                  = PermissionRegistry.get("app.update.router")                   // [global app team pool tag]
+	PermAppUpdateRouterAdd               = PermissionRegistry.get("app.update.router.add")               // [global app team pool tag]
+	PermAppUpdateRouterRemove            = PermissionRegistry.get("app.update.router.remove")            // [global app team pool tag]
+	PermAppUpdateRouterUpdate            = PermissionRegistry.get("app.update.router.update")            // [global app team pool tag]
+	PermAppUpdateSecrets                 = PermissionRegistry.get("app.update.secrets")                  // [global app team pool tag]
+	PermAppUpdateSecretsSet              = PermissionRegistry.get("app.update.secrets.set")              // [global app team pool tag]
+	PermAppUpdateSecretsUnset            = PermissionRegistry.get("app.update.secrets.unset")            // [global app team pool tag]
+	PermAppUpdateSecurityContext         = PermissionRegistry.get("app.update.security-context")         // [global app team pool tag]
+	PermAppUpdateServiceAccount          = PermissionRegistry.get("app.update.service-account")          // [global app team pool tag]
+	PermAppUpdateSpread                  = PermissionRegistry.get("app.update.spread")                   // [global app team pool tag]
+	PermAppUpdateStart                   = PermissionRegistry.get("app.update.start")                    // [global app team pool tag]
+	PermAppUpdateStop                    = PermissionRegistry.get("app.update.stop")                     // [global app team pool tag]
+	PermAppUpdateTags                    = PermissionRegistry.get("app.update.tags")                     // [global app team pool tag]
+	PermAppUpdateTeamowner               = PermissionRegistry.get("app.update.teamowner")                // [global app team pool tag]
+	PermAppUpdateUnarchive               = PermissionRegistry.get("app.update.unarchive")                // [global app team pool tag]
+	PermAppUpdateUnbind                  = PermissionRegistry.get("app.update.unbind")                   // [global app team pool tag]
+	PermAppUpdateUnbindVolume            = PermissionRegistry.get("app.update.unbind-volume")            // [global app team pool tag]
+	PermAppUpdateUnit                    = PermissionRegistry.get("app.update.unit")                     // [global app team pool tag]
+	PermAppUpdateUnitAdd                 = PermissionRegistry.get("app.update.unit.add")                 // [global app team pool tag]
+	PermAppUpdateUnitAutoscale           = PermissionRegistry.get("app.update.unit.autoscale")           // [global app team pool tag]
+	PermAppUpdateUnitAutoscaleAdd        = PermissionRegistry.get("app.update.unit.autoscale.add")       // [global app team pool tag]
+	PermAppUpdateUnitAutoscaleRemove     = PermissionRegistry.get("app.update.unit.autoscale.remove")    // [global app team pool tag]
+	PermAppUpdateUnitCapture             = PermissionRegistry.get("app.update.unit.capture")             // [global app team pool tag]
+	PermAppUpdateUnitKill                = PermissionRegistry.get("app.update.unit.kill")                // [global app team pool tag]
+	PermAppUpdateUnitRebalance           = PermissionRegistry.get("app.update.unit.rebalance")           // [global app team pool tag]
+	PermAppUpdateUnitRemove              = PermissionRegistry.get("app.update.unit.remove")              // [global app team pool tag]
 	PermCertissuer                       = PermissionRegistry.get("certissuer")                          // [global app team pool]
 	PermCertissuerSet                    = PermissionRegistry.get("certissuer.set")                      // [global app team pool]
 	PermCertissuerUnset                  = PermissionRegistry.get("certissuer.unset")                    // [global app team pool]
@@ -91,13 +135,20 @@ var (
 	PermClusterDelete                    = PermissionRegistry.get("cluster.delete")                      // [global]
 	PermClusterRead                      = PermissionRegistry.get("cluster.read")                        // [global]
 	PermClusterReadEvents                = PermissionRegistry.get("cluster.read.events")                 // [global]
+	PermClusterReadOrphans               = PermissionRegistry.get("cluster.read.orphans")                // [global]
+	PermClusterReadRegistryUsage         = PermissionRegistry.get("cluster.read.registry-usage")         // [global]
 	PermClusterUpdate                    = PermissionRegistry.get("cluster.update")                      // [global]
+	PermClusterUpdateLabels              = PermissionRegistry.get("cluster.update.labels")               // [global]
+	PermClusterUpdateNodes               = PermissionRegistry.get("cluster.update.nodes")                // [global]
 	PermDebug                            = PermissionRegistry.get("debug")                               // [global]
+	PermEvent                            = PermissionRegistry.get("event")                               // [global app team pool job tag]
 	PermEventBlock                       = PermissionRegistry.get("event-block")                         // [global]
 	PermEventBlockAdd                    = PermissionRegistry.get("event-block.add")                     // [global]
 	PermEventBlockRead                   = PermissionRegistry.get("event-block.read")                    // [global]
 	PermEventBlockReadEvents             = PermissionRegistry.get("event-block.read.events")             // [global]
 	PermEventBlockRemove                 = PermissionRegistry.get("event-block.remove")                  // [global]
+	PermEventRead                        = PermissionRegistry.get("event.read")                          // [global app team pool job tag]
+	PermEventReadSensitive               = PermissionRegistry.get("event.read.sensitive")                // [global app team pool job tag]
 	PermJob                              = PermissionRegistry.get("job")                                 // [global team pool job]
 	PermJobCreate                        = PermissionRegistry.get("job.create")                          // [global team]
 	PermJobDelete                        = PermissionRegistry.get("job.delete")                          // [global team pool job]
@@ -116,6 +167,8 @@ var (
 	PermPlanDelete                       = PermissionRegistry.get("plan.delete")                         // [global]
 	PermPlanRead                         = PermissionRegistry.get("plan.read")                           // [global]
 	PermPlanReadEvents                   = PermissionRegistry.get("plan.read.events")                    // [global]
+	PermPlanUpdate                       = PermissionRegistry.get("plan.update")                         // [global]
+	PermPlanUpdateDefault                = PermissionRegistry.get("plan.update.default")                 // [global]
 	PermPlatform                         = PermissionRegistry.get("platform")                            // [global]
 	PermPlatformCreate                   = PermissionRegistry.get("platform.create")                     // [global]
 	PermPlatformDelete                   = PermissionRegistry.get("platform.delete")                     // [global]
@@ -128,10 +181,17 @@ var (
 	PermPoolDelete                       = PermissionRegistry.get("pool.delete")                         // [global pool]
 	PermPoolRead                         = PermissionRegistry.get("pool.read")                           // [global pool]
 	PermPoolReadConstraints              = PermissionRegistry.get("pool.read.constraints")               // [global pool]
+	PermPoolReadDrift                    = PermissionRegistry.get("pool.read.drift")                     // [global pool]
 	PermPoolReadEvents                   = PermissionRegistry.get("pool.read.events")                    // [global pool]
+	PermPoolReadPolicies                 = PermissionRegistry.get("pool.read.policies")                  // [global pool]
+	PermPoolReadQuota                    = PermissionRegistry.get("pool.read.quota")                     // [global pool]
 	PermPoolUpdate                       = PermissionRegistry.get("pool.update")                         // [global pool]
 	PermPoolUpdateConstraints            = PermissionRegistry.get("pool.update.constraints")             // [global pool]
 	PermPoolUpdateConstraintsSet         = PermissionRegistry.get("pool.update.constraints.set")         // [global pool]
+	PermPoolUpdateLabels                 = PermissionRegistry.get("pool.update.labels")                  // [global pool]
+	PermPoolUpdatePolicies               = PermissionRegistry.get("pool.update.policies")                // [global pool]
+	PermPoolUpdateQuota                  = PermissionRegistry.get("pool.update.quota")                   // [global pool]
+	PermPoolUpdateRebalance              = PermissionRegistry.get("pool.update.rebalance")               // [global pool]
 	PermPoolUpdateTeam                   = PermissionRegistry.get("pool.update.team")                    // [global pool]
 	PermPoolUpdateTeamAdd                = PermissionRegistry.get("pool.update.team.add")                // [global pool]
 	PermPoolUpdateTeamRemove             = PermissionRegistry.get("pool.update.team.remove")             // [global pool]
@@ -198,21 +258,36 @@ var (
 	PermTeamCreate                       = PermissionRegistry.get("team.create")                         // [global]
 	PermTeamDelete                       = PermissionRegistry.get("team.delete")                         // [global team]
 	PermTeamRead                         = PermissionRegistry.get("team.read")                           // [global team]
+	PermTeamReadBudget                   = PermissionRegistry.get("team.read.budget")                    // [global team]
+	PermTeamReadEnv                      = PermissionRegistry.get("team.read.env")                       // [global team]
 	PermTeamReadEvents                   = PermissionRegistry.get("team.read.events")                    // [global team]
+	PermTeamReadNotifications            = PermissionRegistry.get("team.read.notifications")             // [global team]
 	PermTeamReadQuota                    = PermissionRegistry.get("team.read.quota")                     // [global team]
+	PermTeamReadUsage                    = PermissionRegistry.get("team.read.usage")                     // [global team]
 	PermTeamToken                        = PermissionRegistry.get("team.token")                          // [global team]
 	PermTeamTokenCreate                  = PermissionRegistry.get("team.token.create")                   // [global team]
 	PermTeamTokenDelete                  = PermissionRegistry.get("team.token.delete")                   // [global team]
 	PermTeamTokenRead                    = PermissionRegistry.get("team.token.read")                     // [global team]
 	PermTeamTokenUpdate                  = PermissionRegistry.get("team.token.update")                   // [global team]
+	PermTeamTokenUpdateRotate            = PermissionRegistry.get("team.token.update.rotate")            // [global team]
 	PermTeamUpdate                       = PermissionRegistry.get("team.update")                         // [global team]
+	PermTeamUpdateBudget                 = PermissionRegistry.get("team.update.budget")                  // [global team]
+	PermTeamUpdateEnv                    = PermissionRegistry.get("team.update.env")                     // [global team]
+	PermTeamUpdateIsolationProfile       = PermissionRegistry.get("team.update.isolation-profile")       // [global]
+	PermTeamUpdateNotifications          = PermissionRegistry.get("team.update.notifications")           // [global team]
 	PermTeamUpdateQuota                  = PermissionRegistry.get("team.update.quota")                   // [global team]
+	PermTeamUpdateQuotaApprove           = PermissionRegistry.get("team.update.quota.approve")           // [global team]
+	PermTeamUpdateQuotaRequest           = PermissionRegistry.get("team.update.quota.request")           // [global team]
 	PermUser                             = PermissionRegistry.get("user")                                // [global user]
 	PermUserCreate                       = PermissionRegistry.get("user.create")                         // [global]
 	PermUserDelete                       = PermissionRegistry.get("user.delete")                         // [global user]
 	PermUserRead                         = PermissionRegistry.get("user.read")                           // [global user]
 	PermUserReadEvents                   = PermissionRegistry.get("user.read.events")                    // [global user]
 	PermUserReadQuota                    = PermissionRegistry.get("user.read.quota")                     // [global user]
+	PermUserToken                        = PermissionRegistry.get("user.token")                          // [global user]
+	PermUserTokenCreate                  = PermissionRegistry.get("user.token.create")                   // [global user]
+	PermUserTokenDelete                  = PermissionRegistry.get("user.token.delete")                   // [global user]
+	PermUserTokenRead                    = PermissionRegistry.get("user.token.read")                     // [global user]
 	PermUserUpdate                       = PermissionRegistry.get("user.update")                         // [global user]
 	PermUserUpdatePassword               = PermissionRegistry.get("user.update.password")                // [global user]
 	PermUserUpdateQuota                  = PermissionRegistry.get("user.update.quota")                   // [global user]
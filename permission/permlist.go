@@ -11,7 +11,7 @@ import (
 //go:generate bash -c "rm -f permitems.go && go run ./generator/main.go -o permitems.go"
 
 var PermissionRegistry = (&registry{}).addWithCtx(
-	"app", []permTypes.ContextType{permTypes.CtxApp, permTypes.CtxTeam, permTypes.CtxPool},
+	"app", []permTypes.ContextType{permTypes.CtxApp, permTypes.CtxTeam, permTypes.CtxPool, permTypes.CtxTag},
 ).addWithCtx(
 	"app.create", []permTypes.ContextType{permTypes.CtxTeam},
 ).add(
@@ -22,9 +22,13 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.unit.add",
 	"app.update.unit.remove",
 	"app.update.unit.kill",
+	"app.update.unit.capture",
+	"app.update.unit.rebalance",
 	"app.update.unit.autoscale.add",
 	"app.update.unit.autoscale.remove",
 	"app.update.env.set",
+	"app.update.env.promote",
+	"app.update.env.schema",
 	"app.update.env.unset",
 	"app.update.restart",
 	"app.update.start",
@@ -47,16 +51,40 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.certificate.set",
 	"app.update.certificate.unset",
 	"app.update.deploy.rollback",
+	"app.update.config.restore",
 	"app.update.router.add",
 	"app.update.router.update",
 	"app.update.router.remove",
 	"app.update.routable",
 	"app.update.metadata",
+	"app.update.deletion-protection",
+	"app.update.budget",
+	"app.update.autoredeploy",
+	"app.update.require-approval",
+	"app.update.spread",
+	"app.update.security-context",
+	"app.update.service-account",
+	"app.update.init-containers",
+	"app.update.files.set",
+	"app.update.files.unset",
+	"app.update.secrets.set",
+	"app.update.secrets.unset",
+	"app.update.archive",
+	"app.update.unarchive",
+	"app.update.maintenance.enable",
+	"app.update.maintenance.disable",
+	"app.update.log-sinks.set",
+	"app.update.log-sinks.unset",
 	"app.deploy",
+	"app.deploy.abort",
 	"app.deploy.archive-url",
 	"app.deploy.build",
+	"app.deploy.confirm",
 	"app.deploy.git",
+	"app.deploy.git-url",
 	"app.deploy.image",
+	"app.deploy.pause",
+	"app.deploy.resume",
 	"app.deploy.rollback",
 	"app.deploy.upload",
 	"app.deploy.dockerfile",
@@ -64,15 +92,26 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.read.deploy",
 	"app.read.router",
 	"app.read.env",
+	"app.read.config",
 	"app.read.events",
 	"app.read.log",
 	"app.read.certificate",
 	"app.read.info",
+	"app.read.files",
+	"app.read.secrets",
+	"app.read.secret-values",
 	"app.delete",
 	"app.run",
 	"app.run.shell",
+	"app.run.portforward",
+	"app.run.task",
 	"app.admin.routes",
 	"app.admin.quota",
+	"app.admin.deletion-protection",
+	"app.admin.require-approval",
+	"app.admin.approve-deploy",
+	"app.admin.shell-recording",
+	"app.admin.egress",
 	"app.build",
 ).addWithCtx(
 	"certissuer", []permTypes.ContextType{permTypes.CtxApp, permTypes.CtxTeam, permTypes.CtxPool},
@@ -91,8 +130,20 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"team.token.create",
 	"team.token.delete",
 	"team.token.update",
+	"team.token.update.rotate",
 	"team.read.quota",
 	"team.update.quota",
+	"team.update.quota.approve",
+	"team.update.quota.request",
+	"team.read.env",
+	"team.update.env",
+	"team.read.notifications",
+	"team.update.notifications",
+	"team.read.budget",
+	"team.update.budget",
+	"team.read.usage",
+).addWithCtx(
+	"team.update.isolation-profile", []permTypes.ContextType{},
 ).addWithCtx(
 	"user", []permTypes.ContextType{permTypes.CtxUser},
 ).addWithCtx(
@@ -104,6 +155,9 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"user.update.quota",
 	"user.update.password",
 	"user.update.reset",
+	"user.token.create",
+	"user.token.read",
+	"user.token.delete",
 ).addWithCtx(
 	"apikey", []permTypes.ContextType{permTypes.CtxUser},
 ).add(
@@ -167,6 +221,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"plan.create",
 	"plan.delete",
 	"plan.read.events",
+	"plan.update.default",
 ).addWithCtx(
 	"pool", []permTypes.ContextType{permTypes.CtxPool},
 ).addWithCtx(
@@ -176,7 +231,14 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"pool.update.team.add",
 	"pool.update.team.remove",
 	"pool.update.constraints.set",
+	"pool.update.labels",
 	"pool.read.constraints",
+	"pool.read.drift",
+	"pool.read.policies",
+	"pool.update.policies",
+	"pool.update.rebalance",
+	"pool.read.quota",
+	"pool.update.quota",
 	"pool.delete",
 ).add(
 	"debug",
@@ -185,11 +247,19 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"event-block.read.events",
 	"event-block.add",
 	"event-block.remove",
+).addWithCtx(
+	"event", []permTypes.ContextType{permTypes.CtxApp, permTypes.CtxTeam, permTypes.CtxPool, permTypes.CtxJob, permTypes.CtxTag},
+).add(
+	"event.read.sensitive",
 ).add(
 	"cluster.admin",
 	"cluster.read.events",
+	"cluster.read.orphans",
+	"cluster.read.registry-usage",
 	"cluster.create",
 	"cluster.update",
+	"cluster.update.labels",
+	"cluster.update.nodes",
 	"cluster.delete",
 ).addWithCtx(
 	"volume", []permTypes.ContextType{permTypes.CtxVolume, permTypes.CtxTeam, permTypes.CtxPool},
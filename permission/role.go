@@ -347,5 +347,5 @@ func (r *Role) Add(ctx context.Context) error {
 	if mongo.IsDuplicateKeyError(err) {
 		return permTypes.ErrRoleAlreadyExists
 	}
-	return nil
+	return err
 }
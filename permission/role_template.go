@@ -0,0 +1,156 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package permission
+
+import (
+	"context"
+	"strings"
+
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// RoleTemplate is a built-in set of permissions commonly granted to team
+// members, instantiated as regular roles in the team context.
+type RoleTemplate struct {
+	Name        string                `json:"name"`
+	ContextType permTypes.ContextType `json:"context"`
+	Description string
+	SchemeNames []string `json:"scheme_names"`
+}
+
+var roleTemplates = []RoleTemplate{
+	{
+		Name:        "developer",
+		ContextType: permTypes.CtxTeam,
+		Description: "create, deploy and manage the apps, jobs, service instances and volumes of the team",
+		SchemeNames: []string{
+			"app.create",
+			"app.read",
+			"app.update",
+			"app.deploy",
+			"app.build",
+			"app.run",
+			"app.delete",
+			"job",
+			"service-instance",
+			"volume",
+			"team.read",
+			"team.read.events",
+			"webhook.read",
+		},
+	},
+	{
+		Name:        "operator",
+		ContextType: permTypes.CtxTeam,
+		Description: "operate the running apps and jobs of the team, without deploying or changing their settings",
+		SchemeNames: []string{
+			"app.read",
+			"app.update.restart",
+			"app.update.start",
+			"app.update.stop",
+			"app.update.unit",
+			"app.update.events",
+			"app.deploy.rollback",
+			"app.deploy.abort",
+			"app.run",
+			"job.read",
+			"job.run",
+			"job.trigger",
+			"job.unit",
+			"job.update.events",
+			"service-instance.read",
+			"volume.read",
+			"team.read",
+			"team.read.events",
+		},
+	},
+	{
+		Name:        "auditor",
+		ContextType: permTypes.CtxTeam,
+		Description: "read-only access to the resources and events of the team",
+		SchemeNames: []string{
+			"app.read",
+			"job.read",
+			"service.read",
+			"service-instance.read",
+			"volume.read",
+			"team.read",
+			"team.read.events",
+			"webhook.read",
+			"webhook.read.events",
+		},
+	},
+}
+
+func ListRoleTemplates() []RoleTemplate {
+	templates := make([]RoleTemplate, len(roleTemplates))
+	for i, tpl := range roleTemplates {
+		tpl.SchemeNames = append([]string(nil), tpl.SchemeNames...)
+		templates[i] = tpl
+	}
+	return templates
+}
+
+func FindRoleTemplate(name string) (RoleTemplate, error) {
+	for _, tpl := range ListRoleTemplates() {
+		if tpl.Name == name {
+			return tpl, nil
+		}
+	}
+	return RoleTemplate{}, permTypes.ErrRoleTemplateNotFound
+}
+
+// NewRoleFromTemplate creates a role holding the permissions of the template
+// named templateName. The role is named after the template when name is
+// empty.
+func NewRoleFromTemplate(ctx context.Context, templateName, name, description string) (Role, error) {
+	tpl, err := FindRoleTemplate(templateName)
+	if err != nil {
+		return Role{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = tpl.Name
+	}
+	if description == "" {
+		description = tpl.Description
+	}
+	role := Role{
+		Name:        name,
+		ContextType: tpl.ContextType,
+		Description: description,
+		SchemeNames: tpl.SchemeNames,
+	}
+	err = role.Add(ctx)
+	if err != nil {
+		return Role{}, err
+	}
+	return role, nil
+}
+
+// Clone creates a role named name with the context type and permissions of r,
+// keeping its description when description is empty. Role events are not
+// copied, as they make the role assigned by default to new users or teams,
+// which requires the role.default.create permission.
+func (r *Role) Clone(ctx context.Context, name, description string) (Role, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Role{}, permTypes.ErrInvalidRoleName
+	}
+	if description == "" {
+		description = r.Description
+	}
+	role := Role{
+		Name:        name,
+		ContextType: r.ContextType,
+		Description: description,
+		SchemeNames: append([]string(nil), r.SchemeNames...),
+	}
+	err := role.Add(ctx)
+	if err != nil {
+		return Role{}, err
+	}
+	return role, nil
+}
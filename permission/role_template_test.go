@@ -0,0 +1,91 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package permission
+
+import (
+	"context"
+	"sort"
+
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRoleTemplatesAreValid(c *check.C) {
+	templates := ListRoleTemplates()
+	c.Assert(templates, check.HasLen, 3)
+	for _, tpl := range templates {
+		c.Assert(tpl.SchemeNames, check.Not(check.HasLen), 0)
+		for _, name := range tpl.SchemeNames {
+			scheme, err := SafeGet(name)
+			c.Assert(err, check.IsNil, check.Commentf("template %q", tpl.Name))
+			var allowed bool
+			for _, ctxType := range scheme.AllowedContexts() {
+				allowed = allowed || ctxType == tpl.ContextType
+			}
+			c.Assert(allowed, check.Equals, true, check.Commentf("template %q, permission %q", tpl.Name, name))
+		}
+	}
+}
+
+func (s *S) TestFindRoleTemplate(c *check.C) {
+	tpl, err := FindRoleTemplate("auditor")
+	c.Assert(err, check.IsNil)
+	c.Assert(tpl.ContextType, check.Equals, permTypes.CtxTeam)
+	tpl.SchemeNames[0] = "changed"
+	tpl, err = FindRoleTemplate("auditor")
+	c.Assert(err, check.IsNil)
+	c.Assert(tpl.SchemeNames[0], check.Not(check.Equals), "changed")
+	_, err = FindRoleTemplate("unknown")
+	c.Assert(err, check.Equals, permTypes.ErrRoleTemplateNotFound)
+}
+
+func (s *S) TestNewRoleFromTemplate(c *check.C) {
+	ctx := context.TODO()
+	tpl, err := FindRoleTemplate("developer")
+	c.Assert(err, check.IsNil)
+	role, err := NewRoleFromTemplate(ctx, "developer", "", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.Name, check.Equals, "developer")
+	dbRole, err := FindRole(ctx, "developer")
+	c.Assert(err, check.IsNil)
+	sort.Strings(tpl.SchemeNames)
+	c.Assert(dbRole.ContextType, check.Equals, permTypes.CtxTeam)
+	c.Assert(dbRole.Description, check.Equals, tpl.Description)
+	c.Assert(dbRole.SchemeNames, check.DeepEquals, tpl.SchemeNames)
+	role, err = NewRoleFromTemplate(ctx, "developer", "backend-dev", "backend developers")
+	c.Assert(err, check.IsNil)
+	dbRole, err = FindRole(ctx, "backend-dev")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRole.Description, check.Equals, "backend developers")
+	c.Assert(dbRole.SchemeNames, check.DeepEquals, tpl.SchemeNames)
+	_, err = NewRoleFromTemplate(ctx, "developer", "backend-dev", "")
+	c.Assert(err, check.Equals, permTypes.ErrRoleAlreadyExists)
+	_, err = NewRoleFromTemplate(ctx, "unknown", "", "")
+	c.Assert(err, check.Equals, permTypes.ErrRoleTemplateNotFound)
+}
+
+func (s *S) TestRoleClone(c *check.C) {
+	ctx := context.TODO()
+	r, err := NewRole(ctx, "myrole", "team", "my role")
+	c.Assert(err, check.IsNil)
+	err = r.AddPermissions(ctx, "app.update", "app.deploy")
+	c.Assert(err, check.IsNil)
+	err = r.AddEvent(ctx, permTypes.RoleEventTeamCreate.String())
+	c.Assert(err, check.IsNil)
+	_, err = r.Clone(ctx, " myclone ", "")
+	c.Assert(err, check.IsNil)
+	clone, err := FindRole(ctx, "myclone")
+	c.Assert(err, check.IsNil)
+	c.Assert(clone, check.DeepEquals, Role{
+		Name:        "myclone",
+		ContextType: permTypes.CtxTeam,
+		Description: "my role",
+		SchemeNames: []string{"app.deploy", "app.update"},
+	})
+	_, err = r.Clone(ctx, "myclone", "")
+	c.Assert(err, check.Equals, permTypes.ErrRoleAlreadyExists)
+	_, err = r.Clone(ctx, "  ", "")
+	c.Assert(err, check.Equals, permTypes.ErrInvalidRoleName)
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const defaultOPATimeout = 5 * time.Second
+
+// errUndefinedDeny is returned when the engine has no deny set for a policy,
+// either because its module is not loaded or because it doesn't define one.
+var errUndefinedDeny = errors.New("deny is undefined, the module is not loaded in the engine or does not define a deny set")
+
+// opaEngine manages the policy modules and evaluates them through the REST
+// API of an Open Policy Agent server.
+type opaEngine struct {
+	url     string
+	token   string
+	timeout time.Duration
+}
+
+type opaError struct {
+	Message string `json:"message"`
+	Errors  []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func defaultEngine() (*opaEngine, error) {
+	url, _ := config.GetString("admission-policies:opa:url")
+	if url == "" {
+		return nil, ErrEngineNotConfigured
+	}
+	token, _ := config.GetString("admission-policies:opa:token")
+	timeout, _ := config.GetDuration("admission-policies:opa:timeout")
+	if timeout <= 0 {
+		timeout = defaultOPATimeout
+	}
+	return &opaEngine{url: strings.TrimRight(url, "/"), token: token, timeout: timeout}, nil
+}
+
+func modulePath(name string) string {
+	return "/v1/policies/tsuru-policies-" + name
+}
+
+func (e *opaEngine) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+	return tsuruNet.Dial15Full60ClientNoKeepAlive.Do(req)
+}
+
+// put loads the module of the policy, the server compiles it and rejects
+// modules with errors.
+func (e *opaEngine) put(ctx context.Context, p *Policy) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	rsp, err := e.do(ctx, http.MethodPut, modulePath(p.Name), "text/plain", strings.NewReader(p.Module))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusBadRequest {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid module: %s", opaErrorMessage(rsp.Body))}
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d loading module: %s", rsp.StatusCode, opaErrorMessage(rsp.Body))
+	}
+	return nil
+}
+
+func (e *opaEngine) remove(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	rsp, err := e.do(ctx, http.MethodDelete, modulePath(name), "", nil)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusNotFound {
+		return errors.Errorf("unexpected status code %d removing module: %s", rsp.StatusCode, opaErrorMessage(rsp.Body))
+	}
+	return nil
+}
+
+// deny returns the messages in the deny set of the policy for the input. An
+// undefined deny set is reported as errUndefinedDeny and never allows the
+// operation, as OPA answers the same way for modules it lost.
+func (e *opaEngine) deny(ctx context.Context, name string, input Input) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+	path := "/v1/data/" + strings.ReplaceAll(packagePrefix, ".", "/") + name + "/deny"
+	rsp, err := e.do(ctx, http.MethodPost, path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d evaluating policy: %s", rsp.StatusCode, opaErrorMessage(rsp.Body))
+	}
+	var result struct {
+		Result *[]interface{} `json:"result"`
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "unable to decode policy result, deny must be a set")
+	}
+	if result.Result == nil {
+		return nil, errUndefinedDeny
+	}
+	msgs := make([]string, 0, len(*result.Result))
+	for _, r := range *result.Result {
+		if msg, ok := r.(string); ok {
+			msgs = append(msgs, msg)
+			continue
+		}
+		msgs = append(msgs, fmt.Sprint(r))
+	}
+	return msgs, nil
+}
+
+func opaErrorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(body)
+	var opaErr opaError
+	if err := json.Unmarshal(data, &opaErr); err != nil || opaErr.Message == "" {
+		return string(data)
+	}
+	msgs := []string{opaErr.Message}
+	for _, e := range opaErr.Errors {
+		msgs = append(msgs, e.Message)
+	}
+	return strings.Join(msgs, ": ")
+}
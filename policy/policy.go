@@ -0,0 +1,304 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package policy implements admission policies, Rego modules evaluated by an
+// Open Policy Agent server before mutating operations on apps.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	OperationAppCreate     = "app.create"
+	OperationAppDeploy     = "app.deploy"
+	OperationAppEnvSet     = "app.env.set"
+	OperationAppPlanUpdate = "app.plan.update"
+
+	// packagePrefix is the Rego package every policy module must declare,
+	// followed by the name of the policy.
+	packagePrefix = "tsuru.policies."
+)
+
+var (
+	Operations = []string{
+		OperationAppCreate,
+		OperationAppDeploy,
+		OperationAppEnvSet,
+		OperationAppPlanUpdate,
+	}
+
+	ErrPolicyNotFound      = errors.New("admission policy not found")
+	ErrEngineNotConfigured = errors.New("admission policies engine is not configured, set admission-policies:opa:url")
+	ErrInvalidPolicyName   = &tsuruErrors.ValidationError{Message: "invalid admission policy name, it must start with a lowercase letter and contain only lowercase letters, numbers and underscores"}
+	ErrOperationsRequired  = &tsuruErrors.ValidationError{Message: "admission policy must apply to at least one operation"}
+
+	policyNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+	packageRegexp    = regexp.MustCompile(`(?m)^\s*package\s+(\S+)`)
+)
+
+// Policy is a Rego module evaluated before the listed operations on apps of
+// the pool, or of every pool when Pool is empty. The module denies an
+// operation by adding messages to its deny set.
+type Policy struct {
+	Name       string   `json:"name"`
+	Pool       string   `json:"pool,omitempty"`
+	Operations []string `json:"operations"`
+	Module     string   `json:"module"`
+}
+
+// Input is the document evaluated by the policies, available as input in
+// Rego. Data holds the arguments of the operation.
+type Input struct {
+	Operation string                 `json:"operation"`
+	Pool      string                 `json:"pool"`
+	User      string                 `json:"user"`
+	App       App                    `json:"app"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// App is the state of the app the operation is applied to. Units counts the
+// units of each process.
+type App struct {
+	Name      string            `json:"name"`
+	TeamOwner string            `json:"teamOwner"`
+	Platform  string            `json:"platform"`
+	Plan      string            `json:"plan"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	Units     map[string]int    `json:"units"`
+}
+
+// NewApp returns the state of the app evaluated by the policies, counting
+// the units of each process.
+func NewApp(a *appTypes.App, units []provTypes.Unit) App {
+	result := App{
+		Name:      a.Name,
+		TeamOwner: a.TeamOwner,
+		Platform:  a.Platform,
+		Plan:      a.Plan.Name,
+		Tags:      a.Tags,
+		Labels:    map[string]string{},
+		Units:     map[string]int{},
+	}
+	for _, label := range a.Metadata.Labels {
+		result.Labels[label.Name] = label.Value
+	}
+	for _, u := range units {
+		result.Units[u.ProcessName]++
+	}
+	return result
+}
+
+// DeniedError is returned when policies deny an operation.
+type DeniedError struct {
+	Operation  string
+	Violations map[string][]string
+}
+
+func (e *DeniedError) Error() string {
+	names := make([]string, 0, len(e.Violations))
+	for name := range e.Violations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var msgs []string
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", name, strings.Join(e.Violations[name], "; ")))
+	}
+	return fmt.Sprintf("%s denied by admission policies: %s", e.Operation, strings.Join(msgs, ", "))
+}
+
+func failOpen() bool {
+	open, _ := config.GetBool("admission-policies:fail-open")
+	return open
+}
+
+func validate(p *Policy) error {
+	if !policyNameRegexp.MatchString(p.Name) {
+		return ErrInvalidPolicyName
+	}
+	if len(p.Operations) == 0 {
+		return ErrOperationsRequired
+	}
+	for _, op := range p.Operations {
+		if !validOperation(op) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid operation %q, valid operations are: %s", op, strings.Join(Operations, ", "))}
+		}
+	}
+	expected := packagePrefix + p.Name
+	matches := packageRegexp.FindStringSubmatch(p.Module)
+	if len(matches) < 2 || matches[1] != expected {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("module must declare package %s", expected)}
+	}
+	return nil
+}
+
+func validOperation(op string) bool {
+	for _, valid := range Operations {
+		if op == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Save validates the policy, loads its module in the engine and stores it,
+// replacing any policy with the same name.
+func Save(ctx context.Context, p *Policy) error {
+	if err := validate(p); err != nil {
+		return err
+	}
+	engine, err := defaultEngine()
+	if err != nil {
+		return err
+	}
+	if err = engine.put(ctx, p); err != nil {
+		return err
+	}
+	collection, err := storagev2.AdmissionPoliciesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"name": p.Name}, p, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Get returns the policy with the given name.
+func Get(ctx context.Context, name string) (*Policy, error) {
+	collection, err := storagev2.AdmissionPoliciesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	err = collection.FindOne(ctx, mongoBSON.M{"name": name}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns the policies applied to the pool, including the global ones,
+// or every policy when pool is empty.
+func List(ctx context.Context, pool string) ([]Policy, error) {
+	query := mongoBSON.M{}
+	if pool != "" {
+		query["pool"] = mongoBSON.M{"$in": []string{"", pool}}
+	}
+	return find(ctx, query)
+}
+
+func find(ctx context.Context, query mongoBSON.M) ([]Policy, error) {
+	collection, err := storagev2.AdmissionPoliciesCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(mongoBSON.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	policies := []Policy{}
+	if err = cursor.All(ctx, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Remove removes the policy from the engine and from the storage.
+func Remove(ctx context.Context, name string) error {
+	if _, err := Get(ctx, name); err != nil {
+		return err
+	}
+	engine, err := defaultEngine()
+	if err != nil {
+		return err
+	}
+	if err = engine.remove(ctx, name); err != nil {
+		return err
+	}
+	collection, err := storagev2.AdmissionPoliciesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"name": name})
+	return err
+}
+
+// Check evaluates the policies applied to the operation in the pool of the
+// input, returning a *DeniedError when any of them denies it. Failures to
+// reach the engine deny the operation unless admission-policies:fail-open is
+// set.
+func Check(ctx context.Context, input Input) error {
+	policies, err := find(ctx, mongoBSON.M{
+		"pool":       mongoBSON.M{"$in": []string{"", input.Pool}},
+		"operations": input.Operation,
+	})
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	engine, err := defaultEngine()
+	if err != nil {
+		return evaluationFailure(input, err)
+	}
+	violations, err := evaluate(ctx, engine, policies, input)
+	if err != nil {
+		return evaluationFailure(input, err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &DeniedError{Operation: input.Operation, Violations: violations}
+}
+
+func evaluationFailure(input Input, err error) error {
+	if failOpen() {
+		log.Errorf("[admission-policies] ignoring failure to evaluate policies for %s on app %s: %v", input.Operation, input.App.Name, err)
+		return nil
+	}
+	return errors.Wrap(err, "unable to evaluate admission policies")
+}
+
+// evaluate runs the policies in the engine. A module missing from the
+// engine, like after a restart of the OPA server, is loaded again from the
+// stored policy before evaluating it a second time.
+func evaluate(ctx context.Context, engine *opaEngine, policies []Policy, input Input) (map[string][]string, error) {
+	violations := map[string][]string{}
+	for _, p := range policies {
+		msgs, err := engine.deny(ctx, p.Name, input)
+		if err == errUndefinedDeny {
+			log.Errorf("[admission-policies] deny of policy %s is undefined, loading its module again", p.Name)
+			if err = engine.put(ctx, &p); err == nil {
+				msgs, err = engine.deny(ctx, p.Name, input)
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "policy %s", p.Name)
+		}
+		if len(msgs) > 0 {
+			violations[p.Name] = msgs
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	opa *fakeOPA
+}
+
+var _ = check.Suite(&S{})
+
+// fakeOPA stores the loaded modules and answers evaluations with the deny
+// messages configured for each policy.
+type fakeOPA struct {
+	sync.Mutex
+	server  *httptest.Server
+	modules map[string]string
+	deny    map[string][]string
+	inputs  []Input
+}
+
+func newFakeOPA() *fakeOPA {
+	f := &fakeOPA{modules: map[string]string{}, deny: map[string][]string{}}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.Lock()
+		defer f.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/policies/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v1/policies/")
+			if r.Method == http.MethodDelete {
+				delete(f.modules, id)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(data), "invalid") {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":"invalid_parameter","message":"error(s) occurred while compiling module(s)","errors":[{"message":"rego_parse_error"}]}`))
+				return
+			}
+			f.modules[id] = string(data)
+		case strings.HasPrefix(r.URL.Path, "/v1/data/tsuru/policies/"):
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/data/tsuru/policies/"), "/deny")
+			var body struct {
+				Input Input `json:"input"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			f.inputs = append(f.inputs, body.Input)
+			if _, ok := f.modules["tsuru-policies-"+name]; !ok {
+				w.Write([]byte(`{}`))
+				return
+			}
+			msgs := f.deny[name]
+			if msgs == nil {
+				msgs = []string{}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": msgs})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return f
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "tsuru_policy_tests")
+	storagev2.Reset()
+	err := storagev2.ClearAllCollections(nil)
+	c.Assert(err, check.IsNil)
+	s.opa = newFakeOPA()
+	config.Set("admission-policies:opa:url", s.opa.server.URL)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.opa.server.Close()
+	config.Unset("admission-policies")
+}
+
+func module(name string) string {
+	return "package tsuru.policies." + name + "\n\ndeny contains msg if {\n\tfalse\n\tmsg := \"\"\n}\n"
+}
+
+func (s *S) TestSaveAndList(c *check.C) {
+	err := Save(context.TODO(), &Policy{Name: "min_units", Pool: "prod", Operations: []string{OperationAppDeploy}, Module: module("min_units")})
+	c.Assert(err, check.IsNil)
+	err = Save(context.TODO(), &Policy{Name: "plans", Operations: []string{OperationAppCreate, OperationAppPlanUpdate}, Module: module("plans")})
+	c.Assert(err, check.IsNil)
+	err = Save(context.TODO(), &Policy{Name: "other", Pool: "dev", Operations: []string{OperationAppEnvSet}, Module: module("other")})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.opa.modules, check.HasLen, 3)
+	c.Assert(s.opa.modules["tsuru-policies-plans"], check.Equals, module("plans"))
+	policies, err := List(context.TODO(), "prod")
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.HasLen, 2)
+	c.Assert(policies[0].Name, check.Equals, "min_units")
+	c.Assert(policies[1].Name, check.Equals, "plans")
+	policies, err = List(context.TODO(), "")
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.HasLen, 3)
+}
+
+func (s *S) TestSaveInvalid(c *check.C) {
+	tests := []struct {
+		policy Policy
+		err    string
+	}{
+		{Policy{Name: "Invalid-Name", Operations: []string{OperationAppDeploy}, Module: module("x")}, ErrInvalidPolicyName.Error()},
+		{Policy{Name: "noops", Module: module("noops")}, ErrOperationsRequired.Error()},
+		{Policy{Name: "badop", Operations: []string{"app.remove"}, Module: module("badop")}, `invalid operation "app.remove".*`},
+		{Policy{Name: "badpkg", Operations: []string{OperationAppDeploy}, Module: module("other")}, "module must declare package tsuru.policies.badpkg"},
+		{Policy{Name: "invalid", Operations: []string{OperationAppDeploy}, Module: module("invalid")}, "invalid module: error.* rego_parse_error"},
+	}
+	for _, tt := range tests {
+		err := Save(context.TODO(), &tt.policy)
+		c.Check(err, check.ErrorMatches, tt.err)
+	}
+	policies, err := List(context.TODO(), "")
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.HasLen, 0)
+}
+
+func (s *S) TestSaveEngineNotConfigured(c *check.C) {
+	config.Unset("admission-policies:opa:url")
+	err := Save(context.TODO(), &Policy{Name: "p1", Operations: []string{OperationAppDeploy}, Module: module("p1")})
+	c.Assert(err, check.Equals, ErrEngineNotConfigured)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	err := Save(context.TODO(), &Policy{Name: "p1", Operations: []string{OperationAppDeploy}, Module: module("p1")})
+	c.Assert(err, check.IsNil)
+	err = Remove(context.TODO(), "p1")
+	c.Assert(err, check.IsNil)
+	c.Assert(s.opa.modules, check.HasLen, 0)
+	_, err = Get(context.TODO(), "p1")
+	c.Assert(err, check.Equals, ErrPolicyNotFound)
+	err = Remove(context.TODO(), "p1")
+	c.Assert(err, check.Equals, ErrPolicyNotFound)
+}
+
+func (s *S) TestCheck(c *check.C) {
+	err := Save(context.TODO(), &Policy{Name: "min_units", Pool: "prod", Operations: []string{OperationAppDeploy}, Module: module("min_units")})
+	c.Assert(err, check.IsNil)
+	err = Save(context.TODO(), &Policy{Name: "global", Operations: []string{OperationAppDeploy, OperationAppEnvSet}, Module: module("global")})
+	c.Assert(err, check.IsNil)
+	input := Input{
+		Operation: OperationAppDeploy,
+		Pool:      "prod",
+		User:      "me@tsuru.io",
+		App:       App{Name: "myapp", Units: map[string]int{"web": 1}},
+	}
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.opa.inputs, check.HasLen, 2)
+	c.Assert(s.opa.inputs[0], check.DeepEquals, input)
+	s.opa.deny["min_units"] = []string{"production apps must have at least 2 units"}
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.FitsTypeOf, &DeniedError{})
+	c.Assert(err, check.ErrorMatches, "app.deploy denied by admission policies: min_units: production apps must have at least 2 units")
+	input.Pool = "dev"
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.IsNil)
+	input.Operation = OperationAppPlanUpdate
+	s.opa.inputs = nil
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.opa.inputs, check.HasLen, 0)
+}
+
+func (s *S) TestCheckReloadsLostModules(c *check.C) {
+	err := Save(context.TODO(), &Policy{Name: "p1", Operations: []string{OperationAppCreate}, Module: module("p1")})
+	c.Assert(err, check.IsNil)
+	s.opa.Lock()
+	s.opa.modules = map[string]string{}
+	s.opa.deny["p1"] = []string{"denied"}
+	s.opa.Unlock()
+	input := Input{Operation: OperationAppCreate, Pool: "prod", App: App{Name: "myapp"}}
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.ErrorMatches, "app.create denied by admission policies: p1: denied")
+	c.Assert(s.opa.modules["tsuru-policies-p1"], check.Equals, module("p1"))
+}
+
+func (s *S) TestCheckUndefinedDeny(c *check.C) {
+	err := Save(context.TODO(), &Policy{Name: "p1", Operations: []string{OperationAppCreate}, Module: module("p1")})
+	c.Assert(err, check.IsNil)
+	s.opa.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	input := Input{Operation: OperationAppCreate, Pool: "prod", App: App{Name: "myapp"}}
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.ErrorMatches, "unable to evaluate admission policies: policy p1: deny is undefined.*")
+}
+
+func (s *S) TestCheckEngineUnavailable(c *check.C) {
+	err := Save(context.TODO(), &Policy{Name: "p1", Operations: []string{OperationAppCreate}, Module: module("p1")})
+	c.Assert(err, check.IsNil)
+	s.opa.server.Close()
+	input := Input{Operation: OperationAppCreate, Pool: "prod", App: App{Name: "myapp"}}
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.ErrorMatches, "unable to evaluate admission policies: policy p1: .*")
+	config.Set("admission-policies:fail-open", true)
+	err = Check(context.TODO(), input)
+	c.Assert(err, check.IsNil)
+}
@@ -7,6 +7,7 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -24,6 +25,36 @@ type ClusteredProvisioner interface {
 	ClusterHelp() provTypes.ClusterHelpInfo
 }
 
+// FeatureFlagsProvisioner is implemented by clustered provisioners gating
+// some of their behaviors behind feature flags stored in the cluster custom
+// data.
+type FeatureFlagsProvisioner interface {
+	ClusterFeatures(c *provTypes.Cluster, pool string) []provTypes.ClusterFeature
+}
+
+// ResourceQuotaProvisioner is implemented by clustered provisioners limiting
+// the resources used by the apps of each namespace in the cluster.
+type ResourceQuotaProvisioner interface {
+	ResourceQuotas(ctx context.Context, c *provTypes.Cluster) ([]provTypes.NamespaceResourceQuota, error)
+}
+
+// NodeProvisioner is implemented by clustered provisioners allowing the
+// maintenance of the nodes of their clusters. Annotations with empty values
+// are removed from the node.
+type NodeProvisioner interface {
+	ListNodes(ctx context.Context, c *provTypes.Cluster) ([]provTypes.ClusterNode, error)
+	CordonNode(ctx context.Context, c *provTypes.Cluster, name string, unschedulable bool) error
+	DrainNode(ctx context.Context, c *provTypes.Cluster, name string, w io.Writer) error
+	AnnotateNode(ctx context.Context, c *provTypes.Cluster, name string, annotations map[string]string) error
+}
+
+// CapacityProvisioner is implemented by clustered provisioners able to report
+// the resources of their clusters and to check whether units would fit in
+// them, in which case simulation is not nil.
+type CapacityProvisioner interface {
+	ClusterCapacity(ctx context.Context, c *provTypes.Cluster, simulation *provision.CapacitySimulationOptions) (*provTypes.ClusterCapacity, error)
+}
+
 type clusterService struct {
 	storage provTypes.ClusterStorage
 }
@@ -159,6 +190,14 @@ func (s *clusterService) validate(c provTypes.Cluster, isNewCluster bool) error
 			return errors.WithStack(&tsuruErrors.ValidationError{Message: "either default or a list of pools must be set"})
 		}
 	}
+	if format, keys := c.ImageSignaturePolicy(); format != "" {
+		if format != provTypes.ImageSignatureCosign && format != provTypes.ImageSignatureNotary {
+			return errors.WithStack(&tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid image signature format %q, must be %s or %s", format, provTypes.ImageSignatureCosign, provTypes.ImageSignatureNotary)})
+		}
+		if len(keys) == 0 {
+			return errors.WithStack(&tsuruErrors.ValidationError{Message: "at least one PEM encoded public key is required to verify image signatures"})
+		}
+	}
 	prov, err := provision.Get(c.Provisioner)
 	if err != nil {
 		return errors.WithStack(&tsuruErrors.ValidationError{Message: fmt.Sprintf("provisioner error: %v", err)})
@@ -175,6 +175,39 @@ func (s *S) TestClusterServiceUpdateValidationError(c *check.C) {
 			},
 			err: "provisioner error: unknown provisioner: \"invalid\"",
 		},
+		{
+			c: provTypes.Cluster{
+				Name:        "c1",
+				Default:     true,
+				Provisioner: "fake",
+				CustomData:  map[string]string{provTypes.ClusterImageSignatureKey: "gpg"},
+			},
+			err: `invalid image signature format "gpg", must be cosign or notary`,
+		},
+		{
+			c: provTypes.Cluster{
+				Name:        "c1",
+				Default:     true,
+				Provisioner: "fake",
+				CustomData: map[string]string{
+					provTypes.ClusterImageSignatureKey:           provTypes.ImageSignatureCosign,
+					provTypes.ClusterImageSignaturePublicKeysKey: "not a key",
+				},
+			},
+			err: "at least one PEM encoded public key is required to verify image signatures",
+		},
+		{
+			c: provTypes.Cluster{
+				Name:        "c1",
+				Default:     true,
+				Provisioner: "fake",
+				CustomData: map[string]string{
+					provTypes.ClusterImageSignatureKey:           provTypes.ImageSignatureCosign,
+					provTypes.ClusterImageSignaturePublicKeysKey: "-----BEGIN PUBLIC KEY-----\nMFkw\n-----END PUBLIC KEY-----\n",
+				},
+			},
+			err: "",
+		},
 	}
 	for _, tt := range tests {
 		err := cs.Update(context.TODO(), tt.c)
@@ -25,9 +25,20 @@ func WebProcessDefaultPort() string {
 
 // Envs returns a map representing the apps environment variables.
 func EnvsForApp(app *appTypes.App) map[string]bindTypes.EnvVar {
-	mergedEnvs := make(map[string]bindTypes.EnvVar, len(app.Env)+len(app.ServiceEnvs)+1)
+	return EnvsForAppVersion(app, 0)
+}
+
+// EnvsForAppVersion returns the environment variables of the units of the
+// given app version, including the envs and binds restricted to it. Version
+// 0 returns only the envs shared by all versions.
+func EnvsForAppVersion(app *appTypes.App, version int) map[string]bindTypes.EnvVar {
+	mergedEnvs := make(map[string]bindTypes.EnvVar, len(app.TeamEnvs)+len(app.Env)+len(app.ServiceEnvs)+1)
 	toInterpolate := make(map[string]string)
 	var toInterpolateKeys []string
+	for _, e := range app.TeamEnvs {
+		e.ManagedBy = "team/" + app.TeamOwner
+		mergedEnvs[e.Name] = e
+	}
 	for _, e := range app.Env {
 		mergedEnvs[e.Name] = e
 		if e.Alias != "" {
@@ -35,7 +46,23 @@ func EnvsForApp(app *appTypes.App) map[string]bindTypes.EnvVar {
 			toInterpolateKeys = append(toInterpolateKeys, e.Name)
 		}
 	}
+	for _, e := range app.VersionEnvs {
+		if version == 0 || e.Version != version {
+			continue
+		}
+		mergedEnvs[e.Name] = e.EnvVar
+		delete(toInterpolate, e.Name)
+		if e.Alias != "" {
+			toInterpolate[e.Name] = e.Alias
+			toInterpolateKeys = append(toInterpolateKeys, e.Name)
+		}
+	}
+	serviceEnvs := make([]bindTypes.ServiceEnvVar, 0, len(app.ServiceEnvs))
 	for _, e := range app.ServiceEnvs {
+		if e.Version != 0 && e.Version != version {
+			continue
+		}
+		serviceEnvs = append(serviceEnvs, e)
 		envVar := e.EnvVar
 		envVar.ManagedBy = fmt.Sprintf("%s/%s", e.ServiceName, e.InstanceName)
 		mergedEnvs[e.Name] = envVar
@@ -44,7 +71,7 @@ func EnvsForApp(app *appTypes.App) map[string]bindTypes.EnvVar {
 	for _, envName := range toInterpolateKeys {
 		tsuruEnvs.Interpolate(mergedEnvs, toInterpolate, envName, toInterpolate[envName])
 	}
-	mergedEnvs[tsuruEnvs.TsuruServicesEnvVar] = tsuruEnvs.ServiceEnvsFromEnvVars(app.ServiceEnvs)
+	mergedEnvs[tsuruEnvs.TsuruServicesEnvVar] = tsuruEnvs.ServiceEnvsFromEnvVars(serviceEnvs)
 
 	mergedEnvs["TSURU_APPNAME"] = bindTypes.EnvVar{
 		Name:      "TSURU_APPNAME",
@@ -63,8 +90,12 @@ func EnvsForApp(app *appTypes.App) map[string]bindTypes.EnvVar {
 
 func EnvsForAppAndVersion(a *appTypes.App, process string, version appTypes.AppVersion) []bindTypes.EnvVar {
 	var envs []bindTypes.EnvVar
+	var versionNumber int
+	if version != nil {
+		versionNumber = version.Version()
+	}
 
-	for _, envData := range EnvsForApp(a) {
+	for _, envData := range EnvsForAppVersion(a, versionNumber) {
 		envs = append(envs, envData)
 	}
 	sort.Slice(envs, func(i int, j int) bool {
@@ -64,6 +64,50 @@ func (s *S) TestEnvsForApp(c *check.C) {
 	})
 }
 
+func (s *S) TestEnvsForAppWithTeamEnvs(c *check.C) {
+	a := provisiontest.NewFakeApp("myapp", "crystal", 1)
+	a.TeamOwner = "myteam"
+	a.TeamEnvs = []bindTypes.EnvVar{
+		{Name: "HTTP_PROXY", Value: "http://proxy:3128", Public: true},
+		{Name: "e1", Value: "team-value"},
+	}
+	a.Env = map[string]bindTypes.EnvVar{
+		"e1": {Name: "e1", Value: "v1"},
+	}
+	envs := provision.EnvsForApp(a)
+	c.Assert(envs["HTTP_PROXY"], check.DeepEquals, bindTypes.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy:3128", Public: true, ManagedBy: "team/myteam"})
+	c.Assert(envs["e1"], check.DeepEquals, bindTypes.EnvVar{Name: "e1", Value: "v1"})
+}
+
+func (s *S) TestEnvsForAppVersion(c *check.C) {
+	a := provisiontest.NewFakeApp("myapp", "crystal", 1)
+	a.Env = map[string]bindTypes.EnvVar{
+		"e1": {Name: "e1", Value: "v1"},
+	}
+	a.VersionEnvs = []bindTypes.VersionEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "e1", Value: "canary"}, Version: 2},
+		{EnvVar: bindTypes.EnvVar{Name: "e2", Value: "other"}, Version: 3},
+	}
+	a.ServiceEnvs = []bindTypes.ServiceEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "DB_HOST", Value: "old"}, ServiceName: "db", InstanceName: "old"},
+		{EnvVar: bindTypes.EnvVar{Name: "DB_PASSWORD", Value: "new"}, ServiceName: "db", InstanceName: "new", Version: 2},
+	}
+	envs := provision.EnvsForApp(a)
+	c.Assert(envs["e1"], check.DeepEquals, bindTypes.EnvVar{Name: "e1", Value: "v1"})
+	c.Assert(envs["DB_HOST"].Value, check.Equals, "old")
+	_, ok := envs["DB_PASSWORD"]
+	c.Assert(ok, check.Equals, false)
+	_, ok = envs["e2"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(envs["TSURU_SERVICES"].Value, check.Equals, `{"db":[{"instance_name":"old","envs":{"DB_HOST":"old"}}]}`)
+	envs = provision.EnvsForAppVersion(a, 2)
+	c.Assert(envs["e1"], check.DeepEquals, bindTypes.EnvVar{Name: "e1", Value: "canary"})
+	c.Assert(envs["DB_HOST"].Value, check.Equals, "old")
+	c.Assert(envs["DB_PASSWORD"], check.DeepEquals, bindTypes.EnvVar{Name: "DB_PASSWORD", Value: "new", ManagedBy: "db/new"})
+	_, ok = envs["e2"]
+	c.Assert(ok, check.Equals, false)
+}
+
 func (s *S) TestEnvsForAppWithVersion(c *check.C) {
 	a := provisiontest.NewFakeApp("myapp", "crystal", 1)
 	a.Env = map[string]bindTypes.EnvVar{
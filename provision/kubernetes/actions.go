@@ -98,7 +98,11 @@ var updateAppCR = action.Action{
 		if err != nil {
 			return nil, err
 		}
-		return nil, updateAppNamespace(ctx.Context, client, params.old.Name, client.PoolNamespace(params.new.Pool))
+		ns, err := client.TeamNamespace(ctx.Context, params.new.Pool, params.new.TeamOwner)
+		if err != nil {
+			return nil, err
+		}
+		return nil, updateAppNamespace(ctx.Context, client, params.old.Name, ns)
 	},
 	Backward: func(ctx action.BWContext) {
 		params := ctx.Params[0].(updatePipelineParams)
@@ -113,7 +117,11 @@ func backwardCR(ctx context.Context, params updatePipelineParams) error {
 	if err != nil {
 		return err
 	}
-	return updateAppNamespace(ctx, client, params.old.Name, client.PoolNamespace(params.old.Pool))
+	ns, err := client.TeamNamespace(ctx, params.old.Pool, params.old.TeamOwner)
+	if err != nil {
+		return err
+	}
+	return updateAppNamespace(ctx, client, params.old.Name, ns)
 }
 
 var removeOldAppResources = action.Action{
@@ -130,7 +138,11 @@ var removeOldAppResources = action.Action{
 			log.Errorf("failed to remove old resources: %v", err)
 			return nil, nil
 		}
-		oldAppCR.Spec.NamespaceName = client.PoolNamespace(params.old.Pool)
+		oldAppCR.Spec.NamespaceName, err = client.TeamNamespace(ctx.Context, params.old.Pool, params.old.TeamOwner)
+		if err != nil {
+			log.Errorf("failed to remove old resources: %v", err)
+			return nil, nil
+		}
 		err = params.p.removeResources(ctx.Context, client, oldAppCR, params.old)
 		if err != nil {
 			log.Errorf("failed to remove old resources: %v", err)
@@ -0,0 +1,82 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/secret"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const tsuruLabelAppSecrets = tsuruLabelPrefix + "app-secrets"
+
+func appSecretsLabels(a *appTypes.App) *provision.LabelSet {
+	labelSet := provision.ServiceAccountLabels(provision.ServiceAccountLabelsOpts{
+		App:    a,
+		Prefix: tsuruLabelPrefix,
+	})
+	labelSet.RawLabels = map[string]string{tsuruLabelAppSecrets: strconv.FormatBool(true)}
+	return labelSet
+}
+
+// appSecretsName returns the name of the Secret holding the secrets of the
+// app, changing whenever any of them is set, like the name of the objects
+// holding its files.
+func appSecretsName(a *appTypes.App) string {
+	return fmt.Sprintf("app-%s-secrets-%s", provision.ValidKubeName(a.Name), appTypes.AppSecretsDigest(a.Secrets)[:10])
+}
+
+// ensureAppSecrets opens the secrets of the app into a Secret in its
+// namespace, returning the environment variables referring to it.
+func ensureAppSecrets(ctx context.Context, client *ClusterClient, a *appTypes.App, namespace string) ([]apiv1.EnvVar, error) {
+	if len(a.Secrets) == 0 {
+		return nil, nil
+	}
+	values, err := secret.OpenAll(a.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	name := appSecretsName(a)
+	immutable := true
+	_, err = client.CoreV1().Secrets(namespace).Create(ctx, &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    appSecretsLabels(a).ToLabels(),
+		},
+		Data:      values,
+		Immutable: &immutable,
+	}, metav1.CreateOptions{})
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return nil, errors.WithStack(err)
+	}
+	names := make([]string, 0, len(values))
+	for secretName := range values {
+		names = append(names, secretName)
+	}
+	sort.Strings(names)
+	envs := make([]apiv1.EnvVar, 0, len(names))
+	for _, secretName := range names {
+		envs = append(envs, apiv1.EnvVar{
+			Name: secretName,
+			ValueFrom: &apiv1.EnvVarSource{
+				SecretKeyRef: &apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: name},
+					Key:                  secretName,
+				},
+			},
+		})
+	}
+	return envs, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"io"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestServiceManagerDeployServiceWithSecrets(c *check.C) {
+	config.Set("secrets:master-key", "a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2s=")
+	defer config.Unset("secrets")
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{"web": "run"},
+	})
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	deploy := func() {
+		err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+			App:     a,
+			Version: version,
+		}, servicecommon.ProcessSpec{
+			"web": servicecommon.ProcessState{Start: true},
+		})
+		c.Assert(err, check.IsNil)
+		waitDep()
+	}
+	err = app.SetSecrets(context.TODO(), a, app.SetSecretsArgs{
+		Secrets: map[string]string{"DB_PASSWORD": "abc", "API_TOKEN": "xyz"},
+		Writer:  io.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	deploy()
+	secretName := appSecretsName(a)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	var secretEnvs []apiv1.EnvVar
+	for _, env := range dep.Spec.Template.Spec.Containers[0].Env {
+		c.Assert(env.Value, check.Not(check.Equals), "abc")
+		if env.ValueFrom != nil {
+			secretEnvs = append(secretEnvs, env)
+		}
+	}
+	c.Assert(secretEnvs, check.DeepEquals, []apiv1.EnvVar{
+		{Name: "API_TOKEN", ValueFrom: &apiv1.EnvVarSource{SecretKeyRef: &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+			Key:                  "API_TOKEN",
+		}}},
+		{Name: "DB_PASSWORD", ValueFrom: &apiv1.EnvVarSource{SecretKeyRef: &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+			Key:                  "DB_PASSWORD",
+		}}},
+	})
+	secret, err := s.client.CoreV1().Secrets(ns).Get(context.TODO(), secretName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.Data, check.DeepEquals, map[string][]byte{"DB_PASSWORD": []byte("abc"), "API_TOKEN": []byte("xyz")})
+	c.Assert(*secret.Immutable, check.Equals, true)
+
+	err = app.UnsetSecrets(context.TODO(), a, []string{"API_TOKEN"}, io.Discard, false)
+	c.Assert(err, check.IsNil)
+	deploy()
+	newSecretName := appSecretsName(a)
+	c.Assert(newSecretName, check.Not(check.Equals), secretName)
+	secret, err = s.client.CoreV1().Secrets(ns).Get(context.TODO(), newSecretName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.Data, check.DeepEquals, map[string][]byte{"DB_PASSWORD": []byte("abc")})
+	_, err = s.client.CoreV1().Secrets(ns).Get(context.TODO(), secretName, metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+}
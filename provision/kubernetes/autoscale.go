@@ -33,6 +33,9 @@ import (
 
 const (
 	vpaCRDName = "verticalpodautoscalers.autoscaling.k8s.io"
+
+	customMetricsGroupVersion   = "custom.metrics.k8s.io/v1beta1"
+	externalMetricsGroupVersion = "external.metrics.k8s.io/v1beta1"
 )
 
 var errNoDeploy = errors.New("no routable version found for app, at least one deploy is required before configuring autoscale")
@@ -224,6 +227,33 @@ func scaledObjectToSpec(scaledObject kedav1alpha1.ScaledObject) provTypes.AutoSc
 				PrometheusAddress:   metric.Metadata["serverAddress"],
 			})
 
+		case "kafka":
+			lagThreshold, _ := strconv.ParseInt(metric.Metadata["lagThreshold"], 10, 64)
+			activationLagThreshold, _ := strconv.ParseInt(metric.Metadata["activationLagThreshold"], 10, 64)
+
+			spec.Kafka = append(spec.Kafka, provTypes.AutoScaleKafka{
+				Name:                   metric.Name,
+				BootstrapServers:       metric.Metadata["bootstrapServers"],
+				ConsumerGroup:          metric.Metadata["consumerGroup"],
+				Topic:                  metric.Metadata["topic"],
+				LagThreshold:           lagThreshold,
+				ActivationLagThreshold: activationLagThreshold,
+				AuthenticationRef:      authenticationRefName(metric.AuthenticationRef),
+			})
+
+		case "aws-sqs-queue":
+			queueLength, _ := strconv.ParseInt(metric.Metadata["queueLength"], 10, 64)
+			activationQueueLength, _ := strconv.ParseInt(metric.Metadata["activationQueueLength"], 10, 64)
+
+			spec.SQS = append(spec.SQS, provTypes.AutoScaleSQS{
+				Name:                  metric.Name,
+				QueueURL:              metric.Metadata["queueURL"],
+				QueueLength:           queueLength,
+				ActivationQueueLength: activationQueueLength,
+				Region:                metric.Metadata["awsRegion"],
+				AuthenticationRef:     authenticationRefName(metric.AuthenticationRef),
+			})
+
 		case "cpu":
 			cpuValue := metric.Metadata["value"]
 			if metric.MetricType == autoscalingv2.UtilizationMetricType {
@@ -235,9 +265,37 @@ func scaledObjectToSpec(scaledObject kedav1alpha1.ScaledObject) provTypes.AutoSc
 		}
 	}
 
+	spec.Status = scaledObjectStatus(scaledObject)
+
 	return spec
 }
 
+func authenticationRefName(ref *kedav1alpha1.ScaledObjectAuthRef) string {
+	if ref == nil {
+		return ""
+	}
+	return ref.Name
+}
+
+// scaledObjectStatus returns the status reported by KEDA for the scaled
+// object, nil while it hasn't been reconciled yet.
+func scaledObjectStatus(scaledObject kedav1alpha1.ScaledObject) *provTypes.AutoScaleStatus {
+	conditions := scaledObject.Status.Conditions
+	if len(conditions) == 0 {
+		return nil
+	}
+	ready := conditions.GetReadyCondition()
+	active := conditions.GetActiveCondition()
+	status := &provTypes.AutoScaleStatus{
+		Ready:  ready.IsTrue(),
+		Active: active.IsTrue(),
+	}
+	if !status.Ready {
+		status.Message = ready.Message
+	}
+	return status
+}
+
 func hpaToSpec(hpa autoscalingv2.HorizontalPodAutoscaler) provTypes.AutoScaleSpec {
 	ls := labelSetFromMeta(&hpa.ObjectMeta)
 	spec := provTypes.AutoScaleSpec{
@@ -257,12 +315,19 @@ func hpaToSpec(hpa autoscalingv2.HorizontalPodAutoscaler) provTypes.AutoScaleSpe
 	}
 
 	cpuValue := int64(0)
-	if len(hpa.Spec.Metrics) > 0 && hpa.Spec.Metrics[0].Resource != nil {
-		if hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != nil {
-			cpuValue = int64(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
-			cpuValue = cpuValue * 10
-		} else if hpa.Spec.Metrics[0].Resource.Target.AverageValue != nil {
-			cpuValue = hpa.Spec.Metrics[0].Resource.Target.AverageValue.MilliValue()
+	for _, metric := range hpa.Spec.Metrics {
+		switch {
+		case metric.Resource != nil && metric.Resource.Name == "cpu":
+			if metric.Resource.Target.AverageUtilization != nil {
+				cpuValue = int64(*metric.Resource.Target.AverageUtilization)
+				cpuValue = cpuValue * 10
+			} else if metric.Resource.Target.AverageValue != nil {
+				cpuValue = metric.Resource.Target.AverageValue.MilliValue()
+			}
+		case metric.Pods != nil:
+			spec.Metrics = append(spec.Metrics, hpaMetricToSpec(provTypes.AutoScaleMetricPods, metric.Pods.Metric, metric.Pods.Target))
+		case metric.External != nil:
+			spec.Metrics = append(spec.Metrics, hpaMetricToSpec(provTypes.AutoScaleMetricExternal, metric.External.Metric, metric.External.Target))
 		}
 	}
 
@@ -273,6 +338,22 @@ func hpaToSpec(hpa autoscalingv2.HorizontalPodAutoscaler) provTypes.AutoScaleSpe
 	return spec
 }
 
+func hpaMetricToSpec(metricType string, identifier autoscalingv2.MetricIdentifier, target autoscalingv2.MetricTarget) provTypes.AutoScaleMetric {
+	metric := provTypes.AutoScaleMetric{
+		Name: identifier.Name,
+		Type: metricType,
+	}
+	if identifier.Selector != nil {
+		metric.Selector = metav1.FormatLabelSelector(identifier.Selector)
+	}
+	if target.AverageValue != nil {
+		metric.Target = target.AverageValue.String()
+	} else if target.Value != nil {
+		metric.Target = target.Value.String()
+	}
+	return metric
+}
+
 func (p *kubernetesProvisioner) deleteAllAutoScale(ctx context.Context, a *appTypes.App) error {
 	scaleSpecs, err := p.GetAutoScale(ctx, a)
 	if err != nil {
@@ -376,7 +457,7 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a *appTypes.App, s
 	labels = labels.WithoutIsolated().WithoutRoutable()
 	hpaName := hpaNameForApp(a, depInfo.process)
 
-	if len(spec.Schedules) > 0 || len(spec.Prometheus) > 0 {
+	if spec.UsesScalers() {
 		err = setKEDAAutoscale(ctx, client, spec, a, depInfo, hpaName, labels)
 		if err != nil {
 			return errors.WithStack(err)
@@ -387,21 +468,26 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a *appTypes.App, s
 
 	minUnits := int32(spec.MinUnits)
 
-	cpuValue, err := provision.CPUValueOfAutoScaleSpec(&spec, a)
-	if err != nil {
-		return errors.WithStack(err)
+	var metrics []autoscalingv2.MetricSpec
+	if spec.AverageCPU != "" {
+		var cpuMetric autoscalingv2.MetricSpec
+		cpuMetric, err = cpuMetricSpec(spec, a)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, cpuMetric)
 	}
-
-	target := autoscalingv2.MetricTarget{}
-	if a.Plan.GetMilliCPU() > 0 {
-		target.Type = autoscalingv2.UtilizationMetricType
-		val := int32(cpuValue)
-		target.AverageUtilization = &val
-	} else {
-		target.Type = autoscalingv2.AverageValueMetricType
-		target.AverageValue = resource.NewMilliQuantity(int64(cpuValue), resource.DecimalSI)
-		// Fill string value for easier tests
-		_ = target.AverageValue.String()
+	if len(spec.Metrics) > 0 {
+		err = ensureMetricsAPIs(client, spec.Metrics)
+		if err != nil {
+			return err
+		}
+		var customMetrics []autoscalingv2.MetricSpec
+		customMetrics, err = hpaMetricSpecs(spec.Metrics)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, customMetrics...)
 	}
 
 	hpa := &autoscalingv2.HorizontalPodAutoscaler{
@@ -422,15 +508,7 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a *appTypes.App, s
 			// default to prevent the autoscaler from scaling down too fast
 			// poossibly disrupting the app.
 			Behavior: buildHPABehavior(spec.Behavior.ScaleDown),
-			Metrics: []autoscalingv2.MetricSpec{
-				{
-					Type: autoscalingv2.ResourceMetricSourceType,
-					Resource: &autoscalingv2.ResourceMetricSource{
-						Name:   "cpu",
-						Target: target,
-					},
-				},
-			},
+			Metrics:  metrics,
 		},
 	}
 
@@ -464,6 +542,103 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a *appTypes.App, s
 	return nil
 }
 
+func cpuMetricSpec(spec provTypes.AutoScaleSpec, a *appTypes.App) (autoscalingv2.MetricSpec, error) {
+	cpuValue, err := provision.CPUValueOfAutoScaleSpec(&spec, a)
+	if err != nil {
+		return autoscalingv2.MetricSpec{}, errors.WithStack(err)
+	}
+
+	target := autoscalingv2.MetricTarget{}
+	if a.Plan.GetMilliCPU() > 0 {
+		target.Type = autoscalingv2.UtilizationMetricType
+		val := int32(cpuValue)
+		target.AverageUtilization = &val
+	} else {
+		target.Type = autoscalingv2.AverageValueMetricType
+		target.AverageValue = resource.NewMilliQuantity(int64(cpuValue), resource.DecimalSI)
+		// Fill string value for easier tests
+		_ = target.AverageValue.String()
+	}
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name:   "cpu",
+			Target: target,
+		},
+	}, nil
+}
+
+// hpaMetricSpecs converts the custom and external metrics of an autoscale
+// spec to HPA metrics, all of them targeting an average value per unit.
+func hpaMetricSpecs(metrics []provTypes.AutoScaleMetric) ([]autoscalingv2.MetricSpec, error) {
+	var specs []autoscalingv2.MetricSpec
+	for _, m := range metrics {
+		target, err := resource.ParseQuantity(m.Target)
+		if err != nil {
+			return nil, errors.Errorf("invalid target %q for metric %q", m.Target, m.Name)
+		}
+		// Fill string value for easier tests
+		_ = target.String()
+		identifier := autoscalingv2.MetricIdentifier{Name: m.Name}
+		if m.Selector != "" {
+			identifier.Selector, err = metav1.ParseToLabelSelector(m.Selector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid selector for metric %q", m.Name)
+			}
+		}
+		metricTarget := autoscalingv2.MetricTarget{
+			Type:         autoscalingv2.AverageValueMetricType,
+			AverageValue: &target,
+		}
+		switch m.Type {
+		case provTypes.AutoScaleMetricPods:
+			specs = append(specs, autoscalingv2.MetricSpec{
+				Type: autoscalingv2.PodsMetricSourceType,
+				Pods: &autoscalingv2.PodsMetricSource{Metric: identifier, Target: metricTarget},
+			})
+		case provTypes.AutoScaleMetricExternal:
+			specs = append(specs, autoscalingv2.MetricSpec{
+				Type:     autoscalingv2.ExternalMetricSourceType,
+				External: &autoscalingv2.ExternalMetricSource{Metric: identifier, Target: metricTarget},
+			})
+		default:
+			return nil, errors.Errorf("invalid type %q for metric %q", m.Type, m.Name)
+		}
+	}
+	return specs, nil
+}
+
+// ensureMetricsAPIs checks that the cluster serves the metrics APIs used by
+// the metrics, which are only available when a metrics adapter, like
+// prometheus-adapter, is installed.
+func ensureMetricsAPIs(client *ClusterClient, metrics []provTypes.AutoScaleMetric) error {
+	groupVersions := map[string]string{
+		provTypes.AutoScaleMetricPods:     customMetricsGroupVersion,
+		provTypes.AutoScaleMetricExternal: externalMetricsGroupVersion,
+	}
+	checked := map[string]struct{}{}
+	for _, m := range metrics {
+		gv, ok := groupVersions[m.Type]
+		if !ok {
+			return errors.Errorf("invalid type %q for metric %q", m.Type, m.Name)
+		}
+		if _, ok = checked[gv]; ok {
+			continue
+		}
+		checked[gv] = struct{}{}
+		_, err := client.Discovery().ServerResourcesForGroupVersion(gv)
+		if k8sErrors.IsNotFound(err) {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("metric %q requires the %s API, which is not served by cluster %q, a metrics adapter must be installed", m.Name, gv, client.Name),
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to check %s API", gv)
+		}
+	}
+	return nil
+}
+
 func setKEDAAutoscale(ctx context.Context, client *ClusterClient, spec provTypes.AutoScaleSpec, a *appTypes.App, depInfo *deploymentInfo, hpaName string, labels *provision.LabelSet) error {
 	kedaClient, err := KEDAClientForConfig(client.restConfig)
 	if err != nil {
@@ -551,6 +726,14 @@ func newKEDAScaledObject(spec provTypes.AutoScaleSpec, a *appTypes.App, depInfo
 		kedaTriggers = append(kedaTriggers, *prometheusTrigger)
 	}
 
+	for _, kafka := range spec.Kafka {
+		kedaTriggers = append(kedaTriggers, buildKafkaTrigger(kafka))
+	}
+
+	for _, sqs := range spec.SQS {
+		kedaTriggers = append(kedaTriggers, buildSQSTrigger(sqs))
+	}
+
 	var scaledObjectAnnotation map[string]string
 	if depInfo.replicas == 0 {
 		//this is to disable the scale object when the deployment is scaled to 0 (app stop)
@@ -615,6 +798,48 @@ func buildPrometheusTrigger(ns string, prometheus provTypes.AutoScalePrometheus)
 	}, nil
 }
 
+func buildKafkaTrigger(kafka provTypes.AutoScaleKafka) kedav1alpha1.ScaleTriggers {
+	metadata := map[string]string{
+		"bootstrapServers":       kafka.BootstrapServers,
+		"consumerGroup":          kafka.ConsumerGroup,
+		"lagThreshold":           strconv.FormatInt(kafka.LagThreshold, 10),
+		"activationLagThreshold": strconv.FormatInt(kafka.ActivationLagThreshold, 10),
+	}
+	if kafka.Topic != "" {
+		metadata["topic"] = kafka.Topic
+	}
+	return kedav1alpha1.ScaleTriggers{
+		Type:              "kafka",
+		Name:              kafka.Name,
+		AuthenticationRef: clusterTriggerAuthenticationRef(kafka.AuthenticationRef),
+		Metadata:          metadata,
+	}
+}
+
+func buildSQSTrigger(sqs provTypes.AutoScaleSQS) kedav1alpha1.ScaleTriggers {
+	return kedav1alpha1.ScaleTriggers{
+		Type:              "aws-sqs-queue",
+		Name:              sqs.Name,
+		AuthenticationRef: clusterTriggerAuthenticationRef(sqs.AuthenticationRef),
+		Metadata: map[string]string{
+			"queueURL":              sqs.QueueURL,
+			"queueLength":           strconv.FormatInt(sqs.QueueLength, 10),
+			"activationQueueLength": strconv.FormatInt(sqs.ActivationQueueLength, 10),
+			"awsRegion":             sqs.Region,
+		},
+	}
+}
+
+func clusterTriggerAuthenticationRef(name string) *kedav1alpha1.ScaledObjectAuthRef {
+	if name == "" {
+		return nil
+	}
+	return &kedav1alpha1.ScaledObjectAuthRef{
+		Kind: "ClusterTriggerAuthentication",
+		Name: name,
+	}
+}
+
 func buildDefaultPrometheusAddress(ns string) (string, error) {
 	prometheusAddressTemplate, err := config.GetString("kubernetes:keda:prometheus-address-template")
 	if err != nil {
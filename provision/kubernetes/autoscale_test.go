@@ -13,6 +13,7 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kr/pretty"
 	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	check "gopkg.in/check.v1"
@@ -27,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 )
 
 func toInt32Ptr(i int32) *int32 {
@@ -339,6 +341,81 @@ func (s *S) TestProvisionerSetAutoScale(c *check.C) {
 	}
 }
 
+func (s *S) TestProvisionerSetAutoScaleWithMetrics(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	version := newSuccessfulVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	err := s.p.AddUnits(context.TODO(), a, 1, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	wait()
+
+	spec := provTypes.AutoScaleSpec{
+		MinUnits:   1,
+		MaxUnits:   2,
+		AverageCPU: "500m",
+		Process:    "web",
+		Metrics: []provTypes.AutoScaleMetric{
+			{Name: "http_requests_per_second", Type: "pods", Target: "100"},
+			{Name: "queue_messages_ready", Type: "external", Target: "30", Selector: "queue=orders"},
+		},
+	}
+	err = s.p.SetAutoScale(context.TODO(), a, spec)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `metric "http_requests_per_second" requires the custom.metrics.k8s.io/v1beta1 API, which is not served by cluster "c1", a metrics adapter must be installed`)
+
+	discovery := s.client.Clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.Resources = append(discovery.Resources,
+		&metav1.APIResourceList{GroupVersion: "custom.metrics.k8s.io/v1beta1"},
+		&metav1.APIResourceList{GroupVersion: "external.metrics.k8s.io/v1beta1"},
+	)
+	err = s.p.SetAutoScale(context.TODO(), a, spec)
+	c.Assert(err, check.IsNil)
+
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	hpa, err := s.client.AutoscalingV2().HorizontalPodAutoscalers(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	cpu := resource.MustParse("500m")
+	rps := resource.MustParse("100")
+	messages := resource.MustParse("30")
+	c.Assert(hpa.Spec.Metrics, check.DeepEquals, []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   "cpu",
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &cpu},
+			},
+		},
+		{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: "http_requests_per_second"},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &rps},
+			},
+		},
+		{
+			Type: autoscalingv2.ExternalMetricSourceType,
+			External: &autoscalingv2.ExternalMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{
+					Name:     "queue_messages_ready",
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"queue": "orders"}},
+				},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &messages},
+			},
+		},
+	})
+
+	scales, err := s.p.GetAutoScale(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(scales, check.HasLen, 1)
+	c.Assert(scales[0].AverageCPU, check.Equals, "500m")
+	c.Assert(scales[0].Metrics, check.DeepEquals, spec.Metrics)
+}
+
 func (s *S) TestProvisionerSetScheduleKEDAAutoScale(c *check.C) {
 	a, wait, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
@@ -1409,6 +1486,98 @@ func (s *S) TestProvisionerGetPrometheusKEDAAutoScale(c *check.C) {
 	})
 }
 
+func (s *S) TestProvisionerSetKafkaAndSQSKEDAAutoScale(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	version := newSuccessfulVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	err := s.p.AddUnits(context.TODO(), a, 1, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	wait()
+
+	spec := provTypes.AutoScaleSpec{
+		MinUnits: 1,
+		MaxUnits: 10,
+		Process:  "web",
+		Kafka: []provTypes.AutoScaleKafka{
+			{
+				Name:              "orders-lag",
+				BootstrapServers:  "kafka-0:9092,kafka-1:9092",
+				ConsumerGroup:     "orders",
+				Topic:             "orders",
+				LagThreshold:      50,
+				AuthenticationRef: "kafka-credentials",
+			},
+		},
+		SQS: []provTypes.AutoScaleSQS{
+			{
+				Name:                  "orders-queue",
+				QueueURL:              "https://sqs.us-east-1.amazonaws.com/123456789012/orders",
+				QueueLength:           5,
+				ActivationQueueLength: 1,
+				Region:                "us-east-1",
+			},
+		},
+	}
+	err = s.p.SetAutoScale(context.TODO(), a, spec)
+	c.Assert(err, check.IsNil)
+
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	scaledObject, err := s.client.KEDAClientForConfig.KedaV1alpha1().ScaledObjects(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(scaledObject.Spec.Triggers, check.DeepEquals, []kedav1alpha1.ScaleTriggers{
+		{
+			Type: "kafka",
+			Name: "orders-lag",
+			Metadata: map[string]string{
+				"bootstrapServers":       "kafka-0:9092,kafka-1:9092",
+				"consumerGroup":          "orders",
+				"topic":                  "orders",
+				"lagThreshold":           "50",
+				"activationLagThreshold": "0",
+			},
+			AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{
+				Kind: "ClusterTriggerAuthentication",
+				Name: "kafka-credentials",
+			},
+		},
+		{
+			Type: "aws-sqs-queue",
+			Name: "orders-queue",
+			Metadata: map[string]string{
+				"queueURL":              "https://sqs.us-east-1.amazonaws.com/123456789012/orders",
+				"queueLength":           "5",
+				"activationQueueLength": "1",
+				"awsRegion":             "us-east-1",
+			},
+		},
+	})
+
+	scaledObject.Status.Conditions = kedav1alpha1.Conditions{
+		kedav1alpha1.Condition{Type: kedav1alpha1.ConditionReady, Status: metav1.ConditionFalse, Message: "error getting consumer group lag"},
+		kedav1alpha1.Condition{Type: kedav1alpha1.ConditionActive, Status: metav1.ConditionFalse},
+	}
+	_, err = s.client.KEDAClientForConfig.KedaV1alpha1().ScaledObjects(ns).UpdateStatus(context.TODO(), scaledObject, metav1.UpdateOptions{})
+	c.Assert(err, check.IsNil)
+	_, err = s.client.AutoscalingV2().HorizontalPodAutoscalers(ns).Create(context.TODO(), testKEDAHPA("myapp-web"), metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+
+	scales, err := s.p.GetAutoScale(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(scales, check.HasLen, 1)
+	c.Assert(scales[0].Kafka, check.DeepEquals, spec.Kafka)
+	c.Assert(scales[0].SQS, check.DeepEquals, spec.SQS)
+	c.Assert(scales[0].Status, check.DeepEquals, &provTypes.AutoScaleStatus{
+		Ready:   false,
+		Active:  false,
+		Message: "error getting consumer group lag",
+	})
+}
+
 func (s *S) TestProvisionerKEDAAutoScaleWhenAppStopAppStart(c *check.C) {
 	a, wait, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
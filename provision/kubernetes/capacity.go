@@ -0,0 +1,147 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodeCapacity holds the resources of a node and the ones requested by the
+// pods running in it.
+type nodeCapacity struct {
+	pool                string
+	allocatableCPUMilli int64
+	allocatableMemory   int64
+	allocatablePods     int64
+	requestedCPUMilli   int64
+	requestedMemory     int64
+	pods                int64
+}
+
+// unitsFitting returns how many units requesting cpuMilli and memory may be
+// scheduled in the free resources of the node.
+func (n *nodeCapacity) unitsFitting(cpuMilli, memory int64) int64 {
+	fit := n.allocatablePods - n.pods
+	if cpuMilli > 0 {
+		fit = minInt64(fit, (n.allocatableCPUMilli-n.requestedCPUMilli)/cpuMilli)
+	}
+	if memory > 0 {
+		fit = minInt64(fit, (n.allocatableMemory-n.requestedMemory)/memory)
+	}
+	if fit < 0 {
+		return 0
+	}
+	return fit
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (p *kubernetesProvisioner) ClusterCapacity(ctx context.Context, c *provTypes.Cluster, simulation *provision.CapacitySimulationOptions) (*provTypes.ClusterCapacity, error) {
+	client, err := NewClusterClient(c)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := schedulableNodesCapacity(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	pools := map[string]*provTypes.PoolCapacity{}
+	for _, node := range nodes {
+		pool := pools[node.pool]
+		if pool == nil {
+			pool = &provTypes.PoolCapacity{Pool: node.pool}
+			pools[node.pool] = pool
+		}
+		pool.Nodes++
+		pool.AllocatableCPUMilli += node.allocatableCPUMilli
+		pool.AllocatableMemory += node.allocatableMemory
+		pool.RequestedCPUMilli += node.requestedCPUMilli
+		pool.RequestedMemory += node.requestedMemory
+	}
+	result := &provTypes.ClusterCapacity{Pools: []provTypes.PoolCapacity{}}
+	for _, pool := range pools {
+		result.Pools = append(result.Pools, *pool)
+	}
+	sort.Slice(result.Pools, func(i, j int) bool { return result.Pools[i].Pool < result.Pools[j].Pool })
+	if simulation == nil {
+		return result, nil
+	}
+	requirements, err := planRequirements(client, simulation.Pool, simulation.Plan)
+	if err != nil {
+		return nil, err
+	}
+	sim := &provTypes.CapacitySimulation{
+		Pool:         simulation.Pool,
+		Units:        simulation.Units,
+		UnitCPUMilli: requirements.Requests.Cpu().MilliValue(),
+		UnitMemory:   requirements.Requests.Memory().Value(),
+	}
+	var schedulable int64
+	for _, node := range nodes {
+		if node.pool != simulation.Pool {
+			continue
+		}
+		schedulable += node.unitsFitting(sim.UnitCPUMilli, sim.UnitMemory)
+	}
+	sim.SchedulableUnits = int(schedulable)
+	sim.Fits = sim.SchedulableUnits >= sim.Units
+	result.Simulation = sim
+	return result, nil
+}
+
+// schedulableNodesCapacity returns the capacity of the ready nodes accepting
+// new pods, along with the pool they belong to.
+func schedulableNodesCapacity(ctx context.Context, client *ClusterClient) ([]*nodeCapacity, error) {
+	nodeList, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	nodes := map[string]*nodeCapacity{}
+	var result []*nodeCapacity
+	for _, node := range nodeList.Items {
+		if node.Spec.Unschedulable || !clusterNodeFromNode(&node).Ready {
+			continue
+		}
+		capacity := &nodeCapacity{
+			pool:                node.Labels[tsuruLabelPrefix+provision.LabelNodePool],
+			allocatableCPUMilli: node.Status.Allocatable.Cpu().MilliValue(),
+			allocatableMemory:   node.Status.Allocatable.Memory().Value(),
+			allocatablePods:     node.Status.Allocatable.Pods().Value(),
+		}
+		nodes[node.Name] = capacity
+		result = append(result, capacity)
+	}
+	podList, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			continue
+		}
+		capacity, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		capacity.pods++
+		for _, container := range pod.Spec.Containers {
+			capacity.requestedCPUMilli += container.Resources.Requests.Cpu().MilliValue()
+			capacity.requestedMemory += container.Resources.Requests.Memory().Value()
+		}
+	}
+	return result, nil
+}
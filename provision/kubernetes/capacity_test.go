@@ -0,0 +1,124 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) createCapacityNode(c *check.C, name, pool string, ready, unschedulable bool) {
+	status := apiv1.ConditionTrue
+	if !ready {
+		status = apiv1.ConditionFalse
+	}
+	_, err := s.client.CoreV1().Nodes().Create(context.TODO(), &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"tsuru.io/pool": pool},
+		},
+		Spec: apiv1.NodeSpec{Unschedulable: unschedulable},
+		Status: apiv1.NodeStatus{
+			Allocatable: apiv1.ResourceList{
+				apiv1.ResourceCPU:    resource.MustParse("2"),
+				apiv1.ResourceMemory: resource.MustParse("4Gi"),
+				apiv1.ResourcePods:   resource.MustParse("10"),
+			},
+			Conditions: []apiv1.NodeCondition{
+				{Type: apiv1.NodeReady, Status: status},
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) createCapacityPod(c *check.C, name, node, cpu, memory string) {
+	_, err := s.client.CoreV1().Pods("default").Create(context.TODO(), &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: apiv1.PodSpec{
+			NodeName: node,
+			Containers: []apiv1.Container{
+				{
+					Name: name,
+					Resources: apiv1.ResourceRequirements{
+						Requests: apiv1.ResourceList{
+							apiv1.ResourceCPU:    resource.MustParse(cpu),
+							apiv1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestClusterCapacity(c *check.C) {
+	s.createCapacityNode(c, "n1", "pool1", true, false)
+	s.createCapacityNode(c, "n2", "pool1", true, false)
+	s.createCapacityNode(c, "n3", "pool2", true, false)
+	s.createCapacityNode(c, "n4", "pool2", true, true)
+	s.createCapacityNode(c, "n5", "pool2", false, false)
+	s.createCapacityPod(c, "p1", "n1", "1500m", "1Gi")
+	s.createCapacityPod(c, "p2", "n2", "500m", "3Gi")
+	s.createCapacityPod(c, "p3", "n4", "1", "1Gi")
+	capacity, err := s.p.ClusterCapacity(context.TODO(), s.clusterClient.Cluster, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(capacity, check.DeepEquals, &provTypes.ClusterCapacity{
+		Pools: []provTypes.PoolCapacity{
+			{
+				Pool:                "pool1",
+				Nodes:               2,
+				AllocatableCPUMilli: 4000,
+				AllocatableMemory:   8 * 1024 * 1024 * 1024,
+				RequestedCPUMilli:   2000,
+				RequestedMemory:     4 * 1024 * 1024 * 1024,
+			},
+			{
+				Pool:                "pool2",
+				Nodes:               1,
+				AllocatableCPUMilli: 2000,
+				AllocatableMemory:   4 * 1024 * 1024 * 1024,
+			},
+		},
+	})
+}
+
+func (s *S) TestClusterCapacitySimulation(c *check.C) {
+	s.createCapacityNode(c, "n1", "pool1", true, false)
+	s.createCapacityNode(c, "n2", "pool1", true, false)
+	s.createCapacityPod(c, "p1", "n1", "1500m", "1Gi")
+	s.createCapacityPod(c, "p2", "n2", "500m", "3Gi")
+	capacity, err := s.p.ClusterCapacity(context.TODO(), s.clusterClient.Cluster, &provision.CapacitySimulationOptions{
+		Pool:  "pool1",
+		Plan:  appTypes.Plan{CPUMilli: 500, Memory: 512 * 1024 * 1024},
+		Units: 3,
+	})
+	c.Assert(err, check.IsNil)
+	// n1 fits one unit limited by cpu and n2 fits two units limited by
+	// memory.
+	c.Assert(capacity.Simulation, check.DeepEquals, &provTypes.CapacitySimulation{
+		Pool:             "pool1",
+		Units:            3,
+		UnitCPUMilli:     500,
+		UnitMemory:       512 * 1024 * 1024,
+		SchedulableUnits: 3,
+		Fits:             true,
+	})
+	capacity, err = s.p.ClusterCapacity(context.TODO(), s.clusterClient.Cluster, &provision.CapacitySimulationOptions{
+		Pool:  "pool1",
+		Plan:  appTypes.Plan{CPUMilli: 500, Memory: 512 * 1024 * 1024},
+		Units: 4,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(capacity.Simulation.Fits, check.Equals, false)
+}
@@ -36,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -84,6 +85,12 @@ const (
 	jobEventCreationKey           = "job-event-creation"
 	topologySpreadConstraintsKey  = "topology-spread-constraints"
 	debugContainerImage           = "debug-container-image"
+	egressDefaultDenyKey          = "egress-default-deny"
+	metricsScrapeKey              = "metrics-scrape"
+	namespaceResourceQuotaKey     = "namespace-resource-quota"
+
+	featureProbeDefaults  = "probe-defaults"
+	featureTopologySpread = "topology-spread"
 
 	dialTimeout  = 30 * time.Second
 	tcpKeepAlive = 30 * time.Second
@@ -120,6 +127,17 @@ var (
 		jobEventCreationKey:           "Enable k8s event data tracking cross-referencing with Jobs and send them to tsuru database",
 		topologySpreadConstraintsKey:  "Enable topology spread constraints for apps",
 		debugContainerImage:           "Image used to create debug containers (Ephemeral Containers)",
+		egressDefaultDenyKey:          "Deny outbound traffic from apps to destinations not listed in their app.tsuru.io/egress-allow annotation. This config may be prefixed with `<pool-name>:`.",
+		metricsScrapeKey:              "How metrics endpoints declared in tsuru.yaml are scraped, either `annotations`, adding prometheus.io annotations to units, or `service-monitor`, creating Prometheus operator ServiceMonitors. This config may be prefixed with `<pool-name>:`. Defaults to annotations.",
+		namespaceResourceQuotaKey:     "Create a ResourceQuota and a LimitRange in the namespaces of apps, derived from their plans, unit quotas and the plans allowed in their pools. This config may be prefixed with `<pool-name>:`.",
+
+		provTypes.ClusterFeaturePrefix + featureProbeDefaults:  clusterFeatures[featureProbeDefaults] + " This config may be prefixed with `<pool-name>:`.",
+		provTypes.ClusterFeaturePrefix + featureTopologySpread: clusterFeatures[featureTopologySpread] + " This config may be prefixed with `<pool-name>:`.",
+	}
+
+	clusterFeatures = map[string]string{
+		featureProbeDefaults:  fmt.Sprintf("Healthcheck probes time out after %d seconds unless a timeout is set in tsuru.yaml, instead of 60 seconds.", defaultProbeTimeoutSeconds),
+		featureTopologySpread: "Spread the units of apps across zones when no topology-spread-constraints is configured.",
 	}
 )
 
@@ -151,6 +169,10 @@ var KEDAClientForConfig = func(conf *rest.Config) (kedav1alpha1clientset.Interfa
 	return kedav1alpha1clientset.NewForConfig(conf)
 }
 
+var DynamicClientForConfig = func(conf *rest.Config) (dynamic.Interface, error) {
+	return dynamic.NewForConfig(conf)
+}
+
 type ClusterClient struct {
 	kubernetes.Interface `json:"-" bson:"-"`
 	*provTypes.Cluster
@@ -364,6 +386,21 @@ func (c *ClusterClient) PoolNamespace(pool string) string {
 	return prefix
 }
 
+// TeamNamespace returns the namespace hosting the apps and jobs of the team in
+// the pool. Teams whose isolation profile requires a dedicated namespace get
+// one of their own, derived from the pool namespace.
+func (c *ClusterClient) TeamNamespace(ctx context.Context, pool, team string) (string, error) {
+	ns := c.PoolNamespace(pool)
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, team)
+	if err != nil {
+		return "", err
+	}
+	if profile == nil || !profile.DedicatedNamespace {
+		return ns, nil
+	}
+	return fmt.Sprintf("%s-%s", ns, provision.ValidKubeName(team)), nil
+}
+
 // Namespace returns the namespace to be used by Custom Resources
 func (c *ClusterClient) Namespace() string {
 	namespace := c.configForContext("", namespaceClusterKey)
@@ -598,6 +635,32 @@ func (c *ClusterClient) disablePDB(pool string) bool {
 	return d
 }
 
+func (c *ClusterClient) featureEnabled(pool, feature string) bool {
+	enabled, _ := strconv.ParseBool(c.configForContext(pool, provTypes.ClusterFeaturePrefix+feature))
+	return enabled
+}
+
+func (c *ClusterClient) egressDefaultDeny(pool string) bool {
+	egressDefaultDeny := c.configForContext(pool, egressDefaultDenyKey)
+	if egressDefaultDeny == "" {
+		return false
+	}
+	d, _ := strconv.ParseBool(egressDefaultDeny)
+	return d
+}
+
+func (c *ClusterClient) namespaceResourceQuota(pool string) bool {
+	enabled, _ := strconv.ParseBool(c.configForContext(pool, namespaceResourceQuotaKey))
+	return enabled
+}
+
+func (c *ClusterClient) metricsScrapeMode(pool string) string {
+	if mode := c.configForContext(pool, metricsScrapeKey); mode != "" {
+		return mode
+	}
+	return metricsScrapeAnnotations
+}
+
 func (c *ClusterClient) dockerConfigJSON() string {
 	return c.configForContext("", dockerConfigJSONKey)
 }
@@ -657,6 +657,36 @@ func (s *S) TestClusterDisablePDB(c *check.C) {
 	c.Assert(c4.disablePDB("mypool"), check.Equals, false)
 }
 
+func (s *S) TestClusterFeatureEnabled(c *check.C) {
+	c1, err := NewClusterClient(&provTypes.Cluster{Addresses: []string{"addr1"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(c1.featureEnabled("mypool", featureProbeDefaults), check.Equals, false)
+	c2, err := NewClusterClient(&provTypes.Cluster{Addresses: []string{"addr1"}, CustomData: map[string]string{"feature-probe-defaults": "true", "mypool2:feature-probe-defaults": "false"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(c2.featureEnabled("mypool", featureProbeDefaults), check.Equals, true)
+	c.Assert(c2.featureEnabled("mypool2", featureProbeDefaults), check.Equals, false)
+	c.Assert(c2.featureEnabled("mypool", featureTopologySpread), check.Equals, false)
+	c3, err := NewClusterClient(&provTypes.Cluster{Addresses: []string{"addr1"}, CustomData: map[string]string{"mypool:feature-topology-spread": "true"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(c3.featureEnabled("mypool", featureTopologySpread), check.Equals, true)
+	c.Assert(c3.featureEnabled("mypool2", featureTopologySpread), check.Equals, false)
+}
+
+func (s *S) TestClusterFeatures(c *check.C) {
+	cluster := &provTypes.Cluster{
+		Name:       "c1",
+		CustomData: map[string]string{"feature-probe-defaults": "true", "mypool:feature-topology-spread": "true"},
+	}
+	features := s.p.ClusterFeatures(cluster, "mypool")
+	c.Assert(features, check.DeepEquals, []provTypes.ClusterFeature{
+		{Name: featureProbeDefaults, Description: clusterFeatures[featureProbeDefaults], Enabled: true},
+		{Name: featureTopologySpread, Description: clusterFeatures[featureTopologySpread], Enabled: true},
+	})
+	features = s.p.ClusterFeatures(cluster, "otherpool")
+	c.Assert(features[0].Enabled, check.Equals, true)
+	c.Assert(features[1].Enabled, check.Equals, false)
+}
+
 func (s *S) TestCluster_Registry(c *check.C) {
 	c1, err := NewClusterClient(&provTypes.Cluster{Addresses: []string{"addr1"}})
 	c.Assert(err, check.IsNil)
@@ -23,7 +23,9 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app/image"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/pool"
@@ -32,6 +34,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	routerTypes "github.com/tsuru/tsuru/types/router"
 	appsv1 "k8s.io/api/apps/v1"
@@ -40,6 +43,7 @@ import (
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
@@ -62,6 +66,10 @@ const (
 	backendConfigKey        = "cloud.google.com/backend-config"
 )
 
+// deployPauseCheckInterval is how often a rollout being monitored checks if
+// its deploy was paused.
+var deployPauseCheckInterval = time.Second
+
 func keepAliveSpdyExecutor(config *rest.Config, method string, url *url.URL) (remotecommand.Executor, error) {
 	tlsConfig, err := rest.TLSConfigFor(config)
 	if err != nil {
@@ -281,6 +289,10 @@ func logPodEvents(ctx context.Context, client *ClusterClient, initialResourceVer
 	}, nil
 }
 
+// defaultTopologySpreadConstraints is used by the topology-spread feature
+// when the cluster has no topology-spread-constraints configured.
+const defaultTopologySpreadConstraints = `[{"maxSkew": 1, "topologyKey": "topology.kubernetes.io/zone"}]`
+
 type hcResult struct {
 	liveness  *apiv1.Probe
 	readiness *apiv1.Probe
@@ -303,11 +315,14 @@ func ensureHealthCheckDefaults(hc *provTypes.TsuruYamlHealthcheck) error {
 	return nil
 }
 
-func probesFromHC(hc *provTypes.TsuruYamlHealthcheck, port int) (hcResult, error) {
+func probesFromHC(hc *provTypes.TsuruYamlHealthcheck, port int, probeDefaults bool) (hcResult, error) {
 	var result hcResult
 	if hc == nil || (hc.Path == "" && len(hc.Command) == 0) {
 		return result, nil
 	}
+	if probeDefaults && hc.TimeoutSeconds == 0 {
+		hc.TimeoutSeconds = defaultProbeTimeoutSeconds
+	}
 	if err := ensureHealthCheckDefaults(hc); err != nil {
 		return result, err
 	}
@@ -352,7 +367,15 @@ func ensureNamespaceForApp(ctx context.Context, client *ClusterClient, app *appT
 	if err != nil {
 		return err
 	}
-	return ensureNamespace(ctx, client, ns)
+	err = ensureNamespace(ctx, client, ns)
+	if err != nil {
+		return err
+	}
+	err = ensureAppIsolation(ctx, client, app, ns)
+	if err != nil {
+		return err
+	}
+	return ensureNamespaceQuota(ctx, client, app, ns)
 }
 
 func ensureNamespace(ctx context.Context, client *ClusterClient, namespace string) error {
@@ -373,7 +396,9 @@ func ensureNamespace(ctx context.Context, client *ClusterClient, namespace strin
 	return nil
 }
 
-func ensureServiceAccount(ctx context.Context, client *ClusterClient, name string, labels *provision.LabelSet, namespace string, metadata *appTypes.Metadata) error {
+// serviceAccountAnnotations returns the annotations of service accounts set
+// in the metadata of apps and jobs.
+func serviceAccountAnnotations(metadata *appTypes.Metadata) map[string]string {
 	var annotations map[string]string
 	if metadata != nil {
 		if saAppAnnotationsRaw, ok := metadata.Annotation(AnnotationServiceAccountAppAnnotations); ok {
@@ -387,6 +412,10 @@ func ensureServiceAccount(ctx context.Context, client *ClusterClient, name strin
 			}
 		}
 	}
+	return annotations
+}
+
+func ensureServiceAccount(ctx context.Context, client *ClusterClient, name string, labels *provision.LabelSet, namespace string, annotations map[string]string) error {
 	svcAccount := apiv1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
@@ -425,7 +454,16 @@ func ensureServiceAccountForApp(ctx context.Context, client *ClusterClient, a *a
 		return err
 	}
 	appMeta := provision.GetAppMetadata(a, "")
-	return ensureServiceAccount(ctx, client, serviceAccountNameForApp(a), labels, ns, &appMeta)
+	annotations := serviceAccountAnnotations(&appMeta)
+	// the cloud IAM annotations set through the API take precedence over
+	// the ones in the metadata of the app.
+	for k, v := range a.ServiceAccountAnnotations {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[k] = v
+	}
+	return ensureServiceAccount(ctx, client, serviceAccountNameForApp(a), labels, ns, annotations)
 }
 
 func getClusterNodeSelectorFlag(client *ClusterClient) (bool, error) {
@@ -475,7 +513,39 @@ func defineSelectorAndAffinity(ctx context.Context, a *appTypes.App, client *Clu
 	}).ToNodeByPoolSelector(), affinity, nil
 }
 
-func createAppDeployment(ctx context.Context, client *ClusterClient, depName string, oldDeployment *appsv1.Deployment, a *appTypes.App, process string, version appTypes.AppVersion, replicas int, labels *provision.LabelSet, selector map[string]string) (bool, *appsv1.Deployment, *provision.LabelSet, error) {
+// processNodeSelectorAndTolerations adds the node selector of the process to
+// the one of the pool, which takes precedence so units never leave the nodes
+// of the pool, and returns the tolerations of the process.
+func processNodeSelectorAndTolerations(a *appTypes.App, process string, nodeSelector map[string]string) (map[string]string, []apiv1.Toleration) {
+	for _, p := range a.Processes {
+		if p.Name != process {
+			continue
+		}
+		if len(p.NodeSelector) > 0 {
+			merged := map[string]string{}
+			for k, v := range p.NodeSelector {
+				merged[k] = v
+			}
+			for k, v := range nodeSelector {
+				merged[k] = v
+			}
+			nodeSelector = merged
+		}
+		var tolerations []apiv1.Toleration
+		for _, t := range p.Tolerations {
+			tolerations = append(tolerations, apiv1.Toleration{
+				Key:      t.Key,
+				Operator: apiv1.TolerationOperator(t.Operator),
+				Value:    t.Value,
+				Effect:   apiv1.TaintEffect(t.Effect),
+			})
+		}
+		return nodeSelector, tolerations
+	}
+	return nodeSelector, nil
+}
+
+func createAppDeployment(ctx context.Context, client *ClusterClient, depName string, oldDeployment *appsv1.Deployment, a *appTypes.App, process string, version appTypes.AppVersion, replicas int, labels *provision.LabelSet, selector map[string]string, deployAnnotations map[string]string, planOverride *appTypes.PlanOverride) (bool, *appsv1.Deployment, *provision.LabelSet, error) {
 	realReplicas := int32(replicas)
 	cmdData, err := dockercommon.ContainerCmdsDataFromVersion(version)
 	if err != nil {
@@ -499,6 +569,7 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 		return false, nil, nil, errors.WithStack(err)
 	}
 	var hcData hcResult
+	probeDefaults := client.featureEnabled(a.Pool, featureProbeDefaults)
 	// NOTE: Here is the code that create probes for HEALTHCHECK!
 	if len(yamlData.Processes) > 0 {
 		var healthcheck *provTypes.TsuruYamlHealthcheck
@@ -506,32 +577,21 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 		if err != nil {
 			return false, nil, nil, errors.WithStack(err)
 		}
-		hcData, err = probesFromHC(healthcheck, processPorts[0].TargetPort)
+		hcData, err = probesFromHC(healthcheck, processPorts[0].TargetPort, probeDefaults)
 		if err != nil {
 			return false, nil, nil, err
 		}
 	} else if process == webProcessName && len(processPorts) > 0 {
-		hcData, err = probesFromHC(yamlData.Healthcheck, processPorts[0].TargetPort)
+		hcData, err = probesFromHC(yamlData.Healthcheck, processPorts[0].TargetPort, probeDefaults)
 		if err != nil {
 			return false, nil, nil, err
 		}
 	}
 
-	sleepSec := client.preStopSleepSeconds(a.Pool)
-	terminationGracePeriod := int64(30 + sleepSec)
-
+	shutdown, rollout := processShutdownAndRollout(a, process, yamlData)
 	var lifecycle apiv1.Lifecycle
-	if sleepSec > 0 {
-		lifecycle.PreStop = &apiv1.LifecycleHandler{
-			Exec: &apiv1.ExecAction{
-				// Allow some time for endpoints controller and kube-proxy to
-				// remove the endpoints for the pods before sending SIGTERM to
-				// app. This should reduce the number of failed connections due
-				// to pods stopping while their endpoints are still active.
-				Command: []string{"sh", "-c", fmt.Sprintf("sleep %d || true", sleepSec)},
-			},
-		}
-	}
+	var terminationGracePeriod int64
+	lifecycle.PreStop, terminationGracePeriod = shutdownLifecycle(client, a.Pool, shutdown)
 
 	if yamlData.Hooks != nil && len(yamlData.Hooks.Restart.After) > 0 {
 		hookCmds := []string{
@@ -544,13 +604,16 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 			},
 		}
 	}
-	maxSurge := client.maxSurge(a.Pool)
-	maxUnavailable := client.maxUnavailable(a.Pool)
+	maxSurge, maxUnavailable, err := rolloutStrategy(client, a.Pool, process, rollout)
+	if err != nil {
+		return false, nil, nil, err
+	}
 	dnsConfig := dnsConfigNdots(client, a)
 	nodeSelector, affinity, err := defineSelectorAndAffinity(ctx, a, client)
 	if err != nil {
 		return false, nil, nil, err
 	}
+	nodeSelector, tolerations := processNodeSelectorAndTolerations(a, process, nodeSelector)
 
 	_, uid := dockercommon.UserForContainer()
 	overCommit, err := client.OvercommitFactor(a.Pool)
@@ -574,6 +637,13 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	if err != nil {
 		return false, nil, nil, err
 	}
+	planOverride, err = versionPlanOverride(planOverride, oldDeployment, version)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	if planOverride != nil {
+		plan.MergeOverride(*planOverride)
+	}
 
 	resourceRequirements, err := resourceRequirements(&plan, a.Pool, client, requirementsFactors{
 		overCommit:       overCommit,
@@ -588,10 +658,26 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	if err != nil {
 		return false, nil, nil, err
 	}
+	sidecars, sidecarVolumes, sidecarMounts, err := sidecarContainers(yamlData, process, depName)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	volumes = append(volumes, sidecarVolumes...)
+	mounts = append(mounts, sidecarMounts...)
 	ns, err := client.AppNamespace(ctx, a)
 	if err != nil {
 		return false, nil, nil, err
 	}
+	fileVolumes, fileMounts, err := ensureAppFiles(ctx, client, a, ns)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	volumes = append(volumes, fileVolumes...)
+	mounts = append(mounts, fileMounts...)
+	secretEnvs, err := ensureAppSecrets(ctx, client, a, ns)
+	if err != nil {
+		return false, nil, nil, err
+	}
 	deployImage := version.VersionInfo().DeployImage
 	pullSecrets, err := getImagePullSecrets(ctx, client, ns, deployImage)
 	if err != nil {
@@ -610,6 +696,23 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	for _, annotation := range metadata.Annotations {
 		annotations[annotation.Name] = annotation.Value
 	}
+	depAnnotations := map[string]string{}
+	for k, v := range annotations {
+		depAnnotations[k] = v
+	}
+	for k, v := range deployAnnotations {
+		depAnnotations[k] = v
+	}
+	for k, v := range podMetricsAnnotations(client.metricsScrapeMode(a.Pool), yamlData.Metrics) {
+		annotations[k] = v
+	}
+	if planOverride != nil {
+		data, errMarshal := json.Marshal(planOverride)
+		if errMarshal != nil {
+			return false, nil, nil, errors.WithStack(errMarshal)
+		}
+		depAnnotations[AnnotationVersionPlanOverride] = string(data)
+	}
 
 	depLabels := labels.WithoutVersion().ToLabels()
 	containerPorts := make([]apiv1.ContainerPort, len(processPorts))
@@ -622,10 +725,24 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	}
 	serviceLinks := false
 
-	topologySpreadConstraints, err := topologySpreadConstraints(podLabels, client.TopologySpreadConstraints(a.Pool))
+	topologySpreadRule := client.TopologySpreadConstraints(a.Pool)
+	if topologySpreadRule == "" && client.featureEnabled(a.Pool, featureTopologySpread) {
+		topologySpreadRule = defaultTopologySpreadConstraints
+	}
+	topologySpreadConstraints, err := topologySpreadConstraints(podLabels, topologySpreadRule)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	// the spread of the app or its pool takes precedence over the topology
+	// spread constraints of the cluster.
+	spread, err := spreadForApp(ctx, a)
 	if err != nil {
 		return false, nil, nil, err
 	}
+	if len(spread.TopologySpreadConstraints) > 0 {
+		topologySpreadConstraints = spreadTopologyConstraints(podLabels, spread)
+	}
+	affinity = spreadAffinity(affinity, podLabels, spread)
 
 	routers := a.Routers
 	conditionSet := set.Set{}
@@ -654,7 +771,7 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 			Name:        depName,
 			Namespace:   ns,
 			Labels:      depLabels,
-			Annotations: annotations,
+			Annotations: depAnnotations,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Strategy: appsv1.DeploymentStrategy{
@@ -685,6 +802,7 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 					},
 					RestartPolicy:  apiv1.RestartPolicyAlways,
 					NodeSelector:   nodeSelector,
+					Tolerations:    tolerations,
 					Affinity:       affinity,
 					Volumes:        volumes,
 					Subdomain:      headlessServiceName(a, process),
@@ -695,7 +813,7 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 							Name:           depName,
 							Image:          deployImage,
 							Command:        cmds,
-							Env:            appEnvs(a, process, version),
+							Env:            append(appEnvs(a, process, version), secretEnvs...),
 							ReadinessProbe: hcData.readiness,
 							LivenessProbe:  hcData.liveness,
 							Resources:      resourceRequirements,
@@ -708,6 +826,17 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 			},
 		},
 	}
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.InitContainers, err = initContainers(a, process, podSpec.Containers[0], sidecars)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	podSpec.Containers = append(podSpec.Containers, sidecars...)
+	securityContext, err := securityContextForPool(ctx, a.Pool, a.SecurityContext)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	applySecurityContext(podSpec, securityContext)
 	var newDep *appsv1.Deployment
 	if oldDeployment == nil {
 		newDep, err = client.AppsV1().Deployments(ns).Create(ctx, &deployment, metav1.CreateOptions{})
@@ -722,6 +851,25 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	return true, newDep, labels, errors.WithStack(err)
 }
 
+// versionPlanOverride returns the plan override of the deploy or, when units
+// of the same version are recreated later, the one stored in the existing
+// deployment.
+func versionPlanOverride(planOverride *appTypes.PlanOverride, oldDeployment *appsv1.Deployment, version appTypes.AppVersion) (*appTypes.PlanOverride, error) {
+	if planOverride != nil || oldDeployment == nil {
+		return planOverride, nil
+	}
+	data, ok := oldDeployment.Annotations[AnnotationVersionPlanOverride]
+	if !ok || labelSetFromMeta(&oldDeployment.Spec.Template.ObjectMeta).AppVersion() != version.Version() {
+		return nil, nil
+	}
+	var result appTypes.PlanOverride
+	err := json.Unmarshal([]byte(data), &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation", AnnotationVersionPlanOverride)
+	}
+	return &result, nil
+}
+
 func deploymentUnchanged(deployment *appsv1.Deployment, oldDeployment *appsv1.Deployment, realReplicas int32) bool {
 	return (deployment.ObjectMeta.Name == oldDeployment.ObjectMeta.Name &&
 		deployment.ObjectMeta.Namespace == oldDeployment.ObjectMeta.Namespace &&
@@ -757,6 +905,7 @@ func appEnvs(a *appTypes.App, process string, version appTypes.AppVersion) []api
 type serviceManager struct {
 	client *ClusterClient
 	writer io.Writer
+	event  *event.Event
 }
 
 var _ servicecommon.ServiceManager = &serviceManager{}
@@ -844,6 +993,11 @@ func (m *serviceManager) CleanupServices(ctx context.Context, a *appTypes.App, d
 		}
 	}
 
+	err = cleanupAppObjects(ctx, m.client, a)
+	if err != nil {
+		multiErrors.Add(err)
+	}
+
 	return multiErrors.ToError()
 }
 
@@ -972,7 +1126,7 @@ func formatEvtMessage(msg watch.Event, showSub bool) string {
 	)
 }
 
-func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.Deployment, a *appTypes.App, processName string, w io.Writer, evtResourceVersion string, version appTypes.AppVersion) (string, error) {
+func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.Deployment, a *appTypes.App, processName string, w io.Writer, evtResourceVersion string, version appTypes.AppVersion, evt *event.Event) (string, error) {
 	revision := dep.Annotations[replicaDepRevision]
 	ns, err := client.AppNamespace(ctx, a)
 	if err != nil {
@@ -1040,6 +1194,18 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 	var healthcheckTimeout <-chan time.Time
 	t0 := time.Now()
 	largestReady := int32(0)
+	var lastPauseCheck time.Time
+	pausePoints := tsuruYamlData.PausePoints(processName)
+	resetAfterPause := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(kubeConf.DeploymentProgressTimeout)
+		healthcheckTimeout = nil
+	}
 	for {
 		var specReplicas int32
 		if dep.Spec.Replicas != nil {
@@ -1065,6 +1231,7 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 		readyUnits := dep.Status.UpdatedReplicas - dep.Status.UnavailableReplicas
 		if oldReadyUnits != readyUnits && readyUnits >= 0 {
 			fmt.Fprintf(w, " ---> %d of %d new units ready\n", readyUnits, specReplicas)
+			setUnitsProgress(ctx, evt, processName, int(readyUnits), int(specReplicas))
 		}
 		if readyUnits > largestReady {
 			largestReady = readyUnits
@@ -1084,6 +1251,35 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 			dep.Status.Replicas == specReplicas {
 			break
 		}
+		var pausePoint *provTypes.TsuruYamlPausePoint
+		for len(pausePoints) > 0 && readyUnits >= int32(pausePoints[0].Units) {
+			pausePoint = &pausePoints[0]
+			pausePoints = pausePoints[1:]
+		}
+		if evt != nil && pausePoint != nil {
+			checkpoint := fmt.Sprintf("pause point of process %s, %d of %d units ready", processName, readyUnits, specReplicas)
+			err = waitPausePoint(ctx, client, dep, evt, *pausePoint, checkpoint)
+			if err != nil {
+				return revision, err
+			}
+			resetAfterPause()
+		}
+		if evt != nil && time.Since(lastPauseCheck) >= deployPauseCheckInterval {
+			lastPauseCheck = time.Now()
+			var paused bool
+			paused, err = evt.PauseRequested(ctx)
+			if err != nil {
+				log.Errorf("unable to check if deploy of app %q was paused: %v", a.Name, err)
+			}
+			if paused {
+				checkpoint := fmt.Sprintf("units of process %s partially updated, %d of %d ready", processName, readyUnits, specReplicas)
+				err = pauseDeploymentRollout(ctx, client, dep, evt, checkpoint)
+				if err != nil {
+					return revision, err
+				}
+				resetAfterPause()
+			}
+		}
 		select {
 		case <-time.After(100 * time.Millisecond):
 		case msg, isOpen := <-watchPodCh:
@@ -1131,6 +1327,63 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 	return revision, nil
 }
 
+// setUnitsProgress reports the rollout of units of the process in the deploy
+// event, between the image and the units ready deploy progress.
+func setUnitsProgress(ctx context.Context, evt *event.Event, processName string, ready, total int) {
+	percent := provision.DeployProgressUnitsReady
+	if total > 0 && ready < total {
+		percent = provision.DeployProgressImageReady + (provision.DeployProgressUnitsReady-provision.DeployProgressImageReady)*ready/total
+	}
+	err := evt.SetProgress(ctx, eventTypes.ProgressInfo{
+		Step:    fmt.Sprintf("units of process %s rolling", processName),
+		Percent: percent,
+		Current: ready,
+		Total:   total,
+	})
+	if err != nil {
+		log.Errorf("unable to set progress of deploy: %v", err)
+	}
+}
+
+// pauseDeploymentRollout halts the rollout of the deployment, keeping units
+// already updated running alongside the old ones, until the deploy event is
+// resumed.
+func pauseDeploymentRollout(ctx context.Context, client *ClusterClient, dep *appsv1.Deployment, evt *event.Event, checkpoint string) error {
+	_, err := client.AppsV1().Deployments(dep.Namespace).Patch(ctx, dep.Name, types.MergePatchType, []byte(`{"spec":{"paused":true}}`), metav1.PatchOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = evt.Checkpoint(ctx, checkpoint)
+	// the rollout must be resumed even when the deploy is canceled while
+	// paused, otherwise rolling back would never finish.
+	_, resumeErr := client.AppsV1().Deployments(dep.Namespace).Patch(tsuruNet.WithoutCancel(ctx), dep.Name, types.MergePatchType, []byte(`{"spec":{"paused":false}}`), metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(resumeErr)
+}
+
+// waitPausePoint pauses the deploy at a pause point defined in tsuru.yaml and
+// fails it when it's not continued within the pause point timeout.
+func waitPausePoint(ctx context.Context, client *ClusterClient, dep *appsv1.Deployment, evt *event.Event, point provTypes.TsuruYamlPausePoint, checkpoint string) error {
+	timeout := point.Timeout()
+	err := evt.TryPause(ctx, fmt.Sprintf("waiting to be continued within %s", timeout), "tsuru.yaml")
+	if err != nil && err != event.ErrAlreadyPaused {
+		return err
+	}
+	pauseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = pauseDeploymentRollout(pauseCtx, client, dep, evt, checkpoint)
+	if err != nil && pauseCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		// the pause is lifted so that rolling back isn't held by it.
+		if resumeErr := evt.Resume(ctx); resumeErr != nil {
+			log.Errorf("unable to resume deploy event %s: %v", evt.UniqueID.Hex(), resumeErr)
+		}
+		return errors.Errorf("deploy was not continued within %s at %s", timeout, checkpoint)
+	}
+	return err
+}
+
 func (m *serviceManager) DeployService(ctx context.Context, opts servicecommon.DeployServiceOpts) error {
 	if m.writer == nil {
 		m.writer = io.Discard
@@ -1196,14 +1449,14 @@ func (m *serviceManager) DeployService(ctx context.Context, opts servicecommon.D
 		}
 	}
 
-	changed, newDep, labels, err := createAppDeployment(ctx, m.client, depArgs.name, oldDep, opts.App, opts.ProcessName, opts.Version, opts.Replicas, opts.Labels, depArgs.selector)
+	changed, newDep, labels, err := createAppDeployment(ctx, m.client, depArgs.name, oldDep, opts.App, opts.ProcessName, opts.Version, opts.Replicas, opts.Labels, depArgs.selector, opts.Annotations, opts.PlanOverride)
 	if err != nil {
 		return err
 	}
 
 	if changed {
 		var newRevision string
-		newRevision, err = monitorDeployment(ctx, m.client, newDep, opts.App, opts.ProcessName, m.writer, events.ResourceVersion, opts.Version)
+		newRevision, err = monitorDeployment(ctx, m.client, newDep, opts.App, opts.ProcessName, m.writer, events.ResourceVersion, opts.Version, m.event)
 		if err != nil {
 			// We should only rollback if the updated deployment is a new revision.
 			var rollbackErr error
@@ -1258,11 +1511,16 @@ func (m *serviceManager) DeployService(ctx context.Context, opts servicecommon.D
 		return errors.Wrap(err, "unable to ensure auto scale is configured")
 	}
 
-	err = ensurePDB(ctx, m.client, opts.App, opts.ProcessName)
+	err = ensurePDB(ctx, m.client, opts.App, opts.ProcessName, opts.Version)
 	if err != nil {
 		return errors.Wrap(err, "unable to ensure pod disruption budget")
 	}
 
+	err = ensureEgressPolicy(ctx, m.client, opts.App)
+	if err != nil {
+		return errors.Wrap(err, "unable to ensure egress network policy")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,61 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	deployHookBefore = "before"
+	deployHookAfter  = "after"
+)
+
+// runDeployHooks runs the deploy hooks of the given stage declared in the
+// tsuru.yaml of the deployed version in an isolated unit of that version. The
+// output is written to the deploy log and a non-zero exit fails the deploy.
+func runDeployHooks(ctx context.Context, client *ClusterClient, args provision.DeployArgs, stage string) error {
+	yamlData, err := args.Version.TsuruYamlData()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if yamlData.Hooks == nil {
+		return nil
+	}
+	cmds := yamlData.Hooks.Deploy.Before
+	if stage == deployHookAfter {
+		cmds = yamlData.Hooks.Deploy.After
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	var w io.Writer = io.Discard
+	if args.Event != nil {
+		w = args.Event
+	}
+	fmt.Fprintf(w, "\n---- Running %s deploy hooks ----\n", stage)
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, " ---> Running %q\n", cmd)
+	}
+	err = runIsolatedCmdPod(ctx, client, execOpts{
+		client:       client,
+		app:          args.App,
+		version:      args.Version,
+		cmds:         []string{"/bin/sh", "-lc", "[ -d /home/application/current ] && cd /home/application/current; " + strings.Join(cmds, " && ")},
+		eventsOutput: w,
+		stdout:       w,
+		stderr:       w,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "%s deploy hooks failed", stage)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deployStrategy rolls out the units of the version being deployed,
+// replacing the units of the versions currently deployed.
+type deployStrategy interface {
+	deploy(ctx context.Context, manager *serviceManager, args provision.DeployArgs) error
+}
+
+func deployStrategyFor(name string) (deployStrategy, error) {
+	switch name {
+	case "", provision.DeployStrategyRolling:
+		return &rollingDeployStrategy{}, nil
+	case provision.DeployStrategyBlueGreen:
+		return &blueGreenDeployStrategy{}, nil
+	}
+	return nil, errors.Errorf("invalid deploy strategy %q", name)
+}
+
+// rollingDeployStrategy updates the deployments of the current version in
+// place, unless versions are preserved, in which case the new version gets
+// deployments of its own.
+type rollingDeployStrategy struct{}
+
+func (s *rollingDeployStrategy) deploy(ctx context.Context, manager *serviceManager, args provision.DeployArgs) error {
+	var oldVersionNumber int
+	if !args.PreserveVersions {
+		var err error
+		oldVersionNumber, err = baseVersionForApp(ctx, manager.client, args.App)
+		if err != nil {
+			return err
+		}
+	}
+	return servicecommon.RunServicePipeline(ctx, manager, oldVersionNumber, args, nil)
+}
+
+// blueGreenDeployStrategy deploys the new version alongside the current
+// ones without routing traffic to it. The deploy finishes once its units are
+// ready, leaving the version pending until ConfirmDeploy switches the
+// traffic to it or AbortDeploy removes it.
+type blueGreenDeployStrategy struct{}
+
+func (s *blueGreenDeployStrategy) deploy(ctx context.Context, manager *serviceManager, args provision.DeployArgs) error {
+	if args.OverrideVersions {
+		return errors.New("override-versions can't be used in blue-green deploys")
+	}
+	pending, _, err := blueGreenVersions(ctx, manager.client, args.App)
+	if err == nil {
+		return errors.Errorf("version %d is deployed without receiving traffic, it must be confirmed or aborted before a new blue-green deploy", pending)
+	}
+	if err != provision.ErrNoPendingDeploy {
+		return err
+	}
+	args.PreserveVersions = true
+	err = servicecommon.RunServicePipeline(ctx, manager, 0, args, nil)
+	if err != nil {
+		return err
+	}
+	pending, current, err := blueGreenVersions(ctx, manager.client, args.App)
+	if err == provision.ErrNoPendingDeploy {
+		// the first deploy of the app has no traffic to switch.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(manager.writer, "\n---- Version %d is ready, traffic is still routed to versions %v ----\n", pending, current)
+	fmt.Fprint(manager.writer, " ---> Confirm the deploy to switch the traffic or abort it to remove the new version\n")
+	return nil
+}
+
+// blueGreenVersions returns the latest version deployed without receiving
+// traffic, along with the versions currently receiving it.
+func blueGreenVersions(ctx context.Context, client *ClusterClient, a *appTypes.App) (int, []int, error) {
+	depsData, err := deploymentsDataForApp(ctx, client, a)
+	if err != nil {
+		return 0, nil, err
+	}
+	var pending int
+	var current []int
+	for version, deps := range depsData.versioned {
+		if len(deps) == 0 {
+			continue
+		}
+		if deps[0].isRoutable {
+			current = append(current, version)
+		} else if version > pending {
+			pending = version
+		}
+	}
+	if pending == 0 || len(current) == 0 {
+		return 0, nil, provision.ErrNoPendingDeploy
+	}
+	sort.Ints(current)
+	return pending, current, nil
+}
+
+func (p *kubernetesProvisioner) ConfirmDeploy(ctx context.Context, a *appTypes.App, w io.Writer) (int, error) {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return 0, err
+	}
+	pending, current, err := blueGreenVersions(ctx, client, a)
+	if err != nil {
+		return 0, err
+	}
+	depsData, err := deploymentsDataForApp(ctx, client, a)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(w, "\n---- Switching traffic to version %d ----\n", pending)
+	// The services of each process are pointed to the units of the pending
+	// version at once, before routable labels are swapped unit by unit.
+	for _, depData := range depsData.versioned[pending] {
+		err = pointBaseService(ctx, client, a, depData.process, pending)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, depData := range depsData.versioned[pending] {
+		err = toggleRoutableDeployment(ctx, client, depData.dep, true)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, version := range current {
+		for _, depData := range depsData.versioned[version] {
+			err = toggleRoutableDeployment(ctx, client, depData.dep, false)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n---- Removing versions %v ----\n", current)
+	for _, version := range current {
+		err = removeVersionDeployments(ctx, client, a, depsData.versioned[version], w)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, depData := range depsData.versioned[pending] {
+		err = pointBaseService(ctx, client, a, depData.process, 0)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return pending, ensureAutoScale(ctx, client, a, "")
+}
+
+func (p *kubernetesProvisioner) AbortDeploy(ctx context.Context, a *appTypes.App, w io.Writer) (int, error) {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return 0, err
+	}
+	pending, _, err := blueGreenVersions(ctx, client, a)
+	if err != nil {
+		return 0, err
+	}
+	depsData, err := deploymentsDataForApp(ctx, client, a)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(w, "\n---- Removing version %d ----\n", pending)
+	return pending, removeVersionDeployments(ctx, client, a, depsData.versioned[pending], w)
+}
+
+func removeVersionDeployments(ctx context.Context, client *ClusterClient, a *appTypes.App, deps []deploymentInfo, w io.Writer) error {
+	for _, depData := range deps {
+		err := cleanupServices(ctx, client, a, depData.process, depData.version)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, " ---> Cleaning up deployment %s\n", depData.dep.Name)
+		err = cleanupSingleDeployment(ctx, client, depData.dep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pointBaseService makes the service of the process, used by routers,
+// select only the units of the given version, along with its ports. A zero
+// version restores the selection of routable units.
+func pointBaseService(ctx context.Context, client *ClusterClient, a *appTypes.App, process string, version int) error {
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return err
+	}
+	svc, err := client.CoreV1().Services(ns).Get(ctx, serviceNameForAppBase(a, process), metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	routableKey := tsuruLabelPrefix + "is-routable"
+	versionKey := tsuruLabelPrefix + provision.LabelAppVersion
+	if version == 0 {
+		delete(svc.Spec.Selector, versionKey)
+		svc.Spec.Selector[routableKey] = strconv.FormatBool(true)
+	} else {
+		versionSvc, err := client.CoreV1().Services(ns).Get(ctx, serviceNameForApp(a, process, version), metav1.GetOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+		if err == nil {
+			svc.Spec.Ports = versionSvc.Spec.Ports
+		}
+		delete(svc.Spec.Selector, routableKey)
+		svc.Spec.Selector[versionKey] = strconv.Itoa(version)
+	}
+	_, err = client.CoreV1().Services(ns).Update(ctx, svc, metav1.UpdateOptions{})
+	return errors.WithStack(err)
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"io"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) deployBlueGreen(c *check.C, a *appTypes.App, wait func()) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:      eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:        permission.PermAppDeploy,
+		Owner:       s.token,
+		Allowed:     event.Allowed(permission.PermAppDeploy),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	customData := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "run mycmd arg1",
+		},
+	}
+	version1 := newCommittedVersion(c, a, customData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version1, Event: evt, Strategy: provision.DeployStrategyBlueGreen})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	wait()
+	version2 := newCommittedVersion(c, a, customData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version2, Event: evt, Strategy: provision.DeployStrategyBlueGreen})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	wait()
+	pending, current, err := blueGreenVersions(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.Equals, 2)
+	c.Assert(current, check.DeepEquals, []int{1})
+	version3 := newCommittedVersion(c, a, customData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version3, Event: evt, Strategy: provision.DeployStrategyBlueGreen})
+	c.Assert(err, check.ErrorMatches, `version 2 is deployed without receiving traffic, it must be confirmed or aborted before a new blue-green deploy`)
+}
+
+func (s *S) TestConfirmBlueGreenDeploy(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	s.deployBlueGreen(c, a, wait)
+	version, err := s.p.ConfirmDeploy(context.TODO(), a, io.Discard)
+	c.Assert(err, check.IsNil)
+	c.Assert(version, check.Equals, 2)
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	deps, err := s.client.AppsV1().Deployments(ns).List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(deps.Items, check.HasLen, 1)
+	c.Assert(deps.Items[0].Name, check.Equals, "myapp-web-v2")
+	c.Assert(deps.Items[0].Spec.Template.Labels["tsuru.io/is-routable"], check.Equals, "true")
+	svc, err := s.client.CoreV1().Services(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(svc.Spec.Selector["tsuru.io/is-routable"], check.Equals, "true")
+	c.Assert(svc.Spec.Selector["tsuru.io/app-version"], check.Equals, "")
+	_, err = s.client.CoreV1().Services(ns).Get(context.TODO(), "myapp-web-v1", metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+	_, err = s.p.ConfirmDeploy(context.TODO(), a, io.Discard)
+	c.Assert(err, check.Equals, provision.ErrNoPendingDeploy)
+}
+
+func (s *S) TestAbortBlueGreenDeploy(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	s.deployBlueGreen(c, a, wait)
+	version, err := s.p.AbortDeploy(context.TODO(), a, io.Discard)
+	c.Assert(err, check.IsNil)
+	c.Assert(version, check.Equals, 2)
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	deps, err := s.client.AppsV1().Deployments(ns).List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(deps.Items, check.HasLen, 1)
+	c.Assert(deps.Items[0].Name, check.Equals, "myapp-web")
+	_, err = s.client.CoreV1().Services(ns).Get(context.TODO(), "myapp-web-v2", metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+	_, err = s.p.AbortDeploy(context.TODO(), a, io.Discard)
+	c.Assert(err, check.Equals, provision.ErrNoPendingDeploy)
+}
+
+func (s *S) TestDeployInvalidStrategy(c *check.C) {
+	a, _, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{"web": "run mycmd arg1"},
+	})
+	_, err := s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Strategy: "canary"})
+	c.Assert(err, check.ErrorMatches, `invalid deploy strategy "canary"`)
+}
@@ -45,6 +45,37 @@ import (
 	"k8s.io/utils/ptr"
 )
 
+func (s *S) TestServiceManagerDeployServiceWithAnnotations(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+		Annotations: map[string]string{
+			"tsuru.io/rollback-reason": "broken release",
+		},
+	}, nil)
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Annotations, check.DeepEquals, map[string]string{
+		"tsuru.io/rollback-reason": "broken release",
+	})
+	c.Assert(dep.Spec.Template.Annotations, check.DeepEquals, map[string]string{})
+}
+
 func (s *S) TestServiceManagerDeployService(c *check.C) {
 	waitDep := s.mock.DeploymentReactions(c)
 	defer waitDep()
@@ -444,6 +475,50 @@ func (s *S) TestServiceManagerDeployServiceWithCustomServiceAccountAnnotationsWi
 	})
 }
 
+func (s *S) TestServiceManagerDeployServiceWithServiceAccountIAMAnnotations(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Metadata: appTypes.Metadata{
+			Annotations: []appTypes.MetadataItem{
+				{
+					Name:  AnnotationServiceAccountAppAnnotations,
+					Value: `{"a1": "v1", "eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/other"}`,
+				},
+			},
+		},
+		ServiceAccountAnnotations: map[string]string{
+			"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/myapp",
+		},
+	}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	nsName, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	account, err := s.client.CoreV1().ServiceAccounts(nsName).Get(context.TODO(), "app-myapp", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(account.Annotations, check.DeepEquals, map[string]string{
+		"a1":                         "v1",
+		"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/myapp",
+	})
+}
+
 func (s *S) TestServiceManagerDeployServiceWithCustomAnnotationsFromDeployment(c *check.C) {
 	waitDep := s.mock.DeploymentReactions(c)
 	defer waitDep()
@@ -535,6 +610,48 @@ func (s *S) TestServiceManagerDeployServiceWithNodeAffinity(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (s *S) TestServiceManagerDeployProcessNodeSelectorAndTolerations(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Processes: []appTypes.Process{
+		{
+			Name:         "p1",
+			NodeSelector: map[string]string{"lifecycle": "spot", "tsuru.io/pool": "other"},
+			Tolerations:  []appTypes.Toleration{{Key: "spot", Operator: "Exists", Effect: "NoSchedule"}},
+		},
+	}}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+			"p2": "cmd2",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+		"p2": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.NodeSelector, check.DeepEquals, map[string]string{"tsuru.io/pool": "test-default", "lifecycle": "spot"})
+	c.Assert(dep.Spec.Template.Spec.Tolerations, check.DeepEquals, []apiv1.Toleration{
+		{Key: "spot", Operator: apiv1.TolerationOpExists, Effect: apiv1.TaintEffectNoSchedule},
+	})
+	dep, err = s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p2", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.NodeSelector, check.DeepEquals, map[string]string{"tsuru.io/pool": "test-default"})
+	c.Assert(dep.Spec.Template.Spec.Tolerations, check.IsNil)
+}
+
 func (s *S) TestServiceManagerDeployServiceWithPodAffinity(c *check.C) {
 	waitDep := s.mock.DeploymentReactions(c)
 	defer waitDep()
@@ -2665,6 +2782,53 @@ func (s *S) TestServiceManagerDeployTopologySpreadConstraintEnable(c *check.C) {
 	c.Assert(dep.Spec.Template.Spec.TopologySpreadConstraints, check.DeepEquals, topologySpreadConstraints)
 }
 
+func (s *S) TestServiceManagerDeployTopologySpreadFeature(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	s.clusterClient.CustomData[provTypes.ClusterFeaturePrefix+featureTopologySpread] = "true"
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.TopologySpreadConstraints, check.DeepEquals, []apiv1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: apiv1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"tsuru.io/app-name": "myapp", "tsuru.io/app-process": "p1", "tsuru.io/app-version": "1"}},
+		},
+	})
+}
+
+func (s *S) TestProbesFromHCProbeDefaults(c *check.C) {
+	result, err := probesFromHC(&provTypes.TsuruYamlHealthcheck{Path: "/hc"}, 8888, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.readiness.TimeoutSeconds, check.Equals, int32(defaultProbeTimeoutSeconds))
+	result, err = probesFromHC(&provTypes.TsuruYamlHealthcheck{Path: "/hc", TimeoutSeconds: 20}, 8888, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.readiness.TimeoutSeconds, check.Equals, int32(20))
+	result, err = probesFromHC(&provTypes.TsuruYamlHealthcheck{Path: "/hc"}, 8888, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.readiness.TimeoutSeconds, check.Equals, int32(60))
+}
+
 func (s *S) TestServiceManagerDeployServiceWithPreserveVersions(c *check.C) {
 	waitDep := s.mock.DeploymentReactions(c)
 	defer waitDep()
@@ -4448,3 +4612,38 @@ func (s *S) TestGetImagePullSecrets(c *check.C) {
 		}
 	}
 }
+
+func (s *S) TestVersionPlanOverride(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulVersion(c, a, nil)
+	memory := int64(256 * 1024 * 1024)
+	override := &appTypes.PlanOverride{Memory: &memory}
+	oldDep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{AnnotationVersionPlanOverride: `{"memory":268435456}`},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"tsuru.io/app-version": strconv.Itoa(version.Version())},
+				},
+			},
+		},
+	}
+	result, err := versionPlanOverride(nil, oldDep, version)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, override)
+	cpu := 500
+	result, err = versionPlanOverride(&appTypes.PlanOverride{CPUMilli: &cpu}, oldDep, version)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &appTypes.PlanOverride{CPUMilli: &cpu})
+	oldDep.Spec.Template.Labels["tsuru.io/app-version"] = strconv.Itoa(version.Version() + 1)
+	result, err = versionPlanOverride(nil, oldDep, version)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.IsNil)
+	result, err = versionPlanOverride(nil, nil, version)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.IsNil)
+}
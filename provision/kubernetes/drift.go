@@ -0,0 +1,334 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// driftDeployment is the live state of an app deployment relevant to drift
+// detection.
+type driftDeployment struct {
+	name     string
+	process  string
+	version  int
+	image    string
+	replicas int
+	stopped  bool
+	routable bool
+}
+
+// driftVersion is the desired state of an app version, as stored in tsuru.
+type driftVersion struct {
+	image string
+	// processes maps each process of the version to whether it exposes
+	// ports, and thus requires a service.
+	processes map[string]bool
+}
+
+// driftRepair is a drift on the units of a process along with the
+// arguments needed to deploy the process again.
+type driftRepair struct {
+	provision.Drift
+	version  int
+	replicas int
+	missing  bool
+}
+
+func (p *kubernetesProvisioner) Drift(ctx context.Context, a *appTypes.App) ([]provision.Drift, error) {
+	return p.reconcileApp(ctx, a, nil, false)
+}
+
+func (p *kubernetesProvisioner) RepairDrift(ctx context.Context, a *appTypes.App, w io.Writer) ([]provision.Drift, error) {
+	if w == nil {
+		w = io.Discard
+	}
+	return p.reconcileApp(ctx, a, w, true)
+}
+
+func (p *kubernetesProvisioner) reconcileApp(ctx context.Context, a *appTypes.App, w io.Writer, repair bool) ([]provision.Drift, error) {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	depData, err := deploymentsDataForApp(ctx, client, a)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := servicemanager.AppVersion.AppVersions(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	liveVersions := map[int]struct{}{}
+	var deps []driftDeployment
+	for versionNumber, infos := range depData.versioned {
+		liveVersions[versionNumber] = struct{}{}
+		for _, di := range infos {
+			deps = append(deps, driftDeploymentFromInfo(di))
+		}
+	}
+	if len(liveVersions) == 0 && versions.LastSuccessfulVersion != 0 {
+		liveVersions[versions.LastSuccessfulVersion] = struct{}{}
+	}
+	appVersions := map[int]appTypes.AppVersion{}
+	expected := map[int]driftVersion{}
+	for versionNumber := range liveVersions {
+		info, ok := versions.Versions[versionNumber]
+		if !ok {
+			continue
+		}
+		var version appTypes.AppVersion
+		version, err = servicemanager.AppVersion.AppVersionFromInfo(ctx, a, info)
+		if err != nil {
+			return nil, err
+		}
+		var dv driftVersion
+		dv, err = driftVersionFromAppVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		appVersions[versionNumber] = version
+		expected[versionNumber] = dv
+	}
+	svcs, err := allServicesForApp(ctx, client, a)
+	if err != nil {
+		return nil, err
+	}
+	services := map[string]bool{}
+	for _, svc := range svcs {
+		services[svc.Name] = true
+	}
+	repairs := deploymentDrifts(a, expected, deps, services)
+	volumeDrifts, err := volumeDriftsForApp(ctx, client, a, repair)
+	if err != nil {
+		return nil, err
+	}
+	drifts := make([]provision.Drift, 0, len(repairs)+len(volumeDrifts))
+	if repair {
+		m := &serviceManager{client: client, writer: w}
+		repaired := map[string]error{}
+		for _, r := range repairs {
+			key := fmt.Sprintf("%s/%d", r.Process, r.version)
+			repairErr, done := repaired[key]
+			if !done && r.version != 0 {
+				fmt.Fprintf(w, " ---> Repairing %s drift of app %q: %s\n", r.Kind, a.Name, r.Message)
+				repairErr = repairProcessDrift(ctx, m, a, appVersions[r.version], r, len(liveVersions) > 1)
+				repaired[key] = repairErr
+				done = true
+			}
+			if repairErr != nil {
+				r.Error = repairErr.Error()
+			}
+			r.Repaired = done && repairErr == nil
+			drifts = append(drifts, r.Drift)
+		}
+	} else {
+		for _, r := range repairs {
+			drifts = append(drifts, r.Drift)
+		}
+	}
+	return append(drifts, volumeDrifts...), nil
+}
+
+func driftDeploymentFromInfo(di deploymentInfo) driftDeployment {
+	dd := driftDeployment{
+		name:     di.dep.Name,
+		process:  di.process,
+		version:  di.version,
+		replicas: di.replicas,
+		routable: di.isRoutable,
+		stopped:  labelSetFromMeta(&di.dep.ObjectMeta).IsStopped(),
+	}
+	if containers := di.dep.Spec.Template.Spec.Containers; len(containers) > 0 {
+		dd.image = containers[0].Image
+	}
+	return dd
+}
+
+func driftVersionFromAppVersion(version appTypes.AppVersion) (driftVersion, error) {
+	processes, err := version.Processes()
+	if err != nil {
+		return driftVersion{}, err
+	}
+	dv := driftVersion{
+		image:     version.VersionInfo().DeployImage,
+		processes: map[string]bool{},
+	}
+	for process := range processes {
+		ports, err := loadServicePorts(version, process)
+		if err != nil {
+			return driftVersion{}, err
+		}
+		dv.processes[process] = len(ports) > 0
+	}
+	return dv, nil
+}
+
+// deploymentDrifts compares the live deployments and services of an app with
+// the versions expected to be running. Drifts on deployments whose version
+// is unknown to tsuru are reported with version zero, as they cannot be
+// repaired.
+func deploymentDrifts(a *appTypes.App, expected map[int]driftVersion, deps []driftDeployment, services map[string]bool) []driftRepair {
+	var result []driftRepair
+	found := map[string]bool{}
+	for _, dep := range deps {
+		found[fmt.Sprintf("%s/%d", dep.process, dep.version)] = true
+		newRepair := func(kind, msg string, replicas int) driftRepair {
+			return driftRepair{
+				Drift: provision.Drift{
+					App:     a.Name,
+					Kind:    kind,
+					Name:    dep.name,
+					Process: dep.process,
+					Message: msg,
+				},
+				version:  dep.version,
+				replicas: replicas,
+			}
+		}
+		version, ok := expected[dep.version]
+		if !ok {
+			r := newRepair(provision.DriftKindDeployment, fmt.Sprintf("deployment %s runs version %d, which is unknown to tsuru", dep.name, dep.version), dep.replicas)
+			r.version = 0
+			result = append(result, r)
+			continue
+		}
+		if _, ok = version.processes[dep.process]; !ok {
+			r := newRepair(provision.DriftKindDeployment, fmt.Sprintf("deployment %s runs process %s, which is not part of version %d", dep.name, dep.process, dep.version), dep.replicas)
+			r.version = 0
+			result = append(result, r)
+			continue
+		}
+		if dep.image != version.image {
+			result = append(result, newRepair(provision.DriftKindDeployment, fmt.Sprintf("deployment %s runs image %q, expected %q", dep.name, dep.image, version.image), dep.replicas))
+		}
+		if dep.stopped && dep.replicas > 0 {
+			result = append(result, newRepair(provision.DriftKindScale, fmt.Sprintf("process %s is stopped but deployment %s has %d replicas", dep.process, dep.name, dep.replicas), 0))
+		}
+		if dep.routable && version.processes[dep.process] {
+			svcName := serviceNameForAppBase(a, dep.process)
+			if !services[svcName] {
+				r := newRepair(provision.DriftKindService, fmt.Sprintf("service %s of process %s not found", svcName, dep.process), dep.replicas)
+				r.Name = svcName
+				result = append(result, r)
+			}
+		}
+	}
+	versionNumbers := make([]int, 0, len(expected))
+	for v := range expected {
+		versionNumbers = append(versionNumbers, v)
+	}
+	sort.Ints(versionNumbers)
+	for _, v := range versionNumbers {
+		processes := make([]string, 0, len(expected[v].processes))
+		for process := range expected[v].processes {
+			processes = append(processes, process)
+		}
+		sort.Strings(processes)
+		for _, process := range processes {
+			if found[fmt.Sprintf("%s/%d", process, v)] {
+				continue
+			}
+			name := deploymentNameForAppBase(a, process)
+			if len(expected) > 1 {
+				name = deploymentNameForApp(a, process, v)
+			}
+			result = append(result, driftRepair{
+				Drift: provision.Drift{
+					App:     a.Name,
+					Kind:    provision.DriftKindDeployment,
+					Name:    name,
+					Process: process,
+					Message: fmt.Sprintf("deployment for process %s of version %d not found", process, v),
+				},
+				version:  v,
+				replicas: 1,
+				missing:  true,
+			})
+		}
+	}
+	return result
+}
+
+// repairProcessDrift deploys the process again with the labels and replicas
+// it was running with, letting the regular deploy path overwrite any manual
+// change and recreate missing objects.
+func repairProcessDrift(ctx context.Context, m *serviceManager, a *appTypes.App, version appTypes.AppVersion, r driftRepair, preserveVersions bool) error {
+	var labels *provision.LabelSet
+	var err error
+	if !r.missing {
+		labels, _, err = m.CurrentLabels(ctx, a, r.Process, r.version)
+		if err != nil {
+			return err
+		}
+	}
+	if labels == nil {
+		labels, err = provision.ServiceLabels(ctx, provision.ServiceLabelsOpts{
+			App:     a,
+			Process: r.Process,
+			Version: r.version,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return m.DeployService(ctx, servicecommon.DeployServiceOpts{
+		App:              a,
+		ProcessName:      r.Process,
+		Labels:           labels,
+		Replicas:         r.replicas,
+		Version:          version,
+		PreserveVersions: preserveVersions,
+	})
+}
+
+func volumeDriftsForApp(ctx context.Context, client *ClusterClient, a *appTypes.App, repair bool) ([]provision.Drift, error) {
+	volumes, err := servicemanager.Volume.ListByApp(ctx, a.Name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var drifts []provision.Drift
+	for i := range volumes {
+		opts, err := validateVolume(&volumes[i])
+		if err != nil {
+			return nil, err
+		}
+		if !opts.isPersistent() {
+			continue
+		}
+		exists, err := volumeExists(ctx, client, volumes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		drift := provision.Drift{
+			App:     a.Name,
+			Kind:    provision.DriftKindVolume,
+			Name:    volumes[i].Name,
+			Message: fmt.Sprintf("persistent volume %s not found", volumes[i].Name),
+		}
+		if repair {
+			err = createVolume(ctx, client, &volumes[i], opts, a)
+			if err != nil {
+				drift.Error = err.Error()
+			} else {
+				drift.Repaired = true
+			}
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDeploymentDriftsNoDrift(c *check.C) {
+	a := &appTypes.App{Name: "myapp"}
+	expected := map[int]driftVersion{
+		1: {image: "registry/myapp:v1", processes: map[string]bool{"web": true, "worker": false}},
+	}
+	deps := []driftDeployment{
+		{name: "myapp-web", process: "web", version: 1, image: "registry/myapp:v1", replicas: 2, routable: true},
+		{name: "myapp-worker", process: "worker", version: 1, image: "registry/myapp:v1", replicas: 1, routable: true},
+	}
+	repairs := deploymentDrifts(a, expected, deps, map[string]bool{"myapp-web": true})
+	c.Assert(repairs, check.HasLen, 0)
+}
+
+func (s *S) TestDeploymentDrifts(c *check.C) {
+	a := &appTypes.App{Name: "myapp"}
+	expected := map[int]driftVersion{
+		1: {image: "registry/myapp:v1", processes: map[string]bool{"web": true, "worker": false, "cron": false}},
+	}
+	deps := []driftDeployment{
+		{name: "myapp-web", process: "web", version: 1, image: "nginx:latest", replicas: 2, routable: true},
+		{name: "myapp-worker", process: "worker", version: 1, image: "registry/myapp:v1", replicas: 3, stopped: true, routable: true},
+		{name: "myapp-old", process: "web", version: 9, image: "registry/myapp:v9", replicas: 1},
+	}
+	repairs := deploymentDrifts(a, expected, deps, map[string]bool{})
+	c.Assert(repairs, check.DeepEquals, []driftRepair{
+		{
+			Drift:    provision.Drift{App: "myapp", Kind: provision.DriftKindDeployment, Name: "myapp-web", Process: "web", Message: `deployment myapp-web runs image "nginx:latest", expected "registry/myapp:v1"`},
+			version:  1,
+			replicas: 2,
+		},
+		{
+			Drift:    provision.Drift{App: "myapp", Kind: provision.DriftKindService, Name: "myapp-web", Process: "web", Message: "service myapp-web of process web not found"},
+			version:  1,
+			replicas: 2,
+		},
+		{
+			Drift:    provision.Drift{App: "myapp", Kind: provision.DriftKindScale, Name: "myapp-worker", Process: "worker", Message: "process worker is stopped but deployment myapp-worker has 3 replicas"},
+			version:  1,
+			replicas: 0,
+		},
+		{
+			Drift:    provision.Drift{App: "myapp", Kind: provision.DriftKindDeployment, Name: "myapp-old", Process: "web", Message: "deployment myapp-old runs version 9, which is unknown to tsuru"},
+			replicas: 1,
+		},
+		{
+			Drift:    provision.Drift{App: "myapp", Kind: provision.DriftKindDeployment, Name: "myapp-cron", Process: "cron", Message: "deployment for process cron of version 1 not found"},
+			version:  1,
+			replicas: 1,
+			missing:  true,
+		},
+	})
+}
+
+func (s *S) TestDeploymentDriftsMissingWithMultipleVersions(c *check.C) {
+	a := &appTypes.App{Name: "myapp"}
+	expected := map[int]driftVersion{
+		1: {image: "registry/myapp:v1", processes: map[string]bool{"web": false}},
+		2: {image: "registry/myapp:v2", processes: map[string]bool{"web": false}},
+	}
+	deps := []driftDeployment{
+		{name: "myapp-web", process: "web", version: 1, image: "registry/myapp:v1", replicas: 1, routable: true},
+	}
+	repairs := deploymentDrifts(a, expected, deps, map[string]bool{})
+	c.Assert(repairs, check.HasLen, 1)
+	c.Assert(repairs[0].Name, check.Equals, "myapp-web-v2")
+	c.Assert(repairs[0].version, check.Equals, 2)
+	c.Assert(repairs[0].missing, check.Equals, true)
+}
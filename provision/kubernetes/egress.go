@@ -0,0 +1,138 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// egressFQDNsAnnotation holds the FQDNs allowed for an app. NetworkPolicies
+// only match IP blocks, FQDN rules must be enforced by a mesh or CNI aware of
+// this annotation.
+const egressFQDNsAnnotation = tsuruLabelPrefix + "egress-fqdns"
+
+func ensureEgressPolicy(ctx context.Context, client *ClusterClient, app *appTypes.App) error {
+	policy, err := newEgressPolicy(ctx, client, app)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return removeEgressPolicy(ctx, client, app)
+	}
+	existing, err := client.NetworkingV1().NetworkPolicies(policy.Namespace).Get(ctx, policy.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.NetworkingV1().NetworkPolicies(policy.Namespace).Create(ctx, policy, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(policy.Spec, existing.Spec) && reflect.DeepEqual(policy.Annotations, existing.Annotations) {
+		return nil
+	}
+	policy.ResourceVersion = existing.ResourceVersion
+	_, err = client.NetworkingV1().NetworkPolicies(policy.Namespace).Update(ctx, policy, metav1.UpdateOptions{})
+	return err
+}
+
+func removeEgressPolicy(ctx context.Context, client *ClusterClient, app *appTypes.App) error {
+	ns, err := client.AppNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = client.NetworkingV1().NetworkPolicies(ns).Delete(ctx, egressPolicyNameForApp(app), metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// newEgressPolicy returns the NetworkPolicy restricting outbound traffic of
+// the app, or nil when the app has no egress allow list and the pool does not
+// deny egress by default. DNS and traffic to pods in the namespace of the app
+// are always allowed, as is traffic to namespaces matching the egress
+// namespace labels of the isolation profile of the team owning the app.
+func newEgressPolicy(ctx context.Context, client *ClusterClient, app *appTypes.App) (*networkingv1.NetworkPolicy, error) {
+	allow, hasAllow := provision.GetAppMetadata(app, "").Annotation(appTypes.EgressAllowAnnotation)
+	if !hasAllow && !client.egressDefaultDeny(app.Pool) {
+		return nil, nil
+	}
+	dest, err := appTypes.ParseEgressDestinations(allow)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := client.AppNamespace(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, app.TeamOwner)
+	if err != nil {
+		return nil, err
+	}
+	inCluster := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{}},
+	}
+	if profile != nil && len(profile.EgressNamespaceLabels) > 0 {
+		inCluster = append(inCluster, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: profile.EgressNamespaceLabels},
+		})
+	}
+	udp, tcp := apiv1.ProtocolUDP, apiv1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	rules := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+		{To: inCluster},
+	}
+	if len(dest.CIDRs) > 0 {
+		var peers []networkingv1.NetworkPolicyPeer
+		for _, cidr := range dest.CIDRs {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+		rules = append(rules, networkingv1.NetworkPolicyEgressRule{To: peers})
+	}
+	var annotations map[string]string
+	if len(dest.FQDNs) > 0 {
+		annotations = map[string]string{
+			egressFQDNsAnnotation: strings.Join(dest.FQDNs, ","),
+		}
+	}
+	labels := provision.ServiceAccountLabels(provision.ServiceAccountLabelsOpts{
+		App:    app,
+		Prefix: tsuruLabelPrefix,
+	})
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        egressPolicyNameForApp(app),
+			Namespace:   ns,
+			Labels:      labels.ToLabels(),
+			Annotations: annotations,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{tsuruLabelAppName: app.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      rules,
+		},
+	}, nil
+}
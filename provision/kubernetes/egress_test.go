@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (s *S) TestNewEgressPolicy(c *check.C) {
+	a := &appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Metadata: appTypes.Metadata{
+			Annotations: []appTypes.MetadataItem{
+				{Name: appTypes.EgressAllowAnnotation, Value: "10.0.0.0/8,api.example.com"},
+			},
+		},
+	}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	policy, err := newEgressPolicy(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	udp, tcp := apiv1.ProtocolUDP, apiv1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	c.Assert(policy, check.DeepEquals, &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp-egress",
+			Namespace: "default",
+			Labels: map[string]string{
+				"tsuru.io/is-tsuru": "true",
+				"tsuru.io/app-name": "myapp",
+			},
+			Annotations: map[string]string{
+				"tsuru.io/egress-fqdns": "api.example.com",
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"tsuru.io/app-name": "myapp"},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dnsPort},
+					{Protocol: &tcp, Port: &dnsPort},
+				}},
+				{To: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+				{To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}}}},
+			},
+		},
+	})
+}
+
+func (s *S) TestNewEgressPolicyIsolationProfileNamespaces(c *check.C) {
+	config.Set("isolation-profiles:regulated:egress-namespace-labels", map[interface{}]interface{}{"name": "shared"})
+	defer config.Unset("isolation-profiles")
+	a := &appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Metadata: appTypes.Metadata{
+			Annotations: []appTypes.MetadataItem{
+				{Name: appTypes.EgressAllowAnnotation, Value: "10.0.0.0/8"},
+			},
+		},
+	}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	s.setTeamIsolationProfile("regulated")
+	policy, err := newEgressPolicy(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	c.Assert(policy.Spec.Egress[1], check.DeepEquals, networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{
+			{PodSelector: &metav1.LabelSelector{}},
+			{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "shared"}}},
+		},
+	})
+}
+
+func (s *S) TestNewEgressPolicyNotEnabled(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	policy, err := newEgressPolicy(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, check.IsNil)
+}
+
+func (s *S) TestNewEgressPolicyPoolDefaultDeny(c *check.C) {
+	s.clusterClient.CustomData["test-default:egress-default-deny"] = "true"
+	defer delete(s.clusterClient.CustomData, "test-default:egress-default-deny")
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	policy, err := newEgressPolicy(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, check.NotNil)
+	c.Assert(policy.Annotations, check.IsNil)
+	c.Assert(policy.Spec.Egress, check.HasLen, 2)
+}
+
+func (s *S) TestEnsureEgressPolicy(c *check.C) {
+	a := &appTypes.App{
+		Name:      "myapp",
+		TeamOwner: s.team.Name,
+		Metadata: appTypes.Metadata{
+			Annotations: []appTypes.MetadataItem{
+				{Name: appTypes.EgressAllowAnnotation, Value: "10.0.0.0/8"},
+			},
+		},
+	}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	err = ensureEgressPolicy(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	policy, err := s.client.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "myapp-egress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(policy.Spec.Egress, check.HasLen, 3)
+	a.Metadata.Annotations = nil
+	err = ensureEgressPolicy(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	_, err = s.client.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "myapp-egress", metav1.GetOptions{})
+	c.Assert(err, check.NotNil)
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	tsuruLabelAppFiles = tsuruLabelPrefix + "app-files"
+
+	appFilesVolumeName       = "tsuru-files"
+	appSecretFilesVolumeName = "tsuru-secret-files"
+)
+
+func appFilesLabels(a *appTypes.App) *provision.LabelSet {
+	labelSet := provision.ServiceAccountLabels(provision.ServiceAccountLabelsOpts{
+		App:    a,
+		Prefix: tsuruLabelPrefix,
+	})
+	labelSet.RawLabels = map[string]string{tsuruLabelAppFiles: strconv.FormatBool(true)}
+	return labelSet
+}
+
+// appFilesName returns the name of the ConfigMap, or Secret, holding the
+// files of the app. The name changes along with the files, so units of a
+// deploy or restart get all the new files at once while the units being
+// replaced keep the old ones.
+func appFilesName(a *appTypes.App, files []appTypes.FileMount, secret bool) string {
+	kind := "files"
+	if secret {
+		kind = "secret-files"
+	}
+	return fmt.Sprintf("app-%s-%s-%s", provision.ValidKubeName(a.Name), kind, appTypes.FileMountsDigest(files)[:10])
+}
+
+// ensureAppFiles creates the ConfigMap and Secret holding the files of the
+// app in its namespace, returning the volumes and mounts projecting them
+// into its units.
+func ensureAppFiles(ctx context.Context, client *ClusterClient, a *appTypes.App, namespace string) ([]apiv1.Volume, []apiv1.VolumeMount, error) {
+	var plainFiles, secretFiles []appTypes.FileMount
+	for _, f := range a.Files {
+		if f.Secret {
+			secretFiles = append(secretFiles, f)
+		} else {
+			plainFiles = append(plainFiles, f)
+		}
+	}
+	var volumes []apiv1.Volume
+	var mounts []apiv1.VolumeMount
+	if len(plainFiles) > 0 {
+		name := appFilesName(a, plainFiles, false)
+		data, fileMounts := appFilesData(plainFiles, appFilesVolumeName)
+		immutable := true
+		_, err := client.CoreV1().ConfigMaps(namespace).Create(ctx, &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    appFilesLabels(a).ToLabels(),
+			},
+			BinaryData: data,
+			Immutable:  &immutable,
+		}, metav1.CreateOptions{})
+		if err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return nil, nil, errors.WithStack(err)
+		}
+		volumes = append(volumes, apiv1.Volume{
+			Name: appFilesVolumeName,
+			VolumeSource: apiv1.VolumeSource{
+				ConfigMap: &apiv1.ConfigMapVolumeSource{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: name},
+				},
+			},
+		})
+		mounts = append(mounts, fileMounts...)
+	}
+	if len(secretFiles) > 0 {
+		name := appFilesName(a, secretFiles, true)
+		data, fileMounts := appFilesData(secretFiles, appSecretFilesVolumeName)
+		immutable := true
+		_, err := client.CoreV1().Secrets(namespace).Create(ctx, &apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    appFilesLabels(a).ToLabels(),
+			},
+			Data:      data,
+			Immutable: &immutable,
+		}, metav1.CreateOptions{})
+		if err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return nil, nil, errors.WithStack(err)
+		}
+		volumes = append(volumes, apiv1.Volume{
+			Name: appSecretFilesVolumeName,
+			VolumeSource: apiv1.VolumeSource{
+				Secret: &apiv1.SecretVolumeSource{SecretName: name},
+			},
+		})
+		mounts = append(mounts, fileMounts...)
+	}
+	return volumes, mounts, nil
+}
+
+// appFilesData returns the data of the files keyed by their position in
+// path order, and the mounts of each one of them from the volume.
+func appFilesData(files []appTypes.FileMount, volumeName string) (map[string][]byte, []apiv1.VolumeMount) {
+	sorted := append([]appTypes.FileMount(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+	data := map[string][]byte{}
+	var mounts []apiv1.VolumeMount
+	for i, f := range sorted {
+		key := fmt.Sprintf("file-%d", i)
+		data[key] = f.Content
+		mounts = append(mounts, apiv1.VolumeMount{
+			Name:      volumeName,
+			MountPath: f.Path,
+			SubPath:   key,
+			ReadOnly:  true,
+		})
+	}
+	return data, mounts
+}
+
+// cleanupAppObjects removes the ConfigMaps and Secrets holding files and
+// secrets of the app no longer used by any of its deployments.
+func cleanupAppObjects(ctx context.Context, client *ClusterClient, a *appTypes.App) error {
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return err
+	}
+	deps, err := allDeploymentsForAppNS(ctx, client, ns, a)
+	if err != nil {
+		return err
+	}
+	inUse := map[string]struct{}{}
+	for _, dep := range deps {
+		for _, vol := range dep.Spec.Template.Spec.Volumes {
+			if vol.ConfigMap != nil {
+				inUse[vol.ConfigMap.Name] = struct{}{}
+			}
+			if vol.Secret != nil {
+				inUse[vol.Secret.SecretName] = struct{}{}
+			}
+		}
+		for _, container := range dep.Spec.Template.Spec.Containers {
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					inUse[env.ValueFrom.SecretKeyRef.Name] = struct{}{}
+				}
+			}
+		}
+	}
+	multiErrors := tsuruErrors.NewMultiError()
+	for _, labelSet := range []*provision.LabelSet{appFilesLabels(a), appSecretsLabels(a)} {
+		if err = removeAppObjects(ctx, client, ns, labelSet, inUse); err != nil {
+			multiErrors.Add(err)
+		}
+	}
+	return multiErrors.ToError()
+}
+
+// removeAppObjects removes the ConfigMaps and Secrets matching the labels,
+// except the ones to keep.
+func removeAppObjects(ctx context.Context, client *ClusterClient, namespace string, labelSet *provision.LabelSet, keep map[string]struct{}) error {
+	listOpts := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set(labelSet.ToLabels())).String(),
+	}
+	multiErrors := tsuruErrors.NewMultiError()
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(ctx, listOpts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, cm := range configMaps.Items {
+		if _, ok := keep[cm.Name]; ok {
+			continue
+		}
+		err = client.CoreV1().ConfigMaps(namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			multiErrors.Add(errors.WithStack(err))
+		}
+	}
+	secrets, err := client.CoreV1().Secrets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, secret := range secrets.Items {
+		if _, ok := keep[secret.Name]; ok {
+			continue
+		}
+		err = client.CoreV1().Secrets(namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			multiErrors.Add(errors.WithStack(err))
+		}
+	}
+	return multiErrors.ToError()
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestServiceManagerDeployServiceWithFiles(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{"web": "run"},
+	})
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	deploy := func() {
+		err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+			App:     a,
+			Version: version,
+		}, servicecommon.ProcessSpec{
+			"web": servicecommon.ProcessState{Start: true},
+		})
+		c.Assert(err, check.IsNil)
+		waitDep()
+	}
+	a.Files = []appTypes.FileMount{
+		{Path: "/etc/app/config.yaml", Content: []byte("debug: true")},
+		{Path: "/etc/app/token", Content: []byte("abc"), Secret: true},
+	}
+	deploy()
+	filesName := appFilesName(a, a.Files[:1], false)
+	secretFilesName := appFilesName(a, a.Files[1:], true)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Volumes, check.DeepEquals, []apiv1.Volume{
+		{
+			Name: "tsuru-files",
+			VolumeSource: apiv1.VolumeSource{
+				ConfigMap: &apiv1.ConfigMapVolumeSource{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: filesName},
+				},
+			},
+		},
+		{
+			Name: "tsuru-secret-files",
+			VolumeSource: apiv1.VolumeSource{
+				Secret: &apiv1.SecretVolumeSource{SecretName: secretFilesName},
+			},
+		},
+	})
+	c.Assert(dep.Spec.Template.Spec.Containers[0].VolumeMounts, check.DeepEquals, []apiv1.VolumeMount{
+		{Name: "tsuru-files", MountPath: "/etc/app/config.yaml", SubPath: "file-0", ReadOnly: true},
+		{Name: "tsuru-secret-files", MountPath: "/etc/app/token", SubPath: "file-0", ReadOnly: true},
+	})
+	cm, err := s.client.CoreV1().ConfigMaps(ns).Get(context.TODO(), filesName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(cm.BinaryData, check.DeepEquals, map[string][]byte{"file-0": []byte("debug: true")})
+	c.Assert(*cm.Immutable, check.Equals, true)
+	secret, err := s.client.CoreV1().Secrets(ns).Get(context.TODO(), secretFilesName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.Data, check.DeepEquals, map[string][]byte{"file-0": []byte("abc")})
+
+	a.Files = []appTypes.FileMount{
+		{Path: "/etc/app/config.yaml", Content: []byte("debug: false")},
+	}
+	deploy()
+	newFilesName := appFilesName(a, a.Files, false)
+	c.Assert(newFilesName, check.Not(check.Equals), filesName)
+	dep, err = s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Volumes, check.HasLen, 1)
+	c.Assert(dep.Spec.Template.Spec.Volumes[0].ConfigMap.Name, check.Equals, newFilesName)
+	_, err = s.client.CoreV1().ConfigMaps(ns).Get(context.TODO(), filesName, metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+	_, err = s.client.CoreV1().Secrets(ns).Get(context.TODO(), secretFilesName, metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+}
@@ -99,6 +99,10 @@ func pdbNameForApp(a *appTypes.App, process string) string {
 	return provision.AppProcessName(a, process, 0, "")
 }
 
+func egressPolicyNameForApp(a *appTypes.App) string {
+	return fmt.Sprintf("%s-egress", provision.ValidKubeName(a.Name))
+}
+
 func execCommandPodNameForApp(a *appTypes.App) string {
 	name := provision.ValidKubeName(a.Name)
 	return fmt.Sprintf("%s-isolated-run", name)
@@ -871,6 +875,7 @@ var _ remotecommand.TerminalSizeQueue = &fixedSizeQueue{}
 type execOpts struct {
 	client       *ClusterClient
 	app          *appTypes.App
+	version      appTypes.AppVersion
 	image        string
 	unit         string
 	cmds         []string
@@ -881,6 +886,7 @@ type execOpts struct {
 	termSize     *remotecommand.TerminalSize
 	debug        bool
 	tty          bool
+	task         *provision.TaskOptions
 }
 
 func execCommand(ctx context.Context, opts execOpts) error {
@@ -1018,6 +1024,11 @@ type runSinglePodArgs struct {
 	image        string
 	requirements apiv1.ResourceRequirements
 	app          *appTypes.App
+	volumes      []apiv1.Volume
+	mounts       []apiv1.VolumeMount
+	// deadline is the time in seconds the pod may run before being killed
+	// by kubernetes, zero means no limit.
+	deadline int64
 }
 
 func runPod(ctx context.Context, args runSinglePodArgs) error {
@@ -1064,18 +1075,28 @@ func runPod(ctx context.Context, args runSinglePodArgs) error {
 			RestartPolicy:      apiv1.RestartPolicyNever,
 			Containers: []apiv1.Container{
 				{
-					Name:      args.name,
-					Image:     args.image,
-					Command:   args.cmds,
-					Env:       args.envs,
-					Stdin:     true,
-					StdinOnce: true,
-					TTY:       tty,
-					Resources: args.requirements,
+					Name:         args.name,
+					Image:        args.image,
+					Command:      args.cmds,
+					Env:          args.envs,
+					Stdin:        true,
+					StdinOnce:    true,
+					TTY:          tty,
+					Resources:    args.requirements,
+					VolumeMounts: args.mounts,
 				},
 			},
+			Volumes: args.volumes,
 		},
 	}
+	if args.deadline > 0 {
+		pod.Spec.ActiveDeadlineSeconds = &args.deadline
+	}
+	securityContext, err := securityContextForPool(ctx, args.app.Pool, args.app.SecurityContext)
+	if err != nil {
+		return err
+	}
+	applySecurityContext(&pod.Spec, securityContext)
 
 	var initialResource string
 	if args.eventsOutput != nil {
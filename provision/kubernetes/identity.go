@@ -0,0 +1,45 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ValidateWorkloadToken checks, using the TokenReview API of the app cluster,
+// that the token was issued to the service account of the app.
+func (p *kubernetesProvisioner) ValidateWorkloadToken(ctx context.Context, a *appTypes.App, token string) error {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return err
+	}
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return err
+	}
+	review, err := client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return errors.Errorf("token not authenticated by the cluster: %s", review.Status.Error)
+		}
+		return errors.New("token not authenticated by the cluster")
+	}
+	expected := fmt.Sprintf("system:serviceaccount:%s:%s", ns, serviceAccountNameForApp(a))
+	if review.Status.User.Username != expected {
+		return errors.Errorf("token issued to %q, not to app %q", review.Status.User.Username, a.Name)
+	}
+	return nil
+}
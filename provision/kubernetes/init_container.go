@@ -0,0 +1,58 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// initContainers returns the init containers of the app running in the units
+// of the process. They share the envs, volumes and resources of the app
+// container, whose image is used when they don't set one.
+func initContainers(a *appTypes.App, process string, appContainer apiv1.Container, sidecars []apiv1.Container) ([]apiv1.Container, error) {
+	namesUsed := map[string]bool{appContainer.Name: true}
+	for _, s := range sidecars {
+		namesUsed[s.Name] = true
+	}
+	var containers []apiv1.Container
+	for _, c := range a.InitContainers {
+		if !c.RunsIn(process) {
+			continue
+		}
+		if namesUsed[c.Name] {
+			return nil, errors.Errorf("init container %q has the same name of another container of process %q", c.Name, process)
+		}
+		image := c.Image
+		if image == "" {
+			image = appContainer.Image
+		}
+		envs := append([]apiv1.EnvVar(nil), appContainer.Env...)
+		names := make([]string, 0, len(c.Env))
+		for name := range c.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			envs = append(envs, apiv1.EnvVar{
+				Name:  name,
+				Value: strings.ReplaceAll(c.Env[name], "$", "$$"),
+			})
+		}
+		containers = append(containers, apiv1.Container{
+			Name:         c.Name,
+			Image:        image,
+			Command:      c.Command,
+			Env:          envs,
+			Resources:    appContainer.Resources,
+			VolumeMounts: appContainer.VolumeMounts,
+		})
+	}
+	return containers, nil
+}
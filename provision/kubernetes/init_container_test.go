@@ -0,0 +1,65 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestServiceManagerDeployWithInitContainers(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, InitContainers: []appTypes.InitContainer{
+		{Name: "migrate", Command: []string{"./migrate.sh"}, Env: map[string]string{"MIGRATE_TIMEOUT": "60", "DRY_RUN": "false"}, Processes: []string{"web"}},
+		{Name: "wait-db", Image: "busybox:1.36", Command: []string{"sh", "-c", "until nc -z db 5432; do sleep 1; done"}},
+	}}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":    "cm1",
+			"worker": "cm2",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"web":    servicecommon.ProcessState{Start: true},
+		"worker": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	appContainer := dep.Spec.Template.Spec.Containers[0]
+	inits := dep.Spec.Template.Spec.InitContainers
+	c.Assert(inits, check.HasLen, 2)
+	c.Assert(inits[0].Name, check.Equals, "migrate")
+	c.Assert(inits[0].Image, check.Equals, appContainer.Image)
+	c.Assert(inits[0].Command, check.DeepEquals, []string{"./migrate.sh"})
+	c.Assert(inits[0].Resources, check.DeepEquals, appContainer.Resources)
+	c.Assert(inits[0].Env, check.DeepEquals, append(append([]apiv1.EnvVar(nil), appContainer.Env...),
+		apiv1.EnvVar{Name: "DRY_RUN", Value: "false"},
+		apiv1.EnvVar{Name: "MIGRATE_TIMEOUT", Value: "60"},
+	))
+	c.Assert(inits[1].Name, check.Equals, "wait-db")
+	c.Assert(inits[1].Image, check.Equals, "busybox:1.36")
+	dep, err = s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-worker", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.InitContainers, check.HasLen, 1)
+	c.Assert(dep.Spec.Template.Spec.InitContainers[0].Name, check.Equals, "wait-db")
+}
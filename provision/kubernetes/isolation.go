@@ -0,0 +1,128 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const isolationPolicyName = "tsuru-team-isolation"
+
+// ensureTeamNamespace creates the dedicated namespace of the team in the pool,
+// along with its network policy, when its isolation profile requires one.
+func ensureTeamNamespace(ctx context.Context, client *ClusterClient, pool, team string) error {
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, team)
+	if err != nil || profile == nil || !profile.DedicatedNamespace {
+		return err
+	}
+	ns, err := client.TeamNamespace(ctx, pool, team)
+	if err != nil {
+		return err
+	}
+	err = ensureNamespace(ctx, client, ns)
+	if err != nil {
+		return err
+	}
+	return ensureIsolationPolicy(ctx, client, ns, team, profile)
+}
+
+// ensureAppIsolation applies the network policy of the team owning the app
+// when the app runs in the dedicated namespace of the team. Apps created
+// before the team got its profile stay in their namespace until they change
+// pools.
+func ensureAppIsolation(ctx context.Context, client *ClusterClient, app *appTypes.App, ns string) error {
+	profile, err := servicemanager.Team.FindIsolationProfile(ctx, app.TeamOwner)
+	if err != nil || profile == nil || !profile.DedicatedNamespace {
+		return err
+	}
+	teamNs, err := client.TeamNamespace(ctx, app.Pool, app.TeamOwner)
+	if err != nil || ns != teamNs {
+		return err
+	}
+	return ensureIsolationPolicy(ctx, client, ns, app.TeamOwner, profile)
+}
+
+// ensureIsolationPolicy keeps the namespace wide NetworkPolicy of teams using
+// a profile with strict network policy in sync. It must only be called with
+// the dedicated namespace of the team, never with shared pool namespaces.
+func ensureIsolationPolicy(ctx context.Context, client *ClusterClient, ns, team string, profile *authTypes.IsolationProfile) error {
+	if !profile.StrictNetworkPolicy {
+		return nil
+	}
+	policy := newIsolationPolicy(ns, team, profile)
+	existing, err := client.NetworkingV1().NetworkPolicies(ns).Get(ctx, policy.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.NetworkingV1().NetworkPolicies(ns).Create(ctx, policy, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(policy.Spec, existing.Spec) {
+		return nil
+	}
+	policy.ResourceVersion = existing.ResourceVersion
+	_, err = client.NetworkingV1().NetworkPolicies(ns).Update(ctx, policy, metav1.UpdateOptions{})
+	return err
+}
+
+// newIsolationPolicy returns a NetworkPolicy denying all traffic of pods in the
+// namespace except DNS, traffic between pods of the namespace and ingress
+// from and egress to namespaces matching the profile. Egress policies of apps are additive,
+// so destinations allowed by apps remain reachable.
+func newIsolationPolicy(ns, team string, profile *authTypes.IsolationProfile) *networkingv1.NetworkPolicy {
+	udp, tcp := apiv1.ProtocolUDP, apiv1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	sameNamespace := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{}}
+	ingressPeers := []networkingv1.NetworkPolicyPeer{sameNamespace}
+	if len(profile.IngressNamespaceLabels) > 0 {
+		ingressPeers = append(ingressPeers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: profile.IngressNamespaceLabels},
+		})
+	}
+	egressPeers := []networkingv1.NetworkPolicyPeer{sameNamespace}
+	if len(profile.EgressNamespaceLabels) > 0 {
+		egressPeers = append(egressPeers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: profile.EgressNamespaceLabels},
+		})
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      isolationPolicyName,
+			Namespace: ns,
+			Labels: map[string]string{
+				tsuruLabelPrefix + "is-tsuru":                  "true",
+				tsuruLabelPrefix + provision.LabelAppTeamOwner: team,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: ingressPeers},
+			},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: &udp, Port: &dnsPort},
+						{Protocol: &tcp, Port: &dnsPort},
+					},
+				},
+				{To: egressPeers},
+			},
+		},
+	}
+}
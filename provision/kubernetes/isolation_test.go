@@ -0,0 +1,84 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (s *S) setTeamIsolationProfile(profile string) {
+	s.mockService.Team.OnFindIsolationProfile = func(name string) (*authTypes.IsolationProfile, error) {
+		return auth.GetIsolationProfile(profile)
+	}
+}
+
+func (s *S) TestTeamNamespace(c *check.C) {
+	config.Set("kubernetes:use-pool-namespaces", true)
+	defer config.Unset("kubernetes:use-pool-namespaces")
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	config.Set("isolation-profiles:shared:registry", "registry.example.com")
+	defer config.Unset("isolation-profiles")
+	ns, err := s.clusterClient.TeamNamespace(context.TODO(), "pool1", "team_a")
+	c.Assert(err, check.IsNil)
+	c.Assert(ns, check.Equals, "tsuru-pool1")
+	s.setTeamIsolationProfile("shared")
+	ns, err = s.clusterClient.TeamNamespace(context.TODO(), "pool1", "team_a")
+	c.Assert(err, check.IsNil)
+	c.Assert(ns, check.Equals, "tsuru-pool1")
+	s.setTeamIsolationProfile("regulated")
+	ns, err = s.clusterClient.TeamNamespace(context.TODO(), "pool1", "team_a")
+	c.Assert(err, check.IsNil)
+	c.Assert(ns, check.Equals, "tsuru-pool1-team-a")
+}
+
+func (s *S) TestEnsureTeamNamespace(c *check.C) {
+	config.Set("isolation-profiles:regulated:dedicated-namespace", true)
+	config.Set("isolation-profiles:regulated:strict-network-policy", true)
+	config.Set("isolation-profiles:regulated:ingress-namespace-labels", map[interface{}]interface{}{"name": "ingress"})
+	config.Set("isolation-profiles:regulated:egress-namespace-labels", map[interface{}]interface{}{"name": "shared"})
+	defer config.Unset("isolation-profiles")
+	s.setTeamIsolationProfile("regulated")
+	err := ensureTeamNamespace(context.TODO(), s.clusterClient, "pool1", "myteam")
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().Namespaces().Get(context.TODO(), "default-myteam", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	policy, err := s.client.NetworkingV1().NetworkPolicies("default-myteam").Get(context.TODO(), isolationPolicyName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	udp, tcp := apiv1.ProtocolUDP, apiv1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	c.Assert(policy.Labels, check.DeepEquals, map[string]string{
+		"tsuru.io/is-tsuru": "true",
+		"tsuru.io/app-team": "myteam",
+	})
+	c.Assert(policy.Spec, check.DeepEquals, networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{From: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{}},
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "ingress"}}},
+			}},
+		},
+		Egress: []networkingv1.NetworkPolicyEgressRule{
+			{Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			}},
+			{To: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{}},
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "shared"}}},
+			}},
+		},
+	})
+}
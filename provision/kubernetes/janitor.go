@@ -0,0 +1,264 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	orphanKindService   = "service"
+	orphanKindSecret    = "secret"
+	orphanKindConfigMap = "configmap"
+	orphanKindPVC       = "persistentvolumeclaim"
+
+	defaultJanitorGracePeriod = time.Hour
+)
+
+// janitorObject is a tsuru labeled object that may be left behind when its
+// owner is removed.
+type janitorObject struct {
+	kind string
+	meta metav1.ObjectMeta
+}
+
+// orphanOwners caches the lookups of owners during a janitor run, so each
+// app and volume is only fetched once.
+type orphanOwners struct {
+	apps     map[string]*appTypes.App
+	versions map[string]map[int]struct{}
+	volumes  map[string]bool
+}
+
+func (p *kubernetesProvisioner) OrphanResources(ctx context.Context) ([]provision.OrphanResource, error) {
+	return p.collectOrphans(ctx, false)
+}
+
+func (p *kubernetesProvisioner) RemoveOrphanResources(ctx context.Context) ([]provision.OrphanResource, error) {
+	return p.collectOrphans(ctx, true)
+}
+
+func (p *kubernetesProvisioner) collectOrphans(ctx context.Context, remove bool) ([]provision.OrphanResource, error) {
+	gracePeriod, _ := config.GetDuration("janitor:grace-period")
+	if gracePeriod <= 0 {
+		gracePeriod = defaultJanitorGracePeriod
+	}
+	owners := &orphanOwners{
+		apps:     map[string]*appTypes.App{},
+		versions: map[string]map[int]struct{}{},
+		volumes:  map[string]bool{},
+	}
+	var orphans []provision.OrphanResource
+	err := forEachCluster(ctx, func(client *ClusterClient) error {
+		objs, err := janitorObjects(ctx, client)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			if time.Since(obj.meta.CreationTimestamp.Time) < gracePeriod {
+				continue
+			}
+			var reason string
+			reason, err = owners.orphanReason(ctx, labelSetFromMeta(&obj.meta))
+			if err != nil {
+				return err
+			}
+			if reason == "" {
+				continue
+			}
+			orphan := provision.OrphanResource{
+				Cluster:   client.Name,
+				Namespace: obj.meta.Namespace,
+				Kind:      obj.kind,
+				Name:      obj.meta.Name,
+				Reason:    reason,
+			}
+			if remove {
+				err = deleteJanitorObject(ctx, client, obj)
+				if err != nil {
+					orphan.Error = err.Error()
+				} else {
+					orphan.Removed = true
+				}
+			}
+			orphans = append(orphans, orphan)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		a, b := orphans[i], orphans[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return orphans, nil
+}
+
+func janitorObjects(ctx context.Context, client *ClusterClient) ([]janitorObject, error) {
+	selector := labels.SelectorFromSet(labels.Set(provision.ServiceLabelSet(tsuruLabelPrefix).ToIsTsuruSelector())).String()
+	listOpts := metav1.ListOptions{LabelSelector: selector}
+	var objs []janitorObject
+	svcs, err := client.CoreV1().Services("").List(ctx, listOpts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, svc := range filterTsuruControlledServices(svcs.Items) {
+		objs = append(objs, janitorObject{kind: orphanKindService, meta: svc.ObjectMeta})
+	}
+	secrets, err := client.CoreV1().Secrets("").List(ctx, listOpts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, secret := range secrets.Items {
+		objs = append(objs, janitorObject{kind: orphanKindSecret, meta: secret.ObjectMeta})
+	}
+	configMaps, err := client.CoreV1().ConfigMaps("").List(ctx, listOpts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, cm := range configMaps.Items {
+		objs = append(objs, janitorObject{kind: orphanKindConfigMap, meta: cm.ObjectMeta})
+	}
+	pvcs, err := client.CoreV1().PersistentVolumeClaims("").List(ctx, listOpts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, pvc := range pvcs.Items {
+		objs = append(objs, janitorObject{kind: orphanKindPVC, meta: pvc.ObjectMeta})
+	}
+	return objs, nil
+}
+
+func deleteJanitorObject(ctx context.Context, client *ClusterClient, obj janitorObject) error {
+	opts := metav1.DeleteOptions{PropagationPolicy: propagationPtr(metav1.DeletePropagationForeground)}
+	var err error
+	switch obj.kind {
+	case orphanKindService:
+		err = client.CoreV1().Services(obj.meta.Namespace).Delete(ctx, obj.meta.Name, opts)
+	case orphanKindSecret:
+		err = client.CoreV1().Secrets(obj.meta.Namespace).Delete(ctx, obj.meta.Name, opts)
+	case orphanKindConfigMap:
+		err = client.CoreV1().ConfigMaps(obj.meta.Namespace).Delete(ctx, obj.meta.Name, opts)
+	case orphanKindPVC:
+		err = client.CoreV1().PersistentVolumeClaims(obj.meta.Namespace).Delete(ctx, obj.meta.Name, opts)
+	}
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// orphanReason returns why the object with the labels is considered orphan,
+// or an empty string when its owner still exists. Objects of jobs and image
+// builds are left alone, they are cleaned up by their own controllers.
+func (o *orphanOwners) orphanReason(ctx context.Context, ls *provision.LabelSet) (string, error) {
+	if ls.IsJob() || ls.IsBuild() {
+		return "", nil
+	}
+	if appName := ls.AppName(); appName != "" {
+		a, err := o.app(ctx, appName)
+		if err != nil {
+			return "", err
+		}
+		if a == nil {
+			return fmt.Sprintf("app %q not found", appName), nil
+		}
+		version := ls.AppVersion()
+		if version == 0 {
+			return "", nil
+		}
+		versions, err := o.appVersions(ctx, a)
+		if err != nil {
+			return "", err
+		}
+		if versions == nil {
+			return "", nil
+		}
+		if _, ok := versions[version]; !ok {
+			return fmt.Sprintf("version %d of app %q not found", version, appName), nil
+		}
+		return "", nil
+	}
+	if volumeName := ls.VolumeName(); volumeName != "" {
+		exists, err := o.volume(ctx, volumeName)
+		if err != nil || exists {
+			return "", err
+		}
+		return fmt.Sprintf("volume %q not found", volumeName), nil
+	}
+	return "", nil
+}
+
+func (o *orphanOwners) app(ctx context.Context, name string) (*appTypes.App, error) {
+	if a, ok := o.apps[name]; ok {
+		return a, nil
+	}
+	a, err := servicemanager.App.GetByName(ctx, name)
+	if err == appTypes.ErrAppNotFound {
+		a, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.apps[name] = a
+	return a, nil
+}
+
+// appVersions returns the versions known by tsuru for the app, or nil when
+// the app has no versions stored, in which case no version is considered
+// orphan.
+func (o *orphanOwners) appVersions(ctx context.Context, a *appTypes.App) (map[int]struct{}, error) {
+	if versions, ok := o.versions[a.Name]; ok {
+		return versions, nil
+	}
+	appVersions, err := servicemanager.AppVersion.AppVersions(ctx, a)
+	if err != nil && err != appTypes.ErrNoVersionsAvailable {
+		return nil, err
+	}
+	var versions map[int]struct{}
+	if err == nil {
+		versions = map[int]struct{}{}
+		for v := range appVersions.Versions {
+			versions[v] = struct{}{}
+		}
+	}
+	o.versions[a.Name] = versions
+	return versions, nil
+}
+
+func (o *orphanOwners) volume(ctx context.Context, name string) (bool, error) {
+	if exists, ok := o.volumes[name]; ok {
+		return exists, nil
+	}
+	_, err := servicemanager.Volume.Get(ctx, name)
+	if err != nil && err != volumeTypes.ErrVolumeNotFound {
+		return false, err
+	}
+	o.volumes[name] = err == nil
+	return err == nil, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestRemoveOrphanResources(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{"web": "run mycmd"},
+	})
+	svcs := []apiv1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Name: "myapp-web", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/app-name": "myapp", "tsuru.io/app-process": "web",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "myapp-web-v1", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/app-name": "myapp", "tsuru.io/app-process": "web", "tsuru.io/app-version": "1",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "myapp-web-v7", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/app-name": "myapp", "tsuru.io/app-process": "web", "tsuru.io/app-version": "7",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ghost-web", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/app-name": "ghost", "tsuru.io/app-process": "web",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ghost-lb", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/app-name": "ghost", "tsuru.io/router-lb": "true",
+		}}},
+	}
+	for _, svc := range svcs {
+		_, err = s.client.CoreV1().Services(svc.Namespace).Create(context.TODO(), &svc, metav1.CreateOptions{})
+		c.Assert(err, check.IsNil)
+	}
+	_, err = s.client.CoreV1().PersistentVolumeClaims("default").Create(context.TODO(), &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "ghost-vol-tsuru-claim", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/volume-name": "ghost-vol",
+		}},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().Secrets("default").Create(context.TODO(), &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "myjob-secret", Namespace: "default", Labels: map[string]string{
+			"tsuru.io/is-tsuru": "true", "tsuru.io/is-job": "true", "tsuru.io/job-name": "myjob",
+		}},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	expected := []provision.OrphanResource{
+		{Cluster: s.clusterClient.Name, Namespace: "default", Kind: orphanKindPVC, Name: "ghost-vol-tsuru-claim", Reason: `volume "ghost-vol" not found`},
+		{Cluster: s.clusterClient.Name, Namespace: "default", Kind: orphanKindService, Name: "ghost-web", Reason: `app "ghost" not found`},
+		{Cluster: s.clusterClient.Name, Namespace: "default", Kind: orphanKindService, Name: "myapp-web-v7", Reason: `version 7 of app "myapp" not found`},
+	}
+	orphans, err := s.p.OrphanResources(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.DeepEquals, expected)
+	orphans, err = s.p.RemoveOrphanResources(context.TODO())
+	c.Assert(err, check.IsNil)
+	for i := range expected {
+		expected[i].Removed = true
+	}
+	c.Assert(orphans, check.DeepEquals, expected)
+	remaining, err := s.client.CoreV1().Services("default").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	var names []string
+	for _, svc := range remaining.Items {
+		names = append(names, svc.Name)
+	}
+	c.Assert(names, check.DeepEquals, []string{"ghost-lb", "myapp-web", "myapp-web-v1"})
+	orphans, err = s.p.OrphanResources(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 0)
+}
@@ -125,8 +125,16 @@ func ensureCronjob(ctx context.Context, client *ClusterClient, job *jobTypes.Job
 	if err != nil {
 		return err
 	}
+	securityContext, err := securityContextForPool(ctx, job.Pool, nil)
+	if err != nil {
+		return err
+	}
+	applySecurityContext(&jobSpec.Template.Spec, securityContext)
 
-	namespace := client.PoolNamespace(job.Pool)
+	namespace, err := client.TeamNamespace(ctx, job.Pool, job.TeamOwner)
+	if err != nil {
+		return err
+	}
 
 	existingCronjob, err := client.BatchV1().CronJobs(namespace).Get(ctx, job.Name, metav1.GetOptions{})
 	if k8sErrors.IsNotFound(err) {
@@ -262,6 +270,9 @@ func (p *kubernetesProvisioner) EnsureJob(ctx context.Context, job *jobTypes.Job
 	if err != nil {
 		return err
 	}
+	if err = ensureTeamNamespace(ctx, client, job.Pool, job.TeamOwner); err != nil {
+		return err
+	}
 	if err = ensureServiceAccountForJob(ctx, client, *job); err != nil {
 		return err
 	}
@@ -269,13 +280,16 @@ func (p *kubernetesProvisioner) EnsureJob(ctx context.Context, job *jobTypes.Job
 	return ensureCronjob(ctx, client, job)
 }
 
-func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, name, pool string) error {
-	client, err := clusterForPool(ctx, pool)
+func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job) error {
+	client, err := clusterForPool(ctx, job.Pool)
+	if err != nil {
+		return err
+	}
+	namespace, err := client.TeamNamespace(ctx, job.Pool, job.TeamOwner)
 	if err != nil {
 		return err
 	}
-	namespace := client.PoolNamespace(pool)
-	cron, err := client.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	cron, err := client.BatchV1().CronJobs(namespace).Get(ctx, job.Name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -321,7 +335,11 @@ func (p *kubernetesProvisioner) JobUnits(ctx context.Context, job *jobTypes.Job)
 	listOptions := metav1.ListOptions{
 		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
 	}
-	k8sJobs, err := client.BatchV1().Jobs(client.PoolNamespace(job.Pool)).List(ctx, listOptions)
+	namespace, err := client.TeamNamespace(ctx, job.Pool, job.TeamOwner)
+	if err != nil {
+		return nil, err
+	}
+	k8sJobs, err := client.BatchV1().Jobs(namespace).List(ctx, listOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +351,10 @@ func (p *kubernetesProvisioner) DestroyJob(ctx context.Context, job *jobTypes.Jo
 	if err != nil {
 		return err
 	}
-	namespace := client.PoolNamespace(job.Pool)
+	namespace, err := client.TeamNamespace(ctx, job.Pool, job.TeamOwner)
+	if err != nil {
+		return err
+	}
 	if err = client.CoreV1().ServiceAccounts(namespace).Delete(ctx, serviceAccountNameForJob(*job), metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
 		return err
 	}
@@ -351,7 +372,10 @@ func (p *kubernetesProvisioner) KillJobUnit(ctx context.Context, job *jobTypes.J
 	if err != nil {
 		return err
 	}
-	namespace := client.PoolNamespace(job.Pool)
+	namespace, err := client.TeamNamespace(ctx, job.Pool, job.TeamOwner)
+	if err != nil {
+		return err
+	}
 	k8sJob, err := client.BatchV1().Jobs(namespace).Get(ctx, unit, metav1.GetOptions{})
 	if err != nil {
 		if k8sErrors.IsNotFound(err) {
@@ -368,7 +392,7 @@ func (p *kubernetesProvisioner) KillJobUnit(ctx context.Context, job *jobTypes.J
 	return client.BatchV1().Jobs(namespace).Delete(ctx, unit, metav1.DeleteOptions{})
 }
 
-func (p *kubernetesProvisioner) getPodsForJob(ctx context.Context, client *ClusterClient, job *batchv1.Job) ([]apiv1.Pod, error) {
+func podsForJob(ctx context.Context, client *ClusterClient, job *batchv1.Job) ([]apiv1.Pod, error) {
 	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"job-name": job.Name}}
 	listOptions := metav1.ListOptions{
 		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
@@ -389,7 +413,7 @@ func (p *kubernetesProvisioner) jobsToJobUnits(ctx context.Context, client *Clus
 		var status provTypes.UnitStatus
 		var statusReason string
 		var restarts int32
-		pods, err := p.getPodsForJob(ctx, client, &k8sJob)
+		pods, err := podsForJob(ctx, client, &k8sJob)
 		if err != nil {
 			return nil, err
 		}
@@ -515,8 +539,11 @@ func ensureServiceAccountForJob(ctx context.Context, client *ClusterClient, job
 		Job:    &job,
 		Prefix: tsuruLabelPrefix,
 	})
-	ns := client.PoolNamespace(job.Pool)
-	return ensureServiceAccount(ctx, client, serviceAccountNameForJob(job), labels, ns, &job.Metadata)
+	ns, err := client.TeamNamespace(ctx, job.Pool, job.TeamOwner)
+	if err != nil {
+		return err
+	}
+	return ensureServiceAccount(ctx, client, serviceAccountNameForJob(job), labels, ns, serviceAccountAnnotations(&job.Metadata))
 }
 
 func buildActiveDeadline(activeDeadlineSeconds *int64) *int64 {
@@ -0,0 +1,87 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/job"
+	"github.com/tsuru/tsuru/log"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// jobExecution describes a finished kubernetes job as an execution of the
+// tsuru job, the node and exit code are taken from its last pod.
+func jobExecution(k8sJob *batchv1.Job, pods []apiv1.Pod, status, reason string) jobTypes.Execution {
+	execution := jobTypes.Execution{
+		ID:        k8sJob.Name,
+		Job:       k8sJob.Labels[tsuruLabelJobName],
+		Status:    status,
+		Reason:    reason,
+		StartTime: k8sJob.CreationTimestamp.Time.UTC(),
+		EndTime:   time.Now().UTC(),
+	}
+	if k8sJob.Status.StartTime != nil {
+		execution.StartTime = k8sJob.Status.StartTime.Time.UTC()
+	}
+	if k8sJob.Status.CompletionTime != nil {
+		execution.EndTime = k8sJob.Status.CompletionTime.Time.UTC()
+	}
+	if containers := k8sJob.Spec.Template.Spec.Containers; len(containers) > 0 {
+		execution.Image = containers[0].Image
+	}
+	var last *apiv1.Pod
+	for i := range pods {
+		if last == nil || last.CreationTimestamp.Before(&pods[i].CreationTimestamp) {
+			last = &pods[i]
+		}
+	}
+	if last == nil {
+		return execution
+	}
+	execution.Node = last.Spec.NodeName
+	for _, cs := range last.Status.ContainerStatuses {
+		termination := cs.State.Terminated
+		if termination == nil {
+			termination = cs.LastTerminationState.Terminated
+		}
+		if termination != nil {
+			exitCode := termination.ExitCode
+			execution.ExitCode = &exitCode
+			break
+		}
+	}
+	return execution
+}
+
+func recordJobExecution(client *ClusterClient, k8sJob *batchv1.Job, evt *apiv1.Event, wg *sync.WaitGroup) {
+	defer wg.Done()
+	var status, reason string
+	switch evt.Reason {
+	case "Completed":
+		status = jobTypes.ExecutionSucceeded
+	case "BackoffLimitExceeded":
+		status = jobTypes.ExecutionFailed
+		reason = evt.Message
+	default:
+		return
+	}
+	if k8sJob.Labels[tsuruLabelJobName] == "" {
+		return
+	}
+	ctx := context.Background()
+	pods, err := podsForJob(ctx, client, k8sJob)
+	if err != nil {
+		log.Errorf("[job-executions] unable to list pods of job %q: %v", k8sJob.Name, err)
+	}
+	execution := jobExecution(k8sJob, pods, status, reason)
+	if err = job.RecordExecution(ctx, execution); err != nil {
+		log.Errorf("[job-executions] unable to record execution %q of job %q: %v", execution.ID, execution.Job, err)
+	}
+}
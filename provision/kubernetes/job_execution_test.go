@@ -0,0 +1,65 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"time"
+
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestJobExecution(c *check.C) {
+	start := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	k8sJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "myjob-29012345",
+			Labels:            map[string]string{tsuruLabelJobName: "myjob"},
+			CreationTimestamp: metav1.NewTime(start.Add(-time.Second)),
+		},
+		Spec: batchv1.JobSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{Name: "job", Image: "registry/myjob:v2"}},
+				},
+			},
+		},
+		Status: batchv1.JobStatus{StartTime: &metav1.Time{Time: start}},
+	}
+	terminated := func(code int32) apiv1.ContainerState {
+		return apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: code}}
+	}
+	pods := []apiv1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "myjob-29012345-b", CreationTimestamp: metav1.NewTime(start.Add(time.Minute))},
+			Spec:       apiv1.PodSpec{NodeName: "node2"},
+			Status:     apiv1.PodStatus{ContainerStatuses: []apiv1.ContainerStatus{{State: terminated(2)}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "myjob-29012345-a", CreationTimestamp: metav1.NewTime(start)},
+			Spec:       apiv1.PodSpec{NodeName: "node1"},
+			Status:     apiv1.PodStatus{ContainerStatuses: []apiv1.ContainerStatus{{State: terminated(1)}}},
+		},
+	}
+	execution := jobExecution(k8sJob, pods, jobTypes.ExecutionFailed, "Job has reached the specified backoff limit")
+	c.Assert(execution.ID, check.Equals, "myjob-29012345")
+	c.Assert(execution.Job, check.Equals, "myjob")
+	c.Assert(execution.Status, check.Equals, jobTypes.ExecutionFailed)
+	c.Assert(execution.Reason, check.Equals, "Job has reached the specified backoff limit")
+	c.Assert(execution.Image, check.Equals, "registry/myjob:v2")
+	c.Assert(execution.Node, check.Equals, "node2")
+	c.Assert(*execution.ExitCode, check.Equals, int32(2))
+	c.Assert(execution.StartTime, check.DeepEquals, start)
+	c.Assert(execution.EndTime.IsZero(), check.Equals, false)
+	completed := metav1.NewTime(start.Add(2 * time.Minute))
+	k8sJob.Status.CompletionTime = &completed
+	execution = jobExecution(k8sJob, nil, jobTypes.ExecutionSucceeded, "")
+	c.Assert(execution.EndTime, check.DeepEquals, start.Add(2*time.Minute))
+	c.Assert(execution.ExitCode, check.IsNil)
+	c.Assert(execution.Node, check.Equals, "")
+}
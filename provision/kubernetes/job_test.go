@@ -647,7 +647,7 @@ func (s *S) TestProvisionerTriggerCron(c *check.C) {
 			},
 			scenario: func(t *time.Time) {
 				*t = time.Now()
-				err := s.p.TriggerCron(context.TODO(), "myjob", "test-default")
+				err := s.p.TriggerCron(context.TODO(), &jobTypes.Job{Name: "myjob", TeamOwner: s.team.Name, Pool: "test-default"})
 				c.Assert(err, check.IsNil)
 				waitCron()
 			},
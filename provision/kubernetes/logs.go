@@ -42,7 +42,10 @@ func (p *kubernetesProvisioner) ListLogs(ctx context.Context, obj *logTypes.Loga
 		return nil, err
 	}
 
-	ns := clusterClient.PoolNamespace(obj.Pool)
+	ns, err := clusterClient.TeamNamespace(ctx, obj.Pool, obj.Team)
+	if err != nil {
+		return nil, err
+	}
 
 	podInformer, err := clusterController.getPodInformer()
 	if err != nil {
@@ -72,7 +75,10 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj *logTypes.Log
 		return nil, err
 	}
 
-	ns := clusterClient.PoolNamespace(pool)
+	ns, err := clusterClient.TeamNamespace(ctx, pool, obj.Team)
+	if err != nil {
+		return nil, err
+	}
 
 	podInformer, err := clusterController.getPodInformer()
 	if err != nil {
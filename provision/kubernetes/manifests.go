@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const redactedEnvValue = "(redacted)"
+
+var _ provision.ManifestsProvisioner = &kubernetesProvisioner{}
+
+func (p *kubernetesProvisioner) RenderedManifests(ctx context.Context, a *appTypes.App, version appTypes.AppVersion) ([]provision.RenderedManifest, error) {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := deploymentsDataForApp(ctx, client, a)
+	if err != nil {
+		return nil, err
+	}
+	var manifests []provision.RenderedManifest
+	for _, di := range deps.versioned[version.Version()] {
+		manifest, err := renderedManifest(di.process, di.dep)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Process < manifests[j].Process })
+	return manifests, nil
+}
+
+// renderedManifest returns the spec of the deployment without the fields
+// managed by kubernetes, which change on every rollout, and without the
+// values of environment variables.
+func renderedManifest(process string, dep *appsv1.Deployment) (provision.RenderedManifest, error) {
+	result := provision.RenderedManifest{Process: process}
+	dep = dep.DeepCopy()
+	annotations := dep.Annotations
+	delete(annotations, replicaDepRevision)
+	dep.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+	dep.ObjectMeta = metav1.ObjectMeta{
+		Name:        dep.Name,
+		Namespace:   dep.Namespace,
+		Labels:      dep.Labels,
+		Annotations: annotations,
+	}
+	dep.Status = appsv1.DeploymentStatus{}
+	if containers := dep.Spec.Template.Spec.Containers; len(containers) > 0 {
+		container := &containers[0]
+		result.Image = container.Image
+		result.EnvHash = envHash(container.Env)
+		resources, err := json.Marshal(container.Resources)
+		if err != nil {
+			return result, err
+		}
+		result.Resources = string(resources)
+		probes, err := json.Marshal(map[string]*apiv1.Probe{
+			"liveness":  container.LivenessProbe,
+			"readiness": container.ReadinessProbe,
+			"startup":   container.StartupProbe,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Probes = string(probes)
+		for i := range container.Env {
+			if container.Env[i].ValueFrom == nil {
+				container.Env[i].Value = redactedEnvValue
+			}
+		}
+	}
+	data, err := yaml.Marshal(dep)
+	if err != nil {
+		return result, err
+	}
+	result.Manifest = string(data)
+	return result, nil
+}
+
+func envHash(envs []apiv1.EnvVar) string {
+	sorted := append([]apiv1.EnvVar{}, envs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	hash := sha256.New()
+	for _, env := range sorted {
+		fmt.Fprintf(hash, "%s=%s\n", env.Name, env.Value)
+		if env.ValueFrom != nil {
+			data, _ := json.Marshal(env.ValueFrom)
+			hash.Write(data)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
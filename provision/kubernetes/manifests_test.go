@@ -0,0 +1,63 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"strings"
+
+	check "gopkg.in/check.v1"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func manifestTestDeployment(password string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "myapp-web",
+			Namespace:       "default",
+			ResourceVersion: "42",
+			Annotations:     map[string]string{replicaDepRevision: "3", "team": "a"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{
+						Name:  "myapp-web",
+						Image: "registry/myapp:v1",
+						Env:   []apiv1.EnvVar{{Name: "PASSWORD", Value: password}},
+						Resources: apiv1.ResourceRequirements{
+							Limits: apiv1.ResourceList{apiv1.ResourceMemory: resource.MustParse("128Mi")},
+						},
+						ReadinessProbe: &apiv1.Probe{TimeoutSeconds: 5},
+					}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 2},
+	}
+}
+
+func (s *S) TestRenderedManifest(c *check.C) {
+	dep := manifestTestDeployment("s3cr3t")
+	manifest, err := renderedManifest("web", dep)
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Process, check.Equals, "web")
+	c.Assert(manifest.Image, check.Equals, "registry/myapp:v1")
+	c.Assert(manifest.Resources, check.Equals, `{"limits":{"memory":"128Mi"}}`)
+	c.Assert(manifest.Probes, check.Matches, `.*"readiness":\{.*"timeoutSeconds":5.*`)
+	c.Assert(strings.Contains(manifest.Manifest, "s3cr3t"), check.Equals, false)
+	c.Assert(strings.Contains(manifest.Manifest, redactedEnvValue), check.Equals, true)
+	c.Assert(strings.Contains(manifest.Manifest, "resourceVersion"), check.Equals, false)
+	c.Assert(strings.Contains(manifest.Manifest, replicaDepRevision), check.Equals, false)
+	c.Assert(strings.Contains(manifest.Manifest, "team: a"), check.Equals, true)
+	c.Assert(dep.Spec.Template.Spec.Containers[0].Env[0].Value, check.Equals, "s3cr3t")
+	c.Assert(dep.Annotations[replicaDepRevision], check.Equals, "3")
+	other, err := renderedManifest("web", manifestTestDeployment("other"))
+	c.Assert(err, check.IsNil)
+	c.Assert(other.EnvHash, check.Not(check.Equals), manifest.EnvHash)
+	c.Assert(other.Manifest, check.Equals, manifest.Manifest)
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"github.com/tsuru/tsuru/provision"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -40,6 +41,14 @@ func (p *kubernetesProvisioner) UnitsMetrics(ctx context.Context, a *appTypes.Ap
 		return nil, err
 	}
 	labelSelector := labels.SelectorFromSet(l.ToAppSelector())
+	pods, err := podsForAppProcess(ctx, clusterClient, ns, l.ToAppSelector())
+	if err != nil {
+		return nil, err
+	}
+	podsByName := map[string]*apiv1.Pod{}
+	for i := range pods.Items {
+		podsByName[pods.Items[i].Name] = &pods.Items[i]
+	}
 	metricList, err := metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector.String(),
 	})
@@ -68,12 +77,42 @@ func (p *kubernetesProvisioner) UnitsMetrics(ctx context.Context, a *appTypes.Ap
 			totalMemoryUsage.Add(memoryUsage)
 		}
 
-		unitMetrics = append(unitMetrics, provTypes.UnitMetric{
+		unitMetric := provTypes.UnitMetric{
 			ID:     metric.ObjectMeta.Name,
 			CPU:    totalCPUUsage.String(),
 			Memory: totalMemoryUsage.String(),
-		})
+		}
+		if pod, ok := podsByName[metric.ObjectMeta.Name]; ok {
+			fillUnitMetricResources(&unitMetric, pod)
+		}
+		unitMetrics = append(unitMetrics, unitMetric)
 	}
 
 	return unitMetrics, nil
 }
+
+// fillUnitMetricResources sets the requests and limits of the containers in
+// the pod, which are left empty when none of them sets it.
+func fillUnitMetricResources(unitMetric *provTypes.UnitMetric, pod *apiv1.Pod) {
+	unitMetric.ProcessName = labelSetFromMeta(&pod.ObjectMeta).AppProcess()
+	sum := func(name apiv1.ResourceName, limits bool) string {
+		total := resource.Quantity{}
+		for _, container := range pod.Spec.Containers {
+			resources := container.Resources.Requests
+			if limits {
+				resources = container.Resources.Limits
+			}
+			if quantity, ok := resources[name]; ok {
+				total.Add(quantity)
+			}
+		}
+		if total.IsZero() {
+			return ""
+		}
+		return total.String()
+	}
+	unitMetric.CPURequest = sum(apiv1.ResourceCPU, false)
+	unitMetric.CPULimit = sum(apiv1.ResourceCPU, true)
+	unitMetric.MemoryRequest = sum(apiv1.ResourceMemory, false)
+	unitMetric.MemoryLimit = sum(apiv1.ResourceMemory, true)
+}
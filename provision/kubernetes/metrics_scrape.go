@@ -0,0 +1,234 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	metricsScrapeAnnotations    = "annotations"
+	metricsScrapeServiceMonitor = "service-monitor"
+
+	metricsPortName = "metrics"
+	labelMetricsApp = tsuruLabelPrefix + "metrics-app"
+)
+
+var (
+	_ provision.MetricsScrapeProvisioner = &kubernetesProvisioner{}
+
+	serviceMonitorGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+)
+
+func metricsServiceName(a *appTypes.App) string {
+	return fmt.Sprintf("%s-tsuru-metrics", provision.ValidKubeName(a.Name))
+}
+
+func validMetrics(metrics *provTypes.TsuruYamlMetrics) bool {
+	return metrics != nil && metrics.Port > 0
+}
+
+// podMetricsAnnotations returns the prometheus.io annotations added to the
+// pods of apps in pools scraped through annotations.
+func podMetricsAnnotations(mode string, metrics *provTypes.TsuruYamlMetrics) map[string]string {
+	if mode != metricsScrapeAnnotations || !validMetrics(metrics) {
+		return nil
+	}
+	annotations := map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   strconv.Itoa(metrics.Port),
+		"prometheus.io/path":   metrics.MetricsPath(),
+	}
+	if metrics.Interval != "" {
+		annotations["prometheus.io/interval"] = metrics.Interval
+	}
+	return annotations
+}
+
+// ensureMetricsScrape creates the headless service exposing the metrics port
+// of the app units and the ServiceMonitor scraping it, in pools scraped
+// through the Prometheus operator. Both are removed once the app stops
+// declaring a metrics endpoint.
+func ensureMetricsScrape(ctx context.Context, client *ClusterClient, a *appTypes.App, version appTypes.AppVersion) error {
+	if client.metricsScrapeMode(a.Pool) != metricsScrapeServiceMonitor {
+		return nil
+	}
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return err
+	}
+	if !validMetrics(yamlData.Metrics) {
+		return removeMetricsScrape(ctx, client, a, ns)
+	}
+	metrics := yamlData.Metrics
+	name := metricsServiceName(a)
+	svcLabels := map[string]string{
+		labelMetricsApp:               a.Name,
+		tsuruLabelPrefix + "is-tsuru": "true",
+	}
+	svc := apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    svcLabels,
+		},
+		Spec: apiv1.ServiceSpec{
+			ClusterIP: "None",
+			Selector: map[string]string{
+				tsuruLabelPrefix + provision.LabelAppName: a.Name,
+				tsuruLabelPrefix + provision.LabelIsBuild: "false",
+				tsuruLabelPrefix + "is-isolated-run":      "false",
+			},
+			Ports: []apiv1.ServicePort{{
+				Name:       metricsPortName,
+				Protocol:   apiv1.ProtocolTCP,
+				Port:       int32(metrics.Port),
+				TargetPort: intstr.FromInt(metrics.Port),
+			}},
+		},
+	}
+	existing, err := client.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.CoreV1().Services(ns).Create(ctx, &svc, metav1.CreateOptions{})
+	} else if err == nil {
+		svc.ResourceVersion = existing.ResourceVersion
+		_, err = client.CoreV1().Services(ns).Update(ctx, &svc, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	endpoint := map[string]interface{}{
+		"port": metricsPortName,
+		"path": metrics.MetricsPath(),
+	}
+	if metrics.Interval != "" {
+		endpoint["interval"] = metrics.Interval
+	}
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "ServiceMonitor",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": ns,
+			"labels":    toInterfaceMap(svcLabels),
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{labelMetricsApp: a.Name},
+			},
+			"endpoints": []interface{}{endpoint},
+		},
+	}}
+	dynClient, err := DynamicClientForConfig(client.restConfig)
+	if err != nil {
+		return err
+	}
+	monitors := dynClient.Resource(serviceMonitorGVR).Namespace(ns)
+	existingMonitor, err := monitors.Get(ctx, name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = monitors.Create(ctx, monitor, metav1.CreateOptions{})
+	} else if err == nil {
+		monitor.SetResourceVersion(existingMonitor.GetResourceVersion())
+		_, err = monitors.Update(ctx, monitor, metav1.UpdateOptions{})
+	}
+	return errors.WithStack(err)
+}
+
+func removeMetricsScrape(ctx context.Context, client *ClusterClient, a *appTypes.App, ns string) error {
+	name := metricsServiceName(a)
+	err := client.CoreV1().Services(ns).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
+	dynClient, err := DynamicClientForConfig(client.restConfig)
+	if err != nil {
+		return err
+	}
+	err = dynClient.Resource(serviceMonitorGVR).Namespace(ns).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func (p *kubernetesProvisioner) MetricsScrape(ctx context.Context, a *appTypes.App) (*provTypes.MetricsScrape, error) {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, a)
+	if err != nil {
+		if err == appTypes.ErrNoVersionsAvailable {
+			return nil, nil
+		}
+		return nil, err
+	}
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !validMetrics(yamlData.Metrics) {
+		return nil, nil
+	}
+	result := &provTypes.MetricsScrape{
+		Mode:     client.metricsScrapeMode(a.Pool),
+		Port:     yamlData.Metrics.Port,
+		Path:     yamlData.Metrics.MetricsPath(),
+		Interval: yamlData.Metrics.Interval,
+	}
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	if result.Mode == metricsScrapeServiceMonitor {
+		dynClient, err := DynamicClientForConfig(client.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		_, err = dynClient.Resource(serviceMonitorGVR).Namespace(ns).Get(ctx, metricsServiceName(a), metav1.GetOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return nil, errors.WithStack(err)
+		}
+		result.Configured = err == nil
+		return result, nil
+	}
+	deps, err := allDeploymentsForAppNS(ctx, client, ns, a)
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range deps {
+		if dep.Spec.Template.Annotations["prometheus.io/scrape"] == "true" {
+			result.Configured = true
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+var metricsCustomData = map[string]interface{}{
+	"processes": map[string]interface{}{
+		"web": "run mycmd arg1",
+	},
+	"metrics": map[string]interface{}{
+		"port":     9090,
+		"interval": "15s",
+	},
+}
+
+func (s *S) TestPodMetricsAnnotations(c *check.C) {
+	metrics := &provTypes.TsuruYamlMetrics{Port: 9090, Path: "/prom"}
+	c.Assert(podMetricsAnnotations(metricsScrapeAnnotations, metrics), check.DeepEquals, map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   "9090",
+		"prometheus.io/path":   "/prom",
+	})
+	c.Assert(podMetricsAnnotations(metricsScrapeServiceMonitor, metrics), check.IsNil)
+	c.Assert(podMetricsAnnotations(metricsScrapeAnnotations, nil), check.IsNil)
+	c.Assert(podMetricsAnnotations(metricsScrapeAnnotations, &provTypes.TsuruYamlMetrics{}), check.IsNil)
+}
+
+func (s *S) TestDeployWithMetricsAnnotations(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, metricsCustomData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Event: evt})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	wait()
+	dep, err := s.client.AppsV1().Deployments("default").Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Annotations["prometheus.io/port"], check.Equals, "9090")
+	c.Assert(dep.Spec.Template.Annotations["prometheus.io/path"], check.Equals, "/metrics")
+	c.Assert(dep.Spec.Template.Annotations["prometheus.io/interval"], check.Equals, "15s")
+	_, ok := dep.Annotations["prometheus.io/scrape"]
+	c.Assert(ok, check.Equals, false)
+	scrape, err := s.p.MetricsScrape(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(scrape, check.DeepEquals, &provTypes.MetricsScrape{
+		Mode:       metricsScrapeAnnotations,
+		Port:       9090,
+		Path:       "/metrics",
+		Interval:   "15s",
+		Configured: true,
+	})
+}
+
+func (s *S) TestDeployWithMetricsServiceMonitor(c *check.C) {
+	s.clusterClient.CustomData[metricsScrapeKey] = metricsScrapeServiceMonitor
+	defer delete(s.clusterClient.CustomData, metricsScrapeKey)
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		serviceMonitorGVR: "ServiceMonitorList",
+	})
+	oldDynamicClient := DynamicClientForConfig
+	DynamicClientForConfig = func(conf *rest.Config) (dynamic.Interface, error) {
+		return dynClient, nil
+	}
+	defer func() { DynamicClientForConfig = oldDynamicClient }()
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, metricsCustomData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Event: evt})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	wait()
+	dep, err := s.client.AppsV1().Deployments("default").Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	_, ok := dep.Spec.Template.Annotations["prometheus.io/scrape"]
+	c.Assert(ok, check.Equals, false)
+	svc, err := s.client.CoreV1().Services("default").Get(context.TODO(), "myapp-tsuru-metrics", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(svc.Spec.Ports[0].Port, check.Equals, int32(9090))
+	c.Assert(svc.Spec.Selector["tsuru.io/app-name"], check.Equals, "myapp")
+	monitor, err := dynClient.Resource(serviceMonitorGVR).Namespace("default").Get(context.TODO(), "myapp-tsuru-metrics", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	endpoints, _, err := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+	c.Assert(err, check.IsNil)
+	c.Assert(endpoints, check.DeepEquals, []interface{}{
+		map[string]interface{}{"port": "metrics", "path": "/metrics", "interval": "15s"},
+	})
+	scrape, err := s.p.MetricsScrape(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(scrape.Mode, check.Equals, metricsScrapeServiceMonitor)
+	c.Assert(scrape.Configured, check.Equals, true)
+	version = newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "run mycmd arg1",
+		},
+	})
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Event: evt})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	wait()
+	_, err = dynClient.Resource(serviceMonitorGVR).Namespace("default").Get(context.TODO(), "myapp-tsuru-metrics", metav1.GetOptions{})
+	c.Assert(err, check.NotNil)
+	_, err = s.client.CoreV1().Services("default").Get(context.TODO(), "myapp-tsuru-metrics", metav1.GetOptions{})
+	c.Assert(err, check.NotNil)
+}
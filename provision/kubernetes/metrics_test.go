@@ -76,15 +76,54 @@ func (s *S) Test_MetricsProvisioner_UnitsMetrics(c *check.C) {
 		}, nil
 	})
 	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().Pods("default").Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      a.Name + "-123",
+			Namespace: "default",
+			Labels: map[string]string{
+				"tsuru.io/app-name":    a.Name,
+				"tsuru.io/app-process": "web",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: a.Name + "-123-web",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"cpu":    resource.MustParse("1"),
+							"memory": resource.MustParse("128Mi"),
+						},
+						Limits: corev1.ResourceList{
+							"memory": resource.MustParse("256Mi"),
+						},
+					},
+				},
+				{
+					Name: a.Name + "-123-sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"cpu": resource.MustParse("100m"),
+						},
+					},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
 
 	metrics, err := s.p.UnitsMetrics(context.TODO(), a)
 	c.Assert(err, check.IsNil)
 	c.Assert(metrics, check.HasLen, 1)
 	c.Assert(metrics, check.DeepEquals, []provTypes.UnitMetric{
 		{
-			ID:     "myapp-123",
-			CPU:    "2200m",
-			Memory: "110Mi",
+			ID:            "myapp-123",
+			ProcessName:   "web",
+			CPU:           "2200m",
+			Memory:        "110Mi",
+			CPURequest:    "1100m",
+			MemoryRequest: "128Mi",
+			MemoryLimit:   "256Mi",
 		},
 	})
 
@@ -169,9 +169,10 @@ func (c *clusterController) startJobInformer() error {
 				return
 			}
 			wg := &sync.WaitGroup{}
-			wg.Add(2)
+			wg.Add(3)
 			go createJobEvent(c.cluster, job, evt, wg)
 			go incrementJobMetrics(job, evt, wg)
+			go recordJobExecution(c.cluster, job, evt, wg)
 			wg.Wait()
 		},
 	})
@@ -200,6 +201,9 @@ func (c *clusterController) start() (v1informers.PodInformer, error) {
 				return
 			}
 			c.notifyPodChanges(newPod)
+			if oldPod, ok := oldObj.(*apiv1.Pod); ok {
+				c.recordUnitStatusChanges(oldPod, newPod)
+			}
 		},
 	})
 
@@ -0,0 +1,162 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+func (p *kubernetesProvisioner) ListNodes(ctx context.Context, c *provTypes.Cluster) ([]provTypes.ClusterNode, error) {
+	client, err := NewClusterClient(c)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	result := make([]provTypes.ClusterNode, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		result = append(result, clusterNodeFromNode(&node))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func clusterNodeFromNode(node *apiv1.Node) provTypes.ClusterNode {
+	result := provTypes.ClusterNode{
+		Name:          node.Name,
+		Unschedulable: node.Spec.Unschedulable,
+		Labels:        node.Labels,
+		Annotations:   node.Annotations,
+	}
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == apiv1.NodeInternalIP {
+			result.Address = addr.Address
+			break
+		}
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == apiv1.NodeReady {
+			result.Ready = cond.Status == apiv1.ConditionTrue
+			break
+		}
+	}
+	return result
+}
+
+func (p *kubernetesProvisioner) CordonNode(ctx context.Context, c *provTypes.Cluster, name string, unschedulable bool) error {
+	client, err := NewClusterClient(c)
+	if err != nil {
+		return err
+	}
+	return cordonNode(ctx, client, name, unschedulable)
+}
+
+func cordonNode(ctx context.Context, client *ClusterClient, name string, unschedulable bool) error {
+	return patchNode(ctx, client, name, map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": unschedulable},
+	})
+}
+
+func (p *kubernetesProvisioner) AnnotateNode(ctx context.Context, c *provTypes.Cluster, name string, annotations map[string]string) error {
+	client, err := NewClusterClient(c)
+	if err != nil {
+		return err
+	}
+	patchAnnotations := map[string]interface{}{}
+	for k, v := range annotations {
+		if v == "" {
+			// null values remove the key in JSON merge patches.
+			patchAnnotations[k] = nil
+			continue
+		}
+		patchAnnotations[k] = v
+	}
+	return patchNode(ctx, client, name, map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": patchAnnotations},
+	})
+}
+
+func patchNode(ctx context.Context, client *ClusterClient, name string, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = client.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return provision.ErrNodeNotFound
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DrainNode cordons the node and evicts its pods, respecting their
+// disruption budgets. Pods managed by daemon sets and mirror pods are left
+// running.
+func (p *kubernetesProvisioner) DrainNode(ctx context.Context, c *provTypes.Cluster, name string, w io.Writer) error {
+	client, err := NewClusterClient(c)
+	if err != nil {
+		return err
+	}
+	err = cordonNode(ctx, client, name, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "node %q cordoned\n", name)
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, pod := range pods.Items {
+		if !shouldEvictPod(&pod) {
+			continue
+		}
+		fmt.Fprintf(w, "evicting pod %s/%s\n", pod.Namespace, pod.Name)
+		err = evictPodRespectingBudget(ctx, client, pod.Namespace, pod.Name, w)
+		if err != nil {
+			return errors.Wrapf(err, "unable to evict pod %s/%s", pod.Namespace, pod.Name)
+		}
+	}
+	fmt.Fprintf(w, "node %q drained\n", name)
+	return nil
+}
+
+func shouldEvictPod(pod *apiv1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+		return false
+	}
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
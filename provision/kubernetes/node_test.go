@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/provision"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func (s *S) createTestNode(c *check.C, name string) {
+	_, err := s.client.CoreV1().Nodes().Create(context.TODO(), &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{"tsuru.io/pool": "pool1"},
+			Annotations: map[string]string{"a1": "v1"},
+		},
+		Status: apiv1.NodeStatus{
+			Addresses: []apiv1.NodeAddress{
+				{Type: apiv1.NodeHostName, Address: name},
+				{Type: apiv1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			Conditions: []apiv1.NodeCondition{
+				{Type: apiv1.NodeReady, Status: apiv1.ConditionTrue},
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestListNodes(c *check.C) {
+	s.createTestNode(c, "n2")
+	_, err := s.client.CoreV1().Nodes().Create(context.TODO(), &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Spec:       apiv1.NodeSpec{Unschedulable: true},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	nodes, err := s.p.ListNodes(context.TODO(), s.clusterClient.Cluster)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.DeepEquals, []provTypes.ClusterNode{
+		{Name: "n1", Unschedulable: true},
+		{
+			Name:        "n2",
+			Address:     "10.0.0.1",
+			Ready:       true,
+			Labels:      map[string]string{"tsuru.io/pool": "pool1"},
+			Annotations: map[string]string{"a1": "v1"},
+		},
+	})
+}
+
+func (s *S) TestCordonNode(c *check.C) {
+	s.createTestNode(c, "n1")
+	err := s.p.CordonNode(context.TODO(), s.clusterClient.Cluster, "n1", true)
+	c.Assert(err, check.IsNil)
+	node, err := s.client.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Spec.Unschedulable, check.Equals, true)
+	err = s.p.CordonNode(context.TODO(), s.clusterClient.Cluster, "n1", false)
+	c.Assert(err, check.IsNil)
+	node, err = s.client.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Spec.Unschedulable, check.Equals, false)
+	err = s.p.CordonNode(context.TODO(), s.clusterClient.Cluster, "n2", true)
+	c.Assert(err, check.Equals, provision.ErrNodeNotFound)
+}
+
+func (s *S) TestAnnotateNode(c *check.C) {
+	s.createTestNode(c, "n1")
+	err := s.p.AnnotateNode(context.TODO(), s.clusterClient.Cluster, "n1", map[string]string{"a1": "", "a2": "v2"})
+	c.Assert(err, check.IsNil)
+	node, err := s.client.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Annotations, check.DeepEquals, map[string]string{"a2": "v2"})
+}
+
+func (s *S) TestDrainNode(c *check.C) {
+	s.createTestNode(c, "n1")
+	pods := []apiv1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "myapp-web-1", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "agent-1",
+			Namespace:       "kube-system",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "static-n1",
+			Namespace:   "kube-system",
+			Annotations: map[string]string{mirrorPodAnnotation: "x"},
+		}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "myjob-1", Namespace: "default"},
+			Status:     apiv1.PodStatus{Phase: apiv1.PodSucceeded},
+		},
+	}
+	for _, pod := range pods {
+		pod.Spec.NodeName = "n1"
+		_, err := s.client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+		c.Assert(err, check.IsNil)
+	}
+	var evicted []string
+	blocked := true
+	s.client.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(ktesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if blocked {
+			blocked = false
+			return true, nil, k8sErrors.NewTooManyRequests("disruption budget", 0)
+		}
+		evicted = append(evicted, eviction.Namespace+"/"+eviction.Name)
+		return true, nil, nil
+	})
+	defer func(interval time.Duration) { rebalanceEvictionRetryInterval = interval }(rebalanceEvictionRetryInterval)
+	rebalanceEvictionRetryInterval = time.Millisecond
+	buf := &bytes.Buffer{}
+	err := s.p.DrainNode(context.TODO(), s.clusterClient.Cluster, "n1", buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(evicted, check.DeepEquals, []string{"default/myapp-web-1"})
+	c.Assert(buf.String(), check.Equals, "node \"n1\" cordoned\nevicting pod default/myapp-web-1\n  ---> Eviction of unit myapp-web-1 blocked by disruption budget, waiting\nnode \"n1\" drained\n")
+	node, err := s.client.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Spec.Unschedulable, check.Equals, true)
+}
+
+func (s *S) TestDrainNodeNotFound(c *check.C) {
+	err := s.p.DrainNode(context.TODO(), s.clusterClient.Cluster, "n1", &bytes.Buffer{})
+	c.Assert(err, check.Equals, provision.ErrNodeNotFound)
+}
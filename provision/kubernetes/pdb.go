@@ -8,8 +8,11 @@ import (
 	"context"
 	"reflect"
 
+	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
 	policyv1 "k8s.io/api/policy/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,8 +20,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func ensurePDB(ctx context.Context, client *ClusterClient, app *appTypes.App, process string) error {
-	pdb, err := newPDB(ctx, client, app, process)
+func ensurePDB(ctx context.Context, client *ClusterClient, app *appTypes.App, process string, version appTypes.AppVersion) error {
+	pdb, err := newPDB(ctx, client, app, process, version)
 	if err != nil {
 		return err
 	}
@@ -41,6 +44,83 @@ func ensurePDB(ctx context.Context, client *ClusterClient, app *appTypes.App, pr
 	return err
 }
 
+// ensureAppPDBs reconciles the PDBs of every process of the app with units,
+// using the disruption budgets of the latest successful version.
+func ensureAppPDBs(ctx context.Context, client *ClusterClient, app *appTypes.App) error {
+	depsData, err := deploymentsDataForApp(ctx, client, app)
+	if err != nil {
+		return err
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
+	if err != nil && err != appTypes.ErrNoVersionsAvailable {
+		return err
+	}
+	processes := map[string]struct{}{}
+	for _, deps := range depsData.versioned {
+		for _, dep := range deps {
+			if _, ok := processes[dep.process]; ok {
+				continue
+			}
+			processes[dep.process] = struct{}{}
+			err = ensurePDB(ctx, client, app, dep.process, version)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateDisruptionBudgets checks the disruption budgets declared in the
+// tsuru.yaml of the version.
+func validateDisruptionBudgets(version appTypes.AppVersion) error {
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return err
+	}
+	for _, p := range yamlData.Processes {
+		if p.DisruptionBudget == nil {
+			continue
+		}
+		if err = p.DisruptionBudget.Validate(); err != nil {
+			return errors.Errorf("invalid disruption budget for process %q in tsuru.yaml: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// disruptionBudgetFor returns the disruption budget set for the process in
+// the app, falling back to the one declared in the tsuru.yaml of the version.
+func disruptionBudgetFor(app *appTypes.App, process string, version appTypes.AppVersion) (*provTypes.DisruptionBudget, error) {
+	for _, p := range app.Processes {
+		if p.Name == process && !p.DisruptionBudget.Empty() {
+			return p.DisruptionBudget, nil
+		}
+	}
+	if version == nil {
+		return nil, nil
+	}
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return nil, err
+	}
+	budget := yamlData.DisruptionBudget(process)
+	if budget.Empty() {
+		return nil, nil
+	}
+	return budget, nil
+}
+
+func processDisruptionBudgets(app *appTypes.App) map[string]*provTypes.DisruptionBudget {
+	budgets := map[string]*provTypes.DisruptionBudget{}
+	for _, p := range app.Processes {
+		if !p.DisruptionBudget.Empty() {
+			budgets[p.Name] = p.DisruptionBudget
+		}
+	}
+	return budgets
+}
+
 func allPDBsForApp(ctx context.Context, client *ClusterClient, app *appTypes.App) ([]policyv1.PodDisruptionBudget, error) {
 	ns, err := client.AppNamespace(ctx, app)
 	if err != nil {
@@ -77,16 +157,26 @@ func removeAllPDBs(ctx context.Context, client *ClusterClient, app *appTypes.App
 	return nil
 }
 
-func newPDB(ctx context.Context, client *ClusterClient, app *appTypes.App, process string) (*policyv1.PodDisruptionBudget, error) {
+func newPDB(ctx context.Context, client *ClusterClient, app *appTypes.App, process string, version appTypes.AppVersion) (*policyv1.PodDisruptionBudget, error) {
 	if client.disablePDB(app.Pool) {
 		return nil, nil
 	}
 
-	maxUnavailable := "10%"
-	if value, ok := provision.GetAppMetadata(app, process).Annotation("app.tsuru.io/k8s-pdb-max-unavailable"); ok {
-		maxUnavailable = value
+	budget, err := disruptionBudgetFor(app, process, version)
+	if err != nil {
+		return nil, err
+	}
+	var spec policyv1.PodDisruptionBudgetSpec
+	if budget != nil {
+		spec.MinAvailable = budget.MinAvailable
+		spec.MaxUnavailable = budget.MaxUnavailable
+	} else {
+		maxUnavailable := "10%"
+		if value, ok := provision.GetAppMetadata(app, process).Annotation("app.tsuru.io/k8s-pdb-max-unavailable"); ok {
+			maxUnavailable = value
+		}
+		spec.MaxUnavailable = intOrStringPtr(intstr.FromString(maxUnavailable))
 	}
-	maxUnavailableByProcess := intstr.FromString(maxUnavailable)
 
 	ns, err := client.AppNamespace(ctx, app)
 	if err != nil {
@@ -94,6 +184,7 @@ func newPDB(ctx context.Context, client *ClusterClient, app *appTypes.App, proce
 	}
 	routableLabels := pdbLabels(app, process)
 	routableLabels.SetIsRoutable()
+	spec.Selector = &metav1.LabelSelector{MatchLabels: routableLabels.ToRoutableSelector()}
 
 	return &policyv1.PodDisruptionBudget{
 		ObjectMeta: metav1.ObjectMeta{
@@ -101,10 +192,7 @@ func newPDB(ctx context.Context, client *ClusterClient, app *appTypes.App, proce
 			Namespace: ns,
 			Labels:    pdbLabels(app, process).ToLabels(),
 		},
-		Spec: policyv1.PodDisruptionBudgetSpec{
-			MaxUnavailable: &maxUnavailableByProcess,
-			Selector:       &metav1.LabelSelector{MatchLabels: routableLabels.ToRoutableSelector()},
-		},
+		Spec: spec,
 	}, nil
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/tsuru/tsuru/app"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
 	check "gopkg.in/check.v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +19,7 @@ import (
 func (s *S) TestNewPDB(c *check.C) {
 	tests := map[string]struct {
 		app      *appTypes.App
+		version  appTypes.AppVersion
 		setup    func() (teardown func())
 		expected *policyv1.PodDisruptionBudget
 	}{
@@ -82,6 +84,37 @@ func (s *S) TestNewPDB(c *check.C) {
 				},
 			},
 		},
+		"with process disruption budget": {
+			app: &appTypes.App{
+				Name:      "myapp-04",
+				TeamOwner: s.team.Name,
+				Processes: []appTypes.Process{
+					{Name: "p1", DisruptionBudget: &provTypes.DisruptionBudget{MinAvailable: intOrStringPtr(intstr.FromInt(2))}},
+				},
+			},
+			expected: &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myapp-04-p1",
+					Namespace: "default",
+					Labels: map[string]string{
+						"tsuru.io/is-tsuru":    "true",
+						"tsuru.io/app-name":    "myapp-04",
+						"tsuru.io/app-process": "p1",
+						"tsuru.io/app-team":    "admin",
+					},
+				},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					MinAvailable: intOrStringPtr(intstr.FromInt(2)),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"tsuru.io/app-name":    "myapp-04",
+							"tsuru.io/app-process": "p1",
+							"tsuru.io/is-routable": "true",
+						},
+					},
+				},
+			},
+		},
 		"when disable PDB for cluster/pool": {
 			app: &appTypes.App{Name: "myapp-03", TeamOwner: s.team.Name},
 			setup: func() (teardown func()) {
@@ -101,7 +134,7 @@ func (s *S) TestNewPDB(c *check.C) {
 		err := app.CreateApp(context.TODO(), tt.app, s.user)
 		c.Assert(err, check.IsNil)
 
-		pdb, err := newPDB(context.TODO(), s.clusterClient, tt.app, "p1")
+		pdb, err := newPDB(context.TODO(), s.clusterClient, tt.app, "p1", tt.version)
 		c.Assert(err, check.IsNil)
 		c.Assert(pdb, check.DeepEquals, tt.expected)
 		if teardown != nil {
@@ -109,3 +142,34 @@ func (s *S) TestNewPDB(c *check.C) {
 		}
 	}
 }
+
+func (s *S) TestNewPDBWithTsuruYamlDisruptionBudget(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newEmptyVersion(c, a)
+	err = version.AddData(appTypes.AddVersionDataArgs{
+		Processes: map[string][]string{"p1": {"run"}},
+		CustomData: map[string]interface{}{
+			"processes": []interface{}{
+				map[string]interface{}{
+					"name":              "p1",
+					"command":           "run",
+					"disruption-budget": map[string]interface{}{"maxUnavailable": "50%"},
+				},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	pdb, err := newPDB(context.TODO(), s.clusterClient, a, "p1", version)
+	c.Assert(err, check.IsNil)
+	c.Assert(pdb.Spec.MaxUnavailable, check.DeepEquals, intOrStringPtr(intstr.FromString("50%")))
+	c.Assert(pdb.Spec.MinAvailable, check.IsNil)
+	a.Processes = []appTypes.Process{
+		{Name: "p1", DisruptionBudget: &provTypes.DisruptionBudget{MinAvailable: intOrStringPtr(intstr.FromString("80%"))}},
+	}
+	pdb, err = newPDB(context.TODO(), s.clusterClient, a, "p1", version)
+	c.Assert(err, check.IsNil)
+	c.Assert(pdb.Spec.MinAvailable, check.DeepEquals, intOrStringPtr(intstr.FromString("80%")))
+	c.Assert(pdb.Spec.MaxUnavailable, check.IsNil)
+}
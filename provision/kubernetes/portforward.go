@@ -0,0 +1,118 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+func (p *kubernetesProvisioner) PortForward(ctx context.Context, opts provision.PortForwardOptions) error {
+	client, err := clusterForPool(ctx, opts.App.Pool)
+	if err != nil {
+		return err
+	}
+	ns, err := client.AppNamespace(ctx, opts.App)
+	if err != nil {
+		return err
+	}
+	pod, err := client.CoreV1().Pods(ns).Get(ctx, opts.Unit, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(errors.Cause(err)) {
+			return &provision.UnitNotFoundError{ID: opts.Unit}
+		}
+		return errors.WithStack(err)
+	}
+	l := labelSetFromMeta(&pod.ObjectMeta)
+	if l.AppName() != opts.App.Name {
+		return errors.Errorf("pod %q do not belong to app %q", pod.Name, opts.App.Name)
+	}
+	restCli, err := rest.RESTClientFor(client.restConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req := restCli.Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(ns).
+		SubResource("portforward")
+	transport, upgrader, err := spdy.RoundTripperFor(client.restConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to connect to unit %q", pod.Name)
+	}
+	defer streamConn.Close()
+	return forwardPort(streamConn, opts.Port, opts.Conn)
+}
+
+// forwardPort copies data between conn and the port through the streams of
+// the port forward protocol, the same way kubectl port-forward does for each
+// connection it accepts.
+func forwardPort(streamConn httpstream.Connection, port int, conn io.ReadWriter) error {
+	headers := http.Header{}
+	headers.Set(apiv1.StreamType, apiv1.StreamTypeError)
+	headers.Set(apiv1.PortHeader, strconv.Itoa(port))
+	headers.Set(apiv1.PortForwardRequestIDHeader, "0")
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create error stream for port %d", port)
+	}
+	// Nothing is written to the error stream, only read from it.
+	errorStream.Close()
+	errCh := make(chan error, 1)
+	go func() {
+		message, readErr := io.ReadAll(errorStream)
+		switch {
+		case readErr != nil:
+			errCh <- errors.Wrapf(readErr, "unable to read error stream for port %d", port)
+		case len(message) > 0:
+			errCh <- errors.Errorf("unable to forward port %d: %s", port, message)
+		}
+		close(errCh)
+	}()
+	headers.Set(apiv1.StreamType, apiv1.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create data stream for port %d", port)
+	}
+	remoteDone := make(chan struct{})
+	localDone := make(chan struct{})
+	go func() {
+		io.Copy(conn, dataStream)
+		close(remoteDone)
+	}()
+	go func() {
+		// Closing the data stream tells the unit nothing else will be sent.
+		defer dataStream.Close()
+		io.Copy(dataStream, conn)
+		close(localDone)
+	}()
+	select {
+	case <-remoteDone:
+	case <-localDone:
+		// The client is gone, there's no one left to receive the rest of
+		// the data from the unit.
+		streamConn.Close()
+		<-errCh
+		return nil
+	}
+	return <-errCh
+}
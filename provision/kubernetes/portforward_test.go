@@ -0,0 +1,57 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestPortForwardUnitNotFound(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	version := newSuccessfulVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	err := s.p.AddUnits(context.TODO(), a, 1, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	wait()
+	err = s.p.PortForward(context.TODO(), provision.PortForwardOptions{
+		App:  a,
+		Unit: "invalid-unit",
+		Port: 5005,
+		Conn: bytes.NewBuffer(nil),
+	})
+	c.Assert(err, check.DeepEquals, &provision.UnitNotFoundError{ID: "invalid-unit"})
+}
+
+func (s *S) TestPortForwardUnitFromOtherApp(c *check.C) {
+	a, _, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().Pods(ns).Create(context.TODO(), &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "otherapp-web-pod-1",
+			Namespace: ns,
+			Labels:    map[string]string{"tsuru.io/app-name": "otherapp"},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	err = s.p.PortForward(context.TODO(), provision.PortForwardOptions{
+		App:  a,
+		Unit: "otherapp-web-pod-1",
+		Port: 5005,
+		Conn: bytes.NewBuffer(nil),
+	})
+	c.Assert(err, check.ErrorMatches, `pod "otherapp-web-pod-1" do not belong to app "myapp"`)
+}
@@ -62,6 +62,7 @@ const (
 	defaultDeploymentProgressTimeout           = 10 * time.Minute
 	defaultAttachTimeoutAfterContainerFinished = time.Minute
 	defaultPreStopSleepSeconds                 = 10
+	defaultProbeTimeoutSeconds                 = 5
 )
 
 var (
@@ -80,22 +81,27 @@ type kubernetesProvisioner struct {
 }
 
 var (
-	_ provision.Provisioner              = &kubernetesProvisioner{}
-	_ provision.MessageProvisioner       = &kubernetesProvisioner{}
-	_ provision.VolumeProvisioner        = &kubernetesProvisioner{}
-	_ provision.BuilderDeploy            = &kubernetesProvisioner{}
-	_ provision.InitializableProvisioner = &kubernetesProvisioner{}
-	_ provision.InterAppProvisioner      = &kubernetesProvisioner{}
-	_ provision.HCProvisioner            = &kubernetesProvisioner{}
-	_ provision.VersionsProvisioner      = &kubernetesProvisioner{}
-	_ provision.LogsProvisioner          = &kubernetesProvisioner{}
-	_ provision.MetricsProvisioner       = &kubernetesProvisioner{}
-	_ provision.AutoScaleProvisioner     = &kubernetesProvisioner{}
-	_ cluster.ClusteredProvisioner       = &kubernetesProvisioner{}
-	_ provision.UpdatableProvisioner     = &kubernetesProvisioner{}
-	_ provision.MultiRegistryProvisioner = &kubernetesProvisioner{}
-	_ provision.KillUnitProvisioner      = &kubernetesProvisioner{}
-	_ provision.JobProvisioner           = &kubernetesProvisioner{}
+	_ provision.Provisioner                 = &kubernetesProvisioner{}
+	_ provision.MessageProvisioner          = &kubernetesProvisioner{}
+	_ provision.VolumeProvisioner           = &kubernetesProvisioner{}
+	_ provision.BuilderDeploy               = &kubernetesProvisioner{}
+	_ provision.InitializableProvisioner    = &kubernetesProvisioner{}
+	_ provision.InterAppProvisioner         = &kubernetesProvisioner{}
+	_ provision.HCProvisioner               = &kubernetesProvisioner{}
+	_ provision.VersionsProvisioner         = &kubernetesProvisioner{}
+	_ provision.LogsProvisioner             = &kubernetesProvisioner{}
+	_ provision.MetricsProvisioner          = &kubernetesProvisioner{}
+	_ provision.AutoScaleProvisioner        = &kubernetesProvisioner{}
+	_ cluster.ClusteredProvisioner          = &kubernetesProvisioner{}
+	_ cluster.FeatureFlagsProvisioner       = &kubernetesProvisioner{}
+	_ provision.UpdatableProvisioner        = &kubernetesProvisioner{}
+	_ provision.MultiRegistryProvisioner    = &kubernetesProvisioner{}
+	_ provision.KillUnitProvisioner         = &kubernetesProvisioner{}
+	_ provision.JobProvisioner              = &kubernetesProvisioner{}
+	_ provision.WorkloadIdentityProvisioner = &kubernetesProvisioner{}
+	_ provision.UnitRebalanceProvisioner    = &kubernetesProvisioner{}
+	_ provision.DriftProvisioner            = &kubernetesProvisioner{}
+	_ provision.JanitorProvisioner          = &kubernetesProvisioner{}
 
 	mainKubernetesProvisioner *kubernetesProvisioner
 )
@@ -297,6 +303,20 @@ func (p *kubernetesProvisioner) ClusterHelp() provTypes.ClusterHelpInfo {
 	}
 }
 
+func (p *kubernetesProvisioner) ClusterFeatures(c *provTypes.Cluster, pool string) []provTypes.ClusterFeature {
+	client := &ClusterClient{Cluster: c}
+	features := make([]provTypes.ClusterFeature, 0, len(clusterFeatures))
+	for name, description := range clusterFeatures {
+		features = append(features, provTypes.ClusterFeature{
+			Name:        name,
+			Description: description,
+			Enabled:     client.featureEnabled(pool, name),
+		})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return features
+}
+
 func (p *kubernetesProvisioner) DeleteCluster(ctx context.Context, c *provTypes.Cluster) error {
 	stopClusterControllerByName(p, c.Name)
 	return nil
@@ -463,9 +483,22 @@ func (p *kubernetesProvisioner) removeResources(ctx context.Context, client *Clu
 			}
 		}
 	}
+	if client.metricsScrapeMode(app.Pool) == metricsScrapeServiceMonitor {
+		if err = removeMetricsScrape(ctx, client, app, tsuruApp.Spec.NamespaceName); err != nil {
+			multiErrors.Add(err)
+		}
+	}
 	if err = removeAllPDBs(ctx, client, app); err != nil {
 		multiErrors.Add(errors.WithStack(err))
 	}
+	if err = removeEgressPolicy(ctx, client, app); err != nil {
+		multiErrors.Add(errors.WithStack(err))
+	}
+	for _, labelSet := range []*provision.LabelSet{appFilesLabels(app), appSecretsLabels(app)} {
+		if err = removeAppObjects(ctx, client, tsuruApp.Spec.NamespaceName, labelSet, nil); err != nil {
+			multiErrors.Add(err)
+		}
+	}
 	err = client.CoreV1().ServiceAccounts(tsuruApp.Spec.NamespaceName).Delete(ctx, tsuruApp.Spec.ServiceAccountName, metav1.DeleteOptions{})
 	if err != nil && !k8sErrors.IsNotFound(err) {
 		multiErrors.Add(errors.WithStack(err))
@@ -570,7 +603,7 @@ func patchDeployment(ctx context.Context, client *ClusterClient, a *appTypes.App
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	_, err = monitorDeployment(ctx, client, newDep, a, process, w, events.ResourceVersion, version)
+	_, err = monitorDeployment(ctx, client, newDep, a, process, w, events.ResourceVersion, version, nil)
 	if err != nil {
 		if _, ok := err.(provision.ErrUnitStartup); ok {
 			return err
@@ -1075,21 +1108,40 @@ func (p *kubernetesProvisioner) Deploy(ctx context.Context, args provision.Deplo
 	if args.Version.VersionInfo().DeployImage == "" {
 		return "", errors.New("no build image found")
 	}
+	if err = validateDisruptionBudgets(args.Version); err != nil {
+		return "", err
+	}
+	if err = validateShutdownAndRollouts(args.Version); err != nil {
+		return "", err
+	}
+	if err = validateSidecars(args.Version); err != nil {
+		return "", err
+	}
 	manager := &serviceManager{
 		client: client,
 		writer: args.Event,
+		event:  args.Event,
 	}
-	var oldVersionNumber int
-	if !args.PreserveVersions {
-		oldVersionNumber, err = baseVersionForApp(ctx, client, args.App)
-		if err != nil {
-			return "", err
-		}
+	strategy, err := deployStrategyFor(args.Strategy)
+	if err != nil {
+		return "", err
+	}
+	err = runDeployHooks(ctx, client, args, deployHookBefore)
+	if err != nil {
+		return "", err
 	}
-	err = servicecommon.RunServicePipeline(ctx, manager, oldVersionNumber, args, nil)
+	err = strategy.deploy(ctx, manager, args)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
+	err = runDeployHooks(ctx, client, args, deployHookAfter)
+	if err != nil {
+		return "", err
+	}
+	err = ensureMetricsScrape(ctx, client, args.App, args.Version)
+	if err != nil {
+		return "", err
+	}
 	err = ensureAppCustomResourceSynced(ctx, client, args.App)
 	if err != nil {
 		return "", err
@@ -1150,11 +1202,13 @@ func runIsolatedCmdPod(ctx context.Context, client *ClusterClient, opts execOpts
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	var version appTypes.AppVersion
+	version := opts.version
 	if opts.image == "" {
-		version, err = servicemanager.AppVersion.LatestSuccessfulVersion(ctx, opts.app)
-		if err != nil {
-			return errors.WithStack(err)
+		if version == nil {
+			version, err = servicemanager.AppVersion.LatestSuccessfulVersion(ctx, opts.app)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 		}
 		opts.image = version.VersionInfo().DeployImage
 	}
@@ -1166,6 +1220,9 @@ func runIsolatedCmdPod(ctx context.Context, client *ClusterClient, opts execOpts
 
 	plan := opts.app.Plan
 	pool := opts.app.Pool
+	if opts.task != nil {
+		plan = taskPlan(plan, opts.task)
+	}
 	requirements, err := resourceRequirements(&plan, pool, client, requirementsFactors{
 		overCommit: 1,
 	})
@@ -1173,7 +1230,7 @@ func runIsolatedCmdPod(ctx context.Context, client *ClusterClient, opts execOpts
 		return err
 	}
 
-	return runPod(ctx, runSinglePodArgs{
+	args := runSinglePodArgs{
 		client:       client,
 		eventsOutput: opts.eventsOutput,
 		stdout:       opts.stdout,
@@ -1187,7 +1244,16 @@ func runIsolatedCmdPod(ctx context.Context, client *ClusterClient, opts execOpts
 		name:         baseName,
 		requirements: requirements,
 		app:          opts.app,
-	})
+	}
+	if opts.task != nil {
+		args.name = taskPodNameForApp(opts.app)
+		args.deadline = int64(opts.task.Timeout.Seconds())
+		args.volumes, args.mounts, err = createVolumesForApp(ctx, client, opts.app)
+		if err != nil {
+			return err
+		}
+	}
+	return runPod(ctx, args)
 }
 
 func (p *kubernetesProvisioner) StartupMessage() (string, error) {
@@ -1222,13 +1288,28 @@ func (p *kubernetesProvisioner) IsVolumeProvisioned(ctx context.Context, volumeN
 }
 
 func (p *kubernetesProvisioner) ValidateVolume(ctx context.Context, vol *volumeTypes.Volume) error {
-	_, err := validateVolume(vol)
-	return err
+	opts, err := validateVolume(vol)
+	if err != nil {
+		return err
+	}
+	if vol.IsShared() && (opts.Plugin == "" || !opts.isPersistent()) {
+		return &tsuruErrors.ValidationError{Message: "only volumes using a persistent volume plugin, like nfs, may be shared across pools"}
+	}
+	return validateVolumeOpts(vol, opts)
 }
 
 func (p *kubernetesProvisioner) UpdateApp(ctx context.Context, old, new *appTypes.App, w io.Writer) error {
 	if old.Pool == new.Pool {
-		return nil
+		if reflect.DeepEqual(processDisruptionBudgets(old), processDisruptionBudgets(new)) {
+			return nil
+		}
+		client, err := clusterForPool(ctx, new.Pool)
+		if errors.Cause(err) == provTypes.ErrNoCluster {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return ensureAppPDBs(ctx, client, new)
 	}
 
 	oldClient, err := clusterForPool(ctx, old.Pool)
@@ -1242,7 +1323,15 @@ func (p *kubernetesProvisioner) UpdateApp(ctx context.Context, old, new *appType
 		return err
 	}
 	sameCluster := oldClient.GetCluster().Name == newClient.GetCluster().Name
-	sameNamespace := oldClient.PoolNamespace(old.Pool) == oldClient.PoolNamespace(new.Pool)
+	oldNamespace, err := oldClient.TeamNamespace(ctx, old.Pool, old.TeamOwner)
+	if err != nil {
+		return err
+	}
+	newNamespace, err := oldClient.TeamNamespace(ctx, new.Pool, new.TeamOwner)
+	if err != nil {
+		return err
+	}
+	sameNamespace := oldNamespace == newNamespace
 	if sameCluster && !sameNamespace {
 		var volumes []volumeTypes.Volume
 		volumes, err = servicemanager.Volume.ListByApp(ctx, old.Name)
@@ -1410,9 +1499,13 @@ func ensureAppCustomResource(ctx context.Context, client *ClusterClient, a *appT
 	if !k8sErrors.IsNotFound(err) {
 		return err
 	}
+	ns, err := client.TeamNamespace(ctx, a.Pool, a.TeamOwner)
+	if err != nil {
+		return err
+	}
 	_, err = tclient.TsuruV1().Apps(client.Namespace()).Create(ctx, &tsuruv1.App{
 		ObjectMeta: metav1.ObjectMeta{Name: a.Name},
-		Spec:       tsuruv1.AppSpec{NamespaceName: client.PoolNamespace(a.Pool)},
+		Spec:       tsuruv1.AppSpec{NamespaceName: ns},
 	}, metav1.CreateOptions{})
 	return err
 }
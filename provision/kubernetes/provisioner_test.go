@@ -1175,6 +1175,45 @@ func (s *S) TestDeploy(c *check.C) {
 	})
 }
 
+func (s *S) TestDeployWithDeployHooks(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+	var hookCmds [][]string
+	s.client.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		pod := action.(ktesting.CreateAction).GetObject().(*apiv1.Pod)
+		if pod.Labels["tsuru.io/is-isolated-run"] == "true" {
+			c.Assert(pod.Spec.Containers[0].Image, check.Equals, "tsuru/app-myapp:v1")
+			hookCmds = append(hookCmds, pod.Spec.Containers[0].Command)
+		}
+		return false, nil, nil
+	})
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "run mycmd arg1",
+		},
+		"hooks": map[string]interface{}{
+			"deploy": map[string]interface{}{
+				"before": []string{"./migrate", "./seed"},
+				"after":  []string{"./notify"},
+			},
+		},
+	})
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Event: evt})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	wait()
+	c.Assert(hookCmds, check.HasLen, 2)
+	c.Assert(hookCmds[0][len(hookCmds[0])-1], check.Equals, "[ -d /home/application/current ] && cd /home/application/current; ./migrate && ./seed")
+	c.Assert(hookCmds[1][len(hookCmds[1])-1], check.Equals, "[ -d /home/application/current ] && cd /home/application/current; ./notify")
+	c.Assert(evt.Log(), check.Matches, `(?s).*Running before deploy hooks.*Running after deploy hooks.*`)
+}
+
 func (s *S) TestDeployCreatesAppCR(c *check.C) {
 	a, _, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
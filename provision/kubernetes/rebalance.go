@@ -0,0 +1,276 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	// evictions blocked by a PodDisruptionBudget are retried until the
+	// replacement of previously evicted units become ready.
+	rebalanceEvictionTimeout       = 2 * time.Minute
+	rebalanceEvictionRetryInterval = 5 * time.Second
+)
+
+type rebalancePod struct {
+	name    string
+	process string
+	node    string
+	zone    string
+}
+
+func (p *kubernetesProvisioner) RebalanceUnits(ctx context.Context, a *appTypes.App, opts provision.RebalanceUnitsOptions) ([]provision.UnitEviction, error) {
+	w := opts.Writer
+	if w == nil {
+		w = io.Discard
+	}
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := p.podsForApps(ctx, client, []*appTypes.App{a})
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := rebalanceNodeZones(ctx, client, a, pods)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []rebalancePod
+	for _, pod := range pods {
+		if isTerminating(pod) || podIsAllowedToFail(pod) || pod.Status.Phase != apiv1.PodRunning {
+			continue
+		}
+		process := labelSetFromMeta(&pod.ObjectMeta).AppProcess()
+		zone, eligible := nodes[pod.Spec.NodeName]
+		if process == "" || !eligible {
+			continue
+		}
+		candidates = append(candidates, rebalancePod{
+			name:    pod.Name,
+			process: process,
+			node:    pod.Spec.NodeName,
+			zone:    zone,
+		})
+	}
+	evictions := planUnitEvictions(a.Name, candidates, nodes, opts.MaxEvictions)
+	if len(evictions) == 0 {
+		fmt.Fprintf(w, " ---> Units of app %q are already balanced\n", a.Name)
+		return evictions, nil
+	}
+	for i, eviction := range evictions {
+		if opts.DryRun {
+			fmt.Fprintf(w, " ---> Would evict unit %s: %s\n", eviction.Unit, eviction.Reason)
+			continue
+		}
+		fmt.Fprintf(w, " ---> Evicting unit %s: %s\n", eviction.Unit, eviction.Reason)
+		err = evictPodRespectingBudget(ctx, client, ns, eviction.Unit, w)
+		if err != nil {
+			evictions[i].Error = err.Error()
+			fmt.Fprintf(w, "  ---> Unable to evict unit %s: %v\n", eviction.Unit, err)
+		}
+	}
+	return evictions, nil
+}
+
+// rebalanceNodeZones returns the nodes where units of the app may run mapped
+// to their zones. Without a node selector for the pool, which happens in
+// single pool clusters or pools using custom affinities, only nodes already
+// running units of the app are considered.
+func rebalanceNodeZones(ctx context.Context, client *ClusterClient, a *appTypes.App, pods []apiv1.Pod) (map[string]string, error) {
+	selector, _, err := defineSelectorAndAffinity(ctx, a, client)
+	if err != nil {
+		return nil, err
+	}
+	var nodeList []apiv1.Node
+	if len(selector) > 0 {
+		list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set(selector)).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		nodeList = list.Items
+	} else {
+		seen := map[string]bool{}
+		for _, pod := range pods {
+			if pod.Spec.NodeName == "" || seen[pod.Spec.NodeName] {
+				continue
+			}
+			seen[pod.Spec.NodeName] = true
+			node, err := client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+			if err != nil {
+				if k8sErrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			nodeList = append(nodeList, *node)
+		}
+	}
+	nodes := map[string]string{}
+	for _, node := range nodeList {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		nodes[node.Name] = node.Labels[apiv1.LabelTopologyZone]
+	}
+	return nodes, nil
+}
+
+// planUnitEvictions returns the units to be evicted so that, for each process,
+// zones differ by at most one unit and then nodes inside each zone differ by
+// at most one unit. Evicted units are expected to be rescheduled on the least
+// loaded zone and node.
+func planUnitEvictions(appName string, pods []rebalancePod, nodes map[string]string, maxEvictions int) []provision.UnitEviction {
+	byProcess := map[string][]rebalancePod{}
+	for _, pod := range pods {
+		byProcess[pod.process] = append(byProcess[pod.process], pod)
+	}
+	processes := make([]string, 0, len(byProcess))
+	for process := range byProcess {
+		processes = append(processes, process)
+	}
+	sort.Strings(processes)
+	evictions := []provision.UnitEviction{}
+	limitReached := func() bool {
+		return maxEvictions > 0 && len(evictions) >= maxEvictions
+	}
+	for _, process := range processes {
+		perNode := map[string][]string{}
+		zoneNodes := map[string][]string{}
+		for node, zone := range nodes {
+			perNode[node] = nil
+			zoneNodes[zone] = append(zoneNodes[zone], node)
+		}
+		for _, pod := range byProcess[process] {
+			perNode[pod.node] = append(perNode[pod.node], pod.name)
+		}
+		for node := range perNode {
+			sort.Strings(perNode[node])
+		}
+		zones := make([]string, 0, len(zoneNodes))
+		for zone := range zoneNodes {
+			sort.Strings(zoneNodes[zone])
+			zones = append(zones, zone)
+		}
+		sort.Strings(zones)
+		nodeCount := func(node string) int { return len(perNode[node]) }
+		zoneCount := func(zone string) int {
+			var count int
+			for _, node := range zoneNodes[zone] {
+				count += nodeCount(node)
+			}
+			return count
+		}
+		evict := func(node, zone, reason string) bool {
+			units := perNode[node]
+			for i := len(units) - 1; i >= 0; i-- {
+				// empty names are placeholders for units expected to be
+				// rescheduled on the node.
+				if units[i] == "" {
+					continue
+				}
+				evictions = append(evictions, provision.UnitEviction{
+					App:     appName,
+					Unit:    units[i],
+					Process: process,
+					Node:    node,
+					Zone:    zone,
+					Reason:  reason,
+				})
+				perNode[node] = append(units[:i:i], units[i+1:]...)
+				return true
+			}
+			return false
+		}
+		// zones are only balanced when every node is labeled with one.
+		for len(zones) > 1 && zones[0] != "" && !limitReached() {
+			maxZone, minZone := mostAndLeastLoaded(zones, zoneCount)
+			maxCount, minCount := zoneCount(maxZone), zoneCount(minZone)
+			if maxCount-minCount <= 1 {
+				break
+			}
+			maxNode, _ := mostAndLeastLoaded(zoneNodes[maxZone], nodeCount)
+			_, minNode := mostAndLeastLoaded(zoneNodes[minZone], nodeCount)
+			reason := fmt.Sprintf("zone %s has %d units of process %s while zone %s has %d", maxZone, maxCount, process, minZone, minCount)
+			if !evict(maxNode, maxZone, reason) {
+				break
+			}
+			perNode[minNode] = append(perNode[minNode], "")
+		}
+		for _, zone := range zones {
+			for len(zoneNodes[zone]) > 1 && !limitReached() {
+				maxNode, minNode := mostAndLeastLoaded(zoneNodes[zone], nodeCount)
+				maxCount, minCount := nodeCount(maxNode), nodeCount(minNode)
+				if maxCount-minCount <= 1 {
+					break
+				}
+				reason := fmt.Sprintf("node %s has %d units of process %s while node %s has %d", maxNode, maxCount, process, minNode, minCount)
+				if !evict(maxNode, zone, reason) {
+					break
+				}
+				perNode[minNode] = append(perNode[minNode], "")
+			}
+		}
+	}
+	return evictions
+}
+
+func mostAndLeastLoaded(names []string, count func(string) int) (string, string) {
+	var most, least string
+	for i, name := range names {
+		if i == 0 || count(name) > count(most) {
+			most = name
+		}
+		if i == 0 || count(name) < count(least) {
+			least = name
+		}
+	}
+	return most, least
+}
+
+func evictPodRespectingBudget(ctx context.Context, client *ClusterClient, ns, podName string, w io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, rebalanceEvictionTimeout)
+	defer cancel()
+	for {
+		err := client.CoreV1().Pods(ns).EvictV1(ctx, &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: ns,
+			},
+		})
+		if err == nil || k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		if !k8sErrors.IsTooManyRequests(err) {
+			return err
+		}
+		fmt.Fprintf(w, "  ---> Eviction of unit %s blocked by disruption budget, waiting\n", podName)
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(err, "timeout waiting for disruption budget")
+		case <-time.After(rebalanceEvictionRetryInterval):
+		}
+	}
+}
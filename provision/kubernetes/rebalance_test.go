@@ -0,0 +1,67 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"github.com/tsuru/tsuru/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPlanUnitEvictionsBalancesZones(c *check.C) {
+	nodes := map[string]string{"n1": "zone-a", "n2": "zone-a", "n3": "zone-b"}
+	pods := []rebalancePod{
+		{name: "p1", process: "web", node: "n1", zone: "zone-a"},
+		{name: "p2", process: "web", node: "n1", zone: "zone-a"},
+		{name: "p3", process: "web", node: "n2", zone: "zone-a"},
+		{name: "p4", process: "web", node: "n2", zone: "zone-a"},
+		{name: "p5", process: "worker", node: "n3", zone: "zone-b"},
+	}
+	evictions := planUnitEvictions("myapp", pods, nodes, 0)
+	c.Assert(evictions, check.DeepEquals, []provision.UnitEviction{
+		{App: "myapp", Unit: "p2", Process: "web", Node: "n1", Zone: "zone-a", Reason: "zone zone-a has 4 units of process web while zone zone-b has 0"},
+		{App: "myapp", Unit: "p4", Process: "web", Node: "n2", Zone: "zone-a", Reason: "zone zone-a has 3 units of process web while zone zone-b has 1"},
+	})
+}
+
+func (s *S) TestPlanUnitEvictionsBalancesNodesInZone(c *check.C) {
+	nodes := map[string]string{"n1": "", "n2": "", "n3": ""}
+	pods := []rebalancePod{
+		{name: "p1", process: "web", node: "n1"},
+		{name: "p2", process: "web", node: "n1"},
+		{name: "p3", process: "web", node: "n1"},
+		{name: "p4", process: "web", node: "n1"},
+		{name: "p5", process: "web", node: "n2"},
+	}
+	evictions := planUnitEvictions("myapp", pods, nodes, 0)
+	c.Assert(evictions, check.HasLen, 2)
+	c.Assert(evictions[0].Unit, check.Equals, "p4")
+	c.Assert(evictions[0].Reason, check.Equals, "node n1 has 4 units of process web while node n3 has 0")
+	c.Assert(evictions[1].Unit, check.Equals, "p3")
+	c.Assert(evictions[1].Reason, check.Equals, "node n1 has 3 units of process web while node n2 has 1")
+}
+
+func (s *S) TestPlanUnitEvictionsMaxEvictions(c *check.C) {
+	nodes := map[string]string{"n1": "", "n2": ""}
+	pods := []rebalancePod{
+		{name: "p1", process: "web", node: "n1"},
+		{name: "p2", process: "web", node: "n1"},
+		{name: "p3", process: "web", node: "n1"},
+		{name: "p4", process: "web", node: "n1"},
+	}
+	evictions := planUnitEvictions("myapp", pods, nodes, 1)
+	c.Assert(evictions, check.HasLen, 1)
+	c.Assert(evictions[0].Unit, check.Equals, "p4")
+}
+
+func (s *S) TestPlanUnitEvictionsAlreadyBalanced(c *check.C) {
+	nodes := map[string]string{"n1": "zone-a", "n2": "zone-b"}
+	pods := []rebalancePod{
+		{name: "p1", process: "web", node: "n1", zone: "zone-a"},
+		{name: "p2", process: "web", node: "n1", zone: "zone-a"},
+		{name: "p3", process: "web", node: "n2", zone: "zone-b"},
+	}
+	evictions := planUnitEvictions("myapp", pods, nodes, 0)
+	c.Assert(evictions, check.HasLen, 0)
+}
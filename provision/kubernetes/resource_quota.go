@@ -0,0 +1,390 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	resourceQuotaName = "tsuru-resource-quota"
+	limitRangeName    = "tsuru-limit-range"
+
+	// quotaRolloutFactor reserves room in the quota of namespaces for the
+	// units created during rollouts, which run alongside the old ones until
+	// they are ready, and for the pods of builds and one-off commands.
+	quotaRolloutFactor = 2
+)
+
+var quotaResources = map[apiv1.ResourceName]apiv1.ResourceName{
+	apiv1.ResourceCPU:    apiv1.ResourceLimitsCPU,
+	apiv1.ResourceMemory: apiv1.ResourceLimitsMemory,
+}
+
+// ensureNamespaceQuota keeps the LimitRange and the ResourceQuota of the
+// namespace in sync with the plans, unit quotas and pools of the apps running
+// in it, when enabled in the cluster for the pool of the app.
+func ensureNamespaceQuota(ctx context.Context, client *ClusterClient, app *appTypes.App, ns string) error {
+	if !client.namespaceResourceQuota(app.Pool) {
+		return nil
+	}
+	apps, err := appsInNamespace(ctx, client, app, ns)
+	if err != nil {
+		return err
+	}
+	limitRange, err := newNamespaceLimitRange(ctx, client, app, apps, ns)
+	if err != nil {
+		return err
+	}
+	err = ensureLimitRange(ctx, client, limitRange)
+	if err != nil {
+		return err
+	}
+	quota, err := newNamespaceResourceQuota(ctx, client, apps, ns)
+	if err != nil {
+		return err
+	}
+	if quota == nil {
+		// Some app in the namespace may create as many units as it wants,
+		// there's no way to tell how much the namespace is going to use.
+		err = client.CoreV1().ResourceQuotas(ns).Delete(ctx, resourceQuotaName, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+	return ensureResourceQuota(ctx, client, quota)
+}
+
+// appsInNamespace returns the apps whose custom resources point to the
+// namespace, the app being provisioned is always part of them.
+func appsInNamespace(ctx context.Context, client *ClusterClient, app *appTypes.App, ns string) ([]*appTypes.App, error) {
+	tclient, err := TsuruClientForConfig(client.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	appCRDs, err := tclient.TsuruV1().Apps(client.Namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	names := map[string]struct{}{}
+	for _, appCRD := range appCRDs.Items {
+		if appCRD.Spec.NamespaceName == ns {
+			names[appCRD.Name] = struct{}{}
+		}
+	}
+	delete(names, app.Name)
+	result := []*appTypes.App{app}
+	if len(names) == 0 {
+		return result, nil
+	}
+	apps, err := servicemanager.App.List(ctx, &appTypes.Filter{Pools: client.Pools})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range apps {
+		if _, ok := names[a.Name]; ok {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// newNamespaceLimitRange returns a LimitRange preventing containers in the
+// namespace from using more than the largest plan allowed in the pools of
+// its apps, containers without resources get the default plan of the pool
+// of the app.
+func newNamespaceLimitRange(ctx context.Context, client *ClusterClient, app *appTypes.App, apps []*appTypes.App, ns string) (*apiv1.LimitRange, error) {
+	var allLimits []apiv1.ResourceList
+	poolPlans := map[string]struct{}{}
+	for _, a := range apps {
+		limits, err := appUnitLimits(ctx, client, a)
+		if err != nil {
+			return nil, err
+		}
+		allLimits = append(allLimits, limits)
+		if _, ok := poolPlans[a.Pool]; ok {
+			continue
+		}
+		poolPlans[a.Pool] = struct{}{}
+		limits, err = poolMaxLimits(ctx, client, a.Pool)
+		if err != nil {
+			return nil, err
+		}
+		allLimits = append(allLimits, limits)
+	}
+	p, err := pool.GetPoolByName(ctx, app.Pool)
+	if err != nil {
+		return nil, err
+	}
+	defaultPlan, err := p.GetDefaultPlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := planRequirements(client, app.Pool, *defaultPlan)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      limitRangeName,
+			Namespace: ns,
+			Labels: map[string]string{
+				tsuruLabelPrefix + "is-tsuru": "true",
+			},
+		},
+		Spec: apiv1.LimitRangeSpec{
+			Limits: []apiv1.LimitRangeItem{
+				{
+					Type:           apiv1.LimitTypeContainer,
+					Max:            maxLimits(allLimits),
+					Default:        quotaResourceList(defaults.Limits),
+					DefaultRequest: quotaResourceList(defaults.Requests),
+				},
+			},
+		},
+	}, nil
+}
+
+// newNamespaceResourceQuota returns a ResourceQuota limiting the namespace to
+// the resources needed by the apps in it to run every unit allowed by their
+// quotas. It returns nil when no resource can be limited.
+func newNamespaceResourceQuota(ctx context.Context, client *ClusterClient, apps []*appTypes.App, ns string) (*apiv1.ResourceQuota, error) {
+	hard := apiv1.ResourceList{}
+	unbounded := map[apiv1.ResourceName]bool{}
+	for _, a := range apps {
+		if a.Quota.IsUnlimited() {
+			return nil, nil
+		}
+		limits, err := appUnitLimits(ctx, client, a)
+		if err != nil {
+			return nil, err
+		}
+		units := int64(a.Quota.Limit * quotaRolloutFactor)
+		for name, quotaName := range quotaResources {
+			perUnit, ok := limits[name]
+			if !ok {
+				unbounded[quotaName] = true
+				continue
+			}
+			total := *resource.NewQuantity(perUnit.Value()*units, perUnit.Format)
+			if name == apiv1.ResourceCPU {
+				total = *resource.NewMilliQuantity(perUnit.MilliValue()*units, perUnit.Format)
+			}
+			if current, ok := hard[quotaName]; ok {
+				total.Add(current)
+			}
+			hard[quotaName] = total
+		}
+	}
+	for name := range unbounded {
+		delete(hard, name)
+	}
+	if len(hard) == 0 {
+		return nil, nil
+	}
+	return &apiv1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceQuotaName,
+			Namespace: ns,
+			Labels: map[string]string{
+				tsuruLabelPrefix + "is-tsuru": "true",
+			},
+		},
+		Spec: apiv1.ResourceQuotaSpec{Hard: hard},
+	}, nil
+}
+
+func ensureLimitRange(ctx context.Context, client *ClusterClient, limitRange *apiv1.LimitRange) error {
+	existing, err := client.CoreV1().LimitRanges(limitRange.Namespace).Get(ctx, limitRange.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.CoreV1().LimitRanges(limitRange.Namespace).Create(ctx, limitRange, metav1.CreateOptions{})
+		return errors.WithStack(err)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if apiequality.Semantic.DeepEqual(limitRange.Spec, existing.Spec) {
+		return nil
+	}
+	limitRange.ResourceVersion = existing.ResourceVersion
+	_, err = client.CoreV1().LimitRanges(limitRange.Namespace).Update(ctx, limitRange, metav1.UpdateOptions{})
+	return errors.WithStack(err)
+}
+
+func ensureResourceQuota(ctx context.Context, client *ClusterClient, quota *apiv1.ResourceQuota) error {
+	existing, err := client.CoreV1().ResourceQuotas(quota.Namespace).Get(ctx, quota.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.CoreV1().ResourceQuotas(quota.Namespace).Create(ctx, quota, metav1.CreateOptions{})
+		return errors.WithStack(err)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if apiequality.Semantic.DeepEqual(quota.Spec, existing.Spec) {
+		return nil
+	}
+	quota.ResourceVersion = existing.ResourceVersion
+	_, err = client.CoreV1().ResourceQuotas(quota.Namespace).Update(ctx, quota, metav1.UpdateOptions{})
+	return errors.WithStack(err)
+}
+
+// appUnitLimits returns the limits of the largest unit of the app, processes
+// may use plans other than the one of the app.
+func appUnitLimits(ctx context.Context, client *ClusterClient, a *appTypes.App) (apiv1.ResourceList, error) {
+	plans := []appTypes.Plan{a.Plan}
+	for _, p := range a.Processes {
+		if p.Plan == "" {
+			continue
+		}
+		plan, err := servicemanager.Plan.FindByName(ctx, p.Plan)
+		if err != nil {
+			return nil, errors.WithMessage(err, "Could not fetch plan")
+		}
+		plans = append(plans, *plan)
+	}
+	return plansMaxLimits(client, a.Pool, plans)
+}
+
+// poolMaxLimits returns the limits of units using the largest plan allowed
+// in the pool.
+func poolMaxLimits(ctx context.Context, client *ClusterClient, poolName string) (apiv1.ResourceList, error) {
+	p, err := pool.GetPoolByName(ctx, poolName)
+	if err != nil {
+		return nil, err
+	}
+	planNames, err := p.GetPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plans := make([]appTypes.Plan, 0, len(planNames))
+	for _, name := range planNames {
+		plan, err := servicemanager.Plan.FindByName(ctx, name)
+		if err != nil {
+			return nil, errors.WithMessage(err, "Could not fetch plan")
+		}
+		plans = append(plans, *plan)
+	}
+	return plansMaxLimits(client, poolName, plans)
+}
+
+func plansMaxLimits(client *ClusterClient, pool string, plans []appTypes.Plan) (apiv1.ResourceList, error) {
+	allLimits := make([]apiv1.ResourceList, 0, len(plans))
+	for _, plan := range plans {
+		requirements, err := planRequirements(client, pool, plan)
+		if err != nil {
+			return nil, err
+		}
+		allLimits = append(allLimits, requirements.Limits)
+	}
+	return maxLimits(allLimits), nil
+}
+
+// planRequirements returns the resources of the containers of units using
+// the plan, applying the factors configured in the cluster for the pool.
+func planRequirements(client *ClusterClient, pool string, plan appTypes.Plan) (apiv1.ResourceRequirements, error) {
+	overCommit, err := client.OvercommitFactor(pool)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, errors.WithMessage(err, "misconfigured cluster overcommit factor")
+	}
+	cpuOverCommit, err := client.CPUOvercommitFactor(pool)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, errors.WithMessage(err, "misconfigured cluster cpu overcommit factor")
+	}
+	poolCPUBurst, err := client.CPUBurstFactor(pool)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, errors.WithMessage(err, "misconfigured cluster cpu burst factor")
+	}
+	memoryOverCommit, err := client.MemoryOvercommitFactor(pool)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, errors.WithMessage(err, "misconfigured cluster memory overcommit factor")
+	}
+	return resourceRequirements(&plan, pool, client, requirementsFactors{
+		overCommit:       overCommit,
+		cpuOverCommit:    cpuOverCommit,
+		poolCPUBurst:     poolCPUBurst,
+		memoryOverCommit: memoryOverCommit,
+	})
+}
+
+// maxLimits returns the largest cpu and memory in the lists. Plans without
+// cpu or memory don't limit them, so a resource missing from any of the lists
+// is left out.
+func maxLimits(allLimits []apiv1.ResourceList) apiv1.ResourceList {
+	result := apiv1.ResourceList{}
+	for name := range quotaResources {
+		var max *resource.Quantity
+		for _, limits := range allLimits {
+			quantity, ok := limits[name]
+			if !ok {
+				max = nil
+				break
+			}
+			if max == nil || quantity.Cmp(*max) > 0 {
+				max = &quantity
+			}
+		}
+		if max != nil {
+			result[name] = *max
+		}
+	}
+	return result
+}
+
+func quotaResourceList(list apiv1.ResourceList) apiv1.ResourceList {
+	result := apiv1.ResourceList{}
+	for name := range quotaResources {
+		if quantity, ok := list[name]; ok {
+			result[name] = quantity
+		}
+	}
+	return result
+}
+
+func (p *kubernetesProvisioner) ResourceQuotas(ctx context.Context, c *provTypes.Cluster) ([]provTypes.NamespaceResourceQuota, error) {
+	client, err := NewClusterClient(c)
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := client.CoreV1().ResourceQuotas("").List(ctx, metav1.ListOptions{
+		LabelSelector: tsuruLabelPrefix + "is-tsuru=true",
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	result := []provTypes.NamespaceResourceQuota{}
+	for _, quota := range quotas.Items {
+		if quota.Name != resourceQuotaName {
+			continue
+		}
+		result = append(result, provTypes.NamespaceResourceQuota{
+			Namespace: quota.Namespace,
+			Hard:      resourceListToMap(quota.Spec.Hard),
+			Used:      resourceListToMap(quota.Status.Used),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	return result, nil
+}
+
+func resourceListToMap(list apiv1.ResourceList) map[string]string {
+	result := map[string]string{}
+	for name, quantity := range list {
+		result[string(name)] = quantity.String()
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) quotaTestApp() *appTypes.App {
+	return &appTypes.App{
+		Name:      "myapp",
+		Pool:      "pool1",
+		TeamOwner: s.team.Name,
+		Plan:      appTypes.Plan{Name: "c2m1", Memory: 1024 * 1024 * 1024, CPUMilli: 2000},
+		Quota:     quota.Quota{Limit: 3},
+	}
+}
+
+func (s *S) TestEnsureNamespaceQuota(c *check.C) {
+	s.clusterClient.CustomData[namespaceResourceQuotaKey] = "true"
+	err := ensureNamespaceQuota(context.TODO(), s.clusterClient, s.quotaTestApp(), "tsuru-pool1")
+	c.Assert(err, check.IsNil)
+	limitRange, err := s.client.CoreV1().LimitRanges("tsuru-pool1").Get(context.TODO(), limitRangeName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(limitRange.Spec.Limits, check.HasLen, 1)
+	item := limitRange.Spec.Limits[0]
+	c.Assert(item.Type, check.Equals, apiv1.LimitTypeContainer)
+	c.Assert(item.Max.Cpu().String(), check.Equals, "2")
+	c.Assert(item.Max.Memory().String(), check.Equals, "1Gi")
+	c.Assert(item.Default.Cpu().String(), check.Equals, "2")
+	c.Assert(item.DefaultRequest.Memory().String(), check.Equals, "1Gi")
+	resourceQuota, err := s.client.CoreV1().ResourceQuotas("tsuru-pool1").Get(context.TODO(), resourceQuotaName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	hardCPU := resourceQuota.Spec.Hard[apiv1.ResourceLimitsCPU]
+	hardMemory := resourceQuota.Spec.Hard[apiv1.ResourceLimitsMemory]
+	c.Assert(hardCPU.Cmp(resource.MustParse("12")), check.Equals, 0)
+	c.Assert(hardMemory.Cmp(resource.MustParse("6Gi")), check.Equals, 0)
+}
+
+func (s *S) TestEnsureNamespaceQuotaDisabled(c *check.C) {
+	err := ensureNamespaceQuota(context.TODO(), s.clusterClient, s.quotaTestApp(), "tsuru-pool1")
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().LimitRanges("tsuru-pool1").Get(context.TODO(), limitRangeName, metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+	_, err = s.client.CoreV1().ResourceQuotas("tsuru-pool1").Get(context.TODO(), resourceQuotaName, metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+}
+
+func (s *S) TestEnsureNamespaceQuotaUnlimitedApp(c *check.C) {
+	s.clusterClient.CustomData[namespaceResourceQuotaKey] = "true"
+	a := s.quotaTestApp()
+	err := ensureNamespaceQuota(context.TODO(), s.clusterClient, a, "tsuru-pool1")
+	c.Assert(err, check.IsNil)
+	a.Quota = quota.UnlimitedQuota
+	err = ensureNamespaceQuota(context.TODO(), s.clusterClient, a, "tsuru-pool1")
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().ResourceQuotas("tsuru-pool1").Get(context.TODO(), resourceQuotaName, metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+	_, err = s.client.CoreV1().LimitRanges("tsuru-pool1").Get(context.TODO(), limitRangeName, metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestResourceQuotas(c *check.C) {
+	_, err := s.client.CoreV1().ResourceQuotas("tsuru-pool1").Create(context.TODO(), &apiv1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceQuotaName,
+			Namespace: "tsuru-pool1",
+			Labels:    map[string]string{"tsuru.io/is-tsuru": "true"},
+		},
+		Spec: apiv1.ResourceQuotaSpec{
+			Hard: apiv1.ResourceList{apiv1.ResourceLimitsMemory: resource.MustParse("6Gi")},
+		},
+		Status: apiv1.ResourceQuotaStatus{
+			Used: apiv1.ResourceList{apiv1.ResourceLimitsMemory: resource.MustParse("2Gi")},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().ResourceQuotas("tsuru-pool1").Create(context.TODO(), &apiv1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-quota",
+			Namespace: "tsuru-pool1",
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	quotas, err := s.p.ResourceQuotas(context.TODO(), s.clusterClient.Cluster)
+	c.Assert(err, check.IsNil)
+	c.Assert(quotas, check.DeepEquals, []provTypes.NamespaceResourceQuota{
+		{
+			Namespace: "tsuru-pool1",
+			Hard:      map[string]string{"limits.memory": "6Gi"},
+			Used:      map[string]string{"limits.memory": "2Gi"},
+		},
+	})
+}
@@ -74,16 +74,30 @@ func burstValue(v int64, burst float64) int64 {
 func resourceRequirements(plan *appTypes.Plan, pool string, client *ClusterClient, factors requirementsFactors) (apiv1.ResourceRequirements, error) {
 	resourceLimits := apiv1.ResourceList{}
 	resourceRequests := apiv1.ResourceList{}
+	if overcommit := plan.GetMemoryOvercommit(pool); overcommit > 0 {
+		factors.memoryOverCommit = overcommit
+	}
 	memory := plan.GetMemory()
 	if memory != 0 {
 		resourceLimits[apiv1.ResourceMemory] = factors.memoryLimits(memory)
-		resourceRequests[apiv1.ResourceMemory] = factors.memoryRequests(memory)
+		if memoryRequest := plan.GetMemoryRequest(); memoryRequest > 0 {
+			resourceRequests[apiv1.ResourceMemory] = *resource.NewQuantity(memoryRequest, resource.BinarySI)
+		} else {
+			resourceRequests[apiv1.ResourceMemory] = factors.memoryRequests(memory)
+		}
 	}
 	cpuMilli := int64(plan.GetMilliCPU())
 	cpuBurst := plan.GetCPUBurst()
 	if cpuMilli != 0 {
-		resourceLimits[apiv1.ResourceCPU] = factors.cpuLimits(cpuBurst, cpuMilli)
-		resourceRequests[apiv1.ResourceCPU] = factors.cpuRequests(cpuMilli)
+		if cpuRequest := int64(plan.GetMilliCPURequest()); cpuRequest > 0 {
+			// explicit requests make the plan cpu the limit itself, burst
+			// factors only apply when requests are derived from it.
+			resourceLimits[apiv1.ResourceCPU] = *resource.NewMilliQuantity(cpuMilli, resource.DecimalSI)
+			resourceRequests[apiv1.ResourceCPU] = *resource.NewMilliQuantity(cpuRequest, resource.DecimalSI)
+		} else {
+			resourceLimits[apiv1.ResourceCPU] = factors.cpuLimits(cpuBurst, cpuMilli)
+			resourceRequests[apiv1.ResourceCPU] = factors.cpuRequests(cpuMilli)
+		}
 	}
 	ephemeral, err := client.ephemeralStorage(pool)
 	if err != nil {
@@ -106,6 +106,56 @@ func (s *S) TestGetresourceRequirements(c *check.C) {
 	}
 }
 
+func (s *S) TestGetresourceRequirementsWithPlanRequests(c *check.C) {
+	clusterClient := &ClusterClient{
+		Cluster: &provTypes.Cluster{},
+	}
+	plan := &appTypes.Plan{
+		Memory:   10 * 1024,
+		CPUMilli: 1000,
+		Requests: &appTypes.PlanRequests{
+			CPUMilli: 250,
+			Memory:   4 * 1024,
+		},
+	}
+	requirements, err := resourceRequirements(plan, "", clusterClient, requirementsFactors{
+		overCommit:   2,
+		poolCPUBurst: 2,
+	})
+	c.Assert(err, check.IsNil)
+	memoryLimits := requirements.Limits["memory"]
+	c.Assert(memoryLimits.String(), check.Equals, "10Ki")
+	memoryRequests := requirements.Requests["memory"]
+	c.Assert(memoryRequests.String(), check.Equals, "4Ki")
+	cpuLimits := requirements.Limits["cpu"]
+	c.Assert(cpuLimits.String(), check.Equals, "1")
+	cpuRequests := requirements.Requests["cpu"]
+	c.Assert(cpuRequests.String(), check.Equals, "250m")
+}
+
+func (s *S) TestGetresourceRequirementsWithPlanMemoryOvercommit(c *check.C) {
+	clusterClient := &ClusterClient{
+		Cluster: &provTypes.Cluster{},
+	}
+	plan := &appTypes.Plan{
+		Memory:   10 * 1024,
+		CPUMilli: 1000,
+		Requests: &appTypes.PlanRequests{
+			MemoryOvercommit: map[string]float64{"pool1": 5},
+		},
+	}
+	requirements, err := resourceRequirements(plan, "pool1", clusterClient, requirementsFactors{overCommit: 2})
+	c.Assert(err, check.IsNil)
+	memoryRequests := requirements.Requests["memory"]
+	c.Assert(memoryRequests.String(), check.Equals, "2Ki")
+	cpuRequests := requirements.Requests["cpu"]
+	c.Assert(cpuRequests.String(), check.Equals, "500m")
+	requirements, err = resourceRequirements(plan, "pool2", clusterClient, requirementsFactors{overCommit: 2})
+	c.Assert(err, check.IsNil)
+	memoryRequests = requirements.Requests["memory"]
+	c.Assert(memoryRequests.String(), check.Equals, "5Ki")
+}
+
 func (s *S) TestGetCPULimits(c *check.C) {
 	// empty
 	rf := &requirementsFactors{}
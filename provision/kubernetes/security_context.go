@@ -0,0 +1,70 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tsuru/tsuru/provision/pool"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// securityContextForPool returns the security context of the pool with the
+// fields set in override, the security context of an app, taking precedence.
+func securityContextForPool(ctx context.Context, poolName string, override *appTypes.SecurityContext) (*appTypes.SecurityContext, error) {
+	p, err := pool.GetPoolByName(ctx, poolName)
+	if err != nil {
+		return nil, err
+	}
+	poolSecurityContext, err := p.GetSecurityContext()
+	if err != nil {
+		return nil, err
+	}
+	return poolSecurityContext.Merge(override), nil
+}
+
+// applySecurityContext sets the fields of the security context in the pod
+// and in each of its containers, keeping the user they run as.
+func applySecurityContext(podSpec *apiv1.PodSpec, securityContext *appTypes.SecurityContext) {
+	if securityContext.Empty() {
+		return
+	}
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &apiv1.PodSecurityContext{}
+	}
+	if securityContext.RunAsNonRoot != nil {
+		podSpec.SecurityContext.RunAsNonRoot = securityContext.RunAsNonRoot
+	}
+	if securityContext.FSGroup != nil {
+		podSpec.SecurityContext.FSGroup = securityContext.FSGroup
+	}
+	if securityContext.SeccompProfile != "" {
+		podSpec.SecurityContext.SeccompProfile = seccompProfile(securityContext.SeccompProfile)
+	}
+	if securityContext.ReadOnlyRootFilesystem == nil {
+		return
+	}
+	for _, containers := range [][]apiv1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if containers[i].SecurityContext == nil {
+				containers[i].SecurityContext = &apiv1.SecurityContext{}
+			}
+			readOnly := *securityContext.ReadOnlyRootFilesystem
+			containers[i].SecurityContext.ReadOnlyRootFilesystem = &readOnly
+		}
+	}
+}
+
+func seccompProfile(profile string) *apiv1.SeccompProfile {
+	if localhostProfile, ok := strings.CutPrefix(profile, appTypes.SeccompProfileLocalhostPrefix); ok {
+		return &apiv1.SeccompProfile{
+			Type:             apiv1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: &localhostProfile,
+		}
+	}
+	return &apiv1.SeccompProfile{Type: apiv1.SeccompProfileType(profile)}
+}
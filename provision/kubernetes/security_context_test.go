@@ -0,0 +1,84 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestServiceManagerDeploySecurityContext(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	err := pool.PoolUpdate(context.TODO(), "test-default", pool.UpdatePoolOptions{Labels: map[string]string{
+		"security-context": `{"runAsNonRoot":true,"readOnlyRootFilesystem":true,"seccompProfile":"RuntimeDefault","fsGroup":1000}`,
+	}})
+	c.Assert(err, check.IsNil)
+	defer pool.PoolUpdate(context.TODO(), "test-default", pool.UpdatePoolOptions{Labels: map[string]string{}})
+	m := serviceManager{client: s.clusterClient}
+	writable := false
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, SecurityContext: &appTypes.SecurityContext{
+		ReadOnlyRootFilesystem: &writable,
+		SeccompProfile:         "Localhost/profiles/myapp.json",
+	}}
+	err = app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	podSecurityContext := dep.Spec.Template.Spec.SecurityContext
+	c.Assert(podSecurityContext, check.NotNil)
+	c.Assert(*podSecurityContext.RunAsNonRoot, check.Equals, true)
+	c.Assert(*podSecurityContext.FSGroup, check.Equals, int64(1000))
+	localhostProfile := "profiles/myapp.json"
+	c.Assert(podSecurityContext.SeccompProfile, check.DeepEquals, &apiv1.SeccompProfile{
+		Type:             apiv1.SeccompProfileTypeLocalhost,
+		LocalhostProfile: &localhostProfile,
+	})
+	containerSecurityContext := dep.Spec.Template.Spec.Containers[0].SecurityContext
+	c.Assert(containerSecurityContext, check.NotNil)
+	c.Assert(*containerSecurityContext.ReadOnlyRootFilesystem, check.Equals, false)
+}
+
+func (s *S) TestApplySecurityContext(c *check.C) {
+	uid := int64(1000)
+	podSpec := &apiv1.PodSpec{
+		SecurityContext: &apiv1.PodSecurityContext{RunAsUser: &uid},
+		InitContainers:  []apiv1.Container{{Name: "init"}},
+		Containers:      []apiv1.Container{{Name: "web"}, {Name: "sidecar"}},
+	}
+	applySecurityContext(podSpec, nil)
+	c.Assert(podSpec.Containers[0].SecurityContext, check.IsNil)
+	readOnly := true
+	applySecurityContext(podSpec, &appTypes.SecurityContext{ReadOnlyRootFilesystem: &readOnly, SeccompProfile: "RuntimeDefault"})
+	c.Assert(*podSpec.SecurityContext.RunAsUser, check.Equals, uid)
+	c.Assert(podSpec.SecurityContext.RunAsNonRoot, check.IsNil)
+	c.Assert(podSpec.SecurityContext.SeccompProfile, check.DeepEquals, &apiv1.SeccompProfile{Type: apiv1.SeccompProfileTypeRuntimeDefault})
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		c.Assert(*container.SecurityContext.ReadOnlyRootFilesystem, check.Equals, true, check.Commentf("container %s", container.Name))
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const defaultTerminationGracePeriodSeconds = 30
+
+// validateShutdownAndRollouts checks the shutdown and rollout settings
+// declared in the tsuru.yaml of the version.
+func validateShutdownAndRollouts(version appTypes.AppVersion) error {
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return err
+	}
+	for _, p := range yamlData.Processes {
+		if err = p.Shutdown.Validate(); err != nil {
+			return errors.Errorf("invalid shutdown for process %q in tsuru.yaml: %v", p.Name, err)
+		}
+		if err = p.Rollout.Validate(); err != nil {
+			return errors.Errorf("invalid rollout for process %q in tsuru.yaml: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// processShutdownAndRollout returns the shutdown and rollout settings set for
+// the process in the app, each one falling back to the one declared in
+// tsuru.yaml.
+func processShutdownAndRollout(a *appTypes.App, process string, yamlData provTypes.TsuruYamlData) (*provTypes.ProcessShutdown, *provTypes.ProcessRollout) {
+	shutdown, rollout := yamlData.Shutdown(process), yamlData.Rollout(process)
+	for _, p := range a.Processes {
+		if p.Name != process {
+			continue
+		}
+		if !p.Shutdown.Empty() {
+			shutdown = p.Shutdown
+		}
+		if !p.Rollout.Empty() {
+			rollout = p.Rollout
+		}
+	}
+	if shutdown == nil {
+		shutdown = &provTypes.ProcessShutdown{}
+	}
+	if rollout == nil {
+		rollout = &provTypes.ProcessRollout{}
+	}
+	return shutdown, rollout
+}
+
+// shutdownLifecycle returns the preStop hook and the termination grace period
+// of the units of the process, the process settings taking precedence over
+// the sleep configured for the pool.
+func shutdownLifecycle(client *ClusterClient, pool string, shutdown *provTypes.ProcessShutdown) (*apiv1.LifecycleHandler, int64) {
+	sleepSec := int64(client.preStopSleepSeconds(pool))
+	if shutdown.PreStopSleepSeconds != nil {
+		sleepSec = *shutdown.PreStopSleepSeconds
+	}
+	var preStop *apiv1.LifecycleHandler
+	if len(shutdown.PreStopCommand) > 0 {
+		sleepSec = 0
+		preStop = &apiv1.LifecycleHandler{
+			Exec: &apiv1.ExecAction{Command: shutdown.PreStopCommand},
+		}
+	} else if sleepSec > 0 {
+		preStop = &apiv1.LifecycleHandler{
+			Exec: &apiv1.ExecAction{
+				// Allow some time for endpoints controller and kube-proxy to
+				// remove the endpoints for the pods before sending SIGTERM to
+				// app. This should reduce the number of failed connections due
+				// to pods stopping while their endpoints are still active.
+				Command: []string{"sh", "-c", fmt.Sprintf("sleep %d || true", sleepSec)},
+			},
+		}
+	}
+	terminationGracePeriod := defaultTerminationGracePeriodSeconds + sleepSec
+	if shutdown.TerminationGracePeriodSeconds != nil {
+		terminationGracePeriod = *shutdown.TerminationGracePeriodSeconds
+	}
+	return preStop, terminationGracePeriod
+}
+
+// rolloutStrategy returns the max surge and max unavailable units of the
+// process during rolling updates, the process settings taking precedence
+// over the ones configured for the pool.
+func rolloutStrategy(client *ClusterClient, pool, process string, rollout *provTypes.ProcessRollout) (intstr.IntOrString, intstr.IntOrString, error) {
+	maxSurge := client.maxSurge(pool)
+	if rollout.MaxSurge != nil {
+		maxSurge = *rollout.MaxSurge
+	}
+	maxUnavailable := client.maxUnavailable(pool)
+	if rollout.MaxUnavailable != nil {
+		maxUnavailable = *rollout.MaxUnavailable
+	}
+	if zeroUnits(maxSurge) && zeroUnits(maxUnavailable) {
+		return maxSurge, maxUnavailable, errors.Errorf("rollout of process %q can't have both maxSurge and maxUnavailable set to zero", process)
+	}
+	return maxSurge, maxUnavailable, nil
+}
+
+func zeroUnits(value intstr.IntOrString) bool {
+	return value.String() == "0" || value.String() == "0%"
+}
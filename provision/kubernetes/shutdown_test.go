@@ -0,0 +1,111 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (s *S) TestServiceManagerDeployServiceWithProcessShutdownAndRollout(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newEmptyVersion(c, a)
+	err = version.CommitBuildImage()
+	c.Assert(err, check.IsNil)
+	err = version.AddData(appTypes.AddVersionDataArgs{
+		Processes: map[string][]string{"web": {"run"}},
+		CustomData: map[string]interface{}{
+			"processes": []interface{}{
+				map[string]interface{}{
+					"name":    "web",
+					"command": "run",
+					"shutdown": map[string]interface{}{
+						"terminationGracePeriodSeconds": 120,
+						"preStopCommand":                []string{"/bin/drain"},
+					},
+					"rollout": map[string]interface{}{"maxSurge": 1, "maxUnavailable": "25%"},
+				},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.CommitBaseImage()
+	c.Assert(err, check.IsNil)
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	deploy := func() {
+		err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+			App:     a,
+			Version: version,
+		}, servicecommon.ProcessSpec{
+			"web": servicecommon.ProcessState{Start: true},
+		})
+		c.Assert(err, check.IsNil)
+		waitDep()
+	}
+	deploy()
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Containers[0].Lifecycle.PreStop, check.DeepEquals, &apiv1.LifecycleHandler{
+		Exec: &apiv1.ExecAction{Command: []string{"/bin/drain"}},
+	})
+	c.Assert(*dep.Spec.Template.Spec.TerminationGracePeriodSeconds, check.Equals, int64(120))
+	c.Assert(*dep.Spec.Strategy.RollingUpdate.MaxSurge, check.DeepEquals, intstr.FromInt(1))
+	c.Assert(*dep.Spec.Strategy.RollingUpdate.MaxUnavailable, check.DeepEquals, intstr.FromString("25%"))
+
+	sleep := int64(20)
+	a.Processes = []appTypes.Process{
+		{Name: "web", Shutdown: &provTypes.ProcessShutdown{PreStopSleepSeconds: &sleep}},
+	}
+	deploy()
+	dep, err = s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Containers[0].Lifecycle.PreStop, check.DeepEquals, &apiv1.LifecycleHandler{
+		Exec: &apiv1.ExecAction{Command: []string{"sh", "-c", "sleep 20 || true"}},
+	})
+	c.Assert(*dep.Spec.Template.Spec.TerminationGracePeriodSeconds, check.Equals, int64(50))
+	c.Assert(*dep.Spec.Strategy.RollingUpdate.MaxSurge, check.DeepEquals, intstr.FromInt(1))
+}
+
+func (s *S) TestRolloutStrategy(c *check.C) {
+	maxSurge, maxUnavailable, err := rolloutStrategy(s.clusterClient, "pool1", "web", &provTypes.ProcessRollout{})
+	c.Assert(err, check.IsNil)
+	c.Assert(maxSurge, check.DeepEquals, intstr.FromString("100%"))
+	c.Assert(maxUnavailable, check.DeepEquals, intstr.FromInt(0))
+	zero := intstr.FromString("0%")
+	_, _, err = rolloutStrategy(s.clusterClient, "pool1", "web", &provTypes.ProcessRollout{MaxSurge: &zero})
+	c.Assert(err, check.ErrorMatches, `rollout of process "web" can't have both maxSurge and maxUnavailable set to zero`)
+}
+
+func (s *S) TestValidateShutdownAndRollouts(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newVersion(c, a, map[string]interface{}{
+		"processes": []interface{}{
+			map[string]interface{}{
+				"name":     "web",
+				"command":  "run",
+				"shutdown": map[string]interface{}{"terminationGracePeriodSeconds": 10, "preStopSleepSeconds": 15},
+			},
+		},
+	})
+	err = validateShutdownAndRollouts(version)
+	c.Assert(err, check.ErrorMatches, `invalid shutdown for process "web" in tsuru.yaml: preStopSleepSeconds must be lower than terminationGracePeriodSeconds`)
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func sidecarVolumeName(name string) string {
+	return fmt.Sprintf("%s-sidecar", name)
+}
+
+// validateSidecars checks the sidecars declared in the tsuru.yaml of the
+// version, so invalid ones fail the deploy before any unit is replaced.
+func validateSidecars(version appTypes.AppVersion) error {
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return err
+	}
+	names := map[string]struct{}{}
+	mountPaths := map[string]string{}
+	for _, s := range yamlData.Sidecars {
+		if errs := validation.IsDNS1123Label(s.Name); len(errs) > 0 {
+			return errors.Errorf("invalid sidecar name %q in tsuru.yaml: %s", s.Name, strings.Join(errs, ", "))
+		}
+		if _, ok := names[s.Name]; ok {
+			return errors.Errorf("sidecar %q is declared more than once in tsuru.yaml", s.Name)
+		}
+		names[s.Name] = struct{}{}
+		if s.Image == "" {
+			return errors.Errorf("image is required for sidecar %q in tsuru.yaml", s.Name)
+		}
+		if _, err = sidecarResources(s.Resources); err != nil {
+			return errors.Errorf("invalid resources for sidecar %q in tsuru.yaml: %v", s.Name, err)
+		}
+		for _, m := range s.Mounts {
+			if errs := validation.IsDNS1123Label(m.Name); len(errs) > 0 {
+				return errors.Errorf("invalid mount name %q for sidecar %q in tsuru.yaml: %s", m.Name, s.Name, strings.Join(errs, ", "))
+			}
+			if !path.IsAbs(m.Path) {
+				return errors.Errorf("mount %q for sidecar %q in tsuru.yaml must have an absolute path", m.Name, s.Name)
+			}
+			if p, ok := mountPaths[m.Name]; ok && p != m.Path {
+				return errors.Errorf("mount %q is declared with different paths in tsuru.yaml", m.Name)
+			}
+			mountPaths[m.Name] = m.Path
+		}
+	}
+	return nil
+}
+
+func sidecarResources(resources *provTypes.TsuruYamlSidecarResources) (apiv1.ResourceRequirements, error) {
+	if resources == nil {
+		return apiv1.ResourceRequirements{}, nil
+	}
+	list := apiv1.ResourceList{}
+	for name, value := range map[apiv1.ResourceName]string{
+		apiv1.ResourceCPU:    resources.CPU,
+		apiv1.ResourceMemory: resources.Memory,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return apiv1.ResourceRequirements{}, errors.Errorf("invalid %s %q", name, value)
+		}
+		list[name] = quantity
+	}
+	if len(list) == 0 {
+		return apiv1.ResourceRequirements{}, nil
+	}
+	return apiv1.ResourceRequirements{Requests: list, Limits: list}, nil
+}
+
+// sidecarContainers returns the containers of the sidecars of the process,
+// along with the volumes of the directories they share with the app container
+// and the mounts of these volumes in it.
+func sidecarContainers(yamlData provTypes.TsuruYamlData, process, appContainer string) ([]apiv1.Container, []apiv1.Volume, []apiv1.VolumeMount, error) {
+	var (
+		containers []apiv1.Container
+		volumes    []apiv1.Volume
+		appMounts  []apiv1.VolumeMount
+	)
+	shared := map[string]struct{}{}
+	for _, s := range yamlData.SidecarsFor(process) {
+		if s.Name == appContainer {
+			return nil, nil, nil, errors.Errorf("sidecar %q has the same name of the container of process %q", s.Name, process)
+		}
+		resources, err := sidecarResources(s.Resources)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var mounts []apiv1.VolumeMount
+		for _, m := range s.Mounts {
+			mount := apiv1.VolumeMount{Name: sidecarVolumeName(m.Name), MountPath: m.Path}
+			mounts = append(mounts, mount)
+			if _, ok := shared[m.Name]; ok {
+				continue
+			}
+			shared[m.Name] = struct{}{}
+			volumes = append(volumes, apiv1.Volume{
+				Name: mount.Name,
+				VolumeSource: apiv1.VolumeSource{
+					EmptyDir: &apiv1.EmptyDirVolumeSource{},
+				},
+			})
+			appMounts = append(appMounts, mount)
+		}
+		containers = append(containers, apiv1.Container{
+			Name:         s.Name,
+			Image:        s.Image,
+			Command:      s.Command,
+			Resources:    resources,
+			VolumeMounts: mounts,
+		})
+	}
+	return containers, volumes, appMounts, nil
+}
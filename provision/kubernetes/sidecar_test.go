@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestServiceManagerDeployWithSidecars(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":    "cm1",
+			"worker": "cm2",
+		},
+		"sidecars": []interface{}{
+			map[string]interface{}{
+				"name":      "log-shipper",
+				"image":     "fluent-bit:2",
+				"processes": []interface{}{"web"},
+				"resources": map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+				"mounts": []interface{}{
+					map[string]interface{}{"name": "logs", "path": "/var/log/app"},
+				},
+			},
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"web":    servicecommon.ProcessState{Start: true},
+		"worker": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	podSpec := dep.Spec.Template.Spec
+	c.Assert(podSpec.Containers, check.HasLen, 2)
+	c.Assert(podSpec.Containers[0].VolumeMounts, check.DeepEquals, []apiv1.VolumeMount{
+		{Name: "logs-sidecar", MountPath: "/var/log/app"},
+	})
+	resources := apiv1.ResourceList{
+		apiv1.ResourceCPU:    resource.MustParse("100m"),
+		apiv1.ResourceMemory: resource.MustParse("64Mi"),
+	}
+	c.Assert(podSpec.Containers[1], check.DeepEquals, apiv1.Container{
+		Name:         "log-shipper",
+		Image:        "fluent-bit:2",
+		Resources:    apiv1.ResourceRequirements{Requests: resources, Limits: resources},
+		VolumeMounts: []apiv1.VolumeMount{{Name: "logs-sidecar", MountPath: "/var/log/app"}},
+	})
+	c.Assert(podSpec.Volumes, check.DeepEquals, []apiv1.Volume{
+		{Name: "logs-sidecar", VolumeSource: apiv1.VolumeSource{EmptyDir: &apiv1.EmptyDirVolumeSource{}}},
+	})
+	dep, err = s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-worker", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Containers, check.HasLen, 1)
+}
+
+func (s *S) TestValidateSidecars(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		sidecars []interface{}
+		expected string
+	}{
+		{
+			sidecars: []interface{}{map[string]interface{}{"name": "proxy", "image": "envoy"}},
+		},
+		{
+			sidecars: []interface{}{map[string]interface{}{"name": "Proxy_1", "image": "envoy"}},
+			expected: `invalid sidecar name "Proxy_1" in tsuru.yaml: .*`,
+		},
+		{
+			sidecars: []interface{}{map[string]interface{}{"name": "proxy"}},
+			expected: `image is required for sidecar "proxy" in tsuru.yaml`,
+		},
+		{
+			sidecars: []interface{}{
+				map[string]interface{}{"name": "proxy", "image": "envoy"},
+				map[string]interface{}{"name": "proxy", "image": "nginx"},
+			},
+			expected: `sidecar "proxy" is declared more than once in tsuru.yaml`,
+		},
+		{
+			sidecars: []interface{}{map[string]interface{}{"name": "proxy", "image": "envoy", "resources": map[string]interface{}{"memory": "lots"}}},
+			expected: `invalid resources for sidecar "proxy" in tsuru.yaml: invalid memory "lots"`,
+		},
+		{
+			sidecars: []interface{}{map[string]interface{}{"name": "proxy", "image": "envoy", "mounts": []interface{}{
+				map[string]interface{}{"name": "logs", "path": "logs"},
+			}}},
+			expected: `mount "logs" for sidecar "proxy" in tsuru.yaml must have an absolute path`,
+		},
+		{
+			sidecars: []interface{}{
+				map[string]interface{}{"name": "proxy", "image": "envoy", "mounts": []interface{}{
+					map[string]interface{}{"name": "logs", "path": "/logs"},
+				}},
+				map[string]interface{}{"name": "shipper", "image": "fluent-bit", "mounts": []interface{}{
+					map[string]interface{}{"name": "logs", "path": "/var/log"},
+				}},
+			},
+			expected: `mount "logs" is declared with different paths in tsuru.yaml`,
+		},
+	}
+	for i, tt := range tests {
+		version := newCommittedVersion(c, a, map[string]interface{}{
+			"processes": map[string]interface{}{"web": "cm1"},
+			"sidecars":  tt.sidecars,
+		})
+		err = validateSidecars(version)
+		if tt.expected == "" {
+			c.Assert(err, check.IsNil, check.Commentf("test %d", i))
+		} else {
+			c.Assert(err, check.ErrorMatches, tt.expected, check.Commentf("test %d", i))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision/pool"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// spreadForApp returns the spread of the app merged over the spread of its
+// pool.
+func spreadForApp(ctx context.Context, a *appTypes.App) (*appTypes.Spread, error) {
+	p, err := pool.GetPoolByName(ctx, a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	poolSpread, err := p.GetSpread()
+	if err != nil {
+		return nil, err
+	}
+	return poolSpread.Merge(a.Spread), nil
+}
+
+// spreadTopologyConstraints converts the constraints of the spread to the
+// ones of the pod, selecting the units of the same process.
+func spreadTopologyConstraints(labels map[string]string, spread *appTypes.Spread) []apiv1.TopologySpreadConstraint {
+	var constraints []apiv1.TopologySpreadConstraint
+	for _, c := range spread.TopologySpreadConstraints {
+		maxSkew := c.MaxSkew
+		if maxSkew == 0 {
+			maxSkew = 1
+		}
+		whenUnsatisfiable := apiv1.ScheduleAnyway
+		if c.Required {
+			whenUnsatisfiable = apiv1.DoNotSchedule
+		}
+		constraints = append(constraints, apiv1.TopologySpreadConstraint{
+			MaxSkew:           maxSkew,
+			TopologyKey:       c.TopologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: filterAppLabels(labels),
+			},
+		})
+	}
+	return constraints
+}
+
+// spreadAffinity adds the anti-affinity rules of the spread to the affinity
+// of the pool, keeping units of the same process apart.
+func spreadAffinity(affinity *apiv1.Affinity, labels map[string]string, spread *appTypes.Spread) *apiv1.Affinity {
+	if len(spread.AntiAffinity) == 0 {
+		return affinity
+	}
+	if affinity == nil {
+		affinity = &apiv1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &apiv1.PodAntiAffinity{}
+	}
+	antiAffinity := affinity.PodAntiAffinity
+	for _, r := range spread.AntiAffinity {
+		term := apiv1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: filterAppLabels(labels),
+			},
+			TopologyKey: r.TopologyKey,
+		}
+		if r.Required {
+			antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+			continue
+		}
+		weight := r.Weight
+		if weight == 0 {
+			weight = appTypes.DefaultAntiAffinityWeight
+		}
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, apiv1.WeightedPodAffinityTerm{
+			Weight:          weight,
+			PodAffinityTerm: term,
+		})
+	}
+	return affinity
+}
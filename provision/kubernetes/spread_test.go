@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestServiceManagerDeploySpread(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	s.clusterClient.CustomData[topologySpreadConstraintsKey] = `[{"maxskew":1, "topologykey":"kubernetes.io/hostname"}]`
+	err := pool.PoolUpdate(context.TODO(), "test-default", pool.UpdatePoolOptions{Labels: map[string]string{
+		"spread": `{"topologySpreadConstraints":[{"topologyKey":"topology.kubernetes.io/zone","required":true}],"antiAffinity":[{"topologyKey":"kubernetes.io/hostname","required":true}]}`,
+	}})
+	c.Assert(err, check.IsNil)
+	defer pool.PoolUpdate(context.TODO(), "test-default", pool.UpdatePoolOptions{Labels: map[string]string{}})
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Spread: &appTypes.Spread{
+		AntiAffinity: []appTypes.AntiAffinityRule{{TopologyKey: "kubernetes.io/hostname", Weight: 50}},
+	}}
+	err = app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"tsuru.io/app-name": "myapp", "tsuru.io/app-process": "p1", "tsuru.io/app-version": "1"}}
+	c.Assert(dep.Spec.Template.Spec.TopologySpreadConstraints, check.DeepEquals, []apiv1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: apiv1.DoNotSchedule,
+			LabelSelector:     selector,
+		},
+	})
+	c.Assert(dep.Spec.Template.Spec.Affinity, check.DeepEquals, &apiv1.Affinity{
+		PodAntiAffinity: &apiv1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []apiv1.WeightedPodAffinityTerm{
+				{
+					Weight: 50,
+					PodAffinityTerm: apiv1.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   "kubernetes.io/hostname",
+					},
+				},
+			},
+		},
+	})
+}
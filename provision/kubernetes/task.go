@@ -0,0 +1,59 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+var _ provision.TaskProvisioner = &kubernetesProvisioner{}
+
+func taskPodNameForApp(a *appTypes.App) string {
+	return fmt.Sprintf("%s-task-%s", provision.ValidKubeName(a.Name), rand.String(6))
+}
+
+// taskPlan returns a copy of the plan of the app with the limits requested
+// for the task, the override of the app plan is left untouched.
+func taskPlan(plan appTypes.Plan, opts *provision.TaskOptions) appTypes.Plan {
+	if plan.Override != nil {
+		override := *plan.Override
+		plan.Override = &override
+	}
+	var override appTypes.PlanOverride
+	if opts.CPUMilli > 0 {
+		override.CPUMilli = &opts.CPUMilli
+	}
+	if opts.Memory > 0 {
+		override.Memory = &opts.Memory
+	}
+	plan.MergeOverride(override)
+	return plan
+}
+
+func (p *kubernetesProvisioner) RunTask(ctx context.Context, opts provision.TaskOptions) error {
+	client, err := clusterForPool(ctx, opts.App.Pool)
+	if err != nil {
+		return err
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	return runIsolatedCmdPod(ctx, client, execOpts{
+		client:       client,
+		app:          opts.App,
+		cmds:         opts.Cmds,
+		eventsOutput: opts.Output,
+		stdout:       opts.Output,
+		stderr:       opts.Output,
+		task:         &opts,
+	})
+}
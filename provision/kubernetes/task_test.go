@@ -0,0 +1,55 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/safe"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func (s *S) TestRunTask(c *check.C) {
+	a, _, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	newSuccessfulVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	a.Plan.CPUMilli = 1000
+	a.Plan.Memory = 1024 * 1024 * 1024
+	var created *apiv1.Pod
+	s.client.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		created = action.(ktesting.CreateAction).GetObject().(*apiv1.Pod).DeepCopy()
+		return false, nil, nil
+	})
+	out := safe.NewBuffer(nil)
+	err := s.p.RunTask(context.TODO(), provision.TaskOptions{
+		App:      a,
+		Cmds:     []string{"./migrate"},
+		Output:   out,
+		Timeout:  10 * time.Minute,
+		CPUMilli: 500,
+		Memory:   256 * 1024 * 1024,
+	})
+	c.Assert(err, check.IsNil, check.Commentf("%+v", err))
+	c.Assert(strings.Contains(out.String(), "stdout data"), check.Equals, true)
+	c.Assert(created, check.NotNil)
+	c.Assert(created.Name, check.Matches, `myapp-task-[a-z0-9]{6}`)
+	c.Assert(created.Labels["tsuru.io/is-isolated-run"], check.Equals, "true")
+	c.Assert(*created.Spec.ActiveDeadlineSeconds, check.Equals, int64(600))
+	limits := created.Spec.Containers[0].Resources.Limits
+	c.Assert(limits[apiv1.ResourceMemory], check.DeepEquals, *resource.NewQuantity(256*1024*1024, resource.BinarySI))
+	c.Assert(limits[apiv1.ResourceCPU], check.DeepEquals, *resource.NewMilliQuantity(500, resource.DecimalSI))
+	c.Assert(a.Plan.Override, check.IsNil)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/servicemanager"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const oomKilledReason = "OOMKilled"
+
+// unitStatusChanges compares two observations of the same pod and returns
+// the transitions worth recording in the unit history: container restarts
+// and terminations.
+func unitStatusChanges(oldPod, newPod *apiv1.Pod) []provTypes.UnitStatusHistory {
+	if oldPod == nil || newPod == nil {
+		return nil
+	}
+	if newPod.Labels[tsuruLabelIsBuild] == "true" {
+		return nil
+	}
+	l := labelSetFromMeta(&newPod.ObjectMeta)
+	if l.AppName() == "" || l.IsIsolatedRun() {
+		return nil
+	}
+	oldStatuses := map[string]apiv1.ContainerStatus{}
+	for _, cs := range oldPod.Status.ContainerStatuses {
+		oldStatuses[cs.Name] = cs
+	}
+	var changes []provTypes.UnitStatusHistory
+	for _, cs := range newPod.Status.ContainerStatuses {
+		oldCS, ok := oldStatuses[cs.Name]
+		if !ok {
+			continue
+		}
+		restarted := cs.RestartCount > oldCS.RestartCount
+		terminated := cs.State.Terminated != nil && oldCS.State.Terminated == nil
+		if !restarted && !terminated {
+			continue
+		}
+		h := provTypes.UnitStatusHistory{
+			AppName:     l.AppName(),
+			Unit:        newPod.Name,
+			ProcessName: l.AppProcess(),
+			Version:     l.AppVersion(),
+			Status:      provTypes.UnitStatusError,
+			Restarts:    cs.RestartCount - oldCS.RestartCount,
+			Time:        time.Now().UTC(),
+		}
+		termination := cs.State.Terminated
+		if termination == nil {
+			termination = cs.LastTerminationState.Terminated
+		}
+		if termination != nil {
+			exitCode := termination.ExitCode
+			h.ExitCode = &exitCode
+			h.Reason = termination.Reason
+			h.Message = termination.Message
+			h.OOMKilled = termination.Reason == oomKilledReason
+			if !termination.FinishedAt.IsZero() {
+				h.Time = termination.FinishedAt.Time.UTC()
+			}
+			if exitCode == 0 && !restarted {
+				h.Status = provTypes.UnitStatusStopped
+			}
+		}
+		changes = append(changes, h)
+	}
+	return changes
+}
+
+func (c *clusterController) recordUnitStatusChanges(oldPod, newPod *apiv1.Pod) {
+	if !c.isLeader() {
+		return
+	}
+	changes := unitStatusChanges(oldPod, newPod)
+	if len(changes) == 0 {
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		for _, h := range changes {
+			if err := servicemanager.App.AddUnitStatusHistory(ctx, h); err != nil {
+				log.Errorf("[unit-history] unable to record status of unit %q: %v", h.Unit, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"time"
+
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestUnitStatusChanges(c *check.C) {
+	finishedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	meta := metav1.ObjectMeta{
+		Name: "myapp-web-1",
+		Labels: map[string]string{
+			"tsuru.io/app-name":    "myapp",
+			"tsuru.io/app-process": "web",
+			"tsuru.io/app-version": "2",
+		},
+	}
+	oldPod := &apiv1.Pod{
+		ObjectMeta: meta,
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: "myapp-web", RestartCount: 1},
+			},
+		},
+	}
+	newPod := &apiv1.Pod{
+		ObjectMeta: meta,
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{
+					Name:         "myapp-web",
+					RestartCount: 2,
+					LastTerminationState: apiv1.ContainerState{
+						Terminated: &apiv1.ContainerStateTerminated{
+							ExitCode:   137,
+							Reason:     "OOMKilled",
+							FinishedAt: metav1.NewTime(finishedAt),
+						},
+					},
+				},
+			},
+		},
+	}
+	exitCode := int32(137)
+	c.Assert(unitStatusChanges(oldPod, newPod), check.DeepEquals, []provTypes.UnitStatusHistory{
+		{
+			AppName:     "myapp",
+			Unit:        "myapp-web-1",
+			ProcessName: "web",
+			Version:     2,
+			Status:      provTypes.UnitStatusError,
+			Reason:      "OOMKilled",
+			ExitCode:    &exitCode,
+			Restarts:    1,
+			OOMKilled:   true,
+			Time:        finishedAt,
+		},
+	})
+	c.Assert(unitStatusChanges(newPod, newPod), check.HasLen, 0)
+}
+
+func (s *S) TestUnitStatusChangesIgnoresBuildPods(c *check.C) {
+	meta := metav1.ObjectMeta{
+		Name: "myapp-build",
+		Labels: map[string]string{
+			"tsuru.io/app-name": "myapp",
+			"tsuru.io/is-build": "true",
+		},
+	}
+	oldPod := &apiv1.Pod{ObjectMeta: meta, Status: apiv1.PodStatus{
+		ContainerStatuses: []apiv1.ContainerStatus{{Name: "build"}},
+	}}
+	newPod := &apiv1.Pod{ObjectMeta: meta, Status: apiv1.PodStatus{
+		ContainerStatuses: []apiv1.ContainerStatus{{Name: "build", RestartCount: 1}},
+	}}
+	c.Assert(unitStatusChanges(oldPod, newPod), check.HasLen, 0)
+}
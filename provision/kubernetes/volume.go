@@ -7,6 +7,7 @@ package kubernetes
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -28,6 +29,14 @@ type volumeOptions struct {
 	StorageClass string `json:"storage-class"`
 	Capacity     resource.Quantity
 	AccessModes  string `json:"access-modes"`
+	// MountOptions is a comma separated list of mount options of the
+	// persistent volumes created for plugins.
+	MountOptions string `json:"mount-options"`
+	// Secrets maps the kind of secret handed to a CSI driver, like
+	// node-publish, to its data, kept by tsuru in the namespace of the
+	// volume claims. They are read from the config of the plan, as they are
+	// never stored with the volume.
+	Secrets map[string]map[string]string `json:"-"`
 }
 
 var allowedNonPersistentVolumes = set.FromValues("emptyDir", "ephemeral")
@@ -49,7 +58,7 @@ func createVolumesForApp(ctx context.Context, client *ClusterClient, app *appTyp
 			return nil, nil, err
 		}
 		if opts.isPersistent() {
-			err = createVolume(ctx, client, &volumes[i], opts)
+			err = createVolume(ctx, client, &volumes[i], opts, app)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -162,6 +171,16 @@ func validateVolume(v *volumeTypes.Volume) (*volumeOptions, error) {
 	if opts.Plugin == "" && opts.StorageClass == "" {
 		return nil, errors.New("both volume plan plugin and storage-class are empty")
 	}
+	if opts.MountOptions != "" && (opts.Plugin == "" || !opts.isPersistent()) {
+		return nil, errors.New("volume plan mount-options are only supported by persistent volume plugins")
+	}
+	opts.Secrets, err = volumePlanSecrets(v.Plan.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateVolumeSecrets(&opts); err != nil {
+		return nil, err
+	}
 	if !opts.isPersistent() && opts.Plugin == "emptyDir" {
 		return &opts, nil
 	}
@@ -197,6 +216,20 @@ func pvcForVolume(ctx context.Context, client *ClusterClient, name string) ([]ap
 	return pvcItems.Items, nil
 }
 
+func pvForVolume(ctx context.Context, client *ClusterClient, name string) ([]apiv1.PersistentVolume, error) {
+	labelSet := provision.VolumeLabels(provision.VolumeLabelsOpts{
+		Name:   name,
+		Prefix: tsuruLabelPrefix,
+	})
+	pvItems, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set(labelSet.ToVolumeSelector())).String(),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pvItems.Items, nil
+}
+
 func deleteVolume(ctx context.Context, client *ClusterClient, name string) error {
 	err := client.CoreV1().PersistentVolumes().Delete(ctx, volumeName(name), metav1.DeleteOptions{
 		PropagationPolicy: propagationPtr(metav1.DeletePropagationForeground),
@@ -204,6 +237,19 @@ func deleteVolume(ctx context.Context, client *ClusterClient, name string) error
 	if err != nil && !k8sErrors.IsNotFound(err) {
 		return errors.WithStack(err)
 	}
+	// volumes shared across pools have one persistent volume per namespace.
+	pvItems, err := pvForVolume(ctx, client, name)
+	if err != nil {
+		return err
+	}
+	for _, pv := range pvItems {
+		err = client.CoreV1().PersistentVolumes().Delete(ctx, pv.Name, metav1.DeleteOptions{
+			PropagationPolicy: propagationPtr(metav1.DeletePropagationForeground),
+		})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+	}
 	pvcItems, err := pvcForVolume(ctx, client, name)
 	if err != nil {
 		return err
@@ -216,11 +262,11 @@ func deleteVolume(ctx context.Context, client *ClusterClient, name string) error
 			return err
 		}
 	}
-	return nil
+	return deleteVolumeSecrets(ctx, client, name)
 }
 
-func createVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Volume, opts *volumeOptions) error {
-	namespace, err := getNamespaceForVolume(ctx, client, v)
+func createVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Volume, opts *volumeOptions, a *appTypes.App) error {
+	namespace, err := getNamespaceForVolume(ctx, client, v, a)
 	if err != nil {
 		return err
 	}
@@ -231,6 +277,10 @@ func createVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Vol
 		Plan:   v.Plan.Name,
 		Team:   v.TeamOwner,
 	})
+	secretRefs, err := ensureVolumeSecrets(ctx, client, v, opts, namespace, labelSet)
+	if err != nil {
+		return err
+	}
 	capacity := apiv1.ResourceList{
 		apiv1.ResourceStorage: opts.Capacity,
 	}
@@ -257,9 +307,16 @@ func createVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Vol
 		}
 		pvSpec.Capacity = capacity
 		pvSpec.AccessModes = accessModes
+		if opts.MountOptions != "" {
+			pvSpec.MountOptions = strings.Split(opts.MountOptions, ",")
+		}
+		err = setCSISecretRefs(&pvSpec, secretRefs)
+		if err != nil {
+			return err
+		}
 		pv := &apiv1.PersistentVolume{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:   volumeName(v.Name),
+				Name:   persistentVolumeName(v, namespace),
 				Labels: labelSet.ToLabels(),
 			},
 			Spec: pvSpec,
@@ -271,7 +328,7 @@ func createVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Vol
 		selector = &metav1.LabelSelector{
 			MatchLabels: labelSet.ToVolumeSelector(),
 		}
-		volName = volumeName(v.Name)
+		volName = persistentVolumeName(v, namespace)
 	}
 	pvc := &apiv1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -310,13 +367,30 @@ func volumeExists(ctx context.Context, client *ClusterClient, name string) (bool
 	return false, nil
 }
 
-func getNamespaceForVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Volume) (string, error) {
+// persistentVolumeName returns the name of the persistent volume backing the
+// claims of the volume in the namespace. Volumes shared across pools have one
+// persistent volume for each namespace they are claimed in, as a persistent
+// volume is bound to a single claim.
+func persistentVolumeName(v *volumeTypes.Volume, namespace string) string {
+	if v.IsShared() {
+		return fmt.Sprintf("%s-%s", volumeName(v.Name), namespace)
+	}
+	return volumeName(v.Name)
+}
+
+// getNamespaceForVolume returns the namespace of the claim of the volume
+// used by the app. Volumes not shared across pools must have all their apps
+// in the same namespace.
+func getNamespaceForVolume(ctx context.Context, client *ClusterClient, v *volumeTypes.Volume, a *appTypes.App) (string, error) {
+	if v.IsShared() {
+		return client.AppNamespace(ctx, a)
+	}
 	binds, err := servicemanager.Volume.Binds(ctx, v)
 	if err != nil {
 		return "", err
 	}
 	if len(binds) == 0 {
-		return client.PoolNamespace(v.Pool), nil
+		return client.TeamNamespace(ctx, v.Pool, v.TeamOwner)
 	}
 	var namespace string
 	for _, b := range binds {
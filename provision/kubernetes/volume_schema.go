@@ -0,0 +1,128 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/set"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// volumeOptSchema describes a volume opt accepted by a plugin. validate,
+// when set, returns why the value is invalid.
+type volumeOptSchema struct {
+	required bool
+	validate func(value string) string
+}
+
+// volumePluginSchema maps the opts accepted by a plugin, besides capacity
+// and access-modes, to their schema.
+type volumePluginSchema map[string]volumeOptSchema
+
+// volumePluginSchemas holds the schemas of the well known plugins, volumes
+// using other plugins have their opts handed to the cluster as they are.
+var volumePluginSchemas = map[string]volumePluginSchema{
+	"nfs": {
+		"server":   {required: true},
+		"path":     {required: true, validate: validateAbsolutePathOpt},
+		"readOnly": {validate: validateBoolOpt},
+	},
+	"hostPath": {
+		"path": {required: true, validate: validateAbsolutePathOpt},
+		"type": {},
+	},
+	"emptyDir": {
+		"medium":    {validate: validateEmptyDirMediumOpt},
+		"sizeLimit": {validate: validateQuantityOpt},
+	},
+	"ephemeral": {},
+}
+
+var volumeAccessModes = set.FromValues(
+	string(apiv1.ReadWriteOnce),
+	string(apiv1.ReadOnlyMany),
+	string(apiv1.ReadWriteMany),
+	string(apiv1.ReadWriteOncePod),
+)
+
+// validateVolumeOpts checks the opts of the volume against the schema of the
+// plugin of its plan, so misconfigured volumes are refused when created or
+// updated instead of failing when bound to an app.
+func validateVolumeOpts(v *volumeTypes.Volume, opts *volumeOptions) error {
+	if opts.AccessModes != "" {
+		for _, am := range strings.Split(opts.AccessModes, ",") {
+			if !volumeAccessModes.Includes(am) {
+				return volumeOptError("invalid access mode %q, must be one of %s", am, strings.Join(volumeAccessModes.Sorted(), ", "))
+			}
+		}
+	}
+	schema, ok := volumePluginSchemas[opts.Plugin]
+	if !ok {
+		return nil
+	}
+	names := set.FromMap(schema).Sorted()
+	for _, name := range names {
+		opt := schema[name]
+		value, isSet := v.Opts[name]
+		if !isSet {
+			if opt.required {
+				return volumeOptError("volume plan %q uses the %s plugin, which requires the %q opt", v.Plan.Name, opts.Plugin, name)
+			}
+			continue
+		}
+		if opt.validate == nil {
+			continue
+		}
+		if reason := opt.validate(value); reason != "" {
+			return volumeOptError("invalid value %q for the %q opt of the %s plugin: %s", value, name, opts.Plugin, reason)
+		}
+	}
+	accepted := append([]string{"capacity", "access-modes"}, names...)
+	for _, name := range set.FromMap(v.Opts).Sorted() {
+		if !set.FromSlice(accepted).Includes(name) {
+			return volumeOptError("unknown opt %q for the %s plugin, accepted opts are: %s", name, opts.Plugin, strings.Join(accepted, ", "))
+		}
+	}
+	return nil
+}
+
+func volumeOptError(format string, args ...interface{}) error {
+	return &tsuruErrors.ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+func validateAbsolutePathOpt(value string) string {
+	if !path.IsAbs(value) {
+		return "must be an absolute path"
+	}
+	return ""
+}
+
+func validateBoolOpt(value string) string {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return "must be true or false"
+	}
+	return ""
+}
+
+func validateQuantityOpt(value string) string {
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return "must be a quantity, like 1Gi"
+	}
+	return ""
+}
+
+func validateEmptyDirMediumOpt(value string) string {
+	if value != "" && value != string(apiv1.StorageMediumMemory) {
+		return "must be empty or Memory"
+	}
+	return ""
+}
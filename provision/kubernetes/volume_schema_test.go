@@ -0,0 +1,79 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestValidateVolumeOpts(c *check.C) {
+	tests := []struct {
+		planOpts map[string]interface{}
+		opts     map[string]string
+		err      string
+	}{
+		{
+			planOpts: map[string]interface{}{"plugin": "nfs"},
+			opts:     map[string]string{"server": "192.168.1.1", "path": "/exports", "capacity": "20Gi", "access-modes": "ReadWriteMany"},
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "nfs"},
+			opts:     map[string]string{"path": "/exports", "capacity": "20Gi", "access-modes": "ReadWriteMany"},
+			err:      `volume plan "p1" uses the nfs plugin, which requires the "server" opt`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "nfs"},
+			opts:     map[string]string{"server": "192.168.1.1", "path": "exports", "capacity": "20Gi", "access-modes": "ReadWriteMany"},
+			err:      `invalid value "exports" for the "path" opt of the nfs plugin: must be an absolute path`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "nfs"},
+			opts:     map[string]string{"server": "192.168.1.1", "path": "/exports", "capacity": "20Gi", "access-modes": "ReadWriteMany", "mountOptions": "hard"},
+			err:      `unknown opt "mountOptions" for the nfs plugin, accepted opts are: capacity, access-modes, path, readOnly, server`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "nfs"},
+			opts:     map[string]string{"server": "192.168.1.1", "path": "/exports", "capacity": "20Gi", "access-modes": "ReadWriteAll"},
+			err:      `invalid access mode "ReadWriteAll", must be one of ReadOnlyMany, ReadWriteMany, ReadWriteOnce, ReadWriteOncePod`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "emptyDir"},
+			opts:     map[string]string{"medium": "Memory", "sizeLimit": "1Gi"},
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "emptyDir"},
+			opts:     map[string]string{"sizeLimit": "lots"},
+			err:      `invalid value "lots" for the "sizeLimit" opt of the emptyDir plugin: must be a quantity, like 1Gi`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "ephemeral", "storage-class": "ssd", "access-modes": "ReadWriteOnce"},
+			opts:     map[string]string{"capacity": "10Gi", "path": "/data"},
+			err:      `unknown opt "path" for the ephemeral plugin, accepted opts are: capacity, access-modes`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "csi"},
+			opts:     map[string]string{"driver": "ebs.csi.aws.com", "capacity": "10Gi", "access-modes": "ReadWriteOnce"},
+		},
+	}
+	for i, tt := range tests {
+		v := &volumeTypes.Volume{
+			Name: "v1",
+			Plan: volumeTypes.VolumePlan{Name: "p1", Opts: tt.planOpts},
+			Opts: tt.opts,
+		}
+		err := s.p.ValidateVolume(context.TODO(), v)
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("test %d", i))
+			continue
+		}
+		c.Check(err, check.ErrorMatches, tt.err, check.Commentf("test %d", i))
+		_, ok := err.(*tsuruErrors.ValidationError)
+		c.Check(ok, check.Equals, true, check.Commentf("test %d", i))
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	internalConfig "github.com/tsuru/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/set"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// csiSecretRefs are the kinds of secrets a CSI driver may be handed, as
+// declared in the secrets of volume plans.
+var csiSecretRefs = set.FromValues(
+	"controller-publish",
+	"controller-expand",
+	"node-stage",
+	"node-publish",
+	"node-expand",
+)
+
+// volumePlanSecrets returns the secrets declared in the kubernetes config of
+// the volume plan.
+func volumePlanSecrets(planName string) (map[string]map[string]string, error) {
+	data, err := config.Get(fmt.Sprintf("volume-plans:%s:kubernetes:%s", planName, volumeTypes.PlanSecretsKey))
+	if err != nil {
+		return nil, nil
+	}
+	rawSecrets, err := json.Marshal(internalConfig.ConvertEntries(data))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var secrets map[string]map[string]string
+	if err = json.Unmarshal(rawSecrets, &secrets); err != nil {
+		return nil, errors.Wrapf(err, "invalid secrets for volume plan %q, each one must map keys to strings", planName)
+	}
+	return secrets, nil
+}
+
+func validateVolumeSecrets(opts *volumeOptions) error {
+	if len(opts.Secrets) == 0 {
+		return nil
+	}
+	if opts.Plugin != "" && opts.Plugin != "csi" {
+		return errors.Errorf("volume plan secrets are only supported by the csi plugin or storage classes, got plugin %q", opts.Plugin)
+	}
+	for ref, data := range opts.Secrets {
+		if !csiSecretRefs.Includes(ref) {
+			return errors.Errorf("invalid volume plan secret %q, must be one of %v", ref, csiSecretRefs.Sorted())
+		}
+		if len(data) == 0 {
+			return errors.Errorf("volume plan secret %q has no data", ref)
+		}
+	}
+	return nil
+}
+
+// volumeSecretName returns the name of the secret of the volume handed to
+// the CSI driver. Storage classes may refer to it in their parameters as
+// ${pvc.name}-<ref>, like ${pvc.name}-node-publish.
+func volumeSecretName(name, ref string) string {
+	return fmt.Sprintf("%s-%s", volumeClaimName(name), ref)
+}
+
+// ensureVolumeSecrets creates the secrets declared in the plan of the volume
+// in the namespace, updating them when the plan changes so credentials are
+// rotated on the next deploy or restart of the apps using the volume.
+func ensureVolumeSecrets(ctx context.Context, client *ClusterClient, v *volumeTypes.Volume, opts *volumeOptions, namespace string, labelSet *provision.LabelSet) (map[string]*apiv1.SecretReference, error) {
+	refs := map[string]*apiv1.SecretReference{}
+	for ref, data := range opts.Secrets {
+		secret := &apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      volumeSecretName(v.Name, ref),
+				Namespace: namespace,
+				Labels:    labelSet.ToLabels(),
+			},
+			StringData: data,
+		}
+		existing, err := client.CoreV1().Secrets(namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			_, err = client.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		} else if err == nil && !reflect.DeepEqual(secretStringData(existing), data) {
+			existing.Data = nil
+			existing.StringData = data
+			_, err = client.CoreV1().Secrets(namespace).Update(ctx, existing, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		refs[ref] = &apiv1.SecretReference{Name: secret.Name, Namespace: namespace}
+	}
+	return refs, nil
+}
+
+func secretStringData(secret *apiv1.Secret) map[string]string {
+	data := map[string]string{}
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		data[k] = v
+	}
+	return data
+}
+
+// setCSISecretRefs points the CSI source of the persistent volume to the
+// secrets of the volume.
+func setCSISecretRefs(pvSpec *apiv1.PersistentVolumeSpec, refs map[string]*apiv1.SecretReference) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	csi := pvSpec.CSI
+	if csi == nil {
+		return errors.New("volume plan secrets require a csi volume source")
+	}
+	csi.ControllerPublishSecretRef = refs["controller-publish"]
+	csi.ControllerExpandSecretRef = refs["controller-expand"]
+	csi.NodeStageSecretRef = refs["node-stage"]
+	csi.NodePublishSecretRef = refs["node-publish"]
+	csi.NodeExpandSecretRef = refs["node-expand"]
+	return nil
+}
+
+func deleteVolumeSecrets(ctx context.Context, client *ClusterClient, name string) error {
+	labelSet := provision.VolumeLabels(provision.VolumeLabelsOpts{
+		Name:   name,
+		Prefix: tsuruLabelPrefix,
+	})
+	secrets, err := client.CoreV1().Secrets("").List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set(labelSet.ToVolumeSelector())).String(),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, secret := range secrets.Items {
+		err = client.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/servicemanager"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestCreateVolumesForAppCSISecrets(c *check.C) {
+	config.Set("volume-plans:p1:kubernetes:plugin", "csi")
+	config.Set("volume-plans:p1:kubernetes:mount-options", "nconnect=4,hard")
+	config.Set("volume-plans:p1:kubernetes:secrets", map[interface{}]interface{}{
+		"node-publish": map[interface{}]interface{}{"user": "admin", "password": "abc123"},
+	})
+	defer config.Unset("volume-plans")
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	err := s.p.Provision(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	v := volumeTypes.Volume{
+		Name: "v1",
+		Opts: map[string]string{
+			"driver":       "fs.csi.example.com",
+			"volumeHandle": "fs-1234",
+			"capacity":     "20Gi",
+			"access-modes": string(apiv1.ReadWriteMany),
+		},
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "test-default",
+		TeamOwner: "admin",
+	}
+	err = servicemanager.Volume.Create(context.TODO(), &v)
+	c.Assert(err, check.IsNil)
+	c.Assert(v.Plan.Opts[volumeTypes.PlanSecretsKey], check.IsNil)
+	err = servicemanager.Volume.BindApp(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		AppName:    a.Name,
+		MountPoint: "/mnt",
+	})
+	c.Assert(err, check.IsNil)
+	_, _, err = createVolumesForApp(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	secret, err := s.client.CoreV1().Secrets(ns).Get(context.TODO(), "v1-tsuru-claim-node-publish", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.StringData, check.DeepEquals, map[string]string{"user": "admin", "password": "abc123"})
+	c.Assert(secret.Labels["tsuru.io/volume-name"], check.Equals, "v1")
+	pv, err := s.client.CoreV1().PersistentVolumes().Get(context.TODO(), volumeName(v.Name), metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(pv.Spec.MountOptions, check.DeepEquals, []string{"nconnect=4", "hard"})
+	c.Assert(pv.Spec.CSI.Driver, check.Equals, "fs.csi.example.com")
+	c.Assert(pv.Spec.CSI.NodePublishSecretRef, check.DeepEquals, &apiv1.SecretReference{
+		Name:      "v1-tsuru-claim-node-publish",
+		Namespace: ns,
+	})
+	c.Assert(pv.Spec.CSI.NodeStageSecretRef, check.IsNil)
+
+	config.Set("volume-plans:p1:kubernetes:secrets", map[interface{}]interface{}{
+		"node-publish": map[interface{}]interface{}{"user": "admin", "password": "rotated"},
+	})
+	_, _, err = createVolumesForApp(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	secret, err = s.client.CoreV1().Secrets(ns).Get(context.TODO(), "v1-tsuru-claim-node-publish", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.StringData, check.DeepEquals, map[string]string{"user": "admin", "password": "rotated"})
+
+	err = deleteVolume(context.TODO(), s.clusterClient, "v1")
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().Secrets(ns).Get(context.TODO(), "v1-tsuru-claim-node-publish", metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+}
+
+func (s *S) TestValidateVolumeSecrets(c *check.C) {
+	defer config.Unset("volume-plans")
+	tests := []struct {
+		planOpts map[string]interface{}
+		secrets  interface{}
+		err      string
+	}{
+		{
+			planOpts: map[string]interface{}{"plugin": "csi"},
+			secrets:  map[interface{}]interface{}{"node-stage": map[interface{}]interface{}{"key": "value"}},
+		},
+		{
+			planOpts: map[string]interface{}{"storage-class": "ssd"},
+			secrets:  map[interface{}]interface{}{"node-stage": map[interface{}]interface{}{"key": "value"}},
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "nfs"},
+			secrets:  map[interface{}]interface{}{"node-stage": map[interface{}]interface{}{"key": "value"}},
+			err:      `volume plan secrets are only supported by the csi plugin or storage classes, got plugin "nfs"`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "csi"},
+			secrets:  map[interface{}]interface{}{"mount": map[interface{}]interface{}{"key": "value"}},
+			err:      `invalid volume plan secret "mount", must be one of \[controller-expand controller-publish node-expand node-publish node-stage\]`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "csi"},
+			secrets:  map[interface{}]interface{}{"node-stage": map[interface{}]interface{}{}},
+			err:      `volume plan secret "node-stage" has no data`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "csi"},
+			secrets:  map[interface{}]interface{}{"node-stage": "value"},
+			err:      `invalid secrets for volume plan "p1", each one must map keys to strings: .*`,
+		},
+		{
+			planOpts: map[string]interface{}{"plugin": "emptyDir", "mount-options": "hard"},
+			err:      `volume plan mount-options are only supported by persistent volume plugins`,
+		},
+	}
+	for i, tt := range tests {
+		config.Unset("volume-plans")
+		if tt.secrets != nil {
+			config.Set("volume-plans:p1:kubernetes:secrets", tt.secrets)
+		}
+		v := &volumeTypes.Volume{
+			Name: "v1",
+			Plan: volumeTypes.VolumePlan{Name: "p1", Opts: tt.planOpts},
+			Opts: map[string]string{"capacity": "10Gi", "access-modes": string(apiv1.ReadWriteOnce)},
+		}
+		_, err := validateVolume(v)
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("test %d", i))
+			continue
+		}
+		c.Check(err, check.ErrorMatches, tt.err, check.Commentf("test %d", i))
+	}
+}
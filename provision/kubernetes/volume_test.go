@@ -9,6 +9,7 @@ import (
 
 	"github.com/tsuru/config"
 	tsuruv1 "github.com/tsuru/tsuru/provision/kubernetes/pkg/apis/tsuru/v1"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/servicemanager"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
@@ -499,6 +500,76 @@ func (s *S) TestCreateVolumeMultipleNamespacesFail(c *check.C) {
 	c.Assert(err, check.ErrorMatches, `multiple namespaces for volume not allowed: "tsuru-otherpool" and "tsuru-test-default"`)
 }
 
+func (s *S) TestCreateVolumeSharedAcrossPools(c *check.C) {
+	config.Set("kubernetes:use-pool-namespaces", true)
+	defer config.Unset("kubernetes:use-pool-namespaces")
+	config.Set("volume-plans:p1:kubernetes:plugin", "nfs")
+	defer config.Unset("volume-plans")
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:        "otherpool",
+		Provisioner: "kubernetes",
+	})
+	c.Assert(err, check.IsNil)
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	err = s.p.Provision(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	otherApp := provisiontest.NewFakeAppWithPool("otherapp", "python", "otherpool", 0)
+	err = s.p.Provision(context.TODO(), otherApp)
+	c.Assert(err, check.IsNil)
+	v := volumeTypes.Volume{
+		Name: "v1",
+		Opts: map[string]string{
+			"path":         "/exports",
+			"server":       "192.168.1.1",
+			"capacity":     "20Gi",
+			"access-modes": string(apiv1.ReadOnlyMany),
+		},
+		Plan:        volumeTypes.VolumePlan{Name: "p1"},
+		Pool:        "test-default",
+		SharedPools: []string{"otherpool"},
+		TeamOwner:   "admin",
+	}
+	err = servicemanager.Volume.Create(context.TODO(), &v)
+	c.Assert(err, check.IsNil)
+	for _, appName := range []string{a.Name, otherApp.Name} {
+		err = servicemanager.Volume.BindApp(context.TODO(), &volumeTypes.BindOpts{
+			Volume:     &v,
+			AppName:    appName,
+			MountPoint: "/mnt",
+			ReadOnly:   true,
+		})
+		c.Assert(err, check.IsNil)
+	}
+	_, _, err = createVolumesForApp(context.TODO(), s.clusterClient, a)
+	c.Assert(err, check.IsNil)
+	_, _, err = createVolumesForApp(context.TODO(), s.clusterClient, otherApp)
+	c.Assert(err, check.IsNil)
+	for _, ns := range []string{"tsuru-test-default", "tsuru-otherpool"} {
+		pvc, err := s.client.CoreV1().PersistentVolumeClaims(ns).Get(context.TODO(), volumeClaimName(v.Name), metav1.GetOptions{})
+		c.Assert(err, check.IsNil)
+		c.Assert(pvc.Spec.VolumeName, check.Equals, "v1-tsuru-"+ns)
+		pv, err := s.client.CoreV1().PersistentVolumes().Get(context.TODO(), "v1-tsuru-"+ns, metav1.GetOptions{})
+		c.Assert(err, check.IsNil)
+		c.Assert(pv.Spec.NFS, check.DeepEquals, &apiv1.NFSVolumeSource{Server: "192.168.1.1", Path: "/exports"})
+	}
+	err = deleteVolume(context.TODO(), s.clusterClient, "v1")
+	c.Assert(err, check.IsNil)
+	pvs, err := s.client.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(pvs.Items, check.HasLen, 0)
+}
+
+func (s *S) TestValidateSharedVolumeWithStorageClass(c *check.C) {
+	v := &volumeTypes.Volume{
+		Name:        "v1",
+		Plan:        volumeTypes.VolumePlan{Name: "p1", Opts: map[string]interface{}{"storage-class": "ssd"}},
+		Opts:        map[string]string{"capacity": "20Gi", "access-modes": string(apiv1.ReadOnlyMany)},
+		SharedPools: []string{"otherpool"},
+	}
+	err := s.p.ValidateVolume(context.TODO(), v)
+	c.Assert(err, check.ErrorMatches, `only volumes using a persistent volume plugin, like nfs, may be shared across pools`)
+}
+
 func (s *S) TestDeleteVolume(c *check.C) {
 	config.Set("volume-plans:p1:kubernetes:plugin", "nfs")
 	defer config.Unset("volume-plans")
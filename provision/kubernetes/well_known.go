@@ -20,6 +20,11 @@ const (
 	// only VPA for the application. Its value must be a boolean.
 	AnnotationEnableVPA = "app.tsuru.io/enable-vpa"
 
+	// AnnotationVersionPlanOverride stores in the deployment of a version the
+	// plan override sent in its deploy, as a serialized json object, so it's
+	// kept when the units of the version are recreated.
+	AnnotationVersionPlanOverride = "app.tsuru.io/version-plan-override"
+
 	// AnnotationKEDAPausedReplicas is used to pause the scaling of an app using KEDA scaling
 	// Introduced to avoid scaling up the app when the user requested an app to be stopped
 	AnnotationKEDAPausedReplicas = "autoscaling.keda.sh/paused-replicas"
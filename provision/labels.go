@@ -194,6 +194,10 @@ func (s *LabelSet) AppPool() string {
 	return s.getLabel(LabelAppPool)
 }
 
+func (s *LabelSet) VolumeName() string {
+	return s.getLabel(labelVolumeName)
+}
+
 func (s *LabelSet) NodeAddr() string {
 	return s.getLabel(labelNodeAddr)
 }
@@ -311,6 +315,14 @@ func (s *LabelSet) IsService() bool {
 	return s.getBoolLabel(labelIsService)
 }
 
+func (s *LabelSet) IsBuild() bool {
+	return s.getBoolLabel(LabelIsBuild)
+}
+
+func (s *LabelSet) IsJob() bool {
+	return s.getBoolLabel(LabelIsJob)
+}
+
 func (s *LabelSet) IsIsolatedRun() bool {
 	return s.getBoolLabel(labelIsIsolatedRun) || s.getBoolLabel(labelIsIsolatedRunNew)
 }
@@ -20,7 +20,7 @@ import (
 
 var (
 	ErrInvalidConstraintType = errors.Errorf("invalid constraint type. Valid types are: %s", validConstraintTypes)
-	validConstraintTypes     = []PoolConstraintType{ConstraintTypeTeam, ConstraintTypeService, ConstraintTypeRouter, ConstraintTypePlan, ConstraintTypeVolumePlan, ConstraintTypeCertIssuer}
+	validConstraintTypes     = []PoolConstraintType{ConstraintTypeTeam, ConstraintTypeService, ConstraintTypeRouter, ConstraintTypePlan, ConstraintTypeVolumePlan, ConstraintTypeCertIssuer, ConstraintTypeNodeSelector, ConstraintTypeToleration, ConstraintTypeAnnotation, ConstraintTypeLabel}
 )
 
 type PoolConstraintType string
@@ -32,6 +32,17 @@ const (
 	ConstraintTypePlan       = PoolConstraintType("plan")
 	ConstraintTypeVolumePlan = PoolConstraintType("volume-plan")
 	ConstraintTypeCertIssuer = PoolConstraintType("cert-issuer")
+
+	// ConstraintTypeNodeSelector and ConstraintTypeToleration restrict the
+	// keys of node selectors and tolerations of app processes in the pool.
+	ConstraintTypeNodeSelector = PoolConstraintType("node-selector")
+	ConstraintTypeToleration   = PoolConstraintType("toleration")
+
+	// ConstraintTypeAnnotation and ConstraintTypeLabel restrict the names of
+	// the annotations and labels set in the metadata of apps and their
+	// processes in the pool.
+	ConstraintTypeAnnotation = PoolConstraintType("annotation")
+	ConstraintTypeLabel      = PoolConstraintType("label")
 )
 
 type regexpCache struct {
@@ -219,6 +230,12 @@ func getConstraintsForPool(ctx context.Context, pool string, fields ...PoolConst
 	if err != nil {
 		return nil, err
 	}
+	return mergeConstraintsForPool(pool, constraints)
+}
+
+// mergeConstraintsForPool returns the constraint applied to the pool for
+// each field, the one with the most specific pool expression.
+func mergeConstraintsForPool(pool string, constraints []*PoolConstraint) (map[PoolConstraintType]*PoolConstraint, error) {
 	var matches []*PoolConstraint
 	for _, c := range constraints {
 		pattern := exprAsGlobPattern(c.PoolExpr)
@@ -240,6 +257,75 @@ func getConstraintsForPool(ctx context.Context, pool string, fields ...PoolConst
 	return merged, nil
 }
 
+// Allows reports whether the value is allowed by the constraint, a nil
+// constraint allows every value.
+func (c *PoolConstraint) Allows(v string) bool {
+	if c == nil {
+		return true
+	}
+	return c.check(v)
+}
+
+// SimulateConstraint returns the constraint of the same field that would be
+// applied to each pool matching the pool expression of c after setting it,
+// or appending its values when appendValues is true. Nothing is changed in
+// the storage. Pools left without constraints for the field are mapped to
+// nil.
+func SimulateConstraint(ctx context.Context, c *PoolConstraint, appendValues bool) (map[string]*PoolConstraint, error) {
+	if !validateConstraintType(c.Field) {
+		return nil, ErrInvalidConstraintType
+	}
+	constraints, err := ListPoolsConstraints(ctx, mongoBSON.M{"field": c.Field})
+	if err != nil {
+		return nil, err
+	}
+	changed := &PoolConstraint{PoolExpr: c.PoolExpr, Field: c.Field, Values: c.Values, Blacklist: c.Blacklist}
+	// Setting empty values removes an existing constraint, like
+	// SetPoolConstraint does.
+	empty := len(c.Values) == 0 || (len(c.Values) == 1 && c.Values[0] == "")
+	remove := false
+	simulated := []*PoolConstraint{}
+	for _, existing := range constraints {
+		if existing.PoolExpr != c.PoolExpr {
+			simulated = append(simulated, existing)
+			continue
+		}
+		remove = !appendValues && empty
+		if appendValues {
+			changed.Blacklist = existing.Blacklist
+			changed.Values = append([]string{}, existing.Values...)
+			for _, v := range c.Values {
+				if !contains(changed.Values, v) {
+					changed.Values = append(changed.Values, v)
+				}
+			}
+		}
+	}
+	if !remove {
+		simulated = append(simulated, changed)
+	}
+	pools, err := listPools(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]*PoolConstraint{}
+	for _, p := range pools {
+		match, err := rCache.MatchString(exprAsGlobPattern(c.PoolExpr), p.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		merged, err := mergeConstraintsForPool(p.Name, simulated)
+		if err != nil {
+			return nil, err
+		}
+		result[p.Name] = merged[c.Field]
+	}
+	return result, nil
+}
+
 func getExactConstraintForPool(ctx context.Context, pool string, field PoolConstraintType) (*PoolConstraint, error) {
 	constraints, err := ListPoolsConstraints(ctx, mongoBSON.M{"poolexpr": pool, "field": field})
 	if err != nil {
@@ -193,3 +193,36 @@ func (s *S) TestToConstraintType(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(ct, check.Equals, ConstraintTypeTeam)
 }
+
+func (s *S) TestSimulateConstraint(c *check.C) {
+	for _, name := range []string{"prod-a", "prod-b", "dev"} {
+		err := AddPool(context.TODO(), AddPoolOptions{Name: name})
+		c.Assert(err, check.IsNil)
+	}
+	err := SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "prod-*", Field: ConstraintTypeRouter, Values: []string{"router1"}})
+	c.Assert(err, check.IsNil)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "prod-b", Field: ConstraintTypeRouter, Values: []string{"router2"}})
+	c.Assert(err, check.IsNil)
+	simulated, err := SimulateConstraint(context.TODO(), &PoolConstraint{PoolExpr: "prod-*", Field: ConstraintTypeRouter, Values: []string{"router3"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(simulated, check.DeepEquals, map[string]*PoolConstraint{
+		"prod-a": {PoolExpr: "prod-*", Field: ConstraintTypeRouter, Values: []string{"router3"}},
+		"prod-b": {PoolExpr: "prod-b", Field: ConstraintTypeRouter, Values: []string{"router2"}},
+	})
+	simulated, err = SimulateConstraint(context.TODO(), &PoolConstraint{PoolExpr: "prod-*", Field: ConstraintTypeRouter, Values: []string{"router3"}}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(simulated["prod-a"].Values, check.DeepEquals, []string{"router1", "router3"})
+	simulated, err = SimulateConstraint(context.TODO(), &PoolConstraint{PoolExpr: "prod-b", Field: ConstraintTypeRouter, Values: []string{""}}, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(simulated, check.DeepEquals, map[string]*PoolConstraint{
+		"prod-b": {PoolExpr: "prod-*", Field: ConstraintTypeRouter, Values: []string{"router1"}},
+	})
+	c.Assert(simulated["prod-b"].Allows("router1"), check.Equals, true)
+	c.Assert(simulated["prod-b"].Allows("router2"), check.Equals, false)
+	c.Assert((*PoolConstraint)(nil).Allows("router2"), check.Equals, true)
+	constraints, err := ListPoolsConstraints(context.TODO(), mongoBSON.M{"field": ConstraintTypeRouter})
+	c.Assert(err, check.IsNil)
+	c.Assert(constraints, check.HasLen, 2)
+	_, err = SimulateConstraint(context.TODO(), &PoolConstraint{PoolExpr: "*", Field: "invalid"}, false)
+	c.Assert(err, check.Equals, ErrInvalidConstraintType)
+}
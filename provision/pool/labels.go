@@ -0,0 +1,110 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// SchedulingLabels returns the labels of the pool merged with the ones of the
+// cluster serving it, labels set in the pool take precedence.
+func (p *Pool) SchedulingLabels(ctx context.Context) (map[string]string, error) {
+	labels := map[string]string{}
+	prov, err := p.GetProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := servicemanager.Cluster.FindByPool(ctx, prov.GetName(), p.Name)
+	if err != nil && err != provisionTypes.ErrNoCluster {
+		return nil, err
+	}
+	if cluster != nil {
+		for k, v := range cluster.Labels {
+			labels[k] = v
+		}
+	}
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// ValidateSchedulingConstraints checks whether the labels of the pool and its
+// cluster satisfy all the constraints.
+func (p *Pool) ValidateSchedulingConstraints(ctx context.Context, constraints map[string]string) error {
+	if len(constraints) == 0 {
+		return nil
+	}
+	labels, err := p.SchedulingLabels(ctx)
+	if err != nil {
+		return err
+	}
+	var unsatisfied []string
+	for k, v := range constraints {
+		if labels[k] != v {
+			unsatisfied = append(unsatisfied, k+"="+v)
+		}
+	}
+	if len(unsatisfied) == 0 {
+		return nil
+	}
+	sort.Strings(unsatisfied)
+	msg := fmt.Sprintf("pool %q does not satisfy the scheduling constraints: %s", p.Name, strings.Join(unsatisfied, ", "))
+	return &tsuruErrors.ValidationError{Message: msg}
+}
+
+// FilterBySchedulingConstraints returns the pools satisfying the constraints.
+func FilterBySchedulingConstraints(ctx context.Context, pools []Pool, constraints map[string]string) ([]Pool, error) {
+	var result []Pool
+	for i := range pools {
+		err := pools[i].ValidateSchedulingConstraints(ctx, constraints)
+		if err == nil {
+			result = append(result, pools[i])
+			continue
+		}
+		if _, ok := err.(*tsuruErrors.ValidationError); !ok {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// SetPoolLabels adds the labels to the pool, replacing existing values, and
+// removes the ones in unset.
+func SetPoolLabels(ctx context.Context, name string, labels map[string]string, unset []string) error {
+	p, err := GetPoolByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	newLabels := map[string]string{}
+	for k, v := range p.Labels {
+		newLabels[k] = v
+	}
+	for k, v := range labels {
+		newLabels[k] = v
+	}
+	for _, k := range unset {
+		delete(newLabels, k)
+	}
+	err = validateLabels(newLabels)
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.PoolCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": name}, mongoBSON.M{"$set": mongoBSON.M{"labels": newLabels}})
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) mockClusterLabels(c *check.C, labels map[string]string) func() {
+	oldService := servicemanager.Cluster
+	servicemanager.Cluster = &provTypes.MockClusterService{
+		OnFindByPool: func(prov, pool string) (*provTypes.Cluster, error) {
+			c.Check(prov, check.Equals, "fake")
+			return &provTypes.Cluster{Name: "c1", Labels: labels}, nil
+		},
+	}
+	return func() { servicemanager.Cluster = oldService }
+}
+
+func (s *S) TestSchedulingLabels(c *check.C) {
+	defer s.mockClusterLabels(c, map[string]string{"ssd": "true", "zone": "a"})()
+	p := Pool{Name: "pool1", Provisioner: "fake", Labels: map[string]string{"zone": "b", "pci-dss": "true"}}
+	labels, err := p.SchedulingLabels(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(labels, check.DeepEquals, map[string]string{"ssd": "true", "zone": "b", "pci-dss": "true"})
+}
+
+func (s *S) TestSchedulingLabelsNoCluster(c *check.C) {
+	oldService := servicemanager.Cluster
+	defer func() { servicemanager.Cluster = oldService }()
+	servicemanager.Cluster = &provTypes.MockClusterService{
+		OnFindByPool: func(prov, pool string) (*provTypes.Cluster, error) {
+			return nil, provTypes.ErrNoCluster
+		},
+	}
+	p := Pool{Name: "pool1", Provisioner: "fake", Labels: map[string]string{"ssd": "true"}}
+	labels, err := p.SchedulingLabels(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(labels, check.DeepEquals, map[string]string{"ssd": "true"})
+}
+
+func (s *S) TestValidateSchedulingConstraints(c *check.C) {
+	defer s.mockClusterLabels(c, map[string]string{"ssd": "true"})()
+	p := Pool{Name: "pool1", Provisioner: "fake", Labels: map[string]string{"pci-dss": "false"}}
+	err := p.ValidateSchedulingConstraints(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	err = p.ValidateSchedulingConstraints(context.TODO(), map[string]string{"ssd": "true"})
+	c.Assert(err, check.IsNil)
+	err = p.ValidateSchedulingConstraints(context.TODO(), map[string]string{"ssd": "true", "pci-dss": "true", "gpu": "true"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `pool "pool1" does not satisfy the scheduling constraints: gpu=true, pci-dss=true`)
+}
+
+func (s *S) TestFilterBySchedulingConstraints(c *check.C) {
+	defer s.mockClusterLabels(c, nil)()
+	pools := []Pool{
+		{Name: "pool1", Provisioner: "fake"},
+		{Name: "pool2", Provisioner: "fake", Labels: map[string]string{"ssd": "true"}},
+	}
+	result, err := FilterBySchedulingConstraints(context.TODO(), pools, map[string]string{"ssd": "true"})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, []Pool{pools[1]})
+}
+
+func (s *S) TestSetPoolLabels(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1", Labels: map[string]string{"ssd": "true", "zone": "a"}})
+	c.Assert(err, check.IsNil)
+	err = SetPoolLabels(context.TODO(), "pool1", map[string]string{"pci-dss": "true", "zone": "b"}, []string{"ssd"})
+	c.Assert(err, check.IsNil)
+	p, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Labels, check.DeepEquals, map[string]string{"pci-dss": "true", "zone": "b"})
+	err = SetPoolLabels(context.TODO(), "pool1", map[string]string{"rollback-require-reason": "maybe"}, nil)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = SetPoolLabels(context.TODO(), "unknown", map[string]string{"ssd": "true"}, nil)
+	c.Assert(err, check.Equals, ErrPoolNotFound)
+}
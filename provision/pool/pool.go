@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -43,7 +45,11 @@ var (
 )
 
 const (
-	affinityKey = "affinity"
+	affinityKey              = "affinity"
+	rollbackRequireReasonKey = "rollback-require-reason"
+	securityContextKey       = "security-context"
+	shellAuditKey            = "shell-audit"
+	spreadKey                = "spread"
 )
 
 type Pool struct {
@@ -91,6 +97,56 @@ func (p *Pool) GetAffinity() (*apiv1.Affinity, error) {
 	return nil, nil
 }
 
+// GetSpread returns how units of apps in the pool are distributed across
+// zones or nodes, as set by the spread label, in JSON.
+func (p *Pool) GetSpread() (*appTypes.Spread, error) {
+	spreadStr, ok := p.Labels[spreadKey]
+	if !ok {
+		return nil, nil
+	}
+	return parseSpread(spreadStr)
+}
+
+func parseSpread(value string) (*appTypes.Spread, error) {
+	var spread appTypes.Spread
+	if err := json.Unmarshal([]byte(value), &spread); err != nil {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s label: %v", spreadKey, err)}
+	}
+	return &spread, nil
+}
+
+// GetSecurityContext returns the hardening applied to the units of apps in
+// the pool, as set by the security-context label, in JSON.
+func (p *Pool) GetSecurityContext() (*appTypes.SecurityContext, error) {
+	securityContextStr, ok := p.Labels[securityContextKey]
+	if !ok {
+		return nil, nil
+	}
+	return parseSecurityContext(securityContextStr)
+}
+
+func parseSecurityContext(value string) (*appTypes.SecurityContext, error) {
+	var securityContext appTypes.SecurityContext
+	if err := json.Unmarshal([]byte(value), &securityContext); err != nil {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s label: %v", securityContextKey, err)}
+	}
+	return &securityContext, nil
+}
+
+// RollbackRequiresReason reports whether rollbacks of apps in the pool must
+// state a reason, as set by the rollback-require-reason label.
+func (p *Pool) RollbackRequiresReason() bool {
+	required, _ := strconv.ParseBool(p.Labels[rollbackRequireReasonKey])
+	return required
+}
+
+// ShellAuditEnabled reports whether the input and output of shell sessions in
+// units of apps in the pool are recorded, as set by the shell-audit label.
+func (p *Pool) ShellAuditEnabled() bool {
+	enabled, _ := strconv.ParseBool(p.Labels[shellAuditKey])
+	return enabled
+}
+
 func (p *Pool) GetProvisioner() (provision.Provisioner, error) {
 	if p.Provisioner != "" {
 		return provision.Get(p.Provisioner)
@@ -132,6 +188,63 @@ func (p *Pool) GetCertIssuers(ctx context.Context) (*PoolConstraint, error) {
 	return certIssuerConstraint, nil
 }
 
+// ValidateProcessScheduling checks whether the keys of the node selector and
+// tolerations of the process are allowed by the constraints of the pool. Keys
+// are allowed when the pool has no constraint for them.
+func (p *Pool) ValidateProcessScheduling(ctx context.Context, process appTypes.Process) error {
+	constraints, err := getConstraintsForPool(ctx, p.Name, ConstraintTypeNodeSelector, ConstraintTypeToleration)
+	if err != nil {
+		return err
+	}
+	if c, ok := constraints[ConstraintTypeNodeSelector]; ok {
+		for key := range process.NodeSelector {
+			if !c.check(key) {
+				msg := fmt.Sprintf("node selector key %q of process %q is not allowed in pool %q", key, process.Name, p.Name)
+				return &tsuruErrors.ValidationError{Message: msg}
+			}
+		}
+	}
+	if c, ok := constraints[ConstraintTypeToleration]; ok {
+		for _, t := range process.Tolerations {
+			if !c.check(t.Key) {
+				msg := fmt.Sprintf("toleration key %q of process %q is not allowed in pool %q", t.Key, process.Name, p.Name)
+				return &tsuruErrors.ValidationError{Message: msg}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateMetadata checks whether the names of the annotations and labels in
+// the metadata are allowed by the constraints of the pool, process is empty
+// for the metadata of the app itself. Names are allowed when the pool has no
+// constraint for them.
+func (p *Pool) ValidateMetadata(ctx context.Context, metadata appTypes.Metadata, process string) error {
+	constraints, err := getConstraintsForPool(ctx, p.Name, ConstraintTypeAnnotation, ConstraintTypeLabel)
+	if err != nil {
+		return err
+	}
+	suffix := fmt.Sprintf("is not allowed in pool %q", p.Name)
+	if process != "" {
+		suffix = fmt.Sprintf("of process %q %s", process, suffix)
+	}
+	if c, ok := constraints[ConstraintTypeAnnotation]; ok {
+		for _, item := range metadata.Annotations {
+			if !item.Delete && !c.check(item.Name) {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("annotation %q %s", item.Name, suffix)}
+			}
+		}
+	}
+	if c, ok := constraints[ConstraintTypeLabel]; ok {
+		for _, item := range metadata.Labels {
+			if !item.Delete && !c.check(item.Name) {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("label %q %s", item.Name, suffix)}
+			}
+		}
+	}
+	return nil
+}
+
 func (p *Pool) GetVolumePlans(ctx context.Context) ([]string, error) {
 	allowedValues, err := p.allowedValues(ctx)
 	if err != nil {
@@ -156,7 +269,36 @@ func (p *Pool) GetPlans(ctx context.Context) ([]string, error) {
 	return nil, ErrPoolHasNoPlan
 }
 
+// GetDefaultPlanForTeam returns the default plan of the team when it's
+// allowed in the pool, falling back to the pool default plan.
+func (p *Pool) GetDefaultPlanForTeam(ctx context.Context, team string) (*appTypes.Plan, error) {
+	if team == "" {
+		return p.GetDefaultPlan(ctx)
+	}
+	plan, err := servicemanager.Plan.DefaultPlanFor(ctx, appTypes.PlanDefault{Team: team})
+	if err != nil && err != appTypes.ErrPlanDefaultNotFound {
+		return nil, err
+	}
+	if plan != nil {
+		allowed, err := p.allowedValues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(allowed[ConstraintTypePlan], plan.Name) {
+			return plan, nil
+		}
+	}
+	return p.GetDefaultPlan(ctx)
+}
+
 func (p *Pool) GetDefaultPlan(ctx context.Context) (*appTypes.Plan, error) {
+	plan, err := servicemanager.Plan.DefaultPlanFor(ctx, appTypes.PlanDefault{Pool: p.Name})
+	if err == nil {
+		return plan, nil
+	}
+	if err != appTypes.ErrPlanDefaultNotFound {
+		return nil, err
+	}
 	constraints, err := getConstraintsForPool(ctx, p.Name, ConstraintTypePlan)
 	if err != nil {
 		return nil, err
@@ -185,7 +327,7 @@ func (p *Pool) GetDefaultPlan(ctx context.Context) (*appTypes.Plan, error) {
 		}
 		return defaultPlan, nil
 	}
-	plan, err := servicemanager.Plan.FindByName(ctx, constraint.Values[0])
+	plan, err = servicemanager.Plan.FindByName(ctx, constraint.Values[0])
 	if err != nil {
 		return defaultPlan, nil
 	}
@@ -383,6 +525,33 @@ func validateLabels(labels map[string]string) error {
 			return err
 		}
 	}
+	if spreadStr, ok := labels[spreadKey]; ok {
+		spread, err := parseSpread(spreadStr)
+		if err != nil {
+			return err
+		}
+		if err = spread.Validate(); err != nil {
+			return err
+		}
+	}
+	if securityContextStr, ok := labels[securityContextKey]; ok {
+		securityContext, err := parseSecurityContext(securityContextStr)
+		if err != nil {
+			return err
+		}
+		if err = securityContext.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, key := range []string{rollbackRequireReasonKey, shellAuditKey} {
+		value, ok := labels[key]
+		if !ok {
+			continue
+		}
+		if _, err := strconv.ParseBool(value); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s label %q, must be a boolean", key, value)}
+		}
+	}
 
 	return nil
 }
@@ -726,6 +726,66 @@ func (s *S) TestGetPlans(c *check.C) {
 	c.Assert(plans, check.DeepEquals, []string{"plan1", "plan2"})
 }
 
+func (s *S) TestGetDefaultPlanFromPoolDefault(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	s.mockPlanService.OnDefaultPlanFor = func(d appTypes.PlanDefault) (*appTypes.Plan, error) {
+		if d.Pool == "pool1" {
+			return &s.plans[1], nil
+		}
+		return nil, appTypes.ErrPlanDefaultNotFound
+	}
+	pool, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	plan, err := pool.GetDefaultPlan(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Name, check.Equals, "plan2")
+}
+
+func (s *S) TestGetDefaultPlanForTeam(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	s.mockPlanService.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &s.plans[0], nil
+	}
+	s.mockPlanService.OnDefaultPlanFor = func(d appTypes.PlanDefault) (*appTypes.Plan, error) {
+		if d.Team == "ateam" {
+			return &s.plans[1], nil
+		}
+		return nil, appTypes.ErrPlanDefaultNotFound
+	}
+	pool, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	plan, err := pool.GetDefaultPlanForTeam(context.TODO(), "ateam")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Name, check.Equals, "plan2")
+	plan, err = pool.GetDefaultPlanForTeam(context.TODO(), "test")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Name, check.Equals, "plan1")
+}
+
+func (s *S) TestGetDefaultPlanForTeamNotAllowedInPool(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypePlan, Values: []string{"plan2"}, Blacklist: true})
+	c.Assert(err, check.IsNil)
+	s.mockPlanService.Plans = s.plans
+	s.mockPlanService.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &s.plans[0], nil
+	}
+	s.mockPlanService.OnDefaultPlanFor = func(d appTypes.PlanDefault) (*appTypes.Plan, error) {
+		if d.Team == "ateam" {
+			return &s.plans[1], nil
+		}
+		return nil, appTypes.ErrPlanDefaultNotFound
+	}
+	pool, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	plan, err := pool.GetDefaultPlanForTeam(context.TODO(), "ateam")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Name, check.Equals, "plan1")
+}
+
 func (s *S) TestGetDefaultRouterFromConstraint(c *check.C) {
 	config.Set("routers:router1:type", "hipache")
 	config.Set("routers:router2:type", "hipache")
@@ -942,3 +1002,120 @@ func (s *S) TestGetAffinity(c *check.C) {
 		t.assertion(t.testName, c, affinity, err)
 	}
 }
+
+func (s *S) TestAddPoolWithInvalidRollbackRequireReasonLabel(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{
+		Name:   "pool1",
+		Labels: map[string]string{rollbackRequireReasonKey: "sure"},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid rollback-require-reason label "sure", must be a boolean`)
+}
+
+func (s *S) TestRollbackRequiresReason(c *check.C) {
+	c.Assert((&Pool{Name: "pool1"}).RollbackRequiresReason(), check.Equals, false)
+	c.Assert((&Pool{Name: "pool1", Labels: map[string]string{"rollback-require-reason": "false"}}).RollbackRequiresReason(), check.Equals, false)
+	c.Assert((&Pool{Name: "pool1", Labels: map[string]string{"rollback-require-reason": "true"}}).RollbackRequiresReason(), check.Equals, true)
+}
+
+func (s *S) TestShellAuditEnabled(c *check.C) {
+	c.Assert((&Pool{Name: "pool1"}).ShellAuditEnabled(), check.Equals, false)
+	c.Assert((&Pool{Name: "pool1", Labels: map[string]string{"shell-audit": "true"}}).ShellAuditEnabled(), check.Equals, true)
+	err := AddPool(context.TODO(), AddPoolOptions{
+		Name:   "pool1",
+		Labels: map[string]string{shellAuditKey: "always"},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid shell-audit label "always", must be a boolean`)
+}
+
+func (s *S) TestGetSpread(c *check.C) {
+	spread, err := (&Pool{Name: "pool1"}).GetSpread()
+	c.Assert(err, check.IsNil)
+	c.Assert(spread, check.IsNil)
+	spread, err = (&Pool{Name: "pool1", Labels: map[string]string{spreadKey: `{"topologySpreadConstraints":[{"topologyKey":"topology.kubernetes.io/zone","required":true}],"antiAffinity":[{"topologyKey":"kubernetes.io/hostname"}]}`}}).GetSpread()
+	c.Assert(err, check.IsNil)
+	c.Assert(spread, check.DeepEquals, &appTypes.Spread{
+		TopologySpreadConstraints: []appTypes.TopologySpreadConstraint{{TopologyKey: "topology.kubernetes.io/zone", Required: true}},
+		AntiAffinity:              []appTypes.AntiAffinityRule{{TopologyKey: "kubernetes.io/hostname"}},
+	})
+}
+
+func (s *S) TestAddPoolWithInvalidSpreadLabel(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{
+		Name:   "pool1",
+		Labels: map[string]string{spreadKey: `{"antiAffinity":[{"topologyKey":"kubernetes.io/hostname","weight":200}]}`},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid weight 200 for topology "kubernetes.io/hostname", must be between 1 and 100`)
+	err = AddPool(context.TODO(), AddPoolOptions{
+		Name:   "pool1",
+		Labels: map[string]string{spreadKey: `zones`},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid spread label: .*`)
+}
+
+func (s *S) TestValidateProcessScheduling(c *check.C) {
+	err := SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeNodeSelector, Values: []string{"kubernetes.io/*"}, Blacklist: true})
+	c.Assert(err, check.IsNil)
+	p := &Pool{Name: "pool1"}
+	process := appTypes.Process{
+		Name:         "worker",
+		NodeSelector: map[string]string{"lifecycle": "spot"},
+		Tolerations:  []appTypes.Toleration{{Key: "dedicated", Value: "db"}},
+	}
+	err = p.ValidateProcessScheduling(context.TODO(), process)
+	c.Assert(err, check.IsNil)
+	process.NodeSelector["kubernetes.io/hostname"] = "node1"
+	err = p.ValidateProcessScheduling(context.TODO(), process)
+	c.Assert(err, check.ErrorMatches, `node selector key "kubernetes.io/hostname" of process "worker" is not allowed in pool "pool1"`)
+	err = (&Pool{Name: "pool2"}).ValidateProcessScheduling(context.TODO(), process)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestValidateMetadata(c *check.C) {
+	err := SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeAnnotation, Values: []string{"sidecar.istio.io/*"}, Blacklist: true})
+	c.Assert(err, check.IsNil)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeLabel, Values: []string{"team", "example.com/*"}})
+	c.Assert(err, check.IsNil)
+	p := &Pool{Name: "pool1"}
+	metadata := appTypes.Metadata{
+		Annotations: []appTypes.MetadataItem{{Name: "example.com/owner", Value: "me"}},
+		Labels:      []appTypes.MetadataItem{{Name: "example.com/tier", Value: "backend"}},
+	}
+	err = p.ValidateMetadata(context.TODO(), metadata, "")
+	c.Assert(err, check.IsNil)
+	metadata.Annotations = append(metadata.Annotations, appTypes.MetadataItem{Name: "sidecar.istio.io/inject", Value: "false"})
+	err = p.ValidateMetadata(context.TODO(), metadata, "")
+	c.Assert(err, check.ErrorMatches, `annotation "sidecar.istio.io/inject" is not allowed in pool "pool1"`)
+	metadata.Annotations = metadata.Annotations[:1]
+	metadata.Labels = append(metadata.Labels, appTypes.MetadataItem{Name: "tier", Value: "backend"})
+	err = p.ValidateMetadata(context.TODO(), metadata, "worker")
+	c.Assert(err, check.ErrorMatches, `label "tier" of process "worker" is not allowed in pool "pool1"`)
+	err = (&Pool{Name: "pool2"}).ValidateMetadata(context.TODO(), metadata, "worker")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestGetSecurityContext(c *check.C) {
+	securityContext, err := (&Pool{Name: "pool1"}).GetSecurityContext()
+	c.Assert(err, check.IsNil)
+	c.Assert(securityContext, check.IsNil)
+	securityContext, err = (&Pool{Name: "pool1", Labels: map[string]string{securityContextKey: `{"runAsNonRoot":true,"seccompProfile":"RuntimeDefault","fsGroup":1000}`}}).GetSecurityContext()
+	c.Assert(err, check.IsNil)
+	runAsNonRoot, fsGroup := true, int64(1000)
+	c.Assert(securityContext, check.DeepEquals, &appTypes.SecurityContext{
+		RunAsNonRoot:   &runAsNonRoot,
+		SeccompProfile: "RuntimeDefault",
+		FSGroup:        &fsGroup,
+	})
+}
+
+func (s *S) TestAddPoolWithInvalidSecurityContextLabel(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{
+		Name:   "pool1",
+		Labels: map[string]string{securityContextKey: `{"seccompProfile":"Strict"}`},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid seccompProfile "Strict", must be RuntimeDefault, Unconfined or Localhost/<profile>`)
+	err = AddPool(context.TODO(), AddPoolOptions{
+		Name:   "pool1",
+		Labels: map[string]string{securityContextKey: `nonroot`},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid security-context label: .*`)
+}
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/event"
@@ -39,6 +40,7 @@ var (
 	ErrNodeNotFound = errors.New("node not found")
 
 	ErrLogsUnavailable = errors.New("logs from provisioner are unavailable")
+	ErrNoPendingDeploy = errors.New("there is no blue-green deploy pending confirmation for the app")
 	DefaultProvisioner = defaultKubernetesProvisioner
 )
 
@@ -79,14 +81,44 @@ type RunArgs struct {
 	Debug    bool
 }
 
+// Percentages of the progress reported in deploy events. Provisioners report
+// the rollout of units between DeployProgressImageReady and
+// DeployProgressUnitsReady.
+const (
+	DeployProgressBuildStarted  = 5
+	DeployProgressImageReady    = 40
+	DeployProgressUnitsReady    = 90
+	DeployProgressRoutesSwapped = 95
+	DeployProgressDone          = 100
+)
+
 type DeployArgs struct {
 	App              *appTypes.App
 	Version          appTypes.AppVersion
 	Event            *event.Event
 	PreserveVersions bool
 	OverrideVersions bool
+	// Annotations are added to the resources created by the deploy,
+	// describing why it happened.
+	Annotations map[string]string
+	// PlanOverride changes the resources of the plan only for the units of
+	// the version being deployed.
+	PlanOverride *appTypes.PlanOverride
+	// Strategy is how the units of the new version replace the current ones,
+	// DeployStrategyRolling when empty.
+	Strategy string
 }
 
+const (
+	// DeployStrategyRolling gradually replaces the units of the current
+	// version with units of the new one.
+	DeployStrategyRolling = "rolling"
+	// DeployStrategyBlueGreen provisions the new version alongside the
+	// current one, without receiving traffic, until the deploy is confirmed
+	// or aborted.
+	DeployStrategyBlueGreen = "blue-green"
+)
+
 // BuilderDeploy is a provisioner that allows deploy builded image.
 type BuilderDeploy interface {
 	Deploy(context.Context, DeployArgs) (string, error)
@@ -97,6 +129,15 @@ type VersionsProvisioner interface {
 	DeployedVersions(context.Context, *appTypes.App) ([]int, error)
 }
 
+// BlueGreenProvisioner is a provisioner able to deploy apps with the
+// DeployStrategyBlueGreen strategy. ConfirmDeploy switches the traffic to
+// the pending version, removing the previous ones, while AbortDeploy removes
+// the pending version. Both return the number of the pending version.
+type BlueGreenProvisioner interface {
+	ConfirmDeploy(context.Context, *appTypes.App, io.Writer) (int, error)
+	AbortDeploy(context.Context, *appTypes.App, io.Writer) (int, error)
+}
+
 // Provisioner is the basic interface of this package.
 //
 // Any tsuru provisioner must implement this interface in order to provision
@@ -154,7 +195,7 @@ type JobProvisioner interface {
 	EnsureJob(context.Context, *jobTypes.Job) error
 
 	DestroyJob(context.Context, *jobTypes.Job) error
-	TriggerCron(ctx context.Context, name, pool string) error
+	TriggerCron(ctx context.Context, job *jobTypes.Job) error
 	KillJobUnit(ctx context.Context, job *jobTypes.Job, unitName string, force bool) error
 }
 
@@ -175,6 +216,48 @@ type ExecutableProvisioner interface {
 	ExecuteCommand(ctx context.Context, opts ExecOptions) error
 }
 
+// PortForwardOptions describes a connection tunneled to a port of an app
+// unit. Data read from Conn is sent to the port and data received from it is
+// written back to Conn.
+type PortForwardOptions struct {
+	App  *appTypes.App
+	Unit string
+	Port int
+	Conn io.ReadWriter
+}
+
+// PortForwardProvisioner is a provisioner able to tunnel connections to ports
+// of app units that aren't otherwise exposed, like debug ports.
+type PortForwardProvisioner interface {
+	PortForward(ctx context.Context, opts PortForwardOptions) error
+}
+
+// CapacitySimulationOptions describes units of the given plan to be
+// scheduled in the nodes of a pool.
+type CapacitySimulationOptions struct {
+	Pool  string
+	Plan  appTypes.Plan
+	Units int
+}
+
+// TaskOptions describes a one-off command run in a managed unit created with
+// the image, envs and volumes of the app. CPUMilli and Memory override the
+// limits of the app plan when set.
+type TaskOptions struct {
+	App      *appTypes.App
+	Cmds     []string
+	Output   io.Writer
+	Timeout  time.Duration
+	CPUMilli int
+	Memory   int64
+}
+
+// TaskProvisioner is a provisioner that runs one-off tasks, like database
+// migrations, outside of the units of the app.
+type TaskProvisioner interface {
+	RunTask(ctx context.Context, opts TaskOptions) error
+}
+
 // LogsProvisioner is a provisioner that is self responsible for storage logs.
 type LogsProvisioner interface {
 	ListLogs(ctx context.Context, obj *logTypes.LogabbleObject, args appTypes.ListLogArgs) ([]appTypes.Applog, error)
@@ -199,6 +282,12 @@ type InterAppProvisioner interface {
 	InternalAddresses(ctx context.Context, a *appTypes.App) ([]appTypes.AppInternalAddress, error)
 }
 
+// WorkloadIdentityProvisioner is a provisioner able to check tokens issued to
+// the units of an app, allowing them to be exchanged for tsuru identity tokens.
+type WorkloadIdentityProvisioner interface {
+	ValidateWorkloadToken(ctx context.Context, a *appTypes.App, token string) error
+}
+
 // MessageProvisioner is a provisioner that provides a welcome message for
 // logging.
 type MessageProvisioner interface {
@@ -222,6 +311,99 @@ type KillUnitProvisioner interface {
 	KillUnit(ctx context.Context, app *appTypes.App, unit string, force bool) error
 }
 
+type RebalanceUnitsOptions struct {
+	// DryRun only reports the units that would be evicted.
+	DryRun bool
+	// MaxEvictions limits the number of units evicted in a single run, zero
+	// means no limit.
+	MaxEvictions int
+	Writer       io.Writer
+}
+
+// UnitEviction describes a unit evicted, or that would be evicted in a dry
+// run, to even out the units of an app process.
+type UnitEviction struct {
+	App     string `json:"app"`
+	Unit    string `json:"unit"`
+	Process string `json:"process"`
+	Node    string `json:"node"`
+	Zone    string `json:"zone,omitempty"`
+	Reason  string `json:"reason"`
+	Error   string `json:"error,omitempty"`
+}
+
+// UnitRebalanceProvisioner is a provisioner able to spread the units of an
+// app evenly across nodes and zones, evicting units from overloaded ones.
+// Evictions must respect disruption budgets.
+type UnitRebalanceProvisioner interface {
+	RebalanceUnits(ctx context.Context, app *appTypes.App, opts RebalanceUnitsOptions) ([]UnitEviction, error)
+}
+
+const (
+	DriftKindDeployment = "deployment"
+	DriftKindScale      = "scale"
+	DriftKindService    = "service"
+	DriftKindVolume     = "volume"
+	DriftKindRouter     = "router"
+)
+
+// Drift describes a difference between the state of an app stored in tsuru
+// and the objects running in the provisioner or router.
+type Drift struct {
+	App      string `json:"app"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Process  string `json:"process,omitempty"`
+	Message  string `json:"message"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DriftProvisioner is a provisioner able to compare the desired state of an
+// app with the objects it manages and to bring those objects back in line.
+type DriftProvisioner interface {
+	Drift(ctx context.Context, app *appTypes.App) ([]Drift, error)
+	RepairDrift(ctx context.Context, app *appTypes.App, w io.Writer) ([]Drift, error)
+}
+
+// OrphanResource is an object labeled as managed by tsuru whose owner, an app,
+// app version or volume, no longer exists.
+type OrphanResource struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+	Removed   bool   `json:"removed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// JanitorProvisioner is a provisioner able to find the objects leaked by
+// failed removals of apps, versions and volumes and to delete them.
+type JanitorProvisioner interface {
+	OrphanResources(ctx context.Context) ([]OrphanResource, error)
+	RemoveOrphanResources(ctx context.Context) ([]OrphanResource, error)
+}
+
+// RenderedManifest is the object applied by the provisioner for a process of
+// an app version, along with a summary of the fields most likely to change
+// the behavior of a rollout. Values of environment variables are redacted
+// from Manifest, changes to them are reflected in EnvHash.
+type RenderedManifest struct {
+	Process   string `json:"process"`
+	Image     string `json:"image"`
+	EnvHash   string `json:"envHash"`
+	Resources string `json:"resources"`
+	Probes    string `json:"probes"`
+	Manifest  string `json:"manifest"`
+}
+
+// ManifestsProvisioner is a provisioner able to return the manifests applied
+// for the processes of an app version.
+type ManifestsProvisioner interface {
+	RenderedManifests(ctx context.Context, app *appTypes.App, version appTypes.AppVersion) ([]RenderedManifest, error)
+}
+
 // HCProvisioner is a provisioner that may handle loadbalancing healthchecks.
 type HCProvisioner interface {
 	// HandlesHC returns true if the provisioner will handle healthchecking
@@ -289,6 +471,12 @@ func CPUValueOfAutoScaleSpec(s *provTypes.AutoScaleSpec, a *appTypes.App) (int,
 	return cpu, nil
 }
 
+// MetricsScrapeProvisioner is a provisioner able to configure the scraping of
+// the metrics endpoint declared by apps in tsuru.yaml.
+type MetricsScrapeProvisioner interface {
+	MetricsScrape(ctx context.Context, a *appTypes.App) (*provTypes.MetricsScrape, error)
+}
+
 type AutoScaleProvisioner interface {
 	GetAutoScale(ctx context.Context, a *appTypes.App) ([]provTypes.AutoScaleSpec, error)
 	GetVerticalAutoScaleRecommendations(ctx context.Context, a *appTypes.App) ([]provTypes.RecommendedResources, error)
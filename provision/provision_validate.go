@@ -6,12 +6,16 @@ package provision
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	"github.com/tsuru/tsuru/validation"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func ValidateAutoScaleSpec(spec *provTypes.AutoScaleSpec, quotaLimit int, a *appTypes.App) error {
@@ -24,8 +28,11 @@ func ValidateAutoScaleSpec(spec *provTypes.AutoScaleSpec, quotaLimit int, a *app
 	if quotaLimit > 0 && spec.MaxUnits > uint(quotaLimit) {
 		return errors.New("maximum units cannot be greater than quota limit")
 	}
-	if spec.AverageCPU == "" && len(spec.Schedules) == 0 && len(spec.Prometheus) == 0 {
-		return errors.New("you have to configure at least one trigger between cpu, schedule and prometheus")
+	if spec.AverageCPU == "" && !spec.UsesScalers() && len(spec.Metrics) == 0 {
+		return errors.New("you have to configure at least one trigger between cpu, schedule, prometheus, kafka, sqs and metrics")
+	}
+	if len(spec.Metrics) > 0 && spec.UsesScalers() {
+		return errors.New("metrics cannot be combined with schedule, prometheus, kafka and sqs triggers")
 	}
 	if spec.AverageCPU != "" {
 		_, err := CPUValueOfAutoScaleSpec(spec, a)
@@ -34,7 +41,7 @@ func ValidateAutoScaleSpec(spec *provTypes.AutoScaleSpec, quotaLimit int, a *app
 		}
 	}
 
-	err := ValidateAutoScaleSchedule(spec.Schedules)
+	err := ValidateAutoScaleSchedule(spec.Schedules, spec.MaxUnits)
 	if err != nil {
 		return err
 	}
@@ -44,6 +51,21 @@ func ValidateAutoScaleSpec(spec *provTypes.AutoScaleSpec, quotaLimit int, a *app
 		return err
 	}
 
+	err = ValidateAutoScaleKafka(spec.Kafka)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateAutoScaleSQS(spec.SQS)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateAutoScaleMetrics(spec.Metrics)
+	if err != nil {
+		return err
+	}
+
 	err = ValidateAutoScaleDownSpec(spec)
 	if err != nil {
 		return err
@@ -52,12 +74,26 @@ func ValidateAutoScaleSpec(spec *provTypes.AutoScaleSpec, quotaLimit int, a *app
 	return nil
 }
 
-func ValidateAutoScaleSchedule(schedules []provTypes.AutoScaleSchedule) error {
+func ValidateAutoScaleSchedule(schedules []provTypes.AutoScaleSchedule, maxUnits uint) error {
 	for _, schedule := range schedules {
 		if schedule.Name != "" && !validation.ValidateName(schedule.Name) {
 			return fmt.Errorf("\"%s\" is an invalid name, it must contain only lower case letters, numbers or dashes and starts with a letter", schedule.Name)
 		}
 
+		if schedule.MinReplicas < 0 {
+			return fmt.Errorf("minimum units of schedule %q cannot be negative", schedule.Name)
+		}
+
+		if uint(schedule.MinReplicas) > maxUnits {
+			return fmt.Errorf("minimum units of schedule %q cannot be greater than maximum units", schedule.Name)
+		}
+
+		if schedule.Timezone != "" {
+			if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+				return fmt.Errorf("invalid timezone %q for schedule %q", schedule.Timezone, schedule.Name)
+			}
+		}
+
 		_, err := cron.ParseStandard(schedule.Start)
 		if err != nil {
 			return fmt.Errorf("invalid start time for schedule %q: %v", schedule.Name, err)
@@ -88,6 +124,77 @@ func ValidateAutoScalePrometheus(prometheus []provTypes.AutoScalePrometheus) err
 	return nil
 }
 
+func ValidateAutoScaleKafka(kafka []provTypes.AutoScaleKafka) error {
+	for _, k := range kafka {
+		if !validation.ValidateName(k.Name) {
+			return fmt.Errorf("\"%s\" is an invalid name, it must contain only lower case letters, numbers or dashes and starts with a letter", k.Name)
+		}
+		if k.BootstrapServers == "" {
+			return fmt.Errorf("kafka bootstrapServers of name %q is required", k.Name)
+		}
+		if k.ConsumerGroup == "" {
+			return fmt.Errorf("kafka consumerGroup of name %q is required", k.Name)
+		}
+		if k.LagThreshold <= 0 {
+			return fmt.Errorf("kafka lagThreshold of name %q must be greater than 0", k.Name)
+		}
+		if k.ActivationLagThreshold < 0 {
+			return fmt.Errorf("kafka activationLagThreshold of name %q cannot be negative", k.Name)
+		}
+	}
+	return nil
+}
+
+func ValidateAutoScaleSQS(sqs []provTypes.AutoScaleSQS) error {
+	for _, q := range sqs {
+		if !validation.ValidateName(q.Name) {
+			return fmt.Errorf("\"%s\" is an invalid name, it must contain only lower case letters, numbers or dashes and starts with a letter", q.Name)
+		}
+		queueURL, err := url.Parse(q.QueueURL)
+		if err != nil || queueURL.Scheme != "https" || queueURL.Host == "" {
+			return fmt.Errorf("sqs queueURL of name %q must be a https URL", q.Name)
+		}
+		if q.Region == "" {
+			return fmt.Errorf("sqs region of name %q is required", q.Name)
+		}
+		if q.QueueLength <= 0 {
+			return fmt.Errorf("sqs queueLength of name %q must be greater than 0", q.Name)
+		}
+		if q.ActivationQueueLength < 0 {
+			return fmt.Errorf("sqs activationQueueLength of name %q cannot be negative", q.Name)
+		}
+	}
+	return nil
+}
+
+func ValidateAutoScaleMetrics(metrics []provTypes.AutoScaleMetric) error {
+	names := map[string]struct{}{}
+	for _, metric := range metrics {
+		if metric.Name == "" {
+			return errors.New("metric name is required")
+		}
+		if metric.Type != provTypes.AutoScaleMetricPods && metric.Type != provTypes.AutoScaleMetricExternal {
+			return fmt.Errorf("invalid type %q for metric %q, it must be %q or %q", metric.Type, metric.Name, provTypes.AutoScaleMetricPods, provTypes.AutoScaleMetricExternal)
+		}
+		key := metric.Type + "/" + metric.Name + "/" + metric.Selector
+		if _, ok := names[key]; ok {
+			return fmt.Errorf("metric %q is declared more than once", metric.Name)
+		}
+		names[key] = struct{}{}
+		target, err := resource.ParseQuantity(metric.Target)
+		if err != nil {
+			return fmt.Errorf("invalid target %q for metric %q", metric.Target, metric.Name)
+		}
+		if target.Sign() <= 0 {
+			return fmt.Errorf("target of metric %q must be greater than 0", metric.Name)
+		}
+		if _, err = labels.Parse(metric.Selector); err != nil {
+			return fmt.Errorf("invalid selector for metric %q: %v", metric.Name, err)
+		}
+	}
+	return nil
+}
+
 func ValidateAutoScaleDownSpec(autoScaleSpec *provTypes.AutoScaleSpec) error {
 	if autoScaleSpec == nil {
 		return nil
@@ -56,7 +56,7 @@ func (ProvisionSuite) TestValidateWhenErrors(c *check.C) {
 				MinUnits: 1,
 				MaxUnits: 2,
 			},
-			"you have to configure at least one trigger between cpu, schedule and prometheus",
+			"you have to configure at least one trigger between cpu, schedule, prometheus, kafka, sqs and metrics",
 		},
 		{
 			provTypes.AutoScaleSpec{
@@ -74,6 +74,42 @@ func (ProvisionSuite) TestValidateWhenErrors(c *check.C) {
 			},
 			"autoscale cpu value cannot be less than 20%",
 		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 2,
+				Metrics:  []provTypes.AutoScaleMetric{{Name: "rps", Type: "object", Target: "10"}},
+			},
+			`invalid type "object" for metric "rps", it must be "pods" or "external"`,
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 2,
+				Metrics:  []provTypes.AutoScaleMetric{{Name: "rps", Type: "pods", Target: "0"}},
+			},
+			`target of metric "rps" must be greater than 0`,
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 2,
+				Metrics:  []provTypes.AutoScaleMetric{{Name: "rps", Type: "pods", Target: "many"}},
+			},
+			`invalid target "many" for metric "rps"`,
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 2,
+				Metrics:  []provTypes.AutoScaleMetric{{Name: "rps", Type: "pods", Target: "10"}},
+				Schedules: []provTypes.AutoScaleSchedule{{
+					Start: "5 * * * *",
+					End:   "10 * * * *",
+				}},
+			},
+			"metrics cannot be combined with schedule, prometheus, kafka and sqs triggers",
+		},
 		{
 			provTypes.AutoScaleSpec{
 				MinUnits: 1,
@@ -206,6 +242,82 @@ func (ProvisionSuite) TestValidateWhenErrors(c *check.C) {
 			},
 			"invalid end time for schedule \"valid-name\": end of range (24) above maximum (23): 24",
 		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 10,
+				Kafka: []provTypes.AutoScaleKafka{{
+					Name:             "orders-lag",
+					BootstrapServers: "kafka:9092",
+					LagThreshold:     10,
+				}},
+			},
+			"kafka consumerGroup of name \"orders-lag\" is required",
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 10,
+				Kafka: []provTypes.AutoScaleKafka{{
+					Name:             "orders-lag",
+					BootstrapServers: "kafka:9092",
+					ConsumerGroup:    "orders",
+				}},
+			},
+			"kafka lagThreshold of name \"orders-lag\" must be greater than 0",
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 10,
+				SQS: []provTypes.AutoScaleSQS{{
+					Name:        "orders-queue",
+					QueueURL:    "sqs.us-east-1.amazonaws.com/123456789012/orders",
+					QueueLength: 5,
+					Region:      "us-east-1",
+				}},
+			},
+			"sqs queueURL of name \"orders-queue\" must be a https URL",
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 10,
+				SQS: []provTypes.AutoScaleSQS{{
+					Name:        "orders-queue",
+					QueueURL:    "https://sqs.us-east-1.amazonaws.com/123456789012/orders",
+					QueueLength: 5,
+				}},
+			},
+			"sqs region of name \"orders-queue\" is required",
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 2,
+				MaxUnits: 5,
+				Schedules: []provTypes.AutoScaleSchedule{{
+					Name:        "business-hours",
+					MinReplicas: 10,
+					Start:       "0 8 * * 1-5",
+					End:         "0 20 * * 1-5",
+				}},
+			},
+			"minimum units of schedule \"business-hours\" cannot be greater than maximum units",
+		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 2,
+				MaxUnits: 10,
+				Schedules: []provTypes.AutoScaleSchedule{{
+					Name:        "business-hours",
+					MinReplicas: 10,
+					Start:       "0 8 * * 1-5",
+					End:         "0 20 * * 1-5",
+					Timezone:    "America/Nowhere",
+				}},
+			},
+			"invalid timezone \"America/Nowhere\" for schedule \"business-hours\"",
+		},
 	}
 
 	for _, test := range tests {
@@ -313,6 +425,19 @@ func (ProvisionSuite) TestValidateWhenNoErrors(c *check.C) {
 				}},
 			},
 		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits: 2,
+				MaxUnits: 10,
+				Schedules: []provTypes.AutoScaleSchedule{{
+					Name:        "business-hours",
+					MinReplicas: 10,
+					Start:       "0 8 * * 1-5",
+					End:         "0 20 * * 1-5",
+					Timezone:    "America/Sao_Paulo",
+				}},
+			},
+		},
 		{
 			provTypes.AutoScaleSpec{
 				MinUnits: 1,
@@ -334,6 +459,17 @@ func (ProvisionSuite) TestValidateWhenNoErrors(c *check.C) {
 				}},
 			},
 		},
+		{
+			provTypes.AutoScaleSpec{
+				MinUnits:   1,
+				MaxUnits:   10,
+				AverageCPU: "40",
+				Metrics: []provTypes.AutoScaleMetric{
+					{Name: "http_requests_per_second", Type: "pods", Target: "100"},
+					{Name: "queue_messages_ready", Type: "external", Target: "30", Selector: "queue=orders"},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -6,9 +6,13 @@ package provisiontest
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -33,15 +37,22 @@ var (
 	errNotProvisioned         = &provision.Error{Reason: "App is not provisioned."}
 	uniqueIpCounter     int32 = 0
 
-	_ provision.Provisioner           = &FakeProvisioner{}
-	_ provision.InterAppProvisioner   = &FakeProvisioner{}
-	_ provision.UpdatableProvisioner  = &FakeProvisioner{}
-	_ provision.Provisioner           = &FakeProvisioner{}
-	_ provision.LogsProvisioner       = &FakeProvisioner{}
-	_ provision.MetricsProvisioner    = &FakeProvisioner{}
-	_ provision.VolumeProvisioner     = &FakeProvisioner{}
-	_ provision.AppFilterProvisioner  = &FakeProvisioner{}
-	_ provision.ExecutableProvisioner = &FakeProvisioner{}
+	_ provision.Provisioner                 = &FakeProvisioner{}
+	_ provision.InterAppProvisioner         = &FakeProvisioner{}
+	_ provision.UpdatableProvisioner        = &FakeProvisioner{}
+	_ provision.Provisioner                 = &FakeProvisioner{}
+	_ provision.LogsProvisioner             = &FakeProvisioner{}
+	_ provision.MetricsProvisioner          = &FakeProvisioner{}
+	_ provision.VolumeProvisioner           = &FakeProvisioner{}
+	_ provision.AppFilterProvisioner        = &FakeProvisioner{}
+	_ provision.ExecutableProvisioner       = &FakeProvisioner{}
+	_ provision.WorkloadIdentityProvisioner = &FakeProvisioner{}
+	_ provision.UnitRebalanceProvisioner    = &FakeProvisioner{}
+	_ provision.DriftProvisioner            = &FakeProvisioner{}
+	_ provision.JanitorProvisioner          = &FakeProvisioner{}
+	_ provision.ManifestsProvisioner        = &FakeProvisioner{}
+	_ provision.TaskProvisioner             = &FakeProvisioner{}
+	_ provision.BlueGreenProvisioner        = &FakeProvisioner{}
 )
 
 func init() {
@@ -110,15 +121,18 @@ type failure struct {
 
 // Fake implementation for provision.Provisioner.
 type FakeProvisioner struct {
-	Name        string
-	LogsEnabled bool
-	outputs     chan []byte
-	failures    chan failure
-	apps        map[string]provisionedApp
-	jobs        map[string]*provisionedJob
-	mut         sync.RWMutex
-	execs       map[string][]provision.ExecOptions
-	execsMut    sync.Mutex
+	Name         string
+	LogsEnabled  bool
+	outputs      chan []byte
+	failures     chan failure
+	apps         map[string]provisionedApp
+	jobs         map[string]*provisionedJob
+	orphans      []provision.OrphanResource
+	mut          sync.RWMutex
+	execs        map[string][]provision.ExecOptions
+	portForwards map[string][]provision.PortForwardOptions
+	execsMut     sync.Mutex
+	nodes        map[string]provTypes.ClusterNode
 }
 
 func NewFakeProvisioner() *FakeProvisioner {
@@ -128,6 +142,8 @@ func NewFakeProvisioner() *FakeProvisioner {
 	p.apps = make(map[string]provisionedApp)
 	p.jobs = make(map[string]*provisionedJob)
 	p.execs = make(map[string][]provision.ExecOptions)
+	p.portForwards = make(map[string][]provision.PortForwardOptions)
+	p.nodes = make(map[string]provTypes.ClusterNode)
 	return &p
 }
 
@@ -184,6 +200,13 @@ func (p *FakeProvisioner) Execs(unit string) []provision.ExecOptions {
 	return p.execs[unit]
 }
 
+// PortForwards returns the port forward calls to the unit.
+func (p *FakeProvisioner) PortForwards(unit string) []provision.PortForwardOptions {
+	p.execsMut.Lock()
+	defer p.execsMut.Unlock()
+	return p.portForwards[unit]
+}
+
 // AllExecs return all exec calls to all units.
 func (p *FakeProvisioner) AllExecs() map[string][]provision.ExecOptions {
 	p.execsMut.Lock()
@@ -272,10 +295,13 @@ func (p *FakeProvisioner) Reset() {
 
 	p.mut.Lock()
 	p.jobs = make(map[string]*provisionedJob)
+	p.orphans = nil
+	p.nodes = make(map[string]provTypes.ClusterNode)
 	p.mut.Unlock()
 
 	p.execsMut.Lock()
 	p.execs = make(map[string][]provision.ExecOptions)
+	p.portForwards = make(map[string][]provision.PortForwardOptions)
 	p.execsMut.Unlock()
 
 	uniqueIpCounter = 0
@@ -290,6 +316,142 @@ func (p *FakeProvisioner) Reset() {
 	}
 }
 
+func (p *FakeProvisioner) ClusterFeatures(c *provTypes.Cluster, pool string) []provTypes.ClusterFeature {
+	return []provTypes.ClusterFeature{
+		{Name: "fake-feature", Description: "Fake feature.", Enabled: c.FeatureEnabled(pool, "fake-feature")},
+	}
+}
+
+func (p *FakeProvisioner) ResourceQuotas(ctx context.Context, c *provTypes.Cluster) ([]provTypes.NamespaceResourceQuota, error) {
+	if err := p.getError("ResourceQuotas"); err != nil {
+		return nil, err
+	}
+	return []provTypes.NamespaceResourceQuota{
+		{
+			Namespace: "default",
+			Hard:      map[string]string{"limits.cpu": "4", "limits.memory": "4Gi"},
+			Used:      map[string]string{"limits.cpu": "1", "limits.memory": "1Gi"},
+		},
+	}, nil
+}
+
+func (p *FakeProvisioner) ClusterCapacity(ctx context.Context, c *provTypes.Cluster, simulation *provision.CapacitySimulationOptions) (*provTypes.ClusterCapacity, error) {
+	if err := p.getError("ClusterCapacity"); err != nil {
+		return nil, err
+	}
+	result := &provTypes.ClusterCapacity{
+		Pools: []provTypes.PoolCapacity{
+			{
+				Pool:                "default",
+				Nodes:               2,
+				AllocatableCPUMilli: 4000,
+				AllocatableMemory:   4 * 1024 * 1024 * 1024,
+				RequestedCPUMilli:   1000,
+				RequestedMemory:     1024 * 1024 * 1024,
+			},
+		},
+	}
+	if simulation != nil {
+		const schedulableUnits = 3
+		result.Simulation = &provTypes.CapacitySimulation{
+			Pool:             simulation.Pool,
+			Units:            simulation.Units,
+			UnitCPUMilli:     int64(simulation.Plan.GetMilliCPU()),
+			UnitMemory:       simulation.Plan.GetMemory(),
+			SchedulableUnits: schedulableUnits,
+			Fits:             simulation.Units <= schedulableUnits,
+		}
+	}
+	return result, nil
+}
+
+// AddNode adds a node to the clusters of the provisioner.
+func (p *FakeProvisioner) AddNode(node provTypes.ClusterNode) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.nodes[node.Name] = node
+}
+
+// GetNode returns a node added with AddNode, as changed by the node
+// maintenance methods.
+func (p *FakeProvisioner) GetNode(name string) (provTypes.ClusterNode, bool) {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	node, ok := p.nodes[name]
+	return node, ok
+}
+
+func (p *FakeProvisioner) ListNodes(ctx context.Context, c *provTypes.Cluster) ([]provTypes.ClusterNode, error) {
+	if err := p.getError("ListNodes"); err != nil {
+		return nil, err
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	nodes := make([]provTypes.ClusterNode, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+func (p *FakeProvisioner) CordonNode(ctx context.Context, c *provTypes.Cluster, name string, unschedulable bool) error {
+	if err := p.getError("CordonNode"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	node, ok := p.nodes[name]
+	if !ok {
+		return provision.ErrNodeNotFound
+	}
+	node.Unschedulable = unschedulable
+	p.nodes[name] = node
+	return nil
+}
+
+func (p *FakeProvisioner) DrainNode(ctx context.Context, c *provTypes.Cluster, name string, w io.Writer) error {
+	if err := p.getError("DrainNode"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	node, ok := p.nodes[name]
+	if !ok {
+		return provision.ErrNodeNotFound
+	}
+	node.Unschedulable = true
+	p.nodes[name] = node
+	fmt.Fprintf(w, "node %q drained\n", name)
+	return nil
+}
+
+func (p *FakeProvisioner) AnnotateNode(ctx context.Context, c *provTypes.Cluster, name string, annotations map[string]string) error {
+	if err := p.getError("AnnotateNode"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	node, ok := p.nodes[name]
+	if !ok {
+		return provision.ErrNodeNotFound
+	}
+	newAnnotations := map[string]string{}
+	for k, v := range node.Annotations {
+		newAnnotations[k] = v
+	}
+	for k, v := range annotations {
+		if v == "" {
+			delete(newAnnotations, k)
+			continue
+		}
+		newAnnotations[k] = v
+	}
+	node.Annotations = newAnnotations
+	p.nodes[name] = node
+	return nil
+}
+
 func (p *FakeProvisioner) Deploy(ctx context.Context, args provision.DeployArgs) (string, error) {
 	if err := p.getError("Deploy"); err != nil {
 		return "", err
@@ -300,12 +462,21 @@ func (p *FakeProvisioner) Deploy(ctx context.Context, args provision.DeployArgs)
 	if !ok {
 		return "", errNotProvisioned
 	}
-	if args.Version.VersionInfo().DeployImage != "" {
-		pApp.image = args.Version.VersionInfo().DeployImage
+	image := args.Version.VersionInfo().DeployImage
+	if image == "" {
+		image = args.Version.VersionInfo().BuildImage
+	}
+	if args.Strategy == provision.DeployStrategyBlueGreen && pApp.image != "" {
+		if pApp.pendingVersion != 0 {
+			return "", errors.Errorf("version %d is pending confirmation", pApp.pendingVersion)
+		}
+		pApp.pendingVersion = args.Version.Version()
+		pApp.pendingImage = image
 	} else {
-		pApp.image = args.Version.VersionInfo().BuildImage
+		pApp.image = image
 	}
 	args.Event.Write([]byte("Builder deploy called"))
+	pApp.planOverride = args.PlanOverride
 	p.apps[args.App.Name] = pApp
 	err := args.Version.CommitBaseImage()
 	if err != nil {
@@ -318,6 +489,61 @@ func (p *FakeProvisioner) Deploy(ctx context.Context, args provision.DeployArgs)
 	return args.Version.VersionInfo().DeployImage, nil
 }
 
+func (p *FakeProvisioner) ConfirmDeploy(ctx context.Context, app *appTypes.App, w io.Writer) (int, error) {
+	if err := p.getError("ConfirmDeploy"); err != nil {
+		return 0, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[app.Name]
+	if !ok {
+		return 0, errNotProvisioned
+	}
+	if pApp.pendingVersion == 0 {
+		return 0, provision.ErrNoPendingDeploy
+	}
+	version := pApp.pendingVersion
+	pApp.image = pApp.pendingImage
+	pApp.pendingVersion, pApp.pendingImage = 0, ""
+	p.apps[app.Name] = pApp
+	return version, nil
+}
+
+func (p *FakeProvisioner) AbortDeploy(ctx context.Context, app *appTypes.App, w io.Writer) (int, error) {
+	if err := p.getError("AbortDeploy"); err != nil {
+		return 0, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[app.Name]
+	if !ok {
+		return 0, errNotProvisioned
+	}
+	if pApp.pendingVersion == 0 {
+		return 0, provision.ErrNoPendingDeploy
+	}
+	version := pApp.pendingVersion
+	pApp.pendingVersion, pApp.pendingImage = 0, ""
+	p.apps[app.Name] = pApp
+	return version, nil
+}
+
+// PendingVersion returns the version left pending by a blue-green deploy of
+// the app.
+func (p *FakeProvisioner) PendingVersion(app *appTypes.App) int {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.apps[app.Name].pendingVersion
+}
+
+// DeployPlanOverride returns the plan override sent in the last deploy of the
+// app.
+func (p *FakeProvisioner) DeployPlanOverride(app *appTypes.App) *appTypes.PlanOverride {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.apps[app.Name].planOverride
+}
+
 func (p *FakeProvisioner) Provision(ctx context.Context, app *appTypes.App) error {
 	if err := p.getError("Provision"); err != nil {
 		return err
@@ -532,9 +758,10 @@ func (p *FakeProvisioner) UnitsMetrics(ctx context.Context, a *appTypes.App) ([]
 	var unitsMetrics []provTypes.UnitMetric
 	for _, unit := range p.apps[a.Name].units {
 		unitsMetrics = append(unitsMetrics, provTypes.UnitMetric{
-			ID:     unit.ID,
-			CPU:    "10m",
-			Memory: "100Mi",
+			ID:          unit.ID,
+			ProcessName: unit.ProcessName,
+			CPU:         "10m",
+			Memory:      "100Mi",
 		})
 	}
 	return unitsMetrics, nil
@@ -634,6 +861,52 @@ func (p *FakeProvisioner) ExecuteCommand(ctx context.Context, opts provision.Exe
 	return err
 }
 
+// PortForward records the call and writes the next prepared output, if any,
+// to the connection, as if it came from the forwarded port.
+func (p *FakeProvisioner) PortForward(ctx context.Context, opts provision.PortForwardOptions) error {
+	if err := p.getError("PortForward"); err != nil {
+		return err
+	}
+	p.execsMut.Lock()
+	p.portForwards[opts.Unit] = append(p.portForwards[opts.Unit], opts)
+	p.execsMut.Unlock()
+	select {
+	case output := <-p.outputs:
+		opts.Conn.Write(output)
+	default:
+	}
+	return nil
+}
+
+func (p *FakeProvisioner) RunTask(ctx context.Context, opts provision.TaskOptions) error {
+	if err := p.getError("RunTask"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[opts.App.Name]
+	if !ok {
+		return errNotProvisioned
+	}
+	pApp.tasks = append(pApp.tasks, opts)
+	p.apps[opts.App.Name] = pApp
+	select {
+	case output := <-p.outputs:
+		if opts.Output != nil {
+			opts.Output.Write(output)
+		}
+	default:
+	}
+	return nil
+}
+
+// Tasks returns the tasks run for the app.
+func (p *FakeProvisioner) Tasks(app *appTypes.App) []provision.TaskOptions {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.apps[app.Name].tasks
+}
+
 func (p *FakeProvisioner) FilterAppsByUnitStatus(ctx context.Context, apps []*appTypes.App, status []string) ([]*appTypes.App, error) {
 	filteredApps := []*appTypes.App{}
 	for i := range apps {
@@ -674,6 +947,196 @@ func (p *FakeProvisioner) UpdateApp(ctx context.Context, old, new *appTypes.App,
 	return nil
 }
 
+// ValidateWorkloadToken accepts only "fake-workload-token-<app name>".
+func (p *FakeProvisioner) ValidateWorkloadToken(ctx context.Context, a *appTypes.App, token string) error {
+	if token != "fake-workload-token-"+a.Name {
+		return errors.New("token not issued to the app")
+	}
+	return nil
+}
+
+// RebalanceUnits treats the IP of units as their node, moving units of each
+// process from the most to the least loaded node until they differ by at most
+// one unit.
+func (p *FakeProvisioner) RebalanceUnits(ctx context.Context, a *appTypes.App, opts provision.RebalanceUnitsOptions) ([]provision.UnitEviction, error) {
+	if err := p.getError("RebalanceUnits"); err != nil {
+		return nil, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[a.Name]
+	if !ok {
+		return nil, errNotProvisioned
+	}
+	units := append([]provTypes.Unit{}, pApp.units...)
+	var nodes []string
+	for _, u := range units {
+		if !slices.Contains(nodes, u.IP) {
+			nodes = append(nodes, u.IP)
+		}
+	}
+	evictions := []provision.UnitEviction{}
+	for len(nodes) > 0 && (opts.MaxEvictions <= 0 || len(evictions) < opts.MaxEvictions) {
+		perNode := map[string]map[string][]int{}
+		var processes []string
+		for i, u := range units {
+			if perNode[u.ProcessName] == nil {
+				perNode[u.ProcessName] = map[string][]int{}
+				processes = append(processes, u.ProcessName)
+			}
+			perNode[u.ProcessName][u.IP] = append(perNode[u.ProcessName][u.IP], i)
+		}
+		moved := false
+		for _, process := range processes {
+			procUnits := perNode[process]
+			maxNode, minNode := nodes[0], nodes[0]
+			for _, node := range nodes {
+				if len(procUnits[node]) > len(procUnits[maxNode]) {
+					maxNode = node
+				}
+				if len(procUnits[node]) < len(procUnits[minNode]) {
+					minNode = node
+				}
+			}
+			if len(procUnits[maxNode])-len(procUnits[minNode]) <= 1 {
+				continue
+			}
+			idx := procUnits[maxNode][len(procUnits[maxNode])-1]
+			evictions = append(evictions, provision.UnitEviction{
+				App:     a.Name,
+				Unit:    units[idx].ID,
+				Process: process,
+				Node:    maxNode,
+				Reason:  fmt.Sprintf("node %s has %d units of process %s while node %s has %d", maxNode, len(procUnits[maxNode]), process, minNode, len(procUnits[minNode])),
+			})
+			units[idx].IP = minNode
+			moved = true
+			break
+		}
+		if !moved {
+			break
+		}
+	}
+	if !opts.DryRun {
+		pApp.units = units
+		p.apps[a.Name] = pApp
+	}
+	return evictions, nil
+}
+
+// AddDrift registers drifts to be reported for the app until they are
+// repaired.
+func (p *FakeProvisioner) AddDrift(app *appTypes.App, drifts ...provision.Drift) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	a := p.apps[app.Name]
+	a.drifts = append(a.drifts, drifts...)
+	p.apps[app.Name] = a
+}
+
+func (p *FakeProvisioner) Drift(ctx context.Context, a *appTypes.App) ([]provision.Drift, error) {
+	if err := p.getError("Drift"); err != nil {
+		return nil, err
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	pApp, ok := p.apps[a.Name]
+	if !ok {
+		return nil, errNotProvisioned
+	}
+	return append([]provision.Drift{}, pApp.drifts...), nil
+}
+
+func (p *FakeProvisioner) RepairDrift(ctx context.Context, a *appTypes.App, w io.Writer) ([]provision.Drift, error) {
+	if err := p.getError("RepairDrift"); err != nil {
+		return nil, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[a.Name]
+	if !ok {
+		return nil, errNotProvisioned
+	}
+	drifts := []provision.Drift{}
+	for _, d := range pApp.drifts {
+		if w != nil {
+			fmt.Fprintf(w, "repairing %s %s", d.Kind, d.Name)
+		}
+		d.Repaired = true
+		drifts = append(drifts, d)
+	}
+	pApp.drifts = nil
+	p.apps[a.Name] = pApp
+	return drifts, nil
+}
+
+// RenderedManifests returns a manifest for each process of the version with
+// its image and a hash of the environment variables of the app.
+func (p *FakeProvisioner) RenderedManifests(ctx context.Context, a *appTypes.App, version appTypes.AppVersion) ([]provision.RenderedManifest, error) {
+	if err := p.getError("RenderedManifests"); err != nil {
+		return nil, err
+	}
+	image := version.VersionInfo().DeployImage
+	envs := provision.EnvsForAppVersion(a, version.Version())
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s=%s\n", name, envs[name].Value)
+	}
+	envHash := hex.EncodeToString(hash.Sum(nil))
+	processes, _ := version.Processes()
+	if len(processes) == 0 {
+		processes = map[string][]string{"web": nil}
+	}
+	var manifests []provision.RenderedManifest
+	for process := range processes {
+		manifests = append(manifests, provision.RenderedManifest{
+			Process:  process,
+			Image:    image,
+			EnvHash:  envHash,
+			Manifest: fmt.Sprintf("process: %s\nimage: %s\nenvHash: %s\n", process, image, envHash),
+		})
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Process < manifests[j].Process })
+	return manifests, nil
+}
+
+// AddOrphanResources registers resources to be reported as orphans until
+// they are removed.
+func (p *FakeProvisioner) AddOrphanResources(orphans ...provision.OrphanResource) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.orphans = append(p.orphans, orphans...)
+}
+
+func (p *FakeProvisioner) OrphanResources(ctx context.Context) ([]provision.OrphanResource, error) {
+	if err := p.getError("OrphanResources"); err != nil {
+		return nil, err
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return append([]provision.OrphanResource{}, p.orphans...), nil
+}
+
+func (p *FakeProvisioner) RemoveOrphanResources(ctx context.Context) ([]provision.OrphanResource, error) {
+	if err := p.getError("RemoveOrphanResources"); err != nil {
+		return nil, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	orphans := []provision.OrphanResource{}
+	for _, o := range p.orphans {
+		o.Removed = true
+		orphans = append(orphans, o)
+	}
+	p.orphans = nil
+	return orphans, nil
+}
+
 func (p *FakeProvisioner) InternalAddresses(ctx context.Context, a *appTypes.App) ([]appTypes.AppInternalAddress, error) {
 	return []appTypes.AppInternalAddress{
 		{
@@ -787,8 +1250,13 @@ type provisionedApp struct {
 	lastData  map[string]interface{}
 	image     string
 	mockAddrs []appTypes.RoutableAddresses
+	drifts    []provision.Drift
 
 	restartsByVersion map[string]int
+	planOverride      *appTypes.PlanOverride
+	tasks             []provision.TaskOptions
+	pendingVersion    int
+	pendingImage      string
 }
 
 type provisionedJob struct {
@@ -878,10 +1346,10 @@ func (p *JobProvisioner) DestroyJob(ctx context.Context, job *jobTypes.Job) erro
 	return nil
 }
 
-func (p *JobProvisioner) TriggerCron(ctx context.Context, name, pool string) error {
+func (p *JobProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job) error {
 	p.mut.Lock()
 	defer p.mut.Unlock()
-	j, ok := p.jobs[name]
+	j, ok := p.jobs[job.Name]
 	if !ok {
 		return errNotProvisioned
 	}
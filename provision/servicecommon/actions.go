@@ -46,6 +46,8 @@ type pipelineArgs struct {
 	event            *event.Event
 	preserveVersions bool
 	overrideVersions bool
+	annotations      map[string]string
+	planOverride     *appTypes.PlanOverride
 }
 
 type labelReplicas struct {
@@ -68,6 +70,8 @@ type DeployServiceOpts struct {
 	Version          appTypes.AppVersion
 	PreserveVersions bool
 	OverrideVersions bool
+	Annotations      map[string]string
+	PlanOverride     *appTypes.PlanOverride
 }
 
 // RunServicePipeline runs a pipeline for deploy a service with multiple
@@ -110,6 +114,8 @@ func RunServicePipeline(ctx context.Context, manager ServiceManager, oldVersionN
 		newVersionSpec:   newSpec,
 		event:            args.Event,
 		overrideVersions: args.OverrideVersions,
+		annotations:      args.Annotations,
+		planOverride:     args.PlanOverride,
 	})
 }
 
@@ -236,7 +242,13 @@ var updateServices = &action.Action{
 			totalUnits += labels.realReplicas
 		}
 		var err error
-		for _, processName := range toDeployProcesses {
+		for i, processName := range toDeployProcesses {
+			if i > 0 {
+				err = args.event.Checkpoint(ctx.Context, fmt.Sprintf("units of process %s updated", toDeployProcesses[i-1]))
+				if err != nil {
+					break
+				}
+			}
 			labels := newLabelsMap[processName]
 			err = args.manager.DeployService(ctx.Context, DeployServiceOpts{
 				App:              args.app,
@@ -246,6 +258,8 @@ var updateServices = &action.Action{
 				Version:          args.newVersion,
 				PreserveVersions: args.preserveVersions,
 				OverrideVersions: args.overrideVersions,
+				Annotations:      args.annotations,
+				PlanOverride:     args.planOverride,
 			})
 
 			if err != nil {
@@ -7,6 +7,7 @@ package registry
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -82,6 +83,82 @@ func RemoveImage(ctx context.Context, imageName string) error {
 	return nil
 }
 
+// ImageDigest returns the manifest digest of an image stored in a remote
+// registry v2 server.
+func ImageDigest(ctx context.Context, imageName string) (string, error) {
+	if imageName == "" {
+		return "", errors.New("invalid empty image name")
+	}
+	registry, image, tag := image.ParseImageParts(imageName)
+	if registry == "" {
+		return "", errors.New("invalid empty registry")
+	}
+	r := &dockerRegistry{registry: registry}
+	err := r.registryAuth(ctx, imageName)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get auth for %s registry", r.registry)
+	}
+	digest, err := r.getDigest(ctx, image, tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get digest for image %s/%s:%s on registry", r.registry, image, tag)
+	}
+	return digest, nil
+}
+
+// ListTags returns the tags of a repository, in the form
+// registry.example.com/namespace/name, stored in a remote registry v2 server.
+func ListTags(ctx context.Context, repository string) ([]string, error) {
+	registry, image, _ := image.ParseImageParts(repository)
+	if registry == "" {
+		return nil, errors.New("invalid empty registry")
+	}
+	r := &dockerRegistry{registry: registry}
+	err := r.registryAuth(ctx, repository)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get auth for %s registry", r.registry)
+	}
+	var tags []string
+	path := fmt.Sprintf("/v2/%s/tags/list", image)
+	for path != "" {
+		var page []string
+		page, path, err = r.listTags(ctx, path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list tags for %s/%s on registry", r.registry, image)
+		}
+		tags = append(tags, page...)
+	}
+	return tags, nil
+}
+
+// ImageBlobs returns the size of the config and layers of an image stored in
+// a remote registry v2 server, indexed by their digests.
+func ImageBlobs(ctx context.Context, imageName string) (map[string]int64, error) {
+	if imageName == "" {
+		return nil, errors.New("invalid empty image name")
+	}
+	registry, image, tag := image.ParseImageParts(imageName)
+	if registry == "" {
+		return nil, errors.New("invalid empty registry")
+	}
+	r := &dockerRegistry{registry: registry}
+	err := r.registryAuth(ctx, imageName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get auth for %s registry", r.registry)
+	}
+	manifest, err := r.getManifest(ctx, image, tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get manifest for image %s/%s:%s on registry", r.registry, image, tag)
+	}
+	blobs := map[string]int64{}
+	if manifest.Config.Digest != "" {
+		blobs[manifest.Config.Digest] = manifest.Config.Size
+	}
+	for _, layer := range manifest.Layers {
+		blobs[layer.Digest] = layer.Size
+	}
+	return blobs, nil
+}
+
 // RemoveAppImages removes all app images on all registry v2 server, returning an error
 // in case of failure.
 func RemoveAppImages(ctx context.Context, appName string) error {
@@ -135,6 +212,75 @@ func (r dockerRegistry) getDigest(ctx context.Context, image, tag string) (strin
 	return digest, nil
 }
 
+type manifestBlob struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+type imageManifest struct {
+	Config manifestBlob   `json:"config"`
+	Layers []manifestBlob `json:"layers"`
+}
+
+func (r dockerRegistry) getManifest(ctx context.Context, image, tag string) (*imageManifest, error) {
+	path := fmt.Sprintf("/v2/%s/manifests/%s", image, tag)
+	resp, err := r.doRequest(ctx, "GET", path, map[string]string{"Accept": "application/vnd.docker.distribution.manifest.v2+json"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrImageNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("invalid status reading manifest for %v:%v: %v", image, tag, resp.StatusCode)
+	}
+	var manifest imageManifest
+	err = json.NewDecoder(resp.Body).Decode(&manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// listTags returns a page of tags and the path for the next one, which is
+// empty on the last page.
+func (r dockerRegistry) listTags(ctx context.Context, path string) ([]string, string, error) {
+	resp, err := r.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", errors.Errorf("invalid status listing tags: %v", resp.StatusCode)
+	}
+	var tagList struct {
+		Tags []string `json:"tags"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&tagList)
+	if err != nil {
+		return nil, "", err
+	}
+	return tagList.Tags, nextPagePath(resp.Header.Get("Link")), nil
+}
+
+// nextPagePath extracts the path from a Link header in the format
+// </v2/name/tags/list?n=100&last=tag>; rel="next".
+func nextPagePath(link string) string {
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end <= start || !strings.Contains(link[end:], `rel="next"`) {
+		return ""
+	}
+	u, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
+
 func (r dockerRegistry) removeImage(ctx context.Context, image, tag, digest string) error {
 	// GCR/GAR registries implementation do not completely follow docker
 	// registry spec. They require the image tag to be deleted prior to
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strconv"
 	"testing"
 
@@ -338,3 +339,41 @@ func (s *S) TestDockerRegistryDoRequestTLS(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(rsp.StatusCode, check.Equals, http.StatusOK)
 }
+
+func (s *S) TestImageDigest(c *check.C) {
+	s.server.AddRepo(registrytest.Repository{Name: "tsuru/app-test", Tags: map[string]string{"v1": "sha256:abcdefg"}})
+	digest, err := ImageDigest(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(digest, check.Equals, "sha256:abcdefg")
+	_, err = ImageDigest(context.TODO(), s.server.Addr()+"/tsuru/app-test:v2")
+	c.Assert(errors.Cause(err), check.Equals, ErrDigestNotFound)
+}
+
+func (s *S) TestListTags(c *check.C) {
+	s.server.AddRepo(registrytest.Repository{Name: "tsuru/app-test", Tags: map[string]string{"v1": "sha256:abc", "v2": "sha256:def"}})
+	tags, err := ListTags(context.TODO(), s.server.Addr()+"/tsuru/app-test")
+	c.Assert(err, check.IsNil)
+	sort.Strings(tags)
+	c.Assert(tags, check.DeepEquals, []string{"v1", "v2"})
+	tags, err = ListTags(context.TODO(), s.server.Addr()+"/tsuru/app-other")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.HasLen, 0)
+}
+
+func (s *S) TestImageBlobs(c *check.C) {
+	s.server.AddRepo(registrytest.Repository{
+		Name:   "tsuru/app-test",
+		Tags:   map[string]string{"v1": "sha256:abc"},
+		Layers: map[string][]registrytest.Layer{"v1": {{Digest: "sha256:l1", Size: 10}, {Digest: "sha256:l2", Size: 20}}},
+	})
+	blobs, err := ImageBlobs(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(blobs, check.DeepEquals, map[string]int64{"sha256:l1": 10, "sha256:l2": 20})
+	_, err = ImageBlobs(context.TODO(), s.server.Addr()+"/tsuru/app-test:v2")
+	c.Assert(errors.Cause(err), check.Equals, ErrImageNotFound)
+}
+
+func (s *S) TestNextPagePath(c *check.C) {
+	c.Assert(nextPagePath(`</v2/tsuru/app-test/tags/list?n=2&last=v2>; rel="next"`), check.Equals, "/v2/tsuru/app-test/tags/list?n=2&last=v2")
+	c.Assert(nextPagePath(""), check.Equals, "")
+}
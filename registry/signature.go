@@ -0,0 +1,180 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+var (
+	ErrImageNotSigned    = errors.New("image is not signed by any of the trusted keys")
+	ErrSignatureNoDigest = errors.New("unable to find the digest of the verified image")
+)
+
+var reNotaryDigest = regexp.MustCompile(`@(sha256:[a-f0-9]+)`)
+
+// runSignatureCommand runs the cosign or notation binaries, it's replaced in
+// tests.
+var runSignatureCommand = func(ctx context.Context, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s verify failed: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// ImageSignature describes the verified signature of an image.
+type ImageSignature struct {
+	Format         string    `json:"format"`
+	Image          string    `json:"image"`
+	Digest         string    `json:"digest,omitempty"`
+	KeyFingerprint string    `json:"keyFingerprint"`
+	VerifiedAt     time.Time `json:"verifiedAt"`
+}
+
+// VerifyImageSignature checks that the image is signed, in the given format,
+// by one of the PEM encoded keys. The registry credentials are the ones
+// available to the cosign and notation binaries.
+func VerifyImageSignature(ctx context.Context, imageName, format string, keys []string) (*ImageSignature, error) {
+	var verify func(context.Context, string, string, string) (string, error)
+	switch format {
+	case provTypes.ImageSignatureCosign:
+		verify = verifyCosign
+	case provTypes.ImageSignatureNotary:
+		verify = verifyNotary
+	default:
+		return nil, errors.Errorf("unsupported image signature format %q", format)
+	}
+	dir, err := os.MkdirTemp("", "tsuru-signature-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	var errs []string
+	for i, key := range keys {
+		keyDir := filepath.Join(dir, fmt.Sprintf("key-%d", i))
+		digest, verifyErr := verify(ctx, keyDir, imageName, key)
+		if verifyErr != nil {
+			errs = append(errs, verifyErr.Error())
+			continue
+		}
+		return &ImageSignature{
+			Format:         format,
+			Image:          imageName,
+			Digest:         digest,
+			KeyFingerprint: keyFingerprint(key),
+			VerifiedAt:     time.Now().UTC(),
+		}, nil
+	}
+	return nil, errors.Wrapf(ErrImageNotSigned, "%s signature verification failed for %s: %s", format, imageName, strings.Join(errs, "; "))
+}
+
+// ImageByDigest returns the verified image referenced by its digest, so the
+// tag can't be moved to another image once verified.
+func (s *ImageSignature) ImageByDigest() string {
+	repo := s.Image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo + "@" + s.Digest
+}
+
+func keyFingerprint(key string) string {
+	data := []byte(key)
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func verifyCosign(ctx context.Context, dir, imageName, key string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	keyFile := filepath.Join(dir, "cosign.pub")
+	if err := os.WriteFile(keyFile, []byte(key), 0600); err != nil {
+		return "", err
+	}
+	out, err := runSignatureCommand(ctx, nil, "cosign", "verify", "--key", keyFile, "--output", "json", imageName)
+	if err != nil {
+		return "", err
+	}
+	var payloads []struct {
+		Critical struct {
+			Image struct {
+				Digest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err = json.Unmarshal(out, &payloads); err != nil {
+		return "", errors.Wrap(ErrSignatureNoDigest, err.Error())
+	}
+	if len(payloads) == 0 || payloads[0].Critical.Image.Digest == "" {
+		return "", ErrSignatureNoDigest
+	}
+	for _, p := range payloads[1:] {
+		if p.Critical.Image.Digest != payloads[0].Critical.Image.Digest {
+			return "", errors.Wrap(ErrSignatureNoDigest, "signatures reference different digests")
+		}
+	}
+	return payloads[0].Critical.Image.Digest, nil
+}
+
+// notaryTrustPolicy trusts any identity whose certificate chains to the
+// certificates in the tsuru trust store.
+const notaryTrustPolicy = `{
+	"version": "1.0",
+	"trustPolicies": [{
+		"name": "tsuru",
+		"registryScopes": ["*"],
+		"signatureVerification": {"level": "strict"},
+		"trustStores": ["ca:tsuru"],
+		"trustedIdentities": ["*"]
+	}]
+}`
+
+func verifyNotary(ctx context.Context, dir, imageName, key string) (string, error) {
+	storeDir := filepath.Join(dir, "notation", "truststore", "x509", "ca", "tsuru")
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "tsuru.pem"), []byte(key), 0600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notation", "trustpolicy.json"), []byte(notaryTrustPolicy), 0600); err != nil {
+		return "", err
+	}
+	out, err := runSignatureCommand(ctx, []string{"XDG_CONFIG_HOME=" + dir}, "notation", "verify", imageName)
+	if err != nil {
+		return "", err
+	}
+	if parts := reNotaryDigest.FindSubmatch(out); parts != nil {
+		return string(parts[1]), nil
+	}
+	return "", ErrSignatureNoDigest
+}
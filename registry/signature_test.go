@@ -0,0 +1,111 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+const (
+	signatureKey1 = "-----BEGIN PUBLIC KEY-----\nMFkw\n-----END PUBLIC KEY-----\n"
+	signatureKey2 = "-----BEGIN PUBLIC KEY-----\nMFkx\n-----END PUBLIC KEY-----\n"
+)
+
+func mockSignatureCommand(fn func(env []string, name string, args []string) ([]byte, error)) func() {
+	old := runSignatureCommand
+	runSignatureCommand = func(ctx context.Context, env []string, name string, args ...string) ([]byte, error) {
+		return fn(env, name, args)
+	}
+	return func() { runSignatureCommand = old }
+}
+
+func (s *S) TestVerifyImageSignatureCosign(c *check.C) {
+	var calls int
+	defer mockSignatureCommand(func(env []string, name string, args []string) ([]byte, error) {
+		calls++
+		c.Assert(name, check.Equals, "cosign")
+		c.Assert(args[0:2], check.DeepEquals, []string{"verify", "--key"})
+		c.Assert(args[3:], check.DeepEquals, []string{"--output", "json", "registry.io/tsuru/app:v1"})
+		key, err := os.ReadFile(args[2])
+		c.Assert(err, check.IsNil)
+		if string(key) != signatureKey2 {
+			return nil, errors.New("no matching signatures")
+		}
+		return []byte(`[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}}]`), nil
+	})()
+	sig, err := VerifyImageSignature(context.TODO(), "registry.io/tsuru/app:v1", provisionTypes.ImageSignatureCosign, []string{signatureKey1, signatureKey2})
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 2)
+	c.Assert(sig.Format, check.Equals, "cosign")
+	c.Assert(sig.Image, check.Equals, "registry.io/tsuru/app:v1")
+	c.Assert(sig.Digest, check.Equals, "sha256:abc")
+	c.Assert(sig.KeyFingerprint, check.Equals, keyFingerprint(signatureKey2))
+	c.Assert(sig.VerifiedAt.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestVerifyImageSignatureNotary(c *check.C) {
+	defer mockSignatureCommand(func(env []string, name string, args []string) ([]byte, error) {
+		c.Assert(name, check.Equals, "notation")
+		c.Assert(args, check.DeepEquals, []string{"verify", "registry.io/tsuru/app:v1"})
+		c.Assert(env, check.HasLen, 1)
+		dir := strings.TrimPrefix(env[0], "XDG_CONFIG_HOME=")
+		cert, err := os.ReadFile(filepath.Join(dir, "notation", "truststore", "x509", "ca", "tsuru", "tsuru.pem"))
+		c.Assert(err, check.IsNil)
+		c.Assert(string(cert), check.Equals, signatureKey1)
+		_, err = os.Stat(filepath.Join(dir, "notation", "trustpolicy.json"))
+		c.Assert(err, check.IsNil)
+		return []byte("Successfully verified signature for registry.io/tsuru/app@sha256:def\n"), nil
+	})()
+	sig, err := VerifyImageSignature(context.TODO(), "registry.io/tsuru/app:v1", provisionTypes.ImageSignatureNotary, []string{signatureKey1})
+	c.Assert(err, check.IsNil)
+	c.Assert(sig.Format, check.Equals, "notary")
+	c.Assert(sig.Digest, check.Equals, "sha256:def")
+}
+
+func (s *S) TestVerifyImageSignatureNotSigned(c *check.C) {
+	defer mockSignatureCommand(func(env []string, name string, args []string) ([]byte, error) {
+		return nil, errors.New("cosign verify failed: no signatures found")
+	})()
+	_, err := VerifyImageSignature(context.TODO(), "registry.io/tsuru/app:v1", provisionTypes.ImageSignatureCosign, []string{signatureKey1})
+	c.Assert(errors.Cause(err), check.Equals, ErrImageNotSigned)
+	c.Assert(err, check.ErrorMatches, `cosign signature verification failed for registry.io/tsuru/app:v1: cosign verify failed: no signatures found: .*`)
+	_, err = VerifyImageSignature(context.TODO(), "registry.io/tsuru/app:v1", "gpg", []string{signatureKey1})
+	c.Assert(err, check.ErrorMatches, `unsupported image signature format "gpg"`)
+}
+
+func (s *S) TestVerifyImageSignatureWithoutDigest(c *check.C) {
+	for _, out := range []string{"", "[]", `[{"critical":{"image":{}}}]`, "not json"} {
+		defer mockSignatureCommand(func(env []string, name string, args []string) ([]byte, error) {
+			return []byte(out), nil
+		})()
+		_, err := VerifyImageSignature(context.TODO(), "registry.io/tsuru/app:v1", provisionTypes.ImageSignatureCosign, []string{signatureKey1})
+		c.Assert(errors.Cause(err), check.Equals, ErrImageNotSigned, check.Commentf("output %q", out))
+	}
+	defer mockSignatureCommand(func(env []string, name string, args []string) ([]byte, error) {
+		return []byte("Successfully verified signature for registry.io/tsuru/app:v1\n"), nil
+	})()
+	_, err := VerifyImageSignature(context.TODO(), "registry.io/tsuru/app:v1", provisionTypes.ImageSignatureNotary, []string{signatureKey1})
+	c.Assert(errors.Cause(err), check.Equals, ErrImageNotSigned)
+}
+
+func (s *S) TestImageSignatureImageByDigest(c *check.C) {
+	tests := map[string]string{
+		"registry.io/tsuru/app:v1":               "registry.io/tsuru/app@sha256:abc",
+		"registry.io:5000/tsuru/app":             "registry.io:5000/tsuru/app@sha256:abc",
+		"tsuru/app@sha256:old":                   "tsuru/app@sha256:abc",
+		"registry.io:5000/tsuru/app:v1@sha256:o": "registry.io:5000/tsuru/app@sha256:abc",
+	}
+	for image, expected := range tests {
+		sig := &ImageSignature{Image: image, Digest: "sha256:abc"}
+		c.Check(sig.ImageByDigest(), check.Equals, expected)
+	}
+}
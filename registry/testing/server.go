@@ -27,14 +27,27 @@ var (
 )
 
 type Repository struct {
-	Name     string
-	Tags     map[string]string
+	Name string
+	Tags map[string]string
+	// Layers are the layers of the image with each tag, returned in its
+	// manifest.
+	Layers   map[string][]Layer
 	Username string
 	Password string
 	Token    string
 	Expire   int
 }
 
+type Layer struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+type manifestResponse struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	Layers        []Layer `json:"layers"`
+}
+
 type tagListResponse struct {
 	Name string   `json:"name"`
 	Tags []string `json:"tags"`
@@ -117,6 +130,7 @@ func (s *RegistryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (s *RegistryServer) buildMuxer() {
 	s.muxer = mux.NewRouter()
 	s.muxer.Path("/v2/{name:.*}/manifests/{tag:.*}").Methods("HEAD").HandlerFunc(s.getDigest)
+	s.muxer.Path("/v2/{name:.*}/manifests/{tag:.*}").Methods("GET").HandlerFunc(s.getManifest)
 	s.muxer.Path("/v2/{name:.*}/manifests/{digest:.*}").Methods("DELETE").HandlerFunc(s.removeTag)
 	s.muxer.Path("/v2/{name:.*}/tags/list").Methods("GET").HandlerFunc(s.listTags)
 	s.muxer.Path("/token/{name:.*}").Methods("GET").HandlerFunc(s.getToken)
@@ -236,6 +250,34 @@ func (s *RegistryServer) getDigest(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, fmt.Sprintf("unknown tag=%s", tag), http.StatusNotFound)
 }
 
+func (s *RegistryServer) getManifest(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	repo, index := s.findRepository(name)
+	err := s.auth(r)
+	if err != nil {
+		s.handleAuthError(w, err, name)
+		return
+	}
+	tag := mux.Vars(r)["tag"]
+	if index < 0 {
+		http.Error(w, fmt.Sprintf("unknown repository name=%s", name), http.StatusNotFound)
+		return
+	}
+	s.reposLock.RLock()
+	defer s.reposLock.RUnlock()
+	digest, ok := repo.Tags[tag]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown tag=%s", tag), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+	err = json.NewEncoder(w).Encode(manifestResponse{SchemaVersion: 2, Layers: repo.Layers[tag]})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (s *RegistryServer) listTags(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	repo, index := s.findRepository(name)
@@ -0,0 +1,78 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	UsageKindApp = "app"
+	UsageKindJob = "job"
+)
+
+// Usage is the space used in the registry by the images of an app or job,
+// as found by the last registry garbage collection.
+type Usage struct {
+	ID           string `bson:"_id" json:"-"`
+	Kind         string
+	Name         string
+	Repositories []string
+	Tags         int
+	// UnreferencedTags are the tags not used by any version, removed by the
+	// garbage collection unless it runs in dry-run mode.
+	UnreferencedTags []string
+	UsedBytes        int64
+	// ReclaimableBytes is the size of the layers only used by unreferenced
+	// tags. The space is only freed once the registry collects its blobs.
+	ReclaimableBytes int64
+	UpdatedAt        time.Time
+}
+
+// SaveUsage replaces the stored usage reports with the given ones.
+func SaveUsage(ctx context.Context, usage []Usage) error {
+	collection, err := storagev2.RegistryUsageCollection()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	ids := make([]string, len(usage))
+	for i := range usage {
+		usage[i].ID = usage[i].Kind + "/" + usage[i].Name
+		usage[i].UpdatedAt = now
+		ids[i] = usage[i].ID
+		_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": usage[i].ID}, usage[i], options.Replace().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$nin": ids}})
+	return err
+}
+
+// ListUsage returns the stored usage reports, the ones with more reclaimable
+// space first.
+func ListUsage(ctx context.Context) ([]Usage, error) {
+	collection, err := storagev2.RegistryUsageCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "reclaimablebytes", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, mongoBSON.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	usage := []Usage{}
+	err = cursor.All(ctx, &usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	AccessLogOpt           = "access-log"
+	AccessLogSampleRateOpt = "access-log-sample-rate"
+
+	AccessLogModeFull    = "full"
+	AccessLogModeSampled = "sampled"
+
+	// AccessLogSource is the log source of the access logs sent by routers,
+	// allowing them to be filtered apart from the app logs.
+	AccessLogSource = "router-access"
+
+	defaultAccessLogSampleRate = 0.1
+)
+
+// AccessLogPolicy describes which requests to an app must have their access
+// logs forwarded by the router to the tsuru log API.
+type AccessLogPolicy struct {
+	Mode       string  `json:"mode"`
+	SampleRate float64 `json:"sampleRate"`
+}
+
+// AccessLogPolicyFromOpts parses and validates the access log opts of an app
+// router. It returns nil when access logs are not enabled.
+func AccessLogPolicyFromOpts(opts map[string]string) (*AccessLogPolicy, error) {
+	mode, ok := opts[AccessLogOpt]
+	rate, hasRate := opts[AccessLogSampleRateOpt]
+	mode = strings.TrimSpace(mode)
+	if !ok || mode == "" || mode == "off" {
+		if hasRate {
+			return nil, errors.Errorf("%s requires %s to be %q", AccessLogSampleRateOpt, AccessLogOpt, AccessLogModeSampled)
+		}
+		return nil, nil
+	}
+	switch mode {
+	case AccessLogModeFull:
+		if hasRate {
+			return nil, errors.Errorf("%s requires %s to be %q", AccessLogSampleRateOpt, AccessLogOpt, AccessLogModeSampled)
+		}
+		return &AccessLogPolicy{Mode: mode, SampleRate: 1}, nil
+	case AccessLogModeSampled:
+		policy := &AccessLogPolicy{Mode: mode, SampleRate: defaultAccessLogSampleRate}
+		if hasRate {
+			var err error
+			policy.SampleRate, err = strconv.ParseFloat(strings.TrimSpace(rate), 64)
+			if err != nil || policy.SampleRate <= 0 || policy.SampleRate > 1 {
+				return nil, errors.Errorf("invalid %s %q, must be a number greater than 0 and up to 1", AccessLogSampleRateOpt, rate)
+			}
+		}
+		return policy, nil
+	}
+	return nil, errors.Errorf("invalid %s %q, valid values are: %q, %q or %q", AccessLogOpt, mode, AccessLogModeFull, AccessLogModeSampled, "off")
+}
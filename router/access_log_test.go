@@ -0,0 +1,36 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAccessLogPolicyFromOpts(c *check.C) {
+	tests := []struct {
+		opts     map[string]string
+		expected *AccessLogPolicy
+		err      string
+	}{
+		{opts: map[string]string{}},
+		{opts: map[string]string{"access-log": "off"}},
+		{opts: map[string]string{"access-log": "full"}, expected: &AccessLogPolicy{Mode: "full", SampleRate: 1}},
+		{opts: map[string]string{"access-log": "sampled"}, expected: &AccessLogPolicy{Mode: "sampled", SampleRate: 0.1}},
+		{opts: map[string]string{"access-log": "sampled", "access-log-sample-rate": "0.25"}, expected: &AccessLogPolicy{Mode: "sampled", SampleRate: 0.25}},
+		{opts: map[string]string{"access-log": "sampled", "access-log-sample-rate": "2"}, err: `invalid access-log-sample-rate "2", must be a number greater than 0 and up to 1`},
+		{opts: map[string]string{"access-log": "full", "access-log-sample-rate": "0.5"}, err: `access-log-sample-rate requires access-log to be "sampled"`},
+		{opts: map[string]string{"access-log-sample-rate": "0.5"}, err: `access-log-sample-rate requires access-log to be "sampled"`},
+		{opts: map[string]string{"access-log": "some"}, err: `invalid access-log "some", valid values are: "full", "sampled" or "off"`},
+	}
+	for _, tt := range tests {
+		policy, err := AccessLogPolicyFromOpts(tt.opts)
+		if tt.err != "" {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("opts: %v", tt.opts))
+			continue
+		}
+		c.Check(err, check.IsNil)
+		c.Check(policy, check.DeepEquals, tt.expected, check.Commentf("opts: %v", tt.opts))
+	}
+}
@@ -37,6 +37,7 @@ type apiRouter struct {
 	headers    http.Header
 	client     *http.Client
 	supIface   router.Router
+	config     router.ConfigGetter
 
 	debug        bool
 	multiCluster bool
@@ -77,7 +78,11 @@ type backendResp struct {
 type capability string
 
 var (
-	capTLS = capability("tls")
+	capTLS              = capability("tls")
+	capTLSPolicy        = capability("tls-policy")
+	capAccessLog        = capability("access-log")
+	capVersionTargeting = capability("version-targeting")
+	capMaintenance      = capability("maintenance")
 
 	allCaps = []capability{capTLS}
 )
@@ -103,6 +108,7 @@ func createRouter(routerName string, config router.ConfigGetter) (router.Router,
 		client:     net.Dial15Full60ClientNoKeepAlive,
 		debug:      debug,
 		headers:    headers,
+		config:     config,
 
 		multiCluster: multiCluster,
 	}
@@ -122,9 +128,29 @@ func (r *apiRouter) EnsureBackend(ctx context.Context, app *appTypes.App, o rout
 	path := fmt.Sprintf("backend/%s", app.Name)
 
 	o.Opts = addDefaultOpts(app, o.Opts)
+	err := r.addTLSPolicyOpts(ctx, app, o.Opts)
+	if err != nil {
+		return err
+	}
+	err = r.checkAccessLogOpts(ctx, o.Opts)
+	if err != nil {
+		return err
+	}
+	if len(o.VersionRules) > 0 {
+		err = r.requireCapability(ctx, capVersionTargeting, "version targeting rules")
+		if err != nil {
+			return err
+		}
+	}
+	if o.Maintenance != nil {
+		err = r.requireCapability(ctx, capMaintenance, "maintenance mode")
+		if err != nil {
+			return err
+		}
+	}
 
 	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(o)
+	err = json.NewEncoder(&buf).Encode(o)
 	if err != nil {
 		return err
 	}
@@ -332,6 +358,57 @@ func addDefaultOpts(app *appTypes.App, opts map[string]interface{}) map[string]i
 	return mergedOpts
 }
 
+// addTLSPolicyOpts fills the TLS policy opts not set by the app with the
+// pool defaults from the router config and refuses to send a policy to
+// backends not supporting it.
+func (r *apiRouter) addTLSPolicyOpts(ctx context.Context, app *appTypes.App, opts map[string]interface{}) error {
+	for key, value := range router.PoolTLSPolicyOpts(r.config, app.Pool) {
+		if _, ok := opts[key]; !ok {
+			opts[key] = value
+		}
+	}
+	policy, err := router.TLSPolicyFromOpts(stringOpts(opts, router.TLSPolicyOpts...))
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+	return r.requireCapability(ctx, capTLSPolicy, "TLS policy settings")
+}
+
+func (r *apiRouter) checkAccessLogOpts(ctx context.Context, opts map[string]interface{}) error {
+	policy, err := router.AccessLogPolicyFromOpts(stringOpts(opts, router.AccessLogOpt, router.AccessLogSampleRateOpt))
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+	return r.requireCapability(ctx, capAccessLog, "access logs")
+}
+
+func (r *apiRouter) requireCapability(ctx context.Context, cap capability, feature string) error {
+	supported, err := r.checkSupports(ctx, string(cap))
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return errors.Errorf("router %q does not support %s", r.routerName, feature)
+	}
+	return nil
+}
+
+func stringOpts(opts map[string]interface{}, keys ...string) map[string]string {
+	result := map[string]string{}
+	for _, key := range keys {
+		if value, ok := opts[key]; ok {
+			result[key] = fmt.Sprint(value)
+		}
+	}
+	return result
+}
+
 func (r *apiRouter) checkAllCapabilities(ctx context.Context) map[capability]bool {
 	mu := sync.Mutex{}
 	supports := map[capability]bool{}
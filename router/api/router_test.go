@@ -185,6 +185,70 @@ func (s *S) TestEnsureBackend(c *check.C) {
 	})
 }
 
+func (s *S) TestEnsureBackendTLSPolicy(c *check.C) {
+	s.apiRouter.router.HandleFunc("/support/tls-policy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	s.testRouter.config = router.ConfigGetterFromData(map[string]interface{}{
+		"tls-policy": map[string]interface{}{
+			"tls-min-version": "1.2",
+			"hsts-max-age":    300,
+			"pools": map[string]interface{}{
+				"mypool": map[string]interface{}{
+					"tls-cipher-suites": []interface{}{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				},
+			},
+		},
+	})
+	app := appTypes.App{Name: "myapp", Pool: "mypool", TeamOwner: "team03"}
+	err := s.testRouter.EnsureBackend(context.TODO(), &app, router.EnsureBackendOpts{
+		Opts: map[string]interface{}{
+			"hsts-max-age": "600",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["myapp"].opts, check.DeepEquals, map[string]interface{}{
+		"tls-min-version":        "1.2",
+		"tls-cipher-suites":      "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		"hsts-max-age":           "600",
+		"tsuru.io/app-pool":      "mypool",
+		"tsuru.io/app-teamowner": "team03",
+		"tsuru.io/app-teams":     nil,
+	})
+}
+
+func (s *S) TestEnsureBackendTLSPolicyNotSupported(c *check.C) {
+	app := appTypes.App{Name: "myapp", Pool: "mypool"}
+	err := s.testRouter.EnsureBackend(context.TODO(), &app, router.EnsureBackendOpts{
+		Opts: map[string]interface{}{
+			"tls-min-version": "1.2",
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `router "apirouter" does not support TLS policy settings`)
+	c.Assert(s.apiRouter.backends["myapp"], check.IsNil)
+}
+
+func (s *S) TestEnsureBackendTLSPolicyInvalid(c *check.C) {
+	app := appTypes.App{Name: "myapp", Pool: "mypool"}
+	err := s.testRouter.EnsureBackend(context.TODO(), &app, router.EnsureBackendOpts{
+		Opts: map[string]interface{}{
+			"hsts-preload": "true",
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `hsts-preload requires hsts-max-age to be set`)
+}
+
+func (s *S) TestEnsureBackendAccessLogNotSupported(c *check.C) {
+	app := appTypes.App{Name: "myapp", Pool: "mypool"}
+	err := s.testRouter.EnsureBackend(context.TODO(), &app, router.EnsureBackendOpts{
+		Opts: map[string]interface{}{
+			"access-log": "full",
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `router "apirouter" does not support access logs`)
+	c.Assert(s.apiRouter.backends["myapp"], check.IsNil)
+}
+
 func (s *S) TestCreateRouterSupport(c *check.C) {
 	tt := []struct {
 		features    map[string]bool
@@ -711,3 +775,23 @@ func (f *fakeRouterAPI) setHealthcheck(w http.ResponseWriter, r *http.Request) {
 func (f *fakeRouterAPI) stop() {
 	f.listener.Close()
 }
+
+func (s *S) TestEnsureBackendVersionRulesNotSupported(c *check.C) {
+	app := appTypes.App{Name: "myapp", Pool: "mypool"}
+	err := s.testRouter.EnsureBackend(context.TODO(), &app, router.EnsureBackendOpts{
+		VersionRules: []router.BackendVersionRule{
+			{Prefix: "v1.version", Header: "X-Canary", Value: "1"},
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `router "apirouter" does not support version targeting rules`)
+	c.Assert(s.apiRouter.backends["myapp"], check.IsNil)
+}
+
+func (s *S) TestEnsureBackendMaintenanceNotSupported(c *check.C) {
+	app := appTypes.App{Name: "myapp", Pool: "mypool"}
+	err := s.testRouter.EnsureBackend(context.TODO(), &app, router.EnsureBackendOpts{
+		Maintenance: &router.BackendMaintenance{Page: "<h1>Be right back</h1>"},
+	})
+	c.Assert(err, check.ErrorMatches, `router "apirouter" does not support maintenance mode`)
+	c.Assert(s.apiRouter.backends["myapp"], check.IsNil)
+}
@@ -93,11 +93,32 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 	for key, opt := range appRouter.Opts {
 		opts.Opts[key] = opt
 	}
+	prefixes := map[string]struct{}{}
 	for _, route := range routes {
 		opts.Prefixes = append(opts.Prefixes, router.BackendPrefix{
 			Prefix: route.Prefix,
 			Target: route.ExtraData,
 		})
+		prefixes[route.Prefix] = struct{}{}
+	}
+	for _, rule := range o.App.RoutingRules {
+		prefix := fmt.Sprintf("v%d.version", rule.Version)
+		if _, ok := prefixes[prefix]; !ok {
+			fmt.Fprintf(o.Writer, " ---> Skipping routing rule for version %d, which has no routable address\n", rule.Version)
+			continue
+		}
+		opts.VersionRules = append(opts.VersionRules, router.BackendVersionRule{
+			Prefix: prefix,
+			Header: rule.Header,
+			Cookie: rule.Cookie,
+			Value:  rule.Value,
+		})
+	}
+	if o.App.Maintenance != nil {
+		opts.Maintenance = &router.BackendMaintenance{
+			Page:        o.App.Maintenance.Page,
+			RedirectURL: o.App.Maintenance.RedirectURL,
+		}
 	}
 	return r.EnsureBackend(ctx, o.App, opts)
 }
@@ -8,9 +8,11 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/rebuild"
 	"github.com/tsuru/tsuru/router/routertest"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -86,3 +88,44 @@ func (s *S) TestRebuildRoutesSetsHealthcheck(c *check.C) {
 	}
 	c.Assert(routertest.FakeRouter.GetHealthcheck("my-test-app"), check.DeepEquals, expected)
 }
+
+func (s *S) TestRebuildRoutesVersionRules(c *check.C) {
+	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	addr, _ := url.Parse("http://10.0.0.1:8080")
+	provisiontest.ProvisionerInstance.MockRoutableAddresses(&a, []appTypes.RoutableAddresses{
+		{Prefix: "", Addresses: []*url.URL{addr}},
+		{Prefix: "v1.version", Addresses: []*url.URL{addr}},
+	})
+	a.RoutingRules = []appTypes.VersionRoutingRule{
+		{Version: 1, Header: "X-Canary", Value: "yes"},
+		{Version: 2, Cookie: "beta"},
+	}
+	var buf strings.Builder
+	err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{
+		App:    &a,
+		Writer: &buf,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.BackendOpts["my-test-app"].VersionRules, check.DeepEquals, []router.BackendVersionRule{
+		{Prefix: "v1.version", Header: "X-Canary", Value: "yes"},
+	})
+	c.Assert(buf.String(), check.Matches, `(?s).*Skipping routing rule for version 2, which has no routable address.*`)
+}
+
+func (s *S) TestRebuildRoutesMaintenance(c *check.C) {
+	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	a.Maintenance = &appTypes.Maintenance{RedirectURL: "https://status.example.com", StartedBy: "me"}
+	err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{App: &a})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.BackendOpts["my-test-app"].Maintenance, check.DeepEquals, &router.BackendMaintenance{
+		RedirectURL: "https://status.example.com",
+	})
+	a.Maintenance = nil
+	err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{App: &a})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.BackendOpts["my-test-app"].Maintenance, check.IsNil)
+}
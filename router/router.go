@@ -147,14 +147,33 @@ type BackendPrefix struct {
 	Target map[string]string `json:"target"` // in kubernetes cluster be like {serviceName: "", namespace: ""}
 }
 
+// BackendVersionRule routes requests carrying the header or cookie to the
+// target of Prefix instead of the default one.
+type BackendVersionRule struct {
+	Prefix string `json:"prefix"`
+	Header string `json:"header,omitempty"`
+	Cookie string `json:"cookie,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+// BackendMaintenance makes the router answer all requests to the backend
+// with a 503 page, the default one of the router when Page is empty, or with
+// a redirect to RedirectURL.
+type BackendMaintenance struct {
+	Page        string `json:"page,omitempty"`
+	RedirectURL string `json:"redirectURL,omitempty"`
+}
+
 type EnsureBackendOpts struct {
-	Opts        map[string]interface{} `json:"opts"`
-	CNames      []string               `json:"cnames"`
-	Team        string                 `json:"team,omitempty"`
-	Tags        []string               `json:"tags,omitempty"`
-	CertIssuers map[string]string      `json:"certIssuers,omitempty"`
-	Prefixes    []BackendPrefix        `json:"prefixes"`
-	Healthcheck router.HealthcheckData `json:"healthcheck"`
+	Opts         map[string]interface{} `json:"opts"`
+	CNames       []string               `json:"cnames"`
+	Team         string                 `json:"team,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	CertIssuers  map[string]string      `json:"certIssuers,omitempty"`
+	Prefixes     []BackendPrefix        `json:"prefixes"`
+	VersionRules []BackendVersionRule   `json:"versionRules,omitempty"`
+	Maintenance  *BackendMaintenance    `json:"maintenance,omitempty"`
+	Healthcheck  router.HealthcheckData `json:"healthcheck"`
 }
 
 // TLSRouter is a router that supports adding and removing
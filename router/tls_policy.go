@@ -0,0 +1,195 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TLSMinVersionOpt         = "tls-min-version"
+	TLSCipherSuitesOpt       = "tls-cipher-suites"
+	HSTSMaxAgeOpt            = "hsts-max-age"
+	HSTSIncludeSubdomainsOpt = "hsts-include-subdomains"
+	HSTSPreloadOpt           = "hsts-preload"
+
+	tlsPolicyConfigKey = "tls-policy"
+)
+
+var (
+	TLSPolicyOpts = []string{
+		TLSMinVersionOpt,
+		TLSCipherSuitesOpt,
+		HSTSMaxAgeOpt,
+		HSTSIncludeSubdomainsOpt,
+		HSTSPreloadOpt,
+	}
+
+	tlsVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+	// hstsPreloadMinMaxAge is the minimum max-age accepted by browsers preload
+	// lists, one year.
+	hstsPreloadMinMaxAge = 31536000
+)
+
+// TLSPolicy holds the TLS settings a router must enforce on the frontends of
+// an app. Routers unable to enforce a policy must refuse it instead of
+// silently ignoring it.
+type TLSPolicy struct {
+	MinVersion   string      `json:"minVersion,omitempty"`
+	CipherSuites []string    `json:"cipherSuites,omitempty"`
+	HSTS         *HSTSPolicy `json:"hsts,omitempty"`
+}
+
+type HSTSPolicy struct {
+	MaxAge            int  `json:"maxAge"`
+	IncludeSubdomains bool `json:"includeSubdomains"`
+	Preload           bool `json:"preload"`
+}
+
+// HasTLSPolicyOpts reports whether any TLS policy opt is set.
+func HasTLSPolicyOpts(opts map[string]string) bool {
+	for _, key := range TLSPolicyOpts {
+		if _, ok := opts[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TLSPolicyFromOpts parses and validates the TLS policy opts of an app
+// router. It returns nil when no TLS policy opt is set.
+func TLSPolicyFromOpts(opts map[string]string) (*TLSPolicy, error) {
+	if !HasTLSPolicyOpts(opts) {
+		return nil, nil
+	}
+	policy := &TLSPolicy{}
+	if v, ok := opts[TLSMinVersionOpt]; ok {
+		v = strings.TrimSpace(v)
+		if !validTLSVersion(v) {
+			return nil, errors.Errorf("invalid %s %q, valid versions are: %s", TLSMinVersionOpt, v, strings.Join(tlsVersions, ", "))
+		}
+		policy.MinVersion = v
+	}
+	if v, ok := opts[TLSCipherSuitesOpt]; ok {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !validCipherSuite(name) {
+				return nil, errors.Errorf("invalid %s: unknown cipher suite %q", TLSCipherSuitesOpt, name)
+			}
+			policy.CipherSuites = append(policy.CipherSuites, name)
+		}
+		if policy.MinVersion == "1.3" && len(policy.CipherSuites) > 0 {
+			return nil, errors.Errorf("%s cannot be used with %s 1.3, TLS 1.3 cipher suites are not configurable", TLSCipherSuitesOpt, TLSMinVersionOpt)
+		}
+	}
+	hsts, err := hstsPolicyFromOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+	policy.HSTS = hsts
+	return policy, nil
+}
+
+func hstsPolicyFromOpts(opts map[string]string) (*HSTSPolicy, error) {
+	maxAge, hasMaxAge := opts[HSTSMaxAgeOpt]
+	if !hasMaxAge {
+		for _, key := range []string{HSTSIncludeSubdomainsOpt, HSTSPreloadOpt} {
+			if _, ok := opts[key]; ok {
+				return nil, errors.Errorf("%s requires %s to be set", key, HSTSMaxAgeOpt)
+			}
+		}
+		return nil, nil
+	}
+	hsts := &HSTSPolicy{}
+	var err error
+	hsts.MaxAge, err = strconv.Atoi(strings.TrimSpace(maxAge))
+	if err != nil || hsts.MaxAge < 0 {
+		return nil, errors.Errorf("invalid %s %q, must be a non negative number of seconds", HSTSMaxAgeOpt, maxAge)
+	}
+	if hsts.IncludeSubdomains, err = boolOpt(opts, HSTSIncludeSubdomainsOpt); err != nil {
+		return nil, err
+	}
+	if hsts.Preload, err = boolOpt(opts, HSTSPreloadOpt); err != nil {
+		return nil, err
+	}
+	if hsts.Preload && (!hsts.IncludeSubdomains || hsts.MaxAge < hstsPreloadMinMaxAge) {
+		return nil, errors.Errorf("%s requires %s and %s of at least %d", HSTSPreloadOpt, HSTSIncludeSubdomainsOpt, HSTSMaxAgeOpt, hstsPreloadMinMaxAge)
+	}
+	return hsts, nil
+}
+
+func boolOpt(opts map[string]string, key string) (bool, error) {
+	v, ok := opts[key]
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return false, errors.Errorf("invalid %s %q, must be a boolean", key, v)
+	}
+	return b, nil
+}
+
+func validTLSVersion(v string) bool {
+	for _, version := range tlsVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func validCipherSuite(name string) bool {
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			if suite.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PoolTLSPolicyOpts returns the default TLS policy opts configured in a
+// router for apps in the given pool. Settings under tls-policy apply to every
+// pool and may be overridden by settings under tls-policy:pools:<pool>.
+func PoolTLSPolicyOpts(config ConfigGetter, pool string) map[string]string {
+	opts := map[string]string{}
+	if config == nil {
+		return opts
+	}
+	prefixes := []string{tlsPolicyConfigKey}
+	if pool != "" {
+		prefixes = append(prefixes, fmt.Sprintf("%s:pools:%s", tlsPolicyConfigKey, pool))
+	}
+	for _, prefix := range prefixes {
+		for _, key := range TLSPolicyOpts {
+			value, err := config.Get(prefix + ":" + key)
+			if err != nil || value == nil {
+				continue
+			}
+			switch v := value.(type) {
+			case []interface{}:
+				parts := make([]string, len(v))
+				for i := range v {
+					parts[i] = fmt.Sprint(v[i])
+				}
+				opts[key] = strings.Join(parts, ",")
+			default:
+				opts[key] = fmt.Sprint(v)
+			}
+		}
+	}
+	return opts
+}
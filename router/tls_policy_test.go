@@ -0,0 +1,94 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestTLSPolicyFromOpts(c *check.C) {
+	policy, err := TLSPolicyFromOpts(map[string]string{"other": "x"})
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, check.IsNil)
+	policy, err = TLSPolicyFromOpts(map[string]string{
+		"tls-min-version":         "1.2",
+		"tls-cipher-suites":       "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		"hsts-max-age":            "31536000",
+		"hsts-include-subdomains": "true",
+		"hsts-preload":            "true",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, check.DeepEquals, &TLSPolicy{
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+		HSTS:         &HSTSPolicy{MaxAge: 31536000, IncludeSubdomains: true, Preload: true},
+	})
+}
+
+func (s *S) TestTLSPolicyFromOptsInvalid(c *check.C) {
+	tests := []struct {
+		opts map[string]string
+		err  string
+	}{
+		{
+			opts: map[string]string{"tls-min-version": "1.4"},
+			err:  `invalid tls-min-version "1.4", valid versions are: 1.0, 1.1, 1.2, 1.3`,
+		},
+		{
+			opts: map[string]string{"tls-cipher-suites": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,MY_CIPHER"},
+			err:  `invalid tls-cipher-suites: unknown cipher suite "MY_CIPHER"`,
+		},
+		{
+			opts: map[string]string{"tls-min-version": "1.3", "tls-cipher-suites": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			err:  `tls-cipher-suites cannot be used with tls-min-version 1.3, TLS 1.3 cipher suites are not configurable`,
+		},
+		{
+			opts: map[string]string{"hsts-max-age": "-1"},
+			err:  `invalid hsts-max-age "-1", must be a non negative number of seconds`,
+		},
+		{
+			opts: map[string]string{"hsts-include-subdomains": "true"},
+			err:  `hsts-include-subdomains requires hsts-max-age to be set`,
+		},
+		{
+			opts: map[string]string{"hsts-max-age": "300", "hsts-include-subdomains": "yes"},
+			err:  `invalid hsts-include-subdomains "yes", must be a boolean`,
+		},
+		{
+			opts: map[string]string{"hsts-max-age": "300", "hsts-include-subdomains": "true", "hsts-preload": "true"},
+			err:  `hsts-preload requires hsts-include-subdomains and hsts-max-age of at least 31536000`,
+		},
+	}
+	for _, tt := range tests {
+		policy, err := TLSPolicyFromOpts(tt.opts)
+		c.Check(err, check.ErrorMatches, tt.err, check.Commentf("opts: %v", tt.opts))
+		c.Check(policy, check.IsNil)
+	}
+}
+
+func (s *S) TestPoolTLSPolicyOpts(c *check.C) {
+	config := ConfigGetterFromData(map[string]interface{}{
+		"tls-policy": map[string]interface{}{
+			"tls-min-version": "1.1",
+			"hsts-max-age":    300,
+			"pools": map[string]interface{}{
+				"secure": map[string]interface{}{
+					"tls-min-version":   "1.2",
+					"tls-cipher-suites": []interface{}{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				},
+			},
+		},
+	})
+	c.Assert(PoolTLSPolicyOpts(config, "other"), check.DeepEquals, map[string]string{
+		"tls-min-version": "1.1",
+		"hsts-max-age":    "300",
+	})
+	c.Assert(PoolTLSPolicyOpts(config, "secure"), check.DeepEquals, map[string]string{
+		"tls-min-version":   "1.2",
+		"tls-cipher-suites": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		"hsts-max-age":      "300",
+	})
+	c.Assert(PoolTLSPolicyOpts(nil, "secure"), check.DeepEquals, map[string]string{})
+}
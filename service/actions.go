@@ -234,6 +234,7 @@ var notifyUpdateServiceInstance = action.Action{
 
 type bindAppPipelineArgs struct {
 	app             *appTypes.App
+	version         int
 	writer          io.Writer
 	serviceInstance *ServiceInstance
 	params          BindAppParameters
@@ -425,6 +426,7 @@ var setBoundEnvsAction = &action.Action{
 			Envs:          envs,
 			ShouldRestart: args.shouldRestart,
 			Writer:        args.writer,
+			Version:       args.version,
 		}
 		return addArgs, servicemanager.App.AddInstance(ctx.Context, args.app, addArgs)
 	},
@@ -229,9 +229,17 @@ func (si *ServiceInstance) updateData(ctx context.Context, update mongoBSON.M) e
 
 // BindApp makes the bind between the service instance and an app.
 func (si *ServiceInstance) BindApp(ctx context.Context, app *appTypes.App, params BindAppParameters, shouldRestart bool, writer io.Writer, evt *event.Event, requestID string) error {
+	return si.BindAppVersion(ctx, app, 0, params, shouldRestart, writer, evt, requestID)
+}
+
+// BindAppVersion makes the bind between the service instance and an app,
+// injecting the envs only in the units of the given version. They're applied
+// to all versions once promoted, version 0 binds to all versions right away.
+func (si *ServiceInstance) BindAppVersion(ctx context.Context, app *appTypes.App, version int, params BindAppParameters, shouldRestart bool, writer io.Writer, evt *event.Event, requestID string) error {
 	args := bindAppPipelineArgs{
 		serviceInstance: si,
 		app:             app,
+		version:         version,
 		writer:          writer,
 		shouldRestart:   shouldRestart,
 		params:          params,
@@ -96,6 +96,11 @@ func (m *MockService) ResetPlan() {
 	m.Plan.OnList = nil
 	m.Plan.OnDefaultPlan = nil
 	m.Plan.OnRemove = nil
+	m.Plan.OnSetDefault = nil
+	m.Plan.OnUnsetDefault = nil
+	m.Plan.OnListDefaults = nil
+	m.Plan.OnDefaultPlanFor = nil
+	m.Plan.OnUsage = nil
 }
 
 func (m *MockService) ResetPlatform() {
@@ -121,6 +126,10 @@ func (m *MockService) ResetTeam() {
 	m.Team.OnList = nil
 	m.Team.OnRemove = nil
 	m.Team.OnFindByNames = nil
+	m.Team.OnSetEnvs = nil
+	m.Team.OnUnsetEnvs = nil
+	m.Team.OnAddQuotaGrant = nil
+	m.Team.OnRemoveQuotaGrant = nil
 }
 
 func (m *MockService) ResetUserQuota() {
@@ -28,6 +28,7 @@ type cluster struct {
 	ClientKey   []byte            `bson:",omitempty"`
 	Pools       []string          `bson:",omitempty"`
 	CustomData  map[string]string `bson:",omitempty"`
+	Labels      map[string]string `bson:",omitempty"`
 	Local       bool              `bson:",omitempty"`
 	Default     bool
 	KubeConfig  *provision.KubeConfig `bson:",omitempty"`
@@ -25,6 +25,11 @@ type planOnMongoDB struct {
 	CPUBurst *app.CPUBurst
 	Default  bool
 	Override *app.PlanOverride `bson:"-"`
+	Requests *app.PlanRequests
+
+	HourlyCost float64 `bson:",omitempty"`
+
+	SchedulingConstraints map[string]string `bson:",omitempty"`
 }
 
 func (s *PlanStorage) Insert(ctx context.Context, p app.Plan) error {
@@ -153,3 +158,108 @@ func (s *PlanStorage) Delete(ctx context.Context, p app.Plan) error {
 
 	return nil
 }
+
+type planDefaultOnMongoDB struct {
+	Plan string
+	Pool string
+	Team string
+}
+
+func planDefaultQuery(d app.PlanDefault) mongoBSON.M {
+	return mongoBSON.M{"pool": d.Pool, "team": d.Team}
+}
+
+func (s *PlanStorage) SetDefault(ctx context.Context, d app.PlanDefault) error {
+	collection, err := storagev2.PlanDefaultsCollection()
+	if err != nil {
+		return err
+	}
+
+	query := planDefaultQuery(d)
+	span := newMongoDBSpan(ctx, mongoSpanUpsert, collection.Name())
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	_, err = collection.ReplaceOne(ctx, query, planDefaultOnMongoDB(d), options.Replace().SetUpsert(true))
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *PlanStorage) UnsetDefault(ctx context.Context, d app.PlanDefault) error {
+	collection, err := storagev2.PlanDefaultsCollection()
+	if err != nil {
+		return err
+	}
+
+	query := planDefaultQuery(d)
+	if d.Plan != "" {
+		query["plan"] = d.Plan
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	result, err := collection.DeleteOne(ctx, query)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return app.ErrPlanDefaultNotFound
+	}
+	return nil
+}
+
+func (s *PlanStorage) FindDefaults(ctx context.Context) ([]app.PlanDefault, error) {
+	collection, err := storagev2.PlanDefaultsCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	cursor, err := collection.Find(ctx, mongoBSON.M{}, options.Find().SetSort(mongoBSON.D{{Key: "pool", Value: 1}, {Key: "team", Value: 1}}))
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	defaults := []planDefaultOnMongoDB{}
+	err = cursor.All(ctx, &defaults)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := make([]app.PlanDefault, len(defaults))
+	for i, d := range defaults {
+		result[i] = app.PlanDefault(d)
+	}
+	return result, nil
+}
+
+func (s *PlanStorage) FindDefaultFor(ctx context.Context, d app.PlanDefault) (*app.PlanDefault, error) {
+	collection, err := storagev2.PlanDefaultsCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	query := planDefaultQuery(d)
+	span := newMongoDBSpan(ctx, mongoSpanFindOne, collection.Name())
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	var result planDefaultOnMongoDB
+	err = collection.FindOne(ctx, query).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, app.ErrPlanDefaultNotFound
+		}
+		span.SetError(err)
+		return nil, err
+	}
+	planDefault := app.PlanDefault(result)
+	return &planDefault, nil
+}
@@ -12,6 +12,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/bind"
 	"github.com/tsuru/tsuru/types/quota"
 )
 
@@ -24,6 +25,12 @@ type team struct {
 	CreatingUser string
 	Tags         []string
 	Quota        quota.Quota
+	Env          []bind.EnvVar
+	QuotaGrants  []auth.QuotaGrant
+
+	Notifications    auth.TeamNotifications
+	IsolationProfile string
+	Budget           *quota.Budget
 }
 
 func (s *TeamStorage) Insert(ctx context.Context, t auth.Team) error {
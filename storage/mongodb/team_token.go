@@ -26,6 +26,10 @@ type teamToken struct {
 	CreatorEmail string    `bson:"creator_email"`
 	Team         string
 	Roles        []auth.RoleInstance `bson:",omitempty"`
+
+	PreviousToken           string    `bson:"previous_token,omitempty"`
+	PreviousTokenExpiresAt  time.Time `bson:"previous_token_expires_at,omitempty"`
+	PreviousTokenLastAccess time.Time `bson:"previous_token_last_access,omitempty"`
 }
 
 var _ auth.TeamTokenStorage = &teamTokenStorage{}
@@ -64,6 +68,10 @@ func (s *teamTokenStorage) FindByToken(ctx context.Context, token string) (*auth
 	return s.findOne(ctx, mongoBSON.M{"token": token})
 }
 
+func (s *teamTokenStorage) FindByPreviousToken(ctx context.Context, token string) (*auth.TeamToken, error) {
+	return s.findOne(ctx, mongoBSON.M{"previous_token": token})
+}
+
 func (s *teamTokenStorage) FindByTokenID(ctx context.Context, tokenID string) (*auth.TeamToken, error) {
 	return s.findOne(ctx, mongoBSON.M{"token_id": tokenID})
 }
@@ -76,6 +84,10 @@ func (s *teamTokenStorage) FindByTeams(ctx context.Context, teamNames []string)
 	return s.findByQuery(ctx, query)
 }
 
+func (s *teamTokenStorage) FindByRole(ctx context.Context, roleName string) ([]auth.TeamToken, error) {
+	return s.findByQuery(ctx, mongoBSON.M{"roles.name": roleName})
+}
+
 func (s *teamTokenStorage) findByQuery(ctx context.Context, query mongoBSON.M) ([]auth.TeamToken, error) {
 
 	collection, err := storagev2.TeamTokensCollection()
@@ -105,6 +117,8 @@ func (s *teamTokenStorage) findByQuery(ctx context.Context, query mongoBSON.M) (
 	return authTeams, nil
 }
 
+// UpdateLastAccess sets the last access of the team token, also setting the
+// last access of the previous value when it's the one used.
 func (s *teamTokenStorage) UpdateLastAccess(ctx context.Context, token string) error {
 	collection, err := storagev2.TeamTokensCollection()
 	if err != nil {
@@ -114,11 +128,15 @@ func (s *teamTokenStorage) UpdateLastAccess(ctx context.Context, token string) e
 	span := newMongoDBSpan(ctx, mongoSpanUpdate, collection.Name())
 	defer span.Finish()
 
-	result, err := collection.UpdateOne(ctx, mongoBSON.M{
-		"token": token,
-	}, mongoBSON.M{
-		"$set": mongoBSON.M{"last_access": time.Now().UTC()},
+	now := time.Now().UTC()
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"token": token}, mongoBSON.M{
+		"$set": mongoBSON.M{"last_access": now},
 	})
+	if err == nil && result.MatchedCount == 0 {
+		result, err = collection.UpdateOne(ctx, mongoBSON.M{"previous_token": token}, mongoBSON.M{
+			"$set": mongoBSON.M{"last_access": now, "previous_token_last_access": now},
+		})
+	}
 	if err == mongo.ErrNoDocuments {
 		err = auth.ErrTeamTokenNotFound
 	}
@@ -102,6 +102,7 @@ func (*volumeStorage) ListByFilter(ctx context.Context, f *volume.Filter) ([]vol
 
 		if len(f.Pools) > 0 {
 			orQueries = append(orQueries, mongoBSON.M{"pool": mongoBSON.M{"$in": f.Pools}})
+			orQueries = append(orQueries, mongoBSON.M{"sharedpools": mongoBSON.M{"$in": f.Pools}})
 		}
 
 		if len(f.Teams) > 0 {
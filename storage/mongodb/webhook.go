@@ -7,15 +7,19 @@ package mongodb
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/types/event"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type webhookStorage struct{}
 
+var globRegex = mongoBSON.M{"$regex": `[*?[]`}
+
 var _ event.WebhookStorage = &webhookStorage{}
 
 func (s *webhookStorage) Insert(ctx context.Context, w event.Webhook) error {
@@ -87,9 +91,11 @@ func (s *webhookStorage) FindByEvent(ctx context.Context, f event.WebhookEventFi
 		}
 		f.KindNames = append(f.KindNames, parts...)
 	}
+	// Target filters with globs are also returned, it's up to the caller to
+	// match them against the event.
 	andBlock := []mongoBSON.M{
-		{"$or": []mongoBSON.M{{"eventfilter.targettypes": mongoBSON.M{"$in": f.TargetTypes}}, {"eventfilter.targettypes": []string{}}}},
-		{"$or": []mongoBSON.M{{"eventfilter.targetvalues": mongoBSON.M{"$in": f.TargetValues}}, {"eventfilter.targetvalues": []string{}}}},
+		{"$or": []mongoBSON.M{{"eventfilter.targettypes": mongoBSON.M{"$in": f.TargetTypes}}, {"eventfilter.targettypes": []string{}}, {"eventfilter.targettypes": globRegex}}},
+		{"$or": []mongoBSON.M{{"eventfilter.targetvalues": mongoBSON.M{"$in": f.TargetValues}}, {"eventfilter.targetvalues": []string{}}, {"eventfilter.targetvalues": globRegex}}},
 		{"$or": []mongoBSON.M{{"eventfilter.kindtypes": mongoBSON.M{"$in": f.KindTypes}}, {"eventfilter.kindtypes": []string{}}}},
 		{"$or": []mongoBSON.M{{"eventfilter.kindnames": mongoBSON.M{"$in": f.KindNames}}, {"eventfilter.kindnames": []string{}}}},
 	}
@@ -137,5 +143,61 @@ func (s *webhookStorage) Delete(ctx context.Context, name string) error {
 		return event.ErrWebhookNotFound
 	}
 
-	return nil
+	deliveries, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = deliveries.DeleteMany(ctx, mongoBSON.M{"webhook": name})
+	return err
+}
+
+func (s *webhookStorage) SaveDelivery(ctx context.Context, d event.WebhookDelivery) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": d.ID}, d, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (s *webhookStorage) FindDeliveries(ctx context.Context, name string, limit int) ([]event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"webhook": name}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []event.WebhookDelivery
+	err = cursor.All(ctx, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *webhookStorage) ClaimDeliveryRetry(ctx context.Context, now, claimUntil time.Time) (*event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var d event.WebhookDelivery
+	err = collection.FindOneAndUpdate(ctx, mongoBSON.M{
+		"status":      event.WebhookDeliveryRetrying,
+		"nextattempt": mongoBSON.M{"$lte": now},
+	}, mongoBSON.M{
+		"$set": mongoBSON.M{"nextattempt": claimUntil},
+	}, options.FindOneAndUpdate().SetSort(mongoBSON.D{{Key: "nextattempt", Value: 1}}).SetReturnDocument(options.After)).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
 }
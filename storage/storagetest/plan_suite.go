@@ -144,3 +144,41 @@ func (s *PlanSuite) TestDeletePlanNotFound(c *check.C) {
 	err := s.PlanStorage.Delete(context.TODO(), app.Plan{Name: "myteam"})
 	c.Assert(err, check.Equals, app.ErrPlanNotFound)
 }
+
+func (s *PlanSuite) TestSetDefaultForPoolAndTeam(c *check.C) {
+	err := s.PlanStorage.SetDefault(context.TODO(), app.PlanDefault{Plan: "plan1", Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = s.PlanStorage.SetDefault(context.TODO(), app.PlanDefault{Plan: "plan2", Team: "team1"})
+	c.Assert(err, check.IsNil)
+	err = s.PlanStorage.SetDefault(context.TODO(), app.PlanDefault{Plan: "plan3", Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	d, err := s.PlanStorage.FindDefaultFor(context.TODO(), app.PlanDefault{Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(*d, check.Equals, app.PlanDefault{Plan: "plan3", Pool: "pool1"})
+	d, err = s.PlanStorage.FindDefaultFor(context.TODO(), app.PlanDefault{Team: "team1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(*d, check.Equals, app.PlanDefault{Plan: "plan2", Team: "team1"})
+	defaults, err := s.PlanStorage.FindDefaults(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(defaults, check.DeepEquals, []app.PlanDefault{
+		{Plan: "plan2", Team: "team1"},
+		{Plan: "plan3", Pool: "pool1"},
+	})
+}
+
+func (s *PlanSuite) TestFindDefaultForNotFound(c *check.C) {
+	d, err := s.PlanStorage.FindDefaultFor(context.TODO(), app.PlanDefault{Pool: "pool1"})
+	c.Assert(err, check.Equals, app.ErrPlanDefaultNotFound)
+	c.Assert(d, check.IsNil)
+}
+
+func (s *PlanSuite) TestUnsetDefault(c *check.C) {
+	err := s.PlanStorage.SetDefault(context.TODO(), app.PlanDefault{Plan: "plan1", Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = s.PlanStorage.UnsetDefault(context.TODO(), app.PlanDefault{Plan: "plan2", Pool: "pool1"})
+	c.Assert(err, check.Equals, app.ErrPlanDefaultNotFound)
+	err = s.PlanStorage.UnsetDefault(context.TODO(), app.PlanDefault{Plan: "plan1", Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	_, err = s.PlanStorage.FindDefaultFor(context.TODO(), app.PlanDefault{Pool: "pool1"})
+	c.Assert(err, check.Equals, app.ErrPlanDefaultNotFound)
+}
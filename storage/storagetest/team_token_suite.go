@@ -62,6 +62,29 @@ func (s *TeamTokenSuite) TestFindTeamTokenByTokenNotFound(c *check.C) {
 	c.Assert(token, check.IsNil)
 }
 
+func (s *TeamTokenSuite) TestFindTeamTokenByPreviousToken(c *check.C) {
+	t := auth.TeamToken{Token: "5678", PreviousToken: "1234", PreviousTokenExpiresAt: time.Now().Add(time.Hour)}
+	err := s.TeamTokenStorage.Insert(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	token, err := s.TeamTokenStorage.FindByPreviousToken(context.TODO(), "1234")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Equals, "5678")
+	c.Assert(token.PreviousToken, check.Equals, "1234")
+	_, err = s.TeamTokenStorage.FindByPreviousToken(context.TODO(), "5678")
+	c.Assert(err, check.Equals, auth.ErrTeamTokenNotFound)
+	err = s.TeamTokenStorage.UpdateLastAccess(context.TODO(), "5678")
+	c.Assert(err, check.IsNil)
+	token, err = s.TeamTokenStorage.FindByToken(context.TODO(), "5678")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.LastAccess.IsZero(), check.Equals, false)
+	c.Assert(token.PreviousTokenLastAccess.IsZero(), check.Equals, true)
+	err = s.TeamTokenStorage.UpdateLastAccess(context.TODO(), "1234")
+	c.Assert(err, check.IsNil)
+	token, err = s.TeamTokenStorage.FindByToken(context.TODO(), "5678")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.PreviousTokenLastAccess.IsZero(), check.Equals, false)
+}
+
 func (s *TeamTokenSuite) TestFindTeamTokensByTeams(c *check.C) {
 	err := s.TeamTokenStorage.Insert(context.TODO(), auth.TeamToken{Token: "123", TokenID: "1", Team: "team1"})
 	c.Assert(err, check.IsNil)
@@ -100,6 +123,24 @@ func (s *TeamTokenSuite) TestFindTeamTokensByTeams(c *check.C) {
 	c.Assert(values, check.DeepEquals, []string{"123", "456", "789"})
 }
 
+func (s *TeamTokenSuite) TestFindTeamTokensByRole(c *check.C) {
+	err := s.TeamTokenStorage.Insert(context.TODO(), auth.TeamToken{Token: "123", TokenID: "1", Team: "team1", Roles: []auth.RoleInstance{{Name: "deployer", ContextValue: "app1"}}})
+	c.Assert(err, check.IsNil)
+	err = s.TeamTokenStorage.Insert(context.TODO(), auth.TeamToken{Token: "456", TokenID: "4", Team: "team2", Roles: []auth.RoleInstance{{Name: "other"}, {Name: "deployer", ContextValue: "app2"}}})
+	c.Assert(err, check.IsNil)
+	err = s.TeamTokenStorage.Insert(context.TODO(), auth.TeamToken{Token: "789", TokenID: "7", Team: "team1", Roles: []auth.RoleInstance{{Name: "other"}}})
+	c.Assert(err, check.IsNil)
+	tokens, err := s.TeamTokenStorage.FindByRole(context.TODO(), "deployer")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 2)
+	values := []string{tokens[0].TokenID, tokens[1].TokenID}
+	sort.Strings(values)
+	c.Assert(values, check.DeepEquals, []string{"1", "4"})
+	tokens, err = s.TeamTokenStorage.FindByRole(context.TODO(), "unknown")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+}
+
 func (s *TeamTokenSuite) TestFindTeamTokenByTeamsNotFound(c *check.C) {
 	t1 := auth.TeamToken{Token: "123", Team: "team1"}
 	err := s.TeamTokenStorage.Insert(context.TODO(), t1)
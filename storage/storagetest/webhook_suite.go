@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	eventTypes "github.com/tsuru/tsuru/types/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	check "gopkg.in/check.v1"
 )
 
@@ -43,6 +45,7 @@ func (s *WebhookSuite) TestInsertWebhook(c *check.C) {
 		EventFilter: eventTypes.WebhookEventFilter{
 			KindTypes:    []string{},
 			KindNames:    []string{},
+			Teams:        []string{},
 			TargetTypes:  []string{"app"},
 			TargetValues: []string{"myapp"},
 		},
@@ -225,6 +228,7 @@ func (s *WebhookSuite) TestUpdate(c *check.C) {
 		EventFilter: eventTypes.WebhookEventFilter{
 			KindTypes:    []string{},
 			KindNames:    []string{},
+			Teams:        []string{},
 			TargetTypes:  []string{},
 			TargetValues: []string{},
 		},
@@ -240,3 +244,86 @@ func (s *WebhookSuite) TestFindByNameNotFound(c *check.C) {
 	_, err := s.WebhookStorage.FindByName(context.TODO(), "wh1")
 	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
 }
+
+func (s *WebhookSuite) TestFindByEventTargetGlob(c *check.C) {
+	filters := []eventTypes.WebhookEventFilter{
+		{TargetTypes: []string{"app"}, TargetValues: []string{"myapp-*"}},
+		{TargetTypes: []string{"app"}, TargetValues: []string{"otherapp"}},
+		{TargetTypes: []string{"*"}},
+	}
+	for i, f := range filters {
+		err := s.WebhookStorage.Insert(context.TODO(), eventTypes.Webhook{Name: fmt.Sprintf("wh-%d", i), EventFilter: f})
+		c.Assert(err, check.IsNil)
+	}
+	hooks, err := s.WebhookStorage.FindByEvent(context.TODO(), eventTypes.WebhookEventFilter{
+		TargetTypes:  []string{"job"},
+		TargetValues: []string{"myjob"},
+	}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhooksNames(hooks), check.DeepEquals, []string{"wh-0", "wh-2"})
+}
+
+func (s *WebhookSuite) TestDeliveries(c *check.C) {
+	err := s.WebhookStorage.Insert(context.TODO(), eventTypes.Webhook{Name: "wh1"})
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var ids []primitive.ObjectID
+	for i := 0; i < 3; i++ {
+		d := eventTypes.WebhookDelivery{
+			ID:          primitive.NewObjectID(),
+			Webhook:     "wh1",
+			EventID:     fmt.Sprintf("evt-%d", i),
+			Status:      eventTypes.WebhookDeliverySuccess,
+			Attempts:    1,
+			StatusCode:  http.StatusOK,
+			CreatedAt:   now,
+			LastAttempt: now,
+			ExpireAt:    now.Add(time.Hour),
+		}
+		err = s.WebhookStorage.SaveDelivery(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+		ids = append(ids, d.ID)
+	}
+	deliveries, err := s.WebhookStorage.FindDeliveries(context.TODO(), "wh1", 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 2)
+	c.Assert(deliveries[0].ID, check.Equals, ids[2])
+	c.Assert(deliveries[1].ID, check.Equals, ids[1])
+	deliveries[0].Status = eventTypes.WebhookDeliveryFailed
+	err = s.WebhookStorage.SaveDelivery(context.TODO(), deliveries[0])
+	c.Assert(err, check.IsNil)
+	deliveries, err = s.WebhookStorage.FindDeliveries(context.TODO(), "wh1", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 3)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryFailed)
+	err = s.WebhookStorage.Delete(context.TODO(), "wh1")
+	c.Assert(err, check.IsNil)
+	deliveries, err = s.WebhookStorage.FindDeliveries(context.TODO(), "wh1", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 0)
+}
+
+func (s *WebhookSuite) TestClaimDeliveryRetry(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	d := eventTypes.WebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		Webhook:     "wh1",
+		EventID:     "evt-1",
+		Status:      eventTypes.WebhookDeliveryRetrying,
+		Attempts:    1,
+		NextAttempt: now.Add(time.Minute),
+	}
+	err := s.WebhookStorage.SaveDelivery(context.TODO(), d)
+	c.Assert(err, check.IsNil)
+	claimed, err := s.WebhookStorage.ClaimDeliveryRetry(context.TODO(), now, now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.IsNil)
+	claimed, err = s.WebhookStorage.ClaimDeliveryRetry(context.TODO(), now.Add(time.Minute), now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.NotNil)
+	c.Assert(claimed.ID, check.Equals, d.ID)
+	c.Assert(claimed.NextAttempt.Equal(now.Add(time.Hour)), check.Equals, true)
+	claimed, err = s.WebhookStorage.ClaimDeliveryRetry(context.TODO(), now.Add(time.Minute), now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.IsNil)
+}
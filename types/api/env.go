@@ -17,6 +17,7 @@ type Envs struct {
 	NoRestart   bool
 	Private     bool
 	PruneUnused bool `json:"pruneUnused"`
+	Version     int  `json:"version,omitempty"`
 }
 
 type Env struct {
@@ -26,3 +27,14 @@ type Env struct {
 	Private   *bool  `json:"private,omitempty"`
 	ManagedBy string `json:"-" bson:"managedBy"`
 }
+
+// EnvsImport represents a full set of environment variables to be imported
+// in an app, either as a list or as the contents of a dotenv file.
+type EnvsImport struct {
+	Envs      []Env  `json:"envs"`
+	Dotenv    string `json:"dotenv"`
+	Mode      string `json:"mode"`
+	Private   bool   `json:"private"`
+	DryRun    bool   `json:"dryRun"`
+	NoRestart bool   `json:"noRestart"`
+}
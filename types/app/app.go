@@ -22,6 +22,7 @@ import (
 type App struct {
 	Env             map[string]bind.EnvVar
 	ServiceEnvs     []bind.ServiceEnvVar
+	TeamEnvs        []bind.EnvVar
 	Platform        string `bson:"framework"`
 	PlatformVersion string
 	Name            string
@@ -44,12 +45,105 @@ type App struct {
 	Metadata        Metadata
 	Processes       []Process
 
+	// DeletionProtection prevents the app from being removed until it's
+	// explicitly disabled.
+	DeletionProtection bool
+
+	// AutoRedeploy rebuilds the app automatically when the image of its
+	// platform is updated.
+	AutoRedeploy bool
+
+	// RequireApproval holds deploys to the app until they're approved by a
+	// user other than the one who started them.
+	RequireApproval bool
+
+	// EnvSchema describes the environment variables expected by the app.
+	// It's checked whenever envs change and before every deploy.
+	EnvSchema []EnvVarSchema
+
+	// SchedulingConstraints are labels the pool and cluster of the app must
+	// have, in addition to the ones required by its plan.
+	SchedulingConstraints map[string]string
+
+	// VersionEnvs are set only in the units of a single version, allowing
+	// changes to be tested in a canary before promoting them to all units.
+	VersionEnvs []bind.VersionEnvVar
+
+	// RoutingRules send requests matching a header or cookie to a specific
+	// version, in routers supporting version targeting.
+	RoutingRules []VersionRoutingRule
+
+	// Spread distributes the units of the app across zones or nodes,
+	// overriding the spread of its pool.
+	Spread *Spread
+
+	// SecurityContext hardens the units of the app, overriding the fields
+	// of the security context of its pool.
+	SecurityContext *SecurityContext
+
+	// ServiceAccountAnnotations bind the service account of the app to a
+	// cloud IAM identity, allowing it to access cloud APIs without static
+	// credentials.
+	ServiceAccountAnnotations map[string]string
+
+	// InitContainers run in the units of the app before its processes.
+	InitContainers []InitContainer
+
+	// Files are mounted in the units of the app, replacing the files of the
+	// previous deploy or restart at once.
+	Files []FileMount
+
+	// Secrets are exposed to the units of the app as environment variables,
+	// kept sealed by tsuru and never set in plain text in their specs.
+	Secrets []AppSecret
+
+	// Archive is set while the app is archived: stopped, without router
+	// backends and refusing deploys until it's unarchived.
+	Archive *AppArchive
+
+	// Maintenance is set while the routers of the app answer requests with
+	// a maintenance page instead of sending them to its units.
+	Maintenance *Maintenance
+
+	// Budget limits the projected monthly cost of the units of the app.
+	Budget *quota.Budget
+
+	// LogSinks are the external destinations the logs of the app are
+	// forwarded to.
+	LogSinks []LogSink
+
 	// UUID is a v4 UUID lazily generated on the first call to GetUUID()
 	UUID string
 
 	Quota quota.Quota
 }
 
+const (
+	EnvTypeString = "string"
+	EnvTypeInt    = "int"
+	EnvTypeFloat  = "float"
+	EnvTypeBool   = "bool"
+	EnvTypeURL    = "url"
+)
+
+// EnvVarSchema is the rule an environment variable of an app must follow.
+// Pattern is a regular expression the whole value must match.
+type EnvVarSchema struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+}
+
+// VersionRoutingRule targets requests carrying Header or Cookie to Version.
+// An empty Value matches any request carrying them.
+type VersionRoutingRule struct {
+	Version int    `json:"version"`
+	Header  string `json:"header,omitempty"`
+	Cookie  string `json:"cookie,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
 var CertIssuerDotReplacement = "_dot_"
 
 type CertIssuers map[string]string
@@ -99,6 +193,8 @@ type AppService interface {
 
 	AddInstance(ctx context.Context, app *App, addArgs bind.AddInstanceArgs) error
 	RemoveInstance(ctx context.Context, app *App, removeArgs bind.RemoveInstanceArgs) error
+
+	AddUnitStatusHistory(ctx context.Context, h provision.UnitStatusHistory) error
 }
 
 type AppInfo struct {
@@ -116,11 +212,25 @@ type AppInfo struct {
 	Tags        []string `json:"tags"`
 	Metadata    Metadata `json:"metadata"`
 
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+	AutoRedeploy       bool `json:"autoRedeploy,omitempty"`
+	RequireApproval    bool `json:"requireApproval,omitempty"`
+
+	Archive     *AppArchive   `json:"archive,omitempty"`
+	Maintenance *Maintenance  `json:"maintenance,omitempty"`
+	Budget      *quota.Budget `json:"budget,omitempty"`
+
+	Spread                    *Spread           `json:"spread,omitempty"`
+	SecurityContext           *SecurityContext  `json:"securityContext,omitempty"`
+	ServiceAccountAnnotations map[string]string `json:"serviceAccountAnnotations,omitempty"`
+	InitContainers            []InitContainer   `json:"initContainers,omitempty"`
+
 	Units                   []provision.Unit                 `json:"units"`
 	InternalAddresses       []AppInternalAddress             `json:"internalAddresses,omitempty"`
 	Autoscale               []provision.AutoScaleSpec        `json:"autoscale,omitempty"`
 	UnitsMetrics            []provision.UnitMetric           `json:"unitsMetrics,omitempty"`
 	AutoscaleRecommendation []provision.RecommendedResources `json:"autoscaleRecommendation,omitempty"`
+	MetricsScrape           *provision.MetricsScrape         `json:"metricsScrape,omitempty"`
 
 	Provisioner          string                     `json:"provisioner,omitempty"`
 	Cluster              string                     `json:"cluster,omitempty"`
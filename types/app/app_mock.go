@@ -12,6 +12,7 @@ import (
 	pkgErrors "github.com/pkg/errors"
 	"github.com/tsuru/tsuru/types/app/image"
 	"github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/provision"
 	"github.com/tsuru/tsuru/types/router"
 )
 
@@ -26,6 +27,7 @@ type MockAppService struct {
 	OnRemoveInstance               func(app *App, removeArgs bind.RemoveInstanceArgs) error
 	OnGetInternalBindableAddresses func(app *App) ([]string, error)
 	OnGetHealthcheckData           func(app *App) (router.HealthcheckData, error)
+	OnAddUnitStatusHistory         func(h provision.UnitStatusHistory) error
 }
 
 func (m *MockAppService) GetByName(ctx context.Context, name string) (*App, error) {
@@ -101,3 +103,10 @@ func (m *MockAppService) RemoveInstance(ctx context.Context, app *App, removeArg
 
 	return errors.New("MockAppService.RemoveInstance is not implemented")
 }
+
+func (m *MockAppService) AddUnitStatusHistory(ctx context.Context, h provision.UnitStatusHistory) error {
+	if m.OnAddUnitStatusHistory != nil {
+		return m.OnAddUnitStatusHistory(h)
+	}
+	return nil
+}
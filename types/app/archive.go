@@ -0,0 +1,16 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "time"
+
+// AppArchive describes when and why an app was archived. ConfigSnapshot is
+// the configuration snapshot taken right before archiving it.
+type AppArchive struct {
+	ArchivedAt     time.Time `json:"archivedAt"`
+	ArchivedBy     string    `json:"archivedBy"`
+	Reason         string    `json:"reason,omitempty"`
+	ConfigSnapshot string    `json:"configSnapshot,omitempty"`
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// EgressAllowAnnotation lists the external destinations, as comma separated
+// CIDRs or FQDNs, an app is allowed to reach when egress control is enabled.
+const EgressAllowAnnotation = "app.tsuru.io/egress-allow"
+
+// EgressDestinations holds the parsed destinations of EgressAllowAnnotation.
+type EgressDestinations struct {
+	CIDRs []string
+	FQDNs []string
+}
+
+// ParseEgressDestinations parses the value of EgressAllowAnnotation. Plain IP
+// addresses are converted to single host CIDRs.
+func ParseEgressDestinations(value string) (EgressDestinations, error) {
+	var dest EgressDestinations
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			_, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				return EgressDestinations{}, fmt.Errorf("invalid egress destination %q: %w", item, err)
+			}
+			dest.CIDRs = append(dest.CIDRs, ipNet.String())
+			continue
+		}
+		if ip := net.ParseIP(item); ip != nil {
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			dest.CIDRs = append(dest.CIDRs, fmt.Sprintf("%s/%d", ip.String(), bits))
+			continue
+		}
+		fqdn := strings.ToLower(strings.TrimSuffix(item, "."))
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(fqdn, "*.")); len(errs) > 0 {
+			return EgressDestinations{}, fmt.Errorf("invalid egress destination %q: must be a CIDR, an IP address or a FQDN", item)
+		}
+		dest.FQDNs = append(dest.FQDNs, fqdn)
+	}
+	return dest, nil
+}
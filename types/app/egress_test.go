@@ -0,0 +1,32 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s S) TestParseEgressDestinations(c *check.C) {
+	dest, err := ParseEgressDestinations("10.0.0.0/8, 192.168.1.10,2001:db8::1, API.example.com., *.s3.amazonaws.com,")
+	c.Assert(err, check.IsNil)
+	c.Assert(dest, check.DeepEquals, EgressDestinations{
+		CIDRs: []string{"10.0.0.0/8", "192.168.1.10/32", "2001:db8::1/128"},
+		FQDNs: []string{"api.example.com", "*.s3.amazonaws.com"},
+	})
+}
+
+func (s S) TestParseEgressDestinationsInvalid(c *check.C) {
+	_, err := ParseEgressDestinations("10.0.0.0/33")
+	c.Assert(err, check.ErrorMatches, `invalid egress destination "10.0.0.0/33": .*`)
+	_, err = ParseEgressDestinations("api_example.com")
+	c.Assert(err, check.ErrorMatches, `invalid egress destination "api_example.com": must be a CIDR, an IP address or a FQDN`)
+}
+
+func (s S) TestMetadataValidateEgressAllow(c *check.C) {
+	m := Metadata{Annotations: []MetadataItem{{Name: EgressAllowAnnotation, Value: "10.0.0.0/8,not a host"}}}
+	c.Assert(m.Validate(), check.ErrorMatches, `(?s).*invalid egress destination "not a host".*`)
+	m = Metadata{Annotations: []MetadataItem{{Name: EgressAllowAnnotation, Value: "10.0.0.0/8,api.example.com"}}}
+	c.Assert(m.Validate(), check.IsNil)
+}
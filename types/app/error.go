@@ -17,6 +17,7 @@ var (
 	ErrPlanAlreadyExists      = errors.New("plan already exists")
 	ErrPlanDefaultAmbiguous   = errors.New("more than one default plan found")
 	ErrPlanDefaultNotFound    = errors.New("default plan not found")
+	ErrPlanDefaultTarget      = errors.New("either pool or team must be set as plan default target")
 	ErrLimitOfMemory          = errors.New("The minimum allowed memory is 4MB")
 	ErrPlatformNameMissing    = errors.New("Platform name is required.")
 	ErrPlatformImageMissing   = errors.New("Platform image is required.")
@@ -64,3 +65,13 @@ type PlanValidationError struct {
 func (p PlanValidationError) Error() string {
 	return fmt.Sprintf("invalid value for %s", p.Field)
 }
+
+// PlanInUseError is returned when removing a plan still referenced by apps
+// or jobs.
+type PlanInUseError struct {
+	Usage PlanUsage
+}
+
+func (e *PlanInUseError) Error() string {
+	return fmt.Sprintf("plan %q is used by %d app(s) and %d job(s), a replacement plan is required to remove it", e.Usage.Plan, len(e.Usage.Apps), len(e.Usage.Jobs))
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// MaxFileMountsSize is the maximum size, in bytes, of all the files mounted
+// in the units of an app, keeping them well below the size limit of the
+// objects holding them in the cluster.
+const MaxFileMountsSize = 512 * 1024
+
+// FileMount is a small file uploaded to the app and projected into its units
+// at Path. Secret files are kept apart from the others and their content is
+// never listed.
+type FileMount struct {
+	Path    string `json:"path"`
+	Content []byte `json:"content,omitempty"`
+	Secret  bool   `json:"secret,omitempty" bson:",omitempty"`
+}
+
+// FileMountInfo describes a file mounted in the units of an app, without its
+// content.
+type FileMountInfo struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	Secret bool   `json:"secret,omitempty"`
+	Digest string `json:"digest"`
+}
+
+func (f FileMount) Info() FileMountInfo {
+	return FileMountInfo{
+		Path:   f.Path,
+		Size:   len(f.Content),
+		Secret: f.Secret,
+		Digest: f.Digest(),
+	}
+}
+
+// Digest returns the sha256 of the content of the file.
+func (f FileMount) Digest() string {
+	sum := sha256.Sum256(f.Content)
+	return hex.EncodeToString(sum[:])
+}
+
+// FileMountsDigest returns a digest of the paths and contents of the files,
+// changing whenever any of them changes, regardless of their order.
+func FileMountsDigest(files []FileMount) string {
+	sorted := append([]FileMount(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+	h := sha256.New()
+	for _, f := range sorted {
+		h.Write([]byte(f.Path))
+		h.Write([]byte{0})
+		h.Write([]byte(f.Digest()))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,33 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+// InitContainer runs to completion in each unit of the listed processes, or
+// of every process when none is listed, before the process is started. It's
+// useful for migrations, schema checks and volume initialization. An empty
+// Image runs the image of the version deployed in the unit.
+type InitContainer struct {
+	Name      string            `json:"name"`
+	Image     string            `json:"image,omitempty" bson:",omitempty"`
+	Command   []string          `json:"command,omitempty" bson:",omitempty"`
+	Env       map[string]string `json:"env,omitempty" bson:",omitempty"`
+	Processes []string          `json:"processes,omitempty" bson:",omitempty"`
+
+	// Delete removes the init container with the same name on app update.
+	Delete bool `json:"delete,omitempty" bson:"-"`
+}
+
+// RunsIn reports whether the init container runs in the units of process.
+func (c InitContainer) RunsIn(process string) bool {
+	if len(c.Processes) == 0 {
+		return true
+	}
+	for _, p := range c.Processes {
+		if p == process {
+			return true
+		}
+	}
+	return false
+}
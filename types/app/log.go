@@ -2,8 +2,12 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	logTypes "github.com/tsuru/tsuru/types/log"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -24,6 +28,44 @@ type AppLogServiceInstance interface {
 	Instance() AppLogService
 }
 
+const (
+	LogSeverityDebug   = "debug"
+	LogSeverityInfo    = "info"
+	LogSeverityWarning = "warning"
+	LogSeverityError   = "error"
+)
+
+var (
+	logSeverities = []string{LogSeverityDebug, LogSeverityInfo, LogSeverityWarning, LogSeverityError}
+
+	logSeverityErrorRegexp   = regexp.MustCompile(`(?i)\b(fatal|panic|crit(ical)?|err(or)?)\b`)
+	logSeverityWarningRegexp = regexp.MustCompile(`(?i)\bwarn(ing)?\b`)
+	logSeverityDebugRegexp   = regexp.MustCompile(`(?i)\b(debug|trace)\b`)
+)
+
+// LogSeverity infers the severity of a log message from the level keywords
+// it contains, defaulting to info.
+func LogSeverity(message string) string {
+	switch {
+	case logSeverityErrorRegexp.MatchString(message):
+		return LogSeverityError
+	case logSeverityWarningRegexp.MatchString(message):
+		return LogSeverityWarning
+	case logSeverityDebugRegexp.MatchString(message):
+		return LogSeverityDebug
+	}
+	return LogSeverityInfo
+}
+
+func logSeverityRank(severity string) int {
+	for i, s := range logSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
 type ListLogArgs struct {
 	Name         string
 	Type         logTypes.LogType
@@ -31,6 +73,44 @@ type ListLogArgs struct {
 	Units        []string
 	Limit        int
 	InvertSource bool
+
+	// Query, when set, only matches messages containing it, ignoring case.
+	Query string
+	// Severity, when set, only matches messages with at least this severity.
+	Severity string
+	// Since and Until, when set, limit the time range of the messages.
+	Since time.Time
+	Until time.Time
+}
+
+// Validate checks the search filters of the arguments.
+func (args ListLogArgs) Validate() error {
+	if args.Severity != "" && logSeverityRank(args.Severity) < 0 {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid log severity %q, must be one of: %s", args.Severity, strings.Join(logSeverities, ", "))}
+	}
+	if !args.Since.IsZero() && !args.Until.IsZero() && args.Until.Before(args.Since) {
+		return &tsuruErrors.ValidationError{Message: "the end of the log time range must be after its start"}
+	}
+	return nil
+}
+
+// MatchesSearch reports whether the entry matches the query, severity and
+// time range of the arguments. Source and units are filtered by each log
+// service.
+func (args ListLogArgs) MatchesSearch(entry *Applog) bool {
+	if !args.Since.IsZero() && entry.Date.Before(args.Since) {
+		return false
+	}
+	if !args.Until.IsZero() && entry.Date.After(args.Until) {
+		return false
+	}
+	if args.Query != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(args.Query)) {
+		return false
+	}
+	if args.Severity != "" && logSeverityRank(LogSeverity(entry.Message)) < logSeverityRank(args.Severity) {
+		return false
+	}
+	return true
 }
 
 // Applog represents a log entry.
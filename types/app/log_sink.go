@@ -0,0 +1,60 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	LogSinkTypeSyslog = "syslog"
+	LogSinkTypeKafka  = "kafka"
+	LogSinkTypeHTTP   = "http"
+)
+
+var logSinkNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]{0,39}$`)
+
+// LogSink is an external destination the logs of an app are forwarded to,
+// besides being stored by tsuru.
+//
+// Syslog sinks take a udp:// or tcp:// URL. Kafka sinks take the URL of a
+// Kafka REST proxy and the Topic the logs are produced to. HTTP sinks take
+// the URL the logs are posted to as JSON.
+type LogSink struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Topic string `json:"topic,omitempty" bson:",omitempty"`
+}
+
+func (s LogSink) Validate() error {
+	if !logSinkNameRegexp.MatchString(s.Name) {
+		return &tsuruErrors.ValidationError{Message: "log sink name must start with a letter and contain only lowercase letters, numbers and dashes, up to 40 characters"}
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Host == "" {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid log sink url %q", s.URL)}
+	}
+	switch s.Type {
+	case LogSinkTypeSyslog:
+		if u.Scheme != "udp" && u.Scheme != "tcp" {
+			return &tsuruErrors.ValidationError{Message: "syslog log sink url must use the udp or tcp scheme"}
+		}
+	case LogSinkTypeKafka, LogSinkTypeHTTP:
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("%s log sink url must use the http or https scheme", s.Type)}
+		}
+	default:
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid log sink type %q, must be one of: syslog, kafka, http", s.Type)}
+	}
+	if (s.Type == LogSinkTypeKafka) != (s.Topic != "") {
+		return &tsuruErrors.ValidationError{Message: "log sink topic must be set only in kafka sinks"}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+func (s S) TestLogSinkValidate(c *check.C) {
+	tests := []struct {
+		sink     LogSink
+		expected string
+	}{
+		{LogSink{Name: "papertrail", Type: LogSinkTypeSyslog, URL: "udp://logs.example.com:514"}, ""},
+		{LogSink{Name: "kafka-1", Type: LogSinkTypeKafka, URL: "http://kafka-rest:8082", Topic: "logs"}, ""},
+		{LogSink{Name: "collector", Type: LogSinkTypeHTTP, URL: "https://collector.example.com/logs"}, ""},
+		{LogSink{Name: "Bad_Name", Type: LogSinkTypeHTTP, URL: "https://collector.example.com"}, "log sink name must start with a letter.*"},
+		{LogSink{Name: "s", Type: LogSinkTypeHTTP, URL: "collector"}, `invalid log sink url "collector"`},
+		{LogSink{Name: "s", Type: LogSinkTypeSyslog, URL: "http://logs.example.com"}, "syslog log sink url must use the udp or tcp scheme"},
+		{LogSink{Name: "s", Type: LogSinkTypeHTTP, URL: "tcp://logs.example.com"}, "http log sink url must use the http or https scheme"},
+		{LogSink{Name: "s", Type: "fluentd", URL: "tcp://logs.example.com"}, `invalid log sink type "fluentd", must be one of: syslog, kafka, http`},
+		{LogSink{Name: "s", Type: LogSinkTypeKafka, URL: "http://kafka-rest:8082"}, "log sink topic must be set only in kafka sinks"},
+		{LogSink{Name: "s", Type: LogSinkTypeHTTP, URL: "http://collector", Topic: "logs"}, "log sink topic must be set only in kafka sinks"},
+	}
+	for _, tt := range tests {
+		err := tt.sink.Validate()
+		if tt.expected == "" {
+			c.Check(err, check.IsNil, check.Commentf("sink: %#v", tt.sink))
+			continue
+		}
+		c.Check(err, check.ErrorMatches, tt.expected, check.Commentf("sink: %#v", tt.sink))
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s S) TestLogSeverity(c *check.C) {
+	c.Assert(LogSeverity("ERROR: connection refused"), check.Equals, LogSeverityError)
+	c.Assert(LogSeverity("panic: runtime error"), check.Equals, LogSeverityError)
+	c.Assert(LogSeverity(`level=warn msg="slow query"`), check.Equals, LogSeverityWarning)
+	c.Assert(LogSeverity("[DEBUG] cache hit"), check.Equals, LogSeverityDebug)
+	c.Assert(LogSeverity("GET / 200"), check.Equals, LogSeverityInfo)
+	c.Assert(LogSeverity("0 errors found"), check.Equals, LogSeverityInfo)
+}
+
+func (s S) TestListLogArgsValidate(c *check.C) {
+	c.Assert(ListLogArgs{Severity: "warning"}.Validate(), check.IsNil)
+	c.Assert(ListLogArgs{Severity: "critical"}.Validate(), check.ErrorMatches, `invalid log severity "critical", must be one of: debug, info, warning, error`)
+	now := time.Now()
+	c.Assert(ListLogArgs{Since: now, Until: now.Add(-time.Hour)}.Validate(), check.ErrorMatches, "the end of the log time range must be after its start")
+}
+
+func (s S) TestListLogArgsMatchesSearch(c *check.C) {
+	now := time.Now()
+	entry := &Applog{Date: now, Message: "WARNING: Disk almost full"}
+	c.Assert(ListLogArgs{}.MatchesSearch(entry), check.Equals, true)
+	c.Assert(ListLogArgs{Query: "disk"}.MatchesSearch(entry), check.Equals, true)
+	c.Assert(ListLogArgs{Query: "memory"}.MatchesSearch(entry), check.Equals, false)
+	c.Assert(ListLogArgs{Severity: LogSeverityInfo}.MatchesSearch(entry), check.Equals, true)
+	c.Assert(ListLogArgs{Severity: LogSeverityError}.MatchesSearch(entry), check.Equals, false)
+	c.Assert(ListLogArgs{Since: now.Add(-time.Minute), Until: now.Add(time.Minute)}.MatchesSearch(entry), check.Equals, true)
+	c.Assert(ListLogArgs{Since: now.Add(time.Minute)}.MatchesSearch(entry), check.Equals, false)
+	c.Assert(ListLogArgs{Until: now.Add(-time.Minute)}.MatchesSearch(entry), check.Equals, false)
+}
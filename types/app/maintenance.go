@@ -0,0 +1,18 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "time"
+
+// Maintenance is set while the app is in maintenance mode, in which its
+// routers answer requests with a 503 page, or a redirect to RedirectURL,
+// while its units keep running. An empty Page means the default page of the
+// router.
+type Maintenance struct {
+	Page        string    `json:"page,omitempty"`
+	RedirectURL string    `json:"redirectURL,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	StartedBy   string    `json:"startedBy"`
+}
@@ -68,6 +68,11 @@ func validateAnnotations(items []MetadataItem) *errors.MultiError {
 		for _, msg := range validation.IsQualifiedName(strings.ToLower(item.Name)) {
 			allErrs.Add(field.Invalid(fldPath, item.Name, msg))
 		}
+		if item.Name == EgressAllowAnnotation {
+			if _, err := ParseEgressDestinations(item.Value); err != nil {
+				allErrs.Add(err)
+			}
+		}
 		totalSize += (int64)(len(item.Name)) + (int64)(len(item.Value))
 	}
 	if totalSize > (int64)(totalAnnotationSizeLimitB) {
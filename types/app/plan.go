@@ -13,6 +13,15 @@ type Plan struct {
 	CPUBurst *CPUBurst     `json:"cpuBurst,omitempty"`
 	Default  bool          `json:"default,omitempty"`
 	Override *PlanOverride `json:"override,omitempty"`
+	Requests *PlanRequests `json:"requests,omitempty"`
+
+	// HourlyCost is the cost of running one unit of the plan for an hour,
+	// used to project the monthly cost of apps against their budgets.
+	HourlyCost float64 `json:"hourlyCost,omitempty"`
+
+	// SchedulingConstraints are labels the pool and cluster of apps using
+	// the plan must have.
+	SchedulingConstraints map[string]string `json:"schedulingConstraints,omitempty"`
 }
 
 type PlanOverride struct {
@@ -21,6 +30,15 @@ type PlanOverride struct {
 	CPUBurst *float64 `json:"cpuBurst"`
 }
 
+// PlanRequests configures resource requests independently from the limits
+// defined by Memory and CPUMilli. MemoryOvercommit maps pool names to the
+// factor used to divide the memory limit when Memory is not set.
+type PlanRequests struct {
+	CPUMilli         int                `json:"cpumilli,omitempty"`
+	Memory           int64              `json:"memory,omitempty"`
+	MemoryOvercommit map[string]float64 `json:"memoryOvercommit,omitempty"`
+}
+
 type CPUBurst struct {
 	Default    float64 `json:"default"`
 	MaxAllowed float64 `json:"maxAllowed"`
@@ -87,12 +105,68 @@ func (p Plan) GetCPUBurst() float64 {
 	return 0
 }
 
+// GetMilliCPURequest returns the explicit CPU request of the plan, never
+// greater than its limit, or 0 when requests are derived from the limit.
+func (p Plan) GetMilliCPURequest() int {
+	if p.Requests == nil || p.Requests.CPUMilli <= 0 {
+		return 0
+	}
+	if limit := p.GetMilliCPU(); limit > 0 && p.Requests.CPUMilli > limit {
+		return limit
+	}
+	return p.Requests.CPUMilli
+}
+
+// GetMemoryRequest returns the explicit memory request of the plan, never
+// greater than its limit, or 0 when requests are derived from the limit.
+func (p Plan) GetMemoryRequest() int64 {
+	if p.Requests == nil || p.Requests.Memory <= 0 {
+		return 0
+	}
+	if limit := p.GetMemory(); limit > 0 && p.Requests.Memory > limit {
+		return limit
+	}
+	return p.Requests.Memory
+}
+
+func (p Plan) GetMemoryOvercommit(pool string) float64 {
+	if p.Requests == nil {
+		return 0
+	}
+	return p.Requests.MemoryOvercommit[pool]
+}
+
+// PlanDefault assigns a plan as the default of a single pool or team,
+// taking precedence over the global default plan at app creation.
+type PlanDefault struct {
+	Plan string `json:"plan"`
+	Pool string `json:"pool,omitempty"`
+	Team string `json:"team,omitempty"`
+}
+
+// PlanUsage lists the apps and jobs referencing a plan, either directly or
+// through the plan of one of their processes.
+type PlanUsage struct {
+	Plan string   `json:"plan"`
+	Apps []string `json:"apps"`
+	Jobs []string `json:"jobs"`
+}
+
+func (u *PlanUsage) InUse() bool {
+	return len(u.Apps) > 0 || len(u.Jobs) > 0
+}
+
 type PlanService interface {
 	Create(ctx context.Context, plan Plan) error
 	List(context.Context) ([]Plan, error)
 	FindByName(ctx context.Context, name string) (*Plan, error)
 	DefaultPlan(context.Context) (*Plan, error)
 	Remove(ctx context.Context, planName string) error
+	SetDefault(ctx context.Context, d PlanDefault) error
+	UnsetDefault(ctx context.Context, d PlanDefault) error
+	ListDefaults(ctx context.Context) ([]PlanDefault, error)
+	DefaultPlanFor(ctx context.Context, d PlanDefault) (*Plan, error)
+	Usage(ctx context.Context, planName string) (*PlanUsage, error)
 }
 
 type PlanStorage interface {
@@ -101,4 +175,8 @@ type PlanStorage interface {
 	FindDefault(context.Context) (*Plan, error)
 	FindByName(context.Context, string) (*Plan, error)
 	Delete(context.Context, Plan) error
+	SetDefault(context.Context, PlanDefault) error
+	UnsetDefault(context.Context, PlanDefault) error
+	FindDefaults(context.Context) ([]PlanDefault, error)
+	FindDefaultFor(context.Context, PlanDefault) (*PlanDefault, error)
 }
@@ -15,6 +15,11 @@ type MockPlanStorage struct {
 	OnFindDefault func() (*Plan, error)
 	OnFindByName  func(string) (*Plan, error)
 	OnDelete      func(Plan) error
+
+	OnSetDefault     func(PlanDefault) error
+	OnUnsetDefault   func(PlanDefault) error
+	OnFindDefaults   func() ([]PlanDefault, error)
+	OnFindDefaultFor func(PlanDefault) (*PlanDefault, error)
 }
 
 func (m *MockPlanStorage) Insert(ctx context.Context, p Plan) error {
@@ -37,6 +42,22 @@ func (m *MockPlanStorage) Delete(ctx context.Context, p Plan) error {
 	return m.OnDelete(p)
 }
 
+func (m *MockPlanStorage) SetDefault(ctx context.Context, d PlanDefault) error {
+	return m.OnSetDefault(d)
+}
+
+func (m *MockPlanStorage) UnsetDefault(ctx context.Context, d PlanDefault) error {
+	return m.OnUnsetDefault(d)
+}
+
+func (m *MockPlanStorage) FindDefaults(ctx context.Context) ([]PlanDefault, error) {
+	return m.OnFindDefaults()
+}
+
+func (m *MockPlanStorage) FindDefaultFor(ctx context.Context, d PlanDefault) (*PlanDefault, error) {
+	return m.OnFindDefaultFor(d)
+}
+
 // MockPlanService implements PlanService interface
 type MockPlanService struct {
 	Plans []Plan
@@ -46,6 +67,12 @@ type MockPlanService struct {
 	OnFindByName  func(string) (*Plan, error)
 	OnDefaultPlan func() (*Plan, error)
 	OnRemove      func(string) error
+
+	OnSetDefault     func(PlanDefault) error
+	OnUnsetDefault   func(PlanDefault) error
+	OnListDefaults   func() ([]PlanDefault, error)
+	OnDefaultPlanFor func(PlanDefault) (*Plan, error)
+	OnUsage          func(string) (*PlanUsage, error)
 }
 
 func (m *MockPlanService) Create(ctx context.Context, plan Plan) error {
@@ -91,3 +118,38 @@ func (m *MockPlanService) Remove(ctx context.Context, name string) error {
 	}
 	return m.OnRemove(name)
 }
+
+func (m *MockPlanService) SetDefault(ctx context.Context, d PlanDefault) error {
+	if m.OnSetDefault == nil {
+		return nil
+	}
+	return m.OnSetDefault(d)
+}
+
+func (m *MockPlanService) UnsetDefault(ctx context.Context, d PlanDefault) error {
+	if m.OnUnsetDefault == nil {
+		return nil
+	}
+	return m.OnUnsetDefault(d)
+}
+
+func (m *MockPlanService) ListDefaults(ctx context.Context) ([]PlanDefault, error) {
+	if m.OnListDefaults == nil {
+		return nil, nil
+	}
+	return m.OnListDefaults()
+}
+
+func (m *MockPlanService) DefaultPlanFor(ctx context.Context, d PlanDefault) (*Plan, error) {
+	if m.OnDefaultPlanFor == nil {
+		return nil, ErrPlanDefaultNotFound
+	}
+	return m.OnDefaultPlanFor(d)
+}
+
+func (m *MockPlanService) Usage(ctx context.Context, name string) (*PlanUsage, error) {
+	if m.OnUsage == nil {
+		return &PlanUsage{Plan: name}, nil
+	}
+	return m.OnUsage(name)
+}
@@ -4,12 +4,74 @@
 
 package app
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
 type Process struct {
 	Name     string   `json:"name"` // name of process, it is like a merge key
 	Plan     string   `json:"plan,omitempty"`
 	Metadata Metadata `json:"metadata"`
+	// DisruptionBudget overrides the one declared for the process in
+	// tsuru.yaml.
+	DisruptionBudget *provTypes.DisruptionBudget `json:"disruptionBudget,omitempty"`
+	// Shutdown and Rollout override the ones declared for the process in
+	// tsuru.yaml.
+	Shutdown *provTypes.ProcessShutdown `json:"shutdown,omitempty"`
+	Rollout  *provTypes.ProcessRollout  `json:"rollout,omitempty"`
+	// NodeSelector and Tolerations choose the nodes of the pool running the
+	// units of the process, like workers in spot nodes.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations  []Toleration      `json:"tolerations,omitempty"`
+}
+
+// Toleration allows the units of a process to run in nodes with a matching
+// taint. An empty Operator means Equal, while Exists matches any value of
+// the key, and an empty Effect matches all effects.
+type Toleration struct {
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+const (
+	TolerationOpEqual  = "Equal"
+	TolerationOpExists = "Exists"
+)
+
+var tolerationEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+
+func (t Toleration) Validate() error {
+	switch t.Operator {
+	case "", TolerationOpEqual:
+		if t.Key == "" {
+			return errors.New("key is required unless the operator is Exists")
+		}
+	case TolerationOpExists:
+		if t.Value != "" {
+			return errors.New("value must be empty when the operator is Exists")
+		}
+	default:
+		return fmt.Errorf("invalid operator %q, must be Equal or Exists", t.Operator)
+	}
+	if t.Effect == "" {
+		return nil
+	}
+	for _, e := range tolerationEffects {
+		if t.Effect == e {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid effect %q, must be one of %s", t.Effect, strings.Join(tolerationEffects, ", "))
 }
 
 func (p *Process) Empty() bool {
-	return p.Plan == "" && p.Metadata.Empty()
+	return p.Plan == "" && p.Metadata.Empty() && p.DisruptionBudget.Empty() &&
+		p.Shutdown.Empty() && p.Rollout.Empty() &&
+		len(p.NodeSelector) == 0 && len(p.Tolerations) == 0
 }
@@ -0,0 +1,49 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// AppSecret is a secret of an app, exposed to its units as an environment
+// variable. Its value is only stored sealed and is never listed.
+type AppSecret struct {
+	Name      string      `json:"name"`
+	Value     SealedValue `json:"-"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+	UpdatedBy string      `json:"updatedBy"`
+}
+
+// SealedValue is a value encrypted with a data key of its own, which is in
+// turn encrypted by the key manager identified by KeyManager.
+type SealedValue struct {
+	KeyManager string
+	KeyID      string
+	DataKey    []byte
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// AppSecretsDigest returns a digest of the names and sealed values of the
+// secrets, changing whenever any of them is set again, regardless of their
+// order.
+func AppSecretsDigest(secrets []AppSecret) string {
+	sorted := append([]AppSecret(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	h := sha256.New()
+	for _, s := range sorted {
+		h.Write([]byte(s.Name))
+		h.Write([]byte{0})
+		h.Write(s.Value.Ciphertext)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
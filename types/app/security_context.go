@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	SeccompProfileRuntimeDefault = "RuntimeDefault"
+	SeccompProfileUnconfined     = "Unconfined"
+
+	// SeccompProfileLocalhostPrefix prefixes the path, relative to the
+	// seccomp directory of the kubelet, of profiles loaded from the nodes.
+	SeccompProfileLocalhostPrefix = "Localhost/"
+)
+
+// SecurityContext hardens the units of apps. It may be set on pools, as the
+// security-context label, and on apps, whose fields take precedence over the
+// ones of the pool.
+type SecurityContext struct {
+	RunAsNonRoot           *bool  `json:"runAsNonRoot,omitempty" bson:",omitempty"`
+	ReadOnlyRootFilesystem *bool  `json:"readOnlyRootFilesystem,omitempty" bson:",omitempty"`
+	SeccompProfile         string `json:"seccompProfile,omitempty" bson:",omitempty"`
+	FSGroup                *int64 `json:"fsGroup,omitempty" bson:",omitempty"`
+}
+
+func (s *SecurityContext) Empty() bool {
+	return s == nil || (s.RunAsNonRoot == nil && s.ReadOnlyRootFilesystem == nil && s.SeccompProfile == "" && s.FSGroup == nil)
+}
+
+func (s *SecurityContext) Validate() error {
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.SeccompProfile == "", s.SeccompProfile == SeccompProfileRuntimeDefault, s.SeccompProfile == SeccompProfileUnconfined:
+	case strings.HasPrefix(s.SeccompProfile, SeccompProfileLocalhostPrefix) && len(s.SeccompProfile) > len(SeccompProfileLocalhostPrefix):
+	default:
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid seccompProfile %q, must be %s, %s or %s<profile>", s.SeccompProfile, SeccompProfileRuntimeDefault, SeccompProfileUnconfined, SeccompProfileLocalhostPrefix)}
+	}
+	if s.FSGroup != nil && *s.FSGroup < 0 {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid fsGroup %d, must be positive", *s.FSGroup)}
+	}
+	return nil
+}
+
+// Merge returns the security context with the fields set in override
+// replacing the ones of s.
+func (s *SecurityContext) Merge(override *SecurityContext) *SecurityContext {
+	merged := &SecurityContext{}
+	if s != nil {
+		*merged = *s
+	}
+	if override == nil {
+		return merged
+	}
+	if override.RunAsNonRoot != nil {
+		merged.RunAsNonRoot = override.RunAsNonRoot
+	}
+	if override.ReadOnlyRootFilesystem != nil {
+		merged.ReadOnlyRootFilesystem = override.ReadOnlyRootFilesystem
+	}
+	if override.SeccompProfile != "" {
+		merged.SeccompProfile = override.SeccompProfile
+	}
+	if override.FSGroup != nil {
+		merged.FSGroup = override.FSGroup
+	}
+	return merged
+}
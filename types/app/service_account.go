@@ -0,0 +1,59 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	// ServiceAccountAnnotationAWSRoleARN binds the service account of an app
+	// to an AWS IAM role (IRSA).
+	ServiceAccountAnnotationAWSRoleARN = "eks.amazonaws.com/role-arn"
+	// ServiceAccountAnnotationGCPServiceAccount binds the service account of
+	// an app to a GCP service account (Workload Identity).
+	ServiceAccountAnnotationGCPServiceAccount = "iam.gke.io/gcp-service-account"
+	// ServiceAccountAnnotationAzureClientID binds the service account of an
+	// app to an Azure managed identity (Workload Identity).
+	ServiceAccountAnnotationAzureClientID = "azure.workload.identity/client-id"
+)
+
+var serviceAccountAnnotationFormats = map[string]*regexp.Regexp{
+	ServiceAccountAnnotationAWSRoleARN:        regexp.MustCompile(`^arn:aws[a-zA-Z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`),
+	ServiceAccountAnnotationGCPServiceAccount: regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]@[a-z][a-z0-9-]{4,28}[a-z0-9]\.iam\.gserviceaccount\.com$`),
+	ServiceAccountAnnotationAzureClientID:     regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+}
+
+// ValidateServiceAccountAnnotations checks that only the known cloud IAM
+// annotations are set on the service account of an app and that their values
+// are well formed.
+func ValidateServiceAccountAnnotations(annotations map[string]string) error {
+	for key, value := range annotations {
+		format, ok := serviceAccountAnnotationFormats[key]
+		if !ok {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("annotation %q is not allowed in service accounts, must be one of: %s", key, strings.Join(ServiceAccountAnnotationKeys(), ", "))}
+		}
+		if !format.MatchString(value) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid value %q for annotation %q", value, key)}
+		}
+	}
+	return nil
+}
+
+// ServiceAccountAnnotationKeys returns the annotations allowed in service
+// accounts of apps.
+func ServiceAccountAnnotationKeys() []string {
+	keys := make([]string, 0, len(serviceAccountAnnotationFormats))
+	for key := range serviceAccountAnnotationFormats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,28 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s S) TestValidateServiceAccountAnnotations(c *check.C) {
+	err := ValidateServiceAccountAnnotations(map[string]string{
+		ServiceAccountAnnotationAWSRoleARN:        "arn:aws:iam::123456789012:role/my-app",
+		ServiceAccountAnnotationGCPServiceAccount: "my-app@my-project.iam.gserviceaccount.com",
+		ServiceAccountAnnotationAzureClientID:     "00000000-1111-2222-3333-444444444444",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ValidateServiceAccountAnnotations(nil), check.IsNil)
+}
+
+func (s S) TestValidateServiceAccountAnnotationsInvalid(c *check.C) {
+	err := ValidateServiceAccountAnnotations(map[string]string{"example.com/token": "abc"})
+	c.Assert(err, check.ErrorMatches, `annotation "example.com/token" is not allowed in service accounts, must be one of: azure.workload.identity/client-id, eks.amazonaws.com/role-arn, iam.gke.io/gcp-service-account`)
+	err = ValidateServiceAccountAnnotations(map[string]string{ServiceAccountAnnotationAWSRoleARN: "arn:aws:iam::1234:role/my-app"})
+	c.Assert(err, check.ErrorMatches, `invalid value "arn:aws:iam::1234:role/my-app" for annotation "eks.amazonaws.com/role-arn"`)
+	err = ValidateServiceAccountAnnotations(map[string]string{ServiceAccountAnnotationGCPServiceAccount: "my-app@gmail.com"})
+	c.Assert(err, check.ErrorMatches, `invalid value "my-app@gmail.com" for annotation "iam.gke.io/gcp-service-account"`)
+}
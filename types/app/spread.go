@@ -0,0 +1,89 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const DefaultAntiAffinityWeight = 100
+
+// Spread distributes the units of each process of an app across topology
+// domains, like zones or nodes, instead of relying on the defaults of the
+// cluster. It may be set on pools, as the spread label, and on apps, whose
+// rules take precedence over the ones of the pool.
+type Spread struct {
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty" bson:",omitempty"`
+	AntiAffinity              []AntiAffinityRule         `json:"antiAffinity,omitempty" bson:",omitempty"`
+}
+
+// TopologySpreadConstraint limits the difference in the number of units of a
+// process between the domains of TopologyKey to MaxSkew. Required
+// constraints prevent units from being scheduled when they can't be met.
+type TopologySpreadConstraint struct {
+	TopologyKey string `json:"topologyKey"`
+	MaxSkew     int32  `json:"maxSkew,omitempty" bson:",omitempty"`
+	Required    bool   `json:"required,omitempty" bson:",omitempty"`
+}
+
+// AntiAffinityRule keeps units of a process away from the domains of
+// TopologyKey already running units of the same process. Rules not required
+// are preferences with the given Weight, from 1 to 100.
+type AntiAffinityRule struct {
+	TopologyKey string `json:"topologyKey"`
+	Required    bool   `json:"required,omitempty" bson:",omitempty"`
+	Weight      int32  `json:"weight,omitempty" bson:",omitempty"`
+}
+
+func (s *Spread) Empty() bool {
+	return s == nil || (len(s.TopologySpreadConstraints) == 0 && len(s.AntiAffinity) == 0)
+}
+
+func (s *Spread) Validate() error {
+	if s == nil {
+		return nil
+	}
+	for _, c := range s.TopologySpreadConstraints {
+		if c.TopologyKey == "" {
+			return &tsuruErrors.ValidationError{Message: "topologyKey is required in each topology spread constraint"}
+		}
+		if c.MaxSkew < 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid maxSkew %d for topology %q, must be positive", c.MaxSkew, c.TopologyKey)}
+		}
+	}
+	for _, r := range s.AntiAffinity {
+		if r.TopologyKey == "" {
+			return &tsuruErrors.ValidationError{Message: "topologyKey is required in each anti-affinity rule"}
+		}
+		if r.Weight < 0 || r.Weight > 100 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid weight %d for topology %q, must be between 1 and 100", r.Weight, r.TopologyKey)}
+		}
+		if r.Required && r.Weight != 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("weight can't be set in the required anti-affinity rule for topology %q", r.TopologyKey)}
+		}
+	}
+	return nil
+}
+
+// Merge returns the spread with the rules of override replacing the ones of
+// the same kind in s.
+func (s *Spread) Merge(override *Spread) *Spread {
+	merged := &Spread{}
+	if s != nil {
+		*merged = *s
+	}
+	if override == nil {
+		return merged
+	}
+	if len(override.TopologySpreadConstraints) > 0 {
+		merged.TopologySpreadConstraints = override.TopologySpreadConstraints
+	}
+	if len(override.AntiAffinity) > 0 {
+		merged.AntiAffinity = override.AntiAffinity
+	}
+	return merged
+}
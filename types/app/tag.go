@@ -0,0 +1,46 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+var tagKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]*[a-zA-Z0-9])?$`)
+
+// ParseTag splits a key/value tag, like env:prod, in its key and value. Tags
+// without a colon are plain labels and have only a key.
+func ParseTag(tag string) (key, value string) {
+	key, value, _ = strings.Cut(tag, ":")
+	return key, value
+}
+
+// ValidateTags checks that key/value tags have a valid key and a non empty
+// value and that each key is used at most once, so that a tag like env:prod
+// identifies the apps in a single environment.
+func ValidateTags(tags []string) error {
+	keys := map[string]string{}
+	for _, tag := range tags {
+		if !strings.Contains(tag, ":") {
+			continue
+		}
+		key, value := ParseTag(tag)
+		if !tagKeyRegexp.MatchString(key) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid tag %q: key must contain only letters, numbers, dots, dashes, underscores or slashes", tag)}
+		}
+		if value == "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid tag %q: value must not be empty", tag)}
+		}
+		if previous, ok := keys[key]; ok {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("tags %q and %q have the same key, each key must be used at most once", previous, tag)}
+		}
+		keys[key] = tag
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s S) TestParseTag(c *check.C) {
+	key, value := ParseTag("env:prod")
+	c.Assert(key, check.Equals, "env")
+	c.Assert(value, check.Equals, "prod")
+	key, value = ParseTag("url:http://example.com")
+	c.Assert(key, check.Equals, "url")
+	c.Assert(value, check.Equals, "http://example.com")
+	key, value = ParseTag("frontend")
+	c.Assert(key, check.Equals, "frontend")
+	c.Assert(value, check.Equals, "")
+}
+
+func (s S) TestValidateTags(c *check.C) {
+	c.Assert(ValidateTags(nil), check.IsNil)
+	c.Assert(ValidateTags([]string{"frontend", "env:prod", "tsuru.io/team:payments"}), check.IsNil)
+	c.Assert(ValidateTags([]string{":prod"}), check.ErrorMatches, `invalid tag ":prod": key must contain only .*`)
+	c.Assert(ValidateTags([]string{"my env:prod"}), check.ErrorMatches, `invalid tag "my env:prod": key must contain only .*`)
+	c.Assert(ValidateTags([]string{"env:"}), check.ErrorMatches, `invalid tag "env:": value must not be empty`)
+	c.Assert(ValidateTags([]string{"env:prod", "env:dev"}), check.ErrorMatches, `tags "env:prod" and "env:dev" have the same key, each key must be used at most once`)
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import "errors"
+
+var ErrIsolationProfileNotFound = errors.New("isolation profile not found")
+
+// IsolationProfile is a set of hard isolation rules enforced on every app and
+// job owned by the teams it is assigned to. Profiles are defined by the
+// operator in the isolation-profiles config.
+type IsolationProfile struct {
+	Name string `json:"name"`
+	// DedicatedNamespace places the apps and jobs of each team in a
+	// namespace of its own in every pool.
+	DedicatedNamespace bool `json:"dedicatedNamespace"`
+	// SchedulingConstraints are labels the pools and clusters hosting apps
+	// and jobs of the team must have, restricting them to dedicated nodes.
+	SchedulingConstraints map[string]string `json:"schedulingConstraints,omitempty"`
+	// Registry replaces the registry of the pool for images of the team.
+	Registry string `json:"registry,omitempty"`
+	// StrictNetworkPolicy denies traffic from and to pods outside the team
+	// namespace, except DNS, ingress from namespaces matching
+	// IngressNamespaceLabels and egress to namespaces matching
+	// EgressNamespaceLabels. Egress allowed by apps remains allowed.
+	StrictNetworkPolicy    bool              `json:"strictNetworkPolicy"`
+	IngressNamespaceLabels map[string]string `json:"ingressNamespaceLabels,omitempty"`
+	EgressNamespaceLabels  map[string]string `json:"egressNamespaceLabels,omitempty"`
+}
@@ -7,8 +7,10 @@ package auth
 import (
 	"context"
 	"errors"
+	"time"
 
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/types/bind"
 	"github.com/tsuru/tsuru/types/quota"
 )
 
@@ -16,10 +18,47 @@ var _ quota.QuotaItem = &Team{}
 
 // Team represents a real world team, a team has one creating user and a name.
 type Team struct {
-	Name         string      `json:"name"`
-	CreatingUser string      `json:"creatingUser"`
-	Tags         []string    `json:"tags"`
-	Quota        quota.Quota `json:"quota"`
+	Name         string        `json:"name"`
+	CreatingUser string        `json:"creatingUser"`
+	Tags         []string      `json:"tags"`
+	Quota        quota.Quota   `json:"quota"`
+	Env          []bind.EnvVar `json:"-"`
+	QuotaGrants  []QuotaGrant  `json:"quotaGrants,omitempty"`
+	// Notifications may hold credentials in its URLs, so it's only
+	// exposed by the team notifications API.
+	Notifications TeamNotifications `json:"-"`
+	// IsolationProfile is the name of the isolation profile enforced on
+	// apps and jobs of the team, if any.
+	IsolationProfile string `json:"isolationProfile,omitempty"`
+	// Budget limits the projected monthly cost of the units of all apps
+	// owned by the team.
+	Budget *quota.Budget `json:"budget,omitempty"`
+}
+
+// TeamNotifications are the channels notified about deploy failures, healing
+// actions and quota alerts affecting the resources of a team.
+type TeamNotifications struct {
+	Emails     []string `json:"emails"`
+	WebhookURL string   `json:"webhookURL"`
+	// SlackURL is an incoming webhook of Slack, or any service accepting
+	// its message format.
+	SlackURL string `json:"slackURL"`
+}
+
+func (n TeamNotifications) IsEmpty() bool {
+	return len(n.Emails) == 0 && n.WebhookURL == "" && n.SlackURL == ""
+}
+
+// QuotaGrant is an approved temporary increase of the quota limit of a
+// team. The increase is reverted once ExpiresAt is reached.
+type QuotaGrant struct {
+	// ID is the id of the event which requested the increase.
+	ID          string    `json:"id"`
+	Increase    int       `json:"increase"`
+	Reason      string    `json:"reason"`
+	RequestedBy string    `json:"requestedBy"`
+	ApprovedBy  string    `json:"approvedBy"`
+	ExpiresAt   time.Time `json:"expiresAt"`
 }
 
 func (t Team) GetName() string {
@@ -33,6 +72,14 @@ type TeamService interface {
 	FindByName(context.Context, string) (*Team, error)
 	FindByNames(context.Context, []string) ([]Team, error)
 	Remove(context.Context, string) error
+	SetEnvs(context.Context, string, []bind.EnvVar) error
+	UnsetEnvs(context.Context, string, []string) error
+	AddQuotaGrant(context.Context, string, QuotaGrant) error
+	RemoveQuotaGrant(context.Context, string, string) error
+	SetNotifications(context.Context, string, TeamNotifications) error
+	SetIsolationProfile(context.Context, string, string) error
+	FindIsolationProfile(context.Context, string) (*IsolationProfile, error)
+	SetBudget(context.Context, string, *quota.Budget) error
 }
 
 type TeamStorage interface {
@@ -51,4 +98,7 @@ var (
 	}
 	ErrTeamAlreadyExists = errors.New("team already exists")
 	ErrTeamNotFound      = errors.New("team not found")
+
+	ErrQuotaGrantNotFound  = errors.New("quota grant not found")
+	ErrQuotaGrantUnlimited = errors.New("team quota is unlimited")
 )
@@ -4,7 +4,12 @@
 
 package auth
 
-import "context"
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/quota"
+)
 
 var _ TeamStorage = &MockTeamStorage{}
 var _ TeamService = &MockTeamService{}
@@ -50,6 +55,19 @@ type MockTeamService struct {
 	OnFindByName  func(string) (*Team, error)
 	OnFindByNames func([]string) ([]Team, error)
 	OnRemove      func(string) error
+	OnSetEnvs     func(string, []bind.EnvVar) error
+	OnUnsetEnvs   func(string, []string) error
+
+	OnAddQuotaGrant    func(string, QuotaGrant) error
+	OnRemoveQuotaGrant func(string, string) error
+
+	OnSetNotifications func(string, TeamNotifications) error
+
+	OnSetIsolationProfile func(string, string) error
+
+	OnFindIsolationProfile func(string) (*IsolationProfile, error)
+
+	OnSetBudget func(string, *quota.Budget) error
 }
 
 func (m *MockTeamService) Create(ctx context.Context, teamName string, tags []string, user *User) error {
@@ -93,3 +111,59 @@ func (m *MockTeamService) Remove(ctx context.Context, teamName string) error {
 	}
 	return m.OnRemove(teamName)
 }
+
+func (m *MockTeamService) SetEnvs(ctx context.Context, teamName string, envs []bind.EnvVar) error {
+	if m.OnSetEnvs == nil {
+		return nil
+	}
+	return m.OnSetEnvs(teamName, envs)
+}
+
+func (m *MockTeamService) UnsetEnvs(ctx context.Context, teamName string, names []string) error {
+	if m.OnUnsetEnvs == nil {
+		return nil
+	}
+	return m.OnUnsetEnvs(teamName, names)
+}
+
+func (m *MockTeamService) AddQuotaGrant(ctx context.Context, teamName string, grant QuotaGrant) error {
+	if m.OnAddQuotaGrant == nil {
+		return nil
+	}
+	return m.OnAddQuotaGrant(teamName, grant)
+}
+
+func (m *MockTeamService) RemoveQuotaGrant(ctx context.Context, teamName, id string) error {
+	if m.OnRemoveQuotaGrant == nil {
+		return nil
+	}
+	return m.OnRemoveQuotaGrant(teamName, id)
+}
+
+func (m *MockTeamService) SetNotifications(ctx context.Context, teamName string, notifications TeamNotifications) error {
+	if m.OnSetNotifications == nil {
+		return nil
+	}
+	return m.OnSetNotifications(teamName, notifications)
+}
+
+func (m *MockTeamService) SetIsolationProfile(ctx context.Context, teamName, profile string) error {
+	if m.OnSetIsolationProfile == nil {
+		return nil
+	}
+	return m.OnSetIsolationProfile(teamName, profile)
+}
+
+func (m *MockTeamService) FindIsolationProfile(ctx context.Context, teamName string) (*IsolationProfile, error) {
+	if m.OnFindIsolationProfile == nil {
+		return nil, nil
+	}
+	return m.OnFindIsolationProfile(teamName)
+}
+
+func (m *MockTeamService) SetBudget(ctx context.Context, teamName string, budget *quota.Budget) error {
+	if m.OnSetBudget == nil {
+		return nil
+	}
+	return m.OnSetBudget(teamName, budget)
+}
@@ -24,6 +24,11 @@ type TeamTokenUpdateArgs struct {
 	ExpiresIn   int    `json:"expires_in" form:"expires_in"`
 }
 
+type TeamTokenRotateArgs struct {
+	TokenID string `json:"token_id" form:"token_id"`
+	Overlap int    `json:"overlap" form:"overlap"`
+}
+
 type TeamToken struct {
 	Token        string         `json:"token"`
 	TokenID      string         `json:"token_id"`
@@ -34,13 +39,21 @@ type TeamToken struct {
 	CreatorEmail string         `json:"creator_email"`
 	Team         string         `json:"team"`
 	Roles        []RoleInstance `json:"roles,omitempty"`
+
+	// PreviousToken is the value replaced by the last rotation, still
+	// accepted until PreviousTokenExpiresAt.
+	PreviousToken           string    `json:"-"`
+	PreviousTokenExpiresAt  time.Time `json:"previous_token_expires_at"`
+	PreviousTokenLastAccess time.Time `json:"previous_token_last_access"`
 }
 
 type TeamTokenStorage interface {
 	Insert(context.Context, TeamToken) error
 	FindByTokenID(ctx context.Context, tokenID string) (*TeamToken, error)
 	FindByToken(ctx context.Context, token string) (*TeamToken, error)
+	FindByPreviousToken(ctx context.Context, token string) (*TeamToken, error)
 	FindByTeams(ctx context.Context, teams []string) ([]TeamToken, error)
+	FindByRole(ctx context.Context, roleName string) ([]TeamToken, error)
 	UpdateLastAccess(ctx context.Context, token string) error
 	Update(context.Context, TeamToken) error
 	Delete(ctx context.Context, tokenID string) error
@@ -50,10 +63,12 @@ type TeamTokenService interface {
 	Create(ctx context.Context, args TeamTokenCreateArgs, token Token) (TeamToken, error)
 	Info(ctx context.Context, tokenID string, token Token) (TeamToken, error)
 	Update(ctx context.Context, args TeamTokenUpdateArgs, token Token) (TeamToken, error)
+	Rotate(ctx context.Context, args TeamTokenRotateArgs, token Token) (TeamToken, error)
 	Delete(ctx context.Context, tokenID string) error
 	Authenticate(ctx context.Context, header string) (Token, error)
 	FindByTokenID(ctx context.Context, tokenID string) (TeamToken, error)
 	FindByUserToken(ctx context.Context, t Token) ([]TeamToken, error)
+	FindByRole(ctx context.Context, roleName string) ([]TeamToken, error)
 	AddRole(ctx context.Context, tokenID string, roleName, contextValue string) error
 	RemoveRole(ctx context.Context, tokenID string, roleName, contextValue string) error
 }
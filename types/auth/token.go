@@ -6,6 +6,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/tsuru/tsuru/types/permission"
 )
@@ -21,3 +22,12 @@ type Token interface {
 type NamedToken interface {
 	GetTokenName() string
 }
+
+// RotatedToken is implemented by tokens which may be authenticated with the
+// value replaced by their last rotation, during its overlap window.
+type RotatedToken interface {
+	UsingPreviousValue() bool
+	// PreviousValueLastAccess is when the previous value was used before
+	// this authentication, zero when it's the first use since the rotation.
+	PreviousValueLastAccess() time.Time
+}
@@ -24,6 +24,12 @@ type User struct {
 	// In other words, it does not exist in the storage.
 	FromToken bool
 	Disabled  bool
+	// DirectoryGroups are the groups synchronized from an external
+	// directory.
+	DirectoryGroups []string
+	// MappedRoles are the roles granted by the role mappings of the auth
+	// scheme, the only ones it revokes.
+	MappedRoles []RoleInstance
 
 	APIKeyLastAccess   time.Time
 	APIKeyUsageCounter int64
@@ -32,6 +38,15 @@ type User struct {
 type RoleInstance struct {
 	Name         string
 	ContextValue string
+	// ExpiresAt is when a temporary role assignment is revoked, nil for
+	// permanent ones.
+	ExpiresAt *time.Time `json:",omitempty" bson:",omitempty"`
+}
+
+// Expired reports whether the role assignment is temporary and expired at
+// now.
+func (r RoleInstance) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !r.ExpiresAt.After(now)
 }
 
 type ErrTeamStillUsed struct {
@@ -16,6 +16,16 @@ type ServiceEnvVar struct {
 	EnvVar       `bson:",inline"`
 	ServiceName  string `json:"-"`
 	InstanceName string `json:"-"`
+	// Version restricts the env to the units of a single app version, 0
+	// means all versions.
+	Version int `bson:",omitempty" json:"-"`
+}
+
+// VersionEnvVar is an environment variable set only in the units of a single
+// app version, until it's promoted to all of them.
+type VersionEnvVar struct {
+	EnvVar  `bson:",inline"`
+	Version int `json:"version"`
 }
 
 type ServiceInstanceBind struct {
@@ -23,3 +33,16 @@ type ServiceInstanceBind struct {
 	Instance string `json:"instance"`
 	Plan     string `json:"plan"`
 }
+
+// EnvsDiff holds the names of the environment variables added, changed and
+// removed by an import of environment variables.
+type EnvsDiff struct {
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+// Empty returns whether the import changes no environment variable.
+func (d EnvsDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
@@ -11,18 +11,21 @@ type SetEnvArgs struct {
 	ManagedBy     string
 	PruneUnused   bool
 	ShouldRestart bool
+	Version       int
 }
 
 type UnsetEnvArgs struct {
 	VariableNames []string
 	Writer        io.Writer
 	ShouldRestart bool
+	Version       int
 }
 
 type AddInstanceArgs struct {
 	Envs          []ServiceEnvVar
 	Writer        io.Writer
 	ShouldRestart bool
+	Version       int
 }
 
 type RemoveInstanceArgs struct {
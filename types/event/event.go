@@ -30,11 +30,17 @@ type EventData struct {
 	Kind            Kind
 	Owner           Owner
 	SourceIP        string
+	RequestID       string `bson:",omitempty"`
+	ParentID        string `bson:",omitempty"`
 	LockUpdateTime  time.Time
 	Error           string
 	Log             string     `bson:",omitempty"`
 	StructuredLog   []LogEntry `bson:",omitempty"`
 	CancelInfo      CancelInfo
+	PauseInfo       PauseInfo
+	ApprovalInfo    ApprovalInfo
+	ProgressInfo    ProgressInfo
+	Annotations     []Annotation `bson:",omitempty"`
 	Cancelable      bool
 	Running         bool
 	Allowed         AllowedPermission
@@ -54,6 +60,23 @@ type EventInfo struct {
 	// CustomData is the new way to access eventData.{StartCustomData, EndCustomData, OtherCustomData}
 	// the major advantage is that you can access the data without converting from bson.RawValue
 	CustomData EventInfoCustomData
+
+	// Chain holds the events causally related to this one, from the root
+	// event down, when requested.
+	Chain []ChainLink `json:",omitempty"`
+}
+
+// ChainLink summarizes an event in a causal chain of events.
+type ChainLink struct {
+	UniqueID  primitive.ObjectID
+	ParentID  string `json:",omitempty"`
+	Target    Target
+	Kind      Kind
+	Owner     Owner
+	StartTime time.Time
+	EndTime   time.Time
+	Running   bool
+	Error     string
 }
 
 type EventInfoCustomData struct {
@@ -117,6 +140,41 @@ type CancelInfo struct {
 	Canceled  bool
 }
 
+type PauseInfo struct {
+	Owner      string
+	StartTime  time.Time
+	Reason     string
+	Paused     bool
+	Checkpoint string
+}
+
+type ApprovalInfo struct {
+	Required bool
+	Approved bool
+	Owner    string
+	Time     time.Time
+}
+
+// Annotation is a comment attached to an event by a user, like the
+// acknowledgment of a failure, kept for incident timelines.
+type Annotation struct {
+	Author       string
+	Message      string
+	Acknowledged bool
+	Time         time.Time
+}
+
+// ProgressInfo is the last progress checkpoint reached by a running event,
+// Percent goes from 0 to 100. Current and Total are set for steps counting
+// items, like units.
+type ProgressInfo struct {
+	Step       string
+	Percent    int
+	Current    int
+	Total      int
+	UpdateTime time.Time
+}
+
 type AllowedPermission struct {
 	Scheme   string
 	Contexts []permission.PermissionContext `bson:",omitempty"`
@@ -160,6 +218,7 @@ var (
 	TargetTypeWebhook         = TargetType("webhook")
 	TargetTypeGC              = TargetType("gc")
 	TargetTypeRouter          = TargetType("router")
+	TargetTypeGroup           = TargetType("group")
 
 	ErrInvalidTargetType = errors.New("invalid event target type")
 )
@@ -206,6 +265,8 @@ func GetTargetType(t string) (TargetType, error) {
 		return TargetTypeWebhook, nil
 	case "router":
 		return TargetTypeRouter, nil
+	case "group":
+		return TargetTypeGroup, nil
 	}
 	return TargetType(""), ErrInvalidTargetType
 }
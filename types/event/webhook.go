@@ -8,6 +8,9 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -15,15 +18,41 @@ var (
 	ErrWebhookNotFound      = errors.New("webhook not found")
 )
 
+// WebhookEventFilter selects the events calling a webhook. Target types and
+// values may be globs, e.g. "myapp-*". Teams, when set, only matches events
+// members of one of them are allowed to see.
 type WebhookEventFilter struct {
 	TargetTypes  []string `json:"target_types" form:"target_types"`
 	TargetValues []string `json:"target_values" form:"target_values"`
 	KindTypes    []string `json:"kind_types" form:"kind_types"`
 	KindNames    []string `json:"kind_names" form:"kind_names"`
+	Teams        []string `json:"teams" form:"teams"`
 	ErrorOnly    bool     `json:"error_only" form:"error_only"`
 	SuccessOnly  bool     `json:"success_only" form:"success_only"`
 }
 
+const (
+	WebhookDeliverySuccess  = "success"
+	WebhookDeliveryRetrying = "retrying"
+	WebhookDeliveryFailed   = "failed"
+)
+
+// WebhookDelivery is the call of a webhook for an event, along with its
+// retries. NextAttempt is set while Status is WebhookDeliveryRetrying.
+type WebhookDelivery struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Webhook     string             `json:"webhook"`
+	EventID     string             `json:"event_id"`
+	Status      string             `json:"status"`
+	Attempts    int                `json:"attempts"`
+	StatusCode  int                `json:"status_code,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	LastAttempt time.Time          `json:"last_attempt"`
+	NextAttempt time.Time          `json:"next_attempt" bson:",omitempty"`
+	ExpireAt    time.Time          `json:"-"`
+}
+
 type Webhook struct {
 	Name        string             `json:"name" form:"name"`
 	Description string             `json:"description" form:"description"`
@@ -35,6 +64,9 @@ type Webhook struct {
 	Method      string             `json:"method" form:"method"`
 	Body        string             `json:"body" form:"body"`
 	Insecure    bool               `json:"insecure" form:"insecure"`
+	// TeamScoped restricts the webhook to events the team owner is allowed
+	// to see, regardless of the event filter.
+	TeamScoped bool `json:"team_scoped" form:"team_scoped"`
 }
 
 type WebhookService interface {
@@ -44,6 +76,7 @@ type WebhookService interface {
 	Delete(context.Context, string) error
 	Find(context.Context, string) (Webhook, error)
 	List(context.Context, []string) ([]Webhook, error)
+	Deliveries(ctx context.Context, name string, limit int) ([]WebhookDelivery, error)
 }
 
 type WebhookStorage interface {
@@ -53,4 +86,10 @@ type WebhookStorage interface {
 	FindByName(context.Context, string) (*Webhook, error)
 	FindByEvent(ctx context.Context, f WebhookEventFilter, isSuccess bool) ([]Webhook, error)
 	Delete(context.Context, string) error
+	SaveDelivery(context.Context, WebhookDelivery) error
+	FindDeliveries(ctx context.Context, name string, limit int) ([]WebhookDelivery, error)
+	// ClaimDeliveryRetry returns a delivery due to be retried, postponing
+	// its next attempt to claimUntil so it's retried only once. It returns
+	// nil when there's none.
+	ClaimDeliveryRetry(ctx context.Context, now, claimUntil time.Time) (*WebhookDelivery, error)
 }
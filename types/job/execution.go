@@ -0,0 +1,27 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import "time"
+
+const (
+	ExecutionRunning   = "running"
+	ExecutionSucceeded = "succeeded"
+	ExecutionFailed    = "failed"
+)
+
+// Execution is a single run of a job. Finished executions are kept, along
+// with their logs, after the units of the run are removed from the cluster.
+type Execution struct {
+	ID        string    `json:"id"`
+	Job       string    `json:"job"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	ExitCode  *int32    `json:"exitCode,omitempty"`
+	Node      string    `json:"node,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
@@ -30,9 +30,23 @@ type Job struct {
 
 	DeployOptions *DeployOptions `json:"deployOptions"`
 
+	// Notifications are the channels notified when an execution of the job
+	// fails.
+	Notifications Notifications `json:"notifications"`
+
 	Spec JobSpec `json:"spec"`
 }
 
+// Notifications are the channels notified about failed executions of a job.
+type Notifications struct {
+	Emails     []string `json:"emails,omitempty"`
+	WebhookURL string   `json:"webhookURL,omitempty"`
+}
+
+func (n Notifications) IsEmpty() bool {
+	return len(n.Emails) == 0 && n.WebhookURL == ""
+}
+
 func (job *Job) GetName() string {
 	return job.Name
 }
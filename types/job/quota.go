@@ -0,0 +1,45 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import "fmt"
+
+// QuotaScope is the kind of owner of a job quota.
+type QuotaScope string
+
+const (
+	QuotaScopeTeam QuotaScope = "team"
+	QuotaScopePool QuotaScope = "pool"
+)
+
+// Quota limits the jobs of a team or of a pool. Negative limits mean
+// unlimited.
+type Quota struct {
+	MaxJobs                 int `json:"maxJobs"`
+	MaxConcurrentExecutions int `json:"maxConcurrentExecutions"`
+}
+
+// UnlimitedQuota is the quota of teams and pools without a job quota.
+var UnlimitedQuota = Quota{MaxJobs: -1, MaxConcurrentExecutions: -1}
+
+// QuotaUsage is a job quota along with the resources currently using it.
+type QuotaUsage struct {
+	Quota
+	Jobs              int `json:"jobs"`
+	RunningExecutions int `json:"runningExecutions"`
+}
+
+// QuotaExceededError is returned when creating, moving or triggering a job
+// would exceed a job quota of its team or pool.
+type QuotaExceededError struct {
+	Scope    QuotaScope
+	Name     string
+	Resource string
+	Limit    int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("job quota of %s %q exceeded: at most %d %s allowed", e.Scope, e.Name, e.Limit, e.Resource)
+}
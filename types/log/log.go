@@ -21,6 +21,7 @@ const (
 type LogabbleObject struct {
 	Name string
 	Pool string
+	Team string
 }
 
 type LogWatcher interface {
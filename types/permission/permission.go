@@ -24,9 +24,10 @@ var (
 	CtxServiceInstance = ContextType("service-instance")
 	CtxVolume          = ContextType("volume")
 	CtxRouter          = ContextType("router")
+	CtxTag             = ContextType("tag")
 
 	ContextTypes = []ContextType{
-		CtxGlobal, CtxApp, CtxTeam, CtxUser, CtxPool, CtxService, CtxServiceInstance, CtxVolume, CtxRouter, CtxJob,
+		CtxGlobal, CtxApp, CtxTeam, CtxUser, CtxPool, CtxService, CtxServiceInstance, CtxVolume, CtxRouter, CtxJob, CtxTag,
 	}
 )
 
@@ -11,6 +11,7 @@ import (
 
 var (
 	ErrRoleNotFound          = errors.New("role not found")
+	ErrRoleTemplateNotFound  = errors.New("role template not found")
 	ErrRoleAlreadyExists     = errors.New("role already exists")
 	ErrRoleEventNotFound     = errors.New("role event not found")
 	ErrInvalidRoleName       = errors.New("invalid role name")
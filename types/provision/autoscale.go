@@ -14,8 +14,30 @@ type AutoScaleSpec struct {
 	AverageCPU string                `json:"averageCPU,omitempty"`
 	Schedules  []AutoScaleSchedule   `json:"schedules,omitempty"`
 	Prometheus []AutoScalePrometheus `json:"prometheus,omitempty"`
+	Metrics    []AutoScaleMetric     `json:"metrics,omitempty"`
+	Kafka      []AutoScaleKafka      `json:"kafka,omitempty"`
+	SQS        []AutoScaleSQS        `json:"sqs,omitempty"`
 	Version    int                   `json:"version"`
 	Behavior   BehaviorAutoScaleSpec `json:"behavior,omitempty"`
+
+	// Status is reported by the provisioner for autoscalers managed by
+	// event-driven scalers and ignored when the autoscale is set.
+	Status *AutoScaleStatus `json:"status,omitempty"`
+}
+
+// UsesScalers tells whether the spec has triggers only supported by the
+// event-driven scalers, like KEDA.
+func (s *AutoScaleSpec) UsesScalers() bool {
+	return len(s.Schedules) > 0 || len(s.Prometheus) > 0 || len(s.Kafka) > 0 || len(s.SQS) > 0
+}
+
+// AutoScaleStatus is the state of the scalers of a process. Active tells
+// whether any of the triggers is currently asking for more than the minimum
+// units.
+type AutoScaleStatus struct {
+	Ready   bool   `json:"ready"`
+	Active  bool   `json:"active"`
+	Message string `json:"message,omitempty"`
 }
 
 type BehaviorAutoScaleSpec struct {
@@ -36,6 +58,51 @@ type AutoScalePrometheus struct {
 	PrometheusAddress   string  `json:"prometheusAddress,omitempty"`
 }
 
+const (
+	// AutoScaleMetricPods is a metric of the units of the process served by
+	// the custom metrics API, like the requests per second of each unit.
+	AutoScaleMetricPods = "pods"
+	// AutoScaleMetricExternal is a metric not tied to the units of the
+	// process served by the external metrics API, like the depth of a queue.
+	AutoScaleMetricExternal = "external"
+)
+
+// AutoScaleMetric scales the units of a process on a metric served by a
+// metrics adapter installed in the cluster, like prometheus-adapter. Target is
+// the average value of the metric per unit and Selector is an optional label
+// selector, like queue=orders, narrowing the series of the metric.
+type AutoScaleMetric struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Target   string `json:"target"`
+	Selector string `json:"selector,omitempty"`
+}
+
+// AutoScaleKafka scales the units of a process on the lag of a consumer group
+// in a Kafka topic. AuthenticationRef optionally names a
+// ClusterTriggerAuthentication holding the credentials of the brokers.
+type AutoScaleKafka struct {
+	Name                   string `json:"name"`
+	BootstrapServers       string `json:"bootstrapServers"`
+	ConsumerGroup          string `json:"consumerGroup"`
+	Topic                  string `json:"topic,omitempty"`
+	LagThreshold           int64  `json:"lagThreshold"`
+	ActivationLagThreshold int64  `json:"activationLagThreshold,omitempty"`
+	AuthenticationRef      string `json:"authenticationRef,omitempty"`
+}
+
+// AutoScaleSQS scales the units of a process on the number of messages in an
+// AWS SQS queue. AuthenticationRef optionally names a
+// ClusterTriggerAuthentication holding the AWS credentials.
+type AutoScaleSQS struct {
+	Name                  string `json:"name"`
+	QueueURL              string `json:"queueURL"`
+	QueueLength           int64  `json:"queueLength"`
+	ActivationQueueLength int64  `json:"activationQueueLength,omitempty"`
+	Region                string `json:"region"`
+	AuthenticationRef     string `json:"authenticationRef,omitempty"`
+}
+
 type AutoScaleSchedule struct {
 	Name        string `json:"name,omitempty"`
 	MinReplicas int    `json:"minReplicas"`
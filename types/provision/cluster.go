@@ -6,7 +6,9 @@ package provision
 
 import (
 	"context"
+	"encoding/pem"
 	"errors"
+	"strconv"
 
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
@@ -21,6 +23,7 @@ type Cluster struct {
 	ClientKey   []byte            `json:"clientkey"`
 	Pools       []string          `json:"pools"`
 	CustomData  map[string]string `json:"custom_data"`
+	Labels      map[string]string `json:"labels,omitempty"`
 	Local       bool              `json:"local"`
 	Default     bool              `json:"default"`
 	KubeConfig  *KubeConfig       `json:"kubeConfig,omitempty"`
@@ -37,6 +40,78 @@ type ClusterHelpInfo struct {
 	CustomDataHelp  map[string]string `json:"custom_data_help"`
 }
 
+// ClusterFeaturePrefix prefixes the custom data keys toggling provisioner
+// features, the key may also be prefixed with `<pool-name>:` to enable or
+// disable the feature only for a pool.
+const ClusterFeaturePrefix = "feature-"
+
+// ClusterFeature is a provisioner behavior that is only applied when
+// enabled in the cluster, allowing it to be rolled out gradually.
+type ClusterFeature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// NamespaceResourceQuota holds the resources the apps in a namespace of the
+// cluster are allowed to use and how much of them is currently in use.
+type NamespaceResourceQuota struct {
+	Namespace string            `json:"namespace"`
+	Hard      map[string]string `json:"hard"`
+	Used      map[string]string `json:"used"`
+}
+
+// ClusterNode is a node of a cluster, as exposed to the node maintenance
+// API.
+type ClusterNode struct {
+	Name          string            `json:"name"`
+	Address       string            `json:"address,omitempty"`
+	Ready         bool              `json:"ready"`
+	Unschedulable bool              `json:"unschedulable"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// PoolCapacity aggregates the resources of the schedulable nodes of a pool in
+// the cluster and how much of them is requested by the pods running there.
+type PoolCapacity struct {
+	Pool                string `json:"pool"`
+	Nodes               int    `json:"nodes"`
+	AllocatableCPUMilli int64  `json:"allocatableCPUMilli"`
+	AllocatableMemory   int64  `json:"allocatableMemory"`
+	RequestedCPUMilli   int64  `json:"requestedCPUMilli"`
+	RequestedMemory     int64  `json:"requestedMemory"`
+}
+
+// CapacitySimulation tells how many units, requesting UnitCPUMilli and
+// UnitMemory each, fit in the free resources of the nodes of a pool.
+type CapacitySimulation struct {
+	Pool             string `json:"pool"`
+	Units            int    `json:"units"`
+	UnitCPUMilli     int64  `json:"unitCPUMilli"`
+	UnitMemory       int64  `json:"unitMemory"`
+	SchedulableUnits int    `json:"schedulableUnits"`
+	Fits             bool   `json:"fits"`
+}
+
+type ClusterCapacity struct {
+	Pools      []PoolCapacity      `json:"pools"`
+	Simulation *CapacitySimulation `json:"simulation,omitempty"`
+}
+
+const (
+	// ClusterImageSignatureKey is the custom data key requiring the images
+	// of image deploys to be signed, its value is the signature format.
+	ClusterImageSignatureKey = "image-signature-verification"
+	// ClusterImageSignaturePublicKeysKey is the custom data key holding the
+	// PEM encoded public keys, or certificates for notary, trusted to sign
+	// the images.
+	ClusterImageSignaturePublicKeysKey = "image-signature-public-keys"
+
+	ImageSignatureCosign = "cosign"
+	ImageSignatureNotary = "notary"
+)
+
 type ClusterService interface {
 	Create(context.Context, Cluster) error
 	Update(context.Context, Cluster) error
@@ -57,6 +132,39 @@ type ClusterStorage interface {
 	Delete(context.Context, Cluster) error
 }
 
+// FeatureEnabled reports whether the feature is enabled for the pool, pool
+// specific flags take precedence over the cluster wide ones.
+func (c *Cluster) FeatureEnabled(pool, feature string) bool {
+	key := ClusterFeaturePrefix + feature
+	value, ok := c.CustomData[pool+":"+key]
+	if !ok {
+		value = c.CustomData[key]
+	}
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// ImageSignaturePolicy returns the signature format and the PEM encoded keys
+// used to verify the images deployed in the cluster, the format is empty when
+// verification is not required.
+func (c *Cluster) ImageSignaturePolicy() (string, []string) {
+	format := c.CustomData[ClusterImageSignatureKey]
+	if format == "" {
+		return "", nil
+	}
+	var keys []string
+	rest := []byte(c.CustomData[ClusterImageSignaturePublicKeysKey])
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		keys = append(keys, string(pem.EncodeToMemory(block)))
+	}
+	return format, keys
+}
+
 func (c *Cluster) CleanUpSensitive() {
 	c.ClientKey = nil
 	delete(c.CustomData, "token")
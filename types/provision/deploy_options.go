@@ -9,6 +9,7 @@ type DeployKind string
 const (
 	DeployArchiveURL   DeployKind = "archive-url"
 	DeployGit          DeployKind = "git"
+	DeployGitURL       DeployKind = "git-url"
 	DeployImage        DeployKind = "image"
 	DeployBuildedImage DeployKind = "imagebuild"
 	DeployRollback     DeployKind = "rollback"
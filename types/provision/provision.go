@@ -6,8 +6,14 @@ package provision
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/tsuru/tsuru/types/router"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 var ErrProcessNotFound = errors.New("process name could not be found on YAML data")
@@ -17,11 +23,15 @@ type TsuruYamlData struct {
 	Healthcheck *TsuruYamlHealthcheck      `json:"healthcheck,omitempty" bson:",omitempty"`
 	Kubernetes  *TsuruYamlKubernetesConfig `json:"kubernetes,omitempty" bson:",omitempty"`
 	Processes   []TsuruYamlProcess         `json:"processes,omitempty" bson:",omitempty"`
+	Deploy      *TsuruYamlDeploy           `json:"deploy,omitempty" bson:",omitempty"`
+	Metrics     *TsuruYamlMetrics          `json:"metrics,omitempty" bson:",omitempty"`
+	Sidecars    []TsuruYamlSidecar         `json:"sidecars,omitempty" bson:",omitempty"`
 }
 
 type TsuruYamlHooks struct {
 	Restart TsuruYamlRestartHooks `json:"restart" bson:",omitempty"`
 	Build   []string              `json:"build" bson:",omitempty"`
+	Deploy  TsuruYamlDeployHooks  `json:"deploy" bson:",omitempty"`
 }
 
 type TsuruYamlRestartHooks struct {
@@ -29,6 +39,14 @@ type TsuruYamlRestartHooks struct {
 	After  []string `json:"after" bson:",omitempty"`
 }
 
+// TsuruYamlDeployHooks are commands run once per deploy in an isolated unit
+// of the deployed version, Before prior to updating the units and After once
+// all of them are ready.
+type TsuruYamlDeployHooks struct {
+	Before []string `json:"before" bson:",omitempty"`
+	After  []string `json:"after" bson:",omitempty"`
+}
+
 type TsuruYamlHealthcheck struct {
 	Headers              map[string]string `json:"headers,omitempty" bson:",omitempty"`
 	Path                 string            `json:"path"`
@@ -42,9 +60,277 @@ type TsuruYamlHealthcheck struct {
 }
 
 type TsuruYamlProcess struct {
-	Healthcheck *TsuruYamlHealthcheck `json:"healthcheck,omitempty" bson:",omitempty"`
-	Name        string                `json:"name"`
-	Command     string                `json:"command" yaml:"command" bson:"command"`
+	Healthcheck      *TsuruYamlHealthcheck `json:"healthcheck,omitempty" bson:",omitempty"`
+	Name             string                `json:"name"`
+	Command          string                `json:"command" yaml:"command" bson:"command"`
+	DisruptionBudget *DisruptionBudget     `json:"disruption-budget,omitempty" yaml:"disruption-budget" bson:"disruption-budget,omitempty"`
+	Shutdown         *ProcessShutdown      `json:"shutdown,omitempty" yaml:"shutdown" bson:"shutdown,omitempty"`
+	Rollout          *ProcessRollout       `json:"rollout,omitempty" yaml:"rollout" bson:"rollout,omitempty"`
+}
+
+// DisruptionBudget limits how many units of a process may be voluntarily
+// disrupted at once, like during node drains. Each value is either a number
+// of units or a percentage of them, and only one of them may be set.
+type DisruptionBudget struct {
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty" bson:",omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty" bson:",omitempty"`
+}
+
+func (b *DisruptionBudget) Empty() bool {
+	return b == nil || (b.MinAvailable == nil && b.MaxUnavailable == nil)
+}
+
+func (b *DisruptionBudget) Validate() error {
+	if b.Empty() {
+		return errors.New("either minAvailable or maxUnavailable must be set")
+	}
+	if b.MinAvailable != nil && b.MaxUnavailable != nil {
+		return errors.New("minAvailable and maxUnavailable can't be set together")
+	}
+	if b.MinAvailable != nil {
+		return validateUnitsValue("minAvailable", *b.MinAvailable)
+	}
+	return validateUnitsValue("maxUnavailable", *b.MaxUnavailable)
+}
+
+// ProcessShutdown controls how the units of a process are stopped, giving
+// them time to drain their connections. PreStopCommand replaces the sleep
+// run before the units receive SIGTERM, and only one of PreStopCommand and
+// PreStopSleepSeconds may be set. Unset fields fall back to the defaults of
+// the cluster.
+type ProcessShutdown struct {
+	TerminationGracePeriodSeconds *int64   `json:"terminationGracePeriodSeconds,omitempty" bson:",omitempty"`
+	PreStopCommand                []string `json:"preStopCommand,omitempty" bson:",omitempty"`
+	PreStopSleepSeconds           *int64   `json:"preStopSleepSeconds,omitempty" bson:",omitempty"`
+}
+
+func (s *ProcessShutdown) Empty() bool {
+	return s == nil || (s.TerminationGracePeriodSeconds == nil && len(s.PreStopCommand) == 0 && s.PreStopSleepSeconds == nil)
+}
+
+func (s *ProcessShutdown) Validate() error {
+	if s.Empty() {
+		return nil
+	}
+	if s.TerminationGracePeriodSeconds != nil && *s.TerminationGracePeriodSeconds < 0 {
+		return errors.New("terminationGracePeriodSeconds must not be negative")
+	}
+	if s.PreStopSleepSeconds == nil {
+		return nil
+	}
+	if len(s.PreStopCommand) > 0 {
+		return errors.New("preStopCommand and preStopSleepSeconds can't be set together")
+	}
+	if *s.PreStopSleepSeconds < 0 {
+		return errors.New("preStopSleepSeconds must not be negative")
+	}
+	if s.TerminationGracePeriodSeconds != nil && *s.PreStopSleepSeconds >= *s.TerminationGracePeriodSeconds {
+		return errors.New("preStopSleepSeconds must be lower than terminationGracePeriodSeconds")
+	}
+	return nil
+}
+
+// ProcessRollout limits how many units of a process may be created above the
+// desired number and how many may be unavailable while the units are
+// replaced. Each value is either a number of units or a percentage of them.
+// Unset fields fall back to the defaults of the cluster.
+type ProcessRollout struct {
+	MaxSurge       *intstr.IntOrString `json:"maxSurge,omitempty" bson:",omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty" bson:",omitempty"`
+}
+
+func (r *ProcessRollout) Empty() bool {
+	return r == nil || (r.MaxSurge == nil && r.MaxUnavailable == nil)
+}
+
+func (r *ProcessRollout) Validate() error {
+	if r.Empty() {
+		return nil
+	}
+	if r.MaxSurge != nil {
+		if err := validateUnitsValue("maxSurge", *r.MaxSurge); err != nil {
+			return err
+		}
+	}
+	if r.MaxUnavailable != nil {
+		if err := validateUnitsValue("maxUnavailable", *r.MaxUnavailable); err != nil {
+			return err
+		}
+	}
+	if r.MaxSurge != nil && r.MaxUnavailable != nil && isZeroUnits(*r.MaxSurge) && isZeroUnits(*r.MaxUnavailable) {
+		return errors.New("maxSurge and maxUnavailable can't both be zero")
+	}
+	return nil
+}
+
+func isZeroUnits(value intstr.IntOrString) bool {
+	if value.Type == intstr.Int {
+		return value.IntVal == 0
+	}
+	return value.StrVal == "0%"
+}
+
+func validateUnitsValue(field string, value intstr.IntOrString) error {
+	if value.Type == intstr.Int {
+		if value.IntVal < 0 {
+			return fmt.Errorf("%s must not be negative", field)
+		}
+		return nil
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(value.StrVal, "%"))
+	if err != nil || !strings.HasSuffix(value.StrVal, "%") || percent < 0 || percent > 100 {
+		return fmt.Errorf("%s must be a number of units or a percentage, got %q", field, value.StrVal)
+	}
+	return nil
+}
+
+// DefaultPausePointTimeout is how long a deploy waits at a pause point to be
+// continued when the pause point doesn't define its own timeout.
+const DefaultPausePointTimeout = 10 * time.Minute
+
+type TsuruYamlDeploy struct {
+	PausePoints []TsuruYamlPausePoint `json:"pause_points,omitempty" yaml:"pause_points" bson:"pause_points,omitempty"`
+}
+
+// TsuruYamlPausePoint halts the rollout of a process once the given number of
+// units of the new version are ready, until the deploy is continued. An empty
+// process applies the pause point to every process.
+type TsuruYamlPausePoint struct {
+	Process        string `json:"process,omitempty" bson:",omitempty"`
+	Units          int    `json:"units"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" yaml:"timeout_seconds" bson:"timeout_seconds,omitempty"`
+}
+
+func (p TsuruYamlPausePoint) Timeout() time.Duration {
+	if p.TimeoutSeconds > 0 {
+		return time.Duration(p.TimeoutSeconds) * time.Second
+	}
+	return DefaultPausePointTimeout
+}
+
+// DisruptionBudget returns the disruption budget declared for the process,
+// nil when there's none.
+func (y TsuruYamlData) DisruptionBudget(process string) *DisruptionBudget {
+	for _, p := range y.Processes {
+		if p.Name == process {
+			return p.DisruptionBudget
+		}
+	}
+	return nil
+}
+
+// Shutdown returns the shutdown settings declared for the process, nil when
+// there are none.
+func (y TsuruYamlData) Shutdown(process string) *ProcessShutdown {
+	for _, p := range y.Processes {
+		if p.Name == process {
+			return p.Shutdown
+		}
+	}
+	return nil
+}
+
+// Rollout returns the rollout settings declared for the process, nil when
+// there are none.
+func (y TsuruYamlData) Rollout(process string) *ProcessRollout {
+	for _, p := range y.Processes {
+		if p.Name == process {
+			return p.Rollout
+		}
+	}
+	return nil
+}
+
+// PausePoints returns the pause points of the process sorted by units.
+func (y TsuruYamlData) PausePoints(process string) []TsuruYamlPausePoint {
+	if y.Deploy == nil {
+		return nil
+	}
+	var points []TsuruYamlPausePoint
+	for _, p := range y.Deploy.PausePoints {
+		if p.Units > 0 && (p.Process == "" || p.Process == process) {
+			points = append(points, p)
+		}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Units < points[j].Units
+	})
+	return points
+}
+
+// TsuruYamlSidecar is an extra container run in the units of the listed
+// processes, or of every process when none is listed, like log shippers or
+// proxies.
+type TsuruYamlSidecar struct {
+	Name      string                     `json:"name"`
+	Image     string                     `json:"image"`
+	Command   []string                   `json:"command,omitempty" bson:",omitempty"`
+	Processes []string                   `json:"processes,omitempty" bson:",omitempty"`
+	Resources *TsuruYamlSidecarResources `json:"resources,omitempty" bson:",omitempty"`
+	Mounts    []TsuruYamlSidecarMount    `json:"mounts,omitempty" bson:",omitempty"`
+}
+
+// TsuruYamlSidecarResources are the CPU and memory reserved for a sidecar,
+// in Kubernetes quantities like "100m" and "128Mi", also used as its limits.
+type TsuruYamlSidecarResources struct {
+	CPU    string `json:"cpu,omitempty" bson:",omitempty"`
+	Memory string `json:"memory,omitempty" bson:",omitempty"`
+}
+
+// TsuruYamlSidecarMount is a directory shared between a sidecar and the app,
+// mounted at Path in both containers.
+type TsuruYamlSidecarMount struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// SidecarsFor returns the sidecars running alongside the process.
+func (y TsuruYamlData) SidecarsFor(process string) []TsuruYamlSidecar {
+	var sidecars []TsuruYamlSidecar
+	for _, s := range y.Sidecars {
+		if len(s.Processes) == 0 {
+			sidecars = append(sidecars, s)
+			continue
+		}
+		for _, p := range s.Processes {
+			if p == process {
+				sidecars = append(sidecars, s)
+				break
+			}
+		}
+	}
+	return sidecars
+}
+
+// DefaultMetricsPath is the path scraped for metrics when tsuru.yaml doesn't
+// set one.
+const DefaultMetricsPath = "/metrics"
+
+// TsuruYamlMetrics is the Prometheus metrics endpoint exposed by the units of
+// the app. Interval is a duration like "30s", empty uses the default of the
+// Prometheus server.
+type TsuruYamlMetrics struct {
+	Port     int    `json:"port"`
+	Path     string `json:"path,omitempty" bson:",omitempty"`
+	Interval string `json:"interval,omitempty" bson:",omitempty"`
+}
+
+func (m TsuruYamlMetrics) MetricsPath() string {
+	if m.Path == "" {
+		return DefaultMetricsPath
+	}
+	return m.Path
+}
+
+// MetricsScrape describes how the metrics endpoint of an app is scraped.
+// Configured is false while the provisioner objects for the scrape are
+// missing, like before the first deploy declaring the endpoint.
+type MetricsScrape struct {
+	Mode       string `json:"mode"`
+	Port       int    `json:"port"`
+	Path       string `json:"path"`
+	Interval   string `json:"interval,omitempty"`
+	Configured bool   `json:"configured"`
 }
 
 type TsuruYamlKubernetesConfig struct {
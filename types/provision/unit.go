@@ -145,9 +145,32 @@ const (
 	UnitStatusSucceeded = UnitStatus("succeeded")
 )
 
-// UnitMetric represents a a related metrics for an unit.
+// UnitMetric represents a a related metrics for an unit. CPU and Memory are
+// the current usage of the unit, while requests and limits are the resources
+// reserved for it, empty when not set.
 type UnitMetric struct {
-	ID     string
-	CPU    string
-	Memory string
+	ID            string
+	ProcessName   string `json:",omitempty"`
+	CPU           string
+	Memory        string
+	CPURequest    string `json:",omitempty"`
+	CPULimit      string `json:",omitempty"`
+	MemoryRequest string `json:",omitempty"`
+	MemoryLimit   string `json:",omitempty"`
+}
+
+// UnitStatusHistory represents a single state transition observed on a unit,
+// like a container restart or termination.
+type UnitStatusHistory struct {
+	AppName     string     `json:"appName"`
+	Unit        string     `json:"unit"`
+	ProcessName string     `json:"processName"`
+	Version     int        `json:"version"`
+	Status      UnitStatus `json:"status"`
+	Reason      string     `json:"reason,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	ExitCode    *int32     `json:"exitCode,omitempty"`
+	Restarts    int32      `json:"restarts"`
+	OOMKilled   bool       `json:"oomKilled"`
+	Time        time.Time  `json:"time"`
 }
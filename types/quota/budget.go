@@ -0,0 +1,52 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"fmt"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// HoursPerMonth is the average number of hours in a month, used to project
+// the monthly cost of units from the hourly cost of their plans.
+const HoursPerMonth = 730
+
+// Budget is a monthly cost limit of an app or team. Scale-ups projected to
+// exceed it are refused when Enforce is set, otherwise they only emit
+// warning events.
+type Budget struct {
+	MonthlyLimit float64 `json:"monthlyLimit"`
+	Enforce      bool    `json:"enforce,omitempty"`
+}
+
+func (b *Budget) Validate() error {
+	if b != nil && b.MonthlyLimit <= 0 {
+		return &tsuruErrors.ValidationError{Message: "budget monthly limit must be greater than zero"}
+	}
+	return nil
+}
+
+// BudgetStatus is the projected monthly cost of the running units of an app
+// or team along with its budget, if any.
+type BudgetStatus struct {
+	Budget        *Budget `json:"budget,omitempty"`
+	Units         int     `json:"units"`
+	ProjectedCost float64 `json:"projectedCost"`
+}
+
+func (s BudgetStatus) Exceeded() bool {
+	return s.Budget != nil && s.ProjectedCost > s.Budget.MonthlyLimit
+}
+
+type BudgetExceededError struct {
+	Target    string
+	Limit     float64
+	Projected float64
+}
+
+func (err *BudgetExceededError) Error() string {
+	return fmt.Sprintf("projected monthly cost of %s (%.2f) exceeds its budget of %.2f", err.Target, err.Projected, err.Limit)
+}
@@ -18,8 +18,13 @@ var (
 	ErrVolumeBindNotFound       = errors.New("volume bind not found")
 	ErrVolumeAlreadyProvisioned = errors.New("updating a volume already provisioned is not supported, a new volume must be created and the old one deleted if necessary")
 	ErrVolumePlanNotFound       = errors.New("volume-plan not present in pool constraint")
+	ErrSharedVolumeReadOnly     = errors.New("volumes shared across pools can only be bound read-only")
 )
 
+// PlanSecretsKey is the opt of volume plans holding credentials handed only
+// to the provisioner, which are never stored with volumes nor listed.
+const PlanSecretsKey = "secrets"
+
 type VolumePlan struct {
 	Name string
 	Opts map[string]interface{}
@@ -44,6 +49,30 @@ type Volume struct {
 	Status    string
 	Binds     []VolumeBind      `bson:"-"`
 	Opts      map[string]string `bson:",omitempty"`
+	// SharedPools lists other pools whose apps may bind the volume, always
+	// read-only, besides the ones in Pool.
+	SharedPools []string `bson:",omitempty"`
+}
+
+// IsShared returns whether the volume may be bound by apps of other pools.
+func (v *Volume) IsShared() bool {
+	return len(v.SharedPools) > 0
+}
+
+// Pools returns the pool of the volume followed by the pools it's shared
+// with.
+func (v *Volume) Pools() []string {
+	return append([]string{v.Pool}, v.SharedPools...)
+}
+
+// AllowsPool returns whether apps of the pool may bind the volume.
+func (v *Volume) AllowsPool(pool string) bool {
+	for _, p := range v.Pools() {
+		if p == pool {
+			return true
+		}
+	}
+	return false
 }
 
 func (v *Volume) UnmarshalPlan(result interface{}) error {
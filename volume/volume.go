@@ -132,24 +132,29 @@ func (s *volumeService) Delete(ctx context.Context, v *volumeTypes.Volume) error
 	if len(binds) > 0 {
 		return errors.New("cannot delete volume with existing binds")
 	}
-	p, err := pool.GetPoolByName(ctx, v.Pool)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	prov, err := p.GetProvisioner()
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	if volProv, ok := prov.(provision.VolumeProvisioner); ok {
-		err = volProv.DeleteVolume(ctx, v.Name, v.Pool)
+	for _, poolName := range v.Pools() {
+		p, err := pool.GetPoolByName(ctx, poolName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		prov, err := p.GetProvisioner()
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if volProv, ok := prov.(provision.VolumeProvisioner); ok {
+			err = volProv.DeleteVolume(ctx, v.Name, poolName)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
 	}
 	return s.storage.Delete(ctx, v)
 }
 
 func (s *volumeService) BindApp(ctx context.Context, opts *volumeTypes.BindOpts) error {
+	if opts.Volume.IsShared() && !opts.ReadOnly {
+		return volumeTypes.ErrSharedVolumeReadOnly
+	}
 	bind := &volumeTypes.VolumeBind{
 		ID: volumeTypes.VolumeBindID{
 			App:        opts.AppName,
@@ -218,9 +223,11 @@ func (s *volumeService) ListPlans(ctx context.Context) (map[string][]volumeTypes
 	plansMap := asMapStringInterface(internalConfig.ConvertEntries(plansRaw))
 	for planName, planProvsRaw := range plansMap {
 		for prov, provDataRaw := range asMapStringInterface(planProvsRaw) {
+			opts := asMapStringInterface(provDataRaw)
+			delete(opts, volumeTypes.PlanSecretsKey)
 			plans[prov] = append(plans[prov], volumeTypes.VolumePlan{
 				Name: planName,
-				Opts: asMapStringInterface(provDataRaw),
+				Opts: opts,
 			})
 		}
 	}
@@ -228,7 +235,16 @@ func (s *volumeService) ListPlans(ctx context.Context) (map[string][]volumeTypes
 }
 
 func (s *volumeService) CheckPoolVolumeConstraints(ctx context.Context, volume volumeTypes.Volume) error {
-	pool, err := pool.GetPoolByName(ctx, volume.Pool)
+	for _, poolName := range volume.Pools() {
+		if err := checkPoolVolumePlan(ctx, poolName, volume.Plan.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPoolVolumePlan(ctx context.Context, poolName, planName string) error {
+	pool, err := pool.GetPoolByName(ctx, poolName)
 	if err != nil {
 		return err
 	}
@@ -239,7 +255,7 @@ func (s *volumeService) CheckPoolVolumeConstraints(ctx context.Context, volume v
 	}
 
 	for _, vplan := range vPlans {
-		if volume.Plan.Name == vplan {
+		if planName == vplan {
 			return nil
 		}
 	}
@@ -282,8 +298,14 @@ func (s *volumeService) validate(ctx context.Context, v *volumeTypes.Volume) err
 	if !ok {
 		return errors.Errorf("invalid type for plan opts %T", planOpts)
 	}
+	delete(planOpts, volumeTypes.PlanSecretsKey)
 	v.Plan.Opts = planOpts
 
+	err = validateSharedPools(ctx, v, prov.GetName())
+	if err != nil {
+		return err
+	}
+
 	if volumeProv, ok := prov.(provision.VolumeProvisioner); ok {
 		err = volumeProv.ValidateVolume(ctx, v)
 		if err != nil {
@@ -293,6 +315,32 @@ func (s *volumeService) validate(ctx context.Context, v *volumeTypes.Volume) err
 	return nil
 }
 
+// validateSharedPools checks the pools the volume is shared with exist and
+// are handled by the same provisioner of the pool of the volume.
+func validateSharedPools(ctx context.Context, v *volumeTypes.Volume, provName string) error {
+	seen := map[string]bool{v.Pool: true}
+	for _, poolName := range v.SharedPools {
+		if seen[poolName] {
+			msg := fmt.Sprintf("pool %q is duplicated in the pools of the volume", poolName)
+			return errors.WithStack(&tsuruErrors.ValidationError{Message: msg})
+		}
+		seen[poolName] = true
+		p, err := pool.GetPoolByName(ctx, poolName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		prov, err := p.GetProvisioner()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if prov.GetName() != provName {
+			msg := fmt.Sprintf("pool %q must use the same provisioner of pool %q to share the volume", poolName, v.Pool)
+			return errors.WithStack(&tsuruErrors.ValidationError{Message: msg})
+		}
+	}
+	return nil
+}
+
 func (s *volumeService) validateProvisioner(ctx context.Context, v *volumeTypes.Volume) error {
 	isProv, err := isProvisioned(ctx, v)
 	if err != nil {
@@ -305,23 +353,28 @@ func (s *volumeService) validateProvisioner(ctx context.Context, v *volumeTypes.
 }
 
 func isProvisioned(ctx context.Context, v *volumeTypes.Volume) (bool, error) {
-	p, err := pool.GetPoolByName(ctx, v.Pool)
-	if err != nil {
-		return false, errors.WithStack(err)
-	}
-	prov, err := p.GetProvisioner()
-	if err != nil {
-		return false, errors.WithStack(err)
-	}
-	volProv, ok := prov.(provision.VolumeProvisioner)
-	if !ok {
-		return false, errors.New("provisioner is not a volume provisioner")
-	}
-	isProv, err := volProv.IsVolumeProvisioned(ctx, v.Name, v.Pool)
-	if err != nil {
-		return false, errors.WithStack(err)
+	for _, poolName := range v.Pools() {
+		p, err := pool.GetPoolByName(ctx, poolName)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		prov, err := p.GetProvisioner()
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		volProv, ok := prov.(provision.VolumeProvisioner)
+		if !ok {
+			return false, errors.New("provisioner is not a volume provisioner")
+		}
+		isProv, err := volProv.IsVolumeProvisioned(ctx, v.Name, poolName)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if isProv {
+			return true, nil
+		}
 	}
-	return isProv, nil
+	return false, nil
 }
 
 func asMapStringInterface(val interface{}) map[string]interface{} {
@@ -807,6 +807,46 @@ volume-plans:
 	}
 }
 
+func (s *S) TestVolumeSharedAcrossPools(c *check.C) {
+	vs := &volumeService{
+		storage: &volumeTypes.MockVolumeStorage{},
+	}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:        "mypool2",
+		Provisioner: "other",
+	})
+	c.Assert(err, check.IsNil)
+	v := volumeTypes.Volume{
+		Name:        "v1",
+		Plan:        volumeTypes.VolumePlan{Name: "p1"},
+		Pool:        "mypool",
+		SharedPools: []string{"otherpool", "mypool"},
+		TeamOwner:   "myteam",
+	}
+	err = vs.Create(context.TODO(), &v)
+	c.Assert(err, check.ErrorMatches, `pool "mypool" is duplicated in the pools of the volume`)
+	v.SharedPools = []string{"mypool2"}
+	err = vs.Create(context.TODO(), &v)
+	c.Assert(err, check.ErrorMatches, `pool "mypool2" must use the same provisioner of pool "mypool" to share the volume`)
+	v.SharedPools = []string{"otherpool"}
+	err = vs.Create(context.TODO(), &v)
+	c.Assert(err, check.IsNil)
+	c.Assert(v.Pools(), check.DeepEquals, []string{"mypool", "otherpool"})
+	err = vs.BindApp(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		AppName:    "myapp",
+		MountPoint: "/mnt1",
+	})
+	c.Assert(err, check.Equals, volumeTypes.ErrSharedVolumeReadOnly)
+	err = vs.BindApp(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		AppName:    "myapp",
+		MountPoint: "/mnt1",
+		ReadOnly:   true,
+	})
+	c.Assert(err, check.IsNil)
+}
+
 func (s *S) TestRenameTeam(c *check.C) {
 	vs := &volumeService{
 		storage: &volumeTypes.MockVolumeStorage{},